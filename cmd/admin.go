@@ -6,9 +6,12 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/setting"
 
 	"github.com/urfave/cli"
@@ -24,9 +27,21 @@ to make automatic initialization process more smoothly`,
 		Subcommands: []cli.Command{
 			subcmdCreateUser,
 			subcmdChangePassword,
+			subcmdRegenerate,
 		},
 	}
 
+	adminConfigFlag = cli.StringFlag{
+		Name:  "config, c",
+		Value: "custom/conf/app.ini",
+		Usage: "Custom configuration file path",
+	}
+
+	adminJSONFlag = cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print the result as JSON",
+	}
+
 	subcmdCreateUser = cli.Command{
 		Name:   "create-user",
 		Usage:  "Create a new user in database",
@@ -42,6 +57,10 @@ to make automatic initialization process more smoothly`,
 				Value: "",
 				Usage: "User password",
 			},
+			cli.BoolFlag{
+				Name:  "random-password",
+				Usage: "Generate a random password for the user",
+			},
 			cli.StringFlag{
 				Name:  "email",
 				Value: "",
@@ -51,11 +70,17 @@ to make automatic initialization process more smoothly`,
 				Name:  "admin",
 				Usage: "User is an admin",
 			},
+			cli.BoolFlag{
+				Name:  "access-token",
+				Usage: "Generate an access token for the user",
+			},
 			cli.StringFlag{
-				Name:  "config, c",
-				Value: "custom/conf/app.ini",
-				Usage: "Custom configuration file path",
+				Name:  "access-token-name",
+				Value: "gitea-admin",
+				Usage: "Name of the generated access token",
 			},
+			adminJSONFlag,
+			adminConfigFlag,
 		},
 	}
 
@@ -74,15 +99,72 @@ to make automatic initialization process more smoothly`,
 				Value: "",
 				Usage: "New password to set for user",
 			},
+			adminJSONFlag,
+			adminConfigFlag,
+		},
+	}
+
+	subcmdRegenerate = cli.Command{
+		Name:  "regenerate",
+		Usage: "Regenerate specific files",
+		Subcommands: []cli.Command{
+			microcmdRegenKeys,
+			microcmdRegenHooks,
+		},
+	}
+
+	microcmdRegenKeys = cli.Command{
+		Name:   "keys",
+		Usage:  "Rewrite the authorized_keys file of the SSH server",
+		Action: runRegenerateKeys,
+		Flags: []cli.Flag{
+			adminJSONFlag,
+			adminConfigFlag,
+		},
+	}
+
+	microcmdRegenHooks = cli.Command{
+		Name:   "hooks",
+		Usage:  "Rewrite the git hooks of all repositories",
+		Action: runRegenerateHooks,
+		Flags: []cli.Flag{
+			adminJSONFlag,
+			adminConfigFlag,
 		},
 	}
 )
 
-func runChangePassword(c *cli.Context) error {
-	if !c.IsSet("password") {
-		return fmt.Errorf("Password is not specified")
-	} else if !c.IsSet("username") {
-		return fmt.Errorf("Username is not specified")
+// adminResult is the machine-readable output of admin commands.
+type adminResult struct {
+	ID          int64  `json:"id,omitempty"`
+	Username    string `json:"username,omitempty"`
+	Email       string `json:"email,omitempty"`
+	IsAdmin     bool   `json:"is_admin,omitempty"`
+	Password    string `json:"password,omitempty"`
+	AccessToken string `json:"access_token,omitempty"`
+	Message     string `json:"message"`
+}
+
+// printAdminResult prints the message of the result, or the whole result as
+// JSON if requested so that it can be consumed by orchestration tools.
+func printAdminResult(c *cli.Context, result *adminResult) error {
+	if c.Bool("json") {
+		return json.NewEncoder(os.Stdout).Encode(result)
+	}
+
+	fmt.Println(result.Message)
+	if len(result.Password) > 0 {
+		fmt.Printf("Password: %s\n", result.Password)
+	}
+	if len(result.AccessToken) > 0 {
+		fmt.Printf("Access token: %s\n", result.AccessToken)
+	}
+	return nil
+}
+
+func initDB(c *cli.Context) error {
+	if c.IsSet("config") {
+		setting.CustomConf = c.String("config")
 	}
 
 	setting.NewContext()
@@ -92,6 +174,19 @@ func runChangePassword(c *cli.Context) error {
 	if err := models.SetEngine(); err != nil {
 		return fmt.Errorf("models.SetEngine: %v", err)
 	}
+	return nil
+}
+
+func runChangePassword(c *cli.Context) error {
+	if !c.IsSet("password") {
+		return fmt.Errorf("Password is not specified")
+	} else if !c.IsSet("username") {
+		return fmt.Errorf("Username is not specified")
+	}
+
+	if err := initDB(c); err != nil {
+		return err
+	}
 
 	uname := c.String("username")
 	user, err := models.GetUserByName(uname)
@@ -107,41 +202,88 @@ func runChangePassword(c *cli.Context) error {
 		return fmt.Errorf("%v", err)
 	}
 
-	fmt.Printf("User '%s' password has been successfully updated!\n", uname)
-	return nil
+	return printAdminResult(c, &adminResult{
+		ID:       user.ID,
+		Username: user.Name,
+		Message:  fmt.Sprintf("User '%s' password has been successfully updated!", uname),
+	})
 }
 
 func runCreateUser(c *cli.Context) error {
 	if !c.IsSet("name") {
 		return fmt.Errorf("Username is not specified")
-	} else if !c.IsSet("password") {
-		return fmt.Errorf("Password is not specified")
+	} else if c.IsSet("password") == c.Bool("random-password") {
+		return fmt.Errorf("Either password or random-password must be specified")
 	} else if !c.IsSet("email") {
 		return fmt.Errorf("Email is not specified")
 	}
 
-	if c.IsSet("config") {
-		setting.CustomConf = c.String("config")
+	if err := initDB(c); err != nil {
+		return err
 	}
 
-	setting.NewContext()
-	models.LoadConfigs()
-
-	setting.NewXORMLogService(false)
-	if err := models.SetEngine(); err != nil {
-		return fmt.Errorf("models.SetEngine: %v", err)
+	result := &adminResult{}
+	password := c.String("password")
+	if c.Bool("random-password") {
+		var err error
+		if password, err = base.GetRandomString(setting.MinPasswordLength + 8); err != nil {
+			return fmt.Errorf("GetRandomString: %v", err)
+		}
+		result.Password = password
 	}
 
-	if err := models.CreateUser(&models.User{
+	user := &models.User{
 		Name:     c.String("name"),
 		Email:    c.String("email"),
-		Passwd:   c.String("password"),
+		Passwd:   password,
 		IsActive: true,
 		IsAdmin:  c.Bool("admin"),
-	}); err != nil {
+	}
+	if err := models.CreateUser(user); err != nil {
 		return fmt.Errorf("CreateUser: %v", err)
 	}
 
-	fmt.Printf("New user '%s' has been successfully created!\n", c.String("name"))
-	return nil
+	if c.Bool("access-token") {
+		t := &models.AccessToken{
+			Name: c.String("access-token-name"),
+			UID:  user.ID,
+		}
+		if err := models.NewAccessToken(t); err != nil {
+			return fmt.Errorf("NewAccessToken: %v", err)
+		}
+		result.AccessToken = t.Sha1
+	}
+
+	result.ID = user.ID
+	result.Username = user.Name
+	result.Email = user.Email
+	result.IsAdmin = user.IsAdmin
+	result.Message = fmt.Sprintf("New user '%s' has been successfully created!", user.Name)
+	return printAdminResult(c, result)
+}
+
+func runRegenerateKeys(c *cli.Context) error {
+	if err := initDB(c); err != nil {
+		return err
+	}
+
+	if err := models.RewriteAllPublicKeys(); err != nil {
+		return fmt.Errorf("RewriteAllPublicKeys: %v", err)
+	}
+	return printAdminResult(c, &adminResult{
+		Message: "The authorized_keys file has been successfully rewritten!",
+	})
+}
+
+func runRegenerateHooks(c *cli.Context) error {
+	if err := initDB(c); err != nil {
+		return err
+	}
+
+	if err := models.SyncRepositoryHooks(); err != nil {
+		return fmt.Errorf("SyncRepositoryHooks: %v", err)
+	}
+	return printAdminResult(c, &adminResult{
+		Message: "The hooks of all repositories have been successfully rewritten!",
+	})
 }
@@ -0,0 +1,231 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// CmdDoctor represents the available doctor sub-command.
+var CmdDoctor = cli.Command{
+	Name:  "doctor",
+	Usage: "Diagnose problems of the installation",
+	Description: `Check the configuration, the database and the repositories of the installation
+for common problems, and fix those which are selected by the fix flags`,
+	Action: runDoctor,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "config, c",
+			Value: "custom/conf/app.ini",
+			Usage: "Custom configuration file path",
+		},
+		cli.BoolFlag{
+			Name:  "fix",
+			Usage: "Fix the problems of all checks",
+		},
+		cli.BoolFlag{
+			Name:  "fix-config",
+			Usage: "Create the missing directories of the configuration",
+		},
+		cli.BoolFlag{
+			Name:  "fix-migrations",
+			Usage: "Migrate the database to the current version",
+		},
+		cli.BoolFlag{
+			Name:  "fix-hooks",
+			Usage: "Rewrite the hooks of all repositories",
+		},
+		cli.BoolFlag{
+			Name:  "fix-orphans",
+			Usage: "Move orphaned repositories into the '" + models.OrphanedRepositoriesDir + "' directory of the repository root",
+		},
+		cli.BoolFlag{
+			Name:  "fix-mirrors",
+			Usage: "Restore the remote configuration of mirrors and delete mirrors of missing repositories",
+		},
+	},
+}
+
+// doctorCheck checks one aspect of the installation, it returns the problems
+// found, which are fixed before returning if fix is true. Checks without fix
+// flag only report problems to be fixed manually.
+type doctorCheck struct {
+	title   string
+	fixFlag string
+	run     func(fix bool) ([]string, error)
+}
+
+var doctorChecks = []doctorCheck{
+	{"Configuration settings", "", checkConfigSettings},
+	{"Configuration directories", "fix-config", checkConfigDirs},
+	{"Database version", "fix-migrations", checkDBVersion},
+	{"Repository hooks", "fix-hooks", checkHooks},
+	{"Orphaned repositories", "fix-orphans", checkOrphanedRepositories},
+	{"Mirror remotes", "fix-mirrors", checkMirrors},
+}
+
+func runDoctor(c *cli.Context) error {
+	if err := initDB(c); err != nil {
+		return err
+	}
+
+	var failed int
+	for _, check := range doctorChecks {
+		fix := len(check.fixFlag) > 0 && (c.Bool("fix") || c.Bool(check.fixFlag))
+		problems, err := check.run(fix)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Printf("[ERROR] %s: %v\n", check.title, err)
+		case len(problems) == 0:
+			fmt.Printf("[OK] %s\n", check.title)
+		case fix:
+			fmt.Printf("[FIXED] %s\n", check.title)
+		case len(check.fixFlag) == 0:
+			failed++
+			fmt.Printf("[FAIL] %s\n", check.title)
+		default:
+			failed++
+			fmt.Printf("[FAIL] %s, fix with --%s\n", check.title, check.fixFlag)
+		}
+		for _, problem := range problems {
+			fmt.Printf(" - %s\n", problem)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(doctorChecks))
+	}
+	return nil
+}
+
+func checkConfigSettings(_ bool) ([]string, error) {
+	var problems []string
+	if !setting.InstallLock {
+		problems = append(problems, "INSTALL_LOCK is not set, the installation is not finished")
+	}
+	if setting.SecretKey == "!#@FDEWREWR&*(" {
+		problems = append(problems, "SECRET_KEY has the default value")
+	}
+	if setting.LFS.StartServer && len(setting.LFS.JWTSecretBase64) == 0 {
+		problems = append(problems, "LFS_JWT_SECRET is not set while the LFS server is enabled")
+	}
+	return problems, nil
+}
+
+func checkConfigDirs(fix bool) ([]string, error) {
+	var problems []string
+	dirs := [][2]string{
+		{"APP_DATA_PATH", setting.AppDataPath},
+		{"repository ROOT", setting.RepoRootPath},
+		{"log ROOT_PATH", setting.LogRootPath},
+	}
+	if !setting.SSH.Disabled && !setting.SSH.StartBuiltinServer {
+		dirs = append(dirs, [2]string{"SSH_ROOT_PATH", setting.SSH.RootPath})
+	}
+	if setting.LFS.StartServer {
+		dirs = append(dirs, [2]string{"LFS_CONTENT_PATH", setting.LFS.ContentPath})
+	}
+	for _, d := range dirs {
+		name, dir := d[0], d[1]
+		fi, err := os.Stat(dir)
+		switch {
+		case err == nil && !fi.IsDir():
+			return problems, fmt.Errorf("%s '%s' is not a directory", name, dir)
+		case os.IsNotExist(err):
+			problems = append(problems, fmt.Sprintf("%s '%s' does not exist", name, dir))
+			if fix {
+				if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+					return problems, err
+				}
+			}
+		case err != nil:
+			return problems, err
+		}
+	}
+	return problems, nil
+}
+
+func checkDBVersion(fix bool) ([]string, error) {
+	current, expected, err := models.CheckDBVersion()
+	if err != nil {
+		return nil, err
+	} else if current == expected {
+		return nil, nil
+	}
+
+	problems := []string{fmt.Sprintf("database version is %d, expected %d", current, expected)}
+	if fix {
+		if err = models.NewEngine(); err != nil {
+			return problems, err
+		}
+	}
+	return problems, nil
+}
+
+func checkHooks(fix bool) ([]string, error) {
+	problems, err := models.CheckRepositoryHooks()
+	if err != nil {
+		return nil, err
+	}
+	for i := range problems {
+		problems[i] = fmt.Sprintf("'%s' is missing or outdated", problems[i])
+	}
+
+	if len(problems) > 0 && fix {
+		if err = models.SyncRepositoryHooks(); err != nil {
+			return problems, err
+		}
+	}
+	return problems, nil
+}
+
+func checkOrphanedRepositories(fix bool) ([]string, error) {
+	repoPaths, err := models.FindOrphanedRepositories()
+	if err != nil {
+		return nil, err
+	}
+
+	problems := make([]string, 0, len(repoPaths))
+	for _, repoPath := range repoPaths {
+		problems = append(problems, fmt.Sprintf("'%s' does not belong to any repository", repoPath))
+		if fix {
+			if err = models.MoveOrphanedRepository(repoPath); err != nil {
+				return problems, err
+			}
+		}
+	}
+	return problems, nil
+}
+
+func checkMirrors(fix bool) ([]string, error) {
+	broken, err := models.CheckMirrorRemotes()
+	if err != nil {
+		return nil, err
+	}
+
+	var unfixable int
+	problems := make([]string, 0, len(broken))
+	for _, b := range broken {
+		problems = append(problems, fmt.Sprintf("mirror of repository %d: %s", b.Mirror.RepoID, b.Problem))
+		if !b.Fixable {
+			unfixable++
+		} else if fix {
+			if err = b.Fix(); err != nil {
+				return problems, err
+			}
+		}
+	}
+	if unfixable > 0 {
+		return problems, fmt.Errorf("%d mirrors must be fixed manually", unfixable)
+	}
+	return problems, nil
+}
@@ -6,18 +6,24 @@
 package cmd
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models"
+	gitealog "code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 
-	"github.com/Unknwon/cae/zip"
 	"github.com/Unknwon/com"
 	"github.com/urfave/cli"
 )
@@ -35,6 +41,15 @@ It can be used for backup and capture Gitea server image to send to maintainer`,
 			Value: "custom/conf/app.ini",
 			Usage: "Custom configuration file path",
 		},
+		cli.StringFlag{
+			Name:  "file, f",
+			Usage: `Name of the dump file, "-" writes it to stdout (default "gitea-dump-<timestamp>.<type>")`,
+		},
+		cli.StringFlag{
+			Name:  "type",
+			Value: dumpTypeZip,
+			Usage: `Archive type of the dump, "zip" or "tar.gz"`,
+		},
 		cli.BoolFlag{
 			Name:  "verbose, v",
 			Usage: "Show process details",
@@ -48,13 +63,48 @@ It can be used for backup and capture Gitea server image to send to maintainer`,
 			Name:  "database, d",
 			Usage: "Specify the database SQL syntax",
 		},
+		cli.BoolFlag{
+			Name:  "skip-lfs-data",
+			Usage: "Skip the LFS objects",
+		},
+		cli.BoolFlag{
+			Name:  "skip-attachment-data",
+			Usage: "Skip the attachments",
+		},
+		cli.BoolFlag{
+			Name:  "skip-log",
+			Usage: "Skip the log directory",
+		},
 	},
 }
 
+// Archive types of a dump.
+const (
+	dumpTypeZip   = "zip"
+	dumpTypeTarGz = "tar.gz"
+)
+
+// dumpMetaName is the name of the entry describing the dump, it is written
+// first so it can be checked before anything is restored.
+const dumpMetaName = "gitea-dump.json"
+
+// dumpMeta describes the Gitea instance a dump was created from.
+type dumpMeta struct {
+	Version      string `json:"version"`
+	DBVersion    int64  `json:"db_version"`
+	DatabaseType string `json:"database_type"`
+}
+
 func runDump(ctx *cli.Context) error {
 	if ctx.IsSet("config") {
 		setting.CustomConf = ctx.String("config")
 	}
+	stdout := os.Stdout
+	if ctx.String("file") == "-" {
+		// Everything logged to the console would end up in the dump otherwise.
+		os.Stdout = os.Stderr
+		gitealog.NewLogger(0, "console", `{"level": 0}`)
+	}
 	setting.NewContext()
 	setting.NewServices() // cannot access session settings otherwise
 	models.LoadConfigs()
@@ -64,134 +114,399 @@ func runDump(ctx *cli.Context) error {
 		return err
 	}
 
-	tmpDir := ctx.String("tempdir")
-	if _, err := os.Stat(tmpDir); os.IsNotExist(err) {
-		log.Fatalf("Path does not exist: %s", tmpDir)
+	if _, err = dump(dumpOptions{
+		FileName:           ctx.String("file"),
+		Stdout:             stdout,
+		Type:               ctx.String("type"),
+		TmpDir:             ctx.String("tempdir"),
+		DatabaseType:       ctx.String("database"),
+		Verbose:            ctx.Bool("verbose"),
+		SkipLFSData:        ctx.Bool("skip-lfs-data"),
+		SkipAttachmentData: ctx.Bool("skip-attachment-data"),
+		SkipLog:            ctx.Bool("skip-log"),
+	}); err != nil {
+		log.Fatal(err)
+	}
+	return nil
+}
+
+// dumpOptions represents the options of a dump.
+type dumpOptions struct {
+	// FileName is the name of the dump file, "-" writes it to Stdout.
+	FileName           string
+	Stdout             io.WriteCloser
+	Type               string
+	TmpDir             string
+	DatabaseType       string
+	Verbose            bool
+	SkipLFSData        bool
+	SkipAttachmentData bool
+	SkipLog            bool
+	// CurrentSchema dumps the tables as they are in the database, which is
+	// required before the database is migrated.
+	CurrentSchema bool
+}
+
+// dump writes all related files and database into an archive, and returns
+// its name.
+func dump(opts dumpOptions) (string, error) {
+	if len(opts.Type) == 0 {
+		opts.Type = dumpTypeZip
 	}
-	TmpWorkDir, err := ioutil.TempDir(tmpDir, "gitea-dump-")
+	if opts.Type != dumpTypeZip && opts.Type != dumpTypeTarGz {
+		return "", fmt.Errorf("Unknown dump type: %s", opts.Type)
+	}
+	if _, err := os.Stat(opts.TmpDir); os.IsNotExist(err) {
+		return "", fmt.Errorf("Path does not exist: %s", opts.TmpDir)
+	}
+	TmpWorkDir, err := ioutil.TempDir(opts.TmpDir, "gitea-dump-")
 	if err != nil {
-		log.Fatalf("Failed to create tmp work directory: %v", err)
+		return "", fmt.Errorf("Failed to create tmp work directory: %v", err)
 	}
 	log.Printf("Creating tmp work dir: %s", TmpWorkDir)
+	defer func() {
+		log.Printf("Removing tmp work dir: %s", TmpWorkDir)
+		if err := os.RemoveAll(TmpWorkDir); err != nil {
+			log.Printf("Failed to remove %s: %v", TmpWorkDir, err)
+		}
+	}()
 
 	// work-around #1103
 	if os.Getenv("TMPDIR") == "" {
 		os.Setenv("TMPDIR", TmpWorkDir)
 	}
 
-	reposDump := path.Join(TmpWorkDir, "gitea-repo.zip")
-	dbDump := path.Join(TmpWorkDir, "gitea-db.sql")
-
-	log.Printf("Dumping local repositories...%s", setting.RepoRootPath)
-	zip.Verbose = ctx.Bool("verbose")
-	if err := zip.PackTo(setting.RepoRootPath, reposDump, true); err != nil {
-		log.Fatalf("Failed to dump local repositories: %v", err)
+	dbVersion, _, err := models.CheckDBVersion()
+	if err != nil {
+		return "", fmt.Errorf("Failed to get database version: %v", err)
+	}
+	meta := dumpMeta{
+		Version:      setting.AppVer,
+		DBVersion:    dbVersion,
+		DatabaseType: models.DbCfg.Type,
 	}
 
-	targetDBType := ctx.String("database")
+	dbDump := path.Join(TmpWorkDir, "gitea-db.sql")
+	targetDBType := opts.DatabaseType
 	if len(targetDBType) > 0 && targetDBType != models.DbCfg.Type {
 		log.Printf("Dumping database %s => %s...", models.DbCfg.Type, targetDBType)
+		meta.DatabaseType = targetDBType
 	} else {
 		log.Printf("Dumping database...")
 	}
 
-	if err := models.DumpDatabase(dbDump, targetDBType); err != nil {
-		log.Fatalf("Failed to dump database: %v", err)
+	dumpDatabase := models.DumpDatabase
+	if opts.CurrentSchema {
+		dumpDatabase = models.DumpCurrentDatabase
+	}
+	if err := dumpDatabase(dbDump, targetDBType); err != nil {
+		return "", fmt.Errorf("Failed to dump database: %v", err)
+	}
+
+	fileName := opts.FileName
+	if len(fileName) == 0 {
+		fileName = fmt.Sprintf("gitea-dump-%d.%s", time.Now().Unix(), opts.Type)
+	}
+	out := opts.Stdout
+	if fileName != "-" {
+		f, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return "", fmt.Errorf("Failed to create %s: %v", fileName, err)
+		}
+		out = f
 	}
 
-	fileName := fmt.Sprintf("gitea-dump-%d.zip", time.Now().Unix())
 	log.Printf("Packing dump files...")
-	z, err := zip.Create(fileName)
+	w := newArchiveWriter(out, opts.Type)
+	if err = writeDump(w, meta, dbDump, opts); err == nil {
+		err = w.Close()
+	}
+	if fileName != "-" {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(fileName)
+		}
+	}
 	if err != nil {
-		log.Fatalf("Failed to create %s: %v", fileName, err)
+		return "", fmt.Errorf("Failed to save %s: %v", fileName, err)
 	}
 
-	if err := z.AddFile("gitea-repo.zip", reposDump); err != nil {
-		log.Fatalf("Failed to include gitea-repo.zip: %v", err)
+	log.Printf("Finish dumping in file %s", fileName)
+	return fileName, nil
+}
+
+// dumpDir is a directory of the instance and its name in a dump.
+type dumpDir struct {
+	Name string
+	Path string
+}
+
+// writeDump writes the entries of a dump in the order they are restored.
+func writeDump(w archiveWriter, meta dumpMeta, dbDump string, opts dumpOptions) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
 	}
-	if err := z.AddFile("gitea-db.sql", dbDump); err != nil {
-		log.Fatalf("Failed to include gitea-db.sql: %v", err)
+	if err = w.AddBytes(dumpMetaName, data); err != nil {
+		return fmt.Errorf("Failed to include %s: %v", dumpMetaName, err)
+	}
+	if err = w.AddFile("gitea-db.sql", dbDump); err != nil {
+		return fmt.Errorf("Failed to include gitea-db.sql: %v", err)
+	}
+
+	log.Printf("Dumping local repositories...%s", setting.RepoRootPath)
+	if err = addDirectory(w, "repos", setting.RepoRootPath, nil, opts.Verbose); err != nil {
+		return fmt.Errorf("Failed to dump local repositories: %v", err)
 	}
+
 	customDir, err := os.Stat(setting.CustomPath)
 	if err == nil && customDir.IsDir() {
-		if err := z.AddDir("custom", setting.CustomPath); err != nil {
-			log.Fatalf("Failed to include custom: %v", err)
+		if err := addDirectory(w, "custom", setting.CustomPath, nil, opts.Verbose); err != nil {
+			return fmt.Errorf("Failed to include custom: %v", err)
 		}
 	} else {
 		log.Printf("Custom dir %s doesn't exist, skipped", setting.CustomPath)
 	}
 
+	// LFS objects and attachments are stored in the data directory unless
+	// they are configured elsewhere. A SQLite database is dumped as SQL above.
+	var excludes []string
+	if models.DbCfg.Type == "sqlite3" {
+		excludes = append(excludes, models.DbCfg.Path)
+	}
+	if setting.SessionConfig.Provider == "file" {
+		if len(setting.SessionConfig.ProviderConfig) == 0 {
+			setting.SessionConfig.ProviderConfig = "data/sessions"
+		}
+		excludes = append(excludes, setting.SessionConfig.ProviderConfig)
+	}
+	var extraDirs []dumpDir
+	if setting.LFS.StartServer {
+		if opts.SkipLFSData {
+			excludes = append(excludes, setting.LFS.ContentPath)
+		} else if !isSubDir(setting.AppDataPath, setting.LFS.ContentPath) {
+			extraDirs = append(extraDirs, dumpDir{"lfs", setting.LFS.ContentPath})
+		}
+	}
+	if opts.SkipAttachmentData {
+		excludes = append(excludes, setting.AttachmentPath)
+	} else if !isSubDir(setting.AppDataPath, setting.AttachmentPath) {
+		extraDirs = append(extraDirs, dumpDir{"attachments", setting.AttachmentPath})
+	}
+
 	if com.IsExist(setting.AppDataPath) {
 		log.Printf("Packing data directory...%s", setting.AppDataPath)
-
-		var sessionAbsPath string
-		if setting.SessionConfig.Provider == "file" {
-			if len(setting.SessionConfig.ProviderConfig) == 0 {
-				setting.SessionConfig.ProviderConfig = "data/sessions"
-			}
-			sessionAbsPath, _ = filepath.Abs(setting.SessionConfig.ProviderConfig)
+		if err := addDirectory(w, "data", setting.AppDataPath, excludes, opts.Verbose); err != nil {
+			return fmt.Errorf("Failed to include data directory: %v", err)
+		}
+	}
+	for _, dir := range extraDirs {
+		if !com.IsExist(dir.Path) {
+			continue
 		}
-		if err := zipAddDirectoryExclude(z, "data", setting.AppDataPath, sessionAbsPath); err != nil {
-			log.Fatalf("Failed to include data directory: %v", err)
+		log.Printf("Packing %s directory...%s", dir.Name, dir.Path)
+		if err := addDirectory(w, dir.Name, dir.Path, nil, opts.Verbose); err != nil {
+			return fmt.Errorf("Failed to include %s: %v", dir.Name, err)
 		}
 	}
 
-	if err := z.AddDir("log", setting.LogRootPath); err != nil {
-		log.Fatalf("Failed to include log: %v", err)
+	if !opts.SkipLog {
+		if err := addDirectory(w, "log", setting.LogRootPath, nil, opts.Verbose); err != nil {
+			return fmt.Errorf("Failed to include log: %v", err)
+		}
+	}
+	return nil
+}
+
+// isSubDir returns true if dir is inside of parent, or parent itself.
+func isSubDir(parent, dir string) bool {
+	parent, err := filepath.Abs(parent)
+	if err != nil {
+		return false
 	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(parent, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
 
-	if err = z.Close(); err != nil {
-		_ = os.Remove(fileName)
-		log.Fatalf("Failed to save %s: %v", fileName, err)
+// addDirectory adds the files of absPath to the archive under archivePath,
+// excluding the files and directories in excludes.
+func addDirectory(w archiveWriter, archivePath, absPath string, excludes []string, verbose bool) error {
+	absPath, err := filepath.Abs(absPath)
+	if err != nil {
+		return err
 	}
+	excludeAbsPaths := make(map[string]bool, len(excludes))
+	for _, exclude := range excludes {
+		if exclude, err = filepath.Abs(exclude); err == nil {
+			excludeAbsPaths[exclude] = true
+		}
+	}
+
+	return filepath.Walk(absPath, func(currentAbsPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if excludeAbsPaths[currentAbsPath] {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(absPath, currentAbsPath)
+		if err != nil {
+			return err
+		}
+		currentArchivePath := path.Join(archivePath, filepath.ToSlash(rel))
+		if verbose {
+			log.Printf("Adding %s", currentArchivePath)
+		}
+		switch {
+		case info.IsDir():
+			return w.AddDir(currentArchivePath, info)
+		case info.Mode().IsRegular():
+			return w.AddFile(currentArchivePath, currentAbsPath)
+		}
+		// Sockets, symlinks and the like are not part of a dump.
+		return nil
+	})
+}
 
-	if err := os.Chmod(fileName, 0600); err != nil {
-		log.Printf("Can't change file access permissions mask to 0600: %v", err)
+// archiveWriter writes the entries of a dump into an archive.
+type archiveWriter interface {
+	AddDir(name string, info os.FileInfo) error
+	AddFile(name, absPath string) error
+	AddBytes(name string, data []byte) error
+	Close() error
+}
+
+// newArchiveWriter returns an archiveWriter writing an archive of the given
+// type to w, which does not need to be seekable.
+func newArchiveWriter(w io.Writer, typ string) archiveWriter {
+	if typ == dumpTypeTarGz {
+		gw := gzip.NewWriter(w)
+		return &tarGzArchiveWriter{gw: gw, tw: tar.NewWriter(gw)}
 	}
+	return &zipArchiveWriter{zw: zip.NewWriter(w)}
+}
 
-	log.Printf("Removing tmp work dir: %s", TmpWorkDir)
+type zipArchiveWriter struct {
+	zw *zip.Writer
+}
 
-	if err := os.RemoveAll(TmpWorkDir); err != nil {
-		log.Fatalf("Failed to remove %s: %v", TmpWorkDir, err)
+func (w *zipArchiveWriter) AddDir(name string, info os.FileInfo) error {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
 	}
-	log.Printf("Finish dumping in file %s", fileName)
+	header.Name = name + "/"
+	_, err = w.zw.CreateHeader(header)
+	return err
+}
 
-	return nil
+func (w *zipArchiveWriter) AddFile(name, absPath string) error {
+	f, err := os.Open(absPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+	fw, err := w.zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, f)
+	return err
 }
 
-// zipAddDirectoryExclude zips absPath to specified zipPath inside z excluding excludeAbsPath
-func zipAddDirectoryExclude(zip *zip.ZipArchive, zipPath, absPath string, excludeAbsPath string) error {
-	absPath, err := filepath.Abs(absPath)
+func (w *zipArchiveWriter) AddBytes(name string, data []byte) error {
+	header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+	header.SetModTime(time.Now())
+	header.SetMode(0600)
+	fw, err := w.zw.CreateHeader(header)
 	if err != nil {
 		return err
 	}
-	dir, err := os.Open(absPath)
+	_, err = fw.Write(data)
+	return err
+}
+
+func (w *zipArchiveWriter) Close() error {
+	return w.zw.Close()
+}
+
+type tarGzArchiveWriter struct {
+	gw *gzip.Writer
+	tw *tar.Writer
+}
+
+func (w *tarGzArchiveWriter) AddDir(name string, info os.FileInfo) error {
+	header, err := tar.FileInfoHeader(info, "")
 	if err != nil {
 		return err
 	}
-	defer dir.Close()
+	header.Name = name + "/"
+	return w.tw.WriteHeader(header)
+}
 
-	zip.AddEmptyDir(zipPath)
+func (w *tarGzArchiveWriter) AddFile(name, absPath string) error {
+	f, err := os.Open(absPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
 
-	files, err := dir.Readdir(0)
+	header, err := tar.FileInfoHeader(info, "")
 	if err != nil {
 		return err
 	}
-	for _, file := range files {
-		currentAbsPath := path.Join(absPath, file.Name())
-		currentZipPath := path.Join(zipPath, file.Name())
-		if file.IsDir() {
-			if currentAbsPath != excludeAbsPath {
-				if err = zipAddDirectoryExclude(zip, currentZipPath, currentAbsPath, excludeAbsPath); err != nil {
-					return err
-				}
-			}
+	header.Name = name
+	if err = w.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	// The file may grow while it is dumped, e.g. a log file, but the tar
+	// header has its size already.
+	_, err = io.CopyN(w.tw, f, header.Size)
+	return err
+}
 
-		} else {
-			if err = zip.AddFile(currentZipPath, currentAbsPath); err != nil {
-				return err
-			}
-		}
+func (w *tarGzArchiveWriter) AddBytes(name string, data []byte) error {
+	if err := w.tw.WriteHeader(&tar.Header{
+		Name:     name,
+		Mode:     0600,
+		Size:     int64(len(data)),
+		ModTime:  time.Now(),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
 	}
-	return nil
+	_, err := w.tw.Write(data)
+	return err
+}
+
+func (w *tarGzArchiveWriter) Close() error {
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+	return w.gw.Close()
 }
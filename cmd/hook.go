@@ -8,6 +8,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -84,12 +85,21 @@ func runHookPreReceive(c *cli.Context) error {
 	// the environment setted on serv command
 	repoID, _ := strconv.ParseInt(os.Getenv(models.ProtectedBranchRepoID), 10, 64)
 	isWiki := (os.Getenv(models.EnvRepoIsWiki) == "true")
-	//username := os.Getenv(models.EnvRepoUsername)
-	//reponame := os.Getenv(models.EnvRepoName)
-	//repoPath := models.RepoPath(username, reponame)
+	isReviewOnly := (os.Getenv(models.EnvPushForReviewOnly) == "true")
+	username := os.Getenv(models.EnvRepoUsername)
+	reponame := os.Getenv(models.EnvRepoName)
+	repoPath := models.RepoPath(username, reponame)
+
+	data, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		fail("Internal error", "Fail to read pre-receive input: %v", err)
+	}
+	if err = models.RunGlobalGitHook("pre-receive", c.Args(), data, os.Stderr); err != nil {
+		fail("push declined by the global pre-receive hook", "RunGlobalGitHook: %v", err)
+	}
 
 	buf := bytes.NewBuffer(nil)
-	scanner := bufio.NewScanner(os.Stdin)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 	for scanner.Scan() {
 		buf.Write(scanner.Bytes())
 		buf.WriteByte('\n')
@@ -104,10 +114,21 @@ func runHookPreReceive(c *cli.Context) error {
 			continue
 		}
 
-		//oldCommitID := string(fields[0])
+		oldCommitID := string(fields[0])
 		newCommitID := string(fields[1])
 		refFullName := string(fields[2])
 
+		if strings.HasPrefix(refFullName, models.AgitRefPrefix) {
+			if newCommitID == git.EmptySHA {
+				fail(fmt.Sprintf("%s cannot be deleted", refFullName), "")
+			} else if _, _, err := models.ParseAgitRef(repoPath, refFullName); err != nil {
+				fail(err.Error(), "")
+			}
+			continue
+		} else if isReviewOnly {
+			fail(fmt.Sprintf("you can only push to %s<branch>/<topic> to open pull requests", models.AgitRefPrefix), "")
+		}
+
 		// FIXME: when we add feature to protected branch to deny force push, then uncomment below
 		/*var isForce bool
 		// detect force push
@@ -121,6 +142,17 @@ func runHookPreReceive(c *cli.Context) error {
 		}*/
 
 		branchName := strings.TrimPrefix(refFullName, git.BranchPrefix)
+		if oldCommitID == git.EmptySHA && strings.HasPrefix(refFullName, git.BranchPrefix) {
+			renamed, err := private.GetRenamedBranch(repoID, branchName)
+			if err != nil {
+				log.GitLogger.Error(2, "GetRenamedBranch: %v", err)
+			} else if renamed != nil {
+				fmt.Fprintf(os.Stderr, "Gitea: branch %s was renamed to %s, update your clone with:\n", branchName, renamed.NewName)
+				fmt.Fprintf(os.Stderr, "Gitea:   git branch -m %s %s && git branch -u origin/%s %s\n",
+					branchName, renamed.NewName, renamed.NewName, renamed.NewName)
+			}
+		}
+
 		protectBranch, err := private.GetProtectedBranchBy(repoID, branchName)
 		if err != nil {
 			log.GitLogger.Fatal(2, "retrieve protected branches information failed")
@@ -155,6 +187,10 @@ func runHookUpdate(c *cli.Context) error {
 
 	hookSetup("hooks/update.log")
 
+	if err := models.RunGlobalGitHook("update", c.Args(), nil, os.Stderr); err != nil {
+		fail("push declined by the global update hook", "RunGlobalGitHook: %v", err)
+	}
+
 	return nil
 }
 
@@ -178,12 +214,28 @@ func runHookPostReceive(c *cli.Context) error {
 	pusherID, _ := strconv.ParseInt(os.Getenv(models.EnvPusherID), 10, 64)
 	pusherName := os.Getenv(models.EnvPusherName)
 
+	data, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		fail("Internal error", "Fail to read post-receive input: %v", err)
+	}
+	if err = models.RunGlobalGitHook("post-receive", c.Args(), data, os.Stderr); err != nil {
+		log.GitLogger.Error(2, "RunGlobalGitHook: %v", err)
+	}
+
+	pushOptions := models.ParsePushOptions(os.Getenv)
+	pushedBranches := make([]string, 0, 1)
+	updatedRefs := make([]string, 0, 1)
+
 	buf := bytes.NewBuffer(nil)
-	scanner := bufio.NewScanner(os.Stdin)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 	for scanner.Scan() {
 		buf.Write(scanner.Bytes())
 		buf.WriteByte('\n')
 
+		if fields := bytes.Fields(scanner.Bytes()); len(fields) == 3 {
+			updatedRefs = append(updatedRefs, string(fields[2]))
+		}
+
 		// TODO: support news feeds for wiki
 		if isWiki {
 			continue
@@ -198,6 +250,26 @@ func runHookPostReceive(c *cli.Context) error {
 		newCommitID := string(fields[1])
 		refFullName := string(fields[2])
 
+		// Commits pushed for review are moved to a branch of the pusher.
+		if strings.HasPrefix(refFullName, models.AgitRefPrefix) {
+			messages, err := private.HandleAgitPush(models.PushOptionsRequest{
+				RepoUserName: repoUser,
+				RepoName:     repoName,
+				PusherID:     pusherID,
+				RefFullName:  refFullName,
+				NewCommitID:  newCommitID,
+				Options:      pushOptions,
+			})
+			if err != nil {
+				log.GitLogger.Error(2, "HandleAgitPush: %v", err)
+				fmt.Fprintln(os.Stderr, "Gitea: failed to open the pull request")
+			}
+			for _, msg := range messages {
+				fmt.Fprintln(os.Stderr, "Gitea:", msg)
+			}
+			continue
+		}
+
 		if err := private.PushUpdate(models.PushUpdateOptions{
 			RefFullName:  refFullName,
 			OldCommitID:  oldCommitID,
@@ -209,6 +281,43 @@ func runHookPostReceive(c *cli.Context) error {
 		}); err != nil {
 			log.GitLogger.Error(2, "Update: %v", err)
 		}
+
+		if newCommitID != git.EmptySHA && strings.HasPrefix(refFullName, git.BranchPrefix) {
+			pushedBranches = append(pushedBranches, refFullName)
+		}
+	}
+
+	if accessLog := models.AccessLogFromEnv(os.Getenv); accessLog != nil {
+		accessLog.Service = "git-receive-pack"
+		accessLog.Refs = strings.Join(updatedRefs, "\n")
+		if err = private.LogAccess(accessLog); err != nil {
+			log.GitLogger.Error(2, "LogAccess: %v", err)
+		}
+	}
+
+	if os.Getenv(models.EnvRepoIsEmpty) == "true" && len(pushedBranches) > 0 {
+		fmt.Fprintf(os.Stderr, "Gitea: Visit the new repository at %s%s/%s\n", setting.AppURL, repoUser, repoName)
+	}
+
+	if len(pushOptions) == 0 {
+		return nil
+	}
+	for _, refFullName := range pushedBranches {
+		messages, err := private.HandlePushOptions(models.PushOptionsRequest{
+			RepoUserName: repoUser,
+			RepoName:     repoName,
+			PusherID:     pusherID,
+			RefFullName:  refFullName,
+			Options:      pushOptions,
+		})
+		if err != nil {
+			log.GitLogger.Error(2, "HandlePushOptions: %v", err)
+			fmt.Fprintln(os.Stderr, "Gitea: failed to apply push options")
+			continue
+		}
+		for _, msg := range messages {
+			fmt.Fprintln(os.Stderr, "Gitea:", msg)
+		}
 	}
 
 	return nil
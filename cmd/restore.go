@@ -0,0 +1,304 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/urfave/cli"
+)
+
+// CmdRestore represents the available restore sub-command.
+var CmdRestore = cli.Command{
+	Name:  "restore",
+	Usage: "Restore Gitea files and database from a dump",
+	Description: `Restore unpacks a dump created by "gitea dump" into a fresh Gitea instance,
+which has an empty database and no repositories. The database is migrated
+when Gitea is started afterwards.`,
+	Action: runRestore,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "config, c",
+			Value: "custom/conf/app.ini",
+			Usage: "Custom configuration file path",
+		},
+		cli.StringFlag{
+			Name:  "file, f",
+			Usage: `Name of the dump file, "-" reads it from stdin`,
+		},
+		cli.StringFlag{
+			Name:  "type",
+			Usage: `Archive type of the dump, "zip" or "tar.gz" (default detected from the file name)`,
+		},
+		cli.BoolFlag{
+			Name:  "verbose, v",
+			Usage: "Show process details",
+		},
+	},
+}
+
+func runRestore(ctx *cli.Context) error {
+	if ctx.IsSet("config") {
+		setting.CustomConf = ctx.String("config")
+	}
+	fileName := ctx.String("file")
+	if len(fileName) == 0 {
+		return errors.New("a dump file must be given with --file")
+	}
+	typ := ctx.String("type")
+	if len(typ) == 0 {
+		typ = dumpTypeZip
+		if fileName == "-" || strings.HasSuffix(fileName, ".tar.gz") || strings.HasSuffix(fileName, ".tgz") {
+			typ = dumpTypeTarGz
+		}
+	}
+
+	setting.NewContext()
+	setting.NewServices()
+	models.LoadConfigs()
+	if err := models.SetEngine(); err != nil {
+		return fmt.Errorf("models.SetEngine: %v", err)
+	}
+	if err := checkFreshInstance(); err != nil {
+		return err
+	}
+
+	var r archiveReader
+	switch {
+	case typ == dumpTypeTarGz && fileName == "-":
+		tr, err := newTarGzArchiveReader(os.Stdin)
+		if err != nil {
+			return err
+		}
+		r = tr
+	case typ == dumpTypeTarGz:
+		f, err := os.Open(fileName)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		tr, err := newTarGzArchiveReader(f)
+		if err != nil {
+			return err
+		}
+		r = tr
+	case typ == dumpTypeZip && fileName == "-":
+		return errors.New("zip dumps cannot be read from stdin, use a tar.gz dump instead")
+	case typ == dumpTypeZip:
+		zr, err := zip.OpenReader(fileName)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		r = &zipArchiveReader{files: zr.File}
+	default:
+		return fmt.Errorf("unknown dump type: %s", typ)
+	}
+
+	if err := restore(r, ctx.Bool("verbose")); err != nil {
+		return err
+	}
+	log.Printf("Finish restoring from %s, start Gitea to migrate the database, then resynchronize the repository hooks in the admin panel", fileName)
+	return nil
+}
+
+// checkFreshInstance returns an error if the instance already has data,
+// which would be mixed with the restored one.
+func checkFreshInstance() error {
+	empty, err := models.IsDatabaseEmpty()
+	if err != nil {
+		return fmt.Errorf("IsDatabaseEmpty: %v", err)
+	} else if !empty {
+		return errors.New("the database is not empty, restore needs a fresh instance")
+	}
+
+	dirs, err := ioutil.ReadDir(setting.RepoRootPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	} else if len(dirs) > 0 {
+		return fmt.Errorf("the repository root path %s is not empty, restore needs a fresh instance", setting.RepoRootPath)
+	}
+	return nil
+}
+
+// restore unpacks the entries of a dump into the directories of the
+// instance, and imports its database.
+func restore(r archiveReader, verbose bool) error {
+	name, _, rc, err := r.Next()
+	if err == io.EOF || (err == nil && name != dumpMetaName) {
+		return fmt.Errorf("not a Gitea dump: %s is missing", dumpMetaName)
+	} else if err != nil {
+		return err
+	}
+	var meta dumpMeta
+	err = json.NewDecoder(rc).Decode(&meta)
+	rc.Close()
+	if err != nil {
+		return fmt.Errorf("Failed to read %s: %v", dumpMetaName, err)
+	}
+	if err = checkDumpMeta(meta); err != nil {
+		return err
+	}
+	log.Printf("Restoring dump of Gitea %s, database version %d", meta.Version, meta.DBVersion)
+
+	dirs := map[string]string{
+		"repos":       setting.RepoRootPath,
+		"custom":      setting.CustomPath,
+		"data":        setting.AppDataPath,
+		"lfs":         setting.LFS.ContentPath,
+		"attachments": setting.AttachmentPath,
+		"log":         setting.LogRootPath,
+	}
+	// The configuration and SQLite database of this instance are kept, the
+	// restored configuration may have other paths or database settings.
+	keep := make(map[string]bool, 2)
+	if customConf, err := filepath.Abs(setting.CustomConf); err == nil {
+		keep[customConf] = true
+	}
+	if models.DbCfg.Type == "sqlite3" {
+		if dbPath, err := filepath.Abs(models.DbCfg.Path); err == nil {
+			keep[dbPath] = true
+		}
+	}
+	for {
+		name, info, rc, err := r.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		err = restoreEntry(dirs, keep, name, info, rc, meta, verbose)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("Failed to restore %s: %v", name, err)
+		}
+	}
+}
+
+// checkDumpMeta returns an error if the dump cannot be restored into this
+// instance.
+func checkDumpMeta(meta dumpMeta) error {
+	_, expected, err := models.CheckDBVersion()
+	if err != nil {
+		return err
+	}
+	if meta.DBVersion > expected {
+		return fmt.Errorf("the dump is from Gitea %s with database version %d, which is newer than this Gitea (%s, database version %d)",
+			meta.Version, meta.DBVersion, setting.AppVer, expected)
+	}
+	if meta.DatabaseType != models.DbCfg.Type {
+		return fmt.Errorf("the database dump has %s syntax, but the database is %s, create the dump with --database %s",
+			meta.DatabaseType, models.DbCfg.Type, models.DbCfg.Type)
+	}
+	return nil
+}
+
+// restoreEntry restores a single entry of a dump.
+func restoreEntry(dirs map[string]string, keep map[string]bool, name string, info os.FileInfo, r io.Reader, meta dumpMeta, verbose bool) error {
+	if verbose {
+		log.Printf("Restoring %s", name)
+	}
+	if name == "gitea-db.sql" {
+		log.Printf("Restoring database...")
+		return models.RestoreDatabase(r, meta.DBVersion)
+	}
+
+	// Cleaning the name first keeps entries like "repos/../../etc" out of
+	// the directories of the instance.
+	parts := strings.SplitN(path.Clean(name), "/", 2)
+	dir, has := dirs[parts[0]]
+	if !has {
+		log.Printf("Unknown entry %s, skipped", name)
+		return nil
+	}
+	target := dir
+	if len(parts) == 2 {
+		target = filepath.Join(dir, filepath.FromSlash(parts[1]))
+	}
+
+	switch {
+	case info.IsDir():
+		return os.MkdirAll(target, info.Mode().Perm()|0700)
+	case !info.Mode().IsRegular():
+		return nil
+	}
+	if abs, _ := filepath.Abs(target); keep[abs] {
+		log.Printf("Keeping %s", abs)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// archiveReader reads the entries of a dump in the order they were written.
+type archiveReader interface {
+	// Next returns the next entry, or io.EOF if there are no more entries.
+	Next() (string, os.FileInfo, io.ReadCloser, error)
+}
+
+type zipArchiveReader struct {
+	files []*zip.File
+}
+
+func (r *zipArchiveReader) Next() (string, os.FileInfo, io.ReadCloser, error) {
+	if len(r.files) == 0 {
+		return "", nil, nil, io.EOF
+	}
+	f := r.files[0]
+	r.files = r.files[1:]
+
+	rc, err := f.Open()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return strings.TrimSuffix(f.Name, "/"), f.FileInfo(), rc, nil
+}
+
+type tarGzArchiveReader struct {
+	tr *tar.Reader
+}
+
+func newTarGzArchiveReader(r io.Reader) (*tarGzArchiveReader, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &tarGzArchiveReader{tr: tar.NewReader(gr)}, nil
+}
+
+func (r *tarGzArchiveReader) Next() (string, os.FileInfo, io.ReadCloser, error) {
+	header, err := r.tr.Next()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return strings.TrimSuffix(header.Name, "/"), header.FileInfo(), ioutil.NopCloser(r.tr), nil
+}
@@ -29,7 +29,10 @@ const (
 	lfsAuthenticateVerb = "git-lfs-authenticate"
 )
 
-// CmdServ represents the available serv sub-command.
+// CmdServ represents the available serv sub-command. Unlike web, it does not
+// take the dry-run-migrations and backup-before-migrate flags: it never opens
+// the database but goes through the private API of the web process, so the
+// database is only ever migrated by web.
 var CmdServ = cli.Command{
 	Name:        "serv",
 	Usage:       "This command should only be called by SSH shell",
@@ -44,20 +47,9 @@ var CmdServ = cli.Command{
 	},
 }
 
-func setup(logPath string) error {
+func setup(logPath string) {
 	setting.NewContext()
 	log.NewGitLogger(filepath.Join(setting.LogRootPath, logPath))
-	models.LoadConfigs()
-
-	if setting.UseSQLite3 || setting.UseTiDB {
-		workDir, _ := setting.WorkDir()
-		if err := os.Chdir(workDir); err != nil {
-			log.GitLogger.Fatal(4, "Failed to change directory %s: %v", workDir, err)
-		}
-	}
-
-	setting.NewXORMLogService(true)
-	return models.SetEngine()
 }
 
 func parseCmd(cmd string) (string, string) {
@@ -97,9 +89,7 @@ func runServ(c *cli.Context) error {
 		setting.CustomConf = c.String("config")
 	}
 
-	if err := setup("serv.log"); err != nil {
-		fail("System init failed", fmt.Sprintf("setup: %v", err))
-	}
+	setup("serv.log")
 
 	if setting.SSH.Disabled {
 		println("Gitea: SSH has been disabled")
@@ -142,38 +132,6 @@ func runServ(c *cli.Context) error {
 	username := strings.ToLower(rr[0])
 	reponame := strings.ToLower(strings.TrimSuffix(rr[1], ".git"))
 
-	isWiki := false
-	unitType := models.UnitTypeCode
-	if strings.HasSuffix(reponame, ".wiki") {
-		isWiki = true
-		unitType = models.UnitTypeWiki
-		reponame = reponame[:len(reponame)-5]
-	}
-
-	os.Setenv(models.EnvRepoUsername, username)
-	if isWiki {
-		os.Setenv(models.EnvRepoIsWiki, "true")
-	} else {
-		os.Setenv(models.EnvRepoIsWiki, "false")
-	}
-	os.Setenv(models.EnvRepoName, reponame)
-
-	repoUser, err := models.GetUserByName(username)
-	if err != nil {
-		if models.IsErrUserNotExist(err) {
-			fail("Repository owner does not exist", "Unregistered owner: %s", username)
-		}
-		fail("Internal error", "Failed to get repository owner (%s): %v", username, err)
-	}
-
-	repo, err := models.GetRepositoryByName(repoUser.ID, reponame)
-	if err != nil {
-		if models.IsErrRepoNotExist(err) {
-			fail(accessDenied, "Repository does not exist: %s/%s", repoUser.Name, reponame)
-		}
-		fail("Internal error", "Failed to get repository: %v", err)
-	}
-
 	requestedMode, has := allowedCommands[verb]
 	if !has {
 		fail("Unknown git command", "Unknown git command %s", verb)
@@ -189,85 +147,53 @@ func runServ(c *cli.Context) error {
 		}
 	}
 
-	// Prohibit push to mirror repositories.
-	if requestedMode > models.AccessModeRead && repo.IsMirror {
-		fail("mirror repository is read-only", "")
+	// The key is only required for a push or a private repository,
+	// so its format is checked by the web process.
+	var keyID int64
+	if keys := strings.Split(c.Args()[0], "-"); len(keys) == 2 {
+		keyID = com.StrTo(keys[1]).MustInt64()
 	}
 
-	// Allow anonymous clone for public repositories.
-	var (
-		keyID int64
-		user  *models.User
-	)
-	if requestedMode == models.AccessModeWrite || repo.IsPrivate {
-		keys := strings.Split(c.Args()[0], "-")
-		if len(keys) != 2 {
-			fail("Key ID format error", "Invalid key argument: %s", c.Args()[0])
+	results, err := private.ServCommand(keyID, username, reponame, requestedMode, verb)
+	if err != nil {
+		if private.IsErrServCommand(err) {
+			fail(err.Error(), "")
 		}
+		fail("Internal error", "ServCommand: %v", err)
+	}
 
-		key, err := models.GetPublicKeyByID(com.StrTo(keys[1]).MustInt64())
-		if err != nil {
-			fail("Invalid key ID", "Invalid key ID[%s]: %v", c.Args()[0], err)
-		}
-		keyID = key.ID
-
-		// Check deploy key or user key.
-		if key.Type == models.KeyTypeDeploy {
-			if key.Mode < requestedMode {
-				fail("Key permission denied", "Cannot push with deployment key: %d", key.ID)
-			}
-			// Check if this deploy key belongs to current repository.
-			if !models.HasDeployKey(key.ID, repo.ID) {
-				fail("Key access denied", "Deploy key access denied: [key_id: %d, repo_id: %d]", key.ID, repo.ID)
-			}
-
-			// Update deploy key activity.
-			deployKey, err := models.GetDeployKeyByRepo(key.ID, repo.ID)
-			if err != nil {
-				fail("Internal error", "GetDeployKey: %v", err)
-			}
-
-			deployKey.Updated = time.Now()
-			if err = models.UpdateDeployKey(deployKey); err != nil {
-				fail("Internal error", "UpdateDeployKey: %v", err)
-			}
-		} else {
-			user, err = models.GetUserByKeyID(key.ID)
-			if err != nil {
-				fail("internal error", "Failed to get user by key ID(%d): %v", keyID, err)
-			}
-
-			mode, err := models.AccessLevel(user.ID, repo)
-			if err != nil {
-				fail("Internal error", "Failed to check access: %v", err)
-			} else if mode < requestedMode {
-				clientMessage := accessDenied
-				if mode >= models.AccessModeRead {
-					clientMessage = "You do not have sufficient authorization for this action"
-				}
-				fail(clientMessage,
-					"User %s does not have level %v access to repository %s",
-					user.Name, requestedMode, repoPath)
-			}
-
-			if !repo.CheckUnitUser(user.ID, user.IsAdmin, unitType) {
-				fail("You do not have allowed for this action",
-					"User %s does not have allowed access to repository %s 's code",
-					user.Name, repoPath)
-			}
-
-			os.Setenv(models.EnvPusherName, user.Name)
-			os.Setenv(models.EnvPusherID, fmt.Sprintf("%d", user.ID))
+	os.Setenv(models.EnvRepoUsername, strings.ToLower(results.OwnerName))
+	if results.IsWiki {
+		os.Setenv(models.EnvRepoIsWiki, "true")
+	} else {
+		os.Setenv(models.EnvRepoIsWiki, "false")
+	}
+	os.Setenv(models.EnvRepoName, strings.ToLower(results.RepoName))
+	if results.ForReviewOnly {
+		os.Setenv(models.EnvPushForReviewOnly, "true")
+	}
+	if results.UserID > 0 {
+		os.Setenv(models.EnvPusherName, results.UserName)
+		os.Setenv(models.EnvPusherID, fmt.Sprintf("%d", results.UserID))
+	}
+	if results.IsEmpty {
+		os.Setenv(models.EnvRepoIsEmpty, "true")
+	}
+	if results.IsCreated {
+		visibility := "public"
+		if results.IsPrivate {
+			visibility = "private"
 		}
+		fmt.Fprintf(os.Stderr, "Gitea: Created %s repository %s/%s\n", visibility, results.OwnerName, results.RepoName)
 	}
 
 	//LFS token authentication
 	if verb == lfsAuthenticateVerb {
-		url := fmt.Sprintf("%s%s/%s.git/info/lfs", setting.AppURL, repoUser.Name, repo.Name)
+		url := fmt.Sprintf("%s%s/%s.git/info/lfs", setting.AppURL, results.OwnerName, results.RepoName)
 
 		now := time.Now()
 		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-			"repo": repo.ID,
+			"repo": results.RepoID,
 			"op":   lfsVerb,
 			"exp":  now.Add(5 * time.Minute).Unix(),
 			"nbf":  now.Unix(),
@@ -299,6 +225,8 @@ func runServ(c *cli.Context) error {
 		verb = strings.Replace(verb, "-", " ", 1)
 	}
 
+	isUploadPack := verb == "git-upload-pack" || verb == "git upload-pack"
+
 	var gitcmd *exec.Cmd
 	verbs := strings.Split(verb, " ")
 	if len(verbs) == 2 {
@@ -307,25 +235,63 @@ func runServ(c *cli.Context) error {
 		gitcmd = exec.Command(verb, repoPath)
 	}
 
-	if isWiki {
-		if err = repo.InitWiki(); err != nil {
-			fail("Internal error", "Failed to init wiki repo: %v", err)
-		}
+	// SSH_CONNECTION is "<client ip> <client port> <server ip> <server port>".
+	var remoteAddr string
+	if fields := strings.Fields(os.Getenv("SSH_CONNECTION")); len(fields) > 0 {
+		remoteAddr = fields[0]
 	}
 
-	os.Setenv(models.ProtectedBranchRepoID, fmt.Sprintf("%d", repo.ID))
+	os.Setenv(models.ProtectedBranchRepoID, fmt.Sprintf("%d", results.RepoID))
+	if requestedMode == models.AccessModeWrite {
+		// Accept `git push -o` options, which are handled by the hooks.
+		os.Setenv(models.EnvGitConfigParameters, models.PushOptionsConfigParameter)
+		// The push is logged by the hooks, which know the updated references.
+		if results.IsPrivate {
+			os.Setenv(models.EnvAccessLog, models.EncodeAccessLog(&models.RepoAccessLog{
+				RepoID:     results.RepoID,
+				UserID:     results.UserID,
+				KeyID:      results.KeyID,
+				Protocol:   models.AccessProtocolSSH,
+				RemoteAddr: remoteAddr,
+			}))
+		}
+	} else if isUploadPack {
+		os.Setenv(models.EnvGitConfigParameters, models.UploadPackConfigParameters())
+	}
 
 	gitcmd.Dir = setting.RepoRootPath
 	gitcmd.Stdout = os.Stdout
 	gitcmd.Stdin = os.Stdin
 	gitcmd.Stderr = os.Stderr
+	var shallowGuard *models.ShallowGuard
+	if isUploadPack && !setting.Git.AllowShallowClone {
+		shallowGuard = models.NewShallowGuard(os.Stdin)
+		gitcmd.Stdin = shallowGuard
+	}
 	if err = gitcmd.Run(); err != nil {
+		if shallowGuard != nil && shallowGuard.Err != nil {
+			fail(shallowGuard.Err.Error(), "Shallow clone of %s refused", repoPath)
+		}
 		fail("Internal error", "Failed to execute git command: %v", err)
 	}
 
+	if requestedMode == models.AccessModeRead && results.KeyID > 0 {
+		accessLog := &models.RepoAccessLog{
+			RepoID:     results.RepoID,
+			UserID:     results.UserID,
+			KeyID:      results.KeyID,
+			Protocol:   models.AccessProtocolSSH,
+			Service:    verb,
+			RemoteAddr: remoteAddr,
+		}
+		if err = private.LogAccess(accessLog); err != nil {
+			log.GitLogger.Error(3, "LogAccess: %v", err)
+		}
+	}
+
 	// Update user key activity.
-	if keyID > 0 {
-		if err = private.UpdatePublicKeyUpdated(keyID); err != nil {
+	if results.KeyID > 0 {
+		if err = private.UpdatePublicKeyUpdated(results.KeyID); err != nil {
 			fail("Internal error", "UpdatePublicKey: %v", err)
 		}
 	}
@@ -11,8 +11,11 @@ import (
 	"net/http/fcgi"
 	_ "net/http/pprof" // Used for debugging if enabled and a web server is running
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/routers"
@@ -45,9 +48,44 @@ and it takes care of all the other things for you`,
 			Value: "/var/run/gitea.pid",
 			Usage: "Custom pid file path",
 		},
+		cli.BoolFlag{
+			Name:  "dry-run-migrations",
+			Usage: "Print the pending database migrations and exit without applying them",
+		},
+		cli.BoolFlag{
+			Name:  "backup-before-migrate",
+			Usage: "Dump files and database before pending database migrations are applied",
+		},
 	},
 }
 
+// reloadOnSIGHUP reloads the settings which can be changed without restart
+// whenever the process receives SIGHUP.
+func reloadOnSIGHUP() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+	go func() {
+		for range c {
+			log.Info("Reloading configuration on SIGHUP")
+			if _, err := setting.Reload(); err != nil {
+				log.Error(4, "Failed to reload configuration: %v", err)
+			}
+		}
+	}()
+}
+
+// dryRunMigrations prints the pending database migrations without
+// applying them.
+func dryRunMigrations() error {
+	setting.NewContext()
+	setting.NewServices()
+	models.LoadConfigs()
+	if err := models.SetEngine(); err != nil {
+		return fmt.Errorf("models.SetEngine: %v", err)
+	}
+	return models.DryRunMigrations(os.Stdout)
+}
+
 func runWeb(ctx *cli.Context) error {
 	if ctx.IsSet("config") {
 		setting.CustomConf = ctx.String("config")
@@ -57,10 +95,25 @@ func runWeb(ctx *cli.Context) error {
 		setting.CustomPID = ctx.String("pid")
 	}
 
+	if ctx.Bool("dry-run-migrations") {
+		return dryRunMigrations()
+	}
+	if ctx.Bool("backup-before-migrate") {
+		models.MigrateOptions.BeforeMigrate = func() error {
+			_, err := dump(dumpOptions{
+				TmpDir:        os.TempDir(),
+				CurrentSchema: true,
+			})
+			return err
+		}
+	}
+
 	routers.GlobalInit()
+	reloadOnSIGHUP()
 
 	m := routes.NewMacaron()
 	routes.RegisterRoutes(m)
+	handler := routes.WithGitRoutes(m)
 
 	// Flag for port number in case first time run conflict.
 	if ctx.IsSet("port") {
@@ -89,16 +142,16 @@ func runWeb(ctx *cli.Context) error {
 	var err error
 	switch setting.Protocol {
 	case setting.HTTP:
-		err = runHTTP(listenAddr, context2.ClearHandler(m))
+		err = runHTTP(listenAddr, context2.ClearHandler(handler))
 	case setting.HTTPS:
-		err = runHTTPS(listenAddr, setting.CertFile, setting.KeyFile, context2.ClearHandler(m))
+		err = runHTTPS(listenAddr, setting.CertFile, setting.KeyFile, context2.ClearHandler(handler))
 	case setting.FCGI:
 		listener, err := net.Listen("tcp", listenAddr)
 		if err != nil {
 			log.Fatal(4, "Failed to bind %s", listenAddr, err)
 		}
 		defer listener.Close()
-		err = fcgi.Serve(listener, context2.ClearHandler(m))
+		err = fcgi.Serve(listener, context2.ClearHandler(handler))
 	case setting.UnixSocket:
 		if err := os.Remove(listenAddr); err != nil && !os.IsNotExist(err) {
 			log.Fatal(4, "Failed to remove unix socket directory %s: %v", listenAddr, err)
@@ -114,7 +167,7 @@ func runWeb(ctx *cli.Context) error {
 		if err = os.Chmod(listenAddr, os.FileMode(setting.UnixSocketPermission)); err != nil {
 			log.Fatal(4, "Failed to set permission of unix socket: %v", err)
 		}
-		err = http.Serve(listener, context2.ClearHandler(m))
+		err = http.Serve(listener, context2.ClearHandler(handler))
 	default:
 		log.Fatal(4, "Invalid protocol: %s", setting.Protocol)
 	}
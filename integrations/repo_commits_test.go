@@ -52,6 +52,9 @@ func doTestRepoCommitWithStatus(t *testing.T, state string, classes ...string) {
 		bytes.NewBufferString("{\"state\":\""+state+"\", \"target_url\": \"http://test.ci/\", \"description\": \"\", \"context\": \"testci\"}"))
 
 	req.Header.Add("Content-Type", "application/json")
+	// API requests authenticated by the session must send the CSRF token.
+	csrf, _ := doc.doc.Find("meta[name=_csrf]").Attr("content")
+	req.Header.Add("X-Csrf-Token", csrf)
 	resp = session.MakeRequest(t, req)
 	assert.EqualValues(t, http.StatusCreated, resp.HeaderCode)
 
@@ -37,8 +37,10 @@ func main() {
 		cmd.CmdServ,
 		cmd.CmdHook,
 		cmd.CmdDump,
+		cmd.CmdRestore,
 		cmd.CmdCert,
 		cmd.CmdAdmin,
+		cmd.CmdDoctor,
 	}
 	app.Flags = append(app.Flags, []cli.Flag{}...)
 	err := app.Run(os.Args)
@@ -0,0 +1,355 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-xorm/xorm"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// AbuseReportType represents the kind of content an abuse report is about.
+type AbuseReportType int
+
+// Enumerate all the kinds of content which can be reported
+const (
+	AbuseReportIssue AbuseReportType = iota + 1
+	AbuseReportComment
+	AbuseReportRepository
+	AbuseReportUser
+)
+
+var abuseReportTypeNames = map[AbuseReportType]string{
+	AbuseReportIssue:      "issue",
+	AbuseReportComment:    "comment",
+	AbuseReportRepository: "repo",
+	AbuseReportUser:       "user",
+}
+
+// Name returns the name of the kind of content used in URLs.
+func (t AbuseReportType) Name() string {
+	return abuseReportTypeNames[t]
+}
+
+// ToAbuseReportType returns the kind of content with given name,
+// or 0 if there is none.
+func ToAbuseReportType(name string) AbuseReportType {
+	for t, n := range abuseReportTypeNames {
+		if n == name {
+			return t
+		}
+	}
+	return 0
+}
+
+// AbuseReportStatus represents the state of an abuse report.
+type AbuseReportStatus int
+
+// Enumerate all the states of an abuse report
+const (
+	AbuseReportOpen      AbuseReportStatus = iota + 1 // Waiting for a moderator
+	AbuseReportResolved                               // A moderator acted on the report
+	AbuseReportDismissed                              // A moderator found nothing to act on
+)
+
+// AbuseReport represents a report of a user about an issue, a comment,
+// a repository or a profile which breaks the rules of the instance.
+type AbuseReport struct {
+	ID         int64             `xorm:"pk autoincr"`
+	ReporterID int64             `xorm:"INDEX NOT NULL"`
+	Reporter   *User             `xorm:"-"`
+	Type       AbuseReportType   `xorm:"INDEX(target) NOT NULL"`
+	TargetID   int64             `xorm:"INDEX(target) NOT NULL"`
+	Reason     string            `xorm:"TEXT"`
+	Status     AbuseReportStatus `xorm:"INDEX NOT NULL"`
+	ResolverID int64
+	Resolver   *User `xorm:"-"`
+
+	// TargetTitle, TargetLink and TargetContent describe the reported content,
+	// TargetTitle is empty if the content does not exist anymore.
+	TargetTitle   string `xorm:"-"`
+	TargetLink    string `xorm:"-"`
+	TargetContent string `xorm:"-"`
+
+	Created      time.Time `xorm:"-"`
+	CreatedUnix  int64     `xorm:"INDEX created"`
+	Resolved     time.Time `xorm:"-"`
+	ResolvedUnix int64
+}
+
+// AfterSet is invoked from XORM after setting the value of a field of this object.
+func (r *AbuseReport) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		r.Created = time.Unix(r.CreatedUnix, 0).Local()
+	case "resolved_unix":
+		r.Resolved = time.Unix(r.ResolvedUnix, 0).Local()
+	}
+}
+
+// IsOpen returns true if the report is waiting for a moderator.
+func (r *AbuseReport) IsOpen() bool {
+	return r.Status == AbuseReportOpen
+}
+
+// IsResolved returns true if a moderator acted on the report.
+func (r *AbuseReport) IsResolved() bool {
+	return r.Status == AbuseReportResolved
+}
+
+// excerpt shortens content shown to the moderators.
+func excerpt(content string) string {
+	return base.EllipsisString(strings.TrimSpace(content), 300)
+}
+
+// canReadRepo returns true if doer can see the repository, doer is nil if
+// the content is loaded for the moderators.
+func canReadRepo(e Engine, doer *User, repo *Repository) (bool, error) {
+	if doer == nil || doer.IsAdmin {
+		return true, nil
+	}
+	return hasAccess(e, doer.ID, repo, AccessModeRead)
+}
+
+// loadIssueTarget loads the reported issue, or the issue of the reported
+// comment, if doer can see it.
+func (r *AbuseReport) loadIssueTarget(e Engine, doer *User, issueID int64) (*Issue, error) {
+	issue, err := getIssueByID(e, issueID)
+	if err != nil {
+		if IsErrIssueNotExist(err) {
+			return nil, ErrAbuseReportTargetNotExist{r.Type, r.TargetID}
+		}
+		return nil, err
+	} else if err = issue.loadRepo(e); err != nil {
+		return nil, err
+	}
+
+	canRead, err := canReadRepo(e, doer, issue.Repo)
+	if err != nil {
+		return nil, err
+	} else if canRead && doer != nil {
+		canRead, err = issue.isVisibleTo(e, doer)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !canRead {
+		return nil, ErrAbuseReportTargetNotExist{r.Type, r.TargetID}
+	}
+	return issue, nil
+}
+
+// loadTarget describes the reported content. It returns
+// ErrAbuseReportTargetNotExist if the content does not exist or doer
+// cannot see it, doer is nil if the report is loaded for the moderators.
+func (r *AbuseReport) loadTarget(e Engine, doer *User) error {
+	switch r.Type {
+	case AbuseReportIssue:
+		issue, err := r.loadIssueTarget(e, doer, r.TargetID)
+		if err != nil {
+			return err
+		}
+		r.TargetTitle = fmt.Sprintf("%s#%d %s", issue.Repo.FullName(), issue.Index, issue.Title)
+		r.TargetLink = issue.HTMLURL()
+		r.TargetContent = excerpt(issue.Content)
+
+	case AbuseReportComment:
+		comment := new(Comment)
+		if has, err := e.Id(r.TargetID).Get(comment); err != nil {
+			return err
+		} else if !has || comment.Type != CommentTypeComment {
+			return ErrAbuseReportTargetNotExist{r.Type, r.TargetID}
+		}
+		issue, err := r.loadIssueTarget(e, doer, comment.IssueID)
+		if err != nil {
+			return err
+		}
+		r.TargetTitle = fmt.Sprintf("%s#%d %s", issue.Repo.FullName(), issue.Index, issue.Title)
+		r.TargetLink = issue.HTMLURL() + "#" + comment.HashTag()
+		r.TargetContent = excerpt(comment.Content)
+
+	case AbuseReportRepository:
+		repo, err := getRepositoryByID(e, r.TargetID)
+		if err != nil {
+			if IsErrRepoNotExist(err) {
+				return ErrAbuseReportTargetNotExist{r.Type, r.TargetID}
+			}
+			return err
+		} else if err = repo.getOwner(e); err != nil {
+			return err
+		}
+		if canRead, err := canReadRepo(e, doer, repo); err != nil {
+			return err
+		} else if !canRead {
+			return ErrAbuseReportTargetNotExist{r.Type, r.TargetID}
+		}
+		r.TargetTitle = repo.FullName()
+		r.TargetLink = repo.HTMLURL()
+		r.TargetContent = excerpt(repo.Description)
+
+	case AbuseReportUser:
+		u, err := getUserByID(e, r.TargetID)
+		if err != nil {
+			if IsErrUserNotExist(err) {
+				return ErrAbuseReportTargetNotExist{r.Type, r.TargetID}
+			}
+			return err
+		}
+		r.TargetTitle = u.Name
+		r.TargetLink = u.HTMLURL()
+		r.TargetContent = excerpt(u.FullName)
+
+	default:
+		return ErrAbuseReportTargetNotExist{r.Type, r.TargetID}
+	}
+	return nil
+}
+
+func (r *AbuseReport) loadAttributes(e Engine) (err error) {
+	if r.Reporter == nil {
+		if r.Reporter, err = getUserByID(e, r.ReporterID); err != nil {
+			if !IsErrUserNotExist(err) {
+				return fmt.Errorf("getUserByID [%d]: %v", r.ReporterID, err)
+			}
+			r.Reporter = NewGhostUser()
+		}
+	}
+	if r.ResolverID > 0 && r.Resolver == nil {
+		if r.Resolver, err = getUserByID(e, r.ResolverID); err != nil {
+			if !IsErrUserNotExist(err) {
+				return fmt.Errorf("getUserByID [%d]: %v", r.ResolverID, err)
+			}
+			r.Resolver = NewGhostUser()
+		}
+	}
+	if err = r.loadTarget(e, nil); err != nil && !IsErrAbuseReportTargetNotExist(err) {
+		return fmt.Errorf("loadTarget: %v", err)
+	}
+	return nil
+}
+
+// DescribeAbuseReportTarget returns a report of doer about given content
+// which is not saved yet, to show the content being reported.
+func DescribeAbuseReportTarget(doer *User, typ AbuseReportType, targetID int64) (*AbuseReport, error) {
+	r := &AbuseReport{
+		ReporterID: doer.ID,
+		Reporter:   doer,
+		Type:       typ,
+		TargetID:   targetID,
+	}
+	return r, r.loadTarget(x, doer)
+}
+
+// NewAbuseReport files a report of doer about given content for the
+// moderators. A user can only have one open report about the same content,
+// and only file a limited number of reports per day.
+func NewAbuseReport(doer *User, typ AbuseReportType, targetID int64, reason string) (*AbuseReport, error) {
+	r, err := DescribeAbuseReportTarget(doer, typ, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	has, err := x.
+		Where("reporter_id = ? AND type = ? AND target_id = ? AND status = ?", doer.ID, typ, targetID, AbuseReportOpen).
+		Get(new(AbuseReport))
+	if err != nil {
+		return nil, err
+	} else if has {
+		return nil, ErrAbuseReportAlreadyExist{doer.ID, typ, targetID}
+	}
+
+	if setting.Service.MaxAbuseReportsPerDay > 0 {
+		count, err := x.
+			Where("reporter_id = ? AND created_unix > ?", doer.ID, time.Now().Add(-24*time.Hour).Unix()).
+			Count(new(AbuseReport))
+		if err != nil {
+			return nil, err
+		} else if count >= int64(setting.Service.MaxAbuseReportsPerDay) {
+			return nil, ErrAbuseReportLimitExceeded{doer.ID}
+		}
+	}
+
+	r.Reason = reason
+	r.Status = AbuseReportOpen
+	if _, err = x.Insert(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetAbuseReports returns the open reports, or the closed ones, newest first.
+func GetAbuseReports(isOpen bool) ([]*AbuseReport, error) {
+	sess := x.Desc("created_unix")
+	if isOpen {
+		sess.Where("status = ?", AbuseReportOpen)
+	} else {
+		sess.Where("status != ?", AbuseReportOpen).Limit(50)
+	}
+
+	reports := make([]*AbuseReport, 0, 10)
+	if err := sess.Find(&reports); err != nil {
+		return nil, err
+	}
+	for _, r := range reports {
+		if err := r.loadAttributes(x); err != nil {
+			return nil, err
+		}
+	}
+	return reports, nil
+}
+
+// CountOpenAbuseReports returns the number of reports waiting for a moderator.
+func CountOpenAbuseReports() (int64, error) {
+	return x.Where("status = ?", AbuseReportOpen).Count(new(AbuseReport))
+}
+
+// GetAbuseReportByID returns the abuse report with given ID.
+func GetAbuseReportByID(id int64) (*AbuseReport, error) {
+	r := new(AbuseReport)
+	has, err := x.Id(id).Get(r)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrAbuseReportNotExist{id}
+	}
+	return r, r.loadAttributes(x)
+}
+
+// close closes an open report and lets the reporter know.
+func (r *AbuseReport) close(doer *User, status AbuseReportStatus) error {
+	if !r.IsOpen() {
+		return nil
+	}
+
+	r.Status = status
+	r.ResolverID = doer.ID
+	r.Resolver = doer
+	r.ResolvedUnix = time.Now().Unix()
+	if _, err := x.Id(r.ID).Cols("status", "resolver_id", "resolved_unix").Update(r); err != nil {
+		return err
+	}
+
+	if r.Reporter.ID > 0 {
+		SendAbuseReportClosedMail(r)
+	}
+	log.Trace("Abuse report %d closed by %s with status %d", r.ID, doer.Name, status)
+	return nil
+}
+
+// Resolve closes the report after a moderator acted on it.
+func (r *AbuseReport) Resolve(doer *User) error {
+	return r.close(doer, AbuseReportResolved)
+}
+
+// Dismiss closes the report without any action.
+func (r *AbuseReport) Dismiss(doer *User) error {
+	return r.close(doer, AbuseReportDismissed)
+}
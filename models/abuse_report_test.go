@@ -0,0 +1,69 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+func TestNewAbuseReport(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+
+	r, err := NewAbuseReport(doer, AbuseReportComment, 2, "spam")
+	assert.NoError(t, err)
+	assert.Equal(t, "user2/repo1#1 issue1", r.TargetTitle)
+	AssertExistsAndLoadBean(t, &AbuseReport{ID: r.ID, ReporterID: 4, Status: AbuseReportOpen})
+
+	_, err = NewAbuseReport(doer, AbuseReportComment, 2, "spam again")
+	assert.True(t, IsErrAbuseReportAlreadyExist(err))
+
+	// non-existent content and content the reporter cannot see
+	_, err = NewAbuseReport(doer, AbuseReportIssue, 999, "spam")
+	assert.True(t, IsErrAbuseReportTargetNotExist(err))
+	_, err = NewAbuseReport(doer, AbuseReportRepository, 2, "spam")
+	assert.True(t, IsErrAbuseReportTargetNotExist(err))
+	_, err = NewAbuseReport(doer, AbuseReportComment, 1, "spam")
+	assert.True(t, IsErrAbuseReportTargetNotExist(err))
+
+	defer func(limit int) { setting.Service.MaxAbuseReportsPerDay = limit }(setting.Service.MaxAbuseReportsPerDay)
+	setting.Service.MaxAbuseReportsPerDay = 2
+	_, err = NewAbuseReport(doer, AbuseReportUser, 2, "spam")
+	assert.NoError(t, err)
+	_, err = NewAbuseReport(doer, AbuseReportRepository, 1, "spam")
+	assert.True(t, IsErrAbuseReportLimitExceeded(err))
+}
+
+func TestAbuseReport_Resolve(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	admin := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+
+	r, err := NewAbuseReport(doer, AbuseReportIssue, 1, "spam")
+	assert.NoError(t, err)
+	reports, err := GetAbuseReports(true)
+	assert.NoError(t, err)
+	assert.Len(t, reports, 1)
+
+	r, err = GetAbuseReportByID(r.ID)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Resolve(admin))
+	AssertExistsAndLoadBean(t, &AbuseReport{ID: r.ID, Status: AbuseReportResolved, ResolverID: admin.ID})
+
+	reports, err = GetAbuseReports(true)
+	assert.NoError(t, err)
+	assert.Len(t, reports, 0)
+	reports, err = GetAbuseReports(false)
+	assert.NoError(t, err)
+	assert.Len(t, reports, 1)
+
+	// the reporter can report the content again once the report is closed
+	_, err = NewAbuseReport(doer, AbuseReportIssue, 1, "more spam")
+	assert.NoError(t, err)
+}
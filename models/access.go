@@ -14,18 +14,22 @@ const (
 	AccessModeNone AccessMode = iota // 0
 	// AccessModeRead read access
 	AccessModeRead // 1
+	// AccessModeTriage read access, and managing issues without write access
+	AccessModeTriage // 2
 	// AccessModeWrite write access
-	AccessModeWrite // 2
+	AccessModeWrite // 3
 	// AccessModeAdmin admin access
-	AccessModeAdmin // 3
+	AccessModeAdmin // 4
 	// AccessModeOwner owner access
-	AccessModeOwner // 4
+	AccessModeOwner // 5
 )
 
 func (mode AccessMode) String() string {
 	switch mode {
 	case AccessModeRead:
 		return "read"
+	case AccessModeTriage:
+		return "triage"
 	case AccessModeWrite:
 		return "write"
 	case AccessModeAdmin:
@@ -40,6 +44,8 @@ func (mode AccessMode) String() string {
 // ParseAccessMode returns corresponding access mode to given permission string.
 func ParseAccessMode(permission string) AccessMode {
 	switch permission {
+	case "triage":
+		return AccessModeTriage
 	case "write":
 		return AccessModeWrite
 	case "admin":
@@ -96,6 +102,84 @@ func HasAccess(userID int64, repo *Repository, testMode AccessMode) (bool, error
 	return hasAccess(x, userID, repo, testMode)
 }
 
+// getTeamAccessMode returns the highest access mode given to the user by teams
+// of the organization owning the repository.
+func (repo *Repository) getTeamAccessMode(e Engine, userID int64) (AccessMode, error) {
+	if err := repo.getOwner(e); err != nil {
+		return AccessModeNone, err
+	} else if !repo.Owner.IsOrganization() {
+		return AccessModeNone, nil
+	}
+
+	teams, err := getUserTeams(e, repo.OwnerID, userID)
+	if err != nil {
+		return AccessModeNone, err
+	}
+
+	mode := AccessModeNone
+	for _, t := range teams {
+		if t.IsOwnerTeam() {
+			mode = maxAccessMode(mode, AccessModeOwner)
+		} else if t.hasRepository(e, repo.ID) {
+			mode = maxAccessMode(mode, t.Authorize)
+		}
+	}
+	return mode, nil
+}
+
+func unitAccessModes(e Engine, userID int64, repo *Repository) (map[UnitType]AccessMode, error) {
+	mode, err := accessLevel(e, userID, repo)
+	if err != nil || mode <= AccessModeRead || userID == repo.OwnerID {
+		return nil, err
+	}
+
+	collaboration := &Collaboration{RepoID: repo.ID, UserID: userID}
+	if has, err := e.Get(collaboration); err != nil || !has || len(collaboration.UnitTypes) == 0 {
+		return nil, err
+	}
+
+	// Units the collaboration is not restricted to are read only,
+	// unless teams give more access to the user.
+	otherMode, err := repo.getTeamAccessMode(e, userID)
+	if err != nil {
+		return nil, err
+	}
+	otherMode = maxAccessMode(otherMode, AccessModeRead)
+
+	modes := make(map[UnitType]AccessMode, len(allRepUnitTypes))
+	for _, tp := range allRepUnitTypes {
+		if collaboration.EnableUnit(tp) {
+			modes[tp] = mode
+		} else {
+			modes[tp] = otherMode
+		}
+	}
+	return modes, nil
+}
+
+// UnitAccessModes returns the access the user has to each unit of the repository
+// if it differs between units because of a restricted collaboration, nil otherwise.
+func UnitAccessModes(userID int64, repo *Repository) (map[UnitType]AccessMode, error) {
+	return unitAccessModes(x, userID, repo)
+}
+
+// UnitAccessLevel returns the access the user has to a unit of the repository.
+func UnitAccessLevel(userID int64, repo *Repository, unitType UnitType) (AccessMode, error) {
+	modes, err := unitAccessModes(x, userID, repo)
+	if err != nil {
+		return AccessModeNone, err
+	} else if modes != nil {
+		return modes[unitType], nil
+	}
+	return accessLevel(x, userID, repo)
+}
+
+// HasUnitAccess returns true if user has access to a unit of the repository
+func HasUnitAccess(userID int64, repo *Repository, unitType UnitType, testMode AccessMode) (bool, error) {
+	mode, err := UnitAccessLevel(userID, repo, unitType)
+	return testMode <= mode, err
+}
+
 type repoAccess struct {
 	Access     `xorm:"extends"`
 	Repository `xorm:"extends"`
@@ -143,7 +227,7 @@ func (user *User) GetRepositoryAccesses() (map[*Repository]AccessMode, error) {
 // If limit is smaller than 1 means returns all found results.
 func (user *User) GetAccessibleRepositories(limit int) (repos []*Repository, _ error) {
 	sess := x.
-		Where("owner_id !=? ", user.ID).
+		Where("owner_id !=? AND deleted_unix = 0", user.ID).
 		Desc("updated_unix")
 	if limit > 0 {
 		sess.Limit(limit)
@@ -12,6 +12,7 @@ import (
 
 var accessModes = []AccessMode{
 	AccessModeRead,
+	AccessModeTriage,
 	AccessModeWrite,
 	AccessModeAdmin,
 	AccessModeOwner,
@@ -123,3 +124,52 @@ func TestRepository_RecalculateAccesses2(t *testing.T) {
 	assert.NoError(t, err)
 	assert.False(t, has)
 }
+
+func TestUnitAccessLevel(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 4}).(*Repository)
+	modes, err := UnitAccessModes(4, repo)
+	assert.NoError(t, err)
+	assert.Nil(t, modes)
+
+	assert.NoError(t, repo.ChangeCollaborationUnits(4, []UnitType{UnitTypeIssues}))
+	level, err := UnitAccessLevel(4, repo, UnitTypeIssues)
+	assert.NoError(t, err)
+	assert.Equal(t, AccessModeWrite, level)
+
+	level, err = UnitAccessLevel(4, repo, UnitTypeCode)
+	assert.NoError(t, err)
+	assert.Equal(t, AccessModeRead, level)
+
+	has, err := HasUnitAccess(4, repo, UnitTypeCode, AccessModeWrite)
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	// The owner is not restricted.
+	has, err = HasUnitAccess(5, repo, UnitTypeCode, AccessModeWrite)
+	assert.NoError(t, err)
+	assert.True(t, has)
+}
+
+func TestTriageAccessLevel(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.Equal(t, AccessModeTriage, ParseAccessMode("triage"))
+	assert.Equal(t, "triage", AccessModeTriage.String())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 4}).(*Repository)
+	assert.NoError(t, repo.ChangeCollaborationAccessMode(4, AccessModeTriage))
+
+	level, err := AccessLevel(4, repo)
+	assert.NoError(t, err)
+	assert.Equal(t, AccessModeTriage, level)
+
+	has, err := HasAccess(4, repo, AccessModeTriage)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	has, err = HasAccess(4, repo, AccessModeWrite)
+	assert.NoError(t, err)
+	assert.False(t, has)
+}
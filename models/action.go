@@ -120,7 +120,7 @@ func (a *Action) loadActUser() {
 }
 
 func (a *Action) loadRepo() {
-	if a.ActUser != nil {
+	if a.Repo != nil {
 		return
 	}
 	var err error
@@ -216,9 +216,9 @@ func (a *Action) GetIssueInfos() []string {
 // with the action.
 func (a *Action) GetIssueTitle() string {
 	index := com.StrTo(a.GetIssueInfos()[0]).MustInt64()
-	issue, err := GetIssueByIndex(a.RepoID, index)
+	issue, err := GetRawIssueByIndex(a.RepoID, index)
 	if err != nil {
-		log.Error(4, "GetIssueByIndex: %v", err)
+		log.Error(4, "GetRawIssueByIndex: %v", err)
 		return "500 when get issue"
 	}
 	return issue.Title
@@ -228,9 +228,9 @@ func (a *Action) GetIssueTitle() string {
 // this action.
 func (a *Action) GetIssueContent() string {
 	index := com.StrTo(a.GetIssueInfos()[0]).MustInt64()
-	issue, err := GetIssueByIndex(a.RepoID, index)
+	issue, err := GetRawIssueByIndex(a.RepoID, index)
 	if err != nil {
-		log.Error(4, "GetIssueByIndex: %v", err)
+		log.Error(4, "GetRawIssueByIndex: %v", err)
 		return "500 when get issue"
 	}
 	return issue.Content
@@ -366,7 +366,11 @@ func (pc *PushCommits) AvatarLink(email string) string {
 }
 
 // UpdateIssuesCommit checks if issues are manipulated by commit message.
-func UpdateIssuesCommit(doer *User, repo *Repository, commits []*PushCommit) error {
+// Issues are referenced from commits pushed to any branch, but only closed or
+// reopened from those pushed to the default branch.
+func UpdateIssuesCommit(doer *User, repo *Repository, commits []*PushCommit, branchName string) error {
+	changeStatus := repo.CloseIssuesViaCommit && branchName == repo.DefaultBranch
+
 	// Commits are appended in the reverse order.
 	for i := len(commits) - 1; i >= 0; i-- {
 		c := commits[i]
@@ -408,6 +412,10 @@ func UpdateIssuesCommit(doer *User, repo *Repository, commits []*PushCommit) err
 			}
 		}
 
+		if !changeStatus {
+			continue
+		}
+
 		refMarked = make(map[int64]bool)
 		// FIXME: can merge this one and next one to a common function.
 		for _, ref := range issueCloseKeywordsPat.FindAllString(c.Message, -1) {
@@ -444,7 +452,7 @@ func UpdateIssuesCommit(doer *User, repo *Repository, commits []*PushCommit) err
 				continue
 			}
 
-			if err = issue.ChangeStatus(doer, repo, true); err != nil {
+			if err = issue.changeStatusByCommit(doer, repo, true, c.Sha1); err != nil {
 				return err
 			}
 		}
@@ -484,7 +492,7 @@ func UpdateIssuesCommit(doer *User, repo *Repository, commits []*PushCommit) err
 				continue
 			}
 
-			if err = issue.ChangeStatus(doer, repo, false); err != nil {
+			if err = issue.changeStatusByCommit(doer, repo, false, c.Sha1); err != nil {
 				return err
 			}
 		}
@@ -536,7 +544,7 @@ func CommitRepoAction(opts CommitRepoActionOptions) error {
 			opts.Commits.CompareURL = repo.ComposeCompareURL(opts.OldCommitID, opts.NewCommitID)
 		}
 
-		if err = UpdateIssuesCommit(pusher, repo, opts.Commits.Commits); err != nil {
+		if err = UpdateIssuesCommit(pusher, repo, opts.Commits.Commits, git.RefEndName(opts.RefFullName)); err != nil {
 			log.Error(4, "updateIssuesCommit: %v", err)
 		}
 	}
@@ -678,10 +686,32 @@ type GetFeedsOptions struct {
 	RequestingUserID int64
 	IncludePrivate   bool // include private actions
 	OnlyPerformedBy  bool // only actions performed by requested user
+	OnlyFollowing    bool // only public actions performed by the users requested user follows
+	Page             int
 }
 
+// FeedPagingNum is the number of actions in a page of feeds.
+const FeedPagingNum = 20
+
 // GetFeeds returns actions according to the provided options
 func GetFeeds(opts GetFeedsOptions) ([]*Action, error) {
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+	actions := make([]*Action, 0, FeedPagingNum)
+	sess := x.Limit(FeedPagingNum, (opts.Page-1)*FeedPagingNum).
+		Desc("id")
+
+	// The actions of a user are copied to the feeds of the watchers of the
+	// repository, only the copy in the feed of the user itself is kept.
+	if opts.OnlyFollowing {
+		return actions, sess.
+			Where("user_id = act_user_id").
+			And("act_user_id IN (SELECT follow_id FROM follow WHERE user_id = ?)", opts.RequestedUser.ID).
+			And("is_private = ?", false).
+			Find(&actions)
+	}
+
 	var repoIDs []int64
 	if opts.RequestedUser.IsOrganization() {
 		env, err := opts.RequestedUser.AccessibleReposEnv(opts.RequestingUserID)
@@ -693,10 +723,7 @@ func GetFeeds(opts GetFeedsOptions) ([]*Action, error) {
 		}
 	}
 
-	actions := make([]*Action, 0, 20)
-	sess := x.Limit(20).
-		Desc("id").
-		Where("user_id = ?", opts.RequestedUser.ID)
+	sess.Where("user_id = ?", opts.RequestedUser.ID)
 	if opts.OnlyPerformedBy {
 		sess.And("act_user_id = ?", opts.RequestedUser.ID)
 	}
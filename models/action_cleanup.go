@@ -0,0 +1,156 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// archivedAction is the record of an action in an archive file.
+type archivedAction struct {
+	ID          int64      `json:"id"`
+	UserID      int64      `json:"user_id"`
+	OpType      ActionType `json:"op_type"`
+	ActUserID   int64      `json:"act_user_id"`
+	RepoID      int64      `json:"repo_id"`
+	RefName     string     `json:"ref_name"`
+	IsPrivate   bool       `json:"is_private"`
+	Content     string     `json:"content"`
+	CreatedUnix int64      `json:"created_unix"`
+}
+
+// archiveActions writes the actions created before olderThan to a new gzip
+// compressed file of JSON lines in dir, no file is written if there are no
+// such actions.
+func archiveActions(olderThan int64, dir string) (err error) {
+	count, err := x.Where("created_unix < ?", olderThan).Count(new(Action))
+	if err != nil {
+		return fmt.Errorf("Count: %v", err)
+	} else if count == 0 {
+		return nil
+	}
+
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return fmt.Errorf("MkdirAll: %v", err)
+	}
+	fileName := path.Join(dir, fmt.Sprintf("actions-%s.json.gz", time.Now().Format("20060102150405")))
+	f, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("Create: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(fileName)
+		}
+	}()
+	defer f.Close()
+
+	w := gzip.NewWriter(f)
+	enc := json.NewEncoder(w)
+	if err = x.
+		Where("created_unix < ?", olderThan).
+		Asc("id").
+		Iterate(new(Action), func(idx int, bean interface{}) error {
+			a := bean.(*Action)
+			return enc.Encode(&archivedAction{
+				ID:          a.ID,
+				UserID:      a.UserID,
+				OpType:      a.OpType,
+				ActUserID:   a.ActUserID,
+				RepoID:      a.RepoID,
+				RefName:     a.RefName,
+				IsPrivate:   a.IsPrivate,
+				Content:     a.Content,
+				CreatedUnix: a.CreatedUnix,
+			})
+		}); err != nil {
+		return fmt.Errorf("Iterate: %v", err)
+	}
+	return w.Close()
+}
+
+// deleteOldActions deletes the actions created before olderThan, they are
+// archived in archiveDir first unless it is empty.
+func deleteOldActions(olderThan int64, archiveDir string) (int64, error) {
+	if len(archiveDir) > 0 {
+		if err := archiveActions(olderThan, archiveDir); err != nil {
+			return 0, fmt.Errorf("archiveActions: %v", err)
+		}
+	}
+	return x.Where("created_unix < ?", olderThan).Delete(new(Action))
+}
+
+// DeleteOldActions deletes the actions created longer than configured ago.
+// Actions are deleted by creation time, so the feeds of all users lose the
+// same events.
+func DeleteOldActions() {
+	if !taskStatusTable.StartIfNotRunning(actionCleanup) {
+		return
+	}
+	defer taskStatusTable.Stop(actionCleanup)
+
+	log.Trace("Doing: ActionCleanup")
+
+	var archiveDir string
+	if setting.Cron.ActionCleanup.Archive {
+		archiveDir = setting.Cron.ActionCleanup.ArchivePath
+	}
+	olderThan := time.Now().Add(-setting.Cron.ActionCleanup.OlderThan).Unix()
+	deleted, err := deleteOldActions(olderThan, archiveDir)
+	if err != nil {
+		log.Error(4, "ActionCleanup: %v", err)
+		return
+	}
+	log.Trace("ActionCleanup: %d actions deleted", deleted)
+}
+
+// ActionStats represents the size of the action table and of its archives.
+type ActionStats struct {
+	Count        int64
+	Oldest       time.Time
+	ArchiveCount int
+	ArchiveSize  int64
+}
+
+// GetActionStats returns the number of actions, the creation time of the
+// oldest one and the number and total size of the archive files.
+func GetActionStats() (*ActionStats, error) {
+	stats := new(ActionStats)
+	count, err := x.Count(new(Action))
+	if err != nil {
+		return nil, fmt.Errorf("Count: %v", err)
+	}
+	stats.Count = count
+
+	oldest := new(Action)
+	has, err := x.Asc("created_unix").Get(oldest)
+	if err != nil {
+		return nil, fmt.Errorf("Get: %v", err)
+	} else if has {
+		stats.Oldest = oldest.Created
+	}
+
+	files, err := ioutil.ReadDir(setting.Cron.ActionCleanup.ArchivePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("ReadDir: %v", err)
+	}
+	for _, fi := range files {
+		if !fi.IsDir() && strings.HasPrefix(fi.Name(), "actions-") {
+			stats.ArchiveCount++
+			stats.ArchiveSize += fi.Size()
+		}
+	}
+	return stats, nil
+}
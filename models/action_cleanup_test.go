@@ -0,0 +1,59 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteOldActions(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	dir, err := ioutil.TempDir("", "action-archives")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	deleted, err := deleteOldActions(946684801, dir)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, deleted)
+	AssertNotExistsBean(t, &Action{ID: 1})
+	AssertNotExistsBean(t, &Action{ID: 2})
+	AssertExistsAndLoadBean(t, &Action{ID: 3})
+
+	files, err := filepath.Glob(filepath.Join(dir, "actions-*.json.gz"))
+	assert.NoError(t, err)
+	if assert.Len(t, files, 1) {
+		f, err := os.Open(files[0])
+		assert.NoError(t, err)
+		defer f.Close()
+		r, err := gzip.NewReader(f)
+		assert.NoError(t, err)
+
+		var ids []int64
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			var a archivedAction
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &a))
+			ids = append(ids, a.ID)
+		}
+		assert.Equal(t, []int64{1, 2}, ids)
+	}
+
+	// Nothing left to archive.
+	deleted, err = deleteOldActions(946684801, dir)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, deleted)
+	files, err = filepath.Glob(filepath.Join(dir, "actions-*.json.gz"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+}
@@ -0,0 +1,88 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import "fmt"
+
+// ActionList defines a list of actions
+type ActionList []*Action
+
+func (actions ActionList) getRepoIDs() []int64 {
+	repoIDs := make(map[int64]struct{}, len(actions))
+	for _, action := range actions {
+		repoIDs[action.RepoID] = struct{}{}
+	}
+	return keysInt64(repoIDs)
+}
+
+func (actions ActionList) loadRepositories(e Engine) ([]*Repository, error) {
+	if len(actions) == 0 {
+		return nil, nil
+	}
+
+	repoIDs := actions.getRepoIDs()
+	repoMaps := make(map[int64]*Repository, len(repoIDs))
+	if err := e.
+		In("id", repoIDs).
+		Find(&repoMaps); err != nil {
+		return nil, fmt.Errorf("find repository: %v", err)
+	}
+
+	for _, action := range actions {
+		action.Repo = repoMaps[action.RepoID]
+	}
+	return valuesRepository(repoMaps), nil
+}
+
+// loadUsers loads the acting users of the actions and the owners of their
+// repositories in a single query.
+func (actions ActionList) loadUsers(e Engine, repos []*Repository) error {
+	userIDs := make(map[int64]struct{}, len(actions)+len(repos))
+	for _, action := range actions {
+		userIDs[action.ActUserID] = struct{}{}
+	}
+	for _, repo := range repos {
+		userIDs[repo.OwnerID] = struct{}{}
+	}
+
+	userMaps := make(map[int64]*User, len(userIDs))
+	if err := e.
+		In("id", keysInt64(userIDs)).
+		Find(&userMaps); err != nil {
+		return fmt.Errorf("find user: %v", err)
+	}
+
+	for _, action := range actions {
+		var ok bool
+		if action.ActUser, ok = userMaps[action.ActUserID]; !ok {
+			action.ActUser = NewGhostUser()
+		}
+	}
+	for _, repo := range repos {
+		var ok bool
+		if repo.Owner, ok = userMaps[repo.OwnerID]; !ok {
+			repo.Owner = NewGhostUser()
+		}
+	}
+	return nil
+}
+
+func (actions ActionList) loadAttributes(e Engine) error {
+	if len(actions) == 0 {
+		return nil
+	}
+
+	repos, err := actions.loadRepositories(e)
+	if err != nil {
+		return err
+	}
+	return actions.loadUsers(e, repos)
+}
+
+// LoadAttributes loads the acting users and the repositories of the actions,
+// the repository of an action is nil if it no longer exists.
+func (actions ActionList) LoadAttributes() error {
+	return actions.loadAttributes(x)
+}
@@ -0,0 +1,32 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActionList_LoadAttributes(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	actions := ActionList{
+		AssertExistsAndLoadBean(t, &Action{ID: 1}).(*Action),
+		AssertExistsAndLoadBean(t, &Action{ID: 2}).(*Action),
+		{ActUserID: NonexistentID, RepoID: NonexistentID},
+	}
+	assert.NoError(t, actions.LoadAttributes())
+
+	for _, action := range actions[:2] {
+		assert.EqualValues(t, action.ActUserID, action.ActUser.ID)
+		if assert.NotNil(t, action.Repo) {
+			assert.EqualValues(t, action.RepoID, action.Repo.ID)
+			assert.EqualValues(t, action.Repo.OwnerID, action.Repo.Owner.ID)
+		}
+	}
+	assert.EqualValues(t, -1, actions[2].ActUser.ID)
+	assert.Nil(t, actions[2].Repo)
+}
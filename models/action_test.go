@@ -184,6 +184,7 @@ func TestUpdateIssuesCommit(t *testing.T) {
 	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
 	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
 	repo.Owner = user
+	repo.DefaultBranch = "master"
 
 	commentBean := &Comment{
 		Type:      CommentTypeCommitRef,
@@ -195,9 +196,21 @@ func TestUpdateIssuesCommit(t *testing.T) {
 
 	AssertNotExistsBean(t, commentBean)
 	AssertNotExistsBean(t, &Issue{RepoID: repo.ID, Index: 2}, "is_closed=1")
-	assert.NoError(t, UpdateIssuesCommit(user, repo, pushCommits))
+
+	// Pushed to another branch
+	assert.NoError(t, UpdateIssuesCommit(user, repo, pushCommits, "feature"))
 	AssertExistsAndLoadBean(t, commentBean)
-	AssertExistsAndLoadBean(t, issueBean, "is_closed=1")
+	AssertNotExistsBean(t, &Issue{RepoID: repo.ID, Index: 2}, "is_closed=1")
+
+	// Disabled for the repository
+	repo.CloseIssuesViaCommit = false
+	assert.NoError(t, UpdateIssuesCommit(user, repo, pushCommits, "master"))
+	AssertNotExistsBean(t, &Issue{RepoID: repo.ID, Index: 2}, "is_closed=1")
+
+	repo.CloseIssuesViaCommit = true
+	assert.NoError(t, UpdateIssuesCommit(user, repo, pushCommits, "master"))
+	issue := AssertExistsAndLoadBean(t, issueBean, "is_closed=1").(*Issue)
+	AssertExistsAndLoadBean(t, &Comment{Type: CommentTypeClose, IssueID: issue.ID, CommitSHA: "abcdef2"})
 	CheckConsistencyFor(t, &Action{})
 }
 
@@ -352,3 +365,37 @@ func TestGetFeeds2(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, actions, 0)
 }
+
+func TestGetFeeds_OnlyFollowing(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	user := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	AssertExistsAndLoadBean(t, &Follow{UserID: user.ID, FollowID: 2})
+
+	public := &Action{UserID: 2, ActUserID: 2, OpType: ActionCreateRepo, RepoID: 1}
+	_, err := x.Insert(public,
+		// The copy of the action in the feed of a watcher.
+		&Action{UserID: 5, ActUserID: 2, OpType: ActionCreateRepo, RepoID: 1},
+		// An action of a user who is not followed.
+		&Action{UserID: 8, ActUserID: 8, OpType: ActionCreateRepo, RepoID: 1},
+	)
+	assert.NoError(t, err)
+
+	actions, err := GetFeeds(GetFeedsOptions{
+		RequestedUser:    user,
+		RequestingUserID: user.ID,
+		OnlyFollowing:    true,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, actions, 1) {
+		assert.EqualValues(t, public.ID, actions[0].ID)
+	}
+
+	actions, err = GetFeeds(GetFeedsOptions{
+		RequestedUser:    user,
+		RequestingUserID: user.ID,
+		OnlyFollowing:    true,
+		Page:             2,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, actions, 0)
+}
@@ -23,13 +23,26 @@ const (
 	NoticeRepository NoticeType = iota + 1
 )
 
+// NoticeSeverity describes how serious the problem of a notice is
+type NoticeSeverity int
+
+const (
+	// NoticeSeverityInfo is a notice which needs no action
+	NoticeSeverityInfo NoticeSeverity = iota + 1
+	// NoticeSeverityWarning is a notice of a problem which does not stop anything from working
+	NoticeSeverityWarning
+	// NoticeSeverityError is a notice of a failure
+	NoticeSeverityError
+)
+
 // Notice represents a system notice for admin.
 type Notice struct {
 	ID          int64 `xorm:"pk autoincr"`
 	Type        NoticeType
-	Description string    `xorm:"TEXT"`
-	Created     time.Time `xorm:"-"`
-	CreatedUnix int64     `xorm:"INDEX"`
+	Severity    NoticeSeverity `xorm:"NOT NULL DEFAULT 3"`
+	Description string         `xorm:"TEXT"`
+	Created     time.Time      `xorm:"-"`
+	CreatedUnix int64          `xorm:"INDEX"`
 }
 
 // BeforeInsert is invoked from XORM before inserting an object of this type.
@@ -50,14 +63,24 @@ func (n *Notice) TrStr() string {
 	return "admin.notices.type_" + com.ToStr(n.Type)
 }
 
+// SeverityTrStr returns a translation format string of the severity.
+func (n *Notice) SeverityTrStr() string {
+	return "admin.notices.severity_" + com.ToStr(n.Severity)
+}
+
 // CreateNotice creates new system notice.
 func CreateNotice(tp NoticeType, desc string) error {
 	return createNotice(x, tp, desc)
 }
 
 func createNotice(e Engine, tp NoticeType, desc string) error {
+	return createSeverityNotice(e, tp, NoticeSeverityError, desc)
+}
+
+func createSeverityNotice(e Engine, tp NoticeType, severity NoticeSeverity, desc string) error {
 	n := &Notice{
 		Type:        tp,
+		Severity:    severity,
 		Description: desc,
 	}
 	_, err := e.Insert(n)
@@ -69,6 +92,12 @@ func CreateRepositoryNotice(desc string) error {
 	return createNotice(x, NoticeRepository, desc)
 }
 
+// CreateSeverityRepositoryNotice creates new system notice with type
+// NoticeRepository and given severity.
+func CreateSeverityRepositoryNotice(severity NoticeSeverity, desc string) error {
+	return createSeverityNotice(x, NoticeRepository, severity, desc)
+}
+
 // RemoveAllWithNotice removes all directories in given path and
 // creates a system notice when error occurs.
 func RemoveAllWithNotice(title, path string) {
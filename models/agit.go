@@ -0,0 +1,125 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/git"
+)
+
+// EnvPushForReviewOnly is set when the pusher is only allowed to push
+// references for review, because of a read access to the repository.
+const EnvPushForReviewOnly = "GITEA_PUSH_FOR_REVIEW_ONLY"
+
+const (
+	// AgitRefPrefix is the prefix of the references pushed to open pull requests
+	// without forking, e.g. refs/for/master/my-topic.
+	AgitRefPrefix = "refs/for/"
+	// AgitBranchPrefix is the prefix of the branches holding the commits of
+	// those pull requests.
+	AgitBranchPrefix = "agit/"
+)
+
+var agitTopicPattern = regexp.MustCompile(`^[\w.\-]+(/[\w.\-]+)*$`)
+
+// ParseAgitRef returns the target branch and the topic of a reference pushed
+// for review. The target branch may contain slashes, the longest existing
+// branch is used.
+func ParseAgitRef(repoPath, refFullName string) (target, topic string, err error) {
+	name := strings.TrimPrefix(refFullName, AgitRefPrefix)
+	if name == refFullName {
+		return "", "", fmt.Errorf("%s is not a reference for review", refFullName)
+	}
+
+	for i := strings.LastIndexByte(name, '/'); i > 0; i = strings.LastIndexByte(name[:i], '/') {
+		if git.IsBranchExist(repoPath, name[:i]) {
+			target, topic = name[:i], name[i+1:]
+			break
+		}
+	}
+	if len(target) == 0 {
+		if git.IsBranchExist(repoPath, name) {
+			return "", "", fmt.Errorf("a topic is required, push to %s%s/<topic>", AgitRefPrefix, name)
+		}
+		return "", "", fmt.Errorf("no target branch found in %s", refFullName)
+	}
+
+	if !agitTopicPattern.MatchString(topic) || strings.Contains(topic, "..") || strings.HasSuffix(topic, ".lock") {
+		return "", "", fmt.Errorf("%q is not a valid topic", topic)
+	}
+	return target, topic, nil
+}
+
+// AgitBranchName returns the name of the branch holding the commits pushed
+// for review by given user on given topic.
+func AgitBranchName(pusher *User, topic string) string {
+	return AgitBranchPrefix + pusher.LowerName + "/" + topic
+}
+
+// HandleAgitPush moves the commits pushed for review to the branch of the
+// pusher for the topic, and opens or updates the pull request from that
+// branch into the target branch.
+func HandleAgitPush(pusher *User, repo *Repository, refFullName, newCommitID string, opts PushOptions) (*PushOptionsResult, error) {
+	res := &PushOptionsResult{}
+	repoPath := repo.RepoPath()
+	target, topic, err := ParseAgitRef(repoPath, refFullName)
+	if err != nil {
+		res.addMessage("Pull request is not created: %v", err)
+		return res, nil
+	}
+
+	branch := AgitBranchName(pusher, topic)
+	if _, err = git.NewCommand("update-ref", git.BranchPrefix+branch, newCommitID).RunInDir(repoPath); err != nil {
+		return nil, fmt.Errorf("update-ref %s: %v", branch, err)
+	} else if _, err = git.NewCommand("update-ref", "-d", refFullName).RunInDir(repoPath); err != nil {
+		return nil, fmt.Errorf("update-ref -d %s: %v", refFullName, err)
+	}
+
+	pr, err := GetUnmergedPullRequest(repo.ID, repo.ID, branch, target)
+	if err != nil && !IsErrPullRequestNotExist(err) {
+		return nil, fmt.Errorf("GetUnmergedPullRequest: %v", err)
+	}
+
+	agitOpts := make(PushOptions, len(opts)+2)
+	for key, value := range opts {
+		agitOpts[key] = value
+	}
+	agitOpts[PushOptionPullTarget] = target
+	if pr != nil {
+		if err = pr.LoadIssue(); err != nil {
+			return nil, fmt.Errorf("LoadIssue: %v", err)
+		}
+		go AddTestPullRequestTask(pusher, repo.ID, branch, true)
+		res.addMessage("Updated pull request #%d: %s", pr.Issue.Index, pr.Issue.HTMLURL())
+		delete(agitOpts, PushOptionPullCreate)
+	} else {
+		agitOpts[PushOptionPullCreate] = "true"
+		if len(agitOpts[PushOptionPullTitle]) == 0 {
+			agitOpts[PushOptionPullTitle] = topic
+			if commit, err := getCommitOfBranch(repoPath, branch); err == nil {
+				agitOpts[PushOptionPullTitle] = commit.Summary()
+			}
+		}
+	}
+
+	optsRes, err := HandlePushOptions(pusher, repo, branch, agitOpts)
+	if err != nil {
+		return nil, err
+	}
+	res.Messages = append(res.Messages, optsRes.Messages...)
+	res.NewPull = optsRes.NewPull
+	return res, nil
+}
+
+func getCommitOfBranch(repoPath, branch string) (*git.Commit, error) {
+	gitRepo, err := git.OpenRepository(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return gitRepo.GetBranchCommit(branch)
+}
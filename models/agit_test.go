@@ -0,0 +1,54 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"code.gitea.io/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAgitRef(t *testing.T) {
+	repoPath, err := ioutil.TempDir("", "agit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(repoPath)
+
+	for _, args := range [][]string{
+		{"init"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "initial"},
+		{"branch", "-M", "master"},
+		{"branch", "release/1.0"},
+	} {
+		_, err = git.NewCommand(args...).RunInDir(repoPath)
+		assert.NoError(t, err)
+	}
+
+	target, topic, err := ParseAgitRef(repoPath, "refs/for/master/fix-build")
+	assert.NoError(t, err)
+	assert.Equal(t, "master", target)
+	assert.Equal(t, "fix-build", topic)
+
+	target, topic, err = ParseAgitRef(repoPath, "refs/for/release/1.0/bug/42")
+	assert.NoError(t, err)
+	assert.Equal(t, "release/1.0", target)
+	assert.Equal(t, "bug/42", topic)
+
+	for _, ref := range []string{
+		"refs/heads/master",
+		"refs/for/master",
+		"refs/for/unknown/topic",
+		"refs/for/master/..",
+		"refs/for/master/a b",
+	} {
+		_, _, err = ParseAgitRef(repoPath, ref)
+		assert.Error(t, err, ref)
+	}
+
+	assert.Equal(t, "agit/user2/fix", AgitBranchName(&User{LowerName: "user2"}, "fix"))
+}
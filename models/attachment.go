@@ -10,11 +10,13 @@ import (
 	"mime/multipart"
 	"os"
 	"path"
+	"regexp"
 	"time"
 
 	"github.com/go-xorm/xorm"
 	gouuid "github.com/satori/go.uuid"
 
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 )
 
@@ -25,7 +27,9 @@ type Attachment struct {
 	IssueID       int64  `xorm:"INDEX"`
 	ReleaseID     int64  `xorm:"INDEX"`
 	CommentID     int64
+	UploaderID    int64 `xorm:"INDEX DEFAULT 0"`
 	Name          string
+	Size          int64     `xorm:"NOT NULL DEFAULT 0"`
 	DownloadCount int64     `xorm:"DEFAULT 0"`
 	Created       time.Time `xorm:"-"`
 	CreatedUnix   int64
@@ -69,11 +73,18 @@ func (a *Attachment) LocalPath() string {
 	return AttachmentLocalPath(a.UUID)
 }
 
-// NewAttachment creates a new attachment object.
-func NewAttachment(name string, buf []byte, file multipart.File) (_ *Attachment, err error) {
+// DownloadURL returns the absolute URL to download the attachment.
+func (a *Attachment) DownloadURL() string {
+	return setting.AppURL + "attachments/" + a.UUID
+}
+
+// NewAttachment creates a new attachment object uploaded by the user with
+// uploaderID, 0 for anonymous uploads.
+func NewAttachment(uploaderID int64, name string, buf []byte, file multipart.File) (_ *Attachment, err error) {
 	attach := &Attachment{
-		UUID: gouuid.NewV4().String(),
-		Name: name,
+		UUID:       gouuid.NewV4().String(),
+		UploaderID: uploaderID,
+		Name:       name,
 	}
 
 	localPath := attach.LocalPath()
@@ -85,21 +96,94 @@ func NewAttachment(name string, buf []byte, file multipart.File) (_ *Attachment,
 	if err != nil {
 		return nil, fmt.Errorf("Create: %v", err)
 	}
-	defer fw.Close()
+	defer func() {
+		fw.Close()
+		if err != nil {
+			os.Remove(localPath)
+		}
+	}()
 
+	var n int64
 	if _, err = fw.Write(buf); err != nil {
 		return nil, fmt.Errorf("Write: %v", err)
-	} else if _, err = io.Copy(fw, file); err != nil {
+	} else if n, err = io.Copy(fw, file); err != nil {
 		return nil, fmt.Errorf("Copy: %v", err)
 	}
+	attach.Size = int64(len(buf)) + n
 
-	if _, err := x.Insert(attach); err != nil {
+	if err = CheckUserAttachmentQuota(uploaderID, attach.Size); err != nil {
+		return nil, err
+	} else if _, err = x.Insert(attach); err != nil {
 		return nil, err
 	}
 
 	return attach, nil
 }
 
+// sumAttachmentSize returns the total size of the attachments matching the
+// query.
+func sumAttachmentSize(e Engine, query string, args ...interface{}) (int64, error) {
+	sizes, err := e.Where(query, args...).SumsInt(new(Attachment), "size")
+	if err != nil {
+		return 0, err
+	}
+	return sizes[0], nil
+}
+
+// CheckUserAttachmentQuota returns ErrAttachmentQuotaExceeded if the user
+// with uploaderID can't upload size more bytes of attachments. Anonymous
+// uploads are not limited.
+func CheckUserAttachmentQuota(uploaderID, size int64) error {
+	return checkUserAttachmentQuota(x, uploaderID, size)
+}
+
+func checkUserAttachmentQuota(e Engine, uploaderID, size int64) error {
+	if setting.AttachmentUserQuota <= 0 || uploaderID == 0 {
+		return nil
+	}
+
+	quota := setting.AttachmentUserQuota * 1024 * 1024
+	used, err := sumAttachmentSize(e, "uploader_id = ?", uploaderID)
+	if err != nil {
+		return err
+	} else if used+size > quota {
+		return ErrAttachmentQuotaExceeded{UserID: uploaderID, Quota: quota}
+	}
+	return nil
+}
+
+// checkIssueAttachmentQuota returns ErrAttachmentQuotaExceeded if associating
+// the attachments of uuids and the ones linked in content with the issue
+// would make its attachments larger than allowed.
+func checkIssueAttachmentQuota(e Engine, issueID int64, uuids []string, content string) error {
+	if setting.AttachmentIssueQuota <= 0 {
+		return nil
+	}
+
+	attachments, err := getAttachmentsByUUIDs(e, append(contentAttachmentUUIDs(content), uuids...))
+	if err != nil {
+		return err
+	}
+	var added int64
+	for _, attach := range attachments {
+		if attach.IssueID == 0 && attach.ReleaseID == 0 {
+			added += attach.Size
+		}
+	}
+	if added == 0 {
+		return nil
+	}
+
+	quota := setting.AttachmentIssueQuota * 1024 * 1024
+	used, err := sumAttachmentSize(e, "issue_id = ?", issueID)
+	if err != nil {
+		return err
+	} else if used+added > quota {
+		return ErrAttachmentQuotaExceeded{IssueID: issueID, Quota: quota}
+	}
+	return nil
+}
+
 func getAttachmentByUUID(e Engine, uuid string) (*Attachment, error) {
 	attach := &Attachment{UUID: uuid}
 	has, err := e.Get(attach)
@@ -186,3 +270,139 @@ func DeleteAttachmentsByComment(commentID int64, remove bool) (int, error) {
 
 	return DeleteAttachments(attachments, remove)
 }
+
+// AttachmentUsage is the number and the total size of the attachments
+// uploaded by a user or associated with an issue.
+type AttachmentUsage struct {
+	ID             int64
+	NumAttachments int64
+	Size           int64
+
+	User  *User  `xorm:"-"`
+	Issue *Issue `xorm:"-"`
+}
+
+// AttachmentStats is the storage used by the attachments of the instance.
+type AttachmentStats struct {
+	NumAttachments   int64
+	Size             int64
+	NumUnreferenced  int64
+	UnreferencedSize int64
+	TopUploaders     []*AttachmentUsage
+	TopIssues        []*AttachmentUsage
+}
+
+// getAttachmentUsages returns the usage of the limit values of groupCol
+// whose attachments are the largest.
+func getAttachmentUsages(groupCol string, limit int) ([]*AttachmentUsage, error) {
+	usages := make([]*AttachmentUsage, 0, limit)
+	return usages, x.Table("attachment").
+		Select(groupCol + " AS id, COUNT(*) AS num_attachments, SUM(size) AS size").
+		Where(groupCol + " > 0").
+		GroupBy(groupCol).
+		OrderBy("SUM(size) DESC").
+		Limit(limit).
+		Find(&usages)
+}
+
+// GetAttachmentStats returns the storage used by attachments, with the limit
+// users and issues whose attachments are the largest.
+func GetAttachmentStats(limit int) (_ *AttachmentStats, err error) {
+	stats := new(AttachmentStats)
+	if stats.NumAttachments, err = x.Count(new(Attachment)); err != nil {
+		return nil, err
+	} else if stats.Size, err = sumAttachmentSize(x, "id > 0"); err != nil {
+		return nil, err
+	}
+
+	const unreferenced = "issue_id = 0 AND release_id = 0 AND comment_id = 0"
+	if stats.NumUnreferenced, err = x.Where(unreferenced).Count(new(Attachment)); err != nil {
+		return nil, err
+	} else if stats.UnreferencedSize, err = sumAttachmentSize(x, unreferenced); err != nil {
+		return nil, err
+	}
+
+	if stats.TopUploaders, err = getAttachmentUsages("uploader_id", limit); err != nil {
+		return nil, fmt.Errorf("get top uploaders: %v", err)
+	}
+	for _, usage := range stats.TopUploaders {
+		if usage.User, err = getUserByID(x, usage.ID); err != nil && !IsErrUserNotExist(err) {
+			return nil, err
+		}
+	}
+
+	if stats.TopIssues, err = getAttachmentUsages("issue_id", limit); err != nil {
+		return nil, fmt.Errorf("get top issues: %v", err)
+	}
+	for _, usage := range stats.TopIssues {
+		if usage.Issue, err = getIssueByID(x, usage.ID); err != nil {
+			if IsErrIssueNotExist(err) {
+				continue
+			}
+			return nil, err
+		} else if err = usage.Issue.loadRepo(x); err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+// attachmentURLPattern matches the UUIDs of attachments linked in content.
+var attachmentURLPattern = regexp.MustCompile(`/attachments/([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})`)
+
+// contentAttachmentUUIDs returns the UUIDs of the attachments linked in
+// content.
+func contentAttachmentUUIDs(content string) []string {
+	matches := attachmentURLPattern.FindAllStringSubmatch(content, -1)
+	uuids := make([]string, len(matches))
+	for i, m := range matches {
+		uuids[i] = m[1]
+	}
+	return uuids
+}
+
+// linkContentAttachments associates the attachments linked in content, which
+// have been uploaded by doer and not been associated yet, with the issue and
+// optionally its comment.
+func linkContentAttachments(e Engine, doer *User, issueID, commentID int64, content string) error {
+	uuids := contentAttachmentUUIDs(content)
+	if doer == nil || len(uuids) == 0 {
+		return nil
+	}
+
+	_, err := e.In("uuid", uuids).
+		And("uploader_id = ? AND issue_id = 0 AND release_id = 0 AND comment_id = 0", doer.ID).
+		Cols("issue_id", "comment_id").
+		Update(&Attachment{IssueID: issueID, CommentID: commentID})
+	return err
+}
+
+// DeleteUnreferencedAttachments deletes the attachments which have not been
+// associated with an issue, comment or release since they were uploaded
+// longer than configured ago.
+func DeleteUnreferencedAttachments() {
+	if !taskStatusTable.StartIfNotRunning(attachmentCleanup) {
+		return
+	}
+	defer taskStatusTable.Stop(attachmentCleanup)
+
+	log.Trace("Doing: AttachmentCleanup")
+
+	olderThan := time.Now().Add(-setting.Cron.AttachmentCleanup.OlderThan).Unix()
+	attachments := make([]*Attachment, 0, 10)
+	if err := x.
+		Where("issue_id = 0 AND release_id = 0 AND comment_id = 0 AND created_unix < ?", olderThan).
+		Find(&attachments); err != nil {
+		log.Error(4, "AttachmentCleanup: %v", err)
+		return
+	}
+	for _, a := range attachments {
+		if err := os.Remove(a.LocalPath()); err != nil && !os.IsNotExist(err) {
+			log.Error(4, "Remove [%s]: %v", a.UUID, err)
+			continue
+		}
+		if _, err := x.Delete(&Attachment{ID: a.ID}); err != nil {
+			log.Error(4, "Delete attachment [%s]: %v", a.UUID, err)
+		}
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"code.gitea.io/gitea/modules/setting"
 )
 
 func TestIncreaseDownloadCount(t *testing.T) {
@@ -58,3 +60,84 @@ func TestDeleteAttachments(t *testing.T) {
 	assert.True(t, IsErrAttachmentNotExist(err))
 	assert.Nil(t, attachment)
 }
+
+func TestLinkContentAttachments(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	content := "![pasted.png](http://localhost:3000/attachments/a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a19)"
+
+	// Only the uploader can link the attachment.
+	other := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	assert.NoError(t, linkContentAttachments(x, other, 1, 0, content))
+	AssertExistsAndLoadBean(t, &Attachment{ID: 9, IssueID: 0})
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.NoError(t, linkContentAttachments(x, doer, 1, 2, content))
+	AssertExistsAndLoadBean(t, &Attachment{ID: 9, IssueID: 1, CommentID: 2})
+
+	// Attachments which have been linked already are kept.
+	assert.NoError(t, linkContentAttachments(x, doer, 5, 0, content))
+	AssertExistsAndLoadBean(t, &Attachment{ID: 9, IssueID: 1, CommentID: 2})
+}
+
+func TestDeleteUnreferencedAttachments(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	DeleteUnreferencedAttachments()
+	AssertNotExistsBean(t, &Attachment{ID: 9})
+	AssertExistsAndLoadBean(t, &Attachment{ID: 1})
+}
+
+func TestCheckUserAttachmentQuota(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer func(quota int64) { setting.AttachmentUserQuota = quota }(setting.AttachmentUserQuota)
+
+	setting.AttachmentUserQuota = 0
+	assert.NoError(t, CheckUserAttachmentQuota(2, 10*1024*1024))
+
+	setting.AttachmentUserQuota = 1
+	assert.NoError(t, CheckUserAttachmentQuota(2, 512*1024))
+	assert.NoError(t, CheckUserAttachmentQuota(0, 10*1024*1024))
+	err := CheckUserAttachmentQuota(2, 512*1024+1)
+	assert.True(t, IsErrAttachmentQuotaExceeded(err))
+	assert.EqualValues(t, 2, err.(ErrAttachmentQuotaExceeded).UserID)
+}
+
+func TestCheckIssueAttachmentQuota(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer func(quota int64) { setting.AttachmentIssueQuota = quota }(setting.AttachmentIssueQuota)
+	content := "![pasted.png](http://localhost:3000/attachments/a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a19)"
+
+	setting.AttachmentIssueQuota = 2
+	err := checkIssueAttachmentQuota(x, 1, nil, content)
+	assert.True(t, IsErrAttachmentQuotaExceeded(err))
+	assert.EqualValues(t, 1, err.(ErrAttachmentQuotaExceeded).IssueID)
+	assert.NoError(t, checkIssueAttachmentQuota(x, 2, nil, content))
+
+	// Attachments already associated with the issue are not counted twice.
+	assert.NoError(t, checkIssueAttachmentQuota(x, 1, []string{"a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"}, ""))
+
+	setting.AttachmentIssueQuota = 0
+	assert.NoError(t, checkIssueAttachmentQuota(x, 1, nil, content))
+}
+
+func TestGetAttachmentStats(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	stats, err := GetAttachmentStats(1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 9, stats.NumAttachments)
+	assert.EqualValues(t, 2*1024*1024+512*1024, stats.Size)
+	assert.EqualValues(t, 1, stats.NumUnreferenced)
+	assert.EqualValues(t, 512*1024, stats.UnreferencedSize)
+
+	if assert.Len(t, stats.TopUploaders, 1) {
+		assert.EqualValues(t, 2, stats.TopUploaders[0].ID)
+		assert.EqualValues(t, 2, stats.TopUploaders[0].User.ID)
+	}
+	if assert.Len(t, stats.TopIssues, 1) {
+		assert.EqualValues(t, 1, stats.TopIssues[0].ID)
+		assert.EqualValues(t, 2, stats.TopIssues[0].NumAttachments)
+		assert.EqualValues(t, 2*1024*1024, stats.TopIssues[0].Size)
+		assert.NotNil(t, stats.TopIssues[0].Issue.Repo)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-xorm/xorm"
+)
+
+// AuditAction is the kind of an action recorded in the audit log.
+type AuditAction int
+
+// Actions recorded in the audit log.
+const (
+	AuditImpersonationStart     AuditAction = iota + 1 // 1
+	AuditImpersonationStop                             // 2
+	AuditImpersonationExpire                           // 3
+	AuditImpersonatedRequest                           // 4
+	AuditImpersonatedAPIRequest                        // 5
+)
+
+// auditActionNames are the names of the actions, the audit log page
+// translates them.
+var auditActionNames = map[AuditAction]string{
+	AuditImpersonationStart:     "impersonation_start",
+	AuditImpersonationStop:      "impersonation_stop",
+	AuditImpersonationExpire:    "impersonation_expire",
+	AuditImpersonatedRequest:    "impersonated_request",
+	AuditImpersonatedAPIRequest: "impersonated_api_request",
+}
+
+// Name returns the name of the action.
+func (a AuditAction) Name() string {
+	return auditActionNames[a]
+}
+
+// AuditLog represents an action of an admin recorded for later review, like
+// a request made while impersonating another user.
+type AuditLog struct {
+	ID         int64       `xorm:"pk autoincr"`
+	Action     AuditAction `xorm:"INDEX NOT NULL"`
+	DoerID     int64       `xorm:"INDEX NOT NULL"`
+	Doer       *User       `xorm:"-"`
+	UserID     int64       `xorm:"INDEX"`
+	User       *User       `xorm:"-"`
+	Method     string      `xorm:"VARCHAR(16)"`
+	Path       string      `xorm:"TEXT"`
+	RemoteAddr string
+
+	Created     time.Time `xorm:"-"`
+	CreatedUnix int64     `xorm:"INDEX created"`
+}
+
+// AfterSet is invoked from XORM after setting the value of a field of this object.
+func (l *AuditLog) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		l.Created = time.Unix(l.CreatedUnix, 0).Local()
+	}
+}
+
+// CreateAuditLog records an action in the audit log.
+func CreateAuditLog(l *AuditLog) error {
+	if _, err := x.Insert(l); err != nil {
+		return fmt.Errorf("insert audit log: %v", err)
+	}
+	return nil
+}
+
+// CountAuditLogs returns the number of actions in the audit log.
+func CountAuditLogs() (int64, error) {
+	return x.Count(new(AuditLog))
+}
+
+// GetAuditLogs returns a page of the audit log, most recent actions first,
+// with their doers and users loaded. Deleted users are left nil.
+func GetAuditLogs(page, pageSize int) ([]*AuditLog, error) {
+	logs := make([]*AuditLog, 0, pageSize)
+	if err := x.
+		Limit(pageSize, (page-1)*pageSize).
+		Desc("id").
+		Find(&logs); err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]int64, 0, len(logs)*2)
+	for _, l := range logs {
+		userIDs = append(userIDs, l.DoerID)
+		if l.UserID > 0 {
+			userIDs = append(userIDs, l.UserID)
+		}
+	}
+	users := make(map[int64]*User, len(userIDs))
+	if len(userIDs) > 0 {
+		if err := x.In("id", userIDs).Find(&users); err != nil {
+			return nil, fmt.Errorf("find users: %v", err)
+		}
+	}
+	for _, l := range logs {
+		l.Doer = users[l.DoerID]
+		l.User = users[l.UserID]
+	}
+	return logs, nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAuditLog(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, CreateAuditLog(&AuditLog{Action: AuditImpersonationStart, DoerID: 1, UserID: 2}))
+	assert.NoError(t, CreateAuditLog(&AuditLog{Action: AuditImpersonatedRequest, DoerID: 1, UserID: 2, Method: "POST", Path: "/user2/repo1/issues/new"}))
+	assert.NoError(t, CreateAuditLog(&AuditLog{Action: AuditImpersonationStop, DoerID: 1, UserID: NonexistentID}))
+
+	total, err := CountAuditLogs()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+
+	logs, err := GetAuditLogs(1, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, logs, 2) {
+		assert.Equal(t, AuditImpersonationStop, logs[0].Action)
+		assert.EqualValues(t, 1, logs[0].Doer.ID)
+		assert.Nil(t, logs[0].User)
+		assert.Equal(t, AuditImpersonatedRequest, logs[1].Action)
+		assert.EqualValues(t, 2, logs[1].User.ID)
+		assert.Equal(t, "/user2/repo1/issues/new", logs[1].Path)
+	}
+
+	logs, err = GetAuditLogs(2, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, logs, 1) {
+		assert.Equal(t, AuditImpersonationStart, logs[0].Action)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/httplib"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// checkAvatarAddress returns an error if avatars must not be fetched from
+// given IP address of given host. The avatar proxy fetches avatars from hosts
+// found in the DNS records of the email domains, so only the configured
+// avatar source may have a private address.
+func checkAvatarAddress(host string, ip net.IP) error {
+	if !isPrivateIP(ip) {
+		return nil
+	}
+	if source, err := url.Parse(setting.GravatarSource); err == nil && strings.EqualFold(source.Hostname(), host) {
+		return nil
+	}
+	return ErrAvatarAddressDenied{host, ip}
+}
+
+// checkAvatarURL resolves the host of given URL and checks all its addresses.
+func checkAvatarURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	_, err = resolveHost(u.Hostname(), checkAvatarAddress)
+	return err
+}
+
+// NewAvatarProxyClient returns a new client to fetch avatars with for the
+// avatar proxy, which does not connect to private addresses.
+func NewAvatarProxyClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &checkedTransport{
+			direct: &http.Transport{
+				Dial: checkedDialer(timeout, checkAvatarAddress),
+			},
+			proxy: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				Dial:  httplib.TimeoutDialer(timeout, timeout),
+			},
+			checkURL: checkAvatarURL,
+		},
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAvatarAddress(t *testing.T) {
+	defer func(source string) {
+		setting.GravatarSource = source
+	}(setting.GravatarSource)
+
+	setting.GravatarSource = "https://avatars.internal/avatar/"
+	assert.NoError(t, checkAvatarAddress("example.com", net.ParseIP("8.8.8.8")))
+	assert.NoError(t, checkAvatarAddress("avatars.internal", net.ParseIP("10.0.0.1")))
+	err := checkAvatarAddress("evil.example.com", net.ParseIP("169.254.169.254"))
+	assert.True(t, IsErrAvatarAddressDenied(err))
+}
+
+func TestNewAvatarProxyClient(t *testing.T) {
+	defer func(source string) {
+		setting.GravatarSource = source
+	}(setting.GravatarSource)
+
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer internal.Close()
+	redirect := httptest.NewServer(http.RedirectHandler(internal.URL, http.StatusFound))
+	defer redirect.Close()
+
+	client := NewAvatarProxyClient(time.Second)
+	setting.GravatarSource = "https://secure.gravatar.com/avatar/"
+	resp, err := client.Get(internal.URL)
+	assert.Error(t, err)
+	if err == nil {
+		resp.Body.Close()
+	}
+
+	// Redirects from the configured source are checked as well.
+	setting.GravatarSource = "http://localhost/avatar/"
+	resp, err = client.Get(strings.Replace(redirect.URL, "127.0.0.1", "localhost", 1))
+	assert.Error(t, err)
+	if err == nil {
+		resp.Body.Close()
+	}
+
+	setting.GravatarSource = "http://127.0.0.1/avatar/"
+	resp, err = client.Get(internal.URL)
+	assert.NoError(t, err)
+	if err == nil {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"time"
+
+	"github.com/go-xorm/xorm"
+)
+
+// BannerLevel represents the severity of a banner.
+type BannerLevel int
+
+// Enumerate all the banner levels
+const (
+	BannerInfo BannerLevel = iota + 1
+	BannerWarning
+	BannerError
+)
+
+// Class returns the CSS class of the message rendering a banner of the level.
+func (level BannerLevel) Class() string {
+	switch level {
+	case BannerWarning:
+		return "warning"
+	case BannerError:
+		return "negative"
+	}
+	return "info"
+}
+
+// Banner represents a message shown at the top of all pages, either instance
+// wide (OwnerID is 0) or in repositories of an organization.
+type Banner struct {
+	ID          int64  `xorm:"pk autoincr"`
+	OwnerID     int64  `xorm:"UNIQUE"`
+	Message     string `xorm:"TEXT NOT NULL"`
+	Level       BannerLevel
+	Expires     time.Time `xorm:"-"`
+	ExpiresUnix int64     `xorm:"INDEX"`
+	Created     time.Time `xorm:"-"`
+	CreatedUnix int64
+	Updated     time.Time `xorm:"-"`
+	UpdatedUnix int64
+}
+
+// BeforeInsert will be invoked by XORM before inserting a record
+func (b *Banner) BeforeInsert() {
+	b.CreatedUnix = time.Now().Unix()
+	b.UpdatedUnix = b.CreatedUnix
+}
+
+// BeforeUpdate is invoked from XORM before updating this object.
+func (b *Banner) BeforeUpdate() {
+	b.UpdatedUnix = time.Now().Unix()
+}
+
+// AfterSet is invoked from XORM after setting the value of a field of this object.
+func (b *Banner) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "expires_unix":
+		b.Expires = time.Unix(b.ExpiresUnix, 0).Local()
+	case "created_unix":
+		b.Created = time.Unix(b.CreatedUnix, 0).Local()
+	case "updated_unix":
+		b.Updated = time.Unix(b.UpdatedUnix, 0).Local()
+	}
+}
+
+// HasExpiry returns true if the banner is only shown until a given time.
+func (b *Banner) HasExpiry() bool {
+	return b.ExpiresUnix > 0
+}
+
+// IsExpired returns true if the banner is no longer shown.
+func (b *Banner) IsExpired() bool {
+	return b.HasExpiry() && b.ExpiresUnix <= time.Now().Unix()
+}
+
+// GetBanner returns the banner of given owner.
+func GetBanner(ownerID int64) (*Banner, error) {
+	b := new(Banner)
+	has, err := x.
+		Where("owner_id = ?", ownerID).
+		Get(b)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrBannerNotExist{ownerID}
+	}
+	return b, nil
+}
+
+// GetActiveBanners returns the instance wide banner followed by the banner
+// of given owner, leaving out those which have expired.
+func GetActiveBanners(ownerID int64) ([]*Banner, error) {
+	banners := make([]*Banner, 0, 2)
+	return banners, x.
+		In("owner_id", []int64{0, ownerID}).
+		And("(expires_unix = 0 OR expires_unix > ?)", time.Now().Unix()).
+		Asc("owner_id").
+		Find(&banners)
+}
+
+// UpdateBanner creates or updates the banner of its owner.
+func UpdateBanner(b *Banner) error {
+	if b.Level < BannerInfo || b.Level > BannerError {
+		b.Level = BannerInfo
+	}
+
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	has, err := sess.
+		Where("owner_id = ?", b.OwnerID).
+		Cols("id").
+		Get(new(Banner))
+	if err != nil {
+		return err
+	} else if has {
+		_, err = sess.
+			Where("owner_id = ?", b.OwnerID).
+			Cols("message", "level", "expires_unix", "updated_unix").
+			Update(b)
+	} else {
+		_, err = sess.Insert(b)
+	}
+	if err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// DeleteBanner deletes the banner of given owner.
+func DeleteBanner(ownerID int64) error {
+	_, err := x.
+		Where("owner_id = ?", ownerID).
+		Delete(new(Banner))
+	return err
+}
@@ -0,0 +1,68 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBanner(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	banner, err := GetBanner(0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, banner.ID)
+	assert.False(t, banner.IsExpired())
+
+	banner, err = GetBanner(6)
+	assert.NoError(t, err)
+	assert.True(t, banner.IsExpired())
+
+	_, err = GetBanner(7)
+	assert.True(t, IsErrBannerNotExist(err))
+}
+
+func TestGetActiveBanners(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	banners, err := GetActiveBanners(0)
+	assert.NoError(t, err)
+	if assert.Len(t, banners, 1) {
+		assert.EqualValues(t, 1, banners[0].ID)
+	}
+
+	banners, err = GetActiveBanners(3)
+	assert.NoError(t, err)
+	if assert.Len(t, banners, 2) {
+		assert.EqualValues(t, 1, banners[0].ID)
+		assert.EqualValues(t, 2, banners[1].ID)
+		assert.Equal(t, "warning", banners[1].Level.Class())
+	}
+
+	banners, err = GetActiveBanners(6)
+	assert.NoError(t, err)
+	assert.Len(t, banners, 1)
+}
+
+func TestUpdateBanner(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	assert.NoError(t, UpdateBanner(&Banner{OwnerID: 7, Message: "new banner"}))
+	AssertExistsAndLoadBean(t, &Banner{OwnerID: 7, Message: "new banner", Level: BannerInfo})
+
+	expires := time.Now().Add(time.Hour).Unix()
+	assert.NoError(t, UpdateBanner(&Banner{OwnerID: 6, Message: "renewed", Level: BannerError, ExpiresUnix: expires}))
+	AssertExistsAndLoadBean(t, &Banner{ID: 3, OwnerID: 6, Message: "renewed", ExpiresUnix: expires})
+	banners, err := GetActiveBanners(6)
+	assert.NoError(t, err)
+	assert.Len(t, banners, 2)
+}
+
+func TestDeleteBanner(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	assert.NoError(t, DeleteBanner(0))
+	AssertNotExistsBean(t, &Banner{ID: 1})
+	AssertExistsAndLoadBean(t, &Banner{ID: 2})
+}
@@ -0,0 +1,563 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-xorm/xorm"
+)
+
+// defaultBoardColumns are the columns a new board starts with,
+// the last one receives the issues which get closed.
+var defaultBoardColumns = []string{"To do", "In progress", "Done"}
+
+// Board represents an issue board of a repository or an organization.
+// Boards of an organization can hold issues of all its repositories.
+type Board struct {
+	ID          int64  `xorm:"pk autoincr"`
+	RepoID      int64  `xorm:"INDEX"`
+	OrgID       int64  `xorm:"INDEX"`
+	Title       string `xorm:"NOT NULL"`
+	Description string `xorm:"TEXT"`
+	CreatorID   int64
+
+	Columns []*BoardColumn `xorm:"-"`
+
+	Created     time.Time `xorm:"-"`
+	CreatedUnix int64     `xorm:"INDEX created"`
+	Updated     time.Time `xorm:"-"`
+	UpdatedUnix int64     `xorm:"INDEX updated"`
+}
+
+// AfterSet is invoked from XORM after setting the value of a field of this object.
+func (b *Board) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		b.Created = time.Unix(b.CreatedUnix, 0).Local()
+	case "updated_unix":
+		b.Updated = time.Unix(b.UpdatedUnix, 0).Local()
+	}
+}
+
+// BoardColumn represents a column of an issue board.
+type BoardColumn struct {
+	ID      int64  `xorm:"pk autoincr"`
+	BoardID int64  `xorm:"INDEX NOT NULL"`
+	Title   string `xorm:"NOT NULL"`
+	Sorting int    `xorm:"NOT NULL DEFAULT 0"`
+	// IsClosed marks the column which issues are moved to when they get closed.
+	IsClosed bool `xorm:"NOT NULL DEFAULT false"`
+
+	Cards []*BoardIssue `xorm:"-"`
+}
+
+// BoardIssue represents an issue placed as a card on an issue board.
+type BoardIssue struct {
+	ID       int64  `xorm:"pk autoincr"`
+	BoardID  int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	ColumnID int64  `xorm:"INDEX NOT NULL"`
+	IssueID  int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Sorting  int    `xorm:"NOT NULL DEFAULT 0"`
+	Issue    *Issue `xorm:"-"`
+}
+
+// NewBoard creates a new issue board with the default columns.
+func NewBoard(b *Board) (err error) {
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err = sess.Insert(b); err != nil {
+		return err
+	}
+	for i, title := range defaultBoardColumns {
+		if _, err = sess.Insert(&BoardColumn{
+			BoardID:  b.ID,
+			Title:    title,
+			Sorting:  i,
+			IsClosed: i == len(defaultBoardColumns)-1,
+		}); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+func getBoard(e Engine, id, repoID, orgID int64) (*Board, error) {
+	b := new(Board)
+	has, err := e.
+		Where("id = ? AND repo_id = ? AND org_id = ?", id, repoID, orgID).
+		Get(b)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrBoardNotExist{id, repoID, orgID}
+	}
+	return b, nil
+}
+
+// GetBoardByRepoID returns the issue board of given ID in a repository.
+func GetBoardByRepoID(repoID, id int64) (*Board, error) {
+	return getBoard(x, id, repoID, 0)
+}
+
+// GetBoardByOrgID returns the issue board of given ID in an organization.
+func GetBoardByOrgID(orgID, id int64) (*Board, error) {
+	return getBoard(x, id, 0, orgID)
+}
+
+func getBoards(repoID, orgID int64) ([]*Board, error) {
+	boards := make([]*Board, 0, 5)
+	return boards, x.
+		Where("repo_id = ? AND org_id = ?", repoID, orgID).
+		Asc("title").
+		Find(&boards)
+}
+
+// GetBoardsByRepoID returns all issue boards of a repository.
+func GetBoardsByRepoID(repoID int64) ([]*Board, error) {
+	return getBoards(repoID, 0)
+}
+
+// GetBoardsByOrgID returns all issue boards of an organization.
+func GetBoardsByOrgID(orgID int64) ([]*Board, error) {
+	return getBoards(0, orgID)
+}
+
+// UpdateBoard updates the title and description of an issue board.
+func UpdateBoard(b *Board) error {
+	_, err := x.Id(b.ID).Cols("title", "description").Update(b)
+	return err
+}
+
+func deleteBoards(e Engine, boardIDs []int64) error {
+	if len(boardIDs) == 0 {
+		return nil
+	}
+	if _, err := e.In("board_id", boardIDs).Delete(new(BoardIssue)); err != nil {
+		return err
+	}
+	if _, err := e.In("board_id", boardIDs).Delete(new(BoardColumn)); err != nil {
+		return err
+	}
+	_, err := e.In("id", boardIDs).Delete(new(Board))
+	return err
+}
+
+// deleteRepoBoards deletes the boards of a repository and removes
+// its issues from the boards of its owner.
+func deleteRepoBoards(e Engine, repoID int64, issueIDs []int64) error {
+	boardIDs := make([]int64, 0, 5)
+	if err := e.
+		Table("board").
+		Cols("id").
+		Where("repo_id = ?", repoID).
+		Find(&boardIDs); err != nil {
+		return err
+	}
+	if err := deleteBoards(e, boardIDs); err != nil {
+		return err
+	}
+	if len(issueIDs) > 0 {
+		if _, err := e.In("issue_id", issueIDs).Delete(new(BoardIssue)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteOrgBoards deletes the boards of an organization.
+func deleteOrgBoards(e Engine, orgID int64) error {
+	boardIDs := make([]int64, 0, 5)
+	if err := e.
+		Table("board").
+		Cols("id").
+		Where("org_id = ?", orgID).
+		Find(&boardIDs); err != nil {
+		return err
+	}
+	return deleteBoards(e, boardIDs)
+}
+
+// DeleteBoard deletes an issue board with its columns and cards.
+func DeleteBoard(b *Board) (err error) {
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if err = deleteBoards(sess, []int64{b.ID}); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func (b *Board) getColumns(e Engine) ([]*BoardColumn, error) {
+	columns := make([]*BoardColumn, 0, len(defaultBoardColumns))
+	return columns, e.
+		Where("board_id = ?", b.ID).
+		Asc("sorting", "id").
+		Find(&columns)
+}
+
+// LoadColumns loads the columns of the board with their cards and issues.
+func (b *Board) LoadColumns() (err error) {
+	if b.Columns, err = b.getColumns(x); err != nil {
+		return fmt.Errorf("getColumns: %v", err)
+	}
+
+	cards := make([]*BoardIssue, 0, 20)
+	if err = x.
+		Where("board_id = ?", b.ID).
+		Asc("sorting", "id").
+		Find(&cards); err != nil {
+		return fmt.Errorf("find cards: %v", err)
+	}
+
+	issueIDs := make([]int64, 0, len(cards))
+	for _, card := range cards {
+		issueIDs = append(issueIDs, card.IssueID)
+	}
+	issues, err := getIssuesByIDs(x, issueIDs)
+	if err != nil {
+		return fmt.Errorf("getIssuesByIDs: %v", err)
+	}
+	if _, err = IssueList(issues).loadRepositories(x); err != nil {
+		return fmt.Errorf("loadRepositories: %v", err)
+	}
+	issueMap := make(map[int64]*Issue, len(issues))
+	for _, issue := range issues {
+		issueMap[issue.ID] = issue
+	}
+
+	columnMap := make(map[int64]*BoardColumn, len(b.Columns))
+	for _, column := range b.Columns {
+		columnMap[column.ID] = column
+	}
+	for _, card := range cards {
+		column, ok := columnMap[card.ColumnID]
+		if !ok {
+			continue
+		}
+		if card.Issue = issueMap[card.IssueID]; card.Issue != nil {
+			column.Cards = append(column.Cards, card)
+		}
+	}
+	return nil
+}
+
+// CanHoldIssue returns an error if the issue may not be placed on the board,
+// i.e. it belongs neither to the repository of the board nor to a repository
+// of its organization.
+func (b *Board) CanHoldIssue(issue *Issue) error {
+	if err := issue.loadRepo(x); err != nil {
+		return err
+	}
+	if (b.RepoID > 0 && issue.RepoID != b.RepoID) ||
+		(b.OrgID > 0 && issue.Repo.OwnerID != b.OrgID) {
+		return ErrBoardIssueNotAllowed{b.ID, issue.ID}
+	}
+	return nil
+}
+
+func getBoardColumn(e Engine, boardID, id int64) (*BoardColumn, error) {
+	c := new(BoardColumn)
+	has, err := e.
+		Where("id = ? AND board_id = ?", id, boardID).
+		Get(c)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrBoardColumnNotExist{id, boardID}
+	}
+	return c, nil
+}
+
+// GetColumn returns the column of given ID on the board.
+func (b *Board) GetColumn(id int64) (*BoardColumn, error) {
+	return getBoardColumn(x, b.ID, id)
+}
+
+// NewBoardColumn adds a new column at the end of a board.
+func NewBoardColumn(c *BoardColumn) (err error) {
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if c.IsClosed {
+		if _, err = sess.
+			Where("board_id = ?", c.BoardID).
+			Cols("is_closed").
+			Update(&BoardColumn{IsClosed: false}); err != nil {
+			return err
+		}
+	}
+
+	last := new(BoardColumn)
+	has, err := sess.Where("board_id = ?", c.BoardID).Desc("sorting").Get(last)
+	if err != nil {
+		return err
+	} else if has {
+		c.Sorting = last.Sorting + 1
+	}
+	if _, err = sess.Insert(c); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// UpdateBoardColumn updates the title of a column and whether it
+// receives closed issues, which at most one column of a board does.
+func UpdateBoardColumn(c *BoardColumn) (err error) {
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if c.IsClosed {
+		if _, err = sess.
+			Where("board_id = ? AND id != ?", c.BoardID, c.ID).
+			Cols("is_closed").
+			Update(&BoardColumn{IsClosed: false}); err != nil {
+			return err
+		}
+	}
+	if _, err = sess.Id(c.ID).Cols("title", "is_closed").Update(c); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// DeleteBoardColumn deletes a column of a board, its cards are moved
+// to the end of the first remaining column. The last column of a board
+// cannot be deleted.
+func DeleteBoardColumn(c *BoardColumn) (err error) {
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	columns, err := (&Board{ID: c.BoardID}).getColumns(sess)
+	if err != nil {
+		return err
+	} else if len(columns) <= 1 {
+		return ErrBoardLastColumn{c.BoardID}
+	}
+
+	var target *BoardColumn
+	for _, column := range columns {
+		if column.ID != c.ID {
+			target = column
+			break
+		}
+	}
+	if err = moveBoardIssuesToColumn(sess, c, target); err != nil {
+		return err
+	}
+	if _, err = sess.Id(c.ID).Delete(new(BoardColumn)); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// nextBoardIssueSorting returns the sorting which places a card at the end of given column.
+func nextBoardIssueSorting(e Engine, columnID int64) (int, error) {
+	last := new(BoardIssue)
+	has, err := e.Where("column_id = ?", columnID).Desc("sorting").Get(last)
+	if err != nil {
+		return 0, err
+	} else if !has {
+		return 0, nil
+	}
+	return last.Sorting + 1, nil
+}
+
+// moveBoardIssuesToColumn moves all cards of a column to the end of another one.
+func moveBoardIssuesToColumn(e Engine, from, to *BoardColumn) error {
+	sorting, err := nextBoardIssueSorting(e, to.ID)
+	if err != nil {
+		return err
+	}
+	_, err = e.Exec("UPDATE `board_issue` SET column_id = ?, sorting = sorting + ? WHERE column_id = ?",
+		to.ID, sorting, from.ID)
+	return err
+}
+
+// SortColumns orders the columns of the board as given by their IDs,
+// unknown IDs are ignored.
+func (b *Board) SortColumns(columnIDs []int64) (err error) {
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	for i, id := range columnIDs {
+		if _, err = sess.
+			Where("id = ? AND board_id = ?", id, b.ID).
+			Cols("sorting").
+			Update(&BoardColumn{Sorting: i}); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+func (b *Board) getFirstColumn(e Engine, closed bool) (*BoardColumn, error) {
+	columns, err := b.getColumns(e)
+	if err != nil {
+		return nil, err
+	} else if len(columns) == 0 {
+		return nil, ErrBoardColumnNotExist{0, b.ID}
+	}
+	if closed {
+		for _, column := range columns {
+			if column.IsClosed {
+				return column, nil
+			}
+		}
+	}
+	for _, column := range columns {
+		if !column.IsClosed {
+			return column, nil
+		}
+	}
+	return columns[0], nil
+}
+
+// AddIssue places the issue at the end of the first column of the board,
+// or of the column of closed issues if it is closed. Nothing is done if
+// the issue is already on the board.
+func (b *Board) AddIssue(issue *Issue) (err error) {
+	if err = b.CanHoldIssue(issue); err != nil {
+		return err
+	}
+
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	has, err := sess.
+		Where("board_id = ? AND issue_id = ?", b.ID, issue.ID).
+		Get(new(BoardIssue))
+	if err != nil {
+		return err
+	} else if has {
+		return nil
+	}
+
+	column, err := b.getFirstColumn(sess, issue.IsClosed)
+	if err != nil {
+		return err
+	}
+	sorting, err := nextBoardIssueSorting(sess, column.ID)
+	if err != nil {
+		return err
+	}
+	if _, err = sess.Insert(&BoardIssue{
+		BoardID:  b.ID,
+		ColumnID: column.ID,
+		IssueID:  issue.ID,
+		Sorting:  sorting,
+	}); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// MoveIssue moves the card of an issue to given column, and orders the
+// cards of that column as given by the IDs of their issues.
+func (b *Board) MoveIssue(issueID, columnID int64, issueIDs []int64) (err error) {
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err = getBoardColumn(sess, b.ID, columnID); err != nil {
+		return err
+	}
+	cnt, err := sess.
+		Where("board_id = ? AND issue_id = ?", b.ID, issueID).
+		Cols("column_id").
+		Update(&BoardIssue{ColumnID: columnID})
+	if err != nil {
+		return err
+	} else if cnt == 0 {
+		return ErrBoardIssueNotExist{b.ID, issueID}
+	}
+
+	for i, id := range issueIDs {
+		if _, err = sess.
+			Where("board_id = ? AND column_id = ? AND issue_id = ?", b.ID, columnID, id).
+			Cols("sorting").
+			Update(&BoardIssue{Sorting: i}); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+// RemoveIssue removes the card of an issue from the board.
+func (b *Board) RemoveIssue(issueID int64) error {
+	_, err := x.
+		Where("board_id = ? AND issue_id = ?", b.ID, issueID).
+		Delete(new(BoardIssue))
+	return err
+}
+
+// moveIssueOnBoards moves the cards of an issue which just got closed to the
+// column of closed issues of their boards, and the ones of a reopened issue
+// out of it to the first column.
+func moveIssueOnBoards(e Engine, issue *Issue) error {
+	cards := make([]*BoardIssue, 0, 2)
+	if err := e.
+		Where("issue_id = ?", issue.ID).
+		Find(&cards); err != nil {
+		return err
+	}
+
+	for _, card := range cards {
+		column, err := getBoardColumn(e, card.BoardID, card.ColumnID)
+		if err != nil && !IsErrBoardColumnNotExist(err) {
+			return err
+		} else if err == nil && column.IsClosed == issue.IsClosed {
+			continue
+		}
+
+		target, err := (&Board{ID: card.BoardID}).getFirstColumn(e, issue.IsClosed)
+		if err != nil {
+			if IsErrBoardColumnNotExist(err) {
+				continue
+			}
+			return err
+		} else if target.IsClosed != issue.IsClosed {
+			// The board has no column for the new state.
+			continue
+		}
+
+		sorting, err := nextBoardIssueSorting(e, target.ID)
+		if err != nil {
+			return err
+		}
+		card.ColumnID = target.ID
+		card.Sorting = sorting
+		if _, err = e.Id(card.ID).Cols("column_id", "sorting").Update(card); err != nil {
+			return err
+		}
+	}
+	return nil
+}
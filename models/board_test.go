@@ -0,0 +1,143 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBoard(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	board := &Board{RepoID: 1, Title: "newBoard", CreatorID: 2}
+	assert.NoError(t, NewBoard(board))
+	AssertExistsAndLoadBean(t, &Board{ID: board.ID, RepoID: 1})
+
+	assert.NoError(t, board.LoadColumns())
+	if assert.Len(t, board.Columns, len(defaultBoardColumns)) {
+		assert.False(t, board.Columns[0].IsClosed)
+		assert.True(t, board.Columns[len(defaultBoardColumns)-1].IsClosed)
+	}
+}
+
+func TestGetBoardByRepoID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	board, err := GetBoardByRepoID(1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "board1", board.Title)
+
+	_, err = GetBoardByRepoID(2, 1)
+	assert.True(t, IsErrBoardNotExist(err))
+	_, err = GetBoardByOrgID(3, 1)
+	assert.True(t, IsErrBoardNotExist(err))
+
+	board, err = GetBoardByOrgID(3, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "board2", board.Title)
+}
+
+func TestBoard_LoadColumns(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	board := AssertExistsAndLoadBean(t, &Board{ID: 1}).(*Board)
+	assert.NoError(t, board.LoadColumns())
+	if assert.Len(t, board.Columns, 3) && assert.Len(t, board.Columns[0].Cards, 2) {
+		assert.EqualValues(t, 1, board.Columns[0].Cards[0].Issue.ID)
+		assert.EqualValues(t, 2, board.Columns[0].Cards[1].Issue.ID)
+		assert.NotNil(t, board.Columns[0].Cards[0].Issue.Repo)
+	}
+}
+
+func TestBoard_AddIssue(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	board := AssertExistsAndLoadBean(t, &Board{ID: 1}).(*Board)
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 3}).(*Issue)
+	assert.NoError(t, board.AddIssue(issue))
+	AssertExistsAndLoadBean(t, &BoardIssue{BoardID: 1, ColumnID: 1, IssueID: 3, Sorting: 2})
+
+	// Closed issues are placed in the column of closed issues.
+	issue = AssertExistsAndLoadBean(t, &Issue{ID: 5}).(*Issue)
+	assert.NoError(t, board.AddIssue(issue))
+	AssertExistsAndLoadBean(t, &BoardIssue{BoardID: 1, ColumnID: 3, IssueID: 5})
+
+	issue = AssertExistsAndLoadBean(t, &Issue{ID: 4}).(*Issue)
+	assert.True(t, IsErrBoardIssueNotAllowed(board.AddIssue(issue)))
+
+	// Organization boards hold issues of the repositories of the organization.
+	orgBoard := AssertExistsAndLoadBean(t, &Board{ID: 2}).(*Board)
+	assert.True(t, IsErrBoardIssueNotAllowed(orgBoard.AddIssue(issue)))
+}
+
+func TestBoard_MoveIssue(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	board := AssertExistsAndLoadBean(t, &Board{ID: 1}).(*Board)
+
+	assert.NoError(t, board.MoveIssue(2, 2, []int64{2}))
+	AssertExistsAndLoadBean(t, &BoardIssue{BoardID: 1, ColumnID: 2, IssueID: 2, Sorting: 0})
+
+	assert.NoError(t, board.MoveIssue(1, 2, []int64{1, 2}))
+	AssertExistsAndLoadBean(t, &BoardIssue{BoardID: 1, ColumnID: 2, IssueID: 1, Sorting: 0})
+	AssertExistsAndLoadBean(t, &BoardIssue{BoardID: 1, ColumnID: 2, IssueID: 2, Sorting: 1})
+
+	assert.True(t, IsErrBoardColumnNotExist(board.MoveIssue(1, 4, nil)))
+	assert.True(t, IsErrBoardIssueNotExist(board.MoveIssue(3, 2, nil)))
+}
+
+func TestBoard_SortColumns(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	board := AssertExistsAndLoadBean(t, &Board{ID: 1}).(*Board)
+	assert.NoError(t, board.SortColumns([]int64{3, 1, 2, 4}))
+	AssertExistsAndLoadBean(t, &BoardColumn{ID: 3, Sorting: 0})
+	AssertExistsAndLoadBean(t, &BoardColumn{ID: 1, Sorting: 1})
+	AssertExistsAndLoadBean(t, &BoardColumn{ID: 2, Sorting: 2})
+	// Columns of other boards are left alone.
+	AssertExistsAndLoadBean(t, &BoardColumn{ID: 4, BoardID: 2})
+}
+
+func TestNewBoardColumn(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	column := &BoardColumn{BoardID: 1, Title: "Released", IsClosed: true}
+	assert.NoError(t, NewBoardColumn(column))
+	assert.Equal(t, 3, column.Sorting)
+	AssertExistsAndLoadBean(t, &BoardColumn{ID: column.ID, IsClosed: true})
+
+	column = AssertExistsAndLoadBean(t, &BoardColumn{ID: 3}).(*BoardColumn)
+	assert.False(t, column.IsClosed)
+}
+
+func TestDeleteBoardColumn(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	column := AssertExistsAndLoadBean(t, &BoardColumn{ID: 1}).(*BoardColumn)
+	assert.NoError(t, DeleteBoardColumn(column))
+	AssertNotExistsBean(t, &BoardColumn{ID: 1})
+	AssertExistsAndLoadBean(t, &BoardIssue{IssueID: 1, ColumnID: 2})
+	AssertExistsAndLoadBean(t, &BoardIssue{IssueID: 2, ColumnID: 2})
+
+	column = AssertExistsAndLoadBean(t, &BoardColumn{ID: 4}).(*BoardColumn)
+	assert.True(t, IsErrBoardLastColumn(DeleteBoardColumn(column)))
+}
+
+func TestDeleteBoard(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	board := AssertExistsAndLoadBean(t, &Board{ID: 1}).(*Board)
+	assert.NoError(t, DeleteBoard(board))
+	AssertNotExistsBean(t, &Board{ID: 1})
+	AssertNotExistsBean(t, &BoardColumn{BoardID: 1})
+	AssertNotExistsBean(t, &BoardIssue{BoardID: 1})
+}
+
+func TestIssue_ChangeStatus_Board(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.NoError(t, issue.LoadAttributes())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	assert.NoError(t, issue.ChangeStatus(doer, issue.Repo, true))
+	AssertExistsAndLoadBean(t, &BoardIssue{IssueID: 1, ColumnID: 3})
+
+	assert.NoError(t, issue.ChangeStatus(doer, issue.Repo, false))
+	AssertExistsAndLoadBean(t, &BoardIssue{IssueID: 1, ColumnID: 1})
+}
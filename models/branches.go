@@ -25,6 +25,11 @@ type ProtectedBranch struct {
 	CreatedUnix int64
 	Updated     time.Time `xorm:"-"`
 	UpdatedUnix int64
+
+	// RequiredApprovals is the number of approvals a pull request needs to be
+	// merged into the branch, the approval of its poster does not count.
+	RequiredApprovals     int64 `xorm:"NOT NULL DEFAULT 0"`
+	DismissStaleApprovals bool  `xorm:"NOT NULL DEFAULT false"`
 }
 
 // BeforeInsert before protected branch insert create and update time
@@ -138,6 +143,19 @@ func (repo *Repository) ChangeProtectedBranch(id int64, canPush bool) error {
 	return sess.Commit()
 }
 
+// ChangeProtectedBranchApprovals changes the approvals pull requests into the
+// protected branch need to be merged.
+func (repo *Repository) ChangeProtectedBranchApprovals(id, requiredApprovals int64, dismissStaleApprovals bool) error {
+	_, err := x.
+		Where("id = ? AND repo_id = ?", id, repo.ID).
+		Cols("required_approvals", "dismiss_stale_approvals").
+		Update(&ProtectedBranch{
+			RequiredApprovals:     requiredApprovals,
+			DismissStaleApprovals: dismissStaleApprovals,
+		})
+	return err
+}
+
 // DeleteProtectedBranch removes ProtectedBranch relation between the user and repository.
 func (repo *Repository) DeleteProtectedBranch(id int64) (err error) {
 	protectedBranch := &ProtectedBranch{
@@ -69,7 +69,9 @@ func assertCount(t *testing.T, bean interface{}, expected int) {
 }
 
 func (user *User) checkForConsistency(t *testing.T) {
-	assertCount(t, &Repository{OwnerID: user.ID}, user.NumRepos)
+	actual := getCount(t, x.Where("deleted_unix = 0"), &Repository{OwnerID: user.ID})
+	assert.EqualValues(t, user.NumRepos, actual,
+		"Unexpected number of repositories for user %+v", user)
 	assertCount(t, &Star{UID: user.ID}, user.NumStars)
 	assertCount(t, &OrgUser{OrgID: user.ID}, user.NumMembers)
 	assertCount(t, &Team{OrgID: user.ID}, user.NumTeams)
@@ -86,7 +88,8 @@ func (repo *Repository) checkForConsistency(t *testing.T) {
 	assertCount(t, &Star{RepoID: repo.ID}, repo.NumStars)
 	assertCount(t, &Watch{RepoID: repo.ID}, repo.NumWatches)
 	assertCount(t, &Milestone{RepoID: repo.ID}, repo.NumMilestones)
-	assertCount(t, &Repository{ForkID: repo.ID}, repo.NumForks)
+	assert.EqualValues(t, repo.NumForks, getCount(t, x.Where("deleted_unix = 0"), &Repository{ForkID: repo.ID}),
+		"Unexpected number of forks for repo %+v", repo)
 	if repo.IsFork {
 		AssertExistsAndLoadBean(t, &Repository{ID: repo.ForkID})
 	}
@@ -0,0 +1,126 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"time"
+
+	"github.com/go-xorm/xorm"
+	gouuid "github.com/satori/go.uuid"
+
+	"code.gitea.io/gitea/modules/base"
+)
+
+// DeployToken represents a token which gives access to a single repository,
+// independent of any user account.
+type DeployToken struct {
+	ID        int64 `xorm:"pk autoincr"`
+	RepoID    int64 `xorm:"INDEX"`
+	CreatorID int64 `xorm:"INDEX"`
+	Name      string
+	Sha1      string     `xorm:"UNIQUE VARCHAR(40)"`
+	Mode      AccessMode `xorm:"NOT NULL DEFAULT 1"`
+
+	Created           time.Time `xorm:"-"`
+	CreatedUnix       int64     `xorm:"INDEX"`
+	Updated           time.Time `xorm:"-"` // Note: Updated must below Created for AfterSet.
+	UpdatedUnix       int64     `xorm:"INDEX"`
+	Expires           time.Time `xorm:"-"`
+	ExpiresUnix       int64     // Zero if the token never expires
+	HasRecentActivity bool      `xorm:"-"`
+	HasUsed           bool      `xorm:"-"`
+}
+
+// BeforeInsert will be invoked by XORM before inserting a record representing this object.
+func (t *DeployToken) BeforeInsert() {
+	t.CreatedUnix = time.Now().Unix()
+	t.UpdatedUnix = t.CreatedUnix
+}
+
+// BeforeUpdate is invoked from XORM before updating this object.
+func (t *DeployToken) BeforeUpdate() {
+	t.UpdatedUnix = time.Now().Unix()
+}
+
+// AfterSet is invoked from XORM after setting the value of a field of this object.
+func (t *DeployToken) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		t.Created = time.Unix(t.CreatedUnix, 0).Local()
+	case "updated_unix":
+		t.Updated = time.Unix(t.UpdatedUnix, 0).Local()
+		t.HasUsed = t.Updated.After(t.Created)
+		t.HasRecentActivity = t.Updated.Add(7 * 24 * time.Hour).After(time.Now())
+	case "expires_unix":
+		t.Expires = time.Unix(t.ExpiresUnix, 0).Local()
+	}
+}
+
+// IsWritable returns true if the token allows to push to the repository.
+func (t *DeployToken) IsWritable() bool {
+	return t.Mode >= AccessModeWrite
+}
+
+// IsExpired returns true if the token has an expiry date which has passed.
+func (t *DeployToken) IsExpired() bool {
+	return t.ExpiresUnix > 0 && t.ExpiresUnix <= time.Now().Unix()
+}
+
+// NewDeployToken creates new deploy token of a repository.
+func NewDeployToken(t *DeployToken) error {
+	t.Sha1 = base.EncodeSha1(gouuid.NewV4().String())
+	if t.Mode < AccessModeRead {
+		t.Mode = AccessModeRead
+	} else if t.Mode > AccessModeWrite {
+		t.Mode = AccessModeWrite
+	}
+	_, err := x.Insert(t)
+	return err
+}
+
+// GetDeployTokenBySHA returns the deploy token of given repository by its sha1.
+func GetDeployTokenBySHA(repoID int64, sha string) (*DeployToken, error) {
+	if len(sha) == 0 {
+		return nil, ErrAccessTokenEmpty{}
+	}
+	t := new(DeployToken)
+	has, err := x.
+		Where("repo_id = ? AND sha1 = ?", repoID, sha).
+		Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrDeployTokenNotExist{sha}
+	}
+	return t, nil
+}
+
+// ListDeployTokens returns all deploy tokens of given repository.
+func ListDeployTokens(repoID int64) ([]*DeployToken, error) {
+	tokens := make([]*DeployToken, 0, 5)
+	return tokens, x.
+		Where("repo_id = ?", repoID).
+		Desc("id").
+		Find(&tokens)
+}
+
+// UpdateDeployTokenUsed records that the deploy token has just been used.
+func UpdateDeployTokenUsed(t *DeployToken) error {
+	_, err := x.Id(t.ID).Cols("updated_unix").Update(t)
+	return err
+}
+
+// DeleteDeployToken deletes the deploy token of given repository by its ID.
+func DeleteDeployToken(repoID, id int64) error {
+	cnt, err := x.
+		Where("id = ? AND repo_id = ?", id, repoID).
+		Delete(new(DeployToken))
+	if err != nil {
+		return err
+	} else if cnt != 1 {
+		return ErrDeployTokenNotExist{}
+	}
+	return nil
+}
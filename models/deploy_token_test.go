@@ -0,0 +1,71 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDeployToken(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	token := &DeployToken{
+		RepoID:    1,
+		CreatorID: 2,
+		Name:      "ci",
+		Mode:      AccessModeAdmin,
+	}
+	assert.NoError(t, NewDeployToken(token))
+	assert.Len(t, token.Sha1, 40)
+	assert.Equal(t, AccessModeWrite, token.Mode)
+	assert.True(t, token.IsWritable())
+	assert.False(t, token.IsExpired())
+	AssertExistsAndLoadBean(t, &DeployToken{ID: token.ID, RepoID: 1, Sha1: token.Sha1})
+}
+
+func TestGetDeployTokenBySHA(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	token := &DeployToken{RepoID: 1, CreatorID: 2, Name: "read"}
+	assert.NoError(t, NewDeployToken(token))
+	assert.Equal(t, AccessModeRead, token.Mode)
+
+	found, err := GetDeployTokenBySHA(1, token.Sha1)
+	assert.NoError(t, err)
+	assert.Equal(t, token.ID, found.ID)
+	assert.False(t, found.HasUsed)
+
+	_, err = GetDeployTokenBySHA(2, token.Sha1)
+	assert.True(t, IsErrDeployTokenNotExist(err))
+
+	_, err = GetDeployTokenBySHA(1, "")
+	assert.True(t, IsErrAccessTokenEmpty(err))
+}
+
+func TestDeployToken_IsExpired(t *testing.T) {
+	token := &DeployToken{}
+	assert.False(t, token.IsExpired())
+	token.ExpiresUnix = time.Now().Add(-time.Minute).Unix()
+	assert.True(t, token.IsExpired())
+	token.ExpiresUnix = time.Now().Add(time.Hour).Unix()
+	assert.False(t, token.IsExpired())
+}
+
+func TestListAndDeleteDeployTokens(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	token := &DeployToken{RepoID: 1, CreatorID: 2, Name: "ci"}
+	assert.NoError(t, NewDeployToken(token))
+
+	tokens, err := ListDeployTokens(1)
+	assert.NoError(t, err)
+	if assert.Len(t, tokens, 1) {
+		assert.Equal(t, token.ID, tokens[0].ID)
+	}
+
+	assert.True(t, IsErrDeployTokenNotExist(DeleteDeployToken(2, token.ID)))
+	assert.NoError(t, DeleteDeployToken(1, token.ID))
+	AssertNotExistsBean(t, &DeployToken{ID: token.ID})
+}
@@ -0,0 +1,203 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/ini.v1"
+
+	"code.gitea.io/gitea/models/migrations"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// OrphanedRepositoriesDir is the directory in the repository root path
+// orphaned repositories are moved to, it cannot clash with a user name.
+const OrphanedRepositoriesDir = ".orphaned"
+
+// CheckDBVersion returns the current version of the database and the version
+// it has after all migrations.
+func CheckDBVersion() (current, expected int64, err error) {
+	current, err = migrations.CurrentVersion(x)
+	return current, migrations.ExpectedVersion(), err
+}
+
+// checkDelegateHooks returns the hook scripts of the repository which are
+// missing or differ from what createDelegateHooks writes.
+func checkDelegateHooks(repoPath string) ([]string, error) {
+	hookTpl, giteaHookTpls := delegateHookContents()
+	hookDir := filepath.Join(repoPath, "hooks")
+
+	var outdated []string
+	for i, hookName := range delegateHookNames {
+		for path, content := range map[string]string{
+			filepath.Join(hookDir, hookName):               hookTpl,
+			filepath.Join(hookDir, hookName+".d", "gitea"): giteaHookTpls[i],
+		} {
+			data, err := ioutil.ReadFile(path)
+			if err != nil && !os.IsNotExist(err) {
+				return nil, err
+			} else if string(data) != content {
+				outdated = append(outdated, path)
+			}
+		}
+	}
+	return outdated, nil
+}
+
+// CheckRepositoryHooks returns the hook scripts of all repositories which are
+// missing or outdated, they are rewritten by SyncRepositoryHooks.
+func CheckRepositoryHooks() ([]string, error) {
+	var outdated []string
+	err := x.Where("id > 0 AND deleted_unix = 0").Iterate(new(Repository),
+		func(idx int, bean interface{}) error {
+			repo := bean.(*Repository)
+			repoPaths := []string{repo.RepoPath()}
+			if repo.HasWiki() {
+				repoPaths = append(repoPaths, repo.WikiPath())
+			}
+
+			for _, repoPath := range repoPaths {
+				paths, err := checkDelegateHooks(repoPath)
+				if err != nil {
+					return fmt.Errorf("checkDelegateHooks: %v", err)
+				}
+				outdated = append(outdated, paths...)
+			}
+			return nil
+		})
+	return outdated, err
+}
+
+// FindOrphanedRepositories returns the paths of the repositories in the
+// repository root path which do not belong to any repository in the database.
+func FindOrphanedRepositories() ([]string, error) {
+	users := make([]*User, 0, 10)
+	if err := x.Cols("id", "lower_name").Find(&users); err != nil {
+		return nil, fmt.Errorf("find users: %v", err)
+	}
+	ownerNames := make(map[int64]string, len(users))
+	for _, u := range users {
+		ownerNames[u.ID] = u.LowerName
+	}
+
+	repos := make([]*Repository, 0, 10)
+	if err := x.Cols("owner_id", "lower_name").Find(&repos); err != nil {
+		return nil, fmt.Errorf("find repositories: %v", err)
+	}
+	repoNames := make(map[string]bool, len(repos))
+	for _, repo := range repos {
+		repoNames[ownerNames[repo.OwnerID]+"/"+repo.LowerName] = true
+	}
+
+	owners, err := ioutil.ReadDir(setting.RepoRootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var orphaned []string
+	for _, owner := range owners {
+		if !owner.IsDir() || owner.Name() == OrphanedRepositoriesDir {
+			continue
+		}
+
+		ownerPath := filepath.Join(setting.RepoRootPath, owner.Name())
+		dirs, err := ioutil.ReadDir(ownerPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range dirs {
+			if !dir.IsDir() || !strings.HasSuffix(dir.Name(), ".git") {
+				continue
+			}
+
+			name := strings.TrimSuffix(strings.TrimSuffix(dir.Name(), ".git"), ".wiki")
+			if !repoNames[strings.ToLower(owner.Name())+"/"+strings.ToLower(name)] {
+				orphaned = append(orphaned, filepath.Join(ownerPath, dir.Name()))
+			}
+		}
+	}
+	return orphaned, nil
+}
+
+// MoveOrphanedRepository moves an orphaned repository found by
+// FindOrphanedRepositories out of the way into OrphanedRepositoriesDir.
+func MoveOrphanedRepository(repoPath string) error {
+	relPath, err := filepath.Rel(setting.RepoRootPath, repoPath)
+	if err != nil {
+		return err
+	}
+
+	newPath := filepath.Join(setting.RepoRootPath, OrphanedRepositoriesDir, relPath)
+	if err = os.MkdirAll(filepath.Dir(newPath), os.ModePerm); err != nil {
+		return err
+	}
+	return os.Rename(repoPath, newPath)
+}
+
+// BrokenMirror describes a mirror whose remote cannot be synchronized.
+type BrokenMirror struct {
+	Mirror  *Mirror
+	Problem string
+	Fixable bool
+}
+
+// CheckMirrorRemotes returns the mirrors whose repository is missing or whose
+// remote is not configured to mirror in the Git config of the repository.
+func CheckMirrorRemotes() ([]*BrokenMirror, error) {
+	mirrors := make([]*Mirror, 0, 10)
+	if err := x.Find(&mirrors); err != nil {
+		return nil, err
+	}
+
+	var broken []*BrokenMirror
+	for _, m := range mirrors {
+		if m.Repo == nil {
+			broken = append(broken, &BrokenMirror{m, "repository does not exist", true})
+			continue
+		}
+
+		cfg, err := ini.Load(m.Repo.GitConfigPath())
+		if err != nil {
+			broken = append(broken, &BrokenMirror{m, fmt.Sprintf("cannot read Git config: %v", err), false})
+			continue
+		}
+		remote := cfg.Section("remote \"origin\"")
+		switch {
+		case len(remote.Key("url").Value()) == 0:
+			broken = append(broken, &BrokenMirror{m, "remote address is missing", false})
+		case remote.Key("mirror").Value() != "true" || remote.Key("fetch").Value() != "+refs/*:refs/*":
+			broken = append(broken, &BrokenMirror{m, "remote is not configured to mirror", true})
+		}
+	}
+	return broken, nil
+}
+
+// Fix deletes the mirror of a missing repository, or configures
+// the remote of the repository to mirror again.
+func (b *BrokenMirror) Fix() error {
+	if !b.Fixable {
+		return fmt.Errorf("mirror [%d] cannot be fixed: %s", b.Mirror.ID, b.Problem)
+	}
+
+	if b.Mirror.Repo == nil {
+		_, err := x.Id(b.Mirror.ID).Delete(new(Mirror))
+		return err
+	}
+
+	configPath := b.Mirror.Repo.GitConfigPath()
+	cfg, err := ini.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("Load: %v", err)
+	}
+	remote := cfg.Section("remote \"origin\"")
+	remote.Key("fetch").SetValue("+refs/*:refs/*")
+	remote.Key("mirror").SetValue("true")
+	return cfg.SaveToIndent(configPath, "\t")
+}
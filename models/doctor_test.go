@@ -0,0 +1,60 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+func TestFindOrphanedRepositories(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	root, err := ioutil.TempDir("", "orphaned-repos")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+	oldRoot := setting.RepoRootPath
+	setting.RepoRootPath = root
+	defer func() { setting.RepoRootPath = oldRoot }()
+
+	for _, dir := range []string{"user2/repo1.git", "user2/repo1.wiki.git", "user2/ghost.git", "ghost/repo1.git"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(root, dir), os.ModePerm))
+	}
+
+	orphaned, err := FindOrphanedRepositories()
+	assert.NoError(t, err)
+	assert.Len(t, orphaned, 2)
+	assert.Contains(t, orphaned, filepath.Join(root, "user2/ghost.git"))
+	assert.Contains(t, orphaned, filepath.Join(root, "ghost/repo1.git"))
+
+	assert.NoError(t, MoveOrphanedRepository(filepath.Join(root, "user2/ghost.git")))
+	_, err = os.Stat(filepath.Join(root, OrphanedRepositoriesDir, "user2/ghost.git"))
+	assert.NoError(t, err)
+
+	orphaned, err = FindOrphanedRepositories()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(root, "ghost/repo1.git")}, orphaned)
+}
+
+func TestCheckDelegateHooks(t *testing.T) {
+	repoPath, err := ioutil.TempDir("", "delegate-hooks")
+	assert.NoError(t, err)
+	defer os.RemoveAll(repoPath)
+
+	outdated, err := checkDelegateHooks(repoPath)
+	assert.NoError(t, err)
+	assert.Len(t, outdated, 2*len(delegateHookNames))
+
+	assert.NoError(t, createDelegateHooks(repoPath))
+	outdated, err = checkDelegateHooks(repoPath)
+	assert.NoError(t, err)
+	assert.Empty(t, outdated)
+}
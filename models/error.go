@@ -6,6 +6,9 @@ package models
 
 import (
 	"fmt"
+	"net"
+	"strings"
+	"time"
 )
 
 // ErrNameReserved represents a "reserved name" error.
@@ -78,6 +81,97 @@ func (err ErrUserNotExist) Error() string {
 	return fmt.Sprintf("user does not exist [uid: %d, name: %s, keyid: %d]", err.UID, err.Name, err.KeyID)
 }
 
+// ErrProfileFieldNotExist represents a "ProfileFieldNotExist" kind of error.
+type ErrProfileFieldNotExist struct {
+	ID int64
+}
+
+// IsErrProfileFieldNotExist checks if an error is a ErrProfileFieldNotExist.
+func IsErrProfileFieldNotExist(err error) bool {
+	_, ok := err.(ErrProfileFieldNotExist)
+	return ok
+}
+
+func (err ErrProfileFieldNotExist) Error() string {
+	return fmt.Sprintf("profile field does not exist [id: %d]", err.ID)
+}
+
+// ErrProfileFieldAlreadyExist represents a "ProfileFieldAlreadyExist" kind of error.
+type ErrProfileFieldAlreadyExist struct {
+	Name string
+}
+
+// IsErrProfileFieldAlreadyExist checks if an error is a ErrProfileFieldAlreadyExist.
+func IsErrProfileFieldAlreadyExist(err error) bool {
+	_, ok := err.(ErrProfileFieldAlreadyExist)
+	return ok
+}
+
+func (err ErrProfileFieldAlreadyExist) Error() string {
+	return fmt.Sprintf("profile field already exists [name: %s]", err.Name)
+}
+
+// ErrProfileFieldInvalidValue represents a "ProfileFieldInvalidValue" kind of error.
+type ErrProfileFieldInvalidValue struct {
+	Name  string
+	Value string
+}
+
+// IsErrProfileFieldInvalidValue checks if an error is a ErrProfileFieldInvalidValue.
+func IsErrProfileFieldInvalidValue(err error) bool {
+	_, ok := err.(ErrProfileFieldInvalidValue)
+	return ok
+}
+
+func (err ErrProfileFieldInvalidValue) Error() string {
+	return fmt.Sprintf("invalid profile field value [name: %s, value: %s]", err.Name, err.Value)
+}
+
+// ErrGitHookTemplateNotExist represents a "GitHookTemplateNotExist" kind of error.
+type ErrGitHookTemplateNotExist struct {
+	ID int64
+}
+
+// IsErrGitHookTemplateNotExist checks if an error is a ErrGitHookTemplateNotExist.
+func IsErrGitHookTemplateNotExist(err error) bool {
+	_, ok := err.(ErrGitHookTemplateNotExist)
+	return ok
+}
+
+func (err ErrGitHookTemplateNotExist) Error() string {
+	return fmt.Sprintf("Git hook template does not exist [id: %d]", err.ID)
+}
+
+// ErrGitHookTemplateAlreadyExist represents a "GitHookTemplateAlreadyExist" kind of error.
+type ErrGitHookTemplateAlreadyExist struct {
+	Name string
+}
+
+// IsErrGitHookTemplateAlreadyExist checks if an error is a ErrGitHookTemplateAlreadyExist.
+func IsErrGitHookTemplateAlreadyExist(err error) bool {
+	_, ok := err.(ErrGitHookTemplateAlreadyExist)
+	return ok
+}
+
+func (err ErrGitHookTemplateAlreadyExist) Error() string {
+	return fmt.Sprintf("Git hook template already exists [name: %s]", err.Name)
+}
+
+// ErrGitHookParameterInvalid represents a "GitHookParameterInvalid" kind of error.
+type ErrGitHookParameterInvalid struct {
+	Name string
+}
+
+// IsErrGitHookParameterInvalid checks if an error is a ErrGitHookParameterInvalid.
+func IsErrGitHookParameterInvalid(err error) bool {
+	_, ok := err.(ErrGitHookParameterInvalid)
+	return ok
+}
+
+func (err ErrGitHookParameterInvalid) Error() string {
+	return fmt.Sprintf("invalid Git hook parameter name [name: %s]", err.Name)
+}
+
 // ErrEmailAlreadyUsed represents a "EmailAlreadyUsed" kind of error.
 type ErrEmailAlreadyUsed struct {
 	Email string
@@ -93,6 +187,21 @@ func (err ErrEmailAlreadyUsed) Error() string {
 	return fmt.Sprintf("e-mail has been used [email: %s]", err.Email)
 }
 
+// ErrEmailDomainNotAllowed represents a "EmailDomainNotAllowed" kind of error.
+type ErrEmailDomainNotAllowed struct {
+	Email string
+}
+
+// IsErrEmailDomainNotAllowed checks if an error is a ErrEmailDomainNotAllowed.
+func IsErrEmailDomainNotAllowed(err error) bool {
+	_, ok := err.(ErrEmailDomainNotAllowed)
+	return ok
+}
+
+func (err ErrEmailDomainNotAllowed) Error() string {
+	return fmt.Sprintf("domain of e-mail is not allowed [email: %s]", err.Email)
+}
+
 // ErrOpenIDAlreadyUsed represents a "OpenIDAlreadyUsed" kind of error.
 type ErrOpenIDAlreadyUsed struct {
 	OpenID string
@@ -138,6 +247,38 @@ func (err ErrUserHasOrgs) Error() string {
 	return fmt.Sprintf("user still has membership of organizations [uid: %d]", err.UID)
 }
 
+// ErrUserLocked represents a "UserLocked" kind of error.
+type ErrUserLocked struct {
+	UID   int64
+	Until time.Time
+}
+
+// IsErrUserLocked checks if an error is a ErrUserLocked.
+func IsErrUserLocked(err error) bool {
+	_, ok := err.(ErrUserLocked)
+	return ok
+}
+
+func (err ErrUserLocked) Error() string {
+	return fmt.Sprintf("too many failed sign-in attempts [uid: %d, until: %v]", err.UID, err.Until)
+}
+
+// ErrUserSuspended represents a "UserSuspended" kind of error.
+type ErrUserSuspended struct {
+	UID    int64
+	Reason string
+}
+
+// IsErrUserSuspended checks if an error is a ErrUserSuspended.
+func IsErrUserSuspended(err error) bool {
+	_, ok := err.(ErrUserSuspended)
+	return ok
+}
+
+func (err ErrUserSuspended) Error() string {
+	return fmt.Sprintf("user is suspended [uid: %d, reason: %s]", err.UID, err.Reason)
+}
+
 // ErrUserNotAllowedCreateOrg represents a "UserNotAllowedCreateOrg" kind of error.
 type ErrUserNotAllowedCreateOrg struct {
 }
@@ -189,6 +330,21 @@ func (err ErrWikiAlreadyExist) Error() string {
 	return fmt.Sprintf("wiki page already exists [title: %s]", err.Title)
 }
 
+// ErrWikiPageNotExist represents a "WikiPageNotExist" kind of error.
+type ErrWikiPageNotExist struct {
+	URL string
+}
+
+// IsErrWikiPageNotExist checks if an error is a ErrWikiPageNotExist.
+func IsErrWikiPageNotExist(err error) bool {
+	_, ok := err.(ErrWikiPageNotExist)
+	return ok
+}
+
+func (err ErrWikiPageNotExist) Error() string {
+	return fmt.Sprintf("wiki page does not exist [url: %s]", err.URL)
+}
+
 // __________     ___.   .__  .__          ____  __.
 // \______   \__ _\_ |__ |  | |__| ____   |    |/ _|____ ___.__.
 //  |     ___/  |  \ __ \|  | |  |/ ___\  |      <_/ __ <   |  |
@@ -441,6 +597,21 @@ func (err ErrAccessTokenEmpty) Error() string {
 	return fmt.Sprintf("access token is empty")
 }
 
+// ErrDeployTokenNotExist represents a "DeployTokenNotExist" kind of error.
+type ErrDeployTokenNotExist struct {
+	SHA string
+}
+
+// IsErrDeployTokenNotExist checks if an error is a ErrDeployTokenNotExist.
+func IsErrDeployTokenNotExist(err error) bool {
+	_, ok := err.(ErrDeployTokenNotExist)
+	return ok
+}
+
+func (err ErrDeployTokenNotExist) Error() string {
+	return fmt.Sprintf("deploy token does not exist [sha: %s]", err.SHA)
+}
+
 // ________                            .__                __  .__
 // \_____  \_______  _________    ____ |__|____________ _/  |_|__| ____   ____
 //  /   |   \_  __ \/ ___\__  \  /    \|  \___   /\__  \\   __\  |/  _ \ /    \
@@ -463,6 +634,21 @@ func (err ErrLastOrgOwner) Error() string {
 	return fmt.Sprintf("user is the last member of owner team [uid: %d]", err.UID)
 }
 
+// ErrBannerNotExist represents a "BannerNotExist" kind of error.
+type ErrBannerNotExist struct {
+	OwnerID int64
+}
+
+// IsErrBannerNotExist checks if an error is a ErrBannerNotExist.
+func IsErrBannerNotExist(err error) bool {
+	_, ok := err.(ErrBannerNotExist)
+	return ok
+}
+
+func (err ErrBannerNotExist) Error() string {
+	return fmt.Sprintf("banner does not exist [owner_id: %d]", err.OwnerID)
+}
+
 // __________                           .__  __
 // \______   \ ____ ______   ____  _____|__|/  |_  ___________ ___.__.
 //  |       _// __ \\____ \ /  _ \/  ___/  \   __\/  _ \_  __ <   |  |
@@ -487,6 +673,22 @@ func (err ErrRepoNotExist) Error() string {
 	return fmt.Sprintf("repository does not exist [id: %d, uid: %d, name: %s]", err.ID, err.UID, err.Name)
 }
 
+// ErrRepoOwnerDeleted represents a "RepoOwnerDeleted" kind of error.
+type ErrRepoOwnerDeleted struct {
+	ID  int64
+	UID int64
+}
+
+// IsErrRepoOwnerDeleted checks if an error is a ErrRepoOwnerDeleted.
+func IsErrRepoOwnerDeleted(err error) bool {
+	_, ok := err.(ErrRepoOwnerDeleted)
+	return ok
+}
+
+func (err ErrRepoOwnerDeleted) Error() string {
+	return fmt.Sprintf("owner of repository is deleted [id: %d, uid: %d]", err.ID, err.UID)
+}
+
 // ErrRepoAlreadyExist represents a "RepoAlreadyExist" kind of error.
 type ErrRepoAlreadyExist struct {
 	Uname string
@@ -503,6 +705,22 @@ func (err ErrRepoAlreadyExist) Error() string {
 	return fmt.Sprintf("repository already exists [uname: %s, name: %s]", err.Uname, err.Name)
 }
 
+// ErrServiceDeskNotExist represents a "ServiceDeskNotExist" kind of error.
+type ErrServiceDeskNotExist struct {
+	RepoID int64
+	Token  string
+}
+
+// IsErrServiceDeskNotExist checks if an error is a ErrServiceDeskNotExist.
+func IsErrServiceDeskNotExist(err error) bool {
+	_, ok := err.(ErrServiceDeskNotExist)
+	return ok
+}
+
+func (err ErrServiceDeskNotExist) Error() string {
+	return fmt.Sprintf("service desk does not exist [repo_id: %d, token: %s]", err.RepoID, err.Token)
+}
+
 // ErrRepoRedirectNotExist represents a "RepoRedirectNotExist" kind of error.
 type ErrRepoRedirectNotExist struct {
 	OwnerID  int64
@@ -613,6 +831,68 @@ func (err ErrRepoFileAlreadyExist) Error() string {
 	return fmt.Sprintf("repository file already exists [file_name: %s]", err.FileName)
 }
 
+// ErrInvalidCompareSpec represents a "InvalidCompareSpec" kind of error.
+type ErrInvalidCompareSpec struct {
+	Spec string
+}
+
+// IsErrInvalidCompareSpec checks if an error is a ErrInvalidCompareSpec.
+func IsErrInvalidCompareSpec(err error) bool {
+	_, ok := err.(ErrInvalidCompareSpec)
+	return ok
+}
+
+func (err ErrInvalidCompareSpec) Error() string {
+	return fmt.Sprintf("compare spec is not valid [spec: %s]", err.Spec)
+}
+
+// ErrForkSyncConflict represents a "ForkSyncConflict" kind of error.
+type ErrForkSyncConflict struct {
+	RepoID int64
+	Branch string
+	Style  ForkSyncStyle
+}
+
+// IsErrForkSyncConflict checks if an error is a ErrForkSyncConflict.
+func IsErrForkSyncConflict(err error) bool {
+	_, ok := err.(ErrForkSyncConflict)
+	return ok
+}
+
+func (err ErrForkSyncConflict) Error() string {
+	return fmt.Sprintf("fork branch cannot be synced with base repository [repo_id: %d, branch: %s, style: %s]",
+		err.RepoID, err.Branch, err.Style)
+}
+
+// ErrShallowCloneNotAllowed represents a "ShallowCloneNotAllowed" kind of error.
+type ErrShallowCloneNotAllowed struct{}
+
+// IsErrShallowCloneNotAllowed checks if an error is a ErrShallowCloneNotAllowed.
+func IsErrShallowCloneNotAllowed(err error) bool {
+	_, ok := err.(ErrShallowCloneNotAllowed)
+	return ok
+}
+
+func (err ErrShallowCloneNotAllowed) Error() string {
+	return "shallow clones are not allowed on this server"
+}
+
+// ErrPushCreateNotAllowed represents a "PushCreateNotAllowed" kind of error.
+type ErrPushCreateNotAllowed struct {
+	OwnerName string
+	Name      string
+}
+
+// IsErrPushCreateNotAllowed checks if an error is a ErrPushCreateNotAllowed.
+func IsErrPushCreateNotAllowed(err error) bool {
+	_, ok := err.(ErrPushCreateNotAllowed)
+	return ok
+}
+
+func (err ErrPushCreateNotAllowed) Error() string {
+	return fmt.Sprintf("repository cannot be created by push [owner: %s, name: %s]", err.OwnerName, err.Name)
+}
+
 // __________                             .__
 // \______   \____________    ____   ____ |  |__
 //  |    |  _/\_  __ \__  \  /    \_/ ___\|  |  \
@@ -635,6 +915,36 @@ func (err ErrBranchNotExist) Error() string {
 	return fmt.Sprintf("branch does not exist [name: %s]", err.Name)
 }
 
+// ErrBranchAlreadyExist represents a "BranchAlreadyExist" kind of error.
+type ErrBranchAlreadyExist struct {
+	Name string
+}
+
+// IsErrBranchAlreadyExist checks if an error is a ErrBranchAlreadyExist.
+func IsErrBranchAlreadyExist(err error) bool {
+	_, ok := err.(ErrBranchAlreadyExist)
+	return ok
+}
+
+func (err ErrBranchAlreadyExist) Error() string {
+	return fmt.Sprintf("branch already exists [name: %s]", err.Name)
+}
+
+// ErrInvalidBranchName represents a "InvalidBranchName" kind of error.
+type ErrInvalidBranchName struct {
+	Name string
+}
+
+// IsErrInvalidBranchName checks if an error is a ErrInvalidBranchName.
+func IsErrInvalidBranchName(err error) bool {
+	_, ok := err.(ErrInvalidBranchName)
+	return ok
+}
+
+func (err ErrInvalidBranchName) Error() string {
+	return fmt.Sprintf("branch name is invalid [name: %s]", err.Name)
+}
+
 //  __      __      ___.   .__                   __
 // /  \    /  \ ____\_ |__ |  |__   ____   ____ |  | __
 // \   \/\/   // __ \| __ \|  |  \ /  _ \ /  _ \|  |/ /
@@ -657,6 +967,38 @@ func (err ErrWebhookNotExist) Error() string {
 	return fmt.Sprintf("webhook does not exist [id: %d]", err.ID)
 }
 
+// ErrWebhookAddressDenied represents a "WebhookAddressDenied" kind of error.
+type ErrWebhookAddressDenied struct {
+	Host string
+	IP   net.IP
+}
+
+// IsErrWebhookAddressDenied checks if an error is a ErrWebhookAddressDenied.
+func IsErrWebhookAddressDenied(err error) bool {
+	_, ok := err.(ErrWebhookAddressDenied)
+	return ok
+}
+
+func (err ErrWebhookAddressDenied) Error() string {
+	return fmt.Sprintf("webhook delivery to private address is denied [host: %s, ip: %s]", err.Host, err.IP)
+}
+
+// ErrAvatarAddressDenied represents a "AvatarAddressDenied" kind of error.
+type ErrAvatarAddressDenied struct {
+	Host string
+	IP   net.IP
+}
+
+// IsErrAvatarAddressDenied checks if an error is a ErrAvatarAddressDenied.
+func IsErrAvatarAddressDenied(err error) bool {
+	_, ok := err.(ErrAvatarAddressDenied)
+	return ok
+}
+
+func (err ErrAvatarAddressDenied) Error() string {
+	return fmt.Sprintf("avatar fetch from private address is denied [host: %s, ip: %s]", err.Host, err.IP)
+}
+
 // .___
 // |   | ______ ________ __   ____
 // |   |/  ___//  ___/  |  \_/ __ \
@@ -681,6 +1023,22 @@ func (err ErrIssueNotExist) Error() string {
 	return fmt.Sprintf("issue does not exist [id: %d, repo_id: %d, index: %d]", err.ID, err.RepoID, err.Index)
 }
 
+// ErrIssueMaxPinReached represents a "IssueMaxPinReached" kind of error.
+type ErrIssueMaxPinReached struct {
+	RepoID int64
+	Max    int
+}
+
+// IsErrIssueMaxPinReached checks if an error is a ErrIssueMaxPinReached.
+func IsErrIssueMaxPinReached(err error) bool {
+	_, ok := err.(ErrIssueMaxPinReached)
+	return ok
+}
+
+func (err ErrIssueMaxPinReached) Error() string {
+	return fmt.Sprintf("maximum number of pinned issues reached [repo_id: %d, max: %d]", err.RepoID, err.Max)
+}
+
 // __________      .__  .__ __________                                     __
 // \______   \__ __|  | |  |\______   \ ____  ________ __   ____   _______/  |_
 //  |     ___/  |  \  | |  | |       _// __ \/ ____/  |  \_/ __ \ /  ___/\   __\
@@ -731,6 +1089,72 @@ func (err ErrPullRequestAlreadyExists) Error() string {
 		err.ID, err.IssueID, err.HeadRepoID, err.BaseRepoID, err.HeadBranch, err.BaseBranch)
 }
 
+// ErrPullRequestUpdateConflict represents a "PullRequestUpdateConflict"-error
+type ErrPullRequestUpdateConflict struct {
+	ID         int64
+	HeadBranch string
+	BaseBranch string
+}
+
+// IsErrPullRequestUpdateConflict checks if an error is a ErrPullRequestUpdateConflict.
+func IsErrPullRequestUpdateConflict(err error) bool {
+	_, ok := err.(ErrPullRequestUpdateConflict)
+	return ok
+}
+
+func (err ErrPullRequestUpdateConflict) Error() string {
+	return fmt.Sprintf("base branch cannot be applied to head branch without conflicts [id: %d, head_branch: %s, base_branch: %s]",
+		err.ID, err.HeadBranch, err.BaseBranch)
+}
+
+// ErrPullRequestMergeConflict represents a "PullRequestMergeConflict"-error
+type ErrPullRequestMergeConflict struct {
+	ID    int64
+	Style MergeStyle
+}
+
+// IsErrPullRequestMergeConflict checks if an error is a ErrPullRequestMergeConflict.
+func IsErrPullRequestMergeConflict(err error) bool {
+	_, ok := err.(ErrPullRequestMergeConflict)
+	return ok
+}
+
+func (err ErrPullRequestMergeConflict) Error() string {
+	return fmt.Sprintf("pull request cannot be merged without conflicts [id: %d, style: %s]", err.ID, err.Style)
+}
+
+// ErrPullRequestBackportConflict represents a "PullRequestBackportConflict"-error
+type ErrPullRequestBackportConflict struct {
+	ID     int64
+	Branch string
+}
+
+// IsErrPullRequestBackportConflict checks if an error is a ErrPullRequestBackportConflict.
+func IsErrPullRequestBackportConflict(err error) bool {
+	_, ok := err.(ErrPullRequestBackportConflict)
+	return ok
+}
+
+func (err ErrPullRequestBackportConflict) Error() string {
+	return fmt.Sprintf("pull request cannot be cherry-picked onto branch without conflicts [id: %d, branch: %s]", err.ID, err.Branch)
+}
+
+// ErrPullRequestBaseIsHead represents a "PullRequestBaseIsHead"-error
+type ErrPullRequestBaseIsHead struct {
+	ID     int64
+	Branch string
+}
+
+// IsErrPullRequestBaseIsHead checks if an error is a ErrPullRequestBaseIsHead.
+func IsErrPullRequestBaseIsHead(err error) bool {
+	_, ok := err.(ErrPullRequestBaseIsHead)
+	return ok
+}
+
+func (err ErrPullRequestBaseIsHead) Error() string {
+	return fmt.Sprintf("base branch of pull request is its head branch [id: %d, branch: %s]", err.ID, err.Branch)
+}
+
 // _________                                       __
 // \_   ___ \  ____   _____   _____   ____   _____/  |_
 // /    \  \/ /  _ \ /     \ /     \_/ __ \ /    \   __\
@@ -754,6 +1178,160 @@ func (err ErrCommentNotExist) Error() string {
 	return fmt.Sprintf("comment does not exist [id: %d, issue_id: %d]", err.ID, err.IssueID)
 }
 
+// ErrContentBlocked represents a "ContentBlocked" kind of error.
+type ErrContentBlocked struct {
+	Word string
+}
+
+// IsErrContentBlocked checks if an error is a ErrContentBlocked.
+func IsErrContentBlocked(err error) bool {
+	_, ok := err.(ErrContentBlocked)
+	return ok
+}
+
+func (err ErrContentBlocked) Error() string {
+	return fmt.Sprintf("content contains blocked word [word: %s]", err.Word)
+}
+
+// ErrContentHeldForModeration represents a "ContentHeldForModeration" kind of error.
+type ErrContentHeldForModeration struct {
+	ItemID int64
+}
+
+// IsErrContentHeldForModeration checks if an error is a ErrContentHeldForModeration.
+func IsErrContentHeldForModeration(err error) bool {
+	_, ok := err.(ErrContentHeldForModeration)
+	return ok
+}
+
+func (err ErrContentHeldForModeration) Error() string {
+	return fmt.Sprintf("content is held for moderation [item_id: %d]", err.ItemID)
+}
+
+// ErrBlockedWordAlreadyExist represents a "BlockedWordAlreadyExist" kind of error.
+type ErrBlockedWordAlreadyExist struct {
+	Word string
+}
+
+// IsErrBlockedWordAlreadyExist checks if an error is a ErrBlockedWordAlreadyExist.
+func IsErrBlockedWordAlreadyExist(err error) bool {
+	_, ok := err.(ErrBlockedWordAlreadyExist)
+	return ok
+}
+
+func (err ErrBlockedWordAlreadyExist) Error() string {
+	return fmt.Sprintf("blocked word already exists [word: %s]", err.Word)
+}
+
+// ErrModerationItemNotExist represents a "ModerationItemNotExist" kind of error.
+type ErrModerationItemNotExist struct {
+	ID int64
+}
+
+// IsErrModerationItemNotExist checks if an error is a ErrModerationItemNotExist.
+func IsErrModerationItemNotExist(err error) bool {
+	_, ok := err.(ErrModerationItemNotExist)
+	return ok
+}
+
+func (err ErrModerationItemNotExist) Error() string {
+	return fmt.Sprintf("moderation item does not exist [id: %d]", err.ID)
+}
+
+// ErrAbuseReportNotExist represents a "AbuseReportNotExist" kind of error.
+type ErrAbuseReportNotExist struct {
+	ID int64
+}
+
+// IsErrAbuseReportNotExist checks if an error is a ErrAbuseReportNotExist.
+func IsErrAbuseReportNotExist(err error) bool {
+	_, ok := err.(ErrAbuseReportNotExist)
+	return ok
+}
+
+func (err ErrAbuseReportNotExist) Error() string {
+	return fmt.Sprintf("abuse report does not exist [id: %d]", err.ID)
+}
+
+// ErrAbuseReportTargetNotExist represents a "AbuseReportTargetNotExist" kind of error.
+type ErrAbuseReportTargetNotExist struct {
+	Type     AbuseReportType
+	TargetID int64
+}
+
+// IsErrAbuseReportTargetNotExist checks if an error is a ErrAbuseReportTargetNotExist.
+func IsErrAbuseReportTargetNotExist(err error) bool {
+	_, ok := err.(ErrAbuseReportTargetNotExist)
+	return ok
+}
+
+func (err ErrAbuseReportTargetNotExist) Error() string {
+	return fmt.Sprintf("reported content does not exist [type: %s, target_id: %d]", err.Type.Name(), err.TargetID)
+}
+
+// ErrAbuseReportAlreadyExist represents a "AbuseReportAlreadyExist" kind of error.
+type ErrAbuseReportAlreadyExist struct {
+	ReporterID int64
+	Type       AbuseReportType
+	TargetID   int64
+}
+
+// IsErrAbuseReportAlreadyExist checks if an error is a ErrAbuseReportAlreadyExist.
+func IsErrAbuseReportAlreadyExist(err error) bool {
+	_, ok := err.(ErrAbuseReportAlreadyExist)
+	return ok
+}
+
+func (err ErrAbuseReportAlreadyExist) Error() string {
+	return fmt.Sprintf("abuse report already exists [reporter_id: %d, type: %s, target_id: %d]", err.ReporterID, err.Type.Name(), err.TargetID)
+}
+
+// ErrAbuseReportLimitExceeded represents a "AbuseReportLimitExceeded" kind of error.
+type ErrAbuseReportLimitExceeded struct {
+	ReporterID int64
+}
+
+// IsErrAbuseReportLimitExceeded checks if an error is a ErrAbuseReportLimitExceeded.
+func IsErrAbuseReportLimitExceeded(err error) bool {
+	_, ok := err.(ErrAbuseReportLimitExceeded)
+	return ok
+}
+
+func (err ErrAbuseReportLimitExceeded) Error() string {
+	return fmt.Sprintf("too many abuse reports [reporter_id: %d]", err.ReporterID)
+}
+
+// ErrUserExportNotExist represents a "UserExportNotExist" kind of error.
+type ErrUserExportNotExist struct {
+	UserID int64
+}
+
+// IsErrUserExportNotExist checks if an error is a ErrUserExportNotExist.
+func IsErrUserExportNotExist(err error) bool {
+	_, ok := err.(ErrUserExportNotExist)
+	return ok
+}
+
+func (err ErrUserExportNotExist) Error() string {
+	return fmt.Sprintf("user data export does not exist [user_id: %d]", err.UserID)
+}
+
+// ErrUserExportTooSoon represents a "UserExportTooSoon" kind of error.
+type ErrUserExportTooSoon struct {
+	UserID int64
+	Next   time.Time
+}
+
+// IsErrUserExportTooSoon checks if an error is a ErrUserExportTooSoon.
+func IsErrUserExportTooSoon(err error) bool {
+	_, ok := err.(ErrUserExportTooSoon)
+	return ok
+}
+
+func (err ErrUserExportTooSoon) Error() string {
+	return fmt.Sprintf("user data export requested too soon [user_id: %d, next: %s]", err.UserID, err.Next)
+}
+
 // .____          ___.          .__
 // |    |   _____ \_ |__   ____ |  |
 // |    |   \__  \ | __ \_/ __ \|  |
@@ -800,6 +1378,142 @@ func (err ErrMilestoneNotExist) Error() string {
 	return fmt.Sprintf("milestone does not exist [id: %d, repo_id: %d]", err.ID, err.RepoID)
 }
 
+// __________                       .___
+// \______   \ _________ _______  __| _/
+//  |    |  _//  _ \__  \\_  __ \/ __ |
+//  |    |   (  <_> ) __ \|  | \/ /_/ |
+//  |______  /\____(____  /__|  \____ |
+//         \/           \/           \/
+
+// ErrBoardNotExist represents a "BoardNotExist" kind of error.
+type ErrBoardNotExist struct {
+	ID     int64
+	RepoID int64
+	OrgID  int64
+}
+
+// IsErrBoardNotExist checks if an error is a ErrBoardNotExist.
+func IsErrBoardNotExist(err error) bool {
+	_, ok := err.(ErrBoardNotExist)
+	return ok
+}
+
+func (err ErrBoardNotExist) Error() string {
+	return fmt.Sprintf("board does not exist [id: %d, repo_id: %d, org_id: %d]", err.ID, err.RepoID, err.OrgID)
+}
+
+// ErrBoardColumnNotExist represents a "BoardColumnNotExist" kind of error.
+type ErrBoardColumnNotExist struct {
+	ID      int64
+	BoardID int64
+}
+
+// IsErrBoardColumnNotExist checks if an error is a ErrBoardColumnNotExist.
+func IsErrBoardColumnNotExist(err error) bool {
+	_, ok := err.(ErrBoardColumnNotExist)
+	return ok
+}
+
+func (err ErrBoardColumnNotExist) Error() string {
+	return fmt.Sprintf("board column does not exist [id: %d, board_id: %d]", err.ID, err.BoardID)
+}
+
+// ErrBoardLastColumn represents a "BoardLastColumn" kind of error.
+type ErrBoardLastColumn struct {
+	BoardID int64
+}
+
+// IsErrBoardLastColumn checks if an error is a ErrBoardLastColumn.
+func IsErrBoardLastColumn(err error) bool {
+	_, ok := err.(ErrBoardLastColumn)
+	return ok
+}
+
+func (err ErrBoardLastColumn) Error() string {
+	return fmt.Sprintf("last column of a board cannot be deleted [board_id: %d]", err.BoardID)
+}
+
+// ErrBoardIssueNotExist represents a "BoardIssueNotExist" kind of error.
+type ErrBoardIssueNotExist struct {
+	BoardID int64
+	IssueID int64
+}
+
+// IsErrBoardIssueNotExist checks if an error is a ErrBoardIssueNotExist.
+func IsErrBoardIssueNotExist(err error) bool {
+	_, ok := err.(ErrBoardIssueNotExist)
+	return ok
+}
+
+func (err ErrBoardIssueNotExist) Error() string {
+	return fmt.Sprintf("issue is not on the board [board_id: %d, issue_id: %d]", err.BoardID, err.IssueID)
+}
+
+// ErrBoardIssueNotAllowed represents a "BoardIssueNotAllowed" kind of error.
+type ErrBoardIssueNotAllowed struct {
+	BoardID int64
+	IssueID int64
+}
+
+// IsErrBoardIssueNotAllowed checks if an error is a ErrBoardIssueNotAllowed.
+func IsErrBoardIssueNotAllowed(err error) bool {
+	_, ok := err.(ErrBoardIssueNotAllowed)
+	return ok
+}
+
+func (err ErrBoardIssueNotAllowed) Error() string {
+	return fmt.Sprintf("issue cannot be placed on the board [board_id: %d, issue_id: %d]", err.BoardID, err.IssueID)
+}
+
+// ErrReviewSelfApproval represents a "ReviewSelfApproval" kind of error.
+type ErrReviewSelfApproval struct {
+	PullRequestID int64
+	UserID        int64
+}
+
+// IsErrReviewSelfApproval checks if an error is a ErrReviewSelfApproval.
+func IsErrReviewSelfApproval(err error) bool {
+	_, ok := err.(ErrReviewSelfApproval)
+	return ok
+}
+
+func (err ErrReviewSelfApproval) Error() string {
+	return fmt.Sprintf("poster cannot approve own pull request [pull_request_id: %d, user_id: %d]", err.PullRequestID, err.UserID)
+}
+
+// ErrRepoAdvisoryNotExist represents a "RepoAdvisoryNotExist" kind of error.
+type ErrRepoAdvisoryNotExist struct {
+	RepoID int64
+	Index  int64
+}
+
+// IsErrRepoAdvisoryNotExist checks if an error is a ErrRepoAdvisoryNotExist.
+func IsErrRepoAdvisoryNotExist(err error) bool {
+	_, ok := err.(ErrRepoAdvisoryNotExist)
+	return ok
+}
+
+func (err ErrRepoAdvisoryNotExist) Error() string {
+	return fmt.Sprintf("security advisory does not exist [repo_id: %d, index: %d]", err.RepoID, err.Index)
+}
+
+// ErrRepoAdvisoryUnmerged represents a "RepoAdvisoryUnmerged" kind of error.
+type ErrRepoAdvisoryUnmerged struct {
+	RepoID   int64
+	Index    int64
+	Branches []string
+}
+
+// IsErrRepoAdvisoryUnmerged checks if an error is a ErrRepoAdvisoryUnmerged.
+func IsErrRepoAdvisoryUnmerged(err error) bool {
+	_, ok := err.(ErrRepoAdvisoryUnmerged)
+	return ok
+}
+
+func (err ErrRepoAdvisoryUnmerged) Error() string {
+	return fmt.Sprintf("security advisory fork has unmerged branches [repo_id: %d, index: %d, branches: %s]", err.RepoID, err.Index, strings.Join(err.Branches, ", "))
+}
+
 //    _____   __    __                .__                           __
 //   /  _  \_/  |__/  |______    ____ |  |__   _____   ____   _____/  |_
 //  /  /_\  \   __\   __\__  \ _/ ___\|  |  \ /     \_/ __ \ /    \   __\
@@ -823,6 +1537,23 @@ func (err ErrAttachmentNotExist) Error() string {
 	return fmt.Sprintf("attachment does not exist [id: %d, uuid: %s]", err.ID, err.UUID)
 }
 
+// ErrAttachmentQuotaExceeded represents a "AttachmentQuotaExceeded" kind of error.
+type ErrAttachmentQuotaExceeded struct {
+	UserID  int64
+	IssueID int64
+	Quota   int64
+}
+
+// IsErrAttachmentQuotaExceeded checks if an error is a ErrAttachmentQuotaExceeded.
+func IsErrAttachmentQuotaExceeded(err error) bool {
+	_, ok := err.(ErrAttachmentQuotaExceeded)
+	return ok
+}
+
+func (err ErrAttachmentQuotaExceeded) Error() string {
+	return fmt.Sprintf("attachment quota exceeded [user_id: %d, issue_id: %d, quota: %d]", err.UserID, err.IssueID, err.Quota)
+}
+
 // .____                 .__           _________
 // |    |    ____   ____ |__| ____    /   _____/ ____  __ _________   ____  ____
 // |    |   /  _ \ / ___\|  |/    \   \_____  \ /  _ \|  |  \_  __ \_/ ___\/ __ \
@@ -941,6 +1672,53 @@ func (err ErrUploadNotExist) Error() string {
 	return fmt.Sprintf("attachment does not exist [id: %d, uuid: %s]", err.ID, err.UUID)
 }
 
+// ErrUploadSessionNotExist represents a "UploadSessionNotExist" kind of error.
+type ErrUploadSessionNotExist struct {
+	UUID string
+}
+
+// IsErrUploadSessionNotExist checks if an error is a ErrUploadSessionNotExist.
+func IsErrUploadSessionNotExist(err error) bool {
+	_, ok := err.(ErrUploadSessionNotExist)
+	return ok
+}
+
+func (err ErrUploadSessionNotExist) Error() string {
+	return fmt.Sprintf("upload session does not exist [uuid: %s]", err.UUID)
+}
+
+// ErrUploadSessionOffset represents a "UploadSessionOffset" kind of error.
+type ErrUploadSessionOffset struct {
+	Offset   int64
+	Received int64
+}
+
+// IsErrUploadSessionOffset checks if an error is a ErrUploadSessionOffset.
+func IsErrUploadSessionOffset(err error) bool {
+	_, ok := err.(ErrUploadSessionOffset)
+	return ok
+}
+
+func (err ErrUploadSessionOffset) Error() string {
+	return fmt.Sprintf("upload offset does not match received size [offset: %d, received: %d]", err.Offset, err.Received)
+}
+
+// ErrUploadSessionTooLarge represents a "UploadSessionTooLarge" kind of error.
+type ErrUploadSessionTooLarge struct {
+	Size    int64
+	MaxSize int64
+}
+
+// IsErrUploadSessionTooLarge checks if an error is a ErrUploadSessionTooLarge.
+func IsErrUploadSessionTooLarge(err error) bool {
+	_, ok := err.(ErrUploadSessionTooLarge)
+	return ok
+}
+
+func (err ErrUploadSessionTooLarge) Error() string {
+	return fmt.Sprintf("upload is too large [size: %d, max size: %d]", err.Size, err.MaxSize)
+}
+
 //  ___________         __                             .__    .____                 .__          ____ ___
 //  \_   _____/__  ____/  |_  ___________  ____ _____  |  |   |    |    ____   ____ |__| ____   |    |   \______ ___________
 //   |    __)_\  \/  /\   __\/ __ \_  __ \/    \\__  \ |  |   |    |   /  _ \ / ___\|  |/    \  |    |   /  ___// __ \_  __ \
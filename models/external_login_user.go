@@ -55,9 +55,12 @@ func LinkAccountToUser(user *User, gothUser goth.User) error {
 	return err
 }
 
-// RemoveAccountLink will remove all external login sources for the given user
+// RemoveAccountLink will remove the external login source linked to the given user
 func RemoveAccountLink(user *User, loginSourceID int64) (int64, error) {
-	deleted, err := x.Delete(&ExternalLoginUser{UserID: user.ID, LoginSourceID: loginSourceID})
+	deleted, err := x.
+		Where("user_id = ?", user.ID).
+		And("login_source_id = ?", loginSourceID).
+		Delete(new(ExternalLoginUser))
 	if err != nil {
 		return deleted, err
 	}
@@ -0,0 +1,34 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveAccountLink(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	for _, link := range []*ExternalLoginUser{
+		{ExternalID: "a", UserID: user.ID, LoginSourceID: 1},
+		{ExternalID: "b", UserID: user.ID, LoginSourceID: 2},
+	} {
+		_, err := x.Insert(link)
+		assert.NoError(t, err)
+	}
+
+	deleted, err := RemoveAccountLink(user, 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, deleted)
+	AssertNotExistsBean(t, &ExternalLoginUser{ExternalID: "a", LoginSourceID: 1})
+	AssertExistsAndLoadBean(t, &ExternalLoginUser{ExternalID: "b", LoginSourceID: 2})
+
+	_, err = RemoveAccountLink(user, 0)
+	assert.True(t, IsErrExternalLoginUserNotExist(err))
+	AssertExistsAndLoadBean(t, &ExternalLoginUser{ExternalID: "b", LoginSourceID: 2})
+}
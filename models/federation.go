@@ -0,0 +1,92 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"path"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// federationKeySize is the size in bits of the RSA keys of actors.
+const federationKeySize = 2048
+
+// FederationActorType is the type of what a federation key belongs to.
+type FederationActorType int
+
+// Types of the actors of the federation.
+const (
+	FederationActorUser FederationActorType = iota + 1 // 1
+	FederationActorRepo                                // 2
+)
+
+// FederationKey is the key pair a user or a repository signs its activities
+// with, other instances verify them with the public key of its actor.
+type FederationKey struct {
+	ID          int64               `xorm:"pk autoincr"`
+	ActorType   FederationActorType `xorm:"UNIQUE(s) NOT NULL"`
+	ActorID     int64               `xorm:"UNIQUE(s) NOT NULL"`
+	PublicKey   string              `xorm:"TEXT NOT NULL"`
+	PrivateKey  string              `xorm:"TEXT NOT NULL"`
+	CreatedUnix int64               `xorm:"created"`
+}
+
+// generateFederationKey returns a new RSA key pair, PEM encoded.
+func generateFederationKey() (publicKey, privateKey string, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, federationKeySize)
+	if err != nil {
+		return "", "", fmt.Errorf("GenerateKey: %v", err)
+	}
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return "", "", fmt.Errorf("MarshalPKIXPublicKey: %v", err)
+	}
+
+	publicKey = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}))
+	privateKey = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	return publicKey, privateKey, nil
+}
+
+// GetFederationKey returns the key of given actor, it is generated the first
+// time it is asked for.
+func GetFederationKey(actorType FederationActorType, actorID int64) (*FederationKey, error) {
+	key := &FederationKey{ActorType: actorType, ActorID: actorID}
+	if has, err := x.Get(key); err != nil {
+		return nil, err
+	} else if has {
+		return key, nil
+	}
+
+	publicKey, privateKey, err := generateFederationKey()
+	if err != nil {
+		return nil, err
+	}
+	key.PublicKey = publicKey
+	key.PrivateKey = privateKey
+	if _, err = x.Insert(key); err != nil {
+		// The key may have been generated by a concurrent request.
+		existing := &FederationKey{ActorType: actorType, ActorID: actorID}
+		if has, getErr := x.Get(existing); getErr == nil && has {
+			return existing, nil
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// ActorURL returns the URL of the ActivityPub actor of the user.
+func (u *User) ActorURL() string {
+	return setting.AppURL + path.Join("api/v1/activitypub/user", u.Name)
+}
+
+// ActorURL returns the URL of the ActivityPub actor of the repository.
+func (repo *Repository) ActorURL() string {
+	return setting.AppURL + path.Join("api/v1/activitypub/repo", repo.FullName())
+}
@@ -0,0 +1,44 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFederationKey(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	key, err := GetFederationKey(FederationActorUser, 2)
+	assert.NoError(t, err)
+	AssertExistsAndLoadBean(t, &FederationKey{ActorType: FederationActorUser, ActorID: 2})
+
+	block, _ := pem.Decode([]byte(key.PublicKey))
+	if assert.NotNil(t, block) {
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		assert.NoError(t, err)
+		assert.IsType(t, &rsa.PublicKey{}, pub)
+	}
+
+	// The key is generated once per actor.
+	again, err := GetFederationKey(FederationActorUser, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, key.PublicKey, again.PublicKey)
+
+	repoKey, err := GetFederationKey(FederationActorRepo, 2)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key.PublicKey, repoKey.PublicKey)
+
+	// Deleting the keys of a user keeps those of the repository of same ID.
+	_, err = x.Delete(&FederationKey{ActorType: FederationActorUser, ActorID: 2})
+	assert.NoError(t, err)
+	AssertNotExistsBean(t, &FederationKey{ActorType: FederationActorUser, ActorID: 2})
+	AssertExistsAndLoadBean(t, &FederationKey{ActorType: FederationActorRepo, ActorID: 2})
+}
@@ -14,6 +14,7 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 
 	"code.gitea.io/git"
@@ -364,6 +365,11 @@ func ParsePatch(maxLines, maxLineCharacters, maxFiles int, reader io.Reader) (*D
 				b = string(git.UnescapeChars([]byte(b[1 : len(b)-1])))
 			}
 
+			if len(diff.Files) >= maxFiles {
+				diff.IsIncomplete = true
+				io.Copy(ioutil.Discard, reader)
+				break
+			}
 			curFile = &DiffFile{
 				Name:     a,
 				Index:    len(diff.Files) + 1,
@@ -371,11 +377,6 @@ func ParsePatch(maxLines, maxLineCharacters, maxFiles int, reader io.Reader) (*D
 				Sections: make([]*DiffSection, 0, 10),
 			}
 			diff.Files = append(diff.Files, curFile)
-			if len(diff.Files) >= maxFiles {
-				diff.IsIncomplete = true
-				io.Copy(ioutil.Discard, reader)
-				break
-			}
 			curFileLinesCount = 0
 			curFileLFSPrefix = false
 
@@ -497,6 +498,128 @@ func GetDiffRange(repoPath, beforeCommitID, afterCommitID string, maxLines, maxL
 	return diff, nil
 }
 
+// emptyTreeSHA is the ID of the empty tree, which every repository knows of.
+const emptyTreeSHA = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// getDiffRangeBase returns the commit to diff afterCommitID against, the
+// parent commit or the empty tree if beforeCommitID is not given.
+func getDiffRangeBase(repoPath, beforeCommitID, afterCommitID string) (string, error) {
+	if len(beforeCommitID) > 0 {
+		return beforeCommitID, nil
+	}
+
+	gitRepo, err := git.OpenRepository(repoPath)
+	if err != nil {
+		return "", err
+	}
+	commit, err := gitRepo.GetCommit(afterCommitID)
+	if err != nil {
+		return "", err
+	}
+	if commit.ParentCount() == 0 {
+		return emptyTreeSHA, nil
+	}
+	c, err := commit.Parent(0)
+	if err != nil {
+		return "", err
+	}
+	return c.ID.String(), nil
+}
+
+// DiffFileStat represents the changed lines of a file without its content.
+type DiffFileStat struct {
+	Index     int    `json:"index"`
+	Name      string `json:"name"`
+	OldName   string `json:"old_name,omitempty"`
+	Addition  int    `json:"additions"`
+	Deletion  int    `json:"deletions"`
+	IsBin     bool   `json:"is_bin"`
+	IsRenamed bool   `json:"is_renamed"`
+}
+
+// parseDiffNumstat parses output of "git diff --numstat -z".
+func parseDiffNumstat(stdout string) ([]*DiffFileStat, error) {
+	stats := make([]*DiffFileStat, 0, 10)
+	fields := strings.Split(stdout, "\x00")
+	for i := 0; i < len(fields); i++ {
+		if len(fields[i]) == 0 {
+			continue
+		}
+
+		infos := strings.SplitN(fields[i], "\t", 3)
+		if len(infos) != 3 {
+			return nil, fmt.Errorf("malformed numstat line: %q", fields[i])
+		}
+		stat := &DiffFileStat{
+			Index: len(stats) + 1,
+			Name:  infos[2],
+		}
+		// Binary files are reported as "-".
+		if infos[0] == "-" && infos[1] == "-" {
+			stat.IsBin = true
+		} else {
+			stat.Addition, _ = strconv.Atoi(infos[0])
+			stat.Deletion, _ = strconv.Atoi(infos[1])
+		}
+
+		// Renamed files have an empty name followed by the old and new name.
+		if len(stat.Name) == 0 {
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("malformed numstat rename: %q", fields[i])
+			}
+			stat.IsRenamed = true
+			stat.OldName = fields[i+1]
+			stat.Name = fields[i+2]
+			i += 2
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+// GetDiffRangeFileStats returns the changed files between two commits with
+// their numbers of changed lines, in the same order as GetDiffRange.
+func GetDiffRangeFileStats(repoPath, beforeCommitID, afterCommitID string) ([]*DiffFileStat, error) {
+	base, err := getDiffRangeBase(repoPath, beforeCommitID, afterCommitID)
+	if err != nil {
+		return nil, fmt.Errorf("getDiffRangeBase: %v", err)
+	}
+
+	stdout, err := git.NewCommand("diff", "-M", "--numstat", "-z", base, afterCommitID).RunInDir(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("git diff --numstat: %v", err)
+	}
+	return parseDiffNumstat(stdout)
+}
+
+// GetDiffRangeFile builds the diff of a single file between two commits,
+// oldName should be given for renamed files. It returns nil if the file
+// has not been changed.
+func GetDiffRangeFile(repoPath, beforeCommitID, afterCommitID, name, oldName string, maxLines, maxLineCharacters int) (*DiffFile, error) {
+	base, err := getDiffRangeBase(repoPath, beforeCommitID, afterCommitID)
+	if err != nil {
+		return nil, fmt.Errorf("getDiffRangeBase: %v", err)
+	}
+
+	args := []string{"diff", "-M", base, afterCommitID, "--", name}
+	if len(oldName) > 0 && oldName != name {
+		args = append(args, oldName)
+	}
+	stdout, err := git.NewCommand(args...).RunInDirBytes(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("git diff: %v", err)
+	}
+
+	diff, err := ParsePatch(maxLines, maxLineCharacters, 1, bytes.NewReader(stdout))
+	if err != nil {
+		return nil, fmt.Errorf("ParsePatch: %v", err)
+	}
+	if len(diff.Files) == 0 {
+		return nil, nil
+	}
+	return diff.Files[0], nil
+}
+
 // RawDiffType type of a raw diff.
 type RawDiffType string
 
@@ -552,6 +675,31 @@ func GetRawDiff(repoPath, commitID string, diffType RawDiffType, writer io.Write
 	return nil
 }
 
+// GetRawDiffRange dumps diff results of the commits between two commits in
+// repository in given diff type to writer.
+func GetRawDiffRange(repoPath, beforeCommitID, afterCommitID string, diffType RawDiffType, writer io.Writer) error {
+	var cmd *exec.Cmd
+	switch diffType {
+	case RawDiffNormal:
+		cmd = exec.Command("git", "diff", "-M", beforeCommitID, afterCommitID)
+	case RawDiffPatch:
+		cmd = exec.Command("git", "format-patch", "--no-signature", "--stdout", beforeCommitID+".."+afterCommitID)
+	default:
+		return fmt.Errorf("invalid diffType: %s", diffType)
+	}
+
+	stderr := new(bytes.Buffer)
+
+	cmd.Dir = repoPath
+	cmd.Stdout = writer
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("Run: %v - %s", err, stderr)
+	}
+	return nil
+}
+
 // GetDiffCommit builds a Diff representing the given commitID.
 func GetDiffCommit(repoPath, commitID string, maxLines, maxLineCharacters, maxFiles int) (*Diff, error) {
 	return GetDiffRange(repoPath, "", commitID, maxLines, maxLineCharacters, maxFiles)
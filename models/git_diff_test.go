@@ -4,6 +4,8 @@ import (
 	dmp "github.com/sergi/go-diff/diffmatchpatch"
 	"html/template"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func assertEqual(t *testing.T, s1 string, s2 template.HTML) {
@@ -33,3 +35,18 @@ func TestDiffToHTML(t *testing.T) {
 		{dmp.DiffEqual, " biz"},
 	}, DiffLineDel))
 }
+
+func TestParseDiffNumstat(t *testing.T) {
+	stats, err := parseDiffNumstat("3\t1\tREADME.md\x00" +
+		"-\t-\tlogo.png\x00" +
+		"0\t0\t\x00old.go\x00new.go\x00")
+	assert.NoError(t, err)
+	assert.Len(t, stats, 3)
+
+	assert.Equal(t, &DiffFileStat{Index: 1, Name: "README.md", Addition: 3, Deletion: 1}, stats[0])
+	assert.Equal(t, &DiffFileStat{Index: 2, Name: "logo.png", IsBin: true}, stats[1])
+	assert.Equal(t, &DiffFileStat{Index: 3, Name: "new.go", OldName: "old.go", IsRenamed: true}, stats[2])
+
+	_, err = parseDiffNumstat("README.md\x00")
+	assert.Error(t, err)
+}
@@ -0,0 +1,116 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"code.gitea.io/git"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// GlobalGitHook represents a system-wide Git hook script which is executed
+// before the hooks of the repository on every push.
+type GlobalGitHook struct {
+	Name    string
+	Content string
+}
+
+// IsActive returns true if the hook has a script to execute.
+func (h *GlobalGitHook) IsActive() bool {
+	return len(strings.TrimSpace(h.Content)) > 0
+}
+
+func globalGitHookPath(name string) string {
+	return filepath.Join(setting.Repository.GlobalHooksPath, name)
+}
+
+// GetGlobalGitHook returns the system-wide Git hook with given name.
+func GetGlobalGitHook(name string) (*GlobalGitHook, error) {
+	if !isDelegateHookName(name) {
+		return nil, git.ErrNotValidHook
+	}
+
+	h := &GlobalGitHook{Name: name}
+	data, err := ioutil.ReadFile(globalGitHookPath(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return nil, err
+	}
+	h.Content = string(data)
+	return h, nil
+}
+
+// GetGlobalGitHooks returns all the system-wide Git hooks.
+func GetGlobalGitHooks() ([]*GlobalGitHook, error) {
+	hooks := make([]*GlobalGitHook, 0, len(delegateHookNames))
+	for _, name := range delegateHookNames {
+		h, err := GetGlobalGitHook(name)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, h)
+	}
+	return hooks, nil
+}
+
+// Update saves the script of the hook, an empty script removes the hook.
+func (h *GlobalGitHook) Update() error {
+	hookPath := globalGitHookPath(h.Name)
+	if !h.IsActive() {
+		if err := os.Remove(hookPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(setting.Repository.GlobalHooksPath, os.ModePerm); err != nil {
+		return fmt.Errorf("create global hooks dir '%s': %v", setting.Repository.GlobalHooksPath, err)
+	}
+	return ioutil.WriteFile(hookPath, []byte(strings.Replace(h.Content, "\r", "", -1)), 0777)
+}
+
+// RunGlobalGitHook executes the system-wide Git hook with given name, if any,
+// with given arguments and standard input. The output of the script goes to
+// given writer, which is sent back to the pusher.
+func RunGlobalGitHook(name string, args []string, stdin []byte, output io.Writer) error {
+	hookPath := globalGitHookPath(name)
+	fi, err := os.Stat(hookPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	} else if fi.IsDir() || fi.Mode()&0111 == 0 {
+		return nil
+	}
+
+	cmd := exec.Command(hookPath, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf("global %s hook: %v", name, err)
+	}
+	return nil
+}
+
+func isDelegateHookName(name string) bool {
+	for _, hookName := range delegateHookNames {
+		if name == hookName {
+			return true
+		}
+	}
+	return false
+}
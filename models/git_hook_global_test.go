@@ -0,0 +1,56 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/git"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlobalGitHook(t *testing.T) {
+	hooksPath, err := ioutil.TempDir("", "global-hooks")
+	assert.NoError(t, err)
+	defer os.RemoveAll(hooksPath)
+	oldHooksPath := setting.Repository.GlobalHooksPath
+	setting.Repository.GlobalHooksPath = filepath.Join(hooksPath, "hooks")
+	defer func() { setting.Repository.GlobalHooksPath = oldHooksPath }()
+
+	_, err = GetGlobalGitHook("post-update")
+	assert.Equal(t, git.ErrNotValidHook, err)
+
+	hooks, err := GetGlobalGitHooks()
+	assert.NoError(t, err)
+	assert.Len(t, hooks, 3)
+	for _, hook := range hooks {
+		assert.False(t, hook.IsActive())
+	}
+
+	var output bytes.Buffer
+	assert.NoError(t, RunGlobalGitHook("pre-receive", nil, []byte("data\n"), &output))
+	assert.Empty(t, output.String())
+
+	hook := &GlobalGitHook{Name: "pre-receive", Content: "#!/bin/sh\r\ncat\necho \"$1\"\nexit 1\n"}
+	assert.NoError(t, hook.Update())
+	hook, err = GetGlobalGitHook("pre-receive")
+	assert.NoError(t, err)
+	assert.True(t, hook.IsActive())
+	assert.Equal(t, "#!/bin/sh\ncat\necho \"$1\"\nexit 1\n", hook.Content)
+
+	assert.Error(t, RunGlobalGitHook("pre-receive", []string{"arg"}, []byte("data\n"), &output))
+	assert.Equal(t, "data\narg\n", output.String())
+
+	hook.Content = ""
+	assert.NoError(t, hook.Update())
+	_, err = os.Stat(filepath.Join(setting.Repository.GlobalHooksPath, "pre-receive"))
+	assert.True(t, os.IsNotExist(err))
+}
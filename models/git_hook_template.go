@@ -0,0 +1,393 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"code.gitea.io/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/go-xorm/xorm"
+)
+
+// GitHookTemplate represents a Git hook script approved by site administrators
+// which repository administrators can enable with their own parameter values.
+type GitHookTemplate struct {
+	ID          int64  `xorm:"pk autoincr"`
+	Name        string `xorm:"UNIQUE NOT NULL"`
+	Description string `xorm:"TEXT"`
+	HookName    string `xorm:"NOT NULL"` // One of pre-receive, update and post-receive.
+	Content     string `xorm:"TEXT"`
+	// Parameters is a comma separated list of the environment variables the
+	// script reads its parameters from.
+	Parameters  string
+	Created     time.Time `xorm:"-"`
+	CreatedUnix int64
+	Updated     time.Time `xorm:"-"`
+	UpdatedUnix int64
+}
+
+// BeforeInsert will be invoked by XORM before inserting a record
+func (t *GitHookTemplate) BeforeInsert() {
+	t.CreatedUnix = time.Now().Unix()
+	t.UpdatedUnix = t.CreatedUnix
+}
+
+// BeforeUpdate is invoked from XORM before updating this object.
+func (t *GitHookTemplate) BeforeUpdate() {
+	t.UpdatedUnix = time.Now().Unix()
+}
+
+// AfterSet is invoked from XORM after setting the value of a field of this object.
+func (t *GitHookTemplate) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		t.Created = time.Unix(t.CreatedUnix, 0).Local()
+	case "updated_unix":
+		t.Updated = time.Unix(t.UpdatedUnix, 0).Local()
+	}
+}
+
+// ParameterNames returns the names of the parameters of the template.
+func (t *GitHookTemplate) ParameterNames() []string {
+	if len(t.Parameters) == 0 {
+		return nil
+	}
+	return strings.Split(t.Parameters, ",")
+}
+
+var gitHookParameterPattern = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+func (t *GitHookTemplate) sanitize() error {
+	t.Name = strings.TrimSpace(t.Name)
+	if !git.IsValidHookName(t.HookName) {
+		return git.ErrNotValidHook
+	}
+
+	names := make([]string, 0, 3)
+	for _, name := range strings.Split(t.Parameters, ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if len(name) == 0 {
+			continue
+		} else if !gitHookParameterPattern.MatchString(name) {
+			return ErrGitHookParameterInvalid{name}
+		}
+		names = append(names, name)
+	}
+	t.Parameters = strings.Join(names, ",")
+	return nil
+}
+
+// shellQuote quotes s to be used as a single word in a shell script.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// script returns the content of the script file of the template, which is
+// run with a shell if it has no shebang line.
+func (t *GitHookTemplate) script() string {
+	content := strings.Replace(t.Content, "\r", "", -1)
+	if !strings.HasPrefix(content, "#!") {
+		content = fmt.Sprintf("#!/usr/bin/env %s\n", setting.ScriptType) + content
+	}
+	return content
+}
+
+// hook returns the content of the hook file of the template, which runs the
+// script of the template with the parameters set to given values in its
+// environment, so that the script may be written in any language.
+func (t *GitHookTemplate) hook(values map[string]string) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "#!/usr/bin/env %s\nexec env", setting.ScriptType)
+	for _, name := range t.ParameterNames() {
+		fmt.Fprintf(&buf, " %s=%s", name, shellQuote(values[name]))
+	}
+	fmt.Fprintf(&buf, " \"$(dirname \"$0\")/../templates/template-%d\" \"$@\"\n", t.ID)
+	return buf.String()
+}
+
+// hookPath returns the path of the hook file of the template in the repository.
+func (t *GitHookTemplate) hookPath(repoPath string) string {
+	return filepath.Join(repoPath, "hooks", t.HookName+".d", fmt.Sprintf("template-%d", t.ID))
+}
+
+// scriptPath returns the path of the script file of the template in the
+// repository, which is kept out of the directories of the hooks so that it is
+// only run by the hook file.
+func (t *GitHookTemplate) scriptPath(repoPath string) string {
+	return filepath.Join(repoPath, "hooks", "templates", fmt.Sprintf("template-%d", t.ID))
+}
+
+// removeRepoGitHook removes the hook and script files of the template from the
+// repository.
+func (t *GitHookTemplate) removeRepoGitHook(repoPath string) error {
+	for _, p := range []string{t.hookPath(repoPath), t.scriptPath(repoPath)} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func isGitHookTemplateExist(id int64, name string) (bool, error) {
+	return x.
+		Where("id != ?", id).
+		And("name = ?", name).
+		Get(new(GitHookTemplate))
+}
+
+// NewGitHookTemplate adds a Git hook template to the library.
+func NewGitHookTemplate(t *GitHookTemplate) error {
+	if err := t.sanitize(); err != nil {
+		return err
+	}
+	has, err := isGitHookTemplateExist(0, t.Name)
+	if err != nil {
+		return err
+	} else if has {
+		return ErrGitHookTemplateAlreadyExist{t.Name}
+	}
+
+	_, err = x.Insert(t)
+	return err
+}
+
+// GetGitHookTemplateByID returns the Git hook template with given ID.
+func GetGitHookTemplateByID(id int64) (*GitHookTemplate, error) {
+	t := new(GitHookTemplate)
+	has, err := x.Id(id).Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrGitHookTemplateNotExist{id}
+	}
+	return t, nil
+}
+
+// GetGitHookTemplates returns all the Git hook templates of the library.
+func GetGitHookTemplates() ([]*GitHookTemplate, error) {
+	templates := make([]*GitHookTemplate, 0, 5)
+	return templates, x.Asc("name").Find(&templates)
+}
+
+// UpdateGitHookTemplate updates a Git hook template, and the hook files of the
+// repositories which enabled it.
+func UpdateGitHookTemplate(t *GitHookTemplate) error {
+	if err := t.sanitize(); err != nil {
+		return err
+	}
+	has, err := isGitHookTemplateExist(t.ID, t.Name)
+	if err != nil {
+		return err
+	} else if has {
+		return ErrGitHookTemplateAlreadyExist{t.Name}
+	}
+
+	old, err := GetGitHookTemplateByID(t.ID)
+	if err != nil {
+		return err
+	}
+	if _, err = x.Id(t.ID).AllCols().Update(t); err != nil {
+		return err
+	}
+
+	hooks, err := getRepoGitHooksByTemplateID(t.ID)
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		repo, err := GetRepositoryByID(hook.RepoID)
+		if err != nil {
+			log.Error(4, "GetRepositoryByID [%d]: %v", hook.RepoID, err)
+			continue
+		}
+		repoPath := repo.RepoPath()
+		if old.HookName != t.HookName {
+			if err = os.Remove(old.hookPath(repoPath)); err != nil && !os.IsNotExist(err) {
+				log.Error(4, "Remove hook file of template %d in %s: %v", t.ID, repoPath, err)
+			}
+		}
+		if err = writeRepoGitHook(repoPath, t, hook.ParameterValues()); err != nil {
+			log.Error(4, "writeRepoGitHook [%d] in %s: %v", t.ID, repoPath, err)
+		}
+	}
+	return nil
+}
+
+// DeleteGitHookTemplate deletes a Git hook template and disables it in all
+// repositories.
+func DeleteGitHookTemplate(id int64) error {
+	t, err := GetGitHookTemplateByID(id)
+	if err != nil {
+		if IsErrGitHookTemplateNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	hooks, err := getRepoGitHooksByTemplateID(id)
+	if err != nil {
+		return err
+	}
+
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+	if _, err = sess.Id(id).Delete(new(GitHookTemplate)); err != nil {
+		return err
+	} else if _, err = sess.Delete(&RepoGitHook{TemplateID: id}); err != nil {
+		return err
+	}
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		repo, err := GetRepositoryByID(hook.RepoID)
+		if err != nil {
+			log.Error(4, "GetRepositoryByID [%d]: %v", hook.RepoID, err)
+			continue
+		}
+		if err = t.removeRepoGitHook(repo.RepoPath()); err != nil {
+			log.Error(4, "Remove hook file of template %d in %s: %v", id, repo.RepoPath(), err)
+		}
+	}
+	return nil
+}
+
+// RepoGitHook represents a Git hook template enabled in a repository.
+type RepoGitHook struct {
+	ID         int64 `xorm:"pk autoincr"`
+	RepoID     int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	TemplateID int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	// Parameters holds the parameter values of the hook, encoded in JSON.
+	Parameters  string           `xorm:"TEXT"`
+	Template    *GitHookTemplate `xorm:"-"`
+	CreatedUnix int64            `xorm:"created"`
+}
+
+// ParameterValues returns the parameter values of the hook by name.
+func (h *RepoGitHook) ParameterValues() map[string]string {
+	values := make(map[string]string)
+	if len(h.Parameters) > 0 {
+		if err := json.Unmarshal([]byte(h.Parameters), &values); err != nil {
+			log.Error(4, "Unmarshal parameters of Git hook [%d]: %v", h.ID, err)
+		}
+	}
+	return values
+}
+
+func writeRepoGitHook(repoPath string, t *GitHookTemplate, values map[string]string) error {
+	files := []struct {
+		path    string
+		content string
+	}{
+		{t.scriptPath(repoPath), t.script()},
+		{t.hookPath(repoPath), t.hook(values)},
+	}
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.path), os.ModePerm); err != nil {
+			return err
+		} else if err = ioutil.WriteFile(f.path, []byte(f.content), 0777); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncRepoGitHooks writes again the hook files of the templates enabled in
+// the repository.
+func syncRepoGitHooks(repo *Repository) error {
+	hooks, err := GetRepoGitHooks(repo.ID)
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		if err = writeRepoGitHook(repo.RepoPath(), hook.Template, hook.ParameterValues()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getRepoGitHooksByTemplateID(templateID int64) ([]*RepoGitHook, error) {
+	hooks := make([]*RepoGitHook, 0, 10)
+	return hooks, x.Where("template_id = ?", templateID).Find(&hooks)
+}
+
+// GetRepoGitHooks returns the Git hook templates enabled in the repository.
+func GetRepoGitHooks(repoID int64) ([]*RepoGitHook, error) {
+	hooks := make([]*RepoGitHook, 0, 5)
+	if err := x.Where("repo_id = ?", repoID).Find(&hooks); err != nil {
+		return nil, err
+	}
+	for _, hook := range hooks {
+		t, err := GetGitHookTemplateByID(hook.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		hook.Template = t
+	}
+	return hooks, nil
+}
+
+// EnableRepoGitHook enables a Git hook template in the repository with given
+// parameter values, or updates its parameter values if it is already enabled.
+func EnableRepoGitHook(repo *Repository, templateID int64, values map[string]string) error {
+	t, err := GetGitHookTemplateByID(templateID)
+	if err != nil {
+		return err
+	}
+
+	params := make(map[string]string)
+	for _, name := range t.ParameterNames() {
+		params[name] = values[name]
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	hook := &RepoGitHook{RepoID: repo.ID, TemplateID: t.ID}
+	has, err := x.Get(hook)
+	if err != nil {
+		return err
+	}
+	hook.Parameters = string(data)
+	if has {
+		_, err = x.Id(hook.ID).Cols("parameters").Update(hook)
+	} else {
+		_, err = x.Insert(hook)
+	}
+	if err != nil {
+		return err
+	}
+	return writeRepoGitHook(repo.RepoPath(), t, params)
+}
+
+// DisableRepoGitHook disables a Git hook template in the repository.
+func DisableRepoGitHook(repo *Repository, templateID int64) error {
+	t, err := GetGitHookTemplateByID(templateID)
+	if err != nil {
+		return err
+	}
+
+	if _, err = x.Delete(&RepoGitHook{RepoID: repo.ID, TemplateID: t.ID}); err != nil {
+		return err
+	}
+	return t.removeRepoGitHook(repo.RepoPath())
+}
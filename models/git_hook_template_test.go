@@ -0,0 +1,108 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitHookTemplate_Script(t *testing.T) {
+	tpl := &GitHookTemplate{
+		ID:         3,
+		Content:    "#!/usr/bin/env python3\r\nimport os\n",
+		Parameters: "BRANCH,LIMIT",
+	}
+	assert.Equal(t, "#!/usr/bin/env python3\nimport os\n", tpl.script())
+	assert.Equal(t, "#!/usr/bin/env "+setting.ScriptType+"\nexec env BRANCH='it'\\''s' LIMIT='' \"$(dirname \"$0\")/../templates/template-3\" \"$@\"\n",
+		tpl.hook(map[string]string{"BRANCH": "it's"}))
+
+	tpl = &GitHookTemplate{Content: "exit 0\n"}
+	assert.Equal(t, "#!/usr/bin/env "+setting.ScriptType+"\nexit 0\n", tpl.script())
+}
+
+func TestNewGitHookTemplate(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	tpl := &GitHookTemplate{Name: "Size limit", HookName: "pre-receive", Content: "exit 0", Parameters: " max_size , ,LIMIT"}
+	assert.NoError(t, NewGitHookTemplate(tpl))
+	assert.Equal(t, []string{"MAX_SIZE", "LIMIT"}, tpl.ParameterNames())
+
+	err := NewGitHookTemplate(&GitHookTemplate{Name: "Size limit", HookName: "update"})
+	assert.True(t, IsErrGitHookTemplateAlreadyExist(err))
+
+	err = NewGitHookTemplate(&GitHookTemplate{Name: "Invalid", HookName: "update", Parameters: "NOT-VALID"})
+	assert.True(t, IsErrGitHookParameterInvalid(err))
+
+	err = NewGitHookTemplate(&GitHookTemplate{Name: "Invalid", HookName: "pre-commit"})
+	assert.Error(t, err)
+}
+
+func TestEnableRepoGitHook(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	root, err := ioutil.TempDir("", "repo-git-hooks")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+	oldRoot := setting.RepoRootPath
+	setting.RepoRootPath = root
+	defer func() { setting.RepoRootPath = oldRoot }()
+
+	tpl := &GitHookTemplate{Name: "Branch guard", HookName: "update", Content: "echo $BRANCH", Parameters: "BRANCH"}
+	assert.NoError(t, NewGitHookTemplate(tpl))
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.NoError(t, EnableRepoGitHook(repo, tpl.ID, map[string]string{"BRANCH": "master", "OTHER": "ignored"}))
+	hook := AssertExistsAndLoadBean(t, &RepoGitHook{RepoID: 1, TemplateID: tpl.ID}).(*RepoGitHook)
+	assert.Equal(t, map[string]string{"BRANCH": "master"}, hook.ParameterValues())
+
+	hookPath := tpl.hookPath(repo.RepoPath())
+	assert.Equal(t, filepath.Join(root, "user2", "repo1.git", "hooks", "update.d"), filepath.Dir(hookPath))
+	data, err := ioutil.ReadFile(hookPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "BRANCH='master'")
+
+	// The script gets the parameters from its environment.
+	cmd := exec.Command(hookPath)
+	cmd.Env = []string{"PATH=" + os.Getenv("PATH")}
+	out, err := cmd.Output()
+	assert.NoError(t, err)
+	assert.Equal(t, "master\n", string(out))
+
+	// Updating the template rewrites the hook files.
+	tpl.HookName = "pre-receive"
+	assert.NoError(t, UpdateGitHookTemplate(tpl))
+	_, err = os.Stat(hookPath)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(tpl.hookPath(repo.RepoPath()))
+	assert.NoError(t, err)
+
+	hooks, err := GetRepoGitHooks(1)
+	assert.NoError(t, err)
+	if assert.Len(t, hooks, 1) {
+		assert.Equal(t, "Branch guard", hooks[0].Template.Name)
+	}
+
+	assert.NoError(t, DisableRepoGitHook(repo, tpl.ID))
+	AssertNotExistsBean(t, &RepoGitHook{RepoID: 1, TemplateID: tpl.ID})
+	_, err = os.Stat(tpl.hookPath(repo.RepoPath()))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(tpl.scriptPath(repo.RepoPath()))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, EnableRepoGitHook(repo, tpl.ID, nil))
+	assert.NoError(t, DeleteGitHookTemplate(tpl.ID))
+	AssertNotExistsBean(t, &GitHookTemplate{ID: tpl.ID})
+	AssertNotExistsBean(t, &RepoGitHook{TemplateID: tpl.ID})
+	_, err = os.Stat(tpl.hookPath(repo.RepoPath()))
+	assert.True(t, os.IsNotExist(err))
+}
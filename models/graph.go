@@ -5,6 +5,8 @@
 package models
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"strings"
 
@@ -17,6 +19,7 @@ type GraphItem struct {
 	Relation     string
 	Branch       string
 	Rev          string
+	Parents      []string
 	Date         string
 	Author       string
 	AuthorEmail  string
@@ -28,12 +31,60 @@ type GraphItem struct {
 // GraphItems is a list of commits from all branches
 type GraphItems []GraphItem
 
-// GetCommitGraph return a list of commit (GraphItems) from all branches
-func GetCommitGraph(r *git.Repository) (GraphItems, error) {
+// GraphPageSize is the number of commits in one page of the commit graph
+const GraphPageSize = 100
+
+// CommitsCount returns the number of commits (not relation-only lines) in the graph
+func (items GraphItems) CommitsCount() int {
+	count := 0
+	for _, item := range items {
+		if !item.OnlyRelation {
+			count++
+		}
+	}
+	return count
+}
+
+// Refs returns the full names of the references pointing to the commit, and
+// HEAD if it points to the commit.
+func (item *GraphItem) Refs() []string {
+	decoration := strings.TrimSpace(item.Branch)
+	decoration = strings.TrimSuffix(strings.TrimPrefix(decoration, "("), ")")
+	if len(decoration) == 0 {
+		return nil
+	}
+
+	var refs []string
+	for _, name := range strings.Split(decoration, ", ") {
+		switch {
+		case name == "HEAD":
+			refs = append(refs, name)
+		case strings.HasPrefix(name, "HEAD -> "):
+			refs = append(refs, "HEAD", git.BranchPrefix+strings.TrimPrefix(name, "HEAD -> "))
+		case strings.HasPrefix(name, "tag: "):
+			refs = append(refs, "refs/tags/"+strings.TrimPrefix(name, "tag: "))
+		default:
+			refs = append(refs, git.BranchPrefix+name)
+		}
+	}
+	return refs
+}
+
+// Column returns the column of the commit in the graph.
+func (item *GraphItem) Column() int {
+	return strings.Index(item.GraphAcii, "*") / 2
+}
+
+// GetCommitGraph return a page of commits (GraphItems) from all branches
+func GetCommitGraph(r *git.Repository, page int) (GraphItems, error) {
 
 	var CommitGraph []GraphItem
 
-	format := "DATA:|%d|%H|%ad|%an|%ae|%h|%s"
+	if page < 1 {
+		page = 1
+	}
+
+	format := "DATA:|%d|%H|%P|%ad|%an|%ae|%h|%s"
 
 	graphCmd := git.NewCommand("log")
 	graphCmd.AddArguments("--graph",
@@ -41,7 +92,8 @@ func GetCommitGraph(r *git.Repository) (GraphItems, error) {
 		"--all",
 		"-C",
 		"-M",
-		"-n 100",
+		fmt.Sprintf("--skip=%d", (page-1)*GraphPageSize),
+		fmt.Sprintf("--max-count=%d", GraphPageSize),
 		"--date=iso",
 		fmt.Sprintf("--pretty=format:%s", format),
 	)
@@ -50,7 +102,10 @@ func GetCommitGraph(r *git.Repository) (GraphItems, error) {
 		return CommitGraph, err
 	}
 
-	CommitGraph = make([]GraphItem, 0, 100)
+	CommitGraph = make([]GraphItem, 0, GraphPageSize)
+	if len(graph) == 0 {
+		return CommitGraph, nil
+	}
 	for _, s := range strings.Split(graph, "\n") {
 		GraphItem, err := graphItemFromString(s, r)
 		if err != nil {
@@ -62,10 +117,21 @@ func GetCommitGraph(r *git.Repository) (GraphItems, error) {
 	return CommitGraph, nil
 }
 
+// GetCommitGraphTipsHash returns a hash of the tips of all references of the
+// repository, it changes whenever the commit graph changes.
+func GetCommitGraphTipsHash(r *git.Repository) (string, error) {
+	refs, err := git.NewCommand("for-each-ref", "--format=%(objectname) %(refname)").RunInDir(r.Path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum([]byte(refs))
+	return hex.EncodeToString(sum[:]), nil
+}
+
 func graphItemFromString(s string, r *git.Repository) (GraphItem, error) {
 
 	var ascii string
-	var data = "|||||||"
+	var data = "||||||||"
 	lines := strings.Split(s, "DATA:")
 
 	switch len(lines) {
@@ -78,30 +144,32 @@ func graphItemFromString(s string, r *git.Repository) (GraphItem, error) {
 		return GraphItem{}, fmt.Errorf("Failed parsing grap line:%s. Expect 1 or two fields", s)
 	}
 
-	rows := strings.SplitN(data, "|", 8)
-	if len(rows) < 8 {
-		return GraphItem{}, fmt.Errorf("Failed parsing grap line:%s - Should containt 8 datafields", s)
+	rows := strings.SplitN(data, "|", 9)
+	if len(rows) < 9 {
+		return GraphItem{}, fmt.Errorf("Failed parsing grap line:%s - Should containt 9 datafields", s)
 	}
 
 	/* // see format in getCommitGraph()
 	   0	Relation string
 	   1	Branch string
 	   2	Rev string
-	   3	Date string
-	   4	Author string
-	   5	AuthorEmail string
-	   6	ShortRev string
-	   7	Subject string
+	   3	Parents []string
+	   4	Date string
+	   5	Author string
+	   6	AuthorEmail string
+	   7	ShortRev string
+	   8	Subject string
 	*/
 	gi := GraphItem{ascii,
 		rows[0],
 		rows[1],
 		rows[2],
-		rows[3],
+		strings.Fields(rows[3]),
 		rows[4],
 		rows[5],
 		rows[6],
 		rows[7],
+		rows[8],
 		len(rows[2]) == 0, // no commits referred to, only relation in current line.
 	}
 	return gi, nil
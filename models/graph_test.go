@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"code.gitea.io/git"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func BenchmarkGetCommitGraph(b *testing.B) {
@@ -18,7 +20,7 @@ func BenchmarkGetCommitGraph(b *testing.B) {
 	}
 
 	for i := 0; i < b.N; i++ {
-		graph, err := GetCommitGraph(currentRepo)
+		graph, err := GetCommitGraph(currentRepo, 1)
 		if err != nil {
 			b.Error("Could get commit graph")
 		}
@@ -30,7 +32,7 @@ func BenchmarkGetCommitGraph(b *testing.B) {
 }
 
 func BenchmarkParseCommitString(b *testing.B) {
-	testString := "* DATA:||4e61bacab44e9b4730e44a6615d04098dd3a8eaf|2016-12-20 21:10:41 +0100|Kjell Kvinge|kjell@kvinge.biz|4e61bac|Add route for graph"
+	testString := "* DATA:||4e61bacab44e9b4730e44a6615d04098dd3a8eaf|a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5|2016-12-20 21:10:41 +0100|Kjell Kvinge|kjell@kvinge.biz|4e61bac|Add route for graph"
 
 	for i := 0; i < b.N; i++ {
 		graphItem, err := graphItemFromString(testString, nil)
@@ -43,3 +45,34 @@ func BenchmarkParseCommitString(b *testing.B) {
 		}
 	}
 }
+
+func TestGraphItems_CommitsCount(t *testing.T) {
+	var items GraphItems
+	for _, s := range []string{
+		"* DATA:||4e61bacab44e9b4730e44a6615d04098dd3a8eaf|a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5|2016-12-20 21:10:41 +0100|Kjell Kvinge|kjell@kvinge.biz|4e61bac|Add route for graph",
+		"|\\",
+		"| * DATA:||a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5||2016-12-19 10:00:00 +0100|Kjell Kvinge|kjell@kvinge.biz|a6b7c8d|Fix graph",
+	} {
+		item, err := graphItemFromString(s, nil)
+		assert.NoError(t, err)
+		items = append(items, item)
+	}
+
+	assert.Equal(t, 2, items.CommitsCount())
+}
+
+func TestGraphItemFromString(t *testing.T) {
+	item, err := graphItemFromString("| * DATA:| (HEAD -> master, tag: v1.0, feature)|4e61bacab44e9b4730e44a6615d04098dd3a8eaf|a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5 b6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5|2016-12-20 21:10:41 +0100|Kjell Kvinge|kjell@kvinge.biz|4e61bac|Add route | for graph", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "4e61bacab44e9b4730e44a6615d04098dd3a8eaf", item.Rev)
+	assert.Equal(t, []string{"a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5", "b6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5"}, item.Parents)
+	assert.Equal(t, []string{"HEAD", "refs/heads/master", "refs/tags/v1.0", "refs/heads/feature"}, item.Refs())
+	assert.Equal(t, 1, item.Column())
+	assert.Equal(t, "Add route | for graph", item.Subject)
+
+	item, err = graphItemFromString("|\\", nil)
+	assert.NoError(t, err)
+	assert.True(t, item.OnlyRelation)
+	assert.Empty(t, item.Parents)
+	assert.Empty(t, item.Refs())
+}
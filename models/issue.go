@@ -48,6 +48,12 @@ type Issue struct {
 	IsPull          bool         `xorm:"INDEX"` // Indicates whether is a pull request or not.
 	PullRequest     *PullRequest `xorm:"-"`
 	NumComments     int
+	// PinOrder is the position of the issue among the pinned issues of the
+	// repository, 0 if it is not pinned.
+	PinOrder int `xorm:"NOT NULL DEFAULT 0"`
+	// IsConfidential limits who can see the issue to its poster and the
+	// users with write access to the repository, e.g. for security reports.
+	IsConfidential bool `xorm:"INDEX NOT NULL DEFAULT false"`
 
 	Deadline     time.Time `xorm:"-"`
 	DeadlineUnix int64     `xorm:"INDEX"`
@@ -173,13 +179,6 @@ func (issue *Issue) loadAttributes(e Engine) (err error) {
 		}
 	}
 
-	if issue.Comments == nil {
-		issue.Comments, err = getCommentsByIssueID(e, issue.ID)
-		if err != nil {
-			return fmt.Errorf("getCommentsByIssueID [%d]: %v", issue.ID, err)
-		}
-	}
-
 	return nil
 }
 
@@ -241,10 +240,18 @@ func (issue *Issue) State() api.StateType {
 	return api.StateOpen
 }
 
+// APIIssue represents an issue in the API, with the fields the SDK does not
+// know about yet.
+type APIIssue struct {
+	*api.Issue
+	PinOrder     int  `json:"pin_order"`
+	Confidential bool `json:"confidential"`
+}
+
 // APIFormat assumes some fields assigned with values:
 // Required - Poster, Labels,
 // Optional - Milestone, Assignee, PullRequest
-func (issue *Issue) APIFormat() *api.Issue {
+func (issue *Issue) APIFormat() *APIIssue {
 	apiLabels := make([]*api.Label, len(issue.Labels))
 	for i := range issue.Labels {
 		apiLabels[i] = issue.Labels[i].APIFormat()
@@ -279,7 +286,11 @@ func (issue *Issue) APIFormat() *api.Issue {
 		}
 	}
 
-	return apiIssue
+	return &APIIssue{
+		Issue:        apiIssue,
+		PinOrder:     issue.PinOrder,
+		Confidential: issue.IsConfidential,
+	}
 }
 
 // HashTag returns unique hash tag for issue.
@@ -309,13 +320,26 @@ func (issue *Issue) sendLabelUpdatedWebhook(doer *User) {
 			log.Error(4, "LoadIssue: %v", err)
 			return
 		}
-		err = PrepareWebhooks(issue.Repo, HookEventPullRequest, &api.PullRequestPayload{
+		err = PrepareWebhooks(issue.Repo, HookEventPullRequest, &PullRequestPayload{
 			Action:      api.HookIssueLabelUpdated,
 			Index:       issue.Index,
 			PullRequest: issue.PullRequest.APIFormat(),
 			Repository:  issue.Repo.APIFormat(AccessModeNone),
 			Sender:      doer.APIFormat(),
 		})
+	} else {
+		issue.Labels, err = getLabelsByIssueID(x, issue.ID)
+		if err != nil {
+			log.Error(4, "getLabelsByIssueID: %v", err)
+			return
+		}
+		err = PrepareWebhooks(issue.Repo, HookEventIssues, &IssuePayload{
+			Action:     api.HookIssueLabelUpdated,
+			Index:      issue.Index,
+			Issue:      issue.APIFormat(),
+			Repository: issue.Repo.APIFormat(AccessModeNone),
+			Sender:     doer.APIFormat(),
+		})
 	}
 	if err != nil {
 		log.Error(4, "PrepareWebhooks [is_pull: %v]: %v", issue.IsPull, err)
@@ -374,7 +398,7 @@ func (issue *Issue) RemoveLabel(doer *User, label *Label) error {
 		return err
 	}
 
-	if has, err := HasAccess(doer.ID, issue.Repo, AccessModeWrite); err != nil {
+	if has, err := HasAccess(doer.ID, issue.Repo, AccessModeTriage); err != nil {
 		return err
 	} else if !has {
 		return ErrLabelNotExist{}
@@ -415,7 +439,7 @@ func (issue *Issue) ClearLabels(doer *User) (err error) {
 		return err
 	}
 
-	if has, err := hasAccess(sess, doer.ID, issue.Repo, AccessModeWrite); err != nil {
+	if has, err := hasAccess(sess, doer.ID, issue.Repo, AccessModeTriage); err != nil {
 		return err
 	} else if !has {
 		return ErrLabelNotExist{}
@@ -435,13 +459,22 @@ func (issue *Issue) ClearLabels(doer *User) (err error) {
 			log.Error(4, "LoadIssue: %v", err)
 			return
 		}
-		err = PrepareWebhooks(issue.Repo, HookEventPullRequest, &api.PullRequestPayload{
+		err = PrepareWebhooks(issue.Repo, HookEventPullRequest, &PullRequestPayload{
 			Action:      api.HookIssueLabelCleared,
 			Index:       issue.Index,
 			PullRequest: issue.PullRequest.APIFormat(),
 			Repository:  issue.Repo.APIFormat(AccessModeNone),
 			Sender:      doer.APIFormat(),
 		})
+	} else {
+		issue.Labels = nil
+		err = PrepareWebhooks(issue.Repo, HookEventIssues, &IssuePayload{
+			Action:     api.HookIssueLabelCleared,
+			Index:      issue.Index,
+			Issue:      issue.APIFormat(),
+			Repository: issue.Repo.APIFormat(AccessModeNone),
+			Sender:     doer.APIFormat(),
+		})
 	}
 	if err != nil {
 		log.Error(4, "PrepareWebhooks [is_pull: %v]: %v", issue.IsPull, err)
@@ -556,7 +589,7 @@ func UpdateIssueCols(issue *Issue, cols ...string) error {
 	return updateIssueCols(x, issue, cols...)
 }
 
-func (issue *Issue) changeStatus(e *xorm.Session, doer *User, repo *Repository, isClosed bool) (err error) {
+func (issue *Issue) changeStatus(e *xorm.Session, doer *User, repo *Repository, isClosed bool, commitSHA string) (err error) {
 	// Nothing should be performed if current status is same as target status
 	if issue.IsClosed == isClosed {
 		return nil
@@ -587,8 +620,12 @@ func (issue *Issue) changeStatus(e *xorm.Session, doer *User, repo *Repository,
 		return err
 	}
 
+	if err = moveIssueOnBoards(e, issue); err != nil {
+		return fmt.Errorf("moveIssueOnBoards: %v", err)
+	}
+
 	// New action comment
-	if _, err = createStatusComment(e, doer, repo, issue); err != nil {
+	if _, err = createStatusComment(e, doer, repo, issue, commitSHA); err != nil {
 		return err
 	}
 
@@ -596,14 +633,20 @@ func (issue *Issue) changeStatus(e *xorm.Session, doer *User, repo *Repository,
 }
 
 // ChangeStatus changes issue status to open or closed.
-func (issue *Issue) ChangeStatus(doer *User, repo *Repository, isClosed bool) (err error) {
+func (issue *Issue) ChangeStatus(doer *User, repo *Repository, isClosed bool) error {
+	return issue.changeStatusByCommit(doer, repo, isClosed, "")
+}
+
+// changeStatusByCommit changes issue status to open or closed, the status
+// comment links to the commit whose message changed it if any.
+func (issue *Issue) changeStatusByCommit(doer *User, repo *Repository, isClosed bool, commitSHA string) (err error) {
 	sess := x.NewSession()
 	defer sessionRelease(sess)
 	if err = sess.Begin(); err != nil {
 		return err
 	}
 
-	if err = issue.changeStatus(sess, doer, repo, isClosed); err != nil {
+	if err = issue.changeStatus(sess, doer, repo, isClosed, commitSHA); err != nil {
 		return err
 	}
 
@@ -611,10 +654,18 @@ func (issue *Issue) ChangeStatus(doer *User, repo *Repository, isClosed bool) (e
 		return fmt.Errorf("Commit: %v", err)
 	}
 
+	if !issue.IsPull {
+		if isClosed {
+			mailServiceDeskReporter(issue, ServiceDeskStatusClosed)
+		} else {
+			mailServiceDeskReporter(issue, ServiceDeskStatusReopened)
+		}
+	}
+
 	if issue.IsPull {
 		// Merge pull request calls issue.changeStatus so we need to handle separately.
 		issue.PullRequest.Issue = issue
-		apiPullRequest := &api.PullRequestPayload{
+		apiPullRequest := &PullRequestPayload{
 			Index:       issue.Index,
 			PullRequest: issue.PullRequest.APIFormat(),
 			Repository:  repo.APIFormat(AccessModeNone),
@@ -626,6 +677,19 @@ func (issue *Issue) ChangeStatus(doer *User, repo *Repository, isClosed bool) (e
 			apiPullRequest.Action = api.HookIssueReOpened
 		}
 		err = PrepareWebhooks(repo, HookEventPullRequest, apiPullRequest)
+	} else {
+		apiIssue := &IssuePayload{
+			Index:      issue.Index,
+			Issue:      issue.APIFormat(),
+			Repository: repo.APIFormat(AccessModeNone),
+			Sender:     doer.APIFormat(),
+		}
+		if isClosed {
+			apiIssue.Action = api.HookIssueClosed
+		} else {
+			apiIssue.Action = api.HookIssueReOpened
+		}
+		err = PrepareWebhooks(repo, HookEventIssues, apiIssue)
 	}
 	if err != nil {
 		log.Error(4, "PrepareWebhooks [is_pull: %v, is_closed: %v]: %v", issue.IsPull, isClosed, err)
@@ -661,10 +725,10 @@ func (issue *Issue) ChangeTitle(doer *User, title string) (err error) {
 
 	if issue.IsPull {
 		issue.PullRequest.Issue = issue
-		err = PrepareWebhooks(issue.Repo, HookEventPullRequest, &api.PullRequestPayload{
+		err = PrepareWebhooks(issue.Repo, HookEventPullRequest, &PullRequestPayload{
 			Action: api.HookIssueEdited,
 			Index:  issue.Index,
-			Changes: &api.ChangesPayload{
+			Changes: &ChangesPayload{
 				Title: &api.ChangesFromPayload{
 					From: oldTitle,
 				},
@@ -673,6 +737,19 @@ func (issue *Issue) ChangeTitle(doer *User, title string) (err error) {
 			Repository:  issue.Repo.APIFormat(AccessModeNone),
 			Sender:      doer.APIFormat(),
 		})
+	} else {
+		err = PrepareWebhooks(issue.Repo, HookEventIssues, &IssuePayload{
+			Action: api.HookIssueEdited,
+			Index:  issue.Index,
+			Changes: &ChangesPayload{
+				Title: &api.ChangesFromPayload{
+					From: oldTitle,
+				},
+			},
+			Issue:      issue.APIFormat(),
+			Repository: issue.Repo.APIFormat(AccessModeNone),
+			Sender:     doer.APIFormat(),
+		})
 	}
 	if err != nil {
 		log.Error(4, "PrepareWebhooks [is_pull: %v]: %v", issue.IsPull, err)
@@ -703,18 +780,26 @@ func AddDeletePRBranchComment(doer *User, repo *Repository, issueID int64, branc
 
 // ChangeContent changes issue content, as the given user.
 func (issue *Issue) ChangeContent(doer *User, content string) (err error) {
+	if err = checkIssueAttachmentQuota(x, issue.ID, nil, content); err != nil {
+		return err
+	}
+
 	oldContent := issue.Content
 	issue.Content = content
 	if err = UpdateIssueCols(issue, "content"); err != nil {
 		return fmt.Errorf("UpdateIssueCols: %v", err)
+	} else if err = linkContentAttachments(x, doer, issue.ID, 0, content); err != nil {
+		return fmt.Errorf("linkContentAttachments: %v", err)
 	}
 
+	addCrossReferences(doer, issue, nil, content)
+
 	if issue.IsPull {
 		issue.PullRequest.Issue = issue
-		err = PrepareWebhooks(issue.Repo, HookEventPullRequest, &api.PullRequestPayload{
+		err = PrepareWebhooks(issue.Repo, HookEventPullRequest, &PullRequestPayload{
 			Action: api.HookIssueEdited,
 			Index:  issue.Index,
-			Changes: &api.ChangesPayload{
+			Changes: &ChangesPayload{
 				Body: &api.ChangesFromPayload{
 					From: oldContent,
 				},
@@ -723,6 +808,19 @@ func (issue *Issue) ChangeContent(doer *User, content string) (err error) {
 			Repository:  issue.Repo.APIFormat(AccessModeNone),
 			Sender:      doer.APIFormat(),
 		})
+	} else {
+		err = PrepareWebhooks(issue.Repo, HookEventIssues, &IssuePayload{
+			Action: api.HookIssueEdited,
+			Index:  issue.Index,
+			Changes: &ChangesPayload{
+				Body: &api.ChangesFromPayload{
+					From: oldContent,
+				},
+			},
+			Issue:      issue.APIFormat(),
+			Repository: issue.Repo.APIFormat(AccessModeNone),
+			Sender:     doer.APIFormat(),
+		})
 	}
 	if err != nil {
 		log.Error(4, "PrepareWebhooks [is_pull: %v]: %v", issue.IsPull, err)
@@ -762,7 +860,7 @@ func (issue *Issue) ChangeAssignee(doer *User, assigneeID int64) (err error) {
 	isRemoveAssignee := err != nil
 	if issue.IsPull {
 		issue.PullRequest.Issue = issue
-		apiPullRequest := &api.PullRequestPayload{
+		apiPullRequest := &PullRequestPayload{
 			Index:       issue.Index,
 			PullRequest: issue.PullRequest.APIFormat(),
 			Repository:  issue.Repo.APIFormat(AccessModeNone),
@@ -777,6 +875,22 @@ func (issue *Issue) ChangeAssignee(doer *User, assigneeID int64) (err error) {
 			log.Error(4, "PrepareWebhooks [is_pull: %v, remove_assignee: %v]: %v", issue.IsPull, isRemoveAssignee, err)
 			return nil
 		}
+	} else {
+		apiIssue := &IssuePayload{
+			Index:      issue.Index,
+			Issue:      issue.APIFormat(),
+			Repository: issue.Repo.APIFormat(AccessModeNone),
+			Sender:     doer.APIFormat(),
+		}
+		if isRemoveAssignee {
+			apiIssue.Action = api.HookIssueUnassigned
+		} else {
+			apiIssue.Action = api.HookIssueAssigned
+		}
+		if err := PrepareWebhooks(issue.Repo, HookEventIssues, apiIssue); err != nil {
+			log.Error(4, "PrepareWebhooks [is_pull: %v, remove_assignee: %v]: %v", issue.IsPull, isRemoveAssignee, err)
+			return nil
+		}
 	}
 	go HookQueue.Add(issue.RepoID)
 	return nil
@@ -879,6 +993,9 @@ func newIssue(e *xorm.Session, doer *User, opts NewIssueOptions) (err error) {
 
 	UpdateIssueIndexer(opts.Issue)
 
+	if err = checkIssueAttachmentQuota(e, opts.Issue.ID, opts.Attachments, opts.Issue.Content); err != nil {
+		return err
+	}
 	if len(opts.Attachments) > 0 {
 		attachments, err := getAttachmentsByUUIDs(e, opts.Attachments)
 		if err != nil {
@@ -892,12 +1009,22 @@ func newIssue(e *xorm.Session, doer *User, opts NewIssueOptions) (err error) {
 			}
 		}
 	}
+	if err = linkContentAttachments(e, opts.Issue.Poster, opts.Issue.ID, 0, opts.Issue.Content); err != nil {
+		return fmt.Errorf("linkContentAttachments: %v", err)
+	}
 
 	return opts.Issue.loadAttributes(e)
 }
 
 // NewIssue creates new issue with labels for repository.
 func NewIssue(repo *Repository, issue *Issue, labelIDs []int64, uuids []string) (err error) {
+	if err = checkContent(repo, 0, issue.Poster, issue.Title, issue.Content); err != nil {
+		return err
+	}
+	return createIssue(repo, issue, labelIDs, uuids)
+}
+
+func createIssue(repo *Repository, issue *Issue, labelIDs []int64, uuids []string) (err error) {
 	sess := x.NewSession()
 	defer sessionRelease(sess)
 	if err = sess.Begin(); err != nil {
@@ -917,21 +1044,40 @@ func NewIssue(repo *Repository, issue *Issue, labelIDs []int64, uuids []string)
 		return fmt.Errorf("Commit: %v", err)
 	}
 
-	if err = NotifyWatchers(&Action{
-		ActUserID: issue.Poster.ID,
-		ActUser:   issue.Poster,
-		OpType:    ActionCreateIssue,
-		Content:   fmt.Sprintf("%d|%s", issue.Index, issue.Title),
-		RepoID:    repo.ID,
-		Repo:      repo,
-		IsPrivate: repo.IsPrivate,
-	}); err != nil {
-		log.Error(4, "NotifyWatchers: %v", err)
+	addCrossReferences(issue.Poster, issue, nil, issue.Content)
+
+	// Feeds are not limited to the users who can see confidential issues.
+	if !issue.IsConfidential {
+		if err = NotifyWatchers(&Action{
+			ActUserID: issue.Poster.ID,
+			ActUser:   issue.Poster,
+			OpType:    ActionCreateIssue,
+			Content:   fmt.Sprintf("%d|%s", issue.Index, issue.Title),
+			RepoID:    repo.ID,
+			Repo:      repo,
+			IsPrivate: repo.IsPrivate,
+		}); err != nil {
+			log.Error(4, "NotifyWatchers: %v", err)
+		}
 	}
 	if err = issue.MailParticipants(); err != nil {
 		log.Error(4, "MailParticipants: %v", err)
 	}
 
+	issue.Repo = repo
+	if err = issue.loadLabels(x); err != nil {
+		log.Error(4, "loadLabels: %v", err)
+	} else if err = PrepareWebhooks(repo, HookEventIssues, &IssuePayload{
+		Action:     api.HookIssueOpened,
+		Index:      issue.Index,
+		Issue:      issue.APIFormat(),
+		Repository: repo.APIFormat(AccessModeNone),
+		Sender:     issue.Poster.APIFormat(),
+	}); err != nil {
+		log.Error(4, "PrepareWebhooks: %v", err)
+	}
+	go HookQueue.Add(repo.ID)
+
 	return nil
 }
 
@@ -953,7 +1099,7 @@ func GetIssueByRef(ref string) (*Issue, error) {
 		return nil, err
 	}
 
-	issue, err := GetIssueByIndex(repo.ID, index)
+	issue, err := GetRawIssueByIndex(repo.ID, index)
 	if err != nil {
 		return nil, err
 	}
@@ -976,12 +1122,19 @@ func GetRawIssueByIndex(repoID, index int64) (*Issue, error) {
 	return issue, nil
 }
 
-// GetIssueByIndex returns issue by index in a repository.
-func GetIssueByIndex(repoID, index int64) (*Issue, error) {
+// GetIssueByIndex returns issue by index in a repository, confidential
+// issues doer cannot see do not exist for doer, which is nil for anonymous
+// users.
+func GetIssueByIndex(repoID, index int64, doer *User) (*Issue, error) {
 	issue, err := GetRawIssueByIndex(repoID, index)
 	if err != nil {
 		return nil, err
 	}
+	if visible, err := issue.IsVisibleTo(doer); err != nil {
+		return nil, err
+	} else if !visible {
+		return nil, ErrIssueNotExist{0, repoID, index}
+	}
 	return issue, issue.LoadAttributes()
 }
 
@@ -1020,11 +1173,19 @@ type IssuesOptions struct {
 	MilestoneID int64
 	RepoIDs     []int64
 	Page        int
+	PageSize    int // setting.UI.IssuePagingNum if not set
 	IsClosed    util.OptionalBool
 	IsPull      util.OptionalBool
 	Labels      string
 	SortType    string
 	IssueIDs    []int64
+	// IsOverdue limits the results to open issues past their deadline.
+	IsOverdue bool
+	// Doer limits the confidential issues to the ones doer can see, nil for
+	// anonymous users.
+	Doer *User
+	// AllConfidential includes all confidential issues regardless of Doer.
+	AllConfidential bool
 }
 
 // sortIssuesSession sort an issues-related session based on the provided
@@ -1043,27 +1204,18 @@ func sortIssuesSession(sess *xorm.Session, sortType string) {
 		sess.Asc("issue.num_comments")
 	case "priority":
 		sess.Desc("issue.priority")
+	case "nearestdeadline":
+		// Issues without a deadline come last.
+		sess.OrderBy("CASE WHEN issue.deadline_unix > 0 THEN 0 ELSE 1 END, issue.deadline_unix ASC")
+	case "farthestdeadline":
+		sess.Desc("issue.deadline_unix")
 	default:
 		sess.Desc("issue.created_unix")
 	}
 }
 
-// Issues returns a list of issues by given conditions.
-func Issues(opts *IssuesOptions) ([]*Issue, error) {
-	var sess *xorm.Session
-	if opts.Page >= 0 {
-		var start int
-		if opts.Page == 0 {
-			start = 0
-		} else {
-			start = (opts.Page - 1) * setting.UI.IssuePagingNum
-		}
-		sess = x.Limit(setting.UI.IssuePagingNum, start)
-	} else {
-		sess = x.NewSession()
-		defer sess.Close()
-	}
-
+// setupIssuesSession adds the conditions of given options to the session.
+func setupIssuesSession(sess *xorm.Session, opts *IssuesOptions) error {
 	if len(opts.IssueIDs) > 0 {
 		sess.In("issue.id", opts.IssueIDs)
 	}
@@ -1107,12 +1259,18 @@ func Issues(opts *IssuesOptions) ([]*Issue, error) {
 		sess.And("issue.is_pull=?", false)
 	}
 
-	sortIssuesSession(sess, opts.SortType)
+	if opts.IsOverdue {
+		sess.And("issue.is_closed=? AND issue.deadline_unix>0 AND issue.deadline_unix<?", false, time.Now().Unix())
+	}
+
+	if !opts.AllConfidential {
+		sess.And(visibleIssueCond(opts.Doer))
+	}
 
 	if len(opts.Labels) > 0 && opts.Labels != "0" {
 		labelIDs, err := base.StringsToInt64s(strings.Split(opts.Labels, ","))
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if len(labelIDs) > 0 {
 			sess.
@@ -1120,8 +1278,36 @@ func Issues(opts *IssuesOptions) ([]*Issue, error) {
 				In("issue_label.label_id", labelIDs)
 		}
 	}
+	return nil
+}
+
+// Issues returns a list of issues by given conditions.
+func Issues(opts *IssuesOptions) ([]*Issue, error) {
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = setting.UI.IssuePagingNum
+	}
+
+	var sess *xorm.Session
+	if opts.Page >= 0 {
+		var start int
+		if opts.Page == 0 {
+			start = 0
+		} else {
+			start = (opts.Page - 1) * pageSize
+		}
+		sess = x.Limit(pageSize, start)
+	} else {
+		sess = x.NewSession()
+		defer sess.Close()
+	}
+
+	if err := setupIssuesSession(sess, opts); err != nil {
+		return nil, err
+	}
+	sortIssuesSession(sess, opts.SortType)
 
-	issues := make([]*Issue, 0, setting.UI.IssuePagingNum)
+	issues := make([]*Issue, 0, pageSize)
 	if err := sess.Find(&issues); err != nil {
 		return nil, fmt.Errorf("Find: %v", err)
 	}
@@ -1133,6 +1319,18 @@ func Issues(opts *IssuesOptions) ([]*Issue, error) {
 	return issues, nil
 }
 
+// CountIssues returns the number of issues matching given conditions,
+// regardless of the page.
+func CountIssues(opts *IssuesOptions) (int64, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+
+	if err := setupIssuesSession(sess, opts); err != nil {
+		return 0, err
+	}
+	return sess.Count(new(Issue))
+}
+
 // GetParticipantsByIssueID returns all users who are participated in comments of an issue.
 func GetParticipantsByIssueID(issueID int64) ([]*User, error) {
 	userIDs := make([]int64, 0, 5)
@@ -1232,6 +1430,8 @@ type IssueStatsOptions struct {
 	PosterID    int64
 	IsPull      bool
 	IssueIDs    []int64
+	IsOverdue   bool
+	Doer        *User // nil for anonymous users
 }
 
 // GetIssueStats returns issue statistic information by given conditions.
@@ -1275,6 +1475,12 @@ func GetIssueStats(opts *IssueStatsOptions) (*IssueStats, error) {
 				And("issue_user.is_mentioned = ?", true)
 		}
 
+		if opts.IsOverdue {
+			sess.And("issue.is_closed = ? AND issue.deadline_unix > 0 AND issue.deadline_unix < ?", false, time.Now().Unix())
+		}
+
+		sess.And(visibleIssueCond(opts.Doer))
+
 		return sess
 	}
 
@@ -46,6 +46,12 @@ const (
 	CommentTypeChangeTitle
 	// Delete Branch
 	CommentTypeDeleteBranch
+	// Pin issue to the top of the issue list
+	CommentTypePin
+	// Unpin issue
+	CommentTypeUnpin
+	// Set, change or remove the deadline of the issue
+	CommentTypeDeadline
 )
 
 // CommentTag defines comment tag type
@@ -61,11 +67,11 @@ const (
 
 // Comment represents a comment in commit and issue page.
 type Comment struct {
-	ID             int64 `xorm:"pk autoincr"`
-	Type           CommentType
-	PosterID       int64 `xorm:"INDEX"`
-	Poster         *User `xorm:"-"`
-	IssueID        int64 `xorm:"INDEX"`
+	ID             int64       `xorm:"pk autoincr"`
+	Type           CommentType `xorm:"INDEX"`
+	PosterID       int64       `xorm:"INDEX"`
+	Poster         *User       `xorm:"-"`
+	IssueID        int64       `xorm:"INDEX"`
 	LabelID        int64
 	Label          *Label `xorm:"-"`
 	OldMilestoneID int64
@@ -92,6 +98,12 @@ type Comment struct {
 	// Reference issue in commit message
 	CommitSHA string `xorm:"VARCHAR(40)"`
 
+	// Reference from another issue, pull request or comment
+	RefRepoID    int64
+	RefIssueID   int64 `xorm:"INDEX"`
+	RefCommentID int64
+	RefIssue     *Issue `xorm:"-"`
+
 	Attachments []*Attachment `xorm:"-"`
 
 	// For view issue page.
@@ -198,6 +210,43 @@ func (c *Comment) APIFormat() *api.Comment {
 	}
 }
 
+func (c *Comment) loadPoster(e Engine) (err error) {
+	if c.Poster == nil {
+		c.Poster, err = getUserByID(e, c.PosterID)
+		if err != nil {
+			if !IsErrUserNotExist(err) {
+				return fmt.Errorf("getUserByID [%d]: %v", c.PosterID, err)
+			}
+			c.Poster = NewGhostUser()
+		}
+	}
+	return nil
+}
+
+func (c *Comment) sendWebhook(doer *User, action HookIssueCommentAction, changes *ChangesPayload) {
+	issue, err := GetIssueByID(c.IssueID)
+	if err != nil {
+		log.Error(4, "GetIssueByID [%d]: %v", c.IssueID, err)
+		return
+	} else if err = c.loadPoster(x); err != nil {
+		log.Error(4, "loadPoster: %v", err)
+		return
+	}
+
+	if err = PrepareWebhooks(issue.Repo, HookEventIssueComment, &IssueCommentPayload{
+		Action:     action,
+		Issue:      issue.APIFormat(),
+		Comment:    c.APIFormat(),
+		Changes:    changes,
+		Repository: issue.Repo.APIFormat(AccessModeNone),
+		Sender:     doer.APIFormat(),
+	}); err != nil {
+		log.Error(4, "PrepareWebhooks [comment_id: %d]: %v", c.ID, err)
+	} else {
+		go HookQueue.Add(issue.RepoID)
+	}
+}
+
 // HashTag returns unique hash tag for comment.
 func (c *Comment) HashTag() string {
 	return "issuecomment-" + com.ToStr(c.ID)
@@ -317,6 +366,9 @@ func createComment(e *xorm.Session, opts *CreateCommentOptions) (_ *Comment, err
 		Content:        opts.Content,
 		OldTitle:       opts.OldTitle,
 		NewTitle:       opts.NewTitle,
+		RefRepoID:      opts.RefRepoID,
+		RefIssueID:     opts.RefIssueID,
+		RefCommentID:   opts.RefCommentID,
 	}
 	if _, err = e.Insert(comment); err != nil {
 		return nil, err
@@ -347,6 +399,9 @@ func createComment(e *xorm.Session, opts *CreateCommentOptions) (_ *Comment, err
 		}
 
 		// Check attachments
+		if err = checkIssueAttachmentQuota(e, opts.Issue.ID, opts.Attachments, opts.Content); err != nil {
+			return nil, err
+		}
 		attachments := make([]*Attachment, 0, len(opts.Attachments))
 		for _, uuid := range opts.Attachments {
 			attach, err := getAttachmentByUUID(e, uuid)
@@ -367,6 +422,9 @@ func createComment(e *xorm.Session, opts *CreateCommentOptions) (_ *Comment, err
 				return nil, fmt.Errorf("update attachment [%d]: %v", attachments[i].ID, err)
 			}
 		}
+		if err = linkContentAttachments(e, opts.Doer, opts.Issue.ID, comment.ID, opts.Content); err != nil {
+			return nil, fmt.Errorf("linkContentAttachments: %v", err)
+		}
 
 	case CommentTypeReopen:
 		act.OpType = ActionReopenIssue
@@ -402,8 +460,11 @@ func createComment(e *xorm.Session, opts *CreateCommentOptions) (_ *Comment, err
 
 	// Notify watchers for whatever action comes in, ignore if no action type.
 	if act.OpType > 0 {
-		if err = notifyWatchers(e, act); err != nil {
-			log.Error(4, "notifyWatchers: %v", err)
+		// Feeds are not limited to the users who can see confidential issues.
+		if !opts.Issue.IsConfidential {
+			if err = notifyWatchers(e, act); err != nil {
+				log.Error(4, "notifyWatchers: %v", err)
+			}
 		}
 		if err = comment.MailParticipants(e, act.OpType, opts.Issue); err != nil {
 			log.Error(4, "MailParticipants: %v", err)
@@ -413,16 +474,17 @@ func createComment(e *xorm.Session, opts *CreateCommentOptions) (_ *Comment, err
 	return comment, nil
 }
 
-func createStatusComment(e *xorm.Session, doer *User, repo *Repository, issue *Issue) (*Comment, error) {
+func createStatusComment(e *xorm.Session, doer *User, repo *Repository, issue *Issue, commitSHA string) (*Comment, error) {
 	cmtType := CommentTypeClose
 	if !issue.IsClosed {
 		cmtType = CommentTypeReopen
 	}
 	return createComment(e, &CreateCommentOptions{
-		Type:  cmtType,
-		Doer:  doer,
-		Repo:  repo,
-		Issue: issue,
+		Type:      cmtType,
+		Doer:      doer,
+		Repo:      repo,
+		Issue:     issue,
+		CommitSHA: commitSHA,
 	})
 }
 
@@ -484,6 +546,26 @@ func createDeleteBranchComment(e *xorm.Session, doer *User, repo *Repository, is
 	})
 }
 
+func createDeadlineComment(e *xorm.Session, doer *User, issue *Issue, oldDeadline, newDeadline string) (*Comment, error) {
+	return createComment(e, &CreateCommentOptions{
+		Type:     CommentTypeDeadline,
+		Doer:     doer,
+		Repo:     issue.Repo,
+		Issue:    issue,
+		OldTitle: oldDeadline,
+		NewTitle: newDeadline,
+	})
+}
+
+func createPinComment(e *xorm.Session, doer *User, issue *Issue, cmtType CommentType) (*Comment, error) {
+	return createComment(e, &CreateCommentOptions{
+		Type:  cmtType,
+		Doer:  doer,
+		Repo:  issue.Repo,
+		Issue: issue,
+	})
+}
+
 // CreateCommentOptions defines options for creating comment
 type CreateCommentOptions struct {
 	Type  CommentType
@@ -503,6 +585,9 @@ type CreateCommentOptions struct {
 	LineNum        int64
 	Content        string
 	Attachments    []string // UUIDs of attachments
+	RefRepoID      int64
+	RefIssueID     int64
+	RefCommentID   int64
 }
 
 // CreateComment creates comment of issue or commit.
@@ -518,11 +603,22 @@ func CreateComment(opts *CreateCommentOptions) (comment *Comment, err error) {
 		return nil, err
 	}
 
-	return comment, sess.Commit()
+	if err = sess.Commit(); err != nil {
+		return nil, err
+	}
+
+	if opts.Type == CommentTypeComment {
+		addCrossReferences(opts.Doer, opts.Issue, comment, opts.Content)
+		comment.sendWebhook(opts.Doer, HookIssueCommentCreated, nil)
+	}
+	return comment, nil
 }
 
 // CreateIssueComment creates a plain issue comment.
 func CreateIssueComment(doer *User, repo *Repository, issue *Issue, content string, attachments []string) (*Comment, error) {
+	if err := checkContent(repo, issue.ID, doer, "", content); err != nil {
+		return nil, err
+	}
 	return CreateComment(&CreateCommentOptions{
 		Type:        CommentTypeComment,
 		Doer:        doer,
@@ -585,9 +681,10 @@ func getCommentsByIssueIDSince(e Engine, issueID, since int64) ([]*Comment, erro
 	return comments, sess.Find(&comments)
 }
 
-func getCommentsByRepoIDSince(e Engine, repoID, since int64) ([]*Comment, error) {
+func getCommentsByRepoIDSince(e Engine, repoID, since int64, doer *User) ([]*Comment, error) {
 	comments := make([]*Comment, 0, 10)
 	sess := e.Where("issue.repo_id = ?", repoID).
+		And(visibleIssueCond(doer)).
 		Join("INNER", "issue", "issue.id = comment.issue_id").
 		Asc("comment.created_unix")
 	if since > 0 {
@@ -610,19 +707,82 @@ func GetCommentsByIssueIDSince(issueID, since int64) ([]*Comment, error) {
 	return getCommentsByIssueIDSince(x, issueID, since)
 }
 
-// GetCommentsByRepoIDSince returns a list of comments for all issues in a repo since a given time point.
-func GetCommentsByRepoIDSince(repoID, since int64) ([]*Comment, error) {
-	return getCommentsByRepoIDSince(x, repoID, since)
+// CountCommentsByIssueID returns the number of comments and timeline events
+// of an issue.
+func CountCommentsByIssueID(issueID int64) (int64, error) {
+	return x.Where("issue_id = ?", issueID).Count(new(Comment))
+}
+
+// GetCommentsByIssueIDRange returns at most count comments and timeline
+// events of an issue, skipping the first start ones, in the order of the
+// timeline.
+func GetCommentsByIssueIDRange(issueID int64, start, count int) ([]*Comment, error) {
+	comments := make([]*Comment, 0, count)
+	return comments, x.
+		Where("issue_id = ?", issueID).
+		Asc("created_unix").
+		Asc("id").
+		Limit(count, start).
+		Find(&comments)
+}
+
+// GetUserCommentsByIssueIDSince returns the page of the comments of users on
+// an issue updated since a given time point, without the timeline events, and
+// the number of such comments.
+func GetUserCommentsByIssueIDSince(issueID, since int64, page, pageSize int) ([]*Comment, int64, error) {
+	where := func() *xorm.Session {
+		sess := x.
+			Where("issue_id = ?", issueID).
+			And("type = ?", CommentTypeComment)
+		if since > 0 {
+			sess.And("updated_unix >= ?", since)
+		}
+		return sess
+	}
+	total, err := where().Count(new(Comment))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	comments := make([]*Comment, 0, pageSize)
+	return comments, total, where().
+		Asc("created_unix").
+		Asc("id").
+		Limit(pageSize, (page-1)*pageSize).
+		Find(&comments)
+}
+
+// GetCommentsByRepoIDSince returns a list of comments for all issues in a repo
+// doer can see since a given time point.
+func GetCommentsByRepoIDSince(repoID, since int64, doer *User) ([]*Comment, error) {
+	return getCommentsByRepoIDSince(x, repoID, since, doer)
 }
 
 // UpdateComment updates information of comment.
-func UpdateComment(c *Comment) error {
-	_, err := x.Id(c.ID).AllCols().Update(c)
-	return err
+func UpdateComment(doer *User, c *Comment, oldContent string) error {
+	if err := checkIssueAttachmentQuota(x, c.IssueID, nil, c.Content); err != nil {
+		return err
+	} else if _, err := x.Id(c.ID).AllCols().Update(c); err != nil {
+		return err
+	} else if err = linkContentAttachments(x, doer, c.IssueID, c.ID, c.Content); err != nil {
+		return fmt.Errorf("linkContentAttachments: %v", err)
+	}
+
+	if c.Type == CommentTypeComment {
+		c.sendWebhook(doer, HookIssueCommentEdited, &ChangesPayload{
+			Body: &api.ChangesFromPayload{
+				From: oldContent,
+			},
+		})
+	}
+	return nil
 }
 
 // DeleteComment deletes the comment
-func DeleteComment(comment *Comment) error {
+func DeleteComment(doer *User, comment *Comment) error {
 	sess := x.NewSession()
 	defer sessionRelease(sess)
 	if err := sess.Begin(); err != nil {
@@ -641,5 +801,12 @@ func DeleteComment(comment *Comment) error {
 		}
 	}
 
-	return sess.Commit()
+	if err := sess.Commit(); err != nil {
+		return err
+	}
+
+	if comment.Type == CommentTypeComment {
+		comment.sendWebhook(doer, HookIssueCommentDeleted, nil)
+	}
+	return nil
 }
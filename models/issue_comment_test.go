@@ -0,0 +1,48 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCommentsByIssueIDRange(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	total, err := CountCommentsByIssueID(1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+
+	comments, err := GetCommentsByIssueIDRange(1, 1, 5)
+	assert.NoError(t, err)
+	if assert.Len(t, comments, 2) {
+		assert.EqualValues(t, 2, comments[0].ID)
+		assert.EqualValues(t, 3, comments[1].ID)
+	}
+
+	comments, err = GetCommentsByIssueIDRange(1, 3, 5)
+	assert.NoError(t, err)
+	assert.Len(t, comments, 0)
+}
+
+func TestGetUserCommentsByIssueIDSince(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	comments, total, err := GetUserCommentsByIssueIDSince(1, 0, 1, 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	if assert.Len(t, comments, 1) {
+		assert.EqualValues(t, 2, comments[0].ID)
+	}
+
+	comments, total, err = GetUserCommentsByIssueIDSince(1, 0, 2, 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	if assert.Len(t, comments, 1) {
+		assert.EqualValues(t, 3, comments[0].ID)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/builder"
+)
+
+// visibleIssueCond returns the condition of the issues doer can see, doer is
+// nil for anonymous users. Confidential issues are only visible to their
+// poster and the users with write access to the repository.
+func visibleIssueCond(doer *User) builder.Cond {
+	if doer == nil {
+		return builder.Eq{"issue.is_confidential": false}
+	} else if doer.IsAdmin {
+		return builder.NewCond()
+	}
+	return builder.Or(
+		builder.Eq{"issue.is_confidential": false},
+		builder.Eq{"issue.poster_id": doer.ID},
+		builder.Expr("issue.repo_id IN (SELECT id FROM repository WHERE owner_id = ?)", doer.ID),
+		builder.Expr("issue.repo_id IN (SELECT repo_id FROM access WHERE user_id = ? AND mode >= ?)", doer.ID, AccessModeWrite),
+	)
+}
+
+func (issue *Issue) isVisibleTo(e Engine, doer *User) (bool, error) {
+	if !issue.IsConfidential {
+		return true, nil
+	} else if doer == nil {
+		return false, nil
+	} else if doer.IsAdmin || issue.PosterID == doer.ID {
+		return true, nil
+	}
+
+	if err := issue.loadRepo(e); err != nil {
+		return false, err
+	}
+	return hasAccess(e, doer.ID, issue.Repo, AccessModeWrite)
+}
+
+// IsVisibleTo returns true if doer can see the issue, doer is nil for
+// anonymous users.
+func (issue *Issue) IsVisibleTo(doer *User) (bool, error) {
+	return issue.isVisibleTo(x, doer)
+}
+
+// visibleUserIDs returns the users of userIDs who can see the issue, the
+// recipients of notifications are limited to them.
+func (issue *Issue) visibleUserIDs(e Engine, userIDs []int64) ([]int64, error) {
+	if !issue.IsConfidential {
+		return userIDs, nil
+	}
+
+	visibleIDs := make([]int64, 0, len(userIDs))
+	for _, userID := range userIDs {
+		user, err := getUserByID(e, userID)
+		if err != nil {
+			if IsErrUserNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if visible, err := issue.isVisibleTo(e, user); err != nil {
+			return nil, err
+		} else if visible {
+			visibleIDs = append(visibleIDs, userID)
+		}
+	}
+	return visibleIDs, nil
+}
+
+// ChangeConfidential changes whether the issue is visible only to its
+// poster and the users with write access to the repository.
+func (issue *Issue) ChangeConfidential(confidential bool) error {
+	if issue.IsPull {
+		return fmt.Errorf("pull request %d cannot be confidential", issue.ID)
+	} else if issue.IsConfidential == confidential {
+		return nil
+	}
+
+	issue.IsConfidential = confidential
+	return UpdateIssueCols(issue, "is_confidential")
+}
@@ -0,0 +1,51 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssue_ChangeConfidential(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.NoError(t, issue.ChangeConfidential(true))
+	AssertExistsAndLoadBean(t, &Issue{ID: 1, IsConfidential: true})
+
+	pull := AssertExistsAndLoadBean(t, &Issue{ID: 2, IsPull: true}).(*Issue)
+	assert.Error(t, pull.ChangeConfidential(true))
+}
+
+func TestIssue_IsVisibleTo(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.NoError(t, issue.ChangeConfidential(true))
+
+	for _, test := range []struct {
+		doer    *User
+		visible bool
+	}{
+		{nil, false},
+		{AssertExistsAndLoadBean(t, &User{ID: 1}).(*User), true},  // admin and poster
+		{AssertExistsAndLoadBean(t, &User{ID: 2}).(*User), true},  // repository owner
+		{AssertExistsAndLoadBean(t, &User{ID: 4}).(*User), false}, // no write access
+	} {
+		visible, err := issue.IsVisibleTo(test.doer)
+		assert.NoError(t, err)
+		assert.Equal(t, test.visible, visible)
+
+		issues, err := Issues(&IssuesOptions{RepoIDs: []int64{1}, Doer: test.doer})
+		assert.NoError(t, err)
+		found := false
+		for _, i := range issues {
+			found = found || i.ID == issue.ID
+		}
+		assert.Equal(t, test.visible, found)
+	}
+}
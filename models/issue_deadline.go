@@ -0,0 +1,184 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// IssueDeadlineReminder records that the assignee of an issue has been
+// reminded of its deadline, a new reminder is sent if the deadline changes.
+type IssueDeadlineReminder struct {
+	ID           int64 `xorm:"pk autoincr"`
+	IssueID      int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	UserID       int64 `xorm:"UNIQUE(s) NOT NULL"`
+	DeadlineUnix int64 `xorm:"UNIQUE(s) NOT NULL"`
+	CreatedUnix  int64 `xorm:"created"`
+}
+
+// HasDeadline returns true if a deadline is set for the issue.
+func (issue *Issue) HasDeadline() bool {
+	return issue.DeadlineUnix > 0
+}
+
+// IsOverdue returns true if the issue is still open after its deadline.
+func (issue *Issue) IsOverdue() bool {
+	return !issue.IsClosed && issue.HasDeadline() && issue.DeadlineUnix < time.Now().Unix()
+}
+
+// DeadlineString returns the deadline of the issue formatted as a date, or an
+// empty string if it has none.
+func (issue *Issue) DeadlineString() string {
+	if !issue.HasDeadline() {
+		return ""
+	}
+	return issue.Deadline.Format("2006-01-02")
+}
+
+// UpdateDeadline sets the deadline of the issue to given time, a zero time
+// removes the deadline.
+func (issue *Issue) UpdateDeadline(doer *User, deadline time.Time) (err error) {
+	oldDeadline := issue.DeadlineString()
+	if deadline.IsZero() {
+		deadline = time.Unix(0, 0)
+	}
+	issue.Deadline = deadline
+	issue.DeadlineUnix = deadline.Unix()
+	if oldDeadline == issue.DeadlineString() {
+		return nil
+	}
+
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if err = updateIssueCols(sess, issue, "deadline_unix"); err != nil {
+		return fmt.Errorf("updateIssueCols: %v", err)
+	}
+
+	if err = issue.loadRepo(sess); err != nil {
+		return err
+	} else if _, err = createDeadlineComment(sess, doer, issue, oldDeadline, issue.DeadlineString()); err != nil {
+		return fmt.Errorf("createDeadlineComment: %v", err)
+	}
+	return sess.Commit()
+}
+
+// noMilestoneDeadline is the deadline given to milestones without a due date.
+var noMilestoneDeadline = time.Date(9999, 1, 1, 0, 0, 0, 0, time.Local).Unix()
+
+// GetAssignedDeadlineIssues returns the open issues assigned to the user
+// which have a deadline, ordered by deadline.
+func GetAssignedDeadlineIssues(userID int64) ([]*Issue, error) {
+	issues := make([]*Issue, 0, 10)
+	if err := x.
+		Where("assignee_id = ? AND is_closed = ? AND deadline_unix > 0", userID, false).
+		Asc("deadline_unix").
+		Find(&issues); err != nil {
+		return nil, err
+	}
+	if _, err := IssueList(issues).loadRepositories(x); err != nil {
+		return nil, fmt.Errorf("loadRepositories: %v", err)
+	}
+	return issues, nil
+}
+
+// GetAssignedDeadlineMilestones returns the open milestones with a due date
+// which contain open issues assigned to the user, ordered by due date.
+func GetAssignedDeadlineMilestones(userID int64) ([]*Milestone, error) {
+	miles := make([]*Milestone, 0, 5)
+	return miles, x.
+		Where("is_closed = ? AND deadline_unix > 0 AND deadline_unix < ?", false, noMilestoneDeadline).
+		And("id IN (SELECT milestone_id FROM issue WHERE assignee_id = ? AND is_closed = ?)", userID, false).
+		Asc("deadline_unix").
+		Find(&miles)
+}
+
+// remindIssueDeadline notifies the assignee of the issue of its deadline,
+// it returns false if the assignee has already been reminded of it.
+func remindIssueDeadline(issue *Issue) (bool, error) {
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err := sess.Begin(); err != nil {
+		return false, err
+	}
+
+	has, err := sess.
+		Where("issue_id = ? AND user_id = ? AND deadline_unix = ?", issue.ID, issue.AssigneeID, issue.DeadlineUnix).
+		Get(new(IssueDeadlineReminder))
+	if err != nil {
+		return false, err
+	} else if has {
+		return false, nil
+	}
+
+	if _, err = sess.Insert(&IssueDeadlineReminder{
+		IssueID:      issue.ID,
+		UserID:       issue.AssigneeID,
+		DeadlineUnix: issue.DeadlineUnix,
+	}); err != nil {
+		return false, err
+	}
+
+	notifications, err := getNotificationsByIssueID(sess, issue.ID)
+	if err != nil {
+		return false, err
+	}
+	if notificationExists(notifications, issue.ID, issue.AssigneeID) {
+		err = updateIssueNotification(sess, issue.AssigneeID, issue.ID, issue.AssigneeID)
+	} else {
+		err = createIssueNotification(sess, issue.AssigneeID, issue, issue.AssigneeID)
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, sess.Commit()
+}
+
+// SendDeadlineReminders reminds the assignees of open issues whose deadline
+// is at most the configured number of days away.
+func SendDeadlineReminders() {
+	if !taskStatusTable.StartIfNotRunning(deadlineReminder) {
+		return
+	}
+	defer taskStatusTable.Stop(deadlineReminder)
+
+	log.Trace("Doing: DeadlineReminder")
+
+	now := time.Now()
+	until := now.AddDate(0, 0, setting.Cron.DeadlineReminder.DaysBefore)
+	issues := make([]*Issue, 0, 10)
+	if err := x.
+		Where("is_closed = ? AND assignee_id > 0", false).
+		And("deadline_unix >= ? AND deadline_unix <= ?", now.Unix(), until.Unix()).
+		Find(&issues); err != nil {
+		log.Error(4, "DeadlineReminder: %v", err)
+		return
+	}
+
+	for _, issue := range issues {
+		reminded, err := remindIssueDeadline(issue)
+		if err != nil {
+			log.Error(4, "remindIssueDeadline [%d]: %v", issue.ID, err)
+			continue
+		} else if !reminded || !setting.Service.EnableNotifyMail {
+			continue
+		}
+
+		if err = issue.LoadAttributes(); err != nil {
+			log.Error(4, "LoadAttributes [%d]: %v", issue.ID, err)
+			continue
+		}
+		if issue.Assignee != nil && issue.Assignee.IsActive {
+			SendIssueDeadlineMail(issue.Assignee, issue)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setIssueDeadline(t *testing.T, issueID int64, deadline time.Time) {
+	_, err := x.Id(issueID).Cols("deadline_unix").Update(&Issue{Deadline: deadline})
+	assert.NoError(t, err)
+}
+
+func TestGetAssignedDeadlineIssues(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issues, err := GetAssignedDeadlineIssues(1)
+	assert.NoError(t, err)
+	assert.Len(t, issues, 0)
+
+	setIssueDeadline(t, 1, time.Now().AddDate(0, 0, 1))
+	issues, err = GetAssignedDeadlineIssues(1)
+	assert.NoError(t, err)
+	if assert.Len(t, issues, 1) {
+		assert.EqualValues(t, 1, issues[0].ID)
+		assert.True(t, issues[0].HasDeadline())
+		assert.NotNil(t, issues[0].Repo)
+	}
+
+	issues, err = GetAssignedDeadlineIssues(2)
+	assert.NoError(t, err)
+	assert.Len(t, issues, 0)
+}
+
+func TestGetAssignedDeadlineMilestones(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	_, err := x.Id(2).Cols("assignee_id").Update(&Issue{AssigneeID: 1})
+	assert.NoError(t, err)
+
+	// Milestones without due date are left out.
+	_, err = x.Id(1).Cols("deadline_unix").Update(&Milestone{Deadline: time.Unix(noMilestoneDeadline, 0)})
+	assert.NoError(t, err)
+	miles, err := GetAssignedDeadlineMilestones(1)
+	assert.NoError(t, err)
+	assert.Len(t, miles, 0)
+
+	_, err = x.Id(1).Cols("deadline_unix").Update(&Milestone{Deadline: time.Now().AddDate(0, 0, 7)})
+	assert.NoError(t, err)
+	miles, err = GetAssignedDeadlineMilestones(1)
+	assert.NoError(t, err)
+	if assert.Len(t, miles, 1) {
+		assert.EqualValues(t, 1, miles[0].ID)
+	}
+
+	miles, err = GetAssignedDeadlineMilestones(2)
+	assert.NoError(t, err)
+	assert.Len(t, miles, 0)
+}
+
+func TestSendDeadlineReminders(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	setting.Cron.DeadlineReminder.DaysBefore = 3
+	setIssueDeadline(t, 1, time.Now().AddDate(0, 0, 2))
+
+	SendDeadlineReminders()
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	AssertExistsAndLoadBean(t, &IssueDeadlineReminder{IssueID: 1, UserID: 1, DeadlineUnix: issue.DeadlineUnix})
+	AssertExistsAndLoadBean(t, &Notification{UserID: 1, IssueID: 1, Status: NotificationStatusUnread})
+
+	// Reminders are sent only once per deadline.
+	reminded, err := remindIssueDeadline(issue)
+	assert.NoError(t, err)
+	assert.False(t, reminded)
+
+	// Deadlines further away are not reminded of.
+	setIssueDeadline(t, 1, time.Now().AddDate(0, 0, 10))
+	SendDeadlineReminders()
+	issue = AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	AssertNotExistsBean(t, &IssueDeadlineReminder{IssueID: 1, DeadlineUnix: issue.DeadlineUnix})
+}
+
+func TestIssue_UpdateDeadline(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.False(t, issue.HasDeadline())
+
+	deadline := time.Date(2017, 12, 31, 0, 0, 0, 0, time.Local)
+	assert.NoError(t, issue.UpdateDeadline(doer, deadline))
+	issue = AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.Equal(t, deadline.Unix(), issue.DeadlineUnix)
+	assert.True(t, issue.IsOverdue())
+	AssertExistsAndLoadBean(t, &Comment{Type: CommentTypeDeadline, IssueID: 1, NewTitle: "2017-12-31"})
+
+	// Setting the same deadline again does nothing.
+	assert.NoError(t, issue.UpdateDeadline(doer, deadline))
+	assert.EqualValues(t, 1, getCount(t, x, &Comment{Type: CommentTypeDeadline, IssueID: 1}))
+
+	assert.NoError(t, issue.UpdateDeadline(doer, time.Time{}))
+	issue = AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.False(t, issue.HasDeadline())
+	assert.False(t, issue.IsOverdue())
+	AssertExistsAndLoadBean(t, &Comment{Type: CommentTypeDeadline, IssueID: 1, OldTitle: "2017-12-31"})
+}
+
+func TestIssues_Overdue(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	setIssueDeadline(t, 1, time.Now().AddDate(0, 0, -1))
+	setIssueDeadline(t, 2, time.Now().AddDate(0, 0, 5))
+	setIssueDeadline(t, 5, time.Now().AddDate(0, 0, -2)) // closed
+
+	issues, err := Issues(&IssuesOptions{RepoID: 1, IsOverdue: true})
+	assert.NoError(t, err)
+	if assert.Len(t, issues, 1) {
+		assert.EqualValues(t, 1, issues[0].ID)
+	}
+
+	stats, err := GetIssueStats(&IssueStatsOptions{RepoID: 1, IsOverdue: true})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, stats.OpenCount)
+	assert.EqualValues(t, 0, stats.ClosedCount)
+
+	issues, err = Issues(&IssuesOptions{RepoID: 1, SortType: "nearestdeadline"})
+	assert.NoError(t, err)
+	if assert.Len(t, issues, 4) {
+		assert.EqualValues(t, 5, issues[0].ID)
+		assert.EqualValues(t, 1, issues[1].ID)
+		assert.EqualValues(t, 2, issues[2].ID)
+		assert.EqualValues(t, 3, issues[3].ID)
+	}
+}
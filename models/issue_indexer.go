@@ -43,16 +43,21 @@ func numericQuery(value int64, field string) *query.NumericRangeQuery {
 	return q
 }
 
-// SearchIssuesByKeyword searches for issues by given conditions.
+// SearchIssuesByKeyword searches for issues by given conditions, the issues
+// of all repositories are searched if repoID is 0.
 // Returns the matching issue IDs
 func SearchIssuesByKeyword(repoID int64, keyword string) ([]int64, error) {
 	terms := strings.Fields(strings.ToLower(keyword))
-	indexerQuery := bleve.NewConjunctionQuery(
-		numericQuery(repoID, "RepoID"),
-		bleve.NewDisjunctionQuery(
-			bleve.NewPhraseQuery(terms, "Title"),
-			bleve.NewPhraseQuery(terms, "Content"),
-		))
+	var indexerQuery query.Query = bleve.NewDisjunctionQuery(
+		bleve.NewPhraseQuery(terms, "Title"),
+		bleve.NewPhraseQuery(terms, "Content"),
+	)
+	if repoID > 0 {
+		indexerQuery = bleve.NewConjunctionQuery(
+			numericQuery(repoID, "RepoID"),
+			indexerQuery,
+		)
+	}
 	search := bleve.NewSearchRequestOptions(indexerQuery, 2147483647, 0, false)
 	search.Fields = []string{"ID"}
 
@@ -133,6 +138,8 @@ func populateIssueIndexer() error {
 				IsClosed: util.OptionalBoolNone,
 				IsPull:   util.OptionalBoolNone,
 				Page:     -1, // do not page
+				// Search results are limited to the issues the searcher can see.
+				AllConfidential: true,
 			})
 			if err != nil {
 				return fmt.Errorf("Issues: %v", err)
@@ -50,11 +50,13 @@ func GetLabelTemplateFile(name string) ([][2]string, error) {
 	return list, nil
 }
 
-// Label represents a label of repository for issues.
+// Label represents a label of repository for issues. A label named
+// "scope/value" is scoped: an issue has at most one label of each scope.
 type Label struct {
 	ID              int64 `xorm:"pk autoincr"`
 	RepoID          int64 `xorm:"INDEX"`
 	Name            string
+	Description     string `xorm:"TEXT"`
 	Color           string `xorm:"VARCHAR(7)"`
 	NumIssues       int
 	NumClosedIssues int
@@ -71,6 +73,30 @@ func (label *Label) APIFormat() *api.Label {
 	}
 }
 
+// IsScoped returns true if the label name has the "scope/value" form.
+func (label *Label) IsScoped() bool {
+	i := strings.LastIndex(label.Name, "/")
+	return i > 0 && i < len(label.Name)-1
+}
+
+// Scope returns the scope part of a scoped label name, or an empty string
+// if the label is not scoped.
+func (label *Label) Scope() string {
+	if !label.IsScoped() {
+		return ""
+	}
+	return label.Name[:strings.LastIndex(label.Name, "/")]
+}
+
+// ScopedValue returns the value part of a scoped label name, or the whole
+// name if the label is not scoped.
+func (label *Label) ScopedValue() string {
+	if !label.IsScoped() {
+		return label.Name
+	}
+	return label.Name[strings.LastIndex(label.Name, "/")+1:]
+}
+
 // CalOpenIssues calculates the open issues of label.
 func (label *Label) CalOpenIssues() {
 	label.NumOpenIssues = label.NumIssues - label.NumClosedIssues
@@ -97,9 +123,20 @@ func (label *Label) ForegroundColor() template.CSS {
 }
 
 // NewLabels creates new label(s) for a repository.
-func NewLabels(labels ...*Label) error {
-	_, err := x.Insert(labels)
-	return err
+func NewLabels(labels ...*Label) (err error) {
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	// Insert one by one so that the IDs of labels are set.
+	for _, label := range labels {
+		if _, err = sess.Insert(label); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
 }
 
 // getLabelInRepoByName returns a label by Name in given repository.
@@ -170,6 +207,19 @@ func GetLabelsInRepoByIDs(repoID int64, labelIDs []int64) ([]*Label, error) {
 		Find(&labels)
 }
 
+// SearchLabels returns labels of given repository whose name or
+// description contain the keyword, ordered by name.
+func SearchLabels(repoID int64, keyword string, limit int) ([]*Label, error) {
+	keyword = "%" + strings.ToLower(keyword) + "%"
+	labels := make([]*Label, 0, limit)
+	return labels, x.
+		Where("repo_id = ?", repoID).
+		And("(LOWER(name) LIKE ? OR LOWER(description) LIKE ?)", keyword, keyword).
+		Asc("name").
+		Limit(limit).
+		Find(&labels)
+}
+
 // GetLabelsByRepoID returns all labels that belong to given repository by ID.
 func GetLabelsByRepoID(repoID int64, sortType string) ([]*Label, error) {
 	labels := make([]*Label, 0, 10)
@@ -268,7 +318,45 @@ func HasIssueLabel(issueID, labelID int64) bool {
 	return hasIssueLabel(x, issueID, labelID)
 }
 
+// removeScopedIssueLabels removes the labels of the issue which have the same
+// scope as given label, so scoped labels stay mutually exclusive.
+func removeScopedIssueLabels(e *xorm.Session, issue *Issue, label *Label, doer *User) error {
+	if !label.IsScoped() {
+		return nil
+	}
+
+	issueLabels, err := getIssueLabels(e, issue.ID)
+	if err != nil {
+		return fmt.Errorf("getIssueLabels: %v", err)
+	} else if len(issueLabels) == 0 {
+		return nil
+	}
+	labelIDs := make([]int64, len(issueLabels))
+	for i := range issueLabels {
+		labelIDs[i] = issueLabels[i].LabelID
+	}
+	labels := make([]*Label, 0, len(labelIDs))
+	if err = e.In("id", labelIDs).Find(&labels); err != nil {
+		return fmt.Errorf("find labels: %v", err)
+	}
+
+	for _, l := range labels {
+		if l.ID == label.ID || l.Scope() != label.Scope() {
+			continue
+		}
+		if err = deleteIssueLabel(e, issue, l, doer); err != nil {
+			return fmt.Errorf("deleteIssueLabel: %v", err)
+		}
+	}
+	issue.Labels = nil
+	return nil
+}
+
 func newIssueLabel(e *xorm.Session, issue *Issue, label *Label, doer *User) (err error) {
+	if err = removeScopedIssueLabels(e, issue, label, doer); err != nil {
+		return err
+	}
+
 	if _, err = e.Insert(&IssueLabel{
 		IssueID: issue.ID,
 		LabelID: label.ID,
@@ -41,6 +41,20 @@ func TestLabel_ForegroundColor(t *testing.T) {
 	assert.Equal(t, template.CSS("#fff"), label.ForegroundColor())
 }
 
+func TestLabel_Scope(t *testing.T) {
+	label := &Label{Name: "priority/high"}
+	assert.True(t, label.IsScoped())
+	assert.Equal(t, "priority", label.Scope())
+	assert.Equal(t, "high", label.ScopedValue())
+
+	for _, name := range []string{"bug", "/bug", "bug/"} {
+		label = &Label{Name: name}
+		assert.False(t, label.IsScoped())
+		assert.Equal(t, "", label.Scope())
+		assert.Equal(t, name, label.ScopedValue())
+	}
+}
+
 func TestNewLabels(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	labels := []*Label{
@@ -119,6 +133,28 @@ func TestGetLabelsByRepoID(t *testing.T) {
 	testSuccess(1, "default", []int64{1, 2})
 }
 
+func TestSearchLabels(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	assert.NoError(t, NewLabels(&Label{RepoID: 1, Name: "bug", Description: "Something is broken", Color: "#ee0701"}))
+
+	labels, err := SearchLabels(1, "LABEL", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, labels, 2) {
+		assert.EqualValues(t, 1, labels[0].ID)
+		assert.EqualValues(t, 2, labels[1].ID)
+	}
+
+	labels, err = SearchLabels(1, "broken", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, labels, 1) {
+		assert.Equal(t, "bug", labels[0].Name)
+	}
+
+	labels, err = SearchLabels(1, "label", 1)
+	assert.NoError(t, err)
+	assert.Len(t, labels, 1)
+}
+
 func TestGetLabelsByIssueID(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	labels, err := GetLabelsByIssueID(1)
@@ -250,3 +286,24 @@ func TestDeleteIssueLabel(t *testing.T) {
 
 	CheckConsistencyFor(t, &Issue{}, &Label{})
 }
+
+func TestNewIssueLabel_Scoped(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	high := &Label{RepoID: 1, Name: "priority/high", Color: "#ee0701"}
+	low := &Label{RepoID: 1, Name: "priority/low", Color: "#009800"}
+	assert.NoError(t, NewLabels(high, low))
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	assert.NoError(t, NewIssueLabel(issue, high, doer))
+	AssertExistsAndLoadBean(t, &IssueLabel{IssueID: issue.ID, LabelID: high.ID})
+
+	assert.NoError(t, NewIssueLabel(issue, low, doer))
+	AssertExistsAndLoadBean(t, &IssueLabel{IssueID: issue.ID, LabelID: low.ID})
+	AssertNotExistsBean(t, &IssueLabel{IssueID: issue.ID, LabelID: high.ID})
+
+	// unscoped labels are left untouched
+	AssertExistsAndLoadBean(t, &IssueLabel{IssueID: issue.ID, LabelID: 1})
+
+	CheckConsistencyFor(t, &Issue{}, &Label{})
+}
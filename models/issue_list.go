@@ -260,36 +260,6 @@ func (issues IssueList) loadAttachments(e Engine) (err error) {
 	return nil
 }
 
-func (issues IssueList) loadComments(e Engine) (err error) {
-	if len(issues) == 0 {
-		return nil
-	}
-
-	var comments = make(map[int64][]*Comment, len(issues))
-	rows, err := e.Table("comment").
-		Join("INNER", "issue", "issue.id = comment.issue_id").
-		In("issue.id", issues.getIssueIDs()).
-		Rows(new(Comment))
-	if err != nil {
-		return err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var comment Comment
-		err = rows.Scan(&comment)
-		if err != nil {
-			return err
-		}
-		comments[comment.IssueID] = append(comments[comment.IssueID], &comment)
-	}
-
-	for _, issue := range issues {
-		issue.Comments = comments[issue.ID]
-	}
-	return nil
-}
-
 func (issues IssueList) loadAttributes(e Engine) (err error) {
 	if _, err = issues.loadRepositories(e); err != nil {
 		return
@@ -319,10 +289,6 @@ func (issues IssueList) loadAttributes(e Engine) (err error) {
 		return
 	}
 
-	if err = issues.loadComments(e); err != nil {
-		return
-	}
-
 	return nil
 }
 
@@ -58,8 +58,5 @@ func TestIssueList_LoadAttributes(t *testing.T) {
 		for _, attachment := range issue.Attachments {
 			assert.EqualValues(t, issue.ID, attachment.IssueID)
 		}
-		for _, comment := range issue.Comments {
-			assert.EqualValues(t, issue.ID, comment.IssueID)
-		}
 	}
 }
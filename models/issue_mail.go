@@ -45,7 +45,7 @@ func mailIssueCommentToParticipants(issue *Issue, doer *User, comment *Comment,
 	tos := make([]string, 0, len(watchers)) // List of email addresses.
 	names := make([]string, 0, len(watchers))
 	for i := range watchers {
-		if watchers[i].UserID == doer.ID {
+		if watchers[i].UserID == doer.ID || !watchers[i].AcceptsIssues() {
 			continue
 		}
 
@@ -55,6 +55,10 @@ func mailIssueCommentToParticipants(issue *Issue, doer *User, comment *Comment,
 		}
 		if to.IsOrganization() {
 			continue
+		} else if visible, err := issue.IsVisibleTo(to); err != nil {
+			return fmt.Errorf("IsVisibleTo [%d]: %v", to.ID, err)
+		} else if !visible {
+			continue
 		}
 
 		tos = append(tos, to.Email)
@@ -65,6 +69,10 @@ func mailIssueCommentToParticipants(issue *Issue, doer *User, comment *Comment,
 			continue
 		} else if com.IsSliceContainsStr(names, participants[i].Name) {
 			continue
+		} else if visible, err := issue.IsVisibleTo(participants[i]); err != nil {
+			return fmt.Errorf("IsVisibleTo [%d]: %v", participants[i].ID, err)
+		} else if !visible {
+			continue
 		}
 
 		tos = append(tos, participants[i].Email)
@@ -80,6 +88,19 @@ func mailIssueCommentToParticipants(issue *Issue, doer *User, comment *Comment,
 		if com.IsSliceContainsStr(names, mentions[i]) {
 			continue
 		}
+		if issue.IsConfidential {
+			mentioned, err := GetUserByName(mentions[i])
+			if err != nil {
+				if IsErrUserNotExist(err) {
+					continue
+				}
+				return fmt.Errorf("GetUserByName [%s]: %v", mentions[i], err)
+			} else if visible, err := issue.IsVisibleTo(mentioned); err != nil {
+				return fmt.Errorf("IsVisibleTo [%d]: %v", mentioned.ID, err)
+			} else if !visible {
+				continue
+			}
+		}
 
 		tos = append(tos, mentions[i])
 	}
@@ -5,11 +5,15 @@
 package models
 
 import (
+	"encoding/csv"
+	"io"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/sdk/gitea"
 
+	"github.com/Unknwon/com"
 	"github.com/go-xorm/xorm"
 )
 
@@ -350,3 +354,124 @@ func DeleteMilestoneByRepoID(repoID, id int64) error {
 	}
 	return sess.Commit()
 }
+
+// MilestoneIssueReport represents one issue or pull request of a milestone
+// as it appears in the milestone report.
+type MilestoneIssueReport struct {
+	Issue       *Issue
+	Closed      time.Time
+	TimeToClose time.Duration
+}
+
+// LabelNames returns the names of all labels of the issue.
+func (r *MilestoneIssueReport) LabelNames() []string {
+	names := make([]string, len(r.Issue.Labels))
+	for i, label := range r.Issue.Labels {
+		names[i] = label.Name
+	}
+	return names
+}
+
+// AssigneeName returns the name of the assignee of the issue if any.
+func (r *MilestoneIssueReport) AssigneeName() string {
+	if r.Issue.Assignee == nil {
+		return ""
+	}
+	return r.Issue.Assignee.Name
+}
+
+// MilestoneIssueReports is a list of MilestoneIssueReport.
+type MilestoneIssueReports []*MilestoneIssueReport
+
+// WriteCSV writes the report as CSV with a header line.
+func (reports MilestoneIssueReports) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"index", "title", "type", "state", "assignee", "labels",
+		"created", "closed", "time_to_close_seconds"}); err != nil {
+		return err
+	}
+	for _, r := range reports {
+		issueType, closed, timeToClose := "issue", "", ""
+		if r.Issue.IsPull {
+			issueType = "pull"
+		}
+		if r.Issue.IsClosed {
+			closed = r.Closed.UTC().Format(time.RFC3339)
+			timeToClose = com.ToStr(int64(r.TimeToClose.Seconds()))
+		}
+		if err := cw.Write([]string{
+			com.ToStr(r.Issue.Index),
+			r.Issue.Title,
+			issueType,
+			string(r.Issue.State()),
+			r.AssigneeName(),
+			strings.Join(r.LabelNames(), ","),
+			r.Issue.Created.UTC().Format(time.RFC3339),
+			closed,
+			timeToClose,
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// GetIssueReport returns all issues and pull requests of the milestone doer
+// can see together with their closing date and time to close, doer is nil
+// for anonymous users.
+func (m *Milestone) GetIssueReport(doer *User) (MilestoneIssueReports, error) {
+	issues := make([]*Issue, 0, m.NumIssues)
+	if err := x.
+		Where("milestone_id = ?", m.ID).
+		And(visibleIssueCond(doer)).
+		Asc("`index`").
+		Find(&issues); err != nil {
+		return nil, err
+	}
+	if err := IssueList(issues).loadLabels(x); err != nil {
+		return nil, err
+	}
+	if err := IssueList(issues).loadAssignees(x); err != nil {
+		return nil, err
+	}
+
+	closedIssueIDs := make([]int64, 0, m.NumClosedIssues)
+	for _, issue := range issues {
+		if issue.IsClosed {
+			closedIssueIDs = append(closedIssueIDs, issue.ID)
+		}
+	}
+
+	// The latest close event of an issue is its closing date.
+	closedUnix := make(map[int64]int64, len(closedIssueIDs))
+	if len(closedIssueIDs) > 0 {
+		comments := make([]*Comment, 0, len(closedIssueIDs))
+		if err := x.
+			In("issue_id", closedIssueIDs).
+			And("type = ?", CommentTypeClose).
+			Find(&comments); err != nil {
+			return nil, err
+		}
+		for _, c := range comments {
+			if c.CreatedUnix > closedUnix[c.IssueID] {
+				closedUnix[c.IssueID] = c.CreatedUnix
+			}
+		}
+	}
+
+	reports := make(MilestoneIssueReports, len(issues))
+	for i, issue := range issues {
+		reports[i] = &MilestoneIssueReport{Issue: issue}
+		if !issue.IsClosed {
+			continue
+		}
+		unix, ok := closedUnix[issue.ID]
+		if !ok {
+			unix = issue.UpdatedUnix
+		}
+		reports[i].Closed = time.Unix(unix, 0).Local()
+		reports[i].TimeToClose = reports[i].Closed.Sub(issue.Created)
+	}
+	return reports, nil
+}
@@ -5,6 +5,8 @@
 package models
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	api "code.gitea.io/sdk/gitea"
@@ -238,3 +240,51 @@ func TestDeleteMilestoneByRepoID(t *testing.T) {
 
 	assert.NoError(t, DeleteMilestoneByRepoID(NonexistentID, NonexistentID))
 }
+
+func TestMilestone_GetIssueReport(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	milestone := AssertExistsAndLoadBean(t, &Milestone{ID: 1}).(*Milestone)
+
+	reports, err := milestone.GetIssueReport(nil)
+	assert.NoError(t, err)
+	if assert.Len(t, reports, 1) {
+		assert.EqualValues(t, 2, reports[0].Issue.Index)
+		assert.True(t, reports[0].Closed.IsZero())
+		assert.Empty(t, reports[0].AssigneeName())
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, reports.WriteCSV(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.Equal(t, "index,title,type,state,assignee,labels,created,closed,time_to_close_seconds", lines[0])
+		assert.Equal(t, "2,issue2,pull,open,,label1,2000-01-01T00:00:10Z,,", lines[1])
+	}
+}
+
+func TestMilestone_GetIssueReport_Confidential(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	milestone := AssertExistsAndLoadBean(t, &Milestone{ID: 1}).(*Milestone)
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.NoError(t, issue.ChangeConfidential(true))
+	_, err := x.Id(issue.ID).Cols("milestone_id").Update(&Issue{MilestoneID: milestone.ID})
+	assert.NoError(t, err)
+
+	for _, test := range []struct {
+		doer    *User
+		visible bool
+	}{
+		{nil, false},
+		{AssertExistsAndLoadBean(t, &User{ID: 2}).(*User), true},  // repository owner
+		{AssertExistsAndLoadBean(t, &User{ID: 4}).(*User), false}, // no write access
+	} {
+		reports, err := milestone.GetIssueReport(test.doer)
+		assert.NoError(t, err)
+		found := false
+		for _, report := range reports {
+			found = found || report.Issue.ID == issue.ID
+		}
+		assert.Equal(t, test.visible, found)
+	}
+}
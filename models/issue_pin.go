@@ -0,0 +1,126 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	api "code.gitea.io/sdk/gitea"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// IsPinned returns true if the issue is pinned to the top of the issue list.
+func (issue *Issue) IsPinned() bool {
+	return issue.PinOrder > 0
+}
+
+// GetPinnedIssues returns the pinned issues of the repository doer can see
+// in pin order, doer is nil for anonymous users.
+func GetPinnedIssues(repoID int64, doer *User) ([]*Issue, error) {
+	issues := make([]*Issue, 0, setting.Repository.MaxPinnedIssues)
+	if err := x.
+		Where("repo_id = ? AND is_pull = ? AND pin_order > 0", repoID, false).
+		And(visibleIssueCond(doer)).
+		Asc("pin_order").
+		Find(&issues); err != nil {
+		return nil, err
+	}
+	return issues, IssueList(issues).LoadAttributes()
+}
+
+// Pin pins the issue after the already pinned issues of its repository.
+func (issue *Issue) Pin(doer *User) (err error) {
+	if issue.IsPinned() {
+		return nil
+	}
+
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	count, err := sess.Where("repo_id = ? AND is_pull = ? AND pin_order > 0", issue.RepoID, false).Count(new(Issue))
+	if err != nil {
+		return err
+	} else if int(count) >= setting.Repository.MaxPinnedIssues {
+		return ErrIssueMaxPinReached{issue.RepoID, setting.Repository.MaxPinnedIssues}
+	}
+
+	var maxOrder int
+	if _, err = sess.Table("issue").Select("MAX(pin_order)").Where("repo_id = ? AND is_pull = ?", issue.RepoID, false).Get(&maxOrder); err != nil {
+		return fmt.Errorf("get max pin order: %v", err)
+	}
+	issue.PinOrder = maxOrder + 1
+	if err = updateIssueCols(sess, issue, "pin_order"); err != nil {
+		return fmt.Errorf("updateIssueCols: %v", err)
+	}
+
+	if err = issue.loadRepo(sess); err != nil {
+		return err
+	} else if _, err = createPinComment(sess, doer, issue, CommentTypePin); err != nil {
+		return fmt.Errorf("createPinComment: %v", err)
+	}
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+
+	prepareIssuePinWebhooks(doer, issue, HookIssuePinned)
+	return nil
+}
+
+// Unpin unpins the issue, the issues pinned after it move up.
+func (issue *Issue) Unpin(doer *User) (err error) {
+	if !issue.IsPinned() {
+		return nil
+	}
+
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err = sess.Exec("UPDATE `issue` SET pin_order = pin_order - 1 WHERE repo_id = ? AND is_pull = ? AND pin_order > ?",
+		issue.RepoID, false, issue.PinOrder); err != nil {
+		return fmt.Errorf("update pin order: %v", err)
+	}
+	issue.PinOrder = 0
+	if err = updateIssueCols(sess, issue, "pin_order"); err != nil {
+		return fmt.Errorf("updateIssueCols: %v", err)
+	}
+
+	if err = issue.loadRepo(sess); err != nil {
+		return err
+	} else if _, err = createPinComment(sess, doer, issue, CommentTypeUnpin); err != nil {
+		return fmt.Errorf("createPinComment: %v", err)
+	}
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+
+	prepareIssuePinWebhooks(doer, issue, HookIssueUnpinned)
+	return nil
+}
+
+func prepareIssuePinWebhooks(doer *User, issue *Issue, action api.HookIssueAction) {
+	if err := issue.LoadAttributes(); err != nil {
+		log.Error(4, "LoadAttributes: %v", err)
+		return
+	}
+	if err := PrepareWebhooks(issue.Repo, HookEventIssues, &IssuePayload{
+		Action:     action,
+		Index:      issue.Index,
+		Issue:      issue.APIFormat(),
+		Repository: issue.Repo.APIFormat(AccessModeNone),
+		Sender:     doer.APIFormat(),
+	}); err != nil {
+		log.Error(4, "PrepareWebhooks: %v", err)
+	} else {
+		go HookQueue.Add(issue.RepoID)
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+func TestIssue_Pin(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	oldMax := setting.Repository.MaxPinnedIssues
+	setting.Repository.MaxPinnedIssues = 1
+	defer func() { setting.Repository.MaxPinnedIssues = oldMax }()
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue1 := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	issue5 := AssertExistsAndLoadBean(t, &Issue{ID: 5}).(*Issue)
+
+	assert.NoError(t, issue1.Pin(doer))
+	assert.True(t, issue1.IsPinned())
+	AssertExistsAndLoadBean(t, &Issue{ID: 1, PinOrder: 1})
+	AssertExistsAndLoadBean(t, &Comment{IssueID: 1, PosterID: doer.ID, Type: CommentTypePin})
+	assert.True(t, IsErrIssueMaxPinReached(issue5.Pin(doer)))
+
+	setting.Repository.MaxPinnedIssues = 2
+	assert.NoError(t, issue5.Pin(doer))
+	AssertExistsAndLoadBean(t, &Issue{ID: 5, PinOrder: 2})
+
+	issues, err := GetPinnedIssues(1, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, issues, 2) {
+		assert.EqualValues(t, 1, issues[0].ID)
+		assert.EqualValues(t, 5, issues[1].ID)
+	}
+
+	assert.NoError(t, issue1.Unpin(doer))
+	assert.False(t, issue1.IsPinned())
+	assert.EqualValues(t, 0, AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue).PinOrder)
+	AssertExistsAndLoadBean(t, &Issue{ID: 5, PinOrder: 1})
+	AssertExistsAndLoadBean(t, &Comment{IssueID: 1, PosterID: doer.ID, Type: CommentTypeUnpin})
+	CheckConsistencyFor(t, &Issue{})
+}
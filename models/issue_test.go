@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"code.gitea.io/gitea/modules/util"
 )
 
 func TestIssue_ReplaceLabels(t *testing.T) {
@@ -81,3 +83,26 @@ func TestGetParticipantsByIssueID(t *testing.T) {
 	// Users 3 and 5 made actual comments (see fixtures/comment.yml)
 	checkParticipants(1, []int{3, 5})
 }
+
+func TestIssues_PageSize(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	opts := &IssuesOptions{RepoID: 1, Page: 1, PageSize: 2, AllConfidential: true}
+	issues, err := Issues(opts)
+	assert.NoError(t, err)
+	assert.Len(t, issues, 2)
+
+	opts.Page = 2
+	issues, err = Issues(opts)
+	assert.NoError(t, err)
+	assert.Len(t, issues, 2)
+
+	count, err := CountIssues(opts)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, count)
+
+	opts.IsClosed = util.OptionalBoolTrue
+	count, err = CountIssues(opts)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+}
@@ -0,0 +1,148 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+// commentTypeNames are the names of the comment types, the issue page renders
+// each type by its name.
+var commentTypeNames = map[CommentType]string{
+	CommentTypeComment:      "comment",
+	CommentTypeReopen:       "reopen",
+	CommentTypeClose:        "close",
+	CommentTypeIssueRef:     "issue_ref",
+	CommentTypeCommitRef:    "commit_ref",
+	CommentTypeCommentRef:   "comment_ref",
+	CommentTypePullRef:      "pull_ref",
+	CommentTypeLabel:        "label",
+	CommentTypeMilestone:    "milestone",
+	CommentTypeAssignees:    "assignees",
+	CommentTypeChangeTitle:  "change_title",
+	CommentTypeDeleteBranch: "delete_branch",
+	CommentTypePin:          "pin",
+	CommentTypeUnpin:        "unpin",
+	CommentTypeDeadline:     "deadline",
+}
+
+// Name returns the name of the comment type.
+func (t CommentType) Name() string {
+	return commentTypeNames[t]
+}
+
+// IsEvent returns true if the comments of the type are events of the timeline
+// of the issue, like a status change, rather than comments of users.
+func (t CommentType) IsEvent() bool {
+	return t != CommentTypeComment
+}
+
+// IsRef returns true if the comments of the type are references to the issue
+// from another issue, pull request or comment.
+func (t CommentType) IsRef() bool {
+	return t == CommentTypeIssueRef || t == CommentTypeCommentRef || t == CommentTypePullRef
+}
+
+// EventName returns the name of the timeline event of the comment, which
+// tells for example whether a label was added or removed.
+func (c *Comment) EventName() string {
+	switch c.Type {
+	case CommentTypeComment:
+		return "commented"
+	case CommentTypeReopen:
+		return "reopened"
+	case CommentTypeClose:
+		return "closed"
+	case CommentTypeIssueRef, CommentTypeCommentRef, CommentTypePullRef:
+		return "cross-referenced"
+	case CommentTypeCommitRef:
+		return "referenced"
+	case CommentTypeLabel:
+		if len(c.Content) > 0 {
+			return "labeled"
+		}
+		return "unlabeled"
+	case CommentTypeMilestone:
+		if c.MilestoneID > 0 {
+			return "milestoned"
+		}
+		return "demilestoned"
+	case CommentTypeAssignees:
+		if c.AssigneeID > 0 {
+			return "assigned"
+		}
+		return "unassigned"
+	case CommentTypeChangeTitle:
+		return "renamed"
+	case CommentTypeDeleteBranch:
+		return "head_ref_deleted"
+	case CommentTypePin:
+		return "pinned"
+	case CommentTypeUnpin:
+		return "unpinned"
+	case CommentTypeDeadline:
+		if len(c.NewTitle) == 0 {
+			return "deadline_removed"
+		}
+		return "deadline_changed"
+	}
+	return ""
+}
+
+// LoadEventAttributes loads the label, milestones, assignees or issue the
+// timeline event of the comment is about. A reference from an issue which
+// has been deleted is left without its issue.
+func (c *Comment) LoadEventAttributes() error {
+	switch {
+	case c.Type == CommentTypeLabel:
+		return c.LoadLabel()
+	case c.Type == CommentTypeMilestone:
+		return c.LoadMilestone()
+	case c.Type == CommentTypeAssignees:
+		return c.LoadAssignees()
+	case c.Type.IsRef():
+		if err := c.LoadRefIssue(); err != nil && !IsErrIssueNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetIssueTimeline returns the timeline events of an issue, without the
+// comments of users, their attributes loaded.
+func GetIssueTimeline(issueID int64) ([]*Comment, error) {
+	events := make([]*Comment, 0, 10)
+	if err := x.
+		Where("issue_id = ?", issueID).
+		And("type <> ?", CommentTypeComment).
+		Asc("created_unix").
+		Asc("id").
+		Find(&events); err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		if err := event.LoadEventAttributes(); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// HideInvisibleRef forgets the issue referencing the issue of the comment if
+// it is in a private repository doer can't see, doer is nil for anonymous
+// users.
+func (c *Comment) HideInvisibleRef(doer *User) error {
+	if c.RefIssue == nil || !c.RefIssue.Repo.IsPrivate {
+		return nil
+	} else if doer == nil {
+		c.RefIssue = nil
+		return nil
+	}
+
+	has, err := HasAccess(doer.ID, c.RefIssue.Repo, AccessModeRead)
+	if err != nil {
+		return err
+	} else if !has {
+		c.RefIssue = nil
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommentType_Name(t *testing.T) {
+	for tp := CommentTypeComment; tp <= CommentTypeDeadline; tp++ {
+		assert.NotEmpty(t, tp.Name(), "comment type %d", tp)
+	}
+	assert.False(t, CommentTypeComment.IsEvent())
+	assert.True(t, CommentTypeClose.IsEvent())
+	assert.True(t, CommentTypePullRef.IsRef())
+	assert.False(t, CommentTypeCommitRef.IsRef())
+}
+
+func TestComment_EventName(t *testing.T) {
+	for _, test := range []struct {
+		Comment *Comment
+		Event   string
+	}{
+		{&Comment{Type: CommentTypeClose}, "closed"},
+		{&Comment{Type: CommentTypeLabel, Content: "1"}, "labeled"},
+		{&Comment{Type: CommentTypeLabel}, "unlabeled"},
+		{&Comment{Type: CommentTypeMilestone, OldMilestoneID: 1, MilestoneID: 2}, "milestoned"},
+		{&Comment{Type: CommentTypeMilestone, OldMilestoneID: 1}, "demilestoned"},
+		{&Comment{Type: CommentTypeAssignees, AssigneeID: 2}, "assigned"},
+		{&Comment{Type: CommentTypeAssignees, OldAssigneeID: 2}, "unassigned"},
+		{&Comment{Type: CommentTypeDeadline, NewTitle: "2018-01-01"}, "deadline_changed"},
+		{&Comment{Type: CommentTypeDeadline, OldTitle: "2018-01-01"}, "deadline_removed"},
+		{&Comment{Type: CommentTypeCommentRef}, "cross-referenced"},
+	} {
+		assert.Equal(t, test.Event, test.Comment.EventName())
+	}
+}
+
+func TestGetIssueTimeline(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	events, err := GetIssueTimeline(1)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.EqualValues(t, 1, events[0].ID)
+		assert.Equal(t, "labeled", events[0].EventName())
+		if assert.NotNil(t, events[0].Label) {
+			assert.EqualValues(t, 1, events[0].Label.ID)
+		}
+	}
+
+	events, err = GetIssueTimeline(2)
+	assert.NoError(t, err)
+	assert.Len(t, events, 0)
+}
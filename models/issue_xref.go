@@ -0,0 +1,191 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/Unknwon/com"
+)
+
+// issueRefPattern matches references to issues of the same repository
+// like #123, and references to issues of other repositories like owner/repo#123.
+var issueRefPattern = regexp.MustCompile(`(?:^|[\s(\[])((?:[\w.-]+/[\w.-]+)?#[0-9]+)\b`)
+
+// findIssueRefs returns all distinct issue references in content, references
+// without repository are completed with the full name of given repository.
+func findIssueRefs(repo *Repository, content string) []string {
+	refs := make([]string, 0, 5)
+	marked := make(map[string]bool)
+	for _, match := range issueRefPattern.FindAllStringSubmatch(content, -1) {
+		ref := match[1]
+		if ref[0] == '#' {
+			ref = repo.FullName() + ref
+		}
+		ref = strings.ToLower(ref)
+		if marked[ref] {
+			continue
+		}
+		marked[ref] = true
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// isUnresolvableIssueRef returns true if the error returned by GetIssueByRef
+// means the reference does not point to any existing issue.
+func isUnresolvableIssueRef(err error) bool {
+	return IsErrIssueNotExist(err) || IsErrRepoNotExist(err) || IsErrUserNotExist(err) ||
+		err == ErrInvalidReference || err == errMissingIssueNumber
+}
+
+// createCrossReferences adds a reference comment to every issue mentioned in
+// content of given issue, or of given comment if it is not nil.
+func createCrossReferences(doer *User, issue *Issue, comment *Comment, content string) error {
+	// References would reveal confidential issues on the referenced issues.
+	if issue.IsConfidential {
+		return nil
+	}
+	if err := issue.loadRepo(x); err != nil {
+		return err
+	}
+
+	cmtType := CommentTypeIssueRef
+	if comment != nil {
+		cmtType = CommentTypeCommentRef
+	} else if issue.IsPull {
+		cmtType = CommentTypePullRef
+	}
+
+	for _, ref := range findIssueRefs(issue.Repo, content) {
+		refIssue, err := GetIssueByRef(ref)
+		if err != nil {
+			if isUnresolvableIssueRef(err) {
+				continue
+			}
+			return fmt.Errorf("GetIssueByRef [%s]: %v", ref, err)
+		} else if refIssue.ID == issue.ID {
+			continue
+		}
+
+		// Do not reveal existence of issues the doer is not allowed to see.
+		if has, err := HasAccess(doer.ID, refIssue.Repo, AccessModeRead); err != nil {
+			return fmt.Errorf("HasAccess: %v", err)
+		} else if !has {
+			continue
+		} else if visible, err := refIssue.IsVisibleTo(doer); err != nil {
+			return fmt.Errorf("IsVisibleTo: %v", err)
+		} else if !visible {
+			continue
+		}
+
+		// Every issue is referenced only once by another issue.
+		has, err := x.
+			Where("issue_id = ? AND ref_issue_id = ?", refIssue.ID, issue.ID).
+			In("type", CommentTypeIssueRef, CommentTypeCommentRef, CommentTypePullRef).
+			Get(new(Comment))
+		if err != nil {
+			return fmt.Errorf("check reference comment: %v", err)
+		} else if has {
+			continue
+		}
+
+		opts := &CreateCommentOptions{
+			Type:       cmtType,
+			Doer:       doer,
+			Repo:       refIssue.Repo,
+			Issue:      refIssue,
+			RefRepoID:  issue.RepoID,
+			RefIssueID: issue.ID,
+		}
+		if comment != nil {
+			opts.RefCommentID = comment.ID
+		}
+		if _, err = CreateComment(opts); err != nil {
+			return fmt.Errorf("CreateComment: %v", err)
+		}
+	}
+	return nil
+}
+
+// addCrossReferences is like createCrossReferences but only logs errors, since
+// failing to reference other issues must not fail the original operation.
+func addCrossReferences(doer *User, issue *Issue, comment *Comment, content string) {
+	if err := createCrossReferences(doer, issue, comment, content); err != nil {
+		log.Error(4, "createCrossReferences [issue_id: %d]: %v", issue.ID, err)
+	}
+}
+
+// LoadRefIssue loads the issue which references the issue of this comment.
+func (c *Comment) LoadRefIssue() (err error) {
+	if c.RefIssueID == 0 || c.RefIssue != nil {
+		return nil
+	}
+
+	c.RefIssue, err = GetIssueByID(c.RefIssueID)
+	if err != nil {
+		return err
+	}
+	return c.RefIssue.loadRepo(x)
+}
+
+// RefLink returns the link to the issue or comment referencing the issue of
+// this comment.
+func (c *Comment) RefLink() string {
+	if c.RefIssue == nil {
+		return ""
+	}
+
+	link := c.RefIssue.HTMLURL()
+	if c.RefCommentID > 0 {
+		link += "#issuecomment-" + com.ToStr(c.RefCommentID)
+	}
+	return link
+}
+
+// closeIssuesByRefs closes issues of given repository referenced with a
+// closing keyword in any of given texts.
+func closeIssuesByRefs(doer *User, repo *Repository, texts ...string) error {
+	marked := make(map[int64]bool)
+	for _, text := range texts {
+		for _, ref := range issueCloseKeywordsPat.FindAllString(text, -1) {
+			ref = ref[strings.IndexByte(ref, byte(' '))+1:]
+			ref = strings.TrimRightFunc(ref, issueIndexTrimRight)
+
+			if len(ref) == 0 {
+				continue
+			}
+
+			// Add repo name if missing
+			if ref[0] == '#' {
+				ref = fmt.Sprintf("%s%s", repo.FullName(), ref)
+			} else if !strings.Contains(ref, "/") {
+				continue
+			}
+
+			issue, err := GetIssueByRef(ref)
+			if err != nil {
+				if isUnresolvableIssueRef(err) {
+					continue
+				}
+				return err
+			}
+
+			if marked[issue.ID] || issue.RepoID != repo.ID || issue.IsClosed || issue.IsPull {
+				continue
+			}
+			marked[issue.ID] = true
+
+			if err = issue.ChangeStatus(doer, repo, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
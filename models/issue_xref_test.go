@@ -0,0 +1,58 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindIssueRefs(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	assert.Equal(t, []string{"user2/repo1#2", "user2/repo2#1"},
+		findIssueRefs(repo, "see #2, User2/repo2#1 and (#2) but not foo#3"))
+	assert.Empty(t, findIssueRefs(repo, "nothing here"))
+}
+
+func TestCreateIssueComment_CrossReference(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+
+	comment, err := CreateIssueComment(doer, repo, issue, "related to #2 and user2/repo2#1, not #1 itself", nil)
+	assert.NoError(t, err)
+	AssertExistsAndLoadBean(t, &Comment{
+		Type:         CommentTypeCommentRef,
+		IssueID:      2,
+		PosterID:     doer.ID,
+		RefRepoID:    repo.ID,
+		RefIssueID:   issue.ID,
+		RefCommentID: comment.ID,
+	})
+	AssertExistsAndLoadBean(t, &Comment{Type: CommentTypeCommentRef, IssueID: 4, RefIssueID: issue.ID})
+	AssertNotExistsBean(t, &Comment{Type: CommentTypeCommentRef, IssueID: issue.ID})
+
+	// Another mention by the same issue is not recorded again.
+	_, err = CreateIssueComment(doer, repo, issue, "again #2", nil)
+	assert.NoError(t, err)
+	count, err := x.Count(&Comment{IssueID: 2, RefIssueID: issue.ID})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+}
+
+func TestCloseIssuesByRefs(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	assert.NoError(t, closeIssuesByRefs(doer, repo, "Add feature", "Fixes #1, see #3"))
+	AssertExistsAndLoadBean(t, &Issue{ID: 1, IsClosed: true})
+	pull := AssertExistsAndLoadBean(t, &Issue{ID: 3}).(*Issue)
+	assert.False(t, pull.IsClosed)
+}
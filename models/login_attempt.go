@@ -0,0 +1,153 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-xorm/xorm"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// maxLoginLockout is the longest time sign-in attempts are refused for.
+const maxLoginLockout = 24 * time.Hour
+
+// LoginAttempt represents an attempt to sign in to an account with a password.
+type LoginAttempt struct {
+	ID          int64  `xorm:"pk autoincr"`
+	UserID      int64  `xorm:"INDEX NOT NULL"`
+	IP          string `xorm:"VARCHAR(64) INDEX"`
+	UserAgent   string
+	IsSuccess   bool      `xorm:"NOT NULL DEFAULT false"`
+	Created     time.Time `xorm:"-"`
+	CreatedUnix int64     `xorm:"INDEX created"`
+}
+
+// AfterSet is invoked from XORM after setting the value of a field of this object.
+func (a *LoginAttempt) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		a.Created = time.Unix(a.CreatedUnix, 0).Local()
+	}
+}
+
+// loginLockoutUntil returns the time sign-in attempts to the account of the
+// user from given IP address are refused until, and the number of failed
+// attempts since the last successful one. The lockout starts after
+// LoginLockoutThreshold failed attempts, and doubles with every further one.
+func loginLockoutUntil(e Engine, userID int64, ip string) (time.Time, int64, error) {
+	if setting.LoginLockoutThreshold <= 0 {
+		return time.Time{}, 0, nil
+	}
+
+	lastSuccess := new(LoginAttempt)
+	if _, err := e.
+		Where("user_id = ? AND is_success = ?", userID, true).
+		Desc("id").
+		Get(lastSuccess); err != nil {
+		return time.Time{}, 0, err
+	}
+
+	since := time.Now().Add(-maxLoginLockout).Unix()
+	cond := "user_id = ? AND ip = ? AND is_success = ? AND id > ? AND created_unix >= ?"
+	failures, err := e.Where(cond, userID, ip, false, lastSuccess.ID, since).Count(new(LoginAttempt))
+	if err != nil {
+		return time.Time{}, 0, err
+	} else if failures < int64(setting.LoginLockoutThreshold) {
+		return time.Time{}, failures, nil
+	}
+	lastFailure := new(LoginAttempt)
+	if _, err = e.Where(cond, userID, ip, false, lastSuccess.ID, since).Desc("id").Get(lastFailure); err != nil {
+		return time.Time{}, 0, err
+	}
+
+	lockout := setting.LoginLockoutDuration
+	for i := int64(setting.LoginLockoutThreshold); i < failures && lockout < maxLoginLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > maxLoginLockout {
+		lockout = maxLoginLockout
+	}
+	return time.Unix(lastFailure.CreatedUnix, 0).Add(lockout), failures, nil
+}
+
+// recordLoginAttempt saves an attempt to sign in and forgets the attempts
+// older than LoginHistoryDays.
+func recordLoginAttempt(userID int64, ip, userAgent string, isSuccess bool) error {
+	if _, err := x.Insert(&LoginAttempt{
+		UserID:    userID,
+		IP:        ip,
+		UserAgent: userAgent,
+		IsSuccess: isSuccess,
+	}); err != nil {
+		return err
+	}
+
+	deadline := time.Now().AddDate(0, 0, -setting.LoginHistoryDays).Unix()
+	_, err := x.Where("user_id = ? AND created_unix < ?", userID, deadline).Delete(new(LoginAttempt))
+	return err
+}
+
+// UserSignInFrom validates user name and password like UserSignIn, and records
+// the attempt for the account. Attempts from an IP address which failed too
+// many times are refused for a while with ErrUserLocked, and the user is
+// notified when the lockout starts.
+func UserSignInFrom(username, password, ip, userAgent string) (*User, error) {
+	user := &User{LowerName: strings.ToLower(strings.TrimSpace(username))}
+	if strings.Contains(username, "@") {
+		user = &User{Email: strings.ToLower(strings.TrimSpace(username))}
+	}
+	has, err := x.Get(user)
+	if err != nil {
+		return nil, err
+	} else if !has || user.IsOrganization() {
+		return UserSignIn(username, password)
+	}
+
+	until, _, err := loginLockoutUntil(x, user.ID, ip)
+	if err != nil {
+		return nil, err
+	} else if until.After(time.Now()) {
+		return nil, ErrUserLocked{user.ID, until}
+	}
+
+	u, err := UserSignIn(username, password)
+	if err != nil {
+		if !IsErrUserNotExist(err) {
+			return nil, err
+		}
+		if err := recordLoginAttempt(user.ID, ip, userAgent, false); err != nil {
+			return nil, err
+		}
+
+		until, failures, lockErr := loginLockoutUntil(x, user.ID, ip)
+		if lockErr != nil {
+			return nil, lockErr
+		} else if failures == int64(setting.LoginLockoutThreshold) && !user.IsDeleted() {
+			log.Trace("Sign-in attempts to %s from %s refused until %v", user.Name, ip, until)
+			SendLoginLockoutMail(user, ip, until)
+		}
+		return nil, err
+	}
+
+	if err = recordLoginAttempt(u.ID, ip, userAgent, true); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// GetLoginAttempts returns the latest sign-in attempts to the account of the
+// user, newest first.
+func GetLoginAttempts(userID int64, limit int) ([]*LoginAttempt, error) {
+	attempts := make([]*LoginAttempt, 0, limit)
+	return attempts, x.
+		Where("user_id = ?", userID).
+		Desc("created_unix", "id").
+		Limit(limit).
+		Find(&attempts)
+}
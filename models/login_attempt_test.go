@@ -0,0 +1,69 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserSignInFrom(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer func(threshold int, duration time.Duration) {
+		setting.LoginLockoutThreshold = threshold
+		setting.LoginLockoutDuration = duration
+	}(setting.LoginLockoutThreshold, setting.LoginLockoutDuration)
+	setting.LoginLockoutThreshold = 3
+	setting.LoginLockoutDuration = time.Minute
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	u, err := UserSignInFrom(user.Name, "password", "1.2.3.4", "test agent")
+	assert.NoError(t, err)
+	assert.EqualValues(t, user.ID, u.ID)
+
+	for i := 0; i < setting.LoginLockoutThreshold; i++ {
+		_, err = UserSignInFrom(user.Name, "wrong", "1.2.3.4", "test agent")
+		assert.True(t, IsErrUserNotExist(err))
+	}
+
+	// Refused from the same IP address even with the right password
+	_, err = UserSignInFrom(user.Name, "password", "1.2.3.4", "test agent")
+	assert.True(t, IsErrUserLocked(err))
+	until := err.(ErrUserLocked).Until
+	assert.True(t, until.After(time.Now()))
+	assert.True(t, until.Before(time.Now().Add(2*time.Minute)))
+
+	// The lockout doubles with every further failure
+	assert.NoError(t, recordLoginAttempt(user.ID, "1.2.3.4", "test agent", false))
+	longer, failures, err := loginLockoutUntil(x, user.ID, "1.2.3.4")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, failures)
+	assert.True(t, longer.After(time.Now().Add(90*time.Second)))
+
+	// Other IP addresses are not refused
+	u, err = UserSignInFrom(user.Email, "password", "5.6.7.8", "other agent")
+	assert.NoError(t, err)
+	assert.EqualValues(t, user.ID, u.ID)
+
+	attempts, err := GetLoginAttempts(user.ID, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, attempts, 6) {
+		assert.True(t, attempts[0].IsSuccess)
+		assert.Equal(t, "5.6.7.8", attempts[0].IP)
+		assert.Equal(t, "other agent", attempts[0].UserAgent)
+		assert.False(t, attempts[1].IsSuccess)
+		assert.True(t, attempts[5].IsSuccess)
+	}
+
+	// A successful sign-in resets the count
+	_, failures, err = loginLockoutUntil(x, user.ID, "1.2.3.4")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, failures)
+}
@@ -147,6 +147,15 @@ type LoginSource struct {
 	IsSyncEnabled bool            `xorm:"INDEX NOT NULL DEFAULT false"`
 	Cfg           core.Conversion `xorm:"TEXT"`
 
+	// Steps of the user synchronization, in dry run mode changes are only logged.
+	SyncCreateUsers     bool `xorm:"NOT NULL DEFAULT true"`
+	SyncUpdateUsers     bool `xorm:"NOT NULL DEFAULT true"`
+	SyncDeactivateUsers bool `xorm:"NOT NULL DEFAULT true"`
+	// SyncSuspendUsers suspends users removed from the directory instead of
+	// deactivating them.
+	SyncSuspendUsers bool `xorm:"NOT NULL DEFAULT false"`
+	SyncDryRun       bool `xorm:"NOT NULL DEFAULT false"`
+
 	Created     time.Time `xorm:"-"`
 	CreatedUnix int64     `xorm:"INDEX"`
 	Updated     time.Time `xorm:"-"`
@@ -625,8 +634,19 @@ func ExternalUserLogin(user *User, login, password string, source *LoginSource,
 	return nil, ErrUnsupportedLoginType
 }
 
-// UserSignIn validates user name and password.
+// UserSignIn validates user name and password, it returns ErrUserSuspended
+// if the credentials are valid but the user is suspended.
 func UserSignIn(username, password string) (*User, error) {
+	u, err := userSignIn(username, password)
+	if err != nil {
+		return nil, err
+	} else if u.IsSuspended {
+		return nil, ErrUserSuspended{u.ID, u.SuspendReason}
+	}
+	return u, nil
+}
+
+func userSignIn(username, password string) (*User, error) {
 	var user *User
 	if strings.Contains(username, "@") {
 		user = &User{Email: strings.ToLower(strings.TrimSpace(username))}
@@ -655,6 +675,9 @@ func UserSignIn(username, password string) (*User, error) {
 	}
 
 	if hasUser {
+		if user.DeletedUnix > 0 {
+			return nil, ErrUserNotExist{user.ID, user.Name, 0}
+		}
 		switch user.LoginType {
 		case LoginNoType, LoginPlain, LoginOAuth2:
 			if user.ValidatePassword(password) {
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"html/template"
 	"path"
+	"time"
 
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/log"
@@ -25,10 +26,16 @@ const (
 	mailAuthResetPassword  base.TplName = "auth/reset_passwd"
 	mailAuthRegisterNotify base.TplName = "auth/register_notify"
 
-	mailIssueComment base.TplName = "issue/comment"
-	mailIssueMention base.TplName = "issue/mention"
+	mailIssueComment  base.TplName = "issue/comment"
+	mailIssueMention  base.TplName = "issue/mention"
+	mailIssueDeadline base.TplName = "issue/deadline"
+	mailServiceDesk   base.TplName = "issue/service_desk"
 
-	mailNotifyCollaborator base.TplName = "notify/collaborator"
+	mailNotifyCollaborator  base.TplName = "notify/collaborator"
+	mailNotifyMirrorFailure base.TplName = "notify/mirror_failure"
+	mailNotifyAbuseReport   base.TplName = "notify/abuse_report"
+	mailNotifyUserExport    base.TplName = "notify/user_export"
+	mailNotifyLoginLockout  base.TplName = "notify/login_lockout"
 )
 
 var templates *template.Template
@@ -140,6 +147,156 @@ func SendCollaboratorMail(u, doer *User, repo *Repository) {
 	mailer.SendAsync(msg)
 }
 
+// SendMirrorSyncFailureMail sends the last sync error of a mirror repository
+// to one of its owners.
+func SendMirrorSyncFailureMail(u *User, m *Mirror) {
+	if setting.MailService == nil {
+		return
+	}
+
+	repoName := path.Join(m.Repo.Owner.Name, m.Repo.Name)
+	subject := fmt.Sprintf("Mirror %s failed to sync", repoName)
+
+	data := composeTplData(subject, m.LastSyncError, m.Repo.HTMLURL()+"/settings")
+	data["RepoName"] = repoName
+	data["Failures"] = m.SyncFailures
+
+	var content bytes.Buffer
+
+	if err := templates.ExecuteTemplate(&content, string(mailNotifyMirrorFailure), data); err != nil {
+		log.Error(3, "Template: %v", err)
+		return
+	}
+
+	msg := mailer.NewMessage([]string{u.Email}, subject, content.String())
+	msg.Info = fmt.Sprintf("UID: %d, mirror sync failure", u.ID)
+
+	mailer.SendAsync(msg)
+}
+
+// SendAbuseReportClosedMail lets the reporter know that the moderators
+// resolved or dismissed the report.
+func SendAbuseReportClosedMail(r *AbuseReport) {
+	if setting.MailService == nil {
+		return
+	}
+
+	subject := fmt.Sprintf("Your report #%d has been reviewed", r.ID)
+
+	data := composeTplData(subject, "", r.TargetLink)
+	data["Target"] = r.TargetTitle
+	data["IsResolved"] = r.IsResolved()
+
+	var content bytes.Buffer
+
+	if err := templates.ExecuteTemplate(&content, string(mailNotifyAbuseReport), data); err != nil {
+		log.Error(3, "Template: %v", err)
+		return
+	}
+
+	msg := mailer.NewMessage([]string{r.Reporter.Email}, subject, content.String())
+	msg.Info = fmt.Sprintf("UID: %d, abuse report closed", r.Reporter.ID)
+
+	mailer.SendAsync(msg)
+}
+
+// SendUserExportReadyMail lets the user know that the archive of their data
+// can be downloaded.
+func SendUserExportReadyMail(u *User, e *UserExport) {
+	if setting.MailService == nil {
+		return
+	}
+
+	subject := "Your data export is ready"
+
+	data := composeTplData(subject, "", setting.AppURL+"user/settings/export")
+	data["Expiry"] = e.Expiry().Format("2006-01-02 15:04")
+
+	var content bytes.Buffer
+
+	if err := templates.ExecuteTemplate(&content, string(mailNotifyUserExport), data); err != nil {
+		log.Error(3, "Template: %v", err)
+		return
+	}
+
+	msg := mailer.NewMessage([]string{u.Email}, subject, content.String())
+	msg.Info = fmt.Sprintf("UID: %d, user data export ready", u.ID)
+
+	mailer.SendAsync(msg)
+}
+
+// SendLoginLockoutMail lets the user know sign-in attempts from given IP
+// address are refused after too many failures.
+func SendLoginLockoutMail(u *User, ip string, until time.Time) {
+	if setting.MailService == nil {
+		return
+	}
+
+	subject := "Sign-in attempts to your account have been blocked"
+
+	data := composeTplData(subject, "", setting.AppURL+"user/settings/security")
+	data["IP"] = ip
+	data["Until"] = until.Format("2006-01-02 15:04")
+
+	var content bytes.Buffer
+
+	if err := templates.ExecuteTemplate(&content, string(mailNotifyLoginLockout), data); err != nil {
+		log.Error(3, "Template: %v", err)
+		return
+	}
+
+	msg := mailer.NewMessage([]string{u.Email}, subject, content.String())
+	msg.Info = fmt.Sprintf("UID: %d, sign-in lockout", u.ID)
+
+	mailer.SendAsync(msg)
+}
+
+// SendIssueDeadlineMail reminds the assignee of an issue of its approaching deadline.
+func SendIssueDeadlineMail(u *User, issue *Issue) {
+	subject := issue.mailSubject()
+	data := composeTplData(subject, "", issue.HTMLURL())
+	data["Deadline"] = issue.Deadline.Format("2006-01-02")
+
+	var content bytes.Buffer
+
+	if err := templates.ExecuteTemplate(&content, string(mailIssueDeadline), data); err != nil {
+		log.Error(3, "Template: %v", err)
+		return
+	}
+
+	msg := mailer.NewMessage([]string{u.Email}, subject, content.String())
+	msg.Info = fmt.Sprintf("UID: %d, issue deadline reminder", u.ID)
+
+	mailer.SendAsync(msg)
+}
+
+// SendServiceDeskMail sends the status of an issue opened through the service
+// desk to its reporter, who may not have access to the repository.
+func SendServiceDeskMail(reporter *ServiceDeskIssue, issue *Issue, status string) {
+	if setting.MailService == nil {
+		return
+	}
+
+	subject := issue.mailSubject()
+	data := composeTplData(subject, "", "")
+	data["Name"] = reporter.Name
+	data["Index"] = issue.Index
+	data["Title"] = issue.Title
+	data["Status"] = status
+
+	var content bytes.Buffer
+
+	if err := templates.ExecuteTemplate(&content, string(mailServiceDesk), data); err != nil {
+		log.Error(3, "Template: %v", err)
+		return
+	}
+
+	msg := mailer.NewMessage([]string{reporter.Email}, subject, content.String())
+	msg.Info = fmt.Sprintf("Service desk issue: %d, %s", issue.ID, status)
+
+	mailer.SendAsync(msg)
+}
+
 func composeTplData(subject, body, link string) map[string]interface{} {
 	data := make(map[string]interface{}, 10)
 	data["Subject"] = subject
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
@@ -118,10 +119,168 @@ var migrations = []Migration{
 	NewMigration("remove columns from action", removeActionColumns),
 	// v34 -> v35
 	NewMigration("give all units to owner teams", giveAllUnitsToOwnerTeams),
+	// v35 -> v36
+	NewMigration("add contributor stats tables", addContributorStats),
+	// v36 -> v37
+	NewMigration("add index on comment type", addCommentTypeIndex),
+	// v37 -> v38
+	NewMigration("add repository access log table", addRepoAccessLog),
+	// v38 -> v39
+	NewMigration("add last sync result to mirrors", addMirrorLastSync),
+	// v39 -> v40
+	NewMigration("add blocked word and moderation item tables", addModerationTables),
+	// v40 -> v41
+	NewMigration("add cross reference columns to comments", addCommentCrossReference),
+	// v41 -> v42
+	NewMigration("add close issues via commit column to repository table", addRepoCloseIssuesViaCommit),
+	// v42 -> v43
+	NewMigration("add language statistics table", addLanguageStatTable),
+	// v43 -> v44
+	NewMigration("move mirror credentials out of git config", encryptMirrorCredentials),
+	// v44 -> v45
+	NewMigration("add required status context table", addRequiredStatusContextTable),
+	// v45 -> v46
+	NewMigration("add deploy token table", addDeployTokenTable),
+	// v46 -> v47
+	NewMigration("add issue board tables", addBoardTables),
+	// v47 -> v48
+	NewMigration("add issue deadline reminder table", addIssueDeadlineReminderTable),
+	// v48 -> v49
+	NewMigration("add user status", addUserStatus),
+	// v49 -> v50
+	NewMigration("add banner table", addBannerTable),
+	// v50 -> v51
+	NewMigration("add profile field tables", addProfileFieldTables),
+	// v51 -> v52
+	NewMigration("add service desk tables", addServiceDeskTables),
+	// v52 -> v53
+	NewMigration("add notification channel table", addNotificationChannelTable),
+	// v53 -> v54
+	NewMigration("add unit types to collaboration", addCollaborationUnitTypes),
+	// v54 -> v55
+	NewMigration("add triage access mode", addTriageAccessMode),
+	// v55 -> v56
+	NewMigration("add user synchronization options to login source", addLoginSourceSyncOptions),
+	// v56 -> v57
+	NewMigration("add session table for database session provider", addSessionTable),
+	// v57 -> v58
+	NewMigration("add upload session table for resumable uploads", addUploadSessionTable),
+	// v58 -> v59
+	NewMigration("add pin order column to issue table", addIssuePinOrder),
+	// v59 -> v60
+	NewMigration("add uploader id column to attachment table", addAttachmentUploaderID),
+	// v60 -> v61
+	NewMigration("add severity to system notices", addNoticeSeverity),
+	// v61 -> v62
+	NewMigration("add sync duration and failures to mirror table", addMirrorSyncStats),
+	// v62 -> v63
+	NewMigration("add mode column to watch table", addWatchMode),
+	// v63 -> v64
+	NewMigration("add description column to label table", addLabelDescription),
+	// v64 -> v65
+	NewMigration("add organization label and milestone templates", addOrgTemplates),
+	// v65 -> v66
+	NewMigration("add Git hook templates", addGitHookTemplates),
+	// v66 -> v67
+	NewMigration("add previous secret to webhook table", addWebhookPreviousSecret),
+	// v67 -> v68
+	NewMigration("add queue item table", addQueueItemTable),
+	// v68 -> v69
+	NewMigration("add confidential column to issue table", addIssueConfidential),
+	// v69 -> v70
+	NewMigration("add repository security advisory tables", addRepoAdvisoryTables),
+	// v70 -> v71
+	NewMigration("add pull request reviews and required approvals", addReviewsAndRequiredApprovals),
+	// v71 -> v72
+	NewMigration("add abuse report table", addAbuseReportTable),
+	// v72 -> v73
+	NewMigration("add deleted_unix column to repository and user tables", addDeletedUnixToRepositoryAndUser),
+	// v73 -> v74
+	NewMigration("add user export table", addUserExportTable),
+	// v74 -> v75
+	NewMigration("add is_pending_primary column to email_address table", addEmailAddressPendingPrimary),
+	// v75 -> v76
+	NewMigration("add passwd_changed_unix column to user table", addUserPasswdChangedUnix),
+	// v76 -> v77
+	NewMigration("add login attempt table", addLoginAttemptTable),
+	// v77 -> v78
+	NewMigration("add user session table", addUserSessionTable),
+	// v78 -> v79
+	NewMigration("add renamed branch table", addRenamedBranchTable),
+	// v79 -> v80
+	NewMigration("add avatar column to repository table", addRepositoryAvatar),
+	// v80 -> v81
+	NewMigration("add federation key table", addFederationKeyTable),
+	// v81 -> v82
+	NewMigration("add size column to attachment table", addAttachmentSize),
+	// v82 -> v83
+	NewMigration("add audit log table", addAuditLogTable),
+	// v83 -> v84
+	NewMigration("add suspension columns to user and login source tables", addUserSuspension),
+	// v84 -> v85
+	NewMigration("add refs column to repo access log table", addRepoAccessLogRefs),
+}
+
+// ExpectedVersion returns the version of the database after all migrations.
+func ExpectedVersion() int64 {
+	return int64(minDBVersion + len(migrations))
+}
+
+// CurrentVersion returns the current version of the database,
+// or 0 if it has not been migrated yet.
+func CurrentVersion(x *xorm.Engine) (int64, error) {
+	exist, err := x.IsTableExist(new(Version))
+	if err != nil {
+		return 0, fmt.Errorf("IsTableExist: %v", err)
+	} else if !exist {
+		return 0, nil
+	}
+
+	currentVersion := &Version{ID: 1}
+	has, err := x.Get(currentVersion)
+	if err != nil {
+		return 0, fmt.Errorf("get: %v", err)
+	} else if !has {
+		return 0, nil
+	}
+	return currentVersion.Version, nil
+}
+
+// SetVersion sets the version of the database, e.g. after it has been
+// restored from a dump.
+func SetVersion(x *xorm.Engine, version int64) error {
+	if err := x.Sync(new(Version)); err != nil {
+		return fmt.Errorf("sync: %v", err)
+	}
+
+	has, err := x.Get(&Version{ID: 1})
+	if err != nil {
+		return fmt.Errorf("get: %v", err)
+	} else if has {
+		_, err = x.Id(1).Cols("version").Update(&Version{Version: version})
+	} else {
+		_, err = x.Insert(&Version{ID: 1, Version: version})
+	}
+	return err
+}
+
+// Options changes how the database is migrated.
+type Options struct {
+	// DryRun only writes the pending migrations to Output, the database is
+	// not changed.
+	DryRun bool
+	Output io.Writer
+	// BeforeMigrate is called before the pending migrations are applied, if
+	// there are any, e.g. to back up the database.
+	BeforeMigrate func() error
 }
 
 // Migrate database to current version
-func Migrate(x *xorm.Engine) error {
+func Migrate(x *xorm.Engine, opts Options) error {
+	if opts.DryRun {
+		return dryRun(x, opts.Output)
+	}
+
 	if err := x.Sync(new(Version)); err != nil {
 		return fmt.Errorf("sync: %v", err)
 	}
@@ -154,7 +313,14 @@ Please try to upgrade to a lower version (>= v0.6.0) first, then upgrade to curr
 		_, err = x.Id(1).Update(currentVersion)
 		return err
 	}
-	for i, m := range migrations[v-minDBVersion:] {
+
+	pending := migrations[v-minDBVersion:]
+	if len(pending) > 0 && opts.BeforeMigrate != nil {
+		if err = opts.BeforeMigrate(); err != nil {
+			return fmt.Errorf("before migrate: %v", err)
+		}
+	}
+	for i, m := range pending {
 		log.Info("Migration: %s", m.Description())
 		if err = m.Migrate(x); err != nil {
 			return fmt.Errorf("do migrate: %v", err)
@@ -167,6 +333,31 @@ Please try to upgrade to a lower version (>= v0.6.0) first, then upgrade to curr
 	return nil
 }
 
+// dryRun writes the migrations which would be applied to the database.
+func dryRun(x *xorm.Engine, w io.Writer) error {
+	v, err := CurrentVersion(x)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case v == 0:
+		fmt.Fprintln(w, "No migrations: the database is not installed yet")
+		return nil
+	case minDBVersion > v:
+		return fmt.Errorf("database version %d is too old to be migrated, please upgrade to a lower version (>= v0.6.0) first", v)
+	case int(v-minDBVersion) >= len(migrations):
+		fmt.Fprintf(w, "No pending migrations: the database version is %d\n", v)
+		return nil
+	}
+
+	fmt.Fprintf(w, "Pending migrations from version %d to %d:\n", v, ExpectedVersion())
+	for i, m := range migrations[v-minDBVersion:] {
+		fmt.Fprintf(w, "  v%d -> v%d: %s\n", v+int64(i), v+int64(i)+1, m.Description())
+	}
+	return nil
+}
+
 func sessionRelease(sess *xorm.Session) {
 	if !sess.IsCommitedOrRollbacked {
 		sess.Rollback()
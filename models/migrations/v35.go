@@ -0,0 +1,38 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addContributorStats(x *xorm.Engine) error {
+	// ContributorWeek see models/repo_stats.go
+	type ContributorWeek struct {
+		ID        int64  `xorm:"pk autoincr"`
+		RepoID    int64  `xorm:"INDEX UNIQUE(s)"`
+		Email     string `xorm:"UNIQUE(s) NOT NULL"`
+		Name      string
+		Week      int64 `xorm:"UNIQUE(s)"`
+		Commits   int64
+		Additions int64
+		Deletions int64
+	}
+
+	// RepoStatsStatus see models/repo_stats.go
+	type RepoStatsStatus struct {
+		ID          int64  `xorm:"pk autoincr"`
+		RepoID      int64  `xorm:"UNIQUE"`
+		CommitSha   string `xorm:"VARCHAR(40)"`
+		UpdatedUnix int64
+	}
+
+	if err := x.Sync2(new(ContributorWeek), new(RepoStatsStatus)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addRepoAccessLog(x *xorm.Engine) error {
+	// RepoAccessLog see models/repo_access_log.go
+	type RepoAccessLog struct {
+		ID          int64 `xorm:"pk autoincr"`
+		RepoID      int64 `xorm:"INDEX"`
+		UserID      int64 `xorm:"INDEX"`
+		KeyID       int64
+		Protocol    string
+		Service     string
+		RemoteAddr  string
+		CreatedUnix int64 `xorm:"INDEX"`
+	}
+
+	if err := x.Sync2(new(RepoAccessLog)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addMirrorLastSync(x *xorm.Engine) error {
+	// Mirror see models/repo_mirror.go
+	type Mirror struct {
+		ID             int64 `xorm:"pk autoincr"`
+		RepoID         int64 `xorm:"INDEX"`
+		Interval       time.Duration
+		EnablePrune    bool  `xorm:"NOT NULL DEFAULT true"`
+		UpdatedUnix    int64 `xorm:"INDEX"`
+		NextUpdateUnix int64 `xorm:"INDEX"`
+		LastSyncUnix   int64
+		LastSyncError  string `xorm:"TEXT"`
+	}
+
+	if err := x.Sync2(new(Mirror)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
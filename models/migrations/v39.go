@@ -0,0 +1,40 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addModerationTables(x *xorm.Engine) error {
+	// BlockedWord see models/moderation.go
+	type BlockedWord struct {
+		ID          int64  `xorm:"pk autoincr"`
+		OwnerID     int64  `xorm:"UNIQUE(s)"`
+		Word        string `xorm:"UNIQUE(s) NOT NULL"`
+		Action      int
+		CreatedUnix int64
+	}
+
+	// ModerationItem see models/moderation.go
+	type ModerationItem struct {
+		ID          int64 `xorm:"pk autoincr"`
+		OwnerID     int64 `xorm:"INDEX"`
+		RepoID      int64 `xorm:"INDEX"`
+		IssueID     int64
+		PosterID    int64
+		Title       string
+		Content     string `xorm:"TEXT"`
+		Word        string
+		CreatedUnix int64 `xorm:"INDEX"`
+	}
+
+	if err := x.Sync2(new(BlockedWord), new(ModerationItem)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
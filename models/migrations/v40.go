@@ -0,0 +1,26 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addCommentCrossReference(x *xorm.Engine) error {
+	// Comment see models/issue_comment.go
+	type Comment struct {
+		ID           int64 `xorm:"pk autoincr"`
+		RefRepoID    int64
+		RefIssueID   int64 `xorm:"INDEX"`
+		RefCommentID int64
+	}
+
+	if err := x.Sync2(new(Comment)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
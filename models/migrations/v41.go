@@ -0,0 +1,24 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addRepoCloseIssuesViaCommit(x *xorm.Engine) error {
+	// Repository see models/repo.go
+	type Repository struct {
+		ID                   int64 `xorm:"pk autoincr"`
+		CloseIssuesViaCommit bool  `xorm:"NOT NULL DEFAULT true"`
+	}
+
+	if err := x.Sync2(new(Repository)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addLanguageStatTable(x *xorm.Engine) error {
+	// LanguageStat see models/repo_language_stats.go
+	type LanguageStat struct {
+		ID          int64  `xorm:"pk autoincr"`
+		RepoID      int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		CommitID    string `xorm:"VARCHAR(40)"`
+		Language    string `xorm:"VARCHAR(50) UNIQUE(s) INDEX NOT NULL"`
+		Size        int64  `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix int64  `xorm:"INDEX created"`
+	}
+
+	if err := x.Sync2(new(LanguageStat)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
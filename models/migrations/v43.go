@@ -0,0 +1,133 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/secret"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/Unknwon/com"
+	"github.com/go-xorm/xorm"
+	"gopkg.in/ini.v1"
+)
+
+// remoteCredentials returns the user credentials in the address of the
+// origin remote in given Git config.
+func remoteCredentials(configPath string) (*url.Userinfo, error) {
+	if !com.IsFile(configPath) {
+		return nil, nil
+	}
+
+	cfg, err := ini.Load(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %v", err)
+	}
+	u, err := url.Parse(cfg.Section("remote \"origin\"").Key("url").Value())
+	if err != nil {
+		return nil, nil
+	}
+	return u.User, nil
+}
+
+// removeRemoteCredentials removes the user credentials from the address of
+// the origin remote in given Git config.
+func removeRemoteCredentials(configPath string) error {
+	if !com.IsFile(configPath) {
+		return nil
+	}
+
+	cfg, err := ini.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %v", err)
+	}
+	key := cfg.Section("remote \"origin\"").Key("url")
+	u, err := url.Parse(key.Value())
+	if err != nil || u.User == nil {
+		return nil
+	}
+
+	u.User = nil
+	key.SetValue(u.String())
+	if err = cfg.SaveToIndent(configPath, "\t"); err != nil {
+		return fmt.Errorf("save config: %v", err)
+	}
+	return nil
+}
+
+func encryptMirrorCredentials(x *xorm.Engine) error {
+	// Mirror see models/repo_mirror.go
+	type Mirror struct {
+		ID          int64  `xorm:"pk autoincr"`
+		RepoID      int64  `xorm:"INDEX"`
+		Credentials string `xorm:"TEXT"`
+	}
+	type Repository struct {
+		ID      int64
+		OwnerID int64
+		Name    string
+	}
+	type User struct {
+		ID   int64
+		Name string
+	}
+
+	if err := x.Sync2(new(Mirror)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	var mirrors []*Mirror
+	if err := x.Find(&mirrors); err != nil {
+		return fmt.Errorf("find mirrors: %v", err)
+	}
+
+	for _, m := range mirrors {
+		repo := new(Repository)
+		has, err := x.Where("id = ?", m.RepoID).Get(repo)
+		if err != nil {
+			return fmt.Errorf("query repository [repo_id: %d]: %v", m.RepoID, err)
+		} else if !has {
+			continue
+		}
+		user := new(User)
+		has, err = x.Where("id = ?", repo.OwnerID).Get(user)
+		if err != nil {
+			return fmt.Errorf("query owner of repository [repo_id: %d, owner_id: %d]: %v", repo.ID, repo.OwnerID, err)
+		} else if !has {
+			continue
+		}
+
+		repoPath := filepath.Join(setting.RepoRootPath, strings.ToLower(user.Name), strings.ToLower(repo.Name))
+		credentials, err := remoteCredentials(filepath.Join(repoPath+".git", "config"))
+		if err != nil {
+			log.Warn("Read credentials of mirror [repo_id: %d]: %v", repo.ID, err)
+			continue
+		} else if credentials == nil {
+			continue
+		}
+
+		// The credentials are only removed from the Git configs once they
+		// are stored, so they are never lost.
+		if m.Credentials, err = secret.EncryptSecret(setting.SecretKey, credentials.String()); err != nil {
+			return fmt.Errorf("EncryptSecret: %v", err)
+		}
+		if _, err = x.Id(m.ID).Cols("credentials").Update(m); err != nil {
+			return fmt.Errorf("update mirror [repo_id: %d]: %v", repo.ID, err)
+		}
+
+		if err = removeRemoteCredentials(filepath.Join(repoPath+".git", "config")); err != nil {
+			log.Warn("Remove credentials of mirror [repo_id: %d]: %v", repo.ID, err)
+		}
+		if err = removeRemoteCredentials(filepath.Join(repoPath+".wiki.git", "config")); err != nil {
+			log.Warn("Remove credentials of mirror wiki [repo_id: %d]: %v", repo.ID, err)
+		}
+	}
+	return nil
+}
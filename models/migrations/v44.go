@@ -0,0 +1,26 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addRequiredStatusContextTable(x *xorm.Engine) error {
+	// RequiredStatusContext see models/status_context.go
+	type RequiredStatusContext struct {
+		ID          int64  `xorm:"pk autoincr"`
+		RepoID      int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Context     string `xorm:"VARCHAR(255) UNIQUE(s) NOT NULL"`
+		CreatedUnix int64  `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(RequiredStatusContext)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addDeployTokenTable(x *xorm.Engine) error {
+	// DeployToken see models/deploy_token.go
+	type DeployToken struct {
+		ID          int64 `xorm:"pk autoincr"`
+		RepoID      int64 `xorm:"INDEX"`
+		CreatorID   int64 `xorm:"INDEX"`
+		Name        string
+		Sha1        string `xorm:"UNIQUE VARCHAR(40)"`
+		Mode        int    `xorm:"NOT NULL DEFAULT 1"`
+		CreatedUnix int64  `xorm:"INDEX"`
+		UpdatedUnix int64  `xorm:"INDEX"`
+		ExpiresUnix int64
+	}
+
+	// RepoAccessLog see models/repo_access_log.go
+	type RepoAccessLog struct {
+		ID            int64 `xorm:"pk autoincr"`
+		DeployTokenID int64
+	}
+
+	if err := x.Sync2(new(DeployToken), new(RepoAccessLog)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addBoardTables(x *xorm.Engine) error {
+	// Board see models/board.go
+	type Board struct {
+		ID          int64  `xorm:"pk autoincr"`
+		RepoID      int64  `xorm:"INDEX"`
+		OrgID       int64  `xorm:"INDEX"`
+		Title       string `xorm:"NOT NULL"`
+		Description string `xorm:"TEXT"`
+		CreatorID   int64
+		CreatedUnix int64 `xorm:"INDEX created"`
+		UpdatedUnix int64 `xorm:"INDEX updated"`
+	}
+
+	// BoardColumn see models/board.go
+	type BoardColumn struct {
+		ID       int64  `xorm:"pk autoincr"`
+		BoardID  int64  `xorm:"INDEX NOT NULL"`
+		Title    string `xorm:"NOT NULL"`
+		Sorting  int    `xorm:"NOT NULL DEFAULT 0"`
+		IsClosed bool   `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	// BoardIssue see models/board.go
+	type BoardIssue struct {
+		ID       int64 `xorm:"pk autoincr"`
+		BoardID  int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		ColumnID int64 `xorm:"INDEX NOT NULL"`
+		IssueID  int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Sorting  int   `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(Board), new(BoardColumn), new(BoardIssue)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addIssueDeadlineReminderTable(x *xorm.Engine) error {
+	// IssueDeadlineReminder see models/issue_deadline.go
+	type IssueDeadlineReminder struct {
+		ID           int64 `xorm:"pk autoincr"`
+		IssueID      int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		UserID       int64 `xorm:"UNIQUE(s) NOT NULL"`
+		DeadlineUnix int64 `xorm:"UNIQUE(s) NOT NULL"`
+		CreatedUnix  int64 `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(IssueDeadlineReminder)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
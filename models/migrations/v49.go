@@ -0,0 +1,29 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addBannerTable(x *xorm.Engine) error {
+	// Banner see models/banner.go
+	type Banner struct {
+		ID          int64  `xorm:"pk autoincr"`
+		OwnerID     int64  `xorm:"UNIQUE"`
+		Message     string `xorm:"TEXT NOT NULL"`
+		Level       int
+		ExpiresUnix int64 `xorm:"INDEX"`
+		CreatedUnix int64
+		UpdatedUnix int64
+	}
+
+	if err := x.Sync2(new(Banner)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addProfileFieldTables(x *xorm.Engine) error {
+	// ProfileField see models/profile_field.go
+	type ProfileField struct {
+		ID          int64  `xorm:"pk autoincr"`
+		Name        string `xorm:"UNIQUE NOT NULL"`
+		Description string
+		Type        int
+		Visibility  int
+		Sorting     int
+		CreatedUnix int64
+	}
+
+	// ProfileFieldValue see models/profile_field.go
+	type ProfileFieldValue struct {
+		ID      int64  `xorm:"pk autoincr"`
+		FieldID int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		UserID  int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Value   string `xorm:"TEXT"`
+	}
+
+	if err := x.Sync2(new(ProfileField), new(ProfileFieldValue)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addServiceDeskTables(x *xorm.Engine) error {
+	// ServiceDesk see models/service_desk.go
+	type ServiceDesk struct {
+		ID          int64  `xorm:"pk autoincr"`
+		RepoID      int64  `xorm:"UNIQUE"`
+		Token       string `xorm:"UNIQUE NOT NULL"`
+		CreatedUnix int64
+	}
+
+	// ServiceDeskIssue see models/service_desk.go
+	type ServiceDeskIssue struct {
+		ID          int64 `xorm:"pk autoincr"`
+		RepoID      int64 `xorm:"INDEX"`
+		IssueID     int64 `xorm:"UNIQUE"`
+		Name        string
+		Email       string `xorm:"NOT NULL"`
+		CreatedUnix int64
+	}
+
+	if err := x.Sync2(new(ServiceDesk), new(ServiceDeskIssue)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addNotificationChannelTable(x *xorm.Engine) error {
+	// NotificationChannel see models/notification_channel.go
+	type NotificationChannel struct {
+		ID     int64 `xorm:"pk autoincr"`
+		UserID int64 `xorm:"INDEX"`
+		RepoID int64 `xorm:"INDEX"`
+		Type   int
+		URL    string `xorm:"TEXT"`
+		RoomID string
+		Token  string `xorm:"TEXT"`
+
+		EventIssues       bool
+		EventPullRequests bool
+		EventComments     bool
+		EventStatus       bool
+
+		CreatedUnix int64
+	}
+
+	if err := x.Sync2(new(NotificationChannel)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addCollaborationUnitTypes(x *xorm.Engine) error {
+	// Collaboration see models/repo_collaboration.go
+	type Collaboration struct {
+		UnitTypes []int `xorm:"json"`
+	}
+
+	if err := x.Sync2(new(Collaboration)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
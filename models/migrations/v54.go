@@ -0,0 +1,41 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+// triageAccessMode is the access mode inserted between read and write,
+// stored modes from it upwards are shifted by one.
+const triageAccessMode = 2
+
+func addTriageAccessMode(x *xorm.Engine) error {
+	columns := []struct {
+		table, column string
+	}{
+		{"access", "mode"},
+		{"collaboration", "mode"},
+		{"team", "authorize"},
+		{"public_key", "mode"},
+		{"deploy_token", "mode"},
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	for _, c := range columns {
+		if _, err := sess.Exec(fmt.Sprintf("UPDATE `%s` SET `%s` = `%s` + 1 WHERE `%s` >= ?",
+			c.table, c.column, c.column, c.column), triageAccessMode); err != nil {
+			return fmt.Errorf("update %s.%s: %v", c.table, c.column, err)
+		}
+	}
+	return sess.Commit()
+}
@@ -0,0 +1,25 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addSessionTable(x *xorm.Engine) error {
+	// Session see models/session.go
+	type Session struct {
+		Key        string `xorm:"pk CHAR(16)"`
+		Data       []byte `xorm:"BLOB"`
+		ExpiryUnix int64  `xorm:"INDEX"`
+	}
+
+	if err := x.Sync2(new(Session)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
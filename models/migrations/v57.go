@@ -0,0 +1,31 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addUploadSessionTable(x *xorm.Engine) error {
+	// UploadSession see models/upload_session.go
+	type UploadSession struct {
+		ID          int64  `xorm:"pk autoincr"`
+		UUID        string `xorm:"uuid UNIQUE"`
+		Type        int
+		UserID      int64 `xorm:"INDEX"`
+		Name        string
+		Size        int64
+		Received    int64
+		CreatedUnix int64
+		UpdatedUnix int64 `xorm:"INDEX"`
+	}
+
+	if err := x.Sync2(new(UploadSession)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addAttachmentUploaderID(x *xorm.Engine) error {
+	// Attachment see models/attachment.go
+	type Attachment struct {
+		UploaderID int64 `xorm:"INDEX DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(Attachment)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addNoticeSeverity(x *xorm.Engine) error {
+	// Notice see models/admin.go
+	type Notice struct {
+		Severity int `xorm:"NOT NULL DEFAULT 3"`
+	}
+
+	if err := x.Sync2(new(Notice)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addMirrorSyncStats(x *xorm.Engine) error {
+	// Mirror see models/repo_mirror.go
+	type Mirror struct {
+		LastSyncDuration time.Duration
+		SyncFailures     int `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(Mirror)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
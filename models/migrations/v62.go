@@ -0,0 +1,23 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addWatchMode(x *xorm.Engine) error {
+	// Watch see models/repo_watch.go
+	type Watch struct {
+		Mode int `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(Watch)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addOrgTemplates(x *xorm.Engine) error {
+	// OrgLabelTemplate see models/org_template.go
+	type OrgLabelTemplate struct {
+		ID          int64 `xorm:"pk autoincr"`
+		OrgID       int64 `xorm:"INDEX"`
+		Name        string
+		Description string `xorm:"TEXT"`
+		Color       string `xorm:"VARCHAR(7)"`
+	}
+
+	// OrgMilestoneTemplate see models/org_template.go
+	type OrgMilestoneTemplate struct {
+		ID           int64 `xorm:"pk autoincr"`
+		OrgID        int64 `xorm:"INDEX"`
+		Name         string
+		Content      string `xorm:"TEXT"`
+		DueDays      int
+		Count        int `xorm:"NOT NULL DEFAULT 1"`
+		IntervalDays int
+	}
+
+	if err := x.Sync2(new(OrgLabelTemplate), new(OrgMilestoneTemplate)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
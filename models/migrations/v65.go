@@ -0,0 +1,39 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addGitHookTemplates(x *xorm.Engine) error {
+	// GitHookTemplate see models/git_hook_template.go
+	type GitHookTemplate struct {
+		ID          int64  `xorm:"pk autoincr"`
+		Name        string `xorm:"UNIQUE NOT NULL"`
+		Description string `xorm:"TEXT"`
+		HookName    string `xorm:"NOT NULL"`
+		Content     string `xorm:"TEXT"`
+		Parameters  string
+		CreatedUnix int64
+		UpdatedUnix int64
+	}
+
+	// RepoGitHook see models/git_hook_template.go
+	type RepoGitHook struct {
+		ID          int64  `xorm:"pk autoincr"`
+		RepoID      int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		TemplateID  int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Parameters  string `xorm:"TEXT"`
+		CreatedUnix int64  `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(GitHookTemplate), new(RepoGitHook)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
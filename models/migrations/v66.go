@@ -0,0 +1,24 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addWebhookPreviousSecret(x *xorm.Engine) error {
+	// Webhook see models/webhook.go
+	type Webhook struct {
+		PreviousSecret            string `xorm:"TEXT"`
+		PreviousSecretExpiresUnix int64
+	}
+
+	if err := x.Sync2(new(Webhook)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addQueueItemTable(x *xorm.Engine) error {
+	// QueueItem see models/queue.go
+	type QueueItem struct {
+		ID          int64  `xorm:"pk autoincr"`
+		QueueName   string `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		ItemID      string `xorm:"UNIQUE(s) NOT NULL"`
+		ClaimedUnix int64  `xorm:"INDEX"`
+		CreatedUnix int64
+	}
+
+	if err := x.Sync2(new(QueueItem)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
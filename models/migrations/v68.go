@@ -0,0 +1,23 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addIssueConfidential(x *xorm.Engine) error {
+	// Issue see models/issue.go
+	type Issue struct {
+		IsConfidential bool `xorm:"INDEX NOT NULL DEFAULT false"`
+	}
+
+	if err := x.Sync2(new(Issue)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
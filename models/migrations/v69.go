@@ -0,0 +1,53 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addRepoAdvisoryTables(x *xorm.Engine) error {
+	// RepoAdvisory see models/repo_advisory.go
+	type RepoAdvisory struct {
+		ID               int64 `xorm:"pk autoincr"`
+		RepoID           int64 `xorm:"INDEX UNIQUE(s)"`
+		Index            int64 `xorm:"UNIQUE(s)"`
+		PosterID         int64
+		Title            string `xorm:"NOT NULL"`
+		Description      string `xorm:"TEXT"`
+		Severity         string
+		CVEID            string `xorm:"'cve_id'"`
+		AffectedVersions string
+		PatchedVersions  string
+		Status           int `xorm:"INDEX NOT NULL DEFAULT 0"`
+		ForkID           int64
+		CreatedUnix      int64 `xorm:"INDEX created"`
+		UpdatedUnix      int64 `xorm:"updated"`
+		PublishedUnix    int64
+	}
+
+	// RepoAdvisoryCollaborator see models/repo_advisory.go
+	type RepoAdvisoryCollaborator struct {
+		ID         int64 `xorm:"pk autoincr"`
+		AdvisoryID int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		UserID     int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	}
+
+	// RepoAdvisoryComment see models/repo_advisory.go
+	type RepoAdvisoryComment struct {
+		ID          int64 `xorm:"pk autoincr"`
+		AdvisoryID  int64 `xorm:"INDEX NOT NULL"`
+		PosterID    int64
+		Content     string `xorm:"TEXT"`
+		CreatedUnix int64  `xorm:"INDEX created"`
+	}
+
+	if err := x.Sync2(new(RepoAdvisory), new(RepoAdvisoryCollaborator), new(RepoAdvisoryComment)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addReviewsAndRequiredApprovals(x *xorm.Engine) error {
+	// Review see models/review.go
+	type Review struct {
+		ID          int64  `xorm:"pk autoincr"`
+		IssueID     int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		ReviewerID  int64  `xorm:"UNIQUE(s) NOT NULL"`
+		Type        int    `xorm:"NOT NULL"`
+		CommitID    string `xorm:"VARCHAR(40)"`
+		CreatedUnix int64  `xorm:"created"`
+		UpdatedUnix int64  `xorm:"updated"`
+	}
+
+	// ProtectedBranch see models/branches.go
+	type ProtectedBranch struct {
+		RequiredApprovals     int64 `xorm:"NOT NULL DEFAULT 0"`
+		DismissStaleApprovals bool  `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	if err := x.Sync2(new(Review), new(ProtectedBranch)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
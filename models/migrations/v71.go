@@ -0,0 +1,31 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addAbuseReportTable(x *xorm.Engine) error {
+	// AbuseReport see models/abuse_report.go
+	type AbuseReport struct {
+		ID           int64  `xorm:"pk autoincr"`
+		ReporterID   int64  `xorm:"INDEX NOT NULL"`
+		Type         int    `xorm:"INDEX(target) NOT NULL"`
+		TargetID     int64  `xorm:"INDEX(target) NOT NULL"`
+		Reason       string `xorm:"TEXT"`
+		Status       int    `xorm:"INDEX NOT NULL"`
+		ResolverID   int64
+		CreatedUnix  int64 `xorm:"INDEX created"`
+		ResolvedUnix int64
+	}
+
+	if err := x.Sync2(new(AbuseReport)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addDeletedUnixToRepositoryAndUser(x *xorm.Engine) error {
+	// Repository see models/repo.go
+	type Repository struct {
+		DeletedUnix int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	// User see models/user.go
+	type User struct {
+		DeletedUnix int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(Repository), new(User)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addUserExportTable(x *xorm.Engine) error {
+	// UserExport see models/user_export.go
+	type UserExport struct {
+		ID            int64 `xorm:"pk autoincr"`
+		UserID        int64 `xorm:"INDEX NOT NULL"`
+		Status        int   `xorm:"INDEX NOT NULL"`
+		Size          int64
+		CreatedUnix   int64 `xorm:"INDEX created"`
+		CompletedUnix int64 `xorm:"INDEX"`
+	}
+
+	if err := x.Sync2(new(UserExport)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
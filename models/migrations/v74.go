@@ -0,0 +1,27 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addEmailAddressPendingPrimary(x *xorm.Engine) error {
+	// EmailAddress see models/user_mail.go
+	type EmailAddress struct {
+		ID               int64  `xorm:"pk autoincr"`
+		UID              int64  `xorm:"INDEX NOT NULL"`
+		Email            string `xorm:"UNIQUE NOT NULL"`
+		IsActivated      bool
+		IsPendingPrimary bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	if err := x.Sync2(new(EmailAddress)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
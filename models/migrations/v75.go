@@ -0,0 +1,30 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addUserPasswdChangedUnix(x *xorm.Engine) error {
+	// User see models/user.go
+	type User struct {
+		ID                int64 `xorm:"pk autoincr"`
+		PasswdChangedUnix int64 `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(User)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	// Existing passwords expire PASSWORD_EXPIRY_DAYS after the upgrade.
+	if _, err := x.Exec("UPDATE `user` SET passwd_changed_unix = ?", time.Now().Unix()); err != nil {
+		return fmt.Errorf("set passwd_changed_unix: %v", err)
+	}
+	return nil
+}
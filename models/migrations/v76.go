@@ -0,0 +1,28 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addLoginAttemptTable(x *xorm.Engine) error {
+	// LoginAttempt see models/login_attempt.go
+	type LoginAttempt struct {
+		ID          int64  `xorm:"pk autoincr"`
+		UserID      int64  `xorm:"INDEX NOT NULL"`
+		IP          string `xorm:"VARCHAR(64) INDEX"`
+		UserAgent   string
+		IsSuccess   bool  `xorm:"NOT NULL DEFAULT false"`
+		CreatedUnix int64 `xorm:"INDEX created"`
+	}
+
+	if err := x.Sync2(new(LoginAttempt)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
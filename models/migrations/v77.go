@@ -0,0 +1,29 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addUserSessionTable(x *xorm.Engine) error {
+	// UserSession see models/user_session.go
+	type UserSession struct {
+		ID          int64  `xorm:"pk autoincr"`
+		UserID      int64  `xorm:"INDEX NOT NULL"`
+		SessionID   string `xorm:"VARCHAR(64) UNIQUE NOT NULL"`
+		IP          string `xorm:"VARCHAR(64)"`
+		UserAgent   string
+		CreatedUnix int64 `xorm:"created"`
+		UpdatedUnix int64 `xorm:"INDEX"`
+	}
+
+	if err := x.Sync2(new(UserSession)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addRenamedBranchTable(x *xorm.Engine) error {
+	// RenamedBranch see models/repo_branch.go
+	type RenamedBranch struct {
+		ID          int64  `xorm:"pk autoincr"`
+		RepoID      int64  `xorm:"INDEX NOT NULL"`
+		OldName     string `xorm:"NOT NULL"`
+		NewName     string `xorm:"NOT NULL"`
+		CreatedUnix int64  `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(RenamedBranch)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
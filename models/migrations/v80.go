@@ -0,0 +1,28 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addFederationKeyTable(x *xorm.Engine) error {
+	// FederationKey see models/federation.go
+	type FederationKey struct {
+		ID          int64  `xorm:"pk autoincr"`
+		ActorType   int    `xorm:"UNIQUE(s) NOT NULL"`
+		ActorID     int64  `xorm:"UNIQUE(s) NOT NULL"`
+		PublicKey   string `xorm:"TEXT NOT NULL"`
+		PrivateKey  string `xorm:"TEXT NOT NULL"`
+		CreatedUnix int64  `xorm:"created"`
+	}
+
+	if err := x.Sync2(new(FederationKey)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/go-xorm/xorm"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+func addAttachmentSize(x *xorm.Engine) error {
+	// Attachment see models/attachment.go
+	type Attachment struct {
+		ID   int64  `xorm:"pk autoincr"`
+		UUID string `xorm:"uuid UNIQUE"`
+		Size int64  `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(Attachment)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	// Fill in the sizes of the existing attachments from their files, by
+	// batches to not hold all of them in memory.
+	const batchSize = 100
+	for lastID := int64(0); ; {
+		attachments := make([]*Attachment, 0, batchSize)
+		if err := x.Where("id > ?", lastID).Asc("id").Limit(batchSize).Find(&attachments); err != nil {
+			return fmt.Errorf("select attachments: %v", err)
+		} else if len(attachments) == 0 {
+			return nil
+		}
+
+		for _, attach := range attachments {
+			lastID = attach.ID
+			if len(attach.UUID) < 2 {
+				continue
+			}
+			fi, err := os.Stat(path.Join(setting.AttachmentPath, attach.UUID[0:1], attach.UUID[1:2], attach.UUID))
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return fmt.Errorf("Stat: %v", err)
+			}
+			if _, err = x.Id(attach.ID).Cols("size").Update(&Attachment{Size: fi.Size()}); err != nil {
+				return fmt.Errorf("update attachment [%d]: %v", attach.ID, err)
+			}
+		}
+	}
+}
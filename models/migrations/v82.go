@@ -0,0 +1,30 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addAuditLogTable(x *xorm.Engine) error {
+	// AuditLog see models/audit_log.go
+	type AuditLog struct {
+		ID          int64  `xorm:"pk autoincr"`
+		Action      int    `xorm:"INDEX NOT NULL"`
+		DoerID      int64  `xorm:"INDEX NOT NULL"`
+		UserID      int64  `xorm:"INDEX"`
+		Method      string `xorm:"VARCHAR(16)"`
+		Path        string `xorm:"TEXT"`
+		RemoteAddr  string
+		CreatedUnix int64 `xorm:"INDEX created"`
+	}
+
+	if err := x.Sync2(new(AuditLog)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addUserSuspension(x *xorm.Engine) error {
+	// User see models/user.go
+	type User struct {
+		ID            int64  `xorm:"pk autoincr"`
+		IsSuspended   bool   `xorm:"INDEX NOT NULL DEFAULT false"`
+		SuspendReason string `xorm:"TEXT"`
+		SuspendedByID int64  `xorm:"NOT NULL DEFAULT 0"`
+		SuspendedUnix int64  `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	// LoginSource see models/login_source.go
+	type LoginSource struct {
+		ID               int64 `xorm:"pk autoincr"`
+		SyncSuspendUsers bool  `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	if err := x.Sync2(new(User), new(LoginSource)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addRepoAccessLogRefs(x *xorm.Engine) error {
+	// RepoAccessLog see models/repo_access_log.go
+	type RepoAccessLog struct {
+		ID   int64  `xorm:"pk autoincr"`
+		Refs string `xorm:"TEXT"`
+	}
+
+	if err := x.Sync2(new(RepoAccessLog)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"path"
@@ -116,9 +117,45 @@ func init() {
 		new(RepoRedirect),
 		new(ExternalLoginUser),
 		new(ProtectedBranch),
+		new(RenamedBranch),
 		new(UserOpenID),
 		new(IssueWatch),
 		new(CommitStatus),
+		new(ContributorWeek),
+		new(RepoStatsStatus),
+		new(RepoAccessLog),
+		new(BlockedWord),
+		new(ModerationItem),
+		new(LanguageStat),
+		new(RequiredStatusContext),
+		new(DeployToken),
+		new(Board),
+		new(BoardColumn),
+		new(BoardIssue),
+		new(IssueDeadlineReminder),
+		new(Banner),
+		new(ProfileField),
+		new(ProfileFieldValue),
+		new(ServiceDesk),
+		new(ServiceDeskIssue),
+		new(NotificationChannel),
+		new(Session),
+		new(UploadSession),
+		new(OrgLabelTemplate),
+		new(OrgMilestoneTemplate),
+		new(GitHookTemplate),
+		new(RepoGitHook),
+		new(QueueItem),
+		new(RepoAdvisory),
+		new(RepoAdvisoryCollaborator),
+		new(RepoAdvisoryComment),
+		new(Review),
+		new(AbuseReport),
+		new(UserExport),
+		new(LoginAttempt),
+		new(UserSession),
+		new(FederationKey),
+		new(AuditLog),
 	)
 
 	gonicNames := []string{"SSL", "UID"}
@@ -263,6 +300,9 @@ func SetEngine() (err error) {
 	return nil
 }
 
+// MigrateOptions are the options the database is migrated with by NewEngine.
+var MigrateOptions migrations.Options
+
 // NewEngine initializes a new xorm.Engine
 func NewEngine() (err error) {
 	if err = SetEngine(); err != nil {
@@ -273,7 +313,7 @@ func NewEngine() (err error) {
 		return err
 	}
 
-	if err = migrations.Migrate(x); err != nil {
+	if err = migrations.Migrate(x, MigrateOptions); err != nil {
 		return fmt.Errorf("migrate: %v", err)
 	}
 
@@ -338,3 +378,117 @@ func DumpDatabase(filePath string, dbType string) error {
 	}
 	return x.DumpTablesToFile(tbs, filePath)
 }
+
+// DumpCurrentDatabase dumps all tables of the database as they are, which
+// may differ from the models before the database is migrated.
+func DumpCurrentDatabase(filePath string, dbType string) error {
+	if len(dbType) > 0 {
+		return x.DumpAllToFile(filePath, core.DbType(dbType))
+	}
+	return x.DumpAllToFile(filePath)
+}
+
+// IsDatabaseEmpty returns true if the database has no tables, e.g. before
+// Gitea is installed.
+func IsDatabaseEmpty() (bool, error) {
+	metas, err := x.DBMetas()
+	if err != nil {
+		return false, err
+	}
+	return len(metas) == 0, nil
+}
+
+// RestoreDatabase imports a dump of the database created by DumpDatabase,
+// and sets the version of the database to the one the dump was created at,
+// so the database is migrated from there.
+func RestoreDatabase(r io.Reader, version int64) error {
+	if _, err := x.Import(r); err != nil {
+		return fmt.Errorf("Import: %v", err)
+	}
+	return migrations.SetVersion(x, version)
+}
+
+// DryRunMigrations writes the pending migrations of the database, and the
+// changes of its schema to match the models, without changing anything.
+func DryRunMigrations(w io.Writer) error {
+	if err := x.Ping(); err != nil {
+		return err
+	}
+
+	if err := migrations.Migrate(x, migrations.Options{DryRun: true, Output: w}); err != nil {
+		return fmt.Errorf("migrate: %v", err)
+	}
+
+	changes, err := SchemaChanges()
+	if err != nil {
+		return fmt.Errorf("SchemaChanges: %v", err)
+	} else if len(changes) == 0 {
+		fmt.Fprintln(w, "No schema changes")
+		return nil
+	}
+	fmt.Fprintln(w, "Schema changes, including the ones made by the pending migrations:")
+	for _, change := range changes {
+		fmt.Fprintln(w, "  "+change)
+	}
+	return nil
+}
+
+// SchemaChanges returns the tables, columns and indexes of the models which
+// are missing in the database, they are created when the database is synced.
+func SchemaChanges() ([]string, error) {
+	metas, err := x.DBMetas()
+	if err != nil {
+		return nil, err
+	}
+	dbTables := make(map[string]*core.Table, len(metas))
+	for _, table := range metas {
+		dbTables[strings.ToLower(table.Name)] = table
+	}
+
+	var changes []string
+	for _, t := range tables {
+		table := x.TableInfo(t).Table
+		dbTable, has := dbTables[strings.ToLower(table.Name)]
+		if !has {
+			changes = append(changes, fmt.Sprintf("create table %s", table.Name))
+			continue
+		}
+
+		for _, col := range table.Columns() {
+			if dbTable.GetColumn(col.Name) == nil {
+				changes = append(changes, fmt.Sprintf("add column %s.%s %s", table.Name, col.Name, col.SQLType.Name))
+			}
+		}
+		for _, index := range table.Indexes {
+			if !hasIndex(dbTable, index) {
+				kind := "index"
+				if index.Type == core.UniqueType {
+					kind = "unique index"
+				}
+				changes = append(changes, fmt.Sprintf("add %s on %s(%s)", kind, table.Name, strings.Join(index.Cols, ", ")))
+			}
+		}
+	}
+	return changes, nil
+}
+
+// hasIndex returns true if the table of the database has an index of the
+// same type on the same columns, index names differ between databases.
+func hasIndex(dbTable *core.Table, index *core.Index) bool {
+	for _, dbIndex := range dbTable.Indexes {
+		if dbIndex.Type != index.Type || len(dbIndex.Cols) != len(index.Cols) {
+			continue
+		}
+		same := true
+		for i := range index.Cols {
+			if !strings.EqualFold(dbIndex.Cols[i], index.Cols[i]) {
+				same = false
+				break
+			}
+		}
+		if same {
+			return true
+		}
+	}
+	return false
+}
@@ -53,3 +53,21 @@ func Test_parsePostgreSQLHostPort(t *testing.T) {
 		assert.Equal(t, test.Port, port)
 	}
 }
+
+func TestSchemaChanges(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	changes, err := SchemaChanges()
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	_, err = x.Exec("DROP TABLE queue_item")
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, x.Sync2(new(QueueItem)))
+	}()
+
+	changes, err = SchemaChanges()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"create table queue_item"}, changes)
+}
@@ -0,0 +1,283 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-xorm/xorm"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// BlockedWordAction represents what happens to content containing a blocked word.
+type BlockedWordAction int
+
+// Enumerate all the blocked word actions
+const (
+	BlockedWordReject   BlockedWordAction = iota + 1 // Content is rejected with a message
+	BlockedWordModerate                              // Content is held until a moderator approves it
+)
+
+// BlockedWord represents a word which is not allowed in issues and comments,
+// either instance wide (OwnerID is 0) or in repositories of an owner.
+type BlockedWord struct {
+	ID          int64  `xorm:"pk autoincr"`
+	OwnerID     int64  `xorm:"UNIQUE(s)"`
+	Word        string `xorm:"UNIQUE(s) NOT NULL"`
+	Action      BlockedWordAction
+	Created     time.Time `xorm:"-"`
+	CreatedUnix int64
+}
+
+// BeforeInsert will be invoked by XORM before inserting a record
+func (w *BlockedWord) BeforeInsert() {
+	w.CreatedUnix = time.Now().Unix()
+}
+
+// AfterSet is invoked from XORM after setting the value of a field of this object.
+func (w *BlockedWord) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		w.Created = time.Unix(w.CreatedUnix, 0).Local()
+	}
+}
+
+// IsModerate returns true if content containing the word is held for moderation.
+func (w *BlockedWord) IsModerate() bool {
+	return w.Action == BlockedWordModerate
+}
+
+// AddBlockedWord adds a new blocked word for given owner.
+func AddBlockedWord(ownerID int64, word string, action BlockedWordAction) error {
+	word = strings.ToLower(strings.TrimSpace(word))
+	if action != BlockedWordModerate {
+		action = BlockedWordReject
+	}
+
+	has, err := x.
+		Where("owner_id = ? AND word = ?", ownerID, word).
+		Get(new(BlockedWord))
+	if err != nil {
+		return err
+	} else if has {
+		return ErrBlockedWordAlreadyExist{word}
+	}
+
+	_, err = x.Insert(&BlockedWord{
+		OwnerID: ownerID,
+		Word:    word,
+		Action:  action,
+	})
+	return err
+}
+
+// GetBlockedWords returns all blocked words of given owner.
+func GetBlockedWords(ownerID int64) ([]*BlockedWord, error) {
+	words := make([]*BlockedWord, 0, 10)
+	return words, x.
+		Where("owner_id = ?", ownerID).
+		Asc("word").
+		Find(&words)
+}
+
+// DeleteBlockedWord deletes a blocked word of given owner.
+func DeleteBlockedWord(ownerID, id int64) error {
+	_, err := x.
+		Where("id = ? AND owner_id = ?", id, ownerID).
+		Delete(new(BlockedWord))
+	return err
+}
+
+// matchBlockedWords returns the first word contained in any of given texts,
+// rejecting words take precedence over words which require moderation.
+func matchBlockedWords(words []*BlockedWord, texts ...string) *BlockedWord {
+	for i := range texts {
+		texts[i] = strings.ToLower(texts[i])
+	}
+
+	var held *BlockedWord
+	for _, w := range words {
+		for _, text := range texts {
+			if !strings.Contains(text, w.Word) {
+				continue
+			}
+			if !w.IsModerate() {
+				return w
+			} else if held == nil {
+				held = w
+			}
+		}
+	}
+	return held
+}
+
+// checkContent makes sure the content posted by doer to the repository
+// does not contain blocked words. Content with words that require moderation
+// is saved to the moderation queue and ErrContentHeldForModeration is returned.
+func checkContent(repo *Repository, issueID int64, doer *User, title, content string) error {
+	if doer.IsAdmin {
+		return nil
+	}
+
+	words := make([]*BlockedWord, 0, 10)
+	if err := x.
+		In("owner_id", []int64{0, repo.OwnerID}).
+		Find(&words); err != nil {
+		return fmt.Errorf("find blocked words: %v", err)
+	}
+
+	w := matchBlockedWords(words, title, content)
+	if w == nil {
+		return nil
+	} else if !w.IsModerate() {
+		return ErrContentBlocked{w.Word}
+	}
+
+	item := &ModerationItem{
+		OwnerID:  repo.OwnerID,
+		RepoID:   repo.ID,
+		IssueID:  issueID,
+		PosterID: doer.ID,
+		Title:    title,
+		Content:  content,
+		Word:     w.Word,
+	}
+	if _, err := x.Insert(item); err != nil {
+		return fmt.Errorf("insert moderation item: %v", err)
+	}
+	return ErrContentHeldForModeration{item.ID}
+}
+
+// ModerationItem represents an issue or a comment which is held for
+// moderation because it contains a blocked word.
+type ModerationItem struct {
+	ID          int64       `xorm:"pk autoincr"`
+	OwnerID     int64       `xorm:"INDEX"`
+	RepoID      int64       `xorm:"INDEX"`
+	Repo        *Repository `xorm:"-"`
+	IssueID     int64
+	Issue       *Issue `xorm:"-"`
+	PosterID    int64
+	Poster      *User `xorm:"-"`
+	Title       string
+	Content     string `xorm:"TEXT"`
+	Word        string
+	Created     time.Time `xorm:"-"`
+	CreatedUnix int64     `xorm:"INDEX"`
+}
+
+// BeforeInsert will be invoked by XORM before inserting a record
+func (item *ModerationItem) BeforeInsert() {
+	item.CreatedUnix = time.Now().Unix()
+}
+
+// AfterSet is invoked from XORM after setting the value of a field of this object.
+func (item *ModerationItem) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		item.Created = time.Unix(item.CreatedUnix, 0).Local()
+	}
+}
+
+// IsComment returns true if the item is a comment to an existing issue.
+func (item *ModerationItem) IsComment() bool {
+	return item.IssueID > 0
+}
+
+func (item *ModerationItem) loadAttributes(e Engine) (err error) {
+	if item.Repo == nil {
+		if item.Repo, err = getRepositoryByID(e, item.RepoID); err != nil {
+			return fmt.Errorf("getRepositoryByID [%d]: %v", item.RepoID, err)
+		}
+	}
+	if item.Poster == nil {
+		if item.Poster, err = getUserByID(e, item.PosterID); err != nil {
+			if !IsErrUserNotExist(err) {
+				return fmt.Errorf("getUserByID [%d]: %v", item.PosterID, err)
+			}
+			item.Poster = NewGhostUser()
+		}
+	}
+	if item.IsComment() && item.Issue == nil {
+		if item.Issue, err = getIssueByID(e, item.IssueID); err != nil {
+			return fmt.Errorf("getIssueByID [%d]: %v", item.IssueID, err)
+		}
+	}
+	return nil
+}
+
+// GetModerationItems returns items held for moderation in repositories of
+// given owner, or all items if ownerID is 0.
+func GetModerationItems(ownerID int64) ([]*ModerationItem, error) {
+	sess := x.Desc("created_unix")
+	if ownerID > 0 {
+		sess.Where("owner_id = ?", ownerID)
+	}
+
+	items := make([]*ModerationItem, 0, 10)
+	if err := sess.Find(&items); err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if err := item.loadAttributes(x); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// GetModerationItem returns the item held for moderation with given ID in
+// repositories of given owner, any owner is allowed if ownerID is 0.
+func GetModerationItem(ownerID, id int64) (*ModerationItem, error) {
+	item := &ModerationItem{ID: id, OwnerID: ownerID}
+	has, err := x.Get(item)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrModerationItemNotExist{id}
+	}
+	return item, item.loadAttributes(x)
+}
+
+// Approve publishes the held issue or comment and removes it from the
+// moderation queue.
+func (item *ModerationItem) Approve() (err error) {
+	if item.IsComment() {
+		if _, err = CreateComment(&CreateCommentOptions{
+			Type:    CommentTypeComment,
+			Doer:    item.Poster,
+			Repo:    item.Repo,
+			Issue:   item.Issue,
+			Content: item.Content,
+		}); err != nil {
+			return fmt.Errorf("CreateComment: %v", err)
+		}
+	} else {
+		issue := &Issue{
+			RepoID:   item.RepoID,
+			Title:    item.Title,
+			PosterID: item.PosterID,
+			Poster:   item.Poster,
+			Content:  item.Content,
+		}
+		if err = createIssue(item.Repo, issue, nil, nil); err != nil {
+			return fmt.Errorf("createIssue: %v", err)
+		}
+	}
+
+	if _, err = x.Id(item.ID).Delete(new(ModerationItem)); err != nil {
+		log.Error(4, "delete moderation item [%d]: %v", item.ID, err)
+	}
+	return nil
+}
+
+// Reject discards the held issue or comment.
+func (item *ModerationItem) Reject() error {
+	_, err := x.Id(item.ID).Delete(new(ModerationItem))
+	return err
+}
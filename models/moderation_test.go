@@ -0,0 +1,72 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchBlockedWords(t *testing.T) {
+	words := []*BlockedWord{
+		{Word: "spam", Action: BlockedWordModerate},
+		{Word: "scam", Action: BlockedWordReject},
+	}
+
+	assert.Nil(t, matchBlockedWords(words, "title", "nothing to see"))
+	assert.Equal(t, "spam", matchBlockedWords(words, "Buy SPAM now", "").Word)
+	assert.Equal(t, "scam", matchBlockedWords(words, "spam", "this is a scam").Word)
+}
+
+func TestAddBlockedWord(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, AddBlockedWord(3, " Spam ", BlockedWordModerate))
+	AssertExistsAndLoadBean(t, &BlockedWord{OwnerID: 3, Word: "spam", Action: BlockedWordModerate})
+
+	err := AddBlockedWord(3, "spam", BlockedWordReject)
+	assert.True(t, IsErrBlockedWordAlreadyExist(err))
+	assert.NoError(t, AddBlockedWord(0, "spam", BlockedWordReject))
+
+	words, err := GetBlockedWords(3)
+	assert.NoError(t, err)
+	if assert.Len(t, words, 1) {
+		assert.NoError(t, DeleteBlockedWord(3, words[0].ID))
+	}
+	AssertNotExistsBean(t, &BlockedWord{OwnerID: 3})
+}
+
+func TestCreateIssueComment_BlockedWords(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	assert.NoError(t, AddBlockedWord(0, "scam", BlockedWordReject))
+	assert.NoError(t, AddBlockedWord(2, "spam", BlockedWordModerate))
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+
+	_, err := CreateIssueComment(doer, repo, issue, "not a scam", nil)
+	assert.True(t, IsErrContentBlocked(err))
+	AssertNotExistsBean(t, &Comment{IssueID: issue.ID, Content: "not a scam"})
+
+	_, err = CreateIssueComment(doer, repo, issue, "some spam", nil)
+	assert.True(t, IsErrContentHeldForModeration(err))
+	AssertNotExistsBean(t, &Comment{IssueID: issue.ID, Content: "some spam"})
+
+	items, err := GetModerationItems(2)
+	assert.NoError(t, err)
+	if assert.Len(t, items, 1) {
+		assert.True(t, items[0].IsComment())
+		assert.Equal(t, "spam", items[0].Word)
+		assert.NoError(t, items[0].Approve())
+	}
+	AssertExistsAndLoadBean(t, &Comment{IssueID: issue.ID, PosterID: doer.ID, Content: "some spam"})
+	AssertNotExistsBean(t, &ModerationItem{RepoID: repo.ID})
+
+	admin := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	_, err = CreateIssueComment(admin, repo, issue, "admins may say scam", nil)
+	assert.NoError(t, err)
+}
@@ -96,38 +96,56 @@ func CreateOrUpdateIssueNotifications(issue *Issue, notificationAuthorID int64)
 }
 
 func createOrUpdateIssueNotifications(e Engine, issue *Issue, notificationAuthorID int64) error {
-	issueWatches, err := getIssueWatchers(e, issue.ID)
+	userIDs, err := getIssueNotificationRecipients(e, issue, notificationAuthorID)
 	if err != nil {
 		return err
 	}
 
-	watches, err := getWatchers(e, issue.RepoID)
+	notifications, err := getNotificationsByIssueID(e, issue.ID)
 	if err != nil {
 		return err
 	}
 
-	notifications, err := getNotificationsByIssueID(e, issue.ID)
+	for _, userID := range userIDs {
+		if notificationExists(notifications, issue.ID, userID) {
+			err = updateIssueNotification(e, userID, issue.ID, notificationAuthorID)
+		} else {
+			err = createIssueNotification(e, userID, issue, notificationAuthorID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getIssueNotificationRecipients returns the IDs of users who watch the issue
+// or its repository, except the author of the activity being notified.
+func getIssueNotificationRecipients(e Engine, issue *Issue, notificationAuthorID int64) ([]int64, error) {
+	issueWatches, err := getIssueWatchers(e, issue.ID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	watches, err := getWatchers(e, issue.RepoID)
+	if err != nil {
+		return nil, err
 	}
 
 	alreadyNotified := make(map[int64]struct{}, len(issueWatches)+len(watches))
+	userIDs := make([]int64, 0, len(issueWatches)+len(watches))
 
-	notifyUser := func(userID int64) error {
+	notifyUser := func(userID int64) {
 		// do not send notification for the own issuer/commenter
 		if userID == notificationAuthorID {
-			return nil
+			return
 		}
 
 		if _, ok := alreadyNotified[userID]; ok {
-			return nil
+			return
 		}
 		alreadyNotified[userID] = struct{}{}
-
-		if notificationExists(notifications, issue.ID, userID) {
-			return updateIssueNotification(e, userID, issue.ID, notificationAuthorID)
-		}
-		return createIssueNotification(e, userID, issue, notificationAuthorID)
+		userIDs = append(userIDs, userID)
 	}
 
 	for _, issueWatch := range issueWatches {
@@ -137,17 +155,16 @@ func createOrUpdateIssueNotifications(e Engine, issue *Issue, notificationAuthor
 			continue
 		}
 
-		if err := notifyUser(issueWatch.UserID); err != nil {
-			return err
-		}
+		notifyUser(issueWatch.UserID)
 	}
 
 	for _, watch := range watches {
-		if err := notifyUser(watch.UserID); err != nil {
-			return err
+		if !watch.AcceptsIssues() {
+			continue
 		}
+		notifyUser(watch.UserID)
 	}
-	return nil
+	return issue.visibleUserIDs(e, userIDs)
 }
 
 func getNotificationsByIssueID(e Engine, issueID int64) (notifications []*Notification, err error) {
@@ -0,0 +1,196 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-xorm/xorm"
+)
+
+// NotificationChannelType represents the chat service a notification channel delivers to.
+type NotificationChannelType int
+
+// Enumerate all the notification channel types
+const (
+	NotificationChannelMatrix NotificationChannelType = iota + 1
+	NotificationChannelRocketChat
+)
+
+var notificationChannelTypeNames = map[NotificationChannelType]string{
+	NotificationChannelMatrix:     "matrix",
+	NotificationChannelRocketChat: "rocketchat",
+}
+
+// String returns the name of the notification channel type.
+func (t NotificationChannelType) String() string {
+	return notificationChannelTypeNames[t]
+}
+
+// ToNotificationChannelType returns the notification channel type with given name,
+// or 0 if there is no such type.
+func ToNotificationChannelType(name string) NotificationChannelType {
+	for t, typeName := range notificationChannelTypeNames {
+		if typeName == name {
+			return t
+		}
+	}
+	return 0
+}
+
+// NotificationEvent represents the kind of activity on an issue or a pull request
+// notifications are sent for.
+type NotificationEvent int
+
+// Enumerate all the notification events
+const (
+	NotificationEventIssue       NotificationEvent = iota + 1 // A new issue
+	NotificationEventPullRequest                              // A new pull request
+	NotificationEventComment                                  // A new comment
+	NotificationEventStatus                                   // Closed, reopened or merged
+)
+
+// NotificationChannel represents a chat integration of a user or a repository
+// notifications about issues and pull requests are delivered to.
+type NotificationChannel struct {
+	ID     int64 `xorm:"pk autoincr"`
+	UserID int64 `xorm:"INDEX"` // Zero for channels of repositories
+	RepoID int64 `xorm:"INDEX"` // Zero for channels of users
+	Type   NotificationChannelType
+	URL    string `xorm:"TEXT"` // Homeserver of Matrix or incoming webhook of Rocket.Chat
+	RoomID string // Matrix only
+	Token  string `xorm:"TEXT"` // Matrix only
+
+	EventIssues       bool
+	EventPullRequests bool
+	EventComments     bool
+	EventStatus       bool
+
+	Created     time.Time `xorm:"-"`
+	CreatedUnix int64
+}
+
+// BeforeInsert will be invoked by XORM before inserting a record
+func (c *NotificationChannel) BeforeInsert() {
+	c.CreatedUnix = time.Now().Unix()
+}
+
+// AfterSet is invoked from XORM after setting the value of a field of this object.
+func (c *NotificationChannel) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		c.Created = time.Unix(c.CreatedUnix, 0).Local()
+	}
+}
+
+// HasEvent returns true if the channel accepts notifications for given event.
+func (c *NotificationChannel) HasEvent(event NotificationEvent) bool {
+	switch event {
+	case NotificationEventIssue:
+		return c.EventIssues
+	case NotificationEventPullRequest:
+		return c.EventPullRequests
+	case NotificationEventComment:
+		return c.EventComments
+	case NotificationEventStatus:
+		return c.EventStatus
+	}
+	return false
+}
+
+// Destination returns a short description of where the channel delivers to.
+func (c *NotificationChannel) Destination() string {
+	if c.Type == NotificationChannelMatrix {
+		return c.RoomID
+	}
+	return c.URL
+}
+
+// NewNotificationChannel creates a new notification channel of a user or a repository.
+func NewNotificationChannel(c *NotificationChannel) error {
+	if _, ok := notificationChannelTypeNames[c.Type]; !ok {
+		return fmt.Errorf("unknown notification channel type: %d", c.Type)
+	}
+	c.URL = strings.TrimRight(strings.TrimSpace(c.URL), "/")
+	c.RoomID = strings.TrimSpace(c.RoomID)
+	c.Token = strings.TrimSpace(c.Token)
+	_, err := x.Insert(c)
+	return err
+}
+
+// GetNotificationChannelsByUserID returns the notification channels of a user.
+func GetNotificationChannelsByUserID(userID int64) ([]*NotificationChannel, error) {
+	channels := make([]*NotificationChannel, 0, 2)
+	return channels, x.
+		Where("user_id = ?", userID).
+		Asc("id").
+		Find(&channels)
+}
+
+// GetNotificationChannelsByRepoID returns the notification channels of a repository.
+func GetNotificationChannelsByRepoID(repoID int64) ([]*NotificationChannel, error) {
+	channels := make([]*NotificationChannel, 0, 2)
+	return channels, x.
+		Where("repo_id = ?", repoID).
+		Asc("id").
+		Find(&channels)
+}
+
+// DeleteNotificationChannel deletes the notification channel with given ID
+// which belongs to given user or repository.
+func DeleteNotificationChannel(userID, repoID, id int64) error {
+	_, err := x.
+		Where("id = ?", id).
+		And("user_id = ?", userID).
+		And("repo_id = ?", repoID).
+		Delete(new(NotificationChannel))
+	return err
+}
+
+// GetIssueNotificationChannels returns the channels accepting given event on
+// the issue: those of its repository and those of users who are notified of it.
+// The channels of the repository are not used for confidential issues, since
+// anyone may read them, like webhooks are not.
+func GetIssueNotificationChannels(issue *Issue, notificationAuthorID int64, event NotificationEvent) ([]*NotificationChannel, error) {
+	userIDs, err := getIssueNotificationRecipients(x, issue, notificationAuthorID)
+	if err != nil {
+		return nil, err
+	}
+
+	var conds []string
+	var args []interface{}
+	if !issue.IsConfidential {
+		conds = append(conds, "repo_id = ?")
+		args = append(args, issue.RepoID)
+	}
+	if len(userIDs) > 0 {
+		conds = append(conds, "user_id IN ("+strings.Repeat("?,", len(userIDs)-1)+"?)")
+		for _, userID := range userIDs {
+			args = append(args, userID)
+		}
+	}
+	if len(conds) == 0 {
+		return nil, nil
+	}
+	cond := strings.Join(conds, " OR ")
+
+	channels := make([]*NotificationChannel, 0, 5)
+	if err = x.
+		Where(cond, args...).
+		Asc("id").
+		Find(&channels); err != nil {
+		return nil, err
+	}
+
+	filtered := channels[:0]
+	for _, c := range channels {
+		if c.HasEvent(event) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered, nil
+}
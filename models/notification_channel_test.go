@@ -0,0 +1,80 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewNotificationChannel(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	c := &NotificationChannel{
+		RepoID:      2,
+		Type:        NotificationChannelRocketChat,
+		URL:         " https://chat.example.com/hooks/def/ ",
+		EventIssues: true,
+	}
+	assert.NoError(t, NewNotificationChannel(c))
+	AssertExistsAndLoadBean(t, &NotificationChannel{ID: c.ID, URL: "https://chat.example.com/hooks/def"})
+
+	assert.Error(t, NewNotificationChannel(&NotificationChannel{RepoID: 2, Type: 42}))
+}
+
+func TestGetIssueNotificationChannels(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+
+	channelIDs := func(doerID int64, event NotificationEvent) []int64 {
+		channels, err := GetIssueNotificationChannels(issue, doerID, event)
+		assert.NoError(t, err)
+		ids := make([]int64, len(channels))
+		for i, c := range channels {
+			ids[i] = c.ID
+		}
+		return ids
+	}
+
+	// Channel of user 2 is not used since user 2 does not watch the issue.
+	assert.Equal(t, []int64{1, 2}, channelIDs(1, NotificationEventComment))
+	assert.Equal(t, []int64{1}, channelIDs(4, NotificationEventComment))
+	assert.Equal(t, []int64{1}, channelIDs(1, NotificationEventIssue))
+	assert.Equal(t, []int64{2}, channelIDs(1, NotificationEventStatus))
+}
+
+func TestGetIssueNotificationChannels_Confidential(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.NoError(t, issue.ChangeConfidential(true))
+
+	// The channel of the repository is not used, the one of user 4 neither
+	// since user 4 can't see the issue.
+	channels, err := GetIssueNotificationChannels(issue, 1, NotificationEventComment)
+	assert.NoError(t, err)
+	assert.Empty(t, channels)
+	channels, err = GetIssueNotificationChannels(issue, 1, NotificationEventIssue)
+	assert.NoError(t, err)
+	assert.Empty(t, channels)
+
+	// The channels of the users who can see it still are.
+	assert.NoError(t, CreateOrUpdateIssueWatch(2, issue.ID, true))
+	channels, err = GetIssueNotificationChannels(issue, 1, NotificationEventIssue)
+	assert.NoError(t, err)
+	if assert.Len(t, channels, 1) {
+		assert.EqualValues(t, 3, channels[0].ID)
+	}
+}
+
+func TestDeleteNotificationChannel(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// Channels of other owners are not deleted.
+	assert.NoError(t, DeleteNotificationChannel(0, 2, 1))
+	AssertExistsAndLoadBean(t, &NotificationChannel{ID: 1})
+
+	assert.NoError(t, DeleteNotificationChannel(4, 0, 2))
+	AssertNotExistsBean(t, &NotificationChannel{ID: 2})
+}
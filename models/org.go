@@ -13,6 +13,8 @@ import (
 	"github.com/Unknwon/com"
 	"github.com/go-xorm/builder"
 	"github.com/go-xorm/xorm"
+
+	"code.gitea.io/gitea/modules/avatar"
 )
 
 var (
@@ -261,10 +263,18 @@ func deleteOrg(e *xorm.Session, u *User) error {
 		&Team{OrgID: u.ID},
 		&OrgUser{OrgID: u.ID},
 		&TeamUser{OrgID: u.ID},
+		&BlockedWord{OwnerID: u.ID},
+		&Banner{OwnerID: u.ID},
+		&OrgLabelTemplate{OrgID: u.ID},
+		&OrgMilestoneTemplate{OrgID: u.ID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
 
+	if err = deleteOrgBoards(e, u.ID); err != nil {
+		return fmt.Errorf("deleteOrgBoards: %v", err)
+	}
+
 	if _, err = e.Id(u.ID).Delete(new(User)); err != nil {
 		return fmt.Errorf("Delete: %v", err)
 	}
@@ -281,7 +291,7 @@ func deleteOrg(e *xorm.Session, u *User) error {
 	if len(u.Avatar) > 0 {
 		avatarPath := u.CustomAvatarPath()
 		if com.IsExist(avatarPath) {
-			if err := os.Remove(avatarPath); err != nil {
+			if err := avatar.Remove(avatarPath); err != nil {
 				return fmt.Errorf("Failed to remove %s: %v", avatarPath, err)
 			}
 		}
@@ -623,7 +633,7 @@ func (env *accessibleReposEnv) cond() builder.Cond {
 	if len(env.teamIDs) > 0 {
 		cond = cond.Or(builder.In("team_repo.team_id", env.teamIDs))
 	}
-	return cond
+	return cond.And(builder.Eq{"`repository`.deleted_unix": 0})
 }
 
 func (env *accessibleReposEnv) CountRepos() (int64, error) {
@@ -0,0 +1,128 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"github.com/go-xorm/xorm"
+)
+
+// OrgMetrics represents the issue and pull request activity across all
+// repositories of an organization between Since and Until.
+type OrgMetrics struct {
+	Since        int64
+	Until        int64
+	IssuesOpened int64
+	IssuesClosed int64
+	PullsOpened  int64
+	PullsMerged  int64
+	// MergeLeadTime is the average number of seconds between opening and
+	// merging of the pull requests merged within the window.
+	MergeLeadTime int64
+	PullsReviewed int64
+	// ReviewTurnaround is the average number of seconds between opening of a
+	// pull request and the first comment by someone other than its poster.
+	ReviewTurnaround int64
+}
+
+// IssueCloseRate returns the closed issues per opened issue in percent.
+func (m *OrgMetrics) IssueCloseRate() int64 {
+	if m.IssuesOpened == 0 {
+		return 0
+	}
+	return m.IssuesClosed * 100 / m.IssuesOpened
+}
+
+func ownerIssues(e Engine, ownerID int64, isPull bool) *xorm.Session {
+	return e.Join("INNER", "repository", "repository.id = issue.repo_id").
+		Where("repository.owner_id = ?", ownerID).
+		And("issue.is_pull = ?", isPull)
+}
+
+func countOwnerIssuesOpened(e Engine, ownerID int64, isPull bool, since, until int64) (int64, error) {
+	return ownerIssues(e, ownerID, isPull).
+		And("issue.created_unix >= ? AND issue.created_unix < ?", since, until).
+		Count(new(Issue))
+}
+
+func countOwnerIssuesClosed(e Engine, ownerID int64, since, until int64) (int64, error) {
+	return e.Join("INNER", "issue", "issue.id = comment.issue_id").
+		Join("INNER", "repository", "repository.id = issue.repo_id").
+		Where("repository.owner_id = ?", ownerID).
+		And("issue.is_pull = ?", false).
+		And("comment.type = ?", CommentTypeClose).
+		And("comment.created_unix >= ? AND comment.created_unix < ?", since, until).
+		Count(new(Comment))
+}
+
+func ownerMergedPulls(e Engine, ownerID int64, since, until int64) *xorm.Session {
+	return e.Join("INNER", "issue", "issue.id = pull_request.issue_id").
+		Join("INNER", "repository", "repository.id = pull_request.base_repo_id").
+		Where("repository.owner_id = ?", ownerID).
+		And("pull_request.has_merged = ?", true).
+		And("pull_request.merged_unix >= ? AND pull_request.merged_unix < ?", since, until)
+}
+
+// pullFirstReview represents the opening time of a pull request and the
+// time of the first comment by someone other than its poster.
+type pullFirstReview struct {
+	IssueID     int64
+	CreatedUnix int64
+	ReviewUnix  int64
+}
+
+func getOwnerPullFirstReviews(e Engine, ownerID int64, since, until int64) ([]*pullFirstReview, error) {
+	reviews := make([]*pullFirstReview, 0, 10)
+	return reviews, e.Table("issue").
+		Select("issue.id AS issue_id, issue.created_unix, MIN(comment.created_unix) AS review_unix").
+		Join("INNER", "repository", "repository.id = issue.repo_id").
+		Join("INNER", "comment", "comment.issue_id = issue.id AND comment.poster_id <> issue.poster_id AND comment.type = ?", CommentTypeComment).
+		Where("repository.owner_id = ?", ownerID).
+		And("issue.is_pull = ?", true).
+		And("issue.created_unix >= ? AND issue.created_unix < ?", since, until).
+		GroupBy("issue.id, issue.created_unix").
+		Find(&reviews)
+}
+
+// GetMetrics returns the issue and pull request activity across all
+// repositories of the organization within given time window.
+func (org *User) GetMetrics(since, until int64) (_ *OrgMetrics, err error) {
+	m := &OrgMetrics{
+		Since: since,
+		Until: until,
+	}
+
+	if m.IssuesOpened, err = countOwnerIssuesOpened(x, org.ID, false, since, until); err != nil {
+		return nil, err
+	} else if m.IssuesClosed, err = countOwnerIssuesClosed(x, org.ID, since, until); err != nil {
+		return nil, err
+	} else if m.PullsOpened, err = countOwnerIssuesOpened(x, org.ID, true, since, until); err != nil {
+		return nil, err
+	} else if m.PullsMerged, err = ownerMergedPulls(x, org.ID, since, until).Count(new(PullRequest)); err != nil {
+		return nil, err
+	}
+
+	if m.PullsMerged > 0 {
+		leadTime, err := ownerMergedPulls(x, org.ID, since, until).
+			Sum(new(PullRequest), "pull_request.merged_unix - issue.created_unix")
+		if err != nil {
+			return nil, err
+		}
+		m.MergeLeadTime = int64(leadTime) / m.PullsMerged
+	}
+
+	reviews, err := getOwnerPullFirstReviews(x, org.ID, since, until)
+	if err != nil {
+		return nil, err
+	}
+	var turnaround int64
+	for _, r := range reviews {
+		turnaround += r.ReviewUnix - r.CreatedUnix
+	}
+	m.PullsReviewed = int64(len(reviews))
+	if m.PullsReviewed > 0 {
+		m.ReviewTurnaround = turnaround / m.PullsReviewed
+	}
+	return m, nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUser_GetMetrics(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	owner := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	metrics, err := owner.GetMetrics(946684800, 946684900)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, metrics.IssuesOpened)
+	assert.EqualValues(t, 0, metrics.IssuesClosed)
+	assert.EqualValues(t, 2, metrics.PullsOpened)
+	assert.EqualValues(t, 1, metrics.PullsMerged)
+	assert.EqualValues(t, 20, metrics.MergeLeadTime)
+	assert.EqualValues(t, 1, metrics.PullsReviewed)
+	assert.EqualValues(t, 40, metrics.ReviewTurnaround)
+
+	metrics, err = owner.GetMetrics(946684805, 946684900)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, metrics.IssuesOpened)
+	assert.EqualValues(t, 2, metrics.PullsOpened)
+
+	org := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
+	metrics, err = org.GetMetrics(946684800, 946684900)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, metrics.PullsOpened)
+	assert.EqualValues(t, 0, metrics.ReviewTurnaround)
+}
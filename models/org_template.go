@@ -0,0 +1,155 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// OrgLabelTemplate is a label created in every new repository of an organization.
+type OrgLabelTemplate struct {
+	ID          int64 `xorm:"pk autoincr"`
+	OrgID       int64 `xorm:"INDEX"`
+	Name        string
+	Description string `xorm:"TEXT"`
+	Color       string `xorm:"VARCHAR(7)"`
+}
+
+// NewOrgLabelTemplate adds a default label to the organization.
+func NewOrgLabelTemplate(t *OrgLabelTemplate) error {
+	_, err := x.Insert(t)
+	return err
+}
+
+// GetOrgLabelTemplates returns the default labels of the organization.
+func GetOrgLabelTemplates(orgID int64) ([]*OrgLabelTemplate, error) {
+	templates := make([]*OrgLabelTemplate, 0, 10)
+	return templates, x.
+		Where("org_id = ?", orgID).
+		Asc("name").
+		Find(&templates)
+}
+
+// DeleteOrgLabelTemplate deletes a default label of the organization.
+func DeleteOrgLabelTemplate(orgID, id int64) error {
+	_, err := x.
+		Where("id = ? AND org_id = ?", id, orgID).
+		Delete(new(OrgLabelTemplate))
+	return err
+}
+
+// OrgMilestoneTemplate is a milestone created in every new repository of an
+// organization. A recurring template creates Count milestones numbered after
+// the name, every IntervalDays days.
+type OrgMilestoneTemplate struct {
+	ID           int64 `xorm:"pk autoincr"`
+	OrgID        int64 `xorm:"INDEX"`
+	Name         string
+	Content      string `xorm:"TEXT"`
+	DueDays      int    // Days from the creation of the repository to the first deadline, 0 for none.
+	Count        int    `xorm:"NOT NULL DEFAULT 1"`
+	IntervalDays int
+}
+
+// IsRecurring returns true if the template creates more than one milestone.
+func (t *OrgMilestoneTemplate) IsRecurring() bool {
+	return t.Count > 1
+}
+
+// milestones returns the milestones created in a repository from the template
+// at given time.
+func (t *OrgMilestoneTemplate) milestones(repoID int64, now time.Time) []*Milestone {
+	count := t.Count
+	if count < 1 {
+		count = 1
+	}
+
+	milestones := make([]*Milestone, count)
+	for i := 0; i < count; i++ {
+		m := &Milestone{
+			RepoID:  repoID,
+			Name:    t.Name,
+			Content: t.Content,
+		}
+		if t.IsRecurring() {
+			m.Name = fmt.Sprintf("%s %d", t.Name, i+1)
+		}
+		if t.DueDays > 0 {
+			m.Deadline = now.AddDate(0, 0, t.DueDays+i*t.IntervalDays)
+		} else {
+			m.Deadline, _ = time.ParseInLocation("2006-01-02", "9999-12-31", time.Local)
+		}
+		milestones[i] = m
+	}
+	return milestones
+}
+
+// NewOrgMilestoneTemplate adds a default milestone to the organization.
+func NewOrgMilestoneTemplate(t *OrgMilestoneTemplate) error {
+	if t.Count < 1 {
+		t.Count = 1
+	}
+	_, err := x.Insert(t)
+	return err
+}
+
+// GetOrgMilestoneTemplates returns the default milestones of the organization.
+func GetOrgMilestoneTemplates(orgID int64) ([]*OrgMilestoneTemplate, error) {
+	templates := make([]*OrgMilestoneTemplate, 0, 5)
+	return templates, x.
+		Where("org_id = ?", orgID).
+		Asc("id").
+		Find(&templates)
+}
+
+// DeleteOrgMilestoneTemplate deletes a default milestone of the organization.
+func DeleteOrgMilestoneTemplate(orgID, id int64) error {
+	_, err := x.
+		Where("id = ? AND org_id = ?", id, orgID).
+		Delete(new(OrgMilestoneTemplate))
+	return err
+}
+
+// applyOrgTemplates creates the default labels and milestones of the
+// organization in its new repository.
+func applyOrgTemplates(e Engine, orgID int64, repo *Repository) error {
+	labelTemplates := make([]*OrgLabelTemplate, 0, 10)
+	if err := e.Where("org_id = ?", orgID).Find(&labelTemplates); err != nil {
+		return fmt.Errorf("find label templates: %v", err)
+	}
+	for _, t := range labelTemplates {
+		if _, err := e.Insert(&Label{
+			RepoID:      repo.ID,
+			Name:        t.Name,
+			Description: t.Description,
+			Color:       t.Color,
+		}); err != nil {
+			return fmt.Errorf("insert label: %v", err)
+		}
+	}
+
+	milestoneTemplates := make([]*OrgMilestoneTemplate, 0, 5)
+	if err := e.Where("org_id = ?", orgID).Asc("id").Find(&milestoneTemplates); err != nil {
+		return fmt.Errorf("find milestone templates: %v", err)
+	}
+	now := time.Now()
+	numMilestones := 0
+	for _, t := range milestoneTemplates {
+		for _, m := range t.milestones(repo.ID, now) {
+			if _, err := e.Insert(m); err != nil {
+				return fmt.Errorf("insert milestone: %v", err)
+			}
+			numMilestones++
+		}
+	}
+	if numMilestones > 0 {
+		repo.NumMilestones += numMilestones
+		if _, err := e.Exec("UPDATE `repository` SET num_milestones = num_milestones + ? WHERE id = ?", numMilestones, repo.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
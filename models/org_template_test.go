@@ -0,0 +1,69 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrgLabelTemplates(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	tpl := &OrgLabelTemplate{OrgID: 3, Name: "kind/bug", Color: "#ee0701"}
+	assert.NoError(t, NewOrgLabelTemplate(tpl))
+	assert.NoError(t, NewOrgLabelTemplate(&OrgLabelTemplate{OrgID: 6, Name: "other", Color: "#000000"}))
+
+	templates, err := GetOrgLabelTemplates(3)
+	assert.NoError(t, err)
+	if assert.Len(t, templates, 1) {
+		assert.Equal(t, "kind/bug", templates[0].Name)
+	}
+
+	// Templates of another organization can't be deleted
+	assert.NoError(t, DeleteOrgLabelTemplate(6, tpl.ID))
+	AssertExistsAndLoadBean(t, &OrgLabelTemplate{ID: tpl.ID})
+
+	assert.NoError(t, DeleteOrgLabelTemplate(3, tpl.ID))
+	AssertNotExistsBean(t, &OrgLabelTemplate{ID: tpl.ID})
+}
+
+func TestOrgMilestoneTemplate_Milestones(t *testing.T) {
+	now := time.Date(2017, 1, 1, 0, 0, 0, 0, time.Local)
+
+	tpl := &OrgMilestoneTemplate{Name: "Sprint", DueDays: 14, Count: 3, IntervalDays: 14}
+	milestones := tpl.milestones(1, now)
+	if assert.Len(t, milestones, 3) {
+		assert.Equal(t, "Sprint 1", milestones[0].Name)
+		assert.Equal(t, "Sprint 3", milestones[2].Name)
+		assert.Equal(t, now.AddDate(0, 0, 14), milestones[0].Deadline)
+		assert.Equal(t, now.AddDate(0, 0, 42), milestones[2].Deadline)
+	}
+
+	tpl = &OrgMilestoneTemplate{Name: "Backlog"}
+	milestones = tpl.milestones(1, now)
+	if assert.Len(t, milestones, 1) {
+		assert.Equal(t, "Backlog", milestones[0].Name)
+		assert.Equal(t, 9999, milestones[0].Deadline.Year())
+	}
+}
+
+func TestApplyOrgTemplates(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, NewOrgLabelTemplate(&OrgLabelTemplate{OrgID: 3, Name: "kind/bug", Description: "Something is broken", Color: "#ee0701"}))
+	assert.NoError(t, NewOrgMilestoneTemplate(&OrgMilestoneTemplate{OrgID: 3, Name: "Release", DueDays: 30, Count: 2, IntervalDays: 30}))
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+	assert.NoError(t, applyOrgTemplates(x, 3, repo))
+
+	AssertExistsAndLoadBean(t, &Label{RepoID: 3, Name: "kind/bug", Description: "Something is broken", Color: "#ee0701"})
+	AssertExistsAndLoadBean(t, &Milestone{RepoID: 3, Name: "Release 1"})
+	AssertExistsAndLoadBean(t, &Milestone{RepoID: 3, Name: "Release 2"})
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+	assert.Equal(t, 2, repo.NumMilestones)
+}
@@ -0,0 +1,309 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-macaron/binding"
+	"github.com/go-xorm/xorm"
+)
+
+// ProfileFieldType represents the type of values of a custom profile field.
+type ProfileFieldType int
+
+// Enumerate all the profile field types
+const (
+	ProfileFieldText ProfileFieldType = iota + 1
+	ProfileFieldNumber
+	ProfileFieldURL
+	ProfileFieldEmail
+)
+
+var profileFieldTypeNames = map[ProfileFieldType]string{
+	ProfileFieldText:   "text",
+	ProfileFieldNumber: "number",
+	ProfileFieldURL:    "url",
+	ProfileFieldEmail:  "email",
+}
+
+// String returns the name of the profile field type.
+func (t ProfileFieldType) String() string {
+	return profileFieldTypeNames[t]
+}
+
+// ProfileFieldVisibility represents who can see the values of a custom profile field.
+type ProfileFieldVisibility int
+
+// Enumerate all the profile field visibilities
+const (
+	ProfileFieldPublic   ProfileFieldVisibility = iota + 1 // Everyone
+	ProfileFieldSignedIn                                   // Signed in users
+	ProfileFieldPrivate                                    // The user and site administrators
+)
+
+// ProfileField represents a custom field of user profiles defined by site administrators.
+type ProfileField struct {
+	ID          int64  `xorm:"pk autoincr"`
+	Name        string `xorm:"UNIQUE NOT NULL"`
+	Description string
+	Type        ProfileFieldType
+	Visibility  ProfileFieldVisibility
+	Sorting     int
+	Created     time.Time `xorm:"-"`
+	CreatedUnix int64
+}
+
+// BeforeInsert will be invoked by XORM before inserting a record
+func (f *ProfileField) BeforeInsert() {
+	f.CreatedUnix = time.Now().Unix()
+}
+
+// AfterSet is invoked from XORM after setting the value of a field of this object.
+func (f *ProfileField) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		f.Created = time.Unix(f.CreatedUnix, 0).Local()
+	}
+}
+
+// IsURL returns true if values of the field are links.
+func (f *ProfileField) IsURL() bool {
+	return f.Type == ProfileFieldURL
+}
+
+// IsVisibleTo returns true if the value of the field in the profile of
+// owner can be seen by doer, who is nil for anonymous visitors.
+func (f *ProfileField) IsVisibleTo(owner, doer *User) bool {
+	switch f.Visibility {
+	case ProfileFieldSignedIn:
+		return doer != nil
+	case ProfileFieldPrivate:
+		return doer != nil && (doer.ID == owner.ID || doer.IsAdmin)
+	}
+	return true
+}
+
+// ValidateValue returns an error if value is not valid for the type of the field.
+func (f *ProfileField) ValidateValue(value string) error {
+	if len(value) == 0 {
+		return nil
+	}
+
+	var valid bool
+	switch f.Type {
+	case ProfileFieldNumber:
+		_, err := strconv.ParseFloat(value, 64)
+		valid = err == nil
+	case ProfileFieldURL:
+		u, err := url.ParseRequestURI(value)
+		valid = err == nil && (u.Scheme == "http" || u.Scheme == "https")
+	case ProfileFieldEmail:
+		valid = binding.EmailPattern.MatchString(value)
+	default:
+		valid = true
+	}
+	if !valid {
+		return ErrProfileFieldInvalidValue{f.Name, value}
+	}
+	return nil
+}
+
+func (f *ProfileField) sanitize() {
+	f.Name = strings.TrimSpace(f.Name)
+	if _, ok := profileFieldTypeNames[f.Type]; !ok {
+		f.Type = ProfileFieldText
+	}
+	if f.Visibility < ProfileFieldPublic || f.Visibility > ProfileFieldPrivate {
+		f.Visibility = ProfileFieldPublic
+	}
+}
+
+func isProfileFieldExist(id int64, name string) (bool, error) {
+	return x.
+		Where("id != ?", id).
+		And("name = ?", name).
+		Get(new(ProfileField))
+}
+
+// NewProfileField creates a new custom profile field.
+func NewProfileField(f *ProfileField) error {
+	f.sanitize()
+	has, err := isProfileFieldExist(0, f.Name)
+	if err != nil {
+		return err
+	} else if has {
+		return ErrProfileFieldAlreadyExist{f.Name}
+	}
+
+	_, err = x.Insert(f)
+	return err
+}
+
+// GetProfileFieldByID returns the custom profile field with given ID.
+func GetProfileFieldByID(id int64) (*ProfileField, error) {
+	f := new(ProfileField)
+	has, err := x.Id(id).Get(f)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrProfileFieldNotExist{id}
+	}
+	return f, nil
+}
+
+// GetProfileFields returns all custom profile fields.
+func GetProfileFields() ([]*ProfileField, error) {
+	fields := make([]*ProfileField, 0, 5)
+	return fields, x.
+		Asc("sorting").
+		Asc("id").
+		Find(&fields)
+}
+
+// UpdateProfileField updates the definition of a custom profile field.
+func UpdateProfileField(f *ProfileField) error {
+	f.sanitize()
+	has, err := isProfileFieldExist(f.ID, f.Name)
+	if err != nil {
+		return err
+	} else if has {
+		return ErrProfileFieldAlreadyExist{f.Name}
+	}
+
+	_, err = x.Id(f.ID).AllCols().Update(f)
+	return err
+}
+
+// DeleteProfileField deletes a custom profile field and its values in all profiles.
+func DeleteProfileField(id int64) error {
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.Id(id).Delete(new(ProfileField)); err != nil {
+		return err
+	} else if _, err = sess.Delete(&ProfileFieldValue{FieldID: id}); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// ProfileFieldValue represents the value of a custom profile field of a user.
+type ProfileFieldValue struct {
+	ID      int64         `xorm:"pk autoincr"`
+	FieldID int64         `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Field   *ProfileField `xorm:"-"`
+	UserID  int64         `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Value   string        `xorm:"TEXT"`
+}
+
+// GetProfileFieldValues returns the non-empty custom profile fields of owner
+// which are visible to doer, in the order of their definition.
+func GetProfileFieldValues(owner, doer *User) ([]*ProfileFieldValue, error) {
+	fields, err := GetProfileFields()
+	if err != nil {
+		return nil, fmt.Errorf("GetProfileFields: %v", err)
+	}
+
+	values := make([]*ProfileFieldValue, 0, len(fields))
+	if err = x.
+		Where("user_id = ?", owner.ID).
+		Find(&values); err != nil {
+		return nil, err
+	}
+	valueMap := make(map[int64]*ProfileFieldValue, len(values))
+	for _, value := range values {
+		valueMap[value.FieldID] = value
+	}
+
+	values = values[:0]
+	for _, field := range fields {
+		value, ok := valueMap[field.ID]
+		if !ok || len(value.Value) == 0 || !field.IsVisibleTo(owner, doer) {
+			continue
+		}
+		value.Field = field
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// LoadProfileFieldValues loads the values of custom profile fields of all users.
+func LoadProfileFieldValues(users []*User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	userIDs := make([]int64, len(users))
+	userMap := make(map[int64]*User, len(users))
+	for i, u := range users {
+		userIDs[i] = u.ID
+		u.ProfileFieldValues = make(map[int64]string)
+		userMap[u.ID] = u
+	}
+
+	values := make([]*ProfileFieldValue, 0, len(users))
+	if err := x.
+		In("user_id", userIDs).
+		Find(&values); err != nil {
+		return err
+	}
+	for _, value := range values {
+		userMap[value.UserID].ProfileFieldValues[value.FieldID] = value.Value
+	}
+	return nil
+}
+
+// UpdateProfileFieldValues sets the values of custom profile fields of the user,
+// given by field ID. Values are validated before any of them is saved.
+func UpdateProfileFieldValues(userID int64, values map[int64]string) error {
+	fields, err := GetProfileFields()
+	if err != nil {
+		return fmt.Errorf("GetProfileFields: %v", err)
+	}
+	for _, field := range fields {
+		if value, ok := values[field.ID]; ok {
+			if err = field.ValidateValue(strings.TrimSpace(value)); err != nil {
+				return err
+			}
+		}
+	}
+
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		value, ok := values[field.ID]
+		if !ok {
+			continue
+		}
+
+		if _, err = sess.Delete(&ProfileFieldValue{FieldID: field.ID, UserID: userID}); err != nil {
+			return err
+		}
+		value = strings.TrimSpace(value)
+		if len(value) == 0 {
+			continue
+		}
+		if _, err = sess.Insert(&ProfileFieldValue{
+			FieldID: field.ID,
+			UserID:  userID,
+			Value:   value,
+		}); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
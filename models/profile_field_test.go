@@ -0,0 +1,116 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProfileField(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	field := &ProfileField{Name: " Matrix ", Type: 42}
+	assert.NoError(t, NewProfileField(field))
+	AssertExistsAndLoadBean(t, &ProfileField{ID: field.ID, Name: "Matrix", Type: ProfileFieldText, Visibility: ProfileFieldPublic})
+
+	assert.True(t, IsErrProfileFieldAlreadyExist(NewProfileField(&ProfileField{Name: "Department"})))
+}
+
+func TestUpdateProfileField(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	field := AssertExistsAndLoadBean(t, &ProfileField{ID: 1}).(*ProfileField)
+	field.Name = "Homepage"
+	assert.True(t, IsErrProfileFieldAlreadyExist(UpdateProfileField(field)))
+
+	field.Name = "Team"
+	field.Visibility = ProfileFieldSignedIn
+	assert.NoError(t, UpdateProfileField(field))
+	AssertExistsAndLoadBean(t, &ProfileField{ID: 1, Name: "Team", Visibility: ProfileFieldSignedIn})
+}
+
+func TestDeleteProfileField(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	assert.NoError(t, DeleteProfileField(1))
+	AssertNotExistsBean(t, &ProfileField{ID: 1})
+	AssertNotExistsBean(t, &ProfileFieldValue{FieldID: 1})
+	AssertExistsAndLoadBean(t, &ProfileFieldValue{FieldID: 2})
+}
+
+func TestProfileField_ValidateValue(t *testing.T) {
+	for _, test := range []struct {
+		Type  ProfileFieldType
+		Value string
+		Valid bool
+	}{
+		{ProfileFieldText, "anything", true},
+		{ProfileFieldNumber, "12.5", true},
+		{ProfileFieldNumber, "twelve", false},
+		{ProfileFieldURL, "https://example.com", true},
+		{ProfileFieldURL, "javascript:alert(1)", false},
+		{ProfileFieldEmail, "user@example.com", true},
+		{ProfileFieldEmail, "user", false},
+		{ProfileFieldEmail, "", true},
+	} {
+		field := &ProfileField{Name: "field", Type: test.Type}
+		err := field.ValidateValue(test.Value)
+		assert.Equal(t, test.Valid, err == nil, "%s: %s", test.Type, test.Value)
+		if !test.Valid {
+			assert.True(t, IsErrProfileFieldInvalidValue(err))
+		}
+	}
+}
+
+func TestGetProfileFieldValues(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	owner := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	values, err := GetProfileFieldValues(owner, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, values, 1) {
+		assert.Equal(t, "Department", values[0].Field.Name)
+		assert.Equal(t, "Engineering", values[0].Value)
+	}
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	values, err = GetProfileFieldValues(owner, doer)
+	assert.NoError(t, err)
+	assert.Len(t, values, 2)
+
+	values, err = GetProfileFieldValues(owner, owner)
+	assert.NoError(t, err)
+	assert.Len(t, values, 3)
+
+	admin := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	values, err = GetProfileFieldValues(owner, admin)
+	assert.NoError(t, err)
+	assert.Len(t, values, 3)
+}
+
+func TestLoadProfileFieldValues(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	users := []*User{
+		AssertExistsAndLoadBean(t, &User{ID: 1}).(*User),
+		AssertExistsAndLoadBean(t, &User{ID: 2}).(*User),
+	}
+	assert.NoError(t, LoadProfileFieldValues(users))
+	assert.Len(t, users[0].ProfileFieldValues, 0)
+	assert.Equal(t, "1234", users[1].ProfileFieldValues[2])
+}
+
+func TestUpdateProfileFieldValues(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	err := UpdateProfileFieldValues(2, map[int64]string{1: "Sales", 2: "abc"})
+	assert.True(t, IsErrProfileFieldInvalidValue(err))
+	AssertExistsAndLoadBean(t, &ProfileFieldValue{FieldID: 1, UserID: 2, Value: "Engineering"})
+
+	assert.NoError(t, UpdateProfileFieldValues(2, map[int64]string{1: " Sales ", 2: ""}))
+	AssertExistsAndLoadBean(t, &ProfileFieldValue{FieldID: 1, UserID: 2, Value: "Sales"})
+	AssertNotExistsBean(t, &ProfileFieldValue{FieldID: 2, UserID: 2})
+	AssertExistsAndLoadBean(t, &ProfileFieldValue{FieldID: 3, UserID: 2})
+
+	assert.NoError(t, UpdateProfileFieldValues(1, map[int64]string{3: "https://gitea.io"}))
+	AssertExistsAndLoadBean(t, &ProfileFieldValue{FieldID: 3, UserID: 1, Value: "https://gitea.io"})
+}
@@ -26,7 +26,7 @@ import (
 	"github.com/go-xorm/xorm"
 )
 
-var pullRequestQueue = sync.NewUniqueQueue(setting.Repository.PullRequestQueueLength)
+var pullRequestQueue sync.Queue = sync.NewUniqueQueue(setting.Repository.PullRequestQueueLength)
 
 // PullRequestType defines pull request type
 type PullRequestType int
@@ -227,6 +227,25 @@ func (pr *PullRequest) GetHeadRepo() error {
 	return pr.getHeadRepo(x)
 }
 
+// getHeadCommitID returns the ID of the head commit of the pull request, it
+// is empty if the head repository has been deleted.
+func (pr *PullRequest) getHeadCommitID() (string, error) {
+	if err := pr.GetHeadRepo(); err != nil {
+		return "", fmt.Errorf("GetHeadRepo: %v", err)
+	} else if pr.HeadRepo == nil {
+		return "", nil
+	}
+	headGitRepo, err := git.OpenRepository(pr.HeadRepo.RepoPath())
+	if err != nil {
+		return "", fmt.Errorf("OpenRepository: %v", err)
+	}
+	sha, err := headGitRepo.GetBranchCommitID(pr.HeadBranch)
+	if err != nil {
+		return "", fmt.Errorf("GetBranchCommitID: %v", err)
+	}
+	return sha, nil
+}
+
 // GetBaseRepo loads the target repository
 func (pr *PullRequest) GetBaseRepo() (err error) {
 	if pr.BaseRepo != nil {
@@ -250,13 +269,38 @@ func (pr *PullRequest) CanAutoMerge() bool {
 	return pr.Status == PullRequestStatusMergeable
 }
 
-// Merge merges pull request to base repository.
+// MergeStyle is the way the commits of a pull request are merged into its
+// base branch.
+type MergeStyle string
+
+const (
+	// MergeStyleMerge creates a merge commit of the head branch
+	MergeStyleMerge MergeStyle = "merge"
+	// MergeStyleRebase rebases the commits of the head branch onto the base branch
+	MergeStyleRebase MergeStyle = "rebase"
+	// MergeStyleSquash squashes the commits of the head branch into one commit
+	MergeStyleSquash MergeStyle = "squash"
+)
+
+// IsValid returns true if style is a known merge style.
+func (style MergeStyle) IsValid() bool {
+	switch style {
+	case MergeStyleMerge, MergeStyleRebase, MergeStyleSquash:
+		return true
+	}
+	return false
+}
+
+// Merge merges pull request to base repository in given style. The message is
+// the one of the merge or squashed commit, a default is used if it is empty.
 // FIXME: add repoWorkingPull make sure two merges does not happen at same time.
-func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository) (err error) {
+func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository, style MergeStyle, message string) (err error) {
 	if err = pr.GetHeadRepo(); err != nil {
 		return fmt.Errorf("GetHeadRepo: %v", err)
 	} else if err = pr.GetBaseRepo(); err != nil {
 		return fmt.Errorf("GetBaseRepo: %v", err)
+	} else if err = pr.LoadIssue(); err != nil {
+		return fmt.Errorf("LoadIssue: %v", err)
 	}
 
 	defer func() {
@@ -265,9 +309,10 @@ func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository) (err error
 	}()
 
 	headRepoPath := RepoPath(pr.HeadUserName, pr.HeadRepo.Name)
-	headGitRepo, err := git.OpenRepository(headRepoPath)
+
+	oldCommitID, err := baseGitRepo.GetBranchCommitID(pr.BaseBranch)
 	if err != nil {
-		return fmt.Errorf("OpenRepository: %v", err)
+		return fmt.Errorf("GetBranchCommitID: %v", err)
 	}
 
 	// Clone base repo.
@@ -307,18 +352,69 @@ func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository) (err error
 		return fmt.Errorf("git fetch [%s -> %s]: %s", headRepoPath, tmpBasePath, stderr)
 	}
 
-	if _, stderr, err = process.GetManager().ExecDir(-1, tmpBasePath,
-		fmt.Sprintf("PullRequest.Merge (git merge --no-ff --no-commit): %s", tmpBasePath),
-		"git", "merge", "--no-ff", "--no-commit", "head_repo/"+pr.HeadBranch); err != nil {
-		return fmt.Errorf("git merge --no-ff --no-commit [%s]: %v - %s", tmpBasePath, err, stderr)
-	}
-
+	// Rebased commits keep their authors, new commits are authored by doer.
 	sig := doer.NewGitSig()
-	if _, stderr, err = process.GetManager().ExecDir(-1, tmpBasePath,
-		fmt.Sprintf("PullRequest.Merge (git merge): %s", tmpBasePath),
-		"git", "commit", fmt.Sprintf("--author='%s <%s>'", sig.Name, sig.Email),
-		"-m", fmt.Sprintf("Merge branch '%s' of %s/%s into %s", pr.HeadBranch, pr.HeadUserName, pr.HeadRepo.Name, pr.BaseBranch)); err != nil {
-		return fmt.Errorf("git commit [%s]: %v - %s", tmpBasePath, err, stderr)
+	env := append(os.Environ(),
+		"GIT_COMMITTER_NAME="+sig.Name,
+		"GIT_COMMITTER_EMAIL="+sig.Email)
+	authorEnv := append(env,
+		"GIT_AUTHOR_NAME="+sig.Name,
+		"GIT_AUTHOR_EMAIL="+sig.Email)
+
+	switch style {
+	case MergeStyleRebase:
+		if _, stderr, err = process.GetManager().ExecDir(-1, tmpBasePath,
+			fmt.Sprintf("PullRequest.Merge (git checkout -b): %s", tmpBasePath),
+			"git", "checkout", "-b", "head_repo_branch", "head_repo/"+pr.HeadBranch); err != nil {
+			return fmt.Errorf("git checkout -b [%s]: %v - %s", tmpBasePath, err, stderr)
+		}
+		if _, stderr, err = process.GetManager().ExecDirEnv(-1, tmpBasePath,
+			fmt.Sprintf("PullRequest.Merge (git rebase): %s", tmpBasePath), env,
+			"git", "rebase", pr.BaseBranch); err != nil {
+			log.Trace("PullRequest[%d].Merge (git rebase): %s", pr.ID, stderr)
+			return ErrPullRequestMergeConflict{pr.ID, style}
+		}
+		if _, stderr, err = process.GetManager().ExecDir(-1, tmpBasePath,
+			fmt.Sprintf("PullRequest.Merge (git checkout): %s", tmpBasePath),
+			"git", "checkout", pr.BaseBranch); err != nil {
+			return fmt.Errorf("git checkout [%s]: %v - %s", tmpBasePath, err, stderr)
+		}
+		if _, stderr, err = process.GetManager().ExecDir(-1, tmpBasePath,
+			fmt.Sprintf("PullRequest.Merge (git merge --ff-only): %s", tmpBasePath),
+			"git", "merge", "--ff-only", "head_repo_branch"); err != nil {
+			return fmt.Errorf("git merge --ff-only [%s]: %v - %s", tmpBasePath, err, stderr)
+		}
+
+	case MergeStyleSquash:
+		if len(message) == 0 {
+			message = fmt.Sprintf("%s (#%d)", pr.Issue.Title, pr.Index)
+		}
+		if _, stderr, err = process.GetManager().ExecDir(-1, tmpBasePath,
+			fmt.Sprintf("PullRequest.Merge (git merge --squash): %s", tmpBasePath),
+			"git", "merge", "--squash", "head_repo/"+pr.HeadBranch); err != nil {
+			log.Trace("PullRequest[%d].Merge (git merge --squash): %s", pr.ID, stderr)
+			return ErrPullRequestMergeConflict{pr.ID, style}
+		}
+		if _, stderr, err = process.GetManager().ExecDirEnv(-1, tmpBasePath,
+			fmt.Sprintf("PullRequest.Merge (git commit): %s", tmpBasePath), authorEnv,
+			"git", "commit", "-m", message); err != nil {
+			return fmt.Errorf("git commit [%s]: %v - %s", tmpBasePath, err, stderr)
+		}
+
+	default:
+		if len(message) == 0 {
+			message = fmt.Sprintf("Merge branch '%s' of %s/%s into %s", pr.HeadBranch, pr.HeadUserName, pr.HeadRepo.Name, pr.BaseBranch)
+		}
+		if _, stderr, err = process.GetManager().ExecDir(-1, tmpBasePath,
+			fmt.Sprintf("PullRequest.Merge (git merge --no-ff --no-commit): %s", tmpBasePath),
+			"git", "merge", "--no-ff", "--no-commit", "head_repo/"+pr.HeadBranch); err != nil {
+			return fmt.Errorf("git merge --no-ff --no-commit [%s]: %v - %s", tmpBasePath, err, stderr)
+		}
+		if _, stderr, err = process.GetManager().ExecDirEnv(-1, tmpBasePath,
+			fmt.Sprintf("PullRequest.Merge (git merge): %s", tmpBasePath), authorEnv,
+			"git", "commit", "-m", message); err != nil {
+			return fmt.Errorf("git commit [%s]: %v - %s", tmpBasePath, err, stderr)
+		}
 	}
 
 	// Push back to upstream.
@@ -328,7 +424,7 @@ func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository) (err error
 		return fmt.Errorf("git push: %s", stderr)
 	}
 
-	pr.MergedCommitID, err = headGitRepo.GetBranchCommitID(pr.BaseBranch)
+	pr.MergedCommitID, err = baseGitRepo.GetBranchCommitID(pr.BaseBranch)
 	if err != nil {
 		return fmt.Errorf("GetBranchCommit: %v", err)
 	}
@@ -350,7 +446,7 @@ func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository) (err error
 		log.Error(4, "LoadAttributes: %v", err)
 		return nil
 	}
-	if err = PrepareWebhooks(pr.Issue.Repo, HookEventPullRequest, &api.PullRequestPayload{
+	if err = PrepareWebhooks(pr.Issue.Repo, HookEventPullRequest, &PullRequestPayload{
 		Action:      api.HookIssueClosed,
 		Index:       pr.Index,
 		PullRequest: pr.APIFormat(),
@@ -361,28 +457,28 @@ func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository) (err error
 		return nil
 	}
 
-	l, err := headGitRepo.CommitsBetweenIDs(pr.MergedCommitID, pr.MergeBase)
+	l, err := baseGitRepo.CommitsBetweenIDs(pr.MergedCommitID, oldCommitID)
 	if err != nil {
 		log.Error(4, "CommitsBetweenIDs: %v", err)
 		return nil
 	}
 
-	// TODO: when squash commits, no need to append merge commit.
-	// It is possible that head branch is not fully sync with base branch for merge commits,
-	// so we need to get latest head commit and append merge commit manually
-	// to avoid strange diff commits produced.
-	mergeCommit, err := baseGitRepo.GetBranchCommit(pr.BaseBranch)
-	if err != nil {
-		log.Error(4, "GetBranchCommit: %v", err)
-		return nil
+	// Issues are only closed by keywords once they are fixed on the default branch.
+	if pr.BaseBranch == pr.BaseRepo.DefaultBranch {
+		texts := []string{pr.Issue.Content}
+		for e := l.Front(); e != nil; e = e.Next() {
+			texts = append(texts, e.Value.(*git.Commit).Message())
+		}
+		if err = closeIssuesByRefs(doer, pr.BaseRepo, texts...); err != nil {
+			log.Error(4, "closeIssuesByRefs: %v", err)
+		}
 	}
-	l.PushFront(mergeCommit)
 
 	p := &api.PushPayload{
 		Ref:        git.BranchPrefix + pr.BaseBranch,
-		Before:     pr.MergeBase,
+		Before:     oldCommitID,
 		After:      pr.MergedCommitID,
-		CompareURL: setting.AppURL + pr.BaseRepo.ComposeCompareURL(pr.MergeBase, pr.MergedCommitID),
+		CompareURL: setting.AppURL + pr.BaseRepo.ComposeCompareURL(oldCommitID, pr.MergedCommitID),
 		Commits:    ListToPushCommits(l).ToAPIPayloadCommits(pr.BaseRepo.HTMLURL()),
 		Repo:       pr.BaseRepo.APIFormat(AccessModeNone),
 		Pusher:     pr.HeadRepo.MustOwner().APIFormat(),
@@ -394,6 +490,98 @@ func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository) (err error
 	return nil
 }
 
+// CommitsBehind returns the number of commits on the base branch which are
+// not yet contained in the head branch of the pull request.
+func (pr *PullRequest) CommitsBehind(mergeBase string) (int64, error) {
+	if err := pr.GetBaseRepo(); err != nil {
+		return 0, fmt.Errorf("GetBaseRepo: %v", err)
+	}
+	return git.CommitsCount(pr.BaseRepo.RepoPath(), mergeBase+".."+git.BranchPrefix+pr.BaseBranch)
+}
+
+// UpdateBranch brings the head branch of the pull request up to date with its
+// base branch, either by merging the base branch into it or by rebasing the
+// head branch onto the base branch, and pushes the result to the head repository.
+func (pr *PullRequest) UpdateBranch(doer *User, rebase bool) (err error) {
+	if err = pr.GetHeadRepo(); err != nil {
+		return fmt.Errorf("GetHeadRepo: %v", err)
+	} else if pr.HeadRepo == nil {
+		return fmt.Errorf("head repository of pull request [%d] does not exist", pr.ID)
+	} else if err = pr.GetBaseRepo(); err != nil {
+		return fmt.Errorf("GetBaseRepo: %v", err)
+	}
+
+	headRepoPath := pr.HeadRepo.RepoPath()
+	baseRepoPath := pr.BaseRepo.RepoPath()
+
+	// Clone head branch of head repo.
+	tmpBasePath := path.Join(setting.AppDataPath, "tmp/repos", com.ToStr(time.Now().Nanosecond())+".git")
+
+	if err := os.MkdirAll(path.Dir(tmpBasePath), os.ModePerm); err != nil {
+		return fmt.Errorf("Failed to create dir %s: %v", tmpBasePath, err)
+	}
+
+	defer os.RemoveAll(path.Dir(tmpBasePath))
+
+	var stderr string
+	if _, stderr, err = process.GetManager().ExecTimeout(5*time.Minute,
+		fmt.Sprintf("PullRequest.UpdateBranch (git clone): %s", tmpBasePath),
+		"git", "clone", "-b", pr.HeadBranch, headRepoPath, tmpBasePath); err != nil {
+		return fmt.Errorf("git clone: %s", stderr)
+	}
+
+	if _, stderr, err = process.GetManager().ExecDir(5*time.Minute, tmpBasePath,
+		fmt.Sprintf("PullRequest.UpdateBranch (git fetch): %s", tmpBasePath),
+		"git", "fetch", baseRepoPath, pr.BaseBranch); err != nil {
+		return fmt.Errorf("git fetch [%s -> %s]: %s", baseRepoPath, tmpBasePath, stderr)
+	}
+
+	sig := doer.NewGitSig()
+	env := append(os.Environ(),
+		"GIT_COMMITTER_NAME="+sig.Name,
+		"GIT_COMMITTER_EMAIL="+sig.Email)
+
+	forcePush := false
+	if rebase {
+		if _, stderr, err = process.GetManager().ExecDirEnv(-1, tmpBasePath,
+			fmt.Sprintf("PullRequest.UpdateBranch (git rebase): %s", tmpBasePath), env,
+			"git", "rebase", "FETCH_HEAD"); err != nil {
+			log.Trace("PullRequest[%d].UpdateBranch (git rebase): %s", pr.ID, stderr)
+			return ErrPullRequestUpdateConflict{pr.ID, pr.HeadBranch, pr.BaseBranch}
+		}
+		forcePush = true
+	} else {
+		env = append(env,
+			"GIT_AUTHOR_NAME="+sig.Name,
+			"GIT_AUTHOR_EMAIL="+sig.Email)
+		if _, stderr, err = process.GetManager().ExecDirEnv(-1, tmpBasePath,
+			fmt.Sprintf("PullRequest.UpdateBranch (git merge): %s", tmpBasePath), env,
+			"git", "merge", "--no-ff", "--no-edit",
+			"-m", fmt.Sprintf("Merge branch '%s' into %s", pr.BaseBranch, pr.HeadBranch),
+			"FETCH_HEAD"); err != nil {
+			log.Trace("PullRequest[%d].UpdateBranch (git merge): %s", pr.ID, stderr)
+			return ErrPullRequestUpdateConflict{pr.ID, pr.HeadBranch, pr.BaseBranch}
+		}
+	}
+
+	// Push back to head repo.
+	args := []string{"push"}
+	if forcePush {
+		args = append(args, "-f")
+	}
+	args = append(args, headRepoPath, pr.HeadBranch)
+	if _, stderr, err = process.GetManager().ExecDir(-1, tmpBasePath,
+		fmt.Sprintf("PullRequest.UpdateBranch (git push): %s", tmpBasePath),
+		"git", args...); err != nil {
+		return fmt.Errorf("git push: %s", stderr)
+	}
+
+	// Pushing to a local path does not trigger the usual update of pull
+	// requests, so refresh the patch and notify webhooks here.
+	AddTestPullRequestTask(doer, pr.HeadRepo.ID, pr.HeadBranch, true)
+	return nil
+}
+
 // setMerged sets a pull request to merged and closes the corresponding issue
 func (pr *PullRequest) setMerged() (err error) {
 	if pr.HasMerged {
@@ -422,7 +610,7 @@ func (pr *PullRequest) setMerged() (err error) {
 		return err
 	}
 
-	if err = pr.Issue.changeStatus(sess, pr.Merger, pr.Issue.Repo, true); err != nil {
+	if err = pr.Issue.changeStatus(sess, pr.Merger, pr.Issue.Repo, true, ""); err != nil {
 		return fmt.Errorf("Issue.changeStatus: %v", err)
 	}
 	if _, err = sess.Id(pr.ID).AllCols().Update(pr); err != nil {
@@ -599,6 +787,84 @@ func (pr *PullRequest) testPatch() (err error) {
 	return nil
 }
 
+// parseMergeTreeConflicts returns the paths which conflict according to
+// output of the three-way "git merge-tree <base> <ours> <theirs>".
+func parseMergeTreeConflicts(stdout string) []string {
+	var (
+		conflicts = make([]string, 0, 5)
+		seen      = make(map[string]bool)
+
+		section    string
+		filePath   string
+		shas       map[string]string
+		inHunk     bool
+		isConflict bool
+	)
+	flush := func() {
+		// Files removed on one side but changed on the other do not get
+		// conflict markers.
+		if strings.HasPrefix(section, "removed in") && len(shas["base"]) > 0 {
+			for _, role := range []string{"our", "their"} {
+				if len(shas[role]) > 0 && shas[role] != shas["base"] {
+					isConflict = true
+				}
+			}
+		}
+		if isConflict && len(filePath) > 0 && !seen[filePath] {
+			seen[filePath] = true
+			conflicts = append(conflicts, filePath)
+		}
+	}
+
+	for _, line := range strings.Split(stdout, "\n") {
+		switch {
+		case len(line) == 0:
+		case line[0] == '@':
+			inHunk = true
+		case inHunk && strings.HasPrefix(line, "+<<<<<<< "):
+			isConflict = true
+		case line[0] == '+' || line[0] == '-' || line[0] == ' ' || line[0] == '\\':
+			if inHunk {
+				continue
+			}
+			// Entries of the section are listed as "  <role> <mode> <sha> <path>".
+			fields := strings.Fields(line)
+			if len(fields) < 4 {
+				continue
+			}
+			shas[fields[0]] = fields[2]
+			if len(filePath) == 0 {
+				filePath = line[strings.Index(line, fields[2])+len(fields[2])+1:]
+			}
+		default:
+			flush()
+			section = line
+			filePath = ""
+			shas = make(map[string]string, 3)
+			inHunk = false
+			isConflict = false
+		}
+	}
+	flush()
+	return conflicts
+}
+
+// GetConflictedFiles returns the files which conflict when merging the head
+// of the pull request into its base branch.
+func (pr *PullRequest) GetConflictedFiles() ([]string, error) {
+	if err := pr.GetBaseRepo(); err != nil {
+		return nil, fmt.Errorf("GetBaseRepo: %v", err)
+	}
+
+	stdout, err := git.NewCommand("merge-tree", pr.MergeBase,
+		git.BranchPrefix+pr.BaseBranch, fmt.Sprintf("refs/pull/%d/head", pr.Index)).
+		RunInDir(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return nil, fmt.Errorf("git merge-tree: %v", err)
+	}
+	return parseMergeTreeConflicts(stdout), nil
+}
+
 // NewPullRequest creates new pull request with labels for repository.
 func NewPullRequest(repo *Repository, pull *Issue, labelIDs []int64, uuids []string, pr *PullRequest, patch []byte) (err error) {
 	sess := x.NewSession()
@@ -640,6 +906,8 @@ func NewPullRequest(repo *Repository, pull *Issue, labelIDs []int64, uuids []str
 		return fmt.Errorf("Commit: %v", err)
 	}
 
+	addCrossReferences(pull.Poster, pull, nil, pull.Content)
+
 	if err = NotifyWatchers(&Action{
 		ActUserID: pull.Poster.ID,
 		ActUser:   pull.Poster,
@@ -656,7 +924,7 @@ func NewPullRequest(repo *Repository, pull *Issue, labelIDs []int64, uuids []str
 
 	pr.Issue = pull
 	pull.PullRequest = pr
-	if err = PrepareWebhooks(repo, HookEventPullRequest, &api.PullRequestPayload{
+	if err = PrepareWebhooks(repo, HookEventPullRequest, &PullRequestPayload{
 		Action:      api.HookIssueOpened,
 		Index:       pull.Index,
 		PullRequest: pr.APIFormat(),
@@ -673,6 +941,7 @@ func NewPullRequest(repo *Repository, pull *Issue, labelIDs []int64, uuids []str
 // PullRequestsOptions holds the options for PRs
 type PullRequestsOptions struct {
 	Page        int
+	PageSize    int // ItemsPerPage if not set
 	State       string
 	SortType    string
 	Labels      []string
@@ -709,6 +978,9 @@ func PullRequests(baseRepoID int64, opts *PullRequestsOptions) ([]*PullRequest,
 	if opts.Page <= 0 {
 		opts.Page = 1
 	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = ItemsPerPage
+	}
 
 	countSession, err := listPullRequestStatement(baseRepoID, opts)
 	if err != nil {
@@ -721,13 +993,13 @@ func PullRequests(baseRepoID int64, opts *PullRequestsOptions) ([]*PullRequest,
 		return nil, maxResults, err
 	}
 
-	prs := make([]*PullRequest, 0, ItemsPerPage)
+	prs := make([]*PullRequest, 0, opts.PageSize)
 	findSession, err := listPullRequestStatement(baseRepoID, opts)
 	if err != nil {
 		log.Error(4, "listPullRequestStatement", err)
 		return nil, maxResults, err
 	}
-	findSession.Limit(ItemsPerPage, (opts.Page-1)*ItemsPerPage)
+	findSession.Limit(opts.PageSize, (opts.Page-1)*opts.PageSize)
 	return prs, maxResults, findSession.Find(&prs)
 }
 
@@ -887,6 +1159,62 @@ func (pr *PullRequest) UpdatePatch() (err error) {
 	return nil
 }
 
+// ChangeBaseBranch retargets the pull request to another branch of its base
+// repository, its merge base and patch are updated and it is tested again.
+// Comments are kept.
+func (pr *PullRequest) ChangeBaseBranch(doer *User, baseBranch string) (err error) {
+	if pr.BaseBranch == baseBranch {
+		return nil
+	} else if pr.HeadRepoID == pr.BaseRepoID && pr.HeadBranch == baseBranch {
+		return ErrPullRequestBaseIsHead{pr.ID, baseBranch}
+	} else if err = pr.GetBaseRepo(); err != nil {
+		return fmt.Errorf("GetBaseRepo: %v", err)
+	} else if !git.IsBranchExist(pr.BaseRepo.RepoPath(), baseBranch) {
+		return ErrBranchNotExist{baseBranch}
+	}
+
+	existing, err := GetUnmergedPullRequest(pr.HeadRepoID, pr.BaseRepoID, pr.HeadBranch, baseBranch)
+	if err == nil {
+		return ErrPullRequestAlreadyExists{existing.ID, existing.IssueID, existing.HeadRepoID, existing.BaseRepoID, existing.HeadBranch, existing.BaseBranch}
+	} else if !IsErrPullRequestNotExist(err) {
+		return fmt.Errorf("GetUnmergedPullRequest: %v", err)
+	}
+
+	oldBranch := pr.BaseBranch
+	pr.BaseBranch = baseBranch
+	if err = pr.UpdateCols("base_branch"); err != nil {
+		return fmt.Errorf("UpdateCols: %v", err)
+	} else if err = pr.UpdatePatch(); err != nil {
+		return fmt.Errorf("UpdatePatch: %v", err)
+	}
+	pr.AddToTaskQueue()
+
+	if err = pr.LoadIssue(); err != nil {
+		log.Error(4, "LoadIssue: %v", err)
+		return nil
+	} else if err = pr.GetHeadRepo(); err != nil {
+		log.Error(4, "GetHeadRepo: %v", err)
+		return nil
+	}
+	if err = PrepareWebhooks(pr.BaseRepo, HookEventPullRequest, &PullRequestPayload{
+		Action: api.HookIssueEdited,
+		Index:  pr.Index,
+		Changes: &ChangesPayload{
+			Ref: &api.ChangesFromPayload{
+				From: oldBranch,
+			},
+		},
+		PullRequest: pr.APIFormat(),
+		Repository:  pr.BaseRepo.APIFormat(AccessModeNone),
+		Sender:      doer.APIFormat(),
+	}); err != nil {
+		log.Error(4, "PrepareWebhooks: %v", err)
+	} else {
+		go HookQueue.Add(pr.BaseRepoID)
+	}
+	return nil
+}
+
 // PushToBaseRepo pushes commits from branches of head repository to
 // corresponding branches of base repository.
 // FIXME: Only push branches that are actually updates?
@@ -1006,7 +1334,7 @@ func AddTestPullRequestTask(doer *User, repoID int64, branch string, isSync bool
 					log.Error(4, "LoadAttributes: %v", err)
 					continue
 				}
-				if err = PrepareWebhooks(pr.Issue.Repo, HookEventPullRequest, &api.PullRequestPayload{
+				if err = PrepareWebhooks(pr.Issue.Repo, HookEventPullRequest, &PullRequestPayload{
 					Action:      api.HookIssueSynchronized,
 					Index:       pr.Issue.Index,
 					PullRequest: pr.Issue.PullRequest.APIFormat(),
@@ -1096,26 +1424,35 @@ func TestPullRequests() {
 	// Start listening on new test requests.
 	for prID := range pullRequestQueue.Queue() {
 		log.Trace("TestPullRequests[%v]: processing test task", prID)
-		pullRequestQueue.Remove(prID)
 
 		id := com.StrTo(prID).MustInt64()
 		if _, ok := checkedPRs[id]; ok {
+			pullRequestQueue.Remove(prID)
 			continue
 		}
+		finishQueueItem(pullRequestQueue, prID, testPullRequest(id))
+	}
+}
 
-		pr, err := GetPullRequestByID(id)
-		if err != nil {
-			log.Error(4, "GetPullRequestByID[%s]: %v", prID, err)
-			continue
-		} else if pr.manuallyMerged() {
-			continue
-		} else if err = pr.testPatch(); err != nil {
-			log.Error(4, "testPatch[%d]: %v", pr.ID, err)
-			continue
+// testPullRequest tests the pull request taken from the queue and updates
+// its status.
+func testPullRequest(id int64) error {
+	pr, err := GetPullRequestByID(id)
+	if err != nil {
+		log.Error(4, "GetPullRequestByID[%d]: %v", id, err)
+		if IsErrPullRequestNotExist(err) {
+			return nil
 		}
-
-		pr.checkAndUpdateStatus()
+		return err
+	} else if pr.manuallyMerged() {
+		return nil
+	} else if err = pr.testPatch(); err != nil {
+		log.Error(4, "testPatch[%d]: %v", pr.ID, err)
+		return err
 	}
+
+	pr.checkAndUpdateStatus()
+	return nil
 }
 
 // InitTestPullRequests runs the task to test all the checking status pull requests
@@ -0,0 +1,300 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+
+	"code.gitea.io/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/Unknwon/com"
+)
+
+// PullRequestBackport tells whether the commits of a merged pull request have
+// been cherry-picked onto a release branch.
+type PullRequestBackport struct {
+	Branch     string
+	Backported bool
+}
+
+// IsBackportBranch returns true if merged pull requests can be backported to
+// the branch of given name.
+func IsBackportBranch(name string) bool {
+	for _, pattern := range setting.Repository.BackportBranches {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// getPatchIDs returns the stable patch IDs of the non merge commits in given
+// range of the repository. They only depend on the changes of the commits, so
+// a commit and its cherry-picks have the same patch ID.
+func getPatchIDs(repoPath, revRange string) (map[string]bool, error) {
+	logCmd := exec.Command("git", "log", "--no-merges", "--no-color", "-p", revRange, "--")
+	logCmd.Dir = repoPath
+	logStderr := new(bytes.Buffer)
+	logCmd.Stderr = logStderr
+	stdout, err := logCmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("StdoutPipe: %v", err)
+	}
+
+	idCmd := exec.Command("git", "patch-id", "--stable")
+	idCmd.Dir = repoPath
+	idCmd.Stdin = stdout
+	idStdout := new(bytes.Buffer)
+	idCmd.Stdout = idStdout
+
+	if err = logCmd.Start(); err != nil {
+		return nil, fmt.Errorf("Start: %v", err)
+	}
+	pid := process.GetManager().Add(fmt.Sprintf("getPatchIDs [repo_path: %s, range: %s]", repoPath, revRange), logCmd)
+	defer process.GetManager().Remove(pid)
+
+	if err = idCmd.Run(); err != nil {
+		logCmd.Wait()
+		return nil, fmt.Errorf("git patch-id: %v", err)
+	} else if err = logCmd.Wait(); err != nil {
+		return nil, fmt.Errorf("git log: %v - %s", err, logStderr)
+	}
+
+	ids := make(map[string]bool)
+	for _, line := range strings.Split(idStdout.String(), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 {
+			ids[fields[0]] = true
+		}
+	}
+	return ids, nil
+}
+
+// headRefName returns the reference of the head commit of the pull request in
+// its base repository.
+func (pr *PullRequest) headRefName() string {
+	return fmt.Sprintf("refs/pull/%d/head", pr.Index)
+}
+
+// backportRange returns the range of the commits to cherry-pick to backport the
+// merged pull request: the commits of its head branch, or the squashed commit
+// when they are not on the base branch.
+func (pr *PullRequest) backportRange(repoPath string) (string, error) {
+	if len(pr.MergeBase) == 0 || len(pr.MergedCommitID) == 0 {
+		return "", fmt.Errorf("pull request [%d] has no merge base or merged commit", pr.ID)
+	}
+
+	headRange := pr.MergeBase + ".." + pr.headRefName()
+	headIDs, err := getPatchIDs(repoPath, headRange)
+	if err != nil {
+		return "", err
+	}
+	baseIDs, err := getPatchIDs(repoPath, pr.MergeBase+".."+pr.MergedCommitID)
+	if err != nil {
+		return "", err
+	}
+	for id := range headIDs {
+		if !baseIDs[id] {
+			return pr.MergedCommitID + "^.." + pr.MergedCommitID, nil
+		}
+	}
+	return headRange, nil
+}
+
+// isBackportedTo returns true if the changes of given patch IDs are all on the
+// branch, either cherry-picked or because the branch contains the merged commit.
+func (pr *PullRequest) isBackportedTo(repoPath string, ids map[string]bool, branch string) (bool, error) {
+	if _, err := git.NewCommand("merge-base", "--is-ancestor", pr.MergedCommitID, git.BranchPrefix+branch).RunInDir(repoPath); err == nil {
+		return true, nil
+	} else if len(ids) == 0 {
+		return false, nil
+	}
+
+	branchIDs, err := getPatchIDs(repoPath, pr.MergedCommitID+".."+git.BranchPrefix+branch)
+	if err != nil {
+		return false, err
+	}
+	for id := range ids {
+		if !branchIDs[id] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// GetBackports returns whether the merged pull request has been backported to
+// each release branch of its base repository.
+func (pr *PullRequest) GetBackports() ([]*PullRequestBackport, error) {
+	if !pr.HasMerged {
+		return nil, nil
+	} else if err := pr.GetBaseRepo(); err != nil {
+		return nil, fmt.Errorf("GetBaseRepo: %v", err)
+	}
+	repoPath := pr.BaseRepo.RepoPath()
+
+	gitRepo, err := git.OpenRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("OpenRepository: %v", err)
+	}
+	branches, err := gitRepo.GetBranches()
+	if err != nil {
+		return nil, fmt.Errorf("GetBranches: %v", err)
+	}
+
+	backports := make([]*PullRequestBackport, 0, 2)
+	for _, branch := range branches {
+		if branch != pr.BaseBranch && IsBackportBranch(branch) {
+			backports = append(backports, &PullRequestBackport{Branch: branch})
+		}
+	}
+	if len(backports) == 0 {
+		return backports, nil
+	}
+
+	revRange, err := pr.backportRange(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := getPatchIDs(repoPath, revRange)
+	if err != nil {
+		return nil, err
+	}
+	for _, backport := range backports {
+		if backport.Backported, err = pr.isBackportedTo(repoPath, ids, backport.Branch); err != nil {
+			return nil, err
+		}
+	}
+	return backports, nil
+}
+
+// Backport cherry-picks the commits of the merged pull request onto a new
+// branch created from given release branch, and opens a pull request to merge
+// it into the release branch.
+func (pr *PullRequest) Backport(doer *User, branch string) (_ *PullRequest, err error) {
+	if err = pr.GetBaseRepo(); err != nil {
+		return nil, fmt.Errorf("GetBaseRepo: %v", err)
+	} else if err = pr.LoadIssue(); err != nil {
+		return nil, fmt.Errorf("LoadIssue: %v", err)
+	}
+	repoPath := pr.BaseRepo.RepoPath()
+
+	if !git.IsBranchExist(repoPath, branch) {
+		return nil, ErrBranchNotExist{branch}
+	}
+	headBranch := fmt.Sprintf("backport-%d-to-%s", pr.Index, strings.Replace(branch, "/", "-", -1))
+	if git.IsBranchExist(repoPath, headBranch) {
+		return nil, ErrBranchAlreadyExist{headBranch}
+	}
+
+	revRange, err := pr.backportRange(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := git.NewCommand("rev-list", "--reverse", "--no-merges", revRange).RunInDir(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("git rev-list: %v", err)
+	}
+	commits := strings.Fields(stdout)
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("pull request [%d] has no commits to backport", pr.ID)
+	}
+
+	// Clone release branch of base repo.
+	tmpBasePath := path.Join(setting.AppDataPath, "tmp/repos", com.ToStr(time.Now().Nanosecond())+".git")
+
+	if err := os.MkdirAll(path.Dir(tmpBasePath), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("Failed to create dir %s: %v", tmpBasePath, err)
+	}
+
+	defer os.RemoveAll(path.Dir(tmpBasePath))
+
+	var stderr string
+	if _, stderr, err = process.GetManager().ExecTimeout(5*time.Minute,
+		fmt.Sprintf("PullRequest.Backport (git clone): %s", tmpBasePath),
+		"git", "clone", "-b", branch, repoPath, tmpBasePath); err != nil {
+		return nil, fmt.Errorf("git clone: %s", stderr)
+	}
+
+	// The head commits are not on any branch once the head branch is deleted.
+	if _, stderr, err = process.GetManager().ExecDir(5*time.Minute, tmpBasePath,
+		fmt.Sprintf("PullRequest.Backport (git fetch): %s", tmpBasePath),
+		"git", "fetch", "origin", pr.headRefName()); err != nil {
+		return nil, fmt.Errorf("git fetch [%s -> %s]: %s", repoPath, tmpBasePath, stderr)
+	}
+
+	if _, stderr, err = process.GetManager().ExecDir(-1, tmpBasePath,
+		fmt.Sprintf("PullRequest.Backport (git checkout -b): %s", tmpBasePath),
+		"git", "checkout", "-b", headBranch); err != nil {
+		return nil, fmt.Errorf("git checkout -b [%s]: %v - %s", tmpBasePath, err, stderr)
+	}
+
+	// Cherry-picked commits keep their authors, and are committed by doer.
+	sig := doer.NewGitSig()
+	env := append(os.Environ(),
+		"GIT_COMMITTER_NAME="+sig.Name,
+		"GIT_COMMITTER_EMAIL="+sig.Email)
+	if _, stderr, err = process.GetManager().ExecDirEnv(-1, tmpBasePath,
+		fmt.Sprintf("PullRequest.Backport (git cherry-pick): %s", tmpBasePath), env,
+		"git", append([]string{"cherry-pick", "-x"}, commits...)...); err != nil {
+		log.Trace("PullRequest[%d].Backport (git cherry-pick): %s", pr.ID, stderr)
+		return nil, ErrPullRequestBackportConflict{pr.ID, branch}
+	}
+
+	// Push back to base repo.
+	if _, stderr, err = process.GetManager().ExecDir(-1, tmpBasePath,
+		fmt.Sprintf("PullRequest.Backport (git push): %s", tmpBasePath),
+		"git", "push", repoPath, headBranch); err != nil {
+		return nil, fmt.Errorf("git push: %s", stderr)
+	}
+
+	gitRepo, err := git.OpenRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("OpenRepository: %v", err)
+	}
+	mergeBase, err := gitRepo.GetMergeBase(branch, headBranch)
+	if err != nil {
+		return nil, fmt.Errorf("GetMergeBase: %v", err)
+	}
+	patch, err := gitRepo.GetPatch(mergeBase, headBranch)
+	if err != nil {
+		return nil, fmt.Errorf("GetPatch: %v", err)
+	}
+
+	issue := &Issue{
+		RepoID:   pr.BaseRepo.ID,
+		Index:    pr.BaseRepo.NextIssueIndex(),
+		Title:    fmt.Sprintf("[Backport %s] %s", branch, pr.Issue.Title),
+		PosterID: doer.ID,
+		Poster:   doer,
+		IsPull:   true,
+		Content:  fmt.Sprintf("Backport of #%d to %s.", pr.Index, branch),
+	}
+	backport := &PullRequest{
+		HeadRepoID:   pr.BaseRepo.ID,
+		BaseRepoID:   pr.BaseRepo.ID,
+		HeadUserName: pr.BaseRepo.MustOwner().Name,
+		HeadBranch:   headBranch,
+		BaseBranch:   branch,
+		HeadRepo:     pr.BaseRepo,
+		BaseRepo:     pr.BaseRepo,
+		MergeBase:    mergeBase,
+		Type:         PullRequestGitea,
+	}
+	if err = NewPullRequest(pr.BaseRepo, issue, nil, nil, backport, patch); err != nil {
+		return nil, fmt.Errorf("NewPullRequest: %v", err)
+	} else if err = backport.PushToBaseRepo(); err != nil {
+		return nil, fmt.Errorf("PushToBaseRepo: %v", err)
+	}
+	backport.Issue = issue
+	return backport, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/git"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsBackportBranch(t *testing.T) {
+	defer func(patterns []string) {
+		setting.Repository.BackportBranches = patterns
+	}(setting.Repository.BackportBranches)
+	setting.Repository.BackportBranches = []string{"release/*", "stable"}
+
+	assert.True(t, IsBackportBranch("release/1.2"))
+	assert.True(t, IsBackportBranch("stable"))
+	assert.False(t, IsBackportBranch("release/1.2/fix"))
+	assert.False(t, IsBackportBranch("master"))
+}
+
+func TestPullRequest_GetBackports(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 1}).(*PullRequest)
+	assert.NoError(t, pr.GetBaseRepo())
+
+	tmpDir, err := ioutil.TempDir("", "backport")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	defer os.RemoveAll(pr.BaseRepo.RepoPath())
+
+	run := func(args ...string) string {
+		args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+		stdout, err := git.NewCommand(args...).RunInDir(tmpDir)
+		assert.NoError(t, err, strings.Join(args, " "))
+		return strings.TrimSpace(stdout)
+	}
+	commit := func(name string) string {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, name), []byte(name), 0644))
+		run("add", name)
+		run("commit", "-m", name)
+		return run("rev-parse", "HEAD")
+	}
+
+	run("init")
+	base := commit("base")
+	run("branch", "-M", "master")
+	run("branch", "release/1.0")
+	run("branch", "release/1.1")
+	run("checkout", "-b", "feature")
+	first := commit("first")
+	second := commit("second")
+	run("checkout", "master")
+	run("merge", "--no-ff", "-m", "merge", "feature")
+	merged := run("rev-parse", "HEAD")
+	run("branch", "release/2.0")
+	run("update-ref", pr.headRefName(), second)
+
+	// The first commit is cherry-picked onto release/1.0, both onto release/1.1.
+	run("checkout", "release/1.0")
+	run("cherry-pick", "-x", first)
+	run("checkout", "release/1.1")
+	run("cherry-pick", "-x", first, second)
+	run("checkout", "master")
+	run("clone", "--bare", "--mirror", ".", pr.BaseRepo.RepoPath())
+
+	pr.MergeBase = base
+	pr.MergedCommitID = merged
+	backports, err := pr.GetBackports()
+	assert.NoError(t, err)
+	assert.EqualValues(t, []*PullRequestBackport{
+		{Branch: "release/1.0", Backported: false},
+		{Branch: "release/1.1", Backported: true},
+		{Branch: "release/2.0", Backported: true},
+	}, backports)
+
+	revRange, err := pr.backportRange(pr.BaseRepo.RepoPath())
+	assert.NoError(t, err)
+	assert.Equal(t, base+".."+pr.headRefName(), revRange)
+
+	// A squashed pull request is backported with its squashed commit.
+	squashed := strings.Fields(run("commit-tree", "-p", base, "-m", "squashed", second+"^{tree}"))[0]
+	run("push", pr.BaseRepo.RepoPath(), squashed+":refs/heads/squashed")
+	pr.MergedCommitID = squashed
+	revRange, err = pr.backportRange(pr.BaseRepo.RepoPath())
+	assert.NoError(t, err)
+	assert.Equal(t, squashed+"^.."+squashed, revRange)
+}
@@ -5,10 +5,15 @@
 package models
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"code.gitea.io/gitea/modules/setting"
 )
 
 func TestPullRequest_LoadAttributes(t *testing.T) {
@@ -230,3 +235,76 @@ func TestChangeUsernameInPullRequests(t *testing.T) {
 	}
 	CheckConsistencyFor(t, &PullRequest{})
 }
+
+func TestMergeStyle_IsValid(t *testing.T) {
+	for _, style := range []MergeStyle{MergeStyleMerge, MergeStyleRebase, MergeStyleSquash} {
+		assert.True(t, style.IsValid())
+	}
+	assert.False(t, MergeStyle("").IsValid())
+	assert.False(t, MergeStyle("octopus").IsValid())
+}
+
+func TestPullRequest_ChangeBaseBranch(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	root, err := ioutil.TempDir("", "pull-base")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+	oldRoot := setting.RepoRootPath
+	setting.RepoRootPath = filepath.Join(root, "repos")
+	defer func() { setting.RepoRootPath = oldRoot }()
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	createTestGitRepo(t, repo.RepoPath(), map[string]string{"README.md": "readme"})
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	assert.NoError(t, pr.ChangeBaseBranch(doer, "master"))
+	assert.True(t, IsErrPullRequestBaseIsHead(pr.ChangeBaseBranch(doer, pr.HeadBranch)))
+	assert.True(t, IsErrBranchNotExist(pr.ChangeBaseBranch(doer, "nonexistent")))
+	AssertExistsAndLoadBean(t, &PullRequest{ID: 2, BaseBranch: "master"})
+}
+
+func TestParseMergeTreeConflicts(t *testing.T) {
+	stdout := `added in both
+  our    100644 e45c9c2666d44e0327c1f9c239a74c508336053e both.txt
+  their  100644 3e757656cf36eca53338e520d134963a44f793f8 both.txt
+@@ -1 +1,5 @@
++<<<<<<< .our
+ other
++=======
++new
++>>>>>>> .their
+changed in both
+  base   100644 de980441c3ab03a8c07dda1ad27b8a11f39deb1e dir/file name.txt
+  our    100644 64a67abe6dd73f3a5742fd63829a8cbc632cb417 dir/file name.txt
+  their  100644 6c184ee0461b82ebe25680c3d3315bf123e59aab dir/file name.txt
+@@ -1,3 +1,7 @@
+ a
++<<<<<<< .our
+ MASTER
++=======
++FEATURE
++>>>>>>> .their
+ c
+merged
+  result 100644 b77b4eb1d946f923f61785536da9ca5af6909f06 merged.txt
+  our    100644 587be6b4c3f93f93c489c0111bba5596147a26cb merged.txt
+@@ -1 +1,2 @@
+ x
++y
+removed in remote
+  base   100644 587be6b4c3f93f93c489c0111bba5596147a26cb changed.txt
+  our    100644 206b37888d9b7affbbead76084a0419c3c868078 changed.txt
+@@ -1,2 +0,0 @@
+-x
+-z
+removed in remote
+  base   100644 587be6b4c3f93f93c489c0111bba5596147a26cb unchanged.txt
+  our    100644 587be6b4c3f93f93c489c0111bba5596147a26cb unchanged.txt
+@@ -1 +0,0 @@
+-x
+`
+	assert.Equal(t, []string{"both.txt", "dir/file name.txt", "changed.txt"}, parseMergeTreeConflicts(stdout))
+	assert.Empty(t, parseMergeTreeConflicts(""))
+}
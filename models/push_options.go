@@ -0,0 +1,240 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/git"
+)
+
+// env keys for push options
+const (
+	// EnvGitConfigParameters is read by Git for the configuration given on the command line.
+	EnvGitConfigParameters = "GIT_CONFIG_PARAMETERS"
+	// PushOptionsConfigParameter makes receive-pack accept push options.
+	PushOptionsConfigParameter = "'receive.advertisepushoptions=true'"
+
+	envPushOptionCount = "GIT_PUSH_OPTION_COUNT"
+)
+
+// Push options understood by Gitea
+const (
+	PushOptionPullCreate      = "pr.create"
+	PushOptionPullTitle       = "pr.title"
+	PushOptionPullDescription = "pr.description"
+	PushOptionPullTarget      = "pr.target"
+	PushOptionTopic           = "topic"
+)
+
+// PushOptions represents the options given with `git push -o`.
+type PushOptions map[string]string
+
+// ParsePushOptions returns the push options passed by Git to the hooks
+// through given environment.
+func ParsePushOptions(getenv func(string) string) PushOptions {
+	opts := make(PushOptions)
+	count, _ := strconv.Atoi(getenv(envPushOptionCount))
+	for i := 0; i < count; i++ {
+		opt := getenv(fmt.Sprintf("GIT_PUSH_OPTION_%d", i))
+		key, value := opt, "true"
+		if j := strings.IndexByte(opt, '='); j >= 0 {
+			key, value = opt[:j], strings.TrimSpace(opt[j+1:])
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if len(key) == 0 {
+			continue
+		}
+
+		// Topics can be given several times.
+		if key == PushOptionTopic && len(opts[key]) > 0 {
+			value = opts[key] + "," + value
+		}
+		opts[key] = value
+	}
+	return opts
+}
+
+// Bool returns true if the option is given without being set to false.
+func (opts PushOptions) Bool(key string) bool {
+	value, has := opts[key]
+	if !has {
+		return false
+	}
+	b, err := strconv.ParseBool(value)
+	return err != nil || b
+}
+
+// Topics returns the topics given with the push.
+func (opts PushOptions) Topics() []string {
+	topics := make([]string, 0, 2)
+	for _, topic := range strings.Split(opts[PushOptionTopic], ",") {
+		if topic = strings.TrimSpace(topic); len(topic) > 0 {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+// PushOptionsRequest represents the push options of a branch sent by the
+// post-receive hook.
+type PushOptionsRequest struct {
+	RepoUserName string
+	RepoName     string
+	PusherID     int64
+	RefFullName  string
+	NewCommitID  string
+	Options      PushOptions
+}
+
+// PushOptionsResult represents what has been done for the push options.
+type PushOptionsResult struct {
+	// Messages are shown to the pusher in the output of the push.
+	Messages []string
+	// NewPull is the pull request created by the push, if any.
+	NewPull *Issue
+}
+
+func (res *PushOptionsResult) addMessage(format string, args ...interface{}) {
+	res.Messages = append(res.Messages, fmt.Sprintf(format, args...))
+}
+
+// HandlePushOptions applies the push options to the pushed branch: it opens a
+// pull request from the branch if asked to, and labels the open pull request
+// of the branch with the repository labels named by the topics.
+func HandlePushOptions(pusher *User, repo *Repository, branch string, opts PushOptions) (*PushOptionsResult, error) {
+	res := &PushOptionsResult{}
+	topics := opts.Topics()
+	if !opts.Bool(PushOptionPullCreate) && len(topics) == 0 {
+		return res, nil
+	}
+
+	labels := make([]*Label, 0, len(topics))
+	for _, topic := range topics {
+		label, err := GetLabelInRepoByName(repo.ID, topic)
+		if err != nil {
+			if IsErrLabelNotExist(err) {
+				res.addMessage("Topic %q is ignored: there is no label with this name", topic)
+				continue
+			}
+			return nil, fmt.Errorf("GetLabelInRepoByName: %v", err)
+		}
+		labels = append(labels, label)
+	}
+
+	target := opts[PushOptionPullTarget]
+	if len(target) == 0 {
+		target = repo.DefaultBranch
+	}
+
+	pr, err := GetUnmergedPullRequest(repo.ID, repo.ID, branch, target)
+	if err != nil && !IsErrPullRequestNotExist(err) {
+		return nil, fmt.Errorf("GetUnmergedPullRequest: %v", err)
+	}
+
+	if pr == nil && opts.Bool(PushOptionPullCreate) {
+		if err = createPullRequestFromPush(res, pusher, repo, branch, target, opts, labels); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	if pr == nil {
+		if len(labels) > 0 {
+			res.addMessage("Topics are ignored: there is no open pull request from %s into %s", branch, target)
+		}
+		return res, nil
+	} else if err = pr.LoadIssue(); err != nil {
+		return nil, fmt.Errorf("LoadIssue: %v", err)
+	}
+
+	if opts.Bool(PushOptionPullCreate) {
+		res.addMessage("Pull request #%d already exists: %s", pr.Issue.Index, pr.Issue.HTMLURL())
+	}
+	for _, label := range labels {
+		if pr.Issue.HasLabel(label.ID) {
+			continue
+		}
+		if err = pr.Issue.AddLabel(pusher, label); err != nil {
+			return nil, fmt.Errorf("AddLabel: %v", err)
+		}
+		res.addMessage("Pull request #%d is labeled %q", pr.Issue.Index, label.Name)
+	}
+	return res, nil
+}
+
+func createPullRequestFromPush(res *PushOptionsResult, pusher *User, repo *Repository, branch, target string, opts PushOptions, labels []*Label) error {
+	if !repo.AllowsPulls() {
+		res.addMessage("Pull request is not created: pull requests are disabled in this repository")
+		return nil
+	} else if branch == target {
+		res.addMessage("Pull request is not created: cannot merge %s into itself", branch)
+		return nil
+	}
+
+	repoPath := repo.RepoPath()
+	gitRepo, err := git.OpenRepository(repoPath)
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	if !gitRepo.IsBranchExist(target) {
+		res.addMessage("Pull request is not created: branch %s does not exist", target)
+		return nil
+	}
+
+	prInfo, err := gitRepo.GetPullRequestInfo(repoPath, target, branch)
+	if err != nil {
+		return fmt.Errorf("GetPullRequestInfo: %v", err)
+	} else if prInfo.Commits.Len() == 0 {
+		res.addMessage("Pull request is not created: %s has no new commits compared to %s", branch, target)
+		return nil
+	}
+	patch, err := gitRepo.GetPatch(prInfo.MergeBase, branch)
+	if err != nil {
+		return fmt.Errorf("GetPatch: %v", err)
+	}
+
+	title := opts[PushOptionPullTitle]
+	if len(title) == 0 {
+		title = branch
+	}
+	labelIDs := make([]int64, len(labels))
+	for i := range labels {
+		labelIDs[i] = labels[i].ID
+	}
+
+	pull := &Issue{
+		RepoID:   repo.ID,
+		Repo:     repo,
+		Index:    repo.NextIssueIndex(),
+		Title:    title,
+		PosterID: pusher.ID,
+		Poster:   pusher,
+		IsPull:   true,
+		Content:  opts[PushOptionPullDescription],
+	}
+	pr := &PullRequest{
+		HeadRepoID:   repo.ID,
+		BaseRepoID:   repo.ID,
+		HeadUserName: repo.MustOwner().Name,
+		HeadBranch:   branch,
+		BaseBranch:   target,
+		HeadRepo:     repo,
+		BaseRepo:     repo,
+		MergeBase:    prInfo.MergeBase,
+		Type:         PullRequestGitea,
+	}
+	if err = NewPullRequest(repo, pull, labelIDs, nil, pr, patch); err != nil {
+		return fmt.Errorf("NewPullRequest: %v", err)
+	} else if err = pr.PushToBaseRepo(); err != nil {
+		return fmt.Errorf("PushToBaseRepo: %v", err)
+	}
+
+	res.NewPull = pull
+	res.addMessage("Created pull request #%d: %s", pull.Index, pull.HTMLURL())
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePushOptions(t *testing.T) {
+	env := map[string]string{
+		"GIT_PUSH_OPTION_COUNT": "6",
+		"GIT_PUSH_OPTION_0":     "pr.create",
+		"GIT_PUSH_OPTION_1":     "PR.Title=Fix the build = again",
+		"GIT_PUSH_OPTION_2":     "topic=bug",
+		"GIT_PUSH_OPTION_3":     "topic= ui, ,docs",
+		"GIT_PUSH_OPTION_4":     "=ignored",
+		"GIT_PUSH_OPTION_5":     "draft=false",
+		"GIT_PUSH_OPTION_6":     "not=counted",
+	}
+	opts := ParsePushOptions(func(key string) string { return env[key] })
+
+	assert.Len(t, opts, 4)
+	assert.True(t, opts.Bool(PushOptionPullCreate))
+	assert.False(t, opts.Bool("draft"))
+	assert.False(t, opts.Bool("not"))
+	assert.Equal(t, "Fix the build = again", opts[PushOptionPullTitle])
+	assert.Equal(t, []string{"bug", "ui", "docs"}, opts.Topics())
+
+	assert.Empty(t, ParsePushOptions(func(string) string { return "" }))
+}
+
+func TestHandlePushOptions(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	pusher := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	res, err := HandlePushOptions(pusher, repo, "branch2", PushOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, res.Messages)
+
+	res, err = HandlePushOptions(pusher, repo, "branch2", PushOptions{PushOptionTopic: "label1,unknown"})
+	assert.NoError(t, err)
+	assert.Len(t, res.Messages, 2)
+	assert.Nil(t, res.NewPull)
+}
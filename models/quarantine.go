@@ -0,0 +1,270 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Unknwon/com"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// quarantinePath returns the path the files of the repository are kept at
+// while it is deleted.
+func (repo *Repository) quarantinePath() string {
+	return filepath.Join(setting.Admin.QuarantinePath, "repos", com.ToStr(repo.ID)+".git")
+}
+
+// quarantineWikiPath returns the path the wiki of the repository is kept at
+// while it is deleted.
+func (repo *Repository) quarantineWikiPath() string {
+	return filepath.Join(setting.Admin.QuarantinePath, "repos", com.ToStr(repo.ID)+".wiki.git")
+}
+
+// moveDir moves the directory src to dst if it exists.
+func moveDir(src, dst string) error {
+	if !com.IsExist(src) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+	return os.Rename(src, dst)
+}
+
+// IsDeleted returns true if the repository is deleted and kept in quarantine.
+func (repo *Repository) IsDeleted() bool {
+	return repo.DeletedUnix > 0
+}
+
+// PurgeTime returns the time the deleted repository will be purged at.
+func (repo *Repository) PurgeTime() time.Time {
+	return time.Unix(repo.DeletedUnix, 0).Add(setting.Admin.DeletionRetention).Local()
+}
+
+// IsDeleted returns true if the user is deleted and kept in quarantine.
+func (u *User) IsDeleted() bool {
+	return u.DeletedUnix > 0
+}
+
+// PurgeTime returns the time the deleted user will be purged at.
+func (u *User) PurgeTime() time.Time {
+	return time.Unix(u.DeletedUnix, 0).Add(setting.Admin.DeletionRetention).Local()
+}
+
+// SoftDeleteRepository deletes a repository of a user or organization, it is
+// hidden and its files are moved to quarantine until the deletion retention
+// has passed, or deleted right away if there is no retention.
+func SoftDeleteRepository(uid, repoID int64) error {
+	if setting.Admin.DeletionRetention == 0 {
+		return DeleteRepository(uid, repoID)
+	}
+
+	repo := &Repository{ID: repoID, OwnerID: uid}
+	has, err := x.Get(repo)
+	if err != nil {
+		return err
+	} else if !has || repo.IsDeleted() {
+		return ErrRepoNotExist{repoID, uid, ""}
+	}
+	if err = repo.GetOwner(); err != nil {
+		return err
+	}
+	repoPath, wikiPath := repo.RepoPath(), repo.WikiPath()
+
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	repo.DeletedUnix = time.Now().Unix()
+	if _, err = sess.Id(repo.ID).Cols("deleted_unix").Update(repo); err != nil {
+		return err
+	}
+	if repo.IsFork {
+		if _, err = sess.Exec("UPDATE `repository` SET num_forks=num_forks-1 WHERE id=?", repo.ForkID); err != nil {
+			return fmt.Errorf("decrease fork count: %v", err)
+		}
+	}
+	if _, err = sess.Exec("UPDATE `user` SET num_repos=num_repos-1 WHERE id=?", uid); err != nil {
+		return err
+	}
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+
+	if err = moveDir(repoPath, repo.quarantinePath()); err != nil {
+		return fmt.Errorf("move repository files to quarantine: %v", err)
+	} else if err = moveDir(wikiPath, repo.quarantineWikiPath()); err != nil {
+		return fmt.Errorf("move repository wiki to quarantine: %v", err)
+	}
+	return nil
+}
+
+// RestoreRepository brings back a deleted repository from quarantine, the
+// owner of the repository must be restored first.
+func RestoreRepository(id int64) error {
+	repo, err := GetRepositoryByID(id)
+	if err != nil {
+		return err
+	} else if !repo.IsDeleted() {
+		return nil
+	}
+	if err = repo.GetOwner(); err != nil {
+		return err
+	} else if repo.Owner.IsDeleted() {
+		return ErrRepoOwnerDeleted{repo.ID, repo.OwnerID}
+	}
+
+	if err = moveDir(repo.quarantinePath(), repo.RepoPath()); err != nil {
+		return fmt.Errorf("move repository files from quarantine: %v", err)
+	} else if err = moveDir(repo.quarantineWikiPath(), repo.WikiPath()); err != nil {
+		return fmt.Errorf("move repository wiki from quarantine: %v", err)
+	}
+
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	repo.DeletedUnix = 0
+	if _, err = sess.Id(repo.ID).Cols("deleted_unix").Update(repo); err != nil {
+		return err
+	}
+	if repo.IsFork {
+		if _, err = sess.Exec("UPDATE `repository` SET num_forks=num_forks+1 WHERE id=?", repo.ForkID); err != nil {
+			return fmt.Errorf("increase fork count: %v", err)
+		}
+	}
+	if _, err = sess.Exec("UPDATE `user` SET num_repos=num_repos+1 WHERE id=?", repo.OwnerID); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// GetQuarantinedRepositories returns the deleted repositories kept in
+// quarantine, the ones purged first come first.
+func GetQuarantinedRepositories() ([]*Repository, error) {
+	repos := make([]*Repository, 0, 10)
+	if err := x.Where("deleted_unix > 0").Asc("deleted_unix").Find(&repos); err != nil {
+		return nil, err
+	}
+	for _, repo := range repos {
+		if err := repo.GetOwner(); err != nil {
+			return nil, err
+		}
+	}
+	return repos, nil
+}
+
+// SoftDeleteUser deletes a user, the user cannot sign in and is hidden until
+// the deletion retention has passed, or deleted right away if there is no
+// retention. Like DeleteUser, the user must not own any repository nor belong
+// to any organization.
+func SoftDeleteUser(u *User) error {
+	if setting.Admin.DeletionRetention == 0 {
+		return PurgeUser(u)
+	}
+
+	count, err := x.Where("owner_id = ? AND deleted_unix = 0", u.ID).Count(new(Repository))
+	if err != nil {
+		return fmt.Errorf("GetRepositoryCount: %v", err)
+	} else if count > 0 {
+		return ErrUserOwnRepos{UID: u.ID}
+	}
+	count, err = u.getOrganizationCount(x)
+	if err != nil {
+		return fmt.Errorf("GetOrganizationCount: %v", err)
+	} else if count > 0 {
+		return ErrUserHasOrgs{UID: u.ID}
+	}
+
+	u.DeletedUnix = time.Now().Unix()
+	_, err = x.Id(u.ID).Cols("deleted_unix").Update(u)
+	return err
+}
+
+// RestoreUser brings back a deleted user from quarantine.
+func RestoreUser(id int64) error {
+	u, err := GetUserByID(id)
+	if err != nil {
+		return err
+	} else if !u.IsDeleted() {
+		return nil
+	}
+
+	u.DeletedUnix = 0
+	_, err = x.Id(u.ID).Cols("deleted_unix").Update(u)
+	return err
+}
+
+// PurgeUser permanently deletes a user along with the deleted repositories
+// of the user still kept in quarantine.
+func PurgeUser(u *User) error {
+	repos := make([]*Repository, 0, 5)
+	if err := x.Where("owner_id = ? AND deleted_unix > 0", u.ID).Find(&repos); err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		if err := DeleteRepository(u.ID, repo.ID); err != nil {
+			return fmt.Errorf("DeleteRepository [%d]: %v", repo.ID, err)
+		}
+	}
+	return DeleteUser(u)
+}
+
+// GetQuarantinedUsers returns the deleted users kept in quarantine, the ones
+// purged first come first.
+func GetQuarantinedUsers() ([]*User, error) {
+	users := make([]*User, 0, 10)
+	return users, x.Where("deleted_unix > 0").Asc("deleted_unix").Find(&users)
+}
+
+// PurgeDeletedRepositoriesAndUsers permanently deletes the repositories and
+// users which have been kept in quarantine for the deletion retention.
+func PurgeDeletedRepositoriesAndUsers() {
+	if !taskStatusTable.StartIfNotRunning(purgeDeleted) {
+		return
+	}
+	defer taskStatusTable.Stop(purgeDeleted)
+
+	log.Trace("Doing: PurgeDeleted")
+
+	deadline := time.Now().Add(-setting.Admin.DeletionRetention).Unix()
+
+	repos := make([]*Repository, 0, 10)
+	if err := x.Where("deleted_unix > 0 AND deleted_unix <= ?", deadline).Find(&repos); err != nil {
+		log.Error(4, "PurgeDeleted: %v", err)
+		return
+	}
+	for _, repo := range repos {
+		if err := DeleteRepository(repo.OwnerID, repo.ID); err != nil {
+			desc := fmt.Sprintf("Purge deleted repository [%d]: %v", repo.ID, err)
+			log.Warn(desc)
+			if err = CreateRepositoryNotice(desc); err != nil {
+				log.Error(4, "CreateRepositoryNotice: %v", err)
+			}
+		}
+	}
+
+	users := make([]*User, 0, 10)
+	if err := x.Where("deleted_unix > 0 AND deleted_unix <= ?", deadline).Find(&users); err != nil {
+		log.Error(4, "PurgeDeleted: %v", err)
+		return
+	}
+	for _, u := range users {
+		if err := PurgeUser(u); err != nil {
+			log.Error(4, "PurgeUser [%d]: %v", u.ID, err)
+		}
+	}
+	log.Trace("PurgeDeleted: %d repositories and %d users purged", len(repos), len(users))
+}
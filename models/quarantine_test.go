@@ -0,0 +1,130 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Unknwon/com"
+	"github.com/stretchr/testify/assert"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+func prepareQuarantine(retention time.Duration) func() {
+	oldRetention, oldPath := setting.Admin.DeletionRetention, setting.Admin.QuarantinePath
+	setting.Admin.DeletionRetention = retention
+	setting.Admin.QuarantinePath = filepath.Join(os.TempDir(), "quarantine")
+	return func() {
+		setting.Admin.DeletionRetention, setting.Admin.QuarantinePath = oldRetention, oldPath
+	}
+}
+
+func TestSoftDeleteRepository(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer prepareQuarantine(24*time.Hour)()
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	repoPath := RepoPath("user2", "repo1")
+	assert.NoError(t, os.MkdirAll(repoPath, os.ModePerm))
+	defer os.RemoveAll(repoPath)
+
+	assert.NoError(t, SoftDeleteRepository(2, 1))
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.True(t, repo.IsDeleted())
+	assert.False(t, com.IsExist(repoPath))
+	assert.True(t, com.IsDir(repo.quarantinePath()))
+	_, err := GetRepositoryByName(2, "repo1")
+	assert.True(t, IsErrRepoNotExist(err))
+	AssertExistsAndLoadBean(t, &User{ID: 2, NumRepos: 1})
+	CheckConsistencyFor(t, &User{}, &Repository{})
+
+	repos, err := GetQuarantinedRepositories()
+	assert.NoError(t, err)
+	if assert.Len(t, repos, 1) {
+		assert.EqualValues(t, 1, repos[0].ID)
+	}
+
+	assert.NoError(t, RestoreRepository(1))
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.False(t, repo.IsDeleted())
+	assert.True(t, com.IsDir(repoPath))
+	assert.False(t, com.IsExist(repo.quarantinePath()))
+	AssertExistsAndLoadBean(t, &User{ID: 2, NumRepos: 2})
+	CheckConsistencyFor(t, &User{}, &Repository{})
+}
+
+func TestSoftDeleteRepository_NoRetention(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer prepareQuarantine(0)()
+
+	assert.NoError(t, SoftDeleteRepository(2, 1))
+	AssertNotExistsBean(t, &Repository{ID: 1})
+	CheckConsistencyFor(t, &User{}, &Repository{})
+}
+
+func TestSoftDeleteUser(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer prepareQuarantine(24*time.Hour)()
+	user := AssertExistsAndLoadBean(t, &User{ID: 10}).(*User)
+
+	err := SoftDeleteUser(user)
+	assert.True(t, IsErrUserOwnRepos(err))
+
+	for _, repoID := range []int64{6, 7, 8} {
+		assert.NoError(t, SoftDeleteRepository(user.ID, repoID))
+	}
+	assert.NoError(t, SoftDeleteUser(user))
+	_, err = GetUserByName(user.Name)
+	assert.True(t, IsErrUserNotExist(err))
+	_, err = UserSignIn(user.Name, "password")
+	assert.True(t, IsErrUserNotExist(err))
+
+	users, err := GetQuarantinedUsers()
+	assert.NoError(t, err)
+	if assert.Len(t, users, 1) {
+		assert.EqualValues(t, user.ID, users[0].ID)
+	}
+
+	err = RestoreRepository(6)
+	assert.True(t, IsErrRepoOwnerDeleted(err))
+
+	assert.NoError(t, RestoreUser(user.ID))
+	_, err = GetUserByName(user.Name)
+	assert.NoError(t, err)
+	assert.NoError(t, RestoreRepository(6))
+	CheckConsistencyFor(t, &User{}, &Repository{})
+}
+
+func TestPurgeDeletedRepositoriesAndUsers(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer prepareQuarantine(24*time.Hour)()
+	user := AssertExistsAndLoadBean(t, &User{ID: 10}).(*User)
+
+	for _, repoID := range []int64{6, 7, 8} {
+		assert.NoError(t, SoftDeleteRepository(user.ID, repoID))
+	}
+	assert.NoError(t, SoftDeleteUser(user))
+	assert.NoError(t, SoftDeleteRepository(2, 1))
+
+	// only the user and its repositories were deleted long enough ago
+	expired := time.Now().Add(-48 * time.Hour).Unix()
+	_, err := x.Exec("UPDATE `repository` SET deleted_unix = ? WHERE owner_id = ?", expired, user.ID)
+	assert.NoError(t, err)
+	_, err = x.Exec("UPDATE `user` SET deleted_unix = ? WHERE id = ?", expired, user.ID)
+	assert.NoError(t, err)
+
+	PurgeDeletedRepositoriesAndUsers()
+	AssertNotExistsBean(t, &User{ID: user.ID})
+	for _, repoID := range []int64{6, 7, 8} {
+		AssertNotExistsBean(t, &Repository{ID: repoID})
+	}
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.True(t, repo.IsDeleted())
+	CheckConsistencyFor(t, &User{}, &Repository{})
+}
@@ -0,0 +1,228 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/sync"
+
+	"github.com/Unknwon/com"
+)
+
+// QueueItem represents an instance waiting in a persistent queue.
+type QueueItem struct {
+	ID        int64  `xorm:"pk autoincr"`
+	QueueName string `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	ItemID    string `xorm:"UNIQUE(s) NOT NULL"`
+	// ClaimedUnix is the time the instance has been taken to be processed,
+	// it is taken again if it is not removed before the claim timeout. It is
+	// negated when the instance is added again while taken.
+	ClaimedUnix int64 `xorm:"INDEX"`
+	CreatedUnix int64
+}
+
+// BeforeInsert will be invoked by XORM before inserting a record
+func (item *QueueItem) BeforeInsert() {
+	item.CreatedUnix = time.Now().Unix()
+}
+
+// InitQueues initializes the queues of background tasks of the type set in
+// the configuration. It must be called before the tasks are started.
+func InitQueues() {
+	HookQueue = newQueue("hook", setting.Webhook.QueueLength)
+	MirrorQueue = newQueue("mirror", setting.Repository.MirrorQueueLength)
+	pullRequestQueue = newQueue("pull_request", setting.Repository.PullRequestQueueLength)
+	languageStatsQueue = newQueue("language_stats", setting.Repository.LanguageStatsQueueLength)
+	userExportQueue = newQueue("user_export", setting.UserExport.QueueLength)
+}
+
+func newQueue(name string, queueLength int) sync.Queue {
+	if setting.Queue.Type == "database" {
+		return newPersistentQueue(name, queueLength)
+	}
+	return sync.NewUniqueQueue(queueLength)
+}
+
+// persistentQueue is a queue stored in the database, which keeps its
+// instances on restart and is shared by all the instances of Gitea.
+type persistentQueue struct {
+	name  string
+	queue chan string
+	wake  chan struct{}
+}
+
+func newPersistentQueue(name string, queueLength int) *persistentQueue {
+	if queueLength <= 0 {
+		queueLength = 100
+	}
+
+	q := &persistentQueue{
+		name:  name,
+		queue: make(chan string, queueLength),
+		wake:  make(chan struct{}, 1),
+	}
+	go q.run()
+	return q
+}
+
+// run takes the instances of the queue as they are added, by this instance
+// of Gitea or by another one.
+func (q *persistentQueue) run() {
+	for {
+		if err := q.claimItems(); err != nil {
+			log.Error(4, "Claim items of queue %s: %v", q.name, err)
+		}
+
+		select {
+		case <-q.wake:
+		case <-time.After(setting.Queue.PollInterval):
+		}
+	}
+}
+
+// claimItems takes the instances which are not taken yet, or whose claim
+// has expired, and sends them to the channel of the queue.
+func (q *persistentQueue) claimItems() error {
+	for {
+		expired := time.Now().Add(-setting.Queue.ClaimTimeout).Unix()
+		items := make([]*QueueItem, 0, 10)
+		if err := x.
+			Where("queue_name = ?", q.name).
+			And("(claimed_unix >= 0 AND claimed_unix < ?) OR (claimed_unix < 0 AND claimed_unix > ?)", expired, -expired).
+			Asc("id").
+			Limit(10).
+			Find(&items); err != nil {
+			return err
+		} else if len(items) == 0 {
+			return nil
+		}
+
+		for _, item := range items {
+			// Another instance of Gitea may have taken the item in the meantime.
+			claimed, err := x.
+				Where("id = ? AND claimed_unix = ?", item.ID, item.ClaimedUnix).
+				Cols("claimed_unix").
+				Update(&QueueItem{ClaimedUnix: time.Now().Unix()})
+			if err != nil {
+				return err
+			} else if claimed == 1 {
+				q.queue <- item.ItemID
+			}
+		}
+	}
+}
+
+// Queue returns channel of queue for retrieving instances.
+func (q *persistentQueue) Queue() <-chan string {
+	return q.queue
+}
+
+// Exist returns true if there is an instance with given identity
+// exists in the queue.
+func (q *persistentQueue) Exist(id interface{}) bool {
+	has, err := x.Get(&QueueItem{QueueName: q.name, ItemID: com.ToStr(id)})
+	if err != nil {
+		log.Error(4, "Check item of queue %s: %v", q.name, err)
+	}
+	return has
+}
+
+// AddFunc adds new instance to the queue with a custom runnable function. An
+// instance added while it is taken is put back in the queue once removed.
+func (q *persistentQueue) AddFunc(id interface{}, fn func()) {
+	if q.Exist(id) {
+		if _, err := x.Exec("UPDATE `queue_item` SET claimed_unix = -claimed_unix WHERE queue_name = ? AND item_id = ? AND claimed_unix > 0",
+			q.name, com.ToStr(id)); err != nil {
+			log.Error(4, "Add item to queue %s again: %v", q.name, err)
+		}
+		return
+	}
+
+	if fn != nil {
+		fn()
+	}
+	if _, err := x.Insert(&QueueItem{QueueName: q.name, ItemID: com.ToStr(id)}); err != nil {
+		// The instance may have been added by another instance of Gitea.
+		if !q.Exist(id) {
+			log.Error(4, "Add item to queue %s: %v", q.name, err)
+		}
+		return
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Add adds new instance to the queue.
+func (q *persistentQueue) Add(id interface{}) {
+	q.AddFunc(id, nil)
+}
+
+// Remove removes instance from the queue once it has been processed. If it
+// has been added again in the meantime, it is put back in the queue instead.
+// An instance which is not removed, for example because its processing
+// failed, is taken again after the claim timeout.
+func (q *persistentQueue) Remove(id interface{}) {
+	idStr := com.ToStr(id)
+	if _, err := x.
+		Where("queue_name = ? AND item_id = ? AND claimed_unix >= 0", q.name, idStr).
+		Delete(new(QueueItem)); err != nil {
+		log.Error(4, "Remove item from queue %s: %v", q.name, err)
+		return
+	}
+
+	readded, err := x.
+		Where("queue_name = ? AND item_id = ? AND claimed_unix < 0", q.name, idStr).
+		Cols("claimed_unix").
+		Update(&QueueItem{ClaimedUnix: 0})
+	if err != nil {
+		log.Error(4, "Put item back in queue %s: %v", q.name, err)
+	} else if readded > 0 {
+		select {
+		case q.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Position returns the 1-based position of the instance with given identity
+// in the queue, or 0 if it is not in the queue.
+func (q *persistentQueue) Position(id interface{}) int {
+	item := &QueueItem{QueueName: q.name, ItemID: com.ToStr(id)}
+	has, err := x.Get(item)
+	if err != nil {
+		log.Error(4, "Get item of queue %s: %v", q.name, err)
+		return 0
+	} else if !has {
+		return 0
+	}
+
+	count, err := x.
+		Where("queue_name = ? AND id <= ?", q.name, item.ID).
+		Count(new(QueueItem))
+	if err != nil {
+		log.Error(4, "Count items of queue %s: %v", q.name, err)
+		return 0
+	}
+	return int(count)
+}
+
+// finishQueueItem removes the instance from the queue once it has been
+// processed. An instance whose processing failed is kept in a persistent
+// queue to be taken again after the claim timeout, the other queues can't
+// take it again so it is removed.
+func finishQueueItem(q sync.Queue, id string, err error) {
+	if err != nil {
+		if _, ok := q.(*persistentQueue); ok {
+			return
+		}
+	}
+	q.Remove(id)
+}
@@ -0,0 +1,82 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistentQueue(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	q := &persistentQueue{
+		name:  "test",
+		queue: make(chan string, 10),
+		wake:  make(chan struct{}, 1),
+	}
+	other := &persistentQueue{
+		name:  "other",
+		queue: make(chan string, 10),
+		wake:  make(chan struct{}, 1),
+	}
+
+	q.Add(1)
+	q.Add(2)
+	q.Add(1)
+	other.Add(1)
+	assert.True(t, q.Exist(1))
+	assert.False(t, q.Exist(3))
+	assert.Equal(t, 1, q.Position(1))
+	assert.Equal(t, 2, q.Position(2))
+	assert.Equal(t, 0, q.Position(3))
+
+	called := false
+	q.AddFunc(2, func() { called = true })
+	assert.False(t, called)
+	q.AddFunc(3, func() { called = true })
+	assert.True(t, called)
+
+	assert.NoError(t, q.claimItems())
+	assert.Len(t, q.queue, 3)
+	assert.Equal(t, "1", <-q.Queue())
+
+	// Claimed items are not taken again before the claim timeout.
+	assert.NoError(t, q.claimItems())
+	assert.Len(t, q.queue, 2)
+
+	q.Remove(1)
+	assert.False(t, q.Exist(1))
+	assert.Equal(t, 0, q.Position(1))
+	assert.Equal(t, 1, q.Position(2))
+	assert.True(t, other.Exist(1))
+}
+
+func TestPersistentQueue_Readd(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	q := &persistentQueue{
+		name:  "test",
+		queue: make(chan string, 10),
+		wake:  make(chan struct{}, 1),
+	}
+
+	q.Add(1)
+	assert.NoError(t, q.claimItems())
+	assert.Equal(t, "1", <-q.Queue())
+
+	// An item added again while it is processed is put back once removed.
+	q.Add(1)
+	assert.NoError(t, q.claimItems())
+	assert.Len(t, q.queue, 0)
+	q.Remove(1)
+	assert.True(t, q.Exist(1))
+	assert.NoError(t, q.claimItems())
+	assert.Equal(t, "1", <-q.Queue())
+
+	q.Remove(1)
+	assert.False(t, q.Exist(1))
+}
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"code.gitea.io/git"
+	"code.gitea.io/gitea/modules/avatar"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markdown"
 	"code.gitea.io/gitea/modules/options"
@@ -180,6 +181,7 @@ type Repository struct {
 	Description   string
 	Website       string
 	DefaultBranch string
+	Avatar        string `xorm:"VARCHAR(64)"`
 
 	NumWatches          int
 	NumStars            int
@@ -209,10 +211,17 @@ type Repository struct {
 	BaseRepo *Repository `xorm:"-"`
 	Size     int64       `xorm:"NOT NULL DEFAULT 0"`
 
+	// CloseIssuesViaCommit enables closing and reopening issues with keywords
+	// in the messages of commits pushed to the default branch.
+	CloseIssuesViaCommit bool `xorm:"NOT NULL DEFAULT true"`
+
 	Created     time.Time `xorm:"-"`
 	CreatedUnix int64     `xorm:"INDEX"`
 	Updated     time.Time `xorm:"-"`
 	UpdatedUnix int64     `xorm:"INDEX"`
+	// DeletedUnix is set while the deleted repository is kept in quarantine.
+	Deleted     time.Time `xorm:"-"`
+	DeletedUnix int64     `xorm:"INDEX NOT NULL DEFAULT 0"`
 }
 
 // BeforeInsert is invoked from XORM before inserting an object of this type.
@@ -244,6 +253,8 @@ func (repo *Repository) AfterSet(colName string, _ xorm.Cell) {
 		repo.Created = time.Unix(repo.CreatedUnix, 0).Local()
 	case "updated_unix":
 		repo.Updated = time.Unix(repo.UpdatedUnix, 0)
+	case "deleted_unix":
+		repo.Deleted = time.Unix(repo.DeletedUnix, 0).Local()
 	}
 }
 
@@ -908,15 +919,22 @@ func MigrateRepository(u *User, opts MigrateRepoOptions) (*Repository, error) {
 	}
 
 	if opts.IsMirror {
-		if _, err = x.InsertOne(&Mirror{
+		m := &Mirror{
 			RepoID:      repo.ID,
+			Repo:        repo,
 			Interval:    setting.Mirror.DefaultInterval,
 			EnablePrune: true,
 			NextUpdate:  time.Now().Add(setting.Mirror.DefaultInterval),
-		}); err != nil {
+		}
+		if _, err = x.InsertOne(m); err != nil {
 			return repo, fmt.Errorf("InsertOne: %v", err)
 		}
 
+		// Move credentials of the remote address out of the Git config.
+		if err = m.SaveAddress(opts.RemoteAddr); err != nil {
+			return repo, fmt.Errorf("SaveAddress: %v", err)
+		}
+
 		repo.IsMirror = true
 		return repo, UpdateRepository(repo, false)
 	}
@@ -938,21 +956,26 @@ func cleanUpMigrateGitConfig(configPath string) error {
 	return nil
 }
 
+var delegateHookNames = []string{"pre-receive", "update", "post-receive"}
+
+// delegateHookContents returns the contents of the hook scripts which delegate
+// to the scripts in the hooks directories, and of the scripts of Gitea in them.
+func delegateHookContents() (hookTpl string, giteaHookTpls []string) {
+	hookTpl = fmt.Sprintf("#!/usr/bin/env %s\ndata=$(cat)\nexitcodes=\"\"\nhookname=$(basename $0)\nGIT_DIR=${GIT_DIR:-$(dirname $0)}\n\nfor hook in ${GIT_DIR}/hooks/${hookname}.d/*; do\ntest -x \"${hook}\" || continue\necho \"${data}\" | \"${hook}\"\nexitcodes=\"${exitcodes} $?\"\ndone\n\nfor i in ${exitcodes}; do\n[ ${i} -eq 0 ] || exit ${i}\ndone\n", setting.ScriptType)
+	giteaHookTpls = []string{
+		fmt.Sprintf("#!/usr/bin/env %s\n\"%s\" hook --config='%s' pre-receive\n", setting.ScriptType, setting.AppPath, setting.CustomConf),
+		fmt.Sprintf("#!/usr/bin/env %s\n\"%s\" hook --config='%s' update $1 $2 $3\n", setting.ScriptType, setting.AppPath, setting.CustomConf),
+		fmt.Sprintf("#!/usr/bin/env %s\n\"%s\" hook --config='%s' post-receive\n", setting.ScriptType, setting.AppPath, setting.CustomConf),
+	}
+	return hookTpl, giteaHookTpls
+}
+
 // createDelegateHooks creates all the hooks scripts for the repo
 func createDelegateHooks(repoPath string) (err error) {
-	var (
-		hookNames     = []string{"pre-receive", "update", "post-receive"}
-		hookTpl       = fmt.Sprintf("#!/usr/bin/env %s\ndata=$(cat)\nexitcodes=\"\"\nhookname=$(basename $0)\nGIT_DIR=${GIT_DIR:-$(dirname $0)}\n\nfor hook in ${GIT_DIR}/hooks/${hookname}.d/*; do\ntest -x \"${hook}\" || continue\necho \"${data}\" | \"${hook}\"\nexitcodes=\"${exitcodes} $?\"\ndone\n\nfor i in ${exitcodes}; do\n[ ${i} -eq 0 ] || exit ${i}\ndone\n", setting.ScriptType)
-		giteaHookTpls = []string{
-			fmt.Sprintf("#!/usr/bin/env %s\n\"%s\" hook --config='%s' pre-receive\n", setting.ScriptType, setting.AppPath, setting.CustomConf),
-			fmt.Sprintf("#!/usr/bin/env %s\n\"%s\" hook --config='%s' update $1 $2 $3\n", setting.ScriptType, setting.AppPath, setting.CustomConf),
-			fmt.Sprintf("#!/usr/bin/env %s\n\"%s\" hook --config='%s' post-receive\n", setting.ScriptType, setting.AppPath, setting.CustomConf),
-		}
-	)
-
+	hookTpl, giteaHookTpls := delegateHookContents()
 	hookDir := filepath.Join(repoPath, "hooks")
 
-	for i, hookName := range hookNames {
+	for i, hookName := range delegateHookNames {
 		oldHookPath := filepath.Join(hookDir, hookName)
 		newHookPath := filepath.Join(hookDir, hookName+".d", "gitea")
 
@@ -1196,6 +1219,7 @@ func createRepository(e *xorm.Session, u *User, repo *Repository) (err error) {
 		return ErrRepoAlreadyExist{u.Name, repo.Name}
 	}
 
+	repo.CloseIssuesViaCommit = true
 	if _, err = e.Insert(repo); err != nil {
 		return err
 	}
@@ -1231,6 +1255,8 @@ func createRepository(e *xorm.Session, u *User, repo *Repository) (err error) {
 			return fmt.Errorf("getOwnerTeam: %v", err)
 		} else if err = t.addRepository(e, repo); err != nil {
 			return fmt.Errorf("addRepository: %v", err)
+		} else if err = applyOrgTemplates(e, u.ID, repo); err != nil {
+			return fmt.Errorf("applyOrgTemplates: %v", err)
 		}
 	} else {
 		// Organization automatically called this in addRepository method.
@@ -1296,8 +1322,47 @@ func CreateRepository(u *User, opts CreateRepoOptions) (_ *Repository, err error
 	return repo, sess.Commit()
 }
 
+// IsPushCreateEnabled returns true if the repositories of the owner can be
+// created by pushing to them.
+func IsPushCreateEnabled(owner *User) bool {
+	if owner.IsOrganization() {
+		return setting.Repository.EnablePushCreateOrg
+	}
+	return setting.Repository.EnablePushCreateUser
+}
+
+// PushCreateRepo creates the repository of given name of the owner, when the
+// doer pushes to it and it does not exist yet. Users can create their own
+// repositories, and the repositories of the organizations they own.
+func PushCreateRepo(doer, owner *User, name string) (*Repository, error) {
+	if !IsPushCreateEnabled(owner) {
+		return nil, ErrPushCreateNotAllowed{owner.Name, name}
+	} else if owner.IsOrganization() {
+		if !doer.IsAdmin && !owner.IsOwnedBy(doer.ID) {
+			return nil, ErrPushCreateNotAllowed{owner.Name, name}
+		}
+	} else if doer.ID != owner.ID {
+		return nil, ErrPushCreateNotAllowed{owner.Name, name}
+	}
+
+	repo, err := CreateRepository(owner, CreateRepoOptions{
+		Name:      name,
+		IsPrivate: setting.Repository.DefaultPushCreatePrivate || setting.Repository.ForcePrivate,
+	})
+	if err != nil {
+		if repo != nil {
+			if errDelete := DeleteRepository(owner.ID, repo.ID); errDelete != nil {
+				log.Error(4, "DeleteRepository: %v", errDelete)
+			}
+		}
+		return nil, err
+	}
+	log.Trace("Repository created by push of %s [%d]: %s/%s", doer.Name, repo.ID, owner.Name, repo.Name)
+	return repo, nil
+}
+
 func countRepositories(userID int64, private bool) int64 {
-	sess := x.Where("id > 0")
+	sess := x.Where("id > 0 AND deleted_unix = 0")
 
 	if userID > 0 {
 		sess.And("owner_id = ?", userID)
@@ -1659,6 +1724,19 @@ func DeleteRepository(uid, repoID int64) error {
 		&PullRequest{BaseRepoID: repoID},
 		&RepoUnit{RepoID: repoID},
 		&RepoRedirect{RedirectRepoID: repoID},
+		&ContributorWeek{RepoID: repoID},
+		&RepoStatsStatus{RepoID: repoID},
+		&RepoAccessLog{RepoID: repoID},
+		&ModerationItem{RepoID: repoID},
+		&LanguageStat{RepoID: repoID},
+		&RequiredStatusContext{RepoID: repoID},
+		&DeployToken{RepoID: repoID},
+		&ServiceDesk{RepoID: repoID},
+		&ServiceDeskIssue{RepoID: repoID},
+		&NotificationChannel{RepoID: repoID},
+		&RepoGitHook{RepoID: repoID},
+		&RenamedBranch{RepoID: repoID},
+		&FederationKey{ActorType: FederationActorRepo, ActorID: repoID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
@@ -1674,6 +1752,13 @@ func DeleteRepository(uid, repoID int64) error {
 		return err
 	}
 
+	if err = deleteRepoBoards(sess, repoID, issueIDs); err != nil {
+		return fmt.Errorf("deleteRepoBoards: %v", err)
+	}
+	if err = deleteRepoAdvisories(sess, repoID); err != nil {
+		return fmt.Errorf("deleteRepoAdvisories: %v", err)
+	}
+
 	if len(issueIDs) > 0 {
 		if _, err = sess.In("issue_id", issueIDs).Delete(&Comment{}); err != nil {
 			return err
@@ -1681,6 +1766,12 @@ func DeleteRepository(uid, repoID int64) error {
 		if _, err = sess.In("issue_id", issueIDs).Delete(&IssueUser{}); err != nil {
 			return err
 		}
+		if _, err = sess.In("issue_id", issueIDs).Delete(&IssueDeadlineReminder{}); err != nil {
+			return err
+		}
+		if _, err = sess.In("issue_id", issueIDs).Delete(&Review{}); err != nil {
+			return err
+		}
 
 		attachments := make([]*Attachment, 0, 5)
 		if err = sess.
@@ -1705,14 +1796,17 @@ func DeleteRepository(uid, repoID int64) error {
 		return err
 	}
 
-	if repo.IsFork {
-		if _, err = sess.Exec("UPDATE `repository` SET num_forks=num_forks-1 WHERE id=?", repo.ForkID); err != nil {
-			return fmt.Errorf("decrease fork count: %v", err)
+	// The counters were already decreased when the repository was moved to quarantine.
+	if repo.DeletedUnix == 0 {
+		if repo.IsFork {
+			if _, err = sess.Exec("UPDATE `repository` SET num_forks=num_forks-1 WHERE id=?", repo.ForkID); err != nil {
+				return fmt.Errorf("decrease fork count: %v", err)
+			}
 		}
-	}
 
-	if _, err = sess.Exec("UPDATE `user` SET num_repos=num_repos-1 WHERE id=?", uid); err != nil {
-		return err
+		if _, err = sess.Exec("UPDATE `user` SET num_repos=num_repos-1 WHERE id=?", uid); err != nil {
+			return err
+		}
 	}
 
 	// FIXME: Remove repository files should be executed after transaction succeed.
@@ -1721,11 +1815,22 @@ func DeleteRepository(uid, repoID int64) error {
 
 	repo.deleteWiki(sess)
 
+	if repo.DeletedUnix > 0 {
+		removeAllWithNotice(sess, "Delete quarantined repository files", repo.quarantinePath())
+		removeAllWithNotice(sess, "Delete quarantined repository wiki", repo.quarantineWikiPath())
+	}
+
 	// Remove attachment files.
 	for i := range attachmentPaths {
 		removeAllWithNotice(sess, "Delete attachment", attachmentPaths[i])
 	}
 
+	if len(repo.Avatar) > 0 {
+		if err = avatar.Remove(repo.CustomAvatarPath()); err != nil {
+			log.Error(4, "Failed to remove %s: %v", repo.CustomAvatarPath(), err)
+		}
+	}
+
 	// Remove LFS objects
 	var lfsObjects []*LFSMetaObject
 	if err = sess.Where("repository_id=?", repoID).Find(&lfsObjects); err != nil {
@@ -1792,7 +1897,7 @@ func GetRepositoryByName(ownerID int64, name string) (*Repository, error) {
 	has, err := x.Get(repo)
 	if err != nil {
 		return nil, err
-	} else if !has {
+	} else if !has || repo.DeletedUnix > 0 {
 		return nil, ErrRepoNotExist{0, ownerID, name}
 	}
 	return repo, err
@@ -1821,7 +1926,7 @@ func GetUserRepositories(userID int64, private bool, page, pageSize int, orderBy
 	}
 
 	sess := x.
-		Where("owner_id = ?", userID).
+		Where("owner_id = ? AND deleted_unix = 0", userID).
 		OrderBy(orderBy)
 	if !private {
 		sess.And("is_private=?", false)
@@ -1840,7 +1945,7 @@ func GetUserRepositories(userID int64, private bool, page, pageSize int, orderBy
 func GetUserMirrorRepositories(userID int64) ([]*Repository, error) {
 	repos := make([]*Repository, 0, 10)
 	return repos, x.
-		Where("owner_id = ?", userID).
+		Where("owner_id = ? AND deleted_unix = 0", userID).
 		And("is_mirror = ?", true).
 		Find(&repos)
 }
@@ -1850,11 +1955,11 @@ func getRepositoryCount(e Engine, u *User) (int64, error) {
 }
 
 func getPublicRepositoryCount(e Engine, u *User) (int64, error) {
-	return e.Where("is_private = ?", false).Count(&Repository{OwnerID: u.ID})
+	return e.Where("is_private = ? AND deleted_unix = 0", false).Count(&Repository{OwnerID: u.ID})
 }
 
 func getPrivateRepositoryCount(e Engine, u *User) (int64, error) {
-	return e.Where("is_private = ?", true).Count(&Repository{OwnerID: u.ID})
+	return e.Where("is_private = ? AND deleted_unix = 0", true).Count(&Repository{OwnerID: u.ID})
 }
 
 // GetRepositoryCount returns the total number of repositories of user.
@@ -1875,7 +1980,7 @@ func GetPrivateRepositoryCount(u *User) (int64, error) {
 // DeleteRepositoryArchives deletes all repositories' archives.
 func DeleteRepositoryArchives() error {
 	return x.
-		Where("id > 0").
+		Where("id > 0 AND deleted_unix = 0").
 		Iterate(new(Repository),
 			func(idx int, bean interface{}) error {
 				repo := bean.(*Repository)
@@ -1892,7 +1997,7 @@ func DeleteOldRepositoryArchives() {
 
 	log.Trace("Doing: ArchiveCleanup")
 
-	if err := x.Where("id > 0").Iterate(new(Repository), deleteOldRepositoryArchives); err != nil {
+	if err := x.Where("id > 0 AND deleted_unix = 0").Iterate(new(Repository), deleteOldRepositoryArchives); err != nil {
 		log.Error(4, "ArchiveClean: %v", err)
 	}
 }
@@ -1939,7 +2044,7 @@ func deleteOldRepositoryArchives(idx int, bean interface{}) error {
 func gatherMissingRepoRecords() ([]*Repository, error) {
 	repos := make([]*Repository, 0, 10)
 	if err := x.
-		Where("id > 0").
+		Where("id > 0 AND deleted_unix = 0").
 		Iterate(new(Repository),
 			func(idx int, bean interface{}) error {
 				repo := bean.(*Repository)
@@ -2002,11 +2107,14 @@ func ReinitMissingRepositories() error {
 // SyncRepositoryHooks rewrites all repositories' pre-receive, update and post-receive hooks
 // to make sure the binary and custom conf path are up-to-date.
 func SyncRepositoryHooks() error {
-	return x.Where("id > 0").Iterate(new(Repository),
+	return x.Where("id > 0 AND deleted_unix = 0").Iterate(new(Repository),
 		func(idx int, bean interface{}) error {
 			if err := createDelegateHooks(bean.(*Repository).RepoPath()); err != nil {
 				return fmt.Errorf("SyncRepositoryHook: %v", err)
 			}
+			if err := syncRepoGitHooks(bean.(*Repository)); err != nil {
+				return fmt.Errorf("syncRepoGitHooks: %v", err)
+			}
 			if bean.(*Repository).HasWiki() {
 				if err := createDelegateHooks(bean.(*Repository).WikiPath()); err != nil {
 					return fmt.Errorf("SyncRepositoryHook: %v", err)
@@ -2020,10 +2128,18 @@ func SyncRepositoryHooks() error {
 var taskStatusTable = sync.NewStatusTable()
 
 const (
-	mirrorUpdate   = "mirror_update"
-	gitFsck        = "git_fsck"
-	checkRepos     = "check_repos"
-	archiveCleanup = "archive_cleanup"
+	mirrorUpdate     = "mirror_update"
+	gitFsck          = "git_fsck"
+	checkRepos       = "check_repos"
+	archiveCleanup   = "archive_cleanup"
+	accessLogCleanup = "access_log_cleanup"
+	deadlineReminder = "deadline_reminder"
+
+	uploadSessionCleanup = "upload_session_cleanup"
+	attachmentCleanup    = "attachment_cleanup"
+	actionCleanup        = "action_cleanup"
+	purgeDeleted         = "purge_deleted"
+	userExportCleanup    = "user_export_cleanup"
 )
 
 // GitFsck calls 'git fsck' to check repository health.
@@ -2036,18 +2152,10 @@ func GitFsck() {
 	log.Trace("Doing: GitFsck")
 
 	if err := x.
-		Where("id>0").
+		Where("id > 0 AND deleted_unix = 0").
 		Iterate(new(Repository),
 			func(idx int, bean interface{}) error {
-				repo := bean.(*Repository)
-				repoPath := repo.RepoPath()
-				if err := git.Fsck(repoPath, setting.Cron.RepoHealthCheck.Timeout, setting.Cron.RepoHealthCheck.Args...); err != nil {
-					desc := fmt.Sprintf("Failed to health check repository (%s): %v", repoPath, err)
-					log.Warn(desc)
-					if err = CreateRepositoryNotice(desc); err != nil {
-						log.Error(4, "CreateRepositoryNotice: %v", err)
-					}
-				}
+				checkRepoHealth(bean.(*Repository))
 				return nil
 			}); err != nil {
 		log.Error(4, "GitFsck: %v", err)
@@ -2056,23 +2164,15 @@ func GitFsck() {
 
 // GitGcRepos calls 'git gc' to remove unnecessary files and optimize the local repository
 func GitGcRepos() error {
-	args := append([]string{"gc"}, setting.Git.GCArgs...)
 	return x.
-		Where("id > 0").
+		Where("id > 0 AND deleted_unix = 0").
 		Iterate(new(Repository),
 			func(idx int, bean interface{}) error {
 				repo := bean.(*Repository)
 				if err := repo.GetOwner(); err != nil {
 					return err
 				}
-				_, stderr, err := process.GetManager().ExecDir(
-					time.Duration(setting.Git.Timeout.GC)*time.Second,
-					RepoPath(repo.Owner.Name, repo.Name), "Repository garbage collection",
-					"git", args...)
-				if err != nil {
-					return fmt.Errorf("%v: %v", err, stderr)
-				}
-				return nil
+				return repo.GC()
 			})
 }
 
@@ -2127,8 +2227,8 @@ func CheckRepoStats() {
 		},
 		// User.NumRepos
 		{
-			"SELECT `user`.id FROM `user` WHERE `user`.num_repos!=(SELECT COUNT(*) FROM `repository` WHERE owner_id=`user`.id)",
-			"UPDATE `user` SET num_repos=(SELECT COUNT(*) FROM `repository` WHERE owner_id=?) WHERE id=?",
+			"SELECT `user`.id FROM `user` WHERE `user`.num_repos!=(SELECT COUNT(*) FROM `repository` WHERE owner_id=`user`.id AND deleted_unix=0)",
+			"UPDATE `user` SET num_repos=(SELECT COUNT(*) FROM `repository` WHERE owner_id=? AND deleted_unix=0) WHERE id=?",
 			"user count 'num_repos'",
 		},
 		// Issue.NumComments
@@ -2161,7 +2261,7 @@ func CheckRepoStats() {
 
 	// FIXME: use checker when stop supporting old fork repo format.
 	// ***** START: Repository.NumForks *****
-	results, err = x.Query("SELECT repo.id FROM `repository` repo WHERE repo.num_forks!=(SELECT COUNT(*) FROM `repository` WHERE fork_id=repo.id)")
+	results, err = x.Query("SELECT repo.id FROM `repository` repo WHERE repo.num_forks!=(SELECT COUNT(*) FROM `repository` WHERE fork_id=repo.id AND deleted_unix=0)")
 	if err != nil {
 		log.Error(4, "Select repository count 'num_forks': %v", err)
 	} else {
@@ -2175,7 +2275,7 @@ func CheckRepoStats() {
 				continue
 			}
 
-			rawResult, err := x.Query("SELECT COUNT(*) FROM `repository` WHERE fork_id=?", repo.ID)
+			rawResult, err := x.Query("SELECT COUNT(*) FROM `repository` WHERE fork_id=? AND deleted_unix=0", repo.ID)
 			if err != nil {
 				log.Error(4, "Select count of forks[%d]: %v", repo.ID, err)
 				continue
@@ -2219,7 +2319,10 @@ func ForkRepository(u *User, oldRepo *Repository, name, desc string) (_ *Reposit
 			Name:  forkedRepo.Name,
 		}
 	}
+	return forkRepository(u, oldRepo, name, desc, oldRepo.IsPrivate)
+}
 
+func forkRepository(u *User, oldRepo *Repository, name, desc string, isPrivate bool) (_ *Repository, err error) {
 	repo := &Repository{
 		OwnerID:       u.ID,
 		Owner:         u,
@@ -2227,7 +2330,7 @@ func ForkRepository(u *User, oldRepo *Repository, name, desc string) (_ *Reposit
 		LowerName:     strings.ToLower(name),
 		Description:   desc,
 		DefaultBranch: oldRepo.DefaultBranch,
-		IsPrivate:     oldRepo.IsPrivate,
+		IsPrivate:     isPrivate,
 		IsFork:        true,
 		ForkID:        oldRepo.ID,
 	}
@@ -2299,10 +2402,15 @@ func ForkRepository(u *User, oldRepo *Repository, name, desc string) (_ *Reposit
 	return repo, sess2.Commit()
 }
 
-// GetForks returns all the forks of the repository
-func (repo *Repository) GetForks() ([]*Repository, error) {
+// GetForks returns the forks of the repository doer can read, doer is nil for
+// anonymous users. Private forks, like the temporary ones of security
+// advisories, are not listed to everyone who can read the repository.
+func (repo *Repository) GetForks(doer *User) ([]*Repository, error) {
 	forks := make([]*Repository, 0, repo.NumForks)
-	return forks, x.Find(&forks, &Repository{ForkID: repo.ID})
+	return forks, x.
+		Where("fork_id = ?", repo.ID).
+		And(accessibleRepositoryCond(doer)).
+		Find(&forks)
 }
 
 // GetUserFork return user forked repository from this repository, if not forked return nil
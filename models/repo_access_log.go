@@ -0,0 +1,173 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/go-xorm/xorm"
+)
+
+// Protocols a repository can be accessed with.
+const (
+	AccessProtocolHTTP = "http"
+	AccessProtocolSSH  = "ssh"
+)
+
+// EnvAccessLog is the environment variable passing the access log of a push
+// to the hooks, which record it with the references the push updates.
+const EnvAccessLog = "GITEA_ACCESS_LOG"
+
+// RepoAccessLog represents an authenticated git operation (clone, fetch,
+// archive or push) on a private repository.
+type RepoAccessLog struct {
+	ID     int64 `xorm:"pk autoincr"`
+	RepoID int64 `xorm:"INDEX"`
+	UserID int64 `xorm:"INDEX"`
+	User   *User `xorm:"-"`
+	// KeyID is the public or deploy key used to access over SSH,
+	// DeployTokenID the deploy token used to access over HTTP.
+	KeyID         int64
+	DeployTokenID int64
+	Protocol      string
+	Service       string
+	RemoteAddr    string
+	// Refs are the newline separated names of the references updated by a
+	// push, the references fetched are not known.
+	Refs string `xorm:"TEXT"`
+
+	Created     time.Time `xorm:"-"`
+	CreatedUnix int64     `xorm:"INDEX"`
+}
+
+// BeforeInsert will be invoked by XORM before inserting a record
+func (l *RepoAccessLog) BeforeInsert() {
+	l.CreatedUnix = time.Now().Unix()
+}
+
+// AfterSet is invoked from XORM after setting the value of a field of this object.
+func (l *RepoAccessLog) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		l.Created = time.Unix(l.CreatedUnix, 0).Local()
+	}
+}
+
+// IsDeployKey returns true if the access has been made with a deploy key.
+func (l *RepoAccessLog) IsDeployKey() bool {
+	return l.UserID == 0 && l.KeyID > 0
+}
+
+// IsDeployToken returns true if the access has been made with a deploy token.
+func (l *RepoAccessLog) IsDeployToken() bool {
+	return l.UserID == 0 && l.DeployTokenID > 0
+}
+
+// RefNames returns the names of the references updated by a push.
+func (l *RepoAccessLog) RefNames() []string {
+	if len(l.Refs) == 0 {
+		return nil
+	}
+	return strings.Split(l.Refs, "\n")
+}
+
+// EncodeAccessLog returns the value of EnvAccessLog passing l to the hooks of
+// a push, so that they record it with the references the push updates.
+func EncodeAccessLog(l *RepoAccessLog) string {
+	data, _ := json.Marshal(l)
+	return string(data)
+}
+
+// AccessLogFromEnv returns the access log of the push passed to the hooks, or
+// nil if the push is not logged.
+func AccessLogFromEnv(getenv func(string) string) *RepoAccessLog {
+	data := getenv(EnvAccessLog)
+	if len(data) == 0 {
+		return nil
+	}
+	l := new(RepoAccessLog)
+	if err := json.Unmarshal([]byte(data), l); err != nil {
+		log.Error(4, "Unmarshal access log: %v", err)
+		return nil
+	}
+	return l
+}
+
+// LogAccess records given operation on the repository, it does nothing for
+// public repositories or if access logging is disabled.
+func (repo *Repository) LogAccess(l *RepoAccessLog) error {
+	if !repo.IsPrivate || !setting.Repository.EnableAccessLog {
+		return nil
+	}
+
+	l.RepoID = repo.ID
+	if _, err := x.Insert(l); err != nil {
+		return fmt.Errorf("insert access log: %v", err)
+	}
+	return nil
+}
+
+// CountAccessLogs returns the number of recorded operations on the repository.
+func (repo *Repository) CountAccessLogs() (int64, error) {
+	return x.Where("repo_id = ?", repo.ID).Count(new(RepoAccessLog))
+}
+
+// GetAccessLogs returns recorded operations on the repository, newest first.
+func (repo *Repository) GetAccessLogs(page, pageSize int) ([]*RepoAccessLog, error) {
+	if page <= 0 {
+		page = 1
+	}
+	logs := make([]*RepoAccessLog, 0, pageSize)
+	if err := x.
+		Where("repo_id = ?", repo.ID).
+		Desc("created_unix", "id").
+		Limit(pageSize, (page-1)*pageSize).
+		Find(&logs); err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]int64, 0, len(logs))
+	for _, l := range logs {
+		if l.UserID > 0 {
+			userIDs = append(userIDs, l.UserID)
+		}
+	}
+	users := make(map[int64]*User, len(userIDs))
+	if len(userIDs) > 0 {
+		if err := x.In("id", userIDs).Find(&users); err != nil {
+			return nil, fmt.Errorf("find users: %v", err)
+		}
+	}
+	for _, l := range logs {
+		if l.UserID > 0 {
+			l.User = users[l.UserID]
+			if l.User == nil {
+				l.User = NewGhostUser()
+			}
+		}
+	}
+	return logs, nil
+}
+
+// DeleteOldRepoAccessLogs deletes access logs older than configured retention.
+func DeleteOldRepoAccessLogs() {
+	if !taskStatusTable.StartIfNotRunning(accessLogCleanup) {
+		return
+	}
+	defer taskStatusTable.Stop(accessLogCleanup)
+
+	log.Trace("Doing: AccessLogCleanup")
+
+	olderThan := time.Now().Add(-setting.Cron.AccessLogCleanup.OlderThan).Unix()
+	if _, err := x.Where("created_unix < ?", olderThan).Delete(new(RepoAccessLog)); err != nil {
+		log.Error(4, "AccessLogCleanup: %v", err)
+	}
+}
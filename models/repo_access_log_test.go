@@ -0,0 +1,86 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_LogAccess(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	setting.Repository.EnableAccessLog = true
+
+	publicRepo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.NoError(t, publicRepo.LogAccess(&RepoAccessLog{UserID: 2, Protocol: AccessProtocolHTTP}))
+	AssertNotExistsBean(t, &RepoAccessLog{RepoID: 1})
+
+	privateRepo := AssertExistsAndLoadBean(t, &Repository{ID: 2}).(*Repository)
+	assert.NoError(t, privateRepo.LogAccess(&RepoAccessLog{
+		UserID:   2,
+		Protocol: AccessProtocolHTTP,
+		Service:  "git-upload-pack",
+	}))
+	assert.NoError(t, privateRepo.LogAccess(&RepoAccessLog{
+		KeyID:    1,
+		Protocol: AccessProtocolSSH,
+		Service:  "git-upload-pack",
+	}))
+
+	count, err := privateRepo.CountAccessLogs()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+
+	logs, err := privateRepo.GetAccessLogs(1, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, logs, 2) {
+		assert.True(t, logs[0].IsDeployKey())
+		assert.Nil(t, logs[0].User)
+		assert.EqualValues(t, 2, logs[1].User.ID)
+	}
+
+	setting.Repository.EnableAccessLog = false
+	assert.NoError(t, privateRepo.LogAccess(&RepoAccessLog{UserID: 2}))
+	setting.Repository.EnableAccessLog = true
+	count, err = privateRepo.CountAccessLogs()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+}
+
+func TestDeleteOldRepoAccessLogs(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	_, err := x.Insert(&RepoAccessLog{RepoID: 2, UserID: 2})
+	assert.NoError(t, err)
+	_, err = x.Exec("UPDATE repo_access_log SET created_unix = ?", time.Now().Add(-100*24*time.Hour).Unix())
+	assert.NoError(t, err)
+	_, err = x.Insert(&RepoAccessLog{RepoID: 2, UserID: 4})
+	assert.NoError(t, err)
+
+	DeleteOldRepoAccessLogs()
+	AssertNotExistsBean(t, &RepoAccessLog{UserID: 2})
+	AssertExistsAndLoadBean(t, &RepoAccessLog{UserID: 4})
+}
+
+func TestAccessLogFromEnv(t *testing.T) {
+	env := map[string]string{
+		EnvAccessLog: EncodeAccessLog(&RepoAccessLog{RepoID: 2, KeyID: 3, Protocol: AccessProtocolSSH}),
+	}
+	l := AccessLogFromEnv(func(key string) string { return env[key] })
+	if assert.NotNil(t, l) {
+		assert.EqualValues(t, 2, l.RepoID)
+		assert.EqualValues(t, 3, l.KeyID)
+		assert.Equal(t, AccessProtocolSSH, l.Protocol)
+	}
+
+	assert.Nil(t, AccessLogFromEnv(func(string) string { return "" }))
+
+	l = &RepoAccessLog{Refs: "refs/heads/master\nrefs/tags/v1.0"}
+	assert.Equal(t, []string{"refs/heads/master", "refs/tags/v1.0"}, l.RefNames())
+	assert.Nil(t, new(RepoAccessLog).RefNames())
+}
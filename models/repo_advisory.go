@@ -0,0 +1,487 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"code.gitea.io/git"
+
+	"github.com/go-xorm/builder"
+	"github.com/go-xorm/xorm"
+)
+
+// AdvisoryStatus represents the state of a security advisory.
+type AdvisoryStatus int
+
+// Possible states of a security advisory
+const (
+	// AdvisoryStatusDraft advisories are only visible to the maintainers of
+	// the repository and the collaborators of the advisory.
+	AdvisoryStatusDraft AdvisoryStatus = iota
+	AdvisoryStatusPublished
+	AdvisoryStatusClosed
+)
+
+// AdvisorySeverities are the severities a security advisory can have.
+var AdvisorySeverities = []string{"low", "moderate", "high", "critical"}
+
+var cveIDPattern = regexp.MustCompile(`^CVE-\d{4}-\d{4,}$`)
+
+// IsValidAdvisorySeverity returns true if severity is empty or one of
+// AdvisorySeverities.
+func IsValidAdvisorySeverity(severity string) bool {
+	if len(severity) == 0 {
+		return true
+	}
+	for _, s := range AdvisorySeverities {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidCVEID returns true if id is empty or looks like CVE-2017-12345.
+func IsValidCVEID(id string) bool {
+	return len(id) == 0 || cveIDPattern.MatchString(id)
+}
+
+// RepoAdvisory represents a security advisory of a repository. It is drafted
+// privately by the maintainers and the invited collaborators, and becomes
+// visible to everyone who can read the repository once it is published.
+type RepoAdvisory struct {
+	ID       int64       `xorm:"pk autoincr"`
+	RepoID   int64       `xorm:"INDEX UNIQUE(s)"`
+	Repo     *Repository `xorm:"-"`
+	Index    int64       `xorm:"UNIQUE(s)"`
+	PosterID int64
+	Poster   *User `xorm:"-"`
+
+	Title            string `xorm:"NOT NULL"`
+	Description      string `xorm:"TEXT"`
+	Severity         string
+	CVEID            string `xorm:"'cve_id'"`
+	AffectedVersions string
+	PatchedVersions  string
+
+	Status AdvisoryStatus `xorm:"INDEX NOT NULL DEFAULT 0"`
+	// ForkID is the temporary private fork the fix is prepared in.
+	ForkID int64
+	Fork   *Repository `xorm:"-"`
+
+	Created       time.Time `xorm:"-"`
+	CreatedUnix   int64     `xorm:"INDEX created"`
+	Updated       time.Time `xorm:"-"`
+	UpdatedUnix   int64     `xorm:"updated"`
+	Published     time.Time `xorm:"-"`
+	PublishedUnix int64
+}
+
+// AfterSet is invoked from XORM after setting the value of a field of this object.
+func (a *RepoAdvisory) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		a.Created = time.Unix(a.CreatedUnix, 0).Local()
+	case "updated_unix":
+		a.Updated = time.Unix(a.UpdatedUnix, 0).Local()
+	case "published_unix":
+		a.Published = time.Unix(a.PublishedUnix, 0).Local()
+	}
+}
+
+// IsDraft returns true if the advisory has not been published or closed yet.
+func (a *RepoAdvisory) IsDraft() bool {
+	return a.Status == AdvisoryStatusDraft
+}
+
+// IsPublished returns true if the advisory has been published.
+func (a *RepoAdvisory) IsPublished() bool {
+	return a.Status == AdvisoryStatusPublished
+}
+
+// IsClosed returns true if the advisory has been closed without publishing.
+func (a *RepoAdvisory) IsClosed() bool {
+	return a.Status == AdvisoryStatusClosed
+}
+
+// LoadAttributes loads the repository, poster and fork of the advisory.
+func (a *RepoAdvisory) LoadAttributes() (err error) {
+	if a.Repo == nil {
+		if a.Repo, err = GetRepositoryByID(a.RepoID); err != nil {
+			return fmt.Errorf("GetRepositoryByID [%d]: %v", a.RepoID, err)
+		}
+	}
+	if a.Poster == nil {
+		a.Poster, err = GetUserByID(a.PosterID)
+		if IsErrUserNotExist(err) {
+			a.PosterID = -1
+			a.Poster = NewGhostUser()
+		} else if err != nil {
+			return fmt.Errorf("GetUserByID [%d]: %v", a.PosterID, err)
+		}
+	}
+	if a.Fork == nil && a.ForkID > 0 {
+		a.Fork, err = GetRepositoryByID(a.ForkID)
+		if IsErrRepoNotExist(err) {
+			a.ForkID = 0
+		} else if err != nil {
+			return fmt.Errorf("GetRepositoryByID [%d]: %v", a.ForkID, err)
+		}
+	}
+	return nil
+}
+
+// NewRepoAdvisory creates a new draft security advisory.
+func NewRepoAdvisory(a *RepoAdvisory) (err error) {
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	count, err := sess.Count(&RepoAdvisory{RepoID: a.RepoID})
+	if err != nil {
+		return err
+	}
+	a.Index = count + 1
+	a.Status = AdvisoryStatusDraft
+	if _, err = sess.Insert(a); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// GetRepoAdvisoryByIndex returns the security advisory of given index in a repository.
+func GetRepoAdvisoryByIndex(repoID, index int64) (*RepoAdvisory, error) {
+	a := new(RepoAdvisory)
+	has, err := x.
+		Where("repo_id = ? AND `index` = ?", repoID, index).
+		Get(a)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRepoAdvisoryNotExist{repoID, index}
+	}
+	return a, nil
+}
+
+// GetRepoAdvisories returns the security advisories of a repository doer can
+// see. Maintainers see all of them, everyone else the published ones and the
+// drafts they collaborate on.
+func GetRepoAdvisories(repoID int64, doer *User, isMaintainer bool) ([]*RepoAdvisory, error) {
+	cond := builder.NewCond().And(builder.Eq{"repo_id": repoID})
+	if !isMaintainer {
+		visible := builder.NewCond().Or(builder.Eq{"status": AdvisoryStatusPublished})
+		if doer != nil {
+			visible = visible.Or(builder.In("id",
+				builder.Select("advisory_id").From("repo_advisory_collaborator").Where(builder.Eq{"user_id": doer.ID})))
+		}
+		cond = cond.And(visible)
+	}
+
+	advisories := make([]*RepoAdvisory, 0, 5)
+	return advisories, x.Where(cond).Desc("`index`").Find(&advisories)
+}
+
+// UpdateRepoAdvisory updates the description and metadata of an advisory.
+func UpdateRepoAdvisory(a *RepoAdvisory) error {
+	_, err := x.Id(a.ID).
+		Cols("title", "description", "severity", "cve_id", "affected_versions", "patched_versions").
+		Update(a)
+	return err
+}
+
+// Publish makes the advisory visible to everyone who can read the repository
+// and deletes its temporary fork. It is refused while the fork has branches
+// which are not merged into the repository yet.
+func (a *RepoAdvisory) Publish() error {
+	if !a.IsDraft() {
+		return nil
+	}
+	if branches, err := a.unmergedForkBranches(); err != nil {
+		return err
+	} else if len(branches) > 0 {
+		return ErrRepoAdvisoryUnmerged{a.RepoID, a.Index, branches}
+	}
+	a.Status = AdvisoryStatusPublished
+	a.PublishedUnix = time.Now().Unix()
+	a.Published = time.Unix(a.PublishedUnix, 0).Local()
+	if _, err := x.Id(a.ID).Cols("status", "published_unix").Update(a); err != nil {
+		return err
+	}
+	return a.deleteFork()
+}
+
+// Close closes the draft advisory without publishing it, for example because
+// the report turned out not to be a vulnerability. Its fork is deleted.
+func (a *RepoAdvisory) Close() error {
+	if !a.IsDraft() {
+		return nil
+	}
+	a.Status = AdvisoryStatusClosed
+	if _, err := x.Id(a.ID).Cols("status").Update(a); err != nil {
+		return err
+	}
+	return a.deleteFork()
+}
+
+// advisoryForkName returns the name of the temporary fork of an advisory.
+func (a *RepoAdvisory) advisoryForkName() string {
+	return fmt.Sprintf("%s-advisory-%d", a.Repo.Name, a.Index)
+}
+
+// CreateFork creates the temporary private fork of the repository the fix of
+// the advisory is prepared in. The fork is owned by the owner of the
+// repository and its collaborators are the ones of the advisory.
+func (a *RepoAdvisory) CreateFork() error {
+	if !a.IsDraft() {
+		return fmt.Errorf("advisory %d is not a draft", a.ID)
+	} else if err := a.LoadAttributes(); err != nil {
+		return err
+	} else if a.ForkID > 0 {
+		return ErrRepoAlreadyExist{a.Repo.MustOwner().Name, a.Fork.Name}
+	}
+
+	owner := a.Repo.MustOwner()
+	name := a.advisoryForkName()
+	if has, err := IsRepositoryExist(owner, name); err != nil {
+		return err
+	} else if has {
+		return ErrRepoAlreadyExist{owner.Name, name}
+	}
+
+	fork, err := forkRepository(owner, a.Repo, name,
+		fmt.Sprintf("Temporary private fork for security advisory #%d", a.Index), true)
+	if err != nil {
+		return fmt.Errorf("forkRepository: %v", err)
+	}
+	a.ForkID = fork.ID
+	a.Fork = fork
+	if _, err = x.Id(a.ID).Cols("fork_id").Update(a); err != nil {
+		return err
+	}
+
+	collaborators, err := a.GetCollaborators()
+	if err != nil {
+		return err
+	}
+	for _, u := range collaborators {
+		if err = fork.AddCollaborator(u); err != nil {
+			return fmt.Errorf("AddCollaborator: %v", err)
+		}
+	}
+	return nil
+}
+
+// unmergedForkBranches returns the branches of the temporary fork with commits
+// which are not merged into the repository yet. A branch is merged when its
+// head commit is on a branch of the repository, or is the head of a pull
+// request merged into the repository, for example squashed or rebased.
+func (a *RepoAdvisory) unmergedForkBranches() ([]string, error) {
+	if err := a.LoadAttributes(); err != nil {
+		return nil, err
+	} else if a.Fork == nil {
+		return nil, nil
+	}
+
+	branches, err := a.Fork.GetBranches()
+	if err != nil {
+		return nil, fmt.Errorf("GetBranches: %v", err)
+	}
+	repoPath := a.Repo.RepoPath()
+	var unmerged []string
+	for _, branch := range branches {
+		commit, err := branch.GetCommit()
+		if err != nil {
+			return nil, fmt.Errorf("GetCommit [%s]: %v", branch.Name, err)
+		}
+		commitID := commit.ID.String()
+
+		// The commit is not known to the repository if it is on none of its
+		// branches nor pull requests.
+		if _, err = git.NewCommand("cat-file", "-e", commitID+"^{commit}").RunInDir(repoPath); err != nil {
+			unmerged = append(unmerged, branch.Name)
+			continue
+		}
+		stdout, err := git.NewCommand("branch", "--contains", commitID).RunInDir(repoPath)
+		if err != nil {
+			return nil, fmt.Errorf("git branch --contains: %v", err)
+		} else if len(strings.TrimSpace(stdout)) > 0 {
+			continue
+		}
+
+		prs := make([]*PullRequest, 0, 2)
+		if err = x.
+			Where("head_repo_id = ? AND head_branch = ? AND base_repo_id = ? AND has_merged = ?",
+				a.ForkID, branch.Name, a.RepoID, true).
+			Find(&prs); err != nil {
+			return nil, err
+		}
+		merged := false
+		for _, pr := range prs {
+			headID, err := git.NewCommand("rev-parse", fmt.Sprintf("refs/pull/%d/head", pr.Index)).RunInDir(repoPath)
+			if err == nil && strings.TrimSpace(headID) == commitID {
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			unmerged = append(unmerged, branch.Name)
+		}
+	}
+	return unmerged, nil
+}
+
+func (a *RepoAdvisory) deleteFork() error {
+	if a.ForkID == 0 {
+		return nil
+	}
+	fork, err := GetRepositoryByID(a.ForkID)
+	if err != nil && !IsErrRepoNotExist(err) {
+		return err
+	} else if err == nil {
+		if err = DeleteRepository(fork.OwnerID, fork.ID); err != nil {
+			return fmt.Errorf("DeleteRepository: %v", err)
+		}
+	}
+
+	a.ForkID = 0
+	a.Fork = nil
+	_, err = x.Id(a.ID).Cols("fork_id").Update(a)
+	return err
+}
+
+// RepoAdvisoryCollaborator represents a user invited to collaborate on a draft
+// advisory, for example its reporter.
+type RepoAdvisoryCollaborator struct {
+	ID         int64 `xorm:"pk autoincr"`
+	AdvisoryID int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	UserID     int64 `xorm:"UNIQUE(s) INDEX NOT NULL"`
+}
+
+// IsCollaborator returns true if the user collaborates on the advisory.
+func (a *RepoAdvisory) IsCollaborator(userID int64) (bool, error) {
+	return x.Get(&RepoAdvisoryCollaborator{AdvisoryID: a.ID, UserID: userID})
+}
+
+// GetCollaborators returns the users collaborating on the advisory.
+func (a *RepoAdvisory) GetCollaborators() ([]*User, error) {
+	users := make([]*User, 0, 5)
+	return users, x.
+		Join("INNER", "repo_advisory_collaborator", "repo_advisory_collaborator.user_id = `user`.id").
+		Where("repo_advisory_collaborator.advisory_id = ?", a.ID).
+		Asc("`user`.name").
+		Find(&users)
+}
+
+// AddCollaborator invites a user to collaborate on the advisory, the user
+// becomes a collaborator of the temporary fork as well.
+func (a *RepoAdvisory) AddCollaborator(u *User) error {
+	if has, err := a.IsCollaborator(u.ID); err != nil {
+		return err
+	} else if has {
+		return nil
+	}
+	if _, err := x.Insert(&RepoAdvisoryCollaborator{AdvisoryID: a.ID, UserID: u.ID}); err != nil {
+		return err
+	}
+
+	if err := a.LoadAttributes(); err != nil {
+		return err
+	} else if a.Fork != nil {
+		return a.Fork.AddCollaborator(u)
+	}
+	return nil
+}
+
+// DeleteCollaborator removes a user from the collaborators of the advisory
+// and of its temporary fork.
+func (a *RepoAdvisory) DeleteCollaborator(userID int64) error {
+	if _, err := x.Delete(&RepoAdvisoryCollaborator{AdvisoryID: a.ID, UserID: userID}); err != nil {
+		return err
+	}
+
+	if err := a.LoadAttributes(); err != nil {
+		return err
+	} else if a.Fork != nil {
+		return a.Fork.DeleteCollaboration(userID)
+	}
+	return nil
+}
+
+// RepoAdvisoryComment represents a comment in the private discussion of an advisory.
+type RepoAdvisoryComment struct {
+	ID         int64 `xorm:"pk autoincr"`
+	AdvisoryID int64 `xorm:"INDEX NOT NULL"`
+	PosterID   int64
+	Poster     *User  `xorm:"-"`
+	Content    string `xorm:"TEXT"`
+
+	Created     time.Time `xorm:"-"`
+	CreatedUnix int64     `xorm:"INDEX created"`
+}
+
+// AfterSet is invoked from XORM after setting the value of a field of this object.
+func (c *RepoAdvisoryComment) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		c.Created = time.Unix(c.CreatedUnix, 0).Local()
+	}
+}
+
+// CreateComment adds a comment to the discussion of the advisory.
+func (a *RepoAdvisory) CreateComment(doer *User, content string) (*RepoAdvisoryComment, error) {
+	c := &RepoAdvisoryComment{
+		AdvisoryID: a.ID,
+		PosterID:   doer.ID,
+		Poster:     doer,
+		Content:    content,
+	}
+	if _, err := x.Insert(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetComments returns the comments of the advisory with their posters.
+func (a *RepoAdvisory) GetComments() ([]*RepoAdvisoryComment, error) {
+	comments := make([]*RepoAdvisoryComment, 0, 10)
+	if err := x.Where("advisory_id = ?", a.ID).Asc("created_unix").Find(&comments); err != nil {
+		return nil, err
+	}
+	for _, c := range comments {
+		poster, err := GetUserByID(c.PosterID)
+		if IsErrUserNotExist(err) {
+			poster = NewGhostUser()
+		} else if err != nil {
+			return nil, err
+		}
+		c.Poster = poster
+	}
+	return comments, nil
+}
+
+// deleteRepoAdvisories deletes the advisories of a repository with their
+// collaborators and comments, their forks are kept as regular repositories.
+func deleteRepoAdvisories(e Engine, repoID int64) error {
+	advisoryIDs := make([]int64, 0, 5)
+	if err := e.Table("repo_advisory").Cols("id").Where("repo_id = ?", repoID).Find(&advisoryIDs); err != nil {
+		return err
+	} else if len(advisoryIDs) == 0 {
+		return nil
+	}
+
+	if _, err := e.In("advisory_id", advisoryIDs).Delete(new(RepoAdvisoryCollaborator)); err != nil {
+		return err
+	} else if _, err = e.In("advisory_id", advisoryIDs).Delete(new(RepoAdvisoryComment)); err != nil {
+		return err
+	}
+	_, err := e.Where("repo_id = ?", repoID).Delete(new(RepoAdvisory))
+	return err
+}
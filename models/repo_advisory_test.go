@@ -0,0 +1,128 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidCVEID(t *testing.T) {
+	assert.True(t, IsValidCVEID(""))
+	assert.True(t, IsValidCVEID("CVE-2017-1234"))
+	assert.True(t, IsValidCVEID("CVE-2017-1234567"))
+	assert.False(t, IsValidCVEID("CVE-17-1234"))
+	assert.False(t, IsValidCVEID("cve-2017-1234"))
+}
+
+func TestNewRepoAdvisory(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	for i := int64(1); i <= 2; i++ {
+		advisory := &RepoAdvisory{RepoID: 1, PosterID: 2, Title: "advisory"}
+		assert.NoError(t, NewRepoAdvisory(advisory))
+		assert.EqualValues(t, i, advisory.Index)
+		assert.True(t, advisory.IsDraft())
+	}
+	AssertExistsAndLoadBean(t, &RepoAdvisory{RepoID: 1, Index: 2})
+}
+
+func TestGetRepoAdvisories(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	draft := &RepoAdvisory{RepoID: 1, PosterID: 2, Title: "draft"}
+	assert.NoError(t, NewRepoAdvisory(draft))
+	published := &RepoAdvisory{RepoID: 1, PosterID: 2, Title: "published"}
+	assert.NoError(t, NewRepoAdvisory(published))
+	assert.NoError(t, published.Publish())
+
+	reporter := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	assert.NoError(t, draft.AddCollaborator(reporter))
+	collaborators, err := draft.GetCollaborators()
+	assert.NoError(t, err)
+	if assert.Len(t, collaborators, 1) {
+		assert.EqualValues(t, reporter.ID, collaborators[0].ID)
+	}
+
+	for _, test := range []struct {
+		doer         *User
+		isMaintainer bool
+		expected     int
+	}{
+		{nil, false, 1},
+		{AssertExistsAndLoadBean(t, &User{ID: 5}).(*User), false, 1},
+		{reporter, false, 2},
+		{AssertExistsAndLoadBean(t, &User{ID: 2}).(*User), true, 2},
+	} {
+		advisories, err := GetRepoAdvisories(1, test.doer, test.isMaintainer)
+		assert.NoError(t, err)
+		assert.Len(t, advisories, test.expected)
+	}
+
+	assert.NoError(t, draft.DeleteCollaborator(reporter.ID))
+	advisories, err := GetRepoAdvisories(1, reporter, false)
+	assert.NoError(t, err)
+	assert.Len(t, advisories, 1)
+}
+
+func TestRepoAdvisory_Publish_Unmerged(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	root, err := ioutil.TempDir("", "advisory-repos")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+	oldRoot := setting.RepoRootPath
+	setting.RepoRootPath = root
+	defer func() { setting.RepoRootPath = oldRoot }()
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	fork := AssertExistsAndLoadBean(t, &Repository{ID: 2}).(*Repository)
+	createTestGitRepo(t, repo.RepoPath(), map[string]string{"README.md": "readme"})
+	git := func(dir string, args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	git(root, "clone", "-q", "--bare", repo.RepoPath(), fork.RepoPath())
+	commit := func(branch string) {
+		id := git(fork.RepoPath(), "-c", "user.name=test", "-c", "user.email=test@example.com",
+			"commit-tree", "master^{tree}", "-p", "master", "-m", branch)
+		git(fork.RepoPath(), "update-ref", "refs/heads/"+branch, id)
+	}
+
+	advisory := &RepoAdvisory{RepoID: repo.ID, PosterID: 2, Title: "advisory"}
+	assert.NoError(t, NewRepoAdvisory(advisory))
+	advisory.ForkID = fork.ID
+	branches, err := advisory.unmergedForkBranches()
+	assert.NoError(t, err)
+	assert.Empty(t, branches)
+
+	commit("fix")
+	commit("squashed")
+	err = advisory.Publish()
+	assert.True(t, IsErrRepoAdvisoryUnmerged(err))
+	assert.Equal(t, []string{"fix", "squashed"}, err.(ErrRepoAdvisoryUnmerged).Branches)
+	AssertExistsAndLoadBean(t, &RepoAdvisory{ID: advisory.ID, Status: AdvisoryStatusDraft})
+
+	// Merged into a branch of the repository.
+	git(fork.RepoPath(), "push", "-q", repo.RepoPath(), "fix:master")
+	// Squashed from a merged pull request.
+	git(fork.RepoPath(), "push", "-q", repo.RepoPath(), "squashed:refs/pull/100/head")
+	_, err = x.Insert(&PullRequest{HeadRepoID: fork.ID, HeadBranch: "squashed", BaseRepoID: repo.ID, Index: 100, HasMerged: true})
+	assert.NoError(t, err)
+
+	branches, err = advisory.unmergedForkBranches()
+	assert.NoError(t, err)
+	assert.Empty(t, branches)
+}
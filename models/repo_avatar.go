@@ -0,0 +1,100 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"image"
+	"path/filepath"
+	"strings"
+
+	"github.com/Unknwon/com"
+
+	"code.gitea.io/gitea/modules/avatar"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// CustomAvatarPath returns the path of the uploaded avatar of the repository.
+func (repo *Repository) CustomAvatarPath() string {
+	return filepath.Join(setting.RepositoryAvatarUploadPath, repo.Avatar)
+}
+
+// hasCustomAvatar returns true if an avatar has been uploaded for the
+// repository.
+func (repo *Repository) hasCustomAvatar() bool {
+	return len(repo.Avatar) > 0 && com.IsFile(repo.CustomAvatarPath())
+}
+
+// RelAvatarLink returns the relative link of the avatar of the repository, or
+// of the avatar of its owner if none has been uploaded.
+func (repo *Repository) RelAvatarLink() string {
+	if repo.hasCustomAvatar() {
+		return setting.AppSubURL + "/repo-avatars/" + repo.Avatar
+	}
+	return repo.MustOwner().RelAvatarLink()
+}
+
+// SizedRelAvatarLink returns the relative avatar link of given size in pixels.
+func (repo *Repository) SizedRelAvatarLink(size int) string {
+	if repo.hasCustomAvatar() {
+		return repo.RelAvatarLink() + "?size=" + com.ToStr(size)
+	}
+	return repo.MustOwner().SizedRelAvatarLink(size)
+}
+
+// AvatarLink returns the absolute link of the avatar of the repository.
+func (repo *Repository) AvatarLink() string {
+	link := repo.RelAvatarLink()
+	if link[0] == '/' && link[1] != '/' {
+		return setting.AppURL + strings.TrimPrefix(link, setting.AppSubURL)[1:]
+	}
+	return link
+}
+
+// UploadAvatar saves the image of data as the avatar of the repository.
+func (repo *Repository) UploadAvatar(data []byte) error {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("Decode: %v", err)
+	}
+
+	// Repositories with the same image must not share the files, as they are
+	// removed with the avatar.
+	oldAvatarPath := repo.CustomAvatarPath()
+	hadAvatar := len(repo.Avatar) > 0
+	repo.Avatar = fmt.Sprintf("%d-%x", repo.ID, md5.Sum(data))
+	if err = avatar.Save(repo.CustomAvatarPath(), img); err != nil {
+		return fmt.Errorf("Save: %v", err)
+	}
+	if _, err = x.Id(repo.ID).Cols("avatar").Update(repo); err != nil {
+		return fmt.Errorf("update avatar: %v", err)
+	}
+
+	if hadAvatar && oldAvatarPath != repo.CustomAvatarPath() {
+		if err = avatar.Remove(oldAvatarPath); err != nil {
+			log.Error(4, "Failed to remove %s: %v", oldAvatarPath, err)
+		}
+	}
+	return nil
+}
+
+// DeleteAvatar deletes the uploaded avatar of the repository.
+func (repo *Repository) DeleteAvatar() error {
+	log.Trace("DeleteAvatar[%d]: %s", repo.ID, repo.CustomAvatarPath())
+	if len(repo.Avatar) > 0 {
+		if err := avatar.Remove(repo.CustomAvatarPath()); err != nil {
+			return fmt.Errorf("Failed to remove %s: %v", repo.CustomAvatarPath(), err)
+		}
+	}
+
+	repo.Avatar = ""
+	if _, err := x.Id(repo.ID).Cols("avatar").Update(repo); err != nil {
+		return fmt.Errorf("update avatar: %v", err)
+	}
+	return nil
+}
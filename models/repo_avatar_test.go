@@ -0,0 +1,50 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Unknwon/com"
+	"github.com/stretchr/testify/assert"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+func TestRepository_UploadAvatar(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	tmpDir, err := ioutil.TempDir("", "repo-avatars")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	defer func(p string) {
+		setting.RepositoryAvatarUploadPath = p
+	}(setting.RepositoryAvatarUploadPath)
+	setting.RepositoryAvatarUploadPath = tmpDir
+
+	// Without an uploaded avatar, the one of the owner is used.
+	assert.Equal(t, repo.MustOwner().RelAvatarLink(), repo.RelAvatarLink())
+
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 16, 16))))
+	assert.NoError(t, repo.UploadAvatar(buf.Bytes()))
+	assert.True(t, strings.HasPrefix(repo.Avatar, "1-"))
+	assert.True(t, com.IsFile(repo.CustomAvatarPath()))
+	assert.Equal(t, setting.AppSubURL+"/repo-avatars/"+repo.Avatar, repo.RelAvatarLink())
+	AssertExistsAndLoadBean(t, &Repository{ID: 1, Avatar: repo.Avatar})
+
+	avatarPath := repo.CustomAvatarPath()
+	assert.NoError(t, repo.DeleteAvatar())
+	assert.False(t, com.IsFile(avatarPath))
+	assert.Empty(t, repo.Avatar)
+	assert.Equal(t, repo.MustOwner().RelAvatarLink(), repo.RelAvatarLink())
+}
@@ -5,7 +5,11 @@
 package models
 
 import (
+	"fmt"
+
 	"code.gitea.io/git"
+
+	"code.gitea.io/gitea/modules/log"
 )
 
 // Branch holds the branch information
@@ -60,3 +64,113 @@ func (branch *Branch) GetCommit() (*git.Commit, error) {
 	}
 	return gitRepo.GetBranchCommit(branch.Name)
 }
+
+// RenamedBranch represents the old name of a renamed branch, the links to the
+// old name are redirected to the new one and the pushes to it are told about
+// the new name.
+type RenamedBranch struct {
+	ID          int64  `xorm:"pk autoincr"`
+	RepoID      int64  `xorm:"INDEX NOT NULL"`
+	OldName     string `xorm:"NOT NULL"`
+	NewName     string `xorm:"NOT NULL"`
+	CreatedUnix int64  `xorm:"created"`
+}
+
+// GetRenamedBranch returns the renamed branch of given old name, or nil if no
+// branch of the repository was renamed from it.
+func GetRenamedBranch(repoID int64, oldName string) (*RenamedBranch, error) {
+	b := &RenamedBranch{RepoID: repoID, OldName: oldName}
+	has, err := x.Get(b)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return b, nil
+}
+
+// RenameBranch renames a branch of the repository, the default branch, the
+// protection of the branch and the pull requests which are not merged yet
+// follow. If redirect is true, the old name is remembered as a RenamedBranch.
+func (repo *Repository) RenameBranch(oldName, newName string, redirect bool) (err error) {
+	repoPath := repo.RepoPath()
+	if !git.IsBranchExist(repoPath, oldName) {
+		return ErrBranchNotExist{oldName}
+	} else if git.IsBranchExist(repoPath, newName) {
+		return ErrBranchAlreadyExist{newName}
+	} else if _, err = git.NewCommand("check-ref-format", "--branch", newName).RunInDir(repoPath); err != nil {
+		return ErrInvalidBranchName{newName}
+	}
+
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if repo.DefaultBranch == oldName {
+		repo.DefaultBranch = newName
+		if _, err = sess.ID(repo.ID).Cols("default_branch").Update(repo); err != nil {
+			return fmt.Errorf("update default branch: %v", err)
+		}
+	}
+
+	if _, err = sess.Delete(&ProtectedBranch{RepoID: repo.ID, BranchName: newName}); err != nil {
+		return fmt.Errorf("delete protected branch: %v", err)
+	} else if _, err = sess.
+		Where("repo_id = ? AND branch_name = ?", repo.ID, oldName).
+		Cols("branch_name").
+		Update(&ProtectedBranch{BranchName: newName}); err != nil {
+		return fmt.Errorf("update protected branch: %v", err)
+	}
+
+	if _, err = sess.
+		Where("base_repo_id = ? AND base_branch = ? AND has_merged = ?", repo.ID, oldName, false).
+		Cols("base_branch").
+		Update(&PullRequest{BaseBranch: newName}); err != nil {
+		return fmt.Errorf("update pull request base branch: %v", err)
+	} else if _, err = sess.
+		Where("head_repo_id = ? AND head_branch = ? AND has_merged = ?", repo.ID, oldName, false).
+		Cols("head_branch").
+		Update(&PullRequest{HeadBranch: newName}); err != nil {
+		return fmt.Errorf("update pull request head branch: %v", err)
+	}
+
+	// Older redirects follow the branch to its new name.
+	if _, err = sess.
+		Where("repo_id = ? AND (old_name = ? OR old_name = ?)", repo.ID, oldName, newName).
+		Delete(new(RenamedBranch)); err != nil {
+		return fmt.Errorf("delete renamed branch: %v", err)
+	} else if _, err = sess.
+		Where("repo_id = ? AND new_name = ?", repo.ID, oldName).
+		Cols("new_name").
+		Update(&RenamedBranch{NewName: newName}); err != nil {
+		return fmt.Errorf("update renamed branch: %v", err)
+	}
+	if redirect {
+		if _, err = sess.Insert(&RenamedBranch{
+			RepoID:  repo.ID,
+			OldName: oldName,
+			NewName: newName,
+		}); err != nil {
+			return fmt.Errorf("insert renamed branch: %v", err)
+		}
+	}
+
+	if _, err = git.NewCommand("branch", "-m", oldName, newName).RunInDir(repoPath); err != nil {
+		return fmt.Errorf("git branch -m: %v", err)
+	}
+	if repo.DefaultBranch == newName {
+		if _, err = git.NewCommand("symbolic-ref", "HEAD", git.BranchPrefix+newName).RunInDir(repoPath); err != nil {
+			return fmt.Errorf("git symbolic-ref: %v", err)
+		}
+	}
+
+	if err = sess.Commit(); err != nil {
+		if _, errRename := git.NewCommand("branch", "-m", newName, oldName).RunInDir(repoPath); errRename != nil {
+			log.Error(4, "RenameBranch: failed to rename %s back to %s: %v", newName, oldName, errRename)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"code.gitea.io/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_RenameBranch(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	repo.DefaultBranch = "master"
+
+	tmpDir, err := ioutil.TempDir("", "rename-branch")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	defer os.RemoveAll(repo.RepoPath())
+	for _, args := range [][]string{
+		{"init"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "initial"},
+		{"branch", "-M", "master"},
+		{"branch", "develop"},
+		{"clone", "--bare", ".", repo.RepoPath()},
+	} {
+		_, err = git.NewCommand(args...).RunInDir(tmpDir)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, repo.AddProtectedBranch("master", false))
+
+	assert.True(t, IsErrBranchNotExist(repo.RenameBranch("unknown", "main", true)))
+	assert.True(t, IsErrBranchAlreadyExist(repo.RenameBranch("master", "develop", true)))
+	assert.True(t, IsErrInvalidBranchName(repo.RenameBranch("master", "a..b", true)))
+
+	assert.NoError(t, repo.RenameBranch("master", "main", true))
+	assert.True(t, git.IsBranchExist(repo.RepoPath(), "main"))
+	assert.False(t, git.IsBranchExist(repo.RepoPath(), "master"))
+	AssertExistsAndLoadBean(t, &Repository{ID: 1, DefaultBranch: "main"})
+	AssertExistsAndLoadBean(t, &ProtectedBranch{RepoID: 1, BranchName: "main"})
+	AssertExistsAndLoadBean(t, &PullRequest{ID: 2, BaseBranch: "main"})
+	AssertExistsAndLoadBean(t, &PullRequest{ID: 1, BaseBranch: "master"})
+
+	renamed, err := GetRenamedBranch(1, "master")
+	assert.NoError(t, err)
+	if assert.NotNil(t, renamed) {
+		assert.Equal(t, "main", renamed.NewName)
+	}
+
+	// The old redirects follow the branch.
+	assert.NoError(t, repo.RenameBranch("main", "trunk", false))
+	renamed, err = GetRenamedBranch(1, "master")
+	assert.NoError(t, err)
+	if assert.NotNil(t, renamed) {
+		assert.Equal(t, "trunk", renamed.NewName)
+	}
+	renamed, err = GetRenamedBranch(1, "main")
+	assert.NoError(t, err)
+	assert.Nil(t, renamed)
+}
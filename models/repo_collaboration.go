@@ -8,12 +8,37 @@ import (
 	"fmt"
 )
 
+// CollaborationUnitTypes contains the unit types access of collaborators can be restricted to
+var CollaborationUnitTypes = []UnitType{
+	UnitTypeCode,
+	UnitTypeIssues,
+	UnitTypePullRequests,
+	UnitTypeReleases,
+	UnitTypeWiki,
+}
+
 // Collaboration represent the relation between an individual and a repository.
 type Collaboration struct {
 	ID     int64      `xorm:"pk autoincr"`
 	RepoID int64      `xorm:"UNIQUE(s) INDEX NOT NULL"`
 	UserID int64      `xorm:"UNIQUE(s) INDEX NOT NULL"`
-	Mode   AccessMode `xorm:"DEFAULT 2 NOT NULL"`
+	Mode   AccessMode `xorm:"DEFAULT 3 NOT NULL"`
+
+	// Units the mode applies to, other units are read only; empty means all the unit types
+	UnitTypes []UnitType `xorm:"json"`
+}
+
+// EnableUnit returns true if the access mode of the collaboration applies to unit type tp
+func (c *Collaboration) EnableUnit(tp UnitType) bool {
+	if len(c.UnitTypes) == 0 {
+		return true
+	}
+	for _, u := range c.UnitTypes {
+		if u == tp {
+			return true
+		}
+	}
+	return false
 }
 
 // ModeI18nKey returns the collaboration mode I18n Key
@@ -21,6 +46,8 @@ func (c *Collaboration) ModeI18nKey() string {
 	switch c.Mode {
 	case AccessModeRead:
 		return "repo.settings.collaboration.read"
+	case AccessModeTriage:
+		return "repo.settings.collaboration.triage"
 	case AccessModeWrite:
 		return "repo.settings.collaboration.write"
 	case AccessModeAdmin:
@@ -153,6 +180,41 @@ func (repo *Repository) ChangeCollaborationAccessMode(uid int64, mode AccessMode
 	return sess.Commit()
 }
 
+// ChangeCollaborationUnits restricts the access mode of the collaboration to given
+// unit types, other units of the repository are read only for the collaborator.
+// The restriction is lifted if no or all collaboration unit types are given.
+func (repo *Repository) ChangeCollaborationUnits(uid int64, unitTypes []UnitType) error {
+	collaboration := &Collaboration{
+		RepoID: repo.ID,
+		UserID: uid,
+	}
+	has, err := x.Get(collaboration)
+	if err != nil {
+		return fmt.Errorf("get collaboration: %v", err)
+	} else if !has {
+		return nil
+	}
+
+	collaboration.UnitTypes = make([]UnitType, 0, len(unitTypes))
+	for _, tp := range CollaborationUnitTypes {
+		for _, u := range unitTypes {
+			if u == tp {
+				collaboration.UnitTypes = append(collaboration.UnitTypes, tp)
+				break
+			}
+		}
+	}
+	if len(collaboration.UnitTypes) == len(CollaborationUnitTypes) {
+		collaboration.UnitTypes = nil
+	}
+
+	_, err = x.
+		Id(collaboration.ID).
+		Cols("unit_types").
+		Update(collaboration)
+	return err
+}
+
 // DeleteCollaboration removes collaboration relation between the user and repository.
 func (repo *Repository) DeleteCollaboration(uid int64) (err error) {
 	collaboration := &Collaboration{
@@ -90,6 +90,24 @@ func TestRepository_ChangeCollaborationAccessMode(t *testing.T) {
 	CheckConsistencyFor(t, &Repository{ID: repo.ID})
 }
 
+func TestRepository_ChangeCollaborationUnits(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 4}).(*Repository)
+	assert.NoError(t, repo.ChangeCollaborationUnits(4, []UnitType{UnitTypeSettings, UnitTypePullRequests, UnitTypeIssues}))
+
+	collaboration := AssertExistsAndLoadBean(t, &Collaboration{RepoID: repo.ID, UserID: 4}).(*Collaboration)
+	assert.Equal(t, []UnitType{UnitTypeIssues, UnitTypePullRequests}, collaboration.UnitTypes)
+	assert.True(t, collaboration.EnableUnit(UnitTypeIssues))
+	assert.False(t, collaboration.EnableUnit(UnitTypeCode))
+
+	assert.NoError(t, repo.ChangeCollaborationUnits(4, CollaborationUnitTypes))
+	collaboration = AssertExistsAndLoadBean(t, &Collaboration{RepoID: repo.ID, UserID: 4}).(*Collaboration)
+	assert.Empty(t, collaboration.UnitTypes)
+
+	assert.NoError(t, repo.ChangeCollaborationUnits(NonexistentID, CollaborationUnitTypes))
+}
+
 func TestRepository_DeleteCollaboration(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 
@@ -0,0 +1,129 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+
+	"code.gitea.io/git"
+)
+
+// CommunityHealthRepoName is the name of the repository whose community
+// health files are inherited by the repositories of its owner lacking their
+// own.
+const CommunityHealthRepoName = ".gitea"
+
+var (
+	// ContributingCandidates are the paths of contributing guidelines
+	ContributingCandidates = []string{
+		"CONTRIBUTING.md",
+		"contributing.md",
+		".gitea/CONTRIBUTING.md",
+		".github/CONTRIBUTING.md",
+		"docs/CONTRIBUTING.md",
+	}
+	// CodeOfConductCandidates are the paths of codes of conduct
+	CodeOfConductCandidates = []string{
+		"CODE_OF_CONDUCT.md",
+		"code_of_conduct.md",
+		".gitea/CODE_OF_CONDUCT.md",
+		".github/CODE_OF_CONDUCT.md",
+		"docs/CODE_OF_CONDUCT.md",
+	}
+)
+
+// CommunityFile is a community health file on the default branch of a
+// repository.
+type CommunityFile struct {
+	Repo *Repository
+	Path string
+	Blob *git.Blob
+}
+
+// Link returns the relative link to the file.
+func (f *CommunityFile) Link() string {
+	return f.Repo.Link() + "/src/" + f.Repo.DefaultBranch + "/" + f.Path
+}
+
+// Content returns the content of the file.
+func (f *CommunityFile) Content() (string, error) {
+	r, err := f.Blob.Data()
+	if err != nil {
+		return "", err
+	}
+	data, err := ioutil.ReadAll(r)
+	return string(data), err
+}
+
+// findCommunityFile returns the first of candidates which exists on the
+// default branch of the repository, or nil if none exists.
+func (repo *Repository) findCommunityFile(candidates []string) (*CommunityFile, error) {
+	if repo.IsBare {
+		return nil, nil
+	}
+
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return nil, err
+	}
+	commit, err := gitRepo.GetBranchCommit(repo.DefaultBranch)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, candidate := range candidates {
+		entry, err := commit.GetTreeEntryByPath(candidate)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				continue
+			}
+			return nil, err
+		} else if entry.IsDir() {
+			continue
+		}
+		return &CommunityFile{repo, candidate, entry.Blob()}, nil
+	}
+	return nil, nil
+}
+
+// GetCommunityHealthRepo returns the public community health repository of
+// the owner of the repository, or nil if it has none.
+func (repo *Repository) GetCommunityHealthRepo() (*Repository, error) {
+	if repo.LowerName == CommunityHealthRepoName {
+		return nil, nil
+	}
+
+	healthRepo, err := GetRepositoryByName(repo.OwnerID, CommunityHealthRepoName)
+	if err != nil {
+		if IsErrRepoNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	} else if healthRepo.IsPrivate {
+		// Inheriting from a private repository would disclose its files.
+		return nil, nil
+	}
+	return healthRepo, healthRepo.GetOwner()
+}
+
+// FindCommunityFile returns the first of candidates which exists on the
+// default branch of the repository. If there is none, the file is inherited
+// from the community health repository of the owner. It returns nil if
+// neither has the file.
+func (repo *Repository) FindCommunityFile(candidates []string) (*CommunityFile, error) {
+	file, err := repo.findCommunityFile(candidates)
+	if err != nil || file != nil {
+		return file, err
+	}
+
+	healthRepo, err := repo.GetCommunityHealthRepo()
+	if err != nil || healthRepo == nil {
+		return nil, err
+	}
+	return healthRepo.findCommunityFile(candidates)
+}
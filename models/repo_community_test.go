@@ -0,0 +1,99 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// createTestGitRepo creates a bare repository at repoPath with a commit of
+// files on the master branch.
+func createTestGitRepo(t *testing.T, repoPath string, files map[string]string) {
+	tmpDir, err := ioutil.TempDir("", "community-files")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	for name, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(tmpDir, name)), os.ModePerm))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644))
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+		{"branch", "-M", "master"},
+		{"clone", "-q", "--bare", tmpDir, repoPath},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+}
+
+func TestFindCommunityFile(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	root, err := ioutil.TempDir("", "community-repos")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+	oldRoot := setting.RepoRootPath
+	setting.RepoRootPath = root
+	defer func() { setting.RepoRootPath = oldRoot }()
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	repo.DefaultBranch = "master"
+	createTestGitRepo(t, filepath.Join(root, "user2/repo1.git"), map[string]string{
+		".github/CONTRIBUTING.md": "contribute",
+	})
+
+	file, err := repo.FindCommunityFile(ContributingCandidates)
+	assert.NoError(t, err)
+	if assert.NotNil(t, file) {
+		assert.Equal(t, ".github/CONTRIBUTING.md", file.Path)
+		content, err := file.Content()
+		assert.NoError(t, err)
+		assert.Equal(t, "contribute", content)
+	}
+
+	file, err = repo.FindCommunityFile(CodeOfConductCandidates)
+	assert.NoError(t, err)
+	assert.Nil(t, file)
+
+	healthRepo := &Repository{
+		OwnerID:       2,
+		LowerName:     CommunityHealthRepoName,
+		Name:          CommunityHealthRepoName,
+		DefaultBranch: "master",
+	}
+	_, err = x.Insert(healthRepo)
+	assert.NoError(t, err)
+	createTestGitRepo(t, filepath.Join(root, "user2", CommunityHealthRepoName+".git"), map[string]string{
+		"CODE_OF_CONDUCT.md": "be nice",
+	})
+
+	file, err = repo.FindCommunityFile(CodeOfConductCandidates)
+	assert.NoError(t, err)
+	if assert.NotNil(t, file) {
+		assert.Equal(t, healthRepo.ID, file.Repo.ID)
+		assert.Equal(t, setting.AppSubURL+"/user2/.gitea/src/master/CODE_OF_CONDUCT.md", file.Link())
+	}
+
+	// Files of private community health repositories are not inherited.
+	healthRepo.IsPrivate = true
+	_, err = x.Id(healthRepo.ID).Cols("is_private").Update(healthRepo)
+	assert.NoError(t, err)
+	file, err = repo.FindCommunityFile(CodeOfConductCandidates)
+	assert.NoError(t, err)
+	assert.Nil(t, file)
+}
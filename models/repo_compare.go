@@ -0,0 +1,200 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/git"
+)
+
+// CompareSpec is a parsed comparison of a base revision of a repository with
+// a head revision of the same repository or of a repository of its fork
+// network.
+type CompareSpec struct {
+	BaseRef  string
+	HeadRepo *Repository
+	HeadRef  string
+}
+
+// inForkNetwork returns true if a and b are the same repository, one is a
+// fork of the other, or both are forks of the same repository.
+func inForkNetwork(a, b *Repository) bool {
+	return a.ID == b.ID || a.ForkID == b.ID || b.ForkID == a.ID ||
+		(a.ForkID > 0 && a.ForkID == b.ForkID)
+}
+
+// findNetworkRepo returns the repository of owner in the fork network of repo.
+func findNetworkRepo(repo *Repository, owner *User) (*Repository, error) {
+	if repo.OwnerID == owner.ID {
+		return repo, nil
+	} else if fork, has := HasForkedRepo(owner.ID, repo.ID); has {
+		return fork, nil
+	}
+
+	if repo.IsFork {
+		if err := repo.GetBaseRepo(); err != nil {
+			return nil, err
+		} else if repo.BaseRepo.OwnerID == owner.ID {
+			return repo.BaseRepo, nil
+		} else if fork, has := HasForkedRepo(owner.ID, repo.ForkID); has {
+			return fork, nil
+		}
+	}
+	return nil, ErrRepoNotExist{0, owner.ID, ""}
+}
+
+// ParseCompareSpec parses a comparison of the format
+// "<base>...[<owner>[/<repo>]:]<head>" of baseRepo, base and head are branches,
+// tags or commits. The head repository must be readable by doer, which is nil
+// for anonymous users.
+func ParseCompareSpec(baseRepo *Repository, doer *User, spec string) (*CompareSpec, error) {
+	infos := strings.Split(spec, "...")
+	if len(infos) != 2 || len(infos[0]) == 0 || len(infos[1]) == 0 {
+		return nil, ErrInvalidCompareSpec{spec}
+	}
+
+	cs := &CompareSpec{
+		BaseRef:  infos[0],
+		HeadRepo: baseRepo,
+		HeadRef:  infos[1],
+	}
+	headInfos := strings.Split(infos[1], ":")
+	switch len(headInfos) {
+	case 1:
+		return cs, nil
+	case 2:
+		cs.HeadRef = headInfos[1]
+	default:
+		return nil, ErrInvalidCompareSpec{spec}
+	}
+	if len(headInfos[0]) == 0 || len(cs.HeadRef) == 0 {
+		return nil, ErrInvalidCompareSpec{spec}
+	}
+
+	ownerName, repoName := headInfos[0], ""
+	if i := strings.Index(ownerName, "/"); i >= 0 {
+		ownerName, repoName = ownerName[:i], ownerName[i+1:]
+	}
+	owner, err := GetUserByName(ownerName)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(repoName) == 0 {
+		cs.HeadRepo, err = findNetworkRepo(baseRepo, owner)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		cs.HeadRepo, err = GetRepositoryByName(owner.ID, repoName)
+		if err != nil {
+			return nil, err
+		} else if !inForkNetwork(baseRepo, cs.HeadRepo) {
+			return nil, ErrRepoNotExist{0, owner.ID, repoName}
+		}
+	}
+
+	if cs.HeadRepo.IsPrivate && (doer == nil || !doer.IsAdmin) {
+		var doerID int64
+		if doer != nil {
+			doerID = doer.ID
+		}
+		has, err := HasAccess(doerID, cs.HeadRepo, AccessModeRead)
+		if err != nil {
+			return nil, err
+		} else if !has {
+			return nil, ErrRepoNotExist{0, owner.ID, cs.HeadRepo.Name}
+		}
+	}
+	if err = cs.HeadRepo.GetOwner(); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// CompareInfo holds the commits of the head revision of a comparison since
+// its merge base with the base revision.
+type CompareInfo struct {
+	BaseCommitID string
+	HeadCommitID string
+	// MergeBase is the base commit if the revisions have no common ancestor.
+	MergeBase string
+	Commits   *list.List
+}
+
+// getRefCommit returns the commit a branch, tag or commit ID refers to.
+func getRefCommit(gitRepo *git.Repository, ref string) (*git.Commit, error) {
+	// References must not be mistaken for options.
+	if strings.HasPrefix(ref, "-") {
+		return nil, git.ErrNotExist{ID: ref}
+	}
+	stdout, err := git.NewCommand("rev-parse", "--verify", "--quiet", ref+"^{commit}").RunInDir(gitRepo.Path)
+	if err != nil {
+		return nil, git.ErrNotExist{ID: ref}
+	}
+	return gitRepo.GetCommit(strings.TrimSpace(stdout))
+}
+
+// GetCompareInfo resolves the revisions of the comparison of baseRepo given by
+// cs. The commits and the diff of the comparison are in the head repository.
+func GetCompareInfo(baseRepo *Repository, cs *CompareSpec) (*CompareInfo, error) {
+	baseGitRepo, err := git.OpenRepository(baseRepo.RepoPath())
+	if err != nil {
+		return nil, fmt.Errorf("OpenRepository: %v", err)
+	}
+	baseCommit, err := getRefCommit(baseGitRepo, cs.BaseRef)
+	if err != nil {
+		return nil, err
+	}
+
+	headGitRepo := baseGitRepo
+	if cs.HeadRepo.ID != baseRepo.ID {
+		headGitRepo, err = git.OpenRepository(cs.HeadRepo.RepoPath())
+		if err != nil {
+			return nil, fmt.Errorf("OpenRepository: %v", err)
+		}
+	}
+	headCommit, err := getRefCommit(headGitRepo, cs.HeadRef)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &CompareInfo{
+		BaseCommitID: baseCommit.ID.String(),
+		HeadCommitID: headCommit.ID.String(),
+	}
+
+	// The base commit must be fetched into the head repository to find the
+	// merge base.
+	if baseGitRepo != headGitRepo {
+		if _, err = headGitRepo.GetCommit(info.BaseCommitID); err != nil {
+			tmpRemote := strconv.FormatInt(time.Now().UnixNano(), 10)
+			if err = headGitRepo.AddRemote(tmpRemote, baseGitRepo.Path, true); err != nil {
+				return nil, fmt.Errorf("AddRemote: %v", err)
+			}
+			defer headGitRepo.RemoveRemote(tmpRemote)
+		}
+	}
+
+	info.MergeBase, err = headGitRepo.GetMergeBase(info.BaseCommitID, info.HeadCommitID)
+	if err != nil || len(info.MergeBase) == 0 {
+		info.MergeBase = info.BaseCommitID
+	}
+
+	if info.MergeBase == info.HeadCommitID {
+		info.Commits = list.New()
+		return info, nil
+	}
+	info.Commits, err = headCommit.CommitsBeforeUntil(info.MergeBase)
+	if err != nil {
+		return nil, fmt.Errorf("CommitsBeforeUntil: %v", err)
+	}
+	return info, nil
+}
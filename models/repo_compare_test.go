@@ -0,0 +1,104 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/git"
+	"github.com/stretchr/testify/assert"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+func TestParseCompareSpec(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 10}).(*Repository)
+
+	cs, err := ParseCompareSpec(repo, nil, "master...feature")
+	assert.NoError(t, err)
+	assert.Equal(t, "master", cs.BaseRef)
+	assert.EqualValues(t, 10, cs.HeadRepo.ID)
+	assert.Equal(t, "feature", cs.HeadRef)
+
+	for _, spec := range []string{"user13:feature", "user13/repo11:feature"} {
+		cs, err = ParseCompareSpec(repo, nil, "v1.0..."+spec)
+		assert.NoError(t, err)
+		assert.Equal(t, "v1.0", cs.BaseRef)
+		assert.EqualValues(t, 11, cs.HeadRepo.ID)
+		assert.Equal(t, "user13", cs.HeadRepo.Owner.Name)
+		assert.Equal(t, "feature", cs.HeadRef)
+	}
+
+	fork := AssertExistsAndLoadBean(t, &Repository{ID: 11}).(*Repository)
+	fork.IsFork = true
+	cs, err = ParseCompareSpec(fork, nil, "master...user12:feature")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, cs.HeadRepo.ID)
+
+	_, err = ParseCompareSpec(repo, nil, "master...user2/repo1:feature")
+	assert.True(t, IsErrRepoNotExist(err))
+	_, err = ParseCompareSpec(repo, nil, "master...user2:feature")
+	assert.True(t, IsErrRepoNotExist(err))
+	_, err = ParseCompareSpec(repo, nil, "master...nobody:feature")
+	assert.True(t, IsErrUserNotExist(err))
+	for _, spec := range []string{"master", "master...", "...feature", "master...a:b:c", "master...:feature"} {
+		_, err = ParseCompareSpec(repo, nil, spec)
+		assert.True(t, IsErrInvalidCompareSpec(err), spec)
+	}
+}
+
+func TestGetCompareInfo(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	root, err := ioutil.TempDir("", "repo-compare")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+	oldRoot := setting.RepoRootPath
+	setting.RepoRootPath = filepath.Join(root, "repos")
+	defer func() { setting.RepoRootPath = oldRoot }()
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	createTestGitRepo(t, repo.RepoPath(), map[string]string{"README.md": "readme"})
+
+	// Add a commit on a feature branch.
+	tmpDir := filepath.Join(root, "clone")
+	for _, args := range [][]string{
+		{"clone", "-q", repo.RepoPath(), tmpDir},
+		{"-C", tmpDir, "checkout", "-q", "-b", "feature"},
+		{"-C", tmpDir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "feature"},
+		{"-C", tmpDir, "push", "-q", "origin", "feature"},
+	} {
+		out, err := exec.Command("git", args...).CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	assert.NoError(t, err)
+	masterID, err := gitRepo.GetBranchCommitID("master")
+	assert.NoError(t, err)
+	featureID, err := gitRepo.GetBranchCommitID("feature")
+	assert.NoError(t, err)
+
+	info, err := GetCompareInfo(repo, &CompareSpec{BaseRef: "master", HeadRepo: repo, HeadRef: "feature"})
+	assert.NoError(t, err)
+	assert.Equal(t, masterID, info.BaseCommitID)
+	assert.Equal(t, featureID, info.HeadCommitID)
+	assert.Equal(t, masterID, info.MergeBase)
+	assert.Equal(t, 1, info.Commits.Len())
+
+	info, err = GetCompareInfo(repo, &CompareSpec{BaseRef: featureID, HeadRepo: repo, HeadRef: masterID})
+	assert.NoError(t, err)
+	assert.Equal(t, masterID, info.MergeBase)
+	assert.Equal(t, 0, info.Commits.Len())
+
+	for _, ref := range []string{"nonexistent", "--all"} {
+		_, err = GetCompareInfo(repo, &CompareSpec{BaseRef: "master", HeadRepo: repo, HeadRef: ref})
+		assert.True(t, git.IsErrNotExist(err), ref)
+	}
+}
@@ -72,6 +72,14 @@ func (repo *Repository) CheckoutNewBranch(oldBranch, newBranch string) error {
 	return checkoutNewBranch(repo.RepoPath(), repo.LocalCopyPath(), oldBranch, newBranch)
 }
 
+// commitSignature returns committer if not nil, otherwise the signature of doer.
+func commitSignature(doer *User, committer *git.Signature) *git.Signature {
+	if committer != nil {
+		return committer
+	}
+	return doer.NewGitSig()
+}
+
 // UpdateRepoFileOptions holds the repository file update options
 type UpdateRepoFileOptions struct {
 	LastCommitID string
@@ -82,6 +90,8 @@ type UpdateRepoFileOptions struct {
 	Message      string
 	Content      string
 	IsNewFile    bool
+	// Committer overrides the identity of doer in the commit if not nil.
+	Committer *git.Signature
 }
 
 // UpdateRepoFile adds or updates a file in repository.
@@ -132,7 +142,7 @@ func (repo *Repository) UpdateRepoFile(doer *User, opts UpdateRepoFileOptions) (
 	if err = git.AddChanges(localPath, true); err != nil {
 		return fmt.Errorf("git add --all: %v", err)
 	} else if err = git.CommitChanges(localPath, git.CommitChangesOptions{
-		Committer: doer.NewGitSig(),
+		Committer: commitSignature(doer, opts.Committer),
 		Message:   opts.Message,
 	}); err != nil {
 		return fmt.Errorf("CommitChanges: %v", err)
@@ -245,6 +255,8 @@ type DeleteRepoFileOptions struct {
 	NewBranch    string
 	TreePath     string
 	Message      string
+	// Committer overrides the identity of doer in the commit if not nil.
+	Committer *git.Signature
 }
 
 // DeleteRepoFile deletes a repository file
@@ -272,7 +284,7 @@ func (repo *Repository) DeleteRepoFile(doer *User, opts DeleteRepoFileOptions) (
 	if err = git.AddChanges(localPath, true); err != nil {
 		return fmt.Errorf("git add --all: %v", err)
 	} else if err = git.CommitChanges(localPath, git.CommitChangesOptions{
-		Committer: doer.NewGitSig(),
+		Committer: commitSignature(doer, opts.Committer),
 		Message:   opts.Message,
 	}); err != nil {
 		return fmt.Errorf("CommitChanges: %v", err)
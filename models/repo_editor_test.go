@@ -0,0 +1,89 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"code.gitea.io/git"
+	"github.com/stretchr/testify/assert"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+func TestRepository_UpdateRepoFile(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	root, err := ioutil.TempDir("", "repo-editor")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+	oldRoot, oldLocalCopyPath := setting.RepoRootPath, setting.Repository.Local.LocalCopyPath
+	setting.RepoRootPath = filepath.Join(root, "repos")
+	setting.Repository.Local.LocalCopyPath = filepath.Join(root, "local")
+	defer func() {
+		setting.RepoRootPath, setting.Repository.Local.LocalCopyPath = oldRoot, oldLocalCopyPath
+	}()
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	repo.DefaultBranch = "master"
+	createTestGitRepo(t, repo.RepoPath(), map[string]string{"README.md": "readme"})
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	assert.NoError(t, err)
+	lastCommitID, err := gitRepo.GetBranchCommitID("master")
+	assert.NoError(t, err)
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.NoError(t, repo.UpdateRepoFile(doer, UpdateRepoFileOptions{
+		LastCommitID: lastCommitID,
+		OldBranch:    "master",
+		NewBranch:    "feature",
+		OldTreeName:  "docs/new.md",
+		NewTreeName:  "docs/new.md",
+		Message:      "add new.md",
+		Content:      "new",
+		IsNewFile:    true,
+		Committer:    &git.Signature{Name: "bot", Email: "bot@example.com", When: time.Now()},
+	}))
+
+	commit, err := gitRepo.GetBranchCommit("feature")
+	assert.NoError(t, err)
+	assert.Equal(t, "bot", commit.Committer.Name)
+	assert.Equal(t, "bot@example.com", commit.Committer.Email)
+	blob, err := commit.GetBlobByPath("docs/new.md")
+	assert.NoError(t, err)
+	r, err := blob.Data()
+	assert.NoError(t, err)
+	data, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+
+	err = repo.UpdateRepoFile(doer, UpdateRepoFileOptions{
+		LastCommitID: commit.ID.String(),
+		OldBranch:    "feature",
+		NewBranch:    "feature",
+		OldTreeName:  "docs/new.md",
+		NewTreeName:  "docs/new.md",
+		Content:      "again",
+		IsNewFile:    true,
+	})
+	assert.True(t, IsErrRepoFileAlreadyExist(err))
+
+	assert.NoError(t, repo.DeleteRepoFile(doer, DeleteRepoFileOptions{
+		LastCommitID: commit.ID.String(),
+		OldBranch:    "feature",
+		NewBranch:    "feature",
+		TreePath:     "docs/new.md",
+		Message:      "delete new.md",
+	}))
+	commit, err = gitRepo.GetBranchCommit("feature")
+	assert.NoError(t, err)
+	assert.Equal(t, doer.NewGitSig().Name, commit.Committer.Name)
+	_, err = commit.GetBlobByPath("docs/new.md")
+	assert.True(t, git.IsErrNotExist(err))
+}
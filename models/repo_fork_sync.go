@@ -0,0 +1,149 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"code.gitea.io/git"
+	"github.com/Unknwon/com"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ForkSyncStyle represents how a branch of a fork is brought up to date with
+// the same branch of its base repository.
+type ForkSyncStyle string
+
+// Fork sync styles.
+const (
+	// ForkSyncFastForward only syncs the branch if it has no own commits.
+	ForkSyncFastForward ForkSyncStyle = "fast-forward"
+	// ForkSyncMerge merges the base branch into the branch if it can not be
+	// fast-forwarded.
+	ForkSyncMerge ForkSyncStyle = "merge"
+)
+
+// ForkSyncBranchName returns the name of the branch of a fork that receives
+// the given branch of its base repository to be merged by a pull request.
+func ForkSyncBranchName(branch string) string {
+	return "upstream-" + branch
+}
+
+// SyncFork brings the given branch of the fork up to date with the same branch
+// of its base repository and pushes the result to the fork. It returns false
+// if the branch already contains all commits of the base branch.
+func (repo *Repository) SyncFork(doer *User, branch string, style ForkSyncStyle) (_ bool, err error) {
+	if err = repo.GetBaseRepo(); err != nil {
+		return false, fmt.Errorf("GetBaseRepo: %v", err)
+	} else if repo.BaseRepo == nil {
+		return false, fmt.Errorf("repository [%d] is not a fork", repo.ID)
+	}
+
+	repoPath := repo.RepoPath()
+	baseRepoPath := repo.BaseRepo.RepoPath()
+	if !git.IsBranchExist(repoPath, branch) || !git.IsBranchExist(baseRepoPath, branch) {
+		return false, ErrBranchNotExist{branch}
+	}
+
+	// Clone the branch of the fork.
+	tmpBasePath := path.Join(setting.AppDataPath, "tmp/repos", com.ToStr(time.Now().Nanosecond())+".git")
+
+	if err := os.MkdirAll(path.Dir(tmpBasePath), os.ModePerm); err != nil {
+		return false, fmt.Errorf("Failed to create dir %s: %v", tmpBasePath, err)
+	}
+
+	defer os.RemoveAll(path.Dir(tmpBasePath))
+
+	var stderr string
+	if _, stderr, err = process.GetManager().ExecTimeout(5*time.Minute,
+		fmt.Sprintf("Repository.SyncFork (git clone): %s", tmpBasePath),
+		"git", "clone", "-b", branch, repoPath, tmpBasePath); err != nil {
+		return false, fmt.Errorf("git clone: %s", stderr)
+	}
+
+	if _, stderr, err = process.GetManager().ExecDir(5*time.Minute, tmpBasePath,
+		fmt.Sprintf("Repository.SyncFork (git fetch): %s", tmpBasePath),
+		"git", "fetch", baseRepoPath, branch); err != nil {
+		return false, fmt.Errorf("git fetch [%s -> %s]: %s", baseRepoPath, tmpBasePath, stderr)
+	}
+
+	// Nothing to do if the branch already contains the base branch.
+	if _, _, err = process.GetManager().ExecDir(-1, tmpBasePath,
+		fmt.Sprintf("Repository.SyncFork (git merge-base): %s", tmpBasePath),
+		"git", "merge-base", "--is-ancestor", "FETCH_HEAD", "HEAD"); err == nil {
+		return false, nil
+	}
+
+	if _, stderr, err = process.GetManager().ExecDir(-1, tmpBasePath,
+		fmt.Sprintf("Repository.SyncFork (git merge --ff-only): %s", tmpBasePath),
+		"git", "merge", "--ff-only", "FETCH_HEAD"); err != nil {
+		if style != ForkSyncMerge {
+			log.Trace("Repository[%d].SyncFork (git merge --ff-only): %s", repo.ID, stderr)
+			return false, ErrForkSyncConflict{repo.ID, branch, style}
+		}
+
+		sig := doer.NewGitSig()
+		env := append(os.Environ(),
+			"GIT_COMMITTER_NAME="+sig.Name,
+			"GIT_COMMITTER_EMAIL="+sig.Email,
+			"GIT_AUTHOR_NAME="+sig.Name,
+			"GIT_AUTHOR_EMAIL="+sig.Email)
+		if _, stderr, err = process.GetManager().ExecDirEnv(-1, tmpBasePath,
+			fmt.Sprintf("Repository.SyncFork (git merge): %s", tmpBasePath), env,
+			"git", "merge", "--no-ff", "--no-edit",
+			"-m", fmt.Sprintf("Merge branch '%s' of %s into %s", branch, repo.BaseRepo.FullName(), branch),
+			"FETCH_HEAD"); err != nil {
+			log.Trace("Repository[%d].SyncFork (git merge): %s", repo.ID, stderr)
+			return false, ErrForkSyncConflict{repo.ID, branch, style}
+		}
+	}
+
+	// Push back to the fork.
+	if _, stderr, err = process.GetManager().ExecDir(-1, tmpBasePath,
+		fmt.Sprintf("Repository.SyncFork (git push): %s", tmpBasePath),
+		"git", "push", repoPath, branch); err != nil {
+		return false, fmt.Errorf("git push: %s", stderr)
+	}
+
+	// Pushing to a local path does not trigger the usual update of pull
+	// requests, so refresh the patch and notify webhooks here.
+	AddTestPullRequestTask(doer, repo.ID, branch, true)
+	return true, nil
+}
+
+// PushForkSyncBranch fetches the given branch of the base repository of the
+// fork into the branch named by ForkSyncBranchName, so it can be merged by a
+// pull request when it can not be synced directly. An existing branch is only
+// updated if it is not changed in the fork.
+func (repo *Repository) PushForkSyncBranch(doer *User, branch string) (string, error) {
+	if err := repo.GetBaseRepo(); err != nil {
+		return "", fmt.Errorf("GetBaseRepo: %v", err)
+	} else if repo.BaseRepo == nil {
+		return "", fmt.Errorf("repository [%d] is not a fork", repo.ID)
+	}
+
+	baseRepoPath := repo.BaseRepo.RepoPath()
+	if !git.IsBranchExist(baseRepoPath, branch) {
+		return "", ErrBranchNotExist{branch}
+	}
+
+	syncBranch := ForkSyncBranchName(branch)
+	repoPath := repo.RepoPath()
+	if _, stderr, err := process.GetManager().ExecDir(5*time.Minute, repoPath,
+		fmt.Sprintf("Repository.PushForkSyncBranch (git fetch): %s", repoPath),
+		"git", "fetch", baseRepoPath, git.BranchPrefix+branch+":"+git.BranchPrefix+syncBranch); err != nil {
+		log.Trace("Repository[%d].PushForkSyncBranch (git fetch): %s", repo.ID, stderr)
+		return "", ErrForkSyncConflict{repo.ID, syncBranch, ForkSyncFastForward}
+	}
+
+	AddTestPullRequestTask(doer, repo.ID, syncBranch, true)
+	return syncBranch, nil
+}
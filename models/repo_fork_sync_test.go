@@ -0,0 +1,102 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/git"
+	"github.com/stretchr/testify/assert"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// commitTestFile commits a file to the master branch of a bare repository.
+func commitTestFile(t *testing.T, repoPath, name, content string) {
+	tmpDir, err := ioutil.TempDir("", "fork-sync-commit")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	run("clone", "-q", repoPath, ".")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644))
+	run("add", "-A")
+	run("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "change "+name)
+	run("push", "-q", "origin", "master")
+}
+
+func TestRepository_SyncFork(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	root, err := ioutil.TempDir("", "fork-sync")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+	oldRoot := setting.RepoRootPath
+	setting.RepoRootPath = filepath.Join(root, "repos")
+	defer func() { setting.RepoRootPath = oldRoot }()
+	oldDataPath := setting.AppDataPath
+	setting.AppDataPath = filepath.Join(root, "data")
+	defer func() { setting.AppDataPath = oldDataPath }()
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 13}).(*User)
+	base := AssertExistsAndLoadBean(t, &Repository{ID: 10}).(*Repository)
+	fork := AssertExistsAndLoadBean(t, &Repository{ID: 11}).(*Repository)
+	fork.IsFork = true
+	createTestGitRepo(t, base.RepoPath(), map[string]string{"README.md": "readme"})
+	cmd := exec.Command("git", "clone", "-q", "--bare", base.RepoPath(), fork.RepoPath())
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(out))
+
+	branchCommitID := func(repoPath string) string {
+		gitRepo, err := git.OpenRepository(repoPath)
+		assert.NoError(t, err)
+		commitID, err := gitRepo.GetBranchCommitID("master")
+		assert.NoError(t, err)
+		return commitID
+	}
+
+	updated, err := fork.SyncFork(doer, "master", ForkSyncFastForward)
+	assert.NoError(t, err)
+	assert.False(t, updated)
+
+	commitTestFile(t, base.RepoPath(), "base.txt", "base")
+	updated, err = fork.SyncFork(doer, "master", ForkSyncFastForward)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	assert.Equal(t, branchCommitID(base.RepoPath()), branchCommitID(fork.RepoPath()))
+
+	// Both repositories have new commits.
+	commitTestFile(t, base.RepoPath(), "base.txt", "base 2")
+	commitTestFile(t, fork.RepoPath(), "fork.txt", "fork")
+	_, err = fork.SyncFork(doer, "master", ForkSyncFastForward)
+	assert.True(t, IsErrForkSyncConflict(err))
+
+	syncBranch, err := fork.PushForkSyncBranch(doer, "master")
+	assert.NoError(t, err)
+	assert.Equal(t, "upstream-master", syncBranch)
+	assert.True(t, git.IsBranchExist(fork.RepoPath(), syncBranch))
+
+	updated, err = fork.SyncFork(doer, "master", ForkSyncMerge)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+
+	// The same file is changed in both repositories.
+	commitTestFile(t, base.RepoPath(), "README.md", "base readme")
+	commitTestFile(t, fork.RepoPath(), "README.md", "fork readme")
+	_, err = fork.SyncFork(doer, "master", ForkSyncMerge)
+	assert.True(t, IsErrForkSyncConflict(err))
+
+	_, err = fork.SyncFork(doer, "nonexistent", ForkSyncMerge)
+	assert.True(t, IsErrBranchNotExist(err))
+}
@@ -0,0 +1,102 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.gitea.io/git"
+	"github.com/Unknwon/com"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// Fsck runs git fsck on the repository with the configured arguments, it
+// returns the output of the command, which is not empty if git fsck reports
+// warnings or dangling objects, and an error if the repository is broken.
+func (repo *Repository) Fsck() (string, error) {
+	timeout := setting.Cron.RepoHealthCheck.Timeout
+	if timeout <= 0 {
+		timeout = -1
+	}
+	output := new(bytes.Buffer)
+	err := git.NewCommand("fsck").
+		AddArguments(setting.Cron.RepoHealthCheck.Args...).
+		RunInDirTimeoutPipeline(timeout, repo.RepoPath(), output, output)
+	return strings.TrimSpace(output.String()), err
+}
+
+// GC runs git gc on the repository with the configured arguments.
+func (repo *Repository) GC() error {
+	args := append([]string{"gc"}, setting.Git.GCArgs...)
+	_, stderr, err := process.GetManager().ExecDir(
+		time.Duration(setting.Git.Timeout.GC)*time.Second,
+		repo.RepoPath(), "Repository garbage collection",
+		"git", args...)
+	if err != nil {
+		return fmt.Errorf("%v: %v", err, stderr)
+	}
+	return nil
+}
+
+// LooseObjectCount returns the number of loose objects of the repository.
+func (repo *Repository) LooseObjectCount() (int64, error) {
+	stdout, err := git.NewCommand("count-objects", "-v").RunInDir(repo.RepoPath())
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(stdout, "\n") {
+		if strings.HasPrefix(line, "count: ") {
+			return com.StrTo(strings.TrimPrefix(line, "count: ")).Int64()
+		}
+	}
+	return 0, fmt.Errorf("unexpected output of git count-objects: %s", stdout)
+}
+
+// checkRepoHealth runs git fsck on the repository and records problems in
+// system notices, then runs git gc if the repository has more loose objects
+// than configured.
+func checkRepoHealth(repo *Repository) {
+	repoPath := repo.RepoPath()
+	output, err := repo.Fsck()
+	if err != nil {
+		desc := fmt.Sprintf("Failed to health check repository (%s): %v\n%s", repoPath, err, output)
+		log.Warn(desc)
+		if err = CreateSeverityRepositoryNotice(NoticeSeverityError, desc); err != nil {
+			log.Error(4, "CreateSeverityRepositoryNotice: %v", err)
+		}
+		return
+	} else if len(output) > 0 {
+		desc := fmt.Sprintf("Health check of repository (%s) reported:\n%s", repoPath, output)
+		if err = CreateSeverityRepositoryNotice(NoticeSeverityWarning, desc); err != nil {
+			log.Error(4, "CreateSeverityRepositoryNotice: %v", err)
+		}
+	}
+
+	threshold := setting.Cron.RepoHealthCheck.AutoGCLooseObjects
+	if threshold <= 0 {
+		return
+	}
+	count, err := repo.LooseObjectCount()
+	if err != nil {
+		log.Error(4, "LooseObjectCount [%s]: %v", repoPath, err)
+		return
+	} else if count <= threshold {
+		return
+	}
+	log.Trace("Running garbage collection of repository (%s) with %d loose objects", repoPath, count)
+	if err = repo.GC(); err != nil {
+		desc := fmt.Sprintf("Failed to run garbage collection of repository (%s): %v", repoPath, err)
+		log.Warn(desc)
+		if err = CreateSeverityRepositoryNotice(NoticeSeverityError, desc); err != nil {
+			log.Error(4, "CreateSeverityRepositoryNotice: %v", err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+func TestCheckRepoHealth(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	root, err := ioutil.TempDir("", "repo-health")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+	oldRoot := setting.RepoRootPath
+	setting.RepoRootPath = root
+	defer func() { setting.RepoRootPath = oldRoot }()
+	oldThreshold := setting.Cron.RepoHealthCheck.AutoGCLooseObjects
+	setting.Cron.RepoHealthCheck.AutoGCLooseObjects = 1
+	defer func() { setting.Cron.RepoHealthCheck.AutoGCLooseObjects = oldThreshold }()
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	createTestGitRepo(t, repo.RepoPath(), map[string]string{"README.md": "readme"})
+
+	output, err := repo.Fsck()
+	assert.NoError(t, err)
+	assert.Empty(t, output)
+	count, err := repo.LooseObjectCount()
+	assert.NoError(t, err)
+	assert.True(t, count > 1)
+
+	// A healthy repository is garbage collected without notices.
+	notices := CountNotices()
+	checkRepoHealth(repo)
+	assert.Equal(t, notices, CountNotices())
+	count, err = repo.LooseObjectCount()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+
+	// Lose all objects.
+	packs, err := filepath.Glob(filepath.Join(repo.RepoPath(), "objects", "pack", "*"))
+	assert.NoError(t, err)
+	for _, pack := range packs {
+		assert.NoError(t, os.Remove(pack))
+	}
+	checkRepoHealth(repo)
+	assert.Equal(t, notices+1, CountNotices())
+	notice := new(Notice)
+	has, err := x.Desc("id").Get(notice)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.Equal(t, NoticeSeverityError, notice.Severity)
+	assert.Contains(t, notice.Description, repo.RepoPath())
+}
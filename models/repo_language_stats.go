@@ -0,0 +1,212 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/git"
+	"code.gitea.io/gitea/modules/linguist"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/sync"
+
+	"github.com/Unknwon/com"
+)
+
+// languageStatsQueue holds the IDs of repositories whose language statistics
+// need to be computed again.
+var languageStatsQueue sync.Queue = sync.NewUniqueQueue(setting.Repository.LanguageStatsQueueLength)
+
+// LanguageStat represents the total size of the files of one language
+// in the default branch of a repository.
+type LanguageStat struct {
+	ID          int64   `xorm:"pk autoincr"`
+	RepoID      int64   `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	CommitID    string  `xorm:"VARCHAR(40)"`
+	Language    string  `xorm:"VARCHAR(50) UNIQUE(s) INDEX NOT NULL"`
+	Size        int64   `xorm:"NOT NULL DEFAULT 0"`
+	Percentage  float32 `xorm:"-"`
+	CreatedUnix int64   `xorm:"INDEX created"`
+}
+
+// Color returns the color of the language of the statistic.
+func (stat *LanguageStat) Color() string {
+	return linguist.LanguageColor(stat.Language)
+}
+
+// LanguageStatList defines a list of language statistics.
+type LanguageStatList []*LanguageStat
+
+func (stats LanguageStatList) loadPercentages() {
+	var total int64
+	for _, stat := range stats {
+		total += stat.Size
+	}
+	if total == 0 {
+		return
+	}
+	for _, stat := range stats {
+		stat.Percentage = float32(float64(stat.Size*10000/total) / 100)
+	}
+}
+
+// GetLanguageStats returns the language statistics of the repository,
+// ordered by size.
+func (repo *Repository) GetLanguageStats() (LanguageStatList, error) {
+	stats := make(LanguageStatList, 0, 10)
+	if err := x.
+		Where("repo_id = ?", repo.ID).
+		Desc("size").
+		Find(&stats); err != nil {
+		return nil, err
+	}
+	stats.loadPercentages()
+	return stats, nil
+}
+
+// GetStatLanguages returns the names of all languages used by repositories
+// in alphabetical order.
+func GetStatLanguages() ([]string, error) {
+	langs := make([]string, 0, 10)
+	return langs, x.
+		Table("language_stat").
+		Distinct("language").
+		Asc("language").
+		Find(&langs)
+}
+
+// parseLanguageSizes parses the output of git ls-tree -r -l -z and sums up
+// the sizes of the files of every recognized language.
+func parseLanguageSizes(stdout string) map[string]int64 {
+	sizes := make(map[string]int64)
+	for _, line := range strings.Split(stdout, "\x00") {
+		tab := strings.IndexByte(line, '\t')
+		if tab < 0 {
+			continue
+		}
+		fields := strings.Fields(line[:tab])
+		if len(fields) != 4 || fields[1] != "blob" {
+			continue
+		}
+
+		filePath := line[tab+1:]
+		if linguist.IsVendor(filePath) {
+			continue
+		}
+		lang := linguist.DetectLanguage(filePath)
+		if len(lang) == 0 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		sizes[lang] += size
+	}
+	return sizes
+}
+
+// UpdateLanguageStats computes the language statistics of the default branch
+// of the repository if they are not up to date.
+func (repo *Repository) UpdateLanguageStats() error {
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	commitID, err := gitRepo.GetBranchCommitID(repo.DefaultBranch)
+	if err != nil {
+		return fmt.Errorf("GetBranchCommitID: %v", err)
+	}
+
+	has, err := x.
+		Where("repo_id = ? AND commit_id = ?", repo.ID, commitID).
+		Get(new(LanguageStat))
+	if err != nil {
+		return err
+	} else if has {
+		return nil
+	}
+
+	stdout, err := git.NewCommand("ls-tree", "-r", "-l", "-z", commitID).RunInDir(repo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("git ls-tree: %v", err)
+	}
+	sizes := parseLanguageSizes(stdout)
+
+	langs := make([]string, 0, len(sizes))
+	for lang := range sizes {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err = sess.Delete(&LanguageStat{RepoID: repo.ID}); err != nil {
+		return fmt.Errorf("delete old stats: %v", err)
+	}
+	for _, lang := range langs {
+		if _, err = sess.Insert(&LanguageStat{
+			RepoID:   repo.ID,
+			CommitID: commitID,
+			Language: lang,
+			Size:     sizes[lang],
+		}); err != nil {
+			return fmt.Errorf("insert stats: %v", err)
+		}
+	}
+	return sess.Commit()
+}
+
+// AddLanguageStatsTask adds the repository to the queue of repositories
+// whose language statistics need to be updated.
+func AddLanguageStatsTask(repoID int64) {
+	languageStatsQueue.Add(repoID)
+}
+
+// UpdateLanguageStatsQueue updates the language statistics of repositories
+// added to the queue.
+func UpdateLanguageStatsQueue() {
+	for repoID := range languageStatsQueue.Queue() {
+		log.Trace("UpdateLanguageStatsQueue [repo_id: %v]", repoID)
+		finishQueueItem(languageStatsQueue, repoID, updateRepoLanguageStats(repoID))
+	}
+}
+
+// updateRepoLanguageStats updates the language statistics of the repository
+// taken from the queue.
+func updateRepoLanguageStats(repoID string) error {
+	repo, err := GetRepositoryByID(com.StrTo(repoID).MustInt64())
+	if err != nil {
+		log.Error(4, "GetRepositoryByID [%s]: %v", repoID, err)
+		if IsErrRepoNotExist(err) {
+			return nil
+		}
+		return err
+	} else if repo.IsBare {
+		return nil
+	}
+
+	start := time.Now()
+	if err = repo.UpdateLanguageStats(); err != nil {
+		log.Error(4, "UpdateLanguageStats [%s]: %v", repoID, err)
+		return err
+	}
+	log.Trace("Language stats of repository %d updated in %v", repo.ID, time.Since(start))
+	return nil
+}
+
+// InitLanguageStats initializes a go routine to update language statistics
+func InitLanguageStats() {
+	go UpdateLanguageStatsQueue()
+}
@@ -0,0 +1,52 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLanguageSizes(t *testing.T) {
+	stdout := "100644 blob 2b0a1e3c5d9f3f1c3a7e1d0b6f3a8c9d0e1f2a3b     120\tmain.go\x00" +
+		"100644 blob 6ca493ca3aabb26b0c20cb7885024be82ee9789b      30\tcmd/serv.go\x00" +
+		"100644 blob d2e5adc414b775e840b564364aa4081a46087936    5000\tvendor/lib/lib.go\x00" +
+		"100644 blob 750163634861f077bfd5d0566b9c767171a6d003      80\ttemplates/home.tmpl\x00" +
+		"100644 blob 65f1bf27bc3bf70f64657658635e66094edbcb4d      10\tREADME.md\x00" +
+		"160000 commit 65f1bf27bc3bf70f64657658635e66094edbcb4d       -\tsubmodule\x00"
+
+	assert.Equal(t, map[string]int64{
+		"Go":   150,
+		"HTML": 80,
+	}, parseLanguageSizes(stdout))
+}
+
+func TestRepository_GetLanguageStats(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	stats, err := repo.GetLanguageStats()
+	assert.NoError(t, err)
+	if assert.Len(t, stats, 2) {
+		assert.Equal(t, "Go", stats[0].Language)
+		assert.EqualValues(t, 75, stats[0].Percentage)
+		assert.Equal(t, "HTML", stats[1].Language)
+		assert.EqualValues(t, 25, stats[1].Percentage)
+	}
+
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 2}).(*Repository)
+	stats, err = repo.GetLanguageStats()
+	assert.NoError(t, err)
+	assert.Len(t, stats, 0)
+}
+
+func TestGetStatLanguages(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	langs, err := GetStatLanguages()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Go", "HTML"}, langs)
+}
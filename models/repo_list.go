@@ -10,6 +10,8 @@ import (
 
 	"github.com/go-xorm/builder"
 	"github.com/go-xorm/xorm"
+
+	"code.gitea.io/gitea/modules/util"
 )
 
 // RepositoryList contains a list of repositories
@@ -100,6 +102,10 @@ type SearchRepoOptions struct {
 	Starred   bool   `json:"-"`
 	Page      int    `json:"-"`
 	IsProfile bool   `json:"-"`
+	// Language limits the results to repositories using the language
+	Language string            `json:"-"`
+	Fork     util.OptionalBool `json:"-"`
+	Mirror   util.OptionalBool `json:"-"`
 	// Limit of result
 	//
 	// maximum: setting.ExplorePagingNum
@@ -107,6 +113,28 @@ type SearchRepoOptions struct {
 	PageSize int `json:"limit"` // Can be smaller than or equal to setting.ExplorePagingNum
 }
 
+// filterCond returns the condition of the language, fork and mirror filters,
+// deleted repositories kept in quarantine are never listed.
+func (opts *SearchRepoOptions) filterCond() builder.Cond {
+	cond := builder.Cond(builder.Eq{"repository.deleted_unix": 0})
+	if len(opts.Language) > 0 {
+		cond = cond.And(builder.Expr("repository.id IN (SELECT repo_id FROM language_stat WHERE language = ?)", opts.Language))
+	}
+	switch opts.Fork {
+	case util.OptionalBoolTrue:
+		cond = cond.And(builder.Eq{"repository.is_fork": true})
+	case util.OptionalBoolFalse:
+		cond = cond.And(builder.Eq{"repository.is_fork": false})
+	}
+	switch opts.Mirror {
+	case util.OptionalBoolTrue:
+		cond = cond.And(builder.Eq{"repository.is_mirror": true})
+	case util.OptionalBoolFalse:
+		cond = cond.And(builder.Eq{"repository.is_mirror": false})
+	}
+	return cond
+}
+
 // SearchRepositoryByName takes keyword and part of repository name to search,
 // it returns results in given range and number of total results.
 func SearchRepositoryByName(opts *SearchRepoOptions) (repos RepositoryList, count int64, err error) {
@@ -154,6 +182,7 @@ func SearchRepositoryByName(opts *SearchRepoOptions) (repos RepositoryList, coun
 
 		cond = cond.Or(builder.And(builder.Like{"lower_name", opts.Keyword}, builder.In("owner_id", ownerIds)))
 	}
+	cond = cond.And(opts.filterCond())
 
 	if len(opts.OrderBy) == 0 {
 		opts.OrderBy = "name ASC"
@@ -205,6 +234,7 @@ func Repositories(opts *SearchRepoOptions) (_ RepositoryList, count int64, err e
 	repos := make(RepositoryList, 0, opts.PageSize)
 
 	if err = x.
+		Where("deleted_unix = 0").
 		Limit(opts.PageSize, (opts.Page-1)*opts.PageSize).
 		OrderBy(opts.OrderBy).
 		Find(&repos); err != nil {
@@ -250,6 +280,7 @@ func GetRecentUpdatedRepositories(opts *SearchRepoOptions) (repos RepositoryList
 
 		cond = cond.Or(builder.In("owner_id", ownerIds))
 	}
+	cond = cond.And(opts.filterCond())
 
 	count, err := x.Where(cond).Count(new(Repository))
 	if err != nil {
@@ -258,7 +289,6 @@ func GetRecentUpdatedRepositories(opts *SearchRepoOptions) (repos RepositoryList
 
 	if err = x.Where(cond).
 		Limit(opts.PageSize, (opts.Page-1)*opts.PageSize).
-		Limit(opts.PageSize).
 		OrderBy(opts.OrderBy).
 		Find(&repos); err != nil {
 		return nil, 0, fmt.Errorf("Repo: %v", err)
@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"code.gitea.io/gitea/modules/util"
 )
 
 func TestSearchRepositoryByName(t *testing.T) {
@@ -61,3 +63,39 @@ func TestSearchRepositoryByName(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, int64(3), count)
 }
+
+func TestSearchRepositoryFilters(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repos, count, err := SearchRepositoryByName(&SearchRepoOptions{
+		Keyword:  "repo",
+		Page:     1,
+		PageSize: 10,
+		Language: "Go",
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, repos, 1) {
+		assert.EqualValues(t, 1, repos[0].ID)
+	}
+
+	repos, count, err = GetRecentUpdatedRepositories(&SearchRepoOptions{
+		Page:     1,
+		PageSize: 10,
+		Private:  true,
+		Mirror:   util.OptionalBoolTrue,
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, repos, 1) {
+		assert.EqualValues(t, 5, repos[0].ID)
+	}
+
+	_, count, err = GetRecentUpdatedRepositories(&SearchRepoOptions{
+		Page:     1,
+		PageSize: 10,
+		Language: "Rust",
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+}
@@ -6,6 +6,9 @@ package models
 
 import (
 	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -15,12 +18,23 @@ import (
 
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/secret"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/sync"
 )
 
-// MirrorQueue holds an UniqueQueue object of the mirror
-var MirrorQueue = sync.NewUniqueQueue(setting.Repository.MirrorQueueLength)
+// MirrorQueue holds the queue of the mirror
+var MirrorQueue sync.Queue = sync.NewUniqueQueue(setting.Repository.MirrorQueueLength)
+
+// mirrorSyncing holds IDs of repositories whose mirror is being synchronized.
+var mirrorSyncing = sync.NewStatusTable()
+
+// Mirror synchronization states.
+const (
+	MirrorSyncIdle    = "idle"
+	MirrorSyncQueued  = "queued"
+	MirrorSyncRunning = "running"
+)
 
 // Mirror represents mirror information of a repository.
 type Mirror struct {
@@ -34,6 +48,17 @@ type Mirror struct {
 	UpdatedUnix    int64     `xorm:"INDEX"`
 	NextUpdate     time.Time `xorm:"-"`
 	NextUpdateUnix int64     `xorm:"INDEX"`
+	LastSync       time.Time `xorm:"-"`
+	LastSyncUnix   int64
+	LastSyncError  string `xorm:"TEXT"`
+	// LastSyncDuration is how long the last sync took and SyncFailures is
+	// the number of consecutive failed syncs.
+	LastSyncDuration time.Duration
+	SyncFailures     int `xorm:"NOT NULL DEFAULT 0"`
+
+	// Credentials of the mirror address encrypted with the secret key,
+	// they are never written to the Git repository config.
+	Credentials string `xorm:"TEXT"`
 
 	address string `xorm:"-"`
 }
@@ -63,25 +88,116 @@ func (m *Mirror) AfterSet(colName string, _ xorm.Cell) {
 		m.Updated = time.Unix(m.UpdatedUnix, 0).Local()
 	case "next_update_unix":
 		m.NextUpdate = time.Unix(m.NextUpdateUnix, 0).Local()
+	case "last_sync_unix":
+		m.LastSync = time.Unix(m.LastSyncUnix, 0).Local()
 	}
 }
 
+// SyncState returns the current synchronization state of the mirror and its
+// position in the sync queue if it is queued.
+func (m *Mirror) SyncState() (string, int) {
+	if mirrorSyncing.IsRunning(com.ToStr(m.RepoID)) {
+		return MirrorSyncRunning, 0
+	} else if pos := MirrorQueue.Position(m.RepoID); pos > 0 {
+		return MirrorSyncQueued, pos
+	}
+	return MirrorSyncIdle, 0
+}
+
 // ScheduleNextUpdate calculates and sets next update time.
 func (m *Mirror) ScheduleNextUpdate() {
 	m.NextUpdate = time.Now().Add(m.Interval)
 }
 
+func readRemoteAddress(configPath string) (string, error) {
+	cfg, err := ini.Load(configPath)
+	if err != nil {
+		return "", err
+	}
+	return cfg.Section("remote \"origin\"").Key("url").Value(), nil
+}
+
+func saveRemoteAddress(configPath, addr string) error {
+	cfg, err := ini.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("Load: %v", err)
+	}
+
+	cfg.Section("remote \"origin\"").Key("url").SetValue(addr)
+	return cfg.SaveToIndent(configPath, "\t")
+}
+
 func (m *Mirror) readAddress() {
 	if len(m.address) > 0 {
 		return
 	}
 
-	cfg, err := ini.Load(m.Repo.GitConfigPath())
+	var err error
+	m.address, err = readRemoteAddress(m.Repo.GitConfigPath())
 	if err != nil {
 		log.Error(4, "Load: %v", err)
-		return
 	}
-	m.address = cfg.Section("remote \"origin\"").Key("url").Value()
+}
+
+// splitCredentials returns the address without user credentials and
+// the credentials it contains, if any.
+func splitCredentials(addr string) (string, *url.Userinfo) {
+	u, err := url.Parse(addr)
+	if err != nil || u.User == nil {
+		return addr, nil
+	}
+	user := u.User
+	u.User = nil
+	return u.String(), user
+}
+
+// withCredentials returns the address with given user credentials.
+func withCredentials(addr string, user *url.Userinfo) string {
+	if user == nil {
+		return addr
+	}
+	u, err := url.Parse(addr)
+	if err != nil || len(u.Host) == 0 {
+		return addr
+	}
+	u.User = user
+	return u.String()
+}
+
+// userinfo returns the decrypted credentials of the mirror address.
+func (m *Mirror) userinfo() *url.Userinfo {
+	if len(m.Credentials) == 0 {
+		return nil
+	}
+
+	credentials, err := secret.DecryptSecret(setting.SecretKey, m.Credentials)
+	if err != nil {
+		log.Error(4, "DecryptSecret [repo_id: %d]: %v", m.RepoID, err)
+		return nil
+	}
+	u, err := url.Parse("//" + credentials + "@localhost")
+	if err != nil {
+		log.Error(4, "Parse credentials [repo_id: %d]: %v", m.RepoID, err)
+		return nil
+	}
+	return u.User
+}
+
+// Username returns the user name of the credentials of the mirror address.
+func (m *Mirror) Username() string {
+	if user := m.userinfo(); user != nil {
+		return user.Username()
+	}
+	return ""
+}
+
+// HasPassword returns true if the credentials of the mirror address contain a password.
+func (m *Mirror) HasPassword() bool {
+	if user := m.userinfo(); user != nil {
+		_, has := user.Password()
+		return has
+	}
+	return false
 }
 
 // HandleCloneUserCredentials replaces user credentials from HTTP/HTTPS URL
@@ -108,22 +224,101 @@ func (m *Mirror) Address() string {
 	return HandleCloneUserCredentials(m.address, false)
 }
 
-// FullAddress returns mirror address from Git repository config.
+// FullAddress returns mirror address from Git repository config
+// along with the decrypted user credentials.
 func (m *Mirror) FullAddress() string {
 	m.readAddress()
-	return m.address
+	return withCredentials(m.address, m.userinfo())
 }
 
-// SaveAddress writes new address to Git repository config.
-func (m *Mirror) SaveAddress(addr string) error {
-	configPath := m.Repo.GitConfigPath()
-	cfg, err := ini.Load(configPath)
-	if err != nil {
-		return fmt.Errorf("Load: %v", err)
+// SaveAddress writes new address without user credentials to Git repository
+// config of the repository and its wiki, and stores the credentials encrypted.
+func (m *Mirror) SaveAddress(addr string) (err error) {
+	addr, user := splitCredentials(addr)
+	if err = saveRemoteAddress(m.Repo.GitConfigPath(), addr); err != nil {
+		return err
 	}
+	m.address = addr
 
-	cfg.Section("remote \"origin\"").Key("url").SetValue(addr)
-	return cfg.SaveToIndent(configPath, "\t")
+	if m.Repo.HasWiki() {
+		wikiConfigPath := filepath.Join(m.Repo.WikiPath(), "config")
+		wikiAddr, err := readRemoteAddress(wikiConfigPath)
+		if err != nil {
+			return fmt.Errorf("Load: %v", err)
+		}
+		wikiAddr, _ = splitCredentials(wikiAddr)
+		if err = saveRemoteAddress(wikiConfigPath, wikiAddr); err != nil {
+			return err
+		}
+	}
+
+	m.Credentials = ""
+	if user != nil {
+		if m.Credentials, err = secret.EncryptSecret(setting.SecretKey, user.String()); err != nil {
+			return fmt.Errorf("EncryptSecret: %v", err)
+		}
+	}
+	_, err = x.Id(m.ID).Cols("credentials").Update(m)
+	return err
+}
+
+// UpdateAddress saves new address of the mirror. Given user name and password
+// are used if the address has no credentials, an empty password keeps the
+// current one if the user name is not changed.
+func (m *Mirror) UpdateAddress(addr, username, password string) error {
+	if u, err := url.Parse(addr); err == nil && u.User == nil && len(u.Host) > 0 && len(username) > 0 {
+		if len(password) == 0 && username == m.Username() {
+			password, _ = m.userinfo().Password()
+		}
+		if len(password) > 0 {
+			u.User = url.UserPassword(username, password)
+		} else {
+			u.User = url.User(username)
+		}
+		addr = u.String()
+	}
+	return m.SaveAddress(addr)
+}
+
+// maskCredentials replaces the password of given credentials in the output
+// of a Git command.
+func maskCredentials(output string, user *url.Userinfo) string {
+	if user == nil {
+		return output
+	}
+	if password, has := user.Password(); has && len(password) > 0 {
+		output = strings.Replace(output, url.UserPassword("", password).String()[1:], "<credentials>", -1)
+		output = strings.Replace(output, password, "<credentials>", -1)
+	}
+	return output
+}
+
+// mirrorCredentialHelper is a Git credential helper answering with the
+// credentials given in the environment of the Git process, so they are not
+// visible in its arguments.
+const mirrorCredentialHelper = `!f() { test "$1" = get && echo "username=$GITEA_MIRROR_USERNAME" && echo "password=$GITEA_MIRROR_PASSWORD"; }; f`
+
+// gitCredentialArgs returns the arguments and the environment of a Git
+// command run with given credentials, the previous credential helpers are
+// ignored.
+func gitCredentialArgs(user *url.Userinfo, args ...string) ([]string, []string) {
+	if user == nil {
+		return args, nil
+	}
+	password, _ := user.Password()
+	env := append(os.Environ(),
+		"GITEA_MIRROR_USERNAME="+user.Username(),
+		"GITEA_MIRROR_PASSWORD="+password)
+	return append([]string{"-c", "credential.helper=", "-c", "credential.helper=" + mirrorCredentialHelper}, args...), env
+}
+
+// syncErrorMessage returns the message recorded for a failed sync, which is
+// the error itself if Git could not be run and wrote nothing to stderr.
+func syncErrorMessage(stderr string, err error) string {
+	if len(strings.TrimSpace(stderr)) == 0 {
+		return err.Error()
+	}
+	return stderr
 }
 
 // runSync returns true if sync finished without error.
@@ -131,15 +326,22 @@ func (m *Mirror) runSync() bool {
 	repoPath := m.Repo.RepoPath()
 	wikiPath := m.Repo.WikiPath()
 	timeout := time.Duration(setting.Git.Timeout.Mirror) * time.Second
+	m.LastSyncError = ""
 
+	// Credentials are not stored in the Git config, they are given to Git by
+	// a credential helper.
+	user := m.userinfo()
 	gitArgs := []string{"remote", "update"}
 	if m.EnablePrune {
 		gitArgs = append(gitArgs, "--prune")
 	}
+	gitArgs, env := gitCredentialArgs(user, gitArgs...)
 
-	if _, stderr, err := process.GetManager().ExecDir(
-		timeout, repoPath, fmt.Sprintf("Mirror.runSync: %s", repoPath),
+	if _, stderr, err := process.GetManager().ExecDirEnv(
+		timeout, repoPath, fmt.Sprintf("Mirror.runSync: %s", repoPath), env,
 		"git", gitArgs...); err != nil {
+		stderr = maskCredentials(syncErrorMessage(stderr, err), user)
+		m.LastSyncError = stderr
 		desc := fmt.Sprintf("Failed to update mirror repository '%s': %s", repoPath, stderr)
 		log.Error(4, desc)
 		if err = CreateRepositoryNotice(desc); err != nil {
@@ -153,9 +355,12 @@ func (m *Mirror) runSync() bool {
 	}
 
 	if m.Repo.HasWiki() {
-		if _, stderr, err := process.GetManager().ExecDir(
-			timeout, wikiPath, fmt.Sprintf("Mirror.runSync: %s", wikiPath),
-			"git", "remote", "update", "--prune"); err != nil {
+		gitArgs, env = gitCredentialArgs(user, "remote", "update", "--prune")
+		if _, stderr, err := process.GetManager().ExecDirEnv(
+			timeout, wikiPath, fmt.Sprintf("Mirror.runSync: %s", wikiPath), env,
+			"git", gitArgs...); err != nil {
+			stderr = maskCredentials(syncErrorMessage(stderr, err), user)
+			m.LastSyncError = stderr
 			desc := fmt.Sprintf("Failed to update mirror wiki repository '%s': %s", wikiPath, stderr)
 			log.Error(4, desc)
 			if err = CreateRepositoryNotice(desc); err != nil {
@@ -216,6 +421,8 @@ func MirrorUpdate() {
 			if m.Repo == nil {
 				log.Error(4, "Disconnected mirror repository found: %d", m.ID)
 				return nil
+			} else if m.Repo.DeletedUnix > 0 {
+				return nil
 			}
 
 			MirrorQueue.Add(m.RepoID)
@@ -225,29 +432,81 @@ func MirrorUpdate() {
 	}
 }
 
+// syncFailureRecipients returns the users notified of repeated sync failures
+// of the mirror, which are the owner of a user repository or the members of
+// the owner team of an organization repository.
+func (m *Mirror) syncFailureRecipients() ([]*User, error) {
+	if err := m.Repo.GetOwner(); err != nil {
+		return nil, fmt.Errorf("GetOwner: %v", err)
+	}
+	if !m.Repo.Owner.IsOrganization() {
+		return []*User{m.Repo.Owner}, nil
+	}
+
+	t, err := m.Repo.Owner.GetOwnerTeam()
+	if err != nil {
+		return nil, fmt.Errorf("GetOwnerTeam: %v", err)
+	} else if err = t.GetMembers(); err != nil {
+		return nil, fmt.Errorf("GetMembers: %v", err)
+	}
+	return t.Members, nil
+}
+
+// notifySyncFailure sends the last sync error of the mirror to the owners of
+// the repository.
+func (m *Mirror) notifySyncFailure() {
+	users, err := m.syncFailureRecipients()
+	if err != nil {
+		log.Error(4, "syncFailureRecipients [%d]: %v", m.RepoID, err)
+		return
+	}
+	for _, u := range users {
+		SendMirrorSyncFailureMail(u, m)
+	}
+}
+
+// syncMirror syncs the mirror of given repository and records the result.
+func syncMirror(repoID string) {
+	m, err := GetMirrorByRepoID(com.StrTo(repoID).MustInt64())
+	if err != nil {
+		log.Error(4, "GetMirrorByRepoID [%s]: %v", repoID, err)
+		return
+	}
+
+	mirrorSyncing.Start(repoID)
+	start := time.Now()
+	ok := m.runSync()
+	// Millisecond precision keeps the displayed duration readable.
+	m.LastSyncDuration = time.Since(start) / time.Millisecond * time.Millisecond
+	mirrorSyncing.Stop(repoID)
+
+	m.LastSyncUnix = time.Now().Unix()
+	if !ok {
+		m.SyncFailures++
+		if _, err = x.Id(m.ID).Cols("last_sync_unix", "last_sync_error", "last_sync_duration", "sync_failures").Update(m); err != nil {
+			log.Error(4, "Update mirror sync result [%s]: %v", repoID, err)
+		}
+		if m.SyncFailures == setting.Mirror.NotifyFailures {
+			m.notifySyncFailure()
+		}
+		return
+	}
+
+	m.SyncFailures = 0
+	m.ScheduleNextUpdate()
+	if err = UpdateMirror(m); err != nil {
+		log.Error(4, "UpdateMirror [%s]: %v", repoID, err)
+	}
+}
+
 // SyncMirrors checks and syncs mirrors.
 // TODO: sync more mirrors at same time.
 func SyncMirrors() {
 	// Start listening on new sync requests.
 	for repoID := range MirrorQueue.Queue() {
 		log.Trace("SyncMirrors [repo_id: %v]", repoID)
+		syncMirror(repoID)
 		MirrorQueue.Remove(repoID)
-
-		m, err := GetMirrorByRepoID(com.StrTo(repoID).MustInt64())
-		if err != nil {
-			log.Error(4, "GetMirrorByRepoID [%s]: %v", repoID, err)
-			continue
-		}
-
-		if !m.runSync() {
-			continue
-		}
-
-		m.ScheduleNextUpdate()
-		if err = UpdateMirror(m); err != nil {
-			log.Error(4, "UpdateMirror [%s]: %v", repoID, err)
-			continue
-		}
 	}
 }
 
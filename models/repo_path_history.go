@@ -0,0 +1,121 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/git"
+)
+
+// PathCommit represents a commit which changed a path, along with the name
+// of the path in that commit when renames are followed.
+type PathCommit struct {
+	*git.Commit
+	Path    string
+	OldPath string // Previous name of the path if it was renamed by this commit
+	Status  string // Status letter of the change as reported by git, e.g. A, M, D or R
+}
+
+// PathHistoryOptions contains the options of a path history search.
+type PathHistoryOptions struct {
+	Revision string
+	Path     string
+	Follow   bool
+	Page     int
+	PageSize int
+}
+
+// pathHistoryItem is one commit parsed from the git log output.
+type pathHistoryItem struct {
+	ID      string
+	Path    string
+	OldPath string
+	Status  string
+}
+
+// parsePathHistory parses the output of git log with --name-status, commits
+// without a reported change keep the name the path had in the newer commit.
+func parsePathHistory(stdout, path string, follow bool) []*pathHistoryItem {
+	items := make([]*pathHistoryItem, 0, 10)
+	var cur *pathHistoryItem
+	for _, line := range strings.Split(stdout, "\n") {
+		if len(line) == 0 {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) == 1 {
+			cur = &pathHistoryItem{
+				ID:   line,
+				Path: path,
+			}
+			items = append(items, cur)
+			continue
+		} else if cur == nil || len(cur.Status) > 0 {
+			continue
+		}
+
+		// With --follow git only reports the followed file, whose name may
+		// differ from the requested path in older commits.
+		newPath := fields[len(fields)-1]
+		if !follow && newPath != path {
+			continue
+		}
+		cur.Status = fields[0][:1]
+		cur.Path = newPath
+		if len(fields) == 3 {
+			cur.OldPath = fields[1]
+			if follow {
+				path = cur.OldPath
+			}
+		}
+	}
+	return items
+}
+
+// GetPathHistory returns a page of commits which changed given path, starting
+// at given revision. Renames of a single file are followed if requested.
+func GetPathHistory(gitRepo *git.Repository, opts *PathHistoryOptions) ([]*PathCommit, error) {
+	if opts.Page < 1 {
+		opts.Page = 1
+	}
+	if opts.PageSize < 1 {
+		opts.PageSize = git.CommitsRangeSize
+	}
+	follow := opts.Follow && len(opts.Path) > 0
+
+	cmd := git.NewCommand("log", opts.Revision, "--format=format:%H",
+		fmt.Sprintf("--skip=%d", (opts.Page-1)*opts.PageSize),
+		fmt.Sprintf("--max-count=%d", opts.PageSize))
+	if follow {
+		cmd.AddArguments("--follow")
+	}
+	if len(opts.Path) > 0 {
+		cmd.AddArguments("--name-status", "--", opts.Path)
+	}
+
+	stdout, err := cmd.RunInDir(gitRepo.Path)
+	if err != nil {
+		return nil, fmt.Errorf("git log: %v", err)
+	}
+
+	items := parsePathHistory(stdout, opts.Path, follow)
+	commits := make([]*PathCommit, len(items))
+	for i, item := range items {
+		commit, err := gitRepo.GetCommit(item.ID)
+		if err != nil {
+			return nil, fmt.Errorf("GetCommit [%s]: %v", item.ID, err)
+		}
+		commits[i] = &PathCommit{
+			Commit:  commit,
+			Path:    item.Path,
+			OldPath: item.OldPath,
+			Status:  item.Status,
+		}
+	}
+	return commits, nil
+}
@@ -0,0 +1,34 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePathHistory(t *testing.T) {
+	stdout := "6ca493ca3aabb26b0c20cb7885024be82ee9789b\n" +
+		"M\tb.txt\n\n" +
+		"d2e5adc414b775e840b564364aa4081a46087936\n" +
+		"R100\ta.txt\tb.txt\n\n" +
+		"2b0a1e3c5d9f3f1c3a7e1d0b6f3a8c9d0e1f2a3b\n" +
+		"750163634861f077bfd5d0566b9c767171a6d003\n" +
+		"A\ta.txt"
+
+	items := parsePathHistory(stdout, "b.txt", true)
+	if assert.Len(t, items, 4) {
+		assert.Equal(t, &pathHistoryItem{ID: "6ca493ca3aabb26b0c20cb7885024be82ee9789b", Path: "b.txt", Status: "M"}, items[0])
+		assert.Equal(t, &pathHistoryItem{ID: "d2e5adc414b775e840b564364aa4081a46087936", Path: "b.txt", OldPath: "a.txt", Status: "R"}, items[1])
+		assert.Equal(t, &pathHistoryItem{ID: "2b0a1e3c5d9f3f1c3a7e1d0b6f3a8c9d0e1f2a3b", Path: "a.txt"}, items[2])
+		assert.Equal(t, &pathHistoryItem{ID: "750163634861f077bfd5d0566b9c767171a6d003", Path: "a.txt", Status: "A"}, items[3])
+	}
+
+	items = parsePathHistory("6ca493ca3aabb26b0c20cb7885024be82ee9789b\nM\tdir/a.txt\nA\tdir/b.txt\n", "dir", false)
+	if assert.Len(t, items, 1) {
+		assert.Equal(t, &pathHistoryItem{ID: "6ca493ca3aabb26b0c20cb7885024be82ee9789b", Path: "dir"}, items[0])
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/git"
+	"code.gitea.io/gitea/modules/sync"
+
+	"github.com/Unknwon/com"
+)
+
+var repoStatsPool = sync.NewExclusivePool()
+
+// ContributorWeek represents the activity of one contributor of a repository
+// during one week, weeks start on Sunday 00:00 UTC.
+type ContributorWeek struct {
+	ID        int64  `xorm:"pk autoincr"`
+	RepoID    int64  `xorm:"INDEX UNIQUE(s)"`
+	Email     string `xorm:"UNIQUE(s) NOT NULL"`
+	Name      string
+	Week      int64 `xorm:"UNIQUE(s)"`
+	Commits   int64
+	Additions int64
+	Deletions int64
+}
+
+// Percent returns the commits of this week relative to given maximum.
+func (w *ContributorWeek) Percent(max int64) int64 {
+	if max <= 0 {
+		return 0
+	}
+	return w.Commits * 100 / max
+}
+
+// RepoStatsStatus records the commit which the cached statistics of a
+// repository have been computed for.
+type RepoStatsStatus struct {
+	ID          int64  `xorm:"pk autoincr"`
+	RepoID      int64  `xorm:"UNIQUE"`
+	CommitSha   string `xorm:"VARCHAR(40)"`
+	UpdatedUnix int64
+}
+
+// ContributorStats represents the statistics of one contributor of a repository.
+type ContributorStats struct {
+	Name      string
+	Email     string
+	Total     int64
+	Additions int64
+	Deletions int64
+	Weeks     []*ContributorWeek
+}
+
+// MaxWeekCommits returns the highest number of commits within one week.
+func (s *ContributorStats) MaxWeekCommits() int64 {
+	var max int64
+	for _, w := range s.Weeks {
+		if w.Commits > max {
+			max = w.Commits
+		}
+	}
+	return max
+}
+
+func weekStart(unix int64) int64 {
+	t := time.Unix(unix, 0).UTC()
+	t = t.AddDate(0, 0, -int(t.Weekday()))
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix()
+}
+
+const secondsPerWeek = 7 * 24 * 60 * 60
+
+func nextWeek(week int64) int64 {
+	return weekStart(week + secondsPerWeek)
+}
+
+const contributorLogHeader = "\x00"
+
+// parseContributorLog parses output of "git log --numstat" produced with
+// format "%x00%aN%x00%aE%x00%at" into per-week activity of every author.
+func parseContributorLog(repoID int64, log string) ([]*ContributorWeek, error) {
+	weeks := make(map[string]*ContributorWeek)
+	var current *ContributorWeek
+	for _, line := range strings.Split(log, "\n") {
+		if len(line) == 0 {
+			continue
+		}
+
+		if strings.HasPrefix(line, contributorLogHeader) {
+			fields := strings.Split(line[1:], contributorLogHeader)
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("malformed commit header: %q", line)
+			}
+			unix, err := strconv.ParseInt(fields[2], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("malformed commit time: %q", fields[2])
+			}
+			email := strings.ToLower(fields[1])
+			week := weekStart(unix)
+			key := email + contributorLogHeader + com.ToStr(week)
+			current = weeks[key]
+			if current == nil {
+				current = &ContributorWeek{
+					RepoID: repoID,
+					Email:  email,
+					Name:   fields[0],
+					Week:   week,
+				}
+				weeks[key] = current
+			}
+			current.Commits++
+			continue
+		}
+
+		if current == nil {
+			return nil, fmt.Errorf("numstat line without commit: %q", line)
+		}
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		// Binary files are reported as "-".
+		additions, _ := strconv.ParseInt(fields[0], 10, 64)
+		deletions, _ := strconv.ParseInt(fields[1], 10, 64)
+		current.Additions += additions
+		current.Deletions += deletions
+	}
+
+	list := make([]*ContributorWeek, 0, len(weeks))
+	for _, w := range weeks {
+		list = append(list, w)
+	}
+	return list, nil
+}
+
+func generateContributorWeeks(repo *Repository, commitID string) ([]*ContributorWeek, error) {
+	stdout, err := git.NewCommand("log", "--no-merges", "--numstat",
+		"--format=%x00%aN%x00%aE%x00%at", commitID).RunInDir(repo.RepoPath())
+	if err != nil {
+		return nil, fmt.Errorf("git log: %v", err)
+	}
+	return parseContributorLog(repo.ID, stdout)
+}
+
+// updateContributorStats recomputes cached statistics if the default branch
+// has moved since they were last generated.
+func updateContributorStats(repo *Repository) error {
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	commitID, err := gitRepo.GetBranchCommitID(repo.DefaultBranch)
+	if err != nil {
+		return fmt.Errorf("GetBranchCommitID: %v", err)
+	}
+
+	status := &RepoStatsStatus{RepoID: repo.ID}
+	has, err := x.Get(status)
+	if err != nil {
+		return err
+	} else if has && status.CommitSha == commitID {
+		return nil
+	}
+
+	weeks, err := generateContributorWeeks(repo, commitID)
+	if err != nil {
+		return err
+	}
+
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err = sess.Delete(&ContributorWeek{RepoID: repo.ID}); err != nil {
+		return fmt.Errorf("delete old stats: %v", err)
+	}
+	for _, w := range weeks {
+		if _, err = sess.Insert(w); err != nil {
+			return fmt.Errorf("insert stats: %v", err)
+		}
+	}
+
+	status.CommitSha = commitID
+	status.UpdatedUnix = time.Now().Unix()
+	if has {
+		_, err = sess.Id(status.ID).Cols("commit_sha", "updated_unix").Update(status)
+	} else {
+		_, err = sess.Insert(status)
+	}
+	if err != nil {
+		return fmt.Errorf("update stats status: %v", err)
+	}
+	return sess.Commit()
+}
+
+// GetContributorStats returns statistics of all contributors to the default
+// branch of the repository, ordered by number of commits.
+func (repo *Repository) GetContributorStats() ([]*ContributorStats, error) {
+	if repo.IsBare {
+		return []*ContributorStats{}, nil
+	}
+
+	repoStatsPool.CheckIn(com.ToStr(repo.ID))
+	err := updateContributorStats(repo)
+	repoStatsPool.CheckOut(com.ToStr(repo.ID))
+	if err != nil {
+		return nil, fmt.Errorf("updateContributorStats: %v", err)
+	}
+
+	weeks := make([]*ContributorWeek, 0, 50)
+	if err = x.
+		Where("repo_id = ?", repo.ID).
+		Asc("email", "week").
+		Find(&weeks); err != nil {
+		return nil, err
+	}
+
+	stats := make([]*ContributorStats, 0, 10)
+	var current *ContributorStats
+	for _, w := range weeks {
+		if current == nil || current.Email != w.Email {
+			current = &ContributorStats{
+				Name:  w.Name,
+				Email: w.Email,
+			}
+			stats = append(stats, current)
+		}
+		current.Total += w.Commits
+		current.Additions += w.Additions
+		current.Deletions += w.Deletions
+		current.Weeks = append(current.Weeks, w)
+	}
+
+	// Fill the gaps so every contributor covers the same continuous range of weeks.
+	if len(weeks) > 0 {
+		first, last := weeks[0].Week, weeks[0].Week
+		for _, w := range weeks {
+			if w.Week < first {
+				first = w.Week
+			}
+			if w.Week > last {
+				last = w.Week
+			}
+		}
+		for _, s := range stats {
+			filled := make([]*ContributorWeek, 0, (last-first)/secondsPerWeek+1)
+			i := 0
+			for week := first; week <= last; week = nextWeek(week) {
+				if i < len(s.Weeks) && s.Weeks[i].Week == week {
+					filled = append(filled, s.Weeks[i])
+					i++
+					continue
+				}
+				filled = append(filled, &ContributorWeek{
+					RepoID: repo.ID,
+					Email:  s.Email,
+					Name:   s.Name,
+					Week:   week,
+				})
+			}
+			s.Weeks = filled
+		}
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].Total > stats[j].Total
+	})
+	return stats, nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeekStart(t *testing.T) {
+	// Wednesday, 2017-06-14 12:00:00 UTC
+	assert.EqualValues(t, 1497139200, weekStart(1497441600))
+	// Sunday, 2017-06-11 00:00:00 UTC
+	assert.EqualValues(t, 1497139200, weekStart(1497139200))
+}
+
+func TestParseContributorLog(t *testing.T) {
+	log := "\x00User Two\x00User2@example.com\x001497441600\n" +
+		"3\t1\tREADME.md\n" +
+		"-\t-\tlogo.png\n" +
+		"\n" +
+		"\x00User Two\x00user2@example.com\x001497139200\n" +
+		"10\t0\tmain.go\n" +
+		"\n" +
+		"\x00User Three\x00user3@example.com\x001496534400\n"
+
+	weeks, err := parseContributorLog(1, log)
+	assert.NoError(t, err)
+	assert.Len(t, weeks, 2)
+
+	for _, w := range weeks {
+		assert.EqualValues(t, 1, w.RepoID)
+		switch w.Email {
+		case "user2@example.com":
+			assert.EqualValues(t, 1497139200, w.Week)
+			assert.EqualValues(t, 2, w.Commits)
+			assert.EqualValues(t, 13, w.Additions)
+			assert.EqualValues(t, 1, w.Deletions)
+		case "user3@example.com":
+			assert.Equal(t, "User Three", w.Name)
+			assert.EqualValues(t, 1, w.Commits)
+			assert.EqualValues(t, 0, w.Additions)
+		default:
+			t.Errorf("unexpected contributor: %s", w.Email)
+		}
+	}
+
+	_, err = parseContributorLog(1, "1\t2\tfile\n")
+	assert.Error(t, err)
+}
@@ -115,6 +115,32 @@ func TestGetUserFork(t *testing.T) {
 	assert.Nil(t, repo)
 }
 
+func TestRepository_GetForks(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 10}).(*Repository)
+
+	forks, err := repo.GetForks(nil)
+	assert.NoError(t, err)
+	assert.Len(t, forks, 1)
+
+	// Private forks are listed only to those who can read them.
+	_, err = x.Id(11).Cols("is_private").Update(&Repository{IsPrivate: true})
+	assert.NoError(t, err)
+	for _, test := range []struct {
+		doer     *User
+		expected int
+	}{
+		{nil, 0},
+		{AssertExistsAndLoadBean(t, &User{ID: 2}).(*User), 0},
+		{AssertExistsAndLoadBean(t, &User{ID: 13}).(*User), 1}, // owner
+		{AssertExistsAndLoadBean(t, &User{ID: 1}).(*User), 1},  // admin
+	} {
+		forks, err = repo.GetForks(test.doer)
+		assert.NoError(t, err)
+		assert.Len(t, forks, test.expected)
+	}
+}
+
 func TestForkRepository(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 
@@ -154,3 +180,27 @@ func TestRepoLocalCopyPath(t *testing.T) {
 	setting.Repository.Local.LocalCopyPath = tempPath
 	assert.Equal(t, expected, repo.LocalCopyPath())
 }
+
+func TestPushCreateRepo_NotAllowed(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	org3 := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
+	user4 := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+
+	_, err := PushCreateRepo(user2, user2, "new-repo")
+	assert.True(t, IsErrPushCreateNotAllowed(err))
+
+	setting.Repository.EnablePushCreateUser = true
+	setting.Repository.EnablePushCreateOrg = true
+	defer func() {
+		setting.Repository.EnablePushCreateUser = false
+		setting.Repository.EnablePushCreateOrg = false
+	}()
+
+	// Only the owner of the user, or an owner of the organization, can create repositories.
+	_, err = PushCreateRepo(user4, user2, "new-repo")
+	assert.True(t, IsErrPushCreateNotAllowed(err))
+	_, err = PushCreateRepo(user4, org3, "new-repo")
+	assert.True(t, IsErrPushCreateNotAllowed(err))
+	AssertNotExistsBean(t, &Repository{LowerName: "new-repo"})
+}
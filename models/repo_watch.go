@@ -6,11 +6,68 @@ package models
 
 import "fmt"
 
+// RepoWatchMode specifies what activity of a repository a watcher is notified of.
+type RepoWatchMode int
+
+// Possible watch modes.
+const (
+	RepoWatchModeAll      RepoWatchMode = iota // 0
+	RepoWatchModeIssues                        // 1
+	RepoWatchModeReleases                      // 2
+	RepoWatchModeIgnore                        // 3
+)
+
+var repoWatchModeNames = map[RepoWatchMode]string{
+	RepoWatchModeAll:      "all",
+	RepoWatchModeIssues:   "issues",
+	RepoWatchModeReleases: "releases",
+	RepoWatchModeIgnore:   "ignore",
+}
+
+// String returns the name of the watch mode.
+func (mode RepoWatchMode) String() string {
+	return repoWatchModeNames[mode]
+}
+
+// ParseRepoWatchMode returns the watch mode of given name.
+func ParseRepoWatchMode(name string) (RepoWatchMode, bool) {
+	for mode, modeName := range repoWatchModeNames {
+		if modeName == name {
+			return mode, true
+		}
+	}
+	return RepoWatchModeAll, false
+}
+
 // Watch is connection request for receiving repository notification.
 type Watch struct {
-	ID     int64 `xorm:"pk autoincr"`
-	UserID int64 `xorm:"UNIQUE(watch)"`
-	RepoID int64 `xorm:"UNIQUE(watch)"`
+	ID     int64         `xorm:"pk autoincr"`
+	UserID int64         `xorm:"UNIQUE(watch)"`
+	RepoID int64         `xorm:"UNIQUE(watch)"`
+	Mode   RepoWatchMode `xorm:"NOT NULL DEFAULT 0"`
+}
+
+// AcceptsIssues returns true if the watcher is notified of issues and pull requests.
+func (w *Watch) AcceptsIssues() bool {
+	return w.Mode == RepoWatchModeAll || w.Mode == RepoWatchModeIssues
+}
+
+// AcceptsAction returns true if the watcher receives feeds of given action type.
+func (w *Watch) AcceptsAction(opType ActionType) bool {
+	switch w.Mode {
+	case RepoWatchModeAll:
+		return true
+	case RepoWatchModeIssues:
+		switch opType {
+		case ActionCreateIssue, ActionCreatePullRequest, ActionCommentIssue,
+			ActionMergePullRequest, ActionCloseIssue, ActionReopenIssue,
+			ActionClosePullRequest, ActionReopenPullRequest:
+			return true
+		}
+	case RepoWatchModeReleases:
+		return opType == ActionPushTag
+	}
+	return false
 }
 
 func isWatching(e Engine, userID, repoID int64) bool {
@@ -36,7 +93,7 @@ func watchRepo(e Engine, userID, repoID int64, watch bool) (err error) {
 		if !isWatching(e, userID, repoID) {
 			return nil
 		}
-		if _, err = e.Delete(&Watch{0, userID, repoID}); err != nil {
+		if _, err = e.Delete(&Watch{UserID: userID, RepoID: repoID}); err != nil {
 			return err
 		}
 		_, err = e.Exec("UPDATE `repository` SET num_watches = num_watches - 1 WHERE id = ?", repoID)
@@ -49,6 +106,43 @@ func WatchRepo(userID, repoID int64, watch bool) (err error) {
 	return watchRepo(x, userID, repoID, watch)
 }
 
+func getWatch(e Engine, userID, repoID int64) (*Watch, error) {
+	watch := &Watch{UserID: userID, RepoID: repoID}
+	has, err := e.Get(watch)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return watch, nil
+}
+
+// GetWatch returns the watch of the user on given repository,
+// or nil if the user does not watch it.
+func GetWatch(userID, repoID int64) (*Watch, error) {
+	return getWatch(x, userID, repoID)
+}
+
+// SetWatchMode watches the repository as the user with given mode.
+func SetWatchMode(userID, repoID int64, mode RepoWatchMode) (err error) {
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if err = watchRepo(sess, userID, repoID, true); err != nil {
+		return err
+	}
+	if _, err = sess.
+		Where("user_id = ? AND repo_id = ?", userID, repoID).
+		Cols("mode").
+		Update(&Watch{Mode: mode}); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
 func getWatchers(e Engine, repoID int64) ([]*Watch, error) {
 	watches := make([]*Watch, 0, 10)
 	return watches, e.Find(&watches, &Watch{RepoID: repoID})
@@ -84,7 +178,7 @@ func notifyWatchers(e Engine, act *Action) error {
 	}
 
 	for i := range watches {
-		if act.ActUserID == watches[i].UserID {
+		if act.ActUserID == watches[i].UserID || !watches[i].AcceptsAction(act.OpType) {
 			continue
 		}
 
@@ -96,3 +96,68 @@ func TestNotifyWatchers(t *testing.T) {
 		OpType:    action.OpType,
 	})
 }
+
+func TestSetWatchMode(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	const repoID = 3
+	const userID = 2
+
+	assert.NoError(t, SetWatchMode(userID, repoID, RepoWatchModeIssues))
+	AssertExistsAndLoadBean(t, &Watch{RepoID: repoID, UserID: userID, Mode: RepoWatchModeIssues})
+	CheckConsistencyFor(t, &Repository{ID: repoID})
+
+	assert.NoError(t, SetWatchMode(userID, repoID, RepoWatchModeAll))
+	watch, err := GetWatch(userID, repoID)
+	assert.NoError(t, err)
+	assert.Equal(t, RepoWatchModeAll, watch.Mode)
+	CheckConsistencyFor(t, &Repository{ID: repoID})
+
+	watch, err = GetWatch(userID, NonexistentID)
+	assert.NoError(t, err)
+	assert.Nil(t, watch)
+}
+
+func TestWatch_AcceptsAction(t *testing.T) {
+	all := &Watch{Mode: RepoWatchModeAll}
+	assert.True(t, all.AcceptsAction(ActionCommitRepo))
+	assert.True(t, all.AcceptsIssues())
+
+	issues := &Watch{Mode: RepoWatchModeIssues}
+	assert.True(t, issues.AcceptsAction(ActionCommentIssue))
+	assert.False(t, issues.AcceptsAction(ActionPushTag))
+	assert.True(t, issues.AcceptsIssues())
+
+	releases := &Watch{Mode: RepoWatchModeReleases}
+	assert.True(t, releases.AcceptsAction(ActionPushTag))
+	assert.False(t, releases.AcceptsAction(ActionCreateIssue))
+	assert.False(t, releases.AcceptsIssues())
+
+	ignore := &Watch{Mode: RepoWatchModeIgnore}
+	assert.False(t, ignore.AcceptsAction(ActionPushTag))
+	assert.False(t, ignore.AcceptsIssues())
+}
+
+func TestNotifyWatchers_WatchMode(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	assert.NoError(t, SetWatchMode(4, 1, RepoWatchModeReleases))
+
+	action := &Action{
+		ActUserID: 8,
+		RepoID:    1,
+		OpType:    ActionStarRepo,
+	}
+	assert.NoError(t, NotifyWatchers(action))
+
+	AssertExistsAndLoadBean(t, &Action{
+		ActUserID: action.ActUserID,
+		UserID:    1,
+		RepoID:    action.RepoID,
+		OpType:    action.OpType,
+	})
+	AssertNotExistsBean(t, &Action{
+		ActUserID: action.ActUserID,
+		UserID:    4,
+		RepoID:    action.RepoID,
+		OpType:    action.OpType,
+	})
+}
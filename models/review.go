@@ -0,0 +1,156 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-xorm/xorm"
+)
+
+// ReviewType defines the verdict of a pull request review.
+type ReviewType int
+
+// Possible verdicts of a pull request review
+const (
+	ReviewTypeApprove ReviewType = iota + 1
+	ReviewTypeReject
+)
+
+// Review represents the latest verdict of a reviewer on a pull request.
+type Review struct {
+	ID         int64      `xorm:"pk autoincr"`
+	IssueID    int64      `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	ReviewerID int64      `xorm:"UNIQUE(s) NOT NULL"`
+	Reviewer   *User      `xorm:"-"`
+	Type       ReviewType `xorm:"NOT NULL"`
+	// CommitID is the head commit of the pull request the verdict was given for.
+	CommitID string `xorm:"VARCHAR(40)"`
+	// IsStale is set if new commits were pushed after the review and the base
+	// branch dismisses stale approvals.
+	IsStale bool `xorm:"-"`
+
+	Created     time.Time `xorm:"-"`
+	CreatedUnix int64     `xorm:"created"`
+	Updated     time.Time `xorm:"-"`
+	UpdatedUnix int64     `xorm:"updated"`
+}
+
+// AfterSet is invoked from XORM after setting the value of a field of this object.
+func (r *Review) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		r.Created = time.Unix(r.CreatedUnix, 0).Local()
+	case "updated_unix":
+		r.Updated = time.Unix(r.UpdatedUnix, 0).Local()
+	}
+}
+
+// IsApproval returns true if the reviewer approved the pull request.
+func (r *Review) IsApproval() bool {
+	return r.Type == ReviewTypeApprove
+}
+
+// SubmitReview records the verdict of doer on the current head commit of the
+// pull request, it replaces the previous verdict of doer. The poster of the
+// pull request cannot approve it.
+func SubmitReview(doer *User, pr *PullRequest, typ ReviewType) (*Review, error) {
+	if err := pr.LoadIssue(); err != nil {
+		return nil, err
+	} else if typ == ReviewTypeApprove && pr.Issue.PosterID == doer.ID {
+		return nil, ErrReviewSelfApproval{pr.ID, doer.ID}
+	}
+	commitID, err := pr.getHeadCommitID()
+	if err != nil {
+		return nil, err
+	}
+
+	review := &Review{IssueID: pr.IssueID, ReviewerID: doer.ID}
+	has, err := x.Get(review)
+	if err != nil {
+		return nil, err
+	}
+	review.Reviewer = doer
+	review.Type = typ
+	review.CommitID = commitID
+	if has {
+		_, err = x.Id(review.ID).Cols("type", "commit_id").Update(review)
+	} else {
+		_, err = x.Insert(review)
+	}
+	return review, err
+}
+
+// GetReviews returns the latest verdicts of the reviewers of the pull
+// request, stale ones are marked if the base branch dismisses them.
+func (pr *PullRequest) GetReviews() ([]*Review, error) {
+	reviews := make([]*Review, 0, 5)
+	if err := x.Where("issue_id = ?", pr.IssueID).Asc("updated_unix").Find(&reviews); err != nil {
+		return nil, err
+	}
+	if len(reviews) == 0 {
+		return reviews, nil
+	}
+
+	protectedBranch, err := GetProtectedBranchBy(pr.BaseRepoID, pr.BaseBranch)
+	if err != nil {
+		return nil, err
+	}
+	if protectedBranch != nil && protectedBranch.DismissStaleApprovals {
+		commitID, err := pr.getHeadCommitID()
+		if err != nil {
+			return nil, err
+		}
+		for _, review := range reviews {
+			review.IsStale = review.CommitID != commitID
+		}
+	}
+
+	for _, review := range reviews {
+		review.Reviewer, err = GetUserByID(review.ReviewerID)
+		if IsErrUserNotExist(err) {
+			review.Reviewer = NewGhostUser()
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return reviews, nil
+}
+
+// GetApprovals returns the number of approvals which count towards the
+// approvals required by the base branch and that number. Approvals of the
+// poster, stale approvals and approvals of users who cannot write to the base
+// repository are not counted.
+func (pr *PullRequest) GetApprovals() (approvals, required int64, err error) {
+	protectedBranch, err := GetProtectedBranchBy(pr.BaseRepoID, pr.BaseBranch)
+	if err != nil {
+		return 0, 0, err
+	} else if protectedBranch == nil || protectedBranch.RequiredApprovals == 0 {
+		return 0, 0, nil
+	}
+	if err = pr.LoadIssue(); err != nil {
+		return 0, 0, err
+	} else if err = pr.GetBaseRepo(); err != nil {
+		return 0, 0, err
+	}
+
+	reviews, err := pr.GetReviews()
+	if err != nil {
+		return 0, 0, fmt.Errorf("GetReviews: %v", err)
+	}
+	for _, review := range reviews {
+		if !review.IsApproval() || review.IsStale || review.ReviewerID == pr.Issue.PosterID {
+			continue
+		}
+		canWrite, err := hasAccess(x, review.ReviewerID, pr.BaseRepo, AccessModeWrite)
+		if err != nil {
+			return 0, 0, err
+		} else if canWrite {
+			approvals++
+		}
+	}
+	return approvals, protectedBranch.RequiredApprovals, nil
+}
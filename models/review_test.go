@@ -0,0 +1,84 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+func TestPullRequest_GetApprovals(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	root, err := ioutil.TempDir("", "pull-reviews")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+	oldRoot := setting.RepoRootPath
+	setting.RepoRootPath = filepath.Join(root, "repos")
+	defer func() { setting.RepoRootPath = oldRoot }()
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	createTestGitRepo(t, repo.RepoPath(), map[string]string{"README.md": "readme"})
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo.RepoPath()
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	git("branch", "branch2", "master")
+
+	assert.NoError(t, repo.AddProtectedBranch("master", true))
+	protectedBranch, err := GetProtectedBranchBy(repo.ID, "master")
+	assert.NoError(t, err)
+	assert.NoError(t, repo.ChangeProtectedBranchApprovals(protectedBranch.ID, 1, true))
+
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	poster := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	owner := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	reader := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+
+	_, err = SubmitReview(poster, pr, ReviewTypeApprove)
+	assert.True(t, IsErrReviewSelfApproval(err))
+	_, err = SubmitReview(reader, pr, ReviewTypeApprove)
+	assert.NoError(t, err)
+	approvals, required, err := pr.GetApprovals()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, approvals)
+	assert.EqualValues(t, 1, required)
+
+	_, err = SubmitReview(owner, pr, ReviewTypeApprove)
+	assert.NoError(t, err)
+	approvals, _, err = pr.GetApprovals()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, approvals)
+
+	// A new commit makes the approvals stale.
+	commitID := git("commit-tree", "-p", "branch2", "-m", "fix", "branch2^{tree}")
+	git("update-ref", "refs/heads/branch2", commitID)
+	approvals, _, err = pr.GetApprovals()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, approvals)
+
+	assert.NoError(t, repo.ChangeProtectedBranchApprovals(protectedBranch.ID, 1, false))
+	approvals, _, err = pr.GetApprovals()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, approvals)
+
+	_, err = SubmitReview(owner, pr, ReviewTypeReject)
+	assert.NoError(t, err)
+	approvals, _, err = pr.GetApprovals()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, approvals)
+	AssertExistsAndLoadBean(t, &Review{IssueID: pr.IssueID, ReviewerID: owner.ID, Type: ReviewTypeReject})
+}
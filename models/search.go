@@ -0,0 +1,82 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/builder"
+)
+
+// accessibleRepositoryCond returns the condition of the repositories doer can
+// read, doer is nil for anonymous users. Deleted repositories kept in
+// quarantine can't be read.
+func accessibleRepositoryCond(doer *User) builder.Cond {
+	notDeleted := builder.Eq{"repository.deleted_unix": 0}
+	if doer == nil {
+		return builder.And(notDeleted, builder.Eq{"repository.is_private": false})
+	} else if doer.IsAdmin {
+		return notDeleted
+	}
+	return builder.And(notDeleted, builder.Or(
+		builder.Eq{"repository.is_private": false},
+		builder.Eq{"repository.owner_id": doer.ID},
+		builder.Expr("repository.id IN (SELECT repo_id FROM access WHERE user_id = ? AND mode >= ?)", doer.ID, AccessModeRead),
+	))
+}
+
+// SearchIssuesOptions holds the options of an issue search across
+// repositories.
+type SearchIssuesOptions struct {
+	Keyword  string
+	Doer     *User // nil for anonymous users
+	Page     int
+	PageSize int
+}
+
+// SearchIssues searches the issues and pull requests of all repositories
+// readable by the doer, it returns results in given range and number of total
+// results.
+func SearchIssues(opts *SearchIssuesOptions) (IssueList, int64, error) {
+	if len(opts.Keyword) == 0 {
+		return IssueList{}, 0, nil
+	}
+	issueIDs, err := SearchIssuesByKeyword(0, opts.Keyword)
+	if err != nil {
+		return nil, 0, fmt.Errorf("SearchIssuesByKeyword: %v", err)
+	}
+	return searchIssuesInIDs(opts, issueIDs)
+}
+
+func searchIssuesInIDs(opts *SearchIssuesOptions, issueIDs []int64) (IssueList, int64, error) {
+	if len(issueIDs) == 0 {
+		return IssueList{}, 0, nil
+	}
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+
+	cond := builder.In("issue.id", issueIDs).
+		And(accessibleRepositoryCond(opts.Doer)).
+		And(visibleIssueCond(opts.Doer))
+	count, err := x.
+		Join("INNER", "repository", "issue.repo_id = repository.id").
+		Where(cond).
+		Count(new(Issue))
+	if err != nil {
+		return nil, 0, fmt.Errorf("Count: %v", err)
+	}
+
+	issues := make(IssueList, 0, opts.PageSize)
+	if err = x.
+		Join("INNER", "repository", "issue.repo_id = repository.id").
+		Where(cond).
+		Desc("issue.updated_unix").
+		Limit(opts.PageSize, (opts.Page-1)*opts.PageSize).
+		Find(&issues); err != nil {
+		return nil, 0, fmt.Errorf("Find: %v", err)
+	}
+	return issues, count, issues.LoadAttributes()
+}
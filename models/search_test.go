@@ -0,0 +1,45 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchIssuesInIDs(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	test := func(doer *User, expectedIDs ...int64) {
+		issues, count, err := searchIssuesInIDs(&SearchIssuesOptions{
+			Doer:     doer,
+			PageSize: 10,
+		}, []int64{1, 4})
+		assert.NoError(t, err)
+		assert.EqualValues(t, len(expectedIDs), count)
+		if assert.Len(t, issues, len(expectedIDs)) {
+			for _, issue := range issues {
+				assert.Contains(t, expectedIDs, issue.ID)
+				assert.NotNil(t, issue.Repo)
+			}
+		}
+	}
+	test(nil, 1)
+	test(AssertExistsAndLoadBean(t, &User{ID: 1}).(*User), 1, 4)
+	test(AssertExistsAndLoadBean(t, &User{ID: 2}).(*User), 1, 4)
+	test(AssertExistsAndLoadBean(t, &User{ID: 4}).(*User), 1)
+
+	// Issues of deleted repositories kept in quarantine are not found.
+	_, err := x.Id(1).Cols("deleted_unix").Update(&Repository{DeletedUnix: 1})
+	assert.NoError(t, err)
+	test(nil)
+	test(AssertExistsAndLoadBean(t, &User{ID: 1}).(*User), 4)
+
+	issues, count, err := searchIssuesInIDs(&SearchIssuesOptions{PageSize: 10}, nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+	assert.Len(t, issues, 0)
+}
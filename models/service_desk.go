@@ -0,0 +1,190 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-xorm/xorm"
+	gouuid "github.com/satori/go.uuid"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// Status updates sent to reporters of service desk issues
+const (
+	ServiceDeskStatusOpened   = "opened"
+	ServiceDeskStatusClosed   = "closed"
+	ServiceDeskStatusReopened = "reopened"
+)
+
+// ServiceDesk represents the public form of a repository through which
+// people without an account can open issues.
+type ServiceDesk struct {
+	ID          int64     `xorm:"pk autoincr"`
+	RepoID      int64     `xorm:"UNIQUE"`
+	Token       string    `xorm:"UNIQUE NOT NULL"`
+	Created     time.Time `xorm:"-"`
+	CreatedUnix int64
+}
+
+// BeforeInsert will be invoked by XORM before inserting a record
+func (desk *ServiceDesk) BeforeInsert() {
+	desk.CreatedUnix = time.Now().Unix()
+}
+
+// AfterSet is invoked from XORM after setting the value of a field of this object.
+func (desk *ServiceDesk) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		desk.Created = time.Unix(desk.CreatedUnix, 0).Local()
+	}
+}
+
+// HTMLURL returns the URL of the public form of the service desk.
+func (desk *ServiceDesk) HTMLURL() string {
+	return setting.AppURL + "service_desk/" + desk.Token
+}
+
+// EnableServiceDesk enables the service desk of a repository, the token of
+// the form is regenerated if it is already enabled.
+func EnableServiceDesk(repoID int64) (*ServiceDesk, error) {
+	desk, err := GetServiceDeskByRepoID(repoID)
+	if err != nil && !IsErrServiceDeskNotExist(err) {
+		return nil, err
+	}
+
+	token := base.EncodeSha1(gouuid.NewV4().String())
+	if desk == nil {
+		desk = &ServiceDesk{RepoID: repoID, Token: token}
+		_, err = x.Insert(desk)
+	} else {
+		desk.Token = token
+		_, err = x.Id(desk.ID).Cols("token").Update(desk)
+	}
+	return desk, err
+}
+
+// DisableServiceDesk disables the service desk of a repository.
+func DisableServiceDesk(repoID int64) error {
+	_, err := x.Where("repo_id = ?", repoID).Delete(new(ServiceDesk))
+	return err
+}
+
+func getServiceDesk(desk *ServiceDesk) (*ServiceDesk, error) {
+	has, err := x.Get(desk)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrServiceDeskNotExist{desk.RepoID, desk.Token}
+	}
+	return desk, nil
+}
+
+// GetServiceDeskByRepoID returns the service desk of a repository.
+func GetServiceDeskByRepoID(repoID int64) (*ServiceDesk, error) {
+	return getServiceDesk(&ServiceDesk{RepoID: repoID})
+}
+
+// GetServiceDeskByToken returns the service desk with given form token.
+func GetServiceDeskByToken(token string) (*ServiceDesk, error) {
+	if len(token) == 0 {
+		return nil, ErrServiceDeskNotExist{0, token}
+	}
+	return getServiceDesk(&ServiceDesk{Token: token})
+}
+
+// ServiceDeskIssue records the external reporter of an issue opened
+// through the service desk of a repository.
+type ServiceDeskIssue struct {
+	ID          int64 `xorm:"pk autoincr"`
+	RepoID      int64 `xorm:"INDEX"`
+	IssueID     int64 `xorm:"UNIQUE"`
+	Name        string
+	Email       string    `xorm:"NOT NULL"`
+	Created     time.Time `xorm:"-"`
+	CreatedUnix int64
+}
+
+// BeforeInsert will be invoked by XORM before inserting a record
+func (r *ServiceDeskIssue) BeforeInsert() {
+	r.CreatedUnix = time.Now().Unix()
+}
+
+// AfterSet is invoked from XORM after setting the value of a field of this object.
+func (r *ServiceDeskIssue) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		r.Created = time.Unix(r.CreatedUnix, 0).Local()
+	}
+}
+
+// GetServiceDeskIssue returns the reporter of an issue opened through the
+// service desk, or nil if the issue has been opened otherwise.
+func GetServiceDeskIssue(issueID int64) (*ServiceDeskIssue, error) {
+	r := &ServiceDeskIssue{IssueID: issueID}
+	has, err := x.Get(r)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return r, nil
+}
+
+// NewServiceDeskIssue opens an issue in the repository of the service desk on
+// behalf of an external reporter, who is notified by email about its status.
+func (desk *ServiceDesk) NewServiceDeskIssue(title, content, name, email string) (*Issue, error) {
+	repo, err := GetRepositoryByID(desk.RepoID)
+	if err != nil {
+		return nil, fmt.Errorf("GetRepositoryByID: %v", err)
+	}
+
+	issue := &Issue{
+		RepoID:   repo.ID,
+		Title:    title,
+		PosterID: -1,
+		Poster:   NewGhostUser(),
+		Content:  content,
+	}
+	if err = NewIssue(repo, issue, nil, nil); err != nil {
+		return nil, err
+	}
+
+	reporter := &ServiceDeskIssue{
+		RepoID:  repo.ID,
+		IssueID: issue.ID,
+		Name:    strings.TrimSpace(name),
+		Email:   strings.TrimSpace(email),
+	}
+	if _, err = x.Insert(reporter); err != nil {
+		return nil, fmt.Errorf("insert reporter: %v", err)
+	}
+
+	SendServiceDeskMail(reporter, issue, ServiceDeskStatusOpened)
+	return issue, nil
+}
+
+// mailServiceDeskReporter sends the new status of an issue to its reporter
+// if it has been opened through the service desk.
+func mailServiceDeskReporter(issue *Issue, status string) {
+	reporter, err := GetServiceDeskIssue(issue.ID)
+	if err != nil {
+		log.Error(4, "GetServiceDeskIssue [%d]: %v", issue.ID, err)
+		return
+	} else if reporter == nil {
+		return
+	}
+
+	if err = issue.LoadAttributes(); err != nil {
+		log.Error(4, "LoadAttributes [%d]: %v", issue.ID, err)
+		return
+	}
+	SendServiceDeskMail(reporter, issue, status)
+}
@@ -0,0 +1,66 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnableServiceDesk(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	desk, err := EnableServiceDesk(2)
+	assert.NoError(t, err)
+	assert.Len(t, desk.Token, 40)
+	AssertExistsAndLoadBean(t, &ServiceDesk{RepoID: 2, Token: desk.Token})
+
+	// Enabling again regenerates the token.
+	oldToken := AssertExistsAndLoadBean(t, &ServiceDesk{ID: 1}).(*ServiceDesk).Token
+	desk, err = EnableServiceDesk(1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, desk.ID)
+	assert.NotEqual(t, oldToken, desk.Token)
+	AssertNotExistsBean(t, &ServiceDesk{Token: oldToken})
+}
+
+func TestDisableServiceDesk(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	assert.NoError(t, DisableServiceDesk(1))
+	AssertNotExistsBean(t, &ServiceDesk{RepoID: 1})
+	// Reporters of existing issues are kept.
+	AssertExistsAndLoadBean(t, &ServiceDeskIssue{IssueID: 1})
+}
+
+func TestGetServiceDeskByToken(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	desk, err := GetServiceDeskByToken("4c8e2b7f0d9a4e61b3f5a2c8d7e9f01a23b45c67")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, desk.RepoID)
+
+	_, err = GetServiceDeskByToken("")
+	assert.True(t, IsErrServiceDeskNotExist(err))
+	_, err = GetServiceDeskByToken("invalid")
+	assert.True(t, IsErrServiceDeskNotExist(err))
+}
+
+func TestServiceDesk_NewServiceDeskIssue(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	desk := AssertExistsAndLoadBean(t, &ServiceDesk{ID: 1}).(*ServiceDesk)
+	issue, err := desk.NewServiceDeskIssue("Printer is on fire", "Please help", " Jane ", "jane@example.com")
+	assert.NoError(t, err)
+	AssertExistsAndLoadBean(t, &Issue{ID: issue.ID, RepoID: 1, PosterID: -1})
+
+	reporter, err := GetServiceDeskIssue(issue.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, reporter) {
+		assert.Equal(t, "Jane", reporter.Name)
+		assert.Equal(t, "jane@example.com", reporter.Email)
+	}
+
+	reporter, err = GetServiceDeskIssue(2)
+	assert.NoError(t, err)
+	assert.Nil(t, reporter)
+}
@@ -0,0 +1,104 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Session represents a session of the database session provider.
+type Session struct {
+	Key        string `xorm:"pk CHAR(16)"`
+	Data       []byte `xorm:"BLOB"`
+	ExpiryUnix int64  `xorm:"INDEX"`
+}
+
+func getSession(e Engine, key string) (*Session, bool, error) {
+	s := &Session{Key: key}
+	has, err := e.Id(key).Get(s)
+	return s, has, err
+}
+
+// ReadSession returns the session with given key, it is created if it does
+// not exist.
+func ReadSession(key string) (*Session, error) {
+	s, has, err := getSession(x, key)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		s.ExpiryUnix = time.Now().Unix()
+		if _, err = x.Insert(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// ExistSession returns true if the session with given key exists.
+func ExistSession(key string) (bool, error) {
+	_, has, err := getSession(x, key)
+	return has, err
+}
+
+// UpdateSession updates the data of the session with given key and marks
+// it as used.
+func UpdateSession(key string, data []byte) error {
+	_, err := x.Id(key).Cols("data", "expiry_unix").Update(&Session{
+		Data:       data,
+		ExpiryUnix: time.Now().Unix(),
+	})
+	return err
+}
+
+// DestroySession deletes the session with given key.
+func DestroySession(key string) error {
+	_, err := x.Id(key).Delete(new(Session))
+	return err
+}
+
+// RegenerateSession moves the data of the session with the old key to a new
+// session with the new key, it returns the new session.
+func RegenerateSession(oldKey, newKey string) (_ *Session, err error) {
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	s, has, err := getSession(sess, newKey)
+	if err != nil {
+		return nil, err
+	} else if has {
+		return nil, fmt.Errorf("session '%s' already exists", newKey)
+	}
+
+	old, has, err := getSession(sess, oldKey)
+	if err != nil {
+		return nil, err
+	} else if has {
+		s.Data = old.Data
+		if _, err = sess.Id(oldKey).Delete(new(Session)); err != nil {
+			return nil, err
+		}
+	}
+	s.ExpiryUnix = time.Now().Unix()
+	if _, err = sess.Insert(s); err != nil {
+		return nil, err
+	}
+	return s, sess.Commit()
+}
+
+// CountSessions returns the number of sessions.
+func CountSessions() (int64, error) {
+	return x.Count(new(Session))
+}
+
+// DeleteExpiredSessions deletes the sessions which have not been used for
+// longer than maxLifetime seconds.
+func DeleteExpiredSessions(maxLifetime int64) error {
+	_, err := x.Where("expiry_unix <= ?", time.Now().Unix()-maxLifetime).Delete(new(Session))
+	return err
+}
@@ -0,0 +1,48 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSession(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	s, err := ReadSession("0123456789abcdef")
+	assert.NoError(t, err)
+	assert.Empty(t, s.Data)
+	has, err := ExistSession("0123456789abcdef")
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	assert.NoError(t, UpdateSession("0123456789abcdef", []byte("data")))
+	s, err = RegenerateSession("0123456789abcdef", "fedcba9876543210")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), s.Data)
+	AssertNotExistsBean(t, &Session{Key: "0123456789abcdef"})
+
+	_, err = RegenerateSession("0123456789abcdef", "fedcba9876543210")
+	assert.Error(t, err)
+
+	assert.NoError(t, DestroySession("fedcba9876543210"))
+	AssertNotExistsBean(t, &Session{Key: "fedcba9876543210"})
+}
+
+func TestDeleteExpiredSessions(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	_, err := ReadSession("0123456789abcdef")
+	assert.NoError(t, err)
+	_, err = x.Insert(&Session{Key: "fedcba9876543210", ExpiryUnix: time.Now().Unix() - 7200})
+	assert.NoError(t, err)
+
+	assert.NoError(t, DeleteExpiredSessions(3600))
+	AssertExistsAndLoadBean(t, &Session{Key: "0123456789abcdef"})
+	AssertNotExistsBean(t, &Session{Key: "fedcba9876543210"})
+}
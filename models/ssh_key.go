@@ -51,7 +51,7 @@ type PublicKey struct {
 	Name        string     `xorm:"NOT NULL"`
 	Fingerprint string     `xorm:"NOT NULL"`
 	Content     string     `xorm:"TEXT NOT NULL"`
-	Mode        AccessMode `xorm:"NOT NULL DEFAULT 2"`
+	Mode        AccessMode `xorm:"NOT NULL DEFAULT 3"`
 	Type        KeyType    `xorm:"NOT NULL DEFAULT 1"`
 
 	Created           time.Time `xorm:"-"`
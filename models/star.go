@@ -77,7 +77,7 @@ func (u *User) GetStarredRepos(private bool, page, pageSize int, orderBy string)
 	}
 	sess := x.
 		Join("INNER", "star", "star.repo_id = repository.id").
-		Where("star.uid = ?", u.ID).
+		Where("star.uid = ? AND repository.deleted_unix = 0", u.ID).
 		OrderBy(orderBy)
 
 	if !private {
@@ -261,7 +261,25 @@ func NewCommitStatus(repo *Repository, creator *User, sha string, status *Commit
 		return fmt.Errorf("NewCommitStatus[repo_id: %d, user_id: %d, sha: %s]: %v", repo.ID, creator.ID, sha, err)
 	}
 
-	return sess.Commit()
+	if err := sess.Commit(); err != nil {
+		return err
+	}
+
+	if err := PrepareWebhooks(repo, HookEventStatus, &StatusPayload{
+		ID:          status.Index,
+		SHA:         status.SHA,
+		State:       api.StatusState(status.State),
+		Context:     status.Context,
+		Description: status.Description,
+		TargetURL:   status.TargetURL,
+		Repository:  repo.APIFormat(AccessModeNone),
+		Sender:      creator.APIFormat(),
+	}); err != nil {
+		log.Error(4, "PrepareWebhooks [repo_id: %d, sha: %s]: %v", repo.ID, sha, err)
+	} else {
+		go HookQueue.Add(repo.ID)
+	}
+	return nil
 }
 
 // SignCommitWithStatuses represents a commit with validation of signature and status state.
@@ -0,0 +1,170 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// statusContextRecentPeriod is how long a context is listed after it
+// reported its last commit status.
+const statusContextRecentPeriod = 30 * 24 * time.Hour
+
+// RequiredStatusContext represents a commit status context which has to report
+// success for the head commit of a pull request before it can be merged.
+type RequiredStatusContext struct {
+	ID          int64  `xorm:"pk autoincr"`
+	RepoID      int64  `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Context     string `xorm:"VARCHAR(255) UNIQUE(s) NOT NULL"`
+	CreatedUnix int64  `xorm:"created"`
+}
+
+// StatusContext represents a commit status context of a repository along with
+// the last status it reported.
+type StatusContext struct {
+	Context  string
+	Required bool
+	Latest   *CommitStatus
+}
+
+// GetRequiredStatusContexts returns the names of the required commit status
+// contexts of the repository.
+func (repo *Repository) GetRequiredStatusContexts() ([]string, error) {
+	contexts := make([]string, 0, 5)
+	return contexts, x.
+		Table("required_status_context").
+		Where("repo_id = ?", repo.ID).
+		Asc("context").
+		Cols("context").
+		Find(&contexts)
+}
+
+// GetStatusContexts returns the commit status contexts which reported recently
+// to the repository, and the required contexts which did not.
+func (repo *Repository) GetStatusContexts() ([]*StatusContext, error) {
+	required, err := repo.GetRequiredStatusContexts()
+	if err != nil {
+		return nil, fmt.Errorf("GetRequiredStatusContexts: %v", err)
+	}
+	isRequired := make(map[string]bool, len(required))
+	for _, context := range required {
+		isRequired[context] = true
+	}
+
+	ids := make([]int64, 0, 10)
+	if err = x.
+		Table(&CommitStatus{}).
+		Where("repo_id = ? AND updated_unix >= ?", repo.ID, time.Now().Add(-statusContextRecentPeriod).Unix()).
+		Select("max( id ) as id").
+		GroupBy("context").
+		Find(&ids); err != nil {
+		return nil, err
+	}
+	statuses := make([]*CommitStatus, 0, len(ids))
+	if len(ids) > 0 {
+		if err = x.In("id", ids).Desc("id").Find(&statuses); err != nil {
+			return nil, err
+		}
+	}
+
+	contexts := make([]*StatusContext, 0, len(statuses)+len(required))
+	for _, status := range statuses {
+		contexts = append(contexts, &StatusContext{
+			Context:  status.Context,
+			Required: isRequired[status.Context],
+			Latest:   status,
+		})
+		delete(isRequired, status.Context)
+	}
+	for _, context := range required {
+		if isRequired[context] {
+			contexts = append(contexts, &StatusContext{
+				Context:  context,
+				Required: true,
+			})
+		}
+	}
+	return contexts, nil
+}
+
+// SetStatusContextRequired marks given commit status context as required or
+// not required for merging pull requests.
+func (repo *Repository) SetStatusContextRequired(context string, required bool) error {
+	context = strings.TrimSpace(context)
+	if len(context) == 0 {
+		return nil
+	}
+
+	has, err := x.
+		Where("repo_id = ? AND context = ?", repo.ID, context).
+		Get(new(RequiredStatusContext))
+	if err != nil {
+		return err
+	}
+
+	if required && !has {
+		_, err = x.Insert(&RequiredStatusContext{
+			RepoID:  repo.ID,
+			Context: context,
+		})
+	} else if !required && has {
+		_, err = x.
+			Where("repo_id = ? AND context = ?", repo.ID, context).
+			Delete(new(RequiredStatusContext))
+	}
+	return err
+}
+
+// GetMissingStatusContexts returns the required commit status contexts which
+// have not reported success for the head commit of the pull request.
+func (pr *PullRequest) GetMissingStatusContexts() ([]string, error) {
+	if err := pr.GetBaseRepo(); err != nil {
+		return nil, fmt.Errorf("GetBaseRepo: %v", err)
+	}
+	required, err := pr.BaseRepo.GetRequiredStatusContexts()
+	if err != nil {
+		return nil, fmt.Errorf("GetRequiredStatusContexts: %v", err)
+	} else if len(required) == 0 {
+		return nil, nil
+	}
+
+	sha, err := pr.getHeadCommitID()
+	if err != nil {
+		return nil, err
+	} else if len(sha) == 0 {
+		return required, nil
+	}
+
+	ids := make([]int64, 0, len(required))
+	if err = x.
+		Table(&CommitStatus{}).
+		Where("repo_id = ? AND sha = ?", pr.BaseRepo.ID, sha).
+		In("context", required).
+		Select("max( id ) as id").
+		GroupBy("context").
+		Find(&ids); err != nil {
+		return nil, err
+	}
+	statuses := make([]*CommitStatus, 0, len(ids))
+	if len(ids) > 0 {
+		if err = x.In("id", ids).Find(&statuses); err != nil {
+			return nil, err
+		}
+	}
+
+	succeeded := make(map[string]bool, len(statuses))
+	for _, status := range statuses {
+		succeeded[status.Context] = status.State == CommitStatusSuccess
+	}
+	missing := make([]string, 0, len(required))
+	for _, context := range required {
+		if !succeeded[context] {
+			missing = append(missing, context)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_SetStatusContextRequired(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.NoError(t, repo.SetStatusContextRequired("ci/awesomeness", true))
+	assert.NoError(t, repo.SetStatusContextRequired("ci/awesomeness", true))
+	assert.NoError(t, repo.SetStatusContextRequired(" cov/awesomeness ", true))
+	AssertExistsAndLoadBean(t, &RequiredStatusContext{RepoID: 1, Context: "cov/awesomeness"})
+
+	contexts, err := repo.GetRequiredStatusContexts()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ci/awesomeness", "cov/awesomeness"}, contexts)
+
+	assert.NoError(t, repo.SetStatusContextRequired("ci/awesomeness", false))
+	AssertNotExistsBean(t, &RequiredStatusContext{RepoID: 1, Context: "ci/awesomeness"})
+}
+
+func TestRepository_GetStatusContexts(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	_, err := x.Insert(&CommitStatus{
+		Index:     1,
+		RepoID:    repo.ID,
+		State:     CommitStatusSuccess,
+		SHA:       "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		Context:   "ci/new",
+		CreatorID: 2,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.SetStatusContextRequired("ci/required", true))
+
+	contexts, err := repo.GetStatusContexts()
+	assert.NoError(t, err)
+	if assert.Len(t, contexts, 2) {
+		assert.Equal(t, "ci/new", contexts[0].Context)
+		assert.False(t, contexts[0].Required)
+		if assert.NotNil(t, contexts[0].Latest) {
+			assert.Equal(t, CommitStatusSuccess, contexts[0].Latest.State)
+		}
+		assert.Equal(t, "ci/required", contexts[1].Context)
+		assert.True(t, contexts[1].Required)
+		assert.Nil(t, contexts[1].Latest)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// recentSessionPeriod is the period in seconds a session has been used in to
+// be counted as recent.
+const recentSessionPeriod = 60 * 60
+
+// TableStatistic is the number of rows of a table of the database.
+type TableStatistic struct {
+	Name string
+	Rows int64
+}
+
+// StorageStatistic contains the number of rows of the tables of the database
+// and the storage used on disk by repositories, LFS objects, attachments and
+// avatars. It is shared by the admin statistics page, the admin API and the
+// metrics endpoint.
+type StorageStatistic struct {
+	Tables []*TableStatistic
+
+	NumRepos       int64
+	RepoSize       int64
+	NumLFSObjects  int64
+	LFSSize        int64
+	NumAttachments int64
+	AttachmentSize int64
+	AvatarSize     int64
+	RepoAvatarSize int64
+
+	NumHookTasks        int64
+	NumPendingHookTasks int64
+
+	NumSessions       int64
+	NumRecentSessions int64
+}
+
+// dirSize returns the size of the files in a directory and its
+// subdirectories, a directory which does not exist is empty.
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// GetStorageStatistic collects the number of rows of every table and the
+// storage used on disk. The size of repositories is the one computed when
+// they are pushed to, the other sizes are measured on disk.
+func GetStorageStatistic() (*StorageStatistic, error) {
+	stats := &StorageStatistic{
+		Tables: make([]*TableStatistic, 0, len(tables)),
+	}
+	for _, t := range tables {
+		name := x.TableInfo(t).Name
+		rows, err := x.Count(t)
+		if err != nil {
+			return nil, fmt.Errorf("count %s: %v", name, err)
+		}
+		stats.Tables = append(stats.Tables, &TableStatistic{Name: name, Rows: rows})
+	}
+
+	sizes, err := x.SumsInt(new(Repository), "size")
+	if err != nil {
+		return nil, fmt.Errorf("sum repository size: %v", err)
+	}
+	stats.RepoSize = sizes[0]
+	if sizes, err = x.SumsInt(new(Attachment), "size"); err != nil {
+		return nil, fmt.Errorf("sum attachment size: %v", err)
+	}
+	stats.AttachmentSize = sizes[0]
+
+	if stats.NumRepos, err = x.Count(new(Repository)); err != nil {
+		return nil, fmt.Errorf("count repositories: %v", err)
+	} else if stats.NumLFSObjects, err = x.Count(new(LFSMetaObject)); err != nil {
+		return nil, fmt.Errorf("count LFS objects: %v", err)
+	} else if stats.NumAttachments, err = x.Count(new(Attachment)); err != nil {
+		return nil, fmt.Errorf("count attachments: %v", err)
+	} else if stats.NumHookTasks, err = x.Count(new(HookTask)); err != nil {
+		return nil, fmt.Errorf("count hook tasks: %v", err)
+	} else if stats.NumPendingHookTasks, err = x.Where("is_delivered = ?", false).Count(new(HookTask)); err != nil {
+		return nil, fmt.Errorf("count pending hook tasks: %v", err)
+	} else if stats.NumSessions, err = x.Count(new(UserSession)); err != nil {
+		return nil, fmt.Errorf("count sessions: %v", err)
+	} else if stats.NumRecentSessions, err = x.
+		Where("updated_unix > ?", time.Now().Unix()-recentSessionPeriod).
+		Count(new(UserSession)); err != nil {
+		return nil, fmt.Errorf("count recent sessions: %v", err)
+	}
+
+	if setting.LFS.StartServer {
+		if stats.LFSSize, err = dirSize(setting.LFS.ContentPath); err != nil {
+			return nil, fmt.Errorf("LFS size: %v", err)
+		}
+	}
+	if stats.AvatarSize, err = dirSize(setting.AvatarUploadPath); err != nil {
+		return nil, fmt.Errorf("avatar size: %v", err)
+	} else if stats.RepoAvatarSize, err = dirSize(setting.RepositoryAvatarUploadPath); err != nil {
+		return nil, fmt.Errorf("repository avatar size: %v", err)
+	}
+	return stats, nil
+}
+
+var storageStatisticCache struct {
+	sync.Mutex
+	stats     *StorageStatistic
+	updatedAt time.Time
+}
+
+// GetCachedStorageStatistic returns the statistics collected by
+// GetStorageStatistic less than maxAge ago, or collects them again. Callers
+// asking at the same time wait for a single collection.
+func GetCachedStorageStatistic(maxAge time.Duration) (*StorageStatistic, error) {
+	storageStatisticCache.Lock()
+	defer storageStatisticCache.Unlock()
+
+	if storageStatisticCache.stats != nil && time.Since(storageStatisticCache.updatedAt) < maxAge {
+		return storageStatisticCache.stats, nil
+	}
+	stats, err := GetStorageStatistic()
+	if err != nil {
+		return nil, err
+	}
+	storageStatisticCache.stats = stats
+	storageStatisticCache.updatedAt = time.Now()
+	return stats, nil
+}
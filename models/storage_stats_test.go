@@ -0,0 +1,54 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetStorageStatistic(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	stats, err := GetStorageStatistic()
+	assert.NoError(t, err)
+	assert.Len(t, stats.Tables, len(tables))
+	for _, table := range stats.Tables {
+		if table.Name == "repository" {
+			assert.EqualValues(t, stats.NumRepos, table.Rows)
+		}
+	}
+	assert.EqualValues(t, 14, stats.NumRepos)
+	assert.EqualValues(t, 9, stats.NumAttachments)
+	assert.EqualValues(t, 2*1048576+524288, stats.AttachmentSize)
+	assert.EqualValues(t, 1, stats.NumHookTasks)
+	assert.EqualValues(t, 0, stats.NumPendingHookTasks)
+	assert.EqualValues(t, 0, stats.NumSessions)
+
+	hookTask := &HookTask{RepoID: 1, HookID: 1, UUID: "uuid-pending"}
+	_, err = x.Insert(hookTask)
+	assert.NoError(t, err)
+	stats, err = GetStorageStatistic()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, stats.NumHookTasks)
+	assert.EqualValues(t, 1, stats.NumPendingHookTasks)
+}
+
+func TestGetCachedStorageStatistic(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	stats, err := GetCachedStorageStatistic(time.Hour)
+	assert.NoError(t, err)
+	cached, err := GetCachedStorageStatistic(time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, stats == cached)
+
+	fresh, err := GetCachedStorageStatistic(0)
+	assert.NoError(t, err)
+	assert.False(t, stats == fresh)
+	assert.Equal(t, stats.NumRepos, fresh.NumRepos)
+}
@@ -22,6 +22,8 @@ const (
 	EnvRepoIsWiki   = "GITEA_REPO_IS_WIKI"
 	EnvPusherName   = "GITEA_PUSHER_NAME"
 	EnvPusherID     = "GITEA_PUSHER_ID"
+	// EnvRepoIsEmpty is set when the push is the first one to the repository.
+	EnvRepoIsEmpty = "GITEA_REPO_IS_EMPTY"
 )
 
 // CommitToPushCommit transforms a git.Commit to PushCommit type.
@@ -0,0 +1,92 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// UploadPackConfig returns the configuration git upload-pack is run with.
+// Partial clones need the filters, and the missing objects to be fetched by
+// their SHA when they are needed.
+func UploadPackConfig() []string {
+	if !setting.Git.AllowPartialClone {
+		return nil
+	}
+	return []string{"uploadpack.allowFilter=true", "uploadpack.allowAnySHA1InWant=true"}
+}
+
+// UploadPackArgs returns the arguments which give UploadPackConfig to git.
+func UploadPackArgs() []string {
+	config := UploadPackConfig()
+	args := make([]string, 0, 2*len(config))
+	for _, c := range config {
+		args = append(args, "-c", c)
+	}
+	return args
+}
+
+// UploadPackConfigParameters returns UploadPackConfig in the format of
+// EnvGitConfigParameters.
+func UploadPackConfigParameters() string {
+	config := UploadPackConfig()
+	for i := range config {
+		config[i] = "'" + config[i] + "'"
+	}
+	return strings.Join(config, " ")
+}
+
+// ShallowGuard reads an upload-pack request, and fails with
+// ErrShallowCloneNotAllowed if it asks for a shallow history. The depth is
+// given before the first flush packet, the rest of the request is passed as is.
+type ShallowGuard struct {
+	r    io.Reader
+	buf  []byte
+	done bool
+
+	// Err is set once the request was refused.
+	Err error
+}
+
+// NewShallowGuard returns a new ShallowGuard reading the request from r.
+func NewShallowGuard(r io.Reader) *ShallowGuard {
+	return &ShallowGuard{r: r}
+}
+
+func (g *ShallowGuard) Read(p []byte) (int, error) {
+	if g.Err != nil {
+		return 0, g.Err
+	}
+	n, err := g.r.Read(p)
+	if g.done {
+		return n, err
+	}
+
+	g.buf = append(g.buf, p[:n]...)
+	for len(g.buf) >= 4 {
+		size, perr := strconv.ParseUint(string(g.buf[:4]), 16, 16)
+		if perr != nil || size < 4 {
+			// A flush packet, or a malformed one which git reports.
+			g.done = true
+			break
+		} else if int(size) > len(g.buf) {
+			break
+		}
+		if bytes.HasPrefix(g.buf[4:size], []byte("deepen")) {
+			g.Err = ErrShallowCloneNotAllowed{}
+			return 0, g.Err
+		}
+		g.buf = g.buf[size:]
+	}
+	if g.done {
+		g.buf = nil
+	}
+	return n, err
+}
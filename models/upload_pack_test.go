@@ -0,0 +1,33 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShallowGuard(t *testing.T) {
+	want := "0032want 0000000000000000000000000000000000000001\n"
+
+	request := want + "0000" + "0009done\n"
+	content, err := ioutil.ReadAll(NewShallowGuard(strings.NewReader(request)))
+	assert.NoError(t, err)
+	assert.Equal(t, request, string(content))
+
+	g := NewShallowGuard(strings.NewReader(want + "000cdeepen 1\n" + "0000" + "0009done\n"))
+	_, err = ioutil.ReadAll(g)
+	assert.True(t, IsErrShallowCloneNotAllowed(err))
+	assert.True(t, IsErrShallowCloneNotAllowed(g.Err))
+
+	// Only the first section of the request can ask for a depth.
+	request = want + "0000" + "000cdeepen 1\n"
+	content, err = ioutil.ReadAll(NewShallowGuard(strings.NewReader(request)))
+	assert.NoError(t, err)
+	assert.Equal(t, request, string(content))
+}
@@ -0,0 +1,249 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	gouuid "github.com/satori/go.uuid"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// UploadSessionType represents the kind of file uploaded in an upload session.
+type UploadSessionType int
+
+// Enumerate all the upload session types
+const (
+	UploadSessionAttachment UploadSessionType = iota + 1
+	UploadSessionRepoFile
+)
+
+// MaxSize returns the maximum size in bytes of files of the type.
+func (t UploadSessionType) MaxSize() int64 {
+	switch t {
+	case UploadSessionAttachment:
+		return setting.AttachmentMaxSize * 1024 * 1024
+	case UploadSessionRepoFile:
+		return setting.Repository.Upload.FileMaxSize * 1024 * 1024
+	}
+	return 0
+}
+
+// UploadSession represents a resumable upload of a file which is received
+// in chunks. Once complete, the file becomes an attachment or an upload to a
+// repository with the UUID of the session.
+type UploadSession struct {
+	ID          int64  `xorm:"pk autoincr"`
+	UUID        string `xorm:"uuid UNIQUE"`
+	Type        UploadSessionType
+	UserID      int64 `xorm:"INDEX"`
+	Name        string
+	Size        int64
+	Received    int64
+	CreatedUnix int64
+	UpdatedUnix int64 `xorm:"INDEX"`
+}
+
+// BeforeInsert is invoked from XORM before inserting an object of this type.
+func (s *UploadSession) BeforeInsert() {
+	s.CreatedUnix = time.Now().Unix()
+	s.UpdatedUnix = s.CreatedUnix
+}
+
+// BeforeUpdate is invoked from XORM before updating this object.
+func (s *UploadSession) BeforeUpdate() {
+	s.UpdatedUnix = time.Now().Unix()
+}
+
+// LocalPath returns where the received part of the file is stored.
+func (s *UploadSession) LocalPath() string {
+	return path.Join(setting.AppDataPath, "upload_sessions", s.UUID)
+}
+
+// IsComplete returns true if the whole file has been received.
+func (s *UploadSession) IsComplete() bool {
+	return s.Received == s.Size
+}
+
+// NewUploadSession starts a session to upload a file of given size.
+func NewUploadSession(doer *User, tp UploadSessionType, name string, size int64) (*UploadSession, error) {
+	if maxSize := tp.MaxSize(); size > maxSize {
+		return nil, ErrUploadSessionTooLarge{size, maxSize}
+	}
+	if tp == UploadSessionAttachment {
+		if err := CheckUserAttachmentQuota(doer.ID, size); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &UploadSession{
+		UUID:   gouuid.NewV4().String(),
+		Type:   tp,
+		UserID: doer.ID,
+		Name:   name,
+		Size:   size,
+	}
+	localPath := s.LocalPath()
+	if err := os.MkdirAll(path.Dir(localPath), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("MkdirAll: %v", err)
+	}
+	fw, err := os.Create(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("Create: %v", err)
+	}
+	fw.Close()
+
+	if _, err = x.Insert(s); err != nil {
+		os.Remove(localPath)
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetUploadSessionByUUID returns the upload session with given UUID.
+func GetUploadSessionByUUID(uuid string) (*UploadSession, error) {
+	s := &UploadSession{UUID: uuid}
+	has, err := x.Get(s)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrUploadSessionNotExist{uuid}
+	}
+	return s, nil
+}
+
+// WriteChunk appends the data of r to the file of the session, offset must
+// equal the number of bytes received so far. The bytes written are kept even
+// if reading r fails, so that the upload can be resumed from there.
+func (s *UploadSession) WriteChunk(offset int64, r io.Reader) error {
+	if offset != s.Received {
+		return ErrUploadSessionOffset{offset, s.Received}
+	}
+
+	fw, err := os.OpenFile(s.LocalPath(), os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("OpenFile: %v", err)
+	}
+	defer fw.Close()
+
+	// Drop the remains of chunks whose receipt has not been recorded.
+	if err = fw.Truncate(s.Received); err != nil {
+		return fmt.Errorf("Truncate: %v", err)
+	} else if _, err = fw.Seek(s.Received, io.SeekStart); err != nil {
+		return fmt.Errorf("Seek: %v", err)
+	}
+
+	// Read one more byte than remaining to detect oversized uploads.
+	n, copyErr := io.Copy(fw, io.LimitReader(r, s.Size-s.Received+1))
+	if s.Received+n > s.Size {
+		return ErrUploadSessionTooLarge{s.Received + n, s.Size}
+	}
+
+	// Only record the receipt if no other chunk has been received meanwhile.
+	affected, err := x.Id(s.ID).Where("received = ?", s.Received).Cols("received", "updated_unix").
+		Update(&UploadSession{Received: s.Received + n})
+	if err != nil {
+		return err
+	} else if affected == 0 {
+		if _, err = x.Id(s.ID).Get(s); err != nil {
+			return err
+		}
+		return ErrUploadSessionOffset{offset, s.Received}
+	}
+	s.Received += n
+	return copyErr
+}
+
+// Finish turns the file of a complete session into an attachment or an
+// upload to a repository, which has the UUID of the session. The quota of
+// attachments is checked again with the size of the received file, since
+// other attachments may have been uploaded since the session started.
+func (s *UploadSession) Finish() error {
+	if !s.IsComplete() {
+		return fmt.Errorf("upload session is not complete [received: %d, size: %d]", s.Received, s.Size)
+	}
+
+	fi, err := os.Stat(s.LocalPath())
+	if err != nil {
+		return fmt.Errorf("Stat: %v", err)
+	}
+
+	var localPath string
+	var bean interface{}
+	switch s.Type {
+	case UploadSessionAttachment:
+		localPath = AttachmentLocalPath(s.UUID)
+		bean = &Attachment{UUID: s.UUID, UploaderID: s.UserID, Name: s.Name, Size: fi.Size()}
+	case UploadSessionRepoFile:
+		localPath = UploadLocalPath(s.UUID)
+		bean = &Upload{UUID: s.UUID, Name: s.Name}
+	default:
+		return fmt.Errorf("unknown upload session type: %d", s.Type)
+	}
+
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+	if s.Type == UploadSessionAttachment {
+		if err = checkUserAttachmentQuota(sess, s.UserID, fi.Size()); err != nil {
+			return err
+		}
+	}
+	if _, err = sess.Insert(bean); err != nil {
+		return err
+	} else if _, err = sess.Id(s.ID).Delete(new(UploadSession)); err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(path.Dir(localPath), os.ModePerm); err != nil {
+		return fmt.Errorf("MkdirAll: %v", err)
+	} else if err = os.Rename(s.LocalPath(), localPath); err != nil {
+		return fmt.Errorf("Rename: %v", err)
+	}
+	return sess.Commit()
+}
+
+// DeleteUploadSession deletes an upload session and the received part of
+// its file.
+func DeleteUploadSession(s *UploadSession) error {
+	if _, err := x.Id(s.ID).Delete(new(UploadSession)); err != nil {
+		return err
+	}
+	if err := os.Remove(s.LocalPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove upload session file: %v", err)
+	}
+	return nil
+}
+
+// DeleteAbandonedUploadSessions deletes upload sessions which have not
+// received any data for longer than configured.
+func DeleteAbandonedUploadSessions() {
+	if !taskStatusTable.StartIfNotRunning(uploadSessionCleanup) {
+		return
+	}
+	defer taskStatusTable.Stop(uploadSessionCleanup)
+
+	log.Trace("Doing: UploadSessionCleanup")
+
+	olderThan := time.Now().Add(-setting.Cron.UploadSessionCleanup.OlderThan).Unix()
+	sessions := make([]*UploadSession, 0, 10)
+	if err := x.Where("updated_unix < ?", olderThan).Find(&sessions); err != nil {
+		log.Error(4, "UploadSessionCleanup: %v", err)
+		return
+	}
+	for _, s := range sessions {
+		if err := DeleteUploadSession(s); err != nil {
+			log.Error(4, "DeleteUploadSession [%s]: %v", s.UUID, err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadSession(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	setting.AttachmentMaxSize = 1
+	setting.AttachmentPath = filepath.Join(setting.AppDataPath, "attachments")
+
+	_, err := NewUploadSession(&User{ID: 2}, UploadSessionAttachment, "big.txt", 2*1024*1024)
+	assert.True(t, IsErrUploadSessionTooLarge(err))
+
+	s, err := NewUploadSession(&User{ID: 2}, UploadSessionAttachment, "file.txt", 10)
+	assert.NoError(t, err)
+	AssertExistsAndLoadBean(t, &UploadSession{UUID: s.UUID, UserID: 2})
+
+	assert.NoError(t, s.WriteChunk(0, strings.NewReader("hello")))
+	assert.EqualValues(t, 5, s.Received)
+	assert.False(t, s.IsComplete())
+
+	err = s.WriteChunk(0, strings.NewReader("hello"))
+	assert.True(t, IsErrUploadSessionOffset(err))
+
+	s, err = GetUploadSessionByUUID(s.UUID)
+	assert.NoError(t, err)
+	err = s.WriteChunk(5, strings.NewReader("world!"))
+	assert.True(t, IsErrUploadSessionTooLarge(err))
+	assert.EqualValues(t, 5, s.Received)
+
+	assert.NoError(t, s.WriteChunk(5, strings.NewReader("world")))
+	assert.True(t, s.IsComplete())
+	assert.NoError(t, s.Finish())
+
+	AssertNotExistsBean(t, &UploadSession{ID: s.ID})
+	attach, err := GetAttachmentByUUID(s.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, "file.txt", attach.Name)
+	_, err = os.Stat(attach.LocalPath())
+	assert.NoError(t, err)
+
+	_, err = GetUploadSessionByUUID(s.UUID)
+	assert.True(t, IsErrUploadSessionNotExist(err))
+}
+
+func TestDeleteUploadSession(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	setting.Repository.Upload.FileMaxSize = 1
+
+	s, err := NewUploadSession(&User{ID: 2}, UploadSessionRepoFile, "file.txt", 10)
+	assert.NoError(t, err)
+	assert.NoError(t, DeleteUploadSession(s))
+	AssertNotExistsBean(t, &UploadSession{ID: s.ID})
+	_, err = os.Stat(s.LocalPath())
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestUploadSession_FinishQuota(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer func(quota int64) { setting.AttachmentUserQuota = quota }(setting.AttachmentUserQuota)
+	setting.AttachmentMaxSize = 1
+	setting.AttachmentUserQuota = 1
+	setting.AttachmentPath = filepath.Join(setting.AppDataPath, "attachments")
+
+	s, err := NewUploadSession(&User{ID: 2}, UploadSessionAttachment, "file.txt", 10)
+	assert.NoError(t, err)
+	assert.NoError(t, s.WriteChunk(0, strings.NewReader("helloworld")))
+
+	// Another attachment uploaded meanwhile fills the quota.
+	_, err = x.Insert(&Attachment{UUID: "other-upload", UploaderID: 2, Name: "other.txt", Size: 512 * 1024})
+	assert.NoError(t, err)
+	assert.True(t, IsErrAttachmentQuotaExceeded(s.Finish()))
+	AssertExistsAndLoadBean(t, &UploadSession{ID: s.ID})
+	_, err = GetAttachmentByUUID(s.UUID)
+	assert.True(t, IsErrAttachmentNotExist(err))
+	assert.NoError(t, DeleteUploadSession(s))
+}
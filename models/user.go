@@ -16,7 +16,6 @@ import (
 	"image"
 	// Needed for jpeg support
 	_ "image/jpeg"
-	"image/png"
 	"os"
 	"path/filepath"
 	"strings"
@@ -26,7 +25,6 @@ import (
 	"github.com/Unknwon/com"
 	"github.com/go-xorm/builder"
 	"github.com/go-xorm/xorm"
-	"github.com/nfnt/resize"
 	"golang.org/x/crypto/pbkdf2"
 
 	"code.gitea.io/git"
@@ -91,15 +89,26 @@ type User struct {
 	Repos            []*Repository `xorm:"-"`
 	Location         string
 	Website          string
+	StatusMessage    string `xorm:"VARCHAR(100)"`
+	IsBusy           bool   `xorm:"NOT NULL DEFAULT false"`
 	Rands            string `xorm:"VARCHAR(10)"`
 	Salt             string `xorm:"VARCHAR(10)"`
 
+	// Values of custom profile fields by field ID, see LoadProfileFieldValues
+	ProfileFieldValues map[int64]string `xorm:"-"`
+
 	Created       time.Time `xorm:"-"`
 	CreatedUnix   int64     `xorm:"INDEX"`
 	Updated       time.Time `xorm:"-"`
 	UpdatedUnix   int64     `xorm:"INDEX"`
 	LastLogin     time.Time `xorm:"-"`
 	LastLoginUnix int64     `xorm:"INDEX"`
+	// PasswdChangedUnix is the time the password was set at, see
+	// IsPasswordExpired.
+	PasswdChangedUnix int64 `xorm:"NOT NULL DEFAULT 0"`
+	// DeletedUnix is set while the deleted user is kept in quarantine.
+	Deleted     time.Time `xorm:"-"`
+	DeletedUnix int64     `xorm:"INDEX NOT NULL DEFAULT 0"`
 
 	// Remember visibility choice for convenience, true for private
 	LastRepoVisibility bool
@@ -114,6 +123,15 @@ type User struct {
 	AllowCreateOrganization bool `xorm:"DEFAULT true"`
 	ProhibitLogin           bool
 
+	// Suspension, suspended users keep their data but can't sign in nor use
+	// git. SuspendedByID is 0 if the synchronization of external users
+	// suspended the user.
+	IsSuspended   bool      `xorm:"INDEX NOT NULL DEFAULT false"`
+	SuspendReason string    `xorm:"TEXT"`
+	SuspendedByID int64     `xorm:"NOT NULL DEFAULT 0"`
+	Suspended     time.Time `xorm:"-"`
+	SuspendedUnix int64     `xorm:"NOT NULL DEFAULT 0"`
+
 	// Avatar
 	Avatar          string `xorm:"VARCHAR(2048) NOT NULL"`
 	AvatarEmail     string `xorm:"NOT NULL"`
@@ -172,6 +190,10 @@ func (u *User) AfterSet(colName string, _ xorm.Cell) {
 		u.Updated = time.Unix(u.UpdatedUnix, 0).Local()
 	case "last_login_unix":
 		u.LastLogin = time.Unix(u.LastLoginUnix, 0).Local()
+	case "deleted_unix":
+		u.Deleted = time.Unix(u.DeletedUnix, 0).Local()
+	case "suspended_unix":
+		u.Suspended = time.Unix(u.SuspendedUnix, 0).Local()
 	}
 }
 
@@ -238,9 +260,16 @@ func (u *User) CanCreateOrganization() bool {
 	return u.IsAdmin || (u.AllowCreateOrganization && !setting.Admin.DisableRegularOrgCreation)
 }
 
-// CanEditGitHook returns true if user can edit Git hooks.
+// CanImpersonate returns true if user can act as the target user. Only
+// admins can, and not as themselves, other admins or organizations.
+func (u *User) CanImpersonate(target *User) bool {
+	return u.IsAdmin && !setting.Admin.DisableImpersonation && target.ID != u.ID &&
+		!target.IsAdmin && !target.IsOrganization() && !target.IsDeleted()
+}
+
+// CanEditGitHook returns true if user can edit the raw scripts of Git hooks.
 func (u *User) CanEditGitHook() bool {
-	return u.IsAdmin || u.AllowGitHook
+	return u.IsAdmin || (u.AllowGitHook && !setting.Repository.RestrictRawGitHooks)
 }
 
 // CanImportLocal returns true if user can migrate repository by local path.
@@ -308,21 +337,12 @@ func (u *User) generateRandomAvatar(e Engine) error {
 	// NOTICE for random avatar, it still uses id as avatar name, but custom avatar use md5
 	// since random image is not a user's photo, there is no security for enumable
 	u.Avatar = fmt.Sprintf("%d", u.ID)
-	if err = os.MkdirAll(filepath.Dir(u.CustomAvatarPath()), os.ModePerm); err != nil {
-		return fmt.Errorf("MkdirAll: %v", err)
-	}
-	fw, err := os.Create(u.CustomAvatarPath())
-	if err != nil {
-		return fmt.Errorf("Create: %v", err)
-	}
-	defer fw.Close()
-
 	if _, err := e.Id(u.ID).Cols("avatar").Update(u); err != nil {
 		return err
 	}
 
-	if err = png.Encode(fw, img); err != nil {
-		return fmt.Errorf("Encode: %v", err)
+	if err = avatar.Save(u.CustomAvatarPath(), img); err != nil {
+		return fmt.Errorf("Save: %v", err)
 	}
 
 	log.Info("New random avatar created: %d", u.ID)
@@ -344,7 +364,7 @@ func (u *User) RelAvatarLink() string {
 			return defaultImgURL
 		}
 		return setting.AppSubURL + "/avatars/" + u.Avatar
-	case setting.DisableGravatar, setting.OfflineMode:
+	case setting.DisableGravatar:
 		if !com.IsFile(u.CustomAvatarPath()) {
 			if err := u.GenerateRandomAvatar(); err != nil {
 				log.Error(3, "GenerateRandomAvatar: %v", err)
@@ -356,6 +376,21 @@ func (u *User) RelAvatarLink() string {
 	return base.AvatarLink(u.AvatarEmail)
 }
 
+// SizedRelAvatarLink returns the relative avatar link of given size in
+// pixels, which the avatar server and Gravatar-like services scale to.
+func (u *User) SizedRelAvatarLink(size int) string {
+	link := u.RelAvatarLink()
+	switch {
+	case strings.HasPrefix(link, setting.AppSubURL+"/img/"):
+		return link
+	case strings.HasPrefix(link, setting.AppSubURL+"/avatars/"):
+		return link + "?size=" + com.ToStr(size)
+	case strings.Contains(link, "?"):
+		return link + "&s=" + com.ToStr(size)
+	}
+	return link + "?s=" + com.ToStr(size)
+}
+
 // AvatarLink returns user avatar absolute link.
 func (u *User) AvatarLink() string {
 	link := u.RelAvatarLink()
@@ -366,10 +401,10 @@ func (u *User) AvatarLink() string {
 }
 
 // GetFollowers returns range of user's followers.
-func (u *User) GetFollowers(page int) ([]*User, error) {
-	users := make([]*User, 0, ItemsPerPage)
+func (u *User) GetFollowers(page, pageSize int) ([]*User, error) {
+	users := make([]*User, 0, pageSize)
 	sess := x.
-		Limit(ItemsPerPage, (page-1)*ItemsPerPage).
+		Limit(pageSize, (page-1)*pageSize).
 		Where("follow.follow_id=?", u.ID)
 	if setting.UsePostgreSQL {
 		sess = sess.Join("LEFT", "follow", `"user".id=follow.user_id`)
@@ -385,10 +420,10 @@ func (u *User) IsFollowing(followID int64) bool {
 }
 
 // GetFollowing returns range of user's following.
-func (u *User) GetFollowing(page int) ([]*User, error) {
-	users := make([]*User, 0, ItemsPerPage)
+func (u *User) GetFollowing(page, pageSize int) ([]*User, error) {
+	users := make([]*User, 0, pageSize)
 	sess := x.
-		Limit(ItemsPerPage, (page-1)*ItemsPerPage).
+		Limit(pageSize, (page-1)*pageSize).
 		Where("follow.user_id=?", u.ID)
 	if setting.UsePostgreSQL {
 		sess = sess.Join("LEFT", "follow", `"user".id=follow.follow_id`)
@@ -411,6 +446,17 @@ func (u *User) NewGitSig() *git.Signature {
 func (u *User) EncodePasswd() {
 	newPasswd := pbkdf2.Key([]byte(u.Passwd), []byte(u.Salt), 10000, 50, sha256.New)
 	u.Passwd = fmt.Sprintf("%x", newPasswd)
+	u.PasswdChangedUnix = time.Now().Unix()
+}
+
+// IsPasswordExpired returns true if the user of a local account has to change
+// the password before doing anything else.
+func (u *User) IsPasswordExpired() bool {
+	if setting.PasswordExpiryDays <= 0 || !u.IsLocal() || u.IsOrganization() {
+		return false
+	}
+	expiry := time.Duration(setting.PasswordExpiryDays) * 24 * time.Hour
+	return time.Unix(u.PasswdChangedUnix, 0).Add(expiry).Before(time.Now())
 }
 
 // ValidatePassword checks if given password matches the one belongs to the user.
@@ -433,8 +479,6 @@ func (u *User) UploadAvatar(data []byte) error {
 		return fmt.Errorf("Decode: %v", err)
 	}
 
-	m := resize.Resize(avatar.AvatarSize, avatar.AvatarSize, img, resize.NearestNeighbor)
-
 	sess := x.NewSession()
 	defer sessionRelease(sess)
 	if err = sess.Begin(); err != nil {
@@ -447,18 +491,8 @@ func (u *User) UploadAvatar(data []byte) error {
 		return fmt.Errorf("updateUser: %v", err)
 	}
 
-	if err := os.MkdirAll(setting.AvatarUploadPath, os.ModePerm); err != nil {
-		return fmt.Errorf("Failed to create dir %s: %v", setting.AvatarUploadPath, err)
-	}
-
-	fw, err := os.Create(u.CustomAvatarPath())
-	if err != nil {
-		return fmt.Errorf("Create: %v", err)
-	}
-	defer fw.Close()
-
-	if err = png.Encode(fw, m); err != nil {
-		return fmt.Errorf("Encode: %v", err)
+	if err = avatar.Save(u.CustomAvatarPath(), img); err != nil {
+		return fmt.Errorf("Save: %v", err)
 	}
 
 	return sess.Commit()
@@ -468,7 +502,7 @@ func (u *User) UploadAvatar(data []byte) error {
 func (u *User) DeleteAvatar() error {
 	log.Trace("DeleteAvatar[%d]: %s", u.ID, u.CustomAvatarPath())
 	if len(u.Avatar) > 0 {
-		if err := os.Remove(u.CustomAvatarPath()); err != nil {
+		if err := avatar.Remove(u.CustomAvatarPath()); err != nil {
 			return fmt.Errorf("Failed to remove %s: %v", u.CustomAvatarPath(), err)
 		}
 	}
@@ -607,6 +641,11 @@ func (u *User) IsMailable() bool {
 	return u.IsActive
 }
 
+// HasStatus returns true if the user has set a status message or is busy.
+func (u *User) HasStatus() bool {
+	return u.IsBusy || len(u.StatusMessage) > 0
+}
+
 // IsUserExist checks if given user name exist,
 // the user name should be noncased unique.
 // If uid is presented, then check will rule out that one,
@@ -635,7 +674,7 @@ func NewGhostUser() *User {
 }
 
 var (
-	reservedUsernames    = []string{"assets", "css", "explore", "img", "js", "less", "plugins", "debug", "raw", "install", "api", "avatar", "user", "org", "help", "stars", "issues", "pulls", "commits", "repo", "template", "admin", "new", ".", ".."}
+	reservedUsernames    = []string{"assets", "css", "explore", "img", "js", "less", "plugins", "debug", "raw", "install", "api", "avatar", "avatars", "user", "org", "help", "stars", "issues", "pulls", "commits", "repo", "template", "admin", "new", "service_desk", "metrics", ".", ".."}
 	reservedUserPatterns = []string{"*.keys"}
 )
 
@@ -731,7 +770,7 @@ func CreateUser(u *User) (err error) {
 
 func countUsers(e Engine) int64 {
 	count, _ := e.
-		Where("type=0").
+		Where("type=0 AND deleted_unix = 0").
 		Count(new(User))
 	return count
 }
@@ -750,7 +789,7 @@ func Users(opts *SearchUserOptions) ([]*User, error) {
 	users := make([]*User, 0, opts.PageSize)
 	sess := x.
 		Limit(opts.PageSize, (opts.Page-1)*opts.PageSize).
-		Where("type=0")
+		Where("type=0 AND deleted_unix = 0")
 
 	return users, sess.
 		OrderBy(opts.OrderBy).
@@ -870,6 +909,7 @@ func updateUser(e Engine, u *User) error {
 	u.LowerName = strings.ToLower(u.Name)
 	u.Location = base.TruncateString(u.Location, 255)
 	u.Website = base.TruncateString(u.Website, 255)
+	u.StatusMessage = base.TruncateString(u.StatusMessage, 100)
 	u.Description = base.TruncateString(u.Description, 255)
 
 	u.FullName = markdown.Sanitize(u.FullName)
@@ -962,6 +1002,10 @@ func deleteUser(e *xorm.Session, u *User) error {
 	}
 	// ***** END: Follow *****
 
+	if err = deleteUserExportFiles(e, u.ID); err != nil {
+		return fmt.Errorf("deleteUserExportFiles: %v", err)
+	}
+
 	if err = deleteBeans(e,
 		&AccessToken{UID: u.ID},
 		&Collaboration{UserID: u.ID},
@@ -974,6 +1018,13 @@ func deleteUser(e *xorm.Session, u *User) error {
 		&IssueUser{UID: u.ID},
 		&EmailAddress{UID: u.ID},
 		&UserOpenID{UID: u.ID},
+		&ModerationItem{PosterID: u.ID},
+		&ProfileFieldValue{UserID: u.ID},
+		&NotificationChannel{UserID: u.ID},
+		&UserExport{UserID: u.ID},
+		&LoginAttempt{UserID: u.ID},
+		&UserSession{UserID: u.ID},
+		&FederationKey{ActorType: FederationActorUser, ActorID: u.ID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
@@ -1020,7 +1071,7 @@ func deleteUser(e *xorm.Session, u *User) error {
 	if len(u.Avatar) > 0 {
 		avatarPath := u.CustomAvatarPath()
 		if com.IsExist(avatarPath) {
-			if err := os.Remove(avatarPath); err != nil {
+			if err := avatar.Remove(avatarPath); err != nil {
 				return fmt.Errorf("Failed to remove %s: %v", avatarPath, err)
 			}
 		}
@@ -1089,7 +1140,7 @@ func GetUserByKeyID(keyID int64) (*User, error) {
 	if err != nil {
 		return nil, err
 	}
-	if !has {
+	if !has || user.DeletedUnix > 0 {
 		return nil, ErrUserNotExist{0, "", keyID}
 	}
 	return &user, nil
@@ -1131,7 +1182,7 @@ func GetUserByName(name string) (*User, error) {
 	has, err := x.Get(u)
 	if err != nil {
 		return nil, err
-	} else if !has {
+	} else if !has || u.DeletedUnix > 0 {
 		return nil, ErrUserNotExist{0, name, 0}
 	}
 	return u, nil
@@ -1177,6 +1228,25 @@ func GetUserIDsByNames(names []string) []int64 {
 	return ids
 }
 
+// GetUsersWithStatusByNames returns the users among given names which have
+// set a status message or are marked as busy.
+func GetUsersWithStatusByNames(names []string) ([]*User, error) {
+	users := make([]*User, 0, 5)
+	if len(names) == 0 {
+		return users, nil
+	}
+	lowerNames := make([]string, len(names))
+	for i := range names {
+		lowerNames[i] = strings.ToLower(names[i])
+	}
+	return users, x.
+		In("lower_name", lowerNames).
+		And("type = ?", UserTypeIndividual).
+		And("(is_busy = ? OR status_message != ?)", true, "").
+		Asc("name").
+		Find(&users)
+}
+
 // UserCommit represents a commit with validation of user.
 type UserCommit struct {
 	User *User
@@ -1233,6 +1303,9 @@ func GetUserByEmail(email string) (*User, error) {
 		return nil, err
 	}
 	if has {
+		if user.DeletedUnix > 0 {
+			return nil, ErrUserNotExist{0, email, 0}
+		}
 		return user, nil
 	}
 
@@ -1243,12 +1316,27 @@ func GetUserByEmail(email string) (*User, error) {
 		return nil, err
 	}
 	if has {
-		return GetUserByID(emailAddress.UID)
+		if user, err = GetUserByID(emailAddress.UID); err == nil && user.DeletedUnix > 0 {
+			return nil, ErrUserNotExist{0, email, 0}
+		}
+		return user, err
 	}
 
 	return nil, ErrUserNotExist{0, email, 0}
 }
 
+// GetAvatarEmailByHash returns the avatar email of the user whose avatar from
+// a Gravatar-like service has given email hash, or an empty string if there is
+// no such user.
+func GetAvatarEmailByHash(hash string) (string, error) {
+	u := new(User)
+	has, err := x.Where("avatar = ?", hash).Cols("avatar_email", "use_custom_avatar").Get(u)
+	if err != nil || !has || u.UseCustomAvatar {
+		return "", err
+	}
+	return u.AvatarEmail, nil
+}
+
 // GetUser checks if a user already exists
 func GetUser(user *User) (bool, error) {
 	return x.Get(user)
@@ -1282,7 +1370,7 @@ func SearchUserByName(opts *SearchUserOptions) (users []*User, _ int64, _ error)
 
 	// Append conditions
 	cond := builder.And(
-		builder.Eq{"type": opts.Type},
+		builder.Eq{"type": opts.Type, "deleted_unix": 0},
 		builder.Or(
 			builder.Like{"lower_name", opts.Keyword},
 			builder.Like{"LOWER(full_name)", opts.Keyword},
@@ -1304,7 +1392,7 @@ func SearchUserByName(opts *SearchUserOptions) (users []*User, _ int64, _ error)
 
 // GetStarredRepos returns the repos starred by a particular user
 func GetStarredRepos(userID int64, private bool) ([]*Repository, error) {
-	sess := x.Where("star.uid=?", userID).
+	sess := x.Where("star.uid=? AND `repository`.deleted_unix = 0", userID).
 		Join("LEFT", "star", "`repository`.id=`star`.repo_id")
 	if !private {
 		sess = sess.And("is_private=?", false)
@@ -1319,7 +1407,7 @@ func GetStarredRepos(userID int64, private bool) ([]*Repository, error) {
 
 // GetWatchedRepos returns the repos watched by a particular user
 func GetWatchedRepos(userID int64, private bool) ([]*Repository, error) {
-	sess := x.Where("watch.user_id=?", userID).
+	sess := x.Where("watch.user_id=? AND `repository`.deleted_unix = 0", userID).
 		Join("LEFT", "watch", "`repository`.id=`watch`.repo_id")
 	if !private {
 		sess = sess.And("is_private=?", false)
@@ -1350,108 +1438,156 @@ func SyncExternalUsers() {
 	updateExisting := setting.Cron.SyncExternalUsers.UpdateExisting
 
 	for _, s := range ls {
-		if !s.IsActived || !s.IsSyncEnabled {
+		if !s.IsActived || !s.IsSyncEnabled || !s.IsLDAP() {
+			continue
+		}
+		if err = s.syncLDAPUsers(updateExisting); err != nil {
+			log.Error(4, "SyncExternalUsers[%s]: %v", s.Name, err)
+		}
+	}
+}
+
+// syncLDAPUsers creates the users of the LDAP directory of the source, updates
+// existing ones and deactivates those removed from the directory, as far as
+// these steps are enabled for the source.
+func (source *LoginSource) syncLDAPUsers(updateExisting bool) error {
+	log.Trace("Doing: SyncExternalUsers[%s]", source.Name)
+
+	users := make([]*User, 0, 10)
+	if err := x.
+		Where("login_type = ?", source.Type).
+		And("login_source = ?", source.ID).
+		Find(&users); err != nil {
+		return fmt.Errorf("find users: %v", err)
+	}
+
+	// Nothing must be deactivated if the directory cannot be read.
+	entries, err := source.LDAP().SearchEntries()
+	if err != nil {
+		return fmt.Errorf("SearchEntries: %v", err)
+	}
+
+	dryRun := ""
+	if source.SyncDryRun {
+		dryRun = " (dry run)"
+	}
+	hasAdminFilter := len(source.LDAP().AdminFilter) > 0
+
+	existingUsers := make(map[string]*User, len(users))
+	for _, u := range users {
+		existingUsers[u.LowerName] = u
+	}
+	presentUsers := make(map[int64]bool, len(users))
+
+	var created, updated, deactivated, suspended int
+	for _, su := range entries {
+		if len(su.Username) == 0 {
 			continue
 		}
-		if s.IsLDAP() {
-			log.Trace("Doing: SyncExternalUsers[%s]", s.Name)
 
-			var existingUsers []int64
+		if len(su.Mail) == 0 {
+			su.Mail = fmt.Sprintf("%s@localhost", su.Username)
+		}
 
-			// Find all users with this login type
-			var users []User
-			x.Where("login_type = ?", LoginLDAP).
-				And("login_source = ?", s.ID).
-				Find(&users)
+		fullName := composeFullName(su.Name, su.Surname, su.Username)
+		usr, ok := existingUsers[strings.ToLower(su.Username)]
+		if !ok {
+			if !source.SyncCreateUsers {
+				continue
+			}
+			log.Info("SyncExternalUsers[%s]%s: Creating user %s", source.Name, dryRun, su.Username)
+			created++
+			if source.SyncDryRun {
+				continue
+			}
 
-			sr := s.LDAP().SearchEntries()
+			if err = CreateUser(&User{
+				LowerName:   strings.ToLower(su.Username),
+				Name:        su.Username,
+				FullName:    fullName,
+				LoginType:   source.Type,
+				LoginSource: source.ID,
+				LoginName:   su.Username,
+				Email:       su.Mail,
+				IsAdmin:     su.IsAdmin,
+				IsActive:    true,
+			}); err != nil {
+				log.Error(4, "SyncExternalUsers[%s]: Error creating user %s: %v", source.Name, su.Username, err)
+			}
+			continue
+		}
 
-			for _, su := range sr {
-				if len(su.Username) == 0 {
-					continue
-				}
+		presentUsers[usr.ID] = true
+		if !updateExisting || !source.SyncUpdateUsers {
+			continue
+		}
 
-				if len(su.Mail) == 0 {
-					su.Mail = fmt.Sprintf("%s@localhost", su.Username)
-				}
+		// Check if user data has changed, users the synchronization suspended
+		// are back in the directory.
+		isSyncSuspended := usr.IsSuspended && usr.SuspendedByID == 0
+		if (!hasAdminFilter || usr.IsAdmin == su.IsAdmin) &&
+			strings.ToLower(usr.Email) == strings.ToLower(su.Mail) &&
+			usr.FullName == fullName &&
+			usr.IsActive && !isSyncSuspended {
+			continue
+		}
+
+		log.Info("SyncExternalUsers[%s]%s: Updating user %s", source.Name, dryRun, usr.Name)
+		updated++
+		if source.SyncDryRun {
+			continue
+		}
+
+		usr.FullName = fullName
+		usr.Email = su.Mail
+		// Change existing admin flag only if AdminFilter option is set
+		if hasAdminFilter {
+			usr.IsAdmin = su.IsAdmin
+		}
+		usr.IsActive = true
+		if err = UpdateUser(usr); err != nil {
+			log.Error(4, "SyncExternalUsers[%s]: Error updating user %s: %v", source.Name, usr.Name, err)
+		} else if isSyncSuspended {
+			if err = UnsuspendUser(usr); err != nil {
+				log.Error(4, "SyncExternalUsers[%s]: Error unsuspending user %s: %v", source.Name, usr.Name, err)
+			}
+		}
+	}
 
-				var usr *User
-				// Search for existing user
-				for _, du := range users {
-					if du.LowerName == strings.ToLower(su.Username) {
-						usr = &du
-						break
-					}
+	// Deactivate or suspend users not present in LDAP
+	if updateExisting && source.SyncDeactivateUsers {
+		for _, usr := range users {
+			if presentUsers[usr.ID] || !usr.IsActive || usr.IsSuspended {
+				continue
+			}
+
+			if source.SyncSuspendUsers {
+				log.Info("SyncExternalUsers[%s]%s: Suspending user %s", source.Name, dryRun, usr.Name)
+				suspended++
+				if source.SyncDryRun {
+					continue
 				}
 
-				fullName := composeFullName(su.Name, su.Surname, su.Username)
-				// If no existing user found, create one
-				if usr == nil {
-					log.Trace("SyncExternalUsers[%s]: Creating user %s", s.Name, su.Username)
-
-					usr = &User{
-						LowerName:   strings.ToLower(su.Username),
-						Name:        su.Username,
-						FullName:    fullName,
-						LoginType:   s.Type,
-						LoginSource: s.ID,
-						LoginName:   su.Username,
-						Email:       su.Mail,
-						IsAdmin:     su.IsAdmin,
-						IsActive:    true,
-					}
-
-					err = CreateUser(usr)
-					if err != nil {
-						log.Error(4, "SyncExternalUsers[%s]: Error creating user %s: %v", s.Name, su.Username, err)
-					}
-				} else if updateExisting {
-					existingUsers = append(existingUsers, usr.ID)
-					// Check if user data has changed
-					if (len(s.LDAP().AdminFilter) > 0 && usr.IsAdmin != su.IsAdmin) ||
-						strings.ToLower(usr.Email) != strings.ToLower(su.Mail) ||
-						usr.FullName != fullName ||
-						!usr.IsActive {
-
-						log.Trace("SyncExternalUsers[%s]: Updating user %s", s.Name, usr.Name)
-
-						usr.FullName = fullName
-						usr.Email = su.Mail
-						// Change existing admin flag only if AdminFilter option is set
-						if len(s.LDAP().AdminFilter) > 0 {
-							usr.IsAdmin = su.IsAdmin
-						}
-						usr.IsActive = true
-
-						err = UpdateUser(usr)
-						if err != nil {
-							log.Error(4, "SyncExternalUsers[%s]: Error updating user %s: %v", s.Name, usr.Name, err)
-						}
-					}
+				if err = SuspendUser(usr, nil, fmt.Sprintf("Removed from the directory of %s", source.Name)); err != nil {
+					log.Error(4, "SyncExternalUsers[%s]: Error suspending user %s: %v", source.Name, usr.Name, err)
 				}
+				continue
 			}
 
-			// Deactivate users not present in LDAP
-			if updateExisting {
-				for _, usr := range users {
-					found := false
-					for _, uid := range existingUsers {
-						if usr.ID == uid {
-							found = true
-							break
-						}
-					}
-					if !found {
-						log.Trace("SyncExternalUsers[%s]: Deactivating user %s", s.Name, usr.Name)
-
-						usr.IsActive = false
-						err = UpdateUser(&usr)
-						if err != nil {
-							log.Error(4, "SyncExternalUsers[%s]: Error deactivating user %s: %v", s.Name, usr.Name, err)
-						}
-					}
-				}
+			log.Info("SyncExternalUsers[%s]%s: Deactivating user %s", source.Name, dryRun, usr.Name)
+			deactivated++
+			if source.SyncDryRun {
+				continue
+			}
+
+			usr.IsActive = false
+			if err = UpdateUser(usr); err != nil {
+				log.Error(4, "SyncExternalUsers[%s]: Error deactivating user %s: %v", source.Name, usr.Name, err)
 			}
 		}
 	}
+
+	log.Info("SyncExternalUsers[%s]%s: %d users created, %d updated, %d deactivated, %d suspended",
+		source.Name, dryRun, created, updated, deactivated, suspended)
+	return nil
 }
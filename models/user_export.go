@@ -0,0 +1,524 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Unknwon/com"
+	"github.com/go-xorm/xorm"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/sync"
+)
+
+// userExportQueue holds the IDs of the user data exports to produce.
+var userExportQueue sync.Queue = sync.NewUniqueQueue(setting.UserExport.QueueLength)
+
+// UserExportStatus represents the state of a user data export.
+type UserExportStatus int
+
+// Enumerate all the states of a user data export
+const (
+	UserExportPending UserExportStatus = iota + 1 // Waiting in the queue
+	UserExportReady                               // Can be downloaded
+	UserExportFailed                              // Could not be produced
+)
+
+// UserExport represents an archive of the data of a user which the user can
+// download.
+type UserExport struct {
+	ID     int64            `xorm:"pk autoincr"`
+	UserID int64            `xorm:"INDEX NOT NULL"`
+	Status UserExportStatus `xorm:"INDEX NOT NULL"`
+	Size   int64
+
+	Created       time.Time `xorm:"-"`
+	CreatedUnix   int64     `xorm:"INDEX created"`
+	Completed     time.Time `xorm:"-"`
+	CompletedUnix int64     `xorm:"INDEX"`
+}
+
+// AfterSet is invoked from XORM after setting the value of a field of this object.
+func (e *UserExport) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		e.Created = time.Unix(e.CreatedUnix, 0).Local()
+	case "completed_unix":
+		e.Completed = time.Unix(e.CompletedUnix, 0).Local()
+	}
+}
+
+// IsPending returns true if the export is waiting to be produced.
+func (e *UserExport) IsPending() bool {
+	return e.Status == UserExportPending
+}
+
+// IsReady returns true if the export can be downloaded.
+func (e *UserExport) IsReady() bool {
+	return e.Status == UserExportReady && !e.IsExpired()
+}
+
+// IsFailed returns true if the export could not be produced.
+func (e *UserExport) IsFailed() bool {
+	return e.Status == UserExportFailed
+}
+
+// Expiry returns the time the export can no longer be downloaded at.
+func (e *UserExport) Expiry() time.Time {
+	return time.Unix(e.CompletedUnix, 0).Add(setting.UserExport.Expiry).Local()
+}
+
+// IsExpired returns true if the export has been ready for longer than the
+// configured expiry.
+func (e *UserExport) IsExpired() bool {
+	return e.Status == UserExportReady && time.Now().After(e.Expiry())
+}
+
+// NextRequestTime returns the time the user can request a new export at.
+func (e *UserExport) NextRequestTime() time.Time {
+	return e.Created.Add(setting.UserExport.MinInterval)
+}
+
+// ArchivePath returns the path of the archive of the export.
+func (e *UserExport) ArchivePath() string {
+	return filepath.Join(setting.UserExport.Path, com.ToStr(e.ID)+".zip")
+}
+
+// GetLatestUserExport returns the most recent export of the user.
+func GetLatestUserExport(userID int64) (*UserExport, error) {
+	e := new(UserExport)
+	has, err := x.Where("user_id = ?", userID).Desc("id").Get(e)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrUserExportNotExist{userID}
+	}
+	return e, nil
+}
+
+// NewUserExport queues a new export of the data of the user. A user can only
+// request a new export once the configured interval has passed since the
+// previous request.
+func NewUserExport(u *User) (*UserExport, error) {
+	latest, err := GetLatestUserExport(u.ID)
+	if err != nil && !IsErrUserExportNotExist(err) {
+		return nil, err
+	} else if err == nil && (latest.IsPending() || time.Now().Before(latest.NextRequestTime())) {
+		return nil, ErrUserExportTooSoon{u.ID, latest.NextRequestTime()}
+	}
+
+	e := &UserExport{
+		UserID: u.ID,
+		Status: UserExportPending,
+	}
+	if _, err = x.Insert(e); err != nil {
+		return nil, err
+	}
+	userExportQueue.Add(e.ID)
+	return e, nil
+}
+
+// deleteUserExportFiles removes the archives of the exports of the user.
+func deleteUserExportFiles(e Engine, userID int64) error {
+	exports := make([]*UserExport, 0, 5)
+	if err := e.Where("user_id = ?", userID).Find(&exports); err != nil {
+		return err
+	}
+	for _, export := range exports {
+		removeAllWithNotice(e, "Delete user data export", export.ArchivePath())
+	}
+	return nil
+}
+
+// DeleteExpiredUserExports removes the archives of the exports which have
+// been ready for longer than the configured expiry.
+func DeleteExpiredUserExports() {
+	if !taskStatusTable.StartIfNotRunning(userExportCleanup) {
+		return
+	}
+	defer taskStatusTable.Stop(userExportCleanup)
+
+	log.Trace("Doing: UserExportCleanup")
+
+	exports := make([]*UserExport, 0, 10)
+	if err := x.
+		Where("status = ? AND completed_unix < ?", UserExportReady, time.Now().Add(-setting.UserExport.Expiry).Unix()).
+		Find(&exports); err != nil {
+		log.Error(4, "UserExportCleanup: %v", err)
+		return
+	}
+	for _, e := range exports {
+		removeAllWithNotice(x, "Delete expired user data export", e.ArchivePath())
+		if _, err := x.Id(e.ID).Delete(new(UserExport)); err != nil {
+			log.Error(4, "UserExportCleanup [%d]: %v", e.ID, err)
+		}
+	}
+	log.Trace("UserExportCleanup: %d exports deleted", len(exports))
+}
+
+// exportedProfile is the profile of the user in an export.
+type exportedProfile struct {
+	Name          string            `json:"name"`
+	FullName      string            `json:"full_name"`
+	Email         string            `json:"email"`
+	Emails        []string          `json:"emails"`
+	Website       string            `json:"website"`
+	Location      string            `json:"location"`
+	Description   string            `json:"description"`
+	ProfileFields map[string]string `json:"profile_fields,omitempty"`
+	IsAdmin       bool              `json:"is_admin"`
+	Created       time.Time         `json:"created"`
+	LastLogin     time.Time         `json:"last_login"`
+}
+
+// exportedKey is the metadata of a SSH or GPG key of the user in an export.
+type exportedKey struct {
+	Type        string     `json:"type"`
+	Name        string     `json:"name,omitempty"`
+	Fingerprint string     `json:"fingerprint,omitempty"`
+	KeyID       string     `json:"key_id,omitempty"`
+	Emails      []string   `json:"emails,omitempty"`
+	Created     time.Time  `json:"created"`
+	Expires     *time.Time `json:"expires,omitempty"`
+}
+
+// exportedIssue is an issue or a pull request posted by the user in an export.
+type exportedIssue struct {
+	Repo     string    `json:"repository"`
+	Index    int64     `json:"index"`
+	IsPull   bool      `json:"is_pull"`
+	IsClosed bool      `json:"is_closed"`
+	Title    string    `json:"title"`
+	Content  string    `json:"content"`
+	Created  time.Time `json:"created"`
+}
+
+// exportedComment is a comment posted by the user in an export.
+type exportedComment struct {
+	Repo    string    `json:"repository"`
+	Index   int64     `json:"issue_index"`
+	Content string    `json:"content"`
+	Created time.Time `json:"created"`
+}
+
+// exportedRepo is a repository owned by the user in an export.
+type exportedRepo struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Website     string    `json:"website"`
+	IsPrivate   bool      `json:"is_private"`
+	IsFork      bool      `json:"is_fork"`
+	IsMirror    bool      `json:"is_mirror"`
+	URL         string    `json:"url"`
+	Created     time.Time `json:"created"`
+}
+
+// userExportRepoNames caches the full names of the repositories of the
+// issues of the user.
+type userExportRepoNames map[int64]string
+
+func (names userExportRepoNames) get(repoID int64) (string, error) {
+	if name, ok := names[repoID]; ok {
+		return name, nil
+	}
+	repo, err := GetRepositoryByID(repoID)
+	if err != nil {
+		if !IsErrRepoNotExist(err) {
+			return "", err
+		}
+		names[repoID] = ""
+		return "", nil
+	}
+	names[repoID] = repo.FullName()
+	return names[repoID], nil
+}
+
+func exportUserProfile(u *User) (interface{}, error) {
+	profile := &exportedProfile{
+		Name:        u.Name,
+		FullName:    u.FullName,
+		Email:       u.Email,
+		Website:     u.Website,
+		Location:    u.Location,
+		Description: u.Description,
+		IsAdmin:     u.IsAdmin,
+		Created:     u.Created,
+		LastLogin:   u.LastLogin,
+	}
+
+	emails, err := GetEmailAddresses(u.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetEmailAddresses: %v", err)
+	}
+	for _, email := range emails {
+		profile.Emails = append(profile.Emails, email.Email)
+	}
+
+	values, err := GetProfileFieldValues(u, u)
+	if err != nil {
+		return nil, fmt.Errorf("GetProfileFieldValues: %v", err)
+	}
+	if len(values) > 0 {
+		profile.ProfileFields = make(map[string]string, len(values))
+		for _, value := range values {
+			profile.ProfileFields[value.Field.Name] = value.Value
+		}
+	}
+	return profile, nil
+}
+
+func exportUserKeys(u *User) (interface{}, error) {
+	publicKeys, err := ListPublicKeys(u.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ListPublicKeys: %v", err)
+	}
+	gpgKeys, err := ListGPGKeys(u.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ListGPGKeys: %v", err)
+	}
+
+	keys := make([]*exportedKey, 0, len(publicKeys)+len(gpgKeys))
+	for _, key := range publicKeys {
+		keys = append(keys, &exportedKey{
+			Type:        "ssh",
+			Name:        key.Name,
+			Fingerprint: key.Fingerprint,
+			Created:     key.Created,
+		})
+	}
+	for _, key := range gpgKeys {
+		exported := &exportedKey{
+			Type:    "gpg",
+			KeyID:   key.KeyID,
+			Created: key.Created,
+		}
+		if key.ExpiredUnix > 0 {
+			exported.Expires = &key.Expired
+		}
+		for _, email := range key.Emails {
+			exported.Emails = append(exported.Emails, email.Email)
+		}
+		keys = append(keys, exported)
+	}
+	return keys, nil
+}
+
+func exportUserIssues(u *User, repoNames userExportRepoNames) (interface{}, error) {
+	issues := make([]*Issue, 0, 10)
+	if err := x.Where("poster_id = ?", u.ID).Asc("id").Find(&issues); err != nil {
+		return nil, err
+	}
+
+	exported := make([]*exportedIssue, 0, len(issues))
+	for _, issue := range issues {
+		repoName, err := repoNames.get(issue.RepoID)
+		if err != nil {
+			return nil, err
+		}
+		exported = append(exported, &exportedIssue{
+			Repo:     repoName,
+			Index:    issue.Index,
+			IsPull:   issue.IsPull,
+			IsClosed: issue.IsClosed,
+			Title:    issue.Title,
+			Content:  issue.Content,
+			Created:  issue.Created,
+		})
+	}
+	return exported, nil
+}
+
+func exportUserComments(u *User, repoNames userExportRepoNames) (interface{}, error) {
+	comments := make([]*Comment, 0, 10)
+	if err := x.Where("poster_id = ? AND type = ?", u.ID, CommentTypeComment).Asc("id").Find(&comments); err != nil {
+		return nil, err
+	}
+
+	exported := make([]*exportedComment, 0, len(comments))
+	issues := make(map[int64]*Issue)
+	for _, comment := range comments {
+		issue, ok := issues[comment.IssueID]
+		if !ok {
+			var err error
+			if issue, err = GetIssueByID(comment.IssueID); err != nil && !IsErrIssueNotExist(err) {
+				return nil, err
+			}
+			issues[comment.IssueID] = issue
+		}
+
+		exportedComment := &exportedComment{
+			Content: comment.Content,
+			Created: comment.Created,
+		}
+		if issue != nil {
+			repoName, err := repoNames.get(issue.RepoID)
+			if err != nil {
+				return nil, err
+			}
+			exportedComment.Repo = repoName
+			exportedComment.Index = issue.Index
+		}
+		exported = append(exported, exportedComment)
+	}
+	return exported, nil
+}
+
+func exportUserRepos(u *User) (interface{}, error) {
+	repos := make([]*Repository, 0, u.NumRepos)
+	if err := x.Where("owner_id = ? AND deleted_unix = 0", u.ID).Asc("lower_name").Find(&repos); err != nil {
+		return nil, err
+	}
+
+	exported := make([]*exportedRepo, 0, len(repos))
+	for _, repo := range repos {
+		repo.Owner = u
+		exported = append(exported, &exportedRepo{
+			Name:        repo.FullName(),
+			Description: repo.Description,
+			Website:     repo.Website,
+			IsPrivate:   repo.IsPrivate,
+			IsFork:      repo.IsFork,
+			IsMirror:    repo.IsMirror,
+			URL:         repo.HTMLURL(),
+			Created:     repo.Created,
+		})
+	}
+	return exported, nil
+}
+
+// writeUserExport writes the data of the user as JSON files to the archive.
+func writeUserExport(u *User, archive *zip.Writer) error {
+	repoNames := make(userExportRepoNames)
+	files := []struct {
+		name   string
+		export func() (interface{}, error)
+	}{
+		{"profile.json", func() (interface{}, error) { return exportUserProfile(u) }},
+		{"keys.json", func() (interface{}, error) { return exportUserKeys(u) }},
+		{"issues.json", func() (interface{}, error) { return exportUserIssues(u, repoNames) }},
+		{"comments.json", func() (interface{}, error) { return exportUserComments(u, repoNames) }},
+		{"repositories.json", func() (interface{}, error) { return exportUserRepos(u) }},
+	}
+
+	for _, file := range files {
+		data, err := file.export()
+		if err != nil {
+			return fmt.Errorf("export %s: %v", file.name, err)
+		}
+		w, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(data); err != nil {
+			return fmt.Errorf("encode %s: %v", file.name, err)
+		}
+	}
+	return nil
+}
+
+// produce writes the archive of the export and marks it as ready.
+func (e *UserExport) produce() error {
+	u, err := GetUserByID(e.UserID)
+	if err != nil {
+		return fmt.Errorf("GetUserByID: %v", err)
+	}
+
+	if err = os.MkdirAll(setting.UserExport.Path, os.ModePerm); err != nil {
+		return err
+	}
+	tmpPath := e.ArchivePath() + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+
+	archive := zip.NewWriter(f)
+	if err = writeUserExport(u, archive); err != nil {
+		f.Close()
+		return err
+	} else if err = archive.Close(); err != nil {
+		f.Close()
+		return err
+	} else if err = f.Close(); err != nil {
+		return err
+	}
+
+	fi, err := os.Stat(tmpPath)
+	if err != nil {
+		return err
+	} else if err = os.Rename(tmpPath, e.ArchivePath()); err != nil {
+		return err
+	}
+
+	e.Status = UserExportReady
+	e.Size = fi.Size()
+	e.CompletedUnix = time.Now().Unix()
+	e.Completed = time.Unix(e.CompletedUnix, 0).Local()
+	if _, err = x.Id(e.ID).Cols("status", "size", "completed_unix").Update(e); err != nil {
+		return err
+	}
+	SendUserExportReadyMail(u, e)
+	return nil
+}
+
+// ProduceUserExports produces the user data exports added to the queue.
+func ProduceUserExports() {
+	for id := range userExportQueue.Queue() {
+		log.Trace("ProduceUserExports [export_id: %v]", id)
+		finishQueueItem(userExportQueue, id, produceUserExport(id))
+	}
+}
+
+// produceUserExport produces the user data export taken from the queue, or
+// marks it as failed.
+func produceUserExport(id string) error {
+	e := new(UserExport)
+	has, err := x.Id(com.StrTo(id).MustInt64()).Get(e)
+	if err != nil {
+		log.Error(4, "Get user export [%s]: %v", id, err)
+		return err
+	} else if !has || !e.IsPending() {
+		return nil
+	}
+
+	if err = e.produce(); err != nil {
+		log.Error(4, "Produce user export [%s]: %v", id, err)
+		e.Status = UserExportFailed
+		if _, err = x.Id(e.ID).Cols("status").Update(e); err != nil {
+			log.Error(4, "Update user export [%s]: %v", id, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// InitUserExports adds the pending user data exports to the queue again and
+// starts a go routine to produce them.
+func InitUserExports() {
+	exports := make([]*UserExport, 0, 10)
+	if err := x.Where("status = ?", UserExportPending).Find(&exports); err != nil {
+		log.Error(4, "Find pending user exports: %v", err)
+	}
+	go func() {
+		for _, e := range exports {
+			userExportQueue.Add(e.ID)
+		}
+	}()
+	go ProduceUserExports()
+}
@@ -0,0 +1,89 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+func TestNewUserExport(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	e, err := NewUserExport(user)
+	assert.NoError(t, err)
+	AssertExistsAndLoadBean(t, &UserExport{ID: e.ID, UserID: 2, Status: UserExportPending})
+	userExportQueue.Remove(e.ID)
+
+	_, err = NewUserExport(user)
+	assert.True(t, IsErrUserExportTooSoon(err))
+
+	latest, err := GetLatestUserExport(2)
+	assert.NoError(t, err)
+	assert.Equal(t, e.ID, latest.ID)
+
+	_, err = GetLatestUserExport(4)
+	assert.True(t, IsErrUserExportNotExist(err))
+}
+
+func TestUserExport_produce(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer func(path string) { setting.UserExport.Path = path }(setting.UserExport.Path)
+	setting.UserExport.Path = filepath.Join(os.TempDir(), "user_exports")
+
+	e := &UserExport{UserID: 2, Status: UserExportPending}
+	_, err := x.Insert(e)
+	assert.NoError(t, err)
+	assert.NoError(t, e.produce())
+	defer os.Remove(e.ArchivePath())
+
+	e = AssertExistsAndLoadBean(t, &UserExport{ID: e.ID}).(*UserExport)
+	assert.True(t, e.IsReady())
+	assert.True(t, e.Size > 0)
+
+	archive, err := zip.OpenReader(e.ArchivePath())
+	assert.NoError(t, err)
+	defer archive.Close()
+
+	files := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+	for _, name := range []string{"profile.json", "keys.json", "issues.json", "comments.json", "repositories.json"} {
+		assert.Contains(t, files, name)
+	}
+
+	r, err := files["repositories.json"].Open()
+	assert.NoError(t, err)
+	defer r.Close()
+	var repos []*exportedRepo
+	assert.NoError(t, json.NewDecoder(r).Decode(&repos))
+	if assert.Len(t, repos, 2) {
+		assert.Equal(t, "user2/repo1", repos[0].Name)
+		assert.Equal(t, "user2/repo2", repos[1].Name)
+	}
+}
+
+func TestDeleteExpiredUserExports(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	expired := &UserExport{UserID: 2, Status: UserExportReady, CompletedUnix: time.Now().Add(-setting.UserExport.Expiry - time.Hour).Unix()}
+	ready := &UserExport{UserID: 4, Status: UserExportReady, CompletedUnix: time.Now().Unix()}
+	_, err := x.Insert(expired, ready)
+	assert.NoError(t, err)
+
+	DeleteExpiredUserExports()
+	AssertNotExistsBean(t, &UserExport{ID: expired.ID})
+	AssertExistsAndLoadBean(t, &UserExport{ID: ready.ID})
+}
@@ -7,7 +7,10 @@ package models
 import (
 	"errors"
 	"fmt"
+	"path"
 	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
 )
 
 var (
@@ -23,6 +26,33 @@ type EmailAddress struct {
 	Email       string `xorm:"UNIQUE NOT NULL"`
 	IsActivated bool
 	IsPrimary   bool `xorm:"-"`
+	// IsPendingPrimary is set if the address becomes the primary address of
+	// the user once it is activated.
+	IsPendingPrimary bool `xorm:"NOT NULL DEFAULT false"`
+}
+
+// matchEmailDomain returns true if domain matches any of the patterns.
+func matchEmailDomain(patterns []string, domain string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, domain); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEmailDomainAllowed returns true if the domain of the email address is
+// in the configured whitelist if there is one, and not in the blacklist.
+func IsEmailDomainAllowed(email string) bool {
+	i := strings.LastIndex(email, "@")
+	if i < 0 {
+		return false
+	}
+	domain := strings.ToLower(strings.TrimSpace(email[i+1:]))
+	if len(setting.Service.EmailDomainWhitelist) > 0 {
+		return matchEmailDomain(setting.Service.EmailDomainWhitelist, domain)
+	}
+	return !matchEmailDomain(setting.Service.EmailDomainBlacklist, domain)
 }
 
 // GetEmailAddresses returns all email addresses belongs to given user.
@@ -76,6 +106,9 @@ func IsEmailUsed(email string) (bool, error) {
 
 func addEmailAddress(e Engine, email *EmailAddress) error {
 	email.Email = strings.ToLower(strings.TrimSpace(email.Email))
+	if !IsEmailDomainAllowed(email.Email) {
+		return ErrEmailDomainNotAllowed{email.Email}
+	}
 	used, err := isEmailUsed(e, email.Email)
 	if err != nil {
 		return err
@@ -101,6 +134,9 @@ func AddEmailAddresses(emails []*EmailAddress) error {
 	// Check if any of them has been used
 	for i := range emails {
 		emails[i].Email = strings.ToLower(strings.TrimSpace(emails[i].Email))
+		if !IsEmailDomainAllowed(emails[i].Email) {
+			return ErrEmailDomainNotAllowed{emails[i].Email}
+		}
 		used, err := IsEmailUsed(emails[i].Email)
 		if err != nil {
 			return err
@@ -142,7 +178,13 @@ func (email *EmailAddress) Activate() error {
 		return err
 	}
 
-	return sess.Commit()
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+	if email.IsPendingPrimary {
+		return MakeEmailPrimary(&EmailAddress{ID: email.ID})
+	}
+	return nil
 }
 
 // DeleteEmailAddress deletes an email address of given user.
@@ -225,6 +267,73 @@ func MakeEmailPrimary(email *EmailAddress) error {
 	if _, err = sess.Id(user.ID).AllCols().Update(user); err != nil {
 		return err
 	}
+	if _, err = sess.Exec("UPDATE `email_address` SET is_pending_primary = ? WHERE uid = ?", false, user.ID); err != nil {
+		return err
+	}
 
 	return sess.Commit()
 }
+
+// ChangePrimaryEmail changes the primary email address of the user. If email
+// addresses must be confirmed and the new address is not an activated address
+// of the user, it only becomes primary once it is activated, and is returned
+// so that the activation mail can be sent.
+func ChangePrimaryEmail(u *User, email string) (*EmailAddress, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == u.Email {
+		return nil, nil
+	} else if !IsEmailDomainAllowed(email) {
+		return nil, ErrEmailDomainNotAllowed{email}
+	}
+
+	if err := checkDupEmail(x, &User{ID: u.ID, Type: u.Type, Email: email}); err != nil {
+		return nil, err
+	}
+	address := &EmailAddress{Email: email}
+	has, err := x.Get(address)
+	if err != nil {
+		return nil, err
+	} else if has && address.UID != u.ID {
+		return nil, ErrEmailAlreadyUsed{email}
+	}
+
+	if !setting.Service.RegisterEmailConfirm || address.IsActivated {
+		if !has {
+			address = &EmailAddress{UID: u.ID, Email: email, IsActivated: true}
+			if _, err = x.Insert(address); err != nil {
+				return nil, err
+			}
+		} else if !address.IsActivated {
+			address.IsActivated = true
+			if _, err = x.Id(address.ID).Cols("is_activated").Update(address); err != nil {
+				return nil, err
+			}
+		}
+		if err = MakeEmailPrimary(&EmailAddress{ID: address.ID}); err != nil {
+			return nil, err
+		}
+		u.Email = email
+		return nil, nil
+	}
+
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	if _, err = sess.Exec("UPDATE `email_address` SET is_pending_primary = ? WHERE uid = ?", false, u.ID); err != nil {
+		return nil, err
+	}
+	address.IsPendingPrimary = true
+	if has {
+		_, err = sess.Id(address.ID).Cols("is_pending_primary").Update(address)
+	} else {
+		address.UID = u.ID
+		_, err = sess.Insert(address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return address, sess.Commit()
+}
@@ -7,6 +7,8 @@ package models
 import (
 	"testing"
 
+	"code.gitea.io/gitea/modules/setting"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -167,3 +169,65 @@ func TestActivate(t *testing.T) {
 	assert.True(t, emails[2].IsActivated)
 	assert.True(t, emails[2].IsPrimary)
 }
+
+func TestIsEmailDomainAllowed(t *testing.T) {
+	defer func(whitelist, blacklist []string) {
+		setting.Service.EmailDomainWhitelist = whitelist
+		setting.Service.EmailDomainBlacklist = blacklist
+	}(setting.Service.EmailDomainWhitelist, setting.Service.EmailDomainBlacklist)
+
+	setting.Service.EmailDomainWhitelist = nil
+	setting.Service.EmailDomainBlacklist = []string{"spam.com", "*.spam.org"}
+	assert.True(t, IsEmailDomainAllowed("user@example.com"))
+	assert.False(t, IsEmailDomainAllowed("user@SPAM.com"))
+	assert.False(t, IsEmailDomainAllowed("user@mail.spam.org"))
+	assert.False(t, IsEmailDomainAllowed("user"))
+
+	setting.Service.EmailDomainWhitelist = []string{"example.com"}
+	assert.True(t, IsEmailDomainAllowed("user@example.com"))
+	assert.False(t, IsEmailDomainAllowed("user@example.org"))
+
+	assert.NoError(t, PrepareTestDatabase())
+	err := AddEmailAddress(&EmailAddress{UID: 1, Email: "user1234@example.org"})
+	assert.True(t, IsErrEmailDomainNotAllowed(err))
+}
+
+func TestChangePrimaryEmail(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer func(confirm bool) {
+		setting.Service.RegisterEmailConfirm = confirm
+	}(setting.Service.RegisterEmailConfirm)
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+
+	// Used by another user
+	_, err := ChangePrimaryEmail(user, "user2@example.com")
+	assert.True(t, IsErrEmailAlreadyUsed(err))
+	_, err = ChangePrimaryEmail(user, "user21@example.com")
+	assert.True(t, IsErrEmailAlreadyUsed(err))
+
+	// Confirmation required, becomes primary once activated
+	setting.Service.RegisterEmailConfirm = true
+	pending, err := ChangePrimaryEmail(user, "User1New@example.com")
+	assert.NoError(t, err)
+	assert.NotNil(t, pending)
+	assert.Equal(t, "user1@example.com", user.Email)
+	AssertExistsAndLoadBean(t, &EmailAddress{UID: 1, Email: "user1new@example.com", IsPendingPrimary: true})
+	AssertExistsAndLoadBean(t, &User{ID: 1, Email: "user1@example.com"})
+
+	assert.NoError(t, pending.Activate())
+	AssertExistsAndLoadBean(t, &User{ID: 1, Email: "user1new@example.com"})
+	count, err := x.Where("uid = ? AND is_pending_primary = ?", 1, true).Count(new(EmailAddress))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+
+	// No confirmation required
+	setting.Service.RegisterEmailConfirm = false
+	user = AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	pending, err = ChangePrimaryEmail(user, "user1other@example.com")
+	assert.NoError(t, err)
+	assert.Nil(t, pending)
+	assert.Equal(t, "user1other@example.com", user.Email)
+	AssertExistsAndLoadBean(t, &User{ID: 1, Email: "user1other@example.com"})
+	AssertExistsAndLoadBean(t, &EmailAddress{Email: "user1new@example.com", IsActivated: true})
+}
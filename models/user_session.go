@@ -0,0 +1,123 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"time"
+
+	"github.com/go-xorm/xorm"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// userSessionTouchInterval is how often the last use of a session is saved.
+const userSessionTouchInterval = time.Minute
+
+// UserSession represents a web session a user is signed in with, whatever
+// the session provider is.
+type UserSession struct {
+	ID          int64  `xorm:"pk autoincr"`
+	UserID      int64  `xorm:"INDEX NOT NULL"`
+	SessionID   string `xorm:"VARCHAR(64) UNIQUE NOT NULL"`
+	IP          string `xorm:"VARCHAR(64)"`
+	UserAgent   string
+	Created     time.Time `xorm:"-"`
+	CreatedUnix int64     `xorm:"created"`
+	Updated     time.Time `xorm:"-"`
+	UpdatedUnix int64     `xorm:"INDEX"`
+}
+
+// AfterSet is invoked from XORM after setting the value of a field of this object.
+func (s *UserSession) AfterSet(colName string, _ xorm.Cell) {
+	switch colName {
+	case "created_unix":
+		s.Created = time.Unix(s.CreatedUnix, 0).Local()
+	case "updated_unix":
+		s.Updated = time.Unix(s.UpdatedUnix, 0).Local()
+	}
+}
+
+// TouchUserSession records that the user is signed in with the session of
+// given ID, and when the session was last used from where.
+func TouchUserSession(userID int64, sid, ip, userAgent string) error {
+	s := &UserSession{SessionID: sid}
+	has, err := x.Get(s)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if !has || s.UserID != userID {
+		if has {
+			if _, err = x.Id(s.ID).Delete(new(UserSession)); err != nil {
+				return err
+			}
+		}
+		_, err = x.Insert(&UserSession{
+			UserID:      userID,
+			SessionID:   sid,
+			IP:          ip,
+			UserAgent:   userAgent,
+			UpdatedUnix: now.Unix(),
+		})
+		return err
+	} else if time.Unix(s.UpdatedUnix, 0).Add(userSessionTouchInterval).After(now) {
+		return nil
+	}
+
+	s.IP = ip
+	s.UserAgent = userAgent
+	s.UpdatedUnix = now.Unix()
+	_, err = x.Id(s.ID).Cols("ip", "user_agent", "updated_unix").Update(s)
+	return err
+}
+
+// DeleteUserSession forgets the session with given ID, when the user signs out.
+func DeleteUserSession(sid string) error {
+	_, err := x.Delete(&UserSession{SessionID: sid})
+	return err
+}
+
+// GetUserSessions returns the sessions the user is signed in with, the most
+// recently used first.
+func GetUserSessions(userID int64) ([]*UserSession, error) {
+	sessions := make([]*UserSession, 0, 5)
+	return sessions, x.
+		Where("user_id = ? AND updated_unix > ?", userID, time.Now().Unix()-setting.SessionConfig.Maxlifetime).
+		Desc("updated_unix").
+		Find(&sessions)
+}
+
+// RevokeUserSessions forgets given sessions of the user, the data of the
+// sessions must be removed from the session provider first. The sign-ins
+// remembered by the browsers of the user are forgotten too.
+func RevokeUserSessions(u *User, sessions []*UserSession) (err error) {
+	sess := x.NewSession()
+	defer sessionRelease(sess)
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	for _, s := range sessions {
+		if _, err = sess.Where("id = ? AND user_id = ?", s.ID, u.ID).Delete(new(UserSession)); err != nil {
+			return err
+		}
+	}
+
+	if u.Rands, err = GetUserSalt(); err != nil {
+		return err
+	}
+	if _, err = sess.Id(u.ID).Cols("rands").Update(u); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// DeleteExpiredUserSessions forgets the sessions which have not been used for
+// longer than maxLifetime seconds.
+func DeleteExpiredUserSessions(maxLifetime int64) error {
+	_, err := x.Where("updated_unix <= ?", time.Now().Unix()-maxLifetime).Delete(new(UserSession))
+	return err
+}
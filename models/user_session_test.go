@@ -0,0 +1,59 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserSessions(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer func(maxLifetime int64) {
+		setting.SessionConfig.Maxlifetime = maxLifetime
+	}(setting.SessionConfig.Maxlifetime)
+	setting.SessionConfig.Maxlifetime = 3600
+
+	assert.NoError(t, TouchUserSession(2, "session1", "1.2.3.4", "agent 1"))
+	assert.NoError(t, TouchUserSession(2, "session2", "5.6.7.8", "agent 2"))
+	assert.NoError(t, TouchUserSession(4, "session3", "1.2.3.4", "agent 3"))
+
+	// Used again from elsewhere within the touch interval
+	assert.NoError(t, TouchUserSession(2, "session1", "9.9.9.9", "agent 1"))
+	AssertExistsAndLoadBean(t, &UserSession{SessionID: "session1", IP: "1.2.3.4"})
+
+	// Reused by another user after signing out
+	assert.NoError(t, TouchUserSession(4, "session2", "5.6.7.8", "agent 2"))
+	AssertExistsAndLoadBean(t, &UserSession{SessionID: "session2", UserID: 4})
+	assert.NoError(t, TouchUserSession(2, "session2", "5.6.7.8", "agent 2"))
+
+	sessions, err := GetUserSessions(2)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	rands := user.Rands
+	assert.NoError(t, RevokeUserSessions(user, sessions[:1]))
+	assert.NotEqual(t, rands, user.Rands)
+	AssertExistsAndLoadBean(t, &User{ID: 2, Rands: user.Rands})
+	AssertNotExistsBean(t, &UserSession{ID: sessions[0].ID})
+
+	// Sessions of other users are not revoked
+	other := AssertExistsAndLoadBean(t, &UserSession{SessionID: "session3"}).(*UserSession)
+	assert.NoError(t, RevokeUserSessions(user, []*UserSession{other}))
+	AssertExistsAndLoadBean(t, &UserSession{SessionID: "session3"})
+
+	assert.NoError(t, DeleteUserSession("session3"))
+	AssertNotExistsBean(t, &UserSession{SessionID: "session3"})
+
+	_, err = x.Exec("UPDATE `user_session` SET updated_unix = ?", time.Now().Add(-2*time.Hour).Unix())
+	assert.NoError(t, err)
+	assert.NoError(t, DeleteExpiredUserSessions(3600))
+	AssertNotExistsBean(t, &UserSession{UserID: 2})
+}
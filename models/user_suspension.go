@@ -0,0 +1,74 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// suspensionCols are the columns of the user table changed by a suspension.
+var suspensionCols = []string{"is_suspended", "suspend_reason", "suspended_by_id", "suspended_unix"}
+
+// SuspendUser suspends the user with a reason shown to them when they sign
+// in: they can no longer sign in, use the API nor git, and their web
+// sessions are ended, but their repositories, issues and comments are kept.
+// doer is nil for the synchronization of external users.
+func SuspendUser(u, doer *User, reason string) (err error) {
+	if u.IsOrganization() {
+		return fmt.Errorf("organization can't be suspended [uid: %d]", u.ID)
+	}
+
+	u.IsSuspended = true
+	u.SuspendReason = reason
+	u.SuspendedByID = 0
+	if doer != nil {
+		u.SuspendedByID = doer.ID
+	}
+	u.SuspendedUnix = time.Now().Unix()
+	u.Suspended = time.Unix(u.SuspendedUnix, 0).Local()
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err = sess.Id(u.ID).Cols(suspensionCols...).Update(u); err != nil {
+		return fmt.Errorf("update user: %v", err)
+	} else if _, err = sess.Delete(&UserSession{UserID: u.ID}); err != nil {
+		return fmt.Errorf("delete user sessions: %v", err)
+	}
+	return sess.Commit()
+}
+
+// UnsuspendUser lifts the suspension of the user.
+func UnsuspendUser(u *User) error {
+	u.IsSuspended = false
+	u.SuspendReason = ""
+	u.SuspendedByID = 0
+	u.SuspendedUnix = 0
+	if _, err := x.Id(u.ID).Cols(suspensionCols...).Update(u); err != nil {
+		return fmt.Errorf("update user: %v", err)
+	}
+	return nil
+}
+
+// GetSuspendedBy returns the admin who suspended the user, or nil if the
+// user was suspended by the synchronization of external users or the admin
+// has been deleted.
+func (u *User) GetSuspendedBy() (*User, error) {
+	if u.SuspendedByID == 0 {
+		return nil, nil
+	}
+	doer, err := GetUserByID(u.SuspendedByID)
+	if err != nil {
+		if IsErrUserNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return doer, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuspendUser(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer func(maxLifetime int64) {
+		setting.SessionConfig.Maxlifetime = maxLifetime
+	}(setting.SessionConfig.Maxlifetime)
+	setting.SessionConfig.Maxlifetime = 3600
+
+	admin := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.NoError(t, TouchUserSession(user.ID, "session1", "1.2.3.4", "agent"))
+
+	assert.NoError(t, SuspendUser(user, admin, "spam"))
+	user = AssertExistsAndLoadBean(t, &User{ID: 2, IsSuspended: true}).(*User)
+	assert.Equal(t, "spam", user.SuspendReason)
+	assert.EqualValues(t, admin.ID, user.SuspendedByID)
+	assert.NotZero(t, user.SuspendedUnix)
+	AssertNotExistsBean(t, &UserSession{UserID: user.ID})
+
+	suspendedBy, err := user.GetSuspendedBy()
+	assert.NoError(t, err)
+	if assert.NotNil(t, suspendedBy) {
+		assert.EqualValues(t, admin.ID, suspendedBy.ID)
+	}
+
+	_, err = UserSignIn(user.Name, "password")
+	if assert.True(t, IsErrUserSuspended(err)) {
+		assert.Equal(t, "spam", err.(ErrUserSuspended).Reason)
+	}
+	_, err = UserSignIn(user.Name, "wrong")
+	assert.False(t, IsErrUserSuspended(err))
+
+	assert.NoError(t, UnsuspendUser(user))
+	user = AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.False(t, user.IsSuspended)
+	assert.Empty(t, user.SuspendReason)
+	assert.Zero(t, user.SuspendedByID)
+
+	u, err := UserSignIn(user.Name, "password")
+	assert.NoError(t, err)
+	assert.EqualValues(t, user.ID, u.ID)
+
+	org := AssertExistsAndLoadBean(t, &User{ID: 3, Type: UserTypeOrganization}).(*User)
+	assert.Error(t, SuspendUser(org, admin, "spam"))
+}
+
+func TestSuspendUser_Sync(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.NoError(t, SuspendUser(user, nil, "Removed from the directory"))
+	user = AssertExistsAndLoadBean(t, &User{ID: 2, IsSuspended: true}).(*User)
+	assert.Zero(t, user.SuspendedByID)
+
+	suspendedBy, err := user.GetSuspendedBy()
+	assert.NoError(t, err)
+	assert.Nil(t, suspendedBy)
+}
@@ -5,8 +5,12 @@
 package models
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
+	"time"
 
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/setting"
 
 	"github.com/stretchr/testify/assert"
@@ -20,6 +24,55 @@ func TestGetUserEmailsByNames(t *testing.T) {
 	assert.Equal(t, []string{"user8@example.com", "user5@example.com"}, GetUserEmailsByNames([]string{"user8", "user5"}))
 }
 
+func TestGetAvatarEmailByHash(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	email, err := GetAvatarEmailByHash("avatar2")
+	assert.NoError(t, err)
+	assert.Equal(t, "user2@example.com", email)
+
+	email, err = GetAvatarEmailByHash("nonexistent")
+	assert.NoError(t, err)
+	assert.Empty(t, email)
+}
+
+func TestSizedRelAvatarLink(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	setting.DisableGravatar = false
+	setting.EnableFederatedAvatar = false
+	setting.GravatarSource = "https://secure.gravatar.com/avatar/"
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.Equal(t, "https://secure.gravatar.com/avatar/"+base.HashEmail(user.AvatarEmail)+"?s=80", user.SizedRelAvatarLink(80))
+
+	user.UseCustomAvatar = true
+	user.Avatar = "custom"
+	assert.NoError(t, os.MkdirAll(setting.AvatarUploadPath, os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(user.CustomAvatarPath(), nil, 0644))
+	defer os.Remove(user.CustomAvatarPath())
+	assert.Equal(t, setting.AppSubURL+"/avatars/custom?size=80", user.SizedRelAvatarLink(80))
+}
+
+func TestGetUsersWithStatusByNames(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	users, err := GetUsersWithStatusByNames([]string{"user1", "user2"})
+	assert.NoError(t, err)
+	assert.Len(t, users, 0)
+
+	_, err = x.Id(2).Cols("is_busy").Update(&User{IsBusy: true})
+	assert.NoError(t, err)
+	_, err = x.Id(4).Cols("status_message").Update(&User{StatusMessage: "On vacation"})
+	assert.NoError(t, err)
+
+	users, err = GetUsersWithStatusByNames([]string{"User2", "user3", "user4"})
+	assert.NoError(t, err)
+	if assert.Len(t, users, 2) {
+		assert.EqualValues(t, 2, users[0].ID)
+		assert.True(t, users[0].HasStatus())
+		assert.Equal(t, "On vacation", users[1].StatusMessage)
+	}
+}
+
 func TestCanCreateOrganization(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 
@@ -38,6 +91,22 @@ func TestCanCreateOrganization(t *testing.T) {
 	assert.False(t, user.CanCreateOrganization())
 }
 
+func TestCanImpersonate(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	admin := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	org := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
+	assert.True(t, admin.CanImpersonate(user))
+	assert.False(t, admin.CanImpersonate(admin))
+	assert.False(t, admin.CanImpersonate(org))
+	assert.False(t, user.CanImpersonate(admin))
+
+	setting.Admin.DisableImpersonation = true
+	assert.False(t, admin.CanImpersonate(user))
+	setting.Admin.DisableImpersonation = false
+}
+
 func TestDeleteUser(t *testing.T) {
 	test := func(userID int64) {
 		assert.NoError(t, PrepareTestDatabase())
@@ -69,3 +138,23 @@ func TestDeleteUser(t *testing.T) {
 	test(8)
 	test(11)
 }
+
+func TestIsPasswordExpired(t *testing.T) {
+	defer func(days int) {
+		setting.PasswordExpiryDays = days
+	}(setting.PasswordExpiryDays)
+
+	user := &User{Passwd: "password", Salt: "salt"}
+	user.EncodePasswd()
+	setting.PasswordExpiryDays = 0
+	assert.False(t, user.IsPasswordExpired())
+
+	setting.PasswordExpiryDays = 30
+	assert.False(t, user.IsPasswordExpired())
+
+	user.PasswdChangedUnix = time.Now().Add(-31 * 24 * time.Hour).Unix()
+	assert.True(t, user.IsPasswordExpired())
+
+	user.LoginType = LoginOAuth2
+	assert.False(t, user.IsPasswordExpired())
+}
@@ -6,10 +6,13 @@
 package models
 
 import (
-	"crypto/tls"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"strings"
 	"time"
 
@@ -25,7 +28,7 @@ import (
 )
 
 // HookQueue is a global queue of web hooks
-var HookQueue = sync.NewUniqueQueue(setting.Webhook.QueueLength)
+var HookQueue sync.Queue = sync.NewUniqueQueue(setting.Webhook.QueueLength)
 
 // HookContentType is the content type of a web hook
 type HookContentType int
@@ -66,9 +69,12 @@ func IsValidHookContentType(name string) bool {
 
 // HookEvents is a set of web hook events
 type HookEvents struct {
-	Create      bool `json:"create"`
-	Push        bool `json:"push"`
-	PullRequest bool `json:"pull_request"`
+	Create       bool `json:"create"`
+	Push         bool `json:"push"`
+	PullRequest  bool `json:"pull_request"`
+	Issues       bool `json:"issues"`
+	IssueComment bool `json:"issue_comment"`
+	Status       bool `json:"status"`
 }
 
 // HookEvent represents events that will delivery hook.
@@ -98,7 +104,11 @@ type Webhook struct {
 	URL          string `xorm:"url TEXT"`
 	ContentType  HookContentType
 	Secret       string `xorm:"TEXT"`
-	Events       string `xorm:"TEXT"`
+	// PreviousSecret still signs the deliveries until it expires, to rotate
+	// the secret without failing deliveries.
+	PreviousSecret            string `xorm:"TEXT"`
+	PreviousSecretExpiresUnix int64
+	Events                    string `xorm:"TEXT"`
 	*HookEvent   `xorm:"-"`
 	IsSSL        bool `xorm:"is_ssl"`
 	IsActive     bool `xorm:"INDEX"`
@@ -155,6 +165,92 @@ func (w *Webhook) History(page int) ([]*HookTask, error) {
 	return HookTasks(w.ID, page)
 }
 
+// HookFailureSummary summarizes the recent deliveries of a webhook.
+type HookFailureSummary struct {
+	Total       int
+	Failed      int
+	LastFailure *HookTask
+}
+
+// RecentFailures summarizes failures among the last n deliveries of the webhook.
+func (w *Webhook) RecentFailures(n int) (*HookFailureSummary, error) {
+	tasks := make([]*HookTask, 0, n)
+	if err := x.
+		Where("hook_id=? AND is_delivered=?", w.ID, true).
+		Desc("id").
+		Limit(n).
+		Find(&tasks); err != nil {
+		return nil, err
+	}
+
+	summary := &HookFailureSummary{Total: len(tasks)}
+	for _, t := range tasks {
+		if t.IsSucceed {
+			continue
+		}
+		summary.Failed++
+		if summary.LastFailure == nil {
+			summary.LastFailure = t
+		}
+	}
+	return summary, nil
+}
+
+// ChangeSecret sets a new secret for the webhook. The previous secret keeps
+// signing the deliveries during the grace period set in the configuration, so
+// that receivers can be updated without rejecting deliveries.
+func (w *Webhook) ChangeSecret(secret string) {
+	if secret == w.Secret {
+		return
+	}
+
+	w.PreviousSecret = ""
+	w.PreviousSecretExpiresUnix = 0
+	if len(w.Secret) > 0 && setting.Webhook.SecretRotationGracePeriod > 0 {
+		w.PreviousSecret = w.Secret
+		w.PreviousSecretExpiresUnix = time.Now().
+			Add(time.Duration(setting.Webhook.SecretRotationGracePeriod) * time.Hour).Unix()
+	}
+	w.Secret = secret
+}
+
+// IsRotatingSecret returns true if the deliveries are still signed with the
+// previous secret.
+func (w *Webhook) IsRotatingSecret() bool {
+	return len(w.PreviousSecret) > 0 && time.Now().Unix() < w.PreviousSecretExpiresUnix
+}
+
+// PreviousSecretExpires returns the time the previous secret stops signing
+// the deliveries.
+func (w *Webhook) PreviousSecretExpires() time.Time {
+	return time.Unix(w.PreviousSecretExpiresUnix, 0).Local()
+}
+
+func signPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureHeaders returns the signature headers a delivery of given payload
+// carries, the signature is the hex encoded HMAC-SHA256 of the payload keyed
+// with the secret of the webhook. While the secret is rotated, the signature
+// with the previous secret is sent as well.
+func (w *Webhook) SignatureHeaders(payload []byte) map[string]string {
+	headers := make(map[string]string)
+	if len(w.Secret) == 0 || w.HookTaskType == SLACK {
+		return headers
+	}
+
+	signature := signPayload(w.Secret, payload)
+	headers["X-Gitea-Signature"] = signature
+	headers["X-Gogs-Signature"] = signature
+	if w.IsRotatingSecret() {
+		headers["X-Gitea-Signature-Previous"] = signPayload(w.PreviousSecret, payload)
+	}
+	return headers
+}
+
 // UpdateEvent handles conversion from HookEvent to Events.
 func (w *Webhook) UpdateEvent() error {
 	data, err := json.Marshal(w.HookEvent)
@@ -180,9 +276,27 @@ func (w *Webhook) HasPullRequestEvent() bool {
 		(w.ChooseEvents && w.HookEvents.PullRequest)
 }
 
+// HasIssuesEvent returns true if hook enabled issues event.
+func (w *Webhook) HasIssuesEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Issues)
+}
+
+// HasIssueCommentEvent returns true if hook enabled issue comment event.
+func (w *Webhook) HasIssueCommentEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.IssueComment)
+}
+
+// HasStatusEvent returns true if hook enabled commit status event.
+func (w *Webhook) HasStatusEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Status)
+}
+
 // EventsArray returns an array of hook events
 func (w *Webhook) EventsArray() []string {
-	events := make([]string, 0, 3)
+	events := make([]string, 0, 6)
 	if w.HasCreateEvent() {
 		events = append(events, "create")
 	}
@@ -192,6 +306,15 @@ func (w *Webhook) EventsArray() []string {
 	if w.HasPullRequestEvent() {
 		events = append(events, "pull_request")
 	}
+	if w.HasIssuesEvent() {
+		events = append(events, "issues")
+	}
+	if w.HasIssueCommentEvent() {
+		events = append(events, "issue_comment")
+	}
+	if w.HasStatusEvent() {
+		events = append(events, "status")
+	}
 	return events
 }
 
@@ -351,9 +474,12 @@ type HookEventType string
 
 // Types of hook events
 const (
-	HookEventCreate      HookEventType = "create"
-	HookEventPush        HookEventType = "push"
-	HookEventPullRequest HookEventType = "pull_request"
+	HookEventCreate       HookEventType = "create"
+	HookEventPush         HookEventType = "push"
+	HookEventPullRequest  HookEventType = "pull_request"
+	HookEventIssues       HookEventType = "issues"
+	HookEventIssueComment HookEventType = "issue_comment"
+	HookEventStatus       HookEventType = "status"
 )
 
 // HookRequest represents hook task request information.
@@ -470,8 +596,25 @@ func UpdateHookTask(t *HookTask) error {
 	return err
 }
 
+// isConfidentialPayload returns true if the payload is about a confidential
+// issue.
+func isConfidentialPayload(p api.Payloader) bool {
+	switch p := p.(type) {
+	case *IssuePayload:
+		return p.Issue != nil && p.Issue.Confidential
+	case *IssueCommentPayload:
+		return p.Issue != nil && p.Issue.Confidential
+	}
+	return false
+}
+
 // PrepareWebhooks adds new webhooks to task queue for given payload.
 func PrepareWebhooks(repo *Repository, event HookEventType, p api.Payloader) error {
+	// Webhooks are delivered to services anyone may have access to.
+	if isConfidentialPayload(p) {
+		return nil
+	}
+
 	ws, err := GetActiveWebhooksByRepoID(repo.ID)
 	if err != nil {
 		return fmt.Errorf("GetActiveWebhooksByRepoID: %v", err)
@@ -506,6 +649,18 @@ func PrepareWebhooks(repo *Repository, event HookEventType, p api.Payloader) err
 			if !w.HasPullRequestEvent() {
 				continue
 			}
+		case HookEventIssues:
+			if !w.HasIssuesEvent() {
+				continue
+			}
+		case HookEventIssueComment:
+			if !w.HasIssueCommentEvent() {
+				continue
+			}
+		case HookEventStatus:
+			if !w.HasStatusEvent() {
+				continue
+			}
 		}
 
 		// Use separate objects so modifications won't be made on payload on non-Gogs/Gitea type hooks.
@@ -546,14 +701,25 @@ func (t *HookTask) deliver() {
 		Header("X-Gogs-Delivery", t.UUID).
 		Header("X-Gogs-Event", string(t.EventType)).
 		Header("X-GitHub-Delivery", t.UUID).
-		Header("X-GitHub-Event", string(t.EventType)).
-		SetTLSClientConfig(&tls.Config{InsecureSkipVerify: setting.Webhook.SkipTLSVerify})
+		Header("X-GitHub-Event", string(t.EventType))
 
+	// The signatures are computed over the raw body of the request.
+	body := t.PayloadContent
 	switch t.ContentType {
 	case ContentTypeJSON:
-		req = req.Header("Content-Type", "application/json").Body(t.PayloadContent)
+		req = req.Header("Content-Type", "application/json")
 	case ContentTypeForm:
-		req.Param("payload", t.PayloadContent)
+		req = req.Header("Content-Type", "application/x-www-form-urlencoded")
+		body = "payload=" + url.QueryEscape(t.PayloadContent)
+	}
+	req = req.Body(body)
+
+	if w, err := getWebhook(&Webhook{ID: t.HookID}); err != nil {
+		log.Error(5, "GetWebhookByID [%d]: %v", t.HookID, err)
+	} else {
+		for k, v := range w.SignatureHeaders([]byte(body)) {
+			req = req.Header(k, v)
+		}
 	}
 
 	// Record delivery information.
@@ -593,7 +759,12 @@ func (t *HookTask) deliver() {
 		}
 	}()
 
-	resp, err := req.Response()
+	trans, err := newWebhookTransport(timeout)
+	if err != nil {
+		t.ResponseInfo.Body = fmt.Sprintf("Delivery: %v", err)
+		return
+	}
+	resp, err := req.SetTransport(trans).Response()
 	if err != nil {
 		t.ResponseInfo.Body = fmt.Sprintf("Delivery: %v", err)
 		return
@@ -637,21 +808,26 @@ func DeliverHooks() {
 	// Start listening on new hook requests.
 	for repoID := range HookQueue.Queue() {
 		log.Trace("DeliverHooks [repo_id: %v]", repoID)
-		HookQueue.Remove(repoID)
+		finishQueueItem(HookQueue, repoID, deliverRepoHooks(repoID))
+	}
+}
 
-		tasks = make([]*HookTask, 0, 5)
-		if err := x.Where("repo_id=? AND is_delivered=?", repoID, false).Find(&tasks); err != nil {
-			log.Error(4, "Get repository [%s] hook tasks: %v", repoID, err)
-			continue
-		}
-		for _, t := range tasks {
-			t.deliver()
-			if err := UpdateHookTask(t); err != nil {
-				log.Error(4, "UpdateHookTask [%d]: %v", t.ID, err)
-				continue
-			}
+// deliverRepoHooks delivers the hook tasks of the repository which are not
+// delivered yet.
+func deliverRepoHooks(repoID string) error {
+	tasks := make([]*HookTask, 0, 5)
+	if err := x.Where("repo_id=? AND is_delivered=?", repoID, false).Find(&tasks); err != nil {
+		log.Error(4, "Get repository [%s] hook tasks: %v", repoID, err)
+		return err
+	}
+	for _, t := range tasks {
+		t.deliver()
+		if err := UpdateHookTask(t); err != nil {
+			log.Error(4, "UpdateHookTask [%d]: %v", t.ID, err)
+			return err
 		}
 	}
+	return nil
 }
 
 // InitDeliverHooks starts the hooks delivery thread
@@ -0,0 +1,226 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/httplib"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// privateNetworks are the loopback, private, link-local and unspecified
+// networks webhooks are not delivered to when private addresses are denied.
+var privateNetworks = mustParseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::/128",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets[i] = ipNet
+	}
+	return nets
+}
+
+func isPrivateIP(ip net.IP) bool {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	for _, ipNet := range privateNetworks {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAllowedWebhookHost returns true if given host, or the IP address it
+// resolves to, is in the list of hosts allowed even when private addresses
+// are denied.
+func isAllowedWebhookHost(host string, ip net.IP) bool {
+	for _, allowed := range setting.Webhook.AllowedHosts {
+		allowed = strings.TrimSpace(allowed)
+		if len(allowed) == 0 {
+			continue
+		}
+		if strings.EqualFold(allowed, host) {
+			return true
+		}
+		if ip == nil {
+			continue
+		}
+		if strings.Contains(allowed, "/") {
+			if _, ipNet, err := net.ParseCIDR(allowed); err == nil && ipNet.Contains(ip) {
+				return true
+			}
+		} else if allowedIP := net.ParseIP(allowed); allowedIP != nil && allowedIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkWebhookAddress returns an error if webhooks must not be delivered to
+// given IP address of given host.
+func checkWebhookAddress(host string, ip net.IP) error {
+	if !setting.Webhook.DenyPrivateAddresses || !isPrivateIP(ip) || isAllowedWebhookHost(host, ip) {
+		return nil
+	}
+	return ErrWebhookAddressDenied{host, ip}
+}
+
+// addressCheck returns an error if no connection must be made to given IP
+// address of given host.
+type addressCheck func(host string, ip net.IP) error
+
+// resolveHost resolves given host and checks all its addresses, so the
+// connections are only made to a host none of the addresses of which is
+// denied.
+func resolveHost(host string, check addressCheck) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, check(host, ip)
+	}
+
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		if err = check(host, ip); err != nil {
+			return nil, err
+		}
+	}
+	return ips, nil
+}
+
+// checkWebhookURL resolves the host of given URL and checks all its addresses.
+// It is used when the deliveries go through a proxy, the addresses of the
+// connections are then checked by the proxy only.
+func checkWebhookURL(rawURL string) error {
+	if !setting.Webhook.DenyPrivateAddresses {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := u.Hostname()
+	if isAllowedWebhookHost(host, nil) {
+		return nil
+	}
+	_, err = resolveHost(host, checkWebhookAddress)
+	return err
+}
+
+// checkedDialer returns a dialer which resolves the host name itself, checks
+// the addresses and connects to the checked addresses only, so the check
+// cannot be bypassed by a host name resolving to different addresses.
+func checkedDialer(timeout time.Duration, check addressCheck) func(netw, addr string) (net.Conn, error) {
+	return func(netw, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		ips, err := resolveHost(host, check)
+		if err != nil {
+			return nil, err
+		}
+
+		var conn net.Conn
+		for _, ip := range ips {
+			conn, err = net.DialTimeout(netw, net.JoinHostPort(ip.String(), port), timeout)
+			if err == nil {
+				conn.SetDeadline(time.Now().Add(timeout))
+				return conn, nil
+			}
+		}
+		return nil, err
+	}
+}
+
+// webhookDialer returns the dialer webhooks are delivered with when they do
+// not go through a proxy.
+func webhookDialer(timeout time.Duration) func(netw, addr string) (net.Conn, error) {
+	dial := checkedDialer(timeout, checkWebhookAddress)
+	return func(netw, addr string) (net.Conn, error) {
+		if !setting.Webhook.DenyPrivateAddresses {
+			return httplib.TimeoutDialer(timeout, timeout)(netw, addr)
+		}
+		return dial(netw, addr)
+	}
+}
+
+// checkedTransport is a transport whose every request, the ones following
+// redirects included, is checked: without proxy the addresses of the
+// connections are checked by the dialer, through a proxy the addresses the
+// host resolves to are checked by checkURL before the request.
+type checkedTransport struct {
+	direct   *http.Transport
+	proxy    *http.Transport
+	checkURL func(rawURL string) error
+}
+
+// newWebhookTransport returns a new transport to deliver webhooks with.
+func newWebhookTransport(timeout time.Duration) (*checkedTransport, error) {
+	proxy := http.ProxyFromEnvironment
+	if len(setting.Webhook.ProxyURL) > 0 {
+		proxyURL, err := url.Parse(setting.Webhook.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("parse proxy URL: %v", err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: setting.Webhook.SkipTLSVerify}
+	return &checkedTransport{
+		direct: &http.Transport{
+			TLSClientConfig: tlsConfig,
+			Dial:            webhookDialer(timeout),
+		},
+		proxy: &http.Transport{
+			TLSClientConfig: tlsConfig,
+			Proxy:           proxy,
+			Dial:            httplib.TimeoutDialer(timeout, timeout),
+		},
+		checkURL: checkWebhookURL,
+	}, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *checkedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	proxyURL, err := t.proxy.Proxy(req)
+	if err != nil {
+		return nil, fmt.Errorf("proxy: %v", err)
+	} else if proxyURL == nil {
+		return t.direct.RoundTrip(req)
+	}
+
+	if err = t.checkURL(req.URL.String()); err != nil {
+		return nil, err
+	}
+	return t.proxy.RoundTrip(req)
+}
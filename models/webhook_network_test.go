@@ -0,0 +1,87 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPrivateIP(t *testing.T) {
+	for _, ip := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0", "::1", "::", "fd00::1", "fe80::1", "::ffff:127.0.0.1"} {
+		assert.True(t, isPrivateIP(net.ParseIP(ip)), ip)
+	}
+	for _, ip := range []string{"8.8.8.8", "172.32.0.1", "2001:4860:4860::8888"} {
+		assert.False(t, isPrivateIP(net.ParseIP(ip)), ip)
+	}
+}
+
+func TestCheckWebhookAddress(t *testing.T) {
+	defer func(deny bool, allowed []string) {
+		setting.Webhook.DenyPrivateAddresses = deny
+		setting.Webhook.AllowedHosts = allowed
+	}(setting.Webhook.DenyPrivateAddresses, setting.Webhook.AllowedHosts)
+
+	setting.Webhook.DenyPrivateAddresses = false
+	assert.NoError(t, checkWebhookAddress("localhost", net.ParseIP("127.0.0.1")))
+
+	setting.Webhook.DenyPrivateAddresses = true
+	setting.Webhook.AllowedHosts = []string{"ci.internal", "10.0.0.0/24", "192.168.1.5"}
+	err := checkWebhookAddress("localhost", net.ParseIP("127.0.0.1"))
+	assert.True(t, IsErrWebhookAddressDenied(err))
+	assert.NoError(t, checkWebhookAddress("example.com", net.ParseIP("8.8.8.8")))
+	assert.NoError(t, checkWebhookAddress("CI.internal", net.ParseIP("10.9.9.9")))
+	assert.NoError(t, checkWebhookAddress("build", net.ParseIP("10.0.0.7")))
+	assert.NoError(t, checkWebhookAddress("build", net.ParseIP("192.168.1.5")))
+	assert.Error(t, checkWebhookAddress("build", net.ParseIP("192.168.1.6")))
+
+	assert.Error(t, checkWebhookURL("http://127.0.0.1:3000/hook"))
+	assert.NoError(t, checkWebhookURL("http://ci.internal/hook"))
+}
+
+func TestWebhookTransport(t *testing.T) {
+	defer func(deny bool, allowed []string) {
+		setting.Webhook.DenyPrivateAddresses = deny
+		setting.Webhook.AllowedHosts = allowed
+	}(setting.Webhook.DenyPrivateAddresses, setting.Webhook.AllowedHosts)
+
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer internal.Close()
+	redirect := httptest.NewServer(http.RedirectHandler(internal.URL, http.StatusFound))
+	defer redirect.Close()
+
+	setting.Webhook.DenyPrivateAddresses = true
+	setting.Webhook.AllowedHosts = []string{"localhost"}
+
+	_, err := webhookDialer(time.Second)("tcp", strings.TrimPrefix(internal.URL, "http://"))
+	assert.True(t, IsErrWebhookAddressDenied(err))
+
+	trans, err := newWebhookTransport(time.Second)
+	assert.NoError(t, err)
+	client := &http.Client{Transport: trans}
+	redirectURL := strings.Replace(redirect.URL, "127.0.0.1", "localhost", 1)
+
+	resp, err := client.Post(redirectURL, "application/json", nil)
+	assert.Error(t, err)
+	if err == nil {
+		resp.Body.Close()
+	}
+
+	setting.Webhook.AllowedHosts = []string{"localhost", "127.0.0.1"}
+	resp, err = client.Post(redirectURL, "application/json", nil)
+	assert.NoError(t, err)
+	if err == nil {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"encoding/json"
+
+	api "code.gitea.io/sdk/gitea"
+)
+
+// The webhook payloads of events and fields the SDK does not know about are
+// defined here, so that they are kept when the SDK is vendored again.
+
+const (
+	// HookIssuePinned is an issue action for when an issue is pinned to the top of the issue list.
+	HookIssuePinned api.HookIssueAction = "pinned"
+	// HookIssueUnpinned is an issue action for when an issue is unpinned.
+	HookIssueUnpinned api.HookIssueAction = "unpinned"
+)
+
+// ChangesPayload represents the payload information of the changes made by
+// an edition, Ref is the former base branch of a pull request.
+type ChangesPayload struct {
+	Title *api.ChangesFromPayload `json:"title,omitempty"`
+	Body  *api.ChangesFromPayload `json:"body,omitempty"`
+	Ref   *api.ChangesFromPayload `json:"ref,omitempty"`
+}
+
+// IssuePayload represents the payload information that is sent along with an issue event.
+type IssuePayload struct {
+	Secret     string              `json:"secret"`
+	Action     api.HookIssueAction `json:"action"`
+	Index      int64               `json:"number"`
+	Changes    *ChangesPayload     `json:"changes,omitempty"`
+	Issue      *APIIssue           `json:"issue"`
+	Repository *api.Repository     `json:"repository"`
+	Sender     *api.User           `json:"sender"`
+}
+
+// SetSecret modifies the secret of the IssuePayload.
+func (p *IssuePayload) SetSecret(secret string) {
+	p.Secret = secret
+}
+
+// JSONPayload implements Payload
+func (p *IssuePayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// PullRequestPayload represents a payload information of pull request event.
+type PullRequestPayload struct {
+	Secret      string              `json:"secret"`
+	Action      api.HookIssueAction `json:"action"`
+	Index       int64               `json:"number"`
+	Changes     *ChangesPayload     `json:"changes,omitempty"`
+	PullRequest *api.PullRequest    `json:"pull_request"`
+	Repository  *api.Repository     `json:"repository"`
+	Sender      *api.User           `json:"sender"`
+}
+
+// SetSecret modifies the secret of the PullRequestPayload.
+func (p *PullRequestPayload) SetSecret(secret string) {
+	p.Secret = secret
+}
+
+// JSONPayload implements Payload
+func (p *PullRequestPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookIssueCommentAction defines hook issue comment action
+type HookIssueCommentAction string
+
+const (
+	// HookIssueCommentCreated created
+	HookIssueCommentCreated HookIssueCommentAction = "created"
+	// HookIssueCommentEdited edited
+	HookIssueCommentEdited HookIssueCommentAction = "edited"
+	// HookIssueCommentDeleted deleted
+	HookIssueCommentDeleted HookIssueCommentAction = "deleted"
+)
+
+// IssueCommentPayload represents a payload information of issue comment event.
+type IssueCommentPayload struct {
+	Secret     string                 `json:"secret"`
+	Action     HookIssueCommentAction `json:"action"`
+	Issue      *APIIssue              `json:"issue"`
+	Comment    *api.Comment           `json:"comment"`
+	Changes    *ChangesPayload        `json:"changes,omitempty"`
+	Repository *api.Repository        `json:"repository"`
+	Sender     *api.User              `json:"sender"`
+}
+
+// SetSecret modifies the secret of the IssueCommentPayload.
+func (p *IssueCommentPayload) SetSecret(secret string) {
+	p.Secret = secret
+}
+
+// JSONPayload implements Payload
+func (p *IssueCommentPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// StatusPayload represents a payload information of commit status event.
+type StatusPayload struct {
+	Secret      string          `json:"secret"`
+	ID          int64           `json:"id"`
+	SHA         string          `json:"sha"`
+	State       api.StatusState `json:"state"`
+	Context     string          `json:"context"`
+	Description string          `json:"description"`
+	TargetURL   string          `json:"target_url"`
+	Repository  *api.Repository `json:"repository"`
+	Sender      *api.User       `json:"sender"`
+}
+
+// SetSecret modifies the secret of the StatusPayload.
+func (p *StatusPayload) SetSecret(secret string) {
+	p.Secret = secret
+}
+
+// JSONPayload implements Payload
+func (p *StatusPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
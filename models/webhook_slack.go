@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/git"
 	api "code.gitea.io/sdk/gitea"
 
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/setting"
 )
 
@@ -141,7 +142,7 @@ func getSlackPushPayload(p *api.PushPayload, slack *SlackMeta) (*SlackPayload, e
 	}, nil
 }
 
-func getSlackPullRequestPayload(p *api.PullRequestPayload, slack *SlackMeta) (*SlackPayload, error) {
+func getSlackPullRequestPayload(p *PullRequestPayload, slack *SlackMeta) (*SlackPayload, error) {
 	senderLink := SlackLinkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName)
 	titleLink := SlackLinkFormatter(fmt.Sprintf("%s/pulls/%d", p.Repository.HTMLURL, p.Index),
 		fmt.Sprintf("#%d %s", p.Index, p.PullRequest.Title))
@@ -189,6 +190,101 @@ func getSlackPullRequestPayload(p *api.PullRequestPayload, slack *SlackMeta) (*S
 	}, nil
 }
 
+func getSlackIssuesPayload(p *IssuePayload, slack *SlackMeta) (*SlackPayload, error) {
+	senderLink := SlackLinkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName)
+	titleLink := SlackLinkFormatter(fmt.Sprintf("%s/issues/%d", p.Repository.HTMLURL, p.Index),
+		fmt.Sprintf("#%d %s", p.Index, p.Issue.Title))
+	var text, title, attachmentText string
+	switch p.Action {
+	case api.HookIssueOpened:
+		text = fmt.Sprintf("[%s] Issue submitted by %s", p.Repository.FullName, senderLink)
+		title = titleLink
+		attachmentText = SlackTextFormatter(p.Issue.Body)
+	case api.HookIssueClosed:
+		text = fmt.Sprintf("[%s] Issue closed: %s by %s", p.Repository.FullName, titleLink, senderLink)
+	case api.HookIssueReOpened:
+		text = fmt.Sprintf("[%s] Issue re-opened: %s by %s", p.Repository.FullName, titleLink, senderLink)
+	case api.HookIssueEdited:
+		text = fmt.Sprintf("[%s] Issue edited: %s by %s", p.Repository.FullName, titleLink, senderLink)
+		attachmentText = SlackTextFormatter(p.Issue.Body)
+	case api.HookIssueAssigned:
+		text = fmt.Sprintf("[%s] Issue assigned to %s: %s by %s", p.Repository.FullName,
+			SlackLinkFormatter(setting.AppURL+p.Issue.Assignee.UserName, p.Issue.Assignee.UserName),
+			titleLink, senderLink)
+	case api.HookIssueUnassigned:
+		text = fmt.Sprintf("[%s] Issue unassigned: %s by %s", p.Repository.FullName, titleLink, senderLink)
+	case api.HookIssueLabelUpdated:
+		text = fmt.Sprintf("[%s] Issue labels updated: %s by %s", p.Repository.FullName, titleLink, senderLink)
+	case api.HookIssueLabelCleared:
+		text = fmt.Sprintf("[%s] Issue labels cleared: %s by %s", p.Repository.FullName, titleLink, senderLink)
+	case HookIssuePinned:
+		text = fmt.Sprintf("[%s] Issue pinned: %s by %s", p.Repository.FullName, titleLink, senderLink)
+	case HookIssueUnpinned:
+		text = fmt.Sprintf("[%s] Issue unpinned: %s by %s", p.Repository.FullName, titleLink, senderLink)
+	}
+
+	return &SlackPayload{
+		Channel:  slack.Channel,
+		Text:     text,
+		Username: slack.Username,
+		IconURL:  slack.IconURL,
+		Attachments: []SlackAttachment{{
+			Color: slack.Color,
+			Title: title,
+			Text:  attachmentText,
+		}},
+	}, nil
+}
+
+func getSlackIssueCommentPayload(p *IssueCommentPayload, slack *SlackMeta) (*SlackPayload, error) {
+	senderLink := SlackLinkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName)
+	titleLink := SlackLinkFormatter(p.Comment.HTMLURL, fmt.Sprintf("#%d %s", p.Issue.Index, p.Issue.Title))
+	var text, title, attachmentText string
+	switch p.Action {
+	case HookIssueCommentCreated:
+		text = fmt.Sprintf("[%s] New comment on %s by %s", p.Repository.FullName, titleLink, senderLink)
+		title = titleLink
+		attachmentText = SlackTextFormatter(p.Comment.Body)
+	case HookIssueCommentEdited:
+		text = fmt.Sprintf("[%s] Comment edited on %s by %s", p.Repository.FullName, titleLink, senderLink)
+		attachmentText = SlackTextFormatter(p.Comment.Body)
+	case HookIssueCommentDeleted:
+		text = fmt.Sprintf("[%s] Comment deleted on %s by %s", p.Repository.FullName, titleLink, senderLink)
+	}
+
+	return &SlackPayload{
+		Channel:  slack.Channel,
+		Text:     text,
+		Username: slack.Username,
+		IconURL:  slack.IconURL,
+		Attachments: []SlackAttachment{{
+			Color: slack.Color,
+			Title: title,
+			Text:  attachmentText,
+		}},
+	}, nil
+}
+
+func getSlackStatusPayload(p *StatusPayload, slack *SlackMeta) (*SlackPayload, error) {
+	commitLink := SlackLinkFormatter(fmt.Sprintf("%s/commit/%s", p.Repository.HTMLURL, p.SHA), base.ShortSha(p.SHA))
+	context := p.Context
+	if len(p.TargetURL) > 0 {
+		context = SlackLinkFormatter(p.TargetURL, p.Context)
+	}
+	text := fmt.Sprintf("[%s] %s: %s for commit %s", p.Repository.FullName, context, p.State, commitLink)
+
+	return &SlackPayload{
+		Channel:  slack.Channel,
+		Text:     text,
+		Username: slack.Username,
+		IconURL:  slack.IconURL,
+		Attachments: []SlackAttachment{{
+			Color: slack.Color,
+			Text:  SlackTextFormatter(p.Description),
+		}},
+	}, nil
+}
+
 // GetSlackPayload converts a slack webhook into a SlackPayload
 func GetSlackPayload(p api.Payloader, event HookEventType, meta string) (*SlackPayload, error) {
 	s := new(SlackPayload)
@@ -204,7 +300,13 @@ func GetSlackPayload(p api.Payloader, event HookEventType, meta string) (*SlackP
 	case HookEventPush:
 		return getSlackPushPayload(p.(*api.PushPayload), slack)
 	case HookEventPullRequest:
-		return getSlackPullRequestPayload(p.(*api.PullRequestPayload), slack)
+		return getSlackPullRequestPayload(p.(*PullRequestPayload), slack)
+	case HookEventIssues:
+		return getSlackIssuesPayload(p.(*IssuePayload), slack)
+	case HookEventIssueComment:
+		return getSlackIssueCommentPayload(p.(*IssueCommentPayload), slack)
+	case HookEventStatus:
+		return getSlackStatusPayload(p.(*StatusPayload), slack)
 	}
 
 	return s, nil
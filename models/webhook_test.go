@@ -7,6 +7,7 @@ package models
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	api "code.gitea.io/sdk/gitea"
 
@@ -50,6 +51,66 @@ func TestWebhook_History(t *testing.T) {
 	assert.Len(t, tasks, 0)
 }
 
+func TestWebhook_RecentFailures(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	webhook := AssertExistsAndLoadBean(t, &Webhook{ID: 1}).(*Webhook)
+	summary, err := webhook.RecentFailures(10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.Total)
+	assert.Equal(t, 1, summary.Failed)
+	if assert.NotNil(t, summary.LastFailure) {
+		assert.EqualValues(t, 1, summary.LastFailure.ID)
+	}
+
+	webhook = AssertExistsAndLoadBean(t, &Webhook{ID: 2}).(*Webhook)
+	summary, err = webhook.RecentFailures(10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, summary.Total)
+	assert.Nil(t, summary.LastFailure)
+}
+
+func TestWebhook_SignatureHeaders(t *testing.T) {
+	webhook := &Webhook{Secret: "secret", HookTaskType: GITEA}
+	headers := webhook.SignatureHeaders([]byte(`{"ref":"refs/heads/master"}`))
+	assert.Len(t, headers, 2)
+	assert.Equal(t, "18bd702ca7dab5713101db346ec6cd6768820c090515db9744deff53bc95ff52", headers["X-Gitea-Signature"])
+	assert.Equal(t, headers["X-Gitea-Signature"], headers["X-Gogs-Signature"])
+
+	webhook.Secret = ""
+	assert.Empty(t, webhook.SignatureHeaders([]byte("{}")))
+
+	webhook = &Webhook{Secret: "secret", HookTaskType: SLACK}
+	assert.Empty(t, webhook.SignatureHeaders([]byte("{}")))
+}
+
+func TestWebhook_ChangeSecret(t *testing.T) {
+	webhook := &Webhook{HookTaskType: GITEA}
+	webhook.ChangeSecret("first")
+	assert.Equal(t, "first", webhook.Secret)
+	assert.False(t, webhook.IsRotatingSecret())
+
+	webhook.ChangeSecret("first")
+	assert.False(t, webhook.IsRotatingSecret())
+
+	webhook.ChangeSecret("second")
+	assert.Equal(t, "second", webhook.Secret)
+	assert.Equal(t, "first", webhook.PreviousSecret)
+	assert.True(t, webhook.IsRotatingSecret())
+	assert.True(t, webhook.PreviousSecretExpires().After(time.Now()))
+
+	payload := []byte(`{"ref":"refs/heads/master"}`)
+	headers := webhook.SignatureHeaders(payload)
+	assert.Len(t, headers, 3)
+	assert.Equal(t, "18bd702ca7dab5713101db346ec6cd6768820c090515db9744deff53bc95ff52",
+		(&Webhook{Secret: "secret"}).SignatureHeaders(payload)["X-Gitea-Signature"])
+	assert.Equal(t, signPayload("first", payload), headers["X-Gitea-Signature-Previous"])
+	assert.Equal(t, signPayload("second", payload), headers["X-Gitea-Signature"])
+
+	webhook.PreviousSecretExpiresUnix = time.Now().Add(-time.Minute).Unix()
+	assert.False(t, webhook.IsRotatingSecret())
+	assert.Len(t, webhook.SignatureHeaders(payload), 2)
+}
+
 func TestWebhook_UpdateEvent(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	webhook := AssertExistsAndLoadBean(t, &Webhook{ID: 1}).(*Webhook)
@@ -72,7 +133,7 @@ func TestWebhook_UpdateEvent(t *testing.T) {
 }
 
 func TestWebhook_EventsArray(t *testing.T) {
-	assert.Equal(t, []string{"create", "push", "pull_request"},
+	assert.Equal(t, []string{"create", "push", "pull_request", "issues", "issue_comment", "status"},
 		(&Webhook{
 			HookEvent: &HookEvent{SendEverything: true},
 		}).EventsArray(),
@@ -261,6 +322,41 @@ func TestPrepareWebhooks(t *testing.T) {
 	}
 }
 
+func TestPrepareWebhooks_Issues(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	hook := &Webhook{
+		RepoID:   1,
+		URL:      "www.example.com/issues",
+		IsActive: true,
+		HookEvent: &HookEvent{
+			ChooseEvents: true,
+			HookEvents: HookEvents{
+				Issues:       true,
+				IssueComment: true,
+			},
+		},
+	}
+	assert.NoError(t, hook.UpdateEvent())
+	assert.NoError(t, CreateWebhook(hook))
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue, err := GetIssueByID(1)
+	assert.NoError(t, err)
+	assert.False(t, issue.IsPull)
+
+	assert.NoError(t, issue.ChangeTitle(doer, "new title"))
+	AssertExistsAndLoadBean(t, &HookTask{HookID: hook.ID, EventType: HookEventIssues})
+
+	comment, err := CreateIssueComment(doer, issue.Repo, issue, "hello", nil)
+	assert.NoError(t, err)
+	AssertExistsAndLoadBean(t, &HookTask{HookID: hook.ID, EventType: HookEventIssueComment})
+	assert.NoError(t, DeleteComment(doer, comment))
+
+	// Push-only hook of the same repository must not receive issue events.
+	AssertNotExistsBean(t, &HookTask{HookID: 1, EventType: HookEventIssues})
+}
+
 // TODO TestHookTask_deliver
 
 // TODO TestDeliverHooks
@@ -5,24 +5,28 @@
 package models
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/Unknwon/com"
 
 	"code.gitea.io/git"
 
+	"code.gitea.io/gitea/modules/markdown"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/sync"
 )
 
 var (
-	reservedWikiPaths = []string{"_pages", "_new", "_edit"}
+	reservedWikiPaths = []string{"_pages", "_new", "_edit", "_search"}
 	wikiWorkingPool   = sync.NewExclusivePool()
 )
 
@@ -221,3 +225,219 @@ func (repo *Repository) DeleteWikiPage(doer *User, wikiPath string) (err error)
 
 	return nil
 }
+
+// WikiPage represents a page of the repository wiki.
+type WikiPage struct {
+	Name       string
+	URL        string
+	Content    string
+	LastCommit *git.Commit
+}
+
+// wikiEntries returns the git repository of the wiki and the entries of its
+// master branch, entries are nil if the wiki has no commits yet.
+func (repo *Repository) wikiEntries() (*git.Repository, git.Entries, error) {
+	if !repo.HasWiki() {
+		return nil, nil, nil
+	}
+
+	wikiRepo, err := git.OpenRepository(repo.WikiPath())
+	if err != nil {
+		return nil, nil, fmt.Errorf("OpenRepository: %v", err)
+	} else if !wikiRepo.IsBranchExist("master") {
+		return wikiRepo, nil, nil
+	}
+
+	commit, err := wikiRepo.GetBranchCommit("master")
+	if err != nil {
+		return nil, nil, fmt.Errorf("GetBranchCommit: %v", err)
+	}
+	entries, err := commit.ListEntries()
+	if err != nil {
+		return nil, nil, fmt.Errorf("ListEntries: %v", err)
+	}
+	return wikiRepo, entries, nil
+}
+
+// GetWikiPages returns all pages of the repository wiki, without content.
+func (repo *Repository) GetWikiPages() ([]*WikiPage, error) {
+	wikiRepo, entries, err := repo.wikiEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]*WikiPage, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type != git.ObjectBlob {
+			continue
+		}
+		pageURL := wikiPageURL(entry.Name())
+		if len(pageURL) == 0 {
+			continue
+		}
+
+		commit, err := wikiRepo.GetCommitByPath(entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("GetCommitByPath [%s]: %v", entry.Name(), err)
+		}
+		pages = append(pages, &WikiPage{
+			Name:       ToWikiPageName(pageURL),
+			URL:        pageURL,
+			LastCommit: commit,
+		})
+	}
+	return pages, nil
+}
+
+// GetWikiPage returns the page of the repository wiki with given URL name.
+func (repo *Repository) GetWikiPage(wikiPath string) (*WikiPage, error) {
+	wikiRepo, entries, err := repo.wikiEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.Type != git.ObjectBlob || wikiPageURL(entry.Name()) != wikiPath {
+			continue
+		}
+
+		r, err := entry.Blob().Data()
+		if err != nil {
+			return nil, fmt.Errorf("Data: %v", err)
+		}
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("ReadAll: %v", err)
+		}
+		commit, err := wikiRepo.GetCommitByPath(entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("GetCommitByPath [%s]: %v", entry.Name(), err)
+		}
+		return &WikiPage{
+			Name:       ToWikiPageName(wikiPath),
+			URL:        wikiPath,
+			Content:    string(data),
+			LastCommit: commit,
+		}, nil
+	}
+	return nil, ErrWikiPageNotExist{wikiPath}
+}
+
+// WikiSearchMatch represents a line of a wiki page matching a search keyword.
+type WikiSearchMatch struct {
+	Line    int
+	Content string
+}
+
+// WikiSearchResult represents a wiki page matching a search keyword.
+type WikiSearchResult struct {
+	Name        string
+	URL         string
+	NameMatched bool
+	Matches     []*WikiSearchMatch
+}
+
+// wikiSearchMaxMatches is the maximum number of matching lines kept per page.
+const wikiSearchMaxMatches = 5
+
+// wikiPageURL returns the URL of the wiki page stored in given file,
+// or an empty string if the file is not a wiki page.
+func wikiPageURL(filename string) string {
+	ext := filepath.Ext(filename)
+	if !markdown.IsMarkdownFile(filename) && ext != ".textile" {
+		return ""
+	}
+	return strings.TrimSuffix(filename, ext)
+}
+
+// parseWikiGrep parses output of "git grep -n -z" run against given
+// revision and adds the matching lines to the results of the pages.
+func parseWikiGrep(revision, stdout string, results map[string]*WikiSearchResult) {
+	for _, line := range strings.Split(stdout, "\n") {
+		fields := strings.SplitN(line, "\x00", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		filename := strings.TrimPrefix(fields[0], revision+":")
+		result := results[filename]
+		if result == nil || len(result.Matches) >= wikiSearchMaxMatches {
+			continue
+		}
+		lineNum, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		result.Matches = append(result.Matches, &WikiSearchMatch{
+			Line:    lineNum,
+			Content: strings.TrimSpace(fields[2]),
+		})
+	}
+}
+
+// SearchWiki returns the wiki pages whose name or content contains given
+// keyword, pages with matching names first.
+func (repo *Repository) SearchWiki(keyword string) ([]*WikiSearchResult, error) {
+	keyword = strings.TrimSpace(keyword)
+	if len(keyword) == 0 || !repo.HasWiki() {
+		return []*WikiSearchResult{}, nil
+	}
+
+	wikiRepo, err := git.OpenRepository(repo.WikiPath())
+	if err != nil {
+		return nil, fmt.Errorf("OpenRepository: %v", err)
+	} else if !wikiRepo.IsBranchExist("master") {
+		return []*WikiSearchResult{}, nil
+	}
+	commit, err := wikiRepo.GetBranchCommit("master")
+	if err != nil {
+		return nil, fmt.Errorf("GetBranchCommit: %v", err)
+	}
+	entries, err := commit.ListEntries()
+	if err != nil {
+		return nil, fmt.Errorf("ListEntries: %v", err)
+	}
+
+	lowerKeyword := strings.ToLower(keyword)
+	pages := make(map[string]*WikiSearchResult, len(entries))
+	for _, entry := range entries {
+		if entry.Type != git.ObjectBlob {
+			continue
+		}
+		pageURL := wikiPageURL(entry.Name())
+		if len(pageURL) == 0 {
+			continue
+		}
+		name := ToWikiPageName(pageURL)
+		pages[entry.Name()] = &WikiSearchResult{
+			Name:        name,
+			URL:         pageURL,
+			NameMatched: strings.Contains(strings.ToLower(name), lowerKeyword),
+		}
+	}
+
+	// git grep exits with status 1 and nothing on stderr if no line matches.
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	if err = git.NewCommand("grep", "-n", "-z", "-i", "-I", "-F", "-e", keyword, "master").
+		RunInDirPipeline(repo.WikiPath(), stdout, stderr); err != nil && stderr.Len() > 0 {
+		return nil, fmt.Errorf("git grep: %v - %s", err, stderr)
+	}
+	parseWikiGrep("master", stdout.String(), pages)
+
+	results := make([]*WikiSearchResult, 0, len(pages))
+	for _, page := range pages {
+		if page.NameMatched || len(page.Matches) > 0 {
+			results = append(results, page)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].NameMatched != results[j].NameMatched {
+			return results[i].NameMatched
+		}
+		if len(results[i].Matches) != len(results[j].Matches) {
+			return len(results[i].Matches) > len(results[j].Matches)
+		}
+		return results[i].Name < results[j].Name
+	})
+	return results, nil
+}
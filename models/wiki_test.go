@@ -60,4 +60,33 @@ func TestRepository_LocalWikiPath(t *testing.T) {
 
 // TODO TestRepository_UpdateLocalWiki
 
+func TestParseWikiGrep(t *testing.T) {
+	pages := map[string]*WikiSearchResult{
+		"Home.md":          {Name: "Home", URL: "Home"},
+		"Install-Guide.md": {Name: "Install Guide", URL: "Install-Guide"},
+	}
+	parseWikiGrep("master", "master:Home.md\x003\x00  Read the install guide\n"+
+		"master:Install-Guide.md\x001\x00# Install\n"+
+		"master:Install-Guide.md\x0010\x00install: with:colons\n"+
+		"master:logo.png\x001\x00install\n", pages)
+
+	assert.Equal(t, []*WikiSearchMatch{{Line: 3, Content: "Read the install guide"}}, pages["Home.md"].Matches)
+	assert.Equal(t, []*WikiSearchMatch{
+		{Line: 1, Content: "# Install"},
+		{Line: 10, Content: "install: with:colons"},
+	}, pages["Install-Guide.md"].Matches)
+}
+
+func TestRepository_GetWikiPage(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	pages, err := repo.GetWikiPages()
+	assert.NoError(t, err)
+	assert.Len(t, pages, 0)
+
+	_, err = repo.GetWikiPage("Home")
+	assert.True(t, IsErrWikiPageNotExist(err))
+}
+
 // TODO ... (all remaining untested functions)
@@ -47,3 +47,64 @@ type AdminEditUserForm struct {
 func (f *AdminEditUserForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// AdminSuspendUserForm form for suspending a user
+type AdminSuspendUserForm struct {
+	Reason string `binding:"Required;MaxSize(255)"`
+}
+
+// Validate validates form fields
+func (f *AdminSuspendUserForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// BlockedWordForm form for adding a blocked word to the instance or an organization
+type BlockedWordForm struct {
+	Word     string `binding:"Required;MaxSize(100)"`
+	Moderate bool
+}
+
+// Validate validates form fields
+func (f *BlockedWordForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// BannerForm form for updating the banner of the instance or an organization
+type BannerForm struct {
+	Message string `binding:"Required;MaxSize(2048)"`
+	Level   int    `binding:"Range(1,3)"`
+	Expires string
+}
+
+// Validate validates form fields
+func (f *BannerForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// ProfileFieldForm form for defining a custom profile field
+type ProfileFieldForm struct {
+	Name        string `binding:"Required;MaxSize(50)"`
+	Description string `binding:"MaxSize(255)"`
+	Type        int    `binding:"Range(1,4)"`
+	Visibility  int    `binding:"Range(1,3)"`
+	Sorting     int
+}
+
+// Validate validates form fields
+func (f *ProfileFieldForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// GitHookTemplateForm form for adding or updating a Git hook template
+type GitHookTemplateForm struct {
+	Name        string `binding:"Required;MaxSize(50)"`
+	Description string `binding:"MaxSize(255)"`
+	HookName    string `binding:"Required;In(pre-receive,update,post-receive)"`
+	Content     string `binding:"Required"`
+	Parameters  string `binding:"MaxSize(255)"`
+}
+
+// Validate validates form fields
+func (f *GitHookTemplateForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
@@ -5,6 +5,9 @@
 package auth
 
 import (
+	"net"
+	"net/http"
+	"net/mail"
 	"reflect"
 	"strings"
 	"time"
@@ -27,6 +30,33 @@ func IsAPIPath(url string) bool {
 	return strings.HasPrefix(url, "/api/")
 }
 
+// accessTokenSHA returns the access token given in the query or in the
+// Authorization header of the request, if any.
+func accessTokenSHA(ctx *macaron.Context) string {
+	tokenSHA := ctx.Query("token")
+	if len(tokenSHA) <= 0 {
+		tokenSHA = ctx.Query("access_token")
+	}
+	if len(tokenSHA) == 0 {
+		// Well, check with header again.
+		auHead := ctx.Req.Header.Get("Authorization")
+		if len(auHead) > 0 {
+			auths := strings.Fields(auHead)
+			if len(auths) == 2 && auths[0] == "token" {
+				tokenSHA = auths[1]
+			}
+		}
+	}
+	return tokenSHA
+}
+
+// HasAPICredentials returns true if the request carries an access token,
+// which browsers do not send on their own. The session of the user is then
+// never used to authenticate the request, even if the token is invalid.
+func HasAPICredentials(ctx *macaron.Context) bool {
+	return len(accessTokenSHA(ctx)) > 0
+}
+
 // SignedInID returns the id of signed in user.
 func SignedInID(ctx *macaron.Context, sess session.Store) int64 {
 	if !models.HasEngine {
@@ -35,23 +65,8 @@ func SignedInID(ctx *macaron.Context, sess session.Store) int64 {
 
 	// Check access token.
 	if IsAPIPath(ctx.Req.URL.Path) {
-		tokenSHA := ctx.Query("token")
-		if len(tokenSHA) <= 0 {
-			tokenSHA = ctx.Query("access_token")
-		}
-		if len(tokenSHA) == 0 {
-			// Well, check with header again.
-			auHead := ctx.Req.Header.Get("Authorization")
-			if len(auHead) > 0 {
-				auths := strings.Fields(auHead)
-				if len(auths) == 2 && auths[0] == "token" {
-					tokenSHA = auths[1]
-				}
-			}
-		}
-
 		// Let's see if token is valid.
-		if len(tokenSHA) > 0 {
+		if tokenSHA := accessTokenSHA(ctx); len(tokenSHA) > 0 {
 			t, err := models.GetAccessTokenBySHA(tokenSHA)
 			if err != nil {
 				if models.IsErrAccessTokenNotExist(err) || models.IsErrAccessTokenEmpty(err) {
@@ -76,6 +91,93 @@ func SignedInID(ctx *macaron.Context, sess session.Store) int64 {
 	return 0
 }
 
+// ReverseProxyAuthUser returns the name of the user authenticated by the
+// reverse proxy, the header is ignored if the request does not come from a
+// trusted proxy.
+func ReverseProxyAuthUser(ctx *macaron.Context) string {
+	if !setting.Service.EnableReverseProxyAuth {
+		return ""
+	}
+
+	webAuthUser := ctx.Req.Header.Get(setting.ReverseProxyAuthUser)
+	if len(webAuthUser) == 0 {
+		return ""
+	} else if !setting.IsTrustedProxy(ctx.Req.RemoteAddr) {
+		log.Trace("Reverse proxy authentication header from untrusted address %s is ignored", ctx.Req.RemoteAddr)
+		return ""
+	}
+	return webAuthUser
+}
+
+// RemoteAddr returns the IP address of the client of the request. Clients can
+// set the X-Real-IP and X-Forwarded-For headers as they like, so these are
+// only honoured when the request comes from a trusted proxy.
+func RemoteAddr(req *http.Request) string {
+	addr := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	if !setting.IsTrustedProxy(req.RemoteAddr) {
+		return addr
+	}
+
+	if realIP := strings.TrimSpace(req.Header.Get("X-Real-IP")); len(realIP) > 0 {
+		return realIP
+	}
+	// Every proxy appends the address it got the request from, the first
+	// address from the right which is not a trusted proxy is the client.
+	forwarded := strings.Split(req.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(forwarded[i])
+		if len(ip) == 0 {
+			continue
+		}
+		addr = ip
+		if !setting.IsTrustedProxy(ip) {
+			break
+		}
+	}
+	return addr
+}
+
+// reverseProxySignIn returns the user authenticated by the reverse proxy,
+// and registers the user if auto-registration is enabled.
+func reverseProxySignIn(ctx *macaron.Context, webAuthUser string) *models.User {
+	u, err := models.GetUserByName(webAuthUser)
+	if err == nil {
+		if u.IsSuspended {
+			return nil
+		}
+		return u
+	} else if !models.IsErrUserNotExist(err) {
+		log.Error(4, "GetUserByName: %v", err)
+		return nil
+	}
+
+	// Check if enabled auto-registration.
+	if !setting.Service.EnableReverseProxyAutoRegister {
+		return nil
+	}
+
+	email := gouuid.NewV4().String() + "@localhost"
+	if addr, err := mail.ParseAddress(ctx.Req.Header.Get(setting.ReverseProxyAuthEmail)); err == nil {
+		email = addr.Address
+	}
+	u = &models.User{
+		Name:     webAuthUser,
+		FullName: ctx.Req.Header.Get(setting.ReverseProxyAuthFullName),
+		Email:    email,
+		Passwd:   webAuthUser,
+		IsActive: true,
+	}
+	if err = models.CreateUser(u); err != nil {
+		// FIXME: should I create a system notice?
+		log.Error(4, "CreateUser: %v", err)
+		return nil
+	}
+	return u
+}
+
 // SignedInUser returns the user object of signed user.
 // It returns a bool value to indicate whether user uses basic auth or not.
 func SignedInUser(ctx *macaron.Context, sess session.Store) (*models.User, bool) {
@@ -83,43 +185,24 @@ func SignedInUser(ctx *macaron.Context, sess session.Store) (*models.User, bool)
 		return nil, false
 	}
 
+	webAuthUser := ReverseProxyAuthUser(ctx)
+	if len(webAuthUser) > 0 && setting.ReverseProxyDisableFallback {
+		return reverseProxySignIn(ctx, webAuthUser), false
+	}
+
 	if uid := SignedInID(ctx, sess); uid > 0 {
 		user, err := models.GetUserByID(uid)
 		if err == nil {
-			return user, false
+			if !user.IsDeleted() && !user.IsSuspended {
+				return user, false
+			}
 		} else if !models.IsErrUserNotExist(err) {
 			log.Error(4, "GetUserById: %v", err)
 		}
 	}
 
-	if setting.Service.EnableReverseProxyAuth {
-		webAuthUser := ctx.Req.Header.Get(setting.ReverseProxyAuthUser)
-		if len(webAuthUser) > 0 {
-			u, err := models.GetUserByName(webAuthUser)
-			if err != nil {
-				if !models.IsErrUserNotExist(err) {
-					log.Error(4, "GetUserByName: %v", err)
-					return nil, false
-				}
-
-				// Check if enabled auto-registration.
-				if setting.Service.EnableReverseProxyAutoRegister {
-					u := &models.User{
-						Name:     webAuthUser,
-						Email:    gouuid.NewV4().String() + "@localhost",
-						Passwd:   webAuthUser,
-						IsActive: true,
-					}
-					if err = models.CreateUser(u); err != nil {
-						// FIXME: should I create a system notice?
-						log.Error(4, "CreateUser: %v", err)
-						return nil, false
-					}
-					return u, false
-				}
-			}
-			return u, false
-		}
+	if len(webAuthUser) > 0 {
+		return reverseProxySignIn(ctx, webAuthUser), false
 	}
 
 	// Check with basic auth.
@@ -129,10 +212,10 @@ func SignedInUser(ctx *macaron.Context, sess session.Store) (*models.User, bool)
 		if len(auths) == 2 && auths[0] == "Basic" {
 			uname, passwd, _ := base.BasicAuthDecode(auths[1])
 
-			u, err := models.UserSignIn(uname, passwd)
+			u, err := models.UserSignInFrom(uname, passwd, RemoteAddr(ctx.Req.Request), ctx.Req.UserAgent())
 			if err != nil {
-				if !models.IsErrUserNotExist(err) {
-					log.Error(4, "UserSignIn: %v", err)
+				if !models.IsErrUserNotExist(err) && !models.IsErrUserSuspended(err) && !models.IsErrUserLocked(err) {
+					log.Error(4, "UserSignInFrom: %v", err)
 				}
 				return nil, false
 			}
@@ -29,6 +29,11 @@ type AuthenticationForm struct {
 	AdminFilter                   string
 	IsActive                      bool
 	IsSyncEnabled                 bool
+	SyncCreateUsers               bool
+	SyncUpdateUsers               bool
+	SyncDeactivateUsers           bool
+	SyncSuspendUsers              bool
+	SyncDryRun                    bool
 	SMTPAuth                      string
 	SMTPHost                      string
 	SMTPPort                      int
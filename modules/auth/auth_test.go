@@ -0,0 +1,47 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"net"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteAddr(t *testing.T) {
+	oldProxies := setting.ReverseProxyTrustedProxies
+	defer func() { setting.ReverseProxyTrustedProxies = oldProxies }()
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	setting.ReverseProxyTrustedProxies = []*net.IPNet{proxies}
+
+	for _, c := range []struct {
+		remoteAddr, realIP, forwardedFor string
+		expected                         string
+	}{
+		{"192.0.2.1:1234", "", "", "192.0.2.1"},
+		{"[2001:db8::1]:1234", "", "", "2001:db8::1"},
+		// Untrusted clients can't choose their address.
+		{"192.0.2.1:1234", "198.51.100.7", "", "192.0.2.1"},
+		{"192.0.2.1:1234", "", "198.51.100.7", "192.0.2.1"},
+		// Trusted proxies can.
+		{"10.0.0.1:1234", "198.51.100.7", "", "198.51.100.7"},
+		{"10.0.0.1:1234", "", "198.51.100.7", "198.51.100.7"},
+		{"10.0.0.1:1234", "", "203.0.113.9, 198.51.100.7, 10.0.0.2", "198.51.100.7"},
+		{"10.0.0.1:1234", "", "10.0.0.3, 10.0.0.2", "10.0.0.3"},
+	} {
+		req := &http.Request{RemoteAddr: c.remoteAddr, Header: http.Header{}}
+		if len(c.realIP) > 0 {
+			req.Header.Set("X-Real-IP", c.realIP)
+		}
+		if len(c.forwardedFor) > 0 {
+			req.Header.Set("X-Forwarded-For", c.forwardedFor)
+		}
+		assert.Equal(t, c.expected, RemoteAddr(req), "%+v", c)
+	}
+}
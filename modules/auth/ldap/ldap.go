@@ -270,20 +270,18 @@ func (ls *Source) SearchEntry(name, passwd string, directBind bool) *SearchResul
 }
 
 // SearchEntries : search an LDAP source for all users matching userFilter
-func (ls *Source) SearchEntries() []*SearchResult {
+func (ls *Source) SearchEntries() ([]*SearchResult, error) {
 	l, err := dial(ls)
 	if err != nil {
-		log.Error(4, "LDAP Connect error, %s:%v", ls.Host, err)
 		ls.Enabled = false
-		return nil
+		return nil, fmt.Errorf("connect to %s: %v", ls.Host, err)
 	}
 	defer l.Close()
 
 	if ls.BindDN != "" && ls.BindPassword != "" {
 		err := l.Bind(ls.BindDN, ls.BindPassword)
 		if err != nil {
-			log.Debug("Failed to bind as BindDN[%s]: %v", ls.BindDN, err)
-			return nil
+			return nil, fmt.Errorf("bind as BindDN[%s]: %v", ls.BindDN, err)
 		}
 		log.Trace("Bound as BindDN %s", ls.BindDN)
 	} else {
@@ -300,8 +298,7 @@ func (ls *Source) SearchEntries() []*SearchResult {
 
 	sr, err := l.Search(search)
 	if err != nil {
-		log.Error(4, "LDAP Search failed unexpectedly! (%v)", err)
-		return nil
+		return nil, fmt.Errorf("search: %v", err)
 	}
 
 	result := make([]*SearchResult, len(sr.Entries))
@@ -316,5 +313,5 @@ func (ls *Source) SearchEntries() []*SearchResult {
 		}
 	}
 
-	return result
+	return result, nil
 }
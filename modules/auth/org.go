@@ -62,3 +62,29 @@ type CreateTeamForm struct {
 func (f *CreateTeamForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// OrgLabelTemplateForm form for adding a default label to an organization
+type OrgLabelTemplateForm struct {
+	Name        string `json:"name" binding:"Required;MaxSize(50)" locale:"repo.issues.label_title"`
+	Description string `json:"description" binding:"MaxSize(200)" locale:"repo.issues.label_description"`
+	Color       string `json:"color" binding:"Required;Size(7)" locale:"repo.issues.label_color"`
+}
+
+// Validate validates the fields
+func (f *OrgLabelTemplateForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// OrgMilestoneTemplateForm form for adding a default milestone to an organization
+type OrgMilestoneTemplateForm struct {
+	Name         string `json:"name" binding:"Required;MaxSize(50)" locale:"repo.milestones.title"`
+	Content      string `json:"content"`
+	DueDays      int    `json:"due_days" binding:"Range(0,3650)" locale:"org.settings.defaults.due_days"`
+	Count        int    `json:"count" binding:"Range(0,100)" locale:"org.settings.defaults.count"`
+	IntervalDays int    `json:"interval_days" binding:"Range(0,3650)" locale:"org.settings.defaults.interval_days"`
+}
+
+// Validate validates the fields
+func (f *OrgMilestoneTemplateForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
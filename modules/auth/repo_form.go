@@ -86,13 +86,15 @@ func (f MigrateRepoForm) ParseRemoteAddr(user *models.User) (string, error) {
 
 // RepoSettingForm form for changing repository settings
 type RepoSettingForm struct {
-	RepoName      string `binding:"Required;AlphaDashDot;MaxSize(100)"`
-	Description   string `binding:"MaxSize(255)"`
-	Website       string `binding:"ValidUrl;MaxSize(255)"`
-	Interval      string
-	MirrorAddress string
-	Private       bool
-	EnablePrune   bool
+	RepoName       string `binding:"Required;AlphaDashDot;MaxSize(100)"`
+	Description    string `binding:"MaxSize(255)"`
+	Website        string `binding:"ValidUrl;MaxSize(255)"`
+	Interval       string
+	MirrorAddress  string
+	MirrorUsername string
+	MirrorPassword string
+	Private        bool
+	EnablePrune    bool
 
 	// Advanced settings
 	EnableWiki            bool
@@ -100,6 +102,7 @@ type RepoSettingForm struct {
 	ExternalWikiURL       string
 	EnableIssues          bool
 	EnableExternalTracker bool
+	CloseIssuesViaCommit  bool
 	ExternalTrackerURL    string
 	TrackerURLFormat      string
 	TrackerIssueStyle     string
@@ -111,6 +114,18 @@ func (f *RepoSettingForm) Validate(ctx *macaron.Context, errs binding.Errors) bi
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// NewDeployTokenForm form for creating deploy token of a repository
+type NewDeployTokenForm struct {
+	Name        string `binding:"Required;MaxSize(255)"`
+	Writable    bool
+	ExpiresDays int `binding:"Range(0,3650)"`
+}
+
+// Validate validates the fields
+func (f *NewDeployTokenForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 //  __      __      ___.   .__    .__            __
 // /  \    /  \ ____\_ |__ |  |__ |  |__   ____ |  | __
 // \   \/\/   // __ \| __ \|  |  \|  |  \ /  _ \|  |/ /
@@ -120,11 +135,14 @@ func (f *RepoSettingForm) Validate(ctx *macaron.Context, errs binding.Errors) bi
 
 // WebhookForm form for changing web hook
 type WebhookForm struct {
-	Events      string
-	Create      bool
-	Push        bool
-	PullRequest bool
-	Active      bool
+	Events       string
+	Create       bool
+	Push         bool
+	PullRequest  bool
+	Issues       bool
+	IssueComment bool
+	Status       bool
+	Active       bool
 }
 
 // PushOnly if the hook will be triggered when push
@@ -183,6 +201,43 @@ func (f *NewSlackHookForm) Validate(ctx *macaron.Context, errs binding.Errors) b
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// NotificationChannelForm form for adding a chat notification channel of a user or a repository
+type NotificationChannelForm struct {
+	Type              string `binding:"Required;In(matrix,rocketchat)"`
+	URL               string `binding:"Required;ValidUrl;MaxSize(255)"`
+	RoomID            string `binding:"MaxSize(255)"`
+	Token             string
+	EventIssues       bool
+	EventPullRequests bool
+	EventComments     bool
+	EventStatus       bool
+}
+
+// Validate validates the fields
+func (f *NotificationChannelForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// IsMatrixIncomplete returns true if a Matrix channel lacks its room or access token.
+func (f *NotificationChannelForm) IsMatrixIncomplete() bool {
+	return f.Type == models.NotificationChannelMatrix.String() &&
+		(len(strings.TrimSpace(f.RoomID)) == 0 || len(strings.TrimSpace(f.Token)) == 0)
+}
+
+// NotificationChannel returns the notification channel described by the form.
+func (f *NotificationChannelForm) NotificationChannel() *models.NotificationChannel {
+	return &models.NotificationChannel{
+		Type:              models.ToNotificationChannelType(f.Type),
+		URL:               f.URL,
+		RoomID:            f.RoomID,
+		Token:             f.Token,
+		EventIssues:       f.EventIssues,
+		EventPullRequests: f.EventPullRequests,
+		EventComments:     f.EventComments,
+		EventStatus:       f.EventStatus,
+	}
+}
+
 // .___
 // |   | ______ ________ __   ____
 // |   |/  ___//  ___/  |  \_/ __ \
@@ -198,6 +253,8 @@ type CreateIssueForm struct {
 	AssigneeID  int64
 	Content     string
 	Files       []string
+
+	IsConfidential bool
 }
 
 // Validate validates the fields
@@ -205,6 +262,19 @@ func (f *CreateIssueForm) Validate(ctx *macaron.Context, errs binding.Errors) bi
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// ServiceDeskForm form for opening an issue through the service desk of a repository
+type ServiceDeskForm struct {
+	Name    string `binding:"MaxSize(100)"`
+	Email   string `binding:"Required;Email;MaxSize(254)"`
+	Title   string `binding:"Required;MaxSize(255)"`
+	Content string
+}
+
+// Validate validates the fields
+func (f *ServiceDeskForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // CreateCommentForm form for creating comment
 type CreateCommentForm struct {
 	Content string
@@ -236,6 +306,50 @@ func (f *CreateMilestoneForm) Validate(ctx *macaron.Context, errs binding.Errors
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// __________                       .___
+// \______   \ _________ _______  __| _/
+//  |    |  _//  _ \__  \\_  __ \/ __ |
+//  |    |   (  <_> ) __ \|  | \/ /_/ |
+//  |______  /\____(____  /__|  \____ |
+//         \/           \/           \/
+
+// CreateBoardForm form for creating and editing issue boards
+type CreateBoardForm struct {
+	Title       string `binding:"Required;MaxSize(255)"`
+	Description string
+}
+
+// Validate validates the fields
+func (f *CreateBoardForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// BoardColumnForm form for creating and editing columns of issue boards
+type BoardColumnForm struct {
+	Title    string `binding:"Required;MaxSize(255)"`
+	IsClosed bool
+}
+
+// Validate validates the fields
+func (f *BoardColumnForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// RepoAdvisoryForm form for creating and editing security advisories
+type RepoAdvisoryForm struct {
+	Title            string `binding:"Required;MaxSize(255)"`
+	Description      string
+	Severity         string `binding:"OmitEmpty;In(low,moderate,high,critical)"`
+	CVEID            string `form:"cve_id" binding:"MaxSize(255)"`
+	AffectedVersions string `binding:"MaxSize(255)"`
+	PatchedVersions  string `binding:"MaxSize(255)"`
+}
+
+// Validate validates the fields
+func (f *RepoAdvisoryForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // .____          ___.          .__
 // |    |   _____ \_ |__   ____ |  |
 // |    |   \__  \ | __ \_/ __ \|  |
@@ -245,9 +359,10 @@ func (f *CreateMilestoneForm) Validate(ctx *macaron.Context, errs binding.Errors
 
 // CreateLabelForm form for creating label
 type CreateLabelForm struct {
-	ID    int64
-	Title string `binding:"Required;MaxSize(50)" locale:"repo.issues.label_name"`
-	Color string `binding:"Required;Size(7)" locale:"repo.issues.label_color"`
+	ID          int64
+	Title       string `binding:"Required;MaxSize(50)" locale:"repo.issues.label_name"`
+	Description string `binding:"MaxSize(200)" locale:"repo.issues.label_description"`
+	Color       string `binding:"Required;Size(7)" locale:"repo.issues.label_color"`
 }
 
 // Validate validates the fields
@@ -255,6 +370,31 @@ func (f *CreateLabelForm) Validate(ctx *macaron.Context, errs binding.Errors) bi
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// CreateRepoLabelForm form for creating a label through the API
+type CreateRepoLabelForm struct {
+	Name        string `json:"name" binding:"Required;MaxSize(50)"`
+	Description string `json:"description" binding:"MaxSize(200)"`
+	Color       string `json:"color" binding:"Required;Size(7)"`
+}
+
+// Validate validates the fields
+func (f *CreateRepoLabelForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// EditRepoLabelForm form for editing a label through the API, only given
+// fields are changed
+type EditRepoLabelForm struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Color       *string `json:"color"`
+}
+
+// Validate validates the fields
+func (f *EditRepoLabelForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // InitializeLabelsForm form for initializing labels
 type InitializeLabelsForm struct {
 	TemplateName string `binding:"Required"`
@@ -408,3 +548,81 @@ type DeleteRepoFileForm struct {
 func (f *DeleteRepoFileForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// RepoFileIdentity is the identity of a committer of a repository file change
+type RepoFileIdentity struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// RepoContentsForm form for creating, updating or deleting a repository file
+// through the API. Content is base64 encoded.
+type RepoContentsForm struct {
+	Content   string            `json:"content"`
+	Message   string            `json:"message"`
+	Branch    string            `json:"branch" binding:"GitRefName;MaxSize(100)"`
+	NewBranch string            `json:"new_branch" binding:"GitRefName;MaxSize(100)"`
+	SHA       string            `json:"sha"`
+	Committer *RepoFileIdentity `json:"committer"`
+}
+
+// Validate validates the fields
+func (f *RepoContentsForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// EditIssueForm form for editing an issue through the API, DueDate is a
+// date like 2017-12-31, an empty string removes it.
+type EditIssueForm struct {
+	Title     string  `json:"title"`
+	Body      *string `json:"body"`
+	Assignee  *string `json:"assignee"`
+	Milestone *int64  `json:"milestone"`
+	State     *string `json:"state"`
+	DueDate   *string `json:"due_date"`
+
+	Confidential *bool `json:"confidential"`
+}
+
+// Validate validates the fields
+func (f *EditIssueForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// EditPullRequestForm form for editing a pull request through the API, Base
+// retargets it to another branch.
+type EditPullRequestForm struct {
+	Title     string  `json:"title"`
+	Body      string  `json:"body"`
+	Assignee  string  `json:"assignee"`
+	Milestone int64   `json:"milestone"`
+	Labels    []int64 `json:"labels"`
+	State     *string `json:"state"`
+	Base      string  `json:"base" binding:"GitRefName;MaxSize(100)"`
+}
+
+// Validate validates the fields
+func (f *EditPullRequestForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// MergePullRequestForm form for merging a pull request through the API
+type MergePullRequestForm struct {
+	Style   string `json:"style" binding:"OmitEmpty;In(merge,rebase,squash)"`
+	Message string `json:"message"`
+}
+
+// Validate validates the fields
+func (f *MergePullRequestForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// ChangePullBaseForm form for retargeting a pull request to another branch
+type ChangePullBaseForm struct {
+	BaseBranch string `binding:"Required;GitRefName;MaxSize(100)"`
+}
+
+// Validate validates the fields
+func (f *ChangePullBaseForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
@@ -106,6 +106,8 @@ type UpdateProfileForm struct {
 	KeepEmailPrivate bool
 	Website          string `binding:"ValidUrl;MaxSize(255)"`
 	Location         string `binding:"MaxSize(50)"`
+	StatusMessage    string `binding:"MaxSize(100)"`
+	IsBusy           bool
 }
 
 // Validate validates the fields
@@ -205,3 +207,15 @@ type TwoFactorScratchAuthForm struct {
 func (f *TwoFactorScratchAuthForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// AbuseReportForm form for reporting an issue, a comment, a repository or a user
+type AbuseReportForm struct {
+	Type     string `binding:"Required"`
+	TargetID int64  `binding:"Required"`
+	Reason   string `binding:"Required;MaxSize(2000)"`
+}
+
+// Validate validates the fields
+func (f *AbuseReportForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
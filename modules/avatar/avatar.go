@@ -5,18 +5,27 @@
 package avatar
 
 import (
+	"bytes"
 	"fmt"
 	"image"
 	"image/color/palette"
+	"image/png"
+	"io/ioutil"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/issue9/identicon"
+	"github.com/nfnt/resize"
 )
 
 // AvatarSize returns avatar's size
 const AvatarSize = 290
 
+// Sizes are the sizes avatars are stored in, in descending order.
+var Sizes = []int{AvatarSize, 140, 80, 40}
+
 // RandomImageSize generates and returns a random avatar image unique to input data
 // in custom size (height and width).
 func RandomImageSize(size int, data []byte) (image.Image, error) {
@@ -42,3 +51,83 @@ func RandomImageSize(size int, data []byte) (image.Image, error) {
 func RandomImage(data []byte) (image.Image, error) {
 	return RandomImageSize(AvatarSize, data)
 }
+
+// SizedPath returns the path of the file of the avatar stored at p in the
+// smallest size which is at least size, or in AvatarSize if size is not
+// positive.
+func SizedPath(p string, size int, webp bool) string {
+	if size <= 0 {
+		size = AvatarSize
+	}
+	best := AvatarSize
+	for _, s := range Sizes {
+		if s >= size && s < best {
+			best = s
+		}
+	}
+	if best != AvatarSize {
+		p = fmt.Sprintf("%s-%d", p, best)
+	}
+	if webp {
+		p += ".webp"
+	}
+	return p
+}
+
+// writeFile replaces the file at p with data, so that it is never served
+// partially written.
+func writeFile(p string, data []byte) error {
+	tmpPath := p + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, p)
+}
+
+// Save resizes img to all Sizes and writes them in PNG format to the paths
+// returned by SizedPath. WebP files are only written if they are smaller than
+// the PNG files.
+func Save(p string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return fmt.Errorf("MkdirAll: %v", err)
+	}
+
+	for _, size := range Sizes {
+		m := resize.Resize(uint(size), uint(size), img, resize.Bilinear)
+
+		var pngBuf, webpBuf bytes.Buffer
+		if err := png.Encode(&pngBuf, m); err != nil {
+			return fmt.Errorf("png.Encode: %v", err)
+		} else if err = EncodeWebP(&webpBuf, m); err != nil {
+			return fmt.Errorf("EncodeWebP: %v", err)
+		}
+
+		if err := writeFile(SizedPath(p, size, false), pngBuf.Bytes()); err != nil {
+			return err
+		}
+		webpPath := SizedPath(p, size, true)
+		if webpBuf.Len() < pngBuf.Len() {
+			if err := writeFile(webpPath, webpBuf.Bytes()); err != nil {
+				return err
+			}
+		} else if err := os.Remove(webpPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove deletes the files of the avatar stored at p.
+func Remove(p string) error {
+	if err := os.Remove(p); err != nil {
+		return err
+	}
+	for _, size := range Sizes {
+		for _, webp := range []bool{false, true} {
+			if err := os.Remove(SizedPath(p, size, webp)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
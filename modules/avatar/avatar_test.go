@@ -5,7 +5,17 @@
 package avatar
 
 import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -17,3 +27,92 @@ func Test_RandomImage(t *testing.T) {
 	_, err = RandomImageSize(0, []byte("gogs@local"))
 	assert.Error(t, err)
 }
+
+func Test_SizedPath(t *testing.T) {
+	assert.Equal(t, "a", SizedPath("a", 0, false))
+	assert.Equal(t, "a.webp", SizedPath("a", 1000, true))
+	assert.Equal(t, "a-80", SizedPath("a", 80, false))
+	assert.Equal(t, "a-140.webp", SizedPath("a", 81, true))
+	assert.Equal(t, "a-40", SizedPath("a", 20, false))
+}
+
+func Test_SaveRemove(t *testing.T) {
+	dir, err := ioutil.TempDir("", "avatar")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	img, err := RandomImage([]byte("gogs@local"))
+	assert.NoError(t, err)
+	p := filepath.Join(dir, "avatars", "1")
+	assert.NoError(t, Save(p, img))
+	for _, size := range Sizes {
+		f, err := os.Open(SizedPath(p, size, false))
+		assert.NoError(t, err)
+		m, err := png.Decode(f)
+		f.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, size, m.Bounds().Dx())
+	}
+
+	assert.NoError(t, Remove(p))
+	files, err := ioutil.ReadDir(filepath.Dir(p))
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func Test_EncodeWebP(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	for i := range img.Pix {
+		img.Pix[i] = byte(i * 10)
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, EncodeWebP(&buf, img))
+	data := buf.Bytes()
+	assert.Equal(t, "RIFF", string(data[:4]))
+	assert.EqualValues(t, len(data)-8, binary.LittleEndian.Uint32(data[4:]))
+	assert.Equal(t, "WEBPVP8L", string(data[8:16]))
+	// Signature, width and height minus one, alpha flag
+	assert.EqualValues(t, 0x2f, data[20])
+	header := binary.LittleEndian.Uint32(data[21:])
+	assert.EqualValues(t, 2, header&0x3fff)
+	assert.EqualValues(t, 1, header>>14&0x3fff)
+	assert.EqualValues(t, 1, header>>28&1)
+
+	assert.Error(t, EncodeWebP(&buf, image.NewNRGBA(image.Rect(0, 0, 0, 0))))
+}
+
+func Test_Fetch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "avatar")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	requests := 0
+	found := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		img, _ := RandomImage([]byte("gogs@local"))
+		png.Encode(w, img)
+	}))
+	defer server.Close()
+	getURL := func() string { return server.URL }
+
+	// Failures are not fetched again before the maximum age.
+	p := filepath.Join(dir, "avatar_proxy", "hash")
+	assert.Error(t, Fetch(http.DefaultClient, p, getURL, time.Hour))
+	assert.Equal(t, ErrFetchFailedRecently, Fetch(http.DefaultClient, p, getURL, time.Hour))
+	assert.Equal(t, 1, requests)
+
+	found = true
+	assert.NoError(t, Fetch(http.DefaultClient, p, getURL, 0))
+	assert.NoError(t, Fetch(http.DefaultClient, p, getURL, time.Hour))
+	assert.Equal(t, 2, requests)
+	_, err = os.Stat(p)
+	assert.NoError(t, err)
+	_, err = os.Stat(p + ".failed")
+	assert.True(t, os.IsNotExist(err))
+}
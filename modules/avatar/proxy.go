@@ -0,0 +1,66 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package avatar
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxFetchSize is the maximum size in bytes of fetched avatars.
+const maxFetchSize = 1 << 20
+
+// ErrFetchFailedRecently is returned by Fetch when fetching the avatar has
+// already failed less than the maximum age ago.
+var ErrFetchFailedRecently = errors.New("fetching the avatar failed recently")
+
+// Fetch downloads the image at the URL returned by getURL with client and
+// saves it at p like Save. Nothing is fetched, and getURL is not called, if
+// the avatar at p has been saved or fetching it has failed less than maxAge
+// ago.
+func Fetch(client *http.Client, p string, getURL func() string, maxAge time.Duration) error {
+	if fi, err := os.Stat(p); err == nil && time.Since(fi.ModTime()) < maxAge {
+		return nil
+	}
+	failedPath := p + ".failed"
+	if fi, err := os.Stat(failedPath); err == nil && time.Since(fi.ModTime()) < maxAge {
+		return ErrFetchFailedRecently
+	}
+
+	if err := fetch(client, p, getURL()); err != nil {
+		if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err == nil {
+			ioutil.WriteFile(failedPath, nil, 0644)
+		}
+		return err
+	}
+	os.Remove(failedPath)
+	return nil
+}
+
+func fetch(client *http.Client, p, url string) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	img, _, err := image.Decode(io.LimitReader(resp.Body, maxFetchSize))
+	if err != nil {
+		return fmt.Errorf("Decode: %v", err)
+	}
+	return Save(p, img)
+}
@@ -0,0 +1,525 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package avatar
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"sort"
+)
+
+// This file implements a lossless WebP (VP8L) encoder, as specified in
+// https://developers.google.com/speed/webp/docs/webp_lossless_bitstream_specification.
+// It applies the subtract green and a single predictor transform, and codes
+// runs of repeated pixels as backward references to the left or upper pixel.
+
+const (
+	vp8lMaxSize         = 1 << 14
+	vp8lPredictorBits   = 9
+	vp8lNumLengthCodes  = 24
+	vp8lNumDistCodes    = 40
+	vp8lMaxCodeLength   = 15
+	vp8lMaxCodeLenCode  = 7
+	vp8lMinCopyLength   = 3
+	vp8lMaxCopyLength   = 4096
+	vp8lTransformPred   = 0
+	vp8lTransformGreen  = 2
+	vp8lPlaneCodeUpper  = 1
+	vp8lPlaneCodeLeft   = 2
+	vp8lNumPlaneCodes   = 120
+	vp8lMaxDistance     = 1<<20 - vp8lNumPlaneCodes
+	vp8lPredictorL      = 1
+	vp8lPredictorT      = 2
+	vp8lPredictorAvgLT  = 7
+	vp8lPredictorGrad   = 12
+	vp8lOpaqueBlack     = 0xff000000
+	vp8lNumCodeLenCodes = 19
+)
+
+// vp8lCodeLenCodeOrder is the order the lengths of the code length code are
+// written in.
+var vp8lCodeLenCodeOrder = [vp8lNumCodeLenCodes]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+type bitWriter struct {
+	buf  []byte
+	acc  uint64
+	nacc uint
+}
+
+// writeBits writes the n lowest bits of v, least significant bit first.
+func (w *bitWriter) writeBits(v uint32, n uint) {
+	w.acc |= uint64(v) << w.nacc
+	w.nacc += n
+	for w.nacc >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.nacc -= 8
+	}
+}
+
+func (w *bitWriter) flush() []byte {
+	if w.nacc > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.nacc = 0, 0
+	}
+	return w.buf
+}
+
+// prefixCode is a canonical Huffman code, codes are stored bit-reversed to be
+// written least significant bit first.
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint32
+}
+
+func (c *prefixCode) write(w *bitWriter, symbol int) {
+	w.writeBits(c.codes[symbol], uint(c.lengths[symbol]))
+}
+
+type huffmanNode struct {
+	weight      int
+	symbol      int
+	left, right *huffmanNode
+}
+
+type huffmanHeap []*huffmanNode
+
+func (h huffmanHeap) Len() int            { return len(h) }
+func (h huffmanHeap) Less(i, j int) bool  { return h[i].weight < h[j].weight }
+func (h huffmanHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *huffmanHeap) Push(x interface{}) { *h = append(*h, x.(*huffmanNode)) }
+func (h *huffmanHeap) Pop() interface{} {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+func setHuffmanLengths(n *huffmanNode, depth uint8, lengths []uint8) uint8 {
+	if n.left == nil {
+		lengths[n.symbol] = depth
+		return depth
+	}
+	l := setHuffmanLengths(n.left, depth+1, lengths)
+	if r := setHuffmanLengths(n.right, depth+1, lengths); r > l {
+		return r
+	}
+	return l
+}
+
+// newPrefixCode builds a complete canonical Huffman code for the symbol
+// frequencies whose codes are at most maxLength bits long. Codes always
+// have at least two symbols, since decoders treat codes of a single symbol
+// differently.
+func newPrefixCode(freqs []int, maxLength uint8) *prefixCode {
+	var symbols []int
+	for s, f := range freqs {
+		if f > 0 {
+			symbols = append(symbols, s)
+		}
+	}
+	for s := 0; len(symbols) < 2; s++ {
+		if freqs[s] == 0 {
+			symbols = append(symbols, s)
+		}
+	}
+
+	lengths := make([]uint8, len(freqs))
+	// Flatten the frequencies until the code is short enough.
+	for minWeight := 1; ; minWeight *= 2 {
+		h := make(huffmanHeap, 0, len(symbols))
+		for _, s := range symbols {
+			weight := freqs[s]
+			if weight < minWeight {
+				weight = minWeight
+			}
+			h = append(h, &huffmanNode{weight: weight, symbol: s})
+		}
+		heap.Init(&h)
+		for h.Len() > 1 {
+			l := heap.Pop(&h).(*huffmanNode)
+			r := heap.Pop(&h).(*huffmanNode)
+			heap.Push(&h, &huffmanNode{weight: l.weight + r.weight, left: l, right: r})
+		}
+		if setHuffmanLengths(h[0], 0, lengths) <= maxLength {
+			break
+		}
+	}
+
+	// Assign canonical codes in order of length and symbol.
+	sort.Ints(symbols)
+	codes := make([]uint32, len(freqs))
+	code := uint32(0)
+	for length := uint8(1); length <= maxLength; length++ {
+		for _, s := range symbols {
+			if lengths[s] != length {
+				continue
+			}
+			var reversed uint32
+			for i := uint8(0); i < length; i++ {
+				reversed |= (code >> i & 1) << (length - 1 - i)
+			}
+			codes[s] = reversed
+			code++
+		}
+		code <<= 1
+	}
+	return &prefixCode{lengths, codes}
+}
+
+// codeLengthToken is a code length, or a repeat code with extra bits.
+type codeLengthToken struct {
+	code   int
+	extra  uint32
+	nextra uint
+}
+
+// codeLengthTokens codes runs of zeros and repeated code lengths with the
+// repeat codes 16, 17 and 18.
+func codeLengthTokens(lengths []uint8) []codeLengthToken {
+	var tokens []codeLengthToken
+	for i := 0; i < len(lengths); {
+		l := lengths[i]
+		n := 1
+		for i+n < len(lengths) && lengths[i+n] == l {
+			n++
+		}
+		i += n
+
+		if l != 0 {
+			tokens = append(tokens, codeLengthToken{code: int(l)})
+			n--
+		}
+		for n > 0 {
+			switch {
+			case l == 0 && n >= 11:
+				k := n
+				if k > 138 {
+					k = 138
+				}
+				tokens = append(tokens, codeLengthToken{18, uint32(k - 11), 7})
+				n -= k
+			case l == 0 && n >= 3:
+				tokens = append(tokens, codeLengthToken{17, uint32(n - 3), 3})
+				n = 0
+			case l != 0 && n >= 3:
+				k := n
+				if k > 6 {
+					k = 6
+				}
+				tokens = append(tokens, codeLengthToken{16, uint32(k - 3), 2})
+				n -= k
+			default:
+				tokens = append(tokens, codeLengthToken{code: int(l)})
+				n--
+			}
+		}
+	}
+	return tokens
+}
+
+// writePrefixCode writes the code lengths of c using a normal code length
+// code.
+func writePrefixCode(w *bitWriter, c *prefixCode) {
+	tokens := codeLengthTokens(c.lengths)
+	freqs := make([]int, vp8lNumCodeLenCodes)
+	for _, t := range tokens {
+		freqs[t.code]++
+	}
+	lenCode := newPrefixCode(freqs, vp8lMaxCodeLenCode)
+
+	numCodes := 4
+	for i, s := range vp8lCodeLenCodeOrder {
+		if lenCode.lengths[s] > 0 && i+1 > numCodes {
+			numCodes = i + 1
+		}
+	}
+
+	w.writeBits(0, 1) // normal code
+	w.writeBits(uint32(numCodes-4), 4)
+	for _, s := range vp8lCodeLenCodeOrder[:numCodes] {
+		w.writeBits(uint32(lenCode.lengths[s]), 3)
+	}
+	w.writeBits(0, 1) // code lengths of all symbols follow
+	for _, t := range tokens {
+		lenCode.write(w, t.code)
+		w.writeBits(t.extra, t.nextra)
+	}
+}
+
+// prefixEncode returns the prefix symbol and the extra bits of a length or
+// distance value.
+func prefixEncode(v int) (symbol int, extra uint32, nextra uint) {
+	v--
+	if v < 4 {
+		return v, 0, 0
+	}
+	highest := uint(0)
+	for v>>(highest+1) > 0 {
+		highest++
+	}
+	second := v >> (highest - 1) & 1
+	nextra = highest - 1
+	return int(2*highest) + second, uint32(v) & (1<<nextra - 1), nextra
+}
+
+// vp8lToken is a literal pixel, or a backward reference of length pixels to
+// the pixel at planeCode.
+type vp8lToken struct {
+	argb      uint32
+	length    int
+	planeCode int
+}
+
+func matchLength(pixels []uint32, i, dist int) int {
+	n := 0
+	for i+n < len(pixels) && n < vp8lMaxCopyLength && pixels[i+n] == pixels[i+n-dist] {
+		n++
+	}
+	return n
+}
+
+// tokenize codes pixels as literals and backward references to the left or
+// upper pixel, or to the last occurrence of the following three pixels.
+func tokenize(pixels []uint32, width int) []vp8lToken {
+	tokens := make([]vp8lToken, 0, len(pixels))
+	last := make(map[[vp8lMinCopyLength]uint32]int)
+	remember := func(i int) {
+		if i+vp8lMinCopyLength <= len(pixels) {
+			var key [vp8lMinCopyLength]uint32
+			copy(key[:], pixels[i:])
+			last[key] = i
+		}
+	}
+
+	for i := 0; i < len(pixels); {
+		length, planeCode := 0, 0
+		for _, c := range [][2]int{{1, vp8lPlaneCodeLeft}, {width, vp8lPlaneCodeUpper}} {
+			if i >= c[0] {
+				if n := matchLength(pixels, i, c[0]); n > length {
+					length, planeCode = n, c[1]
+				}
+			}
+		}
+		if i+vp8lMinCopyLength <= len(pixels) {
+			var key [vp8lMinCopyLength]uint32
+			copy(key[:], pixels[i:])
+			if j, ok := last[key]; ok && i-j <= vp8lMaxDistance {
+				if n := matchLength(pixels, i, i-j); n > length+1 {
+					length, planeCode = n, i-j+vp8lNumPlaneCodes
+				}
+			}
+		}
+
+		if length < vp8lMinCopyLength {
+			tokens = append(tokens, vp8lToken{argb: pixels[i]})
+			length = 1
+		} else {
+			tokens = append(tokens, vp8lToken{length: length, planeCode: planeCode})
+		}
+		for end := i + length; i < end; i++ {
+			remember(i)
+		}
+	}
+	return tokens
+}
+
+// writeEntropyCodedImage writes pixels without color cache and with a single
+// set of prefix codes.
+func writeEntropyCodedImage(w *bitWriter, pixels []uint32, width int, isMain bool) {
+	w.writeBits(0, 1) // no color cache
+	if isMain {
+		w.writeBits(0, 1) // no meta prefix codes
+	}
+
+	tokens := tokenize(pixels, width)
+	green := make([]int, 256+vp8lNumLengthCodes)
+	red := make([]int, 256)
+	blue := make([]int, 256)
+	alpha := make([]int, 256)
+	dist := make([]int, vp8lNumDistCodes)
+	for _, t := range tokens {
+		if t.length > 0 {
+			lenSymbol, _, _ := prefixEncode(t.length)
+			distSymbol, _, _ := prefixEncode(t.planeCode)
+			green[256+lenSymbol]++
+			dist[distSymbol]++
+			continue
+		}
+		green[t.argb>>8&0xff]++
+		red[t.argb>>16&0xff]++
+		blue[t.argb&0xff]++
+		alpha[t.argb>>24]++
+	}
+
+	codes := make([]*prefixCode, 5)
+	for i, freqs := range [][]int{green, red, blue, alpha, dist} {
+		codes[i] = newPrefixCode(freqs, vp8lMaxCodeLength)
+		writePrefixCode(w, codes[i])
+	}
+
+	for _, t := range tokens {
+		if t.length > 0 {
+			symbol, extra, nextra := prefixEncode(t.length)
+			codes[0].write(w, 256+symbol)
+			w.writeBits(extra, nextra)
+			symbol, extra, nextra = prefixEncode(t.planeCode)
+			codes[4].write(w, symbol)
+			w.writeBits(extra, nextra)
+			continue
+		}
+		codes[0].write(w, int(t.argb>>8&0xff))
+		codes[1].write(w, int(t.argb>>16&0xff))
+		codes[2].write(w, int(t.argb&0xff))
+		codes[3].write(w, int(t.argb>>24))
+	}
+}
+
+// mapChannels applies f to each 8 bit channel of the pixels a, b and c.
+func mapChannels(a, b, c uint32, f func(a, b, c int) int) uint32 {
+	var p uint32
+	for shift := uint(0); shift < 32; shift += 8 {
+		p |= uint32(f(int(a>>shift&0xff), int(b>>shift&0xff), int(c>>shift&0xff))&0xff) << shift
+	}
+	return p
+}
+
+func predict(mode int, left, top, topLeft uint32) uint32 {
+	switch mode {
+	case vp8lPredictorL:
+		return left
+	case vp8lPredictorT:
+		return top
+	case vp8lPredictorAvgLT:
+		return mapChannels(left, top, 0, func(l, t, _ int) int { return (l + t) / 2 })
+	}
+	return mapChannels(left, top, topLeft, func(l, t, tl int) int {
+		v := l + t - tl
+		if v < 0 {
+			return 0
+		} else if v > 255 {
+			return 255
+		}
+		return v
+	})
+}
+
+// residuals returns the differences of the pixels to their predictions.
+func residuals(pixels []uint32, width, mode int) []uint32 {
+	res := make([]uint32, len(pixels))
+	for i, p := range pixels {
+		x, y := i%width, i/width
+		var pred uint32
+		switch {
+		case x == 0 && y == 0:
+			pred = vp8lOpaqueBlack
+		case y == 0:
+			pred = pixels[i-1]
+		case x == 0:
+			pred = pixels[i-width]
+		default:
+			pred = predict(mode, pixels[i-1], pixels[i-width], pixels[i-width-1])
+		}
+		res[i] = mapChannels(p, pred, 0, func(p, pred, _ int) int { return p - pred })
+	}
+	return res
+}
+
+// entropy estimates the number of bits to code the channels of pixels.
+func entropy(pixels []uint32) float64 {
+	var hist [4][256]int
+	for _, p := range pixels {
+		for c := uint(0); c < 4; c++ {
+			hist[c][p>>(8*c)&0xff]++
+		}
+	}
+	var bits float64
+	total := float64(len(pixels))
+	for c := range hist {
+		for _, n := range hist[c] {
+			if n > 0 {
+				bits -= float64(n) * math.Log2(float64(n)/total)
+			}
+		}
+	}
+	return bits
+}
+
+// EncodeWebP writes img to w in lossless WebP format.
+func EncodeWebP(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= 0 || height <= 0 || width > vp8lMaxSize || height > vp8lMaxSize {
+		return errors.New("webp: invalid image size")
+	}
+
+	pixels := make([]uint32, 0, width*height)
+	hasAlpha := false
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			hasAlpha = hasAlpha || c.A != 0xff
+			// Subtract green transform
+			pixels = append(pixels, uint32(c.A)<<24|uint32(c.R-c.G)<<16|uint32(c.G)<<8|uint32(c.B-c.G))
+		}
+	}
+
+	mode, best := vp8lPredictorL, []uint32(nil)
+	bestBits := math.Inf(1)
+	for _, m := range []int{vp8lPredictorL, vp8lPredictorT, vp8lPredictorAvgLT, vp8lPredictorGrad} {
+		res := residuals(pixels, width, m)
+		if bits := entropy(res); bits < bestBits {
+			mode, best, bestBits = m, res, bits
+		}
+	}
+
+	bw := &bitWriter{}
+	bw.writeBits(0x2f, 8) // signature
+	bw.writeBits(uint32(width-1), 14)
+	bw.writeBits(uint32(height-1), 14)
+	if hasAlpha {
+		bw.writeBits(1, 1)
+	} else {
+		bw.writeBits(0, 1)
+	}
+	bw.writeBits(0, 3) // version
+
+	bw.writeBits(1, 1)
+	bw.writeBits(vp8lTransformGreen, 2)
+	bw.writeBits(1, 1)
+	bw.writeBits(vp8lTransformPred, 2)
+	bw.writeBits(vp8lPredictorBits-2, 3)
+	blockWidth := (width + 1<<vp8lPredictorBits - 1) >> vp8lPredictorBits
+	blockHeight := (height + 1<<vp8lPredictorBits - 1) >> vp8lPredictorBits
+	modes := make([]uint32, blockWidth*blockHeight)
+	for i := range modes {
+		modes[i] = uint32(mode) << 8
+	}
+	writeEntropyCodedImage(bw, modes, blockWidth, false)
+	bw.writeBits(0, 1) // no more transforms
+
+	writeEntropyCodedImage(bw, best, width, true)
+	data := bw.flush()
+
+	padded := len(data) + len(data)%2
+	header := make([]byte, 20)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(12+padded))
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(data)))
+	if len(data)%2 == 1 {
+		data = append(data, 0)
+	}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
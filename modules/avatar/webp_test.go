@@ -0,0 +1,458 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package avatar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// This file implements a lossless WebP decoder following the specification,
+// independently of the encoder, for the features the encoder uses.
+
+type bitReader struct {
+	data []byte
+	pos  uint
+}
+
+func (r *bitReader) readBits(n uint) (uint32, error) {
+	var v uint32
+	for i := uint(0); i < n; i++ {
+		if r.pos/8 >= uint(len(r.data)) {
+			return 0, errors.New("unexpected end of data")
+		}
+		v |= uint32(r.data[r.pos/8]>>(r.pos%8)&1) << i
+		r.pos++
+	}
+	return v, nil
+}
+
+// huffmanDecoder decodes a canonical prefix code, reading the bits of the
+// codes one at a time from the most significant one.
+type huffmanDecoder struct {
+	counts  [16]int
+	symbols []int
+	single  int
+}
+
+func newHuffmanDecoder(lengths []int) (*huffmanDecoder, error) {
+	d := &huffmanDecoder{single: -1}
+	for _, l := range lengths {
+		d.counts[l]++
+	}
+	nonZero := len(lengths) - d.counts[0]
+	if nonZero == 0 {
+		return nil, errors.New("empty prefix code")
+	}
+	for l := 1; l < 16; l++ {
+		for s, sl := range lengths {
+			if sl == l {
+				d.symbols = append(d.symbols, s)
+			}
+		}
+	}
+	if nonZero == 1 {
+		d.single = d.symbols[0]
+		return d, nil
+	}
+
+	left := 1
+	for l := 1; l < 16; l++ {
+		left = left*2 - d.counts[l]
+		if left < 0 {
+			return nil, errors.New("oversubscribed prefix code")
+		}
+	}
+	if left != 0 {
+		return nil, errors.New("incomplete prefix code")
+	}
+	return d, nil
+}
+
+func (d *huffmanDecoder) decode(r *bitReader) (int, error) {
+	if d.single >= 0 {
+		return d.single, nil
+	}
+	code, first, index := 0, 0, 0
+	for l := 1; l < 16; l++ {
+		bit, err := r.readBits(1)
+		if err != nil {
+			return 0, err
+		}
+		code |= int(bit)
+		count := d.counts[l]
+		if code-first < count {
+			return d.symbols[index+code-first], nil
+		}
+		index += count
+		first = (first + count) << 1
+		code <<= 1
+	}
+	return 0, errors.New("invalid prefix code")
+}
+
+func readPrefixCode(r *bitReader, alphabetSize int) (*huffmanDecoder, error) {
+	lengths := make([]int, alphabetSize)
+	simple, err := r.readBits(1)
+	if err != nil {
+		return nil, err
+	}
+	if simple == 1 {
+		numSymbols, _ := r.readBits(1)
+		firstBits, _ := r.readBits(1)
+		s0, _ := r.readBits(1 + 7*uint(firstBits))
+		lengths[s0] = 1
+		if numSymbols == 1 {
+			s1, err := r.readBits(8)
+			if err != nil {
+				return nil, err
+			}
+			lengths[s1] = 1
+		}
+		return newHuffmanDecoder(lengths)
+	}
+
+	order := []int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	numCodes, err := r.readBits(4)
+	if err != nil {
+		return nil, err
+	}
+	lenLengths := make([]int, len(order))
+	for _, s := range order[:numCodes+4] {
+		l, err := r.readBits(3)
+		if err != nil {
+			return nil, err
+		}
+		lenLengths[s] = int(l)
+	}
+	lenCode, err := newHuffmanDecoder(lenLengths)
+	if err != nil {
+		return nil, err
+	}
+
+	maxSymbol := alphabetSize
+	if useMax, _ := r.readBits(1); useMax == 1 {
+		n, _ := r.readBits(3)
+		m, err := r.readBits(2 + 2*uint(n))
+		if err != nil {
+			return nil, err
+		}
+		maxSymbol = 2 + int(m)
+	}
+
+	prev := 8
+	for s := 0; s < alphabetSize && maxSymbol > 0; maxSymbol-- {
+		code, err := lenCode.decode(r)
+		if err != nil {
+			return nil, err
+		}
+		repeat, value := 1, code
+		switch code {
+		case 16:
+			n, _ := r.readBits(2)
+			repeat, value = 3+int(n), prev
+		case 17:
+			n, _ := r.readBits(3)
+			repeat, value = 3+int(n), 0
+		case 18:
+			n, _ := r.readBits(7)
+			repeat, value = 11+int(n), 0
+		default:
+			if code != 0 {
+				prev = code
+			}
+		}
+		if s+repeat > alphabetSize {
+			return nil, errors.New("too many code lengths")
+		}
+		for ; repeat > 0; repeat-- {
+			lengths[s] = value
+			s++
+		}
+	}
+	return newHuffmanDecoder(lengths)
+}
+
+func readPrefixValue(r *bitReader, symbol int) (int, error) {
+	if symbol < 4 {
+		return symbol + 1, nil
+	}
+	extraBits := uint(symbol-2) >> 1
+	offset := (2 + symbol&1) << extraBits
+	extra, err := r.readBits(extraBits)
+	return offset + int(extra) + 1, err
+}
+
+func decodeEntropyCodedImage(r *bitReader, width, height int, isMain bool) ([]uint32, error) {
+	if cache, _ := r.readBits(1); cache == 1 {
+		return nil, errors.New("unsupported color cache")
+	}
+	if isMain {
+		if meta, _ := r.readBits(1); meta == 1 {
+			return nil, errors.New("unsupported meta prefix codes")
+		}
+	}
+
+	codes := make([]*huffmanDecoder, 5)
+	for i, size := range []int{256 + 24, 256, 256, 256, 40} {
+		var err error
+		if codes[i], err = readPrefixCode(r, size); err != nil {
+			return nil, err
+		}
+	}
+
+	pixels := make([]uint32, 0, width*height)
+	for len(pixels) < width*height {
+		g, err := codes[0].decode(r)
+		if err != nil {
+			return nil, err
+		}
+		if g < 256 {
+			var argb [3]int
+			for i := range argb {
+				if argb[i], err = codes[i+1].decode(r); err != nil {
+					return nil, err
+				}
+			}
+			pixels = append(pixels, uint32(argb[2])<<24|uint32(argb[0])<<16|uint32(g)<<8|uint32(argb[1]))
+			continue
+		}
+
+		length, err := readPrefixValue(r, g-256)
+		if err != nil {
+			return nil, err
+		}
+		distSymbol, err := codes[4].decode(r)
+		if err != nil {
+			return nil, err
+		}
+		distCode, err := readPrefixValue(r, distSymbol)
+		if err != nil {
+			return nil, err
+		}
+		var dist int
+		switch distCode {
+		case 1:
+			dist = width
+		case 2:
+			dist = 1
+		default:
+			if distCode <= 120 {
+				return nil, fmt.Errorf("unsupported distance code %d", distCode)
+			}
+			dist = distCode - 120
+		}
+		if dist > len(pixels) || len(pixels)+length > width*height {
+			return nil, errors.New("invalid backward reference")
+		}
+		for ; length > 0; length-- {
+			pixels = append(pixels, pixels[len(pixels)-dist])
+		}
+	}
+	return pixels, nil
+}
+
+func addPixels(a, b uint32) uint32 {
+	var p uint32
+	for shift := uint(0); shift < 32; shift += 8 {
+		p |= (a>>shift + b>>shift) & 0xff << shift
+	}
+	return p
+}
+
+func average2(a, b uint32) uint32 {
+	var p uint32
+	for shift := uint(0); shift < 32; shift += 8 {
+		p |= (a>>shift&0xff + b>>shift&0xff) / 2 << shift
+	}
+	return p
+}
+
+func clampAddSubtractFull(a, b, c uint32) uint32 {
+	var p uint32
+	for shift := uint(0); shift < 32; shift += 8 {
+		v := int(a>>shift&0xff) + int(b>>shift&0xff) - int(c>>shift&0xff)
+		if v < 0 {
+			v = 0
+		} else if v > 255 {
+			v = 255
+		}
+		p |= uint32(v) << shift
+	}
+	return p
+}
+
+// decodeWebP decodes a lossless WebP image.
+func decodeWebP(data []byte) (*image.NRGBA, error) {
+	if len(data) < 20 || string(data[:4]) != "RIFF" || string(data[8:16]) != "WEBPVP8L" {
+		return nil, errors.New("not a lossless WebP image")
+	}
+	size := binary.LittleEndian.Uint32(data[16:])
+	if int(size) > len(data)-20 {
+		return nil, errors.New("invalid chunk size")
+	}
+	r := &bitReader{data: data[20 : 20+size]}
+
+	if sig, _ := r.readBits(8); sig != 0x2f {
+		return nil, errors.New("invalid signature")
+	}
+	w, _ := r.readBits(14)
+	h, _ := r.readBits(14)
+	width, height := int(w)+1, int(h)+1
+	r.readBits(1) // alpha hint
+	if version, err := r.readBits(3); err != nil || version != 0 {
+		return nil, errors.New("invalid version")
+	}
+
+	type transform struct {
+		kind      uint32
+		sizeBits  uint
+		subPixels []uint32
+	}
+	var transforms []transform
+	for {
+		more, err := r.readBits(1)
+		if err != nil {
+			return nil, err
+		} else if more == 0 {
+			break
+		}
+		t := transform{}
+		t.kind, _ = r.readBits(2)
+		switch t.kind {
+		case vp8lTransformGreen:
+		case vp8lTransformPred:
+			bits, _ := r.readBits(3)
+			t.sizeBits = uint(bits) + 2
+			blocks := func(n int) int { return (n + 1<<t.sizeBits - 1) >> t.sizeBits }
+			if t.subPixels, err = decodeEntropyCodedImage(r, blocks(width), blocks(height), false); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported transform %d", t.kind)
+		}
+		transforms = append(transforms, t)
+	}
+
+	pixels, err := decodeEntropyCodedImage(r, width, height, true)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(transforms) - 1; i >= 0; i-- {
+		t := transforms[i]
+		switch t.kind {
+		case vp8lTransformGreen:
+			for j, p := range pixels {
+				g := p >> 8 & 0xff
+				pixels[j] = p&0xff00ff00 | (p>>16+g)&0xff<<16 | (p+g)&0xff
+			}
+		case vp8lTransformPred:
+			blockWidth := (width + 1<<t.sizeBits - 1) >> t.sizeBits
+			for j, p := range pixels {
+				x, y := j%width, j/width
+				var pred uint32
+				switch {
+				case x == 0 && y == 0:
+					pred = vp8lOpaqueBlack
+				case y == 0:
+					pred = pixels[j-1]
+				case x == 0:
+					pred = pixels[j-width]
+				default:
+					left, top, topLeft := pixels[j-1], pixels[j-width], pixels[j-width-1]
+					mode := t.subPixels[(y>>t.sizeBits)*blockWidth+x>>t.sizeBits] >> 8 & 0xf
+					switch mode {
+					case 0:
+						pred = vp8lOpaqueBlack
+					case 1:
+						pred = left
+					case 2:
+						pred = top
+					case 4:
+						pred = topLeft
+					case 6:
+						pred = average2(left, topLeft)
+					case 7:
+						pred = average2(left, top)
+					case 8:
+						pred = average2(topLeft, top)
+					case 12:
+						pred = clampAddSubtractFull(left, top, topLeft)
+					default:
+						return nil, fmt.Errorf("unsupported predictor %d", mode)
+					}
+				}
+				pixels[j] = addPixels(p, pred)
+			}
+		}
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for j, p := range pixels {
+		img.Pix[4*j] = uint8(p >> 16)
+		img.Pix[4*j+1] = uint8(p >> 8)
+		img.Pix[4*j+2] = uint8(p)
+		img.Pix[4*j+3] = uint8(p >> 24)
+	}
+	return img, nil
+}
+
+func Test_EncodeWebPDecode(t *testing.T) {
+	identicon, err := RandomImage([]byte("gogs@local"))
+	assert.NoError(t, err)
+
+	noise := image.NewNRGBA(image.Rect(0, 0, 37, 23))
+	for i := range noise.Pix {
+		noise.Pix[i] = byte(i * 7919 % 251)
+	}
+
+	// Wider than a predictor block, with long runs and repeated rows.
+	gradient := image.NewNRGBA(image.Rect(10, 10, 610, 30))
+	for y := 10; y < 30; y++ {
+		for x := 10; x < 610; x++ {
+			gradient.Set(x, y, color.NRGBA{uint8(x / 50), uint8(y % 4), 200, uint8(255 - x/100)})
+		}
+	}
+
+	uniform := image.NewNRGBA(image.Rect(0, 0, 100, 100))
+	for i := range uniform.Pix {
+		uniform.Pix[i] = 0x80
+	}
+
+	for _, img := range []image.Image{
+		identicon,
+		noise,
+		gradient,
+		uniform,
+		image.NewNRGBA(image.Rect(0, 0, 1, 1)),
+	} {
+		var buf bytes.Buffer
+		assert.NoError(t, EncodeWebP(&buf, img))
+		decoded, err := decodeWebP(buf.Bytes())
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		bounds := img.Bounds()
+		assert.Equal(t, bounds.Dx(), decoded.Bounds().Dx())
+		assert.Equal(t, bounds.Dy(), decoded.Bounds().Dy())
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				expected := color.NRGBAModel.Convert(img.At(x, y))
+				if !assert.Equal(t, expected, decoded.At(x-bounds.Min.X, y-bounds.Min.Y), "pixel %d,%d", x, y) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -196,6 +196,10 @@ func HashEmail(email string) string {
 // which includes app sub-url as prefix. However, it is possible
 // to return full URL if user enables Gravatar-like service.
 func AvatarLink(email string) string {
+	if setting.EnableAvatarProxy && !setting.DisableGravatar {
+		return setting.AppSubURL + "/avatar/" + HashEmail(email)
+	}
+
 	if setting.EnableFederatedAvatar && setting.LibravatarService != nil {
 		// TODO: This doesn't check any error. AvatarLink should return (string, error)
 		url, _ := setting.LibravatarService.FromEmail(email)
@@ -138,6 +138,13 @@ func TestAvatarLink(t *testing.T) {
 		"353cbad9b58e69c96154ad99f92bedc7",
 		AvatarLink("gitea@example.com"),
 	)
+
+	setting.EnableAvatarProxy = true
+	assert.Equal(t,
+		"/avatar/353cbad9b58e69c96154ad99f92bedc7",
+		AvatarLink("gitea@example.com"),
+	)
+	setting.EnableAvatarProxy = false
 }
 
 func TestComputeTimeDiff(t *testing.T) {
@@ -5,7 +5,9 @@
 package context
 
 import (
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"code.gitea.io/git"
@@ -71,26 +73,72 @@ func (ctx *APIContext) Error(status int, title string, obj interface{}) {
 	})
 }
 
-// SetLinkHeader sets pagination link header by given total number and page size.
+// pageLink returns the URL of given page of the list requested, keeping the
+// other query parameters of the request.
+func (ctx *APIContext) pageLink(page int) string {
+	query := ctx.Req.URL.Query()
+	query.Set("page", strconv.Itoa(page))
+	return fmt.Sprintf("%s%s?%s", setting.AppURL, ctx.Req.URL.Path[1:], query.Encode())
+}
+
+// SetLinkHeader sets pagination link header by given total number and page
+// size as described by RFC 5988, and the total number in the X-Total-Count
+// header.
 func (ctx *APIContext) SetLinkHeader(total, pageSize int) {
 	page := paginater.New(total, pageSize, ctx.QueryInt("page"), 0)
 	links := make([]string, 0, 4)
 	if page.HasNext() {
-		links = append(links, fmt.Sprintf("<%s%s?page=%d>; rel=\"next\"", setting.AppURL, ctx.Req.URL.Path[1:], page.Next()))
+		links = append(links, fmt.Sprintf("<%s>; rel=\"next\"", ctx.pageLink(page.Next())))
 	}
 	if !page.IsLast() {
-		links = append(links, fmt.Sprintf("<%s%s?page=%d>; rel=\"last\"", setting.AppURL, ctx.Req.URL.Path[1:], page.TotalPages()))
+		links = append(links, fmt.Sprintf("<%s>; rel=\"last\"", ctx.pageLink(page.TotalPages())))
 	}
 	if !page.IsFirst() {
-		links = append(links, fmt.Sprintf("<%s%s?page=1>; rel=\"first\"", setting.AppURL, ctx.Req.URL.Path[1:]))
+		links = append(links, fmt.Sprintf("<%s>; rel=\"first\"", ctx.pageLink(1)))
 	}
 	if page.HasPrevious() {
-		links = append(links, fmt.Sprintf("<%s%s?page=%d>; rel=\"prev\"", setting.AppURL, ctx.Req.URL.Path[1:], page.Previous()))
+		links = append(links, fmt.Sprintf("<%s>; rel=\"prev\"", ctx.pageLink(page.Previous())))
 	}
 
 	if len(links) > 0 {
 		ctx.Header().Set("Link", strings.Join(links, ","))
 	}
+	ctx.Header().Set("X-Total-Count", strconv.Itoa(total))
+}
+
+// etagMatches returns true if the If-None-Match header value matches given
+// ETag, weak comparison is used as described by RFC 7232.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// JSONWithETag responds obj in JSON like JSON does, with an ETag header
+// computed from the response so that clients can make conditional requests.
+// If the If-None-Match header of the request matches the ETag, it responds
+// 304 Not Modified without body instead.
+func (ctx *APIContext) JSONWithETag(status int, obj interface{}) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		ctx.Error(500, "Marshal", err)
+		return
+	}
+
+	etag := `"` + base.EncodeSha1(string(data)) + `"`
+	ctx.Header().Set("ETag", etag)
+	if etagMatches(ctx.Req.Header.Get("If-None-Match"), etag) {
+		ctx.Status(304)
+		return
+	}
+
+	ctx.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	ctx.Resp.WriteHeader(status)
+	ctx.Resp.Write(data)
 }
 
 // APIContexter returns apicontext as macaron middleware
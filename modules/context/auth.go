@@ -49,6 +49,13 @@ func Toggle(options *ToggleOptions) macaron.Handler {
 			return
 		}
 
+		// Users with an expired password have to change it before anything else.
+		if ctx.IsSigned && ctx.User.IsPasswordExpired() && !auth.IsAPIPath(ctx.Req.URL.Path) &&
+			ctx.Req.URL.Path != "/user/settings/password" && ctx.Req.URL.Path != "/user/logout" {
+			ctx.Redirect(setting.AppSubURL + "/user/settings/password")
+			return
+		}
+
 		if !options.SignOutRequired && !options.DisableCSRF && ctx.Req.Method == "POST" && !auth.IsAPIPath(ctx.Req.URL.Path) {
 			csrf.Validate(ctx.Context, ctx.csrf)
 			if ctx.Written() {
@@ -56,6 +63,17 @@ func Toggle(options *ToggleOptions) macaron.Handler {
 			}
 		}
 
+		// API requests authenticated by the cookies of the user are protected
+		// like the web forms, those authenticated by an access token or basic
+		// authentication cannot be forged by another site.
+		if ctx.IsSigned && !options.DisableCSRF && auth.IsAPIPath(ctx.Req.URL.Path) &&
+			!isSafeMethod(ctx.Req.Method) && !ctx.IsBasicAuth && !auth.HasAPICredentials(ctx.Context) {
+			csrf.Validate(ctx.Context, ctx.csrf)
+			if ctx.Written() {
+				return
+			}
+		}
+
 		if options.SignInRequired {
 			if !ctx.IsSigned {
 				// Restrict API calls with error message.
@@ -93,3 +111,8 @@ func Toggle(options *ToggleOptions) macaron.Handler {
 		}
 	}
 }
+
+// isSafeMethod returns true if requests with given HTTP method do not change anything.
+func isSafeMethod(method string) bool {
+	return method == "GET" || method == "HEAD" || method == "OPTIONS"
+}
@@ -18,6 +18,7 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 	"github.com/go-macaron/cache"
 	"github.com/go-macaron/csrf"
 	"github.com/go-macaron/i18n"
@@ -36,11 +37,19 @@ type Context struct {
 	User        *models.User
 	IsSigned    bool
 	IsBasicAuth bool
+	// Impersonator is the admin acting as User, if any.
+	Impersonator *models.User
 
 	Repo *Repository
 	Org  *Organization
 }
 
+// RemoteAddr returns the IP address of the client, the forwarding headers
+// are only honoured from trusted proxies.
+func (ctx *Context) RemoteAddr() string {
+	return auth.RemoteAddr(ctx.Req.Request)
+}
+
 // HasAPIError returns true if error occurs in form validation.
 func (ctx *Context) HasAPIError() bool {
 	hasErr, ok := ctx.Data["HasError"]
@@ -72,12 +81,44 @@ func (ctx *Context) HasValue(name string) bool {
 	return ok
 }
 
+// QueryOptionalBool returns the boolean value of the query parameter, or none
+// if the parameter is not given.
+func (ctx *Context) QueryOptionalBool(key string) util.OptionalBool {
+	if len(ctx.Query(key)) == 0 {
+		return util.OptionalBoolNone
+	}
+	return util.OptionalBoolOf(ctx.QueryBool(key))
+}
+
 // HTML calls Context.HTML and converts template name to string.
 func (ctx *Context) HTML(status int, name base.TplName) {
 	log.Debug("Template: %s", name)
+	ctx.loadBanners()
 	ctx.Context.HTML(status, string(name))
 }
 
+// loadBanners makes the instance wide banner and the banner of the organization
+// owning the requested page available to templates.
+func (ctx *Context) loadBanners() {
+	if !setting.InstallLock {
+		return
+	}
+
+	var ownerID int64
+	if ctx.Repo != nil && ctx.Repo.Owner != nil && ctx.Repo.Owner.IsOrganization() {
+		ownerID = ctx.Repo.Owner.ID
+	} else if ctx.Org != nil && ctx.Org.Organization != nil {
+		ownerID = ctx.Org.Organization.ID
+	}
+
+	banners, err := models.GetActiveBanners(ownerID)
+	if err != nil {
+		log.Error(4, "GetActiveBanners: %v", err)
+		return
+	}
+	ctx.Data["Banners"] = banners
+}
+
 // RenderWithErr used for page has form validation but need to prompt error to users.
 func (ctx *Context) RenderWithErr(msg string, tpl base.TplName, form interface{}) {
 	if form != nil {
@@ -145,6 +186,21 @@ func (ctx *Context) ServeContent(name string, r io.ReadSeeker, params ...interfa
 	http.ServeContent(ctx.Resp, ctx.Req.Request, name, modtime, r)
 }
 
+// GitContexter initializes a context for a request of the git smart HTTP
+// protocol, without session, CSRF protection nor locale.
+func GitContexter() macaron.Handler {
+	return func(c *macaron.Context) {
+		ctx := &Context{
+			Context: c,
+			Repo: &Repository{
+				PullRequest: &PullRequest{},
+			},
+			Org: &Organization{},
+		}
+		c.Map(ctx)
+	}
+}
+
 // Contexter initializes a classic context for a request.
 func Contexter() macaron.Handler {
 	return func(c *macaron.Context, l i18n.Locale, cache cache.Cache, sess session.Store, f *session.Flash, x csrf.CSRF) {
@@ -166,6 +222,9 @@ func Contexter() macaron.Handler {
 
 		// Get user from session if logged in.
 		ctx.User, ctx.IsBasicAuth = auth.SignedInUser(ctx.Context, ctx.Session)
+		if ctx.User != nil && !ctx.IsBasicAuth {
+			ctx.loadImpersonation()
+		}
 
 		if ctx.User != nil {
 			ctx.IsSigned = true
@@ -174,6 +233,13 @@ func Contexter() macaron.Handler {
 			ctx.Data["SignedUserID"] = ctx.User.ID
 			ctx.Data["SignedUserName"] = ctx.User.Name
 			ctx.Data["IsAdmin"] = ctx.User.IsAdmin
+
+			// Keep track of the web sessions the user is signed in with.
+			if uid, ok := ctx.Session.Get("uid").(int64); ok && uid == ctx.User.ID {
+				if err := models.TouchUserSession(uid, ctx.Session.ID(), ctx.RemoteAddr(), ctx.Req.UserAgent()); err != nil {
+					log.Error(4, "TouchUserSession: %v", err)
+				}
+			}
 		} else {
 			ctx.Data["SignedUserID"] = 0
 			ctx.Data["SignedUserName"] = ""
@@ -0,0 +1,80 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	macaron "gopkg.in/macaron.v1"
+)
+
+// isAllowedOrigin returns true if given origin may call the API
+// cross-origin, and whether the origin is listed explicitly rather than
+// matched by a wildcard.
+func isAllowedOrigin(origin string) (allowed, explicit bool) {
+	u, err := url.Parse(origin)
+	if err != nil || len(u.Host) == 0 {
+		return false, false
+	}
+
+	for _, domain := range setting.CORSConfig.AllowDomain {
+		domain = strings.TrimSpace(domain)
+		switch {
+		case domain == "*":
+			allowed = true
+		case strings.HasPrefix(domain, "*."):
+			if strings.HasSuffix(strings.ToLower(u.Hostname()), strings.ToLower(domain[1:])) {
+				allowed = true
+			}
+		case strings.Contains(domain, "://"):
+			if strings.EqualFold(origin, domain) {
+				return true, true
+			}
+		case strings.EqualFold(u.Host, domain):
+			return true, true
+		}
+	}
+	return allowed, false
+}
+
+// CORS returns a middleware adding the CORS headers to the responses of the
+// API, and answering preflight requests.
+func CORS() macaron.Handler {
+	return func(ctx *macaron.Context) {
+		if !strings.HasPrefix(ctx.Req.URL.Path, "/api/v1/") {
+			return
+		}
+
+		origin := ctx.Req.Header.Get("Origin")
+		if len(origin) == 0 {
+			return
+		}
+		allowed, explicit := isAllowedOrigin(origin)
+		if !allowed {
+			return
+		}
+
+		header := ctx.Resp.Header()
+		header.Add("Vary", "Origin")
+		header.Set("Access-Control-Allow-Origin", origin)
+		// Any site matching a wildcard could read the responses made with the
+		// cookies of the user.
+		if setting.CORSConfig.AllowCredentials && explicit {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		// Preflight request.
+		if ctx.Req.Method == "OPTIONS" && len(ctx.Req.Header.Get("Access-Control-Request-Method")) > 0 {
+			header.Set("Access-Control-Allow-Methods", strings.Join(setting.CORSConfig.Methods, ", "))
+			header.Set("Access-Control-Allow-Headers", strings.Join(setting.CORSConfig.Headers, ", "))
+			header.Set("Access-Control-Max-Age", strconv.Itoa(setting.CORSConfig.MaxAge))
+			ctx.Resp.WriteHeader(200)
+		}
+	}
+}
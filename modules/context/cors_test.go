@@ -0,0 +1,38 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsAllowedOrigin(t *testing.T) {
+	defer func(domains []string) {
+		setting.CORSConfig.AllowDomain = domains
+	}(setting.CORSConfig.AllowDomain)
+
+	for _, c := range []struct {
+		domains           []string
+		origin            string
+		allowed, explicit bool
+	}{
+		{[]string{"*"}, "https://evil.com", true, false},
+		{[]string{"*.example.com"}, "https://tool.example.com:8443", true, false},
+		{[]string{"*.example.com"}, "https://example.com.evil.com", false, false},
+		{[]string{"*", "tool.example.com"}, "https://tool.example.com", true, true},
+		{[]string{"https://tool.example.com"}, "https://tool.example.com", true, true},
+		{[]string{"https://tool.example.com"}, "http://tool.example.com", false, false},
+		{[]string{"tool.example.com"}, "not an origin", false, false},
+	} {
+		setting.CORSConfig.AllowDomain = c.domains
+		allowed, explicit := isAllowedOrigin(c.origin)
+		assert.Equal(t, c.allowed, allowed, "%v %s", c.domains, c.origin)
+		assert.Equal(t, c.explicit, explicit, "%v %s", c.domains, c.origin)
+	}
+}
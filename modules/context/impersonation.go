@@ -0,0 +1,110 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// Session keys of the user an admin impersonates and of when the
+// impersonation ends.
+const (
+	impersonatedUIDKey      = "impersonatedUID"
+	impersonationExpiresKey = "impersonationExpires"
+)
+
+// audit records an action of the admin impersonating the user in the audit
+// log. A failure is logged but does not fail the request.
+func (ctx *Context) audit(action models.AuditAction, doer *models.User, userID int64) {
+	if err := models.CreateAuditLog(&models.AuditLog{
+		Action:     action,
+		DoerID:     doer.ID,
+		UserID:     userID,
+		Method:     ctx.Req.Method,
+		Path:       ctx.Req.URL.RequestURI(),
+		RemoteAddr: ctx.RemoteAddr(),
+	}); err != nil {
+		log.Error(4, "CreateAuditLog: %v", err)
+	}
+}
+
+// clearImpersonation forgets the impersonation of the session.
+func (ctx *Context) clearImpersonation() {
+	ctx.Session.Delete(impersonatedUIDKey)
+	ctx.Session.Delete(impersonationExpiresKey)
+}
+
+// loadImpersonation switches the signed in admin to the user impersonated in
+// the session, until the impersonation expires, and records the request in
+// the audit log like Sudo does. The impersonation is forgotten if the admin
+// can no longer act as the user.
+func (ctx *Context) loadImpersonation() {
+	uid, ok := ctx.Session.Get(impersonatedUIDKey).(int64)
+	if !ok {
+		return
+	}
+	if sessUID, _ := ctx.Session.Get("uid").(int64); sessUID != ctx.User.ID {
+		ctx.clearImpersonation()
+		return
+	}
+
+	expires, _ := ctx.Session.Get(impersonationExpiresKey).(int64)
+	if time.Now().Unix() >= expires {
+		ctx.audit(models.AuditImpersonationExpire, ctx.User, uid)
+		ctx.clearImpersonation()
+		return
+	}
+
+	u, err := models.GetUserByID(uid)
+	if err != nil {
+		if !models.IsErrUserNotExist(err) {
+			log.Error(4, "GetUserByID: %v", err)
+		}
+		ctx.clearImpersonation()
+		return
+	} else if !ctx.User.CanImpersonate(u) {
+		ctx.clearImpersonation()
+		return
+	}
+
+	ctx.Impersonator = ctx.User
+	ctx.User = u
+	ctx.Data["Impersonator"] = ctx.Impersonator
+	ctx.Data["ImpersonationExpires"] = time.Unix(expires, 0).Local()
+	ctx.audit(models.AuditImpersonatedRequest, ctx.Impersonator, u.ID)
+}
+
+// StartImpersonation makes the signed in admin act as given user for the
+// time allowed by the settings, the caller must have checked the admin can.
+func (ctx *Context) StartImpersonation(u *models.User) {
+	ctx.Session.Set(impersonatedUIDKey, u.ID)
+	ctx.Session.Set(impersonationExpiresKey, time.Now().Add(setting.Admin.ImpersonationDuration).Unix())
+	ctx.audit(models.AuditImpersonationStart, ctx.User, u.ID)
+}
+
+// StopImpersonation switches the admin impersonating a user back to
+// themselves, it does nothing if no user is impersonated.
+func (ctx *Context) StopImpersonation() {
+	if ctx.Impersonator == nil {
+		return
+	}
+	ctx.audit(models.AuditImpersonationStop, ctx.Impersonator, ctx.User.ID)
+	ctx.clearImpersonation()
+	ctx.User = ctx.Impersonator
+	ctx.Impersonator = nil
+}
+
+// Sudo makes the signed in admin act as given user for the current request
+// only, the request is recorded in the audit log. The caller must have
+// checked the admin can.
+func (ctx *Context) Sudo(u *models.User) {
+	ctx.audit(models.AuditImpersonatedAPIRequest, ctx.User, u.ID)
+	ctx.Impersonator = ctx.User
+	ctx.User = u
+}
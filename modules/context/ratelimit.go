@@ -0,0 +1,42 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Unknwon/com"
+
+	"code.gitea.io/gitea/modules/ratelimit"
+	"code.gitea.io/gitea/modules/setting"
+
+	macaron "gopkg.in/macaron.v1"
+)
+
+// APIRateLimit returns a middleware limiting the number of requests each user,
+// or each IP address for anonymous requests, can make to the API. The limit
+// and what is left of it are told in the X-RateLimit-* headers. The address
+// is taken from the forwarding headers only for trusted proxies, clients
+// could otherwise get a new limit with every request.
+func APIRateLimit() macaron.Handler {
+	limiter := ratelimit.NewLimiter(setting.API.RateLimit, setting.API.RateLimitWindow)
+	return func(ctx *APIContext) {
+		key := "ip:" + ctx.RemoteAddr()
+		if ctx.IsSigned {
+			key = "user:" + com.ToStr(ctx.User.ID)
+		}
+
+		remaining, reset, ok := limiter.Take(key)
+		header := ctx.Resp.Header()
+		header.Set("X-RateLimit-Limit", strconv.Itoa(limiter.Limit))
+		header.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		header.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		if !ok {
+			header.Set("Retry-After", strconv.Itoa(int(reset.Sub(time.Now())/time.Second)+1))
+			ctx.Error(429, "", "API rate limit exceeded")
+		}
+	}
+}
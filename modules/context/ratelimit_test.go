@@ -0,0 +1,47 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+	macaron "gopkg.in/macaron.v1"
+)
+
+func TestAPIRateLimit(t *testing.T) {
+	defer func(limit int, window time.Duration) {
+		setting.API.RateLimit = limit
+		setting.API.RateLimitWindow = window
+	}(setting.API.RateLimit, setting.API.RateLimitWindow)
+	setting.API.RateLimit = 1
+	setting.API.RateLimitWindow = time.Hour
+
+	m := macaron.New()
+	m.Use(macaron.Renderer())
+	m.Use(func(c *macaron.Context) {
+		c.Map(&APIContext{Context: &Context{Context: c}})
+	})
+	m.Get("/", APIRateLimit(), func() {})
+
+	request := func(remoteAddr, forwardedFor string) int {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		resp := httptest.NewRecorder()
+		m.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("192.0.2.1:1234", "198.51.100.1"))
+	// A forged forwarded address does not give a new bucket.
+	assert.Equal(t, 429, request("192.0.2.1:1234", "198.51.100.2"))
+	assert.Equal(t, http.StatusOK, request("192.0.2.2:1234", ""))
+}
@@ -0,0 +1,66 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// RenderCacheKey returns the key of a render result of given kind, which is
+// derived from all inputs of the render so that changed inputs miss the cache.
+func RenderCacheKey(kind string, metas map[string]string, inputs ...string) string {
+	h := sha256.New()
+	for _, input := range inputs {
+		h.Write([]byte(input))
+		h.Write([]byte{0})
+	}
+
+	names := make([]string, 0, len(metas))
+	for name := range metas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h.Write([]byte(name + "=" + metas[name]))
+		h.Write([]byte{0})
+	}
+	return "render-" + kind + ":" + hex.EncodeToString(h.Sum(nil))
+}
+
+// GetRenderCache returns the cached render result of key.
+func (ctx *Context) GetRenderCache(key string) (string, bool) {
+	if !setting.RenderCache.Enabled {
+		return "", false
+	}
+	result, ok := ctx.Cache.Get(key).(string)
+	return result, ok
+}
+
+// PutRenderCache caches the render result of key, unless it is larger than
+// the maximum size of cached results.
+func (ctx *Context) PutRenderCache(key, result string) {
+	if !setting.RenderCache.Enabled || int64(len(result)) > setting.RenderCache.MaxSize {
+		return
+	}
+	if err := ctx.Cache.Put(key, result, int64(setting.RenderCache.TTL.Seconds())); err != nil {
+		log.Error(4, "Put render cache: %v", err)
+	}
+}
+
+// DeleteRenderCache removes the render result of key from the cache, e.g.
+// when its input has been edited.
+func (ctx *Context) DeleteRenderCache(key string) {
+	if !setting.RenderCache.Enabled {
+		return
+	}
+	if err := ctx.Cache.Delete(key); err != nil {
+		log.Error(4, "Delete render cache: %v", err)
+	}
+}
@@ -48,6 +48,9 @@ type Repository struct {
 	Mirror       *models.Mirror
 
 	PullRequest *PullRequest
+
+	// Access to each unit if it differs between units, see models.UnitAccessModes
+	UnitAccessModes map[models.UnitType]models.AccessMode
 }
 
 // IsOwner returns true if current user is the owner of repository.
@@ -65,6 +68,24 @@ func (r *Repository) IsWriter() bool {
 	return r.AccessMode >= models.AccessModeWrite
 }
 
+// UnitAccessMode returns the access of current user to given unit of repository.
+func (r *Repository) UnitAccessMode(unitType models.UnitType) models.AccessMode {
+	if mode, ok := r.UnitAccessModes[unitType]; ok {
+		return mode
+	}
+	return r.AccessMode
+}
+
+// CanWrite returns true if current user has write or higher access to given unit of repository.
+func (r *Repository) CanWrite(unitType models.UnitType) bool {
+	return r.UnitAccessMode(unitType) >= models.AccessModeWrite
+}
+
+// CanTriage returns true if current user has triage or higher access to given unit of repository.
+func (r *Repository) CanTriage(unitType models.UnitType) bool {
+	return r.UnitAccessMode(unitType) >= models.AccessModeTriage
+}
+
 // HasAccess returns true if the current user has at least read access for this repository
 func (r *Repository) HasAccess() bool {
 	return r.AccessMode >= models.AccessModeRead
@@ -72,7 +93,7 @@ func (r *Repository) HasAccess() bool {
 
 // CanEnableEditor returns true if repository is editable and user has proper access level.
 func (r *Repository) CanEnableEditor() bool {
-	return r.Repository.CanEnableEditor() && r.IsViewBranch && r.IsWriter()
+	return r.Repository.CanEnableEditor() && r.IsViewBranch && r.CanWrite(models.UnitTypeCode)
 }
 
 // CanCommitToBranch returns true if repository is editable and user has proper access level
@@ -231,6 +252,12 @@ func RepoAssignment() macaron.Handler {
 				return
 			}
 			ctx.Repo.AccessMode = mode
+
+			ctx.Repo.UnitAccessModes, err = models.UnitAccessModes(userID, repo)
+			if err != nil {
+				ctx.Handle(500, "UnitAccessModes", err)
+				return
+			}
 		}
 
 		// Check access.
@@ -290,7 +317,15 @@ func RepoAssignment() macaron.Handler {
 		ctx.Data["WikiCloneLink"] = repo.WikiCloneLink()
 
 		if ctx.IsSigned {
-			ctx.Data["IsWatchingRepo"] = models.IsWatching(ctx.User.ID, repo.ID)
+			watch, err := models.GetWatch(ctx.User.ID, repo.ID)
+			if err != nil {
+				ctx.Handle(500, "GetWatch", err)
+				return
+			}
+			ctx.Data["IsWatchingRepo"] = watch != nil
+			if watch != nil {
+				ctx.Data["WatchMode"] = watch.Mode.String()
+			}
 			ctx.Data["IsStaringRepo"] = models.IsStaring(ctx.User.ID, repo.ID)
 		}
 
@@ -359,6 +394,31 @@ func RepoAssignment() macaron.Handler {
 	}
 }
 
+// redirectRenamedBranch redirects to the new name of the branch the path
+// starts with, if it was renamed. It returns false if no branch was.
+func redirectRenamedBranch(ctx *Context, parts []string) bool {
+	refName := ""
+	for i, part := range parts {
+		refName = strings.TrimPrefix(refName+"/"+part, "/")
+		renamed, err := models.GetRenamedBranch(ctx.Repo.Repository.ID, refName)
+		if err != nil {
+			ctx.Handle(500, "GetRenamedBranch", err)
+			return true
+		} else if renamed == nil || !ctx.Repo.GitRepo.IsBranchExist(renamed.NewName) {
+			continue
+		}
+
+		link := strings.TrimSuffix(ctx.Req.URL.Path, ctx.Params("*")) +
+			path.Join(append([]string{renamed.NewName}, parts[i+1:]...)...)
+		if len(ctx.Req.URL.RawQuery) > 0 {
+			link += "?" + ctx.Req.URL.RawQuery
+		}
+		ctx.Redirect(setting.AppSubURL + link)
+		return true
+	}
+	return false
+}
+
 // RepoRef handles repository reference name including those contain `/`.
 func RepoRef() macaron.Handler {
 	return func(ctx *Context) {
@@ -449,6 +509,9 @@ func RepoRef() macaron.Handler {
 					return
 				}
 			} else {
+				if redirectRenamedBranch(ctx, parts) {
+					return
+				}
 				ctx.Handle(404, "RepoRef invalid repo", fmt.Errorf("branch or tag not exist: %s", refName))
 				return
 			}
@@ -491,6 +554,28 @@ func RequireRepoWriter() macaron.Handler {
 	}
 }
 
+// RequireRepoUnitWriter returns a macaron middleware for requiring write access
+// to given unit of the repository.
+func RequireRepoUnitWriter(unitType models.UnitType) macaron.Handler {
+	return func(ctx *Context) {
+		if !ctx.IsSigned || (!ctx.Repo.CanWrite(unitType) && !ctx.User.IsAdmin) {
+			ctx.Handle(404, ctx.Req.RequestURI, nil)
+			return
+		}
+	}
+}
+
+// RequireRepoUnitTriager returns a macaron middleware for requiring triage access
+// to given unit of the repository.
+func RequireRepoUnitTriager(unitType models.UnitType) macaron.Handler {
+	return func(ctx *Context) {
+		if !ctx.IsSigned || (!ctx.Repo.CanTriage(unitType) && !ctx.User.IsAdmin) {
+			ctx.Handle(404, ctx.Req.RequestURI, nil)
+			return
+		}
+	}
+}
+
 // LoadRepoUnits loads repsitory's units, it should be called after repository and user loaded
 func LoadRepoUnits() macaron.Handler {
 	return func(ctx *Context) {
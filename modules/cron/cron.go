@@ -11,6 +11,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/session"
 	"code.gitea.io/gitea/modules/setting"
 )
 
@@ -66,6 +67,28 @@ func NewContext() {
 			go models.DeleteOldRepositoryArchives()
 		}
 	}
+	if setting.Cron.AccessLogCleanup.Enabled {
+		entry, err = c.AddFunc("Clean up old repository access logs", setting.Cron.AccessLogCleanup.Schedule, models.DeleteOldRepoAccessLogs)
+		if err != nil {
+			log.Fatal(4, "Cron[Clean up old repository access logs]: %v", err)
+		}
+		if setting.Cron.AccessLogCleanup.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go models.DeleteOldRepoAccessLogs()
+		}
+	}
+	if setting.Cron.DeadlineReminder.Enabled {
+		entry, err = c.AddFunc("Send issue deadline reminders", setting.Cron.DeadlineReminder.Schedule, models.SendDeadlineReminders)
+		if err != nil {
+			log.Fatal(4, "Cron[Send issue deadline reminders]: %v", err)
+		}
+		if setting.Cron.DeadlineReminder.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go models.SendDeadlineReminders()
+		}
+	}
 	if setting.Cron.SyncExternalUsers.Enabled {
 		entry, err = c.AddFunc("Synchronize external users", setting.Cron.SyncExternalUsers.Schedule, models.SyncExternalUsers)
 		if err != nil {
@@ -77,6 +100,72 @@ func NewContext() {
 			go models.SyncExternalUsers()
 		}
 	}
+	if setting.Cron.SessionCleanup.Enabled {
+		entry, err = c.AddFunc("Delete expired sessions", setting.Cron.SessionCleanup.Schedule, session.DeleteExpired)
+		if err != nil {
+			log.Fatal(4, "Cron[Delete expired sessions]: %v", err)
+		}
+		if setting.Cron.SessionCleanup.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go session.DeleteExpired()
+		}
+	}
+	if setting.Cron.UploadSessionCleanup.Enabled {
+		entry, err = c.AddFunc("Delete abandoned upload sessions", setting.Cron.UploadSessionCleanup.Schedule, models.DeleteAbandonedUploadSessions)
+		if err != nil {
+			log.Fatal(4, "Cron[Delete abandoned upload sessions]: %v", err)
+		}
+		if setting.Cron.UploadSessionCleanup.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go models.DeleteAbandonedUploadSessions()
+		}
+	}
+	if setting.Cron.AttachmentCleanup.Enabled {
+		entry, err = c.AddFunc("Delete unreferenced attachments", setting.Cron.AttachmentCleanup.Schedule, models.DeleteUnreferencedAttachments)
+		if err != nil {
+			log.Fatal(4, "Cron[Delete unreferenced attachments]: %v", err)
+		}
+		if setting.Cron.AttachmentCleanup.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go models.DeleteUnreferencedAttachments()
+		}
+	}
+	if setting.Cron.ActionCleanup.Enabled {
+		entry, err = c.AddFunc("Delete old actions", setting.Cron.ActionCleanup.Schedule, models.DeleteOldActions)
+		if err != nil {
+			log.Fatal(4, "Cron[Delete old actions]: %v", err)
+		}
+		if setting.Cron.ActionCleanup.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go models.DeleteOldActions()
+		}
+	}
+	if setting.Cron.PurgeDeleted.Enabled {
+		entry, err = c.AddFunc("Purge deleted repositories and users", setting.Cron.PurgeDeleted.Schedule, models.PurgeDeletedRepositoriesAndUsers)
+		if err != nil {
+			log.Fatal(4, "Cron[Purge deleted repositories and users]: %v", err)
+		}
+		if setting.Cron.PurgeDeleted.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go models.PurgeDeletedRepositoriesAndUsers()
+		}
+	}
+	if setting.Cron.UserExportCleanup.Enabled {
+		entry, err = c.AddFunc("Delete expired user data exports", setting.Cron.UserExportCleanup.Schedule, models.DeleteExpiredUserExports)
+		if err != nil {
+			log.Fatal(4, "Cron[Delete expired user data exports]: %v", err)
+		}
+		if setting.Cron.UserExportCleanup.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go models.DeleteExpiredUserExports()
+		}
+	}
 	c.Start()
 }
 
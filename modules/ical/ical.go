@@ -0,0 +1,96 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ical
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"time"
+)
+
+// maxLineLength is the number of octets after which lines are folded (RFC 5545 3.1).
+const maxLineLength = 75
+
+// Event represents an all-day event of a calendar.
+type Event struct {
+	UID         string
+	Date        time.Time
+	Summary     string
+	Description string
+	URL         string
+	Updated     time.Time
+}
+
+// Calendar represents an iCalendar object holding events.
+type Calendar struct {
+	ProductID string
+	Name      string
+	Events    []*Event
+}
+
+var escaper = strings.NewReplacer(
+	`\`, `\\`,
+	`;`, `\;`,
+	`,`, `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+// escape escapes a text value.
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// writeLine writes a content line, folded to lines of at most
+// maxLineLength octets without splitting UTF-8 sequences.
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := maxLineLength
+	for len(line) > limit {
+		n := limit
+		// Do not split in the middle of a multibyte character.
+		for n > 0 && line[n]&0xC0 == 0x80 {
+			n--
+		}
+		buf.WriteString(line[:n])
+		buf.WriteString("\r\n ")
+		line = line[n:]
+		// Continuation lines start with a space.
+		limit = maxLineLength - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+// Encode writes the calendar in iCalendar format.
+func (c *Calendar) Encode(w io.Writer) error {
+	var buf bytes.Buffer
+	writeLine(&buf, "BEGIN:VCALENDAR")
+	writeLine(&buf, "VERSION:2.0")
+	writeLine(&buf, "PRODID:"+escape(c.ProductID))
+	writeLine(&buf, "CALSCALE:GREGORIAN")
+	writeLine(&buf, "METHOD:PUBLISH")
+	if len(c.Name) > 0 {
+		writeLine(&buf, "X-WR-CALNAME:"+escape(c.Name))
+	}
+	for _, e := range c.Events {
+		writeLine(&buf, "BEGIN:VEVENT")
+		writeLine(&buf, "UID:"+escape(e.UID))
+		writeLine(&buf, "DTSTAMP:"+e.Updated.UTC().Format("20060102T150405Z"))
+		writeLine(&buf, "DTSTART;VALUE=DATE:"+e.Date.Format("20060102"))
+		writeLine(&buf, "DTEND;VALUE=DATE:"+e.Date.AddDate(0, 0, 1).Format("20060102"))
+		writeLine(&buf, "SUMMARY:"+escape(e.Summary))
+		if len(e.Description) > 0 {
+			writeLine(&buf, "DESCRIPTION:"+escape(e.Description))
+		}
+		if len(e.URL) > 0 {
+			writeLine(&buf, "URL:"+e.URL)
+		}
+		writeLine(&buf, "END:VEVENT")
+	}
+	writeLine(&buf, "END:VCALENDAR")
+	_, err := buf.WriteTo(w)
+	return err
+}
@@ -0,0 +1,76 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `a\, b\; c\\d\ne`, escape("a, b; c\\d\ne"))
+}
+
+func TestWriteLine(t *testing.T) {
+	var buf bytes.Buffer
+	writeLine(&buf, "SUMMARY:short")
+	assert.Equal(t, "SUMMARY:short\r\n", buf.String())
+
+	buf.Reset()
+	line := "SUMMARY:" + strings.Repeat("x", 100)
+	writeLine(&buf, line)
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	if assert.Len(t, lines, 2) {
+		assert.Len(t, lines[0], maxLineLength)
+		assert.True(t, strings.HasPrefix(lines[1], " "))
+		assert.Equal(t, line, lines[0]+lines[1][1:])
+	}
+
+	// Multibyte characters are not split.
+	buf.Reset()
+	writeLine(&buf, "SUMMARY:"+strings.Repeat("é", 50))
+	for _, l := range strings.Split(buf.String(), "\r\n") {
+		assert.True(t, len(l) <= maxLineLength)
+		assert.NotContains(t, l, "�")
+	}
+	assert.Equal(t, "SUMMARY:"+strings.Repeat("é", 50), strings.Replace(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n ", "", -1))
+}
+
+func TestCalendar_Encode(t *testing.T) {
+	date := time.Date(2017, 5, 4, 0, 0, 0, 0, time.UTC)
+	c := &Calendar{
+		ProductID: "-//Gitea//Gitea//EN",
+		Name:      "user2",
+		Events: []*Event{{
+			UID:     "issue-1@localhost",
+			Date:    date,
+			Summary: "repo1#1: issue1",
+			URL:     "http://localhost/user2/repo1/issues/1",
+			Updated: date,
+		}},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, c.Encode(&buf))
+	assert.Equal(t, "BEGIN:VCALENDAR\r\n"+
+		"VERSION:2.0\r\n"+
+		"PRODID:-//Gitea//Gitea//EN\r\n"+
+		"CALSCALE:GREGORIAN\r\n"+
+		"METHOD:PUBLISH\r\n"+
+		"X-WR-CALNAME:user2\r\n"+
+		"BEGIN:VEVENT\r\n"+
+		"UID:issue-1@localhost\r\n"+
+		"DTSTAMP:20170504T000000Z\r\n"+
+		"DTSTART;VALUE=DATE:20170504\r\n"+
+		"DTEND;VALUE=DATE:20170505\r\n"+
+		"SUMMARY:repo1#1: issue1\r\n"+
+		"URL:http://localhost/user2/repo1/issues/1\r\n"+
+		"END:VEVENT\r\n"+
+		"END:VCALENDAR\r\n", buf.String())
+}
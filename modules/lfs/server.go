@@ -491,7 +491,7 @@ func authenticate(ctx *context.Context, repository *models.Repository, authoriza
 	user, password := cs[:i], cs[i+1:]
 
 	userModel, err := models.GetUserByName(user)
-	if err != nil {
+	if err != nil || userModel.IsSuspended {
 		return false
 	}
 
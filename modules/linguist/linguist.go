@@ -0,0 +1,203 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package linguist
+
+import (
+	"path"
+	"strings"
+)
+
+var (
+	// File names which do not tell the language by their extension.
+	languageFileNames = map[string]string{
+		"dockerfile":  "Dockerfile",
+		"makefile":    "Makefile",
+		"gnumakefile": "Makefile",
+		"rakefile":    "Ruby",
+		"gemfile":     "Ruby",
+		"vagrantfile": "Ruby",
+	}
+
+	// Extensions of recognized languages, data and prose files are left out.
+	languageExts = map[string]string{
+		".as":     "ActionScript",
+		".asm":    "Assembly",
+		".s":      "Assembly",
+		".sh":     "Shell",
+		".bash":   "Shell",
+		".zsh":    "Shell",
+		".bat":    "Batchfile",
+		".cmd":    "Batchfile",
+		".c":      "C",
+		".h":      "C",
+		".cc":     "C++",
+		".cpp":    "C++",
+		".cxx":    "C++",
+		".hh":     "C++",
+		".hpp":    "C++",
+		".cs":     "C#",
+		".clj":    "Clojure",
+		".cmake":  "CMake",
+		".coffee": "CoffeeScript",
+		".css":    "CSS",
+		".d":      "D",
+		".dart":   "Dart",
+		".ex":     "Elixir",
+		".exs":    "Elixir",
+		".elm":    "Elm",
+		".erl":    "Erlang",
+		".fs":     "F#",
+		".f90":    "Fortran",
+		".go":     "Go",
+		".groovy": "Groovy",
+		".hs":     "Haskell",
+		".htm":    "HTML",
+		".html":   "HTML",
+		".tmpl":   "HTML",
+		".java":   "Java",
+		".js":     "JavaScript",
+		".jsx":    "JavaScript",
+		".jl":     "Julia",
+		".kt":     "Kotlin",
+		".less":   "Less",
+		".lisp":   "Common Lisp",
+		".lua":    "Lua",
+		".m":      "Objective-C",
+		".mm":     "Objective-C++",
+		".ml":     "OCaml",
+		".pas":    "Pascal",
+		".pl":     "Perl",
+		".pm":     "Perl",
+		".php":    "PHP",
+		".ps1":    "PowerShell",
+		".py":     "Python",
+		".r":      "R",
+		".rb":     "Ruby",
+		".rs":     "Rust",
+		".sass":   "Sass",
+		".scala":  "Scala",
+		".scss":   "SCSS",
+		".sql":    "SQL",
+		".swift":  "Swift",
+		".tcl":    "Tcl",
+		".tex":    "TeX",
+		".ts":     "TypeScript",
+		".tsx":    "TypeScript",
+		".vb":     "Visual Basic",
+		".vim":    "Vim script",
+		".vue":    "Vue",
+	}
+
+	// Colors of languages, same as the ones of GitHub linguist.
+	languageColors = map[string]string{
+		"ActionScript":  "#882b0f",
+		"Assembly":      "#6e4c13",
+		"Batchfile":     "#c1f12e",
+		"C":             "#555555",
+		"C#":            "#178600",
+		"C++":           "#f34b7d",
+		"Clojure":       "#db5855",
+		"CMake":         "#da3434",
+		"CoffeeScript":  "#244776",
+		"Common Lisp":   "#3fb68b",
+		"CSS":           "#563d7c",
+		"D":             "#ba595e",
+		"Dart":          "#00b4ab",
+		"Dockerfile":    "#384d54",
+		"Elixir":        "#6e4a7e",
+		"Elm":           "#60b5cc",
+		"Erlang":        "#b83998",
+		"F#":            "#b845fc",
+		"Fortran":       "#4d41b1",
+		"Go":            "#00add8",
+		"Groovy":        "#e69f56",
+		"Haskell":       "#5e5086",
+		"HTML":          "#e34c26",
+		"Java":          "#b07219",
+		"JavaScript":    "#f1e05a",
+		"Julia":         "#a270ba",
+		"Kotlin":        "#f18e33",
+		"Less":          "#1d365d",
+		"Lua":           "#000080",
+		"Makefile":      "#427819",
+		"Objective-C":   "#438eff",
+		"Objective-C++": "#6866fb",
+		"OCaml":         "#3be133",
+		"Pascal":        "#e3f171",
+		"Perl":          "#0298c3",
+		"PHP":           "#4f5d95",
+		"PowerShell":    "#012456",
+		"Python":        "#3572a5",
+		"R":             "#198ce7",
+		"Ruby":          "#701516",
+		"Rust":          "#dea584",
+		"Sass":          "#a53b70",
+		"Scala":         "#c22d40",
+		"SCSS":          "#c6538c",
+		"Shell":         "#89e051",
+		"SQL":           "#e38c00",
+		"Swift":         "#ffac45",
+		"Tcl":           "#e4cc98",
+		"TeX":           "#3d6117",
+		"TypeScript":    "#2b7489",
+		"Visual Basic":  "#945db7",
+		"Vim script":    "#199f4b",
+		"Vue":           "#2c3e50",
+	}
+
+	// Path prefixes of vendored and generated files.
+	vendorPrefixes = []string{
+		"vendor/",
+		"node_modules/",
+		"bower_components/",
+		"third_party/",
+		"Godeps/",
+	}
+
+	// File name suffixes of minified and generated files.
+	vendorSuffixes = []string{
+		".min.js",
+		".min.css",
+		".pb.go",
+		"-min.js",
+	}
+)
+
+// DefaultColor is the color of languages which have no known color.
+const DefaultColor = "#cccccc"
+
+// IsVendor returns true if given path is a vendored or generated file,
+// which should not be counted in language statistics.
+func IsVendor(filePath string) bool {
+	for _, prefix := range vendorPrefixes {
+		if strings.HasPrefix(filePath, prefix) || strings.Contains(filePath, "/"+prefix) {
+			return true
+		}
+	}
+	for _, suffix := range vendorSuffixes {
+		if strings.HasSuffix(filePath, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectLanguage returns the language of given file by its name,
+// or an empty string if the language is unknown.
+func DetectLanguage(filePath string) string {
+	name := strings.ToLower(path.Base(filePath))
+	if lang, ok := languageFileNames[name]; ok {
+		return lang
+	}
+	return languageExts[path.Ext(name)]
+}
+
+// LanguageColor returns the color of given language.
+func LanguageColor(lang string) string {
+	if color, ok := languageColors[lang]; ok {
+		return color
+	}
+	return DefaultColor
+}
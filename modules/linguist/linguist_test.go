@@ -0,0 +1,33 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package linguist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	assert.Equal(t, "Go", DetectLanguage("models/repo.go"))
+	assert.Equal(t, "JavaScript", DetectLanguage("public/js/index.JS"))
+	assert.Equal(t, "Makefile", DetectLanguage("Makefile"))
+	assert.Equal(t, "Dockerfile", DetectLanguage("docker/Dockerfile"))
+	assert.Equal(t, "", DetectLanguage("README.md"))
+	assert.Equal(t, "", DetectLanguage("LICENSE"))
+}
+
+func TestIsVendor(t *testing.T) {
+	assert.True(t, IsVendor("vendor/github.com/lib/lib.go"))
+	assert.True(t, IsVendor("web/node_modules/jquery/jquery.js"))
+	assert.True(t, IsVendor("public/js/jquery.min.js"))
+	assert.False(t, IsVendor("models/vendor.go"))
+	assert.False(t, IsVendor("public/js/index.js"))
+}
+
+func TestLanguageColor(t *testing.T) {
+	assert.Equal(t, "#00add8", LanguageColor("Go"))
+	assert.Equal(t, DefaultColor, LanguageColor("Unknown"))
+}
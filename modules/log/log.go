@@ -30,6 +30,8 @@ func NewLogger(bufLen int64, mode, config string) {
 		if l.adapter == mode {
 			isExist = true
 			loggers[i] = logger
+			// Release the outputs of the replaced logger, e.g. open log files.
+			defer l.Close()
 		}
 	}
 	if !isExist {
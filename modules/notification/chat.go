@@ -0,0 +1,152 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package notification
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"time"
+
+	gouuid "github.com/satori/go.uuid"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/httplib"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ChatMessage is a notification about an issue or a pull request sent to chat channels.
+type ChatMessage struct {
+	Text string // Plain text without the link
+	Link string
+}
+
+// ChatSender delivers chat messages to notification channels of one type.
+type ChatSender interface {
+	Send(channel *models.NotificationChannel, msg *ChatMessage) error
+}
+
+var chatSenders = map[models.NotificationChannelType]ChatSender{
+	models.NotificationChannelMatrix:     matrixSender{},
+	models.NotificationChannelRocketChat: rocketChatSender{},
+}
+
+// RegisterChatSender sets the sender of notification channels of given type.
+func RegisterChatSender(t models.NotificationChannelType, sender ChatSender) {
+	chatSenders[t] = sender
+}
+
+// chatNotifier delivers notifications to the chat integrations of
+// repositories and of users who are notified.
+type chatNotifier struct{}
+
+func (chatNotifier) NotifyIssue(issue *models.Issue, notificationAuthorID int64, event models.NotificationEvent) error {
+	channels, err := models.GetIssueNotificationChannels(issue, notificationAuthorID, event)
+	if err != nil {
+		return fmt.Errorf("GetIssueNotificationChannels: %v", err)
+	} else if len(channels) == 0 {
+		return nil
+	}
+
+	if err = issue.LoadAttributes(); err != nil {
+		return fmt.Errorf("LoadAttributes: %v", err)
+	}
+	doer, err := models.GetUserByID(notificationAuthorID)
+	if err != nil {
+		doer = models.NewGhostUser()
+	}
+	msg := newChatMessage(issue, doer, event)
+
+	for _, c := range channels {
+		sender, ok := chatSenders[c.Type]
+		if !ok {
+			continue
+		}
+		if err = sender.Send(c, msg); err != nil {
+			log.Error(4, "Send notification to channel [%d]: %v", c.ID, err)
+		}
+	}
+	return nil
+}
+
+func newChatMessage(issue *models.Issue, doer *models.User, event models.NotificationEvent) *ChatMessage {
+	kind := "issue"
+	if issue.IsPull {
+		kind = "pull request"
+	}
+
+	var action string
+	switch event {
+	case models.NotificationEventIssue, models.NotificationEventPullRequest:
+		action = "opened " + kind
+	case models.NotificationEventComment:
+		action = "commented on " + kind
+	case models.NotificationEventStatus:
+		switch {
+		case issue.IsPull && issue.PullRequest != nil && issue.PullRequest.HasMerged:
+			action = "merged " + kind
+		case issue.IsClosed:
+			action = "closed " + kind
+		default:
+			action = "reopened " + kind
+		}
+	}
+
+	return &ChatMessage{
+		Text: fmt.Sprintf("[%s] %s %s #%d: %s", issue.Repo.FullName(), doer.Name, action, issue.Index, issue.Title),
+		Link: issue.HTMLURL(),
+	}
+}
+
+func chatRequest(req *httplib.Request) *httplib.Request {
+	timeout := time.Duration(setting.Webhook.DeliverTimeout) * time.Second
+	return req.SetTimeout(timeout, timeout).
+		Header("Content-Type", "application/json").
+		SetTLSClientConfig(&tls.Config{InsecureSkipVerify: setting.Webhook.SkipTLSVerify})
+}
+
+func sendChatRequest(req *httplib.Request, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := req.Body(data).Response()
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+	return nil
+}
+
+// matrixSender posts messages to a Matrix room through the client-server API.
+type matrixSender struct{}
+
+func (matrixSender) Send(c *models.NotificationChannel, msg *ChatMessage) error {
+	link := fmt.Sprintf("%s/_matrix/client/r0/rooms/%s/send/m.room.message/%s?access_token=%s",
+		c.URL, url.PathEscape(c.RoomID), gouuid.NewV4().String(), url.QueryEscape(c.Token))
+	return sendChatRequest(chatRequest(httplib.Put(link)), map[string]string{
+		"msgtype":        "m.notice",
+		"body":           msg.Text + " " + msg.Link,
+		"format":         "org.matrix.custom.html",
+		"formatted_body": fmt.Sprintf(`%s <a href="%s">%s</a>`, html.EscapeString(msg.Text), html.EscapeString(msg.Link), html.EscapeString(msg.Link)),
+	})
+}
+
+// rocketChatSender posts messages to an incoming webhook of Rocket.Chat.
+type rocketChatSender struct{}
+
+func (rocketChatSender) Send(c *models.NotificationChannel, msg *ChatMessage) error {
+	return sendChatRequest(chatRequest(httplib.Post(c.URL)), map[string]string{
+		"text": fmt.Sprintf("%s [%s](%s)", msg.Text, msg.Link, msg.Link),
+	})
+}
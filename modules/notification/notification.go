@@ -9,14 +9,21 @@ import (
 	"code.gitea.io/gitea/modules/log"
 )
 
+// Notifier delivers notifications about activity on issues and pull requests.
+type Notifier interface {
+	NotifyIssue(issue *models.Issue, notificationAuthorID int64, event models.NotificationEvent) error
+}
+
 type (
 	notificationService struct {
 		issueQueue chan issueNotificationOpts
+		notifiers  []Notifier
 	}
 
 	issueNotificationOpts struct {
 		issue                *models.Issue
 		notificationAuthorID int64
+		event                models.NotificationEvent
 	}
 )
 
@@ -28,23 +35,41 @@ var (
 )
 
 func init() {
+	RegisterNotifier(webNotifier{})
+	RegisterNotifier(chatNotifier{})
 	go Service.Run()
 }
 
+// RegisterNotifier adds a notifier all notifications are delivered to,
+// it must be called before the service starts delivering notifications.
+func RegisterNotifier(n Notifier) {
+	Service.notifiers = append(Service.notifiers, n)
+}
+
 func (ns *notificationService) Run() {
 	for {
 		select {
 		case opts := <-ns.issueQueue:
-			if err := models.CreateOrUpdateIssueNotifications(opts.issue, opts.notificationAuthorID); err != nil {
-				log.Error(4, "Was unable to create issue notification: %v", err)
+			for _, n := range ns.notifiers {
+				if err := n.NotifyIssue(opts.issue, opts.notificationAuthorID, opts.event); err != nil {
+					log.Error(4, "Was unable to create issue notification: %v", err)
+				}
 			}
 		}
 	}
 }
 
-func (ns *notificationService) NotifyIssue(issue *models.Issue, notificationAuthorID int64) {
+func (ns *notificationService) NotifyIssue(issue *models.Issue, notificationAuthorID int64, event models.NotificationEvent) {
 	ns.issueQueue <- issueNotificationOpts{
 		issue,
 		notificationAuthorID,
+		event,
 	}
 }
+
+// webNotifier creates the notifications shown on the web interface.
+type webNotifier struct{}
+
+func (webNotifier) NotifyIssue(issue *models.Issue, notificationAuthorID int64, _ models.NotificationEvent) error {
+	return models.CreateOrUpdateIssueNotifications(issue, notificationAuthorID)
+}
@@ -0,0 +1,267 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// MaxRequestSize is the size of the largest upload-pack request whose response
+// is cached, the requests of clones only list the wanted commits.
+const MaxRequestSize = 64 * 1024
+
+// Stats is the usage of the cache since the server started, and its content.
+type Stats struct {
+	Hits    int64
+	Misses  int64
+	Entries int
+	Size    int64
+}
+
+// HitRate returns the percentage of the requests served from the cache.
+func (s *Stats) HitRate() int64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return s.Hits * 100 / (s.Hits + s.Misses)
+}
+
+// Cache stores the responses of git upload-pack in files, so that the clones
+// asking for the same commits reuse them. The responses only depend on the
+// request and the objects of the repository, which never change, so they can
+// be reused until they are removed to keep the cache under its max size.
+type Cache struct {
+	Dir     string
+	MaxSize int64
+	TTL     time.Duration
+
+	hits   int64
+	misses int64
+	lock   sync.Mutex
+}
+
+// NewCache initializes and returns a new Cache keeping at most maxSize bytes
+// of responses in dir, each for ttl after it was last used.
+func NewCache(dir string, maxSize int64, ttl time.Duration) *Cache {
+	return &Cache{
+		Dir:     dir,
+		MaxSize: maxSize,
+		TTL:     ttl,
+	}
+}
+
+var defaultCache *Cache
+
+// NewContext initializes the cache when it is enabled.
+func NewContext() {
+	if !setting.Git.PackCache.Enabled {
+		return
+	}
+	defaultCache = NewCache(setting.Git.PackCache.Path, setting.Git.PackCache.MaxSize*1024*1024, setting.Git.PackCache.TTL)
+	if err := os.MkdirAll(defaultCache.tmpDir(), os.ModePerm); err != nil {
+		log.Fatal(4, "Failed to create pack cache directory '%s': %v", defaultCache.Dir, err)
+	}
+}
+
+// Default returns the cache of the server, or nil when it is disabled.
+func Default() *Cache {
+	return defaultCache
+}
+
+// Key returns the key of the response to the upload-pack request made to the
+// repository at given path.
+func Key(repoPath string, request []byte) string {
+	h := sha256.New()
+	h.Write([]byte(repoPath))
+	h.Write([]byte{0})
+	h.Write(request)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// IsClone returns true if the upload-pack request does not tell any commit
+// the client has, only those requests are worth caching.
+func IsClone(request []byte) bool {
+	for len(request) >= 4 {
+		size, err := strconv.ParseUint(string(request[:4]), 16, 16)
+		if err != nil {
+			return false
+		} else if size == 0 {
+			request = request[4:]
+			continue
+		} else if size < 4 || int(size) > len(request) {
+			return false
+		}
+		if bytes.HasPrefix(request[4:size], []byte("have ")) {
+			return false
+		}
+		request = request[size:]
+	}
+	return len(request) == 0
+}
+
+func (c *Cache) tmpDir() string {
+	return filepath.Join(c.Dir, "tmp")
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, key[:2], key)
+}
+
+// Get returns the cached response of given key, or nil if there is none.
+func (c *Cache) Get(key string) *os.File {
+	p := c.path(key)
+	fi, err := os.Stat(p)
+	if err != nil || time.Since(fi.ModTime()) > c.TTL {
+		atomic.AddInt64(&c.misses, 1)
+		return nil
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		atomic.AddInt64(&c.misses, 1)
+		return nil
+	}
+	now := time.Now()
+	os.Chtimes(p, now, now)
+	atomic.AddInt64(&c.hits, 1)
+	return f
+}
+
+// Entry is a response being written to the cache, it can only be read once
+// committed.
+type Entry struct {
+	c   *Cache
+	key string
+	f   *os.File
+	err error
+}
+
+// Create returns a new entry to write the response of given key to.
+func (c *Cache) Create(key string) (*Entry, error) {
+	f, err := ioutil.TempFile(c.tmpDir(), key)
+	if err != nil {
+		return nil, err
+	}
+	return &Entry{c: c, key: key, f: f}, nil
+}
+
+// Write writes p to the entry. It never fails so that the response is still
+// sent to the client when it can't be cached, the error is returned by Commit.
+func (e *Entry) Write(p []byte) (int, error) {
+	if e.err == nil {
+		_, e.err = e.f.Write(p)
+	}
+	return len(p), nil
+}
+
+// Commit adds the entry to the cache, and removes the least recently used
+// entries if the cache is too large.
+func (e *Entry) Commit() error {
+	if err := e.f.Close(); err != nil && e.err == nil {
+		e.err = err
+	}
+	if e.err != nil {
+		os.Remove(e.f.Name())
+		return e.err
+	}
+
+	p := e.c.path(e.key)
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		os.Remove(e.f.Name())
+		return err
+	} else if err = os.Rename(e.f.Name(), p); err != nil {
+		os.Remove(e.f.Name())
+		return err
+	}
+	return e.c.evict()
+}
+
+// Abort discards the entry.
+func (e *Entry) Abort() {
+	e.f.Close()
+	os.Remove(e.f.Name())
+}
+
+type cacheFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// files returns the entries of the cache, the least recently used first.
+func (c *Cache) files() ([]cacheFile, error) {
+	files := make([]cacheFile, 0, 10)
+	err := filepath.Walk(c.Dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		} else if fi.IsDir() {
+			if p == c.tmpDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		files = append(files, cacheFile{p, fi.Size(), fi.ModTime()})
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	return files, err
+}
+
+// evict removes the expired entries, and the least recently used ones until
+// the cache is no larger than its max size.
+func (c *Cache) evict() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	files, err := c.files()
+	if err != nil {
+		return err
+	}
+	var size int64
+	for _, f := range files {
+		size += f.size
+	}
+	for _, f := range files {
+		if size <= c.MaxSize && time.Since(f.modTime) <= c.TTL {
+			continue
+		}
+		if err = os.Remove(f.path); err != nil {
+			return err
+		}
+		size -= f.size
+	}
+	return nil
+}
+
+// Stats returns the usage and the content of the cache.
+func (c *Cache) Stats() (*Stats, error) {
+	files, err := c.files()
+	if err != nil {
+		return nil, err
+	}
+	stats := &Stats{
+		Hits:    atomic.LoadInt64(&c.hits),
+		Misses:  atomic.LoadInt64(&c.misses),
+		Entries: len(files),
+	}
+	for _, f := range files {
+		stats.Size += f.size
+	}
+	return stats, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packcache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsClone(t *testing.T) {
+	assert.True(t, IsClone([]byte("0032want 0000000000000000000000000000000000000001\n00000009done\n")))
+	assert.False(t, IsClone([]byte("0032want 0000000000000000000000000000000000000001\n00000032have 0000000000000000000000000000000000000002\n0009done\n")))
+	assert.False(t, IsClone([]byte("0032want")))
+	assert.False(t, IsClone([]byte("zzzz")))
+}
+
+func newTestCache(t *testing.T, maxSize int64) *Cache {
+	dir, err := ioutil.TempDir("", "packcache")
+	assert.NoError(t, err)
+	c := NewCache(dir, maxSize, time.Hour)
+	assert.NoError(t, os.MkdirAll(c.tmpDir(), os.ModePerm))
+	return c
+}
+
+func put(t *testing.T, c *Cache, key, content string) {
+	e, err := c.Create(key)
+	assert.NoError(t, err)
+	e.Write([]byte(content))
+	assert.NoError(t, e.Commit())
+}
+
+func TestCache(t *testing.T) {
+	c := newTestCache(t, 1024)
+	defer os.RemoveAll(c.Dir)
+
+	key := Key("/repos/user/repo.git", []byte("request"))
+	assert.Nil(t, c.Get(key))
+
+	put(t, c, key, "response")
+	f := c.Get(key)
+	if assert.NotNil(t, f) {
+		content, err := ioutil.ReadAll(f)
+		f.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, "response", string(content))
+	}
+
+	// Aborted entries are not added.
+	other := Key("/repos/user/repo.git", []byte("other request"))
+	e, err := c.Create(other)
+	assert.NoError(t, err)
+	e.Write([]byte("response"))
+	e.Abort()
+	assert.Nil(t, c.Get(other))
+
+	stats, err := c.Stats()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, stats.Hits)
+	assert.EqualValues(t, 2, stats.Misses)
+	assert.EqualValues(t, 33, stats.HitRate())
+	assert.Equal(t, 1, stats.Entries)
+	assert.EqualValues(t, len("response"), stats.Size)
+}
+
+func TestCache_Evict(t *testing.T) {
+	c := newTestCache(t, 10)
+	defer os.RemoveAll(c.Dir)
+
+	first, second := Key("", []byte("1")), Key("", []byte("2"))
+	put(t, c, first, "123456")
+	old := time.Now().Add(-time.Minute)
+	assert.NoError(t, os.Chtimes(filepath.Join(c.Dir, first[:2], first), old, old))
+
+	// The least recently used entry is removed to make room.
+	put(t, c, second, "123456")
+	assert.Nil(t, c.Get(first))
+	f := c.Get(second)
+	if assert.NotNil(t, f) {
+		f.Close()
+	}
+}
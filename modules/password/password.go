@@ -0,0 +1,72 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// charClasses are the classes of characters a password can be required to
+// contain, see setting.PasswordComplexity.
+var charClasses = map[string]func(rune) bool{
+	"lower": unicode.IsLower,
+	"upper": unicode.IsUpper,
+	"digit": unicode.IsDigit,
+	"spec": func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r)
+	},
+}
+
+// IsComplexEnough returns true if the password contains at least one
+// character of every required class.
+func IsComplexEnough(pwd string) bool {
+	for _, class := range setting.PasswordComplexity {
+		if strings.IndexFunc(pwd, charClasses[class]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsPwned returns true if the password is found in the dataset of breached
+// passwords, if there is one. The dataset is a directory of files named after
+// the first five hexadecimal characters of the SHA-1 hash of the passwords,
+// listing the remaining characters of the hashes like the responses of the
+// range API of Pwned Passwords, e.g. "0018A45C4D1DEF81644B54AB7F969B88D65:10".
+func IsPwned(pwd string) (bool, error) {
+	if len(setting.PasswordBreachedPath) == 0 {
+		return false, nil
+	}
+
+	hash := fmt.Sprintf("%X", sha1.Sum([]byte(pwd)))
+	f, err := os.Open(filepath.Join(setting.PasswordBreachedPath, hash[:5]+".txt"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			line = line[:i]
+		}
+		if strings.EqualFold(strings.TrimSpace(line), hash[5:]) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,60 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package password
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsComplexEnough(t *testing.T) {
+	defer func(classes []string) {
+		setting.PasswordComplexity = classes
+	}(setting.PasswordComplexity)
+
+	setting.PasswordComplexity = nil
+	assert.True(t, IsComplexEnough("password"))
+
+	setting.PasswordComplexity = []string{"lower", "upper", "digit", "spec"}
+	assert.False(t, IsComplexEnough("password"))
+	assert.False(t, IsComplexEnough("Password1"))
+	assert.False(t, IsComplexEnough("Pass word1"))
+	assert.True(t, IsComplexEnough("Pass-word1"))
+	assert.True(t, IsComplexEnough("Ünïcødé-1"))
+}
+
+func TestIsPwned(t *testing.T) {
+	defer func(path string) {
+		setting.PasswordBreachedPath = path
+	}(setting.PasswordBreachedPath)
+
+	setting.PasswordBreachedPath = ""
+	pwned, err := IsPwned("password")
+	assert.NoError(t, err)
+	assert.False(t, pwned)
+
+	dir, err := ioutil.TempDir("", "pwned")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	setting.PasswordBreachedPath = dir
+
+	// SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "5BAA6.txt"),
+		[]byte("003D68EB55068C33ACE09247EE4C639306B:3\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:3730471\r\n"), 0644))
+
+	pwned, err = IsPwned("password")
+	assert.NoError(t, err)
+	assert.True(t, pwned)
+
+	pwned, err = IsPwned("correct horse battery staple")
+	assert.NoError(t, err)
+	assert.False(t, pwned)
+}
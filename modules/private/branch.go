@@ -41,3 +41,31 @@ func GetProtectedBranchBy(repoID int64, branchName string) (*models.ProtectedBra
 
 	return &branch, nil
 }
+
+// GetRenamedBranch returns the renamed branch of given old name, or nil if no
+// branch of the repository was renamed from it.
+func GetRenamedBranch(repoID int64, oldName string) (*models.RenamedBranch, error) {
+	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/renamed_branch/%d/%s", repoID, oldName)
+	log.GitLogger.Trace("GetRenamedBranch: %s", reqURL)
+
+	resp, err := newRequest(reqURL, "GET").SetTLSClientConfig(&tls.Config{
+		InsecureSkipVerify: true,
+	}).Response()
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 {
+		return nil, nil
+	} else if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("Failed to get renamed branch: %s", decodeJSONError(resp).Err)
+	}
+
+	var branch models.RenamedBranch
+	if err = json.NewDecoder(resp.Body).Decode(&branch); err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
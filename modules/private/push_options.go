@@ -0,0 +1,57 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package private
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// HandlePushOptions applies the push options of a pushed branch, and returns
+// the messages to show to the pusher
+func HandlePushOptions(opt models.PushOptionsRequest) ([]string, error) {
+	return postPushRequest("api/internal/push/options", "HandlePushOptions", opt)
+}
+
+// HandleAgitPush opens or updates the pull request of commits pushed for
+// review, and returns the messages to show to the pusher
+func HandleAgitPush(opt models.PushOptionsRequest) ([]string, error) {
+	return postPushRequest("api/internal/push/agit", "HandleAgitPush", opt)
+}
+
+func postPushRequest(path, name string, opt models.PushOptionsRequest) ([]string, error) {
+	reqURL := setting.LocalURL + path
+	log.GitLogger.Trace("%s: %s", name, reqURL)
+
+	body, err := json.Marshal(&opt)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := newRequest(reqURL, "POST").Body(body).SetTLSClientConfig(&tls.Config{
+		InsecureSkipVerify: true,
+	}).Response()
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	// All 2XX status codes are accepted and others will return an error
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("Failed to handle push: %s", decodeJSONError(resp).Err)
+	}
+
+	var res models.PushOptionsResult
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+	return res.Messages, nil
+}
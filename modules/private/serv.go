@@ -0,0 +1,110 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package private
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ServCommandResults represents the repository and the user a serv command
+// is granted access to.
+type ServCommandResults struct {
+	KeyID       int64
+	IsDeployKey bool
+	UserID      int64
+	UserName    string
+	OwnerName   string
+	RepoID      int64
+	RepoName    string
+	IsWiki      bool
+	// ForReviewOnly is true when the user can only push commits for review.
+	ForReviewOnly bool
+	// IsCreated is true when the repository was created by the push.
+	IsCreated bool
+	IsPrivate bool
+	IsEmpty   bool
+}
+
+// ErrServCommand represents an access denied, or an internal error, to a
+// serv command. Err is the message shown to the user.
+type ErrServCommand struct {
+	Err        string
+	StatusCode int
+}
+
+// IsErrServCommand checks if an error is a ErrServCommand.
+func IsErrServCommand(err error) bool {
+	_, ok := err.(ErrServCommand)
+	return ok
+}
+
+func (err ErrServCommand) Error() string {
+	return err.Err
+}
+
+// ServCommand looks up the key and checks it has the requested access mode
+// to the repository for the command.
+func ServCommand(keyID int64, ownerName, repoName string, mode models.AccessMode, verb string) (*ServCommandResults, error) {
+	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/serv/command/%d/%s/%s?mode=%d&verb=%s",
+		keyID, url.PathEscape(ownerName), url.PathEscape(repoName), mode, url.QueryEscape(verb))
+	log.GitLogger.Trace("ServCommand: %s", reqURL)
+
+	resp, err := newRequest(reqURL, "GET").SetTLSClientConfig(&tls.Config{
+		InsecureSkipVerify: true,
+	}).Response()
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	// All 2XX status codes are accepted and others will return an error
+	if resp.StatusCode/100 != 2 {
+		return nil, ErrServCommand{
+			Err:        decodeJSONError(resp).Err,
+			StatusCode: resp.StatusCode,
+		}
+	}
+
+	var res ServCommandResults
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// LogAccess records an operation on a repository over SSH, or a push by the
+// hooks.
+func LogAccess(l *models.RepoAccessLog) error {
+	reqURL := setting.LocalURL + "api/internal/serv/access_log"
+	log.GitLogger.Trace("LogAccess: %s", reqURL)
+
+	body, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	resp, err := newRequest(reqURL, "POST").Body(body).SetTLSClientConfig(&tls.Config{
+		InsecureSkipVerify: true,
+	}).Response()
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	// All 2XX status codes are accepted and others will return an error
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("Failed to log access: %s", decodeJSONError(resp).Err)
+	}
+	return nil
+}
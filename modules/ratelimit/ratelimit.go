@@ -0,0 +1,73 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// counter is the number of requests a client made in a window of time.
+type counter struct {
+	count int
+	reset time.Time
+}
+
+// Limiter limits the number of requests each client can make in a window of
+// time, clients are identified by an arbitrary key.
+type Limiter struct {
+	Limit  int
+	Window time.Duration
+
+	lock      sync.Mutex
+	windows   map[string]*counter
+	lastSweep time.Time
+}
+
+// NewLimiter initializes and returns a new Limiter allowing limit requests
+// per window to each client.
+func NewLimiter(limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		Limit:     limit,
+		Window:    window,
+		windows:   make(map[string]*counter),
+		lastSweep: time.Now(),
+	}
+}
+
+// sweep forgets the windows which have ended, at most once per window.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.Window {
+		return
+	}
+	for key, w := range l.windows {
+		if !now.Before(w.reset) {
+			delete(l.windows, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// Take counts a request of the client with given key. It returns the number
+// of requests the client can still make in the current window, the time the
+// window ends, and false if the client has made too many requests already.
+func (l *Limiter) Take(key string) (remaining int, reset time.Time, ok bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	w, has := l.windows[key]
+	if !has || !now.Before(w.reset) {
+		w = &counter{reset: now.Add(l.Window)}
+		l.windows[key] = w
+	}
+	if w.count >= l.Limit {
+		return 0, w.reset, false
+	}
+	w.count++
+	return l.Limit - w.count, w.reset, true
+}
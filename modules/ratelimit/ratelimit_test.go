@@ -0,0 +1,48 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_Take(t *testing.T) {
+	l := NewLimiter(2, time.Hour)
+
+	remaining, reset, ok := l.Take("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, remaining)
+	assert.True(t, reset.After(time.Now()))
+
+	remaining, _, ok = l.Take("a")
+	assert.True(t, ok)
+	assert.Equal(t, 0, remaining)
+
+	remaining, _, ok = l.Take("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, remaining)
+
+	// Other clients have their own window.
+	remaining, _, ok = l.Take("b")
+	assert.True(t, ok)
+	assert.Equal(t, 1, remaining)
+}
+
+func TestLimiter_Window(t *testing.T) {
+	l := NewLimiter(1, 10*time.Millisecond)
+
+	_, _, ok := l.Take("a")
+	assert.True(t, ok)
+	_, _, ok = l.Take("a")
+	assert.False(t, ok)
+
+	time.Sleep(20 * time.Millisecond)
+	_, _, ok = l.Take("a")
+	assert.True(t, ok)
+	assert.Len(t, l.windows, 1)
+}
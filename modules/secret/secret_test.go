@@ -0,0 +1,27 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package secret
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptDecryptSecret(t *testing.T) {
+	encrypted, err := EncryptSecret("secret key", "user:password")
+	assert.NoError(t, err)
+	assert.NotContains(t, encrypted, "password")
+
+	text, err := DecryptSecret("secret key", encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "user:password", text)
+
+	_, err = DecryptSecret("another key", encrypted)
+	assert.Error(t, err)
+
+	_, err = DecryptSecret("secret key", "bm90IGVuY3J5cHRlZA==")
+	assert.Error(t, err)
+}
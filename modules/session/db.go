@@ -0,0 +1,155 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package session
+
+import (
+	"sync"
+
+	"github.com/go-macaron/session"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// DBStore represents a session store of the database session provider.
+type DBStore struct {
+	sid  string
+	lock sync.RWMutex
+	data map[interface{}]interface{}
+}
+
+// NewDBStore creates and returns a database session store.
+func NewDBStore(sid string, kv map[interface{}]interface{}) *DBStore {
+	return &DBStore{
+		sid:  sid,
+		data: kv,
+	}
+}
+
+// Set sets value to given key in session.
+func (s *DBStore) Set(key, val interface{}) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.data[key] = val
+	return nil
+}
+
+// Get gets value by given key in session.
+func (s *DBStore) Get(key interface{}) interface{} {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.data[key]
+}
+
+// Delete deletes a key from session.
+func (s *DBStore) Delete(key interface{}) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.data, key)
+	return nil
+}
+
+// ID returns current session ID.
+func (s *DBStore) ID() string {
+	return s.sid
+}
+
+// Release saves the session data to the database.
+func (s *DBStore) Release() error {
+	s.lock.RLock()
+	data, err := session.EncodeGob(s.data)
+	s.lock.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	return models.UpdateSession(s.sid, data)
+}
+
+// Flush deletes all session data.
+func (s *DBStore) Flush() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.data = make(map[interface{}]interface{})
+	return nil
+}
+
+// DBProvider represents a session provider which stores sessions in the
+// database of Gitea, so that no further service is needed.
+type DBProvider struct {
+	maxLifetime int64
+}
+
+// Init initializes the database session provider, it has no configuration.
+func (p *DBProvider) Init(maxLifetime int64, _ string) error {
+	p.maxLifetime = maxLifetime
+	return nil
+}
+
+func newDBStore(s *models.Session) (*DBStore, error) {
+	if len(s.Data) == 0 {
+		return NewDBStore(s.Key, make(map[interface{}]interface{})), nil
+	}
+
+	kv, err := session.DecodeGob(s.Data)
+	if err != nil {
+		return nil, err
+	}
+	return NewDBStore(s.Key, kv), nil
+}
+
+// Read returns raw session store by session ID.
+func (p *DBProvider) Read(sid string) (session.RawStore, error) {
+	s, err := models.ReadSession(sid)
+	if err != nil {
+		return nil, err
+	}
+	return newDBStore(s)
+}
+
+// Exist returns true if session with given ID exists.
+func (p *DBProvider) Exist(sid string) bool {
+	has, err := models.ExistSession(sid)
+	return err == nil && has
+}
+
+// Destory deletes a session by session ID.
+func (p *DBProvider) Destory(sid string) error {
+	return models.DestroySession(sid)
+}
+
+// Regenerate regenerates a session store from old session ID to new one.
+func (p *DBProvider) Regenerate(oldsid, sid string) (session.RawStore, error) {
+	s, err := models.RegenerateSession(oldsid, sid)
+	if err != nil {
+		return nil, err
+	}
+	return newDBStore(s)
+}
+
+// Count counts and returns number of sessions.
+func (p *DBProvider) Count() int {
+	count, err := models.CountSessions()
+	if err != nil {
+		log.Error(4, "CountSessions: %v", err)
+		return 0
+	}
+	return int(count)
+}
+
+// GC deletes the expired sessions.
+func (p *DBProvider) GC() {
+	if err := models.DeleteExpiredSessions(p.maxLifetime); err != nil {
+		log.Error(4, "DeleteExpiredSessions: %v", err)
+	}
+}
+
+func init() {
+	session.Register("db", &DBProvider{})
+}
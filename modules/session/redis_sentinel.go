@@ -0,0 +1,133 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Unknwon/com"
+	"github.com/go-macaron/session"
+	redisstore "github.com/go-macaron/session/redis"
+	"gopkg.in/redis.v2"
+)
+
+// RedisSentinelProvider represents a session provider which stores sessions
+// in Redis, connecting to the current master through Redis Sentinel.
+type RedisSentinelProvider struct {
+	c        *redis.Client
+	duration time.Duration
+	prefix   string
+}
+
+// Init initializes the Redis Sentinel session provider, sentinel addresses
+// are separated by spaces, e.g.
+// master_name=mymaster,sentinel_addrs=10.0.0.1:26379 10.0.0.2:26379,password=macaron,db=0,pool_size=100,idle_timeout=180,prefix=session:
+func (p *RedisSentinelProvider) Init(maxLifetime int64, configs string) (err error) {
+	p.duration = time.Duration(maxLifetime) * time.Second
+
+	opt := &redis.FailoverOptions{}
+	for _, config := range strings.Split(configs, ",") {
+		fields := strings.SplitN(config, "=", 2)
+		if len(fields) != 2 {
+			return fmt.Errorf("session/redis-sentinel: invalid option '%s'", config)
+		}
+
+		k, v := strings.TrimSpace(fields[0]), strings.TrimSpace(fields[1])
+		switch k {
+		case "master_name":
+			opt.MasterName = v
+		case "sentinel_addrs":
+			opt.SentinelAddrs = strings.Fields(v)
+		case "password":
+			opt.Password = v
+		case "db":
+			opt.DB = com.StrTo(v).MustInt64()
+		case "pool_size":
+			opt.PoolSize = com.StrTo(v).MustInt()
+		case "idle_timeout":
+			opt.IdleTimeout, err = time.ParseDuration(v + "s")
+			if err != nil {
+				return fmt.Errorf("session/redis-sentinel: error parsing idle timeout: %v", err)
+			}
+		case "prefix":
+			p.prefix = v
+		default:
+			return fmt.Errorf("session/redis-sentinel: unsupported option '%s'", k)
+		}
+	}
+	if len(opt.MasterName) == 0 || len(opt.SentinelAddrs) == 0 {
+		return fmt.Errorf("session/redis-sentinel: master_name and sentinel_addrs are required")
+	}
+
+	p.c = redis.NewFailoverClient(opt)
+	return p.c.Ping().Err()
+}
+
+func (p *RedisSentinelProvider) read(sid string) (session.RawStore, error) {
+	kvs, err := p.c.Get(p.prefix + sid).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	kv := make(map[interface{}]interface{})
+	if len(kvs) > 0 {
+		if kv, err = session.DecodeGob([]byte(kvs)); err != nil {
+			return nil, err
+		}
+	}
+	return redisstore.NewRedisStore(p.c, p.prefix, sid, p.duration, kv), nil
+}
+
+// Read returns raw session store by session ID.
+func (p *RedisSentinelProvider) Read(sid string) (session.RawStore, error) {
+	if !p.Exist(sid) {
+		if err := p.c.Set(p.prefix+sid, "").Err(); err != nil {
+			return nil, err
+		}
+	}
+	return p.read(sid)
+}
+
+// Exist returns true if session with given ID exists.
+func (p *RedisSentinelProvider) Exist(sid string) bool {
+	has, err := p.c.Exists(p.prefix + sid).Result()
+	return err == nil && has
+}
+
+// Destory deletes a session by session ID.
+func (p *RedisSentinelProvider) Destory(sid string) error {
+	return p.c.Del(p.prefix + sid).Err()
+}
+
+// Regenerate regenerates a session store from old session ID to new one.
+func (p *RedisSentinelProvider) Regenerate(oldsid, sid string) (session.RawStore, error) {
+	if p.Exist(sid) {
+		return nil, fmt.Errorf("new sid '%s' already exists", sid)
+	} else if !p.Exist(oldsid) {
+		// Make a fake old session.
+		if err := p.c.SetEx(p.prefix+oldsid, p.duration, "").Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.c.Rename(p.prefix+oldsid, p.prefix+sid).Err(); err != nil {
+		return nil, err
+	}
+	return p.read(sid)
+}
+
+// Count counts and returns number of sessions.
+func (p *RedisSentinelProvider) Count() int {
+	return int(p.c.DbSize().Val())
+}
+
+// GC does nothing, Redis expires sessions by itself.
+func (p *RedisSentinelProvider) GC() {}
+
+func init() {
+	session.Register("redis-sentinel", &RedisSentinelProvider{})
+}
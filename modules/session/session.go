@@ -0,0 +1,33 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package session
+
+import (
+	"github.com/go-macaron/session"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// DeleteExpired deletes the expired sessions of the database and file session
+// providers, other providers expire sessions by themselves.
+func DeleteExpired() {
+	switch setting.SessionConfig.Provider {
+	case "db", "file":
+		// Providers are shared with the session middleware, initializing
+		// them again with the same configuration does no harm.
+		manager, err := session.NewManager(setting.SessionConfig.Provider, setting.SessionConfig)
+		if err != nil {
+			log.Error(4, "NewManager: %v", err)
+			return
+		}
+		manager.GC()
+	}
+
+	if err := models.DeleteExpiredUserSessions(setting.SessionConfig.Maxlifetime); err != nil {
+		log.Error(4, "DeleteExpiredUserSessions: %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/Unknwon/com"
+	ini "gopkg.in/ini.v1"
+)
+
+var (
+	// loadedCfg is the custom configuration file as its settings are in effect,
+	// without the default values filled in by NewContext.
+	loadedCfg *ini.File
+
+	reloadLock sync.Mutex
+)
+
+// reloadableKeys lists the keys per section which can be changed without
+// restart, all keys of a section are reloadable if the list is empty.
+var reloadableKeys = map[string][]string{
+	"log": {"LEVEL"},
+	"mailer": {"NAME", "FROM", "SEND_AS_PLAIN_TEXT", "ENABLE_HTML_ALTERNATIVE",
+		"HOST", "USER", "PASSWD", "DISABLE_HELO", "HELO_HOSTNAME", "SKIP_VERIFY",
+		"USE_CERTIFICATE", "CERT_FILE", "KEY_FILE", "SENDMAIL_PATH"},
+	"webhook":  {"DELIVER_TIMEOUT", "SKIP_TLS_VERIFY", "PAGING_NUM"},
+	"ui":       nil,
+	"ui.admin": nil,
+	"ui.user":  nil,
+	"ui.meta":  nil,
+}
+
+func isReloadable(section, key string) bool {
+	if strings.HasPrefix(section, "log.") {
+		return key == "LEVEL"
+	}
+
+	keys, ok := reloadableKeys[section]
+	if !ok {
+		return false
+	}
+	return len(keys) == 0 || com.IsSliceContainsStr(keys, key)
+}
+
+func loadCustomConf() (*ini.File, error) {
+	cfg := ini.Empty()
+	if com.IsFile(CustomConf) {
+		if err := cfg.Append(CustomConf); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// changedKeys returns the section and key names of the keys which are added,
+// changed or removed in newCfg.
+func changedKeys(oldCfg, newCfg *ini.File) [][2]string {
+	var keys [][2]string
+	for _, sec := range newCfg.Sections() {
+		oldSec, _ := oldCfg.GetSection(sec.Name())
+		for _, key := range sec.Keys() {
+			if oldSec == nil || !oldSec.HasKey(key.Name()) || oldSec.Key(key.Name()).Value() != key.Value() {
+				keys = append(keys, [2]string{sec.Name(), key.Name()})
+			}
+		}
+	}
+	for _, sec := range oldCfg.Sections() {
+		newSec, _ := newCfg.GetSection(sec.Name())
+		for _, key := range sec.Keys() {
+			if newSec == nil || !newSec.HasKey(key.Name()) {
+				keys = append(keys, [2]string{sec.Name(), key.Name()})
+			}
+		}
+	}
+	return keys
+}
+
+// copyKey sets the key of cfg to its value in src, or deletes it if src
+// does not have the key.
+func copyKey(cfg, src *ini.File, section, key string) {
+	if srcSec, err := src.GetSection(section); err == nil && srcSec.HasKey(key) {
+		cfg.Section(section).Key(key).SetValue(srcSec.Key(key).Value())
+	} else if sec, err := cfg.GetSection(section); err == nil {
+		sec.DeleteKey(key)
+	}
+}
+
+// ReloadReport lists the keys in the form "section.KEY" whose values have
+// changed in the custom configuration file.
+type ReloadReport struct {
+	Applied         []string
+	RequiresRestart []string
+}
+
+// Reload re-reads the custom configuration file and applies the changes of
+// log levels, mailer, webhook and UI settings. Changes of other settings are
+// reported, they take effect after restart.
+func Reload() (*ReloadReport, error) {
+	reloadLock.Lock()
+	defer reloadLock.Unlock()
+
+	newCfg, err := loadCustomConf()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load custom conf '%s': %v", CustomConf, err)
+	}
+
+	report := &ReloadReport{}
+	changedSections := make(map[string]bool)
+	for _, k := range changedKeys(loadedCfg, newCfg) {
+		section, key := k[0], k[1]
+		sectionKey := section + "." + key
+		if !isReloadable(section, key) {
+			log.Warn("Setting %s has changed, it requires a restart to take effect", sectionKey)
+			report.RequiresRestart = append(report.RequiresRestart, sectionKey)
+			continue
+		}
+
+		copyKey(Cfg, newCfg, section, key)
+		copyKey(loadedCfg, newCfg, section, key)
+		changedSections[strings.SplitN(section, ".", 2)[0]] = true
+		log.Info("Setting %s has been reloaded", sectionKey)
+		report.Applied = append(report.Applied, sectionKey)
+	}
+
+	if changedSections["log"] {
+		newLogService()
+	}
+	if changedSections["webhook"] {
+		newWebhookService()
+	}
+	if changedSections["ui"] {
+		if err = Cfg.Section("ui").MapTo(&UI); err != nil {
+			return report, fmt.Errorf("failed to map UI settings: %v", err)
+		}
+		UI.ShowUserEmail = Cfg.Section("ui").Key("SHOW_USER_EMAIL").MustBool(true)
+	}
+	// Enabling or disabling the mail service requires a restart.
+	if changedSections["mailer"] && MailService != nil {
+		mailer, err := newMailer(Cfg.Section("mailer"))
+		if err != nil {
+			return report, fmt.Errorf("mail service is unchanged: %v", err)
+		}
+		MailService = mailer
+	}
+	return report, nil
+}
@@ -10,6 +10,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"net"
 	"net/mail"
 	"net/url"
 	"os"
@@ -114,14 +115,24 @@ var (
 	}
 
 	// Security settings
-	InstallLock          bool
-	SecretKey            string
-	LogInRememberDays    int
-	CookieUserName       string
-	CookieRememberName   string
-	ReverseProxyAuthUser string
-	MinPasswordLength    int
-	ImportLocalPaths     bool
+	InstallLock                 bool
+	SecretKey                   string
+	LogInRememberDays           int
+	CookieUserName              string
+	CookieRememberName          string
+	ReverseProxyAuthUser        string
+	ReverseProxyAuthEmail       string
+	ReverseProxyAuthFullName    string
+	ReverseProxyTrustedProxies  []*net.IPNet
+	ReverseProxyDisableFallback bool
+	MinPasswordLength           int
+	PasswordComplexity          []string
+	PasswordBreachedPath        string
+	PasswordExpiryDays          int
+	LoginLockoutThreshold       int
+	LoginLockoutDuration        time.Duration
+	LoginHistoryDays            int
+	ImportLocalPaths            bool
 
 	// Database settings
 	UseSQLite3    bool
@@ -141,24 +152,39 @@ var (
 		QueueLength    int
 		DeliverTimeout int
 		SkipTLSVerify  bool
-		Types          []string
-		PagingNum      int
+		Types                     []string
+		PagingNum                 int
+		SecretRotationGracePeriod int
+		ProxyURL                  string
+		DenyPrivateAddresses      bool
+		AllowedHosts              []string
 	}{
-		QueueLength:    1000,
-		DeliverTimeout: 5,
-		SkipTLSVerify:  false,
-		PagingNum:      10,
+		QueueLength:               1000,
+		DeliverTimeout:            5,
+		SkipTLSVerify:             false,
+		PagingNum:                 10,
+		SecretRotationGracePeriod: 24,
 	}
 
 	// Repository settings
 	Repository = struct {
-		AnsiCharset            string
-		ForcePrivate           bool
-		MaxCreationLimit       int
-		MirrorQueueLength      int
-		PullRequestQueueLength int
-		PreferredLicenses      []string
-		DisableHTTPGit         bool
+		AnsiCharset              string
+		ForcePrivate             bool
+		MaxCreationLimit         int
+		MirrorQueueLength        int
+		PullRequestQueueLength   int
+		LanguageStatsQueueLength int
+		PreferredLicenses        []string
+		DisableHTTPGit           bool
+		EnableAccessLog          bool
+		PullRequestUpdateStyle   string
+		MaxPinnedIssues          int
+		RestrictRawGitHooks      bool
+		GlobalHooksPath          string
+		EnablePushCreateUser     bool
+		EnablePushCreateOrg      bool
+		DefaultPushCreatePrivate bool
+		BackportBranches         []string
 
 		// Repository editor settings
 		Editor struct {
@@ -180,13 +206,23 @@ var (
 			LocalCopyPath string
 		} `ini:"-"`
 	}{
-		AnsiCharset:            "",
-		ForcePrivate:           false,
-		MaxCreationLimit:       -1,
-		MirrorQueueLength:      1000,
-		PullRequestQueueLength: 1000,
-		PreferredLicenses:      []string{"Apache License 2.0,MIT License"},
-		DisableHTTPGit:         false,
+		AnsiCharset:              "",
+		ForcePrivate:             false,
+		MaxCreationLimit:         -1,
+		MirrorQueueLength:        1000,
+		PullRequestQueueLength:   1000,
+		LanguageStatsQueueLength: 1000,
+		PreferredLicenses:        []string{"Apache License 2.0,MIT License"},
+		DisableHTTPGit:           false,
+		EnableAccessLog:          true,
+		PullRequestUpdateStyle:   "merge",
+		MaxPinnedIssues:          3,
+		RestrictRawGitHooks:      false,
+		GlobalHooksPath:          "",
+		EnablePushCreateUser:     false,
+		EnablePushCreateOrg:      false,
+		DefaultPushCreatePrivate: true,
+		BackportBranches:         []string{"release/*"},
 
 		// Repository editor settings
 		Editor: struct {
@@ -224,12 +260,13 @@ var (
 
 	// UI settings
 	UI = struct {
-		ExplorePagingNum   int
-		IssuePagingNum     int
-		FeedMaxCommitNum   int
-		ThemeColorMetaTag  string
-		MaxDisplayFileSize int64
-		ShowUserEmail      bool
+		ExplorePagingNum      int
+		IssuePagingNum        int
+		IssueCommentPagingNum int
+		FeedMaxCommitNum      int
+		ThemeColorMetaTag     string
+		MaxDisplayFileSize    int64
+		ShowUserEmail         bool
 
 		Admin struct {
 			UserPagingNum   int
@@ -246,11 +283,12 @@ var (
 			Keywords    string
 		} `ini:"ui.meta"`
 	}{
-		ExplorePagingNum:   20,
-		IssuePagingNum:     10,
-		FeedMaxCommitNum:   5,
-		ThemeColorMetaTag:  `#6cc644`,
-		MaxDisplayFileSize: 8388608,
+		ExplorePagingNum:      20,
+		IssuePagingNum:        10,
+		IssueCommentPagingNum: 50,
+		FeedMaxCommitNum:      5,
+		ThemeColorMetaTag:     `#6cc644`,
+		MaxDisplayFileSize:    8388608,
 		Admin: struct {
 			UserPagingNum   int
 			RepoPagingNum   int
@@ -291,14 +329,28 @@ var (
 	// Admin settings
 	Admin struct {
 		DisableRegularOrgCreation bool
+		// DeletionRetention is how long deleted repositories and users are
+		// kept in QuarantinePath so that they can be restored, they are
+		// deleted right away if it is 0.
+		DeletionRetention time.Duration
+		QuarantinePath    string `ini:"-"`
+		// DisableImpersonation forbids admins to act as other users, with
+		// the Sudo header of the API or from the admin panel.
+		DisableImpersonation bool
+		// ImpersonationDuration is how long an admin can act as another
+		// user from the admin panel before being switched back.
+		ImpersonationDuration time.Duration `ini:"-"`
 	}
 
 	// Picture settings
-	AvatarUploadPath      string
-	GravatarSource        string
-	DisableGravatar       bool
-	EnableFederatedAvatar bool
-	LibravatarService     *libravatar.Libravatar
+	AvatarUploadPath           string
+	RepositoryAvatarUploadPath string
+	GravatarSource             string
+	DisableGravatar            bool
+	EnableFederatedAvatar      bool
+	LibravatarService          *libravatar.Libravatar
+	EnableAvatarProxy          bool
+	AvatarProxyCacheTTL        time.Duration
 
 	// Log settings
 	LogRootPath string
@@ -306,11 +358,14 @@ var (
 	LogConfigs  []string
 
 	// Attachment settings
-	AttachmentPath         string
-	AttachmentAllowedTypes string
-	AttachmentMaxSize      int64
-	AttachmentMaxFiles     int
-	AttachmentEnabled      bool
+	AttachmentPath              string
+	AttachmentAllowedTypes      string
+	AttachmentBlockedExtensions []string
+	AttachmentMaxSize           int64
+	AttachmentMaxFiles          int
+	AttachmentUserQuota         int64
+	AttachmentIssueQuota        int64
+	AttachmentEnabled           bool
 
 	// Time settings
 	TimeFormat string
@@ -320,10 +375,42 @@ var (
 	CacheInterval int
 	CacheConn     string
 
+	// RenderCache settings of the cache of render results
+	RenderCache struct {
+		Enabled bool
+		TTL     time.Duration
+		MaxSize int64
+	}
+
 	// Session settings
 	SessionConfig  session.Options
 	CSRFCookieName = "_csrf"
 
+	// Queue settings
+	Queue = struct {
+		Type         string
+		PollInterval time.Duration
+		ClaimTimeout time.Duration
+	}{
+		Type:         "memory",
+		PollInterval: 5 * time.Second,
+		ClaimTimeout: 10 * time.Minute,
+	}
+
+	// UserExport settings of the archives of their data users can download
+	UserExport = struct {
+		Enabled     bool
+		Path        string
+		QueueLength int
+		MinInterval time.Duration
+		Expiry      time.Duration
+	}{
+		Enabled:     true,
+		QueueLength: 100,
+		MinInterval: 24 * time.Hour,
+		Expiry:      7 * 24 * time.Hour,
+	}
+
 	// Cron tasks
 	Cron = struct {
 		UpdateMirror struct {
@@ -332,11 +419,12 @@ var (
 			Schedule   string
 		} `ini:"cron.update_mirrors"`
 		RepoHealthCheck struct {
-			Enabled    bool
-			RunAtStart bool
-			Schedule   string
-			Timeout    time.Duration
-			Args       []string `delim:" "`
+			Enabled            bool
+			RunAtStart         bool
+			Schedule           string
+			Timeout            time.Duration
+			Args               []string `delim:" "`
+			AutoGCLooseObjects int64    `ini:"AUTO_GC_LOOSE_OBJECTS"`
 		} `ini:"cron.repo_health_check"`
 		CheckRepoStats struct {
 			Enabled    bool
@@ -349,12 +437,59 @@ var (
 			Schedule   string
 			OlderThan  time.Duration
 		} `ini:"cron.archive_cleanup"`
+		AccessLogCleanup struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+			OlderThan  time.Duration
+		} `ini:"cron.access_log_cleanup"`
+		DeadlineReminder struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+			DaysBefore int
+		} `ini:"cron.deadline_reminders"`
 		SyncExternalUsers struct {
 			Enabled        bool
 			RunAtStart     bool
 			Schedule       string
 			UpdateExisting bool
 		} `ini:"cron.sync_external_users"`
+		SessionCleanup struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+		} `ini:"cron.session_cleanup"`
+		UploadSessionCleanup struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+			OlderThan  time.Duration
+		} `ini:"cron.upload_session_cleanup"`
+		AttachmentCleanup struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+			OlderThan  time.Duration
+		} `ini:"cron.attachment_cleanup"`
+		ActionCleanup struct {
+			Enabled     bool
+			RunAtStart  bool
+			Schedule    string
+			OlderThan   time.Duration
+			Archive     bool
+			ArchivePath string `ini:"-"`
+		} `ini:"cron.action_cleanup"`
+		PurgeDeleted struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+		} `ini:"cron.purge_deleted"`
+		UserExportCleanup struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+		} `ini:"cron.user_export_cleanup"`
 	}{
 		UpdateMirror: struct {
 			Enabled    bool
@@ -366,37 +501,61 @@ var (
 			Schedule:   "@every 10m",
 		},
 		RepoHealthCheck: struct {
+			Enabled            bool
+			RunAtStart         bool
+			Schedule           string
+			Timeout            time.Duration
+			Args               []string `delim:" "`
+			AutoGCLooseObjects int64    `ini:"AUTO_GC_LOOSE_OBJECTS"`
+		}{
+			Enabled:            true,
+			RunAtStart:         false,
+			Schedule:           "@every 24h",
+			Timeout:            60 * time.Second,
+			Args:               []string{},
+			AutoGCLooseObjects: 6700,
+		},
+		CheckRepoStats: struct {
 			Enabled    bool
 			RunAtStart bool
 			Schedule   string
-			Timeout    time.Duration
-			Args       []string `delim:" "`
 		}{
 			Enabled:    true,
-			RunAtStart: false,
+			RunAtStart: true,
 			Schedule:   "@every 24h",
-			Timeout:    60 * time.Second,
-			Args:       []string{},
 		},
-		CheckRepoStats: struct {
+		ArchiveCleanup: struct {
 			Enabled    bool
 			RunAtStart bool
 			Schedule   string
+			OlderThan  time.Duration
 		}{
 			Enabled:    true,
 			RunAtStart: true,
 			Schedule:   "@every 24h",
+			OlderThan:  24 * time.Hour,
 		},
-		ArchiveCleanup: struct {
+		AccessLogCleanup: struct {
 			Enabled    bool
 			RunAtStart bool
 			Schedule   string
 			OlderThan  time.Duration
 		}{
 			Enabled:    true,
-			RunAtStart: true,
+			RunAtStart: false,
 			Schedule:   "@every 24h",
-			OlderThan:  24 * time.Hour,
+			OlderThan:  90 * 24 * time.Hour,
+		},
+		DeadlineReminder: struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+			DaysBefore int
+		}{
+			Enabled:    true,
+			RunAtStart: false,
+			Schedule:   "@every 1h",
+			DaysBefore: 3,
 		},
 		SyncExternalUsers: struct {
 			Enabled        bool
@@ -409,6 +568,69 @@ var (
 			Schedule:       "@every 24h",
 			UpdateExisting: true,
 		},
+		SessionCleanup: struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+		}{
+			Enabled:    true,
+			RunAtStart: false,
+			Schedule:   "@every 1h",
+		},
+		UploadSessionCleanup: struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+			OlderThan  time.Duration
+		}{
+			Enabled:    true,
+			RunAtStart: false,
+			Schedule:   "@every 24h",
+			OlderThan:  24 * time.Hour,
+		},
+		AttachmentCleanup: struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+			OlderThan  time.Duration
+		}{
+			Enabled:    true,
+			RunAtStart: false,
+			Schedule:   "@every 24h",
+			OlderThan:  24 * time.Hour,
+		},
+		ActionCleanup: struct {
+			Enabled     bool
+			RunAtStart  bool
+			Schedule    string
+			OlderThan   time.Duration
+			Archive     bool
+			ArchivePath string `ini:"-"`
+		}{
+			Enabled:    false,
+			RunAtStart: false,
+			Schedule:   "@every 24h",
+			OlderThan:  365 * 24 * time.Hour,
+			Archive:    true,
+		},
+		PurgeDeleted: struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+		}{
+			Enabled:    true,
+			RunAtStart: false,
+			Schedule:   "@every 1h",
+		},
+		UserExportCleanup: struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+		}{
+			Enabled:    true,
+			RunAtStart: false,
+			Schedule:   "@every 1h",
+		},
 	}
 
 	// Git settings
@@ -419,6 +641,10 @@ var (
 		MaxGitDiffLineCharacters int
 		MaxGitDiffFiles          int
 		GCArgs                   []string `delim:" "`
+		GraphCacheTTL            int64    `ini:"GRAPH_CACHE_TTL"`
+		LastCommitCacheTTL       int64    `ini:"LAST_COMMIT_CACHE_TTL"`
+		AllowShallowClone        bool
+		AllowPartialClone        bool
 		Timeout                  struct {
 			Migrate int
 			Mirror  int
@@ -426,12 +652,22 @@ var (
 			Pull    int
 			GC      int `ini:"GC"`
 		} `ini:"git.timeout"`
+		PackCache struct {
+			Enabled bool
+			Path    string `ini:"-"`
+			MaxSize int64
+			TTL     time.Duration `ini:"TTL"`
+		} `ini:"git.pack_cache"`
 	}{
 		DisableDiffHighlight:     false,
 		MaxGitDiffLines:          1000,
 		MaxGitDiffLineCharacters: 500,
 		MaxGitDiffFiles:          100,
 		GCArgs:                   []string{},
+		GraphCacheTTL:            3600,
+		LastCommitCacheTTL:       3600,
+		AllowShallowClone:        true,
+		AllowPartialClone:        false,
 		Timeout: struct {
 			Migrate int
 			Mirror  int
@@ -445,19 +681,69 @@ var (
 			Pull:    300,
 			GC:      60,
 		},
+		PackCache: struct {
+			Enabled bool
+			Path    string `ini:"-"`
+			MaxSize int64
+			TTL     time.Duration `ini:"TTL"`
+		}{
+			Enabled: false,
+			MaxSize: 1024,
+			TTL:     time.Hour,
+		},
 	}
 
 	// Mirror settings
 	Mirror struct {
 		DefaultInterval time.Duration
 		MinInterval     time.Duration
+		NotifyFailures  int
 	}
 
 	// API settings
 	API = struct {
 		MaxResponseItems int
+		DefaultPagingNum int
+		RateLimit        int
+		RateLimitWindow  time.Duration
 	}{
 		MaxResponseItems: 50,
+		DefaultPagingNum: 10,
+		RateLimit:        0,
+		RateLimitWindow:  time.Hour,
+	}
+
+	// CORS settings
+	CORSConfig = struct {
+		Enabled          bool
+		AllowDomain      []string
+		Methods          []string
+		Headers          []string
+		MaxAge           int
+		AllowCredentials bool
+	}{
+		Enabled:          false,
+		AllowDomain:      []string{"*"},
+		Methods:          []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		Headers:          []string{"Content-Type", "Authorization", "X-Csrf-Token"},
+		MaxAge:           600,
+		AllowCredentials: false,
+	}
+
+	// Federation settings
+	Federation = struct {
+		Enabled bool
+	}{
+		Enabled: false,
+	}
+
+	// Metrics settings
+	Metrics = struct {
+		Enabled bool
+		Token   string
+	}{
+		Enabled: false,
+		Token:   "",
 	}
 
 	// I18n settings
@@ -544,6 +830,43 @@ func forcePathSeparator(path string) {
 	}
 }
 
+// parseIPNet parses a CIDR notation, a single IP address is taken as the
+// network of this address only.
+func parseIPNet(s string) (*net.IPNet, error) {
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address")
+		}
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 8*net.IPv4len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, network, err := net.ParseCIDR(s)
+	return network, err
+}
+
+// IsTrustedProxy returns true if given remote address, as "host:port" or a
+// single host, is one of the trusted reverse proxies.
+func IsTrustedProxy(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range ReverseProxyTrustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsRunUserMatchCurrentUser returns false if configured run user does not match
 // actual user that runs the app. The first return value is the actual user name.
 // This check is ignored under Windows since SSH remote login is not the main
@@ -612,6 +935,9 @@ please consider changing to GITEA_CUSTOM`)
 		log.Warn("Custom config '%s' not found, ignore this if you're running first time", CustomConf)
 	}
 	Cfg.NameMapper = ini.AllCapsUnderscore
+	if loadedCfg, err = loadCustomConf(); err != nil {
+		log.Fatal(4, "Failed to load custom conf '%s': %v", CustomConf, err)
+	}
 
 	homeDir, err := com.HomeDir()
 	if err != nil {
@@ -794,7 +1120,45 @@ please consider changing to GITEA_CUSTOM`)
 	CookieUserName = sec.Key("COOKIE_USERNAME").MustString("gitea_awesome")
 	CookieRememberName = sec.Key("COOKIE_REMEMBER_NAME").MustString("gitea_incredible")
 	ReverseProxyAuthUser = sec.Key("REVERSE_PROXY_AUTHENTICATION_USER").MustString("X-WEBAUTH-USER")
+	ReverseProxyAuthEmail = sec.Key("REVERSE_PROXY_AUTHENTICATION_EMAIL").MustString("X-WEBAUTH-EMAIL")
+	ReverseProxyAuthFullName = sec.Key("REVERSE_PROXY_AUTHENTICATION_FULL_NAME").MustString("X-WEBAUTH-FULLNAME")
+	ReverseProxyTrustedProxies = ReverseProxyTrustedProxies[:0]
+	for _, proxy := range strings.Split(sec.Key("REVERSE_PROXY_TRUSTED_PROXIES").MustString("127.0.0.0/8,::1/128"), ",") {
+		proxy = strings.TrimSpace(proxy)
+		if len(proxy) == 0 {
+			continue
+		} else if proxy == "*" {
+			proxy = "0.0.0.0/0,::/0"
+		}
+		for _, cidr := range strings.Split(proxy, ",") {
+			network, err := parseIPNet(cidr)
+			if err != nil {
+				log.Fatal(4, "Invalid trusted proxy '%s': %v", cidr, err)
+			}
+			ReverseProxyTrustedProxies = append(ReverseProxyTrustedProxies, network)
+		}
+	}
+	ReverseProxyDisableFallback = sec.Key("REVERSE_PROXY_DISABLE_FALLBACK").MustBool(false)
 	MinPasswordLength = sec.Key("MIN_PASSWORD_LENGTH").MustInt(6)
+	PasswordComplexity = nil
+	for _, class := range sec.Key("PASSWORD_COMPLEXITY").Strings(",") {
+		class = strings.ToLower(class)
+		switch class {
+		case "off":
+		case "lower", "upper", "digit", "spec":
+			PasswordComplexity = append(PasswordComplexity, class)
+		default:
+			log.Fatal(4, "Unknown password complexity class: %s", class)
+		}
+	}
+	PasswordBreachedPath = sec.Key("PASSWORD_BREACHED_PATH").String()
+	if len(PasswordBreachedPath) > 0 && !filepath.IsAbs(PasswordBreachedPath) {
+		PasswordBreachedPath = path.Join(workDir, PasswordBreachedPath)
+	}
+	PasswordExpiryDays = sec.Key("PASSWORD_EXPIRY_DAYS").MustInt(0)
+	LoginLockoutThreshold = sec.Key("LOGIN_LOCKOUT_THRESHOLD").MustInt(5)
+	LoginLockoutDuration = sec.Key("LOGIN_LOCKOUT_DURATION").MustDuration(5 * time.Minute)
+	LoginHistoryDays = sec.Key("LOGIN_HISTORY_DAYS").MustInt(90)
 	ImportLocalPaths = sec.Key("IMPORT_LOCAL_PATHS").MustBool(false)
 	InternalToken = sec.Key("INTERNAL_TOKEN").String()
 	if len(InternalToken) == 0 {
@@ -840,8 +1204,16 @@ please consider changing to GITEA_CUSTOM`)
 		AttachmentPath = path.Join(workDir, AttachmentPath)
 	}
 	AttachmentAllowedTypes = strings.Replace(sec.Key("ALLOWED_TYPES").MustString("image/jpeg,image/png,application/zip,application/gzip"), "|", ",", -1)
+	AttachmentBlockedExtensions = make([]string, 0, 10)
+	for _, ext := range strings.Split(sec.Key("BLOCKED_EXTENSIONS").MustString(".exe|.bat|.cmd|.com|.scr|.msi|.ps1|.vbs|.js|.jar|.html|.htm|.xhtml|.svg"), "|") {
+		if ext = strings.ToLower(strings.TrimSpace(ext)); len(ext) > 0 {
+			AttachmentBlockedExtensions = append(AttachmentBlockedExtensions, "."+strings.TrimPrefix(ext, "."))
+		}
+	}
 	AttachmentMaxSize = sec.Key("MAX_SIZE").MustInt64(4)
 	AttachmentMaxFiles = sec.Key("MAX_FILES").MustInt(5)
+	AttachmentUserQuota = sec.Key("USER_QUOTA").MustInt64(0)
+	AttachmentIssueQuota = sec.Key("ISSUE_QUOTA").MustInt64(0)
 	AttachmentEnabled = sec.Key("ENABLE").MustBool(true)
 
 	TimeFormatKey := Cfg.Section("time").Key("FORMAT").MustString("RFC1123")
@@ -906,6 +1278,11 @@ please consider changing to GITEA_CUSTOM`)
 	if !filepath.IsAbs(Repository.Upload.TempPath) {
 		Repository.Upload.TempPath = path.Join(workDir, Repository.Upload.TempPath)
 	}
+	if len(Repository.GlobalHooksPath) == 0 {
+		Repository.GlobalHooksPath = path.Join(CustomPath, "hooks")
+	} else if !filepath.IsAbs(Repository.GlobalHooksPath) {
+		Repository.GlobalHooksPath = path.Join(workDir, Repository.GlobalHooksPath)
+	}
 
 	sec = Cfg.Section("picture")
 	AvatarUploadPath = sec.Key("AVATAR_UPLOAD_PATH").MustString(path.Join(AppDataPath, "avatars"))
@@ -913,6 +1290,11 @@ please consider changing to GITEA_CUSTOM`)
 	if !filepath.IsAbs(AvatarUploadPath) {
 		AvatarUploadPath = path.Join(workDir, AvatarUploadPath)
 	}
+	RepositoryAvatarUploadPath = sec.Key("REPOSITORY_AVATAR_UPLOAD_PATH").MustString(path.Join(AppDataPath, "repo-avatars"))
+	forcePathSeparator(RepositoryAvatarUploadPath)
+	if !filepath.IsAbs(RepositoryAvatarUploadPath) {
+		RepositoryAvatarUploadPath = path.Join(workDir, RepositoryAvatarUploadPath)
+	}
 	switch source := sec.Key("GRAVATAR_SOURCE").MustString("gravatar"); source {
 	case "duoshuo":
 		GravatarSource = "http://gravatar.duoshuo.com/avatar/"
@@ -925,7 +1307,10 @@ please consider changing to GITEA_CUSTOM`)
 	}
 	DisableGravatar = sec.Key("DISABLE_GRAVATAR").MustBool()
 	EnableFederatedAvatar = sec.Key("ENABLE_FEDERATED_AVATAR").MustBool()
-	if OfflineMode {
+	EnableAvatarProxy = sec.Key("ENABLE_AVATAR_PROXY").MustBool()
+	AvatarProxyCacheTTL = sec.Key("AVATAR_PROXY_CACHE_TTL").MustDuration(24 * time.Hour)
+	// Avatars fetched by the proxy do not reveal anything to third parties.
+	if OfflineMode && !EnableAvatarProxy {
 		DisableGravatar = true
 		EnableFederatedAvatar = false
 	}
@@ -959,11 +1344,41 @@ please consider changing to GITEA_CUSTOM`)
 		log.Fatal(4, "Failed to map Git settings: %v", err)
 	} else if err = Cfg.Section("api").MapTo(&API); err != nil {
 		log.Fatal(4, "Failed to map API settings: %v", err)
+	} else if err = Cfg.Section("cors").MapTo(&CORSConfig); err != nil {
+		log.Fatal(4, "Failed to map CORS settings: %v", err)
+	} else if err = Cfg.Section("federation").MapTo(&Federation); err != nil {
+		log.Fatal(4, "Failed to map Federation settings: %v", err)
+	} else if err = Cfg.Section("metrics").MapTo(&Metrics); err != nil {
+		log.Fatal(4, "Failed to map Metrics settings: %v", err)
+	}
+	Cron.ActionCleanup.ArchivePath = Cfg.Section("cron.action_cleanup").Key("ARCHIVE_PATH").MustString(path.Join(AppDataPath, "action_archives"))
+	if !filepath.IsAbs(Cron.ActionCleanup.ArchivePath) {
+		Cron.ActionCleanup.ArchivePath = path.Join(workDir, Cron.ActionCleanup.ArchivePath)
+	}
+	Git.PackCache.Path = Cfg.Section("git.pack_cache").Key("PATH").MustString(path.Join(AppDataPath, "pack_cache"))
+	if !filepath.IsAbs(Git.PackCache.Path) {
+		Git.PackCache.Path = path.Join(workDir, Git.PackCache.Path)
 	}
+	Admin.QuarantinePath = Cfg.Section("admin").Key("QUARANTINE_PATH").MustString(path.Join(AppDataPath, "quarantine"))
+	if !filepath.IsAbs(Admin.QuarantinePath) {
+		Admin.QuarantinePath = path.Join(workDir, Admin.QuarantinePath)
+	}
+	Admin.ImpersonationDuration = Cfg.Section("admin").Key("IMPERSONATION_DURATION").MustDuration(30 * time.Minute)
+
+	sec = Cfg.Section("user_export")
+	UserExport.Enabled = sec.Key("ENABLED").MustBool(true)
+	UserExport.Path = sec.Key("PATH").MustString(path.Join(AppDataPath, "user_exports"))
+	if !filepath.IsAbs(UserExport.Path) {
+		UserExport.Path = path.Join(workDir, UserExport.Path)
+	}
+	UserExport.QueueLength = sec.Key("QUEUE_LENGTH").MustInt(100)
+	UserExport.MinInterval = sec.Key("MIN_INTERVAL").MustDuration(24 * time.Hour)
+	UserExport.Expiry = sec.Key("EXPIRY").MustDuration(7 * 24 * time.Hour)
 
 	sec = Cfg.Section("mirror")
 	Mirror.MinInterval = sec.Key("MIN_INTERVAL").MustDuration(10 * time.Minute)
 	Mirror.DefaultInterval = sec.Key("DEFAULT_INTERVAL").MustDuration(8 * time.Hour)
+	Mirror.NotifyFailures = sec.Key("NOTIFY_FAILURES").MustInt(3)
 	if Mirror.MinInterval.Minutes() < 1 {
 		log.Warn("Mirror.MinInterval is too low")
 		Mirror.MinInterval = 1 * time.Minute
@@ -1007,6 +1422,10 @@ var Service struct {
 	DefaultKeepEmailPrivate        bool
 	DefaultAllowCreateOrganization bool
 	NoReplyAddress                 string
+	MaxAbuseReportsPerDay          int
+	EmailDomainWhitelist           []string
+	EmailDomainBlacklist           []string
+	HideSuspendedUsers             bool
 
 	// OpenID settings
 	EnableOpenIDSignIn bool
@@ -1015,6 +1434,18 @@ var Service struct {
 	OpenIDBlacklist    []*regexp.Regexp
 }
 
+// parseEmailDomains returns the lower cased non-empty domain patterns.
+func parseEmailDomains(patterns []string) []string {
+	domains := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if len(pattern) > 0 {
+			domains = append(domains, pattern)
+		}
+	}
+	return domains
+}
+
 func newService() {
 	sec := Cfg.Section("service")
 	Service.ActiveCodeLives = sec.Key("ACTIVE_CODE_LIVE_MINUTES").MustInt(180)
@@ -1028,6 +1459,10 @@ func newService() {
 	Service.DefaultKeepEmailPrivate = sec.Key("DEFAULT_KEEP_EMAIL_PRIVATE").MustBool()
 	Service.DefaultAllowCreateOrganization = sec.Key("DEFAULT_ALLOW_CREATE_ORGANIZATION").MustBool(true)
 	Service.NoReplyAddress = sec.Key("NO_REPLY_ADDRESS").MustString("noreply.example.org")
+	Service.MaxAbuseReportsPerDay = sec.Key("MAX_ABUSE_REPORTS_PER_DAY").MustInt(10)
+	Service.EmailDomainWhitelist = parseEmailDomains(sec.Key("EMAIL_DOMAIN_WHITELIST").Strings(","))
+	Service.EmailDomainBlacklist = parseEmailDomains(sec.Key("EMAIL_DOMAIN_BLACKLIST").Strings(","))
+	Service.HideSuspendedUsers = sec.Key("HIDE_SUSPENDED_USERS").MustBool()
 
 	sec = Cfg.Section("openid")
 	Service.EnableOpenIDSignIn = sec.Key("ENABLE_OPENID_SIGNIN").MustBool(false)
@@ -1233,12 +1668,17 @@ func newCacheService() {
 		log.Fatal(4, "Unknown cache adapter: %s", CacheAdapter)
 	}
 
+	sec := Cfg.Section("cache")
+	RenderCache.Enabled = sec.Key("RENDER_CACHE_ENABLED").MustBool(true)
+	RenderCache.TTL = sec.Key("RENDER_CACHE_TTL").MustDuration(24 * time.Hour)
+	RenderCache.MaxSize = sec.Key("RENDER_CACHE_MAX_SIZE").MustInt64(512 * 1024)
+
 	log.Info("Cache Service Enabled")
 }
 
 func newSessionService() {
 	SessionConfig.Provider = Cfg.Section("session").Key("PROVIDER").In("memory",
-		[]string{"memory", "file", "redis", "mysql"})
+		[]string{"memory", "file", "db", "redis", "redis-sentinel", "mysql"})
 	SessionConfig.ProviderConfig = strings.Trim(Cfg.Section("session").Key("PROVIDER_CONFIG").String(), "\" ")
 	SessionConfig.CookieName = Cfg.Section("session").Key("COOKIE_NAME").MustString("i_like_gitea")
 	SessionConfig.CookiePath = AppSubURL
@@ -1284,7 +1724,17 @@ func newMailService() {
 		return
 	}
 
-	MailService = &Mailer{
+	var err error
+	if MailService, err = newMailer(sec); err != nil {
+		log.Fatal(4, "%v", err)
+	}
+
+	log.Info("Mail Service Enabled")
+}
+
+// newMailer creates the mail service from the mailer section.
+func newMailer(sec *ini.Section) (*Mailer, error) {
+	mailer := &Mailer{
 		QueueLength:     sec.Key("SEND_BUFFER_LEN").MustInt(100),
 		Name:            sec.Key("NAME").MustString(AppName),
 		SendAsPlainText: sec.Key("SEND_AS_PLAIN_TEXT").MustBool(false),
@@ -1302,20 +1752,19 @@ func newMailService() {
 		UseSendmail:  sec.Key("USE_SENDMAIL").MustBool(),
 		SendmailPath: sec.Key("SENDMAIL_PATH").MustString("sendmail"),
 	}
-	MailService.From = sec.Key("FROM").MustString(MailService.User)
+	mailer.From = sec.Key("FROM").MustString(mailer.User)
 
 	if sec.HasKey("ENABLE_HTML_ALTERNATIVE") {
 		log.Warn("ENABLE_HTML_ALTERNATIVE is deprecated, use SEND_AS_PLAIN_TEXT")
-		MailService.SendAsPlainText = !sec.Key("ENABLE_HTML_ALTERNATIVE").MustBool(false)
+		mailer.SendAsPlainText = !sec.Key("ENABLE_HTML_ALTERNATIVE").MustBool(false)
 	}
 
-	parsed, err := mail.ParseAddress(MailService.From)
+	parsed, err := mail.ParseAddress(mailer.From)
 	if err != nil {
-		log.Fatal(4, "Invalid mailer.FROM (%s): %v", MailService.From, err)
+		return nil, fmt.Errorf("Invalid mailer.FROM (%s): %v", mailer.From, err)
 	}
-	MailService.FromEmail = parsed.Address
-
-	log.Info("Mail Service Enabled")
+	mailer.FromEmail = parsed.Address
+	return mailer, nil
 }
 
 func newRegisterMailService() {
@@ -1347,6 +1796,33 @@ func newWebhookService() {
 	Webhook.SkipTLSVerify = sec.Key("SKIP_TLS_VERIFY").MustBool()
 	Webhook.Types = []string{"gitea", "gogs", "slack"}
 	Webhook.PagingNum = sec.Key("PAGING_NUM").MustInt(10)
+	Webhook.SecretRotationGracePeriod = sec.Key("SECRET_ROTATION_GRACE_PERIOD").MustInt(24)
+	Webhook.ProxyURL = sec.Key("PROXY_URL").String()
+	if len(Webhook.ProxyURL) > 0 {
+		proxyURL, err := url.Parse(Webhook.ProxyURL)
+		if err != nil {
+			log.Fatal(4, "Invalid webhook proxy URL '%s': %v", Webhook.ProxyURL, err)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5":
+		default:
+			log.Fatal(4, "Unsupported webhook proxy scheme '%s'", proxyURL.Scheme)
+		}
+	}
+	Webhook.DenyPrivateAddresses = sec.Key("DENY_PRIVATE_ADDRESSES").MustBool()
+	Webhook.AllowedHosts = sec.Key("ALLOWED_HOSTS").Strings(",")
+}
+
+func newQueueService() {
+	sec := Cfg.Section("queue")
+	Queue.Type = sec.Key("TYPE").In("memory", []string{"memory", "database"})
+	Queue.PollInterval = sec.Key("POLL_INTERVAL").MustDuration(5 * time.Second)
+	Queue.ClaimTimeout = sec.Key("CLAIM_TIMEOUT").MustDuration(10 * time.Minute)
+	if Queue.PollInterval <= 0 {
+		log.Fatal(4, "Invalid queue poll interval: %v", Queue.PollInterval)
+	}
+
+	log.Info("Queue Service Enabled: %s", Queue.Type)
 }
 
 // NewServices initializes the services
@@ -1360,4 +1836,5 @@ func NewServices() {
 	newRegisterMailService()
 	newNotifyMailService()
 	newWebhookService()
+	newQueueService()
 }
@@ -0,0 +1,34 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTrustedProxy(t *testing.T) {
+	oldProxies := ReverseProxyTrustedProxies
+	defer func() { ReverseProxyTrustedProxies = oldProxies }()
+
+	ReverseProxyTrustedProxies = nil
+	for _, proxy := range []string{"127.0.0.0/8", "::1", "10.0.0.5"} {
+		network, err := parseIPNet(proxy)
+		assert.NoError(t, err)
+		ReverseProxyTrustedProxies = append(ReverseProxyTrustedProxies, network)
+	}
+	assert.Equal(t, net.CIDRMask(32, 32), ReverseProxyTrustedProxies[2].Mask)
+
+	assert.True(t, IsTrustedProxy("127.0.0.1:54321"))
+	assert.True(t, IsTrustedProxy("[::1]:80"))
+	assert.True(t, IsTrustedProxy("10.0.0.5"))
+	assert.False(t, IsTrustedProxy("10.0.0.6:80"))
+	assert.False(t, IsTrustedProxy("not-an-ip"))
+
+	_, err := parseIPNet("10.0.0.300")
+	assert.Error(t, err)
+}
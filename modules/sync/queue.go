@@ -0,0 +1,27 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+// Queue is a queue of unique instances, identified by their ID, consumed by
+// receiving from the channel returned by Queue. UniqueQueue is the in-memory
+// implementation.
+type Queue interface {
+	// Queue returns channel of queue for retrieving instances.
+	Queue() <-chan string
+	// Exist returns true if there is an instance with given identity
+	// exists in the queue.
+	Exist(id interface{}) bool
+	// AddFunc adds new instance to the queue with a custom runnable function.
+	AddFunc(id interface{}, fn func())
+	// Add adds new instance to the queue.
+	Add(id interface{})
+	// Remove removes instance from the queue.
+	Remove(id interface{})
+	// Position returns the 1-based position of the instance with given
+	// identity in the queue, or 0 if it is not in the queue.
+	Position(id interface{}) int
+}
+
+var _ Queue = &UniqueQueue{}
@@ -17,6 +17,12 @@ import (
 type UniqueQueue struct {
 	table *StatusTable
 	queue chan string
+	out   chan string
+	order []string
+	// taken are the instances received from the queue and not removed yet,
+	// readded the ones of them added again in the meantime.
+	taken   map[string]bool
+	readded map[string]bool
 }
 
 // NewUniqueQueue initializes and returns a new UniqueQueue object.
@@ -25,15 +31,34 @@ func NewUniqueQueue(queueLength int) *UniqueQueue {
 		queueLength = 100
 	}
 
-	return &UniqueQueue{
-		table: NewStatusTable(),
-		queue: make(chan string, queueLength),
+	q := &UniqueQueue{
+		table:   NewStatusTable(),
+		queue:   make(chan string, queueLength),
+		out:     make(chan string),
+		taken:   make(map[string]bool),
+		readded: make(map[string]bool),
 	}
+	go q.forward()
+	return q
 }
 
-// Queue returns channel of queue for retrieving instances.
+// forward hands the instances of the line to the consumer, keeping track of
+// those which have been received.
+func (q *UniqueQueue) forward() {
+	for idStr := range q.queue {
+		q.out <- idStr
+		q.table.lock.Lock()
+		if _, has := q.table.pool[idStr]; has {
+			q.taken[idStr] = true
+		}
+		q.table.lock.Unlock()
+	}
+}
+
+// Queue returns channel of queue for retrieving instances, they must be
+// removed once processed.
 func (q *UniqueQueue) Queue() <-chan string {
-	return q.queue
+	return q.out
 }
 
 // Exist returns true if there is an instance with given identity
@@ -43,15 +68,21 @@ func (q *UniqueQueue) Exist(id interface{}) bool {
 }
 
 // AddFunc adds new instance to the queue with a custom runnable function,
-// the queue is blocked until the function exits.
+// the queue is blocked until the function exits. An instance added while it
+// is being processed is put back in the line once removed.
 func (q *UniqueQueue) AddFunc(id interface{}, fn func()) {
-	if q.Exist(id) {
-		return
-	}
-
 	idStr := com.ToStr(id)
 	q.table.lock.Lock()
+	if _, has := q.table.pool[idStr]; has {
+		if q.taken[idStr] {
+			q.readded[idStr] = true
+		}
+		q.table.lock.Unlock()
+		return
+	}
 	q.table.pool[idStr] = struct{}{}
+	q.order = append(q.order, idStr)
+	delete(q.taken, idStr)
 	if fn != nil {
 		fn()
 	}
@@ -64,7 +95,42 @@ func (q *UniqueQueue) Add(id interface{}) {
 	q.AddFunc(id, nil)
 }
 
-// Remove removes instance from the queue.
+// Remove removes instance from the queue once it has been processed. If it
+// has been added again in the meantime, it is put back in the line instead.
 func (q *UniqueQueue) Remove(id interface{}) {
-	q.table.Stop(com.ToStr(id))
+	idStr := com.ToStr(id)
+	q.table.lock.Lock()
+	defer q.table.lock.Unlock()
+	for i := range q.order {
+		if q.order[i] == idStr {
+			q.order = append(q.order[:i], q.order[i+1:]...)
+			break
+		}
+	}
+	delete(q.taken, idStr)
+	if !q.readded[idStr] {
+		delete(q.table.pool, idStr)
+		return
+	}
+
+	delete(q.readded, idStr)
+	q.order = append(q.order, idStr)
+	// The consumer removing the instance must not wait for room in the line.
+	go func() {
+		q.queue <- idStr
+	}()
+}
+
+// Position returns the 1-based position of the instance with given identity
+// in the queue, or 0 if it is not in the queue.
+func (q *UniqueQueue) Position(id interface{}) int {
+	idStr := com.ToStr(id)
+	q.table.lock.RLock()
+	defer q.table.lock.RUnlock()
+	for i := range q.order {
+		if q.order[i] == idStr {
+			return i + 1
+		}
+	}
+	return 0
 }
@@ -0,0 +1,59 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UniqueQueue(t *testing.T) {
+	queue := NewUniqueQueue(10)
+
+	queue.Add(1)
+	queue.Add(2)
+	queue.Add(1)
+	assert.True(t, queue.Exist(1))
+	assert.Equal(t, 1, queue.Position(1))
+	assert.Equal(t, 2, queue.Position(2))
+	assert.Equal(t, 0, queue.Position(3))
+
+	assert.Equal(t, "1", <-queue.Queue())
+	queue.Remove(1)
+	assert.False(t, queue.Exist(1))
+	assert.Equal(t, 0, queue.Position(1))
+	assert.Equal(t, 1, queue.Position(2))
+}
+
+func Test_UniqueQueue_Readd(t *testing.T) {
+	queue := NewUniqueQueue(10)
+	// The instance is known to be taken shortly after it has been received.
+	waitTaken := func(idStr string) {
+		for {
+			queue.table.lock.RLock()
+			taken := queue.taken[idStr]
+			queue.table.lock.RUnlock()
+			if taken {
+				return
+			}
+			runtime.Gosched()
+		}
+	}
+
+	queue.Add(1)
+	assert.Equal(t, "1", <-queue.Queue())
+	waitTaken("1")
+	// Added again while being processed.
+	queue.Add(1)
+	assert.True(t, queue.Exist(1))
+
+	queue.Remove(1)
+	assert.True(t, queue.Exist(1))
+	assert.Equal(t, "1", <-queue.Queue())
+	queue.Remove(1)
+	assert.False(t, queue.Exist(1))
+}
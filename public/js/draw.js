@@ -10,4 +10,42 @@ $(document).ready(function () {
 	})
 	
 	gitGraph(document.getElementById('graph-canvas'), graphList);
+
+	var $more = $('#graph-load-more');
+	$more.click(function () {
+		if ($more.hasClass('loading')) {
+			return;
+		}
+		$more.addClass('loading');
+
+		var page = parseInt($more.data('page')) + 1;
+		$.getJSON($more.data('url'), {page: page}, function (data) {
+			$.each(data.items, function (_, item) {
+				graphList.push(item.relation);
+				$('#graph-raw-list').append($('<li>').append($('<span class="node-relation">').text(item.relation)));
+
+				var $li = $('<li>');
+				if (item.only_relation) {
+					$li.append('<span />');
+				} else {
+					$li.append($('<code>').attr('id', item.short_rev).append(
+						$('<a>').attr('href', $more.data('commit-url') + item.rev).text(item.short_rev)));
+					$li.append(' ', $('<strong>').text(' ' + item.branch));
+					$li.append(' ', $('<em>').html(item.subject), ' ' + $more.data('by') + ' ');
+					$li.append($('<span class="author">').text(item.author));
+					$li.append(' ', $('<span class="time">').text(item.date));
+				}
+				$('#rev-list').append($li);
+			});
+
+			gitGraph(document.getElementById('graph-canvas'), graphList);
+
+			$more.data('page', page).removeClass('loading');
+			if (!data.has_more) {
+				$more.remove();
+			}
+		}).fail(function () {
+			$more.removeClass('loading');
+		});
+	});
 })
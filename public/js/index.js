@@ -101,6 +101,13 @@ function updateIssuesMeta(url, action, issueIds, elementId, afterSuccess) {
     })
 }
 
+function insertAtCursor(textarea, text) {
+    var start = textarea.selectionStart;
+    var end = textarea.selectionEnd;
+    textarea.value = textarea.value.substring(0, start) + text + textarea.value.substring(end);
+    textarea.selectionStart = textarea.selectionEnd = start + text.length;
+}
+
 function initCommentForm() {
     if ($('.comment.form').length == 0) {
         return
@@ -133,6 +140,13 @@ function initCommentForm() {
                 );
             }
         } else {
+            // Scoped labels are mutually exclusive within their scope.
+            var scope = $(this).data('scope');
+            if (scope) {
+                $(this).siblings('.checked.item').filter(function () {
+                    return $(this).data('scope') == scope;
+                }).removeClass('checked').find('.octicon').removeClass('octicon-check');
+            }
             $(this).addClass('checked');
             $(this).find('.octicon').addClass('octicon-check');
             if (hasLabelUpdateAction) {
@@ -162,6 +176,29 @@ function initCommentForm() {
         $($(this).parent().data('id')).val(labelIds.join(","));
         return false;
     });
+    var labelSearchTimeout;
+    $labelMenu.find('.label-search').on('input', function () {
+        var keyword = $(this).val();
+        clearTimeout(labelSearchTimeout);
+        labelSearchTimeout = setTimeout(function () {
+            var $items = $labelMenu.find('.item:not(.no-select)');
+            if (keyword.length == 0) {
+                $items.show();
+                return;
+            }
+            $.getJSON($labelMenu.data('search-url'), {q: keyword}, function (labels) {
+                var ids = {};
+                $.each(labels, function (i, label) {
+                    ids[label.id] = true;
+                });
+                $items.each(function () {
+                    $(this).toggle(ids[$(this).data('id')] === true);
+                });
+            });
+        }, 300);
+    }).click(function (e) {
+        e.stopPropagation();
+    });
     $labelMenu.find('.no-select.item').click(function () {
         if (hasLabelUpdateAction) {
             updateIssuesMeta(
@@ -196,6 +233,10 @@ function initCommentForm() {
         });
 
         $menu.find('.item:not(.no-select)').click(function () {
+            if ($(this).data('busy') && !confirm($(this).data('busy'))) {
+                return false;
+            }
+
             $(this).parent().find('.item').each(function () {
                 $(this).removeClass('selected active')
             });
@@ -217,7 +258,7 @@ function initCommentForm() {
                 case '#assignee_id':
                     $list.find('.selected').html('<a class="item" href=' + $(this).data('href') + '>' +
                         '<img class="ui avatar image" src=' + $(this).data('avatar') + '>' +
-                        $(this).text() + '</a>');
+                        $(this).data('name') + '</a>');
             }
             $('.ui' + select_id + '.list .no-select').addClass('hide');
             $(input_id).val($(this).data('id'));
@@ -356,6 +397,16 @@ function initRepository() {
             $(this).find('.text').addClass('black');
             return false;
         });
+
+        // Sync fork with base repository.
+        var $syncFork = $('#sync-fork');
+        $syncFork.dropdown({
+            action: function (text, value) {
+                $syncFork.dropdown('hide');
+                $syncFork.find('input[name=style]').val(value);
+                $syncFork.closest('form').submit();
+            }
+        });
     }
 
     // Wiki
@@ -413,6 +464,7 @@ function initRepository() {
         $('.edit-label-button').click(function () {
             $('#label-modal-id').val($(this).data('id'));
             $('.edit-label .new-label-input').val($(this).data('title'));
+            $('.edit-label .new-label-desc-input').val($(this).data('description'));
             $('.edit-label .color-picker').val($(this).data('color'));
             $('.minicolors-swatch-color').css("background-color", $(this).data('color'));
             $('.edit-label.modal').modal({
@@ -481,7 +533,7 @@ function initRepository() {
         });
 
         // Edit issue or comment content
-        $('.edit-content').click(function () {
+        $('.comment-list').on('click', '.edit-content', function () {
             var $segment = $(this).parent().parent().parent().next();
             var $editContentZone = $segment.find('.edit-content-zone');
             var $renderContent = $segment.find('.render-content');
@@ -545,7 +597,7 @@ function initRepository() {
         });
 
         // Delete comment
-        $('.delete-comment').click(function () {
+        $('.comment-list').on('click', '.delete-comment', function () {
             var $this = $(this);
             if (confirm($this.data('locale'))) {
                 $.post($this.data('url'), {
@@ -557,6 +609,23 @@ function initRepository() {
             return false;
         });
 
+        // Load the comments hidden in the middle of long discussions
+        $('.comment-list').on('click', '.hidden-comments .button', function () {
+            var $button = $(this);
+            $button.addClass('loading disabled');
+            $.get($button.data('url'), function (data) {
+                var $data = $('<div>').html(data);
+                $data.find('.has-emoji').each(function () {
+                    emojify.run(this);
+                });
+                $data.find('pre code').each(function (i, block) {
+                    hljs.highlightBlock(block);
+                });
+                initMentionStatuses($data);
+                $button.parent().replaceWith($data.contents());
+            });
+        });
+
         // Change status
         var $statusButton = $('#status-button');
         $('#comment-form .edit_area').keyup(function () {
@@ -574,16 +643,41 @@ function initRepository() {
 
     // Diff
     if ($('.repository.diff').length > 0) {
-        var $counter = $('.diff-counter');
-        if ($counter.length >= 1) {
-            $counter.each(function (i, item) {
-                var $item = $(item);
-                var addLine = $item.find('span[data-line].add').data("line");
-                var delLine = $item.find('span[data-line].del').data("line");
-                var addPercent = parseFloat(addLine) / (parseFloat(addLine) + parseFloat(delLine)) * 100;
-                $item.find(".bar .add").css("width", addPercent + "%");
+        initDiffCounters($('.repository.diff'));
+
+        // Files beyond the limit are loaded one by one on demand.
+        var loadDiffFile = function ($box) {
+            if ($box.hasClass('loading')) {
+                return $.Deferred().resolve().promise();
+            }
+            $box.addClass('loading');
+            $box.find('.diff-load-file').addClass('loading disabled');
+            return $.get($box.data('url'), function (data) {
+                var $file = $(data);
+                $box.replaceWith($file);
+                initDiffCounters($file);
+                $file.find('pre code').each(function (i, block) {
+                    hljs.highlightBlock(block);
+                });
             });
-        }
+        };
+        $('.diff-load-file').click(function () {
+            loadDiffFile($(this).closest('.diff-lazy'));
+        });
+        $('#diff-load-more').click(function () {
+            var $button = $(this);
+            var $boxes = $('.diff-lazy:not(.loading)').slice(0, $button.data('batch'));
+            var loads = $boxes.map(function () {
+                return loadDiffFile($(this));
+            }).get();
+            $button.addClass('loading disabled');
+            $.when.apply($, loads).always(function () {
+                $button.removeClass('loading disabled');
+                if ($('.diff-lazy').length == 0) {
+                    $button.parent().remove();
+                }
+            });
+        });
     }
 
     // Quick start and repository home
@@ -611,6 +705,16 @@ function initRepository() {
     }
 }
 
+function initDiffCounters($container) {
+    $container.find('.diff-counter').each(function (i, item) {
+        var $item = $(item);
+        var addLine = $item.find('span[data-line].add').data("line");
+        var delLine = $item.find('span[data-line].del').data("line");
+        var addPercent = parseFloat(addLine) / (parseFloat(addLine) + parseFloat(delLine)) * 100;
+        $item.find(".bar .add").css("width", addPercent + "%");
+    });
+}
+
 function initProtectedBranch() {
     $('#protectedBranch').change(function () {
         var $this = $(this);
@@ -659,6 +763,131 @@ function initRepositoryCollaboration() {
             "mode": $(this).data('value')
         })
     });
+
+    // Change units the access mode of collaborator applies to
+    $('.collaboration-units').popup();
+    $('.collaboration-units input').change(function () {
+        var $units = $(this).closest('.collaboration-units');
+        var checked = $units.find('input:checked').map(function () {
+            return $(this).val();
+        }).get();
+        // Access mode applies to at least one unit, otherwise collaborator should be a reader.
+        if (checked.length === 0) {
+            $(this).prop('checked', true);
+            return;
+        }
+        $.post($units.data('url'), {
+            "_csrf": csrf,
+            "uid": $units.data('uid'),
+            "units": checked.join(',')
+        })
+    });
+}
+
+function initMentionStatuses($container) {
+    var $statuses = $container.find('.mention-statuses');
+    if ($statuses.length === 0) {
+        return;
+    }
+
+    var statuses = {};
+    $statuses.find('.item').each(function () {
+        statuses['@' + $(this).attr('data-name').toLowerCase()] = $(this);
+    });
+
+    // Decorate rendered mentions of users who set a status.
+    $container.find('.render-content.markdown a').each(function () {
+        var $status = statuses[$(this).text().toLowerCase()];
+        if (!$status) {
+            return;
+        }
+
+        var $label = $('<span class="ui mini basic label mention-status"></span>').attr('title', $status.attr('data-status'));
+        if ($status.attr('data-busy') === 'true') {
+            $label.addClass('red').text($statuses.data('busy-label'));
+        } else {
+            $label.append('<i class="octicon octicon-comment"></i>');
+        }
+        $(this).after($label);
+    });
+}
+
+function initBoard() {
+    var $board = $('#board');
+    if ($board.length === 0) {
+        return;
+    }
+
+    $board.find('.board-card-remove').click(function () {
+        $.post($board.data('url') + '/issues/remove', {
+            "_csrf": csrf,
+            "id": $(this).data('id')
+        }).done(function (data) {
+            window.location.href = data.redirect;
+        });
+    });
+
+    if (!$board.data('writable')) {
+        return;
+    }
+
+    // Drag and drop of cards between columns, and of columns by their headers.
+    var $dragged = null;
+    $board.on('dragstart', '.board-card, .board-column-header', function (e) {
+        $dragged = $(this).hasClass('board-card') ? $(this) : $(this).closest('.board-column');
+        e.originalEvent.dataTransfer.effectAllowed = 'move';
+        e.originalEvent.dataTransfer.setData('text/plain', '');
+        e.stopPropagation();
+    });
+    $board.on('dragend', function () {
+        $dragged = null;
+    });
+    $board.on('dragover', '.board-column[data-id]', function (e) {
+        if ($dragged !== null) {
+            e.preventDefault();
+        }
+    });
+    $board.on('drop', '.board-column[data-id]', function (e) {
+        if ($dragged === null) {
+            return;
+        }
+        e.preventDefault();
+        var $column = $(this);
+
+        if ($dragged.hasClass('board-column')) {
+            if ($dragged.is($column)) {
+                return;
+            }
+            if ($dragged.index() < $column.index()) {
+                $dragged.insertAfter($column);
+            } else {
+                $dragged.insertBefore($column);
+            }
+            $.post($board.data('url') + '/columns/sort', {
+                "_csrf": csrf,
+                "ids": $board.children('.board-column[data-id]').map(function () {
+                    return $(this).data('id');
+                }).get().join(',')
+            });
+            return;
+        }
+
+        var $cards = $column.find('.board-cards');
+        var $target = $(e.target).closest('.board-card');
+        if ($target.length > 0 && !$target.is($dragged)) {
+            $dragged.insertBefore($target);
+        } else if ($target.length === 0) {
+            $cards.append($dragged);
+        }
+        $.post($board.data('url') + '/issues/move', {
+            "_csrf": csrf,
+            "issue": $dragged.data('issue'),
+            "column": $column.data('id'),
+            "order": $cards.children('.board-card').map(function () {
+                return $(this).data('issue');
+            }).get().join(',')
+        });
+    });
 }
 
 function initWikiForm() {
@@ -1433,6 +1662,46 @@ $(document).ready(function () {
         });
     }
 
+    // Upload files pasted or dropped into markdown editors and link them.
+    function uploadPastedFiles($textarea, files) {
+        $.each(files, function (i, file) {
+            var placeholder = '![' + file.name + '](uploading...)';
+            insertAtCursor($textarea[0], placeholder);
+
+            var data = new FormData();
+            data.append('file', file, file.name);
+            $.ajax({
+                url: $textarea.data('paste-url'),
+                type: 'POST',
+                headers: {"X-Csrf-Token": csrf},
+                data: data,
+                processData: false,
+                contentType: false
+            }).done(function (resp) {
+                $textarea.val($textarea.val().replace(placeholder, resp.markdown));
+            }).fail(function () {
+                $textarea.val($textarea.val().replace(placeholder, ''));
+            });
+        });
+    }
+    $(document).on('paste', 'textarea[data-paste-url]', function (e) {
+        var clipboard = e.originalEvent.clipboardData;
+        if (clipboard && clipboard.files && clipboard.files.length > 0) {
+            e.preventDefault();
+            uploadPastedFiles($(this), clipboard.files);
+        }
+    });
+    $(document).on('dragover', 'textarea[data-paste-url]', function (e) {
+        e.preventDefault();
+    });
+    $(document).on('drop', 'textarea[data-paste-url]', function (e) {
+        var transfer = e.originalEvent.dataTransfer;
+        if (transfer && transfer.files && transfer.files.length > 0) {
+            e.preventDefault();
+            uploadPastedFiles($(this), transfer.files);
+        }
+    });
+
     // Emojify
     emojify.setConfig({
         img_dir: suburl + '/img/emoji',
@@ -1443,6 +1712,41 @@ $(document).ready(function () {
         emojify.run(hasEmoji[i]);
     }
 
+    // Dashboard activity feed is loaded after the page, and by page when
+    // scrolling it or switching to the feed of followed users.
+    var $dashboardFeeds = $('#dashboard-feeds');
+    if ($dashboardFeeds.length > 0) {
+        var loadFeeds = function (url, $replaced) {
+            $.get(url, function (data) {
+                var $data = $('<div>').html(data);
+                $data.find('.has-emoji').each(function () {
+                    emojify.run(this);
+                });
+                if ($replaced) {
+                    $replaced.replaceWith($data.contents());
+                } else {
+                    $dashboardFeeds.empty().append($data.contents());
+                }
+            });
+        };
+        loadFeeds($dashboardFeeds.data('url'));
+
+        $('#dashboard-feeds-tabs .item').click(function () {
+            var $tab = $(this);
+            if ($tab.hasClass('active')) {
+                return;
+            }
+            $tab.addClass('active').siblings().removeClass('active');
+            $dashboardFeeds.html('<div class="ui active centered inline loader"></div>');
+            loadFeeds($tab.data('url'));
+        });
+        $dashboardFeeds.on('click', '.feeds-load-more', function () {
+            var $button = $(this);
+            $button.addClass('loading disabled');
+            loadFeeds($button.data('url'), $button);
+        });
+    }
+
     // Clipboard JS
     var clipboard = new Clipboard('.clipboard');
     clipboard.on('success', function (e) {
@@ -1560,6 +1864,8 @@ $(document).ready(function () {
     initWebhook();
     initAdmin();
     initCodeView();
+    initBoard();
+    initMentionStatuses($(document));
     initDashboardSearch();
 
     // Repo clone url.
@@ -17,6 +17,7 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/cron"
+	"code.gitea.io/gitea/modules/packcache"
 	"code.gitea.io/gitea/modules/process"
 	"code.gitea.io/gitea/modules/setting"
 )
@@ -122,6 +123,8 @@ const (
 	syncRepositoryUpdateHook
 	reinitMissingRepository
 	syncExternalUsers
+	reloadConfig
+	deleteOldActions
 )
 
 // Dashboard show admin panel dashboard
@@ -161,6 +164,22 @@ func Dashboard(ctx *context.Context) {
 		case syncExternalUsers:
 			success = ctx.Tr("admin.dashboard.sync_external_users_started")
 			go models.SyncExternalUsers()
+		case reloadConfig:
+			var report *setting.ReloadReport
+			if report, err = setting.Reload(); err != nil {
+				break
+			}
+			if len(report.Applied) > 0 {
+				success = ctx.Tr("admin.dashboard.reload_config_success", strings.Join(report.Applied, ", "))
+			} else {
+				success = ctx.Tr("admin.dashboard.reload_config_unchanged")
+			}
+			if len(report.RequiresRestart) > 0 {
+				ctx.Flash.Info(ctx.Tr("admin.dashboard.reload_config_requires_restart", strings.Join(report.RequiresRestart, ", ")))
+			}
+		case deleteOldActions:
+			success = ctx.Tr("admin.dashboard.delete_old_actions_started")
+			go models.DeleteOldActions()
 		}
 
 		if err != nil {
@@ -173,6 +192,13 @@ func Dashboard(ctx *context.Context) {
 	}
 
 	ctx.Data["Stats"] = models.GetStatistic()
+	actionStats, err := models.GetActionStats()
+	if err != nil {
+		ctx.Handle(500, "GetActionStats", err)
+		return
+	}
+	ctx.Data["ActionStats"] = actionStats
+	ctx.Data["ActionCleanup"] = setting.Cron.ActionCleanup
 	// FIXME: update periodically
 	updateSystemStatus()
 	ctx.Data["SysStatus"] = sysStatus
@@ -210,6 +236,7 @@ func Config(ctx *context.Context) {
 	ctx.Data["LogRootPath"] = setting.LogRootPath
 	ctx.Data["ScriptType"] = setting.ScriptType
 	ctx.Data["ReverseProxyAuthUser"] = setting.ReverseProxyAuthUser
+	ctx.Data["ReverseProxyTrustedProxies"] = setting.ReverseProxyTrustedProxies
 
 	ctx.Data["SSH"] = setting.SSH
 
@@ -226,11 +253,14 @@ func Config(ctx *context.Context) {
 	ctx.Data["CacheAdapter"] = setting.CacheAdapter
 	ctx.Data["CacheInterval"] = setting.CacheInterval
 	ctx.Data["CacheConn"] = setting.CacheConn
+	ctx.Data["RenderCache"] = setting.RenderCache
 
 	ctx.Data["SessionConfig"] = setting.SessionConfig
 
 	ctx.Data["DisableGravatar"] = setting.DisableGravatar
 	ctx.Data["EnableFederatedAvatar"] = setting.EnableFederatedAvatar
+	ctx.Data["EnableAvatarProxy"] = setting.EnableAvatarProxy
+	ctx.Data["AvatarProxyCacheTTL"] = setting.AvatarProxyCacheTTL
 
 	ctx.Data["Git"] = setting.Git
 
@@ -253,5 +283,13 @@ func Monitor(ctx *context.Context) {
 	ctx.Data["PageIsAdminMonitor"] = true
 	ctx.Data["Processes"] = process.GetManager().Processes
 	ctx.Data["Entries"] = cron.ListTasks()
+	if cache := packcache.Default(); cache != nil {
+		stats, err := cache.Stats()
+		if err != nil {
+			ctx.Handle(500, "Stats", err)
+			return
+		}
+		ctx.Data["PackCache"] = stats
+	}
 	ctx.HTML(200, tplMonitor)
 }
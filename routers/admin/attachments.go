@@ -0,0 +1,47 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplAttachments base.TplName = "admin/attachments"
+
+	// attachmentsTopNum is the number of users and issues with the largest
+	// attachments shown in the report.
+	attachmentsTopNum = 20
+)
+
+// attachmentQuota returns the size of an attachment quota in MB, or the
+// word telling it is unlimited.
+func attachmentQuota(ctx *context.Context, quota int64) string {
+	if quota <= 0 {
+		return ctx.Tr("admin.attachments.unlimited")
+	}
+	return base.FileSize(quota * 1024 * 1024)
+}
+
+// Attachments shows the storage used by attachments and who uses the most
+func Attachments(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.attachments")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminAttachments"] = true
+
+	stats, err := models.GetAttachmentStats(attachmentsTopNum)
+	if err != nil {
+		ctx.Handle(500, "GetAttachmentStats", err)
+		return
+	}
+	ctx.Data["Stats"] = stats
+	ctx.Data["UserQuota"] = attachmentQuota(ctx, setting.AttachmentUserQuota)
+	ctx.Data["IssueQuota"] = attachmentQuota(ctx, setting.AttachmentIssueQuota)
+
+	ctx.HTML(200, tplAttachments)
+}
@@ -75,6 +75,9 @@ func NewAuthSource(ctx *context.Context) {
 	ctx.Data["smtp_auth"] = "PLAIN"
 	ctx.Data["is_active"] = true
 	ctx.Data["is_sync_enabled"] = true
+	ctx.Data["sync_create_users"] = true
+	ctx.Data["sync_update_users"] = true
+	ctx.Data["sync_deactivate_users"] = true
 	ctx.Data["AuthSources"] = authSources
 	ctx.Data["SecurityProtocols"] = securityProtocols
 	ctx.Data["SMTPAuths"] = models.SMTPAuths
@@ -192,6 +195,12 @@ func NewAuthSourcePost(ctx *context.Context, form auth.AuthenticationForm) {
 		IsActived:     form.IsActive,
 		IsSyncEnabled: form.IsSyncEnabled,
 		Cfg:           config,
+
+		SyncCreateUsers:     form.SyncCreateUsers,
+		SyncUpdateUsers:     form.SyncUpdateUsers,
+		SyncDeactivateUsers: form.SyncDeactivateUsers,
+		SyncSuspendUsers:    form.SyncSuspendUsers,
+		SyncDryRun:          form.SyncDryRun,
 	}); err != nil {
 		if models.IsErrLoginSourceAlreadyExist(err) {
 			ctx.Data["Err_Name"] = true
@@ -276,6 +285,11 @@ func EditAuthSourcePost(ctx *context.Context, form auth.AuthenticationForm) {
 	source.Name = form.Name
 	source.IsActived = form.IsActive
 	source.IsSyncEnabled = form.IsSyncEnabled
+	source.SyncCreateUsers = form.SyncCreateUsers
+	source.SyncUpdateUsers = form.SyncUpdateUsers
+	source.SyncDeactivateUsers = form.SyncDeactivateUsers
+	source.SyncSuspendUsers = form.SyncSuspendUsers
+	source.SyncDryRun = form.SyncDryRun
 	source.Cfg = config
 	if err := models.UpdateSource(source); err != nil {
 		if models.IsErrOpenIDConnectInitialize(err) {
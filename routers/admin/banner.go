@@ -0,0 +1,79 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplBanner base.TplName = "admin/banner"
+)
+
+// Banner shows the instance wide banner
+func Banner(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.banner")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminBanner"] = true
+	ctx.Data["BannerDesc"] = ctx.Tr("admin.banner.desc")
+
+	banner, err := models.GetBanner(0)
+	if err != nil && !models.IsErrBannerNotExist(err) {
+		ctx.Handle(500, "GetBanner", err)
+		return
+	}
+	ctx.Data["Banner"] = banner
+
+	ctx.HTML(200, tplBanner)
+}
+
+// BannerPost updates the instance wide banner
+func BannerPost(ctx *context.Context, form auth.BannerForm) {
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(setting.AppSubURL + "/admin/banner")
+		return
+	}
+
+	banner := &models.Banner{
+		Message: form.Message,
+		Level:   models.BannerLevel(form.Level),
+	}
+	if len(form.Expires) > 0 {
+		expires, err := time.ParseInLocation("2006-01-02", form.Expires, time.Local)
+		if err != nil {
+			ctx.Flash.Error(ctx.Tr("admin.banner.invalid_expires_format"))
+			ctx.Redirect(setting.AppSubURL + "/admin/banner")
+			return
+		}
+		banner.ExpiresUnix = time.Date(expires.Year(), expires.Month(), expires.Day(), 23, 59, 59, 0, expires.Location()).Unix()
+	}
+	if err := models.UpdateBanner(banner); err != nil {
+		ctx.Handle(500, "UpdateBanner", err)
+		return
+	}
+
+	log.Trace("Banner updated by admin %s", ctx.User.Name)
+	ctx.Flash.Success(ctx.Tr("admin.banner.update_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/banner")
+}
+
+// DeleteBanner deletes the instance wide banner
+func DeleteBanner(ctx *context.Context) {
+	if err := models.DeleteBanner(0); err != nil {
+		ctx.Handle(500, "DeleteBanner", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("admin.banner.delete_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/banner")
+}
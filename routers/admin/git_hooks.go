@@ -0,0 +1,194 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"code.gitea.io/git"
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplGitHookTemplates    base.TplName = "admin/git_hook/list"
+	tplGitHookTemplateEdit base.TplName = "admin/git_hook/edit"
+	tplGlobalGitHookEdit   base.TplName = "admin/git_hook/global"
+)
+
+// GitHookTemplates shows the library of Git hook templates
+func GitHookTemplates(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.git_hooks")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminRepositories"] = true
+
+	templates, err := models.GetGitHookTemplates()
+	if err != nil {
+		ctx.Handle(500, "GetGitHookTemplates", err)
+		return
+	}
+	ctx.Data["HookTemplates"] = templates
+
+	globalHooks, err := models.GetGlobalGitHooks()
+	if err != nil {
+		ctx.Handle(500, "GetGlobalGitHooks", err)
+		return
+	}
+	ctx.Data["GlobalHooks"] = globalHooks
+
+	ctx.HTML(200, tplGitHookTemplates)
+}
+
+func gitHookTemplateError(ctx *context.Context, err error) string {
+	switch {
+	case models.IsErrGitHookTemplateAlreadyExist(err):
+		ctx.Data["Err_Name"] = true
+		return ctx.Tr("admin.git_hooks.name_already_exists")
+	case models.IsErrGitHookParameterInvalid(err):
+		ctx.Data["Err_Parameters"] = true
+		return ctx.Tr("admin.git_hooks.invalid_parameter", err.(models.ErrGitHookParameterInvalid).Name)
+	}
+	return ""
+}
+
+// NewGitHookTemplatePost adds a Git hook template to the library
+func NewGitHookTemplatePost(ctx *context.Context, form auth.GitHookTemplateForm) {
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(setting.AppSubURL + "/admin/git_hooks")
+		return
+	}
+
+	t := &models.GitHookTemplate{
+		Name:        form.Name,
+		Description: form.Description,
+		HookName:    form.HookName,
+		Content:     form.Content,
+		Parameters:  form.Parameters,
+	}
+	if err := models.NewGitHookTemplate(t); err != nil {
+		if msg := gitHookTemplateError(ctx, err); len(msg) > 0 {
+			ctx.Flash.Error(msg)
+			ctx.Redirect(setting.AppSubURL + "/admin/git_hooks")
+			return
+		}
+		ctx.Handle(500, "NewGitHookTemplate", err)
+		return
+	}
+
+	log.Trace("Git hook template added by admin %s: %s", ctx.User.Name, t.Name)
+	ctx.Flash.Success(ctx.Tr("admin.git_hooks.add_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/git_hooks")
+}
+
+// EditGitHookTemplate shows the form for editing a Git hook template
+func EditGitHookTemplate(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.git_hooks.edit")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminRepositories"] = true
+
+	t, err := models.GetGitHookTemplateByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetGitHookTemplateByID", models.IsErrGitHookTemplateNotExist, err)
+		return
+	}
+	ctx.Data["HookTemplate"] = t
+
+	ctx.HTML(200, tplGitHookTemplateEdit)
+}
+
+// EditGitHookTemplatePost updates a Git hook template and the repositories
+// which enabled it
+func EditGitHookTemplatePost(ctx *context.Context, form auth.GitHookTemplateForm) {
+	ctx.Data["Title"] = ctx.Tr("admin.git_hooks.edit")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminRepositories"] = true
+
+	t, err := models.GetGitHookTemplateByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetGitHookTemplateByID", models.IsErrGitHookTemplateNotExist, err)
+		return
+	}
+	ctx.Data["HookTemplate"] = t
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplGitHookTemplateEdit)
+		return
+	}
+
+	t.Name = form.Name
+	t.Description = form.Description
+	t.HookName = form.HookName
+	t.Content = form.Content
+	t.Parameters = form.Parameters
+	if err = models.UpdateGitHookTemplate(t); err != nil {
+		if msg := gitHookTemplateError(ctx, err); len(msg) > 0 {
+			ctx.RenderWithErr(msg, tplGitHookTemplateEdit, &form)
+			return
+		}
+		ctx.Handle(500, "UpdateGitHookTemplate", err)
+		return
+	}
+
+	log.Trace("Git hook template updated by admin %s: %s", ctx.User.Name, t.Name)
+	ctx.Flash.Success(ctx.Tr("admin.git_hooks.update_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/git_hooks")
+}
+
+// DeleteGitHookTemplate deletes a Git hook template and disables it in all
+// repositories
+func DeleteGitHookTemplate(ctx *context.Context) {
+	if err := models.DeleteGitHookTemplate(ctx.QueryInt64("id")); err != nil {
+		ctx.Handle(500, "DeleteGitHookTemplate", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("admin.git_hooks.delete_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/git_hooks")
+}
+
+// EditGlobalGitHook shows the form for editing a system-wide Git hook
+func EditGlobalGitHook(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.git_hooks.global_edit")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminRepositories"] = true
+
+	hook, err := models.GetGlobalGitHook(ctx.Params(":name"))
+	if err != nil {
+		if err == git.ErrNotValidHook {
+			ctx.Handle(404, "GetGlobalGitHook", err)
+		} else {
+			ctx.Handle(500, "GetGlobalGitHook", err)
+		}
+		return
+	}
+	ctx.Data["Hook"] = hook
+
+	ctx.HTML(200, tplGlobalGitHookEdit)
+}
+
+// EditGlobalGitHookPost updates the script of a system-wide Git hook
+func EditGlobalGitHookPost(ctx *context.Context) {
+	hook, err := models.GetGlobalGitHook(ctx.Params(":name"))
+	if err != nil {
+		if err == git.ErrNotValidHook {
+			ctx.Handle(404, "GetGlobalGitHook", err)
+		} else {
+			ctx.Handle(500, "GetGlobalGitHook", err)
+		}
+		return
+	}
+	hook.Content = ctx.Query("content")
+	if err = hook.Update(); err != nil {
+		ctx.Handle(500, "hook.Update", err)
+		return
+	}
+
+	log.Trace("Global %s hook updated by admin %s", hook.Name, ctx.User.Name)
+	ctx.Flash.Success(ctx.Tr("admin.git_hooks.global_update_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/git_hooks")
+}
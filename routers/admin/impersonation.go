@@ -0,0 +1,87 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"github.com/Unknwon/com"
+	"github.com/Unknwon/paginater"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplAuditLogs base.TplName = "admin/audit"
+
+	// auditLogPagingNum is the number of actions shown per page of the audit
+	// log.
+	auditLogPagingNum = 50
+)
+
+// ImpersonateUser makes the admin act as the user for the time allowed by
+// the settings
+func ImpersonateUser(ctx *context.Context) {
+	u, err := models.GetUserByID(ctx.ParamsInt64(":userid"))
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			ctx.Handle(404, "GetUserByID", err)
+		} else {
+			ctx.Handle(500, "GetUserByID", err)
+		}
+		return
+	}
+
+	if !ctx.User.CanImpersonate(u) {
+		ctx.Flash.Error(ctx.Tr("admin.users.cannot_impersonate"))
+		ctx.Redirect(setting.AppSubURL + "/admin/users/" + com.ToStr(u.ID))
+		return
+	}
+
+	ctx.StartImpersonation(u)
+	ctx.Redirect(setting.AppSubURL + "/")
+}
+
+// StopImpersonation switches the admin impersonating a user back to
+// themselves
+func StopImpersonation(ctx *context.Context) {
+	if ctx.Impersonator == nil {
+		ctx.Redirect(setting.AppSubURL + "/")
+		return
+	}
+
+	uid := ctx.User.ID
+	ctx.StopImpersonation()
+	ctx.Redirect(setting.AppSubURL + "/admin/users/" + com.ToStr(uid))
+}
+
+// AuditLogs shows the actions of admins recorded in the audit log
+func AuditLogs(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.audit")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminAudit"] = true
+
+	total, err := models.CountAuditLogs()
+	if err != nil {
+		ctx.Handle(500, "CountAuditLogs", err)
+		return
+	}
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	ctx.Data["Page"] = paginater.New(int(total), auditLogPagingNum, page, 5)
+
+	logs, err := models.GetAuditLogs(page, auditLogPagingNum)
+	if err != nil {
+		ctx.Handle(500, "GetAuditLogs", err)
+		return
+	}
+	ctx.Data["AuditLogs"] = logs
+	ctx.Data["Total"] = total
+
+	ctx.HTML(200, tplAuditLogs)
+}
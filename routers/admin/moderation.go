@@ -0,0 +1,156 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplModeration base.TplName = "admin/moderation"
+)
+
+// Moderation shows instance wide blocked words and all content held for moderation
+func Moderation(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.moderation")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminModeration"] = true
+
+	words, err := models.GetBlockedWords(0)
+	if err != nil {
+		ctx.Handle(500, "GetBlockedWords", err)
+		return
+	}
+	ctx.Data["BlockedWords"] = words
+
+	items, err := models.GetModerationItems(0)
+	if err != nil {
+		ctx.Handle(500, "GetModerationItems", err)
+		return
+	}
+	ctx.Data["ModerationItems"] = items
+
+	showClosed := ctx.Query("reports") == "closed"
+	reports, err := models.GetAbuseReports(!showClosed)
+	if err != nil {
+		ctx.Handle(500, "GetAbuseReports", err)
+		return
+	}
+	ctx.Data["AbuseReports"] = reports
+	ctx.Data["ShowClosedReports"] = showClosed
+
+	ctx.HTML(200, tplModeration)
+}
+
+// AddBlockedWord adds an instance wide blocked word
+func AddBlockedWord(ctx *context.Context, form auth.BlockedWordForm) {
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(setting.AppSubURL + "/admin/moderation")
+		return
+	}
+
+	action := models.BlockedWordReject
+	if form.Moderate {
+		action = models.BlockedWordModerate
+	}
+	if err := models.AddBlockedWord(0, form.Word, action); err != nil {
+		if models.IsErrBlockedWordAlreadyExist(err) {
+			ctx.Flash.Error(ctx.Tr("admin.moderation.word_already_exists"))
+			ctx.Redirect(setting.AppSubURL + "/admin/moderation")
+			return
+		}
+		ctx.Handle(500, "AddBlockedWord", err)
+		return
+	}
+
+	log.Trace("Blocked word added by admin %s: %s", ctx.User.Name, form.Word)
+	ctx.Flash.Success(ctx.Tr("admin.moderation.add_word_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/moderation")
+}
+
+// DeleteBlockedWord deletes an instance wide blocked word
+func DeleteBlockedWord(ctx *context.Context) {
+	if err := models.DeleteBlockedWord(0, ctx.QueryInt64("id")); err != nil {
+		ctx.Handle(500, "DeleteBlockedWord", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("admin.moderation.delete_word_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/moderation")
+}
+
+// ApproveModerationItem publishes content held for moderation
+func ApproveModerationItem(ctx *context.Context) {
+	item, err := models.GetModerationItem(0, ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetModerationItem", models.IsErrModerationItemNotExist, err)
+		return
+	}
+
+	if err = item.Approve(); err != nil {
+		ctx.Handle(500, "Approve", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("admin.moderation.approve_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/moderation")
+}
+
+// RejectModerationItem discards content held for moderation
+func RejectModerationItem(ctx *context.Context) {
+	item, err := models.GetModerationItem(0, ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetModerationItem", models.IsErrModerationItemNotExist, err)
+		return
+	}
+
+	if err = item.Reject(); err != nil {
+		ctx.Handle(500, "Reject", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("admin.moderation.reject_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/moderation")
+}
+
+// ResolveAbuseReport closes an abuse report after acting on the reported content
+func ResolveAbuseReport(ctx *context.Context) {
+	report, err := models.GetAbuseReportByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetAbuseReportByID", models.IsErrAbuseReportNotExist, err)
+		return
+	}
+
+	if err = report.Resolve(ctx.User); err != nil {
+		ctx.Handle(500, "Resolve", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("admin.moderation.resolve_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/moderation")
+}
+
+// DismissAbuseReport closes an abuse report without any action
+func DismissAbuseReport(ctx *context.Context) {
+	report, err := models.GetAbuseReportByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetAbuseReportByID", models.IsErrAbuseReportNotExist, err)
+		return
+	}
+
+	if err = report.Dismiss(ctx.User); err != nil {
+		ctx.Handle(500, "Dismiss", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("admin.moderation.dismiss_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/moderation")
+}
@@ -0,0 +1,130 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplProfileFields    base.TplName = "admin/profile_field/list"
+	tplProfileFieldEdit base.TplName = "admin/profile_field/edit"
+)
+
+// ProfileFields shows the custom profile fields of users
+func ProfileFields(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.profile_fields")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminUsers"] = true
+
+	fields, err := models.GetProfileFields()
+	if err != nil {
+		ctx.Handle(500, "GetProfileFields", err)
+		return
+	}
+	ctx.Data["ProfileFields"] = fields
+
+	ctx.HTML(200, tplProfileFields)
+}
+
+// NewProfileFieldPost adds a custom profile field
+func NewProfileFieldPost(ctx *context.Context, form auth.ProfileFieldForm) {
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(setting.AppSubURL + "/admin/profile_fields")
+		return
+	}
+
+	field := &models.ProfileField{
+		Name:        form.Name,
+		Description: form.Description,
+		Type:        models.ProfileFieldType(form.Type),
+		Visibility:  models.ProfileFieldVisibility(form.Visibility),
+		Sorting:     form.Sorting,
+	}
+	if err := models.NewProfileField(field); err != nil {
+		if models.IsErrProfileFieldAlreadyExist(err) {
+			ctx.Flash.Error(ctx.Tr("admin.profile_fields.name_already_exists"))
+			ctx.Redirect(setting.AppSubURL + "/admin/profile_fields")
+			return
+		}
+		ctx.Handle(500, "NewProfileField", err)
+		return
+	}
+
+	log.Trace("Profile field added by admin %s: %s", ctx.User.Name, field.Name)
+	ctx.Flash.Success(ctx.Tr("admin.profile_fields.add_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/profile_fields")
+}
+
+// EditProfileField shows the form for editing a custom profile field
+func EditProfileField(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.profile_fields.edit")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminUsers"] = true
+
+	field, err := models.GetProfileFieldByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetProfileFieldByID", models.IsErrProfileFieldNotExist, err)
+		return
+	}
+	ctx.Data["ProfileField"] = field
+
+	ctx.HTML(200, tplProfileFieldEdit)
+}
+
+// EditProfileFieldPost updates a custom profile field
+func EditProfileFieldPost(ctx *context.Context, form auth.ProfileFieldForm) {
+	ctx.Data["Title"] = ctx.Tr("admin.profile_fields.edit")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminUsers"] = true
+
+	field, err := models.GetProfileFieldByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetProfileFieldByID", models.IsErrProfileFieldNotExist, err)
+		return
+	}
+	ctx.Data["ProfileField"] = field
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplProfileFieldEdit)
+		return
+	}
+
+	field.Name = form.Name
+	field.Description = form.Description
+	field.Type = models.ProfileFieldType(form.Type)
+	field.Visibility = models.ProfileFieldVisibility(form.Visibility)
+	field.Sorting = form.Sorting
+	if err = models.UpdateProfileField(field); err != nil {
+		if models.IsErrProfileFieldAlreadyExist(err) {
+			ctx.Data["Err_Name"] = true
+			ctx.RenderWithErr(ctx.Tr("admin.profile_fields.name_already_exists"), tplProfileFieldEdit, &form)
+			return
+		}
+		ctx.Handle(500, "UpdateProfileField", err)
+		return
+	}
+
+	log.Trace("Profile field updated by admin %s: %s", ctx.User.Name, field.Name)
+	ctx.Flash.Success(ctx.Tr("admin.profile_fields.update_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/profile_fields")
+}
+
+// DeleteProfileField deletes a custom profile field and its values
+func DeleteProfileField(ctx *context.Context) {
+	if err := models.DeleteProfileField(ctx.QueryInt64("id")); err != nil {
+		ctx.Handle(500, "DeleteProfileField", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("admin.profile_fields.delete_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/profile_fields")
+}
@@ -0,0 +1,140 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplQuarantine base.TplName = "admin/quarantine"
+)
+
+// Quarantine shows the deleted repositories and users which can still be restored
+func Quarantine(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.quarantine")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminQuarantine"] = true
+	ctx.Data["DeletionRetention"] = setting.Admin.DeletionRetention
+
+	repos, err := models.GetQuarantinedRepositories()
+	if err != nil {
+		ctx.Handle(500, "GetQuarantinedRepositories", err)
+		return
+	}
+	ctx.Data["Repos"] = repos
+
+	users, err := models.GetQuarantinedUsers()
+	if err != nil {
+		ctx.Handle(500, "GetQuarantinedUsers", err)
+		return
+	}
+	ctx.Data["Users"] = users
+
+	ctx.HTML(200, tplQuarantine)
+}
+
+// getQuarantinedRepo returns the deleted repository of the request.
+func getQuarantinedRepo(ctx *context.Context) *models.Repository {
+	repo, err := models.GetRepositoryByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetRepositoryByID", models.IsErrRepoNotExist, err)
+		return nil
+	} else if !repo.IsDeleted() {
+		ctx.Handle(404, "GetRepositoryByID", nil)
+		return nil
+	}
+	return repo
+}
+
+// RestoreQuarantinedRepo brings back a deleted repository
+func RestoreQuarantinedRepo(ctx *context.Context) {
+	repo := getQuarantinedRepo(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.RestoreRepository(repo.ID); err != nil {
+		if models.IsErrRepoOwnerDeleted(err) {
+			ctx.Flash.Error(ctx.Tr("admin.quarantine.owner_deleted"))
+			ctx.Redirect(setting.AppSubURL + "/admin/quarantine")
+			return
+		}
+		ctx.Handle(500, "RestoreRepository", err)
+		return
+	}
+	log.Trace("Repository restored by admin %s: %d", ctx.User.Name, repo.ID)
+
+	ctx.Flash.Success(ctx.Tr("admin.quarantine.restore_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/quarantine")
+}
+
+// PurgeQuarantinedRepo permanently deletes a deleted repository
+func PurgeQuarantinedRepo(ctx *context.Context) {
+	repo := getQuarantinedRepo(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteRepository(repo.OwnerID, repo.ID); err != nil {
+		ctx.Handle(500, "DeleteRepository", err)
+		return
+	}
+	log.Trace("Repository purged by admin %s: %d", ctx.User.Name, repo.ID)
+
+	ctx.Flash.Success(ctx.Tr("admin.quarantine.purge_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/quarantine")
+}
+
+// getQuarantinedUser returns the deleted user of the request.
+func getQuarantinedUser(ctx *context.Context) *models.User {
+	u, err := models.GetUserByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetUserByID", models.IsErrUserNotExist, err)
+		return nil
+	} else if !u.IsDeleted() {
+		ctx.Handle(404, "GetUserByID", nil)
+		return nil
+	}
+	return u
+}
+
+// RestoreQuarantinedUser brings back a deleted user
+func RestoreQuarantinedUser(ctx *context.Context) {
+	u := getQuarantinedUser(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.RestoreUser(u.ID); err != nil {
+		ctx.Handle(500, "RestoreUser", err)
+		return
+	}
+	log.Trace("Account restored by admin %s: %s", ctx.User.Name, u.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.quarantine.restore_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/quarantine")
+}
+
+// PurgeQuarantinedUser permanently deletes a deleted user
+func PurgeQuarantinedUser(ctx *context.Context) {
+	u := getQuarantinedUser(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.PurgeUser(u); err != nil {
+		ctx.Handle(500, "PurgeUser", err)
+		return
+	}
+	log.Trace("Account purged by admin %s: %s", ctx.User.Name, u.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.quarantine.purge_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/quarantine")
+}
@@ -24,10 +24,11 @@ func Repos(ctx *context.Context) {
 	ctx.Data["PageIsAdminRepositories"] = true
 
 	routers.RenderRepoSearch(ctx, &routers.RepoSearchOptions{
-		Ranger:   models.Repositories,
-		Private:  true,
-		PageSize: setting.UI.Admin.RepoPagingNum,
-		TplName:  tplRepos,
+		Ranger:      models.Repositories,
+		Private:     true,
+		LoadMirrors: true,
+		PageSize:    setting.UI.Admin.RepoPagingNum,
+		TplName:     tplRepos,
 	})
 }
 
@@ -39,8 +40,8 @@ func DeleteRepo(ctx *context.Context) {
 		return
 	}
 
-	if err := models.DeleteRepository(repo.MustOwner().ID, repo.ID); err != nil {
-		ctx.Handle(500, "DeleteRepository", err)
+	if err := models.SoftDeleteRepository(repo.MustOwner().ID, repo.ID); err != nil {
+		ctx.Handle(500, "SoftDeleteRepository", err)
 		return
 	}
 	log.Trace("Repository deleted: %s/%s", repo.MustOwner().Name, repo.Name)
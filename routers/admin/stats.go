@@ -0,0 +1,33 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const tplStats base.TplName = "admin/stats"
+
+// Stats shows the number of rows of the tables of the database and the
+// storage used on disk
+func Stats(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.stats")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminStats"] = true
+
+	stats, err := models.GetStorageStatistic()
+	if err != nil {
+		ctx.Handle(500, "GetStorageStatistic", err)
+		return
+	}
+	ctx.Data["Stats"] = stats
+	ctx.Data["LFSEnabled"] = setting.LFS.StartServer
+	ctx.Data["MetricsEnabled"] = setting.Metrics.Enabled
+
+	ctx.HTML(200, tplStats)
+}
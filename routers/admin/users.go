@@ -36,6 +36,8 @@ func Users(ctx *context.Context) {
 		Ranger:   models.Users,
 		PageSize: setting.UI.Admin.UserPagingNum,
 		TplName:  tplUsers,
+
+		LoadProfileFields: true,
 	})
 }
 
@@ -160,10 +162,21 @@ func EditUser(ctx *context.Context) {
 	ctx.Data["PageIsAdminUsers"] = true
 	ctx.Data["DisableRegularOrgCreation"] = setting.Admin.DisableRegularOrgCreation
 
-	prepareUserInfo(ctx)
+	u := prepareUserInfo(ctx)
 	if ctx.Written() {
 		return
 	}
+	ctx.Data["CanImpersonate"] = ctx.User.CanImpersonate(u)
+	ctx.Data["ImpersonationDuration"] = setting.Admin.ImpersonationDuration
+
+	if u.IsSuspended {
+		suspendedBy, err := u.GetSuspendedBy()
+		if err != nil {
+			ctx.Handle(500, "GetSuspendedBy", err)
+			return
+		}
+		ctx.Data["SuspendedBy"] = suspendedBy
+	}
 
 	ctx.HTML(200, tplUserEdit)
 }
@@ -241,7 +254,7 @@ func DeleteUser(ctx *context.Context) {
 		return
 	}
 
-	if err = models.DeleteUser(u); err != nil {
+	if err = models.SoftDeleteUser(u); err != nil {
 		switch {
 		case models.IsErrUserOwnRepos(err):
 			ctx.Flash.Error(ctx.Tr("admin.users.still_own_repo"))
@@ -254,7 +267,7 @@ func DeleteUser(ctx *context.Context) {
 				"redirect": setting.AppSubURL + "/admin/users/" + ctx.Params(":userid"),
 			})
 		default:
-			ctx.Handle(500, "DeleteUser", err)
+			ctx.Handle(500, "SoftDeleteUser", err)
 		}
 		return
 	}
@@ -265,3 +278,58 @@ func DeleteUser(ctx *context.Context) {
 		"redirect": setting.AppSubURL + "/admin/users",
 	})
 }
+
+// SuspendUser suspends the user with the reason given by the admin
+func SuspendUser(ctx *context.Context, form auth.AdminSuspendUserForm) {
+	u, err := models.GetUserByID(ctx.ParamsInt64(":userid"))
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			ctx.Handle(404, "GetUserByID", err)
+		} else {
+			ctx.Handle(500, "GetUserByID", err)
+		}
+		return
+	}
+	link := setting.AppSubURL + "/admin/users/" + com.ToStr(u.ID)
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(link)
+		return
+	} else if u.IsOrganization() || u.ID == ctx.User.ID {
+		ctx.Flash.Error(ctx.Tr("admin.users.cannot_suspend"))
+		ctx.Redirect(link)
+		return
+	}
+
+	if err = models.SuspendUser(u, ctx.User, form.Reason); err != nil {
+		ctx.Handle(500, "SuspendUser", err)
+		return
+	}
+	log.Trace("Account suspended by admin (%s): %s", ctx.User.Name, u.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.users.suspend_success"))
+	ctx.Redirect(link)
+}
+
+// UnsuspendUser lifts the suspension of the user
+func UnsuspendUser(ctx *context.Context) {
+	u, err := models.GetUserByID(ctx.ParamsInt64(":userid"))
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			ctx.Handle(404, "GetUserByID", err)
+		} else {
+			ctx.Handle(500, "GetUserByID", err)
+		}
+		return
+	}
+
+	if err = models.UnsuspendUser(u); err != nil {
+		ctx.Handle(500, "UnsuspendUser", err)
+		return
+	}
+	log.Trace("Account suspension lifted by admin (%s): %s", ctx.User.Name, u.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.users.unsuspend_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/users/" + com.ToStr(u.ID))
+}
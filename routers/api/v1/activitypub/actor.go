@@ -0,0 +1,273 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package activitypub
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+)
+
+// ContentType is the media type of ActivityPub documents.
+const ContentType = "application/activity+json"
+
+// Contexts of the JSON-LD documents of the actors: ActivityStreams, the
+// security vocabulary for their public key, and ForgeFed for repositories.
+var (
+	actorContext = []string{
+		"https://www.w3.org/ns/activitystreams",
+		"https://w3id.org/security/v1",
+	}
+	repositoryContext = []string{
+		"https://www.w3.org/ns/activitystreams",
+		"https://w3id.org/security/v1",
+		"https://forgefed.org/ns",
+	}
+)
+
+// Image is an ActivityStreams image, the icon of an actor.
+type Image struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// PublicKey is the key other instances verify the signatures of the
+// activities of an actor with.
+// swagger:model ActivityPubPublicKey
+type PublicKey struct {
+	ID           string `json:"id"`
+	Owner        string `json:"owner"`
+	PublicKeyPem string `json:"publicKeyPem"`
+}
+
+// Actor is an ActivityPub actor, a user, an organization or a repository.
+type Actor struct {
+	Context           []string   `json:"@context"`
+	ID                string     `json:"id"`
+	Type              string     `json:"type"`
+	PreferredUsername string     `json:"preferredUsername"`
+	Name              string     `json:"name,omitempty"`
+	Summary           string     `json:"summary,omitempty"`
+	URL               string     `json:"url"`
+	Icon              *Image     `json:"icon,omitempty"`
+	AttributedTo      string     `json:"attributedTo,omitempty"`
+	Inbox             string     `json:"inbox"`
+	Outbox            string     `json:"outbox"`
+	PublicKey         *PublicKey `json:"publicKey"`
+}
+
+// OrderedCollection is an ActivityStreams ordered collection, the outbox of an
+// actor.
+type OrderedCollection struct {
+	Context      []string      `json:"@context"`
+	ID           string        `json:"id"`
+	Type         string        `json:"type"`
+	TotalItems   int           `json:"totalItems"`
+	OrderedItems []interface{} `json:"orderedItems"`
+}
+
+// render writes given ActivityPub document as the response.
+func render(ctx *context.APIContext, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		ctx.Error(500, "Marshal", err)
+		return
+	}
+	ctx.Resp.Header().Set("Content-Type", ContentType+"; charset=utf-8")
+	ctx.Resp.WriteHeader(200)
+	ctx.Resp.Write(data)
+}
+
+// newActor returns the actor of given ID, signing with the key of given actor
+// type and ID.
+func newActor(actorType models.FederationActorType, actorID int64, id string) (*Actor, error) {
+	key, err := models.GetFederationKey(actorType, actorID)
+	if err != nil {
+		return nil, fmt.Errorf("GetFederationKey: %v", err)
+	}
+	return &Actor{
+		Context: actorContext,
+		ID:      id,
+		Inbox:   id + "/inbox",
+		Outbox:  id + "/outbox",
+		PublicKey: &PublicKey{
+			ID:           id + "#main-key",
+			Owner:        id,
+			PublicKeyPem: key.PublicKey,
+		},
+	}, nil
+}
+
+// emptyOutbox returns the outbox of the actor of given ID, activities are not
+// published yet.
+func emptyOutbox(id string) *OrderedCollection {
+	return &OrderedCollection{
+		Context:      actorContext[:1],
+		ID:           id + "/outbox",
+		Type:         "OrderedCollection",
+		OrderedItems: []interface{}{},
+	}
+}
+
+// getUser returns the user of the :username parameter, or nil after writing
+// the error response.
+func getUser(ctx *context.APIContext) *models.User {
+	u, err := models.GetUserByName(ctx.Params(":username"))
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "GetUserByName", err)
+		}
+		return nil
+	}
+	return u
+}
+
+// getRepository returns the public repository of the :username and :reponame
+// parameters, or nil after writing the error response.
+func getRepository(ctx *context.APIContext) *models.Repository {
+	owner := getUser(ctx)
+	if owner == nil {
+		return nil
+	}
+	repo, err := models.GetRepositoryByName(owner.ID, ctx.Params(":reponame"))
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "GetRepositoryByName", err)
+		}
+		return nil
+	} else if repo.IsPrivate {
+		ctx.Status(404)
+		return nil
+	}
+	repo.Owner = owner
+	return repo
+}
+
+// Person returns the actor of a user or an organization
+func Person(ctx *context.APIContext) {
+	// swagger:route GET /activitypub/user/{username} activitypubPerson
+	//
+	//     Produces:
+	//     - application/activity+json
+	//
+	//     Responses:
+	//       200: ActivityPubActor
+	//       404: notFound
+	//       500: error
+
+	u := getUser(ctx)
+	if u == nil {
+		return
+	}
+	actor, err := newActor(models.FederationActorUser, u.ID, u.ActorURL())
+	if err != nil {
+		ctx.Error(500, "newActor", err)
+		return
+	}
+
+	actor.Type = "Person"
+	if u.IsOrganization() {
+		actor.Type = "Organization"
+	}
+	actor.PreferredUsername = u.Name
+	actor.Name = u.FullName
+	actor.Summary = u.Description
+	actor.URL = u.HTMLURL()
+	actor.Icon = &Image{Type: "Image", URL: u.AvatarLink()}
+	render(ctx, actor)
+}
+
+// PersonOutbox returns the activities of a user or an organization
+func PersonOutbox(ctx *context.APIContext) {
+	// swagger:route GET /activitypub/user/{username}/outbox activitypubPersonOutbox
+	//
+	//     Produces:
+	//     - application/activity+json
+	//
+	//     Responses:
+	//       200: ActivityPubOrderedCollection
+	//       404: notFound
+	//       500: error
+
+	u := getUser(ctx)
+	if u == nil {
+		return
+	}
+	render(ctx, emptyOutbox(u.ActorURL()))
+}
+
+// Repository returns the actor of a public repository
+func Repository(ctx *context.APIContext) {
+	// swagger:route GET /activitypub/repo/{username}/{reponame} activitypubRepository
+	//
+	//     Produces:
+	//     - application/activity+json
+	//
+	//     Responses:
+	//       200: ActivityPubActor
+	//       404: notFound
+	//       500: error
+
+	repo := getRepository(ctx)
+	if repo == nil {
+		return
+	}
+	actor, err := newActor(models.FederationActorRepo, repo.ID, repo.ActorURL())
+	if err != nil {
+		ctx.Error(500, "newActor", err)
+		return
+	}
+
+	actor.Context = repositoryContext
+	actor.Type = "Repository"
+	actor.PreferredUsername = repo.Name
+	actor.Name = repo.FullName()
+	actor.Summary = repo.Description
+	actor.URL = repo.HTMLURL()
+	actor.Icon = &Image{Type: "Image", URL: repo.AvatarLink()}
+	actor.AttributedTo = repo.Owner.ActorURL()
+	render(ctx, actor)
+}
+
+// RepositoryOutbox returns the activities of a public repository
+func RepositoryOutbox(ctx *context.APIContext) {
+	// swagger:route GET /activitypub/repo/{username}/{reponame}/outbox activitypubRepositoryOutbox
+	//
+	//     Produces:
+	//     - application/activity+json
+	//
+	//     Responses:
+	//       200: ActivityPubOrderedCollection
+	//       404: notFound
+	//       500: error
+
+	repo := getRepository(ctx)
+	if repo == nil {
+		return
+	}
+	render(ctx, emptyOutbox(repo.ActorURL()))
+}
+
+// Inbox receives the activities sent to an actor by other instances, they
+// are not handled yet
+func Inbox(ctx *context.APIContext) {
+	// swagger:route POST /activitypub/user/{username}/inbox activitypubInbox
+	//
+	//     Responses:
+	//       501: error
+
+	// swagger:route POST /activitypub/repo/{username}/{reponame}/inbox activitypubRepositoryInbox
+	//
+	//     Responses:
+	//       501: error
+
+	ctx.Error(501, "", "activities are not handled yet")
+}
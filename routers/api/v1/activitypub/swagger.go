@@ -0,0 +1,42 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package activitypub
+
+// The declarations below are only read by the swagger generator, they
+// describe the parameters and responses of the routes of this package.
+
+// swagger:parameters activitypubPerson activitypubPersonOutbox activitypubInbox activitypubRepository activitypubRepositoryOutbox activitypubRepositoryInbox
+type swaggerUsernameParams struct {
+	// name of the user, or of the owner of the repository
+	//
+	// in: path
+	// required: true
+	Username string `json:"username"`
+}
+
+// swagger:parameters activitypubRepository activitypubRepositoryOutbox activitypubRepositoryInbox
+type swaggerRepoParams struct {
+	// name of the repository
+	//
+	// in: path
+	// required: true
+	Reponame string `json:"reponame"`
+}
+
+// ActivityPubActor is an ActivityPub actor, a user, an organization or a
+// repository
+// swagger:response ActivityPubActor
+type swaggerActor struct {
+	// in: body
+	Body Actor
+}
+
+// ActivityPubOrderedCollection is an ActivityStreams ordered collection, the
+// outbox of an actor
+// swagger:response ActivityPubOrderedCollection
+type swaggerOrderedCollection struct {
+	// in: body
+	Body OrderedCollection
+}
@@ -0,0 +1,73 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+)
+
+// TableStats represents the number of rows of a table of the database
+type TableStats struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+}
+
+// StorageStats represents the statistics of the database and the storage
+// used on disk
+type StorageStats struct {
+	Tables           []*TableStats `json:"tables"`
+	Repos            int64         `json:"repos"`
+	RepoSize         int64         `json:"repo_size"`
+	LFSObjects       int64         `json:"lfs_objects"`
+	LFSSize          int64         `json:"lfs_size"`
+	Attachments      int64         `json:"attachments"`
+	AttachmentSize   int64         `json:"attachment_size"`
+	AvatarSize       int64         `json:"avatar_size"`
+	RepoAvatarSize   int64         `json:"repo_avatar_size"`
+	HookTasks        int64         `json:"hook_tasks"`
+	PendingHookTasks int64         `json:"pending_hook_tasks"`
+	Sessions         int64         `json:"sessions"`
+	RecentSessions   int64         `json:"recent_sessions"`
+}
+
+// GetStats returns the statistics of the database and the storage
+func GetStats(ctx *context.APIContext) {
+	// swagger:route GET /admin/stats admin adminGetStats
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: StorageStats
+	//       403: forbidden
+	//       500: error
+
+	stats, err := models.GetStorageStatistic()
+	if err != nil {
+		ctx.Error(500, "GetStorageStatistic", err)
+		return
+	}
+
+	tables := make([]*TableStats, len(stats.Tables))
+	for i, table := range stats.Tables {
+		tables[i] = &TableStats{Name: table.Name, Rows: table.Rows}
+	}
+	ctx.JSON(200, &StorageStats{
+		Tables:           tables,
+		Repos:            stats.NumRepos,
+		RepoSize:         stats.RepoSize,
+		LFSObjects:       stats.NumLFSObjects,
+		LFSSize:          stats.LFSSize,
+		Attachments:      stats.NumAttachments,
+		AttachmentSize:   stats.AttachmentSize,
+		AvatarSize:       stats.AvatarSize,
+		RepoAvatarSize:   stats.RepoAvatarSize,
+		HookTasks:        stats.NumHookTasks,
+		PendingHookTasks: stats.NumPendingHookTasks,
+		Sessions:         stats.NumSessions,
+		RecentSessions:   stats.NumRecentSessions,
+	})
+}
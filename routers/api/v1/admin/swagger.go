@@ -0,0 +1,69 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	api "code.gitea.io/sdk/gitea"
+)
+
+// The declarations below are only read by the swagger generator, they
+// describe the parameters and responses of the routes of this package.
+
+// swagger:parameters adminEditUser adminDeleteUser adminCreatePublicKey adminCreateOrg adminCreateRepo adminGetSuspension adminSuspendUser adminUnsuspendUser
+type swaggerUsernameParams struct {
+	// in: path
+	// required: true
+	Username string `json:"username"`
+}
+
+// swagger:parameters adminCreateUser
+type swaggerCreateUserParams struct {
+	// in: body
+	Body api.CreateUserOption
+}
+
+// swagger:parameters adminEditUser
+type swaggerEditUserParams struct {
+	// in: body
+	Body api.EditUserOption
+}
+
+// swagger:parameters adminCreatePublicKey
+type swaggerCreateKeyParams struct {
+	// in: body
+	Body api.CreateKeyOption
+}
+
+// swagger:parameters adminCreateOrg
+type swaggerCreateOrgParams struct {
+	// in: body
+	Body api.CreateOrgOption
+}
+
+// swagger:parameters adminCreateRepo
+type swaggerCreateRepoParams struct {
+	// in: body
+	Body api.CreateRepoOption
+}
+
+// swagger:parameters adminSuspendUser
+type swaggerSuspendUserParams struct {
+	// in: body
+	Body SuspendUserOption
+}
+
+// StorageStats represents the statistics of the database and the storage
+// swagger:response StorageStats
+type swaggerStorageStats struct {
+	// in: body
+	Body StorageStats
+}
+
+// Suspension represents the suspension of a user
+// swagger:response Suspension
+type swaggerSuspension struct {
+	// in: body
+	Body Suspension
+}
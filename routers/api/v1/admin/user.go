@@ -5,6 +5,8 @@
 package admin
 
 import (
+	"time"
+
 	api "code.gitea.io/sdk/gitea"
 
 	"code.gitea.io/gitea/models"
@@ -37,6 +39,20 @@ func parseLoginSource(ctx *context.APIContext, u *models.User, sourceID int64, l
 // CreateUser api for creating a user
 // see https://github.com/gogits/go-gogs-client/wiki/Administration-Users#create-a-new-user
 func CreateUser(ctx *context.APIContext, form api.CreateUserOption) {
+	// swagger:route POST /admin/users admin adminCreateUser
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       201: User
+	//       403: forbidden
+	//       422: validationError
+	//       500: error
+
 	u := &models.User{
 		Name:      form.Username,
 		FullName:  form.FullName,
@@ -75,6 +91,21 @@ func CreateUser(ctx *context.APIContext, form api.CreateUserOption) {
 // EditUser api for modifying a user's information
 // see https://github.com/gogits/go-gogs-client/wiki/Administration-Users#edit-an-existing-user
 func EditUser(ctx *context.APIContext, form api.EditUserOption) {
+	// swagger:route PATCH /admin/users/{username} admin adminEditUser
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: User
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
 	u := user.GetUserByParams(ctx)
 	if ctx.Written() {
 		return
@@ -132,17 +163,29 @@ func EditUser(ctx *context.APIContext, form api.EditUserOption) {
 // DeleteUser api for deleting a user
 // https://github.com/gogits/go-gogs-client/wiki/Administration-Users#delete-a-user
 func DeleteUser(ctx *context.APIContext) {
+	// swagger:route DELETE /admin/users/{username} admin adminDeleteUser
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
 	u := user.GetUserByParams(ctx)
 	if ctx.Written() {
 		return
 	}
 
-	if err := models.DeleteUser(u); err != nil {
+	if err := models.SoftDeleteUser(u); err != nil {
 		if models.IsErrUserOwnRepos(err) ||
 			models.IsErrUserHasOrgs(err) {
 			ctx.Error(422, "", err)
 		} else {
-			ctx.Error(500, "DeleteUser", err)
+			ctx.Error(500, "SoftDeleteUser", err)
 		}
 		return
 	}
@@ -151,9 +194,134 @@ func DeleteUser(ctx *context.APIContext) {
 	ctx.Status(204)
 }
 
+// SuspendUserOption options for suspending a user
+type SuspendUserOption struct {
+	Reason string `json:"reason" binding:"Required;MaxSize(255)"`
+}
+
+// Suspension represents the suspension of a user
+type Suspension struct {
+	Suspended   bool       `json:"suspended"`
+	Reason      string     `json:"reason,omitempty"`
+	SuspendedBy *api.User  `json:"suspended_by,omitempty"`
+	Created     *time.Time `json:"created_at,omitempty"`
+}
+
+// GetSuspension api for getting the suspension of a user
+func GetSuspension(ctx *context.APIContext) {
+	// swagger:route GET /admin/users/{username}/suspension admin adminGetSuspension
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Suspension
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
+	u := user.GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	s := &Suspension{Suspended: u.IsSuspended}
+	if u.IsSuspended {
+		suspendedBy, err := u.GetSuspendedBy()
+		if err != nil {
+			ctx.Error(500, "GetSuspendedBy", err)
+			return
+		}
+		s.Reason = u.SuspendReason
+		s.Created = &u.Suspended
+		if suspendedBy != nil {
+			s.SuspendedBy = suspendedBy.APIFormat()
+		}
+	}
+	ctx.JSON(200, s)
+}
+
+// SuspendUser api for suspending a user
+func SuspendUser(ctx *context.APIContext, form SuspendUserOption) {
+	// swagger:route PUT /admin/users/{username}/suspension admin adminSuspendUser
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
+	u := user.GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if u.IsOrganization() || u.ID == ctx.User.ID {
+		ctx.Error(422, "", "organizations and your own account can't be suspended")
+		return
+	}
+
+	if err := models.SuspendUser(u, ctx.User, form.Reason); err != nil {
+		ctx.Error(500, "SuspendUser", err)
+		return
+	}
+	log.Trace("Account suspended by admin(%s): %s", ctx.User.Name, u.Name)
+
+	ctx.Status(204)
+}
+
+// UnsuspendUser api for lifting the suspension of a user
+func UnsuspendUser(ctx *context.APIContext) {
+	// swagger:route DELETE /admin/users/{username}/suspension admin adminUnsuspendUser
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
+	u := user.GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.UnsuspendUser(u); err != nil {
+		ctx.Error(500, "UnsuspendUser", err)
+		return
+	}
+	log.Trace("Account suspension lifted by admin(%s): %s", ctx.User.Name, u.Name)
+
+	ctx.Status(204)
+}
+
 // CreatePublicKey api for creating a public key to a user
 // see https://github.com/gogits/go-gogs-client/wiki/Administration-Users#create-a-public-key-for-user
 func CreatePublicKey(ctx *context.APIContext, form api.CreateKeyOption) {
+	// swagger:route POST /admin/users/{username}/keys admin adminCreatePublicKey
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       201: PublicKey
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
 	u := user.GetUserByParams(ctx)
 	if ctx.Written() {
 		return
@@ -2,9 +2,9 @@
 // Use of this source code is governed by a MIT-style
 // license that can be found in the LICENSE file.
 
-//go:generate swagger generate spec -o ../../../public/swagger.v1.json
-//go:generate sed -i "s;\".ref\": \"#/definitions/GPGKey\";\"type\": \"object\";g" ../../../public/swagger.v1.json
-//go:generate sed -i "s;^          \".ref\": \"#/definitions/Repository\";          \"type\": \"object\";g" ../../../public/swagger.v1.json
+//go:generate swagger generate spec -o ../../../templates/swagger/v1_json.tmpl
+//go:generate sed -i "s;\".ref\": \"#/definitions/GPGKey\";\"type\": \"object\";g" ../../../templates/swagger/v1_json.tmpl
+//go:generate sed -i "s;^          \".ref\": \"#/definitions/Repository\";          \"type\": \"object\";g" ../../../templates/swagger/v1_json.tmpl
 
 // Package v1 Gitea API.
 //
@@ -15,8 +15,8 @@
 // there are no TOS at this moment, use at your own risk we take no responsibility
 //
 //     Schemes: http, https
-//     BasePath: /api/v1
-//     Version: 1.1.1
+//     BasePath: {{AppSubUrl | Safe}}/api/v1
+//     Version: {{AppVer | Safe}}
 //     License: MIT http://opensource.org/licenses/MIT
 //
 //     Consumes:
@@ -41,6 +41,8 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/auth"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/v1/activitypub"
 	"code.gitea.io/gitea/routers/api/v1/admin"
 	"code.gitea.io/gitea/routers/api/v1/misc"
 	"code.gitea.io/gitea/routers/api/v1/org"
@@ -102,6 +104,12 @@ func repoAssignment() macaron.Handler {
 				return
 			}
 			ctx.Repo.AccessMode = mode
+
+			ctx.Repo.UnitAccessModes, err = models.UnitAccessModes(ctx.User.ID, repo)
+			if err != nil {
+				ctx.Error(500, "UnitAccessModes", err)
+				return
+			}
 		}
 
 		if !ctx.Repo.HasAccess() {
@@ -113,6 +121,38 @@ func repoAssignment() macaron.Handler {
 	}
 }
 
+// sudo makes a signed in admin act as the user named by the Sudo header or
+// the sudo query parameter for the request.
+func sudo() macaron.Handler {
+	return func(ctx *context.APIContext) {
+		name := ctx.Query("sudo")
+		if len(name) == 0 {
+			name = ctx.Req.Header.Get("Sudo")
+		}
+		if len(name) == 0 {
+			return
+		}
+
+		if !ctx.IsSigned || !ctx.User.IsAdmin || setting.Admin.DisableImpersonation {
+			ctx.Error(403, "", "Only admins can act as other users.")
+			return
+		}
+		u, err := models.GetUserByName(name)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.Status(404)
+			} else {
+				ctx.Error(500, "GetUserByName", err)
+			}
+			return
+		} else if !ctx.User.CanImpersonate(u) {
+			ctx.Error(403, "", "This user can't be acted as.")
+			return
+		}
+		ctx.Sudo(u)
+	}
+}
+
 // Contexter middleware already checks token for user sign in process.
 func reqToken() macaron.Handler {
 	return func(ctx *context.Context) {
@@ -123,6 +163,27 @@ func reqToken() macaron.Handler {
 	}
 }
 
+func reqFederation() macaron.Handler {
+	return func(ctx *context.Context) {
+		if !setting.Federation.Enabled {
+			ctx.Error(404)
+			return
+		}
+	}
+}
+
+// reqNotSudo refuses the requests of admins acting as another user with Sudo.
+// Like on the settings pages of the web interface, they can't change the
+// tokens, keys, emails or other settings of the account they act as.
+func reqNotSudo() macaron.Handler {
+	return func(ctx *context.APIContext) {
+		if ctx.Impersonator != nil {
+			ctx.Error(403, "", "Admins acting as other users can't change their account settings.")
+			return
+		}
+	}
+}
+
 func reqBasicAuth() macaron.Handler {
 	return func(ctx *context.Context) {
 		if !ctx.IsBasicAuth {
@@ -150,6 +211,15 @@ func reqRepoWriter() macaron.Handler {
 	}
 }
 
+func reqRepoUnitWriter(unitType models.UnitType) macaron.Handler {
+	return func(ctx *context.Context) {
+		if !ctx.Repo.CanWrite(unitType) {
+			ctx.Error(403)
+			return
+		}
+	}
+}
+
 func reqOrgMembership() macaron.Handler {
 	return func(ctx *context.APIContext) {
 		var orgID int64
@@ -244,6 +314,13 @@ func mustEnableIssues(ctx *context.APIContext) {
 	}
 }
 
+func mustEnableWiki(ctx *context.APIContext) {
+	if !ctx.Repo.Repository.EnableUnit(models.UnitTypeWiki) {
+		ctx.Status(404)
+		return
+	}
+}
+
 func mustAllowPulls(ctx *context.Context) {
 	if !ctx.Repo.Repository.AllowsPulls() {
 		ctx.Status(404)
@@ -256,11 +333,31 @@ func mustAllowPulls(ctx *context.Context) {
 func RegisterRoutes(m *macaron.Macaron) {
 	bind := binding.Bind
 
+	handlers := []macaron.Handler{context.APIContexter(), sudo()}
+	if setting.API.RateLimit > 0 {
+		handlers = append(handlers, context.APIRateLimit())
+	}
+
 	m.Group("/v1", func() {
 		// Miscellaneous
 		m.Get("/version", misc.Version)
 		m.Post("/markdown", bind(api.MarkdownOption{}), misc.Markdown)
 		m.Post("/markdown/raw", misc.MarkdownRaw)
+		m.Get("/search", misc.Search)
+
+		// ActivityPub actors
+		m.Group("/activitypub", func() {
+			m.Group("/user/:username", func() {
+				m.Get("", activitypub.Person)
+				m.Get("/outbox", activitypub.PersonOutbox)
+				m.Post("/inbox", activitypub.Inbox)
+			})
+			m.Group("/repo/:username/:reponame", func() {
+				m.Get("", activitypub.Repository)
+				m.Get("/outbox", activitypub.RepositoryOutbox)
+				m.Post("/inbox", activitypub.Inbox)
+			})
+		}, reqFederation())
 
 		// Users
 		m.Group("/users", func() {
@@ -270,10 +367,13 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Get("", user.GetInfo)
 
 				m.Get("/repos", user.ListUserRepos)
+				m.Get("/profile_fields", user.ListProfileFields)
+				// The listed tokens include their secrets, so they can't be
+				// listed with Sudo either.
 				m.Group("/tokens", func() {
 					m.Combo("").Get(user.ListAccessTokens).
 						Post(bind(api.CreateAccessTokenOption{}), user.CreateAccessToken)
-				}, reqBasicAuth())
+				}, reqBasicAuth(), reqNotSudo())
 			})
 		})
 
@@ -297,8 +397,11 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Group("/user", func() {
 			m.Get("", user.GetAuthenticatedUser)
 			m.Combo("/emails").Get(user.ListEmails).
-				Post(bind(api.CreateEmailOption{}), user.AddEmail).
-				Delete(bind(api.CreateEmailOption{}), user.DeleteEmail)
+				Post(reqNotSudo(), bind(api.CreateEmailOption{}), user.AddEmail).
+				Delete(reqNotSudo(), bind(api.CreateEmailOption{}), user.DeleteEmail)
+
+			m.Combo("/profile_fields").Get(user.ListMyProfileFields).
+				Patch(reqNotSudo(), user.EditMyProfileFields)
 
 			m.Get("/followers", user.ListMyFollowers)
 			m.Group("/following", func() {
@@ -308,16 +411,16 @@ func RegisterRoutes(m *macaron.Macaron) {
 
 			m.Group("/keys", func() {
 				m.Combo("").Get(user.ListMyPublicKeys).
-					Post(bind(api.CreateKeyOption{}), user.CreatePublicKey)
+					Post(reqNotSudo(), bind(api.CreateKeyOption{}), user.CreatePublicKey)
 				m.Combo("/:id").Get(user.GetPublicKey).
-					Delete(user.DeletePublicKey)
+					Delete(reqNotSudo(), user.DeletePublicKey)
 			})
 
 			m.Group("/gpg_keys", func() {
 				m.Combo("").Get(user.ListMyGPGKeys).
-					Post(bind(api.CreateGPGKeyOption{}), user.CreateGPGKey)
+					Post(reqNotSudo(), bind(api.CreateGPGKeyOption{}), user.CreateGPGKey)
 				m.Combo("/:id").Get(user.GetGPGKey).
-					Delete(user.DeleteGPGKey)
+					Delete(reqNotSudo(), user.DeleteGPGKey)
 			})
 
 			m.Combo("/repos").Get(user.ListMyRepos).
@@ -333,6 +436,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 			})
 
 			m.Get("/subscriptions", user.GetMyWatchedRepos)
+			m.Get("/calendar.ics", user.GetMyCalendar)
 		}, reqToken())
 
 		// Repositories
@@ -355,6 +459,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Combo("/:id").Get(repo.GetHook).
 						Patch(bind(api.EditHookOption{}), repo.EditHook).
 						Delete(repo.DeleteHook)
+					m.Post("/:id/signature", repo.GetHookSignature)
 				}, reqRepoWriter())
 				m.Group("/collaborators", func() {
 					m.Get("", repo.ListCollaborators)
@@ -363,12 +468,22 @@ func RegisterRoutes(m *macaron.Macaron) {
 						Delete(repo.DeleteCollaborator)
 				})
 				m.Get("/raw/*", context.RepoRef(), repo.GetRawFile)
+				m.Get("/readme", context.ReferencesGitRepo(), repo.GetReadme)
+				m.Get("/compare/*", repo.Compare)
+				m.Combo("/contents/*", context.ReferencesGitRepo()).Get(repo.GetContents).
+					Post(reqRepoUnitWriter(models.UnitTypeCode), bind(auth.RepoContentsForm{}), repo.CreateContents).
+					Put(reqRepoUnitWriter(models.UnitTypeCode), bind(auth.RepoContentsForm{}), repo.UpdateContents).
+					Delete(reqRepoUnitWriter(models.UnitTypeCode), bind(auth.RepoContentsForm{}), repo.DeleteContents)
 				m.Get("/archive/*", repo.GetArchive)
 				m.Combo("/forks").Get(repo.ListForks).
 					Post(bind(api.CreateForkOption{}), repo.CreateFork)
+				m.Combo("/avatar", reqToken()).
+					Post(bind(repo.UpdateRepoAvatarOption{}), repo.UpdateAvatar).
+					Delete(repo.DeleteAvatar)
 				m.Group("/branches", func() {
 					m.Get("", repo.ListBranches)
-					m.Get("/:branchname", repo.GetBranch)
+					m.Combo("/:branchname").Get(repo.GetBranch).
+						Patch(reqToken(), bind(repo.RenameBranchOption{}), repo.RenameBranch)
 				})
 				m.Group("/keys", func() {
 					m.Combo("").Get(repo.ListDeployKeys).
@@ -377,19 +492,21 @@ func RegisterRoutes(m *macaron.Macaron) {
 						Delete(repo.DeleteDeploykey)
 				})
 				m.Group("/issues", func() {
-					m.Combo("").Get(repo.ListIssues).Post(bind(api.CreateIssueOption{}), repo.CreateIssue)
+					m.Combo("").Get(repo.ListIssues).Post(bind(repo.CreateIssueOption{}), repo.CreateIssue)
+					m.Get("/pinned", repo.ListPinnedIssues)
 					m.Group("/comments", func() {
 						m.Get("", repo.ListRepoIssueComments)
 						m.Combo("/:id").Patch(bind(api.EditIssueCommentOption{}), repo.EditIssueComment)
 					})
 					m.Group("/:index", func() {
-						m.Combo("").Get(repo.GetIssue).Patch(bind(api.EditIssueOption{}), repo.EditIssue)
+						m.Combo("").Get(repo.GetIssue).Patch(bind(auth.EditIssueForm{}), repo.EditIssue)
 
 						m.Group("/comments", func() {
 							m.Combo("").Get(repo.ListIssueComments).Post(bind(api.CreateIssueCommentOption{}), repo.CreateIssueComment)
 							m.Combo("/:id").Patch(bind(api.EditIssueCommentOption{}), repo.EditIssueComment).
 								Delete(repo.DeleteIssueComment)
 						})
+						m.Get("/timeline", repo.ListIssueTimeline)
 
 						m.Group("/labels", func() {
 							m.Combo("").Get(repo.ListIssueLabels).
@@ -399,20 +516,22 @@ func RegisterRoutes(m *macaron.Macaron) {
 							m.Delete("/:id", repo.DeleteIssueLabel)
 						})
 
+						m.Combo("/pin", reqRepoUnitWriter(models.UnitTypeIssues)).Post(repo.PinIssue).Delete(repo.UnpinIssue)
 					})
 				}, mustEnableIssues)
 				m.Group("/labels", func() {
 					m.Combo("").Get(repo.ListLabels).
-						Post(bind(api.CreateLabelOption{}), repo.CreateLabel)
-					m.Combo("/:id").Get(repo.GetLabel).Patch(bind(api.EditLabelOption{}), repo.EditLabel).
+						Post(bind(auth.CreateRepoLabelForm{}), repo.CreateLabel)
+					m.Combo("/:id").Get(repo.GetLabel).Patch(bind(auth.EditRepoLabelForm{}), repo.EditLabel).
 						Delete(repo.DeleteLabel)
 				})
 				m.Group("/milestones", func() {
 					m.Combo("").Get(repo.ListMilestones).
-						Post(reqRepoWriter(), bind(api.CreateMilestoneOption{}), repo.CreateMilestone)
+						Post(reqRepoUnitWriter(models.UnitTypeIssues), bind(api.CreateMilestoneOption{}), repo.CreateMilestone)
 					m.Combo("/:id").Get(repo.GetMilestone).
-						Patch(reqRepoWriter(), bind(api.EditMilestoneOption{}), repo.EditMilestone).
-						Delete(reqRepoWriter(), repo.DeleteMilestone)
+						Patch(reqRepoUnitWriter(models.UnitTypeIssues), bind(api.EditMilestoneOption{}), repo.EditMilestone).
+						Delete(reqRepoUnitWriter(models.UnitTypeIssues), repo.DeleteMilestone)
+					m.Get("/:id/report", repo.GetMilestoneReport)
 				})
 				m.Get("/stargazers", repo.ListStargazers)
 				m.Get("/subscribers", repo.ListSubscribers)
@@ -428,23 +547,38 @@ func RegisterRoutes(m *macaron.Macaron) {
 						Patch(bind(api.EditReleaseOption{}), repo.EditRelease).
 						Delete(repo.DeleteRelease)
 				})
-				m.Post("/mirror-sync", repo.MirrorSync)
+				m.Combo("/mirror-sync").Get(repo.GetMirrorSyncStatus).Post(repo.MirrorSync)
 				m.Get("/editorconfig/:filename", context.RepoRef(), repo.GetEditorconfig)
 				m.Group("/pulls", func() {
-					m.Combo("").Get(bind(api.ListPullRequestsOptions{}), repo.ListPullRequests).Post(reqRepoWriter(), bind(api.CreatePullRequestOption{}), repo.CreatePullRequest)
+					m.Combo("").Get(bind(api.ListPullRequestsOptions{}), repo.ListPullRequests).Post(reqRepoUnitWriter(models.UnitTypePullRequests), bind(api.CreatePullRequestOption{}), repo.CreatePullRequest)
 					m.Group("/:index", func() {
-						m.Combo("").Get(repo.GetPullRequest).Patch(reqRepoWriter(), bind(api.EditPullRequestOption{}), repo.EditPullRequest)
-						m.Combo("/merge").Get(repo.IsPullRequestMerged).Post(reqRepoWriter(), repo.MergePullRequest)
+						m.Combo("").Get(repo.GetPullRequest).Patch(reqRepoUnitWriter(models.UnitTypePullRequests), bind(api.EditPullRequestOption{}), repo.EditPullRequest)
+						m.Combo("/merge").Get(repo.IsPullRequestMerged).Post(reqRepoUnitWriter(models.UnitTypePullRequests), repo.MergePullRequest)
+						m.Get("/conflicts", repo.GetPullRequestConflicts)
 					})
 
 				}, mustAllowPulls, context.ReferencesGitRepo())
 				m.Group("/statuses", func() {
-					m.Combo("/:sha").Get(repo.GetCommitStatuses).Post(reqRepoWriter(), bind(api.CreateStatusOption{}), repo.NewCommitStatus)
+					m.Combo("/:sha").Get(repo.GetCommitStatuses).Post(reqRepoUnitWriter(models.UnitTypeCode), bind(api.CreateStatusOption{}), repo.NewCommitStatus)
 				})
+				m.Get("/commits", context.ReferencesGitRepo(), repo.ListCommits)
+				m.Get("/commits/graph", context.ReferencesGitRepo(), repo.GetCommitGraph)
 				m.Group("/commits/:ref", func() {
 					m.Get("/status", repo.GetCombinedCommitStatus)
 					m.Get("/statuses", repo.GetCommitStatuses)
 				})
+				m.Group("/stats", func() {
+					m.Get("/contributors", repo.GetContributorStats)
+				})
+				m.Get("/languages", repo.GetLanguageStats)
+				m.Group("/wiki", func() {
+					m.Combo("/pages").Get(repo.ListWikiPages).
+						Post(reqRepoUnitWriter(models.UnitTypeWiki), bind(auth.NewWikiForm{}), repo.CreateWikiPage)
+					m.Combo("/pages/:page").Get(repo.GetWikiPage).
+						Patch(reqRepoUnitWriter(models.UnitTypeWiki), bind(auth.NewWikiForm{}), repo.EditWikiPage).
+						Delete(reqRepoUnitWriter(models.UnitTypeWiki), repo.DeleteWikiPage)
+					m.Get("/search", repo.SearchWiki)
+				}, mustEnableWiki)
 			}, repoAssignment())
 		}, reqToken())
 
@@ -467,6 +601,17 @@ func RegisterRoutes(m *macaron.Macaron) {
 			})
 			m.Combo("/teams", reqToken(), reqOrgMembership()).Get(org.ListTeams).
 				Post(bind(api.CreateTeamOption{}), org.CreateTeam)
+			m.Get("/metrics", reqToken(), reqOrgMembership(), org.GetMetrics)
+			m.Group("/label_templates", func() {
+				m.Combo("").Get(org.ListLabelTemplates).
+					Post(bind(auth.OrgLabelTemplateForm{}), org.CreateLabelTemplate)
+				m.Delete("/:id", org.DeleteLabelTemplate)
+			}, reqToken(), reqOrgOwnership())
+			m.Group("/milestone_templates", func() {
+				m.Combo("").Get(org.ListMilestoneTemplates).
+					Post(bind(auth.OrgMilestoneTemplateForm{}), org.CreateMilestoneTemplate)
+				m.Delete("/:id", org.DeleteMilestoneTemplate)
+			}, reqToken(), reqOrgOwnership())
 			m.Group("/hooks", func() {
 				m.Combo("").Get(org.ListHooks).
 					Post(bind(api.CreateHookOption{}), org.CreateHook)
@@ -498,6 +643,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 		})
 
 		m.Group("/admin", func() {
+			m.Get("/stats", admin.GetStats)
 			m.Group("/users", func() {
 				m.Post("", bind(api.CreateUserOption{}), admin.CreateUser)
 				m.Group("/:username", func() {
@@ -506,8 +652,11 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Post("/keys", bind(api.CreateKeyOption{}), admin.CreatePublicKey)
 					m.Post("/orgs", bind(api.CreateOrgOption{}), admin.CreateOrg)
 					m.Post("/repos", bind(api.CreateRepoOption{}), admin.CreateRepo)
+					m.Combo("/suspension").Get(admin.GetSuspension).
+						Put(bind(admin.SuspendUserOption{}), admin.SuspendUser).
+						Delete(admin.UnsuspendUser)
 				})
 			})
 		}, reqAdmin())
-	}, context.APIContexter())
+	}, handlers...)
 }
@@ -11,8 +11,9 @@ import (
 // ToCorrectPageSize makes sure page size is in allowed range.
 func ToCorrectPageSize(size int) int {
 	if size <= 0 {
-		size = 10
-	} else if size > setting.API.MaxResponseItems {
+		size = setting.API.DefaultPagingNum
+	}
+	if size > setting.API.MaxResponseItems {
 		size = setting.API.MaxResponseItems
 	}
 	return size
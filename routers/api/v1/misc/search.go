@@ -0,0 +1,142 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"strings"
+
+	api "code.gitea.io/sdk/gitea"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// searchIssue is an issue found by the search with the full name of its
+// repository.
+type searchIssue struct {
+	*models.APIIssue
+	Repository string `json:"repository"`
+}
+
+// Search searches the repositories, users, organizations or issues given by
+// the scope parameter, only results readable by the signed in user are
+// returned.
+func Search(ctx *context.APIContext) {
+	// swagger:route GET /search search
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: SearchResults
+	//       422: validationError
+	//       500: error
+
+	keyword := strings.Trim(ctx.Query("q"), " ")
+	listOpts := utils.GetListOptions(ctx)
+	page, pageSize := listOpts.Page, listOpts.PageSize
+
+	var (
+		results interface{}
+		count   int64
+		err     error
+	)
+	switch ctx.Query("scope") {
+	case "", "repositories":
+		var repos models.RepositoryList
+		repos, count, err = models.SearchRepositoryByName(&models.SearchRepoOptions{
+			Keyword:  keyword,
+			Searcher: ctx.User,
+			Private:  ctx.IsSigned && ctx.User.IsAdmin,
+			Page:     page,
+			PageSize: pageSize,
+		})
+		if err != nil {
+			ctx.Error(500, "SearchRepositoryByName", err)
+			return
+		}
+		var userID int64
+		if ctx.IsSigned {
+			userID = ctx.User.ID
+		}
+		apiRepos := make([]*api.Repository, len(repos))
+		for i, repo := range repos {
+			accessMode, err := models.AccessLevel(userID, repo)
+			if err != nil {
+				ctx.Error(500, "AccessLevel", err)
+				return
+			}
+			apiRepos[i] = repo.APIFormat(accessMode)
+		}
+		results = apiRepos
+	case "users":
+		var users []*models.User
+		users, count, err = models.SearchUserByName(&models.SearchUserOptions{
+			Keyword:  keyword,
+			Type:     models.UserTypeIndividual,
+			OrderBy:  "name ASC",
+			Page:     page,
+			PageSize: pageSize,
+		})
+		if err != nil {
+			ctx.Error(500, "SearchUserByName", err)
+			return
+		}
+		apiUsers := make([]*api.User, len(users))
+		for i := range users {
+			apiUsers[i] = users[i].APIFormat()
+		}
+		results = apiUsers
+	case "organizations":
+		var orgs []*models.User
+		orgs, count, err = models.SearchUserByName(&models.SearchUserOptions{
+			Keyword:  keyword,
+			Type:     models.UserTypeOrganization,
+			OrderBy:  "name ASC",
+			Page:     page,
+			PageSize: pageSize,
+		})
+		if err != nil {
+			ctx.Error(500, "SearchUserByName", err)
+			return
+		}
+		apiOrgs := make([]*api.Organization, len(orgs))
+		for i := range orgs {
+			apiOrgs[i] = convert.ToOrganization(orgs[i])
+		}
+		results = apiOrgs
+	case "issues":
+		var issues models.IssueList
+		issues, count, err = models.SearchIssues(&models.SearchIssuesOptions{
+			Keyword:  keyword,
+			Doer:     ctx.User,
+			Page:     page,
+			PageSize: pageSize,
+		})
+		if err != nil {
+			ctx.Error(500, "SearchIssues", err)
+			return
+		}
+		apiIssues := make([]*searchIssue, len(issues))
+		for i := range issues {
+			apiIssues[i] = &searchIssue{
+				APIIssue:   issues[i].APIFormat(),
+				Repository: issues[i].Repo.FullName(),
+			}
+		}
+		results = apiIssues
+	default:
+		ctx.Error(422, "", "unsupported search scope: "+ctx.Query("scope"))
+		return
+	}
+
+	ctx.SetLinkHeader(int(count), pageSize)
+	ctx.JSON(200, map[string]interface{}{
+		"ok":   true,
+		"data": results,
+	})
+}
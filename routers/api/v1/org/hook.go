@@ -15,12 +15,26 @@ import (
 
 // ListHooks list an organziation's webhooks
 func ListHooks(ctx *context.APIContext) {
+	// swagger:route GET /orgs/{orgname}/hooks orgListHooks
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: HookList
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
 	org := ctx.Org.Organization
 	orgHooks, err := models.GetWebhooksByOrgID(org.ID)
 	if err != nil {
 		ctx.Error(500, "GetWebhooksByOrgID", err)
 		return
 	}
+	start, end := utils.Paginate(ctx, len(orgHooks))
+	orgHooks = orgHooks[start:end]
+
 	hooks := make([]*api.Hook, len(orgHooks))
 	for i, hook := range orgHooks {
 		hooks[i] = convert.ToHook(org.HomeLink(), hook)
@@ -30,6 +44,17 @@ func ListHooks(ctx *context.APIContext) {
 
 // GetHook get an organization's hook by id
 func GetHook(ctx *context.APIContext) {
+	// swagger:route GET /orgs/{orgname}/hooks/{id} orgGetHook
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Hook
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
 	org := ctx.Org.Organization
 	hookID := ctx.ParamsInt64(":id")
 	hook, err := utils.GetOrgHook(ctx, org.ID, hookID)
@@ -41,6 +66,21 @@ func GetHook(ctx *context.APIContext) {
 
 // CreateHook create a hook for an organization
 func CreateHook(ctx *context.APIContext, form api.CreateHookOption) {
+	// swagger:route POST /orgs/{orgname}/hooks orgCreateHook
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Hook
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
 	if !utils.CheckCreateHookOption(ctx, &form) {
 		return
 	}
@@ -49,12 +89,38 @@ func CreateHook(ctx *context.APIContext, form api.CreateHookOption) {
 
 // EditHook modify a hook of a repository
 func EditHook(ctx *context.APIContext, form api.EditHookOption) {
+	// swagger:route PATCH /orgs/{orgname}/hooks/{id} orgEditHook
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Hook
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
 	hookID := ctx.ParamsInt64(":id")
 	utils.EditOrgHook(ctx, &form, hookID)
 }
 
 // DeleteHook delete a hook of an organization
 func DeleteHook(ctx *context.APIContext) {
+	// swagger:route DELETE /orgs/{orgname}/hooks/{id} orgDeleteHook
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
 	org := ctx.Org.Organization
 	hookID := ctx.ParamsInt64(":id")
 	if err := models.DeleteWebhookByOrgID(org.ID, hookID); err != nil {
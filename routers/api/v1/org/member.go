@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/routers/api/v1/user"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 // listMembers list an organization's members
@@ -44,6 +45,9 @@ func listMembers(ctx *context.APIContext, publicOnly bool) {
 		members = ctx.Org.Organization.Members
 	}
 
+	start, end := utils.Paginate(ctx, len(members))
+	members = members[start:end]
+
 	apiMembers := make([]*api.User, len(members))
 	for i, member := range members {
 		apiMembers[i] = member.APIFormat()
@@ -53,17 +57,48 @@ func listMembers(ctx *context.APIContext, publicOnly bool) {
 
 // ListMembers list an organization's members
 func ListMembers(ctx *context.APIContext) {
+	// swagger:route GET /orgs/{orgname}/members orgListMembers
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: UserList
+	//       404: notFound
+	//       500: error
+
 	publicOnly := ctx.User == nil || !ctx.Org.Organization.IsOrgMember(ctx.User.ID)
 	listMembers(ctx, publicOnly)
 }
 
 // ListPublicMembers list an organization's public members
 func ListPublicMembers(ctx *context.APIContext) {
+	// swagger:route GET /orgs/{orgname}/public_members orgListPublicMembers
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: UserList
+	//       404: notFound
+	//       500: error
+
 	listMembers(ctx, true)
 }
 
 // IsMember check if a user is a member of an organization
 func IsMember(ctx *context.APIContext) {
+	// swagger:route GET /orgs/{orgname}/members/{username} orgIsMember
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       302: empty
+	//       404: notFound
+	//       500: error
+
 	userToCheck := user.GetUserByParams(ctx)
 	if ctx.Written() {
 		return
@@ -85,6 +120,16 @@ func IsMember(ctx *context.APIContext) {
 
 // IsPublicMember check if a user is a public member of an organization
 func IsPublicMember(ctx *context.APIContext) {
+	// swagger:route GET /orgs/{orgname}/public_members/{username} orgIsPublicMember
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       404: notFound
+	//       500: error
+
 	userToCheck := user.GetUserByParams(ctx)
 	if ctx.Written() {
 		return
@@ -98,6 +143,17 @@ func IsPublicMember(ctx *context.APIContext) {
 
 // PublicizeMember make a member's membership public
 func PublicizeMember(ctx *context.APIContext) {
+	// swagger:route PUT /orgs/{orgname}/public_members/{username} orgPublicizeMember
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
 	userToPublicize := user.GetUserByParams(ctx)
 	if ctx.Written() {
 		return
@@ -116,6 +172,17 @@ func PublicizeMember(ctx *context.APIContext) {
 
 // ConcealMember make a member's membership not public
 func ConcealMember(ctx *context.APIContext) {
+	// swagger:route DELETE /orgs/{orgname}/public_members/{username} orgConcealMember
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
 	userToConceal := user.GetUserByParams(ctx)
 	if ctx.Written() {
 		return
@@ -134,6 +201,17 @@ func ConcealMember(ctx *context.APIContext) {
 
 // DeleteMember remove a member from an organization
 func DeleteMember(ctx *context.APIContext) {
+	// swagger:route DELETE /orgs/{orgname}/members/{username} orgDeleteMember
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
 	member := user.GetUserByParams(ctx)
 	if ctx.Written() {
 		return
@@ -0,0 +1,93 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/modules/context"
+)
+
+const (
+	defaultMetricsDays = 30
+	maxMetricsDays     = 365
+	// metricsCacheTTL is the number of seconds computed metrics are cached for.
+	metricsCacheTTL = 10 * 60
+)
+
+type orgMetrics struct {
+	Since                   time.Time `json:"since"`
+	Until                   time.Time `json:"until"`
+	IssuesOpened            int64     `json:"issues_opened"`
+	IssuesClosed            int64     `json:"issues_closed"`
+	IssueCloseRate          int64     `json:"issue_close_rate"`
+	PullsOpened             int64     `json:"pulls_opened"`
+	PullsMerged             int64     `json:"pulls_merged"`
+	MergeLeadTimeSeconds    int64     `json:"merge_lead_time_seconds"`
+	PullsReviewed           int64     `json:"pulls_reviewed"`
+	ReviewTurnaroundSeconds int64     `json:"review_turnaround_seconds"`
+}
+
+// GetMetrics returns issue and pull request activity across all repositories
+// of an organization during the last `days` days
+func GetMetrics(ctx *context.APIContext) {
+	// swagger:route GET /orgs/{orgname}/metrics orgGetMetrics
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: OrgMetrics
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
+	days := ctx.QueryInt("days")
+	if days == 0 {
+		days = defaultMetricsDays
+	} else if days < 0 || days > maxMetricsDays {
+		ctx.Error(422, "", errors.New("days must be between 1 and 365"))
+		return
+	}
+
+	org := ctx.Org.Organization
+	key := fmt.Sprintf("org-metrics:%d:%d", org.ID, days)
+	if cached, ok := ctx.Cache.Get(key).(string); ok {
+		var apiMetrics orgMetrics
+		if err := json.Unmarshal([]byte(cached), &apiMetrics); err == nil {
+			ctx.JSON(200, &apiMetrics)
+			return
+		}
+	}
+
+	until := time.Now()
+	since := until.AddDate(0, 0, -days)
+	metrics, err := org.GetMetrics(since.Unix(), until.Unix())
+	if err != nil {
+		ctx.Error(500, "GetMetrics", err)
+		return
+	}
+
+	apiMetrics := &orgMetrics{
+		Since:                   since,
+		Until:                   until,
+		IssuesOpened:            metrics.IssuesOpened,
+		IssuesClosed:            metrics.IssuesClosed,
+		IssueCloseRate:          metrics.IssueCloseRate(),
+		PullsOpened:             metrics.PullsOpened,
+		PullsMerged:             metrics.PullsMerged,
+		MergeLeadTimeSeconds:    metrics.MergeLeadTime,
+		PullsReviewed:           metrics.PullsReviewed,
+		ReviewTurnaroundSeconds: metrics.ReviewTurnaround,
+	}
+	if data, err := json.Marshal(apiMetrics); err == nil {
+		ctx.Cache.Put(key, string(data), metricsCacheTTL)
+	}
+	ctx.JSON(200, apiMetrics)
+}
@@ -11,6 +11,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/routers/api/v1/convert"
 	"code.gitea.io/gitea/routers/api/v1/user"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 func listUserOrgs(ctx *context.APIContext, u *models.User, all bool) {
@@ -19,9 +20,12 @@ func listUserOrgs(ctx *context.APIContext, u *models.User, all bool) {
 		return
 	}
 
-	apiOrgs := make([]*api.Organization, len(u.Orgs))
-	for i := range u.Orgs {
-		apiOrgs[i] = convert.ToOrganization(u.Orgs[i])
+	start, end := utils.Paginate(ctx, len(u.Orgs))
+	orgs := u.Orgs[start:end]
+
+	apiOrgs := make([]*api.Organization, len(orgs))
+	for i := range orgs {
+		apiOrgs[i] = convert.ToOrganization(orgs[i])
 	}
 	ctx.JSON(200, &apiOrgs)
 }
@@ -29,12 +33,31 @@ func listUserOrgs(ctx *context.APIContext, u *models.User, all bool) {
 // ListMyOrgs list all my orgs
 // see https://github.com/gogits/go-gogs-client/wiki/Organizations#list-your-organizations
 func ListMyOrgs(ctx *context.APIContext) {
+	// swagger:route GET /user/orgs orgListCurrentUserOrgs
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: OrganizationList
+	//       500: error
+
 	listUserOrgs(ctx, ctx.User, true)
 }
 
 // ListUserOrgs list user's orgs
 // see https://github.com/gogits/go-gogs-client/wiki/Organizations#list-user-organizations
 func ListUserOrgs(ctx *context.APIContext) {
+	// swagger:route GET /users/{username}/orgs orgListUserOrgs
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: OrganizationList
+	//       404: notFound
+	//       500: error
+
 	u := user.GetUserByParams(ctx)
 	if ctx.Written() {
 		return
@@ -45,12 +68,36 @@ func ListUserOrgs(ctx *context.APIContext) {
 // Get get an organization
 // see https://github.com/gogits/go-gogs-client/wiki/Organizations#get-an-organization
 func Get(ctx *context.APIContext) {
+	// swagger:route GET /orgs/{orgname} orgGet
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Organization
+	//       404: notFound
+
 	ctx.JSON(200, convert.ToOrganization(ctx.Org.Organization))
 }
 
 // Edit change an organization's information
 // see https://github.com/gogits/go-gogs-client/wiki/Organizations#edit-an-organization
 func Edit(ctx *context.APIContext, form api.EditOrgOption) {
+	// swagger:route PATCH /orgs/{orgname} orgEdit
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Organization
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
 	org := ctx.Org.Organization
 	org.FullName = form.FullName
 	org.Description = form.Description
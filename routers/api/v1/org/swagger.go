@@ -0,0 +1,152 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	api "code.gitea.io/sdk/gitea"
+
+	"code.gitea.io/gitea/modules/auth"
+)
+
+// The declarations below are only read by the swagger generator, they
+// describe the parameters and responses of the routes of this package.
+
+// swagger:parameters orgGet orgEdit orgListMembers orgIsMember orgDeleteMember orgListPublicMembers orgIsPublicMember orgPublicizeMember orgConcealMember orgListTeams orgCreateTeam orgGetMetrics orgListLabelTemplates orgCreateLabelTemplate orgDeleteLabelTemplate orgListMilestoneTemplates orgCreateMilestoneTemplate orgDeleteMilestoneTemplate orgListHooks orgCreateHook orgGetHook orgEditHook orgDeleteHook
+type swaggerOrgParams struct {
+	// name of the organization
+	//
+	// in: path
+	// required: true
+	Orgname string `json:"orgname"`
+}
+
+// swagger:parameters orgListUserOrgs orgIsMember orgDeleteMember orgIsPublicMember orgPublicizeMember orgConcealMember orgAddTeamMember orgRemoveTeamMember
+type swaggerUsernameParams struct {
+	// in: path
+	// required: true
+	Username string `json:"username"`
+}
+
+// swagger:parameters orgDeleteLabelTemplate orgDeleteMilestoneTemplate orgGetHook orgEditHook orgDeleteHook
+type swaggerIDParams struct {
+	// in: path
+	// required: true
+	ID int64 `json:"id"`
+}
+
+// swagger:parameters orgGetTeam orgEditTeam orgDeleteTeam orgListTeamMembers orgAddTeamMember orgRemoveTeamMember orgListTeamRepos orgAddTeamRepository orgRemoveTeamRepository
+type swaggerTeamParams struct {
+	// in: path
+	// required: true
+	TeamID int64 `json:"teamid"`
+}
+
+// swagger:parameters orgAddTeamRepository orgRemoveTeamRepository
+type swaggerTeamRepoParams struct {
+	// owner of the repository
+	//
+	// in: path
+	// required: true
+	Orgname string `json:"orgname"`
+	// name of the repository
+	//
+	// in: path
+	// required: true
+	Reponame string `json:"reponame"`
+}
+
+// swagger:parameters orgEdit
+type swaggerEditOrgParams struct {
+	// in: body
+	Body api.EditOrgOption
+}
+
+// swagger:parameters orgCreateTeam
+type swaggerCreateTeamParams struct {
+	// in: body
+	Body api.CreateTeamOption
+}
+
+// swagger:parameters orgEditTeam
+type swaggerEditTeamParams struct {
+	// in: body
+	Body api.EditTeamOption
+}
+
+// swagger:parameters orgCreateLabelTemplate
+type swaggerLabelTemplateParams struct {
+	// in: body
+	Body auth.OrgLabelTemplateForm
+}
+
+// swagger:parameters orgCreateMilestoneTemplate
+type swaggerMilestoneTemplateParams struct {
+	// in: body
+	Body auth.OrgMilestoneTemplateForm
+}
+
+// Organization represents an organization
+// swagger:response Organization
+type swaggerOrganization struct {
+	// in: body
+	Body api.Organization
+}
+
+// OrganizationList represents a list of organizations
+// swagger:response OrganizationList
+type swaggerOrganizationList struct {
+	// in: body
+	Body []*api.Organization
+}
+
+// Team represents a team of an organization
+// swagger:response Team
+type swaggerTeam struct {
+	// in: body
+	Body api.Team
+}
+
+// TeamList represents a list of teams
+// swagger:response TeamList
+type swaggerTeamList struct {
+	// in: body
+	Body []*api.Team
+}
+
+// OrgMetrics represents the issue and pull request activity of an
+// organization
+// swagger:response OrgMetrics
+type swaggerOrgMetrics struct {
+	// in: body
+	Body orgMetrics
+}
+
+// LabelTemplate represents a label template of an organization
+// swagger:response LabelTemplate
+type swaggerLabelTemplate struct {
+	// in: body
+	Body labelTemplate
+}
+
+// LabelTemplateList represents a list of label templates
+// swagger:response LabelTemplateList
+type swaggerLabelTemplateList struct {
+	// in: body
+	Body []*labelTemplate
+}
+
+// MilestoneTemplate represents a milestone template of an organization
+// swagger:response MilestoneTemplate
+type swaggerMilestoneTemplate struct {
+	// in: body
+	Body milestoneTemplate
+}
+
+// MilestoneTemplateList represents a list of milestone templates
+// swagger:response MilestoneTemplateList
+type swaggerMilestoneTemplateList struct {
+	// in: body
+	Body []*milestoneTemplate
+}
@@ -11,30 +11,70 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/routers/api/v1/convert"
 	"code.gitea.io/gitea/routers/api/v1/user"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 // ListTeams list all the teams of an organization
 func ListTeams(ctx *context.APIContext) {
+	// swagger:route GET /orgs/{orgname}/teams orgListTeams
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: TeamList
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
 	org := ctx.Org.Organization
 	if err := org.GetTeams(); err != nil {
 		ctx.Error(500, "GetTeams", err)
 		return
 	}
 
-	apiTeams := make([]*api.Team, len(org.Teams))
-	for i := range org.Teams {
-		apiTeams[i] = convert.ToTeam(org.Teams[i])
+	start, end := utils.Paginate(ctx, len(org.Teams))
+	teams := org.Teams[start:end]
+
+	apiTeams := make([]*api.Team, len(teams))
+	for i := range teams {
+		apiTeams[i] = convert.ToTeam(teams[i])
 	}
 	ctx.JSON(200, apiTeams)
 }
 
 // GetTeam api for get a team
 func GetTeam(ctx *context.APIContext) {
+	// swagger:route GET /teams/{teamid} orgGetTeam
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Team
+	//       403: forbidden
+	//       404: notFound
+
 	ctx.JSON(200, convert.ToTeam(ctx.Org.Team))
 }
 
 // CreateTeam api for create a team
 func CreateTeam(ctx *context.APIContext, form api.CreateTeamOption) {
+	// swagger:route POST /orgs/{orgname}/teams orgCreateTeam
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       201: Team
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
 	team := &models.Team{
 		OrgID:       ctx.Org.Organization.ID,
 		Name:        form.Name,
@@ -55,6 +95,21 @@ func CreateTeam(ctx *context.APIContext, form api.CreateTeamOption) {
 
 // EditTeam api for edit a team
 func EditTeam(ctx *context.APIContext, form api.EditTeamOption) {
+	// swagger:route PATCH /teams/{teamid} orgEditTeam
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Team
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
 	team := &models.Team{
 		ID:          ctx.Org.Team.ID,
 		OrgID:       ctx.Org.Team.OrgID,
@@ -71,6 +126,17 @@ func EditTeam(ctx *context.APIContext, form api.EditTeamOption) {
 
 // DeleteTeam api for delete a team
 func DeleteTeam(ctx *context.APIContext) {
+	// swagger:route DELETE /teams/{teamid} orgDeleteTeam
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
 	if err := models.DeleteTeam(ctx.Org.Team); err != nil {
 		ctx.Error(500, "DeleteTeam", err)
 		return
@@ -80,6 +146,17 @@ func DeleteTeam(ctx *context.APIContext) {
 
 // GetTeamMembers api for get a team's members
 func GetTeamMembers(ctx *context.APIContext) {
+	// swagger:route GET /teams/{teamid}/members orgListTeamMembers
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: UserList
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
 	if !models.IsOrganizationMember(ctx.Org.Team.OrgID, ctx.User.ID) {
 		ctx.Status(404)
 		return
@@ -89,8 +166,9 @@ func GetTeamMembers(ctx *context.APIContext) {
 		ctx.Error(500, "GetTeamMembers", err)
 		return
 	}
-	members := make([]*api.User, len(team.Members))
-	for i, member := range team.Members {
+	start, end := utils.Paginate(ctx, len(team.Members))
+	members := make([]*api.User, end-start)
+	for i, member := range team.Members[start:end] {
 		members[i] = member.APIFormat()
 	}
 	ctx.JSON(200, members)
@@ -98,6 +176,17 @@ func GetTeamMembers(ctx *context.APIContext) {
 
 // AddTeamMember api for add a member to a team
 func AddTeamMember(ctx *context.APIContext) {
+	// swagger:route PUT /teams/{teamid}/members/{username} orgAddTeamMember
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
 	u := user.GetUserByParams(ctx)
 	if ctx.Written() {
 		return
@@ -111,6 +200,17 @@ func AddTeamMember(ctx *context.APIContext) {
 
 // RemoveTeamMember api for remove one member from a team
 func RemoveTeamMember(ctx *context.APIContext) {
+	// swagger:route DELETE /teams/{teamid}/members/{username} orgRemoveTeamMember
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
 	u := user.GetUserByParams(ctx)
 	if ctx.Written() {
 		return
@@ -125,12 +225,25 @@ func RemoveTeamMember(ctx *context.APIContext) {
 
 // GetTeamRepos api for get a team's repos
 func GetTeamRepos(ctx *context.APIContext) {
+	// swagger:route GET /teams/{teamid}/repos orgListTeamRepos
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: RepositoryList
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
 	team := ctx.Org.Team
 	if err := team.GetRepositories(); err != nil {
 		ctx.Error(500, "GetTeamRepos", err)
+		return
 	}
-	repos := make([]*api.Repository, len(team.Repos))
-	for i, repo := range team.Repos {
+	start, end := utils.Paginate(ctx, len(team.Repos))
+	repos := make([]*api.Repository, end-start)
+	for i, repo := range team.Repos[start:end] {
 		access, err := models.AccessLevel(ctx.User.ID, repo)
 		if err != nil {
 			ctx.Error(500, "GetTeamRepos", err)
@@ -157,6 +270,17 @@ func getRepositoryByParams(ctx *context.APIContext) *models.Repository {
 
 // AddTeamRepository api for adding a repository to a team
 func AddTeamRepository(ctx *context.APIContext) {
+	// swagger:route PUT /teams/{teamid}/repos/{orgname}/{reponame} orgAddTeamRepository
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
 	repo := getRepositoryByParams(ctx)
 	if ctx.Written() {
 		return
@@ -177,6 +301,17 @@ func AddTeamRepository(ctx *context.APIContext) {
 
 // RemoveTeamRepository api for removing a repository from a team
 func RemoveTeamRepository(ctx *context.APIContext) {
+	// swagger:route DELETE /teams/{teamid}/repos/{orgname}/{reponame} orgRemoveTeamRepository
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
 	repo := getRepositoryByParams(ctx)
 	if ctx.Written() {
 		return
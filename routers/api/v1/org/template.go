@@ -0,0 +1,201 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/context"
+)
+
+type labelTemplate struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Color       string `json:"color"`
+}
+
+func toLabelTemplate(t *models.OrgLabelTemplate) *labelTemplate {
+	return &labelTemplate{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		Color:       t.Color,
+	}
+}
+
+type milestoneTemplate struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	Content      string `json:"content"`
+	DueDays      int    `json:"due_days"`
+	Count        int    `json:"count"`
+	IntervalDays int    `json:"interval_days"`
+}
+
+func toMilestoneTemplate(t *models.OrgMilestoneTemplate) *milestoneTemplate {
+	return &milestoneTemplate{
+		ID:           t.ID,
+		Name:         t.Name,
+		Content:      t.Content,
+		DueDays:      t.DueDays,
+		Count:        t.Count,
+		IntervalDays: t.IntervalDays,
+	}
+}
+
+// ListLabelTemplates list the labels created in new repositories of an organization
+func ListLabelTemplates(ctx *context.APIContext) {
+	// swagger:route GET /orgs/{orgname}/label_templates orgListLabelTemplates
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: LabelTemplateList
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
+	templates, err := models.GetOrgLabelTemplates(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Error(500, "GetOrgLabelTemplates", err)
+		return
+	}
+
+	apiTemplates := make([]*labelTemplate, len(templates))
+	for i := range templates {
+		apiTemplates[i] = toLabelTemplate(templates[i])
+	}
+	ctx.JSON(200, &apiTemplates)
+}
+
+// CreateLabelTemplate add a label created in new repositories of an organization
+func CreateLabelTemplate(ctx *context.APIContext, form auth.OrgLabelTemplateForm) {
+	// swagger:route POST /orgs/{orgname}/label_templates orgCreateLabelTemplate
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       201: LabelTemplate
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
+	t := &models.OrgLabelTemplate{
+		OrgID:       ctx.Org.Organization.ID,
+		Name:        form.Name,
+		Description: form.Description,
+		Color:       form.Color,
+	}
+	if err := models.NewOrgLabelTemplate(t); err != nil {
+		ctx.Error(500, "NewOrgLabelTemplate", err)
+		return
+	}
+	ctx.JSON(201, toLabelTemplate(t))
+}
+
+// DeleteLabelTemplate delete a label created in new repositories of an organization
+func DeleteLabelTemplate(ctx *context.APIContext) {
+	// swagger:route DELETE /orgs/{orgname}/label_templates/{id} orgDeleteLabelTemplate
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
+	if err := models.DeleteOrgLabelTemplate(ctx.Org.Organization.ID, ctx.ParamsInt64(":id")); err != nil {
+		ctx.Error(500, "DeleteOrgLabelTemplate", err)
+		return
+	}
+	ctx.Status(204)
+}
+
+// ListMilestoneTemplates list the milestones created in new repositories of an organization
+func ListMilestoneTemplates(ctx *context.APIContext) {
+	// swagger:route GET /orgs/{orgname}/milestone_templates orgListMilestoneTemplates
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: MilestoneTemplateList
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
+	templates, err := models.GetOrgMilestoneTemplates(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Error(500, "GetOrgMilestoneTemplates", err)
+		return
+	}
+
+	apiTemplates := make([]*milestoneTemplate, len(templates))
+	for i := range templates {
+		apiTemplates[i] = toMilestoneTemplate(templates[i])
+	}
+	ctx.JSON(200, &apiTemplates)
+}
+
+// CreateMilestoneTemplate add a milestone created in new repositories of an organization
+func CreateMilestoneTemplate(ctx *context.APIContext, form auth.OrgMilestoneTemplateForm) {
+	// swagger:route POST /orgs/{orgname}/milestone_templates orgCreateMilestoneTemplate
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       201: MilestoneTemplate
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
+	t := &models.OrgMilestoneTemplate{
+		OrgID:        ctx.Org.Organization.ID,
+		Name:         form.Name,
+		Content:      form.Content,
+		DueDays:      form.DueDays,
+		Count:        form.Count,
+		IntervalDays: form.IntervalDays,
+	}
+	if err := models.NewOrgMilestoneTemplate(t); err != nil {
+		ctx.Error(500, "NewOrgMilestoneTemplate", err)
+		return
+	}
+	ctx.JSON(201, toMilestoneTemplate(t))
+}
+
+// DeleteMilestoneTemplate delete a milestone created in new repositories of an organization
+func DeleteMilestoneTemplate(ctx *context.APIContext) {
+	// swagger:route DELETE /orgs/{orgname}/milestone_templates/{id} orgDeleteMilestoneTemplate
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
+	if err := models.DeleteOrgMilestoneTemplate(ctx.Org.Organization.ID, ctx.ParamsInt64(":id")); err != nil {
+		ctx.Error(500, "DeleteOrgMilestoneTemplate", err)
+		return
+	}
+	ctx.Status(204)
+}
@@ -0,0 +1,75 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"encoding/base64"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+)
+
+// UpdateRepoAvatarOption represents the options to set the avatar of a
+// repository
+type UpdateRepoAvatarOption struct {
+	// Base64 encoded image
+	Image string `json:"image" binding:"Required"`
+}
+
+// UpdateAvatar sets the avatar of a repository
+func UpdateAvatar(ctx *context.APIContext, form UpdateRepoAvatarOption) {
+	// swagger:route POST /repos/{username}/{reponame}/avatar repoUpdateAvatar
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       422: validationError
+	//       500: error
+
+	if !ctx.Repo.IsAdmin() {
+		ctx.Error(403, "", "Must have admin rights")
+		return
+	}
+
+	data, err := base64.StdEncoding.DecodeString(form.Image)
+	if err != nil {
+		ctx.Error(422, "", "image is not valid base64")
+		return
+	} else if !base.IsImageFile(data) {
+		ctx.Error(422, "", "image is not an image file")
+		return
+	}
+
+	if err = ctx.Repo.Repository.UploadAvatar(data); err != nil {
+		ctx.Error(500, "UploadAvatar", err)
+		return
+	}
+	ctx.Status(204)
+}
+
+// DeleteAvatar deletes the avatar of a repository, the avatar of its owner is
+// used instead
+func DeleteAvatar(ctx *context.APIContext) {
+	// swagger:route DELETE /repos/{username}/{reponame}/avatar repoDeleteAvatar
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       500: error
+
+	if !ctx.Repo.IsAdmin() {
+		ctx.Error(403, "", "Must have admin rights")
+		return
+	}
+
+	if err := ctx.Repo.Repository.DeleteAvatar(); err != nil {
+		ctx.Error(500, "DeleteAvatar", err)
+		return
+	}
+	ctx.Status(204)
+}
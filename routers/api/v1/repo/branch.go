@@ -10,11 +10,22 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/routers/api/v1/convert"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 // GetBranch get a branch of a repository
 // see https://github.com/gogits/go-gogs-client/wiki/Repositories#get-branch
 func GetBranch(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/branches/{branchname} repoGetBranch
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Branch
+	//       404: notFound
+	//       500: error
+
 	branch, err := ctx.Repo.Repository.GetBranch(ctx.Params(":branchname"))
 	if err != nil {
 		if models.IsErrBranchNotExist(err) {
@@ -37,12 +48,24 @@ func GetBranch(ctx *context.APIContext) {
 // ListBranches list all the branches of a repository
 // see https://github.com/gogits/go-gogs-client/wiki/Repositories#list-branches
 func ListBranches(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/branches repoListBranches
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: BranchList
+	//       500: error
+
 	branches, err := ctx.Repo.Repository.GetBranches()
 	if err != nil {
 		ctx.Error(500, "GetBranches", err)
 		return
 	}
 
+	start, end := utils.Paginate(ctx, len(branches))
+	branches = branches[start:end]
+
 	apiBranches := make([]*api.Branch, len(branches))
 	for i := range branches {
 		c, err := branches[i].GetCommit()
@@ -55,3 +78,56 @@ func ListBranches(ctx *context.APIContext) {
 
 	ctx.JSON(200, &apiBranches)
 }
+
+// RenameBranchOption represents the options to rename a branch
+type RenameBranchOption struct {
+	Name     string `json:"name" binding:"Required"`
+	Redirect bool   `json:"redirect"`
+}
+
+// RenameBranch renames a branch of a repository, and the default branch if
+// it is the one renamed
+func RenameBranch(ctx *context.APIContext, form RenameBranchOption) {
+	// swagger:route PATCH /repos/{username}/{reponame}/branches/{branchname} repoRenameBranch
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Branch
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
+	if !ctx.Repo.IsAdmin() {
+		ctx.Error(403, "", "Must have admin rights")
+		return
+	}
+
+	if err := ctx.Repo.Repository.RenameBranch(ctx.Params(":branchname"), form.Name, form.Redirect); err != nil {
+		if models.IsErrBranchNotExist(err) {
+			ctx.Status(404)
+		} else if models.IsErrBranchAlreadyExist(err) || models.IsErrInvalidBranchName(err) {
+			ctx.Error(422, "", err)
+		} else {
+			ctx.Error(500, "RenameBranch", err)
+		}
+		return
+	}
+
+	branch, err := ctx.Repo.Repository.GetBranch(form.Name)
+	if err != nil {
+		ctx.Error(500, "GetBranch", err)
+		return
+	}
+	c, err := branch.GetCommit()
+	if err != nil {
+		ctx.Error(500, "GetCommit", err)
+		return
+	}
+	ctx.JSON(200, convert.ToBranch(branch, c))
+}
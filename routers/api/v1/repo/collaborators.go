@@ -9,10 +9,21 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 // ListCollaborators list a repository's collaborators
 func ListCollaborators(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/collaborators repoListCollaborators
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: UserList
+	//       403: forbidden
+	//       500: error
+
 	if !ctx.Repo.IsWriter() {
 		ctx.Error(403, "", "User does not have push access")
 		return
@@ -22,6 +33,8 @@ func ListCollaborators(ctx *context.APIContext) {
 		ctx.Error(500, "ListCollaborators", err)
 		return
 	}
+	start, end := utils.Paginate(ctx, len(collaborators))
+	collaborators = collaborators[start:end]
 	users := make([]*api.User, len(collaborators))
 	for i, collaborator := range collaborators {
 		users[i] = collaborator.APIFormat()
@@ -31,6 +44,18 @@ func ListCollaborators(ctx *context.APIContext) {
 
 // IsCollaborator check if a user is a collaborator of a repository
 func IsCollaborator(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/collaborators/{collaborator} repoCheckCollaborator
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
 	if !ctx.Repo.IsWriter() {
 		ctx.Error(403, "", "User does not have push access")
 		return
@@ -58,6 +83,20 @@ func IsCollaborator(ctx *context.APIContext) {
 
 // AddCollaborator add a collaborator of a repository
 func AddCollaborator(ctx *context.APIContext, form api.AddCollaboratorOption) {
+	// swagger:route PUT /repos/{username}/{reponame}/collaborators/{collaborator} repoAddCollaborator
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       422: validationError
+	//       500: error
+
 	if !ctx.Repo.IsWriter() {
 		ctx.Error(403, "", "User does not have push access")
 		return
@@ -89,6 +128,17 @@ func AddCollaborator(ctx *context.APIContext, form api.AddCollaboratorOption) {
 
 // DeleteCollaborator delete a collaborator from a repository
 func DeleteCollaborator(ctx *context.APIContext) {
+	// swagger:route DELETE /repos/{username}/{reponame}/collaborators/{collaborator} repoDeleteCollaborator
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       422: validationError
+	//       500: error
+
 	if !ctx.Repo.IsWriter() {
 		ctx.Error(403, "", "User does not have push access")
 		return
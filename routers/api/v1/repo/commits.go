@@ -0,0 +1,182 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"time"
+
+	api "code.gitea.io/sdk/gitea"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+type pathCommit struct {
+	*api.PayloadCommit
+	Path    string `json:"path,omitempty"`
+	OldPath string `json:"old_path,omitempty"`
+	Status  string `json:"status,omitempty"`
+}
+
+// ListCommits returns the commit history of a repository, or of a single path
+// if the path query is given. Renames of a file are followed if follow is true.
+func ListCommits(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/commits repoListCommits
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: CommitList
+	//       404: notFound
+	//       500: error
+
+	if ctx.Repo.Repository.IsBare {
+		ctx.JSON(200, []*pathCommit{})
+		return
+	}
+
+	revision := ctx.Query("sha")
+	if len(revision) == 0 {
+		revision = ctx.Repo.Repository.DefaultBranch
+	}
+	commit, err := ctx.Repo.GitRepo.GetCommit(revision)
+	if err != nil {
+		ctx.Error(404, "GetCommit", err)
+		return
+	}
+
+	listOpts := utils.GetListOptions(ctx)
+	commits, err := models.GetPathHistory(ctx.Repo.GitRepo, &models.PathHistoryOptions{
+		Revision: commit.ID.String(),
+		Path:     ctx.Query("path"),
+		Follow:   ctx.QueryBool("follow"),
+		Page:     listOpts.Page,
+		PageSize: listOpts.PageSize,
+	})
+	if err != nil {
+		ctx.Error(500, "GetPathHistory", err)
+		return
+	}
+
+	apiCommits := make([]*pathCommit, len(commits))
+	for i := range commits {
+		apiCommits[i] = &pathCommit{
+			PayloadCommit: convert.ToCommit(commits[i].Commit),
+			Path:          commits[i].Path,
+			OldPath:       commits[i].OldPath,
+			Status:        commits[i].Status,
+		}
+	}
+	ctx.JSON(200, &apiCommits)
+}
+
+type graphCommit struct {
+	SHA      string    `json:"sha"`
+	Parents  []string  `json:"parents"`
+	Children []string  `json:"children"`
+	Refs     []string  `json:"refs"`
+	Column   int       `json:"column"`
+	Author   *api.User `json:"author,omitempty"`
+	Name     string    `json:"author_name"`
+	Email    string    `json:"author_email"`
+	Date     time.Time `json:"date"`
+	Subject  string    `json:"subject"`
+}
+
+type commitGraph struct {
+	Page    int            `json:"page"`
+	HasMore bool           `json:"has_more"`
+	Commits []*graphCommit `json:"commits"`
+}
+
+// GetCommitGraph returns a page of the commits of all branches in graph
+// order. Children are only listed if they are on the same page.
+func GetCommitGraph(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/commits/graph repoGetCommitGraph
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: CommitGraph
+	//       500: error
+
+	page := ctx.QueryInt("page")
+	if page < 1 {
+		page = 1
+	}
+	if ctx.Repo.Repository.IsBare {
+		ctx.JSON(200, &commitGraph{Page: page, Commits: []*graphCommit{}})
+		return
+	}
+
+	graph, err := models.GetCommitGraph(ctx.Repo.GitRepo, page)
+	if err != nil {
+		ctx.Error(500, "GetCommitGraph", err)
+		return
+	}
+
+	commits := make([]*graphCommit, 0, graph.CommitsCount())
+	commitsBySHA := make(map[string]*graphCommit, graph.CommitsCount())
+	emails := make(map[string]*models.User)
+	for i := range graph {
+		item := &graph[i]
+		if item.OnlyRelation {
+			continue
+		}
+
+		date, err := time.Parse("2006-01-02 15:04:05 -0700", item.Date)
+		if err != nil {
+			ctx.Error(500, "Parse", err)
+			return
+		}
+		c := &graphCommit{
+			SHA:      item.Rev,
+			Parents:  item.Parents,
+			Children: []string{},
+			Refs:     item.Refs(),
+			Column:   item.Column(),
+			Name:     item.Author,
+			Email:    item.AuthorEmail,
+			Date:     date,
+			Subject:  item.Subject,
+		}
+		if c.Parents == nil {
+			c.Parents = []string{}
+		}
+		if c.Refs == nil {
+			c.Refs = []string{}
+		}
+
+		u, ok := emails[item.AuthorEmail]
+		if !ok {
+			u, _ = models.GetUserByEmail(item.AuthorEmail)
+			emails[item.AuthorEmail] = u
+		}
+		if u != nil {
+			c.Author = u.APIFormat()
+		}
+
+		commits = append(commits, c)
+		commitsBySHA[c.SHA] = c
+	}
+	// Commits are listed before their parents.
+	for _, c := range commits {
+		for _, parent := range c.Parents {
+			if p, ok := commitsBySHA[parent]; ok {
+				p.Children = append(p.Children, c.SHA)
+			}
+		}
+	}
+
+	ctx.JSON(200, &commitGraph{
+		Page:    page,
+		HasMore: len(commits) == models.GraphPageSize,
+		Commits: commits,
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/git"
+	api "code.gitea.io/sdk/gitea"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+)
+
+type comparison struct {
+	BaseCommitID string                 `json:"base_commit_id"`
+	HeadCommitID string                 `json:"head_commit_id"`
+	MergeBase    string                 `json:"merge_base_commit_id"`
+	TotalCommits int                    `json:"total_commits"`
+	Commits      []*api.PayloadCommit   `json:"commits"`
+	Additions    int                    `json:"additions"`
+	Deletions    int                    `json:"deletions"`
+	Files        []*models.DiffFileStat `json:"files"`
+	HTMLURL      string                 `json:"html_url"`
+	DiffURL      string                 `json:"diff_url"`
+	PatchURL     string                 `json:"patch_url"`
+}
+
+// Compare returns the commits and changed files of a branch, tag or commit
+// since its merge base with another one of the same repository or of a fork,
+// the format of the path is "<base>...[<owner>[/<repo>]:]<head>".
+func Compare(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/compare/{basehead} repoCompare
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Comparison
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
+	if ctx.Repo.Repository.IsBare {
+		ctx.Status(404)
+		return
+	}
+
+	spec := ctx.Params("*")
+	cs, err := models.ParseCompareSpec(ctx.Repo.Repository, ctx.User, spec)
+	if err != nil {
+		if models.IsErrInvalidCompareSpec(err) {
+			ctx.Error(422, "", err)
+		} else if models.IsErrUserNotExist(err) || models.IsErrRepoNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "ParseCompareSpec", err)
+		}
+		return
+	}
+
+	info, err := models.GetCompareInfo(ctx.Repo.Repository, cs)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "GetCompareInfo", err)
+		}
+		return
+	}
+
+	files, err := models.GetDiffRangeFileStats(cs.HeadRepo.RepoPath(), info.MergeBase, info.HeadCommitID)
+	if err != nil {
+		ctx.Error(500, "GetDiffRangeFileStats", err)
+		return
+	}
+
+	htmlURL := ctx.Repo.Repository.HTMLURL() + "/compare/" + spec
+	c := &comparison{
+		BaseCommitID: info.BaseCommitID,
+		HeadCommitID: info.HeadCommitID,
+		MergeBase:    info.MergeBase,
+		TotalCommits: info.Commits.Len(),
+		Commits:      make([]*api.PayloadCommit, 0, info.Commits.Len()),
+		Files:        files,
+		HTMLURL:      htmlURL,
+		DiffURL:      htmlURL + ".diff",
+		PatchURL:     htmlURL + ".patch",
+	}
+	for e := info.Commits.Front(); e != nil; e = e.Next() {
+		c.Commits = append(c.Commits, convert.ToCommit(e.Value.(*git.Commit)))
+	}
+	for _, file := range files {
+		c.Additions += file.Addition
+		c.Deletions += file.Deletion
+	}
+	ctx.JSON(200, c)
+}
@@ -0,0 +1,453 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"path"
+	"strings"
+	"time"
+
+	"code.gitea.io/git"
+	api "code.gitea.io/sdk/gitea"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+)
+
+type contentsEntry struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	SHA         string `json:"sha"`
+	Size        int64  `json:"size"`
+	Encoding    string `json:"encoding,omitempty"`
+	Content     string `json:"content,omitempty"`
+	HTMLURL     string `json:"html_url"`
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+type contentsResponse struct {
+	Content *contentsEntry     `json:"content"`
+	Commit  *api.PayloadCommit `json:"commit"`
+}
+
+// toContentsEntry converts the tree entry at treePath of ref, the content of
+// files is only included if withContent is true.
+func toContentsEntry(repo *models.Repository, ref, treePath string, entry *git.TreeEntry, withContent bool) (*contentsEntry, error) {
+	repoURL := repo.HTMLURL()
+	e := &contentsEntry{
+		Type:    "file",
+		Name:    path.Base(treePath),
+		Path:    treePath,
+		SHA:     entry.ID.String(),
+		HTMLURL: repoURL + "/src/" + ref + "/" + treePath,
+	}
+	switch {
+	case entry.IsDir():
+		e.Type = "dir"
+		return e, nil
+	case entry.IsSubModule():
+		e.Type = "submodule"
+		return e, nil
+	case entry.IsLink():
+		e.Type = "symlink"
+	}
+
+	e.Size = entry.Size()
+	e.DownloadURL = repoURL + "/raw/" + ref + "/" + treePath
+	if !withContent {
+		return e, nil
+	}
+
+	r, err := entry.Blob().Data()
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	e.Encoding = "base64"
+	e.Content = base64.StdEncoding.EncodeToString(data)
+	return e, nil
+}
+
+// cleanContentsPath returns the path of a file relative to the repository
+// root, it can neither leave the repository nor enter the .git directory of
+// the local copy.
+func cleanContentsPath(treePath string) (string, error) {
+	treePath = strings.TrimPrefix(path.Clean("/"+treePath), "/")
+	for _, part := range strings.Split(treePath, "/") {
+		if strings.EqualFold(part, ".git") {
+			return "", errors.New("path must not contain a .git directory")
+		}
+	}
+	return treePath, nil
+}
+
+// GetContents returns the file or the entries of the directory at the path of
+// a branch, tag or commit given by the ref query, or of the default branch
+func GetContents(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/contents/{filepath} repoGetContents
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: ContentsEntryList
+	//       404: notFound
+	//       500: error
+
+	if ctx.Repo.Repository.IsBare {
+		ctx.Status(404)
+		return
+	}
+
+	treePath, err := cleanContentsPath(ctx.Params("*"))
+	if err != nil {
+		ctx.Status(404)
+		return
+	}
+	ref := ctx.Query("ref")
+	if len(ref) == 0 {
+		ref = ctx.Repo.Repository.DefaultBranch
+	}
+	commit, err := ctx.Repo.GitRepo.GetCommit(ref)
+	if err != nil {
+		ctx.Error(404, "GetCommit", err)
+		return
+	}
+
+	entry, err := commit.GetTreeEntryByPath(treePath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "GetTreeEntryByPath", err)
+		}
+		return
+	}
+	if !entry.IsDir() {
+		file, err := toContentsEntry(ctx.Repo.Repository, ref, treePath, entry, true)
+		if err != nil {
+			ctx.Error(500, "toContentsEntry", err)
+			return
+		}
+		ctx.JSON(200, file)
+		return
+	}
+
+	tree, err := commit.SubTree(treePath)
+	if err != nil {
+		ctx.Error(500, "SubTree", err)
+		return
+	}
+	entries, err := tree.ListEntries()
+	if err != nil {
+		ctx.Error(500, "ListEntries", err)
+		return
+	}
+	files := make([]*contentsEntry, len(entries))
+	for i, entry := range entries {
+		files[i], err = toContentsEntry(ctx.Repo.Repository, ref, path.Join(treePath, entry.Name()), entry, false)
+		if err != nil {
+			ctx.Error(500, "toContentsEntry", err)
+			return
+		}
+	}
+	ctx.JSON(200, &files)
+}
+
+// contentsChange is a change of a repository file requested by the API
+type contentsChange struct {
+	TreePath  string
+	OldBranch string
+	NewBranch string
+	Commit    *git.Commit
+	// Entry is the current file, nil if it does not exist.
+	Entry     *git.TreeEntry
+	Committer *git.Signature
+}
+
+// prepareContentsChange checks that the file at the path of the request may
+// be changed as requested by form.
+func prepareContentsChange(ctx *context.APIContext, form auth.RepoContentsForm) *contentsChange {
+	if ctx.Repo.Repository.IsBare {
+		ctx.Status(404)
+		return nil
+	} else if !ctx.Repo.Repository.CanEnableEditor() {
+		ctx.Error(403, "", "repository can not be edited")
+		return nil
+	}
+
+	treePath, err := cleanContentsPath(ctx.Params("*"))
+	if err != nil {
+		ctx.Error(422, "", err)
+		return nil
+	} else if len(treePath) == 0 {
+		ctx.Error(422, "", "path must not be empty")
+		return nil
+	}
+
+	change := &contentsChange{
+		TreePath:  treePath,
+		OldBranch: form.Branch,
+		NewBranch: form.NewBranch,
+	}
+	if len(change.OldBranch) == 0 {
+		change.OldBranch = ctx.Repo.Repository.DefaultBranch
+	}
+	change.Commit, err = ctx.Repo.GitRepo.GetBranchCommit(change.OldBranch)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.Error(404, "", err)
+		} else {
+			ctx.Error(500, "GetBranchCommit", err)
+		}
+		return nil
+	}
+
+	if len(change.NewBranch) == 0 {
+		change.NewBranch = change.OldBranch
+	}
+	if change.NewBranch != change.OldBranch {
+		if ctx.Repo.GitRepo.IsBranchExist(change.NewBranch) {
+			ctx.Error(409, "", "new branch already exists")
+			return nil
+		}
+	} else if protected, err := ctx.Repo.Repository.IsProtectedBranch(change.NewBranch); err != nil {
+		ctx.Error(500, "IsProtectedBranch", err)
+		return nil
+	} else if protected {
+		ctx.Error(403, "", "branch is protected")
+		return nil
+	}
+
+	// Every parent must be a directory, and the file itself a regular file.
+	parts := strings.Split(treePath, "/")
+	for i := range parts {
+		entry, err := change.Commit.GetTreeEntryByPath(strings.Join(parts[:i+1], "/"))
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				break
+			}
+			ctx.Error(500, "GetTreeEntryByPath", err)
+			return nil
+		}
+		if i < len(parts)-1 {
+			if !entry.IsDir() {
+				ctx.Error(422, "", "parent of the path is a file")
+				return nil
+			}
+		} else if entry.IsDir() || entry.IsLink() || entry.IsSubModule() {
+			ctx.Error(422, "", "path is not a regular file")
+			return nil
+		} else {
+			change.Entry = entry
+		}
+	}
+
+	if form.Committer != nil {
+		if len(form.Committer.Name) == 0 || len(form.Committer.Email) == 0 {
+			ctx.Error(422, "", "committer must have a name and an email")
+			return nil
+		}
+		change.Committer = &git.Signature{
+			Name:  form.Committer.Name,
+			Email: form.Committer.Email,
+			When:  time.Now(),
+		}
+	}
+	return change
+}
+
+// checkContentsSHA checks that the sha of form is the one of the current file
+func checkContentsSHA(ctx *context.APIContext, form auth.RepoContentsForm, change *contentsChange) bool {
+	if change.Entry == nil {
+		ctx.Status(404)
+		return false
+	} else if len(form.SHA) == 0 {
+		ctx.Error(422, "", "sha must be given")
+		return false
+	} else if form.SHA != change.Entry.ID.String() {
+		ctx.Error(409, "", "sha does not match the current file")
+		return false
+	}
+	return true
+}
+
+// contentsMessage returns the commit message of form, or the default message
+// given by key.
+func contentsMessage(ctx *context.APIContext, form auth.RepoContentsForm, key, treePath string) string {
+	if message := strings.TrimSpace(form.Message); len(message) > 0 {
+		return message
+	}
+	return ctx.Tr(key, treePath)
+}
+
+// writeContentsResponse writes the commit of the change on its new branch,
+// and the file at the path if it exists.
+func writeContentsResponse(ctx *context.APIContext, status int, change *contentsChange) {
+	commit, err := ctx.Repo.GitRepo.GetBranchCommit(change.NewBranch)
+	if err != nil {
+		ctx.Error(500, "GetBranchCommit", err)
+		return
+	}
+
+	resp := &contentsResponse{Commit: convert.ToCommit(commit)}
+	entry, err := commit.GetTreeEntryByPath(change.TreePath)
+	if err == nil {
+		resp.Content, err = toContentsEntry(ctx.Repo.Repository, change.NewBranch, change.TreePath, entry, false)
+		if err != nil {
+			ctx.Error(500, "toContentsEntry", err)
+			return
+		}
+	} else if !git.IsErrNotExist(err) {
+		ctx.Error(500, "GetTreeEntryByPath", err)
+		return
+	}
+	ctx.JSON(status, resp)
+}
+
+// updateContents creates or updates the file at the path with the content of
+// form.
+func updateContents(ctx *context.APIContext, form auth.RepoContentsForm, isNewFile bool) {
+	change := prepareContentsChange(ctx, form)
+	if ctx.Written() {
+		return
+	}
+
+	messageKey := "repo.editor.add"
+	if isNewFile {
+		if change.Entry != nil {
+			ctx.Error(409, "", models.ErrRepoFileAlreadyExist{FileName: change.TreePath})
+			return
+		}
+	} else {
+		if !checkContentsSHA(ctx, form, change) {
+			return
+		}
+		messageKey = "repo.editor.update"
+	}
+
+	content, err := base64.StdEncoding.DecodeString(form.Content)
+	if err != nil {
+		ctx.Error(422, "", "content must be base64 encoded")
+		return
+	}
+
+	if err = ctx.Repo.Repository.UpdateRepoFile(ctx.User, models.UpdateRepoFileOptions{
+		LastCommitID: change.Commit.ID.String(),
+		OldBranch:    change.OldBranch,
+		NewBranch:    change.NewBranch,
+		OldTreeName:  change.TreePath,
+		NewTreeName:  change.TreePath,
+		Message:      contentsMessage(ctx, form, messageKey, change.TreePath),
+		Content:      string(content),
+		IsNewFile:    isNewFile,
+		Committer:    change.Committer,
+	}); err != nil {
+		if models.IsErrRepoFileAlreadyExist(err) {
+			ctx.Error(409, "", err)
+		} else {
+			ctx.Error(500, "UpdateRepoFile", err)
+		}
+		return
+	}
+
+	status := 200
+	if isNewFile {
+		status = 201
+	}
+	writeContentsResponse(ctx, status, change)
+}
+
+// CreateContents creates a file in a repository
+func CreateContents(ctx *context.APIContext, form auth.RepoContentsForm) {
+	// swagger:route POST /repos/{username}/{reponame}/contents/{filepath} repoCreateContents
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       201: ContentsResponse
+	//       403: forbidden
+	//       404: notFound
+	//       409: error
+	//       422: validationError
+	//       500: error
+
+	updateContents(ctx, form, true)
+}
+
+// UpdateContents updates a file of a repository, the sha of the form must be
+// the one of the current file.
+func UpdateContents(ctx *context.APIContext, form auth.RepoContentsForm) {
+	// swagger:route PUT /repos/{username}/{reponame}/contents/{filepath} repoUpdateContents
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: ContentsResponse
+	//       403: forbidden
+	//       404: notFound
+	//       409: error
+	//       422: validationError
+	//       500: error
+
+	updateContents(ctx, form, false)
+}
+
+// DeleteContents deletes a file of a repository, the sha of the form must be
+// the one of the current file.
+func DeleteContents(ctx *context.APIContext, form auth.RepoContentsForm) {
+	// swagger:route DELETE /repos/{username}/{reponame}/contents/{filepath} repoDeleteContents
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: ContentsResponse
+	//       403: forbidden
+	//       404: notFound
+	//       409: error
+	//       422: validationError
+	//       500: error
+
+	change := prepareContentsChange(ctx, form)
+	if ctx.Written() {
+		return
+	} else if !checkContentsSHA(ctx, form, change) {
+		return
+	}
+
+	if err := ctx.Repo.Repository.DeleteRepoFile(ctx.User, models.DeleteRepoFileOptions{
+		LastCommitID: change.Commit.ID.String(),
+		OldBranch:    change.OldBranch,
+		NewBranch:    change.NewBranch,
+		TreePath:     change.TreePath,
+		Message:      contentsMessage(ctx, form, "repo.editor.delete", change.TreePath),
+		Committer:    change.Committer,
+	}); err != nil {
+		ctx.Error(500, "DeleteRepoFile", err)
+		return
+	}
+	writeContentsResponse(ctx, 200, change)
+}
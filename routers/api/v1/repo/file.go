@@ -15,6 +15,16 @@ import (
 // GetRawFile get a file by path on a repository
 // see https://github.com/gogits/go-gogs-client/wiki/Repositories-Contents#download-raw-content
 func GetRawFile(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/raw/{filepath} repoGetRawFile
+	//
+	//     Produces:
+	//     - application/octet-stream
+	//
+	//     Responses:
+	//       200: file
+	//       404: notFound
+	//       500: error
+
 	if !ctx.Repo.HasAccess() {
 		ctx.Status(404)
 		return
@@ -42,6 +52,16 @@ func GetRawFile(ctx *context.APIContext) {
 // GetArchive get archive of a repository
 // see https://github.com/gogits/go-gogs-client/wiki/Repositories-Contents#download-archive
 func GetArchive(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/archive/{archive} repoGetArchive
+	//
+	//     Produces:
+	//     - application/octet-stream
+	//
+	//     Responses:
+	//       200: file
+	//       404: notFound
+	//       500: error
+
 	repoPath := models.RepoPath(ctx.Params(":username"), ctx.Params(":reponame"))
 	gitRepo, err := git.OpenRepository(repoPath)
 	if err != nil {
@@ -55,6 +75,16 @@ func GetArchive(ctx *context.APIContext) {
 
 // GetEditorconfig get editor config of a repository
 func GetEditorconfig(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/editorconfig/{filename} repoGetEditorConfig
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: EditorConfigDefinition
+	//       404: notFound
+	//       500: error
+
 	ec, err := ctx.Repo.GetEditorconfig()
 	if err != nil {
 		if git.IsErrNotExist(err) {
@@ -73,3 +103,50 @@ func GetEditorconfig(ctx *context.APIContext) {
 	}
 	ctx.JSON(200, def)
 }
+
+// GetReadme returns the README file in the root directory of a branch, tag or
+// commit given by the ref query, or of the default branch
+func GetReadme(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/readme repoGetReadme
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: ContentsEntry
+	//       404: notFound
+	//       500: error
+
+	if ctx.Repo.Repository.IsBare {
+		ctx.Status(404)
+		return
+	}
+
+	ref := ctx.Query("ref")
+	if len(ref) == 0 {
+		ref = ctx.Repo.Repository.DefaultBranch
+	}
+	commit, err := ctx.Repo.GitRepo.GetCommit(ref)
+	if err != nil {
+		ctx.Error(404, "GetCommit", err)
+		return
+	}
+
+	entries, err := commit.ListEntries()
+	if err != nil {
+		ctx.Error(500, "ListEntries", err)
+		return
+	}
+	entry := repo.FindReadmeEntry(entries)
+	if entry == nil {
+		ctx.Status(404)
+		return
+	}
+
+	file, err := toContentsEntry(ctx.Repo.Repository, ref, entry.Name(), entry, true)
+	if err != nil {
+		ctx.Error(500, "toContentsEntry", err)
+		return
+	}
+	ctx.JSON(200, file)
+}
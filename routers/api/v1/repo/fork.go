@@ -9,11 +9,12 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 // ListForks list a repository's forks
 func ListForks(ctx *context.APIContext) {
-	// swagger:route GET /repos/{owner}/{repo}/forks listForks
+	// swagger:route GET /repos/{username}/{reponame}/forks listForks
 	//
 	//     Produces:
 	//     - application/json
@@ -22,11 +23,13 @@ func ListForks(ctx *context.APIContext) {
 	//       200: RepositoryList
 	//       500: error
 
-	forks, err := ctx.Repo.Repository.GetForks()
+	forks, err := ctx.Repo.Repository.GetForks(ctx.User)
 	if err != nil {
 		ctx.Error(500, "GetForks", err)
 		return
 	}
+	start, end := utils.Paginate(ctx, len(forks))
+	forks = forks[start:end]
 	apiForks := make([]*api.Repository, len(forks))
 	for i, fork := range forks {
 		access, err := models.AccessLevel(ctx.User.ID, fork)
@@ -41,7 +44,7 @@ func ListForks(ctx *context.APIContext) {
 
 // CreateFork create a fork of a repo
 func CreateFork(ctx *context.APIContext, form api.CreateForkOption) {
-	// swagger:route POST /repos/{owner}/{repo}/forks createFork
+	// swagger:route POST /repos/{username}/{reponame}/forks createFork
 	//
 	//     Produces:
 	//     - application/json
@@ -15,13 +15,13 @@ import (
 
 // ListHooks list all hooks of a repository
 func ListHooks(ctx *context.APIContext) {
-	// swagger:route GET /repos/{username}/{reponame}/hooks
+	// swagger:route GET /repos/{username}/{reponame}/hooks repoListHooks
 	//
 	//     Produces:
 	//     - application/json
 	//
 	//     Responses:
-	//       200: apiHooks
+	//       200: HookList
 	//       500: error
 
 	hooks, err := models.GetWebhooksByRepoID(ctx.Repo.Repository.ID)
@@ -30,6 +30,9 @@ func ListHooks(ctx *context.APIContext) {
 		return
 	}
 
+	start, end := utils.Paginate(ctx, len(hooks))
+	hooks = hooks[start:end]
+
 	apiHooks := make([]*api.Hook, len(hooks))
 	for i := range hooks {
 		apiHooks[i] = convert.ToHook(ctx.Repo.RepoLink, hooks[i])
@@ -39,6 +42,16 @@ func ListHooks(ctx *context.APIContext) {
 
 // GetHook get a repo's hook by id
 func GetHook(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/hooks/{id} repoGetHook
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Hook
+	//       404: notFound
+	//       500: error
+
 	repo := ctx.Repo
 	hookID := ctx.ParamsInt64(":id")
 	hook, err := utils.GetRepoHook(ctx, repo.Repository.ID, hookID)
@@ -48,9 +61,37 @@ func GetHook(ctx *context.APIContext) {
 	ctx.JSON(200, convert.ToHook(repo.RepoLink, hook))
 }
 
+// GetHookSignature returns the signature headers a delivery of the request
+// body to the hook would carry
+func GetHookSignature(ctx *context.APIContext) {
+	// swagger:route POST /repos/{username}/{reponame}/hooks/{id}/signature repoHookSignature
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: empty
+	//       404: notFound
+	//       500: error
+
+	hook, err := utils.GetRepoHook(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+
+	body, err := ctx.Req.Body().Bytes()
+	if err != nil {
+		ctx.Error(500, "ReadBody", err)
+		return
+	}
+	ctx.JSON(200, map[string]interface{}{
+		"headers": hook.SignatureHeaders(body),
+	})
+}
+
 // CreateHook create a hook for a repository
 func CreateHook(ctx *context.APIContext, form api.CreateHookOption) {
-	// swagger:route POST /repos/{username}/{reponame}/hooks
+	// swagger:route POST /repos/{username}/{reponame}/hooks repoCreateHook
 	//
 	//     Consumes:
 	//     - application/json
@@ -59,7 +100,7 @@ func CreateHook(ctx *context.APIContext, form api.CreateHookOption) {
 	//     - application/json
 	//
 	//     Responses:
-	//       200: apiHook
+	//       200: Hook
 	//       422: validationError
 	//       500: error
 
@@ -71,13 +112,13 @@ func CreateHook(ctx *context.APIContext, form api.CreateHookOption) {
 
 // EditHook modify a hook of a repository
 func EditHook(ctx *context.APIContext, form api.EditHookOption) {
-	// swagger:route PATCH /repos/{username}/{reponame}/hooks/{id}
+	// swagger:route PATCH /repos/{username}/{reponame}/hooks/{id} repoEditHook
 	//
 	//     Produces:
 	//     - application/json
 	//
 	//     Responses:
-	//       200: apiHook //TODO
+	//       200: Hook
 	//       422: validationError
 	//       500: error
 
@@ -87,7 +128,7 @@ func EditHook(ctx *context.APIContext, form api.EditHookOption) {
 
 // DeleteHook delete a hook of a repository
 func DeleteHook(ctx *context.APIContext) {
-	// swagger:route DELETE /repos/{username}/{reponame}/hooks/{id}
+	// swagger:route DELETE /repos/{username}/{reponame}/hooks/{id} repoDeleteHook
 	//
 	//     Produces:
 	//     - application/json
@@ -7,22 +7,52 @@ package repo
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	api "code.gitea.io/sdk/gitea"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
 	"code.gitea.io/gitea/modules/context"
-	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
+type issueInfo struct {
+	*models.APIIssue
+	DueDate string `json:"due_date,omitempty"`
+}
+
+func toIssueInfo(issue *models.Issue) *issueInfo {
+	return &issueInfo{
+		APIIssue: issue.APIFormat(),
+		DueDate:  issue.DeadlineString(),
+	}
+}
+
 // ListIssues list the issues of a repository
 func ListIssues(ctx *context.APIContext) {
-	isClosed := ctx.Query("state") == "closed"
+	// swagger:route GET /repos/{username}/{reponame}/issues issueListIssues
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: IssueList
+	//       500: error
+
+	listOpts := utils.GetListOptions(ctx)
 	issueOpts := models.IssuesOptions{
-		RepoID:   ctx.Repo.Repository.ID,
-		Page:     ctx.QueryInt("page"),
-		IsClosed: util.OptionalBoolOf(isClosed),
+		RepoID:    ctx.Repo.Repository.ID,
+		Page:      listOpts.Page,
+		PageSize:  listOpts.PageSize,
+		IsClosed:  util.OptionalBoolOf(ctx.Query("state") == "closed"),
+		SortType:  ctx.Query("sort"),
+		IsOverdue: ctx.QueryBool("overdue"),
+		Doer:      ctx.User,
+	}
+	if ctx.Query("state") == "all" {
+		issueOpts.IsClosed = util.OptionalBoolNone
 	}
 
 	issues, err := models.Issues(&issueOpts)
@@ -30,14 +60,10 @@ func ListIssues(ctx *context.APIContext) {
 		ctx.Error(500, "Issues", err)
 		return
 	}
-	if ctx.Query("state") == "all" {
-		issueOpts.IsClosed = util.OptionalBoolOf(!isClosed)
-		tempIssues, err := models.Issues(&issueOpts)
-		if err != nil {
-			ctx.Error(500, "Issues", err)
-			return
-		}
-		issues = append(issues, tempIssues...)
+	count, err := models.CountIssues(&issueOpts)
+	if err != nil {
+		ctx.Error(500, "CountIssues", err)
+		return
 	}
 
 	err = models.IssueList(issues).LoadAttributes()
@@ -46,18 +72,28 @@ func ListIssues(ctx *context.APIContext) {
 		return
 	}
 
-	apiIssues := make([]*api.Issue, len(issues))
+	apiIssues := make([]*issueInfo, len(issues))
 	for i := range issues {
-		apiIssues[i] = issues[i].APIFormat()
+		apiIssues[i] = toIssueInfo(issues[i])
 	}
 
-	ctx.SetLinkHeader(ctx.Repo.Repository.NumIssues, setting.UI.IssuePagingNum)
+	utils.SetPaginationHeaders(ctx, int(count))
 	ctx.JSON(200, &apiIssues)
 }
 
 // GetIssue get an issue of a repository
 func GetIssue(ctx *context.APIContext) {
-	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	// swagger:route GET /repos/{username}/{reponame}/issues/{index} issueGetIssue
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Issue
+	//       404: notFound
+	//       500: error
+
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"), ctx.User)
 	if err != nil {
 		if models.IsErrIssueNotExist(err) {
 			ctx.Status(404)
@@ -66,20 +102,44 @@ func GetIssue(ctx *context.APIContext) {
 		}
 		return
 	}
-	ctx.JSON(200, issue.APIFormat())
+	ctx.JSONWithETag(200, toIssueInfo(issue))
+}
+
+// CreateIssueOption options to create one issue, with the fields the SDK
+// does not know about yet
+type CreateIssueOption struct {
+	api.CreateIssueOption
+	Confidential bool `json:"confidential"`
 }
 
 // CreateIssue create an issue of a repository
-func CreateIssue(ctx *context.APIContext, form api.CreateIssueOption) {
+func CreateIssue(ctx *context.APIContext, form CreateIssueOption) {
+	// swagger:route POST /repos/{username}/{reponame}/issues issueCreateIssue
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       201: Issue
+	//       202: empty
+	//       413: error
+	//       422: validationError
+	//       500: error
+
 	issue := &models.Issue{
 		RepoID:   ctx.Repo.Repository.ID,
 		Title:    form.Title,
 		PosterID: ctx.User.ID,
 		Poster:   ctx.User,
 		Content:  form.Body,
+
+		IsConfidential: form.Confidential,
 	}
 
-	if ctx.Repo.IsWriter() {
+	if ctx.Repo.CanTriage(models.UnitTypeIssues) {
 		if len(form.Assignee) > 0 {
 			assignee, err := models.GetUserByName(form.Assignee)
 			if err != nil {
@@ -98,7 +158,15 @@ func CreateIssue(ctx *context.APIContext, form api.CreateIssueOption) {
 	}
 
 	if err := models.NewIssue(ctx.Repo.Repository, issue, form.Labels, nil); err != nil {
-		ctx.Error(500, "NewIssue", err)
+		if models.IsErrContentBlocked(err) {
+			ctx.Error(422, "", err)
+		} else if models.IsErrAttachmentQuotaExceeded(err) {
+			ctx.Error(413, "", err)
+		} else if models.IsErrContentHeldForModeration(err) {
+			ctx.Status(202)
+		} else {
+			ctx.Error(500, "NewIssue", err)
+		}
 		return
 	}
 
@@ -116,12 +184,27 @@ func CreateIssue(ctx *context.APIContext, form api.CreateIssueOption) {
 		ctx.Error(500, "GetIssueByID", err)
 		return
 	}
-	ctx.JSON(201, issue.APIFormat())
+	ctx.JSON(201, toIssueInfo(issue))
 }
 
 // EditIssue modify an issue of a repository
-func EditIssue(ctx *context.APIContext, form api.EditIssueOption) {
-	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+func EditIssue(ctx *context.APIContext, form auth.EditIssueForm) {
+	// swagger:route PATCH /repos/{username}/{reponame}/issues/{index} issueEditIssue
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       201: Issue
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"), ctx.User)
 	if err != nil {
 		if models.IsErrIssueNotExist(err) {
 			ctx.Status(404)
@@ -131,19 +214,32 @@ func EditIssue(ctx *context.APIContext, form api.EditIssueOption) {
 		return
 	}
 
-	if !issue.IsPoster(ctx.User.ID) && !ctx.Repo.IsWriter() {
+	// Triagers may change the state and metadata of issues but not their content.
+	canEditContent := issue.IsPoster(ctx.User.ID) || ctx.Repo.CanWrite(models.UnitTypeIssues)
+	if !canEditContent && !ctx.Repo.CanTriage(models.UnitTypeIssues) {
 		ctx.Status(403)
 		return
 	}
 
-	if len(form.Title) > 0 {
-		issue.Title = form.Title
+	var deadline time.Time
+	if form.DueDate != nil && len(*form.DueDate) > 0 {
+		deadline, err = time.ParseInLocation("2006-01-02", *form.DueDate, time.Local)
+		if err != nil {
+			ctx.Error(422, "", fmt.Sprintf("invalid due date: %s", *form.DueDate))
+			return
+		}
 	}
-	if form.Body != nil {
-		issue.Content = *form.Body
+
+	if canEditContent {
+		if len(form.Title) > 0 {
+			issue.Title = form.Title
+		}
+		if form.Body != nil {
+			issue.Content = *form.Body
+		}
 	}
 
-	if ctx.Repo.IsWriter() && form.Assignee != nil &&
+	if ctx.Repo.CanTriage(models.UnitTypeIssues) && form.Assignee != nil &&
 		(issue.Assignee == nil || issue.Assignee.LowerName != strings.ToLower(*form.Assignee)) {
 		if len(*form.Assignee) == 0 {
 			issue.AssigneeID = 0
@@ -165,7 +261,7 @@ func EditIssue(ctx *context.APIContext, form api.EditIssueOption) {
 			return
 		}
 	}
-	if ctx.Repo.IsWriter() && form.Milestone != nil &&
+	if ctx.Repo.CanTriage(models.UnitTypeIssues) && form.Milestone != nil &&
 		issue.MilestoneID != *form.Milestone {
 		oldMilestoneID := issue.MilestoneID
 		issue.MilestoneID = *form.Milestone
@@ -175,10 +271,20 @@ func EditIssue(ctx *context.APIContext, form api.EditIssueOption) {
 		}
 	}
 
+	if ctx.Repo.CanWrite(models.UnitTypeIssues) && form.Confidential != nil && !issue.IsPull {
+		issue.IsConfidential = *form.Confidential
+	}
+
 	if err = models.UpdateIssue(issue); err != nil {
 		ctx.Error(500, "UpdateIssue", err)
 		return
 	}
+	if ctx.Repo.CanTriage(models.UnitTypeIssues) && form.DueDate != nil {
+		if err = issue.UpdateDeadline(ctx.User, deadline); err != nil {
+			ctx.Error(500, "UpdateDeadline", err)
+			return
+		}
+	}
 	if form.State != nil {
 		if err = issue.ChangeStatus(ctx.User, ctx.Repo.Repository, api.StateClosed == api.StateType(*form.State)); err != nil {
 			ctx.Error(500, "ChangeStatus", err)
@@ -192,5 +298,5 @@ func EditIssue(ctx *context.APIContext, form api.EditIssueOption) {
 		ctx.Error(500, "GetIssueByID", err)
 		return
 	}
-	ctx.JSON(201, issue.APIFormat())
+	ctx.JSON(201, toIssueInfo(issue))
 }
@@ -11,27 +11,56 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
+// userComments returns the comments of users among comments, the other ones
+// are events listed by the timeline of their issue.
+func userComments(comments []*models.Comment) []*models.Comment {
+	userComments := make([]*models.Comment, 0, len(comments))
+	for _, c := range comments {
+		if !c.Type.IsEvent() {
+			userComments = append(userComments, c)
+		}
+	}
+	return userComments
+}
+
 // ListIssueComments list all the comments of an issue
 func ListIssueComments(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/issues/{index}/comments issueListComments
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: CommentList
+	//       404: notFound
+	//       500: error
+
 	var since time.Time
 	if len(ctx.Query("since")) > 0 {
 		since, _ = time.Parse(time.RFC3339, ctx.Query("since"))
 	}
 
 	// comments,err:=models.GetCommentsByIssueIDSince(, since)
-	issue, err := models.GetRawIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"), ctx.User)
 	if err != nil {
-		ctx.Error(500, "GetRawIssueByIndex", err)
+		if models.IsErrIssueNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "GetIssueByIndex", err)
+		}
 		return
 	}
 
-	comments, err := models.GetCommentsByIssueIDSince(issue.ID, since.Unix())
+	opts := utils.GetListOptions(ctx)
+	comments, total, err := models.GetUserCommentsByIssueIDSince(issue.ID, since.Unix(), opts.Page, opts.PageSize)
 	if err != nil {
-		ctx.Error(500, "GetCommentsByIssueIDSince", err)
+		ctx.Error(500, "GetUserCommentsByIssueIDSince", err)
 		return
 	}
+	utils.SetPaginationHeaders(ctx, int(total))
 
 	apiComments := make([]*api.Comment, len(comments))
 	for i := range comments {
@@ -42,16 +71,29 @@ func ListIssueComments(ctx *context.APIContext) {
 
 // ListRepoIssueComments returns all issue-comments for an issue
 func ListRepoIssueComments(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/issues/comments issueListRepoComments
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: CommentList
+	//       500: error
+
 	var since time.Time
 	if len(ctx.Query("since")) > 0 {
 		since, _ = time.Parse(time.RFC3339, ctx.Query("since"))
 	}
 
-	comments, err := models.GetCommentsByRepoIDSince(ctx.Repo.Repository.ID, since.Unix())
+	comments, err := models.GetCommentsByRepoIDSince(ctx.Repo.Repository.ID, since.Unix(), ctx.User)
 	if err != nil {
 		ctx.Error(500, "GetCommentsByRepoIDSince", err)
 		return
 	}
+	comments = userComments(comments)
+
+	start, end := utils.Paginate(ctx, len(comments))
+	comments = comments[start:end]
 
 	apiComments := make([]*api.Comment, len(comments))
 	for i := range comments {
@@ -62,15 +104,43 @@ func ListRepoIssueComments(ctx *context.APIContext) {
 
 // CreateIssueComment create a comment for an issue
 func CreateIssueComment(ctx *context.APIContext, form api.CreateIssueCommentOption) {
-	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	// swagger:route POST /repos/{username}/{reponame}/issues/{index}/comments issueCreateComment
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       201: Comment
+	//       202: empty
+	//       404: notFound
+	//       413: error
+	//       422: validationError
+	//       500: error
+
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"), ctx.User)
 	if err != nil {
-		ctx.Error(500, "GetIssueByIndex", err)
+		if models.IsErrIssueNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "GetIssueByIndex", err)
+		}
 		return
 	}
 
 	comment, err := models.CreateIssueComment(ctx.User, ctx.Repo.Repository, issue, form.Body, nil)
 	if err != nil {
-		ctx.Error(500, "CreateIssueComment", err)
+		if models.IsErrContentBlocked(err) {
+			ctx.Error(422, "", err)
+		} else if models.IsErrAttachmentQuotaExceeded(err) {
+			ctx.Error(413, "", err)
+		} else if models.IsErrContentHeldForModeration(err) {
+			ctx.Status(202)
+		} else {
+			ctx.Error(500, "CreateIssueComment", err)
+		}
 		return
 	}
 
@@ -79,6 +149,40 @@ func CreateIssueComment(ctx *context.APIContext, form api.CreateIssueCommentOpti
 
 // EditIssueComment modify a comment of an issue
 func EditIssueComment(ctx *context.APIContext, form api.EditIssueCommentOption) {
+	// swagger:route PATCH /repos/{username}/{reponame}/issues/comments/{id} issueEditRepoComment
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Comment
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       413: error
+	//       422: validationError
+	//       500: error
+
+	// swagger:route PATCH /repos/{username}/{reponame}/issues/{index}/comments/{id} issueEditComment
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Comment
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       413: error
+	//       422: validationError
+	//       500: error
+
 	comment, err := models.GetCommentByID(ctx.ParamsInt64(":id"))
 	if err != nil {
 		if models.IsErrCommentNotExist(err) {
@@ -97,9 +201,14 @@ func EditIssueComment(ctx *context.APIContext, form api.EditIssueCommentOption)
 		return
 	}
 
+	oldContent := comment.Content
 	comment.Content = form.Body
-	if err := models.UpdateComment(comment); err != nil {
-		ctx.Error(500, "UpdateComment", err)
+	if err := models.UpdateComment(ctx.User, comment, oldContent); err != nil {
+		if models.IsErrAttachmentQuotaExceeded(err) {
+			ctx.Error(413, "", err)
+		} else {
+			ctx.Error(500, "UpdateComment", err)
+		}
 		return
 	}
 	ctx.JSON(200, comment.APIFormat())
@@ -107,6 +216,17 @@ func EditIssueComment(ctx *context.APIContext, form api.EditIssueCommentOption)
 
 // DeleteIssueComment delete a comment from an issue
 func DeleteIssueComment(ctx *context.APIContext) {
+	// swagger:route DELETE /repos/{username}/{reponame}/issues/{index}/comments/{id} issueDeleteComment
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
 	comment, err := models.GetCommentByID(ctx.ParamsInt64(":id"))
 	if err != nil {
 		if models.IsErrCommentNotExist(err) {
@@ -125,7 +245,7 @@ func DeleteIssueComment(ctx *context.APIContext) {
 		return
 	}
 
-	if err = models.DeleteComment(comment); err != nil {
+	if err = models.DeleteComment(ctx.User, comment); err != nil {
 		ctx.Error(500, "DeleteCommentByID", err)
 		return
 	}
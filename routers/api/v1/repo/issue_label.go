@@ -13,7 +13,17 @@ import (
 
 // ListIssueLabels list all the labels of an issue
 func ListIssueLabels(ctx *context.APIContext) {
-	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	// swagger:route GET /repos/{username}/{reponame}/issues/{index}/labels issueGetLabels
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: IssueLabelList
+	//       404: notFound
+	//       500: error
+
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"), ctx.User)
 	if err != nil {
 		if models.IsErrIssueNotExist(err) {
 			ctx.Status(404)
@@ -32,12 +42,27 @@ func ListIssueLabels(ctx *context.APIContext) {
 
 // AddIssueLabels add labels for an issue
 func AddIssueLabels(ctx *context.APIContext, form api.IssueLabelsOption) {
-	if !ctx.Repo.IsWriter() {
+	// swagger:route POST /repos/{username}/{reponame}/issues/{index}/labels issueAddLabels
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: IssueLabelList
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
+	if !ctx.Repo.CanTriage(models.UnitTypeIssues) {
 		ctx.Status(403)
 		return
 	}
 
-	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"), ctx.User)
 	if err != nil {
 		if models.IsErrIssueNotExist(err) {
 			ctx.Status(404)
@@ -73,12 +98,24 @@ func AddIssueLabels(ctx *context.APIContext, form api.IssueLabelsOption) {
 
 // DeleteIssueLabel delete a label for an issue
 func DeleteIssueLabel(ctx *context.APIContext) {
-	if !ctx.Repo.IsWriter() {
+	// swagger:route DELETE /repos/{username}/{reponame}/issues/{index}/labels/{id} issueRemoveLabel
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
+	if !ctx.Repo.CanTriage(models.UnitTypeIssues) {
 		ctx.Status(403)
 		return
 	}
 
-	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"), ctx.User)
 	if err != nil {
 		if models.IsErrIssueNotExist(err) {
 			ctx.Status(404)
@@ -108,12 +145,27 @@ func DeleteIssueLabel(ctx *context.APIContext) {
 
 // ReplaceIssueLabels replace labels for an issue
 func ReplaceIssueLabels(ctx *context.APIContext, form api.IssueLabelsOption) {
-	if !ctx.Repo.IsWriter() {
+	// swagger:route PUT /repos/{username}/{reponame}/issues/{index}/labels issueReplaceLabels
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: IssueLabelList
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
+	if !ctx.Repo.CanTriage(models.UnitTypeIssues) {
 		ctx.Status(403)
 		return
 	}
 
-	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"), ctx.User)
 	if err != nil {
 		if models.IsErrIssueNotExist(err) {
 			ctx.Status(404)
@@ -149,12 +201,23 @@ func ReplaceIssueLabels(ctx *context.APIContext, form api.IssueLabelsOption) {
 
 // ClearIssueLabels delete all the labels for an issue
 func ClearIssueLabels(ctx *context.APIContext) {
-	if !ctx.Repo.IsWriter() {
+	// swagger:route DELETE /repos/{username}/{reponame}/issues/{index}/labels issueClearLabels
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
+	if !ctx.Repo.CanTriage(models.UnitTypeIssues) {
 		ctx.Status(403)
 		return
 	}
 
-	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"), ctx.User)
 	if err != nil {
 		if models.IsErrIssueNotExist(err) {
 			ctx.Status(404)
@@ -0,0 +1,109 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListPinnedIssues list the pinned issues of a repository in pin order
+func ListPinnedIssues(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/issues/pinned issueListPinned
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: PinnedIssueList
+	//       500: error
+
+	issues, err := models.GetPinnedIssues(ctx.Repo.Repository.ID, ctx.User)
+	if err != nil {
+		ctx.Error(500, "GetPinnedIssues", err)
+		return
+	}
+
+	start, end := utils.Paginate(ctx, len(issues))
+	issues = issues[start:end]
+
+	apiIssues := make([]*models.APIIssue, len(issues))
+	for i := range issues {
+		apiIssues[i] = issues[i].APIFormat()
+	}
+	ctx.JSON(200, &apiIssues)
+}
+
+// getPinIssue returns the issue of the request which can be pinned
+func getPinIssue(ctx *context.APIContext) *models.Issue {
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"), ctx.User)
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "GetIssueByIndex", err)
+		}
+		return nil
+	} else if issue.IsPull {
+		ctx.Error(422, "", "pull requests cannot be pinned")
+		return nil
+	}
+	return issue
+}
+
+// PinIssue pins an issue to the top of the issue list
+func PinIssue(ctx *context.APIContext) {
+	// swagger:route POST /repos/{username}/{reponame}/issues/{index}/pin issuePin
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
+	issue := getPinIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := issue.Pin(ctx.User); err != nil {
+		if models.IsErrIssueMaxPinReached(err) {
+			ctx.Error(422, "", err)
+		} else {
+			ctx.Error(500, "Pin", err)
+		}
+		return
+	}
+	ctx.Status(204)
+}
+
+// UnpinIssue unpins an issue
+func UnpinIssue(ctx *context.APIContext) {
+	// swagger:route DELETE /repos/{username}/{reponame}/issues/{index}/pin issueUnpin
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
+	issue := getPinIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := issue.Unpin(ctx.User); err != nil {
+		ctx.Error(500, "Unpin", err)
+		return
+	}
+	ctx.Status(204)
+}
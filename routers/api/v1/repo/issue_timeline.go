@@ -0,0 +1,138 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"time"
+
+	api "code.gitea.io/sdk/gitea"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// TimelineChange represents the change of a value by a timeline event, like
+// the title of a renamed issue
+type TimelineChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// TimelineSource represents the issue or pull request an issue is referenced
+// from
+type TimelineSource struct {
+	Repository string `json:"repository"`
+	Number     int64  `json:"number"`
+	Title      string `json:"title"`
+	IsPull     bool   `json:"is_pull"`
+	HTMLURL    string `json:"html_url"`
+}
+
+// TimelineEvent represents an event of the timeline of an issue, like a
+// status, label or milestone change
+type TimelineEvent struct {
+	ID           int64           `json:"id"`
+	Event        string          `json:"event"`
+	Actor        *api.User       `json:"actor"`
+	Label        *api.Label      `json:"label,omitempty"`
+	Milestone    *api.Milestone  `json:"milestone,omitempty"`
+	OldMilestone *api.Milestone  `json:"old_milestone,omitempty"`
+	Assignee     *api.User       `json:"assignee,omitempty"`
+	OldAssignee  *api.User       `json:"old_assignee,omitempty"`
+	Change       *TimelineChange `json:"change,omitempty"`
+	Branch       string          `json:"branch,omitempty"`
+	CommitID     string          `json:"commit_id,omitempty"`
+	Source       *TimelineSource `json:"source,omitempty"`
+	Created      time.Time       `json:"created_at"`
+}
+
+// toTimelineEvent converts a comment with its event attributes loaded to the
+// timeline event it records.
+func toTimelineEvent(c *models.Comment) *TimelineEvent {
+	event := &TimelineEvent{
+		ID:      c.ID,
+		Event:   c.EventName(),
+		Actor:   c.Poster.APIFormat(),
+		Created: c.Created,
+	}
+
+	switch {
+	case c.Type == models.CommentTypeLabel && c.Label != nil:
+		event.Label = c.Label.APIFormat()
+	case c.Type == models.CommentTypeMilestone:
+		if c.Milestone != nil {
+			event.Milestone = c.Milestone.APIFormat()
+		}
+		if c.OldMilestone != nil {
+			event.OldMilestone = c.OldMilestone.APIFormat()
+		}
+	case c.Type == models.CommentTypeAssignees:
+		if c.Assignee != nil {
+			event.Assignee = c.Assignee.APIFormat()
+		}
+		if c.OldAssignee != nil {
+			event.OldAssignee = c.OldAssignee.APIFormat()
+		}
+	case c.Type == models.CommentTypeChangeTitle, c.Type == models.CommentTypeDeadline:
+		event.Change = &TimelineChange{From: c.OldTitle, To: c.NewTitle}
+	case c.Type == models.CommentTypeDeleteBranch:
+		event.Branch = c.CommitSHA
+	case c.Type == models.CommentTypeCommitRef:
+		event.CommitID = c.CommitSHA
+	case c.Type.IsRef() && c.RefIssue != nil:
+		event.Source = &TimelineSource{
+			Repository: c.RefIssue.Repo.FullName(),
+			Number:     c.RefIssue.Index,
+			Title:      c.RefIssue.Title,
+			IsPull:     c.RefIssue.IsPull,
+			HTMLURL:    c.RefLink(),
+		}
+	}
+	return event
+}
+
+// ListIssueTimeline lists the timeline events of an issue, without the
+// comments of users
+func ListIssueTimeline(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/issues/{index}/timeline issueListTimeline
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: TimelineEventList
+	//       404: notFound
+	//       500: error
+
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"), ctx.User)
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "GetIssueByIndex", err)
+		}
+		return
+	}
+
+	comments, err := models.GetIssueTimeline(issue.ID)
+	if err != nil {
+		ctx.Error(500, "GetIssueTimeline", err)
+		return
+	}
+
+	start, end := utils.Paginate(ctx, len(comments))
+	comments = comments[start:end]
+
+	events := make([]*TimelineEvent, len(comments))
+	for i := range comments {
+		if err = comments[i].HideInvisibleRef(ctx.User); err != nil {
+			ctx.Error(500, "HideInvisibleRef", err)
+			return
+		}
+		events[i] = toTimelineEvent(comments[i])
+	}
+	ctx.JSON(200, &events)
+}
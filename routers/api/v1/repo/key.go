@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/routers/api/v1/convert"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 func composeDeployKeysAPILink(repoPath string) string {
@@ -22,12 +23,24 @@ func composeDeployKeysAPILink(repoPath string) string {
 // ListDeployKeys list all the deploy keys of a repository
 // see https://github.com/gogits/go-gogs-client/wiki/Repositories-Deploy-Keys#list-deploy-keys
 func ListDeployKeys(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/keys repoListKeys
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: DeployKeyList
+	//       500: error
+
 	keys, err := models.ListDeployKeys(ctx.Repo.Repository.ID)
 	if err != nil {
 		ctx.Error(500, "ListDeployKeys", err)
 		return
 	}
 
+	start, end := utils.Paginate(ctx, len(keys))
+	keys = keys[start:end]
+
 	apiLink := composeDeployKeysAPILink(ctx.Repo.Owner.Name + "/" + ctx.Repo.Repository.Name)
 	apiKeys := make([]*api.DeployKey, len(keys))
 	for i := range keys {
@@ -44,6 +57,16 @@ func ListDeployKeys(ctx *context.APIContext) {
 // GetDeployKey get a deploy key by id
 // see https://github.com/gogits/go-gogs-client/wiki/Repositories-Deploy-Keys#get-a-deploy-key
 func GetDeployKey(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/keys/{id} repoGetKey
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: DeployKey
+	//       404: notFound
+	//       500: error
+
 	key, err := models.GetDeployKeyByID(ctx.ParamsInt64(":id"))
 	if err != nil {
 		if models.IsErrDeployKeyNotExist(err) {
@@ -87,6 +110,19 @@ func HandleAddKeyError(ctx *context.APIContext, err error) {
 // CreateDeployKey create deploy key for a repository
 // see https://github.com/gogits/go-gogs-client/wiki/Repositories-Deploy-Keys#add-a-new-deploy-key
 func CreateDeployKey(ctx *context.APIContext, form api.CreateKeyOption) {
+	// swagger:route POST /repos/{username}/{reponame}/keys repoCreateKey
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       201: DeployKey
+	//       422: validationError
+	//       500: error
+
 	content, err := models.CheckPublicKeyString(form.Key)
 	if err != nil {
 		HandleCheckKeyStringError(ctx, err)
@@ -107,6 +143,16 @@ func CreateDeployKey(ctx *context.APIContext, form api.CreateKeyOption) {
 // DeleteDeploykey delete deploy key for a repository
 // see https://github.com/gogits/go-gogs-client/wiki/Repositories-Deploy-Keys#remove-a-deploy-key
 func DeleteDeploykey(ctx *context.APIContext) {
+	// swagger:route DELETE /repos/{username}/{reponame}/keys/{id} repoDeleteKey
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       500: error
+
 	if err := models.DeleteDeployKey(ctx.User, ctx.ParamsInt64(":id")); err != nil {
 		if models.IsErrKeyAccessDenied(err) {
 			ctx.Error(403, "", "You do not have access to this key")
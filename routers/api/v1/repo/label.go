@@ -10,26 +10,75 @@ import (
 	api "code.gitea.io/sdk/gitea"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
-// ListLabels list all the labels of a repository
+// labelInfo represents a label of a repository with its description
+type labelInfo struct {
+	*api.Label
+	Description string `json:"description"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+func toLabelInfo(label *models.Label) *labelInfo {
+	return &labelInfo{
+		Label:       label.APIFormat(),
+		Description: label.Description,
+		Scope:       label.Scope(),
+	}
+}
+
+// ListLabels list all the labels of a repository, or those matching the
+// keyword given by the q parameter
 func ListLabels(ctx *context.APIContext) {
-	labels, err := models.GetLabelsByRepoID(ctx.Repo.Repository.ID, ctx.Query("sort"))
+	// swagger:route GET /repos/{username}/{reponame}/labels repoListLabels
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: LabelList
+	//       500: error
+
+	var (
+		labels []*models.Label
+		err    error
+	)
+	if keyword := ctx.Query("q"); len(keyword) > 0 {
+		labels, err = models.SearchLabels(ctx.Repo.Repository.ID, keyword, setting.API.MaxResponseItems)
+	} else {
+		labels, err = models.GetLabelsByRepoID(ctx.Repo.Repository.ID, ctx.Query("sort"))
+	}
 	if err != nil {
 		ctx.Error(500, "GetLabelsByRepoID", err)
 		return
 	}
 
-	apiLabels := make([]*api.Label, len(labels))
+	start, end := utils.Paginate(ctx, len(labels))
+	labels = labels[start:end]
+
+	apiLabels := make([]*labelInfo, len(labels))
 	for i := range labels {
-		apiLabels[i] = labels[i].APIFormat()
+		apiLabels[i] = toLabelInfo(labels[i])
 	}
 	ctx.JSON(200, &apiLabels)
 }
 
 // GetLabel get label by repository and label id
 func GetLabel(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/labels/{id} repoGetLabel
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Label
+	//       404: notFound
+	//       500: error
+
 	var (
 		label *models.Label
 		err   error
@@ -49,31 +98,61 @@ func GetLabel(ctx *context.APIContext) {
 		return
 	}
 
-	ctx.JSON(200, label.APIFormat())
+	ctx.JSON(200, toLabelInfo(label))
 }
 
 // CreateLabel create a label for a repository
-func CreateLabel(ctx *context.APIContext, form api.CreateLabelOption) {
-	if !ctx.Repo.IsWriter() {
+func CreateLabel(ctx *context.APIContext, form auth.CreateRepoLabelForm) {
+	// swagger:route POST /repos/{username}/{reponame}/labels repoCreateLabel
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       201: Label
+	//       403: forbidden
+	//       422: validationError
+	//       500: error
+
+	if !ctx.Repo.CanWrite(models.UnitTypeIssues) {
 		ctx.Status(403)
 		return
 	}
 
 	label := &models.Label{
-		Name:   form.Name,
-		Color:  form.Color,
-		RepoID: ctx.Repo.Repository.ID,
+		Name:        form.Name,
+		Description: form.Description,
+		Color:       form.Color,
+		RepoID:      ctx.Repo.Repository.ID,
 	}
 	if err := models.NewLabels(label); err != nil {
 		ctx.Error(500, "NewLabel", err)
 		return
 	}
-	ctx.JSON(201, label.APIFormat())
+	ctx.JSON(201, toLabelInfo(label))
 }
 
 // EditLabel modify a label for a repository
-func EditLabel(ctx *context.APIContext, form api.EditLabelOption) {
-	if !ctx.Repo.IsWriter() {
+func EditLabel(ctx *context.APIContext, form auth.EditRepoLabelForm) {
+	// swagger:route PATCH /repos/{username}/{reponame}/labels/{id} repoEditLabel
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Label
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
+	if !ctx.Repo.CanWrite(models.UnitTypeIssues) {
 		ctx.Status(403)
 		return
 	}
@@ -91,19 +170,36 @@ func EditLabel(ctx *context.APIContext, form api.EditLabelOption) {
 	if form.Name != nil {
 		label.Name = *form.Name
 	}
+	if form.Description != nil {
+		label.Description = *form.Description
+	}
 	if form.Color != nil {
 		label.Color = *form.Color
 	}
+	if len(label.Name) == 0 || len(label.Name) > 50 || len(label.Description) > 200 || len(label.Color) != 7 {
+		ctx.Error(422, "", "invalid name, description or color")
+		return
+	}
 	if err := models.UpdateLabel(label); err != nil {
 		ctx.Handle(500, "UpdateLabel", err)
 		return
 	}
-	ctx.JSON(200, label.APIFormat())
+	ctx.JSON(200, toLabelInfo(label))
 }
 
 // DeleteLabel delete a label for a repository
 func DeleteLabel(ctx *context.APIContext) {
-	if !ctx.Repo.IsWriter() {
+	// swagger:route DELETE /repos/{username}/{reponame}/labels/{id} repoDeleteLabel
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       500: error
+
+	if !ctx.Repo.CanWrite(models.UnitTypeIssues) {
 		ctx.Status(403)
 		return
 	}
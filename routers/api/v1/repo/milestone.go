@@ -5,22 +5,37 @@
 package repo
 
 import (
+	"bytes"
+	"fmt"
 	"time"
 
 	api "code.gitea.io/sdk/gitea"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 // ListMilestones list all the milestones for a repository
 func ListMilestones(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/milestones repoListMilestones
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: MilestoneList
+	//       500: error
+
 	milestones, err := models.GetMilestonesByRepoID(ctx.Repo.Repository.ID)
 	if err != nil {
 		ctx.Error(500, "GetMilestonesByRepoID", err)
 		return
 	}
 
+	start, end := utils.Paginate(ctx, len(milestones))
+	milestones = milestones[start:end]
+
 	apiMilestones := make([]*api.Milestone, len(milestones))
 	for i := range milestones {
 		apiMilestones[i] = milestones[i].APIFormat()
@@ -30,6 +45,16 @@ func ListMilestones(ctx *context.APIContext) {
 
 // GetMilestone get a milestone for a repository
 func GetMilestone(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/milestones/{id} repoGetMilestone
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Milestone
+	//       404: notFound
+	//       500: error
+
 	milestone, err := models.GetMilestoneByRepoID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
 	if err != nil {
 		if models.IsErrMilestoneNotExist(err) {
@@ -44,6 +69,20 @@ func GetMilestone(ctx *context.APIContext) {
 
 // CreateMilestone create a milestone for a repository
 func CreateMilestone(ctx *context.APIContext, form api.CreateMilestoneOption) {
+	// swagger:route POST /repos/{username}/{reponame}/milestones repoCreateMilestone
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       201: Milestone
+	//       403: forbidden
+	//       422: validationError
+	//       500: error
+
 	if form.Deadline == nil {
 		defaultDeadline, _ := time.ParseInLocation("2006-01-02", "9999-12-31", time.Local)
 		form.Deadline = &defaultDeadline
@@ -65,6 +104,21 @@ func CreateMilestone(ctx *context.APIContext, form api.CreateMilestoneOption) {
 
 // EditMilestone modify a milestone for a repository
 func EditMilestone(ctx *context.APIContext, form api.EditMilestoneOption) {
+	// swagger:route PATCH /repos/{username}/{reponame}/milestones/{id} repoEditMilestone
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Milestone
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
 	milestone, err := models.GetMilestoneByRepoID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
 	if err != nil {
 		if models.IsErrMilestoneNotExist(err) {
@@ -94,9 +148,92 @@ func EditMilestone(ctx *context.APIContext, form api.EditMilestoneOption) {
 
 // DeleteMilestone delete a milestone for a repository
 func DeleteMilestone(ctx *context.APIContext) {
+	// swagger:route DELETE /repos/{username}/{reponame}/milestones/{id} repoDeleteMilestone
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       500: error
+
 	if err := models.DeleteMilestoneByRepoID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id")); err != nil {
 		ctx.Error(500, "DeleteMilestoneByRepoID", err)
 		return
 	}
 	ctx.Status(204)
 }
+
+type milestoneIssueReport struct {
+	Index       int64      `json:"number"`
+	Title       string     `json:"title"`
+	IsPull      bool       `json:"is_pull"`
+	State       string     `json:"state"`
+	Assignee    string     `json:"assignee"`
+	Labels      []string   `json:"labels"`
+	Created     time.Time  `json:"created_at"`
+	Closed      *time.Time `json:"closed_at"`
+	TimeToClose *int64     `json:"time_to_close"`
+}
+
+// GetMilestoneReport exports all issues of a milestone as JSON or, with
+// format=csv, as CSV
+func GetMilestoneReport(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/milestones/{id}/report repoGetMilestoneReport
+	//
+	//     Produces:
+	//     - application/json
+	//     - text/csv
+	//
+	//     Responses:
+	//       200: MilestoneReport
+	//       404: notFound
+	//       500: error
+
+	milestone, err := models.GetMilestoneByRepoID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrMilestoneNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "GetMilestoneByRepoID", err)
+		}
+		return
+	}
+
+	reports, err := milestone.GetIssueReport(ctx.User)
+	if err != nil {
+		ctx.Error(500, "GetIssueReport", err)
+		return
+	}
+
+	if ctx.Query("format") == "csv" {
+		var buf bytes.Buffer
+		if err = reports.WriteCSV(&buf); err != nil {
+			ctx.Error(500, "WriteCSV", err)
+			return
+		}
+		ctx.ServeContent(fmt.Sprintf("%s-milestone-%d.csv", ctx.Repo.Repository.Name, milestone.ID), bytes.NewReader(buf.Bytes()))
+		return
+	}
+
+	apiReports := make([]*milestoneIssueReport, len(reports))
+	for i, r := range reports {
+		apiReports[i] = &milestoneIssueReport{
+			Index:    r.Issue.Index,
+			Title:    r.Issue.Title,
+			IsPull:   r.Issue.IsPull,
+			State:    string(r.Issue.State()),
+			Assignee: r.AssigneeName(),
+			Labels:   r.LabelNames(),
+			Created:  r.Issue.Created,
+		}
+		if r.Issue.IsClosed {
+			closed := r.Closed
+			seconds := int64(r.TimeToClose.Seconds())
+			apiReports[i].Closed = &closed
+			apiReports[i].TimeToClose = &seconds
+		}
+	}
+	ctx.JSON(200, &apiReports)
+}
@@ -10,16 +10,30 @@ import (
 
 	"code.gitea.io/git"
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 
 	api "code.gitea.io/sdk/gitea"
 )
 
 // ListPullRequests returns a list of all PRs
 func ListPullRequests(ctx *context.APIContext, form api.ListPullRequestsOptions) {
+	// swagger:route GET /repos/{username}/{reponame}/pulls repoListPullRequests
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: PullRequestList
+	//       500: error
+
+	listOpts := utils.GetListOptions(ctx)
 	prs, maxResults, err := models.PullRequests(ctx.Repo.Repository.ID, &models.PullRequestsOptions{
-		Page:        ctx.QueryInt("page"),
+		Page:        listOpts.Page,
+		PageSize:    listOpts.PageSize,
 		State:       ctx.QueryTrim("state"),
 		SortType:    ctx.QueryTrim("sort"),
 		Labels:      ctx.QueryStrings("labels"),
@@ -44,12 +58,22 @@ func ListPullRequests(ctx *context.APIContext, form api.ListPullRequestsOptions)
 		apiPrs[i] = prs[i].APIFormat()
 	}
 
-	ctx.SetLinkHeader(int(maxResults), models.ItemsPerPage)
+	utils.SetPaginationHeaders(ctx, int(maxResults))
 	ctx.JSON(200, &apiPrs)
 }
 
 // GetPullRequest returns a single PR based on index
 func GetPullRequest(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/pulls/{index} repoGetPullRequest
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: PullRequest
+	//       404: notFound
+	//       500: error
+
 	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
 	if err != nil {
 		if models.IsErrPullRequestNotExist(err) {
@@ -65,8 +89,66 @@ func GetPullRequest(ctx *context.APIContext) {
 	ctx.JSON(200, pr.APIFormat())
 }
 
+type pullRequestConflicts struct {
+	Mergeable bool     `json:"mergeable"`
+	Checking  bool     `json:"checking"`
+	Files     []string `json:"files"`
+}
+
+// GetPullRequestConflicts returns the files conflicting with the base branch
+func GetPullRequestConflicts(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/pulls/{index}/conflicts repoGetPullRequestConflicts
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: PullRequestConflicts
+	//       404: notFound
+	//       500: error
+
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "GetPullRequestByIndex", err)
+		}
+		return
+	}
+
+	conflicts := &pullRequestConflicts{
+		Mergeable: pr.CanAutoMerge(),
+		Checking:  pr.IsChecking(),
+		Files:     []string{},
+	}
+	if !pr.HasMerged && pr.Status == models.PullRequestStatusConflict {
+		if conflicts.Files, err = pr.GetConflictedFiles(); err != nil {
+			ctx.Error(500, "GetConflictedFiles", err)
+			return
+		}
+	}
+	ctx.JSON(200, conflicts)
+}
+
 // CreatePullRequest does what it says
 func CreatePullRequest(ctx *context.APIContext, form api.CreatePullRequestOption) {
+	// swagger:route POST /repos/{username}/{reponame}/pulls repoCreatePullRequest
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       201: PullRequest
+	//       403: forbidden
+	//       404: notFound
+	//       409: error
+	//       422: validationError
+	//       500: error
+
 	var (
 		repo        = ctx.Repo.Repository
 		labelIDs    []int64
@@ -188,8 +270,25 @@ func CreatePullRequest(ctx *context.APIContext, form api.CreatePullRequestOption
 	ctx.JSON(201, pr.APIFormat())
 }
 
-// EditPullRequest does what it says
-func EditPullRequest(ctx *context.APIContext, form api.EditPullRequestOption) {
+// EditPullRequest does what it says, writers can retarget an unmerged pull
+// request to another base branch.
+func EditPullRequest(ctx *context.APIContext, form auth.EditPullRequestForm) {
+	// swagger:route PATCH /repos/{username}/{reponame}/pulls/{index} repoEditPullRequest
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       201: PullRequest
+	//       403: forbidden
+	//       404: notFound
+	//       409: error
+	//       422: validationError
+	//       500: error
+
 	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
 	if err != nil {
 		if models.IsErrPullRequestNotExist(err) {
@@ -203,7 +302,7 @@ func EditPullRequest(ctx *context.APIContext, form api.EditPullRequestOption) {
 	pr.LoadIssue()
 	issue := pr.Issue
 
-	if !issue.IsPoster(ctx.User.ID) && !ctx.Repo.IsWriter() {
+	if !issue.IsPoster(ctx.User.ID) && !ctx.Repo.CanWrite(models.UnitTypePullRequests) {
 		ctx.Status(403)
 		return
 	}
@@ -215,7 +314,7 @@ func EditPullRequest(ctx *context.APIContext, form api.EditPullRequestOption) {
 		issue.Content = form.Body
 	}
 
-	if ctx.Repo.IsWriter() && len(form.Assignee) > 0 &&
+	if ctx.Repo.CanWrite(models.UnitTypePullRequests) && len(form.Assignee) > 0 &&
 		(issue.Assignee == nil || issue.Assignee.LowerName != strings.ToLower(form.Assignee)) {
 		if len(form.Assignee) == 0 {
 			issue.AssigneeID = 0
@@ -237,7 +336,7 @@ func EditPullRequest(ctx *context.APIContext, form api.EditPullRequestOption) {
 			return
 		}
 	}
-	if ctx.Repo.IsWriter() && form.Milestone != 0 &&
+	if ctx.Repo.CanWrite(models.UnitTypePullRequests) && form.Milestone != 0 &&
 		issue.MilestoneID != form.Milestone {
 		oldMilestoneID := issue.MilestoneID
 		issue.MilestoneID = form.Milestone
@@ -247,6 +346,23 @@ func EditPullRequest(ctx *context.APIContext, form api.EditPullRequestOption) {
 		}
 	}
 
+	if ctx.Repo.CanWrite(models.UnitTypePullRequests) && len(form.Base) > 0 && form.Base != pr.BaseBranch {
+		if pr.HasMerged {
+			ctx.Error(422, "", "pull request has been merged")
+			return
+		}
+		if err = pr.ChangeBaseBranch(ctx.User, form.Base); err != nil {
+			if models.IsErrBranchNotExist(err) || models.IsErrPullRequestBaseIsHead(err) {
+				ctx.Error(422, "", err)
+			} else if models.IsErrPullRequestAlreadyExists(err) {
+				ctx.Error(409, "", err)
+			} else {
+				ctx.Error(500, "ChangeBaseBranch", err)
+			}
+			return
+		}
+	}
+
 	if err = models.UpdateIssue(issue); err != nil {
 		ctx.Error(500, "UpdateIssue", err)
 		return
@@ -276,6 +392,16 @@ func EditPullRequest(ctx *context.APIContext, form api.EditPullRequestOption) {
 //  - Returns 204 if it exists
 //    Otherwise 404
 func IsPullRequestMerged(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/pulls/{index}/merge repoPullRequestIsMerged
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       404: notFound
+	//       500: error
+
 	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
 	if err != nil {
 		if models.IsErrPullRequestNotExist(err) {
@@ -292,8 +418,25 @@ func IsPullRequestMerged(ctx *context.APIContext) {
 	ctx.Status(404)
 }
 
-// MergePullRequest merges a PR given an index
-func MergePullRequest(ctx *context.APIContext) {
+// MergePullRequest merges a PR given an index with the style of the form,
+// a merge commit by default.
+func MergePullRequest(ctx *context.APIContext, form auth.MergePullRequestForm) {
+	// swagger:route POST /repos/{username}/{reponame}/pulls/{index}/merge repoMergePullRequest
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: empty
+	//       403: forbidden
+	//       404: notFound
+	//       405: error
+	//       409: error
+	//       500: error
+
 	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
 	if err != nil {
 		if models.IsErrPullRequestNotExist(err) {
@@ -330,8 +473,34 @@ func MergePullRequest(ctx *context.APIContext) {
 		return
 	}
 
-	if err := pr.Merge(ctx.User, ctx.Repo.GitRepo); err != nil {
-		ctx.Error(500, "Merge", err)
+	missing, err := pr.GetMissingStatusContexts()
+	if err != nil {
+		ctx.Error(500, "GetMissingStatusContexts", err)
+		return
+	} else if len(missing) > 0 {
+		ctx.Status(405)
+		return
+	}
+
+	approvals, required, err := pr.GetApprovals()
+	if err != nil {
+		ctx.Error(500, "GetApprovals", err)
+		return
+	} else if approvals < required {
+		ctx.Status(405)
+		return
+	}
+
+	style := models.MergeStyle(form.Style)
+	if len(style) == 0 {
+		style = models.MergeStyleMerge
+	}
+	if err := pr.Merge(ctx.User, ctx.Repo.GitRepo, style, form.Message); err != nil {
+		if models.IsErrPullRequestMergeConflict(err) {
+			ctx.Error(409, "", err)
+		} else {
+			ctx.Error(500, "Merge", err)
+		}
 		return
 	}
 
@@ -339,6 +508,91 @@ func MergePullRequest(ctx *context.APIContext) {
 	ctx.Status(200)
 }
 
+// pullRequestCommitRange returns the repository holding the commits of a pull
+// request, and the range of the commits: from the merge base to the merge
+// commit if it has been merged, otherwise to the head branch.
+func pullRequestCommitRange(ctx *context.APIContext) (gitRepo *git.Repository, mergeBase, endCommitID string) {
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "GetPullRequestByIndex", err)
+		}
+		return nil, "", ""
+	}
+
+	if pr.HasMerged {
+		return ctx.Repo.GitRepo, pr.MergeBase, pr.MergedCommitID
+	}
+
+	if err = pr.GetHeadRepo(); err != nil || pr.HeadRepo == nil {
+		ctx.Status(404)
+		return nil, "", ""
+	}
+	gitRepo, err = git.OpenRepository(pr.HeadRepo.RepoPath())
+	if err != nil {
+		ctx.Error(500, "OpenRepository", err)
+		return nil, "", ""
+	}
+	if !gitRepo.IsBranchExist(pr.HeadBranch) {
+		ctx.Status(404)
+		return nil, "", ""
+	}
+	prInfo, err := gitRepo.GetPullRequestInfo(ctx.Repo.Repository.RepoPath(), pr.BaseBranch, pr.HeadBranch)
+	if err != nil {
+		ctx.Error(500, "GetPullRequestInfo", err)
+		return nil, "", ""
+	}
+	endCommitID, err = gitRepo.GetBranchCommitID(pr.HeadBranch)
+	if err != nil {
+		ctx.Error(500, "GetBranchCommitID", err)
+		return nil, "", ""
+	}
+	return gitRepo, prInfo.MergeBase, endCommitID
+}
+
+// ListPullRequestFiles returns the files changed by a pull request
+func ListPullRequestFiles(ctx *context.APIContext) {
+	gitRepo, mergeBase, endCommitID := pullRequestCommitRange(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	files, err := models.GetDiffRangeFileStats(gitRepo.Path, mergeBase, endCommitID)
+	if err != nil {
+		ctx.Error(500, "GetDiffRangeFileStats", err)
+		return
+	}
+	ctx.JSON(200, &files)
+}
+
+// ListPullRequestCommits returns the commits of a pull request
+func ListPullRequestCommits(ctx *context.APIContext) {
+	gitRepo, mergeBase, endCommitID := pullRequestCommitRange(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	apiCommits := make([]*api.PayloadCommit, 0)
+	if mergeBase != endCommitID {
+		endCommit, err := gitRepo.GetCommit(endCommitID)
+		if err != nil {
+			ctx.Error(500, "GetCommit", err)
+			return
+		}
+		commits, err := endCommit.CommitsBeforeUntil(mergeBase)
+		if err != nil {
+			ctx.Error(500, "CommitsBeforeUntil", err)
+			return
+		}
+		for e := commits.Front(); e != nil; e = e.Next() {
+			apiCommits = append(apiCommits, convert.ToCommit(e.Value.(*git.Commit)))
+		}
+	}
+	ctx.JSON(200, &apiCommits)
+}
+
 func parseCompareInfo(ctx *context.APIContext, form api.CreatePullRequestOption) (*models.User, *models.Repository, *git.Repository, *git.PullRequestInfo, string, string) {
 	baseRepo := ctx.Repo.Repository
 
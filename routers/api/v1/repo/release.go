@@ -11,10 +11,21 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 // GetRelease get a single release of a repository
 func GetRelease(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/releases/{id} repoGetRelease
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Release
+	//       404: notFound
+	//       500: error
+
 	id := ctx.ParamsInt64(":id")
 	release, err := models.GetReleaseByID(id)
 	if err != nil {
@@ -34,11 +45,22 @@ func GetRelease(ctx *context.APIContext) {
 
 // ListReleases list a repository's releases
 func ListReleases(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/releases repoListReleases
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: ReleaseList
+	//       500: error
+
 	releases, err := models.GetReleasesByRepoID(ctx.Repo.Repository.ID, 1, 2147483647)
 	if err != nil {
 		ctx.Error(500, "GetReleasesByRepoID", err)
 		return
 	}
+	start, end := utils.Paginate(ctx, len(releases))
+	releases = releases[start:end]
 	rels := make([]*api.Release, len(releases))
 	access, err := models.AccessLevel(ctx.User.ID, ctx.Repo.Repository)
 	if err != nil {
@@ -61,6 +83,22 @@ func ListReleases(ctx *context.APIContext) {
 
 // CreateRelease create a release
 func CreateRelease(ctx *context.APIContext, form api.CreateReleaseOption) {
+	// swagger:route POST /repos/{username}/{reponame}/releases repoCreateRelease
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       201: Release
+	//       403: forbidden
+	//       404: notFound
+	//       409: error
+	//       422: validationError
+	//       500: error
+
 	if ctx.Repo.AccessMode < models.AccessModeWrite {
 		ctx.Status(403)
 		return
@@ -112,6 +150,21 @@ func CreateRelease(ctx *context.APIContext, form api.CreateReleaseOption) {
 
 // EditRelease edit a release
 func EditRelease(ctx *context.APIContext, form api.EditReleaseOption) {
+	// swagger:route PATCH /repos/{username}/{reponame}/releases/{id} repoEditRelease
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: Release
+	//       403: forbidden
+	//       404: notFound
+	//       422: validationError
+	//       500: error
+
 	if ctx.Repo.AccessMode < models.AccessModeWrite {
 		ctx.Status(403)
 		return
@@ -164,6 +217,17 @@ func EditRelease(ctx *context.APIContext, form api.EditReleaseOption) {
 
 // DeleteRelease delete a release from a repository
 func DeleteRelease(ctx *context.APIContext) {
+	// swagger:route DELETE /repos/{username}/{reponame}/releases/{id} repoDeleteRelease
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
 	if ctx.Repo.AccessMode < models.AccessModeWrite {
 		ctx.Status(403)
 		return
@@ -6,6 +6,7 @@ package repo
 
 import (
 	"strings"
+	"time"
 
 	api "code.gitea.io/sdk/gitea"
 
@@ -14,7 +15,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
-	"code.gitea.io/gitea/routers/api/v1/convert"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 // Search repositories via options
@@ -28,10 +29,12 @@ func Search(ctx *context.APIContext) {
 	//       200: SearchResults
 	//       500: SearchError
 
+	listOpts := utils.GetListOptions(ctx)
 	opts := &models.SearchRepoOptions{
 		Keyword:  strings.Trim(ctx.Query("q"), " "),
 		OwnerID:  ctx.QueryInt64("uid"),
-		PageSize: convert.ToCorrectPageSize(ctx.QueryInt("limit")),
+		Page:     listOpts.Page,
+		PageSize: listOpts.PageSize,
 	}
 	if ctx.User != nil && ctx.User.ID == opts.OwnerID {
 		opts.Searcher = ctx.User
@@ -90,7 +93,7 @@ func Search(ctx *context.APIContext) {
 		results[i] = repo.APIFormat(accessMode)
 	}
 
-	ctx.SetLinkHeader(int(count), setting.API.MaxResponseItems)
+	ctx.SetLinkHeader(int(count), opts.PageSize)
 	ctx.JSON(200, api.SearchResults{
 		OK:   true,
 		Data: results,
@@ -130,6 +133,19 @@ func CreateUserRepo(ctx *context.APIContext, owner *models.User, opt api.CreateR
 // Create one repository of mine
 // see https://github.com/gogits/go-gogs-client/wiki/Repositories#create
 func Create(ctx *context.APIContext, opt api.CreateRepoOption) {
+	// swagger:route POST /user/repos createCurrentUserRepo
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       201: Repository
+	//       422: validationError
+	//       500: error
+
 	// Shouldn't reach this condition, but just in case.
 	if ctx.User.IsOrganization() {
 		ctx.Error(422, "", "not allowed creating repository for organization")
@@ -173,7 +189,7 @@ func CreateOrgRepo(ctx *context.APIContext, opt api.CreateRepoOption) {
 
 // Migrate migrate remote git repository to gitea
 func Migrate(ctx *context.APIContext, form auth.MigrateRepoForm) {
-	// swagger:route POST /repos/migrate
+	// swagger:route POST /repos/migrate repoMigrate
 	//
 	//     Consumes:
 	//     - application/json
@@ -258,7 +274,7 @@ func Migrate(ctx *context.APIContext, form auth.MigrateRepoForm) {
 
 // Get one repository
 func Get(ctx *context.APIContext) {
-	// swagger:route GET /repos/{username}/{reponame}
+	// swagger:route GET /repos/{username}/{reponame} repoGet
 	//
 	//     Produces:
 	//     - application/json
@@ -273,12 +289,12 @@ func Get(ctx *context.APIContext) {
 		ctx.Error(500, "GetRepository", err)
 		return
 	}
-	ctx.JSON(200, repo.APIFormat(access))
+	ctx.JSONWithETag(200, repo.APIFormat(access))
 }
 
 // GetByID returns a single Repository
 func GetByID(ctx *context.APIContext) {
-	// swagger:route GET /repositories/{id}
+	// swagger:route GET /repositories/{id} repoGetByID
 	//
 	//     Produces:
 	//     - application/json
@@ -295,6 +311,9 @@ func GetByID(ctx *context.APIContext) {
 			ctx.Error(500, "GetRepositoryByID", err)
 		}
 		return
+	} else if repo.IsDeleted() {
+		ctx.Status(404)
+		return
 	}
 
 	access, err := models.AccessLevel(ctx.User.ID, repo)
@@ -302,12 +321,12 @@ func GetByID(ctx *context.APIContext) {
 		ctx.Error(500, "GetRepositoryByID", err)
 		return
 	}
-	ctx.JSON(200, repo.APIFormat(access))
+	ctx.JSONWithETag(200, repo.APIFormat(access))
 }
 
 // Delete one repository
 func Delete(ctx *context.APIContext) {
-	// swagger:route DELETE /repos/{username}/{reponame}
+	// swagger:route DELETE /repos/{username}/{reponame} repoDelete
 	//
 	//     Produces:
 	//     - application/json
@@ -329,8 +348,8 @@ func Delete(ctx *context.APIContext) {
 		return
 	}
 
-	if err := models.DeleteRepository(owner.ID, repo.ID); err != nil {
-		ctx.Error(500, "DeleteRepository", err)
+	if err := models.SoftDeleteRepository(owner.ID, repo.ID); err != nil {
+		ctx.Error(500, "SoftDeleteRepository", err)
 		return
 	}
 
@@ -351,10 +370,70 @@ func MirrorSync(ctx *context.APIContext) {
 
 	repo := ctx.Repo.Repository
 
-	if !ctx.Repo.IsWriter() {
+	if !ctx.Repo.CanWrite(models.UnitTypeCode) {
 		ctx.Error(403, "MirrorSync", "Must have write access")
+		return
+	} else if !repo.IsMirror {
+		ctx.Status(404)
+		return
 	}
 
 	go models.MirrorQueue.Add(repo.ID)
 	ctx.Status(200)
 }
+
+type mirrorSyncStatus struct {
+	Status           string     `json:"status"`
+	QueuePosition    int        `json:"queue_position"`
+	LastSync         *time.Time `json:"last_sync"`
+	LastSyncSuccess  bool       `json:"last_sync_success"`
+	LastSyncError    string     `json:"last_sync_error,omitempty"`
+	LastSyncDuration float64    `json:"last_sync_duration"`
+	SyncFailures     int        `json:"sync_failures"`
+	LastUpdated      time.Time  `json:"last_updated"`
+	NextUpdate       time.Time  `json:"next_update"`
+}
+
+// GetMirrorSyncStatus returns the synchronization state and last sync result
+// of a mirrored repository
+func GetMirrorSyncStatus(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/mirror-sync repoGetMirrorSyncStatus
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: mirrorSyncStatus
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
+	if !ctx.Repo.CanWrite(models.UnitTypeCode) {
+		ctx.Error(403, "GetMirrorSyncStatus", "Must have write access")
+		return
+	}
+
+	mirror, err := models.GetMirrorByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		if err == models.ErrMirrorNotExist {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "GetMirrorByRepoID", err)
+		}
+		return
+	}
+
+	status := &mirrorSyncStatus{
+		LastSyncError:    mirror.LastSyncError,
+		LastSyncDuration: mirror.LastSyncDuration.Seconds(),
+		SyncFailures:     mirror.SyncFailures,
+		LastUpdated:      mirror.Updated,
+		NextUpdate:       mirror.NextUpdate,
+	}
+	status.Status, status.QueuePosition = mirror.SyncState()
+	if mirror.LastSyncUnix > 0 {
+		status.LastSync = &mirror.LastSync
+		status.LastSyncSuccess = len(mirror.LastSyncError) == 0
+	}
+	ctx.JSON(200, status)
+}
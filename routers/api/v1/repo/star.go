@@ -8,15 +8,27 @@ import (
 	api "code.gitea.io/sdk/gitea"
 
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 // ListStargazers list a repository's stargazers
 func ListStargazers(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/stargazers repoListStargazers
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: UserList
+	//       500: error
+
 	stargazers, err := ctx.Repo.Repository.GetStargazers(-1)
 	if err != nil {
 		ctx.Error(500, "GetStargazers", err)
 		return
 	}
+	start, end := utils.Paginate(ctx, len(stargazers))
+	stargazers = stargazers[start:end]
 	users := make([]*api.User, len(stargazers))
 	for i, stargazer := range stargazers {
 		users[i] = stargazer.APIFormat()
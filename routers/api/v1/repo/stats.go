@@ -0,0 +1,95 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	api "code.gitea.io/sdk/gitea"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+)
+
+type contributorWeek struct {
+	Week      int64 `json:"w"`
+	Additions int64 `json:"a"`
+	Deletions int64 `json:"d"`
+	Commits   int64 `json:"c"`
+}
+
+type contributorStats struct {
+	Author *api.User          `json:"author"`
+	Name   string             `json:"name"`
+	Email  string             `json:"email"`
+	Total  int64              `json:"total"`
+	Weeks  []*contributorWeek `json:"weeks"`
+}
+
+// GetContributorStats returns commit activity per contributor per week
+func GetContributorStats(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/stats/contributors repoGetContributorStats
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: ContributorStatsList
+	//       500: error
+
+	stats, err := ctx.Repo.Repository.GetContributorStats()
+	if err != nil {
+		ctx.Error(500, "GetContributorStats", err)
+		return
+	}
+
+	apiStats := make([]*contributorStats, len(stats))
+	for i, s := range stats {
+		apiStats[i] = &contributorStats{
+			Name:  s.Name,
+			Email: s.Email,
+			Total: s.Total,
+			Weeks: make([]*contributorWeek, len(s.Weeks)),
+		}
+		if u, err := models.GetUserByEmail(s.Email); err == nil {
+			apiStats[i].Author = u.APIFormat()
+		} else if !models.IsErrUserNotExist(err) {
+			ctx.Error(500, "GetUserByEmail", err)
+			return
+		}
+		for j, w := range s.Weeks {
+			apiStats[i].Weeks[j] = &contributorWeek{
+				Week:      w.Week,
+				Additions: w.Additions,
+				Deletions: w.Deletions,
+				Commits:   w.Commits,
+			}
+		}
+	}
+	ctx.JSON(200, &apiStats)
+}
+
+// GetLanguageStats returns the number of bytes of every language in the
+// default branch of a repository.
+func GetLanguageStats(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/languages repoGetLanguages
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: LanguageStats
+	//       500: error
+
+	stats, err := ctx.Repo.Repository.GetLanguageStats()
+	if err != nil {
+		ctx.Error(500, "GetLanguageStats", err)
+		return
+	}
+
+	langs := make(map[string]int64, len(stats))
+	for _, stat := range stats {
+		langs[stat.Language] = stat.Size
+	}
+	ctx.JSON(200, langs)
+}
@@ -14,6 +14,21 @@ import (
 
 // NewCommitStatus creates a new CommitStatus
 func NewCommitStatus(ctx *context.APIContext, form api.CreateStatusOption) {
+	// swagger:route POST /repos/{username}/{reponame}/statuses/{sha} repoCreateStatus
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       201: Status
+	//       400: error
+	//       403: forbidden
+	//       422: validationError
+	//       500: error
+
 	sha := ctx.Params("sha")
 	if len(sha) == 0 {
 		sha = ctx.Params("ref")
@@ -43,6 +58,26 @@ func NewCommitStatus(ctx *context.APIContext, form api.CreateStatusOption) {
 
 // GetCommitStatuses returns all statuses for any given commit hash
 func GetCommitStatuses(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/statuses/{sha} repoListStatuses
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: StatusList
+	//       400: error
+	//       500: error
+
+	// swagger:route GET /repos/{username}/{reponame}/commits/{ref}/statuses repoListStatusesByRef
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: StatusList
+	//       400: error
+	//       500: error
+
 	sha := ctx.Params("sha")
 	if len(sha) == 0 {
 		sha = ctx.Params("ref")
@@ -80,6 +115,16 @@ type combinedCommitStatus struct {
 
 // GetCombinedCommitStatus returns the combined status for any given commit hash
 func GetCombinedCommitStatus(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/commits/{ref}/status repoGetCombinedStatusByRef
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: CombinedStatus
+	//       400: error
+	//       500: error
+
 	sha := ctx.Params("sha")
 	if len(sha) == 0 {
 		sha = ctx.Params("ref")
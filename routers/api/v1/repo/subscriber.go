@@ -8,15 +8,27 @@ import (
 	api "code.gitea.io/sdk/gitea"
 
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 // ListSubscribers list a repo's subscribers (i.e. watchers)
 func ListSubscribers(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/subscribers repoListSubscribers
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: UserList
+	//       500: error
+
 	subscribers, err := ctx.Repo.Repository.GetWatchers(0)
 	if err != nil {
 		ctx.Error(500, "GetWatchers", err)
 		return
 	}
+	start, end := utils.Paginate(ctx, len(subscribers))
+	subscribers = subscribers[start:end]
 	users := make([]*api.User, len(subscribers))
 	for i, subscriber := range subscribers {
 		users[i] = subscriber.APIFormat()
@@ -0,0 +1,552 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	api "code.gitea.io/sdk/gitea"
+	editorconfig "gopkg.in/editorconfig/editorconfig-core-go.v1"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+)
+
+// The declarations below are only read by the swagger generator, they
+// describe the parameters and responses of the routes of this package.
+
+// swagger:parameters repoGet repoDelete repoUpdateAvatar repoDeleteAvatar repoListHooks repoCreateHook repoGetHook repoEditHook repoDeleteHook repoHookSignature repoListCollaborators repoCheckCollaborator repoAddCollaborator repoDeleteCollaborator repoGetRawFile repoGetReadme repoCompare repoGetContents repoCreateContents repoUpdateContents repoDeleteContents repoGetArchive listForks createFork repoListBranches repoGetBranch repoRenameBranch repoListKeys repoCreateKey repoGetKey repoDeleteKey issueListIssues issueCreateIssue issueListPinned issueListRepoComments issueEditRepoComment issueGetIssue issueEditIssue issueListComments issueCreateComment issueEditComment issueDeleteComment issueListTimeline issueGetLabels issueAddLabels issueReplaceLabels issueClearLabels issueRemoveLabel issuePin issueUnpin repoListLabels repoCreateLabel repoGetLabel repoEditLabel repoDeleteLabel repoListMilestones repoCreateMilestone repoGetMilestone repoEditMilestone repoDeleteMilestone repoGetMilestoneReport repoListStargazers repoListSubscribers repoListReleases repoCreateRelease repoGetRelease repoEditRelease repoDeleteRelease repoGetMirrorSyncStatus repoMirrorSync repoGetEditorConfig repoListPullRequests repoCreatePullRequest repoGetPullRequest repoEditPullRequest repoPullRequestIsMerged repoMergePullRequest repoGetPullRequestConflicts repoListStatuses repoCreateStatus repoListCommits repoGetCommitGraph repoListStatusesByRef repoGetCombinedStatusByRef repoGetContributorStats repoGetLanguages repoListWikiPages repoCreateWikiPage repoGetWikiPage repoEditWikiPage repoDeleteWikiPage repoSearchWiki
+type swaggerRepoParams struct {
+	// owner of the repository
+	//
+	// in: path
+	// required: true
+	Username string `json:"username"`
+	// name of the repository
+	//
+	// in: path
+	// required: true
+	Reponame string `json:"reponame"`
+}
+
+// swagger:parameters repoGetHook repoEditHook repoDeleteHook repoHookSignature repoGetKey repoDeleteKey issueEditRepoComment issueEditComment issueDeleteComment issueRemoveLabel repoGetLabel repoEditLabel repoDeleteLabel repoGetMilestone repoEditMilestone repoDeleteMilestone repoGetMilestoneReport repoGetRelease repoEditRelease repoDeleteRelease
+type swaggerIDParams struct {
+	// in: path
+	// required: true
+	ID int64 `json:"id"`
+}
+
+// swagger:parameters issueGetIssue issueEditIssue issueListComments issueCreateComment issueEditComment issueDeleteComment issueListTimeline issueGetLabels issueAddLabels issueReplaceLabels issueClearLabels issueRemoveLabel issuePin issueUnpin repoGetPullRequest repoEditPullRequest repoPullRequestIsMerged repoMergePullRequest repoGetPullRequestConflicts
+type swaggerIndexParams struct {
+	// number of the issue or pull request
+	//
+	// in: path
+	// required: true
+	Index int64 `json:"index"`
+}
+
+// swagger:parameters repoCheckCollaborator repoAddCollaborator repoDeleteCollaborator
+type swaggerCollaboratorParams struct {
+	// in: path
+	// required: true
+	Collaborator string `json:"collaborator"`
+}
+
+// swagger:parameters repoGetRawFile repoGetContents repoCreateContents repoUpdateContents repoDeleteContents
+type swaggerFilepathParams struct {
+	// in: path
+	// required: true
+	Filepath string `json:"filepath"`
+}
+
+// swagger:parameters repoCompare
+type swaggerCompareParams struct {
+	// "<base>...[<owner>[/<repo>]:]<head>"
+	//
+	// in: path
+	// required: true
+	Basehead string `json:"basehead"`
+}
+
+// swagger:parameters repoGetArchive
+type swaggerArchiveParams struct {
+	// name of a branch, tag or commit followed by .zip or .tar.gz
+	//
+	// in: path
+	// required: true
+	Archive string `json:"archive"`
+}
+
+// swagger:parameters repoGetBranch repoRenameBranch
+type swaggerBranchParams struct {
+	// in: path
+	// required: true
+	Branchname string `json:"branchname"`
+}
+
+// swagger:parameters repoGetEditorConfig
+type swaggerEditorConfigParams struct {
+	// in: path
+	// required: true
+	Filename string `json:"filename"`
+}
+
+// swagger:parameters repoListStatuses repoCreateStatus
+type swaggerSHAParams struct {
+	// in: path
+	// required: true
+	SHA string `json:"sha"`
+}
+
+// swagger:parameters repoListStatusesByRef repoGetCombinedStatusByRef
+type swaggerRefParams struct {
+	// name of a branch, tag or commit
+	//
+	// in: path
+	// required: true
+	Ref string `json:"ref"`
+}
+
+// swagger:parameters repoGetWikiPage repoEditWikiPage repoDeleteWikiPage
+type swaggerWikiPageParams struct {
+	// in: path
+	// required: true
+	Page string `json:"page"`
+}
+
+// swagger:parameters repoCreateHook orgCreateHook
+type swaggerCreateHookParams struct {
+	// in: body
+	Body api.CreateHookOption
+}
+
+// swagger:parameters repoEditHook orgEditHook
+type swaggerEditHookParams struct {
+	// in: body
+	Body api.EditHookOption
+}
+
+// swagger:parameters repoAddCollaborator
+type swaggerAddCollaboratorParams struct {
+	// in: body
+	Body api.AddCollaboratorOption
+}
+
+// swagger:parameters repoCreateContents repoUpdateContents repoDeleteContents
+type swaggerContentsParams struct {
+	// in: body
+	Body auth.RepoContentsForm
+}
+
+// swagger:parameters createFork
+type swaggerCreateForkParams struct {
+	// in: body
+	Body api.CreateForkOption
+}
+
+// swagger:parameters repoUpdateAvatar
+type swaggerUpdateAvatarParams struct {
+	// in: body
+	Body UpdateRepoAvatarOption
+}
+
+// swagger:parameters repoRenameBranch
+type swaggerRenameBranchParams struct {
+	// in: body
+	Body RenameBranchOption
+}
+
+// swagger:parameters repoCreateKey
+type swaggerCreateKeyParams struct {
+	// in: body
+	Body api.CreateKeyOption
+}
+
+// swagger:parameters repoMigrate
+type swaggerMigrateParams struct {
+	// in: body
+	Body auth.MigrateRepoForm
+}
+
+// swagger:parameters createCurrentUserRepo
+type swaggerCreateRepoParams struct {
+	// in: body
+	Body api.CreateRepoOption
+}
+
+// swagger:parameters issueCreateIssue
+type swaggerCreateIssueParams struct {
+	// in: body
+	Body CreateIssueOption
+}
+
+// swagger:parameters issueEditIssue
+type swaggerEditIssueParams struct {
+	// in: body
+	Body auth.EditIssueForm
+}
+
+// swagger:parameters issueCreateComment
+type swaggerCreateCommentParams struct {
+	// in: body
+	Body api.CreateIssueCommentOption
+}
+
+// swagger:parameters issueEditRepoComment issueEditComment
+type swaggerEditCommentParams struct {
+	// in: body
+	Body api.EditIssueCommentOption
+}
+
+// swagger:parameters issueAddLabels issueReplaceLabels
+type swaggerIssueLabelsParams struct {
+	// in: body
+	Body api.IssueLabelsOption
+}
+
+// swagger:parameters repoCreateLabel
+type swaggerCreateLabelParams struct {
+	// in: body
+	Body auth.CreateRepoLabelForm
+}
+
+// swagger:parameters repoEditLabel
+type swaggerEditLabelParams struct {
+	// in: body
+	Body auth.EditRepoLabelForm
+}
+
+// swagger:parameters repoCreateMilestone
+type swaggerCreateMilestoneParams struct {
+	// in: body
+	Body api.CreateMilestoneOption
+}
+
+// swagger:parameters repoEditMilestone
+type swaggerEditMilestoneParams struct {
+	// in: body
+	Body api.EditMilestoneOption
+}
+
+// swagger:parameters repoCreateRelease
+type swaggerCreateReleaseParams struct {
+	// in: body
+	Body api.CreateReleaseOption
+}
+
+// swagger:parameters repoEditRelease
+type swaggerEditReleaseParams struct {
+	// in: body
+	Body api.EditReleaseOption
+}
+
+// swagger:parameters repoCreatePullRequest
+type swaggerCreatePullRequestParams struct {
+	// in: body
+	Body api.CreatePullRequestOption
+}
+
+// swagger:parameters repoEditPullRequest
+type swaggerEditPullRequestParams struct {
+	// in: body
+	Body auth.EditPullRequestForm
+}
+
+// swagger:parameters repoMergePullRequest
+type swaggerMergePullRequestParams struct {
+	// in: body
+	Body auth.MergePullRequestForm
+}
+
+// swagger:parameters repoCreateStatus
+type swaggerCreateStatusParams struct {
+	// in: body
+	Body api.CreateStatusOption
+}
+
+// swagger:parameters repoCreateWikiPage repoEditWikiPage
+type swaggerWikiPageFormParams struct {
+	// in: body
+	Body auth.NewWikiForm
+}
+
+// Hook represents a webhook
+// swagger:response Hook
+type swaggerHook struct {
+	// in: body
+	Body api.Hook
+}
+
+// HookList represents a list of webhooks
+// swagger:response HookList
+type swaggerHookList struct {
+	// in: body
+	Body []*api.Hook
+}
+
+// File is the raw content of a file
+// swagger:response file
+type swaggerFile struct{}
+
+// ContentsEntry represents a file, directory, symlink or submodule of a
+// repository
+// swagger:response ContentsEntry
+type swaggerContentsEntry struct {
+	// in: body
+	Body contentsEntry
+}
+
+// ContentsEntryList represents the entries of a directory, or the entry of
+// the path if it is not a directory
+// swagger:response ContentsEntryList
+type swaggerContentsEntryList struct {
+	// in: body
+	Body []*contentsEntry
+}
+
+// ContentsResponse represents a changed file and the commit of the change
+// swagger:response ContentsResponse
+type swaggerContentsResponse struct {
+	// in: body
+	Body contentsResponse
+}
+
+// Comparison represents the commits and changed files between two revisions
+// swagger:response Comparison
+type swaggerComparison struct {
+	// in: body
+	Body comparison
+}
+
+// EditorConfigDefinition represents the editorconfig definition of a file
+// swagger:response EditorConfigDefinition
+type swaggerEditorConfigDefinition struct {
+	// in: body
+	Body editorconfig.Definition
+}
+
+// Branch represents a branch
+// swagger:response Branch
+type swaggerBranch struct {
+	// in: body
+	Body api.Branch
+}
+
+// BranchList represents a list of branches
+// swagger:response BranchList
+type swaggerBranchList struct {
+	// in: body
+	Body []*api.Branch
+}
+
+// DeployKey represents a deploy key
+// swagger:response DeployKey
+type swaggerDeployKey struct {
+	// in: body
+	Body api.DeployKey
+}
+
+// DeployKeyList represents a list of deploy keys
+// swagger:response DeployKeyList
+type swaggerDeployKeyList struct {
+	// in: body
+	Body []*api.DeployKey
+}
+
+// Issue represents an issue
+// swagger:response Issue
+type swaggerIssue struct {
+	// in: body
+	Body issueInfo
+}
+
+// IssueList represents a list of issues
+// swagger:response IssueList
+type swaggerIssueList struct {
+	// in: body
+	Body []*issueInfo
+}
+
+// PinnedIssueList represents the pinned issues of a repository in pin order
+// swagger:response PinnedIssueList
+type swaggerPinnedIssueList struct {
+	// in: body
+	Body []*models.APIIssue
+}
+
+// Comment represents a comment of an issue
+// swagger:response Comment
+type swaggerComment struct {
+	// in: body
+	Body api.Comment
+}
+
+// CommentList represents a list of comments
+// swagger:response CommentList
+type swaggerCommentList struct {
+	// in: body
+	Body []*api.Comment
+}
+
+// TimelineEventList represents the timeline events of an issue
+// swagger:response TimelineEventList
+type swaggerTimelineEventList struct {
+	// in: body
+	Body []*TimelineEvent
+}
+
+// IssueLabelList represents the labels of an issue
+// swagger:response IssueLabelList
+type swaggerIssueLabelList struct {
+	// in: body
+	Body []*api.Label
+}
+
+// Label represents a label of a repository
+// swagger:response Label
+type swaggerLabel struct {
+	// in: body
+	Body labelInfo
+}
+
+// LabelList represents a list of labels of a repository
+// swagger:response LabelList
+type swaggerLabelList struct {
+	// in: body
+	Body []*labelInfo
+}
+
+// Milestone represents a milestone
+// swagger:response Milestone
+type swaggerMilestone struct {
+	// in: body
+	Body api.Milestone
+}
+
+// MilestoneList represents a list of milestones
+// swagger:response MilestoneList
+type swaggerMilestoneList struct {
+	// in: body
+	Body []*api.Milestone
+}
+
+// MilestoneReport represents the issues of a milestone, or a CSV file of
+// them with format=csv
+// swagger:response MilestoneReport
+type swaggerMilestoneReport struct {
+	// in: body
+	Body []*milestoneIssueReport
+}
+
+// Release represents a release
+// swagger:response Release
+type swaggerRelease struct {
+	// in: body
+	Body api.Release
+}
+
+// ReleaseList represents a list of releases
+// swagger:response ReleaseList
+type swaggerReleaseList struct {
+	// in: body
+	Body []*api.Release
+}
+
+// MirrorSyncStatus represents the synchronization state of a mirror
+// swagger:response mirrorSyncStatus
+type swaggerMirrorSyncStatus struct {
+	// in: body
+	Body mirrorSyncStatus
+}
+
+// PullRequest represents a pull request
+// swagger:response PullRequest
+type swaggerPullRequest struct {
+	// in: body
+	Body api.PullRequest
+}
+
+// PullRequestList represents a list of pull requests
+// swagger:response PullRequestList
+type swaggerPullRequestList struct {
+	// in: body
+	Body []*api.PullRequest
+}
+
+// PullRequestConflicts represents the files of a pull request conflicting
+// with the base branch
+// swagger:response PullRequestConflicts
+type swaggerPullRequestConflicts struct {
+	// in: body
+	Body pullRequestConflicts
+}
+
+// Status represents a commit status
+// swagger:response Status
+type swaggerStatus struct {
+	// in: body
+	Body api.Status
+}
+
+// StatusList represents a list of commit statuses
+// swagger:response StatusList
+type swaggerStatusList struct {
+	// in: body
+	Body []*api.Status
+}
+
+// CombinedStatus represents the combined status of a commit
+// swagger:response CombinedStatus
+type swaggerCombinedStatus struct {
+	// in: body
+	Body combinedCommitStatus
+}
+
+// CommitList represents a list of commits
+// swagger:response CommitList
+type swaggerCommitList struct {
+	// in: body
+	Body []*pathCommit
+}
+
+// CommitGraph represents a page of the commit graph
+// swagger:response CommitGraph
+type swaggerCommitGraph struct {
+	// in: body
+	Body commitGraph
+}
+
+// ContributorStatsList represents the weekly commit activity of contributors
+// swagger:response ContributorStatsList
+type swaggerContributorStatsList struct {
+	// in: body
+	Body []*contributorStats
+}
+
+// LanguageStats maps languages to their size in bytes
+// swagger:response LanguageStats
+type swaggerLanguageStats struct {
+	// in: body
+	Body map[string]int64
+}
+
+// WikiPage represents a wiki page
+// swagger:response WikiPage
+type swaggerWikiPage struct {
+	// in: body
+	Body wikiPage
+}
+
+// WikiPageList represents a list of wiki pages
+// swagger:response WikiPageList
+type swaggerWikiPageList struct {
+	// in: body
+	Body []*wikiPage
+}
+
+// WikiSearchResultList represents the wiki pages matching a search
+// swagger:response WikiSearchResultList
+type swaggerWikiSearchResultList struct {
+	// in: body
+	Body []*wikiSearchResult
+}
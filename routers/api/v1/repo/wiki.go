@@ -0,0 +1,238 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	api "code.gitea.io/sdk/gitea"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+)
+
+type wikiPage struct {
+	Title      string             `json:"title"`
+	URL        string             `json:"url"`
+	HTMLURL    string             `json:"html_url"`
+	Content    string             `json:"content,omitempty"`
+	LastCommit *api.PayloadCommit `json:"last_commit"`
+}
+
+func toWikiPage(repo *models.Repository, page *models.WikiPage) *wikiPage {
+	return &wikiPage{
+		Title:      page.Name,
+		URL:        page.URL,
+		HTMLURL:    repo.HTMLURL() + "/wiki/" + page.URL,
+		Content:    page.Content,
+		LastCommit: convert.ToCommit(page.LastCommit),
+	}
+}
+
+// ListWikiPages returns all pages of the repository wiki
+func ListWikiPages(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/wiki/pages repoListWikiPages
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: WikiPageList
+	//       500: error
+
+	pages, err := ctx.Repo.Repository.GetWikiPages()
+	if err != nil {
+		ctx.Error(500, "GetWikiPages", err)
+		return
+	}
+
+	apiPages := make([]*wikiPage, len(pages))
+	for i := range pages {
+		apiPages[i] = toWikiPage(ctx.Repo.Repository, pages[i])
+	}
+	ctx.JSON(200, &apiPages)
+}
+
+// getWikiPage returns the page requested by the URL, it writes the response
+// if the page does not exist.
+func getWikiPage(ctx *context.APIContext) *models.WikiPage {
+	wikiPath := models.ToWikiPageURL(models.ToWikiPageName(ctx.Params(":page")))
+	page, err := ctx.Repo.Repository.GetWikiPage(wikiPath)
+	if err != nil {
+		if models.IsErrWikiPageNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "GetWikiPage", err)
+		}
+		return nil
+	}
+	return page
+}
+
+// GetWikiPage returns a single page of the repository wiki with its content
+func GetWikiPage(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/wiki/pages/{page} repoGetWikiPage
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: WikiPage
+	//       404: notFound
+	//       500: error
+
+	page := getWikiPage(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(200, toWikiPage(ctx.Repo.Repository, page))
+}
+
+// CreateWikiPage creates a new page in the repository wiki
+func CreateWikiPage(ctx *context.APIContext, form auth.NewWikiForm) {
+	// swagger:route POST /repos/{username}/{reponame}/wiki/pages repoCreateWikiPage
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       201: WikiPage
+	//       403: forbidden
+	//       409: error
+	//       422: validationError
+	//       500: error
+
+	wikiPath := models.ToWikiPageURL(form.Title)
+	if err := ctx.Repo.Repository.AddWikiPage(ctx.User, wikiPath, form.Content, form.Message); err != nil {
+		if models.IsErrWikiAlreadyExist(err) {
+			ctx.Error(409, "", err)
+		} else {
+			ctx.Error(500, "AddWikiPage", err)
+		}
+		return
+	}
+
+	page, err := ctx.Repo.Repository.GetWikiPage(wikiPath)
+	if err != nil {
+		ctx.Error(500, "GetWikiPage", err)
+		return
+	}
+	ctx.JSON(201, toWikiPage(ctx.Repo.Repository, page))
+}
+
+// EditWikiPage changes the title or content of a page in the repository wiki
+func EditWikiPage(ctx *context.APIContext, form auth.NewWikiForm) {
+	// swagger:route PATCH /repos/{username}/{reponame}/wiki/pages/{page} repoEditWikiPage
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: WikiPage
+	//       403: forbidden
+	//       404: notFound
+	//       409: error
+	//       422: validationError
+	//       500: error
+
+	page := getWikiPage(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	newWikiPath := models.ToWikiPageURL(form.Title)
+	if err := ctx.Repo.Repository.EditWikiPage(ctx.User, page.URL, newWikiPath, form.Content, form.Message); err != nil {
+		if models.IsErrWikiAlreadyExist(err) {
+			ctx.Error(409, "", err)
+		} else {
+			ctx.Error(500, "EditWikiPage", err)
+		}
+		return
+	}
+
+	page, err := ctx.Repo.Repository.GetWikiPage(newWikiPath)
+	if err != nil {
+		ctx.Error(500, "GetWikiPage", err)
+		return
+	}
+	ctx.JSON(200, toWikiPage(ctx.Repo.Repository, page))
+}
+
+// DeleteWikiPage deletes a page from the repository wiki
+func DeleteWikiPage(ctx *context.APIContext) {
+	// swagger:route DELETE /repos/{username}/{reponame}/wiki/pages/{page} repoDeleteWikiPage
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       403: forbidden
+	//       404: notFound
+	//       500: error
+
+	page := getWikiPage(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := ctx.Repo.Repository.DeleteWikiPage(ctx.User, page.URL); err != nil {
+		ctx.Error(500, "DeleteWikiPage", err)
+		return
+	}
+	ctx.Status(204)
+}
+
+type wikiSearchMatch struct {
+	Line    int    `json:"line"`
+	Content string `json:"content"`
+}
+
+type wikiSearchResult struct {
+	Title   string             `json:"title"`
+	HTMLURL string             `json:"html_url"`
+	Matches []*wikiSearchMatch `json:"matches"`
+}
+
+// SearchWiki returns the wiki pages whose title or content contains the keyword
+func SearchWiki(ctx *context.APIContext) {
+	// swagger:route GET /repos/{username}/{reponame}/wiki/search repoSearchWiki
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: WikiSearchResultList
+	//       500: error
+
+	results, err := ctx.Repo.Repository.SearchWiki(ctx.Query("q"))
+	if err != nil {
+		ctx.Error(500, "SearchWiki", err)
+		return
+	}
+
+	wikiLink := ctx.Repo.Repository.HTMLURL() + "/wiki/"
+	apiResults := make([]*wikiSearchResult, len(results))
+	for i, r := range results {
+		apiResults[i] = &wikiSearchResult{
+			Title:   r.Name,
+			HTMLURL: wikiLink + r.URL,
+			Matches: make([]*wikiSearchMatch, len(r.Matches)),
+		}
+		for j, m := range r.Matches {
+			apiResults[i].Matches[j] = &wikiSearchMatch{
+				Line:    m.Line,
+				Content: m.Content,
+			}
+		}
+	}
+	ctx.JSON(200, &apiResults)
+}
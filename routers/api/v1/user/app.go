@@ -9,6 +9,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 // ListAccessTokens list all the access tokens
@@ -28,6 +29,9 @@ func ListAccessTokens(ctx *context.APIContext) {
 		return
 	}
 
+	start, end := utils.Paginate(ctx, len(tokens))
+	tokens = tokens[start:end]
+
 	apiTokens := make([]*api.AccessToken, len(tokens))
 	for i := range tokens {
 		apiTokens[i] = &api.AccessToken{
@@ -40,7 +44,7 @@ func ListAccessTokens(ctx *context.APIContext) {
 
 // CreateAccessToken create access tokens
 func CreateAccessToken(ctx *context.APIContext, form api.CreateAccessTokenOption) {
-	// swagger:route POST /users/{username} /tokens userCreateToken
+	// swagger:route POST /users/{username}/tokens userCreateToken
 	//
 	//     Consumes:
 	//     - application/json
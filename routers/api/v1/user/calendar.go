@@ -0,0 +1,82 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/ical"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// GetMyCalendar returns the deadlines of the open issues assigned to the
+// authenticated user and of their milestones as an iCalendar feed
+func GetMyCalendar(ctx *context.APIContext) {
+	// swagger:route GET /user/calendar.ics userCurrentCalendar
+	//
+	//     Produces:
+	//     - text/calendar
+	//
+	//     Responses:
+	//       200: Calendar
+	//       500: error
+
+	issues, err := models.GetAssignedDeadlineIssues(ctx.User.ID)
+	if err != nil {
+		ctx.Error(500, "GetAssignedDeadlineIssues", err)
+		return
+	}
+	miles, err := models.GetAssignedDeadlineMilestones(ctx.User.ID)
+	if err != nil {
+		ctx.Error(500, "GetAssignedDeadlineMilestones", err)
+		return
+	}
+
+	cal := &ical.Calendar{
+		ProductID: "-//" + setting.AppName + "//" + setting.AppName + "//EN",
+		Name:      fmt.Sprintf("%s - %s", ctx.User.Name, setting.AppName),
+		Events:    make([]*ical.Event, 0, len(issues)+len(miles)),
+	}
+	now := time.Now()
+	repos := make(map[int64]*models.Repository)
+	for _, issue := range issues {
+		repos[issue.RepoID] = issue.Repo
+		cal.Events = append(cal.Events, &ical.Event{
+			UID:     fmt.Sprintf("issue-%d@%s", issue.ID, setting.Domain),
+			Date:    issue.Deadline,
+			Summary: fmt.Sprintf("%s#%d: %s", issue.Repo.FullName(), issue.Index, issue.Title),
+			URL:     issue.HTMLURL(),
+			Updated: issue.Updated,
+		})
+	}
+	for _, m := range miles {
+		repo, ok := repos[m.RepoID]
+		if !ok {
+			if repo, err = models.GetRepositoryByID(m.RepoID); err != nil {
+				ctx.Error(500, "GetRepositoryByID", err)
+				return
+			}
+			repos[m.RepoID] = repo
+		}
+		cal.Events = append(cal.Events, &ical.Event{
+			UID:         fmt.Sprintf("milestone-%d@%s", m.ID, setting.Domain),
+			Date:        m.Deadline,
+			Summary:     fmt.Sprintf("%s: %s", repo.FullName(), m.Name),
+			Description: m.Content,
+			URL:         fmt.Sprintf("%s/issues?milestone=%d", repo.HTMLURL(), m.ID),
+			Updated:     now,
+		})
+	}
+
+	ctx.Resp.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	ctx.Resp.WriteHeader(200)
+	if err = cal.Encode(ctx.Resp); err != nil {
+		log.Error(4, "Encode: %v", err)
+	}
+}
@@ -11,16 +11,28 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/routers/api/v1/convert"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 // ListEmails list all the emails of mine
 // see https://github.com/gogits/go-gogs-client/wiki/Users-Emails#list-email-addresses-for-a-user
 func ListEmails(ctx *context.APIContext) {
+	// swagger:route GET /user/emails userListEmails
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: EmailList
+	//       500: error
+
 	emails, err := models.GetEmailAddresses(ctx.User.ID)
 	if err != nil {
 		ctx.Error(500, "GetEmailAddresses", err)
 		return
 	}
+	start, end := utils.Paginate(ctx, len(emails))
+	emails = emails[start:end]
 	apiEmails := make([]*api.Email, len(emails))
 	for i := range emails {
 		apiEmails[i] = convert.ToEmail(emails[i])
@@ -31,6 +43,19 @@ func ListEmails(ctx *context.APIContext) {
 // AddEmail add email for me
 // see https://github.com/gogits/go-gogs-client/wiki/Users-Emails#add-email-addresses
 func AddEmail(ctx *context.APIContext, form api.CreateEmailOption) {
+	// swagger:route POST /user/emails userAddEmail
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       201: EmailList
+	//       422: validationError
+	//       500: error
+
 	if len(form.Emails) == 0 {
 		ctx.Status(422)
 		return
@@ -48,12 +73,20 @@ func AddEmail(ctx *context.APIContext, form api.CreateEmailOption) {
 	if err := models.AddEmailAddresses(emails); err != nil {
 		if models.IsErrEmailAlreadyUsed(err) {
 			ctx.Error(422, "", "Email address has been used: "+err.(models.ErrEmailAlreadyUsed).Email)
+		} else if models.IsErrEmailDomainNotAllowed(err) {
+			ctx.Error(422, "", "Email address domain is not allowed: "+err.(models.ErrEmailDomainNotAllowed).Email)
 		} else {
 			ctx.Error(500, "AddEmailAddresses", err)
 		}
 		return
 	}
 
+	if setting.Service.RegisterEmailConfirm {
+		for _, email := range emails {
+			models.SendActivateEmailMail(ctx.Context.Context, ctx.User, email)
+		}
+	}
+
 	apiEmails := make([]*api.Email, len(emails))
 	for i := range emails {
 		apiEmails[i] = convert.ToEmail(emails[i])
@@ -64,6 +97,18 @@ func AddEmail(ctx *context.APIContext, form api.CreateEmailOption) {
 // DeleteEmail delete email
 // see https://github.com/gogits/go-gogs-client/wiki/Users-Emails#delete-email-addresses
 func DeleteEmail(ctx *context.APIContext, form api.CreateEmailOption) {
+	// swagger:route DELETE /user/emails userDeleteEmail
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       204: empty
+	//       500: error
+
 	if len(form.Emails) == 0 {
 		ctx.Status(204)
 		return
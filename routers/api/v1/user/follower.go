@@ -9,6 +9,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 func responseAPIUsers(ctx *context.APIContext, users []*models.User) {
@@ -20,11 +21,13 @@ func responseAPIUsers(ctx *context.APIContext, users []*models.User) {
 }
 
 func listUserFollowers(ctx *context.APIContext, u *models.User) {
-	users, err := u.GetFollowers(ctx.QueryInt("page"))
+	opts := utils.GetListOptions(ctx)
+	users, err := u.GetFollowers(opts.Page, opts.PageSize)
 	if err != nil {
 		ctx.Error(500, "GetUserFollowers", err)
 		return
 	}
+	utils.SetPaginationHeaders(ctx, u.NumFollowers)
 	responseAPIUsers(ctx, users)
 }
 
@@ -44,7 +47,7 @@ func ListMyFollowers(ctx *context.APIContext) {
 
 // ListFollowers list user's followers
 func ListFollowers(ctx *context.APIContext) {
-	// swagger:route GET /users/:username/followers userListFollowers
+	// swagger:route GET /users/{username}/followers userListFollowers
 	//
 	//     Produces:
 	//     - application/json
@@ -61,11 +64,13 @@ func ListFollowers(ctx *context.APIContext) {
 }
 
 func listUserFollowing(ctx *context.APIContext, u *models.User) {
-	users, err := u.GetFollowing(ctx.QueryInt("page"))
+	opts := utils.GetListOptions(ctx)
+	users, err := u.GetFollowing(opts.Page, opts.PageSize)
 	if err != nil {
 		ctx.Error(500, "GetFollowing", err)
 		return
 	}
+	utils.SetPaginationHeaders(ctx, u.NumFollowing)
 	responseAPIUsers(ctx, users)
 }
 
@@ -126,7 +131,7 @@ func CheckMyFollowing(ctx *context.APIContext) {
 
 // CheckFollowing check if the repo is followed by user
 func CheckFollowing(ctx *context.APIContext) {
-	// swagger:route GET /users/{username}/following/:target userCheckFollowing
+	// swagger:route GET /users/{username}/following/{target} userCheckFollowing
 	//
 	//     Responses:
 	//       204: empty
@@ -11,6 +11,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/routers/api/v1/convert"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 func composePublicGPGKeysAPILink() string {
@@ -24,6 +25,9 @@ func listGPGKeys(ctx *context.APIContext, uid int64) {
 		return
 	}
 
+	start, end := utils.Paginate(ctx, len(keys))
+	keys = keys[start:end]
+
 	apiKeys := make([]*api.GPGKey, len(keys))
 	for i := range keys {
 		apiKeys[i] = convert.ToGPGKey(keys[i])
@@ -12,6 +12,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/routers/api/v1/convert"
 	"code.gitea.io/gitea/routers/api/v1/repo"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 // GetUserByParamsName get user by name
@@ -44,6 +45,9 @@ func listPublicKeys(ctx *context.APIContext, uid int64) {
 		return
 	}
 
+	start, end := utils.Paginate(ctx, len(keys))
+	keys = keys[start:end]
+
 	apiLink := composePublicKeysAPILink()
 	apiKeys := make([]*api.PublicKey, len(keys))
 	for i := range keys {
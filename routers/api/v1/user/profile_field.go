@@ -0,0 +1,129 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+)
+
+// profileField represents the value of a custom profile field of a user
+type profileField struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+func listProfileFields(ctx *context.APIContext, u *models.User) {
+	values, err := models.GetProfileFieldValues(u, ctx.User)
+	if err != nil {
+		ctx.Error(500, "GetProfileFieldValues", err)
+		return
+	}
+
+	apiFields := make([]*profileField, len(values))
+	for i, value := range values {
+		apiFields[i] = &profileField{
+			Name:  value.Field.Name,
+			Type:  value.Field.Type.String(),
+			Value: value.Value,
+		}
+	}
+	ctx.JSON(200, &apiFields)
+}
+
+// ListProfileFields list the custom profile fields of a user visible to the requester
+func ListProfileFields(ctx *context.APIContext) {
+	// swagger:route GET /users/{username}/profile_fields userListProfileFields
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: ProfileFieldList
+	//       404: notFound
+	//       500: error
+
+	u := GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	listProfileFields(ctx, u)
+}
+
+// ListMyProfileFields list the custom profile fields of the authenticated user
+func ListMyProfileFields(ctx *context.APIContext) {
+	// swagger:route GET /user/profile_fields userCurrentListProfileFields
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: ProfileFieldList
+	//       500: error
+
+	listProfileFields(ctx, ctx.User)
+}
+
+// EditMyProfileFields set the custom profile fields of the authenticated user,
+// given as an object which maps names of fields to values
+func EditMyProfileFields(ctx *context.APIContext) {
+	// swagger:route PATCH /user/profile_fields userCurrentEditProfileFields
+	//
+	//     Consumes:
+	//     - application/json
+	//
+	//     Produces:
+	//     - application/json
+	//
+	//     Responses:
+	//       200: ProfileFieldList
+	//       422: validationError
+	//       500: error
+
+	body, err := ctx.Req.Body().Bytes()
+	if err != nil {
+		ctx.Error(500, "ReadBody", err)
+		return
+	}
+	form := make(map[string]string)
+	if err = json.Unmarshal(body, &form); err != nil {
+		ctx.Error(422, "", err)
+		return
+	}
+
+	fields, err := models.GetProfileFields()
+	if err != nil {
+		ctx.Error(500, "GetProfileFields", err)
+		return
+	}
+	fieldIDs := make(map[string]int64, len(fields))
+	for _, field := range fields {
+		fieldIDs[field.Name] = field.ID
+	}
+
+	values := make(map[int64]string, len(form))
+	for name, value := range form {
+		id, ok := fieldIDs[name]
+		if !ok {
+			ctx.Error(422, "", fmt.Errorf("profile field does not exist: %s", name))
+			return
+		}
+		values[id] = value
+	}
+	if err = models.UpdateProfileFieldValues(ctx.User.ID, values); err != nil {
+		if models.IsErrProfileFieldInvalidValue(err) {
+			ctx.Error(422, "", err)
+		} else {
+			ctx.Error(500, "UpdateProfileFieldValues", err)
+		}
+		return
+	}
+
+	listProfileFields(ctx, ctx.User)
+}
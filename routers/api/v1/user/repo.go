@@ -3,6 +3,7 @@ package user
 import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 	api "code.gitea.io/sdk/gitea"
 )
 
@@ -31,6 +32,8 @@ func listUserRepos(ctx *context.APIContext, u *models.User) {
 	for i := 0; i < len(accessibleRepos); i++ {
 		apiRepos[i+len(ownRepos)] = accessibleRepos[i]
 	}
+	start, end := utils.Paginate(ctx, len(apiRepos))
+	apiRepos = apiRepos[start:end]
 	ctx.JSON(200, &apiRepos)
 }
 
@@ -9,6 +9,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 // getStarredRepos returns the repos that the user with the specified userID has
@@ -47,7 +48,10 @@ func GetStarredRepos(ctx *context.APIContext) {
 	repos, err := getStarredRepos(user.ID, private)
 	if err != nil {
 		ctx.Error(500, "getStarredRepos", err)
+		return
 	}
+	start, end := utils.Paginate(ctx, len(repos))
+	repos = repos[start:end]
 	ctx.JSON(200, &repos)
 }
 
@@ -65,7 +69,10 @@ func GetMyStarredRepos(ctx *context.APIContext) {
 	repos, err := getStarredRepos(ctx.User.ID, true)
 	if err != nil {
 		ctx.Error(500, "getStarredRepos", err)
+		return
 	}
+	start, end := utils.Paginate(ctx, len(repos))
+	repos = repos[start:end]
 	ctx.JSON(200, &repos)
 }
 
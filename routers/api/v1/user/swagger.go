@@ -0,0 +1,81 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	api "code.gitea.io/sdk/gitea"
+)
+
+// The declarations below are only read by the swagger generator, they
+// describe the parameters and responses of the routes of this package.
+
+// swagger:parameters userGet userListRepos userListProfileFields userGetTokens userCreateToken userListKeys userListGPGKeys userListFollowers userListFollowing userCheckFollowing userListStarred userListSubscriptions userCurrentCheckFollowing userCurrentPutFollow userCurrentDeleteFollow
+type swaggerUsernameParams struct {
+	// in: path
+	// required: true
+	Username string `json:"username"`
+}
+
+// swagger:parameters userCheckFollowing
+type swaggerTargetParams struct {
+	// name of the user who may be followed
+	//
+	// in: path
+	// required: true
+	Target string `json:"target"`
+}
+
+// swagger:parameters userCurrentGetKey userCurrentDeleteKey userCurrentGetGPGKey userCurrentDeleteGPGKey
+type swaggerIDParams struct {
+	// in: path
+	// required: true
+	ID int64 `json:"id"`
+}
+
+// swagger:parameters userCurrentCheckStarring userCurrentPutStar userCurrentDeleteStar userCurrentCheckSubscription userCurrentPutSubscription userCurrentDeleteSubscription
+type swaggerRepoParams struct {
+	// owner of the repository
+	//
+	// in: path
+	// required: true
+	Username string `json:"username"`
+	// name of the repository
+	//
+	// in: path
+	// required: true
+	Reponame string `json:"reponame"`
+}
+
+// swagger:parameters userAddEmail userDeleteEmail
+type swaggerEmailParams struct {
+	// in: body
+	Body api.CreateEmailOption
+}
+
+// swagger:parameters userCurrentEditProfileFields
+type swaggerEditProfileFieldsParams struct {
+	// values of the fields by their names
+	//
+	// in: body
+	Body map[string]string
+}
+
+// EmailList represents a list of email addresses
+// swagger:response EmailList
+type swaggerEmailList struct {
+	// in: body
+	Body []*api.Email
+}
+
+// ProfileFieldList represents a list of the custom profile fields of a user
+// swagger:response ProfileFieldList
+type swaggerProfileFieldList struct {
+	// in: body
+	Body []*profileField
+}
+
+// Calendar is an iCalendar feed
+// swagger:response Calendar
+type swaggerCalendar struct{}
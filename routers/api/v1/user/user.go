@@ -7,12 +7,11 @@ package user
 import (
 	"strings"
 
-	"github.com/Unknwon/com"
-
 	api "code.gitea.io/sdk/gitea"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 // Search search users
@@ -26,16 +25,15 @@ func Search(ctx *context.APIContext) {
 	//       200: UserList
 	//       500: error
 
+	listOpts := utils.GetListOptions(ctx)
 	opts := &models.SearchUserOptions{
 		Keyword:  strings.Trim(ctx.Query("q"), " "),
 		Type:     models.UserTypeIndividual,
-		PageSize: com.StrTo(ctx.Query("limit")).MustInt(),
-	}
-	if opts.PageSize == 0 {
-		opts.PageSize = 10
+		Page:     listOpts.Page,
+		PageSize: listOpts.PageSize,
 	}
 
-	users, _, err := models.SearchUserByName(opts)
+	users, count, err := models.SearchUserByName(opts)
 	if err != nil {
 		ctx.JSON(500, map[string]interface{}{
 			"ok":    false,
@@ -43,6 +41,7 @@ func Search(ctx *context.APIContext) {
 		})
 		return
 	}
+	ctx.SetLinkHeader(int(count), opts.PageSize)
 
 	results := make([]*api.User, len(users))
 	for i := range users {
@@ -89,7 +88,7 @@ func GetInfo(ctx *context.APIContext) {
 	if !ctx.IsSigned {
 		u.Email = ""
 	}
-	ctx.JSON(200, u.APIFormat())
+	ctx.JSONWithETag(200, u.APIFormat())
 }
 
 // GetAuthenticatedUser get curent user's information
@@ -102,5 +101,5 @@ func GetAuthenticatedUser(ctx *context.APIContext) {
 	//     Responses:
 	//       200: User
 
-	ctx.JSON(200, ctx.User.APIFormat())
+	ctx.JSONWithETag(200, ctx.User.APIFormat())
 }
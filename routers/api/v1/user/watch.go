@@ -5,11 +5,15 @@
 package user
 
 import (
+	"encoding/json"
+	"fmt"
+
 	api "code.gitea.io/sdk/gitea"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
 // getWatchedRepos returns the repos that the user with the specified userID is
@@ -47,7 +51,10 @@ func GetWatchedRepos(ctx *context.APIContext) {
 	repos, err := getWatchedRepos(user.ID, private)
 	if err != nil {
 		ctx.Error(500, "getWatchedRepos", err)
+		return
 	}
+	start, end := utils.Paginate(ctx, len(repos))
+	repos = repos[start:end]
 	ctx.JSON(200, &repos)
 }
 
@@ -65,10 +72,38 @@ func GetMyWatchedRepos(ctx *context.APIContext) {
 	repos, err := getWatchedRepos(ctx.User.ID, true)
 	if err != nil {
 		ctx.Error(500, "getWatchedRepos", err)
+		return
 	}
+	start, end := utils.Paginate(ctx, len(repos))
+	repos = repos[start:end]
 	ctx.JSON(200, &repos)
 }
 
+// watchInfo represents the subscription of the authenticated user to a repo
+type watchInfo struct {
+	api.WatchInfo
+	Mode string `json:"mode"`
+}
+
+// watchOption represents the options to subscribe to a repo
+type watchOption struct {
+	Mode string `json:"mode"`
+}
+
+func toWatchInfo(repo *models.Repository, watch *models.Watch) *watchInfo {
+	return &watchInfo{
+		WatchInfo: api.WatchInfo{
+			Subscribed:    watch.Mode != models.RepoWatchModeIgnore,
+			Ignored:       watch.Mode == models.RepoWatchModeIgnore,
+			Reason:        nil,
+			CreatedAt:     repo.Created,
+			URL:           subscriptionURL(repo),
+			RepositoryURL: repositoryURL(repo),
+		},
+		Mode: watch.Mode.String(),
+	}
+}
+
 // IsWatching returns whether the authenticated user is watching the repo
 // specified in ctx
 func IsWatching(ctx *context.APIContext) {
@@ -77,43 +112,55 @@ func IsWatching(ctx *context.APIContext) {
 	//     Responses:
 	//       200: WatchInfo
 	//       404: notFound
+	//       500: error
 
-	if models.IsWatching(ctx.User.ID, ctx.Repo.Repository.ID) {
-		ctx.JSON(200, api.WatchInfo{
-			Subscribed:    true,
-			Ignored:       false,
-			Reason:        nil,
-			CreatedAt:     ctx.Repo.Repository.Created,
-			URL:           subscriptionURL(ctx.Repo.Repository),
-			RepositoryURL: repositoryURL(ctx.Repo.Repository),
-		})
-	} else {
+	watch, err := models.GetWatch(ctx.User.ID, ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(500, "GetWatch", err)
+		return
+	} else if watch == nil {
 		ctx.Status(404)
+		return
 	}
+	ctx.JSON(200, toWatchInfo(ctx.Repo.Repository, watch))
 }
 
-// Watch the repo specified in ctx, as the authenticated user
+// Watch the repo specified in ctx, as the authenticated user. The body may
+// give the watch mode, which is one of "all", "issues", "releases" and "ignore".
 func Watch(ctx *context.APIContext) {
 	// swagger:route PUT /repos/{username}/{reponame}/subscription userCurrentPutSubscription
 	//
+	//     Consumes:
+	//     - application/json
+	//
 	//     Responses:
 	//       200: WatchInfo
+	//       422: validationError
 	//       500: error
 
-	err := models.WatchRepo(ctx.User.ID, ctx.Repo.Repository.ID, true)
+	body, err := ctx.Req.Body().Bytes()
 	if err != nil {
-		ctx.Error(500, "WatchRepo", err)
+		ctx.Error(500, "ReadBody", err)
+		return
+	}
+	form := watchOption{Mode: models.RepoWatchModeAll.String()}
+	if len(body) > 0 {
+		if err = json.Unmarshal(body, &form); err != nil {
+			ctx.Error(422, "", err)
+			return
+		}
+	}
+	mode, ok := models.ParseRepoWatchMode(form.Mode)
+	if !ok {
+		ctx.Error(422, "", fmt.Errorf("invalid watch mode: %s", form.Mode))
 		return
 	}
-	ctx.JSON(200, api.WatchInfo{
-		Subscribed:    true,
-		Ignored:       false,
-		Reason:        nil,
-		CreatedAt:     ctx.Repo.Repository.Created,
-		URL:           subscriptionURL(ctx.Repo.Repository),
-		RepositoryURL: repositoryURL(ctx.Repo.Repository),
-	})
 
+	if err = models.SetWatchMode(ctx.User.ID, ctx.Repo.Repository.ID, mode); err != nil {
+		ctx.Error(500, "SetWatchMode", err)
+		return
+	}
+	ctx.JSON(200, toWatchInfo(ctx.Repo.Repository, &models.Watch{Mode: mode}))
 }
 
 // Unwatch the repo specified in ctx, as the authenticated user
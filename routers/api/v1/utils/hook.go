@@ -97,9 +97,12 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 		HookEvent: &models.HookEvent{
 			ChooseEvents: true,
 			HookEvents: models.HookEvents{
-				Create:      com.IsSliceContainsStr(form.Events, string(models.HookEventCreate)),
-				Push:        com.IsSliceContainsStr(form.Events, string(models.HookEventPush)),
-				PullRequest: com.IsSliceContainsStr(form.Events, string(models.HookEventPullRequest)),
+				Create:       com.IsSliceContainsStr(form.Events, string(models.HookEventCreate)),
+				Push:         com.IsSliceContainsStr(form.Events, string(models.HookEventPush)),
+				PullRequest:  com.IsSliceContainsStr(form.Events, string(models.HookEventPullRequest)),
+				Issues:       com.IsSliceContainsStr(form.Events, string(models.HookEventIssues)),
+				IssueComment: com.IsSliceContainsStr(form.Events, string(models.HookEventIssueComment)),
+				Status:       com.IsSliceContainsStr(form.Events, string(models.HookEventStatus)),
 			},
 		},
 		IsActive:     form.Active,
@@ -182,6 +185,9 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 			}
 			w.ContentType = models.ToHookContentType(ct)
 		}
+		if secret, ok := form.Config["secret"]; ok {
+			w.ChangeSecret(secret)
+		}
 
 		if w.HookTaskType == models.SLACK {
 			if channel, ok := form.Config["channel"]; ok {
@@ -210,6 +216,9 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 	w.Create = com.IsSliceContainsStr(form.Events, string(models.HookEventCreate))
 	w.Push = com.IsSliceContainsStr(form.Events, string(models.HookEventPush))
 	w.PullRequest = com.IsSliceContainsStr(form.Events, string(models.HookEventPullRequest))
+	w.Issues = com.IsSliceContainsStr(form.Events, string(models.HookEventIssues))
+	w.IssueComment = com.IsSliceContainsStr(form.Events, string(models.HookEventIssueComment))
+	w.Status = com.IsSliceContainsStr(form.Events, string(models.HookEventStatus))
 	if err := w.UpdateEvent(); err != nil {
 		ctx.Error(500, "UpdateEvent", err)
 		return false
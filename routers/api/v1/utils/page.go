@@ -0,0 +1,53 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+)
+
+// ListOptions is the page of a list requested with the "page" and "limit"
+// query parameters.
+type ListOptions struct {
+	Page     int
+	PageSize int
+}
+
+// GetListOptions returns the page of the list requested, the first one by
+// default.
+func GetListOptions(ctx *context.APIContext) ListOptions {
+	page := ctx.QueryInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	return ListOptions{
+		Page:     page,
+		PageSize: convert.ToCorrectPageSize(ctx.QueryInt("limit")),
+	}
+}
+
+// SetPaginationHeaders sets the Link and X-Total-Count headers of the page
+// of a list with total items.
+func SetPaginationHeaders(ctx *context.APIContext, total int) {
+	ctx.SetLinkHeader(total, GetListOptions(ctx).PageSize)
+}
+
+// Paginate sets the pagination headers of a list with total items loaded at
+// once, and returns the bounds of the requested page within the list.
+func Paginate(ctx *context.APIContext, total int) (start, end int) {
+	opts := GetListOptions(ctx)
+	ctx.SetLinkHeader(total, opts.PageSize)
+
+	start = (opts.Page - 1) * opts.PageSize
+	if start > total {
+		start = total
+	}
+	end = start + opts.PageSize
+	if end > total {
+		end = total
+	}
+	return start, end
+}
@@ -21,7 +21,9 @@ const (
 	// tplHome home page template
 	tplHome base.TplName = "home"
 	// tplSwagger swagger page template
-	tplSwagger base.TplName = "swagger"
+	tplSwagger base.TplName = "swagger/ui"
+	// tplSwaggerV1JSON swagger specification of the v1 API template
+	tplSwaggerV1JSON base.TplName = "swagger/v1_json"
 	// tplExploreRepos explore repositories page template
 	tplExploreRepos base.TplName = "explore/repos"
 	// tplExploreUsers explore users page template
@@ -58,13 +60,28 @@ func Swagger(ctx *context.Context) {
 	ctx.HTML(200, tplSwagger)
 }
 
+// SwaggerV1JSON render the swagger specification of the v1 API, with the
+// version and base path of this instance.
+func SwaggerV1JSON(ctx *context.Context) {
+	data, err := ctx.HTMLBytes(string(tplSwaggerV1JSON), ctx.Data)
+	if err != nil {
+		ctx.Handle(500, "HTMLBytes", err)
+		return
+	}
+	ctx.Resp.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	ctx.Resp.WriteHeader(200)
+	ctx.Resp.Write(data)
+}
+
 // RepoSearchOptions when calling search repositories
 type RepoSearchOptions struct {
 	Ranger   func(*models.SearchRepoOptions) (models.RepositoryList, int64, error)
 	Searcher *models.User
 	Private  bool
-	PageSize int
-	TplName  base.TplName
+	// LoadMirrors loads the mirror information of mirror repositories.
+	LoadMirrors bool
+	PageSize    int
+	TplName     base.TplName
 }
 
 var (
@@ -105,10 +122,21 @@ func RenderRepoSearch(ctx *context.Context, opts *RepoSearchOptions) {
 		orderBy = "size DESC"
 	case "size":
 		orderBy = "size ASC"
+	case "moststars":
+		orderBy = "num_stars DESC"
+	case "feweststars":
+		orderBy = "num_stars ASC"
 	default:
 		orderBy = "created_unix DESC"
 	}
 
+	language := ctx.Query("language")
+	fork := ctx.QueryOptionalBool("fork")
+	mirror := ctx.QueryOptionalBool("mirror")
+	ctx.Data["Language"] = language
+	ctx.Data["Fork"] = ctx.Query("fork")
+	ctx.Data["Mirror"] = ctx.Query("mirror")
+
 	keyword := strings.Trim(ctx.Query("q"), " ")
 	if len(keyword) == 0 {
 		repos, count, err = opts.Ranger(&models.SearchRepoOptions{
@@ -117,6 +145,9 @@ func RenderRepoSearch(ctx *context.Context, opts *RepoSearchOptions) {
 			Searcher: ctx.User,
 			OrderBy:  orderBy,
 			Private:  opts.Private,
+			Language: language,
+			Fork:     fork,
+			Mirror:   mirror,
 		})
 		if err != nil {
 			ctx.Handle(500, "opts.Ranger", err)
@@ -131,6 +162,9 @@ func RenderRepoSearch(ctx *context.Context, opts *RepoSearchOptions) {
 				Page:     page,
 				PageSize: opts.PageSize,
 				Searcher: ctx.User,
+				Language: language,
+				Fork:     fork,
+				Mirror:   mirror,
 			})
 			if err != nil {
 				ctx.Handle(500, "SearchRepositoryByName", err)
@@ -138,6 +172,12 @@ func RenderRepoSearch(ctx *context.Context, opts *RepoSearchOptions) {
 			}
 		}
 	}
+	if opts.LoadMirrors {
+		if err = models.MirrorRepositoryList(repos).LoadAttributes(); err != nil {
+			ctx.Handle(500, "LoadAttributes", err)
+			return
+		}
+	}
 	ctx.Data["Keyword"] = keyword
 	ctx.Data["Total"] = count
 	ctx.Data["Page"] = paginater.New(int(count), opts.PageSize, page, 5)
@@ -152,6 +192,13 @@ func ExploreRepos(ctx *context.Context) {
 	ctx.Data["PageIsExplore"] = true
 	ctx.Data["PageIsExploreRepositories"] = true
 
+	languages, err := models.GetStatLanguages()
+	if err != nil {
+		ctx.Handle(500, "GetStatLanguages", err)
+		return
+	}
+	ctx.Data["Languages"] = languages
+
 	RenderRepoSearch(ctx, &RepoSearchOptions{
 		Ranger:   models.GetRecentUpdatedRepositories,
 		PageSize: setting.UI.ExplorePagingNum,
@@ -168,6 +215,8 @@ type UserSearchOptions struct {
 	Ranger   func(*models.SearchUserOptions) ([]*models.User, error)
 	PageSize int
 	TplName  base.TplName
+	// LoadProfileFields loads the custom profile fields of found users
+	LoadProfileFields bool
 }
 
 // RenderUserSearch render user search page
@@ -227,6 +276,20 @@ func RenderUserSearch(ctx *context.Context, opts *UserSearchOptions) {
 			}
 		}
 	}
+	if opts.LoadProfileFields {
+		fields, err := models.GetProfileFields()
+		if err != nil {
+			ctx.Handle(500, "GetProfileFields", err)
+			return
+		}
+		ctx.Data["ProfileFields"] = fields
+
+		if err = models.LoadProfileFieldValues(users); err != nil {
+			ctx.Handle(500, "LoadProfileFieldValues", err)
+			return
+		}
+	}
+
 	ctx.Data["Keyword"] = keyword
 	ctx.Data["Total"] = count
 	ctx.Data["Page"] = paginater.New(int(count), opts.PageSize, page, 5)
@@ -0,0 +1,71 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package routers
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// metricsCacheTTL is how long the statistics are served from the cache, so
+// that scrapes don't count the tables and walk the storage every time.
+const metricsCacheTTL = time.Minute
+
+// writeGauge writes a gauge without labels in the Prometheus text format.
+func writeGauge(buf *bytes.Buffer, name, help string, value int64) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", name, help, name, name, value)
+}
+
+// Metrics serves the statistics of the database and the storage in the
+// Prometheus text format, collected at most once per metricsCacheTTL
+func Metrics(ctx *context.Context) {
+	if !setting.Metrics.Enabled {
+		ctx.Error(404)
+		return
+	}
+	if len(setting.Metrics.Token) > 0 {
+		auth := []byte(ctx.Req.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(auth, []byte("Bearer "+setting.Metrics.Token)) != 1 {
+			ctx.Error(401)
+			return
+		}
+	}
+
+	stats, err := models.GetCachedStorageStatistic(metricsCacheTTL)
+	if err != nil {
+		log.Error(4, "GetCachedStorageStatistic: %v", err)
+		ctx.Error(500)
+		return
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# HELP gitea_table_rows Number of rows of a table of the database.\n# TYPE gitea_table_rows gauge\n")
+	for _, table := range stats.Tables {
+		fmt.Fprintf(&buf, "gitea_table_rows{table=%q} %d\n", table.Name, table.Rows)
+	}
+	writeGauge(&buf, "gitea_repositories", "Number of repositories.", stats.NumRepos)
+	writeGauge(&buf, "gitea_repositories_size_bytes", "Size of the repositories.", stats.RepoSize)
+	writeGauge(&buf, "gitea_lfs_objects", "Number of LFS objects.", stats.NumLFSObjects)
+	writeGauge(&buf, "gitea_lfs_size_bytes", "Size of the LFS content store.", stats.LFSSize)
+	writeGauge(&buf, "gitea_attachments", "Number of attachments.", stats.NumAttachments)
+	writeGauge(&buf, "gitea_attachments_size_bytes", "Size of the attachments.", stats.AttachmentSize)
+	writeGauge(&buf, "gitea_avatars_size_bytes", "Size of the avatars of users.", stats.AvatarSize)
+	writeGauge(&buf, "gitea_repo_avatars_size_bytes", "Size of the avatars of repositories.", stats.RepoAvatarSize)
+	writeGauge(&buf, "gitea_hook_tasks", "Number of webhook deliveries.", stats.NumHookTasks)
+	writeGauge(&buf, "gitea_hook_tasks_pending", "Number of webhook deliveries waiting in the queue.", stats.NumPendingHookTasks)
+	writeGauge(&buf, "gitea_sessions", "Number of sessions of signed in users.", stats.NumSessions)
+	writeGauge(&buf, "gitea_sessions_recent", "Number of sessions used in the last hour.", stats.NumRecentSessions)
+
+	ctx.Resp.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	ctx.Resp.WriteHeader(200)
+	ctx.Resp.Write(buf.Bytes())
+}
@@ -6,6 +6,7 @@ package org
 
 import (
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/auth"
@@ -23,6 +24,12 @@ const (
 	tplSettingsDelete base.TplName = "org/settings/delete"
 	// tplSettingsHooks template path for render hook settings
 	tplSettingsHooks base.TplName = "org/settings/hooks"
+	// tplSettingsModeration template path for render moderation settings
+	tplSettingsModeration base.TplName = "org/settings/moderation"
+	// tplSettingsBanner template path for render banner settings
+	tplSettingsBanner base.TplName = "org/settings/banner"
+	// tplSettingsDefaults template path for render default labels and milestones settings
+	tplSettingsDefaults base.TplName = "org/settings/defaults"
 )
 
 // Settings render the main settings page
@@ -116,9 +123,11 @@ func SettingsDelete(ctx *context.Context) {
 
 	org := ctx.Org.Organization
 	if ctx.Req.Method == "POST" {
-		if _, err := models.UserSignIn(ctx.User.Name, ctx.Query("password")); err != nil {
+		if _, err := models.UserSignInFrom(ctx.User.Name, ctx.Query("password"), ctx.RemoteAddr(), ctx.Req.UserAgent()); err != nil {
 			if models.IsErrUserNotExist(err) {
 				ctx.RenderWithErr(ctx.Tr("form.enterred_invalid_password"), tplSettingsDelete, nil)
+			} else if models.IsErrUserLocked(err) {
+				ctx.RenderWithErr(ctx.Tr("form.sign_in_locked"), tplSettingsDelete, nil)
 			} else {
 				ctx.Handle(500, "UserSignIn", err)
 			}
@@ -171,3 +180,248 @@ func DeleteWebhook(ctx *context.Context) {
 		"redirect": ctx.Org.OrgLink + "/settings/hooks",
 	})
 }
+
+// Moderation render blocked words of the organization and content held for
+// moderation in its repositories
+func Moderation(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsModeration"] = true
+
+	words, err := models.GetBlockedWords(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Handle(500, "GetBlockedWords", err)
+		return
+	}
+	ctx.Data["BlockedWords"] = words
+
+	items, err := models.GetModerationItems(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Handle(500, "GetModerationItems", err)
+		return
+	}
+	ctx.Data["ModerationItems"] = items
+
+	ctx.HTML(200, tplSettingsModeration)
+}
+
+// AddBlockedWord response for adding a blocked word to the organization
+func AddBlockedWord(ctx *context.Context, form auth.BlockedWordForm) {
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(ctx.Org.OrgLink + "/settings/moderation")
+		return
+	}
+
+	action := models.BlockedWordReject
+	if form.Moderate {
+		action = models.BlockedWordModerate
+	}
+	if err := models.AddBlockedWord(ctx.Org.Organization.ID, form.Word, action); err != nil {
+		if models.IsErrBlockedWordAlreadyExist(err) {
+			ctx.Flash.Error(ctx.Tr("admin.moderation.word_already_exists"))
+			ctx.Redirect(ctx.Org.OrgLink + "/settings/moderation")
+			return
+		}
+		ctx.Handle(500, "AddBlockedWord", err)
+		return
+	}
+
+	log.Trace("Blocked word added to organization %s: %s", ctx.Org.Organization.Name, form.Word)
+	ctx.Flash.Success(ctx.Tr("admin.moderation.add_word_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/moderation")
+}
+
+// DeleteBlockedWord response for deleting a blocked word of the organization
+func DeleteBlockedWord(ctx *context.Context) {
+	if err := models.DeleteBlockedWord(ctx.Org.Organization.ID, ctx.QueryInt64("id")); err != nil {
+		ctx.Handle(500, "DeleteBlockedWord", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("admin.moderation.delete_word_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/moderation")
+}
+
+// Defaults render default labels and milestones of new repositories of the
+// organization
+func Defaults(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsDefaults"] = true
+
+	labels, err := models.GetOrgLabelTemplates(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Handle(500, "GetOrgLabelTemplates", err)
+		return
+	}
+	ctx.Data["LabelTemplates"] = labels
+
+	milestones, err := models.GetOrgMilestoneTemplates(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Handle(500, "GetOrgMilestoneTemplates", err)
+		return
+	}
+	ctx.Data["MilestoneTemplates"] = milestones
+
+	ctx.HTML(200, tplSettingsDefaults)
+}
+
+// AddLabelTemplate response for adding a default label to the organization
+func AddLabelTemplate(ctx *context.Context, form auth.OrgLabelTemplateForm) {
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(ctx.Org.OrgLink + "/settings/defaults")
+		return
+	}
+
+	if err := models.NewOrgLabelTemplate(&models.OrgLabelTemplate{
+		OrgID:       ctx.Org.Organization.ID,
+		Name:        form.Name,
+		Description: form.Description,
+		Color:       form.Color,
+	}); err != nil {
+		ctx.Handle(500, "NewOrgLabelTemplate", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.settings.defaults.add_label_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/defaults")
+}
+
+// DeleteLabelTemplate response for deleting a default label of the organization
+func DeleteLabelTemplate(ctx *context.Context) {
+	if err := models.DeleteOrgLabelTemplate(ctx.Org.Organization.ID, ctx.QueryInt64("id")); err != nil {
+		ctx.Handle(500, "DeleteOrgLabelTemplate", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.settings.defaults.delete_label_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/defaults")
+}
+
+// AddMilestoneTemplate response for adding a default milestone to the organization
+func AddMilestoneTemplate(ctx *context.Context, form auth.OrgMilestoneTemplateForm) {
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(ctx.Org.OrgLink + "/settings/defaults")
+		return
+	}
+
+	if err := models.NewOrgMilestoneTemplate(&models.OrgMilestoneTemplate{
+		OrgID:        ctx.Org.Organization.ID,
+		Name:         form.Name,
+		Content:      form.Content,
+		DueDays:      form.DueDays,
+		Count:        form.Count,
+		IntervalDays: form.IntervalDays,
+	}); err != nil {
+		ctx.Handle(500, "NewOrgMilestoneTemplate", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.settings.defaults.add_milestone_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/defaults")
+}
+
+// DeleteMilestoneTemplate response for deleting a default milestone of the organization
+func DeleteMilestoneTemplate(ctx *context.Context) {
+	if err := models.DeleteOrgMilestoneTemplate(ctx.Org.Organization.ID, ctx.QueryInt64("id")); err != nil {
+		ctx.Handle(500, "DeleteOrgMilestoneTemplate", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.settings.defaults.delete_milestone_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/defaults")
+}
+
+// ApproveModerationItem response for publishing content held for moderation
+func ApproveModerationItem(ctx *context.Context) {
+	item, err := models.GetModerationItem(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetModerationItem", models.IsErrModerationItemNotExist, err)
+		return
+	}
+
+	if err = item.Approve(); err != nil {
+		ctx.Handle(500, "Approve", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("admin.moderation.approve_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/moderation")
+}
+
+// RejectModerationItem response for discarding content held for moderation
+func RejectModerationItem(ctx *context.Context) {
+	item, err := models.GetModerationItem(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetModerationItem", models.IsErrModerationItemNotExist, err)
+		return
+	}
+
+	if err = item.Reject(); err != nil {
+		ctx.Handle(500, "Reject", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("admin.moderation.reject_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/moderation")
+}
+
+// Banner render the banner shown in repositories of the organization
+func Banner(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsSettingsBanner"] = true
+	ctx.Data["BannerDesc"] = ctx.Tr("org.settings.banner_desc")
+
+	banner, err := models.GetBanner(ctx.Org.Organization.ID)
+	if err != nil && !models.IsErrBannerNotExist(err) {
+		ctx.Handle(500, "GetBanner", err)
+		return
+	}
+	ctx.Data["Banner"] = banner
+
+	ctx.HTML(200, tplSettingsBanner)
+}
+
+// BannerPost response for updating the banner of the organization
+func BannerPost(ctx *context.Context, form auth.BannerForm) {
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(ctx.Org.OrgLink + "/settings/banner")
+		return
+	}
+
+	banner := &models.Banner{
+		OwnerID: ctx.Org.Organization.ID,
+		Message: form.Message,
+		Level:   models.BannerLevel(form.Level),
+	}
+	if len(form.Expires) > 0 {
+		expires, err := time.ParseInLocation("2006-01-02", form.Expires, time.Local)
+		if err != nil {
+			ctx.Flash.Error(ctx.Tr("admin.banner.invalid_expires_format"))
+			ctx.Redirect(ctx.Org.OrgLink + "/settings/banner")
+			return
+		}
+		banner.ExpiresUnix = time.Date(expires.Year(), expires.Month(), expires.Day(), 23, 59, 59, 0, expires.Location()).Unix()
+	}
+	if err := models.UpdateBanner(banner); err != nil {
+		ctx.Handle(500, "UpdateBanner", err)
+		return
+	}
+
+	log.Trace("Banner of organization %s updated by %s", ctx.Org.Organization.Name, ctx.User.Name)
+	ctx.Flash.Success(ctx.Tr("admin.banner.update_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/banner")
+}
+
+// DeleteBanner response for deleting the banner of the organization
+func DeleteBanner(ctx *context.Context) {
+	if err := models.DeleteBanner(ctx.Org.Organization.ID); err != nil {
+		ctx.Handle(500, "DeleteBanner", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("admin.banner.delete_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/banner")
+}
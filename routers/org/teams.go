@@ -251,6 +251,8 @@ func EditTeamPost(ctx *context.Context, form auth.CreateTeamForm) {
 		switch form.Permission {
 		case "read":
 			auth = models.AccessModeRead
+		case "triage":
+			auth = models.AccessModeTriage
 		case "write":
 			auth = models.AccessModeWrite
 		case "admin":
@@ -28,3 +28,18 @@ func GetProtectedBranchBy(ctx *macaron.Context) {
 		})
 	}
 }
+
+// GetRenamedBranch returns the renamed branch of given old name
+func GetRenamedBranch(ctx *macaron.Context) {
+	branch, err := models.GetRenamedBranch(ctx.ParamsInt64(":id"), ctx.Params("*"))
+	if err != nil {
+		ctx.JSON(500, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	} else if branch == nil {
+		ctx.Error(404)
+		return
+	}
+	ctx.JSON(200, branch)
+}
@@ -41,7 +41,12 @@ func UpdatePublicKey(ctx *macaron.Context) {
 func RegisterRoutes(m *macaron.Macaron) {
 	m.Group("/", func() {
 		m.Post("/ssh/:id/update", UpdatePublicKey)
+		m.Get("/serv/command/:keyid/:owner/:repo", ServCommand)
+		m.Post("/serv/access_log", LogAccess)
 		m.Post("/push/update", PushUpdate)
+		m.Post("/push/options", HandlePushOptions)
+		m.Post("/push/agit", HandleAgitPush)
 		m.Get("/branch/:id/*", GetProtectedBranchBy)
+		m.Get("/renamed_branch/:id/*", GetRenamedBranch)
 	}, CheckInternalToken)
 }
@@ -0,0 +1,130 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package private
+
+import (
+	"encoding/json"
+	"strings"
+
+	"code.gitea.io/git"
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+
+	macaron "gopkg.in/macaron.v1"
+)
+
+// HandlePushOptions applies the push options of a pushed branch
+func HandlePushOptions(ctx *macaron.Context) {
+	var opt models.PushOptionsRequest
+	if err := json.NewDecoder(ctx.Req.Request.Body).Decode(&opt); err != nil {
+		ctx.JSON(500, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	branch := strings.TrimPrefix(opt.RefFullName, git.BranchPrefix)
+	if len(branch) == 0 || branch == opt.RefFullName || opt.PusherID <= 0 {
+		ctx.Error(404)
+		log.Trace("HandlePushOptions: ref is not a branch, or pusher ID is not valid")
+		return
+	}
+
+	pusher, repo, ok := getPushRequestInfo(ctx, opt)
+	if !ok {
+		return
+	}
+
+	res, err := models.HandlePushOptions(pusher, repo, branch, opt.Options)
+	if err != nil {
+		ctx.JSON(500, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	if res.NewPull != nil {
+		notification.Service.NotifyIssue(res.NewPull, pusher.ID, models.NotificationEventPullRequest)
+		log.Trace("Pull request created by push: %d/%d", repo.ID, res.NewPull.ID)
+	}
+	ctx.JSON(200, res)
+}
+
+// getPushRequestInfo returns the pusher and the repository of the push
+func getPushRequestInfo(ctx *macaron.Context, opt models.PushOptionsRequest) (*models.User, *models.Repository, bool) {
+	pusher, err := models.GetUserByID(opt.PusherID)
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			ctx.Error(404)
+		} else {
+			ctx.JSON(500, map[string]interface{}{
+				"err": err.Error(),
+			})
+		}
+		return nil, nil, false
+	}
+
+	owner, err := models.GetUserByName(opt.RepoUserName)
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			ctx.Error(404)
+		} else {
+			ctx.JSON(500, map[string]interface{}{
+				"err": err.Error(),
+			})
+		}
+		return nil, nil, false
+	}
+
+	repo, err := models.GetRepositoryByName(owner.ID, opt.RepoName)
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.Error(404)
+		} else {
+			ctx.JSON(500, map[string]interface{}{
+				"err": err.Error(),
+			})
+		}
+		return nil, nil, false
+	}
+	return pusher, repo, true
+}
+
+// HandleAgitPush opens or updates the pull request of commits pushed for review
+func HandleAgitPush(ctx *macaron.Context) {
+	var opt models.PushOptionsRequest
+	if err := json.NewDecoder(ctx.Req.Request.Body).Decode(&opt); err != nil {
+		ctx.JSON(500, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	if !strings.HasPrefix(opt.RefFullName, models.AgitRefPrefix) || opt.PusherID <= 0 {
+		ctx.Error(404)
+		log.Trace("HandleAgitPush: ref is not for review, or pusher ID is not valid")
+		return
+	}
+
+	pusher, repo, ok := getPushRequestInfo(ctx, opt)
+	if !ok {
+		return
+	}
+
+	res, err := models.HandleAgitPush(pusher, repo, opt.RefFullName, opt.NewCommitID, opt.Options)
+	if err != nil {
+		ctx.JSON(500, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	if res.NewPull != nil {
+		notification.Service.NotifyIssue(res.NewPull, pusher.ID, models.NotificationEventPullRequest)
+		log.Trace("Pull request created by push for review: %d/%d", repo.ID, res.NewPull.ID)
+	}
+	ctx.JSON(200, res)
+}
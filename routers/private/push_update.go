@@ -56,5 +56,8 @@ func PushUpdate(ctx *macaron.Context) {
 
 	go models.HookQueue.Add(repo.ID)
 	go models.AddTestPullRequestTask(pusher, repo.ID, branch, true)
+	if branch == repo.DefaultBranch {
+		go models.AddLanguageStatsTask(repo.ID)
+	}
 	ctx.Status(202)
 }
@@ -0,0 +1,268 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package private
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/private"
+
+	macaron "gopkg.in/macaron.v1"
+)
+
+const (
+	servAccessDenied  = "Repository does not exist or you do not have access"
+	servUserSuspended = "Your account is suspended"
+)
+
+// servCommandFail responds with the message shown to the user of the serv
+// command, the reason is only logged.
+func servCommandFail(ctx *macaron.Context, status int, userMessage, logMessage string, args ...interface{}) {
+	if status == 500 {
+		log.Error(4, "ServCommand: "+logMessage, args...)
+	} else {
+		log.Warn("ServCommand: "+logMessage, args...)
+	}
+	ctx.JSON(status, map[string]interface{}{
+		"err": userMessage,
+	})
+}
+
+// ServCommand checks the access of a key to a repository for a serv command
+func ServCommand(ctx *macaron.Context) {
+	keyID := ctx.ParamsInt64(":keyid")
+	ownerName := strings.ToLower(ctx.Params(":owner"))
+	repoName := strings.ToLower(ctx.Params(":repo"))
+	requestedMode := models.AccessMode(ctx.QueryInt("mode"))
+	verb := ctx.Query("verb")
+
+	res := &private.ServCommandResults{
+		OwnerName: ownerName,
+		RepoName:  repoName,
+	}
+	unitType := models.UnitTypeCode
+	if strings.HasSuffix(repoName, ".wiki") {
+		res.IsWiki = true
+		res.RepoName = repoName[:len(repoName)-5]
+		unitType = models.UnitTypeWiki
+	}
+
+	owner, err := models.GetUserByName(ownerName)
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			servCommandFail(ctx, 404, "Repository owner does not exist", "Unregistered owner: %s", ownerName)
+		} else {
+			servCommandFail(ctx, 500, "Internal error", "Failed to get repository owner (%s): %v", ownerName, err)
+		}
+		return
+	}
+	res.OwnerName = owner.Name
+
+	repo, err := models.GetRepositoryByName(owner.ID, res.RepoName)
+	if err != nil {
+		if !models.IsErrRepoNotExist(err) {
+			servCommandFail(ctx, 500, "Internal error", "Failed to get repository: %v", err)
+			return
+		} else if verb != "git-receive-pack" || res.IsWiki || !models.IsPushCreateEnabled(owner) {
+			servCommandFail(ctx, 404, servAccessDenied, "Repository does not exist: %s/%s", owner.Name, res.RepoName)
+			return
+		}
+		if repo = servPushCreateRepo(ctx, keyID, owner, res.RepoName); repo == nil {
+			return
+		}
+		res.IsCreated = true
+	}
+	res.RepoID = repo.ID
+	res.RepoName = repo.Name
+	res.IsPrivate = repo.IsPrivate
+	res.IsEmpty = res.IsCreated || repo.IsBare
+
+	if res.IsWiki && !repo.EnableUnit(models.UnitTypeWiki) {
+		servCommandFail(ctx, 404, servAccessDenied, "Repository wiki is disabled: %s/%s", owner.Name, repo.Name)
+		return
+	}
+
+	// Prohibit push to mirror repositories, their wiki is not mirrored.
+	if requestedMode > models.AccessModeRead && repo.IsMirror && !res.IsWiki {
+		servCommandFail(ctx, 403, "mirror repository is read-only", "Push to mirror repository: %s/%s", owner.Name, repo.Name)
+		return
+	}
+
+	// Allow anonymous clone for public repositories.
+	if requestedMode == models.AccessModeWrite || repo.IsPrivate {
+		key, err := models.GetPublicKeyByID(keyID)
+		if err != nil {
+			servCommandFail(ctx, 403, "Invalid key ID", "Invalid key ID[%d]: %v", keyID, err)
+			return
+		}
+		res.KeyID = key.ID
+
+		// Check deploy key or user key.
+		if key.Type == models.KeyTypeDeploy {
+			if !servDeployKey(ctx, key, repo, requestedMode) {
+				return
+			}
+			res.IsDeployKey = true
+		} else {
+			user, err := models.GetUserByKeyID(key.ID)
+			if err != nil {
+				if models.IsErrUserNotExist(err) {
+					servCommandFail(ctx, 403, "Invalid key ID", "Invalid key ID[%d]: %v", keyID, err)
+				} else {
+					servCommandFail(ctx, 500, "internal error", "Failed to get user by key ID(%d): %v", key.ID, err)
+				}
+				return
+			} else if user.IsSuspended {
+				servCommandFail(ctx, 403, servUserSuspended, "User %s is suspended", user.Name)
+				return
+			}
+
+			mode, err := models.UnitAccessLevel(user.ID, repo, unitType)
+			if err != nil {
+				servCommandFail(ctx, 500, "Internal error", "Failed to check access: %v", err)
+				return
+			} else if mode < requestedMode && verb == "git-receive-pack" && !res.IsWiki &&
+				mode >= models.AccessModeRead && repo.AllowsPulls() {
+				// Users who can read the code can push commits for review.
+				res.ForReviewOnly = true
+			} else if mode < requestedMode {
+				clientMessage := servAccessDenied
+				if mode >= models.AccessModeRead {
+					clientMessage = "You do not have sufficient authorization for this action"
+				}
+				servCommandFail(ctx, 403, clientMessage,
+					"User %s does not have level %v access to repository %s/%s",
+					user.Name, requestedMode, owner.Name, repo.Name)
+				return
+			}
+
+			if !repo.CheckUnitUser(user.ID, user.IsAdmin, unitType) {
+				servCommandFail(ctx, 403, "You do not have allowed for this action",
+					"User %s does not have allowed access to repository %s/%s 's code",
+					user.Name, owner.Name, repo.Name)
+				return
+			}
+
+			res.UserID = user.ID
+			res.UserName = user.Name
+		}
+	}
+
+	if res.IsWiki {
+		if err = repo.InitWiki(); err != nil {
+			servCommandFail(ctx, 500, "Internal error", "Failed to init wiki repo: %v", err)
+			return
+		}
+	}
+
+	ctx.JSON(200, res)
+}
+
+// servPushCreateRepo creates the repository the owner of the key pushes to,
+// it returns nil if the user is not allowed to.
+func servPushCreateRepo(ctx *macaron.Context, keyID int64, owner *models.User, name string) *models.Repository {
+	key, err := models.GetPublicKeyByID(keyID)
+	if err != nil {
+		servCommandFail(ctx, 403, "Invalid key ID", "Invalid key ID[%d]: %v", keyID, err)
+		return nil
+	} else if key.Type == models.KeyTypeDeploy {
+		servCommandFail(ctx, 404, servAccessDenied, "Repository does not exist: %s/%s", owner.Name, name)
+		return nil
+	}
+
+	user, err := models.GetUserByKeyID(key.ID)
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			servCommandFail(ctx, 403, "Invalid key ID", "Invalid key ID[%d]: %v", keyID, err)
+		} else {
+			servCommandFail(ctx, 500, "Internal error", "Failed to get user by key ID(%d): %v", key.ID, err)
+		}
+		return nil
+	} else if user.IsSuspended {
+		servCommandFail(ctx, 403, servUserSuspended, "User %s is suspended", user.Name)
+		return nil
+	}
+
+	repo, err := models.PushCreateRepo(user, owner, name)
+	if err != nil {
+		if models.IsErrPushCreateNotAllowed(err) {
+			servCommandFail(ctx, 404, servAccessDenied, "User %s cannot create repository %s/%s by push", user.Name, owner.Name, name)
+		} else if models.IsErrReachLimitOfRepo(err) || models.IsErrRepoAlreadyExist(err) ||
+			models.IsErrNameReserved(err) || models.IsErrNamePatternNotAllowed(err) {
+			servCommandFail(ctx, 403, "Repository cannot be created: "+err.Error(), "PushCreateRepo: %v", err)
+		} else {
+			servCommandFail(ctx, 500, "Internal error", "PushCreateRepo: %v", err)
+		}
+		return nil
+	}
+	return repo
+}
+
+// servDeployKey checks the access of a deploy key to the repository, and
+// updates its activity.
+func servDeployKey(ctx *macaron.Context, key *models.PublicKey, repo *models.Repository, requestedMode models.AccessMode) bool {
+	if key.Mode < requestedMode {
+		servCommandFail(ctx, 403, "Key permission denied", "Cannot push with deployment key: %d", key.ID)
+		return false
+	}
+	// Check if this deploy key belongs to current repository.
+	if !models.HasDeployKey(key.ID, repo.ID) {
+		servCommandFail(ctx, 403, "Key access denied", "Deploy key access denied: [key_id: %d, repo_id: %d]", key.ID, repo.ID)
+		return false
+	}
+
+	// Update deploy key activity.
+	deployKey, err := models.GetDeployKeyByRepo(key.ID, repo.ID)
+	if err != nil {
+		servCommandFail(ctx, 500, "Internal error", "GetDeployKey: %v", err)
+		return false
+	}
+
+	deployKey.Updated = time.Now()
+	if err = models.UpdateDeployKey(deployKey); err != nil {
+		servCommandFail(ctx, 500, "Internal error", "UpdateDeployKey: %v", err)
+		return false
+	}
+	return true
+}
+
+// LogAccess records an operation on a repository over SSH, or a push by the
+// hooks
+func LogAccess(ctx *macaron.Context) {
+	var l models.RepoAccessLog
+	if err := json.NewDecoder(ctx.Req.Request.Body).Decode(&l); err != nil {
+		ctx.JSON(500, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+	l.ID = 0
+
+	repo, err := models.GetRepositoryByID(l.RepoID)
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.Error(404)
+		} else {
+			ctx.JSON(500, map[string]interface{}{
+				"err": fmt.Sprintf("GetRepositoryByID: %v", err),
+			})
+		}
+		return
+	}
+
+	if err = repo.LogAccess(&l); err != nil {
+		ctx.JSON(500, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	ctx.PlainText(200, []byte("success"))
+}
@@ -0,0 +1,327 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"strings"
+
+	"github.com/Unknwon/com"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/markdown"
+)
+
+const (
+	tplAdvisories   base.TplName = "repo/advisory/list"
+	tplAdvisoryNew  base.TplName = "repo/advisory/new"
+	tplAdvisoryView base.TplName = "repo/advisory/view"
+)
+
+// prepareAdvisoryCtx sets the data shared by the security advisory pages.
+// Advisories are maintained by the administrators of the repository, who
+// also own the temporary forks created for the fixes.
+func prepareAdvisoryCtx(ctx *context.Context, title string) {
+	ctx.Data["Title"] = ctx.Tr(title)
+	ctx.Data["PageIsAdvisories"] = true
+	ctx.Data["AdvisoriesLink"] = ctx.Repo.RepoLink + "/security/advisories"
+	ctx.Data["IsAdvisoryMaintainer"] = ctx.Repo.IsAdmin()
+	ctx.Data["AdvisorySeverities"] = models.AdvisorySeverities
+}
+
+// getAdvisory returns the advisory of the request if the user can see it,
+// drafts are only visible to the maintainers and the collaborators of the
+// advisory. canComment is true if the user can take part in the discussion.
+func getAdvisory(ctx *context.Context) (advisory *models.RepoAdvisory, canComment bool) {
+	advisory, err := models.GetRepoAdvisoryByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetRepoAdvisoryByIndex", models.IsErrRepoAdvisoryNotExist, err)
+		return nil, false
+	}
+	advisory.Repo = ctx.Repo.Repository
+
+	canComment = ctx.Repo.IsAdmin()
+	if !canComment && ctx.IsSigned {
+		if canComment, err = advisory.IsCollaborator(ctx.User.ID); err != nil {
+			ctx.Handle(500, "IsCollaborator", err)
+			return nil, false
+		}
+	}
+	if !canComment && !advisory.IsPublished() {
+		ctx.Handle(404, "GetRepoAdvisoryByIndex", nil)
+		return nil, false
+	}
+
+	ctx.Data["Advisory"] = advisory
+	ctx.Data["AdvisoryLink"] = ctx.Repo.RepoLink + "/security/advisories/" + com.ToStr(advisory.Index)
+	return advisory, canComment && advisory.IsDraft()
+}
+
+// Advisories render the security advisories page of a repository
+func Advisories(ctx *context.Context) {
+	prepareAdvisoryCtx(ctx, "repo.advisories")
+
+	advisories, err := models.GetRepoAdvisories(ctx.Repo.Repository.ID, ctx.User, ctx.Repo.IsAdmin())
+	if err != nil {
+		ctx.Handle(500, "GetRepoAdvisories", err)
+		return
+	}
+	ctx.Data["Advisories"] = advisories
+
+	ctx.HTML(200, tplAdvisories)
+}
+
+// NewAdvisory render drafting security advisory page
+func NewAdvisory(ctx *context.Context) {
+	prepareAdvisoryCtx(ctx, "repo.advisories.new")
+	ctx.HTML(200, tplAdvisoryNew)
+}
+
+// validateAdvisoryForm checks the fields of the form the binding cannot.
+func validateAdvisoryForm(ctx *context.Context, form auth.RepoAdvisoryForm) bool {
+	if ctx.HasError() {
+		ctx.HTML(200, tplAdvisoryNew)
+		return false
+	}
+	if !models.IsValidCVEID(strings.TrimSpace(form.CVEID)) {
+		ctx.Data["Err_CVEID"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.advisories.invalid_cve_id"), tplAdvisoryNew, &form)
+		return false
+	}
+	return true
+}
+
+// NewAdvisoryPost response for drafting security advisory
+func NewAdvisoryPost(ctx *context.Context, form auth.RepoAdvisoryForm) {
+	prepareAdvisoryCtx(ctx, "repo.advisories.new")
+	if !validateAdvisoryForm(ctx, form) {
+		return
+	}
+
+	advisory := &models.RepoAdvisory{
+		RepoID:           ctx.Repo.Repository.ID,
+		PosterID:         ctx.User.ID,
+		Title:            form.Title,
+		Description:      form.Description,
+		Severity:         form.Severity,
+		CVEID:            strings.TrimSpace(form.CVEID),
+		AffectedVersions: form.AffectedVersions,
+		PatchedVersions:  form.PatchedVersions,
+	}
+	if err := models.NewRepoAdvisory(advisory); err != nil {
+		ctx.Handle(500, "NewRepoAdvisory", err)
+		return
+	}
+
+	log.Trace("Security advisory drafted: %d/%d", ctx.Repo.Repository.ID, advisory.Index)
+	ctx.Redirect(ctx.Repo.RepoLink + "/security/advisories/" + com.ToStr(advisory.Index))
+}
+
+// ViewAdvisory render a security advisory with its private discussion
+func ViewAdvisory(ctx *context.Context) {
+	prepareAdvisoryCtx(ctx, "repo.advisories")
+	advisory, canComment := getAdvisory(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := advisory.LoadAttributes(); err != nil {
+		ctx.Handle(500, "LoadAttributes", err)
+		return
+	}
+	ctx.Data["Title"] = advisory.Title
+	ctx.Data["CanComment"] = canComment
+
+	metas := ctx.Repo.Repository.ComposeMetas()
+	ctx.Data["RenderedDescription"] = markdown.RenderString(advisory.Description, ctx.Repo.RepoLink, metas)
+
+	if ctx.Repo.IsAdmin() || canComment {
+		comments, err := advisory.GetComments()
+		if err != nil {
+			ctx.Handle(500, "GetComments", err)
+			return
+		}
+		for _, c := range comments {
+			c.Content = markdown.RenderString(c.Content, ctx.Repo.RepoLink, metas)
+		}
+		ctx.Data["Comments"] = comments
+
+		collaborators, err := advisory.GetCollaborators()
+		if err != nil {
+			ctx.Handle(500, "GetCollaborators", err)
+			return
+		}
+		ctx.Data["Collaborators"] = collaborators
+	}
+
+	ctx.HTML(200, tplAdvisoryView)
+}
+
+// EditAdvisory render editing security advisory page
+func EditAdvisory(ctx *context.Context) {
+	prepareAdvisoryCtx(ctx, "repo.advisories.edit")
+	ctx.Data["PageIsEditAdvisory"] = true
+	advisory, _ := getAdvisory(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.Data["title"] = advisory.Title
+	ctx.Data["description"] = advisory.Description
+	ctx.Data["severity"] = advisory.Severity
+	ctx.Data["cve_id"] = advisory.CVEID
+	ctx.Data["affected_versions"] = advisory.AffectedVersions
+	ctx.Data["patched_versions"] = advisory.PatchedVersions
+	ctx.HTML(200, tplAdvisoryNew)
+}
+
+// EditAdvisoryPost response for editing security advisory
+func EditAdvisoryPost(ctx *context.Context, form auth.RepoAdvisoryForm) {
+	prepareAdvisoryCtx(ctx, "repo.advisories.edit")
+	ctx.Data["PageIsEditAdvisory"] = true
+	advisory, _ := getAdvisory(ctx)
+	if ctx.Written() || !validateAdvisoryForm(ctx, form) {
+		return
+	}
+
+	advisory.Title = form.Title
+	advisory.Description = form.Description
+	advisory.Severity = form.Severity
+	advisory.CVEID = strings.TrimSpace(form.CVEID)
+	advisory.AffectedVersions = form.AffectedVersions
+	advisory.PatchedVersions = form.PatchedVersions
+	if err := models.UpdateRepoAdvisory(advisory); err != nil {
+		ctx.Handle(500, "UpdateRepoAdvisory", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.advisories.edit_success"))
+	ctx.Redirect(ctx.Data["AdvisoryLink"].(string))
+}
+
+// NewAdvisoryComment response for commenting on a draft security advisory
+func NewAdvisoryComment(ctx *context.Context, form auth.CreateCommentForm) {
+	advisory, canComment := getAdvisory(ctx)
+	if ctx.Written() {
+		return
+	} else if !canComment {
+		ctx.Error(403)
+		return
+	}
+
+	if len(strings.TrimSpace(form.Content)) > 0 {
+		if _, err := advisory.CreateComment(ctx.User, form.Content); err != nil {
+			ctx.Handle(500, "CreateComment", err)
+			return
+		}
+	}
+	ctx.Redirect(ctx.Data["AdvisoryLink"].(string))
+}
+
+// AddAdvisoryCollaborator response for inviting a user to a draft security advisory
+func AddAdvisoryCollaborator(ctx *context.Context) {
+	advisory, _ := getAdvisory(ctx)
+	if ctx.Written() {
+		return
+	}
+	link := ctx.Data["AdvisoryLink"].(string)
+
+	u, err := models.GetUserByName(strings.ToLower(ctx.Query("collaborator")))
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("form.user_not_exist"))
+			ctx.Redirect(link)
+		} else {
+			ctx.Handle(500, "GetUserByName", err)
+		}
+		return
+	} else if u.IsOrganization() {
+		ctx.Flash.Error(ctx.Tr("form.user_not_exist"))
+		ctx.Redirect(link)
+		return
+	}
+
+	if err = advisory.AddCollaborator(u); err != nil {
+		ctx.Handle(500, "AddCollaborator", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.advisories.add_collaborator_success", u.Name))
+	ctx.Redirect(link)
+}
+
+// DeleteAdvisoryCollaborator response for removing a user from a security advisory
+func DeleteAdvisoryCollaborator(ctx *context.Context) {
+	advisory, _ := getAdvisory(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := advisory.DeleteCollaborator(ctx.QueryInt64("id")); err != nil {
+		ctx.Flash.Error("DeleteCollaborator: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.advisories.remove_collaborator_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Data["AdvisoryLink"].(string),
+	})
+}
+
+// AdvisoryFork response for creating the temporary private fork of a security advisory
+func AdvisoryFork(ctx *context.Context) {
+	advisory, _ := getAdvisory(ctx)
+	if ctx.Written() {
+		return
+	}
+	link := ctx.Data["AdvisoryLink"].(string)
+
+	if !advisory.IsDraft() {
+		ctx.Redirect(link)
+		return
+	}
+	if err := advisory.CreateFork(); err != nil {
+		if models.IsErrRepoAlreadyExist(err) {
+			ctx.Flash.Error(ctx.Tr("repo.advisories.fork_exists"))
+			ctx.Redirect(link)
+		} else {
+			ctx.Handle(500, "CreateFork", err)
+		}
+		return
+	}
+
+	log.Trace("Security advisory fork created: %d/%d", ctx.Repo.Repository.ID, advisory.ForkID)
+	ctx.Flash.Success(ctx.Tr("repo.advisories.fork_success"))
+	ctx.Redirect(link)
+}
+
+// ChangeAdvisoryStatus response for publishing or closing a draft security advisory
+func ChangeAdvisoryStatus(ctx *context.Context) {
+	advisory, _ := getAdvisory(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	var err error
+	switch ctx.Params(":action") {
+	case "publish":
+		if err = advisory.Publish(); err == nil {
+			ctx.Flash.Success(ctx.Tr("repo.advisories.publish_success"))
+		}
+	case "close":
+		if err = advisory.Close(); err == nil {
+			ctx.Flash.Success(ctx.Tr("repo.advisories.close_success"))
+		}
+	}
+	if models.IsErrRepoAdvisoryUnmerged(err) {
+		ctx.Flash.Error(ctx.Tr("repo.advisories.publish_unmerged", strings.Join(err.(models.ErrRepoAdvisoryUnmerged).Branches, ", ")))
+	} else if err != nil {
+		ctx.Handle(500, "ChangeAdvisoryStatus", err)
+		return
+	}
+	ctx.Redirect(ctx.Data["AdvisoryLink"].(string))
+}
@@ -7,9 +7,11 @@ package repo
 import (
 	"fmt"
 	"net/http"
+	"path"
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -23,50 +25,119 @@ func renderAttachmentSettings(ctx *context.Context) {
 	ctx.Data["AttachmentMaxFiles"] = setting.AttachmentMaxFiles
 }
 
-// UploadAttachment response for uploading issue's attachment
-func UploadAttachment(ctx *context.Context) {
+// isFileTypeAllowed returns true if the content type detected from the start
+// of a file is one of allowedTypes, whatever type the client tells. A type
+// like "image/*" allows all its subtypes and "*/*" allows all types.
+func isFileTypeAllowed(buf []byte, allowedTypes []string) bool {
+	fileType := strings.TrimSpace(strings.SplitN(http.DetectContentType(buf), ";", 2)[0])
+	for _, t := range allowedTypes {
+		t := strings.ToLower(strings.Trim(t, " "))
+		if t == "*/*" || t == fileType ||
+			(strings.HasSuffix(t, "/*") && strings.HasPrefix(fileType, strings.TrimSuffix(t, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAttachmentExtensionBlocked returns true if the extension of the name of
+// an attachment is blocked, trailing dots and spaces ignored as Windows does.
+func isAttachmentExtensionBlocked(name string) bool {
+	ext := strings.ToLower(path.Ext(strings.TrimRight(name, ". ")))
+	for _, blocked := range setting.AttachmentBlockedExtensions {
+		if ext == blocked {
+			return true
+		}
+	}
+	return false
+}
+
+// attachmentQuotaMessage returns the message telling which attachment quota
+// err is about.
+func attachmentQuotaMessage(ctx *context.Context, err models.ErrAttachmentQuotaExceeded) string {
+	if err.IssueID > 0 {
+		return ctx.Tr("repo.issues.attachment.issue_quota_exceeded", base.FileSize(err.Quota))
+	}
+	return ctx.Tr("repo.issues.attachment.user_quota_exceeded", base.FileSize(err.Quota))
+}
+
+// uploadAttachment stores the file of the request as an attachment
+func uploadAttachment(ctx *context.Context) (*models.Attachment, string) {
 	if !setting.AttachmentEnabled {
 		ctx.Error(404, "attachment is not enabled")
-		return
+		return nil, ""
 	}
 
 	file, header, err := ctx.Req.FormFile("file")
 	if err != nil {
 		ctx.Error(500, fmt.Sprintf("FormFile: %v", err))
-		return
+		return nil, ""
 	}
 	defer file.Close()
 
+	if isAttachmentExtensionBlocked(header.Filename) {
+		ctx.Error(400, ErrFileTypeForbidden.Error())
+		return nil, ""
+	}
+
 	buf := make([]byte, 1024)
 	n, _ := file.Read(buf)
 	if n > 0 {
 		buf = buf[:n]
 	}
-	fileType := http.DetectContentType(buf)
+	if !isFileTypeAllowed(buf, strings.Split(setting.AttachmentAllowedTypes, ",")) {
+		ctx.Error(400, ErrFileTypeForbidden.Error())
+		return nil, ""
+	}
 
-	allowedTypes := strings.Split(setting.AttachmentAllowedTypes, ",")
-	allowed := false
-	for _, t := range allowedTypes {
-		t := strings.Trim(t, " ")
-		if t == "*/*" || t == fileType {
-			allowed = true
-			break
+	var uploaderID int64
+	if ctx.IsSigned {
+		uploaderID = ctx.User.ID
+	}
+	attach, err := models.NewAttachment(uploaderID, header.Filename, buf, file)
+	if err != nil {
+		if models.IsErrAttachmentQuotaExceeded(err) {
+			ctx.Error(413, attachmentQuotaMessage(ctx, err.(models.ErrAttachmentQuotaExceeded)))
+		} else {
+			ctx.Error(500, fmt.Sprintf("NewAttachment: %v", err))
 		}
+		return nil, ""
 	}
 
-	if !allowed {
-		ctx.Error(400, ErrFileTypeForbidden.Error())
+	log.Trace("New attachment uploaded: %s", attach.UUID)
+	return attach, http.DetectContentType(buf)
+}
+
+// UploadAttachment response for uploading issue's attachment
+func UploadAttachment(ctx *context.Context) {
+	attach, _ := uploadAttachment(ctx)
+	if ctx.Written() {
 		return
 	}
+	ctx.JSON(200, map[string]string{
+		"uuid": attach.UUID,
+	})
+}
 
-	attach, err := models.NewAttachment(header.Filename, buf, file)
-	if err != nil {
-		ctx.Error(500, fmt.Sprintf("NewAttachment: %v", err))
+// UploadMarkdownAttachment response for uploading a file pasted or dropped
+// into a markdown editor, the attachment is associated with the issue or
+// comment whose content links to it when it is saved.
+func UploadMarkdownAttachment(ctx *context.Context) {
+	attach, fileType := uploadAttachment(ctx)
+	if ctx.Written() {
 		return
 	}
 
-	log.Trace("New attachment uploaded: %s", attach.UUID)
+	url := attach.DownloadURL()
+	name := strings.NewReplacer("[", "", "]", "").Replace(attach.Name)
+	markdown := fmt.Sprintf("[%s](%s)", name, url)
+	if strings.HasPrefix(fileType, "image/") {
+		markdown = "!" + markdown
+	}
 	ctx.JSON(200, map[string]string{
-		"uuid": attach.UUID,
+		"uuid":     attach.UUID,
+		"name":     attach.Name,
+		"url":      url,
+		"markdown": markdown,
 	})
 }
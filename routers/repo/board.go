@@ -0,0 +1,477 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"strings"
+
+	"github.com/Unknwon/com"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+)
+
+const (
+	tplBoards       base.TplName = "repo/board/list"
+	tplBoardNew     base.TplName = "repo/board/new"
+	tplBoardView    base.TplName = "repo/board/view"
+	tplOrgBoards    base.TplName = "org/board/list"
+	tplOrgBoardNew  base.TplName = "org/board/new"
+	tplOrgBoardView base.TplName = "org/board/view"
+)
+
+type boardCtx struct {
+	OrgID        int64
+	RepoID       int64
+	Link         string
+	CanWrite     bool
+	ListTemplate base.TplName
+	NewTemplate  base.TplName
+	ViewTemplate base.TplName
+}
+
+// getBoardCtx determines whether the boards of a repository or of an organization are requested.
+func getBoardCtx(ctx *context.Context) *boardCtx {
+	if len(ctx.Repo.RepoLink) > 0 {
+		return &boardCtx{
+			RepoID:       ctx.Repo.Repository.ID,
+			Link:         ctx.Repo.RepoLink + "/boards",
+			CanWrite:     ctx.Repo.CanWrite(models.UnitTypeIssues),
+			ListTemplate: tplBoards,
+			NewTemplate:  tplBoardNew,
+			ViewTemplate: tplBoardView,
+		}
+	}
+
+	return &boardCtx{
+		OrgID:        ctx.Org.Organization.ID,
+		Link:         ctx.Org.OrgLink + "/boards",
+		CanWrite:     ctx.Org.IsMember,
+		ListTemplate: tplOrgBoards,
+		NewTemplate:  tplOrgBoardNew,
+		ViewTemplate: tplOrgBoardView,
+	}
+}
+
+func prepareBoardCtx(ctx *context.Context, title string) *boardCtx {
+	bCtx := getBoardCtx(ctx)
+	ctx.Data["Title"] = ctx.Tr(title)
+	ctx.Data["PageIsIssueList"] = bCtx.RepoID > 0
+	ctx.Data["PageIsBoards"] = true
+	ctx.Data["BoardsLink"] = bCtx.Link
+	ctx.Data["CanWriteBoards"] = bCtx.CanWrite
+	return bCtx
+}
+
+func getBoard(ctx *context.Context, bCtx *boardCtx) *models.Board {
+	var (
+		board *models.Board
+		err   error
+	)
+	if bCtx.RepoID > 0 {
+		board, err = models.GetBoardByRepoID(bCtx.RepoID, ctx.ParamsInt64(":id"))
+	} else {
+		board, err = models.GetBoardByOrgID(bCtx.OrgID, ctx.ParamsInt64(":id"))
+	}
+	if err != nil {
+		if models.IsErrBoardNotExist(err) {
+			ctx.Handle(404, "GetBoard", nil)
+		} else {
+			ctx.Handle(500, "GetBoard", err)
+		}
+		return nil
+	}
+	ctx.Data["Board"] = board
+	ctx.Data["BoardLink"] = bCtx.Link + "/" + com.ToStr(board.ID)
+	return board
+}
+
+// Boards render issue boards page
+func Boards(ctx *context.Context) {
+	bCtx := prepareBoardCtx(ctx, "repo.boards")
+
+	var (
+		boards []*models.Board
+		err    error
+	)
+	if bCtx.RepoID > 0 {
+		boards, err = models.GetBoardsByRepoID(bCtx.RepoID)
+	} else {
+		boards, err = models.GetBoardsByOrgID(bCtx.OrgID)
+	}
+	if err != nil {
+		ctx.Handle(500, "GetBoards", err)
+		return
+	}
+	ctx.Data["Boards"] = boards
+
+	ctx.HTML(200, bCtx.ListTemplate)
+}
+
+// NewBoard render creating issue board page
+func NewBoard(ctx *context.Context) {
+	bCtx := prepareBoardCtx(ctx, "repo.boards.new")
+	ctx.HTML(200, bCtx.NewTemplate)
+}
+
+// NewBoardPost response for creating issue board
+func NewBoardPost(ctx *context.Context, form auth.CreateBoardForm) {
+	bCtx := prepareBoardCtx(ctx, "repo.boards.new")
+
+	if ctx.HasError() {
+		ctx.HTML(200, bCtx.NewTemplate)
+		return
+	}
+
+	board := &models.Board{
+		RepoID:      bCtx.RepoID,
+		OrgID:       bCtx.OrgID,
+		Title:       form.Title,
+		Description: form.Description,
+		CreatorID:   ctx.User.ID,
+	}
+	if err := models.NewBoard(board); err != nil {
+		ctx.Handle(500, "NewBoard", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.boards.create_success", form.Title))
+	ctx.Redirect(bCtx.Link + "/" + com.ToStr(board.ID))
+}
+
+// EditBoard render editing issue board page
+func EditBoard(ctx *context.Context) {
+	bCtx := prepareBoardCtx(ctx, "repo.boards.edit")
+	ctx.Data["PageIsEditBoard"] = true
+
+	board := getBoard(ctx, bCtx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["title"] = board.Title
+	ctx.Data["description"] = board.Description
+
+	ctx.HTML(200, bCtx.NewTemplate)
+}
+
+// EditBoardPost response for editing issue board
+func EditBoardPost(ctx *context.Context, form auth.CreateBoardForm) {
+	bCtx := prepareBoardCtx(ctx, "repo.boards.edit")
+	ctx.Data["PageIsEditBoard"] = true
+
+	board := getBoard(ctx, bCtx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(200, bCtx.NewTemplate)
+		return
+	}
+
+	board.Title = form.Title
+	board.Description = form.Description
+	if err := models.UpdateBoard(board); err != nil {
+		ctx.Handle(500, "UpdateBoard", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.boards.edit_success", board.Title))
+	ctx.Redirect(bCtx.Link + "/" + com.ToStr(board.ID))
+}
+
+// DeleteBoard delete an issue board
+func DeleteBoard(ctx *context.Context) {
+	bCtx := getBoardCtx(ctx)
+
+	var (
+		board *models.Board
+		err   error
+	)
+	if bCtx.RepoID > 0 {
+		board, err = models.GetBoardByRepoID(bCtx.RepoID, ctx.QueryInt64("id"))
+	} else {
+		board, err = models.GetBoardByOrgID(bCtx.OrgID, ctx.QueryInt64("id"))
+	}
+	if err == nil {
+		err = models.DeleteBoard(board)
+	}
+	if err != nil {
+		ctx.Flash.Error("DeleteBoard: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.boards.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": bCtx.Link,
+	})
+}
+
+// ViewBoard render an issue board with its columns and cards
+func ViewBoard(ctx *context.Context) {
+	bCtx := prepareBoardCtx(ctx, "repo.boards")
+	ctx.Data["PageIsViewBoard"] = true
+
+	board := getBoard(ctx, bCtx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Title"] = board.Title
+
+	if err := board.LoadColumns(); err != nil {
+		ctx.Handle(500, "LoadColumns", err)
+		return
+	}
+
+	// Cards of organization boards may come from private repositories
+	// the user has no access to.
+	if bCtx.OrgID > 0 {
+		canRead := make(map[int64]bool)
+		for _, column := range board.Columns {
+			cards := column.Cards[:0]
+			for _, card := range column.Cards {
+				repo := card.Issue.Repo
+				has, ok := canRead[repo.ID]
+				if !ok {
+					var err error
+					has, err = models.HasAccess(ctx.User.ID, repo, models.AccessModeRead)
+					if err != nil {
+						ctx.Handle(500, "HasAccess", err)
+						return
+					}
+					canRead[repo.ID] = has
+				}
+				if has {
+					cards = append(cards, card)
+				}
+			}
+			column.Cards = cards
+		}
+	}
+
+	ctx.HTML(200, bCtx.ViewTemplate)
+}
+
+func getBoardColumn(ctx *context.Context, board *models.Board, id int64) *models.BoardColumn {
+	column, err := board.GetColumn(id)
+	if err != nil {
+		if models.IsErrBoardColumnNotExist(err) {
+			ctx.Handle(404, "GetColumn", nil)
+		} else {
+			ctx.Handle(500, "GetColumn", err)
+		}
+		return nil
+	}
+	return column
+}
+
+// NewBoardColumnPost response for adding a column to an issue board
+func NewBoardColumnPost(ctx *context.Context, form auth.BoardColumnForm) {
+	bCtx := getBoardCtx(ctx)
+	board := getBoard(ctx, bCtx)
+	if ctx.Written() {
+		return
+	}
+	boardLink := bCtx.Link + "/" + com.ToStr(board.ID)
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(boardLink)
+		return
+	}
+
+	if err := models.NewBoardColumn(&models.BoardColumn{
+		BoardID:  board.ID,
+		Title:    form.Title,
+		IsClosed: form.IsClosed,
+	}); err != nil {
+		ctx.Handle(500, "NewBoardColumn", err)
+		return
+	}
+	ctx.Redirect(boardLink)
+}
+
+// EditBoardColumnPost response for editing a column of an issue board
+func EditBoardColumnPost(ctx *context.Context, form auth.BoardColumnForm) {
+	bCtx := getBoardCtx(ctx)
+	board := getBoard(ctx, bCtx)
+	if ctx.Written() {
+		return
+	}
+	boardLink := bCtx.Link + "/" + com.ToStr(board.ID)
+
+	column := getBoardColumn(ctx, board, ctx.ParamsInt64(":columnID"))
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(boardLink)
+		return
+	}
+
+	column.Title = form.Title
+	column.IsClosed = form.IsClosed
+	if err := models.UpdateBoardColumn(column); err != nil {
+		ctx.Handle(500, "UpdateBoardColumn", err)
+		return
+	}
+	ctx.Redirect(boardLink)
+}
+
+// DeleteBoardColumn delete a column of an issue board
+func DeleteBoardColumn(ctx *context.Context) {
+	bCtx := getBoardCtx(ctx)
+	board := getBoard(ctx, bCtx)
+	if ctx.Written() {
+		return
+	}
+
+	column := getBoardColumn(ctx, board, ctx.QueryInt64("id"))
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteBoardColumn(column); err != nil {
+		if models.IsErrBoardLastColumn(err) {
+			ctx.Flash.Error(ctx.Tr("repo.boards.column_last"))
+		} else {
+			ctx.Flash.Error("DeleteBoardColumn: " + err.Error())
+		}
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.boards.column_deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": bCtx.Link + "/" + com.ToStr(board.ID),
+	})
+}
+
+// SortBoardColumns response for reordering the columns of an issue board
+func SortBoardColumns(ctx *context.Context) {
+	board := getBoard(ctx, getBoardCtx(ctx))
+	if ctx.Written() {
+		return
+	}
+
+	ids, err := base.StringsToInt64s(strings.Split(ctx.Query("ids"), ","))
+	if err != nil {
+		ctx.Error(422)
+		return
+	}
+	if err = board.SortColumns(ids); err != nil {
+		ctx.Handle(500, "SortColumns", err)
+		return
+	}
+	ctx.Status(200)
+}
+
+// findBoardIssue returns the issue referenced by "#index" on boards of a
+// repository, and by "repo#index" on boards of an organization.
+func findBoardIssue(ctx *context.Context, bCtx *boardCtx, ref string) (*models.Issue, error) {
+	ref = strings.TrimSpace(ref)
+	n := strings.IndexByte(ref, '#')
+	index, err := com.StrTo(ref[n+1:]).Int64()
+	if err != nil {
+		return nil, models.ErrIssueNotExist{}
+	}
+
+	if bCtx.RepoID > 0 {
+		return models.GetIssueByIndex(bCtx.RepoID, index, ctx.User)
+	}
+
+	if n <= 0 {
+		return nil, models.ErrIssueNotExist{}
+	}
+	repo, err := models.GetRepositoryByName(bCtx.OrgID, ref[:n])
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			return nil, models.ErrIssueNotExist{}
+		}
+		return nil, err
+	}
+	has, err := models.HasAccess(ctx.User.ID, repo, models.AccessModeRead)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, models.ErrIssueNotExist{}
+	}
+	return models.GetIssueByIndex(repo.ID, index, ctx.User)
+}
+
+// AddBoardIssue response for placing an issue on an issue board
+func AddBoardIssue(ctx *context.Context) {
+	bCtx := getBoardCtx(ctx)
+	board := getBoard(ctx, bCtx)
+	if ctx.Written() {
+		return
+	}
+	boardLink := bCtx.Link + "/" + com.ToStr(board.ID)
+
+	issue, err := findBoardIssue(ctx, bCtx, ctx.Query("issue"))
+	if err == nil {
+		err = board.AddIssue(issue)
+	}
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("repo.boards.issue_not_found", ctx.Query("issue")))
+		} else if models.IsErrBoardIssueNotAllowed(err) {
+			ctx.Flash.Error(ctx.Tr("repo.boards.issue_not_allowed", ctx.Query("issue")))
+		} else {
+			ctx.Handle(500, "AddIssue", err)
+			return
+		}
+	}
+	ctx.Redirect(boardLink)
+}
+
+// MoveBoardIssue response for moving a card of an issue board
+func MoveBoardIssue(ctx *context.Context) {
+	board := getBoard(ctx, getBoardCtx(ctx))
+	if ctx.Written() {
+		return
+	}
+
+	var order []int64
+	if ids := ctx.Query("order"); len(ids) > 0 {
+		var err error
+		if order, err = base.StringsToInt64s(strings.Split(ids, ",")); err != nil {
+			ctx.Error(422)
+			return
+		}
+	}
+
+	if err := board.MoveIssue(ctx.QueryInt64("issue"), ctx.QueryInt64("column"), order); err != nil {
+		if models.IsErrBoardColumnNotExist(err) || models.IsErrBoardIssueNotExist(err) {
+			ctx.Error(404)
+		} else {
+			ctx.Handle(500, "MoveIssue", err)
+		}
+		return
+	}
+	log.Trace("Issue %d moved on board %d", ctx.QueryInt64("issue"), board.ID)
+	ctx.Status(200)
+}
+
+// RemoveBoardIssue response for removing an issue from an issue board
+func RemoveBoardIssue(ctx *context.Context) {
+	bCtx := getBoardCtx(ctx)
+	board := getBoard(ctx, bCtx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := board.RemoveIssue(ctx.QueryInt64("id")); err != nil {
+		ctx.Flash.Error("RemoveIssue: " + err.Error())
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": bCtx.Link + "/" + com.ToStr(board.ID),
+	})
+}
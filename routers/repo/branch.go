@@ -89,3 +89,63 @@ func DeleteBranchPost(ctx *context.Context) {
 
 	ctx.Flash.Success(ctx.Tr("repo.branch.deletion_success", fullBranchName))
 }
+
+// SyncForkPost brings a branch of a fork up to date with the same branch of its
+// base repository, or pushes the base branch to a new branch of the fork and
+// redirects to the pull request creation page if the style is "pull".
+func SyncForkPost(ctx *context.Context) {
+	branchName := ctx.Query("branch")
+	repo := ctx.Repo.Repository
+	if !repo.IsFork || !ctx.Repo.GitRepo.IsBranchExist(branchName) {
+		ctx.Handle(404, "SyncForkPost", nil)
+		return
+	}
+	redirectTo := ctx.Repo.RepoLink + "/src/" + branchName
+
+	style := ctx.Query("style")
+	if style == "pull" {
+		syncBranch, err := repo.PushForkSyncBranch(ctx.User, branchName)
+		if err != nil {
+			if models.IsErrBranchNotExist(err) {
+				ctx.Handle(404, "PushForkSyncBranch", nil)
+			} else if models.IsErrForkSyncConflict(err) {
+				ctx.Flash.Error(ctx.Tr("repo.branch.sync_fork_branch_conflict", models.ForkSyncBranchName(branchName)))
+				ctx.Redirect(redirectTo)
+			} else {
+				ctx.Handle(500, "PushForkSyncBranch", err)
+			}
+			return
+		}
+		ctx.Redirect(ctx.Repo.RepoLink + "/compare/" + branchName + "..." + syncBranch)
+		return
+	}
+
+	syncStyle := models.ForkSyncFastForward
+	if style == string(models.ForkSyncMerge) {
+		syncStyle = models.ForkSyncMerge
+	}
+	updated, err := repo.SyncFork(ctx.User, branchName, syncStyle)
+	if err != nil {
+		if models.IsErrBranchNotExist(err) {
+			ctx.Handle(404, "SyncFork", nil)
+		} else if models.IsErrForkSyncConflict(err) {
+			if syncStyle == models.ForkSyncMerge {
+				ctx.Flash.Error(ctx.Tr("repo.branch.sync_fork_conflict"))
+			} else {
+				ctx.Flash.Error(ctx.Tr("repo.branch.sync_fork_not_fast_forward"))
+			}
+			ctx.Redirect(redirectTo)
+		} else {
+			ctx.Handle(500, "SyncFork", err)
+		}
+		return
+	}
+
+	if updated {
+		log.Trace("Fork branch synced: %s/%s", repo.FullName(), branchName)
+		ctx.Flash.Success(ctx.Tr("repo.branch.sync_fork_success", repo.BaseRepo.FullName()))
+	} else {
+		ctx.Flash.Info(ctx.Tr("repo.branch.sync_fork_up_to_date", repo.BaseRepo.FullName()))
+	}
+	ctx.Redirect(redirectTo)
+}
@@ -6,6 +6,9 @@ package repo
 
 import (
 	"container/list"
+	"encoding/json"
+	"fmt"
+	"html/template"
 	"path"
 	"strings"
 
@@ -14,13 +17,15 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/templates"
 	"github.com/Unknwon/paginater"
 )
 
 const (
-	tplCommits base.TplName = "repo/commits"
-	tplGraph   base.TplName = "repo/graph"
-	tplDiff    base.TplName = "repo/diff/page"
+	tplCommits      base.TplName = "repo/commits"
+	tplGraph        base.TplName = "repo/graph"
+	tplContributors base.TplName = "repo/contributors"
+	tplDiff         base.TplName = "repo/diff/page"
 )
 
 // RefCommits render commits page
@@ -79,6 +84,32 @@ func Commits(ctx *context.Context) {
 	ctx.HTML(200, tplCommits)
 }
 
+// getCommitGraph returns one page of the commit graph, pages are cached
+// until any reference of the repository moves.
+func getCommitGraph(ctx *context.Context, page int) (models.GraphItems, error) {
+	tips, err := models.GetCommitGraphTipsHash(ctx.Repo.GitRepo)
+	if err != nil {
+		return nil, fmt.Errorf("GetCommitGraphTipsHash: %v", err)
+	}
+
+	key := fmt.Sprintf("commit-graph:%d:%s:%d", ctx.Repo.Repository.ID, tips, page)
+	if cached, ok := ctx.Cache.Get(key).(string); ok {
+		var graph models.GraphItems
+		if err = json.Unmarshal([]byte(cached), &graph); err == nil {
+			return graph, nil
+		}
+	}
+
+	graph, err := models.GetCommitGraph(ctx.Repo.GitRepo, page)
+	if err != nil {
+		return nil, fmt.Errorf("GetCommitGraph: %v", err)
+	}
+	if data, err := json.Marshal(graph); err == nil {
+		ctx.Cache.Put(key, string(data), setting.Git.GraphCacheTTL)
+	}
+	return graph, nil
+}
+
 // Graph render commit graph - show commits from all branches.
 func Graph(ctx *context.Context) {
 	ctx.Data["PageIsCommits"] = true
@@ -89,13 +120,14 @@ func Graph(ctx *context.Context) {
 		return
 	}
 
-	graph, err := models.GetCommitGraph(ctx.Repo.GitRepo)
+	graph, err := getCommitGraph(ctx, 1)
 	if err != nil {
 		ctx.Handle(500, "GetCommitGraph", err)
 		return
 	}
 
 	ctx.Data["Graph"] = graph
+	ctx.Data["GraphHasMore"] = graph.CommitsCount() == models.GraphPageSize
 	ctx.Data["Username"] = ctx.Repo.Owner.Name
 	ctx.Data["Reponame"] = ctx.Repo.Repository.Name
 	ctx.Data["CommitCount"] = commitsCount
@@ -105,6 +137,74 @@ func Graph(ctx *context.Context) {
 
 }
 
+type graphItemJSON struct {
+	Relation     string        `json:"relation"`
+	OnlyRelation bool          `json:"only_relation"`
+	Branch       string        `json:"branch"`
+	Rev          string        `json:"rev"`
+	ShortRev     string        `json:"short_rev"`
+	Date         string        `json:"date"`
+	Author       string        `json:"author"`
+	Subject      template.HTML `json:"subject"`
+}
+
+// GraphData returns one page of the commit graph as JSON for incremental loading.
+func GraphData(ctx *context.Context) {
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+
+	graph, err := getCommitGraph(ctx, page)
+	if err != nil {
+		ctx.Handle(500, "GetCommitGraph", err)
+		return
+	}
+
+	metas := ctx.Repo.Repository.ComposeMetas()
+	items := make([]*graphItemJSON, len(graph))
+	for i, item := range graph {
+		items[i] = &graphItemJSON{
+			Relation:     item.GraphAcii,
+			OnlyRelation: item.OnlyRelation,
+			Branch:       item.Branch,
+			Rev:          item.Rev,
+			ShortRev:     item.ShortRev,
+			Date:         item.Date,
+			Author:       item.Author,
+			Subject:      templates.RenderCommitMessage(false, item.Subject, ctx.Repo.RepoLink, metas),
+		}
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"page":     page,
+		"has_more": graph.CommitsCount() == models.GraphPageSize,
+		"items":    items,
+	})
+}
+
+// Contributors render contributors statistics of the default branch
+func Contributors(ctx *context.Context) {
+	ctx.Data["PageIsCommits"] = true
+
+	stats, err := ctx.Repo.Repository.GetContributorStats()
+	if err != nil {
+		ctx.Handle(500, "GetContributorStats", err)
+		return
+	}
+
+	var maxWeekCommits int64
+	for _, s := range stats {
+		if max := s.MaxWeekCommits(); max > maxWeekCommits {
+			maxWeekCommits = max
+		}
+	}
+
+	ctx.Data["Contributors"] = stats
+	ctx.Data["MaxWeekCommits"] = maxWeekCommits
+	ctx.HTML(200, tplContributors)
+}
+
 // SearchCommits render commits filtered by keyword
 func SearchCommits(ctx *context.Context) {
 	ctx.Data["PageIsCommits"] = true
@@ -183,6 +283,30 @@ func FileHistory(ctx *context.Context) {
 	ctx.HTML(200, tplCommits)
 }
 
+// getDiffCommit returns the diff of a commit of the repository, the diff is
+// cached by the SHA of the commit and the diff limits.
+func getDiffCommit(ctx *context.Context, commitID string) (*models.Diff, error) {
+	key := context.RenderCacheKey("diff", nil, ctx.Repo.Repository.RepoPath(), commitID,
+		fmt.Sprint(setting.Git.MaxGitDiffLines, setting.Git.MaxGitDiffLineCharacters, setting.Git.MaxGitDiffFiles))
+	if cached, ok := ctx.GetRenderCache(key); ok {
+		var diff models.Diff
+		if err := json.Unmarshal([]byte(cached), &diff); err == nil {
+			return &diff, nil
+		}
+	}
+
+	diff, err := models.GetDiffCommit(ctx.Repo.Repository.RepoPath(),
+		commitID, setting.Git.MaxGitDiffLines,
+		setting.Git.MaxGitDiffLineCharacters, setting.Git.MaxGitDiffFiles)
+	if err != nil {
+		return nil, err
+	}
+	if data, err := json.Marshal(diff); err == nil {
+		ctx.PutRenderCache(key, string(data))
+	}
+	return diff, nil
+}
+
 // Diff show different from current commit to previous commit
 func Diff(ctx *context.Context) {
 	ctx.Data["PageIsDiff"] = true
@@ -204,9 +328,7 @@ func Diff(ctx *context.Context) {
 	if len(commitID) != 40 {
 		commitID = commit.ID.String()
 	}
-	diff, err := models.GetDiffCommit(models.RepoPath(userName, repoName),
-		commitID, setting.Git.MaxGitDiffLines,
-		setting.Git.MaxGitDiffLineCharacters, setting.Git.MaxGitDiffFiles)
+	diff, err := getDiffCommit(ctx, commitID)
 	if err != nil {
 		ctx.Handle(404, "GetDiffCommit", err)
 		return
@@ -228,6 +350,7 @@ func Diff(ctx *context.Context) {
 	ctx.Data["IsImageFile"] = commit.IsImageFile
 	ctx.Data["Title"] = commit.Summary() + " · " + base.ShortSha(commitID)
 	ctx.Data["Commit"] = commit
+	ctx.Data["LinkPreview"] = commitLinkPreview(ctx.Repo.Repository, commit)
 	ctx.Data["Verification"] = models.ParseCommitWithSignature(commit)
 	ctx.Data["Author"] = models.ValidateCommitWithEmail(commit)
 	ctx.Data["Diff"] = diff
@@ -254,22 +377,108 @@ func RawDiff(ctx *context.Context) {
 	}
 }
 
-// CompareDiff show different from one commit to another commit
-func CompareDiff(ctx *context.Context) {
+// compareRefName returns the short SHA if ref is the commit ID itself.
+func compareRefName(ref, commitID string) string {
+	if ref == commitID {
+		return base.ShortSha(commitID)
+	}
+	return ref
+}
+
+// canComparePull returns true if the current user can create a pull request
+// from the head branch of the comparison into the base branch.
+func canComparePull(ctx *context.Context, cs *models.CompareSpec) bool {
+	if !ctx.IsSigned || !ctx.Repo.Repository.AllowsPulls() {
+		return false
+	} else if cs.HeadRepo.ID != ctx.Repo.Repository.ID && cs.HeadRepo.ForkID != ctx.Repo.Repository.ID {
+		return false
+	} else if !ctx.User.IsWriterOfRepo(cs.HeadRepo) && !ctx.User.IsAdmin {
+		return false
+	}
+	return ctx.Repo.GitRepo.IsBranchExist(cs.BaseRef) &&
+		git.IsBranchExist(cs.HeadRepo.RepoPath(), cs.HeadRef)
+}
+
+// Compare shows the changes of a branch, tag or commit since its merge base
+// with another one of the same repository or of a fork, the format is
+// "<base>...[<owner>[/<repo>]:]<head>". The pull request form is shown
+// instead if the user can propose merging the head branch into the base
+// branch. A ".diff" or ".patch" suffix gives the raw changes.
+func Compare(ctx *context.Context) {
+	spec := ctx.Params("*")
+	var diffType models.RawDiffType
+	for _, typ := range []models.RawDiffType{models.RawDiffNormal, models.RawDiffPatch} {
+		if strings.HasSuffix(spec, "."+string(typ)) {
+			diffType = typ
+			spec = strings.TrimSuffix(spec, "."+string(typ))
+			break
+		}
+	}
+
+	cs, err := models.ParseCompareSpec(ctx.Repo.Repository, ctx.User, spec)
+	if err != nil {
+		if models.IsErrInvalidCompareSpec(err) || models.IsErrUserNotExist(err) || models.IsErrRepoNotExist(err) {
+			ctx.Handle(404, "ParseCompareSpec", nil)
+		} else {
+			ctx.Handle(500, "ParseCompareSpec", err)
+		}
+		return
+	}
+
+	if len(diffType) == 0 && canComparePull(ctx, cs) {
+		MustAllowPulls(ctx)
+		if ctx.Written() {
+			return
+		}
+		CompareAndPullRequest(ctx)
+		return
+	}
+
+	info, err := models.GetCompareInfo(ctx.Repo.Repository, cs)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.Handle(404, "GetCompareInfo", nil)
+		} else {
+			ctx.Handle(500, "GetCompareInfo", err)
+		}
+		return
+	}
+
+	if len(diffType) > 0 {
+		if err = models.GetRawDiffRange(cs.HeadRepo.RepoPath(), info.MergeBase,
+			info.HeadCommitID, diffType, ctx.Resp); err != nil {
+			ctx.Handle(500, "GetRawDiffRange", err)
+		}
+		return
+	}
+	compareDiff(ctx, cs, info)
+}
+
+// compareDiff shows the changes of a comparison which is not a pull request
+func compareDiff(ctx *context.Context, cs *models.CompareSpec, info *models.CompareInfo) {
 	ctx.Data["IsRepoToolbarCommits"] = true
 	ctx.Data["IsDiffCompare"] = true
-	userName := ctx.Repo.Owner.Name
-	repoName := ctx.Repo.Repository.Name
-	beforeCommitID := ctx.Params(":before")
-	afterCommitID := ctx.Params(":after")
-
-	commit, err := ctx.Repo.GitRepo.GetCommit(afterCommitID)
+	userName := cs.HeadRepo.Owner.Name
+	repoName := cs.HeadRepo.Name
+	beforeCommitID := info.MergeBase
+	afterCommitID := info.HeadCommitID
+
+	headGitRepo := ctx.Repo.GitRepo
+	if cs.HeadRepo.ID != ctx.Repo.Repository.ID {
+		var err error
+		headGitRepo, err = git.OpenRepository(cs.HeadRepo.RepoPath())
+		if err != nil {
+			ctx.Handle(500, "OpenRepository", err)
+			return
+		}
+	}
+	commit, err := headGitRepo.GetCommit(afterCommitID)
 	if err != nil {
 		ctx.Handle(404, "GetCommit", err)
 		return
 	}
 
-	diff, err := models.GetDiffRange(models.RepoPath(userName, repoName), beforeCommitID,
+	diff, err := models.GetDiffRange(cs.HeadRepo.RepoPath(), beforeCommitID,
 		afterCommitID, setting.Git.MaxGitDiffLines,
 		setting.Git.MaxGitDiffLineCharacters, setting.Git.MaxGitDiffFiles)
 	if err != nil {
@@ -277,16 +486,12 @@ func CompareDiff(ctx *context.Context) {
 		return
 	}
 
-	commits, err := commit.CommitsBeforeUntil(beforeCommitID)
-	if err != nil {
-		ctx.Handle(500, "CommitsBeforeUntil", err)
-		return
-	}
+	commits := info.Commits
 	commits = models.ValidateCommitsWithEmails(commits)
 	commits = models.ParseCommitsWithSignature(commits)
-	commits = models.ParseCommitsWithStatus(commits, ctx.Repo.Repository)
+	commits = models.ParseCommitsWithStatus(commits, cs.HeadRepo)
 
-	ctx.Data["CommitRepoLink"] = ctx.Repo.RepoLink
+	ctx.Data["CommitRepoLink"] = cs.HeadRepo.Link()
 	ctx.Data["Commits"] = commits
 	ctx.Data["CommitCount"] = commits.Len()
 	ctx.Data["BeforeCommitID"] = beforeCommitID
@@ -294,7 +499,8 @@ func CompareDiff(ctx *context.Context) {
 	ctx.Data["Username"] = userName
 	ctx.Data["Reponame"] = repoName
 	ctx.Data["IsImageFile"] = commit.IsImageFile
-	ctx.Data["Title"] = "Comparing " + base.ShortSha(beforeCommitID) + "..." + base.ShortSha(afterCommitID) + " · " + userName + "/" + repoName
+	ctx.Data["Title"] = "Comparing " + compareRefName(cs.BaseRef, info.BaseCommitID) + "..." +
+		compareRefName(cs.HeadRef, afterCommitID) + " · " + ctx.Repo.Owner.Name + "/" + ctx.Repo.Repository.Name
 	ctx.Data["Commit"] = commit
 	ctx.Data["Diff"] = diff
 	ctx.Data["DiffNotAvailable"] = diff.NumFiles() == 0
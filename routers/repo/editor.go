@@ -7,7 +7,6 @@ package repo
 import (
 	"fmt"
 	"io/ioutil"
-	"net/http"
 	"path"
 	"strings"
 
@@ -557,22 +556,9 @@ func UploadFileToServer(ctx *context.Context) {
 	if n > 0 {
 		buf = buf[:n]
 	}
-	fileType := http.DetectContentType(buf)
-
-	if len(setting.Repository.Upload.AllowedTypes) > 0 {
-		allowed := false
-		for _, t := range setting.Repository.Upload.AllowedTypes {
-			t := strings.Trim(t, " ")
-			if t == "*/*" || t == fileType {
-				allowed = true
-				break
-			}
-		}
-
-		if !allowed {
-			ctx.Error(400, ErrFileTypeForbidden.Error())
-			return
-		}
+	if len(setting.Repository.Upload.AllowedTypes) > 0 && !isFileTypeAllowed(buf, setting.Repository.Upload.AllowedTypes) {
+		ctx.Error(400, ErrFileTypeForbidden.Error())
+		return
 	}
 
 	upload, err := models.NewUpload(header.Filename, buf, file)
@@ -8,6 +8,8 @@ import (
 	"bytes"
 	"compress/gzip"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"os/exec"
@@ -18,9 +20,11 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/packcache"
 	"code.gitea.io/gitea/modules/setting"
 
 	"github.com/Unknwon/com"
@@ -43,6 +47,15 @@ func earlyResponseForGoGetMeta(ctx *context.Context, username, reponame, subpath
 		})))
 }
 
+// handleError responds a plain text error to the git client, since the
+// requests of the protocol are served without templates nor locales.
+func handleError(ctx *context.Context, status int, title string, err error) {
+	if err != nil {
+		log.Error(4, "%s: %v", title, err)
+	}
+	ctx.PlainText(status, []byte(http.StatusText(status)))
+}
+
 // HTTP implmentation git smart HTTP protocol
 func HTTP(ctx *context.Context) {
 	username := ctx.Params(":username")
@@ -69,7 +82,10 @@ func HTTP(ctx *context.Context) {
 		isPull = (ctx.Req.Method == "GET")
 	}
 
-	var accessMode models.AccessMode
+	var (
+		accessMode   models.AccessMode
+		isReviewOnly bool
+	)
 	if isPull {
 		accessMode = models.AccessModeRead
 	} else {
@@ -87,28 +103,37 @@ func HTTP(ctx *context.Context) {
 	repoUser, err := models.GetUserByName(username)
 	if err != nil {
 		if models.IsErrUserNotExist(err) {
-			ctx.Handle(http.StatusNotFound, "GetUserByName", nil)
+			handleError(ctx, http.StatusNotFound, "GetUserByName", nil)
 		} else {
-			ctx.Handle(http.StatusInternalServerError, "GetUserByName", err)
+			handleError(ctx, http.StatusInternalServerError, "GetUserByName", err)
 		}
 		return
 	}
 
 	repo, err := models.GetRepositoryByName(repoUser.ID, reponame)
 	if err != nil {
-		if models.IsErrRepoNotExist(err) {
-			ctx.Handle(http.StatusNotFound, "GetRepositoryByName", nil)
-		} else {
-			ctx.Handle(http.StatusInternalServerError, "GetRepositoryByName", err)
+		if !models.IsErrRepoNotExist(err) {
+			handleError(ctx, http.StatusInternalServerError, "GetRepositoryByName", err)
+			return
+		} else if isPull || isWiki || !models.IsPushCreateEnabled(repoUser) {
+			handleError(ctx, http.StatusNotFound, "GetRepositoryByName", nil)
+			return
 		}
+		// The repository is created by the push once the pusher is signed in.
+		repo = nil
+	}
+
+	if isWiki && !repo.EnableUnit(models.UnitTypeWiki) {
+		handleError(ctx, http.StatusNotFound, "EnableUnit", nil)
 		return
 	}
 
 	// Only public pull don't need auth.
-	isPublicPull := !repo.IsPrivate && isPull
+	isPublicPull := repo != nil && !repo.IsPrivate && isPull
 	var (
 		askAuth      = !isPublicPull || setting.Service.RequireSignInView
 		authUser     *models.User
+		deployToken  *models.DeployToken
 		authUsername string
 		authPasswd   string
 		environ      []string
@@ -117,7 +142,7 @@ func HTTP(ctx *context.Context) {
 	// check access
 	if askAuth {
 		if setting.Service.EnableReverseProxyAuth {
-			authUsername = ctx.Req.Header.Get(setting.ReverseProxyAuthUser)
+			authUsername = auth.ReverseProxyAuthUser(ctx.Context)
 			if len(authUsername) == 0 {
 				ctx.HandleText(401, "reverse proxy login error. authUsername empty")
 				return
@@ -126,6 +151,14 @@ func HTTP(ctx *context.Context) {
 			if err != nil {
 				ctx.HandleText(401, "reverse proxy login error, got error while running GetUserByName")
 				return
+			} else if authUser.IsSuspended {
+				ctx.HandleText(http.StatusForbidden, "user is suspended")
+				return
+			}
+			if repo == nil {
+				if repo = pushCreateRepo(ctx, authUser, repoUser, reponame); repo == nil {
+					return
+				}
 			}
 		} else {
 			authHead := ctx.Req.Header.Get("Authorization")
@@ -150,63 +183,158 @@ func HTTP(ctx *context.Context) {
 				return
 			}
 
-			authUser, err = models.UserSignIn(authUsername, authPasswd)
-			if err != nil {
-				if !models.IsErrUserNotExist(err) {
-					ctx.Handle(http.StatusInternalServerError, "UserSignIn error: %v", err)
+			// Deploy tokens are given as password, or as username like access
+			// tokens. They are looked up first so that using them does not
+			// count as a failed sign-in of the user named in the request.
+			if repo != nil {
+				deployToken, err = getDeployToken(repo.ID, authPasswd, authUsername)
+				if err != nil {
+					handleError(ctx, http.StatusInternalServerError, "GetDeployTokenBySHA", err)
 					return
 				}
+			}
 
-				// Assume username now is a token.
-				token, err := models.GetAccessTokenBySHA(authUsername)
+			if deployToken == nil {
+				authUser, err = models.UserSignInFrom(authUsername, authPasswd, ctx.RemoteAddr(), ctx.Req.UserAgent())
 				if err != nil {
-					if models.IsErrAccessTokenNotExist(err) || models.IsErrAccessTokenEmpty(err) {
+					if models.IsErrUserSuspended(err) {
+						ctx.HandleText(http.StatusForbidden, "user is suspended")
+						return
+					} else if models.IsErrUserLocked(err) {
+						ctx.HandleText(http.StatusTooManyRequests, "too many failed sign-in attempts")
+						return
+					} else if !models.IsErrUserNotExist(err) {
+						handleError(ctx, http.StatusInternalServerError, "UserSignInFrom", err)
+						return
+					}
+				}
+			}
+
+			if deployToken != nil || err != nil {
+				if deployToken != nil {
+					if deployToken.IsExpired() {
+						ctx.HandleText(http.StatusUnauthorized, "expired token")
+						return
+					}
+					if err = models.UpdateDeployTokenUsed(deployToken); err != nil {
+						handleError(ctx, http.StatusInternalServerError, "UpdateDeployTokenUsed", err)
+						return
+					}
+					// Pushes are made on behalf of the creator of the token.
+					authUser, err = models.GetUserByID(deployToken.CreatorID)
+					if err != nil {
+						if models.IsErrUserNotExist(err) {
+							ctx.HandleText(http.StatusUnauthorized, "invalid token")
+						} else {
+							handleError(ctx, http.StatusInternalServerError, "GetUserByID", err)
+						}
+						return
+					} else if authUser.IsDeleted() {
 						ctx.HandleText(http.StatusUnauthorized, "invalid token")
-					} else {
-						ctx.Handle(http.StatusInternalServerError, "GetAccessTokenBySha", err)
+						return
+					}
+				} else {
+					// Assume username now is a token.
+					token, err := models.GetAccessTokenBySHA(authUsername)
+					if err != nil {
+						if models.IsErrAccessTokenNotExist(err) || models.IsErrAccessTokenEmpty(err) {
+							ctx.HandleText(http.StatusUnauthorized, "invalid token")
+						} else {
+							handleError(ctx, http.StatusInternalServerError, "GetAccessTokenBySha", err)
+						}
+						return
 					}
+					token.Updated = time.Now()
+					if err = models.UpdateAccessToken(token); err != nil {
+						handleError(ctx, http.StatusInternalServerError, "UpdateAccessToken", err)
+					}
+					authUser, err = models.GetUserByID(token.UID)
+					if err != nil {
+						handleError(ctx, http.StatusInternalServerError, "GetUserByID", err)
+						return
+					} else if authUser.IsDeleted() {
+						ctx.HandleText(http.StatusUnauthorized, "invalid token")
+						return
+					}
+				}
+			}
+
+			// Neither the tokens of suspended users nor the deploy tokens
+			// they created can be used.
+			if authUser.IsSuspended {
+				ctx.HandleText(http.StatusForbidden, "user is suspended")
+				return
+			}
+
+			if repo == nil {
+				if repo = pushCreateRepo(ctx, authUser, repoUser, reponame); repo == nil {
 					return
 				}
-				token.Updated = time.Now()
-				if err = models.UpdateAccessToken(token); err != nil {
-					ctx.Handle(http.StatusInternalServerError, "UpdateAccessToken", err)
+			}
+
+			if deployToken != nil {
+				if deployToken.Mode < accessMode {
+					ctx.HandleText(http.StatusForbidden, "Token permission denied")
+					return
 				}
-				authUser, err = models.GetUserByID(token.UID)
+				// The token can't do more than its creator still can.
+				has, err := models.HasUnitAccess(authUser.ID, repo, unitType, accessMode)
 				if err != nil {
-					ctx.Handle(http.StatusInternalServerError, "GetUserByID", err)
+					handleError(ctx, http.StatusInternalServerError, "HasUnitAccess", err)
+					return
+				} else if !has {
+					ctx.HandleText(http.StatusForbidden, "Token permission denied")
 					return
 				}
-			}
-
-			if !isPublicPull {
-				has, err := models.HasAccess(authUser.ID, repo, accessMode)
+				if !isPull && repo.IsMirror && !isWiki {
+					ctx.HandleText(http.StatusForbidden, "mirror repository is read-only")
+					return
+				}
+			} else if !isPublicPull {
+				has, err := models.HasUnitAccess(authUser.ID, repo, unitType, accessMode)
 				if err != nil {
-					ctx.Handle(http.StatusInternalServerError, "HasAccess", err)
+					handleError(ctx, http.StatusInternalServerError, "HasUnitAccess", err)
 					return
 				} else if !has {
 					if accessMode == models.AccessModeRead {
 						has, err = models.HasAccess(authUser.ID, repo, models.AccessModeWrite)
 						if err != nil {
-							ctx.Handle(http.StatusInternalServerError, "HasAccess2", err)
+							handleError(ctx, http.StatusInternalServerError, "HasAccess2", err)
 							return
 						} else if !has {
 							ctx.HandleText(http.StatusForbidden, "User permission denied")
 							return
 						}
 					} else {
-						ctx.HandleText(http.StatusForbidden, "User permission denied")
-						return
+						// Users who can read the code can push commits for review.
+						if !isWiki && repo.AllowsPulls() {
+							isReviewOnly, err = models.HasUnitAccess(authUser.ID, repo, unitType, models.AccessModeRead)
+							if err != nil {
+								handleError(ctx, http.StatusInternalServerError, "HasUnitAccess", err)
+								return
+							}
+						}
+						if !isReviewOnly {
+							ctx.HandleText(http.StatusForbidden, "User permission denied")
+							return
+						}
 					}
 				}
 
-				if !isPull && repo.IsMirror {
+				// Wiki of a mirror is not mirrored and can be changed.
+				if !isPull && repo.IsMirror && !isWiki {
 					ctx.HandleText(http.StatusForbidden, "mirror repository is read-only")
 					return
 				}
 			}
 		}
 
-		if !repo.CheckUnitUser(authUser.ID, authUser.IsAdmin, unitType) {
+		if deployToken != nil {
+			if !repo.EnableUnit(unitType) {
+				ctx.HandleText(http.StatusForbidden, fmt.Sprintf("Unit %d of repository %s is disabled", unitType, repo.RepoPath()))
+				return
+			}
+		} else if !repo.CheckUnitUser(authUser.ID, authUser.IsAdmin, unitType) {
 			ctx.HandleText(http.StatusForbidden, fmt.Sprintf("User %s does not have allowed access to repository %s 's code",
 				authUser.Name, repo.RepoPath()))
 			return
@@ -224,6 +352,53 @@ func HTTP(ctx *context.Context) {
 		} else {
 			environ = append(environ, models.EnvRepoIsWiki+"=false")
 		}
+		if isReviewOnly {
+			environ = append(environ, models.EnvPushForReviewOnly+"=true")
+		}
+		if repo.IsBare {
+			environ = append(environ, models.EnvRepoIsEmpty+"=true")
+		}
+		// A push is logged by the hooks, which know the updated references.
+		if !isPull && repo.IsPrivate {
+			l := &models.RepoAccessLog{
+				RepoID:     repo.ID,
+				UserID:     authUser.ID,
+				Protocol:   models.AccessProtocolHTTP,
+				RemoteAddr: ctx.RemoteAddr(),
+			}
+			if deployToken != nil {
+				l.UserID = 0
+				l.DeployTokenID = deployToken.ID
+			}
+			environ = append(environ, models.EnvAccessLog+"="+models.EncodeAccessLog(l))
+		}
+	}
+
+	// Only the request transferring objects is recorded, not the reference discovery.
+	if isPull && authUser != nil && ctx.Req.Method == "POST" {
+		service := path.Base(ctx.Req.URL.Path)
+		if service == "git-upload-pack" || service == "git-upload-archive" {
+			l := &models.RepoAccessLog{
+				UserID:     authUser.ID,
+				Protocol:   models.AccessProtocolHTTP,
+				Service:    service,
+				RemoteAddr: ctx.RemoteAddr(),
+			}
+			if deployToken != nil {
+				l.UserID = 0
+				l.DeployTokenID = deployToken.ID
+			}
+			if err := repo.LogAccess(l); err != nil {
+				log.Error(4, "LogAccess: %v", err)
+			}
+		}
+	}
+
+	if isWiki {
+		if err = repo.InitWiki(); err != nil {
+			handleError(ctx, http.StatusInternalServerError, "InitWiki", err)
+			return
+		}
 	}
 
 	HTTPBackend(ctx, &serviceConfig{
@@ -233,6 +408,52 @@ func HTTP(ctx *context.Context) {
 	})(ctx.Resp, ctx.Req.Request)
 }
 
+// getDeployToken returns the first valid deploy token of the repository among
+// given candidates, or nil if none of them is one.
+// pushCreateRepo creates the repository the user pushes to, it returns nil
+// if the user is not allowed to.
+func pushCreateRepo(ctx *context.Context, doer, owner *models.User, name string) *models.Repository {
+	repo, err := models.PushCreateRepo(doer, owner, name)
+	if err != nil {
+		if models.IsErrPushCreateNotAllowed(err) {
+			handleError(ctx, http.StatusNotFound, "PushCreateRepo", nil)
+		} else if models.IsErrReachLimitOfRepo(err) || models.IsErrRepoAlreadyExist(err) ||
+			models.IsErrNameReserved(err) || models.IsErrNamePatternNotAllowed(err) {
+			ctx.HandleText(http.StatusForbidden, err.Error())
+		} else {
+			handleError(ctx, http.StatusInternalServerError, "PushCreateRepo", err)
+		}
+		return nil
+	}
+	return repo
+}
+
+func getDeployToken(repoID int64, shas ...string) (*models.DeployToken, error) {
+	for _, sha := range shas {
+		token, err := models.GetDeployTokenBySHA(repoID, sha)
+		if err == nil {
+			return token, nil
+		} else if !models.IsErrDeployTokenNotExist(err) && !models.IsErrAccessTokenEmpty(err) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// flushWriter flushes the response after every write, so that the output of
+// git is streamed to the client as it is produced.
+type flushWriter struct {
+	w http.ResponseWriter
+}
+
+func (fw flushWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	if f, ok := fw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return n, err
+}
+
 type serviceConfig struct {
 	UploadPack  bool
 	ReceivePack bool
@@ -369,21 +590,76 @@ func serviceRPC(h serviceHandler, service string) {
 
 	// set this for allow pre-receive and post-receive execute
 	h.environ = append(h.environ, "SSH_ORIGINAL_COMMAND="+service)
+	h.environ = append(h.environ, models.EnvGitConfigParameters+"="+models.PushOptionsConfigParameter)
+
+	var shallowGuard *models.ShallowGuard
+	if service == "upload-pack" && !setting.Git.AllowShallowClone {
+		shallowGuard = models.NewShallowGuard(reqBody)
+		reqBody = ioutil.NopCloser(shallowGuard)
+	}
+
+	var stdout io.Writer = flushWriter{h.w}
+	var cacheEntry *packcache.Entry
+	if cache := packcache.Default(); cache != nil && service == "upload-pack" {
+		request, err := ioutil.ReadAll(io.LimitReader(reqBody, packcache.MaxRequestSize+1))
+		if models.IsErrShallowCloneNotAllowed(err) {
+			h.w.Write(packetWrite("ERR " + err.Error() + "\n"))
+			return
+		} else if err != nil {
+			log.GitLogger.Error(2, "fail to read RPC(%s) request: %v", service, err)
+			h.w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if len(request) <= packcache.MaxRequestSize && packcache.IsClone(request) {
+			key := packcache.Key(h.dir, request)
+			if f := cache.Get(key); f != nil {
+				defer f.Close()
+				if _, err = io.Copy(h.w, f); err != nil {
+					log.GitLogger.Error(2, "fail to serve cached RPC(%s): %v", service, err)
+				}
+				return
+			}
+
+			if cacheEntry, err = cache.Create(key); err != nil {
+				log.GitLogger.Error(2, "fail to create pack cache entry: %v", err)
+			} else {
+				stdout = io.MultiWriter(stdout, cacheEntry)
+			}
+		}
+		reqBody = ioutil.NopCloser(io.MultiReader(bytes.NewReader(request), reqBody))
+	}
 
 	var stderr bytes.Buffer
-	cmd := exec.Command("git", service, "--stateless-rpc", h.dir)
+	args := []string{service, "--stateless-rpc", h.dir}
+	if service == "upload-pack" {
+		args = append(models.UploadPackArgs(), args...)
+	}
+	cmd := exec.Command("git", args...)
 	cmd.Dir = h.dir
 	if service == "receive-pack" {
 		cmd.Env = append(os.Environ(), h.environ...)
 	}
-	cmd.Stdout = h.w
+	cmd.Stdout = stdout
 	cmd.Stdin = reqBody
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
+		if cacheEntry != nil {
+			cacheEntry.Abort()
+		}
+		if shallowGuard != nil && shallowGuard.Err != nil {
+			h.w.Write(packetWrite("ERR " + shallowGuard.Err.Error() + "\n"))
+			return
+		}
 		log.GitLogger.Error(2, "fail to serve RPC(%s): %v - %v", service, err, stderr)
 		h.w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+	if cacheEntry != nil {
+		if err := cacheEntry.Commit(); err != nil {
+			log.GitLogger.Error(2, "fail to cache RPC(%s) response: %v", service, err)
+		}
+	}
 }
 
 func serviceUploadPack(h serviceHandler) {
@@ -418,7 +694,14 @@ func getInfoRefs(h serviceHandler) {
 	h.setHeaderNoCache()
 	if hasAccess(getServiceType(h.r), h, false) {
 		service := getServiceType(h.r)
-		refs := gitCommand(h.dir, service, "--stateless-rpc", "--advertise-refs", ".")
+		args := []string{service, "--stateless-rpc", "--advertise-refs", "."}
+		if service == "receive-pack" {
+			// Accept `git push -o` options, which are handled by the hooks.
+			args = append([]string{"-c", "receive.advertisePushOptions=true"}, args...)
+		} else if service == "upload-pack" {
+			args = append(models.UploadPackArgs(), args...)
+		}
+		refs := gitCommand(h.dir, args...)
 
 		h.w.Header().Set("Content-Type", fmt.Sprintf("application/x-git-%s-advertisement", service))
 		h.w.WriteHeader(http.StatusOK)
@@ -495,7 +778,7 @@ func HTTPBackend(ctx *context.Context, cfg *serviceConfig) http.HandlerFunc {
 				dir, err := getGitRepoPath(m[1])
 				if err != nil {
 					log.GitLogger.Error(4, err.Error())
-					ctx.Handle(http.StatusNotFound, "HTTPBackend", err)
+					handleError(ctx, http.StatusNotFound, "HTTPBackend", err)
 					return
 				}
 
@@ -504,7 +787,7 @@ func HTTPBackend(ctx *context.Context, cfg *serviceConfig) http.HandlerFunc {
 			}
 		}
 
-		ctx.Handle(http.StatusNotFound, "HTTPBackend", nil)
+		handleError(ctx, http.StatusNotFound, "HTTPBackend", nil)
 		return
 	}
 }
@@ -8,8 +8,6 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"strconv"
 	"strings"
 	"time"
@@ -30,9 +28,10 @@ import (
 )
 
 const (
-	tplIssues    base.TplName = "repo/issue/list"
-	tplIssueNew  base.TplName = "repo/issue/new"
-	tplIssueView base.TplName = "repo/issue/view"
+	tplIssues        base.TplName = "repo/issue/list"
+	tplIssueNew      base.TplName = "repo/issue/new"
+	tplIssueView     base.TplName = "repo/issue/view"
+	tplIssueComments base.TplName = "repo/issue/view_content/comments_page"
 
 	tplMilestone     base.TplName = "repo/issue/milestones"
 	tplMilestoneNew  base.TplName = "repo/issue/milestone_new"
@@ -130,10 +129,15 @@ func Issues(ctx *context.Context) {
 		keyword = ""
 	}
 
+	terms, isOverdue, qualifiedSort := parseIssueQualifiers(keyword)
+	if len(qualifiedSort) > 0 {
+		sortType = qualifiedSort
+	}
+
 	var issueIDs []int64
 	var err error
-	if len(keyword) > 0 {
-		issueIDs, err = models.SearchIssuesByKeyword(repo.ID, keyword)
+	if len(terms) > 0 {
+		issueIDs, err = models.SearchIssuesByKeyword(repo.ID, terms)
 		if len(issueIDs) == 0 {
 			forceEmpty = true
 		}
@@ -152,6 +156,8 @@ func Issues(ctx *context.Context) {
 			MentionedID: mentionedID,
 			IsPull:      isPullList,
 			IssueIDs:    issueIDs,
+			IsOverdue:   isOverdue,
+			Doer:        ctx.User,
 		})
 		if err != nil {
 			ctx.Error(500, "GetSearchIssueStats")
@@ -188,6 +194,8 @@ func Issues(ctx *context.Context) {
 			Labels:      selectLabels,
 			SortType:    sortType,
 			IssueIDs:    issueIDs,
+			IsOverdue:   isOverdue,
+			Doer:        ctx.User,
 		})
 		if err != nil {
 			ctx.Handle(500, "Issues", err)
@@ -207,6 +215,15 @@ func Issues(ctx *context.Context) {
 	}
 	ctx.Data["Issues"] = issues
 
+	// Pinned issues are shown on top of the first page.
+	if !isPullList && pager.Current() == 1 {
+		ctx.Data["PinnedIssues"], err = models.GetPinnedIssues(repo.ID, ctx.User)
+		if err != nil {
+			ctx.Handle(500, "GetPinnedIssues", err)
+			return
+		}
+	}
+
 	// Get milestones.
 	ctx.Data["Milestones"], err = models.GetMilestonesByRepoID(repo.ID)
 	if err != nil {
@@ -242,6 +259,24 @@ func Issues(ctx *context.Context) {
 	ctx.HTML(200, tplIssues)
 }
 
+// parseIssueQualifiers extracts the "is:overdue" and "sort:<type>" qualifiers
+// from an issue search keyword, it returns the remaining search terms.
+func parseIssueQualifiers(keyword string) (terms string, isOverdue bool, sortType string) {
+	fields := strings.Fields(keyword)
+	rest := make([]string, 0, len(fields))
+	for _, field := range fields {
+		switch {
+		case field == "is:overdue":
+			isOverdue = true
+		case strings.HasPrefix(field, "sort:"):
+			sortType = strings.Replace(strings.TrimPrefix(field, "sort:"), "-", "", -1)
+		default:
+			rest = append(rest, field)
+		}
+	}
+	return strings.Join(rest, " "), isOverdue, sortType
+}
+
 // RetrieveRepoMilestonesAndAssignees find all the milestones and assignees of a repository
 func RetrieveRepoMilestonesAndAssignees(ctx *context.Context, repo *models.Repository) {
 	var err error
@@ -265,7 +300,7 @@ func RetrieveRepoMilestonesAndAssignees(ctx *context.Context, repo *models.Repos
 
 // RetrieveRepoMetas find all the meta information of a repository
 func RetrieveRepoMetas(ctx *context.Context, repo *models.Repository) []*models.Label {
-	if !ctx.Repo.IsWriter() {
+	if !ctx.Repo.CanTriage(models.UnitTypeIssues) {
 		return nil
 	}
 
@@ -284,39 +319,38 @@ func RetrieveRepoMetas(ctx *context.Context, repo *models.Repository) []*models.
 	return labels
 }
 
-func getFileContentFromDefaultBranch(ctx *context.Context, filename string) (string, bool) {
-	var r io.Reader
-	var bytes []byte
-
-	if ctx.Repo.Commit == nil {
-		var err error
-		ctx.Repo.Commit, err = ctx.Repo.GitRepo.GetBranchCommit(ctx.Repo.Repository.DefaultBranch)
-		if err != nil {
-			return "", false
-		}
-	}
-
-	entry, err := ctx.Repo.Commit.GetTreeEntryByPath(filename)
-	if err != nil {
-		return "", false
-	}
-	r, err = entry.Blob().Data()
+// setTemplateIfExists sets the content of the first of possibleFiles which
+// exists in the repository, or its community health repository.
+func setTemplateIfExists(ctx *context.Context, ctxDataKey string, possibleFiles []string) {
+	file, err := ctx.Repo.Repository.FindCommunityFile(possibleFiles)
 	if err != nil {
-		return "", false
+		log.Error(4, "FindCommunityFile: %v", err)
+		return
+	} else if file == nil {
+		return
 	}
-	bytes, err = ioutil.ReadAll(r)
+
+	content, err := file.Content()
 	if err != nil {
-		return "", false
+		log.Error(4, "Content: %v", err)
+		return
 	}
-	return string(bytes), true
+	ctx.Data[ctxDataKey] = content
 }
 
-func setTemplateIfExists(ctx *context.Context, ctxDataKey string, possibleFiles []string) {
-	for _, filename := range possibleFiles {
-		content, found := getFileContentFromDefaultBranch(ctx, filename)
-		if found {
-			ctx.Data[ctxDataKey] = content
-			return
+// setCommunityLinks sets the links to the contributing guidelines and the
+// code of conduct of the repository, which are shown when creating issues
+// and pull requests.
+func setCommunityLinks(ctx *context.Context) {
+	for key, candidates := range map[string][]string{
+		"ContributingLink":  models.ContributingCandidates,
+		"CodeOfConductLink": models.CodeOfConductCandidates,
+	} {
+		file, err := ctx.Repo.Repository.FindCommunityFile(candidates)
+		if err != nil {
+			log.Error(4, "FindCommunityFile: %v", err)
+		} else if file != nil {
+			ctx.Data[key] = file.Link()
 		}
 	}
 }
@@ -328,6 +362,7 @@ func NewIssue(ctx *context.Context) {
 	ctx.Data["RequireHighlightJS"] = true
 	ctx.Data["RequireSimpleMDE"] = true
 	setTemplateIfExists(ctx, issueTemplateKey, IssueTemplateCandidates)
+	setCommunityLinks(ctx)
 	renderAttachmentSettings(ctx)
 
 	RetrieveRepoMetas(ctx, ctx.Repo.Repository)
@@ -350,7 +385,7 @@ func ValidateRepoMetas(ctx *context.Context, form auth.CreateIssueForm) ([]int64
 		return nil, 0, 0
 	}
 
-	if !ctx.Repo.IsWriter() {
+	if !ctx.Repo.CanTriage(models.UnitTypeIssues) {
 		return nil, 0, 0
 	}
 
@@ -436,25 +471,147 @@ func NewIssuePost(ctx *context.Context, form auth.CreateIssueForm) {
 		MilestoneID: milestoneID,
 		AssigneeID:  assigneeID,
 		Content:     form.Content,
+
+		IsConfidential: form.IsConfidential,
 	}
 	if err := models.NewIssue(repo, issue, labelIDs, attachments); err != nil {
-		ctx.Handle(500, "NewIssue", err)
+		switch {
+		case models.IsErrContentBlocked(err):
+			ctx.RenderWithErr(ctx.Tr("repo.issues.content_blocked", err.(models.ErrContentBlocked).Word), tplIssueNew, &form)
+		case models.IsErrAttachmentQuotaExceeded(err):
+			ctx.RenderWithErr(attachmentQuotaMessage(ctx, err.(models.ErrAttachmentQuotaExceeded)), tplIssueNew, &form)
+		case models.IsErrContentHeldForModeration(err):
+			ctx.Flash.Info(ctx.Tr("repo.issues.held_for_moderation"))
+			ctx.Redirect(ctx.Repo.RepoLink + "/issues")
+		default:
+			ctx.Handle(500, "NewIssue", err)
+		}
 		return
 	}
 
-	notification.Service.NotifyIssue(issue, ctx.User.ID)
+	notification.Service.NotifyIssue(issue, ctx.User.ID, models.NotificationEventIssue)
 
 	log.Trace("Issue created: %d/%d", repo.ID, issue.ID)
 	ctx.Redirect(ctx.Repo.RepoLink + "/issues/" + com.ToStr(issue.Index))
 }
 
+// issueContentCacheKey returns the render cache key of the content of an
+// issue or comment of the repository.
+func issueContentCacheKey(ctx *context.Context, content string) string {
+	return context.RenderCacheKey("issue", ctx.Repo.Repository.ComposeMetas(), content, ctx.Repo.RepoLink)
+}
+
+// renderIssueContent renders the markdown content of an issue or comment,
+// the result is cached by the hash of the content.
+func renderIssueContent(ctx *context.Context, content string) string {
+	key := issueContentCacheKey(ctx, content)
+	if rendered, ok := ctx.GetRenderCache(key); ok {
+		return rendered
+	}
+
+	rendered := string(markdown.Render([]byte(content), ctx.Repo.RepoLink, ctx.Repo.Repository.ComposeMetas()))
+	ctx.PutRenderCache(key, rendered)
+	return rendered
+}
+
+// hiddenComments are the comments and events in the middle of the timeline
+// of a long discussion, which are not shown until they are loaded.
+type hiddenComments struct {
+	AfterID int64 // ID of the comment shown just before them
+	Start   int
+	Count   int
+}
+
+// issueCommentPagingNum returns the number of comments and events shown at
+// once on the page of an issue.
+func issueCommentPagingNum() int {
+	if setting.UI.IssueCommentPagingNum < 2 {
+		return 2
+	}
+	return setting.UI.IssueCommentPagingNum
+}
+
+// getShownIssueComments returns the comments and events shown on the page of
+// an issue: all of them, or the first and the last ones of a long discussion
+// with the ones in between hidden.
+func getShownIssueComments(issueID int64) ([]*models.Comment, *hiddenComments, error) {
+	total, err := models.CountCommentsByIssueID(issueID)
+	if err != nil {
+		return nil, nil, err
+	}
+	pagingNum := issueCommentPagingNum()
+	if total <= int64(pagingNum) {
+		comments, err := models.GetCommentsByIssueIDRange(issueID, 0, pagingNum)
+		return comments, nil, err
+	}
+
+	headNum := pagingNum / 2
+	tailNum := pagingNum - headNum
+	head, err := models.GetCommentsByIssueIDRange(issueID, 0, headNum)
+	if err != nil {
+		return nil, nil, err
+	}
+	tail, err := models.GetCommentsByIssueIDRange(issueID, int(total)-tailNum, tailNum)
+	if err != nil {
+		return nil, nil, err
+	} else if len(head) == 0 {
+		return tail, nil, nil
+	}
+	return append(head, tail...), &hiddenComments{
+		AfterID: head[len(head)-1].ID,
+		Start:   headNum,
+		Count:   int(total) - pagingNum,
+	}, nil
+}
+
+// prepareIssueComments renders the comments of the issue, tags their posters
+// and loads the attributes of its events. It returns the users mentioned by
+// the comments.
+func prepareIssueComments(ctx *context.Context, issue *models.Issue) []string {
+	var (
+		repo     = ctx.Repo.Repository
+		marked   = make(map[int64]models.CommentTag)
+		mentions []string
+		err      error
+	)
+	for _, comment := range issue.Comments {
+		if comment.Type == models.CommentTypeComment {
+			comment.RenderedContent = renderIssueContent(ctx, comment.Content)
+			mentions = append(mentions, markdown.FindAllMentions(comment.Content)...)
+
+			// Check tag.
+			if tag, ok := marked[comment.PosterID]; ok {
+				comment.ShowTag = tag
+				continue
+			}
+
+			if repo.IsOwnedBy(comment.PosterID) ||
+				(repo.Owner.IsOrganization() && repo.Owner.IsOwnedBy(comment.PosterID)) {
+				comment.ShowTag = models.CommentTagOwner
+			} else if comment.Poster.IsWriterOfRepo(repo) {
+				comment.ShowTag = models.CommentTagWriter
+			} else if comment.PosterID == issue.PosterID {
+				comment.ShowTag = models.CommentTagPoster
+			}
+			marked[comment.PosterID] = comment.ShowTag
+		} else if err = comment.LoadEventAttributes(); err != nil {
+			ctx.Handle(500, "LoadEventAttributes", err)
+			return nil
+		} else if err = comment.HideInvisibleRef(ctx.User); err != nil {
+			ctx.Handle(500, "HideInvisibleRef", err)
+			return nil
+		}
+	}
+	return mentions
+}
+
 // ViewIssue render issue view page
 func ViewIssue(ctx *context.Context) {
 	ctx.Data["RequireHighlightJS"] = true
 	ctx.Data["RequireDropzone"] = true
 	renderAttachmentSettings(ctx)
 
-	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"), ctx.User)
 	if err != nil {
 		if models.IsErrIssueNotExist(err) {
 			ctx.Handle(404, "GetIssueByIndex", err)
@@ -507,8 +664,7 @@ func ViewIssue(ctx *context.Context) {
 		ctx.Data["PageIsIssueList"] = true
 	}
 
-	issue.RenderedContent = string(markdown.Render([]byte(issue.Content), ctx.Repo.RepoLink,
-		ctx.Repo.Repository.ComposeMetas()))
+	issue.RenderedContent = renderIssueContent(ctx, issue.Content)
 
 	repo := ctx.Repo.Repository
 
@@ -547,7 +703,7 @@ func ViewIssue(ctx *context.Context) {
 	ctx.Data["Labels"] = labels
 
 	// Check milestone and assignee.
-	if ctx.Repo.IsWriter() {
+	if ctx.Repo.CanTriage(models.UnitTypeIssues) {
 		RetrieveRepoMilestonesAndAssignees(ctx, repo)
 		if ctx.Written() {
 			return
@@ -562,64 +718,31 @@ func ViewIssue(ctx *context.Context) {
 		}
 	}
 
-	var (
-		tag          models.CommentTag
-		ok           bool
-		marked       = make(map[int64]models.CommentTag)
-		comment      *models.Comment
-		participants = make([]*models.User, 1, 10)
-	)
+	// Render comments and fetch participants.
+	var hidden *hiddenComments
+	issue.Comments, hidden, err = getShownIssueComments(issue.ID)
+	if err != nil {
+		ctx.Handle(500, "getShownIssueComments", err)
+		return
+	}
+	mentions := append(markdown.FindAllMentions(issue.Content), prepareIssueComments(ctx, issue)...)
+	if ctx.Written() {
+		return
+	}
+	if hidden != nil {
+		ctx.Data["HiddenComments"] = hidden
+	}
 
-	// Render comments and and fetch participants.
+	posters, err := models.GetParticipantsByIssueID(issue.ID)
+	if err != nil {
+		ctx.Handle(500, "GetParticipantsByIssueID", err)
+		return
+	}
+	participants := make([]*models.User, 1, len(posters)+1)
 	participants[0] = issue.Poster
-	for _, comment = range issue.Comments {
-		if comment.Type == models.CommentTypeComment {
-			comment.RenderedContent = string(markdown.Render([]byte(comment.Content), ctx.Repo.RepoLink,
-				ctx.Repo.Repository.ComposeMetas()))
-
-			// Check tag.
-			tag, ok = marked[comment.PosterID]
-			if ok {
-				comment.ShowTag = tag
-				continue
-			}
-
-			if repo.IsOwnedBy(comment.PosterID) ||
-				(repo.Owner.IsOrganization() && repo.Owner.IsOwnedBy(comment.PosterID)) {
-				comment.ShowTag = models.CommentTagOwner
-			} else if comment.Poster.IsWriterOfRepo(repo) {
-				comment.ShowTag = models.CommentTagWriter
-			} else if comment.PosterID == issue.PosterID {
-				comment.ShowTag = models.CommentTagPoster
-			}
-
-			marked[comment.PosterID] = comment.ShowTag
-
-			isAdded := false
-			for j := range participants {
-				if comment.Poster == participants[j] {
-					isAdded = true
-					break
-				}
-			}
-			if !isAdded && !issue.IsPoster(comment.Poster.ID) {
-				participants = append(participants, comment.Poster)
-			}
-		} else if comment.Type == models.CommentTypeLabel {
-			if err = comment.LoadLabel(); err != nil {
-				ctx.Handle(500, "LoadLabel", err)
-				return
-			}
-		} else if comment.Type == models.CommentTypeMilestone {
-			if err = comment.LoadMilestone(); err != nil {
-				ctx.Handle(500, "LoadMilestone", err)
-				return
-			}
-		} else if comment.Type == models.CommentTypeAssignees {
-			if err = comment.LoadAssignees(); err != nil {
-				ctx.Handle(500, "LoadAssignees", err)
-				return
-			}
+	for _, poster := range posters {
+		if !issue.IsPoster(poster.ID) {
+			participants = append(participants, poster)
 		}
 	}
 
@@ -644,14 +767,83 @@ func ViewIssue(ctx *context.Context) {
 
 	ctx.Data["Participants"] = participants
 	ctx.Data["NumParticipants"] = len(participants)
+	ctx.Data["MentionStatuses"], err = models.GetUsersWithStatusByNames(mentions)
+	if err != nil {
+		ctx.Handle(500, "GetUsersWithStatusByNames", err)
+		return
+	}
+	if ctx.Repo.CanWrite(models.UnitTypeIssues) && !issue.IsPull {
+		ctx.Data["ServiceDeskReporter"], err = models.GetServiceDeskIssue(issue.ID)
+		if err != nil {
+			ctx.Handle(500, "GetServiceDeskIssue", err)
+			return
+		}
+	}
 	ctx.Data["Issue"] = issue
-	ctx.Data["IsIssueOwner"] = ctx.Repo.IsWriter() || (ctx.IsSigned && issue.IsPoster(ctx.User.ID))
+	ctx.Data["LinkPreview"] = issueLinkPreview(ctx.Repo.Repository, issue, issue.RenderedContent)
+	ctx.Data["IsIssueOwner"] = ctx.Repo.CanWrite(models.UnitTypeIssues) || (ctx.IsSigned && issue.IsPoster(ctx.User.ID))
+	ctx.Data["IsIssueTriager"] = ctx.Repo.CanTriage(models.UnitTypeIssues)
+	ctx.Data["CanPinIssue"] = !issue.IsPull && ctx.Repo.CanWrite(models.UnitTypeIssues)
+	ctx.Data["CanChangeConfidential"] = !issue.IsPull && ctx.Repo.CanWrite(models.UnitTypeIssues)
 	ctx.Data["SignInLink"] = setting.AppSubURL + "/user/login?redirect_to=" + ctx.Data["Link"].(string)
 	ctx.HTML(200, tplIssueView)
 }
 
+// IssueComments renders the comments and events hidden in the middle of the
+// timeline of an issue, a chunk of them at a time
+func IssueComments(ctx *context.Context) {
+	issue := getActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+	if issue.IsPull {
+		MustAllowPulls(ctx)
+	} else {
+		MustEnableIssues(ctx)
+	}
+	if ctx.Written() {
+		return
+	}
+
+	start, count := ctx.QueryInt("start"), ctx.QueryInt("count")
+	if start < 0 || count <= 0 {
+		ctx.Error(400)
+		return
+	}
+	chunkNum := issueCommentPagingNum()
+	if count < chunkNum {
+		chunkNum = count
+	}
+
+	var err error
+	issue.Comments, err = models.GetCommentsByIssueIDRange(issue.ID, start, chunkNum)
+	if err != nil {
+		ctx.Handle(500, "GetCommentsByIssueIDRange", err)
+		return
+	}
+	mentions := prepareIssueComments(ctx, issue)
+	if ctx.Written() {
+		return
+	}
+	if count > chunkNum && len(issue.Comments) > 0 {
+		ctx.Data["HiddenComments"] = &hiddenComments{
+			AfterID: issue.Comments[len(issue.Comments)-1].ID,
+			Start:   start + chunkNum,
+			Count:   count - chunkNum,
+		}
+	}
+
+	ctx.Data["MentionStatuses"], err = models.GetUsersWithStatusByNames(mentions)
+	if err != nil {
+		ctx.Handle(500, "GetUsersWithStatusByNames", err)
+		return
+	}
+	ctx.Data["Issue"] = issue
+	ctx.HTML(200, tplIssueComments)
+}
+
 func getActionIssue(ctx *context.Context) *models.Issue {
-	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"), ctx.User)
 	if err != nil {
 		if models.IsErrIssueNotExist(err) {
 			ctx.Error(404, "GetIssueByIndex")
@@ -692,7 +884,7 @@ func UpdateIssueTitle(ctx *context.Context) {
 		return
 	}
 
-	if !ctx.IsSigned || (!issue.IsPoster(ctx.User.ID) && !ctx.Repo.IsWriter()) {
+	if !ctx.IsSigned || (!issue.IsPoster(ctx.User.ID) && !ctx.Repo.CanWrite(models.UnitTypeIssues)) {
 		ctx.Error(403)
 		return
 	}
@@ -720,16 +912,22 @@ func UpdateIssueContent(ctx *context.Context) {
 		return
 	}
 
-	if !ctx.IsSigned || (ctx.User.ID != issue.PosterID && !ctx.Repo.IsWriter()) {
+	if !ctx.IsSigned || (ctx.User.ID != issue.PosterID && !ctx.Repo.CanWrite(models.UnitTypeIssues)) {
 		ctx.Error(403)
 		return
 	}
 
+	oldCacheKey := issueContentCacheKey(ctx, issue.Content)
 	content := ctx.Query("content")
 	if err := issue.ChangeContent(ctx.User, content); err != nil {
-		ctx.Handle(500, "ChangeContent", err)
+		if models.IsErrAttachmentQuotaExceeded(err) {
+			ctx.Error(413, attachmentQuotaMessage(ctx, err.(models.ErrAttachmentQuotaExceeded)))
+		} else {
+			ctx.Handle(500, "ChangeContent", err)
+		}
 		return
 	}
+	ctx.DeleteRenderCache(oldCacheKey)
 
 	ctx.JSON(200, map[string]interface{}{
 		"content": string(markdown.Render([]byte(issue.Content), ctx.Query("context"), ctx.Repo.Repository.ComposeMetas())),
@@ -817,7 +1015,7 @@ func UpdateIssueStatus(ctx *context.Context) {
 
 // NewComment create a comment for issue
 func NewComment(ctx *context.Context, form auth.CreateCommentForm) {
-	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"), ctx.User)
 	if err != nil {
 		ctx.NotFoundOrServerError("GetIssueByIndex", models.IsErrIssueNotExist, err)
 		return
@@ -837,7 +1035,7 @@ func NewComment(ctx *context.Context, form auth.CreateCommentForm) {
 	var comment *models.Comment
 	defer func() {
 		// Check if issue admin/poster changes the status of issue.
-		if (ctx.Repo.IsWriter() || (ctx.IsSigned && issue.IsPoster(ctx.User.ID))) &&
+		if (ctx.Repo.CanTriage(models.UnitTypeIssues) || (ctx.IsSigned && issue.IsPoster(ctx.User.ID))) &&
 			(form.Status == "reopen" || form.Status == "close") &&
 			!(issue.IsPull && issue.PullRequest.HasMerged) {
 
@@ -873,7 +1071,7 @@ func NewComment(ctx *context.Context, form auth.CreateCommentForm) {
 				} else {
 					log.Trace("Issue [%d] status changed to closed: %v", issue.ID, issue.IsClosed)
 
-					notification.Service.NotifyIssue(issue, ctx.User.ID)
+					notification.Service.NotifyIssue(issue, ctx.User.ID, models.NotificationEventStatus)
 				}
 			}
 		}
@@ -897,11 +1095,20 @@ func NewComment(ctx *context.Context, form auth.CreateCommentForm) {
 
 	comment, err = models.CreateIssueComment(ctx.User, ctx.Repo.Repository, issue, form.Content, attachments)
 	if err != nil {
-		ctx.Handle(500, "CreateIssueComment", err)
+		switch {
+		case models.IsErrContentBlocked(err):
+			ctx.Flash.Error(ctx.Tr("repo.issues.content_blocked", err.(models.ErrContentBlocked).Word))
+		case models.IsErrAttachmentQuotaExceeded(err):
+			ctx.Flash.Error(attachmentQuotaMessage(ctx, err.(models.ErrAttachmentQuotaExceeded)))
+		case models.IsErrContentHeldForModeration(err):
+			ctx.Flash.Info(ctx.Tr("repo.issues.held_for_moderation"))
+		default:
+			ctx.Handle(500, "CreateIssueComment", err)
+		}
 		return
 	}
 
-	notification.Service.NotifyIssue(issue, ctx.User.ID)
+	notification.Service.NotifyIssue(issue, ctx.User.ID, models.NotificationEventComment)
 
 	log.Trace("Comment created: %d/%d/%d", ctx.Repo.Repository.ID, issue.ID, comment.ID)
 }
@@ -922,6 +1129,7 @@ func UpdateCommentContent(ctx *context.Context) {
 		return
 	}
 
+	oldContent := comment.Content
 	comment.Content = ctx.Query("content")
 	if len(comment.Content) == 0 {
 		ctx.JSON(200, map[string]interface{}{
@@ -929,10 +1137,15 @@ func UpdateCommentContent(ctx *context.Context) {
 		})
 		return
 	}
-	if err = models.UpdateComment(comment); err != nil {
-		ctx.Handle(500, "UpdateComment", err)
+	if err = models.UpdateComment(ctx.User, comment, oldContent); err != nil {
+		if models.IsErrAttachmentQuotaExceeded(err) {
+			ctx.Error(413, attachmentQuotaMessage(ctx, err.(models.ErrAttachmentQuotaExceeded)))
+		} else {
+			ctx.Handle(500, "UpdateComment", err)
+		}
 		return
 	}
+	ctx.DeleteRenderCache(issueContentCacheKey(ctx, oldContent))
 
 	ctx.JSON(200, map[string]interface{}{
 		"content": string(markdown.Render([]byte(comment.Content), ctx.Query("context"), ctx.Repo.Repository.ComposeMetas())),
@@ -955,7 +1168,7 @@ func DeleteComment(ctx *context.Context) {
 		return
 	}
 
-	if err = models.DeleteComment(comment); err != nil {
+	if err = models.DeleteComment(ctx.User, comment); err != nil {
 		ctx.Handle(500, "DeleteCommentByID", err)
 		return
 	}
@@ -1164,6 +1377,32 @@ func ChangeMilestonStatus(ctx *context.Context) {
 	}
 }
 
+// MilestoneReport exports all issues of a milestone as CSV
+func MilestoneReport(ctx *context.Context) {
+	m, err := models.GetMilestoneByRepoID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrMilestoneNotExist(err) {
+			ctx.Handle(404, "", err)
+		} else {
+			ctx.Handle(500, "GetMilestoneByRepoID", err)
+		}
+		return
+	}
+
+	reports, err := m.GetIssueReport(ctx.User)
+	if err != nil {
+		ctx.Handle(500, "GetIssueReport", err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err = reports.WriteCSV(&buf); err != nil {
+		ctx.Handle(500, "WriteCSV", err)
+		return
+	}
+	ctx.ServeContent(fmt.Sprintf("%s-milestone-%d.csv", ctx.Repo.Repository.Name, m.ID), bytes.NewReader(buf.Bytes()))
+}
+
 // DeleteMilestone delete a milestone
 func DeleteMilestone(ctx *context.Context) {
 	if err := models.DeleteMilestoneByRepoID(ctx.Repo.Repository.ID, ctx.QueryInt64("id")); err != nil {
@@ -0,0 +1,38 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"code.gitea.io/gitea/modules/context"
+)
+
+// IssueConfidential changes whether an issue is visible only to its poster
+// and the users with write access to the repository
+func IssueConfidential(c *context.Context) {
+	confidential, err := strconv.ParseBool(c.Req.PostForm.Get("confidential"))
+	if err != nil {
+		c.Handle(http.StatusInternalServerError, "confidential is not bool", err)
+		return
+	}
+
+	issue := getActionIssue(c)
+	if c.Written() {
+		return
+	} else if issue.IsPull {
+		c.Handle(http.StatusNotFound, "IssueConfidential", nil)
+		return
+	}
+
+	if err = issue.ChangeConfidential(confidential); err != nil {
+		c.Handle(http.StatusInternalServerError, "ChangeConfidential", err)
+		return
+	}
+
+	c.Redirect(fmt.Sprintf("%s/issues/%d", c.Repo.RepoLink, issue.Index), http.StatusSeeOther)
+}
@@ -0,0 +1,40 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/modules/context"
+)
+
+// UpdateIssueDeadline sets or removes the deadline of an issue
+func UpdateIssueDeadline(c *context.Context) {
+	issue := getActionIssue(c)
+	if c.Written() {
+		return
+	}
+
+	var deadline time.Time
+	url := fmt.Sprintf("%s/issues/%d", c.Repo.RepoLink, issue.Index)
+	if value := c.Req.PostForm.Get("deadline"); len(value) > 0 {
+		var err error
+		deadline, err = time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			c.Flash.Error(c.Tr("repo.issues.invalid_deadline"))
+			c.Redirect(url, http.StatusSeeOther)
+			return
+		}
+	}
+
+	if err := issue.UpdateDeadline(c.User, deadline); err != nil {
+		c.Handle(http.StatusInternalServerError, "UpdateDeadline", err)
+		return
+	}
+
+	c.Redirect(url, http.StatusSeeOther)
+}
@@ -10,6 +10,7 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 )
 
 const (
@@ -85,9 +86,10 @@ func NewLabel(ctx *context.Context, form auth.CreateLabelForm) {
 	}
 
 	l := &models.Label{
-		RepoID: ctx.Repo.Repository.ID,
-		Name:   form.Title,
-		Color:  form.Color,
+		RepoID:      ctx.Repo.Repository.ID,
+		Name:        form.Title,
+		Description: form.Description,
+		Color:       form.Color,
 	}
 	if err := models.NewLabels(l); err != nil {
 		ctx.Handle(500, "NewLabel", err)
@@ -110,6 +112,7 @@ func UpdateLabel(ctx *context.Context, form auth.CreateLabelForm) {
 	}
 
 	l.Name = form.Title
+	l.Description = form.Description
 	l.Color = form.Color
 	if err := models.UpdateLabel(l); err != nil {
 		ctx.Handle(500, "UpdateLabel", err)
@@ -118,6 +121,28 @@ func UpdateLabel(ctx *context.Context, form auth.CreateLabelForm) {
 	ctx.Redirect(ctx.Repo.RepoLink + "/labels")
 }
 
+// SearchLabels returns the labels of the repository matching the keyword as JSON,
+// for type-ahead search in the issue sidebar
+func SearchLabels(ctx *context.Context) {
+	labels, err := models.SearchLabels(ctx.Repo.Repository.ID, ctx.Query("q"), setting.UI.IssuePagingNum)
+	if err != nil {
+		ctx.Handle(500, "SearchLabels", err)
+		return
+	}
+
+	results := make([]map[string]interface{}, len(labels))
+	for i, l := range labels {
+		results[i] = map[string]interface{}{
+			"id":          l.ID,
+			"name":        l.Name,
+			"description": l.Description,
+			"color":       l.Color,
+			"scope":       l.Scope(),
+		}
+	}
+	ctx.JSON(200, results)
+}
+
 // DeleteLabel delete a label
 func DeleteLabel(ctx *context.Context) {
 	if err := models.DeleteLabel(ctx.Repo.Repository.ID, ctx.QueryInt64("id")); err != nil {
@@ -0,0 +1,49 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+)
+
+// IssuePin pins or unpins an issue to the top of the issue list
+func IssuePin(c *context.Context) {
+	pin, err := strconv.ParseBool(c.Req.PostForm.Get("pin"))
+	if err != nil {
+		c.Handle(http.StatusInternalServerError, "pin is not bool", err)
+		return
+	}
+
+	issue := getActionIssue(c)
+	if c.Written() {
+		return
+	} else if issue.IsPull {
+		c.Handle(http.StatusNotFound, "IssuePin", nil)
+		return
+	}
+
+	url := fmt.Sprintf("%s/issues/%d", c.Repo.RepoLink, issue.Index)
+	if pin {
+		err = issue.Pin(c.User)
+	} else {
+		err = issue.Unpin(c.User)
+	}
+	if err != nil {
+		if models.IsErrIssueMaxPinReached(err) {
+			c.Flash.Error(c.Tr("repo.issues.max_pinned", err.(models.ErrIssueMaxPinReached).Max))
+			c.Redirect(url, http.StatusSeeOther)
+			return
+		}
+		c.Handle(http.StatusInternalServerError, "Pin", err)
+		return
+	}
+
+	c.Redirect(url, http.StatusSeeOther)
+}
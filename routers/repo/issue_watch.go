@@ -22,7 +22,7 @@ func IssueWatch(c *context.Context) {
 	}
 
 	issueIndex := c.ParamsInt64("index")
-	issue, err := models.GetIssueByIndex(c.Repo.Repository.ID, issueIndex)
+	issue, err := models.GetIssueByIndex(c.Repo.Repository.ID, issueIndex, c.User)
 	if err != nil {
 		c.Handle(http.StatusInternalServerError, "GetIssueByIndex", err)
 		return
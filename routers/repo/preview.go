@@ -0,0 +1,255 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jaytaylor/html2text"
+
+	"code.gitea.io/git"
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/avatar"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/markdown"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// linkPreviewDescriptionLength is the maximum length in runes of the
+// description of link previews.
+const linkPreviewDescriptionLength = 200
+
+// Colors of the states of issues and pull requests in link previews.
+const (
+	linkPreviewColorOpen   = "#21ba45"
+	linkPreviewColorClosed = "#db2828"
+	linkPreviewColorMerged = "#a333c8"
+)
+
+// LinkPreview is what chat tools and social networks show of a page when its
+// link is shared, it is told to them as OpenGraph and oEmbed metadata.
+type LinkPreview struct {
+	Type        string
+	Title       string
+	Description string
+	URL         string
+	Image       string
+	AuthorName  string
+	AuthorURL   string
+	Color       string
+}
+
+// previewExcerpt returns the beginning of the text of given HTML, on a single
+// line.
+func previewExcerpt(html string) string {
+	text, err := html2text.FromString(html)
+	if err != nil {
+		return ""
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > linkPreviewDescriptionLength {
+		text = string(runes[:linkPreviewDescriptionLength-3]) + "..."
+	}
+	return text
+}
+
+// repoLinkPreview returns the preview of the link to a repository.
+func repoLinkPreview(repo *models.Repository) *LinkPreview {
+	owner := repo.MustOwner()
+	return &LinkPreview{
+		Type:        "object",
+		Title:       repo.FullName(),
+		Description: repo.Description,
+		URL:         repo.HTMLURL(),
+		Image:       repo.AvatarLink(),
+		AuthorName:  owner.DisplayName(),
+		AuthorURL:   owner.HTMLURL(),
+	}
+}
+
+// issueLinkPreview returns the preview of the link to an issue or a pull
+// request, whose content is rendered to HTML.
+func issueLinkPreview(repo *models.Repository, issue *models.Issue, renderedContent string) *LinkPreview {
+	kind, color := "Issue", linkPreviewColorOpen
+	if issue.IsPull {
+		kind = "Pull Request"
+	}
+	if issue.IsPull && issue.PullRequest != nil && issue.PullRequest.HasMerged {
+		color = linkPreviewColorMerged
+	} else if issue.IsClosed {
+		color = linkPreviewColorClosed
+	}
+
+	return &LinkPreview{
+		Type:        "object",
+		Title:       fmt.Sprintf("%s · %s #%d · %s", issue.Title, kind, issue.Index, repo.FullName()),
+		Description: previewExcerpt(renderedContent),
+		URL:         issue.HTMLURL(),
+		Image:       issue.Poster.AvatarLink(),
+		AuthorName:  issue.Poster.DisplayName(),
+		AuthorURL:   issue.Poster.HTMLURL(),
+		Color:       color,
+	}
+}
+
+// commitLinkPreview returns the preview of the link to a commit, the avatar
+// of the repository is shown if the author is not a user.
+func commitLinkPreview(repo *models.Repository, commit *git.Commit) *LinkPreview {
+	commitID := commit.ID.String()
+	preview := &LinkPreview{
+		Type:        "object",
+		Title:       fmt.Sprintf("%s · %s@%s", commit.Summary(), repo.FullName(), commitID[:10]),
+		Description: previewExcerpt(strings.TrimSpace(strings.TrimPrefix(commit.Message(), commit.Summary()))),
+		URL:         repo.HTMLURL() + "/commit/" + commitID,
+		Image:       repo.AvatarLink(),
+		AuthorName:  commit.Author.Name,
+	}
+	if author := models.ValidateCommitWithEmail(commit); author != nil {
+		preview.Image = author.AvatarLink()
+		preview.AuthorName = author.DisplayName()
+		preview.AuthorURL = author.HTMLURL()
+	}
+	return preview
+}
+
+// resolveLinkPreview returns the preview of the page at given URL of the
+// site, or nil if it does not exist or the user can't see it.
+func resolveLinkPreview(ctx *context.Context, link string) (*LinkPreview, error) {
+	if !strings.HasPrefix(link, setting.AppURL) {
+		return nil, nil
+	}
+	link = strings.SplitN(strings.SplitN(link[len(setting.AppURL):], "?", 2)[0], "#", 2)[0]
+	parts := strings.Split(strings.Trim(link, "/"), "/")
+	if len(parts) < 2 {
+		return nil, nil
+	}
+
+	owner, err := models.GetUserByName(parts[0])
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	repo, err := models.GetRepositoryByName(owner.ID, parts[1])
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	repo.Owner = owner
+
+	var userID int64
+	if ctx.IsSigned {
+		userID = ctx.User.ID
+	}
+	canRead := func(unitType models.UnitType) (bool, error) {
+		if !repo.EnableUnit(unitType) {
+			return false, nil
+		}
+		mode, err := models.UnitAccessLevel(userID, repo, unitType)
+		return mode >= models.AccessModeRead, err
+	}
+	if ok, err := canRead(models.UnitTypeCode); err != nil || !ok {
+		if mode, err := models.AccessLevel(userID, repo); err != nil || mode < models.AccessModeRead {
+			return nil, err
+		}
+	}
+
+	if len(parts) == 4 && (parts[2] == "issues" || parts[2] == "pulls") {
+		unitType := models.UnitTypeIssues
+		if parts[2] == "pulls" {
+			unitType = models.UnitTypePullRequests
+		}
+		var index int64
+		if _, err := fmt.Sscanf(parts[3], "%d", &index); err != nil {
+			return nil, nil
+		} else if ok, err := canRead(unitType); err != nil || !ok {
+			return nil, err
+		}
+
+		issue, err := models.GetIssueByIndex(repo.ID, index, ctx.User)
+		if err != nil {
+			if models.IsErrIssueNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		rendered := string(markdown.Render([]byte(issue.Content), repo.Link(), repo.ComposeMetas()))
+		return issueLinkPreview(repo, issue, rendered), nil
+	}
+
+	if len(parts) == 4 && parts[2] == "commit" {
+		if ok, err := canRead(models.UnitTypeCode); err != nil || !ok || repo.IsBare {
+			return nil, err
+		}
+		gitRepo, err := git.OpenRepository(repo.RepoPath())
+		if err != nil {
+			return nil, err
+		}
+		commit, err := gitRepo.GetCommit(parts[3])
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return commitLinkPreview(repo, commit), nil
+	}
+
+	return repoLinkPreview(repo), nil
+}
+
+// oEmbedResponse is the description of a link in the oEmbed format.
+type oEmbedResponse struct {
+	Version         string `json:"version"`
+	Type            string `json:"type"`
+	Title           string `json:"title"`
+	AuthorName      string `json:"author_name,omitempty"`
+	AuthorURL       string `json:"author_url,omitempty"`
+	ProviderName    string `json:"provider_name"`
+	ProviderURL     string `json:"provider_url"`
+	ThumbnailURL    string `json:"thumbnail_url,omitempty"`
+	ThumbnailWidth  int    `json:"thumbnail_width,omitempty"`
+	ThumbnailHeight int    `json:"thumbnail_height,omitempty"`
+}
+
+// OEmbed describes the issue, pull request, commit or repository of the "url"
+// query parameter in the oEmbed format, for chat tools unfurling its link
+func OEmbed(ctx *context.Context) {
+	if format := ctx.Query("format"); len(format) > 0 && format != "json" {
+		ctx.Error(501)
+		return
+	}
+
+	preview, err := resolveLinkPreview(ctx, ctx.Query("url"))
+	if err != nil {
+		log.Error(4, "resolveLinkPreview: %v", err)
+		ctx.Error(500)
+		return
+	} else if preview == nil {
+		ctx.Error(404)
+		return
+	}
+
+	resp := &oEmbedResponse{
+		Version:      "1.0",
+		Type:         "link",
+		Title:        preview.Title,
+		AuthorName:   preview.AuthorName,
+		AuthorURL:    preview.AuthorURL,
+		ProviderName: setting.AppName,
+		ProviderURL:  setting.AppURL,
+	}
+	if len(preview.Image) > 0 {
+		resp.ThumbnailURL = preview.Image
+		resp.ThumbnailWidth = avatar.AvatarSize
+		resp.ThumbnailHeight = avatar.AvatarSize
+	}
+	ctx.JSON(200, resp)
+}
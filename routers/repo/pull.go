@@ -27,6 +27,7 @@ const (
 	tplComparePull base.TplName = "repo/pulls/compare"
 	tplPullCommits base.TplName = "repo/pulls/commits"
 	tplPullFiles   base.TplName = "repo/pulls/files"
+	tplDiffFiles   base.TplName = "repo/diff/files"
 
 	pullRequestTemplateKey = "PullRequestTemplate"
 )
@@ -140,7 +141,7 @@ func ForkPost(ctx *context.Context, form auth.CreateRepoForm) {
 }
 
 func checkPullInfo(ctx *context.Context) *models.Issue {
-	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"), ctx.User)
 	if err != nil {
 		if models.IsErrIssueNotExist(err) {
 			ctx.Handle(404, "GetIssueByIndex", err)
@@ -191,6 +192,13 @@ func PrepareMergedViewPullInfo(ctx *context.Context, issue *models.Issue) {
 		ctx.Handle(500, "Repo.GitRepo.FilesCountBetween", err)
 		return
 	}
+
+	backports, err := pull.GetBackports()
+	if err != nil {
+		log.Error(4, "GetBackports: %v", err)
+	}
+	ctx.Data["Backports"] = backports
+	ctx.Data["CanBackport"] = ctx.Repo.CanWrite(models.UnitTypeCode)
 }
 
 // PrepareViewPullInfo show meta information for a pull request preview page
@@ -243,9 +251,73 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.PullReq
 	}
 	ctx.Data["NumCommits"] = prInfo.Commits.Len()
 	ctx.Data["NumFiles"] = prInfo.NumFiles
+
+	if pull.Status == models.PullRequestStatusConflict {
+		conflicts, err := pull.GetConflictedFiles()
+		if err != nil {
+			log.Error(4, "GetConflictedFiles: %v", err)
+		}
+		ctx.Data["ConflictedFiles"] = conflicts
+		ctx.Data["HeadCloneLink"] = pull.HeadRepo.CloneLink().HTTPS
+		ctx.Data["MergeBranchName"] = pull.HeadUserName + "-" + pull.HeadBranch
+	}
+
+	if !issue.IsClosed {
+		behind, err := pull.CommitsBehind(prInfo.MergeBase)
+		if err != nil {
+			log.Error(4, "CommitsBehind: %v", err)
+		}
+		ctx.Data["CommitsBehind"] = behind
+		ctx.Data["CanUpdateBranch"] = behind > 0 && canUpdatePullBranch(ctx, pull)
+		ctx.Data["UpdateBranchByRebase"] = setting.Repository.PullRequestUpdateStyle == "rebase"
+
+		missing, err := pull.GetMissingStatusContexts()
+		if err != nil {
+			log.Error(4, "GetMissingStatusContexts: %v", err)
+		}
+		ctx.Data["MissingStatusContexts"] = strings.Join(missing, ", ")
+		ctx.Data["CanChangeBaseBranch"] = ctx.Repo.CanWrite(models.UnitTypePullRequests)
+
+		reviews, err := pull.GetReviews()
+		if err != nil {
+			log.Error(4, "GetReviews: %v", err)
+		}
+		ctx.Data["Reviews"] = reviews
+		ctx.Data["CanReview"] = ctx.IsSigned && ctx.Repo.CanWrite(models.UnitTypePullRequests)
+
+		approvals, required, err := pull.GetApprovals()
+		if err != nil {
+			log.Error(4, "GetApprovals: %v", err)
+		}
+		ctx.Data["Approvals"] = approvals
+		ctx.Data["RequiredApprovals"] = required
+	}
 	return prInfo
 }
 
+// canUpdatePullBranch returns true if the signed in user is allowed to push
+// to the head branch of the pull request.
+func canUpdatePullBranch(ctx *context.Context, pull *models.PullRequest) bool {
+	if !ctx.IsSigned || pull.HeadRepo == nil {
+		return false
+	}
+
+	has, err := models.HasAccess(ctx.User.ID, pull.HeadRepo, models.AccessModeWrite)
+	if err != nil {
+		log.Error(4, "HasAccess: %v", err)
+		return false
+	} else if !has {
+		return false
+	}
+
+	protected, err := pull.HeadRepo.IsProtectedBranch(pull.HeadBranch)
+	if err != nil {
+		log.Error(4, "IsProtectedBranch: %v", err)
+		return false
+	}
+	return !protected
+}
+
 // ViewPullCommits show commits for a pull request
 func ViewPullCommits(ctx *context.Context) {
 	ctx.Data["PageIsPullList"] = true
@@ -301,74 +373,64 @@ func ViewPullCommits(ctx *context.Context) {
 	ctx.HTML(200, tplPullCommits)
 }
 
-// ViewPullFiles render pull request changed files list page
-func ViewPullFiles(ctx *context.Context) {
-	ctx.Data["PageIsPullList"] = true
-	ctx.Data["PageIsPullFiles"] = true
+// pullDiffRange represents the commits a pull request diff is built from.
+type pullDiffRange struct {
+	RepoPath      string
+	StartCommitID string
+	EndCommitID   string
+	GitRepo       *git.Repository
+}
 
-	issue := checkPullInfo(ctx)
-	if ctx.Written() {
-		return
-	}
+func preparePullDiffRange(ctx *context.Context, issue *models.Issue) *pullDiffRange {
 	pull := issue.PullRequest
 
-	var (
-		diffRepoPath  string
-		startCommitID string
-		endCommitID   string
-		gitRepo       *git.Repository
-	)
-
 	if pull.HasMerged {
 		PrepareMergedViewPullInfo(ctx, issue)
 		if ctx.Written() {
-			return
+			return nil
 		}
 
-		diffRepoPath = ctx.Repo.GitRepo.Path
-		startCommitID = pull.MergeBase
-		endCommitID = pull.MergedCommitID
-		gitRepo = ctx.Repo.GitRepo
-	} else {
-		prInfo := PrepareViewPullInfo(ctx, issue)
-		if ctx.Written() {
-			return
-		} else if prInfo == nil {
-			ctx.Handle(404, "ViewPullFiles", nil)
-			return
+		return &pullDiffRange{
+			RepoPath:      ctx.Repo.GitRepo.Path,
+			StartCommitID: pull.MergeBase,
+			EndCommitID:   pull.MergedCommitID,
+			GitRepo:       ctx.Repo.GitRepo,
 		}
+	}
 
-		headRepoPath := models.RepoPath(pull.HeadUserName, pull.HeadRepo.Name)
+	prInfo := PrepareViewPullInfo(ctx, issue)
+	if ctx.Written() {
+		return nil
+	} else if prInfo == nil {
+		ctx.Handle(404, "ViewPullFiles", nil)
+		return nil
+	}
 
-		headGitRepo, err := git.OpenRepository(headRepoPath)
-		if err != nil {
-			ctx.Handle(500, "OpenRepository", err)
-			return
-		}
+	headRepoPath := models.RepoPath(pull.HeadUserName, pull.HeadRepo.Name)
 
-		headCommitID, err := headGitRepo.GetBranchCommitID(pull.HeadBranch)
-		if err != nil {
-			ctx.Handle(500, "GetBranchCommitID", err)
-			return
-		}
-
-		diffRepoPath = headRepoPath
-		startCommitID = prInfo.MergeBase
-		endCommitID = headCommitID
-		gitRepo = headGitRepo
+	headGitRepo, err := git.OpenRepository(headRepoPath)
+	if err != nil {
+		ctx.Handle(500, "OpenRepository", err)
+		return nil
 	}
 
-	diff, err := models.GetDiffRange(diffRepoPath,
-		startCommitID, endCommitID, setting.Git.MaxGitDiffLines,
-		setting.Git.MaxGitDiffLineCharacters, setting.Git.MaxGitDiffFiles)
+	headCommitID, err := headGitRepo.GetBranchCommitID(pull.HeadBranch)
 	if err != nil {
-		ctx.Handle(500, "GetDiffRange", err)
-		return
+		ctx.Handle(500, "GetBranchCommitID", err)
+		return nil
 	}
-	ctx.Data["Diff"] = diff
-	ctx.Data["DiffNotAvailable"] = diff.NumFiles() == 0
 
-	commit, err := gitRepo.GetCommit(endCommitID)
+	return &pullDiffRange{
+		RepoPath:      headRepoPath,
+		StartCommitID: prInfo.MergeBase,
+		EndCommitID:   headCommitID,
+		GitRepo:       headGitRepo,
+	}
+}
+
+// setPullDiffPaths sets the links used to render the files of a pull request diff.
+func setPullDiffPaths(ctx *context.Context, pull *models.PullRequest, diffRange *pullDiffRange) {
+	commit, err := diffRange.GitRepo.GetCommit(diffRange.EndCommitID)
 	if err != nil {
 		ctx.Handle(500, "GetCommit", err)
 		return
@@ -378,14 +440,126 @@ func ViewPullFiles(ctx *context.Context) {
 	ctx.Data["Username"] = pull.HeadUserName
 	ctx.Data["Reponame"] = pull.HeadRepo.Name
 	ctx.Data["IsImageFile"] = commit.IsImageFile
-	ctx.Data["SourcePath"] = setting.AppSubURL + "/" + path.Join(headTarget, "src", endCommitID)
-	ctx.Data["BeforeSourcePath"] = setting.AppSubURL + "/" + path.Join(headTarget, "src", startCommitID)
-	ctx.Data["RawPath"] = setting.AppSubURL + "/" + path.Join(headTarget, "raw", endCommitID)
+	ctx.Data["SourcePath"] = setting.AppSubURL + "/" + path.Join(headTarget, "src", diffRange.EndCommitID)
+	ctx.Data["BeforeSourcePath"] = setting.AppSubURL + "/" + path.Join(headTarget, "src", diffRange.StartCommitID)
+	ctx.Data["RawPath"] = setting.AppSubURL + "/" + path.Join(headTarget, "raw", diffRange.EndCommitID)
 	ctx.Data["RequireHighlightJS"] = true
+}
+
+// ViewPullFiles render pull request changed files list page
+func ViewPullFiles(ctx *context.Context) {
+	ctx.Data["PageIsPullList"] = true
+	ctx.Data["PageIsPullFiles"] = true
+
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	diffRange := preparePullDiffRange(ctx, issue)
+	if ctx.Written() {
+		return
+	}
+
+	diff, err := models.GetDiffRange(diffRange.RepoPath,
+		diffRange.StartCommitID, diffRange.EndCommitID, setting.Git.MaxGitDiffLines,
+		setting.Git.MaxGitDiffLineCharacters, setting.Git.MaxGitDiffFiles)
+	if err != nil {
+		ctx.Handle(500, "GetDiffRange", err)
+		return
+	}
+	ctx.Data["Diff"] = diff
+	ctx.Data["DiffNotAvailable"] = diff.NumFiles() == 0
+
+	// Files beyond the limit are listed and can be loaded on demand.
+	if diff.IsIncomplete {
+		stats, err := models.GetDiffRangeFileStats(diffRange.RepoPath,
+			diffRange.StartCommitID, diffRange.EndCommitID)
+		if err != nil {
+			ctx.Handle(500, "GetDiffRangeFileStats", err)
+			return
+		}
+		if len(stats) > diff.NumFiles() {
+			ctx.Data["RemainingFiles"] = stats[diff.NumFiles():]
+			ctx.Data["DiffLoadBatch"] = setting.Git.MaxGitDiffFiles
+		}
+	}
+
+	setPullDiffPaths(ctx, issue.PullRequest, diffRange)
+	if ctx.Written() {
+		return
+	}
 
 	ctx.HTML(200, tplPullFiles)
 }
 
+// ViewPullFileList returns the changed files of a pull request with their
+// numbers of changed lines as JSON.
+func ViewPullFileList(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	diffRange := preparePullDiffRange(ctx, issue)
+	if ctx.Written() {
+		return
+	}
+
+	stats, err := models.GetDiffRangeFileStats(diffRange.RepoPath,
+		diffRange.StartCommitID, diffRange.EndCommitID)
+	if err != nil {
+		ctx.Handle(500, "GetDiffRangeFileStats", err)
+		return
+	}
+	ctx.JSON(200, stats)
+}
+
+// ViewPullFileDiff renders the diff of a single file of a pull request.
+func ViewPullFileDiff(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	name := ctx.Query("file")
+	if len(name) == 0 {
+		ctx.Handle(404, "ViewPullFileDiff", nil)
+		return
+	}
+
+	diffRange := preparePullDiffRange(ctx, issue)
+	if ctx.Written() {
+		return
+	}
+
+	file, err := models.GetDiffRangeFile(diffRange.RepoPath,
+		diffRange.StartCommitID, diffRange.EndCommitID, name, ctx.Query("old"),
+		setting.Git.MaxGitDiffLines, setting.Git.MaxGitDiffLineCharacters)
+	if err != nil {
+		ctx.Handle(500, "GetDiffRangeFile", err)
+		return
+	} else if file == nil {
+		ctx.Handle(404, "GetDiffRangeFile", nil)
+		return
+	}
+	if index := ctx.QueryInt("index"); index > 0 {
+		file.Index = index
+	}
+	ctx.Data["Diff"] = &models.Diff{
+		TotalAddition: file.Addition,
+		TotalDeletion: file.Deletion,
+		Files:         []*models.DiffFile{file},
+	}
+
+	setPullDiffPaths(ctx, issue.PullRequest, diffRange)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.HTML(200, tplDiffFiles)
+}
+
 // MergePullRequest response for merging pull request
 func MergePullRequest(ctx *context.Context) {
 	issue := checkPullInfo(ctx)
@@ -412,67 +586,214 @@ func MergePullRequest(ctx *context.Context) {
 		return
 	}
 
+	missing, err := pr.GetMissingStatusContexts()
+	if err != nil {
+		ctx.Handle(500, "GetMissingStatusContexts", err)
+		return
+	} else if len(missing) > 0 {
+		ctx.Flash.Error(ctx.Tr("repo.pulls.required_status_missing", strings.Join(missing, ", ")))
+		ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+		return
+	}
+
+	approvals, required, err := pr.GetApprovals()
+	if err != nil {
+		ctx.Handle(500, "GetApprovals", err)
+		return
+	} else if approvals < required {
+		ctx.Flash.Error(ctx.Tr("repo.pulls.required_approvals_missing", approvals, required))
+		ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+		return
+	}
+
 	pr.Issue = issue
 	pr.Issue.Repo = ctx.Repo.Repository
-	if err = pr.Merge(ctx.User, ctx.Repo.GitRepo); err != nil {
+	if err = pr.Merge(ctx.User, ctx.Repo.GitRepo, models.MergeStyleMerge, ""); err != nil {
 		ctx.Handle(500, "Merge", err)
 		return
 	}
 
-	notification.Service.NotifyIssue(pr.Issue, ctx.User.ID)
+	notification.Service.NotifyIssue(pr.Issue, ctx.User.ID, models.NotificationEventStatus)
 
 	log.Trace("Pull request merged: %d", pr.ID)
 	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
 }
 
+// UpdatePullRequestBranch brings the head branch of a pull request up to date
+// with its base branch
+func UpdatePullRequestBranch(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+	if issue.IsClosed || pr.HasMerged || !canUpdatePullBranch(ctx, pr) {
+		ctx.Handle(404, "UpdatePullRequestBranch", nil)
+		return
+	}
+
+	pr.Issue = issue
+	pr.Issue.Repo = ctx.Repo.Repository
+	if err := pr.UpdateBranch(ctx.User, setting.Repository.PullRequestUpdateStyle == "rebase"); err != nil {
+		if models.IsErrPullRequestUpdateConflict(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.update_branch_conflict"))
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
+		}
+		ctx.Handle(500, "UpdateBranch", err)
+		return
+	}
+
+	log.Trace("Pull request branch updated: %d", pr.ID)
+	ctx.Flash.Success(ctx.Tr("repo.pulls.update_branch_success"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+}
+
+// BackportPullRequest cherry-picks the commits of a merged pull request onto a
+// release branch, and opens a pull request to merge them
+func BackportPullRequest(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+	branch := ctx.Query("branch")
+	if !pr.HasMerged || branch == pr.BaseBranch || !models.IsBackportBranch(branch) || !ctx.Repo.GitRepo.IsBranchExist(branch) {
+		ctx.Handle(404, "BackportPullRequest", nil)
+		return
+	}
+
+	pr.Issue = issue
+	pr.Issue.Repo = ctx.Repo.Repository
+	backport, err := pr.Backport(ctx.User, branch)
+	if err != nil {
+		if models.IsErrPullRequestBackportConflict(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.backport_conflict", branch))
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
+		} else if models.IsErrBranchAlreadyExist(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.backport_branch_exists", err.(models.ErrBranchAlreadyExist).Name))
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
+		}
+		ctx.Handle(500, "Backport", err)
+		return
+	}
+
+	notification.Service.NotifyIssue(backport.Issue, ctx.User.ID, models.NotificationEventPullRequest)
+
+	log.Trace("Pull request backported: %d -> %d", pr.ID, backport.ID)
+	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(backport.Index))
+}
+
+// SubmitPullReview response for approving or requesting changes to a pull request
+func SubmitPullReview(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+	if issue.IsClosed || pr.HasMerged {
+		ctx.Handle(404, "SubmitPullReview", nil)
+		return
+	}
+
+	var typ models.ReviewType
+	switch ctx.Query("type") {
+	case "approve":
+		typ = models.ReviewTypeApprove
+	case "reject":
+		typ = models.ReviewTypeReject
+	default:
+		ctx.Handle(404, "SubmitPullReview", nil)
+		return
+	}
+
+	pr.Issue = issue
+	if _, err := models.SubmitReview(ctx.User, pr, typ); err != nil {
+		if models.IsErrReviewSelfApproval(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.review_self_approval"))
+			ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
+		}
+		ctx.Handle(500, "SubmitReview", err)
+		return
+	}
+
+	log.Trace("Pull request reviewed: %d", pr.ID)
+	ctx.Flash.Success(ctx.Tr("repo.pulls.review_success"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+}
+
+// ChangePullBaseBranch retargets an open pull request to another branch of
+// the repository
+func ChangePullBaseBranch(ctx *context.Context, form auth.ChangePullBaseForm) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+	if issue.IsClosed || pr.HasMerged {
+		ctx.Handle(404, "ChangePullBaseBranch", nil)
+		return
+	}
+
+	pullLink := ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index)
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(pullLink)
+		return
+	}
+
+	pr.Issue = issue
+	pr.Issue.Repo = ctx.Repo.Repository
+	if err := pr.ChangeBaseBranch(ctx.User, form.BaseBranch); err != nil {
+		switch {
+		case models.IsErrBranchNotExist(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.base_branch_not_exist", form.BaseBranch))
+		case models.IsErrPullRequestBaseIsHead(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.base_branch_is_head"))
+		case models.IsErrPullRequestAlreadyExists(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.base_branch_has_pull_request", form.BaseBranch))
+		default:
+			ctx.Handle(500, "ChangeBaseBranch", err)
+			return
+		}
+		ctx.Redirect(pullLink)
+		return
+	}
+
+	log.Trace("Pull request base branch changed: %d", pr.ID)
+	ctx.Flash.Success(ctx.Tr("repo.pulls.change_base_branch_success", form.BaseBranch))
+	ctx.Redirect(pullLink)
+}
+
 // ParseCompareInfo parse compare info between two commit for preparing pull request
 func ParseCompareInfo(ctx *context.Context) (*models.User, *models.Repository, *git.Repository, *git.PullRequestInfo, string, string) {
 	baseRepo := ctx.Repo.Repository
 
 	// Get compared branches information
-	// format: <base branch>...[<head repo>:]<head branch>
+	// format: <base branch>...[<head owner>[/<head repo>]:]<head branch>
 	// base<-head: master...head:feature
 	// same repo: master...feature
-	infos := strings.Split(ctx.Params("*"), "...")
-	if len(infos) != 2 {
-		log.Trace("ParseCompareInfo[%d]: not enough compared branches information %s", baseRepo.ID, infos)
-		ctx.Handle(404, "CompareAndPullRequest", nil)
+	cs, err := models.ParseCompareSpec(baseRepo, ctx.User, ctx.Params("*"))
+	if err != nil {
+		if models.IsErrInvalidCompareSpec(err) || models.IsErrUserNotExist(err) || models.IsErrRepoNotExist(err) {
+			log.Trace("ParseCompareInfo[%d]: %v", baseRepo.ID, err)
+			ctx.Handle(404, "ParseCompareSpec", nil)
+		} else {
+			ctx.Handle(500, "ParseCompareSpec", err)
+		}
 		return nil, nil, nil, nil, "", ""
 	}
 
-	baseBranch := infos[0]
+	baseBranch := cs.BaseRef
 	ctx.Data["BaseBranch"] = baseBranch
 
-	var (
-		headUser   *models.User
-		headBranch string
-		isSameRepo bool
-		err        error
-	)
-
-	// If there is no head repository, it means pull request between same repository.
-	headInfos := strings.Split(infos[1], ":")
-	if len(headInfos) == 1 {
-		isSameRepo = true
-		headUser = ctx.Repo.Owner
-		headBranch = headInfos[0]
-
-	} else if len(headInfos) == 2 {
-		headUser, err = models.GetUserByName(headInfos[0])
-		if err != nil {
-			if models.IsErrUserNotExist(err) {
-				ctx.Handle(404, "GetUserByName", nil)
-			} else {
-				ctx.Handle(500, "GetUserByName", err)
-			}
-			return nil, nil, nil, nil, "", ""
-		}
-		headBranch = headInfos[1]
-		isSameRepo = headUser.ID == ctx.Repo.Owner.ID
-	} else {
-		ctx.Handle(404, "CompareAndPullRequest", nil)
-		return nil, nil, nil, nil, "", ""
-	}
+	headRepo := cs.HeadRepo
+	headUser := headRepo.Owner
+	headBranch := cs.HeadRef
+	isSameRepo := headRepo.ID == baseRepo.ID
 	ctx.Data["HeadUser"] = headUser
 	ctx.Data["HeadBranch"] = headBranch
 	ctx.Repo.PullRequest.SameRepo = isSameRepo
@@ -483,10 +804,9 @@ func ParseCompareInfo(ctx *context.Context) (*models.User, *models.Repository, *
 		return nil, nil, nil, nil, "", ""
 	}
 
-	// Check if current user has fork of repository or in the same repository.
-	headRepo, has := models.HasForkedRepo(headUser.ID, baseRepo.ID)
-	if !has && !isSameRepo {
-		log.Trace("ParseCompareInfo[%d]: does not have fork or in same repository", baseRepo.ID)
+	// Check if head repository is a fork of the repository or the same repository.
+	if !isSameRepo && headRepo.ForkID != baseRepo.ID {
+		log.Trace("ParseCompareInfo[%d]: head repository is not a fork", baseRepo.ID)
 		ctx.Handle(404, "ParseCompareInfo", nil)
 		return nil, nil, nil, nil, "", ""
 	}
@@ -600,6 +920,7 @@ func CompareAndPullRequest(ctx *context.Context) {
 	ctx.Data["IsDiffCompare"] = true
 	ctx.Data["RequireHighlightJS"] = true
 	setTemplateIfExists(ctx, pullRequestTemplateKey, pullRequestTemplateCandidates)
+	setCommunityLinks(ctx)
 	renderAttachmentSettings(ctx)
 
 	headUser, headRepo, headGitRepo, prInfo, baseBranch, headBranch := ParseCompareInfo(ctx)
@@ -708,14 +1029,19 @@ func CompareAndPullRequestPost(ctx *context.Context, form auth.CreateIssueForm)
 	// FIXME: check error in the case two people send pull request at almost same time, give nice error prompt
 	// instead of 500.
 	if err := models.NewPullRequest(repo, pullIssue, labelIDs, attachments, pullRequest, patch); err != nil {
-		ctx.Handle(500, "NewPullRequest", err)
+		if models.IsErrAttachmentQuotaExceeded(err) {
+			ctx.Flash.Error(attachmentQuotaMessage(ctx, err.(models.ErrAttachmentQuotaExceeded)))
+			ctx.Redirect(ctx.Repo.RepoLink + "/compare/" + ctx.Params("*"))
+		} else {
+			ctx.Handle(500, "NewPullRequest", err)
+		}
 		return
 	} else if err := pullRequest.PushToBaseRepo(); err != nil {
 		ctx.Handle(500, "PushToBaseRepo", err)
 		return
 	}
 
-	notification.Service.NotifyIssue(pullIssue, ctx.User.ID)
+	notification.Service.NotifyIssue(pullIssue, ctx.User.ID, models.NotificationEventPullRequest)
 
 	log.Trace("Pull request created: %d/%d", repo.ID, pullIssue.ID)
 	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pullIssue.Index))
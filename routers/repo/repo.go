@@ -242,7 +242,16 @@ func Action(ctx *context.Context) {
 	var err error
 	switch ctx.Params(":action") {
 	case "watch":
-		err = models.WatchRepo(ctx.User.ID, ctx.Repo.Repository.ID, true)
+		if len(ctx.Query("mode")) == 0 {
+			err = models.WatchRepo(ctx.User.ID, ctx.Repo.Repository.ID, true)
+			break
+		}
+		mode, ok := models.ParseRepoWatchMode(ctx.Query("mode"))
+		if !ok {
+			ctx.Error(400)
+			return
+		}
+		err = models.SetWatchMode(ctx.User.ID, ctx.Repo.Repository.ID, mode)
 	case "unwatch":
 		err = models.WatchRepo(ctx.User.ID, ctx.Repo.Repository.ID, false)
 	case "star":
@@ -0,0 +1,102 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"github.com/go-macaron/captcha"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplServiceDesk base.TplName = "repo/service_desk"
+)
+
+// serviceDesk loads the service desk and repository of the public form,
+// it responds 404 if the desk does not exist or issues are disabled.
+func serviceDesk(ctx *context.Context) (*models.ServiceDesk, *models.Repository) {
+	desk, err := models.GetServiceDeskByToken(ctx.Params(":token"))
+	if err != nil {
+		if models.IsErrServiceDeskNotExist(err) {
+			ctx.Handle(404, "GetServiceDeskByToken", nil)
+		} else {
+			ctx.Handle(500, "GetServiceDeskByToken", err)
+		}
+		return nil, nil
+	}
+
+	repo, err := models.GetRepositoryByID(desk.RepoID)
+	if err != nil {
+		ctx.Handle(500, "GetRepositoryByID", err)
+		return nil, nil
+	}
+	if !repo.EnableUnit(models.UnitTypeIssues) {
+		ctx.Handle(404, "ServiceDesk", nil)
+		return nil, nil
+	}
+	if err = repo.GetOwner(); err != nil {
+		ctx.Handle(500, "GetOwner", err)
+		return nil, nil
+	}
+
+	ctx.Data["Title"] = ctx.Tr("repo.service_desk.title", repo.FullName())
+	ctx.Data["ServiceDeskRepo"] = repo
+	ctx.Data["EnableCaptcha"] = setting.Service.EnableCaptcha
+	return desk, repo
+}
+
+// ServiceDesk render the public form of the service desk of a repository
+func ServiceDesk(ctx *context.Context) {
+	serviceDesk(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.HTML(200, tplServiceDesk)
+}
+
+// ServiceDeskPost response for opening an issue through the service desk
+func ServiceDeskPost(ctx *context.Context, cpt *captcha.Captcha, form auth.ServiceDeskForm) {
+	desk, repo := serviceDesk(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplServiceDesk)
+		return
+	}
+
+	if setting.Service.EnableCaptcha && !cpt.VerifyReq(ctx.Req) {
+		ctx.Data["Err_Captcha"] = true
+		ctx.RenderWithErr(ctx.Tr("form.captcha_incorrect"), tplServiceDesk, &form)
+		return
+	}
+
+	issue, err := desk.NewServiceDeskIssue(form.Title, form.Content, form.Name, form.Email)
+	if err != nil {
+		switch {
+		case models.IsErrContentBlocked(err):
+			ctx.RenderWithErr(ctx.Tr("repo.issues.content_blocked", err.(models.ErrContentBlocked).Word), tplServiceDesk, &form)
+		case models.IsErrContentHeldForModeration(err):
+			ctx.Flash.Info(ctx.Tr("repo.service_desk.held_for_moderation"))
+			ctx.Redirect(ctx.Req.URL.Path)
+		default:
+			ctx.Handle(500, "NewServiceDeskIssue", err)
+		}
+		return
+	}
+
+	notification.Service.NotifyIssue(issue, issue.PosterID, models.NotificationEventIssue)
+
+	log.Trace("Issue created through service desk: %d/%d", repo.ID, issue.ID)
+	ctx.Flash.Success(ctx.Tr("repo.service_desk.success", issue.Index))
+	ctx.Redirect(setting.AppSubURL + "/service_desk/" + desk.Token)
+}
@@ -5,6 +5,9 @@
 package repo
 
 import (
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"strings"
 	"time"
 
@@ -16,6 +19,9 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/Unknwon/com"
+	"github.com/Unknwon/paginater"
 )
 
 const (
@@ -25,12 +31,27 @@ const (
 	tplGithooks        base.TplName = "repo/settings/githooks"
 	tplGithookEdit     base.TplName = "repo/settings/githook_edit"
 	tplDeployKeys      base.TplName = "repo/settings/deploy_keys"
+	tplAccessLog       base.TplName = "repo/settings/access_log"
+	tplStatusContexts  base.TplName = "repo/settings/status_contexts"
+	tplDeployTokens    base.TplName = "repo/settings/deploy_tokens"
+
+	tplNotificationChannels base.TplName = "repo/settings/notification_channels"
+
+	accessLogPageSize = 50
 )
 
 // Settings show a repository's settings page
 func Settings(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings")
 	ctx.Data["PageIsSettingsOptions"] = true
+
+	desk, err := models.GetServiceDeskByRepoID(ctx.Repo.Repository.ID)
+	if err != nil && !models.IsErrServiceDeskNotExist(err) {
+		ctx.Handle(500, "GetServiceDeskByRepoID", err)
+		return
+	}
+	ctx.Data["ServiceDesk"] = desk
+
 	ctx.HTML(200, tplSettingsOptions)
 }
 
@@ -123,8 +144,8 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 				return
 			}
 		}
-		if err := ctx.Repo.Mirror.SaveAddress(form.MirrorAddress); err != nil {
-			ctx.Handle(500, "SaveAddress", err)
+		if err := ctx.Repo.Mirror.UpdateAddress(form.MirrorAddress, form.MirrorUsername, form.MirrorPassword); err != nil {
+			ctx.Handle(500, "UpdateAddress", err)
 			return
 		}
 
@@ -219,11 +240,59 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 			ctx.Handle(500, "UpdateRepositoryUnits", err)
 			return
 		}
+
+		// The option is kept while the builtin issue tracker is disabled.
+		if form.EnableIssues && !form.EnableExternalTracker {
+			repo.CloseIssuesViaCommit = form.CloseIssuesViaCommit
+			if err := models.UpdateRepository(repo, false); err != nil {
+				ctx.Handle(500, "UpdateRepository", err)
+				return
+			}
+		}
 		log.Trace("Repository advanced settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
 
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
 
+	case "service-desk-enable":
+		if _, err := models.EnableServiceDesk(repo.ID); err != nil {
+			ctx.Handle(500, "EnableServiceDesk", err)
+			return
+		}
+		log.Trace("Repository service desk enabled: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.service_desk_enable_success"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+
+	case "service-desk-disable":
+		if err := models.DisableServiceDesk(repo.ID); err != nil {
+			ctx.Handle(500, "DisableServiceDesk", err)
+			return
+		}
+		log.Trace("Repository service desk disabled: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.service_desk_disable_success"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+
+	case "fsck":
+		output, err := repo.Fsck()
+		if err != nil {
+			ctx.Flash.Error(ctx.Tr("repo.settings.fsck_failed", err, output))
+		} else if len(output) > 0 {
+			ctx.Flash.Info(ctx.Tr("repo.settings.fsck_warnings", output))
+		} else {
+			ctx.Flash.Success(ctx.Tr("repo.settings.fsck_success"))
+		}
+		ctx.Redirect(repo.Link() + "/settings")
+
+	case "gc":
+		if err := repo.GC(); err != nil {
+			ctx.Flash.Error(ctx.Tr("repo.settings.gc_failed", err))
+		} else {
+			ctx.Flash.Success(ctx.Tr("repo.settings.gc_success"))
+		}
+		ctx.Redirect(repo.Link() + "/settings")
+
 	case "convert":
 		if !ctx.Repo.IsOwner() {
 			ctx.Error(404)
@@ -314,8 +383,8 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 			}
 		}
 
-		if err := models.DeleteRepository(ctx.Repo.Owner.ID, repo.ID); err != nil {
-			ctx.Handle(500, "DeleteRepository", err)
+		if err := models.SoftDeleteRepository(ctx.Repo.Owner.ID, repo.ID); err != nil {
+			ctx.Handle(500, "SoftDeleteRepository", err)
 			return
 		}
 		log.Trace("Repository deleted: %s/%s", ctx.Repo.Owner.Name, repo.Name)
@@ -351,6 +420,56 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 	}
 }
 
+// updateAvatar sets the image of data as the avatar of the repository, after
+// checking that it is an image.
+func updateAvatar(ctx *context.Context, repo *models.Repository, data []byte) error {
+	if !base.IsImageFile(data) {
+		return errors.New(ctx.Tr("settings.uploaded_avatar_not_a_image"))
+	}
+	if err := repo.UploadAvatar(data); err != nil {
+		return fmt.Errorf("UploadAvatar: %v", err)
+	}
+	return nil
+}
+
+// SettingsAvatar response for uploading the avatar of a repository
+func SettingsAvatar(ctx *context.Context, form auth.AvatarForm) {
+	if form.Avatar == nil {
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+		return
+	}
+
+	err := func() error {
+		fr, err := form.Avatar.Open()
+		if err != nil {
+			return fmt.Errorf("Avatar.Open: %v", err)
+		}
+		defer fr.Close()
+
+		data, err := ioutil.ReadAll(fr)
+		if err != nil {
+			return fmt.Errorf("ioutil.ReadAll: %v", err)
+		}
+		return updateAvatar(ctx, ctx.Repo.Repository, data)
+	}()
+	if err != nil {
+		ctx.Flash.Error(err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.update_avatar_success"))
+	}
+
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+}
+
+// SettingsDeleteAvatar response for deleting the avatar of a repository
+func SettingsDeleteAvatar(ctx *context.Context) {
+	if err := ctx.Repo.Repository.DeleteAvatar(); err != nil {
+		ctx.Flash.Error(err.Error())
+	}
+
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+}
+
 // Collaboration render a repository's collaboration page
 func Collaboration(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings")
@@ -363,6 +482,12 @@ func Collaboration(ctx *context.Context) {
 	}
 	ctx.Data["Collaborators"] = users
 
+	units := make([]models.Unit, len(models.CollaborationUnitTypes))
+	for i, tp := range models.CollaborationUnitTypes {
+		units[i] = models.Units[tp]
+	}
+	ctx.Data["CollaborationUnits"] = units
+
 	ctx.HTML(200, tplCollaboration)
 }
 
@@ -412,10 +537,23 @@ func CollaborationPost(ctx *context.Context) {
 	ctx.Redirect(setting.AppSubURL + ctx.Req.URL.Path)
 }
 
-// ChangeCollaborationAccessMode response for changing access of a collaboration
+// ChangeCollaborationAccessMode response for changing access of a collaboration,
+// either its access mode or the units the mode applies to
 func ChangeCollaborationAccessMode(ctx *context.Context) {
+	uid := ctx.QueryInt64("uid")
+	if len(ctx.Query("units")) > 0 {
+		unitTypes := make([]models.UnitType, 0, len(models.CollaborationUnitTypes))
+		for _, unit := range strings.Split(ctx.Query("units"), ",") {
+			unitTypes = append(unitTypes, models.UnitType(com.StrTo(unit).MustInt()))
+		}
+		if err := ctx.Repo.Repository.ChangeCollaborationUnits(uid, unitTypes); err != nil {
+			log.Error(4, "ChangeCollaborationUnits: %v", err)
+		}
+		return
+	}
+
 	if err := ctx.Repo.Repository.ChangeCollaborationAccessMode(
-		ctx.QueryInt64("uid"),
+		uid,
 		models.AccessMode(ctx.QueryInt("mode"))); err != nil {
 		log.Error(4, "ChangeCollaborationAccessMode: %v", err)
 	}
@@ -500,6 +638,28 @@ func ProtectedBranchPost(ctx *context.Context) {
 
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(setting.AppSubURL + ctx.Req.URL.Path)
+	case "rename_branch":
+		oldName := ctx.Query("from")
+		newName := strings.TrimSpace(ctx.Query("to"))
+		if err := repo.RenameBranch(oldName, newName, ctx.QueryBool("redirect")); err != nil {
+			if models.IsErrBranchNotExist(err) {
+				ctx.Flash.Error(ctx.Tr("repo.settings.rename_branch_not_exist", oldName))
+			} else if models.IsErrBranchAlreadyExist(err) {
+				ctx.Flash.Error(ctx.Tr("repo.settings.rename_branch_exists", newName))
+			} else if models.IsErrInvalidBranchName(err) {
+				ctx.Flash.Error(ctx.Tr("repo.settings.rename_branch_invalid", newName))
+			} else {
+				ctx.Handle(500, "RenameBranch", err)
+				return
+			}
+			ctx.Redirect(setting.AppSubURL + ctx.Req.URL.Path)
+			return
+		}
+
+		log.Trace("Branch %s of repository %s/%s renamed to %s", oldName, ctx.Repo.Owner.Name, repo.Name, newName)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.rename_branch_success", oldName, newName))
+		ctx.Redirect(setting.AppSubURL + ctx.Req.URL.Path)
 	case "protected_branch":
 		if ctx.HasError() {
 			ctx.JSON(200, map[string]string{
@@ -556,6 +716,25 @@ func ChangeProtectedBranch(ctx *context.Context) {
 	}
 }
 
+// ChangeProtectedBranchApprovals response for changing the approvals a pull
+// request into a protected branch needs
+func ChangeProtectedBranchApprovals(ctx *context.Context) {
+	required := ctx.QueryInt64("required_approvals")
+	if required < 0 {
+		required = 0
+	}
+	if err := ctx.Repo.Repository.ChangeProtectedBranchApprovals(
+		ctx.QueryInt64("id"),
+		required,
+		ctx.QueryBool("dismiss_stale_approvals")); err != nil {
+		ctx.Handle(500, "ChangeProtectedBranchApprovals", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/branches")
+}
+
 // DeleteProtectedBranch delete a protection for a branch of a repository
 func DeleteProtectedBranch(ctx *context.Context) {
 	if err := ctx.Repo.Repository.DeleteProtectedBranch(ctx.QueryInt64("id")); err != nil {
@@ -599,16 +778,76 @@ func GitHooks(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings.githooks")
 	ctx.Data["PageIsSettingsGitHooks"] = true
 
-	hooks, err := ctx.Repo.GitRepo.Hooks()
+	if ctx.User.CanEditGitHook() {
+		hooks, err := ctx.Repo.GitRepo.Hooks()
+		if err != nil {
+			ctx.Handle(500, "Hooks", err)
+			return
+		}
+		ctx.Data["Hooks"] = hooks
+	}
+
+	templates, err := models.GetGitHookTemplates()
+	if err != nil {
+		ctx.Handle(500, "GetGitHookTemplates", err)
+		return
+	}
+	ctx.Data["HookTemplates"] = templates
+
+	repoHooks, err := models.GetRepoGitHooks(ctx.Repo.Repository.ID)
 	if err != nil {
-		ctx.Handle(500, "Hooks", err)
+		ctx.Handle(500, "GetRepoGitHooks", err)
 		return
 	}
-	ctx.Data["Hooks"] = hooks
+	enabledHooks := make(map[int64]*models.RepoGitHook, len(repoHooks))
+	for _, hook := range repoHooks {
+		enabledHooks[hook.TemplateID] = hook
+	}
+	ctx.Data["EnabledHooks"] = enabledHooks
 
 	ctx.HTML(200, tplGithooks)
 }
 
+// EnableGitHookTemplate response for enabling a Git hook template in a
+// repository, or updating its parameters
+func EnableGitHookTemplate(ctx *context.Context) {
+	t, err := models.GetGitHookTemplateByID(ctx.QueryInt64("id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetGitHookTemplateByID", models.IsErrGitHookTemplateNotExist, err)
+		return
+	}
+
+	values := make(map[string]string)
+	for _, name := range t.ParameterNames() {
+		values[name] = ctx.Query("param_" + name)
+	}
+	if err = models.EnableRepoGitHook(ctx.Repo.Repository, t.ID, values); err != nil {
+		ctx.Handle(500, "EnableRepoGitHook", err)
+		return
+	}
+
+	log.Trace("Git hook template %d enabled in repository %s", t.ID, ctx.Repo.Repository.FullName())
+	ctx.Flash.Success(ctx.Tr("repo.settings.githook_template_enable_success", t.Name))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/hooks/git")
+}
+
+// DisableGitHookTemplate response for disabling a Git hook template in a repository
+func DisableGitHookTemplate(ctx *context.Context) {
+	t, err := models.GetGitHookTemplateByID(ctx.QueryInt64("id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetGitHookTemplateByID", models.IsErrGitHookTemplateNotExist, err)
+		return
+	}
+
+	if err = models.DisableRepoGitHook(ctx.Repo.Repository, t.ID); err != nil {
+		ctx.Handle(500, "DisableRepoGitHook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.githook_template_disable_success", t.Name))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/hooks/git")
+}
+
 // GitHooksEdit render for editing a hook of repository page
 func GitHooksEdit(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings.githooks")
@@ -726,3 +965,194 @@ func DeleteDeployKey(ctx *context.Context) {
 		"redirect": ctx.Repo.RepoLink + "/settings/keys",
 	})
 }
+
+// DeployTokens render the deploy tokens of a repository
+func DeployTokens(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.deploy_tokens")
+	ctx.Data["PageIsSettingsTokens"] = true
+
+	tokens, err := models.ListDeployTokens(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Handle(500, "ListDeployTokens", err)
+		return
+	}
+	ctx.Data["DeployTokens"] = tokens
+
+	ctx.HTML(200, tplDeployTokens)
+}
+
+// DeployTokensPost response for creating a deploy token of a repository
+func DeployTokensPost(ctx *context.Context, form auth.NewDeployTokenForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.deploy_tokens")
+	ctx.Data["PageIsSettingsTokens"] = true
+
+	if ctx.HasError() {
+		tokens, err := models.ListDeployTokens(ctx.Repo.Repository.ID)
+		if err != nil {
+			ctx.Handle(500, "ListDeployTokens", err)
+			return
+		}
+		ctx.Data["DeployTokens"] = tokens
+		ctx.HTML(200, tplDeployTokens)
+		return
+	}
+
+	t := &models.DeployToken{
+		RepoID:    ctx.Repo.Repository.ID,
+		CreatorID: ctx.User.ID,
+		Name:      form.Name,
+		Mode:      models.AccessModeRead,
+	}
+	if form.Writable {
+		t.Mode = models.AccessModeWrite
+	}
+	if form.ExpiresDays > 0 {
+		t.ExpiresUnix = time.Now().AddDate(0, 0, form.ExpiresDays).Unix()
+	}
+	if err := models.NewDeployToken(t); err != nil {
+		ctx.Handle(500, "NewDeployToken", err)
+		return
+	}
+
+	log.Trace("Deploy token added: %d", ctx.Repo.Repository.ID)
+	ctx.Flash.Success(ctx.Tr("repo.settings.add_deploy_token_success", t.Name))
+	ctx.Flash.Info(t.Sha1)
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/tokens")
+}
+
+// DeleteDeployToken response for deleting a deploy token
+func DeleteDeployToken(ctx *context.Context) {
+	if err := models.DeleteDeployToken(ctx.Repo.Repository.ID, ctx.QueryInt64("id")); err != nil {
+		ctx.Flash.Error("DeleteDeployToken: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.deploy_token_deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Repo.RepoLink + "/settings/tokens",
+	})
+}
+
+// NotificationChannels render the chat notification channels of a repository
+func NotificationChannels(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.notification_channels")
+	ctx.Data["PageIsSettingsNotificationChannels"] = true
+
+	channels, err := models.GetNotificationChannelsByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Handle(500, "GetNotificationChannelsByRepoID", err)
+		return
+	}
+	ctx.Data["NotificationChannels"] = channels
+
+	ctx.HTML(200, tplNotificationChannels)
+}
+
+// NotificationChannelsPost response for adding a chat notification channel of a repository
+func NotificationChannelsPost(ctx *context.Context, form auth.NotificationChannelForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.notification_channels")
+	ctx.Data["PageIsSettingsNotificationChannels"] = true
+
+	channels, err := models.GetNotificationChannelsByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Handle(500, "GetNotificationChannelsByRepoID", err)
+		return
+	}
+	ctx.Data["NotificationChannels"] = channels
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplNotificationChannels)
+		return
+	}
+	if form.IsMatrixIncomplete() {
+		ctx.Data["Err_RoomID"] = true
+		ctx.RenderWithErr(ctx.Tr("settings.notification_channel_matrix_incomplete"), tplNotificationChannels, &form)
+		return
+	}
+
+	c := form.NotificationChannel()
+	c.RepoID = ctx.Repo.Repository.ID
+	if err = models.NewNotificationChannel(c); err != nil {
+		ctx.Handle(500, "NewNotificationChannel", err)
+		return
+	}
+
+	log.Trace("Notification channel added: %d", ctx.Repo.Repository.ID)
+	ctx.Flash.Success(ctx.Tr("settings.add_notification_channel_success"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/notifications")
+}
+
+// DeleteNotificationChannel response for deleting a chat notification channel of a repository
+func DeleteNotificationChannel(ctx *context.Context) {
+	if err := models.DeleteNotificationChannel(0, ctx.Repo.Repository.ID, ctx.QueryInt64("id")); err != nil {
+		ctx.Flash.Error("DeleteNotificationChannel: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("settings.notification_channel_deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": ctx.Repo.RepoLink + "/settings/notifications",
+	})
+}
+
+// AccessLog render the clone and fetch history of a private repository
+func AccessLog(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.access_log")
+	ctx.Data["PageIsSettingsAccessLog"] = true
+
+	total, err := ctx.Repo.Repository.CountAccessLogs()
+	if err != nil {
+		ctx.Handle(500, "CountAccessLogs", err)
+		return
+	}
+
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	ctx.Data["Page"] = paginater.New(int(total), accessLogPageSize, page, 5)
+
+	logs, err := ctx.Repo.Repository.GetAccessLogs(page, accessLogPageSize)
+	if err != nil {
+		ctx.Handle(500, "GetAccessLogs", err)
+		return
+	}
+	ctx.Data["AccessLogs"] = logs
+	ctx.Data["AccessLogEnabled"] = setting.Repository.EnableAccessLog
+
+	ctx.HTML(200, tplAccessLog)
+}
+
+// StatusContexts render the commit status contexts which reported to the
+// repository recently, and which of them are required for merging.
+func StatusContexts(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.status_contexts")
+	ctx.Data["PageIsSettingsStatusContexts"] = true
+
+	contexts, err := ctx.Repo.Repository.GetStatusContexts()
+	if err != nil {
+		ctx.Handle(500, "GetStatusContexts", err)
+		return
+	}
+	ctx.Data["StatusContexts"] = contexts
+
+	ctx.HTML(200, tplStatusContexts)
+}
+
+// StatusContextsPost marks a commit status context as required or not
+// required for merging pull requests.
+func StatusContextsPost(ctx *context.Context) {
+	context := ctx.Query("context")
+	required := ctx.QueryBool("required")
+	if err := ctx.Repo.Repository.SetStatusContextRequired(context, required); err != nil {
+		ctx.Handle(500, "SetStatusContextRequired", err)
+		return
+	}
+
+	if required {
+		ctx.Flash.Success(ctx.Tr("repo.settings.status_context_required_success", context))
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.status_context_not_required_success", context))
+	}
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/statuses")
+}
@@ -0,0 +1,225 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/Unknwon/com"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// tusVersion is the version of the tus resumable upload protocol
+// (https://tus.io/protocols/resumable-upload.html) spoken by upload sessions.
+const tusVersion = "1.0.0"
+
+// uploadSessionName returns the file name of a new upload session from the
+// "filename" key of the Upload-Metadata header, or the "name" query parameter.
+func uploadSessionName(ctx *context.Context) string {
+	for _, pair := range strings.Split(ctx.Req.Header.Get("Upload-Metadata"), ",") {
+		fields := strings.Fields(pair)
+		if len(fields) != 2 || fields[0] != "filename" {
+			continue
+		}
+		if name, err := base64.StdEncoding.DecodeString(fields[1]); err == nil {
+			return path.Base(string(name))
+		}
+	}
+	return path.Base(ctx.Query("name"))
+}
+
+func newUploadSession(ctx *context.Context, tp models.UploadSessionType) {
+	ctx.Resp.Header().Set("Tus-Resumable", tusVersion)
+
+	size, err := strconv.ParseInt(ctx.Req.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || size <= 0 {
+		ctx.Error(400, "invalid Upload-Length")
+		return
+	}
+	name := uploadSessionName(ctx)
+	if name == "." || name == "/" {
+		ctx.Error(400, "missing file name")
+		return
+	}
+	if tp == models.UploadSessionAttachment && isAttachmentExtensionBlocked(name) {
+		ctx.Error(400, ErrFileTypeForbidden.Error())
+		return
+	}
+
+	s, err := models.NewUploadSession(ctx.User, tp, name, size)
+	if err != nil {
+		switch {
+		case models.IsErrUploadSessionTooLarge(err):
+			ctx.Error(413, err.Error())
+		case models.IsErrAttachmentQuotaExceeded(err):
+			ctx.Error(413, attachmentQuotaMessage(ctx, err.(models.ErrAttachmentQuotaExceeded)))
+		default:
+			ctx.Error(500, fmt.Sprintf("NewUploadSession: %v", err))
+		}
+		return
+	}
+
+	log.Trace("New upload session started: %s", s.UUID)
+	ctx.Resp.Header().Set("Location", strings.TrimSuffix(ctx.Req.URL.Path, "/")+"/"+s.UUID)
+	ctx.JSON(201, map[string]string{
+		"uuid": s.UUID,
+	})
+}
+
+// NewAttachmentUploadSession starts a resumable upload of an attachment
+func NewAttachmentUploadSession(ctx *context.Context) {
+	if !setting.AttachmentEnabled {
+		ctx.Error(404, "attachment is not enabled")
+		return
+	}
+	newUploadSession(ctx, models.UploadSessionAttachment)
+}
+
+// NewRepoUploadSession starts a resumable upload of a file to the repository
+func NewRepoUploadSession(ctx *context.Context) {
+	newUploadSession(ctx, models.UploadSessionRepoFile)
+}
+
+// getUploadSession returns the upload session of the ":uuid" parameter if it
+// has been started by the signed in user.
+func getUploadSession(ctx *context.Context) *models.UploadSession {
+	ctx.Resp.Header().Set("Tus-Resumable", tusVersion)
+
+	s, err := models.GetUploadSessionByUUID(ctx.Params(":uuid"))
+	if err != nil {
+		if models.IsErrUploadSessionNotExist(err) {
+			ctx.Error(404)
+		} else {
+			ctx.Error(500, fmt.Sprintf("GetUploadSessionByUUID: %v", err))
+		}
+		return nil
+	} else if s.UserID != ctx.User.ID {
+		ctx.Error(404)
+		return nil
+	}
+	return s
+}
+
+// UploadSessionStatus responds with the number of bytes received by an
+// upload session
+func UploadSessionStatus(ctx *context.Context) {
+	s := getUploadSession(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.Resp.Header().Set("Upload-Offset", com.ToStr(s.Received))
+	ctx.Resp.Header().Set("Upload-Length", com.ToStr(s.Size))
+	ctx.Resp.Header().Set("Cache-Control", "no-store")
+	ctx.Status(200)
+}
+
+// isUploadSessionFileAllowed returns true if the type of the file of a
+// complete upload session is allowed.
+func isUploadSessionFileAllowed(s *models.UploadSession) (bool, error) {
+	fr, err := os.Open(s.LocalPath())
+	if err != nil {
+		return false, err
+	}
+	defer fr.Close()
+
+	buf := make([]byte, 1024)
+	n, _ := fr.Read(buf)
+	buf = buf[:n]
+
+	switch s.Type {
+	case models.UploadSessionAttachment:
+		return isFileTypeAllowed(buf, strings.Split(setting.AttachmentAllowedTypes, ",")), nil
+	case models.UploadSessionRepoFile:
+		return len(setting.Repository.Upload.AllowedTypes) == 0 ||
+			isFileTypeAllowed(buf, setting.Repository.Upload.AllowedTypes), nil
+	}
+	return false, nil
+}
+
+// UploadSessionChunk receives a chunk of the file of an upload session, the
+// file becomes an attachment or an upload when its last chunk is received
+func UploadSessionChunk(ctx *context.Context) {
+	s := getUploadSession(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	offset, err := strconv.ParseInt(ctx.Req.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		ctx.Error(400, "invalid Upload-Offset")
+		return
+	}
+
+	body := ctx.Req.Body().ReadCloser()
+	defer body.Close()
+	if err = s.WriteChunk(offset, body); err != nil {
+		switch {
+		case models.IsErrUploadSessionOffset(err):
+			ctx.Error(409, err.Error())
+		case models.IsErrUploadSessionTooLarge(err):
+			ctx.Error(413, err.Error())
+		default:
+			// The bytes received before the error can still be resumed from.
+			ctx.Resp.Header().Set("Upload-Offset", com.ToStr(s.Received))
+			ctx.Error(500, fmt.Sprintf("WriteChunk: %v", err))
+		}
+		return
+	}
+
+	if s.IsComplete() {
+		allowed, err := isUploadSessionFileAllowed(s)
+		if err != nil {
+			ctx.Error(500, fmt.Sprintf("isUploadSessionFileAllowed: %v", err))
+			return
+		} else if !allowed {
+			if err = models.DeleteUploadSession(s); err != nil {
+				log.Error(4, "DeleteUploadSession [%s]: %v", s.UUID, err)
+			}
+			ctx.Error(400, ErrFileTypeForbidden.Error())
+			return
+		}
+
+		if err = s.Finish(); err != nil {
+			if models.IsErrAttachmentQuotaExceeded(err) {
+				if err := models.DeleteUploadSession(s); err != nil {
+					log.Error(4, "DeleteUploadSession [%s]: %v", s.UUID, err)
+				}
+				ctx.Error(413, attachmentQuotaMessage(ctx, err.(models.ErrAttachmentQuotaExceeded)))
+			} else {
+				ctx.Error(500, fmt.Sprintf("Finish: %v", err))
+			}
+			return
+		}
+		log.Trace("Upload session finished: %s", s.UUID)
+	}
+
+	ctx.Resp.Header().Set("Upload-Offset", com.ToStr(s.Received))
+	ctx.Status(204)
+}
+
+// DeleteUploadSession aborts an upload session
+func DeleteUploadSession(ctx *context.Context) {
+	s := getUploadSession(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteUploadSession(s); err != nil {
+		ctx.Error(500, fmt.Sprintf("DeleteUploadSession: %v", err))
+		return
+	}
+	log.Trace("Upload session deleted: %s", s.UUID)
+	ctx.Status(204)
+}
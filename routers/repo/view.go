@@ -7,6 +7,7 @@ package repo
 import (
 	"bytes"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	gotemplate "html/template"
 	"io/ioutil"
@@ -34,27 +35,77 @@ const (
 	tplForks    base.TplName = "repo/forks"
 )
 
-func renderDirectory(ctx *context.Context, treeLink string) {
-	tree, err := ctx.Repo.Commit.SubTree(ctx.Repo.TreePath)
-	if err != nil {
-		ctx.NotFoundOrServerError("Repo.Commit.SubTree", git.IsErrNotExist, err)
-		return
+// getCommitsInfo returns the last commit of every entry of the tree. IDs of
+// these commits are cached by tree SHA, later visits only read the commits.
+func getCommitsInfo(ctx *context.Context, tree *git.Tree, entries git.Entries) ([][]interface{}, error) {
+	key := fmt.Sprintf("last-commits:%d:%s:%s", ctx.Repo.Repository.ID, ctx.Repo.Commit.ID, tree.ID)
+	if cached, ok := ctx.Cache.Get(key).(string); ok {
+		var commitIDs map[string]string
+		if err := json.Unmarshal([]byte(cached), &commitIDs); err == nil {
+			if files, err := commitsInfoFromIDs(ctx, entries, commitIDs); err == nil {
+				return files, nil
+			}
+		}
 	}
 
-	entries, err := tree.ListEntries()
+	files, err := entries.GetCommitsInfo(ctx.Repo.Commit, ctx.Repo.TreePath)
 	if err != nil {
-		ctx.Handle(500, "ListEntries", err)
-		return
+		return nil, err
 	}
-	entries.Sort()
 
-	ctx.Data["Files"], err = entries.GetCommitsInfo(ctx.Repo.Commit, ctx.Repo.TreePath)
-	if err != nil {
-		ctx.Handle(500, "GetCommitsInfo", err)
-		return
+	commitIDs := make(map[string]string, len(files))
+	for _, file := range files {
+		name := file[0].(*git.TreeEntry).Name()
+		switch c := file[1].(type) {
+		case *git.Commit:
+			if c != nil {
+				commitIDs[name] = c.ID.String()
+			}
+		case *git.SubModuleFile:
+			if c.Commit != nil {
+				commitIDs[name] = c.ID.String()
+			}
+		}
+	}
+	if data, err := json.Marshal(commitIDs); err == nil {
+		ctx.Cache.Put(key, string(data), setting.Git.LastCommitCacheTTL)
+	}
+	return files, nil
+}
+
+// commitsInfoFromIDs builds the same result as git.Entries.GetCommitsInfo
+// from cached commit IDs of the entries.
+func commitsInfoFromIDs(ctx *context.Context, entries git.Entries, commitIDs map[string]string) ([][]interface{}, error) {
+	files := make([][]interface{}, len(entries))
+	for i, entry := range entries {
+		commitID, ok := commitIDs[entry.Name()]
+		if !ok {
+			return nil, fmt.Errorf("no cached commit for entry: %s", entry.Name())
+		}
+		commit, err := ctx.Repo.GitRepo.GetCommit(commitID)
+		if err != nil {
+			return nil, err
+		}
+
+		if entry.IsSubModule() {
+			subModuleURL := ""
+			if subModule, err := ctx.Repo.Commit.GetSubModule(entry.Name()); err != nil {
+				return nil, err
+			} else if subModule != nil {
+				subModuleURL = subModule.URL
+			}
+			files[i] = []interface{}{entry, git.NewSubModuleFile(commit, subModuleURL, entry.ID.String())}
+		} else {
+			files[i] = []interface{}{entry, commit}
+		}
 	}
+	return files, nil
+}
 
-	var readmeFile *git.Blob
+// FindReadmeEntry returns the README file of a directory, preferring files in
+// a supported markup language, or nil if there is none.
+func FindReadmeEntry(entries git.Entries) *git.TreeEntry {
+	var readmeEntry *git.TreeEntry
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
@@ -65,45 +116,96 @@ func renderDirectory(ctx *context.Context, treeLink string) {
 			continue
 		}
 
-		readmeFile = entry.Blob()
+		readmeEntry = entry
 		if tp != "" {
 			break
 		}
 	}
+	return readmeEntry
+}
+
+// RenderReadmeBlob renders a README file in its markup language, or as plain
+// text if it is in none. It returns false if the file is not a text file.
+func RenderReadmeBlob(readmeFile *git.Blob, treeLink string, metas map[string]string) (string, bool, error) {
+	dataRc, err := readmeFile.Data()
+	if err != nil {
+		return "", false, err
+	}
+
+	buf := make([]byte, 1024)
+	n, _ := dataRc.Read(buf)
+	buf = buf[:n]
+
+	// FIXME: what happens when README file is an image?
+	if !base.IsTextFile(buf) {
+		return "", false, nil
+	}
+
+	d, _ := ioutil.ReadAll(dataRc)
+	buf = append(buf, d...)
+	newbuf := markup.Render(readmeFile.Name(), buf, treeLink, metas)
+	if newbuf == nil {
+		// FIXME This is the only way to show non-markdown files
+		// instead of a broken "View Raw" link
+		newbuf = bytes.Replace(buf, []byte("\n"), []byte(`<br>`), -1)
+	}
+	return string(newbuf), true, nil
+}
+
+// renderReadme renders the README file of a directory, the result is cached
+// by the SHA of the blob.
+func renderReadme(ctx *context.Context, readmeFile *git.Blob, treeLink string) {
+	ctx.Data["FileName"] = readmeFile.Name()
+	cacheKey := context.RenderCacheKey("readme", ctx.Repo.Repository.ComposeMetas(),
+		readmeFile.ID.String(), readmeFile.Name(), treeLink)
+	if content, ok := ctx.GetRenderCache(cacheKey); ok {
+		ctx.Data["FileIsText"] = true
+		ctx.Data["IsMarkdown"] = true
+		ctx.Data["FileContent"] = content
+		return
+	}
+
+	content, isTextFile, err := RenderReadmeBlob(readmeFile, treeLink, ctx.Repo.Repository.ComposeMetas())
+	if err != nil {
+		ctx.Handle(500, "Data", err)
+		return
+	}
+	ctx.Data["FileIsText"] = isTextFile
+	if isTextFile {
+		ctx.Data["IsMarkdown"] = true
+		ctx.Data["FileContent"] = content
+		ctx.PutRenderCache(cacheKey, content)
+	}
+}
+
+func renderDirectory(ctx *context.Context, treeLink string) {
+	tree, err := ctx.Repo.Commit.SubTree(ctx.Repo.TreePath)
+	if err != nil {
+		ctx.NotFoundOrServerError("Repo.Commit.SubTree", git.IsErrNotExist, err)
+		return
+	}
+
+	entries, err := tree.ListEntries()
+	if err != nil {
+		ctx.Handle(500, "ListEntries", err)
+		return
+	}
+	entries.Sort()
 
-	if readmeFile != nil {
+	ctx.Data["Files"], err = getCommitsInfo(ctx, tree, entries)
+	if err != nil {
+		ctx.Handle(500, "GetCommitsInfo", err)
+		return
+	}
+
+	if readmeEntry := FindReadmeEntry(entries); readmeEntry != nil {
 		ctx.Data["RawFileLink"] = ""
 		ctx.Data["ReadmeInList"] = true
 		ctx.Data["ReadmeExist"] = true
-
-		dataRc, err := readmeFile.Data()
-		if err != nil {
-			ctx.Handle(500, "Data", err)
+		renderReadme(ctx, readmeEntry.Blob(), treeLink)
+		if ctx.Written() {
 			return
 		}
-
-		buf := make([]byte, 1024)
-		n, _ := dataRc.Read(buf)
-		buf = buf[:n]
-
-		isTextFile := base.IsTextFile(buf)
-		ctx.Data["FileIsText"] = isTextFile
-		ctx.Data["FileName"] = readmeFile.Name()
-		// FIXME: what happens when README file is an image?
-		if isTextFile {
-			d, _ := ioutil.ReadAll(dataRc)
-			buf = append(buf, d...)
-			newbuf := markup.Render(readmeFile.Name(), buf, treeLink, ctx.Repo.Repository.ComposeMetas())
-			if newbuf != nil {
-				ctx.Data["IsMarkdown"] = true
-			} else {
-				// FIXME This is the only way to show non-markdown files
-				// instead of a broken "View Raw" link
-				ctx.Data["IsMarkdown"] = true
-				newbuf = bytes.Replace(buf, []byte("\n"), []byte(`<br>`), -1)
-			}
-			ctx.Data["FileContent"] = string(newbuf)
-		}
 	}
 
 	// Show latest commit info of repository in table header,
@@ -121,7 +223,7 @@ func renderDirectory(ctx *context.Context, treeLink string) {
 	ctx.Data["LatestCommitUser"] = models.ValidateCommitWithEmail(latestCommit)
 
 	// Check permission to add or upload new file.
-	if ctx.Repo.IsWriter() && ctx.Repo.IsViewBranch {
+	if ctx.Repo.CanWrite(models.UnitTypeCode) && ctx.Repo.IsViewBranch {
 		ctx.Data["CanAddFile"] = true
 		ctx.Data["CanUploadFile"] = setting.Repository.Upload.Enabled
 	}
@@ -232,7 +334,7 @@ func renderFile(ctx *context.Context, entry *git.TreeEntry, treeLink, rawLink st
 			ctx.Data["EditFileTooltip"] = ctx.Tr("repo.editor.edit_this_file")
 		} else if !ctx.Repo.IsViewBranch {
 			ctx.Data["EditFileTooltip"] = ctx.Tr("repo.editor.must_be_on_a_branch")
-		} else if !ctx.Repo.IsWriter() {
+		} else if !ctx.Repo.CanWrite(models.UnitTypeCode) {
 			ctx.Data["EditFileTooltip"] = ctx.Tr("repo.editor.fork_before_edit")
 		}
 
@@ -249,7 +351,7 @@ func renderFile(ctx *context.Context, entry *git.TreeEntry, treeLink, rawLink st
 		ctx.Data["DeleteFileTooltip"] = ctx.Tr("repo.editor.delete_this_file")
 	} else if !ctx.Repo.IsViewBranch {
 		ctx.Data["DeleteFileTooltip"] = ctx.Tr("repo.editor.must_be_on_a_branch")
-	} else if !ctx.Repo.IsWriter() {
+	} else if !ctx.Repo.CanWrite(models.UnitTypeCode) {
 		ctx.Data["DeleteFileTooltip"] = ctx.Tr("repo.editor.must_have_write_access")
 	}
 }
@@ -313,6 +415,21 @@ func renderCode(ctx *context.Context) {
 		return
 	}
 
+	if len(ctx.Repo.TreePath) == 0 {
+		stats, err := ctx.Repo.Repository.GetLanguageStats()
+		if err != nil {
+			ctx.Handle(500, "GetLanguageStats", err)
+			return
+		}
+		ctx.Data["LanguageStats"] = stats
+	}
+
+	// Writers of a fork can sync the viewed branch with the same branch of
+	// the base repository.
+	baseRepo := ctx.Repo.Repository.BaseRepo
+	ctx.Data["CanSyncFork"] = baseRepo != nil && ctx.Repo.IsViewBranch &&
+		ctx.Repo.CanWrite(models.UnitTypeCode) && git.IsBranchExist(baseRepo.RepoPath(), ctx.Repo.BranchName)
+
 	var treeNames []string
 	paths := make([]string, 0, 5)
 	if len(ctx.Repo.TreePath) > 0 {
@@ -373,7 +490,7 @@ func Stars(ctx *context.Context) {
 func Forks(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repos.forks")
 
-	forks, err := ctx.Repo.Repository.GetForks()
+	forks, err := ctx.Repo.Repository.GetForks(ctx.User)
 	if err != nil {
 		ctx.Handle(500, "GetForks", err)
 		return
@@ -112,9 +112,12 @@ func ParseHookEvent(form auth.WebhookForm) *models.HookEvent {
 		SendEverything: form.SendEverything(),
 		ChooseEvents:   form.ChooseEvents(),
 		HookEvents: models.HookEvents{
-			Create:      form.Create,
-			Push:        form.Push,
-			PullRequest: form.PullRequest,
+			Create:       form.Create,
+			Push:         form.Push,
+			PullRequest:  form.PullRequest,
+			Issues:       form.Issues,
+			IssueComment: form.IssueComment,
+			Status:       form.Status,
 		},
 	}
 }
@@ -302,6 +305,12 @@ func checkWebhook(ctx *context.Context) (*orgRepoCtx, *models.Webhook) {
 	ctx.Data["History"], err = w.History(1)
 	if err != nil {
 		ctx.Handle(500, "History", err)
+		return nil, nil
+	}
+	ctx.Data["RecentFailures"], err = w.RecentFailures(setting.Webhook.PagingNum)
+	if err != nil {
+		ctx.Handle(500, "RecentFailures", err)
+		return nil, nil
 	}
 	return orCtx, w
 }
@@ -345,7 +354,7 @@ func WebHooksEditPost(ctx *context.Context, form auth.NewWebhookForm) {
 
 	w.URL = form.PayloadURL
 	w.ContentType = contentType
-	w.Secret = form.Secret
+	w.ChangeSecret(form.Secret)
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
 	if err := w.UpdateEvent(); err != nil {
@@ -384,7 +393,7 @@ func GogsHooksEditPost(ctx *context.Context, form auth.NewWebhookForm) {
 
 	w.URL = form.PayloadURL
 	w.ContentType = contentType
-	w.Secret = form.Secret
+	w.ChangeSecret(form.Secret)
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
 	if err := w.UpdateEvent(); err != nil {
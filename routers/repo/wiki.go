@@ -23,10 +23,11 @@ import (
 )
 
 const (
-	tplWikiStart base.TplName = "repo/wiki/start"
-	tplWikiView  base.TplName = "repo/wiki/view"
-	tplWikiNew   base.TplName = "repo/wiki/new"
-	tplWikiPages base.TplName = "repo/wiki/pages"
+	tplWikiStart  base.TplName = "repo/wiki/start"
+	tplWikiView   base.TplName = "repo/wiki/view"
+	tplWikiNew    base.TplName = "repo/wiki/new"
+	tplWikiPages  base.TplName = "repo/wiki/pages"
+	tplWikiSearch base.TplName = "repo/wiki/search"
 )
 
 // MustEnableWiki check if wiki is enabled, if external then redirect
@@ -386,6 +387,28 @@ func WikiPages(ctx *context.Context) {
 	ctx.HTML(200, tplWikiPages)
 }
 
+// WikiSearch render wiki pages matching a keyword
+func WikiSearch(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.wiki.search")
+	ctx.Data["PageIsWiki"] = true
+
+	if !ctx.Repo.Repository.HasWiki() {
+		ctx.Redirect(ctx.Repo.RepoLink + "/wiki")
+		return
+	}
+
+	keyword := strings.TrimSpace(ctx.Query("q"))
+	results, err := ctx.Repo.Repository.SearchWiki(keyword)
+	if err != nil {
+		ctx.Handle(500, "SearchWiki", err)
+		return
+	}
+	ctx.Data["Keyword"] = keyword
+	ctx.Data["Results"] = results
+
+	ctx.HTML(200, tplWikiSearch)
+}
+
 // WikiRaw outputs raw blob requested by user (image for example)
 func WikiRaw(ctx *context.Context) {
 	wikiRepo, commit, err := findWikiRepoCommit(ctx)
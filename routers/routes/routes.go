@@ -5,8 +5,11 @@
 package routes
 
 import (
+	"net/http"
 	"os"
 	"path"
+	"regexp"
+	"strings"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/auth"
@@ -15,6 +18,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/options"
 	"code.gitea.io/gitea/modules/public"
+	_ "code.gitea.io/gitea/modules/session" // database and Redis Sentinel session providers
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/templates"
 	"code.gitea.io/gitea/modules/validation"
@@ -62,14 +66,6 @@ func NewMacaron() *macaron.Macaron {
 			SkipLogging: setting.DisableRouterLog,
 		},
 	))
-	m.Use(macaron.Static(
-		setting.AvatarUploadPath,
-		macaron.StaticOptions{
-			Prefix:      "avatars",
-			SkipLogging: setting.DisableRouterLog,
-			ETag:        true,
-		},
-	))
 
 	m.Use(templates.Renderer())
 	models.InitMailRender(templates.Mailer())
@@ -123,9 +119,55 @@ func NewMacaron() *macaron.Macaron {
 		},
 	}))
 	m.Use(context.Contexter())
+	if setting.CORSConfig.Enabled {
+		m.Use(context.CORS())
+	}
 	return m
 }
 
+// gitHTTPPattern matches the paths of the requests of the git smart HTTP
+// protocol, the reference discovery and the transfers of objects.
+var gitHTTPPattern = regexp.MustCompile(`^/[^/]+/[^/]+/(info/refs|git-upload-pack|git-receive-pack|git-upload-archive)$`)
+
+// NewGitMacaron initializes the Macaron instance serving the git smart HTTP
+// protocol. It skips the middlewares of the web interface such as sessions,
+// CSRF protection and locales, which clones and pushes do not need.
+func NewGitMacaron() *macaron.Macaron {
+	m := macaron.New()
+	if !setting.DisableRouterLog {
+		m.Use(macaron.Logger())
+	}
+	m.Use(macaron.Recovery())
+	if setting.Protocol == setting.FCGI {
+		m.SetURLPrefix(setting.AppSubURL)
+	}
+	m.Use(macaron.Renderer(macaron.RenderOptions{
+		TemplateFileSystem: &macaron.TplFileSystem{},
+	}))
+	m.Use(context.GitContexter())
+
+	m.Any("/:username/:reponame/*", repo.HTTP)
+	return m
+}
+
+// WithGitRoutes returns a handler serving the requests of the git smart HTTP
+// protocol with the Macaron instance of NewGitMacaron, and the other requests
+// with given one.
+func WithGitRoutes(m *macaron.Macaron) http.Handler {
+	git := NewGitMacaron()
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		urlPath := req.URL.Path
+		if setting.Protocol == setting.FCGI {
+			urlPath = strings.TrimPrefix(urlPath, setting.AppSubURL)
+		}
+		if gitHTTPPattern.MatchString(urlPath) {
+			git.ServeHTTP(w, req)
+			return
+		}
+		m.ServeHTTP(w, req)
+	})
+}
+
 // RegisterRoutes routes routes to Macaron
 func RegisterRoutes(m *macaron.Macaron) {
 	reqSignIn := context.Toggle(&context.ToggleOptions{SignInRequired: true})
@@ -146,7 +188,9 @@ func RegisterRoutes(m *macaron.Macaron) {
 		return ""
 	})
 	m.Get("/", ignSignIn, routers.Home)
-	m.Get("/swagger", ignSignIn, routers.Swagger)
+	m.Get("/swagger", func(ctx *context.Context) {
+		ctx.Redirect(setting.AppSubURL + "/api/swagger")
+	})
 	m.Group("/explore", func() {
 		m.Get("", func(ctx *context.Context) {
 			ctx.Redirect(setting.AppSubURL + "/explore/repos")
@@ -155,9 +199,12 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Get("/users", routers.ExploreUsers)
 		m.Get("/organizations", routers.ExploreOrganizations)
 	}, ignSignIn)
+	m.Get("/search", ignSignIn, routers.Search)
 	m.Combo("/install", routers.InstallInit).Get(routers.Install).
 		Post(bindIgnErr(auth.InstallForm{}), routers.InstallPost)
 	m.Get("/^:type(issues|pulls)$", reqSignIn, user.Issues)
+	m.Combo("/service_desk/:token").Get(repo.ServiceDesk).
+		Post(bindIgnErr(auth.ServiceDeskForm{}), repo.ServiceDeskPost)
 
 	// ***** START: User *****
 	m.Group("/user", func() {
@@ -180,10 +227,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Post("/sign_up", bindIgnErr(auth.RegisterForm{}), user.SignUpPost)
 		m.Get("/reset_password", user.ResetPasswd)
 		m.Post("/reset_password", user.ResetPasswdPost)
-		m.Group("/oauth2", func() {
-			m.Get("/:provider", user.SignInOAuth)
-			m.Get("/:provider/callback", user.SignInOAuthCallback)
-		})
+		m.Get("/oauth2/:provider", user.SignInOAuth)
 		m.Get("/link_account", user.LinkAccount)
 		m.Post("/link_account_signin", bindIgnErr(auth.SignInForm{}), user.LinkAccountPostSignIn)
 		m.Post("/link_account_signup", bindIgnErr(auth.RegisterForm{}), user.LinkAccountPostRegister)
@@ -195,6 +239,9 @@ func RegisterRoutes(m *macaron.Macaron) {
 		})
 	}, reqSignOut)
 
+	// Signed in users are sent back here when linking external accounts.
+	m.Get("/user/oauth2/:provider/callback", user.SignInOAuthCallback)
+
 	m.Group("/user/settings", func() {
 		m.Get("", user.Settings)
 		m.Post("", bindIgnErr(auth.UpdateProfileForm{}), user.SettingsPost)
@@ -206,6 +253,9 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Post("/email/delete", user.DeleteEmail)
 		m.Get("/password", user.SettingsPassword)
 		m.Post("/password", bindIgnErr(auth.ChangePasswordForm{}), user.SettingsPasswordPost)
+		m.Get("/security", user.SettingsSecurity)
+		m.Post("/security/sessions/revoke", user.SettingsRevokeSession)
+		m.Post("/security/sessions/revoke_others", user.SettingsRevokeOtherSessions)
 		if setting.Service.EnableOpenIDSignIn {
 			m.Group("/openid", func() {
 				m.Combo("").Get(user.SettingsOpenID).
@@ -221,8 +271,16 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Combo("/applications").Get(user.SettingsApplications).
 			Post(bindIgnErr(auth.NewAccessTokenForm{}), user.SettingsApplicationsPost)
 		m.Post("/applications/delete", user.SettingsDeleteApplication)
+		m.Combo("/notifications").Get(user.SettingsNotificationChannels).
+			Post(bindIgnErr(auth.NotificationChannelForm{}), user.SettingsNotificationChannelsPost)
+		m.Post("/notifications/delete", user.SettingsDeleteNotificationChannel)
+		if setting.UserExport.Enabled {
+			m.Combo("/export").Get(user.SettingsExport).Post(user.SettingsExportPost)
+			m.Get("/export/download", user.SettingsExportDownload)
+		}
 		m.Route("/delete", "GET,POST", user.SettingsDelete)
 		m.Combo("/account_link").Get(user.SettingsAccountLinks).Post(user.SettingsDeleteAccountLink)
+		m.Post("/account_link/new", user.SettingsLinkAccount)
 		m.Get("/organization", user.SettingsOrganization)
 		m.Group("/two_factor", func() {
 			m.Get("", user.SettingsTwoFactor)
@@ -233,16 +291,28 @@ func RegisterRoutes(m *macaron.Macaron) {
 		})
 	}, reqSignIn, func(ctx *context.Context) {
 		ctx.Data["PageIsUserSettings"] = true
+		ctx.Data["EnableUserExport"] = setting.UserExport.Enabled
+
+		// Admins impersonating a user can't change the password, keys or
+		// other settings of the account.
+		if ctx.Impersonator != nil && ctx.Req.Method != "GET" {
+			ctx.Error(403)
+			return
+		}
 	})
 
 	m.Group("/user", func() {
 		// r.Get("/feeds", binding.Bind(auth.FeedsForm{}), user.Feeds)
+		m.Get("/dashboard/feeds", reqSignIn, user.DashboardFeeds)
 		m.Any("/activate", user.Activate)
 		m.Any("/activate_email", user.ActivateEmail)
 		m.Get("/email2user", user.Email2User)
 		m.Get("/forgot_password", user.ForgotPasswd)
 		m.Post("/forgot_password", user.ForgotPasswdPost)
 		m.Get("/logout", user.SignOut)
+		m.Post("/impersonation/stop", reqSignIn, admin.StopImpersonation)
+		m.Combo("/report", reqSignIn).Get(user.Report).
+			Post(bindIgnErr(auth.AbuseReportForm{}), user.ReportPost)
 	})
 	// ***** END: User *****
 
@@ -260,6 +330,27 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Combo("/new").Get(admin.NewUser).Post(bindIgnErr(auth.AdminCreateUserForm{}), admin.NewUserPost)
 			m.Combo("/:userid").Get(admin.EditUser).Post(bindIgnErr(auth.AdminEditUserForm{}), admin.EditUserPost)
 			m.Post("/:userid/delete", admin.DeleteUser)
+			m.Post("/:userid/impersonate", admin.ImpersonateUser)
+			m.Post("/:userid/suspend", bindIgnErr(auth.AdminSuspendUserForm{}), admin.SuspendUser)
+			m.Post("/:userid/unsuspend", admin.UnsuspendUser)
+		})
+
+		m.Group("/profile_fields", func() {
+			m.Get("", admin.ProfileFields)
+			m.Post("", bindIgnErr(auth.ProfileFieldForm{}), admin.NewProfileFieldPost)
+			m.Post("/delete", admin.DeleteProfileField)
+			m.Combo("/:id").Get(admin.EditProfileField).
+				Post(bindIgnErr(auth.ProfileFieldForm{}), admin.EditProfileFieldPost)
+		})
+
+		m.Group("/git_hooks", func() {
+			m.Get("", admin.GitHookTemplates)
+			m.Post("", bindIgnErr(auth.GitHookTemplateForm{}), admin.NewGitHookTemplatePost)
+			m.Post("/delete", admin.DeleteGitHookTemplate)
+			m.Combo("/global/:name").Get(admin.EditGlobalGitHook).
+				Post(admin.EditGlobalGitHookPost)
+			m.Combo("/:id").Get(admin.EditGitHookTemplate).
+				Post(bindIgnErr(auth.GitHookTemplateForm{}), admin.EditGitHookTemplatePost)
 		})
 
 		m.Group("/orgs", func() {
@@ -279,14 +370,50 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Post("/:authid/delete", admin.DeleteAuthSource)
 		})
 
+		m.Get("/attachments", admin.Attachments)
+		m.Get("/stats", admin.Stats)
+		m.Get("/audit", admin.AuditLogs)
+
 		m.Group("/notices", func() {
 			m.Get("", admin.Notices)
 			m.Post("/delete", admin.DeleteNotices)
 			m.Get("/empty", admin.EmptyNotices)
 		})
+
+		m.Group("/moderation", func() {
+			m.Get("", admin.Moderation)
+			m.Post("/words", bindIgnErr(auth.BlockedWordForm{}), admin.AddBlockedWord)
+			m.Post("/words/delete", admin.DeleteBlockedWord)
+			m.Post("/items/:id/approve", admin.ApproveModerationItem)
+			m.Post("/items/:id/reject", admin.RejectModerationItem)
+			m.Post("/reports/:id/resolve", admin.ResolveAbuseReport)
+			m.Post("/reports/:id/dismiss", admin.DismissAbuseReport)
+		})
+
+		m.Group("/quarantine", func() {
+			m.Get("", admin.Quarantine)
+			m.Post("/repos/:id/restore", admin.RestoreQuarantinedRepo)
+			m.Post("/repos/:id/purge", admin.PurgeQuarantinedRepo)
+			m.Post("/users/:id/restore", admin.RestoreQuarantinedUser)
+			m.Post("/users/:id/purge", admin.PurgeQuarantinedUser)
+		})
+
+		m.Group("/banner", func() {
+			m.Get("", admin.Banner)
+			m.Post("", bindIgnErr(auth.BannerForm{}), admin.BannerPost)
+			m.Post("/delete", admin.DeleteBanner)
+		})
 	}, adminReq)
 	// ***** END: Admin *****
 
+	// Avatars are served without sign in like static files.
+	m.Get("/avatars/:name", user.Avatar)
+	m.Get("/repo-avatars/:name", user.RepoAvatar)
+	m.Get("/oembed", repo.OEmbed)
+	m.Get("/.well-known/webfinger", routers.WebFinger)
+	m.Get("/metrics", routers.Metrics)
+	m.Get("/avatar/:hash", user.AvatarProxy)
+
 	m.Group("", func() {
 		m.Group("/:username", func() {
 			m.Get("", user.Profile)
@@ -317,12 +444,21 @@ func RegisterRoutes(m *macaron.Macaron) {
 				return
 			}
 
+			// Browsers must not guess a type more dangerous than the one served.
+			ctx.Resp.Header().Set("X-Content-Type-Options", "nosniff")
 			if err = repo.ServeData(ctx, attach.Name, fr); err != nil {
 				ctx.Handle(500, "ServeData", err)
 				return
 			}
 		})
 		m.Post("/attachments", repo.UploadAttachment)
+		m.Post("/attachments/markdown", reqSignIn, repo.UploadMarkdownAttachment)
+		m.Group("/attachments/sessions", func() {
+			m.Post("", repo.NewAttachmentUploadSession)
+			m.Combo("/:uuid").Head(repo.UploadSessionStatus).
+				Patch(repo.UploadSessionChunk).
+				Delete(repo.DeleteUploadSession)
+		}, reqSignIn)
 	}, ignSignIn)
 
 	m.Group("/:username", func() {
@@ -334,7 +470,12 @@ func RegisterRoutes(m *macaron.Macaron) {
 	}
 
 	reqRepoAdmin := context.RequireRepoAdmin()
-	reqRepoWriter := context.RequireRepoWriter()
+	reqCodeWriter := context.RequireRepoUnitWriter(models.UnitTypeCode)
+	reqIssueWriter := context.RequireRepoUnitWriter(models.UnitTypeIssues)
+	reqIssueTriager := context.RequireRepoUnitTriager(models.UnitTypeIssues)
+	reqPullWriter := context.RequireRepoUnitWriter(models.UnitTypePullRequests)
+	reqReleaseWriter := context.RequireRepoUnitWriter(models.UnitTypeReleases)
+	reqWikiWriter := context.RequireRepoUnitWriter(models.UnitTypeWiki)
 
 	// ***** START: Organization *****
 	m.Group("/org", func() {
@@ -349,11 +490,31 @@ func RegisterRoutes(m *macaron.Macaron) {
 
 		m.Group("/:org", func() {
 			m.Get("/dashboard", user.Dashboard)
+			m.Get("/dashboard/feeds", user.DashboardFeeds)
 			m.Get("/^:type(issues|pulls)$", user.Issues)
 			m.Get("/members", org.Members)
 			m.Get("/members/action/:action", org.MembersAction)
 
 			m.Get("/teams", org.Teams)
+
+			m.Group("/boards", func() {
+				m.Get("", repo.Boards)
+				m.Combo("/new").Get(repo.NewBoard).
+					Post(bindIgnErr(auth.CreateBoardForm{}), repo.NewBoardPost)
+				m.Post("/delete", repo.DeleteBoard)
+				m.Group("/:id", func() {
+					m.Get("", repo.ViewBoard)
+					m.Combo("/edit").Get(repo.EditBoard).
+						Post(bindIgnErr(auth.CreateBoardForm{}), repo.EditBoardPost)
+					m.Post("/columns/new", bindIgnErr(auth.BoardColumnForm{}), repo.NewBoardColumnPost)
+					m.Post("/columns/:columnID/edit", bindIgnErr(auth.BoardColumnForm{}), repo.EditBoardColumnPost)
+					m.Post("/columns/delete", repo.DeleteBoardColumn)
+					m.Post("/columns/sort", repo.SortBoardColumns)
+					m.Post("/issues/new", repo.AddBoardIssue)
+					m.Post("/issues/move", repo.MoveBoardIssue)
+					m.Post("/issues/remove", repo.RemoveBoardIssue)
+				})
+			})
 		}, context.OrgAssignment(true))
 
 		m.Group("/:org", func() {
@@ -389,6 +550,28 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Post("/slack/:id", bindIgnErr(auth.NewSlackHookForm{}), repo.SlackHooksEditPost)
 				})
 
+				m.Group("/moderation", func() {
+					m.Get("", org.Moderation)
+					m.Post("/words", bindIgnErr(auth.BlockedWordForm{}), org.AddBlockedWord)
+					m.Post("/words/delete", org.DeleteBlockedWord)
+					m.Post("/items/:id/approve", org.ApproveModerationItem)
+					m.Post("/items/:id/reject", org.RejectModerationItem)
+				})
+
+				m.Group("/defaults", func() {
+					m.Get("", org.Defaults)
+					m.Post("/labels", bindIgnErr(auth.OrgLabelTemplateForm{}), org.AddLabelTemplate)
+					m.Post("/labels/delete", org.DeleteLabelTemplate)
+					m.Post("/milestones", bindIgnErr(auth.OrgMilestoneTemplateForm{}), org.AddMilestoneTemplate)
+					m.Post("/milestones/delete", org.DeleteMilestoneTemplate)
+				})
+
+				m.Group("/banner", func() {
+					m.Get("", org.Banner)
+					m.Post("", bindIgnErr(auth.BannerForm{}), org.BannerPost)
+					m.Post("/delete", org.DeleteBanner)
+				})
+
 				m.Route("/delete", "GET,POST", org.SettingsDelete)
 			})
 
@@ -411,6 +594,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Group("/settings", func() {
 			m.Combo("").Get(repo.Settings).
 				Post(bindIgnErr(auth.RepoSettingForm{}), repo.SettingsPost)
+			m.Post("/avatar", binding.MultipartForm(auth.AvatarForm{}), repo.SettingsAvatar)
+			m.Post("/avatar/delete", repo.SettingsDeleteAvatar)
 			m.Group("/collaboration", func() {
 				m.Combo("").Get(repo.Collaboration).Post(repo.CollaborationPost)
 				m.Post("/access_mode", repo.ChangeCollaborationAccessMode)
@@ -419,6 +604,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Group("/branches", func() {
 				m.Combo("").Get(repo.ProtectedBranch).Post(repo.ProtectedBranchPost)
 				m.Post("/can_push", repo.ChangeProtectedBranch)
+				m.Post("/approvals", repo.ChangeProtectedBranchApprovals)
 				m.Post("/delete", repo.DeleteProtectedBranch)
 			}, repo.MustBeNotBare)
 
@@ -437,9 +623,11 @@ func RegisterRoutes(m *macaron.Macaron) {
 
 				m.Group("/git", func() {
 					m.Get("", repo.GitHooks)
-					m.Combo("/:name").Get(repo.GitHooksEdit).
+					m.Post("/templates/enable", repo.EnableGitHookTemplate)
+					m.Post("/templates/disable", repo.DisableGitHookTemplate)
+					m.Combo("/:name", context.GitHookService()).Get(repo.GitHooksEdit).
 						Post(repo.GitHooksEditPost)
-				}, context.GitHookService())
+				})
 			})
 
 			m.Group("/keys", func() {
@@ -448,6 +636,20 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Post("/delete", repo.DeleteDeployKey)
 			})
 
+			m.Group("/tokens", func() {
+				m.Combo("").Get(repo.DeployTokens).
+					Post(bindIgnErr(auth.NewDeployTokenForm{}), repo.DeployTokensPost)
+				m.Post("/delete", repo.DeleteDeployToken)
+			})
+
+			m.Group("/notifications", func() {
+				m.Combo("").Get(repo.NotificationChannels).
+					Post(bindIgnErr(auth.NotificationChannelForm{}), repo.NotificationChannelsPost)
+				m.Post("/delete", repo.DeleteNotificationChannel)
+			})
+
+			m.Get("/access_log", repo.AccessLog)
+			m.Combo("/statuses").Get(repo.StatusContexts).Post(repo.StatusContextsPost)
 		}, func(ctx *context.Context) {
 			ctx.Data["PageIsSettings"] = true
 		}, context.UnitTypes(), context.LoadRepoUnits(), context.CheckUnit(models.UnitTypeSettings))
@@ -466,13 +668,16 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Post("/title", repo.UpdateIssueTitle)
 				m.Post("/content", repo.UpdateIssueContent)
 				m.Post("/watch", repo.IssueWatch)
+				m.Post("/pin", reqIssueWriter, repo.IssuePin)
+				m.Post("/confidential", reqIssueWriter, repo.IssueConfidential)
+				m.Post("/deadline", reqIssueTriager, repo.UpdateIssueDeadline)
 				m.Combo("/comments").Post(bindIgnErr(auth.CreateCommentForm{}), repo.NewComment)
 			})
 
-			m.Post("/labels", repo.UpdateIssueLabel, reqRepoWriter)
-			m.Post("/milestone", repo.UpdateIssueMilestone, reqRepoWriter)
-			m.Post("/assignee", repo.UpdateIssueAssignee, reqRepoWriter)
-			m.Post("/status", repo.UpdateIssueStatus, reqRepoWriter)
+			m.Post("/labels", repo.UpdateIssueLabel, reqIssueTriager)
+			m.Post("/milestone", repo.UpdateIssueMilestone, reqIssueTriager)
+			m.Post("/assignee", repo.UpdateIssueAssignee, reqIssueTriager)
+			m.Post("/status", repo.UpdateIssueStatus, reqIssueTriager)
 		}, context.CheckUnit(models.UnitTypeIssues))
 		m.Group("/comments/:id", func() {
 			m.Post("", repo.UpdateCommentContent)
@@ -483,7 +688,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Post("/edit", bindIgnErr(auth.CreateLabelForm{}), repo.UpdateLabel)
 			m.Post("/delete", repo.DeleteLabel)
 			m.Post("/initialize", bindIgnErr(auth.InitializeLabelsForm{}), repo.InitializeLabels)
-		}, reqRepoWriter, context.RepoRef(), context.CheckUnit(models.UnitTypeIssues))
+		}, reqIssueWriter, context.RepoRef(), context.CheckUnit(models.UnitTypeIssues))
 		m.Group("/milestones", func() {
 			m.Combo("/new").Get(repo.NewMilestone).
 				Post(bindIgnErr(auth.CreateMilestoneForm{}), repo.NewMilestonePost)
@@ -491,11 +696,41 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Post("/:id/edit", bindIgnErr(auth.CreateMilestoneForm{}), repo.EditMilestonePost)
 			m.Get("/:id/:action", repo.ChangeMilestonStatus)
 			m.Post("/delete", repo.DeleteMilestone)
-		}, reqRepoWriter, context.RepoRef(), context.CheckUnit(models.UnitTypeIssues))
+		}, reqIssueWriter, context.RepoRef(), context.CheckUnit(models.UnitTypeIssues))
+		m.Group("/boards", func() {
+			m.Combo("/new").Get(repo.NewBoard).
+				Post(bindIgnErr(auth.CreateBoardForm{}), repo.NewBoardPost)
+			m.Post("/delete", repo.DeleteBoard)
+			m.Group("/:id", func() {
+				m.Combo("/edit").Get(repo.EditBoard).
+					Post(bindIgnErr(auth.CreateBoardForm{}), repo.EditBoardPost)
+				m.Post("/columns/new", bindIgnErr(auth.BoardColumnForm{}), repo.NewBoardColumnPost)
+				m.Post("/columns/:columnID/edit", bindIgnErr(auth.BoardColumnForm{}), repo.EditBoardColumnPost)
+				m.Post("/columns/delete", repo.DeleteBoardColumn)
+				m.Post("/columns/sort", repo.SortBoardColumns)
+				m.Post("/issues/new", repo.AddBoardIssue)
+				m.Post("/issues/move", repo.MoveBoardIssue)
+				m.Post("/issues/remove", repo.RemoveBoardIssue)
+			})
+		}, reqIssueWriter, context.RepoRef(), context.CheckUnit(models.UnitTypeIssues))
+		m.Group("/security/advisories", func() {
+			m.Group("", func() {
+				m.Combo("/new").Get(repo.NewAdvisory).
+					Post(bindIgnErr(auth.RepoAdvisoryForm{}), repo.NewAdvisoryPost)
+				m.Group("/:index", func() {
+					m.Combo("/edit").Get(repo.EditAdvisory).
+						Post(bindIgnErr(auth.RepoAdvisoryForm{}), repo.EditAdvisoryPost)
+					m.Post("/fork", repo.AdvisoryFork)
+					m.Post("/collaborators", repo.AddAdvisoryCollaborator)
+					m.Post("/collaborators/delete", repo.DeleteAdvisoryCollaborator)
+					m.Post("/^:action(publish|close)$", repo.ChangeAdvisoryStatus)
+				})
+			}, reqRepoAdmin)
+			m.Post("/:index/comments", bindIgnErr(auth.CreateCommentForm{}), repo.NewAdvisoryComment)
+		})
 
-		m.Combo("/compare/*", repo.MustAllowPulls, repo.SetEditorconfigIfExists).
-			Get(repo.CompareAndPullRequest).
-			Post(bindIgnErr(auth.CreateIssueForm{}), repo.CompareAndPullRequestPost)
+		m.Post("/compare/*", repo.MustAllowPulls, repo.SetEditorconfigIfExists,
+			bindIgnErr(auth.CreateIssueForm{}), repo.CompareAndPullRequestPost)
 
 		m.Group("", func() {
 			m.Combo("/_edit/*").Get(repo.EditFile).
@@ -510,6 +745,10 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Combo("/_upload/*").Get(repo.UploadFile).
 					Post(bindIgnErr(auth.UploadRepoFileForm{}), repo.UploadFilePost)
 				m.Post("/upload-file", repo.UploadFileToServer)
+				m.Post("/upload-sessions", repo.NewRepoUploadSession)
+				m.Combo("/upload-sessions/:uuid").Head(repo.UploadSessionStatus).
+					Patch(repo.UploadSessionChunk).
+					Delete(repo.DeleteUploadSession)
 				m.Post("/upload-remove", bindIgnErr(auth.RemoveUploadFileForm{}), repo.RemoveUploadFileFromServer)
 			}, func(ctx *context.Context) {
 				if !setting.Repository.Upload.Enabled {
@@ -517,7 +756,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 					return
 				}
 			})
-		}, repo.MustBeNotBare, reqRepoWriter, context.RepoRef(), func(ctx *context.Context) {
+		}, repo.MustBeNotBare, reqCodeWriter, context.RepoRef(), func(ctx *context.Context) {
 			if !ctx.Repo.Repository.CanEnableEditor() || ctx.Repo.IsViewCommit {
 				ctx.Handle(404, "", nil)
 				return
@@ -532,11 +771,11 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get("/new", repo.NewRelease)
 			m.Post("/new", bindIgnErr(auth.NewReleaseForm{}), repo.NewReleasePost)
 			m.Post("/delete", repo.DeleteRelease)
-		}, repo.MustBeNotBare, reqRepoWriter, context.RepoRef())
+		}, repo.MustBeNotBare, reqReleaseWriter, context.RepoRef())
 		m.Group("/releases", func() {
 			m.Get("/edit/*", repo.EditRelease)
 			m.Post("/edit/*", bindIgnErr(auth.EditReleaseForm{}), repo.EditReleasePost)
-		}, repo.MustBeNotBare, reqRepoWriter, func(ctx *context.Context) {
+		}, repo.MustBeNotBare, reqReleaseWriter, func(ctx *context.Context) {
 			var err error
 			ctx.Repo.Commit, err = ctx.Repo.GitRepo.GetBranchCommit(ctx.Repo.Repository.DefaultBranch)
 			if err != nil {
@@ -556,16 +795,31 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Group("", func() {
 			m.Get("/^:type(issues|pulls)$", repo.RetrieveLabels, repo.Issues)
 			m.Get("/^:type(issues|pulls)$/:index", repo.ViewIssue)
+			m.Get("/issues/:index/comments", repo.IssueComments)
 			m.Get("/labels/", repo.RetrieveLabels, repo.Labels)
+			m.Get("/labels/search", repo.SearchLabels)
 			m.Get("/milestones", repo.Milestones)
+			m.Get("/milestones/:id/report", repo.MilestoneReport)
 		}, context.RepoRef())
 
+		m.Group("/boards", func() {
+			m.Get("", repo.Boards)
+			m.Get("/:id", repo.ViewBoard)
+		}, repo.MustEnableIssues, context.RepoRef(), context.CheckUnit(models.UnitTypeIssues))
+
+		m.Group("/security/advisories", func() {
+			m.Get("", repo.Advisories)
+			m.Get("/:index", repo.ViewAdvisory)
+		})
+
 		// m.Get("/branches", repo.Branches)
-		m.Post("/branches/:name/delete", reqSignIn, reqRepoWriter, repo.MustBeNotBare, repo.DeleteBranchPost)
+		m.Post("/branches/:name/delete", reqSignIn, reqCodeWriter, repo.MustBeNotBare, repo.DeleteBranchPost)
+		m.Post("/sync_fork", reqSignIn, reqCodeWriter, repo.MustBeNotBare, repo.SyncForkPost)
 
 		m.Group("/wiki", func() {
 			m.Get("/?:page", repo.Wiki)
 			m.Get("/_pages", repo.WikiPages)
+			m.Get("/_search", repo.WikiSearch)
 
 			m.Group("", func() {
 				m.Combo("/_new").Get(repo.NewWiki).
@@ -573,7 +827,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Combo("/:page/_edit").Get(repo.EditWiki).
 					Post(bindIgnErr(auth.NewWikiForm{}), repo.EditWikiPost)
 				m.Post("/:page/delete", repo.DeleteWikiPagePost)
-			}, reqSignIn, reqRepoWriter)
+			}, reqSignIn, reqWikiWriter)
 		}, repo.MustEnableWiki, context.RepoRef(), context.CheckUnit(models.UnitTypeWiki))
 
 		m.Group("/wiki", func() {
@@ -586,7 +840,13 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Group("/pulls/:index", func() {
 			m.Get("/commits", context.RepoRef(), repo.ViewPullCommits)
 			m.Get("/files", context.RepoRef(), repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.ViewPullFiles)
-			m.Post("/merge", reqRepoWriter, repo.MergePullRequest)
+			m.Get("/files/list", context.RepoRef(), repo.ViewPullFileList)
+			m.Get("/files/diff", context.RepoRef(), repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.ViewPullFileDiff)
+			m.Post("/merge", reqPullWriter, repo.MergePullRequest)
+			m.Post("/update", reqSignIn, repo.UpdatePullRequestBranch)
+			m.Post("/backport", reqCodeWriter, repo.BackportPullRequest)
+			m.Post("/review", reqPullWriter, repo.SubmitPullReview)
+			m.Post("/base", reqPullWriter, bindIgnErr(auth.ChangePullBaseForm{}), repo.ChangePullBaseBranch)
 		}, repo.MustAllowPulls, context.CheckUnit(models.UnitTypePullRequests))
 
 		m.Group("", func() {
@@ -594,13 +854,15 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get("/raw/*", repo.SingleDownload)
 			m.Get("/commits/*", repo.RefCommits)
 			m.Get("/graph", repo.Graph)
+			m.Get("/graph/data", repo.GraphData)
+			m.Get("/contributors", repo.Contributors)
 			m.Get("/commit/:sha([a-f0-9]{7,40})$", repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.Diff)
 			m.Get("/forks", repo.Forks)
 		}, context.RepoRef(), context.CheckUnit(models.UnitTypeCode))
 		m.Get("/commit/:sha([a-f0-9]{7,40})\\.:ext(patch|diff)", repo.MustBeNotBare, repo.RawDiff, context.CheckUnit(models.UnitTypeCode))
 
-		m.Get("/compare/:before([a-z0-9]{40})\\.\\.\\.:after([a-z0-9]{40})", repo.SetEditorconfigIfExists,
-			repo.SetDiffViewStyle, repo.MustBeNotBare, repo.CompareDiff, context.CheckUnit(models.UnitTypeCode))
+		m.Get("/compare/*", context.CheckUnit(models.UnitTypeCode), repo.MustBeNotBare,
+			repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.Compare)
 	}, ignSignIn, context.RepoAssignment(), context.UnitTypes(), context.LoadRepoUnits())
 	m.Group("/:username/:reponame", func() {
 		m.Get("/stars", repo.Stars)
@@ -635,6 +897,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 	}, reqSignIn)
 
 	m.Group("/api", func() {
+		m.Get("/swagger", routers.Swagger)
+		m.Get("/swagger.v1.json", routers.SwaggerV1JSON)
 		apiv1.RegisterRoutes(m)
 	}, ignSignIn)
 
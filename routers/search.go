@@ -0,0 +1,103 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package routers
+
+import (
+	"strings"
+
+	"github.com/Unknwon/paginater"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	// tplSearch global search page template
+	tplSearch base.TplName = "search"
+)
+
+// Search renders the repositories, users, organizations or issues matching
+// the keyword in the scope given by the scope parameter.
+func Search(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("explore.search")
+	ctx.Data["PageIsExplore"] = true
+
+	keyword := strings.Trim(ctx.Query("q"), " ")
+	page := ctx.QueryInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	pageSize := setting.UI.ExplorePagingNum
+
+	scope := ctx.Query("scope")
+	if len(scope) == 0 {
+		scope = "repositories"
+	}
+	ctx.Data["Keyword"] = keyword
+	ctx.Data["Scope"] = scope
+
+	var (
+		count int64
+		err   error
+	)
+	if len(keyword) > 0 && isKeywordValid(keyword) {
+		switch scope {
+		case "repositories":
+			var repos models.RepositoryList
+			repos, count, err = models.SearchRepositoryByName(&models.SearchRepoOptions{
+				Keyword:  keyword,
+				Searcher: ctx.User,
+				Private:  ctx.IsSigned && ctx.User.IsAdmin,
+				Page:     page,
+				PageSize: pageSize,
+			})
+			if err != nil {
+				ctx.Handle(500, "SearchRepositoryByName", err)
+				return
+			}
+			ctx.Data["Repos"] = repos
+		case "users", "organizations":
+			userType := models.UserTypeIndividual
+			if scope == "organizations" {
+				userType = models.UserTypeOrganization
+			}
+			var users []*models.User
+			users, count, err = models.SearchUserByName(&models.SearchUserOptions{
+				Keyword:  keyword,
+				Type:     userType,
+				OrderBy:  "name ASC",
+				Page:     page,
+				PageSize: pageSize,
+			})
+			if err != nil {
+				ctx.Handle(500, "SearchUserByName", err)
+				return
+			}
+			ctx.Data["Users"] = users
+		case "issues":
+			var issues models.IssueList
+			issues, count, err = models.SearchIssues(&models.SearchIssuesOptions{
+				Keyword:  keyword,
+				Doer:     ctx.User,
+				Page:     page,
+				PageSize: pageSize,
+			})
+			if err != nil {
+				ctx.Handle(500, "SearchIssues", err)
+				return
+			}
+			ctx.Data["Issues"] = issues
+		default:
+			ctx.Handle(404, "Search", nil)
+			return
+		}
+	}
+
+	ctx.Data["Total"] = count
+	ctx.Data["Page"] = paginater.New(int(count), pageSize, page, 5)
+	ctx.HTML(200, tplSearch)
+}
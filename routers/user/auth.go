@@ -7,6 +7,7 @@ package user
 import (
 	"errors"
 	"fmt"
+	"html"
 	"net/http"
 	"net/url"
 	"strings"
@@ -17,6 +18,7 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/password"
 	"code.gitea.io/gitea/modules/setting"
 
 	"github.com/go-macaron/captcha"
@@ -63,6 +65,8 @@ func AutoSignIn(ctx *context.Context) (bool, error) {
 			return false, fmt.Errorf("GetUserByName: %v", err)
 		}
 		return false, nil
+	} else if u.IsSuspended {
+		return false, nil
 	}
 
 	if val, _ := ctx.GetSuperSecureCookie(
@@ -150,10 +154,14 @@ func SignInPost(ctx *context.Context, form auth.SignInForm) {
 		return
 	}
 
-	u, err := models.UserSignIn(form.UserName, form.Password)
+	u, err := models.UserSignInFrom(form.UserName, form.Password, ctx.RemoteAddr(), ctx.Req.UserAgent())
 	if err != nil {
 		if models.IsErrUserNotExist(err) {
 			ctx.RenderWithErr(ctx.Tr("form.username_password_incorrect"), tplSignIn, &form)
+		} else if models.IsErrUserLocked(err) {
+			ctx.RenderWithErr(ctx.Tr("form.sign_in_locked"), tplSignIn, &form)
+		} else if models.IsErrUserSuspended(err) {
+			ctx.RenderWithErr(suspendedMessage(ctx, err), tplSignIn, &form)
 		} else if models.IsErrEmailAlreadyUsed(err) {
 			ctx.RenderWithErr(ctx.Tr("form.email_been_used"), tplSignIn, &form)
 		} else {
@@ -319,6 +327,12 @@ func handleSignIn(ctx *context.Context, u *models.User, remember bool) {
 }
 
 func handleSignInFull(ctx *context.Context, u *models.User, remember bool, obeyRedirect bool) {
+	if u.IsSuspended {
+		ctx.Flash.Error(suspendedMessage(ctx, models.ErrUserSuspended{UID: u.ID, Reason: u.SuspendReason}))
+		ctx.Redirect(setting.AppSubURL + "/user/login")
+		return
+	}
+
 	if remember {
 		days := 86400 * setting.LogInRememberDays
 		ctx.SetCookie(setting.CookieUserName, u.Name, days, setting.AppSubURL)
@@ -334,6 +348,10 @@ func handleSignInFull(ctx *context.Context, u *models.User, remember bool, obeyR
 	ctx.Session.Delete("twofaRemember")
 	ctx.Session.Set("uid", u.ID)
 	ctx.Session.Set("uname", u.Name)
+	if err := models.DeleteUserSession(ctx.Session.ID()); err != nil {
+		ctx.Handle(500, "DeleteUserSession", err)
+		return
+	}
 
 	// Clear whatever CSRF has right now, force to generate a new one
 	ctx.SetCookie(setting.CSRFCookieName, "", -1, setting.AppSubURL)
@@ -399,11 +417,46 @@ func SignInOAuthCallback(ctx *context.Context) {
 		return
 	}
 
+	if ctx.IsSigned {
+		// Signed in users only come back here when linking an external account in their settings.
+		if uid, ok := ctx.Session.Get("linkAccountUserID").(int64); !ok || uid != ctx.User.ID {
+			ctx.Redirect(setting.AppSubURL + "/")
+			return
+		}
+		ctx.Session.Delete("linkAccountUserID")
+		linkOAuth2Account(ctx, loginSource)
+		return
+	}
+
 	u, gothUser, err := oAuth2UserLoginCallback(loginSource, ctx.Req.Request, ctx.Resp)
 
 	handleOAuth2SignIn(u, gothUser, ctx, err)
 }
 
+// linkOAuth2Account links the external account the provider calls back with
+// to the signed in user.
+func linkOAuth2Account(ctx *context.Context, loginSource *models.LoginSource) {
+	u, gothUser, err := oAuth2UserLoginCallback(loginSource, ctx.Req.Request, ctx.Resp)
+	if err != nil {
+		ctx.Handle(500, "UserLinkAccount", err)
+		return
+	}
+
+	switch {
+	case u == nil:
+		if err = models.LinkAccountToUser(ctx.User, gothUser); err != nil {
+			ctx.Handle(500, "LinkAccountToUser", err)
+			return
+		}
+		ctx.Flash.Success(ctx.Tr("settings.add_account_link_success"))
+	case u.ID == ctx.User.ID:
+		ctx.Flash.Info(ctx.Tr("settings.account_link_already_linked"))
+	default:
+		ctx.Flash.Error(ctx.Tr("settings.account_link_used_by_other"))
+	}
+	ctx.Redirect(setting.AppSubURL + "/user/settings/account_link")
+}
+
 func handleOAuth2SignIn(u *models.User, gothUser goth.User, ctx *context.Context, err error) {
 	if err != nil {
 		ctx.Handle(500, "UserSignIn", err)
@@ -415,6 +468,10 @@ func handleOAuth2SignIn(u *models.User, gothUser goth.User, ctx *context.Context
 		ctx.Session.Set("linkAccountGothUser", gothUser)
 		ctx.Redirect(setting.AppSubURL + "/user/link_account")
 		return
+	} else if u.IsSuspended {
+		ctx.Flash.Error(suspendedMessage(ctx, models.ErrUserSuspended{UID: u.ID, Reason: u.SuspendReason}))
+		ctx.Redirect(setting.AppSubURL + "/user/login")
+		return
 	}
 
 	// If this user is enrolled in 2FA, we can't sign the user in just yet.
@@ -518,9 +575,36 @@ func LinkAccount(ctx *context.Context) {
 	ctx.Data["user_name"] = gothUser.(goth.User).NickName
 	ctx.Data["email"] = gothUser.(goth.User).Email
 
+	linkAccountExistingUser(ctx, gothUser.(goth.User))
+	if ctx.Written() {
+		return
+	}
+	if u, ok := ctx.Data["LinkAccountUser"].(*models.User); ok {
+		ctx.Data["user_name"] = u.Name
+	}
+
 	ctx.HTML(200, tplLinkAccount)
 }
 
+// linkAccountExistingUser looks up the local account the email address of the
+// external account belongs to, which is then only offered to be linked by
+// confirming its password instead of signing up with the same email address.
+func linkAccountExistingUser(ctx *context.Context, gothUser goth.User) {
+	u, err := models.GetUserByEmail(gothUser.Email)
+	if err != nil {
+		if !models.IsErrUserNotExist(err) {
+			ctx.Handle(500, "GetUserByEmail", err)
+		}
+		return
+	}
+	if u.IsOrganization() {
+		return
+	}
+
+	ctx.Data["LinkAccountUser"] = u
+	ctx.Data["LinkAccountEmail"] = gothUser.Email
+}
+
 // LinkAccountPostSignIn handle the coupling of external account with another account using signIn
 func LinkAccountPostSignIn(ctx *context.Context, signInForm auth.SignInForm) {
 	ctx.Data["Title"] = ctx.Tr("link_account")
@@ -540,15 +624,24 @@ func LinkAccountPostSignIn(ctx *context.Context, signInForm auth.SignInForm) {
 		return
 	}
 
+	linkAccountExistingUser(ctx, gothUser.(goth.User))
+	if ctx.Written() {
+		return
+	}
+
 	if ctx.HasError() {
 		ctx.HTML(200, tplLinkAccount)
 		return
 	}
 
-	u, err := models.UserSignIn(signInForm.UserName, signInForm.Password)
+	u, err := models.UserSignInFrom(signInForm.UserName, signInForm.Password, ctx.RemoteAddr(), ctx.Req.UserAgent())
 	if err != nil {
 		if models.IsErrUserNotExist(err) {
 			ctx.RenderWithErr(ctx.Tr("form.username_password_incorrect"), tplLinkAccount, &signInForm)
+		} else if models.IsErrUserLocked(err) {
+			ctx.RenderWithErr(ctx.Tr("form.sign_in_locked"), tplLinkAccount, &signInForm)
+		} else if models.IsErrUserSuspended(err) {
+			ctx.RenderWithErr(suspendedMessage(ctx, err), tplLinkAccount, &signInForm)
 		} else {
 			ctx.Handle(500, "UserLinkAccount", err)
 		}
@@ -599,6 +692,11 @@ func LinkAccountPostRegister(ctx *context.Context, cpt *captcha.Captcha, form au
 		return
 	}
 
+	linkAccountExistingUser(ctx, gothUser.(goth.User))
+	if ctx.Written() {
+		return
+	}
+
 	if ctx.HasError() {
 		ctx.HTML(200, tplLinkAccount)
 		return
@@ -620,10 +718,12 @@ func LinkAccountPostRegister(ctx *context.Context, cpt *captcha.Captcha, form au
 		ctx.RenderWithErr(ctx.Tr("form.password_not_match"), tplLinkAccount, &form)
 		return
 	}
-	if len(strings.TrimSpace(form.Password)) > 0 && len(form.Password) < setting.MinPasswordLength {
-		ctx.Data["Err_Password"] = true
-		ctx.RenderWithErr(ctx.Tr("auth.password_too_short", setting.MinPasswordLength), tplLinkAccount, &form)
-		return
+	if len(strings.TrimSpace(form.Password)) > 0 {
+		if msg := checkPassword(ctx, form.Password); len(msg) > 0 {
+			ctx.Data["Err_Password"] = true
+			ctx.RenderWithErr(msg, tplLinkAccount, &form)
+			return
+		}
 	}
 
 	loginSource, err := models.GetActiveOAuth2LoginSourceByName(gothUser.(goth.User).Provider)
@@ -631,6 +731,12 @@ func LinkAccountPostRegister(ctx *context.Context, cpt *captcha.Captcha, form au
 		ctx.Handle(500, "CreateUser", err)
 	}
 
+	if !models.IsEmailDomainAllowed(form.Email) {
+		ctx.Data["Err_Email"] = true
+		ctx.RenderWithErr(ctx.Tr("form.email_domain_not_allowed"), tplLinkAccount, &form)
+		return
+	}
+
 	u := &models.User{
 		Name:        form.UserName,
 		Email:       form.Email,
@@ -689,8 +795,18 @@ func LinkAccountPostRegister(ctx *context.Context, cpt *captcha.Captcha, form au
 	ctx.Redirect(setting.AppSubURL + "/user/login")
 }
 
+// suspendedMessage returns the error shown to a suspended user signing in,
+// with the reason of the suspension.
+func suspendedMessage(ctx *context.Context, err error) string {
+	return ctx.Tr("form.user_suspended", html.EscapeString(err.(models.ErrUserSuspended).Reason))
+}
+
 // SignOut sign out from login status
 func SignOut(ctx *context.Context) {
+	ctx.StopImpersonation()
+	if err := models.DeleteUserSession(ctx.Session.ID()); err != nil {
+		log.Error(4, "DeleteUserSession: %v", err)
+	}
 	ctx.Session.Delete("uid")
 	ctx.Session.Delete("uname")
 	ctx.Session.Delete("socialId")
@@ -715,6 +831,28 @@ func SignUp(ctx *context.Context) {
 	ctx.HTML(200, tplSignUp)
 }
 
+// checkPassword returns the message telling why the password does not comply
+// with the password policy, or an empty string if it does.
+func checkPassword(ctx *context.Context, passwd string) string {
+	if len(passwd) < setting.MinPasswordLength {
+		return ctx.Tr("auth.password_too_short", setting.MinPasswordLength)
+	} else if !password.IsComplexEnough(passwd) {
+		classes := make([]string, len(setting.PasswordComplexity))
+		for i, class := range setting.PasswordComplexity {
+			classes[i] = ctx.Tr("form.password_" + class)
+		}
+		return ctx.Tr("form.password_complexity", strings.Join(classes, ", "))
+	}
+
+	pwned, err := password.IsPwned(passwd)
+	if err != nil {
+		log.Error(4, "IsPwned: %v", err)
+	} else if pwned {
+		return ctx.Tr("form.password_pwned")
+	}
+	return ""
+}
+
 // SignUpPost response for sign up information submission
 func SignUpPost(ctx *context.Context, cpt *captcha.Captcha, form auth.RegisterForm) {
 	ctx.Data["Title"] = ctx.Tr("sign_up")
@@ -744,9 +882,15 @@ func SignUpPost(ctx *context.Context, cpt *captcha.Captcha, form auth.RegisterFo
 		ctx.RenderWithErr(ctx.Tr("form.password_not_match"), tplSignUp, &form)
 		return
 	}
-	if len(form.Password) < setting.MinPasswordLength {
+	if msg := checkPassword(ctx, form.Password); len(msg) > 0 {
 		ctx.Data["Err_Password"] = true
-		ctx.RenderWithErr(ctx.Tr("auth.password_too_short", setting.MinPasswordLength), tplSignUp, &form)
+		ctx.RenderWithErr(msg, tplSignUp, &form)
+		return
+	}
+
+	if !models.IsEmailDomainAllowed(form.Email) {
+		ctx.Data["Err_Email"] = true
+		ctx.RenderWithErr(ctx.Tr("form.email_domain_not_allowed"), tplSignUp, &form)
 		return
 	}
 
@@ -971,12 +1115,12 @@ func ResetPasswdPost(ctx *context.Context) {
 	ctx.Data["Code"] = code
 
 	if u := models.VerifyUserActiveCode(code); u != nil {
-		// Validate password length.
+		// Validate password against the password policy.
 		passwd := ctx.Query("password")
-		if len(passwd) < setting.MinPasswordLength {
+		if msg := checkPassword(ctx, passwd); len(msg) > 0 {
 			ctx.Data["IsResetForm"] = true
 			ctx.Data["Err_Password"] = true
-			ctx.RenderWithErr(ctx.Tr("auth.password_too_short", setting.MinPasswordLength), tplResetPassword, nil)
+			ctx.RenderWithErr(msg, tplResetPassword, nil)
 			return
 		}
 
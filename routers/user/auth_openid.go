@@ -270,10 +270,14 @@ func ConnectOpenIDPost(ctx *context.Context, form auth.ConnectOpenIDForm) {
 	ctx.Data["EnableOpenIDSignUp"] = setting.Service.EnableOpenIDSignUp
 	ctx.Data["OpenID"] = oid
 
-	u, err := models.UserSignIn(form.UserName, form.Password)
+	u, err := models.UserSignInFrom(form.UserName, form.Password, ctx.RemoteAddr(), ctx.Req.UserAgent())
 	if err != nil {
 		if models.IsErrUserNotExist(err) {
 			ctx.RenderWithErr(ctx.Tr("form.username_password_incorrect"), tplConnectOID, &form)
+		} else if models.IsErrUserLocked(err) {
+			ctx.RenderWithErr(ctx.Tr("form.sign_in_locked"), tplConnectOID, &form)
+		} else if models.IsErrUserSuspended(err) {
+			ctx.RenderWithErr(suspendedMessage(ctx, err), tplConnectOID, &form)
 		} else {
 			ctx.Handle(500, "ConnectOpenIDPost", err)
 		}
@@ -361,6 +365,12 @@ func RegisterOpenIDPost(ctx *context.Context, cpt *captcha.Captcha, form auth.Si
 		return
 	}
 
+	if !models.IsEmailDomainAllowed(form.Email) {
+		ctx.Data["Err_Email"] = true
+		ctx.RenderWithErr(ctx.Tr("form.email_domain_not_allowed"), tplSignUpOID, &form)
+		return
+	}
+
 	// TODO: abstract a finalizeSignUp function ?
 	u := &models.User{
 		Name:     form.UserName,
@@ -0,0 +1,132 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Unknwon/com"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/avatar"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+var emailHashPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// avatarProxyClient fetches the avatars of the avatar proxy, it does not
+// connect to private addresses since the hosts of federated avatars are
+// chosen by the owners of the email domains.
+var avatarProxyClient = models.NewAvatarProxyClient(10 * time.Second)
+
+// serveAvatar serves the avatar stored at p in the size of the "size" query
+// parameter, in WebP format if the browser accepts it. Avatars which have been
+// stored before sizes were introduced only exist in AvatarSize.
+func serveAvatar(ctx *context.Context, p string) {
+	size := ctx.QueryInt("size")
+	acceptsWebP := strings.Contains(ctx.Req.Header.Get("Accept"), "image/webp")
+
+	filePath := avatar.SizedPath(p, size, acceptsWebP)
+	if !com.IsFile(filePath) && acceptsWebP {
+		filePath = avatar.SizedPath(p, size, false)
+	}
+	if !com.IsFile(filePath) {
+		filePath = p
+	}
+	if !com.IsFile(filePath) {
+		ctx.Handle(404, "", nil)
+		return
+	}
+
+	if strings.HasSuffix(filePath, ".webp") {
+		ctx.Resp.Header().Set("Content-Type", "image/webp")
+	} else {
+		ctx.Resp.Header().Set("Content-Type", "image/png")
+	}
+	ctx.Resp.Header().Set("Vary", "Accept")
+	http.ServeFile(ctx.Resp, ctx.Req.Request, filePath)
+}
+
+// Avatar serves the uploaded or generated avatar of a user or an organization
+func Avatar(ctx *context.Context) {
+	name := ctx.Params(":name")
+	if name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		ctx.Handle(404, "", nil)
+		return
+	}
+	serveAvatar(ctx, filepath.Join(setting.AvatarUploadPath, name))
+}
+
+// RepoAvatar serves the uploaded avatar of a repository
+func RepoAvatar(ctx *context.Context) {
+	name := ctx.Params(":name")
+	if name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		ctx.Handle(404, "", nil)
+		return
+	}
+	serveAvatar(ctx, filepath.Join(setting.RepositoryAvatarUploadPath, name))
+}
+
+// avatarSourceURL returns the URL of the avatar of an email hash at the
+// Gravatar-like service, Libravatar is asked if the email belongs to a user.
+func avatarSourceURL(hash string) string {
+	link := setting.GravatarSource + hash
+	if setting.EnableFederatedAvatar && setting.LibravatarService != nil {
+		email, err := models.GetAvatarEmailByHash(hash)
+		if err != nil {
+			log.Error(4, "GetAvatarEmailByHash: %v", err)
+		} else if len(email) > 0 {
+			if federatedLink, err := setting.LibravatarService.FromEmail(email); err == nil {
+				link = federatedLink
+			}
+		}
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	q := u.Query()
+	q.Set("s", com.ToStr(avatar.AvatarSize))
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// AvatarProxy serves the avatar of an email hash from a Gravatar-like service,
+// it is fetched by the server and cached, so that browsers never contact the
+// service
+func AvatarProxy(ctx *context.Context) {
+	if !setting.EnableAvatarProxy || setting.DisableGravatar {
+		ctx.Handle(404, "", nil)
+		return
+	}
+
+	hash := ctx.Params(":hash")
+	if !emailHashPattern.MatchString(hash) {
+		ctx.Handle(404, "", nil)
+		return
+	}
+
+	p := filepath.Join(setting.AppDataPath, "avatar_proxy", hash)
+	getURL := func() string { return avatarSourceURL(hash) }
+	if err := avatar.Fetch(avatarProxyClient, p, getURL, setting.AvatarProxyCacheTTL); err != nil {
+		if err != avatar.ErrFetchFailedRecently {
+			log.Warn("Failed to fetch avatar %s: %v", hash, err)
+		}
+		// A stale avatar is better than none.
+		if !com.IsFile(p) {
+			ctx.Redirect(setting.AppSubURL + "/img/avatar_default.png")
+			return
+		}
+	}
+	serveAvatar(ctx, p)
+}
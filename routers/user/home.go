@@ -19,10 +19,11 @@ import (
 )
 
 const (
-	tplDashborad base.TplName = "user/dashboard/dashboard"
-	tplIssues    base.TplName = "user/dashboard/issues"
-	tplProfile   base.TplName = "user/profile"
-	tplOrgHome   base.TplName = "org/home"
+	tplDashborad          base.TplName = "user/dashboard/dashboard"
+	tplDashboardFeedsPage base.TplName = "user/dashboard/feeds_page"
+	tplIssues             base.TplName = "user/dashboard/issues"
+	tplProfile            base.TplName = "user/profile"
+	tplOrgHome            base.TplName = "org/home"
 )
 
 // getDashboardContextUser finds out dashboard is viewing as which context user.
@@ -54,70 +55,30 @@ func getDashboardContextUser(ctx *context.Context) *models.User {
 }
 
 // retrieveFeeds loads feeds for the specified user
-func retrieveFeeds(ctx *context.Context, user *models.User, includePrivate, isProfile bool) {
-	var requestingID int64
+func retrieveFeeds(ctx *context.Context, opts models.GetFeedsOptions) {
 	if ctx.User != nil {
-		requestingID = ctx.User.ID
+		opts.RequestingUserID = ctx.User.ID
 	}
-	actions, err := models.GetFeeds(models.GetFeedsOptions{
-		RequestedUser:    user,
-		RequestingUserID: requestingID,
-		IncludePrivate:   includePrivate,
-		OnlyPerformedBy:  isProfile,
-	})
+	actions, err := models.GetFeeds(opts)
 	if err != nil {
 		ctx.Handle(500, "GetFeeds", err)
 		return
 	}
 
-	userCache := map[int64]*models.User{user.ID: user}
-	if ctx.User != nil {
-		userCache[ctx.User.ID] = ctx.User
+	if err = models.ActionList(actions).LoadAttributes(); err != nil {
+		ctx.Handle(500, "ActionList.LoadAttributes", err)
+		return
 	}
-	repoCache := map[int64]*models.Repository{}
-	for _, act := range actions {
-		// Cache results to reduce queries.
-		u, ok := userCache[act.ActUserID]
-		if !ok {
-			u, err = models.GetUserByID(act.ActUserID)
-			if err != nil {
-				if models.IsErrUserNotExist(err) {
-					continue
-				}
-				ctx.Handle(500, "GetUserByID", err)
-				return
-			}
-			userCache[act.ActUserID] = u
-		}
-		act.ActUser = u
-
-		repo, ok := repoCache[act.RepoID]
-		if !ok {
-			repo, err = models.GetRepositoryByID(act.RepoID)
-			if err != nil {
-				if models.IsErrRepoNotExist(err) {
-					continue
-				}
-				ctx.Handle(500, "GetRepositoryByID", err)
-				return
-			}
-		}
-		act.Repo = repo
 
-		repoOwner, ok := userCache[repo.OwnerID]
-		if !ok {
-			repoOwner, err = models.GetUserByID(repo.OwnerID)
-			if err != nil {
-				if models.IsErrUserNotExist(err) {
-					continue
-				}
-				ctx.Handle(500, "GetUserByID", err)
-				return
-			}
+	// Skip the actions of deleted repositories.
+	feeds := make([]*models.Action, 0, len(actions))
+	for _, act := range actions {
+		if act.Repo != nil {
+			feeds = append(feeds, act)
 		}
-		repo.Owner = repoOwner
 	}
-	ctx.Data["Feeds"] = actions
+	ctx.Data["Feeds"] = feeds
+	ctx.Data["FeedsHasMore"] = len(actions) == models.FeedPagingNum
 }
 
 // Dashboard render the dashborad page
@@ -131,52 +92,30 @@ func Dashboard(ctx *context.Context) {
 	ctx.Data["PageIsDashboard"] = true
 	ctx.Data["PageIsNews"] = true
 
-	// Only user can have collaborative repositories.
-	if !ctxUser.IsOrganization() {
-		collaborateRepos, err := ctx.User.GetAccessibleRepositories(setting.UI.User.RepoPagingNum)
-		if err != nil {
-			ctx.Handle(500, "GetAccessibleRepositories", err)
-			return
-		} else if err = models.RepositoryList(collaborateRepos).LoadAttributes(); err != nil {
-			ctx.Handle(500, "RepositoryList.LoadAttributes", err)
-			return
-		}
-		ctx.Data["CollaborativeRepos"] = collaborateRepos
-	}
-
-	var err error
-	var repos, mirrors []*models.Repository
+	// The repository list is searched through the API and the activity feed
+	// is loaded by DashboardFeeds after the page, so only mirrors are loaded.
+	var (
+		mirrors []*models.Repository
+		err     error
+	)
 	if ctxUser.IsOrganization() {
 		env, err := ctxUser.AccessibleReposEnv(ctx.User.ID)
 		if err != nil {
 			ctx.Handle(500, "AccessibleReposEnv", err)
 			return
 		}
-		repos, err = env.Repos(1, setting.UI.User.RepoPagingNum)
-		if err != nil {
-			ctx.Handle(500, "env.Repos", err)
-			return
-		}
-
 		mirrors, err = env.MirrorRepos()
 		if err != nil {
 			ctx.Handle(500, "env.MirrorRepos", err)
 			return
 		}
 	} else {
-		if err = ctxUser.GetRepositories(1, setting.UI.User.RepoPagingNum); err != nil {
-			ctx.Handle(500, "GetRepositories", err)
-			return
-		}
-		repos = ctxUser.Repos
-
 		mirrors, err = ctxUser.GetMirrorRepositories()
 		if err != nil {
 			ctx.Handle(500, "GetMirrorRepositories", err)
 			return
 		}
 	}
-	ctx.Data["Repos"] = repos
 	ctx.Data["MaxShowRepoNum"] = setting.UI.User.RepoPagingNum
 
 	if err := models.MirrorRepositoryList(mirrors).LoadAttributes(); err != nil {
@@ -186,11 +125,40 @@ func Dashboard(ctx *context.Context) {
 	ctx.Data["MirrorCount"] = len(mirrors)
 	ctx.Data["Mirrors"] = mirrors
 
-	retrieveFeeds(ctx, ctxUser, true, false)
+	ctx.HTML(200, tplDashborad)
+}
+
+// DashboardFeeds renders a page of the activity feed of the dashboard of the
+// signed in user or of the organization, or of the public activity of the
+// users the signed in user follows.
+func DashboardFeeds(ctx *context.Context) {
+	ctxUser := ctx.User
+	if ctx.Org.Organization != nil {
+		ctxUser = ctx.Org.Organization
+	}
+
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	tab := ctx.Query("tab")
+	if tab != "following" || ctxUser.IsOrganization() {
+		tab = ""
+	}
+
+	retrieveFeeds(ctx, models.GetFeedsOptions{
+		RequestedUser:  ctxUser,
+		IncludePrivate: true,
+		OnlyFollowing:  tab == "following",
+		Page:           page,
+	})
 	if ctx.Written() {
 		return
 	}
-	ctx.HTML(200, tplDashborad)
+	ctx.Data["FeedsTab"] = tab
+	ctx.Data["FeedsPage"] = page
+	ctx.Data["FeedsNextPage"] = page + 1
+	ctx.HTML(200, tplDashboardFeedsPage)
 }
 
 // Issues render the user issues page
@@ -280,6 +248,7 @@ func Issues(ctx *context.Context) {
 			IsClosed: util.OptionalBoolOf(isShowClosed),
 			IsPull:   util.OptionalBoolOf(isPullList),
 			SortType: sortType,
+			Doer:     ctx.User,
 		})
 
 	case models.FilterModeAssign:
@@ -291,6 +260,7 @@ func Issues(ctx *context.Context) {
 			IsClosed:   util.OptionalBoolOf(isShowClosed),
 			IsPull:     util.OptionalBoolOf(isPullList),
 			SortType:   sortType,
+			Doer:       ctx.User,
 		})
 
 	case models.FilterModeCreate:
@@ -302,6 +272,7 @@ func Issues(ctx *context.Context) {
 			IsClosed: util.OptionalBoolOf(isShowClosed),
 			IsPull:   util.OptionalBoolOf(isPullList),
 			SortType: sortType,
+			Doer:     ctx.User,
 		})
 	case models.FilterModeMention:
 		// Get all issues created by this user.
@@ -312,6 +283,7 @@ func Issues(ctx *context.Context) {
 			IsClosed:    util.OptionalBoolOf(isShowClosed),
 			IsPull:      util.OptionalBoolOf(isPullList),
 			SortType:    sortType,
+			Doer:        ctx.User,
 		})
 	}
 
@@ -449,6 +421,8 @@ func showOrgProfile(ctx *context.Context) {
 	}
 	ctx.Data["Page"] = paginater.New(int(count), setting.UI.User.RepoPagingNum, page, 5)
 
+	renderProfileReadme(ctx, org)
+
 	if err := org.GetMembers(); err != nil {
 		ctx.Handle(500, "GetMembers", err)
 		return
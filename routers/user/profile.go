@@ -11,10 +11,13 @@ import (
 
 	"github.com/Unknwon/paginater"
 
+	"code.gitea.io/git"
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/routers/repo"
 )
 
@@ -42,6 +45,72 @@ func GetUserByParams(ctx *context.Context) *models.User {
 	return GetUserByName(ctx, ctx.Params(":username"))
 }
 
+// profileReadmeRepoNames are the names of the repositories whose README is
+// shown on the profile page of their owner, the first existing one is used.
+func profileReadmeRepoNames(owner *models.User) []string {
+	return []string{".profile", owner.Name}
+}
+
+// renderProfileReadme renders the README of the public profile repository of
+// the owner, the result is cached by the SHA of the blob. A broken profile
+// repository must not break the profile page, so errors are only logged.
+func renderProfileReadme(ctx *context.Context, owner *models.User) {
+	for _, name := range profileReadmeRepoNames(owner) {
+		profileRepo, err := models.GetRepositoryByName(owner.ID, name)
+		if err != nil {
+			if !models.IsErrRepoNotExist(err) {
+				log.Error(4, "GetRepositoryByName: %v", err)
+			}
+			continue
+		} else if profileRepo.IsPrivate || profileRepo.IsBare {
+			continue
+		}
+		profileRepo.Owner = owner
+
+		gitRepo, err := git.OpenRepository(profileRepo.RepoPath())
+		if err != nil {
+			log.Error(4, "OpenRepository: %v", err)
+			return
+		}
+		commit, err := gitRepo.GetBranchCommit(profileRepo.DefaultBranch)
+		if err != nil {
+			log.Error(4, "GetBranchCommit: %v", err)
+			return
+		}
+		entries, err := commit.ListEntries()
+		if err != nil {
+			log.Error(4, "ListEntries: %v", err)
+			return
+		}
+		readmeEntry := repo.FindReadmeEntry(entries)
+		if readmeEntry == nil {
+			continue
+		}
+
+		readmeFile := readmeEntry.Blob()
+		treeLink := profileRepo.Link() + "/src/" + profileRepo.DefaultBranch
+		metas := profileRepo.ComposeMetas()
+		cacheKey := context.RenderCacheKey("profile-readme", metas, readmeFile.ID.String(), readmeFile.Name(), treeLink)
+		content, ok := ctx.GetRenderCache(cacheKey)
+		if !ok {
+			var isTextFile bool
+			content, isTextFile, err = repo.RenderReadmeBlob(readmeFile, treeLink, metas)
+			if err != nil {
+				log.Error(4, "RenderReadmeBlob: %v", err)
+				return
+			} else if !isTextFile {
+				continue
+			}
+			ctx.PutRenderCache(cacheKey, content)
+		}
+
+		ctx.Data["ProfileReadme"] = content
+		ctx.Data["ProfileReadmeRepo"] = profileRepo
+		ctx.Data["ProfileReadmeFileName"] = readmeFile.Name()
+		return
+	}
+}
+
 // Profile render user's profile page
 func Profile(ctx *context.Context) {
 	uname := ctx.Params(":username")
@@ -64,6 +133,14 @@ func Profile(ctx *context.Context) {
 		return
 	}
 
+	// Suspended users may be hidden from everyone but admins, their
+	// repositories stay visible.
+	if ctxUser.IsSuspended && setting.Service.HideSuspendedUsers &&
+		!(ctx.IsSigned && ctx.User.IsAdmin) {
+		ctx.Handle(404, "GetUserByName", models.ErrUserNotExist{Name: ctxUser.Name})
+		return
+	}
+
 	// Show SSH keys.
 	if isShowKeys {
 		ShowSSHKeys(ctx, ctxUser.ID)
@@ -82,6 +159,13 @@ func Profile(ctx *context.Context) {
 		return
 	}
 
+	profileFields, err := models.GetProfileFieldValues(ctxUser, ctx.User)
+	if err != nil {
+		ctx.Handle(500, "GetProfileFieldValues", err)
+		return
+	}
+	ctx.Data["ProfileFieldValues"] = profileFields
+
 	ctx.Data["Title"] = ctxUser.DisplayName()
 	ctx.Data["PageIsUserProfile"] = true
 	ctx.Data["Owner"] = ctxUser
@@ -138,13 +222,32 @@ func Profile(ctx *context.Context) {
 	ctx.Data["Keyword"] = keyword
 	switch tab {
 	case "activity":
-		retrieveFeeds(ctx, ctxUser, showPrivate, true)
+		retrieveFeeds(ctx, models.GetFeedsOptions{
+			RequestedUser:   ctxUser,
+			IncludePrivate:  showPrivate,
+			OnlyPerformedBy: true,
+		})
 		if ctx.Written() {
 			return
 		}
 	case "stars":
 		ctx.Data["PageIsProfileStarList"] = true
-		if len(keyword) == 0 {
+
+		languages, err := models.GetStatLanguages()
+		if err != nil {
+			ctx.Handle(500, "GetStatLanguages", err)
+			return
+		}
+		ctx.Data["Languages"] = languages
+
+		language := ctx.Query("language")
+		fork := ctx.QueryOptionalBool("fork")
+		mirror := ctx.QueryOptionalBool("mirror")
+		ctx.Data["Language"] = language
+		ctx.Data["Fork"] = ctx.Query("fork")
+		ctx.Data["Mirror"] = ctx.Query("mirror")
+
+		if len(keyword) == 0 && len(language) == 0 && fork == util.OptionalBoolNone && mirror == util.OptionalBoolNone {
 			repos, err = ctxUser.GetStarredRepos(showPrivate, page, setting.UI.User.RepoPagingNum, orderBy)
 			if err != nil {
 				ctx.Handle(500, "GetStarredRepos", err)
@@ -165,6 +268,9 @@ func Profile(ctx *context.Context) {
 				Page:     page,
 				PageSize: setting.UI.User.RepoPagingNum,
 				Starred:  true,
+				Language: language,
+				Fork:     fork,
+				Mirror:   mirror,
 			})
 			if err != nil {
 				ctx.Handle(500, "SearchRepositoryByName", err)
@@ -176,6 +282,8 @@ func Profile(ctx *context.Context) {
 		ctx.Data["Page"] = paginater.New(int(count), setting.UI.User.RepoPagingNum, page, 5)
 		ctx.Data["Total"] = count
 	default:
+		renderProfileReadme(ctx, ctxUser)
+
 		if len(keyword) == 0 {
 			var total int
 			repos, err = models.GetUserRepositories(ctxUser.ID, showPrivate, page, setting.UI.User.RepoPagingNum, orderBy)
@@ -232,7 +340,9 @@ func Followers(ctx *context.Context) {
 	ctx.Data["CardsTitle"] = ctx.Tr("user.followers")
 	ctx.Data["PageIsFollowers"] = true
 	ctx.Data["Owner"] = u
-	repo.RenderUserCards(ctx, u.NumFollowers, u.GetFollowers, tplFollowers)
+	repo.RenderUserCards(ctx, u.NumFollowers, func(page int) ([]*models.User, error) {
+		return u.GetFollowers(page, models.ItemsPerPage)
+	}, tplFollowers)
 }
 
 // Following render user's followering page
@@ -245,7 +355,9 @@ func Following(ctx *context.Context) {
 	ctx.Data["CardsTitle"] = ctx.Tr("user.following")
 	ctx.Data["PageIsFollowing"] = true
 	ctx.Data["Owner"] = u
-	repo.RenderUserCards(ctx, u.NumFollowing, u.GetFollowing, tplFollowers)
+	repo.RenderUserCards(ctx, u.NumFollowing, func(page int) ([]*models.User, error) {
+		return u.GetFollowing(page, models.ItemsPerPage)
+	}, tplFollowers)
 }
 
 // Action response for follow/unfollow user request
@@ -0,0 +1,71 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+)
+
+const (
+	tplReport base.TplName = "user/report"
+)
+
+// prepareReport describes the content the user is reporting, only content
+// the user can see can be reported.
+func prepareReport(ctx *context.Context) *models.AbuseReport {
+	ctx.Data["Title"] = ctx.Tr("user.report")
+	ctx.Data["Type"] = ctx.Query("type")
+	ctx.Data["TargetID"] = ctx.QueryInt64("target_id")
+
+	report, err := models.DescribeAbuseReportTarget(ctx.User,
+		models.ToAbuseReportType(ctx.Query("type")), ctx.QueryInt64("target_id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("DescribeAbuseReportTarget", models.IsErrAbuseReportTargetNotExist, err)
+		return nil
+	}
+	ctx.Data["Report"] = report
+	return report
+}
+
+// Report render the page to report content to the moderators
+func Report(ctx *context.Context) {
+	prepareReport(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.HTML(200, tplReport)
+}
+
+// ReportPost response for reporting content to the moderators
+func ReportPost(ctx *context.Context, form auth.AbuseReportForm) {
+	report := prepareReport(ctx)
+	if ctx.Written() {
+		return
+	}
+	if ctx.HasError() {
+		ctx.HTML(200, tplReport)
+		return
+	}
+
+	if _, err := models.NewAbuseReport(ctx.User, report.Type, report.TargetID, form.Reason); err != nil {
+		switch {
+		case models.IsErrAbuseReportAlreadyExist(err):
+			ctx.RenderWithErr(ctx.Tr("user.report_already_exists"), tplReport, &form)
+		case models.IsErrAbuseReportLimitExceeded(err):
+			ctx.RenderWithErr(ctx.Tr("user.report_limit_exceeded"), tplReport, &form)
+		default:
+			ctx.NotFoundOrServerError("NewAbuseReport", models.IsErrAbuseReportTargetNotExist, err)
+		}
+		return
+	}
+
+	log.Trace("Abuse report filed by %s: %s %d", ctx.User.Name, report.Type.Name(), report.TargetID)
+	ctx.Flash.Success(ctx.Tr("user.report_success"))
+	ctx.Redirect(report.TargetLink)
+}
@@ -21,6 +21,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/auth/oauth2"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
@@ -31,6 +32,7 @@ const (
 	tplSettingsProfile      base.TplName = "user/settings/profile"
 	tplSettingsAvatar       base.TplName = "user/settings/avatar"
 	tplSettingsPassword     base.TplName = "user/settings/password"
+	tplSettingsSecurity     base.TplName = "user/settings/security"
 	tplSettingsEmails       base.TplName = "user/settings/email"
 	tplSettingsKeys         base.TplName = "user/settings/keys"
 	tplSettingsSocial       base.TplName = "user/settings/social"
@@ -39,17 +41,41 @@ const (
 	tplSettingsTwofaEnroll  base.TplName = "user/settings/twofa_enroll"
 	tplSettingsAccountLink  base.TplName = "user/settings/account_link"
 	tplSettingsOrganization base.TplName = "user/settings/organization"
+	tplSettingsExport       base.TplName = "user/settings/export"
 	tplSettingsDelete       base.TplName = "user/settings/delete"
 	tplSecurity             base.TplName = "user/security"
+
+	tplSettingsNotificationChannels base.TplName = "user/settings/notification_channels"
 )
 
 // Settings render user's profile page
 func Settings(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("settings")
 	ctx.Data["PageIsSettingsProfile"] = true
+
+	loadProfileFields(ctx)
+	if ctx.Written() {
+		return
+	}
+
 	ctx.HTML(200, tplSettingsProfile)
 }
 
+// loadProfileFields makes the custom profile fields and their values in the
+// profile of the signed in user available to the template.
+func loadProfileFields(ctx *context.Context) {
+	fields, err := models.GetProfileFields()
+	if err != nil {
+		ctx.Handle(500, "GetProfileFields", err)
+		return
+	}
+	ctx.Data["ProfileFields"] = fields
+
+	if err = models.LoadProfileFieldValues([]*models.User{ctx.User}); err != nil {
+		ctx.Handle(500, "LoadProfileFieldValues", err)
+	}
+}
+
 func handleUsernameChange(ctx *context.Context, newName string) {
 	// Non-local users are not allowed to change their username.
 	if len(newName) == 0 || !ctx.User.IsLocal() {
@@ -90,6 +116,11 @@ func SettingsPost(ctx *context.Context, form auth.UpdateProfileForm) {
 	ctx.Data["Title"] = ctx.Tr("settings")
 	ctx.Data["PageIsSettingsProfile"] = true
 
+	loadProfileFields(ctx)
+	if ctx.Written() {
+		return
+	}
+
 	if ctx.HasError() {
 		ctx.HTML(200, tplSettingsProfile)
 		return
@@ -100,11 +131,43 @@ func SettingsPost(ctx *context.Context, form auth.UpdateProfileForm) {
 		return
 	}
 
+	fields := ctx.Data["ProfileFields"].([]*models.ProfileField)
+	values := make(map[int64]string, len(fields))
+	for _, field := range fields {
+		values[field.ID] = ctx.Query(fmt.Sprintf("profile_field_%d", field.ID))
+	}
+	if err := models.UpdateProfileFieldValues(ctx.User.ID, values); err != nil {
+		if models.IsErrProfileFieldInvalidValue(err) {
+			ctx.Flash.Error(ctx.Tr("settings.profile_field_invalid_value", err.(models.ErrProfileFieldInvalidValue).Name))
+			ctx.Redirect(setting.AppSubURL + "/user/settings")
+			return
+		}
+		ctx.Handle(500, "UpdateProfileFieldValues", err)
+		return
+	}
+
+	// A new primary email address has to be confirmed first if required.
+	pending, err := models.ChangePrimaryEmail(ctx.User, form.Email)
+	if err != nil {
+		switch {
+		case models.IsErrEmailAlreadyUsed(err):
+			ctx.Flash.Error(ctx.Tr("form.email_been_used"))
+		case models.IsErrEmailDomainNotAllowed(err):
+			ctx.Flash.Error(ctx.Tr("form.email_domain_not_allowed"))
+		default:
+			ctx.Handle(500, "ChangePrimaryEmail", err)
+			return
+		}
+		ctx.Redirect(setting.AppSubURL + "/user/settings")
+		return
+	}
+
 	ctx.User.FullName = form.FullName
-	ctx.User.Email = form.Email
 	ctx.User.KeepEmailPrivate = form.KeepEmailPrivate
 	ctx.User.Website = form.Website
 	ctx.User.Location = form.Location
+	ctx.User.StatusMessage = form.StatusMessage
+	ctx.User.IsBusy = form.IsBusy
 	if err := models.UpdateUserSetting(ctx.User); err != nil {
 		if _, ok := err.(models.ErrEmailAlreadyUsed); ok {
 			ctx.Flash.Error(ctx.Tr("form.email_been_used"))
@@ -116,7 +179,12 @@ func SettingsPost(ctx *context.Context, form auth.UpdateProfileForm) {
 	}
 
 	log.Trace("User settings updated: %s", ctx.User.Name)
-	ctx.Flash.Success(ctx.Tr("settings.update_profile_success"))
+	if pending != nil {
+		models.SendActivateEmailMail(ctx.Context, ctx.User, pending)
+		ctx.Flash.Info(ctx.Tr("settings.primary_email_confirmation_sent", pending.Email, base.MinutesToFriendly(setting.Service.ActiveCodeLives)))
+	} else {
+		ctx.Flash.Success(ctx.Tr("settings.update_profile_success"))
+	}
 	ctx.Redirect(setting.AppSubURL + "/user/settings")
 }
 
@@ -195,6 +263,7 @@ func SettingsPassword(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("settings")
 	ctx.Data["PageIsSettingsPassword"] = true
 	ctx.Data["Email"] = ctx.User.Email
+	ctx.Data["PasswordExpiryDays"] = setting.PasswordExpiryDays
 	ctx.HTML(200, tplSettingsPassword)
 }
 
@@ -213,6 +282,10 @@ func SettingsPasswordPost(ctx *context.Context, form auth.ChangePasswordForm) {
 		ctx.Flash.Error(ctx.Tr("settings.password_incorrect"))
 	} else if form.Password != form.Retype {
 		ctx.Flash.Error(ctx.Tr("form.password_not_match"))
+	} else if msg := checkPassword(ctx, form.Password); len(msg) > 0 {
+		ctx.Flash.Error(msg)
+	} else if ctx.User.ValidatePassword(form.Password) {
+		ctx.Flash.Error(ctx.Tr("settings.password_unchanged"))
 	} else {
 		ctx.User.Passwd = form.Password
 		var err error
@@ -232,6 +305,102 @@ func SettingsPasswordPost(ctx *context.Context, form auth.ChangePasswordForm) {
 	ctx.Redirect(setting.AppSubURL + "/user/settings/password")
 }
 
+// SettingsSecurity render the recent sign-in attempts to user's account
+func SettingsSecurity(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("settings")
+	ctx.Data["PageIsSettingsSecurity"] = true
+
+	attempts, err := models.GetLoginAttempts(ctx.User.ID, 30)
+	if err != nil {
+		ctx.Handle(500, "GetLoginAttempts", err)
+		return
+	}
+	ctx.Data["LoginAttempts"] = attempts
+
+	sessions, err := models.GetUserSessions(ctx.User.ID)
+	if err != nil {
+		ctx.Handle(500, "GetUserSessions", err)
+		return
+	}
+	ctx.Data["Sessions"] = sessions
+	ctx.Data["CurrentSessionID"] = ctx.Session.ID()
+
+	ctx.HTML(200, tplSettingsSecurity)
+}
+
+// revokeSessions signs out the other sessions of the user, whatever the
+// session provider is, by removing their data.
+func revokeSessions(ctx *context.Context, sessions []*models.UserSession) error {
+	for _, s := range sessions {
+		if s.SessionID == ctx.Session.ID() {
+			continue
+		}
+		store, err := ctx.Session.Read(s.SessionID)
+		if err != nil {
+			return fmt.Errorf("Read: %v", err)
+		} else if err = store.Flush(); err != nil {
+			return fmt.Errorf("Flush: %v", err)
+		} else if err = store.Release(); err != nil {
+			return fmt.Errorf("Release: %v", err)
+		}
+	}
+	if err := models.RevokeUserSessions(ctx.User, sessions); err != nil {
+		return fmt.Errorf("RevokeUserSessions: %v", err)
+	}
+
+	// Remember the sign-in of the current browser again with the new secret.
+	if len(ctx.GetCookie(setting.CookieUserName)) > 0 {
+		days := 86400 * setting.LogInRememberDays
+		ctx.SetSuperSecureCookie(base.EncodeMD5(ctx.User.Rands+ctx.User.Passwd),
+			setting.CookieRememberName, ctx.User.Name, days, setting.AppSubURL)
+	}
+	return nil
+}
+
+// SettingsRevokeSession signs out one of the other sessions of the user
+func SettingsRevokeSession(ctx *context.Context) {
+	sessions, err := models.GetUserSessions(ctx.User.ID)
+	if err != nil {
+		ctx.Handle(500, "GetUserSessions", err)
+		return
+	}
+	for _, s := range sessions {
+		if s.ID == ctx.QueryInt64("id") && s.SessionID != ctx.Session.ID() {
+			if err = revokeSessions(ctx, []*models.UserSession{s}); err != nil {
+				ctx.Handle(500, "revokeSessions", err)
+				return
+			}
+			log.Trace("Session %d of %s revoked", s.ID, ctx.User.Name)
+			ctx.Flash.Success(ctx.Tr("settings.session_revoke_success"))
+			break
+		}
+	}
+	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
+}
+
+// SettingsRevokeOtherSessions signs out all the other sessions of the user
+func SettingsRevokeOtherSessions(ctx *context.Context) {
+	sessions, err := models.GetUserSessions(ctx.User.ID)
+	if err != nil {
+		ctx.Handle(500, "GetUserSessions", err)
+		return
+	}
+	others := make([]*models.UserSession, 0, len(sessions))
+	for _, s := range sessions {
+		if s.SessionID != ctx.Session.ID() {
+			others = append(others, s)
+		}
+	}
+	if err = revokeSessions(ctx, others); err != nil {
+		ctx.Handle(500, "revokeSessions", err)
+		return
+	}
+
+	log.Trace("Other sessions of %s revoked", ctx.User.Name)
+	ctx.Flash.Success(ctx.Tr("settings.session_revoke_others_success"))
+	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
+}
+
 // SettingsEmails render user's emails page
 func SettingsEmails(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("settings")
@@ -286,6 +455,9 @@ func SettingsEmailPost(ctx *context.Context, form auth.AddEmailForm) {
 		if models.IsErrEmailAlreadyUsed(err) {
 			ctx.RenderWithErr(ctx.Tr("form.email_been_used"), tplSettingsEmails, &form)
 			return
+		} else if models.IsErrEmailDomainNotAllowed(err) {
+			ctx.RenderWithErr(ctx.Tr("form.email_domain_not_allowed"), tplSettingsEmails, &form)
+			return
 		}
 		ctx.Handle(500, "AddEmailAddress", err)
 		return
@@ -449,6 +621,68 @@ func DeleteKey(ctx *context.Context) {
 	})
 }
 
+// SettingsNotificationChannels render the chat notification channels of the user
+func SettingsNotificationChannels(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("settings")
+	ctx.Data["PageIsSettingsNotificationChannels"] = true
+
+	channels, err := models.GetNotificationChannelsByUserID(ctx.User.ID)
+	if err != nil {
+		ctx.Handle(500, "GetNotificationChannelsByUserID", err)
+		return
+	}
+	ctx.Data["NotificationChannels"] = channels
+
+	ctx.HTML(200, tplSettingsNotificationChannels)
+}
+
+// SettingsNotificationChannelsPost response for adding a chat notification channel of the user
+func SettingsNotificationChannelsPost(ctx *context.Context, form auth.NotificationChannelForm) {
+	ctx.Data["Title"] = ctx.Tr("settings")
+	ctx.Data["PageIsSettingsNotificationChannels"] = true
+
+	channels, err := models.GetNotificationChannelsByUserID(ctx.User.ID)
+	if err != nil {
+		ctx.Handle(500, "GetNotificationChannelsByUserID", err)
+		return
+	}
+	ctx.Data["NotificationChannels"] = channels
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplSettingsNotificationChannels)
+		return
+	}
+	if form.IsMatrixIncomplete() {
+		ctx.Data["Err_RoomID"] = true
+		ctx.RenderWithErr(ctx.Tr("settings.notification_channel_matrix_incomplete"), tplSettingsNotificationChannels, &form)
+		return
+	}
+
+	c := form.NotificationChannel()
+	c.UserID = ctx.User.ID
+	if err = models.NewNotificationChannel(c); err != nil {
+		ctx.Handle(500, "NewNotificationChannel", err)
+		return
+	}
+
+	log.Trace("Notification channel added: %d", ctx.User.ID)
+	ctx.Flash.Success(ctx.Tr("settings.add_notification_channel_success"))
+	ctx.Redirect(setting.AppSubURL + "/user/settings/notifications")
+}
+
+// SettingsDeleteNotificationChannel response for deleting a chat notification channel of the user
+func SettingsDeleteNotificationChannel(ctx *context.Context) {
+	if err := models.DeleteNotificationChannel(ctx.User.ID, 0, ctx.QueryInt64("id")); err != nil {
+		ctx.Flash.Error("DeleteNotificationChannel: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("settings.notification_channel_deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/user/settings/notifications",
+	})
+}
+
 // SettingsApplications render user's access tokens page
 func SettingsApplications(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("settings")
@@ -720,12 +954,55 @@ func SettingsAccountLinks(ctx *context.Context) {
 	}
 	ctx.Data["AccountLinks"] = sources
 
+	orderedOAuth2Names, oauth2Providers, err := models.GetActiveOAuth2Providers()
+	if err != nil {
+		ctx.Handle(500, "GetActiveOAuth2Providers", err)
+		return
+	}
+	// providers the user already signs in with cannot be linked again
+	linkedSources := make(map[string]bool, len(sources)+1)
+	for loginSource := range sources {
+		linkedSources[loginSource.Name] = true
+	}
+	if ctx.User.LoginType == models.LoginOAuth2 {
+		if loginSource, err := models.GetLoginSourceByID(ctx.User.LoginSource); err == nil {
+			linkedSources[loginSource.Name] = true
+		}
+	}
+	linkableOAuth2Names := make([]string, 0, len(orderedOAuth2Names))
+	for _, name := range orderedOAuth2Names {
+		if !linkedSources[name] {
+			linkableOAuth2Names = append(linkableOAuth2Names, name)
+		}
+	}
+	ctx.Data["LinkableOAuth2Names"] = linkableOAuth2Names
+	ctx.Data["OAuth2Providers"] = oauth2Providers
+
 	ctx.HTML(200, tplSettingsAccountLink)
 }
 
+// SettingsLinkAccount starts linking an external account of given provider
+// to the signed in user, the provider calls back to SignInOAuthCallback.
+func SettingsLinkAccount(ctx *context.Context) {
+	loginSource, err := models.GetActiveOAuth2LoginSourceByName(ctx.Query("provider"))
+	if err != nil {
+		ctx.Handle(500, "GetActiveOAuth2LoginSourceByName", err)
+		return
+	} else if loginSource == nil {
+		ctx.Handle(404, "GetActiveOAuth2LoginSourceByName", nil)
+		return
+	}
+
+	ctx.Session.Set("linkAccountUserID", ctx.User.ID)
+	if err = oauth2.Auth(loginSource.Name, ctx.Req.Request, ctx.Resp); err != nil {
+		ctx.Handle(500, "Auth", err)
+	}
+	// redirect is done in oauth2.Auth
+}
+
 // SettingsDeleteAccountLink delete a single account link
 func SettingsDeleteAccountLink(ctx *context.Context) {
-	if _, err := models.RemoveAccountLink(ctx.User, ctx.QueryInt64("loginSourceID")); err != nil {
+	if _, err := models.RemoveAccountLink(ctx.User, ctx.QueryInt64("id")); err != nil {
 		ctx.Flash.Error("RemoveAccountLink: " + err.Error())
 	} else {
 		ctx.Flash.Success(ctx.Tr("settings.remove_account_link_success"))
@@ -736,6 +1013,49 @@ func SettingsDeleteAccountLink(ctx *context.Context) {
 	})
 }
 
+// SettingsExport render the page to download an archive of the user's data
+func SettingsExport(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("settings")
+	ctx.Data["PageIsSettingsExport"] = true
+
+	export, err := models.GetLatestUserExport(ctx.User.ID)
+	if err != nil && !models.IsErrUserExportNotExist(err) {
+		ctx.Handle(500, "GetLatestUserExport", err)
+		return
+	}
+	ctx.Data["Export"] = export
+	ctx.HTML(200, tplSettingsExport)
+}
+
+// SettingsExportPost response for requesting an archive of the user's data
+func SettingsExportPost(ctx *context.Context) {
+	if _, err := models.NewUserExport(ctx.User); err != nil {
+		if !models.IsErrUserExportTooSoon(err) {
+			ctx.Handle(500, "NewUserExport", err)
+			return
+		}
+		next := err.(models.ErrUserExportTooSoon).Next
+		ctx.Flash.Error(ctx.Tr("settings.export_too_soon", next.Format("2006-01-02 15:04")))
+	} else {
+		log.Trace("User data export requested: %s", ctx.User.Name)
+		ctx.Flash.Success(ctx.Tr("settings.export_requested"))
+	}
+	ctx.Redirect(setting.AppSubURL + "/user/settings/export")
+}
+
+// SettingsExportDownload serves the archive of the user's data once it is ready
+func SettingsExportDownload(ctx *context.Context) {
+	export, err := models.GetLatestUserExport(ctx.User.ID)
+	if err != nil {
+		ctx.NotFoundOrServerError("GetLatestUserExport", models.IsErrUserExportNotExist, err)
+		return
+	} else if !export.IsReady() {
+		ctx.Handle(404, "GetLatestUserExport", nil)
+		return
+	}
+	ctx.ServeFile(export.ArchivePath(), ctx.User.Name+"-"+export.Completed.Format("20060102")+".zip")
+}
+
 // SettingsDelete render user suicide page and response for delete user himself
 func SettingsDelete(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("settings")
@@ -743,16 +1063,18 @@ func SettingsDelete(ctx *context.Context) {
 	ctx.Data["Email"] = ctx.User.Email
 
 	if ctx.Req.Method == "POST" {
-		if _, err := models.UserSignIn(ctx.User.Name, ctx.Query("password")); err != nil {
+		if _, err := models.UserSignInFrom(ctx.User.Name, ctx.Query("password"), ctx.RemoteAddr(), ctx.Req.UserAgent()); err != nil {
 			if models.IsErrUserNotExist(err) {
 				ctx.RenderWithErr(ctx.Tr("form.enterred_invalid_password"), tplSettingsDelete, nil)
+			} else if models.IsErrUserLocked(err) {
+				ctx.RenderWithErr(ctx.Tr("form.sign_in_locked"), tplSettingsDelete, nil)
 			} else {
 				ctx.Handle(500, "UserSignIn", err)
 			}
 			return
 		}
 
-		if err := models.DeleteUser(ctx.User); err != nil {
+		if err := models.SoftDeleteUser(ctx.User); err != nil {
 			switch {
 			case models.IsErrUserOwnRepos(err):
 				ctx.Flash.Error(ctx.Tr("form.still_own_repo"))
@@ -761,7 +1083,7 @@ func SettingsDelete(ctx *context.Context) {
 				ctx.Flash.Error(ctx.Tr("form.still_has_org"))
 				ctx.Redirect(setting.AppSubURL + "/user/settings/delete")
 			default:
-				ctx.Handle(500, "DeleteUser", err)
+				ctx.Handle(500, "SoftDeleteUser", err)
 			}
 		} else {
 			log.Trace("Account deleted: %s", ctx.User.Name)
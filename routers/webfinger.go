@@ -0,0 +1,127 @@
+// Copyright 2017 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package routers
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/v1/activitypub"
+)
+
+// webfingerLink is a link of a WebFinger resource, see RFC 7033.
+type webfingerLink struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type,omitempty"`
+	Href string `json:"href"`
+}
+
+// webfingerResource is the JSON resource descriptor of a user or a
+// repository.
+type webfingerResource struct {
+	Subject string          `json:"subject"`
+	Aliases []string        `json:"aliases"`
+	Links   []webfingerLink `json:"links"`
+}
+
+// webfingerTarget returns the owner name and, for a repository, the
+// repository name of a WebFinger resource: an account like
+// "acct:user@example.com" or the URL of a user or a repository.
+func webfingerTarget(resource string) (ownerName, repoName string, ok bool) {
+	appURL, err := url.Parse(setting.AppURL)
+	if err != nil {
+		return "", "", false
+	}
+
+	if strings.HasPrefix(resource, "acct:") {
+		parts := strings.SplitN(strings.TrimPrefix(resource, "acct:"), "@", 2)
+		if len(parts) != 2 || !strings.EqualFold(parts[1], appURL.Host) ||
+			len(parts[0]) == 0 || strings.Contains(parts[0], "/") {
+			return "", "", false
+		}
+		return parts[0], "", true
+	}
+
+	if !strings.HasPrefix(resource, setting.AppURL) {
+		return "", "", false
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(resource, setting.AppURL), "/"), "/")
+	switch {
+	case len(parts) == 1 && len(parts[0]) > 0:
+		return parts[0], "", true
+	case len(parts) == 2 && len(parts[0]) > 0 && len(parts[1]) > 0:
+		return parts[0], parts[1], true
+	}
+	return "", "", false
+}
+
+// WebFinger tells other instances the ActivityPub actor of a user or a
+// public repository
+func WebFinger(ctx *context.Context) {
+	if !setting.Federation.Enabled {
+		ctx.Error(404)
+		return
+	}
+
+	resource := ctx.Query("resource")
+	ownerName, repoName, ok := webfingerTarget(resource)
+	if !ok {
+		ctx.Error(400)
+		return
+	}
+
+	owner, err := models.GetUserByName(ownerName)
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			ctx.Error(404)
+		} else {
+			log.Error(4, "GetUserByName: %v", err)
+			ctx.Error(500)
+		}
+		return
+	}
+	htmlURL, actorURL, avatarURL := owner.HTMLURL(), owner.ActorURL(), owner.AvatarLink()
+	if len(repoName) > 0 {
+		repo, err := models.GetRepositoryByName(owner.ID, repoName)
+		if err != nil {
+			if models.IsErrRepoNotExist(err) {
+				ctx.Error(404)
+			} else {
+				log.Error(4, "GetRepositoryByName: %v", err)
+				ctx.Error(500)
+			}
+			return
+		} else if repo.IsPrivate {
+			ctx.Error(404)
+			return
+		}
+		repo.Owner = owner
+		htmlURL, actorURL, avatarURL = repo.HTMLURL(), repo.ActorURL(), repo.AvatarLink()
+	}
+
+	data, err := json.Marshal(&webfingerResource{
+		Subject: resource,
+		Aliases: []string{htmlURL, actorURL},
+		Links: []webfingerLink{
+			{Rel: "http://webfinger.net/rel/profile-page", Type: "text/html", Href: htmlURL},
+			{Rel: "self", Type: activitypub.ContentType, Href: actorURL},
+			{Rel: "http://webfinger.net/rel/avatar", Href: avatarURL},
+		},
+	})
+	if err != nil {
+		log.Error(4, "Marshal: %v", err)
+		ctx.Error(500)
+		return
+	}
+	ctx.Resp.Header().Set("Content-Type", "application/jrd+json; charset=utf-8")
+	ctx.Resp.Header().Set("Access-Control-Allow-Origin", "*")
+	ctx.Resp.WriteHeader(200)
+	ctx.Resp.Write(data)
+}